@@ -0,0 +1,117 @@
+package activities
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/cloud/buckets"
+	"github.com/pkg/errors"
+)
+
+const (
+	// ArchivePrefix the prefix of the keys of the archived activities in the bucket
+	ArchivePrefix = "jenkins-x/activities/"
+
+	// DefaultArchiveTimeout the default timeout of reading and writing the bucket
+	DefaultArchiveTimeout = time.Minute
+)
+
+// Archive archives PipelineActivity resources to a bucket as JSON lines files partitioned by the month
+// the activities completed so they can be queried by tools such as BigQuery or Athena
+type Archive struct {
+	BucketURL string
+	Timeout   time.Duration
+}
+
+// NewArchive creates an archive in the given bucket URL
+func NewArchive(bucketURL string) *Archive {
+	return &Archive{
+		BucketURL: bucketURL,
+		Timeout:   DefaultArchiveTimeout,
+	}
+}
+
+// ArchiveKey returns the key of a new archive file for activities archived at the given time
+func ArchiveKey(now time.Time) string {
+	now = now.UTC()
+	return fmt.Sprintf("%s%04d/%02d/%s.jsonl", ArchivePrefix, now.Year(), now.Month(), now.Format("20060102T150405.000000000Z"))
+}
+
+// Write writes the activities to a new file in the archive returning its key
+func (a *Archive) Write(activities []v1.PipelineActivity, now time.Time) (string, error) {
+	data, err := MarshalActivities(activities)
+	if err != nil {
+		return "", err
+	}
+	key := ArchiveKey(now)
+	err = buckets.WriteBucket(a.BucketURL, key, data, a.Timeout)
+	if err != nil {
+		return "", errors.Wrapf(err, "archiving %d activities", len(activities))
+	}
+	return key, nil
+}
+
+// Load loads all of the archived activities
+func (a *Archive) Load() ([]v1.PipelineActivity, error) {
+	keys, err := buckets.ListBucket(a.BucketURL, ArchivePrefix, a.Timeout)
+	if err != nil {
+		return nil, err
+	}
+	answer := []v1.PipelineActivity{}
+	for _, key := range keys {
+		if !strings.HasSuffix(key, ".jsonl") {
+			continue
+		}
+		data, err := buckets.ReadBucket(a.BucketURL, key, a.Timeout)
+		if err != nil {
+			return answer, err
+		}
+		activities, err := UnmarshalActivities(data)
+		if err != nil {
+			return answer, errors.Wrapf(err, "parsing archive %s", key)
+		}
+		answer = append(answer, activities...)
+	}
+	return answer, nil
+}
+
+// MarshalActivities marshals the activities as JSON lines
+func MarshalActivities(activities []v1.PipelineActivity) ([]byte, error) {
+	var buffer bytes.Buffer
+	for i := range activities {
+		activity := activities[i]
+		activity.ResourceVersion = ""
+		data, err := json.Marshal(&activity)
+		if err != nil {
+			return nil, errors.Wrapf(err, "marshalling activity %s", activity.Name)
+		}
+		buffer.Write(data)
+		buffer.WriteString("\n")
+	}
+	return buffer.Bytes(), nil
+}
+
+// UnmarshalActivities unmarshals activities from JSON lines
+func UnmarshalActivities(data []byte) ([]v1.PipelineActivity, error) {
+	answer := []v1.PipelineActivity{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		activity := v1.PipelineActivity{}
+		err := json.Unmarshal(line, &activity)
+		if err != nil {
+			return answer, errors.Wrap(err, "unmarshalling activity")
+		}
+		answer = append(answer, activity)
+	}
+	return answer, scanner.Err()
+}
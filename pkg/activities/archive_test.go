@@ -0,0 +1,64 @@
+package activities_test
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jenkins-x/jx/pkg/activities"
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestArchiveKey(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2019, time.November, 5, 10, 30, 0, 0, time.UTC)
+	key := activities.ArchiveKey(now)
+	assert.True(t, strings.HasPrefix(key, "jenkins-x/activities/2019/11/20191105T103000"), "key %s", key)
+	assert.True(t, strings.HasSuffix(key, ".jsonl"), "key %s", key)
+}
+
+func TestArchiveWriteAndLoad(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "test-activities-archive-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	archive := activities.NewArchive("file://" + dir)
+	now := time.Now()
+	_, err = archive.Write([]v1.PipelineActivity{newActivity("myorg-myapp-master-1", "1"), newActivity("myorg-myapp-master-2", "2")}, now)
+	require.NoError(t, err)
+	_, err = archive.Write([]v1.PipelineActivity{newActivity("myorg-myapp-master-3", "3")}, now.Add(time.Second))
+	require.NoError(t, err)
+
+	loaded, err := archive.Load()
+	require.NoError(t, err)
+	require.Len(t, loaded, 3)
+	builds := []string{}
+	for _, a := range loaded {
+		assert.Equal(t, "myorg/myapp/master", a.Spec.Pipeline)
+		assert.Equal(t, "", a.ResourceVersion)
+		builds = append(builds, a.Spec.Build)
+	}
+	assert.ElementsMatch(t, []string{"1", "2", "3"}, builds)
+}
+
+func newActivity(name string, build string) v1.PipelineActivity {
+	return v1.PipelineActivity{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            name,
+			ResourceVersion: "123",
+		},
+		Spec: v1.PipelineActivitySpec{
+			Pipeline: "myorg/myapp/master",
+			Build:    build,
+			Status:   v1.ActivityStatusTypeSucceeded,
+		},
+	}
+}
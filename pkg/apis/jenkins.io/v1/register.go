@@ -44,6 +44,8 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 		&BuildPackList{},
 		&App{},
 		&AppList{},
+		&AuditEvent{},
+		&AuditEventList{},
 		&CommitStatus{},
 		&CommitStatusList{},
 		&Environment{},
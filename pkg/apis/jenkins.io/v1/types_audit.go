@@ -0,0 +1,66 @@
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// AuditActionPullRequestRaised a pull request was raised, e.g. to promote an application or upgrade a version
+	AuditActionPullRequestRaised = "pr-raised"
+	// AuditActionEnvironmentApplied the helm chart of an environment was applied
+	AuditActionEnvironmentApplied = "environment-applied"
+	// AuditActionSecretWritten a secret was written
+	AuditActionSecretWritten = "secret-written"
+	// AuditActionAppUpgraded an app was installed or upgraded
+	AuditActionAppUpgraded = "app-upgraded"
+	// AuditActionPromotionWindowOverridden a promotion was forced outside of the promotion windows of an environment
+	AuditActionPromotionWindowOverridden = "promotion-window-overridden"
+)
+
+// +genclient
+// +genclient:noStatus
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +k8s:openapi-gen=true
+
+// AuditEvent records a change which was made by jx so that it can be determined who changed what and when
+type AuditEvent struct {
+	metav1.TypeMeta `json:",inline"`
+	// Standard object's metadata.
+	// More info: http://releases.k8s.io/HEAD/docs/devel/api-conventions.md#metadata
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty" protobuf:"bytes,1,opt,name=metadata"`
+
+	Spec AuditEventSpec `json:"spec,omitempty" protobuf:"bytes,2,opt,name=spec"`
+}
+
+// AuditEventSpec the details of a change made by jx
+type AuditEventSpec struct {
+	// Action the kind of change such as pr-raised, environment-applied, secret-written or app-upgraded
+	Action string `json:"action" protobuf:"bytes,1,opt,name=action"`
+	// User the user or service account which made the change
+	User string `json:"user,omitempty" protobuf:"bytes,2,opt,name=user"`
+	// Resource the resource which was changed such as a pull request URL, an environment, a secret or an app
+	Resource string `json:"resource,omitempty" protobuf:"bytes,3,opt,name=resource"`
+	// Namespace the namespace of the change if any
+	Namespace string `json:"namespace,omitempty" protobuf:"bytes,4,opt,name=namespace"`
+	// Message a description of the change
+	Message string `json:"message,omitempty" protobuf:"bytes,5,opt,name=message"`
+	// Command the jx command which made the change
+	Command string `json:"command,omitempty" protobuf:"bytes,6,opt,name=command"`
+	// Pipeline the pipeline which made the change if it was made from a pipeline
+	Pipeline string `json:"pipeline,omitempty" protobuf:"bytes,7,opt,name=pipeline"`
+	// Details any additional details of the change
+	Details map[string]string `json:"details,omitempty" protobuf:"bytes,8,rep,name=details"`
+	// Timestamp when the change was made
+	Timestamp metav1.Time `json:"timestamp,omitempty" protobuf:"bytes,9,opt,name=timestamp"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// AuditEventList is a list of AuditEvent resources
+type AuditEventList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []AuditEvent `json:"items"`
+}
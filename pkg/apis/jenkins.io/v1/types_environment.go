@@ -6,6 +6,7 @@ import (
 
 	"github.com/jenkins-x/jx/pkg/log"
 	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -19,6 +20,15 @@ const (
 	KubernetesWorkloadBuildPackURL = "https://github.com/jenkins-x-buildpacks/jenkins-x-kubernetes.git"
 	// KubernetesWorkloadBuildPackRef the git reference/version for the kubernetes workloads build packs
 	KubernetesWorkloadBuildPackRef = "master"
+
+	// SpotBuildPoolLabel the label and taint key of the spot/preemptible node pool used for builds
+	SpotBuildPoolLabel = "jenkins-x.io/build-pool"
+	// SpotBuildPoolValue the label and taint value of the spot/preemptible node pool used for builds
+	SpotBuildPoolValue = "spot"
+	// DefaultPreemptionRetries the default number of times the tasks of a pipeline running on spot nodes are retried
+	DefaultPreemptionRetries = 2
+	// DefaultReleasePipelinePriority the default priority of the release pipelines in the pipeline queue
+	DefaultReleasePipelinePriority = 100
 )
 
 // +genclient
@@ -54,12 +64,86 @@ type EnvironmentSpec struct {
 
 	// RemoteCluster flag indicates if the Environment is deployed in a separate cluster to the Development Environment
 	RemoteCluster bool `json:"remoteCluster,omitempty" protobuf:"bytes,12,opt,name=remoteCluster"`
+
+	// PromotionWindows restricts when applications can be promoted to the Environment
+	PromotionWindows *PromotionWindowSettings `json:"promotionWindows,omitempty" protobuf:"bytes,13,opt,name=promotionWindows"`
+
+	// ImagePromotion copies the images of the applications promoted to the Environment to its own container registry
+	ImagePromotion *ImagePromotionSettings `json:"imagePromotion,omitempty" protobuf:"bytes,14,opt,name=imagePromotion"`
+}
+
+// ImagePromotionSettings configures copying the images of the applications promoted to an Environment from the
+// registry of the development environment to the registry of the Environment
+type ImagePromotionSettings struct {
+	// Registry the host of the container registry of the Environment such as prod.azurecr.io
+	Registry string `json:"registry" protobuf:"bytes,1,opt,name=registry"`
+	// Organisation the organisation of the images in the registry of the Environment. Defaults to the organisation
+	// of the images in the registry of the development environment
+	Organisation string `json:"organisation,omitempty" protobuf:"bytes,2,opt,name=organisation"`
+	// RepositoryValue the path of the helm value of the image repository of the applications. Defaults to
+	// image.repository
+	RepositoryValue string `json:"repositoryValue,omitempty" protobuf:"bytes,3,opt,name=repositoryValue"`
+}
+
+// PromotionWindowSettings restricts promotions to an Environment to release train windows and blocks them during
+// freezes
+type PromotionWindowSettings struct {
+	// TimeZone the IANA time zone of the weekly windows such as Europe/London. Defaults to UTC
+	TimeZone string `json:"timeZone,omitempty" protobuf:"bytes,1,opt,name=timeZone"`
+	// Allowed the windows promotions are allowed in such as a weekly release train. Promotions are allowed at any
+	// time outside of the freezes if empty
+	Allowed []PromotionWindow `json:"allowed,omitempty" protobuf:"bytes,2,rep,name=allowed"`
+	// Freezes the windows promotions are not allowed in
+	Freezes []PromotionWindow `json:"freezes,omitempty" protobuf:"bytes,3,rep,name=freezes"`
+}
+
+// PromotionWindow a period of time which either recurs weekly such as from 'Fri 16:00' to 'Mon 09:00' or happens once
+// between two RFC 3339 timestamps such as from '2019-12-20T18:00:00Z' to '2020-01-06T09:00:00Z'
+type PromotionWindow struct {
+	// Name the description of the window such as 'weekend' or 'end of year freeze'
+	Name string `json:"name,omitempty" protobuf:"bytes,1,opt,name=name"`
+	// Start the start of the window
+	Start string `json:"start" protobuf:"bytes,2,opt,name=start"`
+	// End the end of the window
+	End string `json:"end" protobuf:"bytes,3,opt,name=end"`
 }
 
 // EnvironmentStatus is the status for an Environment resource
 type EnvironmentStatus struct {
 	Version string `json:"version,omitempty"`
-}
+
+	// SyncStatus the status of the last apply of the git repository of the environment
+	SyncStatus EnvironmentSyncStatus `json:"syncStatus,omitempty"`
+	// LastAttemptedCommit the commit of the git repository of the environment which was last applied or is being applied
+	LastAttemptedCommit string `json:"lastAttemptedCommit,omitempty"`
+	// LastAttemptedTime the time the last apply started
+	LastAttemptedTime *metav1.Time `json:"lastAttemptedTime,omitempty"`
+	// LastAppliedCommit the commit of the git repository of the environment which was last applied successfully
+	LastAppliedCommit string `json:"lastAppliedCommit,omitempty"`
+	// LastAppliedTime the time the last successful apply completed
+	LastAppliedTime *metav1.Time `json:"lastAppliedTime,omitempty"`
+	// Message describes why the last apply failed
+	Message string `json:"message,omitempty"`
+	// DriftedResources the number of applications whose running version differs from the git repository of the
+	// environment after the last apply
+	DriftedResources int `json:"driftedResources,omitempty"`
+}
+
+// EnvironmentSyncStatus the status of the apply of the git repository of an environment
+type EnvironmentSyncStatus string
+
+const (
+	// EnvironmentSyncStatusApplying the git repository of the environment is being applied
+	EnvironmentSyncStatusApplying EnvironmentSyncStatus = "Applying"
+	// EnvironmentSyncStatusSynced the git repository of the environment was applied and the applications run the
+	// versions of the git repository
+	EnvironmentSyncStatusSynced EnvironmentSyncStatus = "Synced"
+	// EnvironmentSyncStatusDrifted the git repository of the environment was applied but some applications do not run
+	// the versions of the git repository
+	EnvironmentSyncStatusDrifted EnvironmentSyncStatus = "Drifted"
+	// EnvironmentSyncStatusFailed the last apply of the git repository of the environment failed
+	EnvironmentSyncStatusFailed EnvironmentSyncStatus = "Failed"
+)
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
@@ -239,6 +323,115 @@ type TeamSettings struct {
 
 	// BootRequirements is a marshaled string of the jx-requirements.yaml used in the most recent run for this cluster
 	BootRequirements string `json:"bootRequirements,omitempty" protobuf:"bytes,31,opt,name=bootRequirements"`
+
+	// BuildPodPolicies the default resources, node selectors, tolerations and security context of the build pods
+	BuildPodPolicies []BuildPodPolicy `json:"buildPodPolicies,omitempty" protobuf:"bytes,32,rep,name=buildPodPolicies"`
+	// PipelineConcurrency limits the number of pipelines running at the same time, queueing the others
+	PipelineConcurrency *PipelineConcurrency `json:"pipelineConcurrency,omitempty" protobuf:"bytes,33,opt,name=pipelineConcurrency"`
+	// ImageScanPolicy the severity thresholds of the vulnerability scans of the images built by the pipelines
+	ImageScanPolicy *ImageScanPolicy `json:"imageScanPolicy,omitempty" protobuf:"bytes,34,opt,name=imageScanPolicy"`
+	// Audit configures where the changes made by jx are recorded
+	Audit *AuditSettings `json:"audit,omitempty" protobuf:"bytes,35,opt,name=audit"`
+	// ProvenancePolicy the environments which require the releases promoted to them to have signed build provenance
+	ProvenancePolicy *ProvenancePolicy `json:"provenancePolicy,omitempty" protobuf:"bytes,36,opt,name=provenancePolicy"`
+	// TagPolicy the environments which require the git tag, chart and image of the releases promoted to them to refer
+	// to the same commit
+	TagPolicy *TagPolicy `json:"tagPolicy,omitempty" protobuf:"bytes,37,opt,name=tagPolicy"`
+	// CloudEvents the sinks the lifecycle events of the pipelines, releases, promotions and previews are published to
+	CloudEvents *CloudEventsSettings `json:"cloudEvents,omitempty" protobuf:"bytes,38,opt,name=cloudEvents"`
+}
+
+// CloudEventsSettings configures the sinks the lifecycle events published by the controllers as CloudEvents are sent to
+type CloudEventsSettings struct {
+	// Sinks the sinks the events are sent to
+	Sinks []CloudEventSink `json:"sinks,omitempty" protobuf:"bytes,1,rep,name=sinks"`
+}
+
+// CloudEventSink a sink the CloudEvents are sent to
+type CloudEventSink struct {
+	// Kind the kind of the sink which is http, kafka or knative
+	Kind string `json:"kind" protobuf:"bytes,1,opt,name=kind"`
+	// URL the URL the events are posted to. For kafka sinks the URL of the Kafka REST proxy. Defaults to the broker
+	// ingress of the dev namespace for knative sinks
+	URL string `json:"url,omitempty" protobuf:"bytes,2,opt,name=url"`
+	// Topic the Kafka topic the events are published to
+	Topic string `json:"topic,omitempty" protobuf:"bytes,3,opt,name=topic"`
+	// Broker the name of the Knative broker. Defaults to default
+	Broker string `json:"broker,omitempty" protobuf:"bytes,4,opt,name=broker"`
+	// Types the types of the events sent to the sink. All the events are sent if empty
+	Types []string `json:"types,omitempty" protobuf:"bytes,5,rep,name=types"`
+}
+
+// TagPolicy the consistency checks of the git tags, charts and images of the releases
+type TagPolicy struct {
+	// ProtectedEnvironments the environments which releases whose git tag, chart version and image tag do not refer to
+	// the same commit cannot be promoted to
+	ProtectedEnvironments []string `json:"protectedEnvironments,omitempty" protobuf:"bytes,1,rep,name=protectedEnvironments"`
+}
+
+// ProvenancePolicy the requirements on the signed SLSA provenance generated by the release pipelines
+type ProvenancePolicy struct {
+	// ProtectedEnvironments the environments which releases without valid signed provenance cannot be promoted to
+	ProtectedEnvironments []string `json:"protectedEnvironments,omitempty" protobuf:"bytes,1,rep,name=protectedEnvironments"`
+	// TrustedBuilders the builder identities the provenance must have been produced by. Any builder if empty
+	TrustedBuilders []string `json:"trustedBuilders,omitempty" protobuf:"bytes,2,rep,name=trustedBuilders"`
+}
+
+// AuditSettings configures the sinks the audit events of the changes made by jx are recorded to
+type AuditSettings struct {
+	// Disabled disables recording audit events
+	Disabled bool `json:"disabled,omitempty" protobuf:"varint,1,opt,name=disabled"`
+	// Sinks the sinks the audit events are recorded to. The sinks are crd, bucket and webhook. Defaults to crd
+	Sinks []string `json:"sinks,omitempty" protobuf:"bytes,2,rep,name=sinks"`
+	// WebhookURL the URL the audit events are posted to by the webhook sink
+	WebhookURL string `json:"webhookUrl,omitempty" protobuf:"bytes,3,opt,name=webhookUrl"`
+}
+
+// ImageScanPolicy the severity thresholds applied to the vulnerabilities found by scanning the images built by the
+// pipelines. The severities are UNKNOWN, LOW, MEDIUM, HIGH and CRITICAL
+type ImageScanPolicy struct {
+	// FailSeverity the minimum severity of the vulnerabilities which fail the scan. The scan never fails if empty
+	FailSeverity string `json:"failSeverity,omitempty" protobuf:"bytes,1,opt,name=failSeverity"`
+	// WarnSeverity the minimum severity of the vulnerabilities which are reported as warnings. Defaults to LOW
+	WarnSeverity string `json:"warnSeverity,omitempty" protobuf:"bytes,2,opt,name=warnSeverity"`
+	// IgnoreUnfixed ignores the vulnerabilities which have no fixed version yet
+	IgnoreUnfixed bool `json:"ignoreUnfixed,omitempty" protobuf:"varint,3,opt,name=ignoreUnfixed"`
+	// IgnoredVulnerabilities the IDs of the vulnerabilities which have been accepted such as CVE-2019-1234
+	IgnoredVulnerabilities []string `json:"ignoredVulnerabilities,omitempty" protobuf:"bytes,4,rep,name=ignoredVulnerabilities"`
+	// ProtectedEnvironments the environments images with unresolved critical vulnerabilities cannot be promoted to
+	ProtectedEnvironments []string `json:"protectedEnvironments,omitempty" protobuf:"bytes,5,rep,name=protectedEnvironments"`
+}
+
+// PipelineConcurrency limits the number of PipelineRuns running at the same time. The PipelineRuns which cannot start
+// are queued until a running PipelineRun completes
+type PipelineConcurrency struct {
+	// MaxRuns the maximum number of PipelineRuns running in the cluster. Unlimited if 0
+	MaxRuns int `json:"maxRuns,omitempty" protobuf:"varint,1,opt,name=maxRuns"`
+	// MaxRunsPerRepository the maximum number of PipelineRuns running for a repository. Unlimited if 0
+	MaxRunsPerRepository int `json:"maxRunsPerRepository,omitempty" protobuf:"varint,2,opt,name=maxRunsPerRepository"`
+	// Priorities the priorities of the kinds of pipeline such as release or pullrequest. Queued pipelines with a
+	// higher priority start first. Release pipelines default to DefaultReleasePipelinePriority
+	Priorities map[string]int `json:"priorities,omitempty" protobuf:"bytes,3,rep,name=priorities"`
+	// Preempt cancels the running pipelines with a lower priority when a queued pipeline cannot start
+	Preempt bool `json:"preempt,omitempty" protobuf:"varint,4,opt,name=preempt"`
+}
+
+// BuildPodPolicy the defaults applied to the build pods of the pipelines of a kind
+type BuildPodPolicy struct {
+	// PipelineKind the kind of pipeline such as release, pullrequest or feature. Applies to all kinds if empty
+	PipelineKind string `json:"pipelineKind,omitempty" protobuf:"bytes,1,opt,name=pipelineKind"`
+	// Resources the default CPU and memory requests and limits of the steps of the build pods
+	Resources corev1.ResourceRequirements `json:"resources,omitempty" protobuf:"bytes,2,opt,name=resources"`
+	// NodeSelector the node selector of the build pods
+	NodeSelector map[string]string `json:"nodeSelector,omitempty" protobuf:"bytes,3,rep,name=nodeSelector"`
+	// Tolerations the tolerations of the build pods
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty" protobuf:"bytes,4,rep,name=tolerations"`
+	// SecurityContext the default security context of the steps of the build pods
+	SecurityContext *corev1.SecurityContext `json:"securityContext,omitempty" protobuf:"bytes,5,opt,name=securityContext"`
+	// Spot schedules the build pods on the spot/preemptible build node pool when it has capacity
+	Spot *bool `json:"spot,omitempty" protobuf:"varint,6,opt,name=spot"`
+	// PreemptionRetries the number of times the tasks of a pipeline running on spot nodes are retried if they fail
+	PreemptionRetries int `json:"preemptionRetries,omitempty" protobuf:"varint,7,opt,name=preemptionRetries"`
 }
 
 // StorageLocation
@@ -256,6 +449,9 @@ type QuickStartLocation struct {
 	Owner    string   `json:"owner,omitempty" protobuf:"bytes,3,opt,name=owner"`
 	Includes []string `json:"includes,omitempty" protobuf:"bytes,4,opt,name=includes"`
 	Excludes []string `json:"excludes,omitempty" protobuf:"bytes,5,opt,name=excludes"`
+	// Catalog the name of a repository of the owner containing a quickstarts.yml catalog of versioned quickstarts.
+	// If specified the quickstarts are loaded from the catalog rather than from the repositories of the owner
+	Catalog string `json:"catalog,omitempty" protobuf:"bytes,6,opt,name=catalog"`
 }
 
 // PreviewGitSpec is the preview git branch/pull request details
@@ -370,6 +566,96 @@ func (t *TeamSettings) SetStorageLocation(classifier string, storage StorageLoca
 	t.StorageLocations = append(t.StorageLocations, storage)
 }
 
+// GetBuildPodPolicy returns the build pod policy for the given kind of pipeline by overriding the policy for all kinds
+// with the policy for the kind. Returns nil if there is no policy
+func (t *TeamSettings) GetBuildPodPolicy(pipelineKind string) *BuildPodPolicy {
+	var answer *BuildPodPolicy
+	for _, kind := range []string{"", pipelineKind} {
+		for i := range t.BuildPodPolicies {
+			policy := &t.BuildPodPolicies[i]
+			if policy.PipelineKind != kind {
+				continue
+			}
+			if answer == nil {
+				answer = policy.DeepCopy()
+				answer.PipelineKind = pipelineKind
+				continue
+			}
+			answer.merge(policy)
+		}
+		if pipelineKind == "" {
+			break
+		}
+	}
+	return answer
+}
+
+// merge overrides the values of the policy with the values of the given policy
+func (p *BuildPodPolicy) merge(policy *BuildPodPolicy) {
+	for name, quantity := range policy.Resources.Requests {
+		if p.Resources.Requests == nil {
+			p.Resources.Requests = corev1.ResourceList{}
+		}
+		p.Resources.Requests[name] = quantity.DeepCopy()
+	}
+	for name, quantity := range policy.Resources.Limits {
+		if p.Resources.Limits == nil {
+			p.Resources.Limits = corev1.ResourceList{}
+		}
+		p.Resources.Limits[name] = quantity.DeepCopy()
+	}
+	for k, v := range policy.NodeSelector {
+		if p.NodeSelector == nil {
+			p.NodeSelector = map[string]string{}
+		}
+		p.NodeSelector[k] = v
+	}
+	if len(policy.Tolerations) > 0 {
+		p.Tolerations = append([]corev1.Toleration{}, policy.Tolerations...)
+	}
+	if policy.SecurityContext != nil {
+		p.SecurityContext = policy.SecurityContext.DeepCopy()
+	}
+	if policy.Spot != nil {
+		spot := *policy.Spot
+		p.Spot = &spot
+	}
+	if policy.PreemptionRetries > 0 {
+		p.PreemptionRetries = policy.PreemptionRetries
+	}
+}
+
+// IsLimited returns true if the number of running PipelineRuns is limited
+func (c *PipelineConcurrency) IsLimited() bool {
+	return c != nil && (c.MaxRuns > 0 || c.MaxRunsPerRepository > 0)
+}
+
+// GetPriority returns the priority of the given kind of pipeline
+func (c *PipelineConcurrency) GetPriority(pipelineKind string) int {
+	if c != nil {
+		if priority, ok := c.Priorities[pipelineKind]; ok {
+			return priority
+		}
+	}
+	if pipelineKind == "release" {
+		return DefaultReleasePipelinePriority
+	}
+	return 0
+}
+
+// IsSpot returns true if the build pods should be scheduled on the spot/preemptible build node pool
+func (p *BuildPodPolicy) IsSpot() bool {
+	return p.Spot != nil && *p.Spot
+}
+
+// GetPreemptionRetries returns the number of times the tasks of a pipeline running on spot nodes are retried
+func (p *BuildPodPolicy) GetPreemptionRetries() int {
+	if p.PreemptionRetries <= 0 {
+		return DefaultPreemptionRetries
+	}
+	return p.PreemptionRetries
+}
+
 // GetImportMode returns the import mode - returning a default value if it has not been populated yet
 func (t *TeamSettings) GetImportMode() ImportModeType {
 	if string(t.ImportMode) == "" {
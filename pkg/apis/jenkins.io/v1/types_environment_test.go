@@ -8,6 +8,8 @@ import (
 
 	"github.com/jenkins-x/jx/pkg/log"
 	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 )
 
 var (
@@ -50,3 +52,47 @@ func Test_GitPublic_and_GitPrivate_specified_throws_error(t *testing.T) {
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "only GitPublic should be used")
 }
+
+func TestGetBuildPodPolicy(t *testing.T) {
+	spot := true
+	noSpot := false
+	settings := TeamSettings{
+		BuildPodPolicies: []BuildPodPolicy{
+			{
+				PipelineKind: "release",
+				Resources: corev1.ResourceRequirements{
+					Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("4Gi")},
+				},
+				NodeSelector: map[string]string{"pool": "release"},
+				Spot:         &noSpot,
+			},
+			{
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("400m")},
+					Limits:   corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("1Gi")},
+				},
+				NodeSelector:      map[string]string{"pool": "builds", "os": "linux"},
+				Spot:              &spot,
+				PreemptionRetries: 3,
+			},
+		},
+	}
+
+	policy := settings.GetBuildPodPolicy("release")
+	assert.NotNil(t, policy)
+	assert.Equal(t, "release", policy.PipelineKind)
+	assert.Equal(t, "400m", policy.Resources.Requests.Cpu().String())
+	assert.Equal(t, "4Gi", policy.Resources.Limits.Memory().String())
+	assert.Equal(t, map[string]string{"pool": "release", "os": "linux"}, policy.NodeSelector)
+	assert.False(t, policy.IsSpot(), "the release pipelines should not use spot nodes")
+	assert.Equal(t, 3, policy.GetPreemptionRetries())
+
+	policy = settings.GetBuildPodPolicy("pullrequest")
+	assert.NotNil(t, policy)
+	assert.Equal(t, "1Gi", policy.Resources.Limits.Memory().String())
+	assert.Equal(t, "builds", policy.NodeSelector["pool"])
+	assert.True(t, policy.IsSpot())
+
+	assert.Equal(t, "1Gi", settings.BuildPodPolicies[1].Resources.Limits.Memory().String(), "the team settings should not be modified")
+	assert.Nil(t, (&TeamSettings{}).GetBuildPodPolicy("release"))
+}
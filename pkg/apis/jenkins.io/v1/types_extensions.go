@@ -428,6 +428,9 @@ type App struct {
 	metav1.ObjectMeta `json:"metadata,omitempty" protobuf:"bytes,1,opt,name=metadata"`
 
 	Spec AppSpec `json:"spec,omitempty" protobuf:"bytes,2,opt,name=spec"`
+
+	// Status the status of the App such as the results of its hooks
+	Status AppStatus `json:"status,omitempty" protobuf:"bytes,3,opt,name=status"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -533,6 +536,61 @@ type AppSpec struct {
 	SchemaPreprocessorRole *v1.Role          `json:"schemaPreprocessorRole,omitempty" protobuf:"bytes,2,opt,name=schemaPreprocessorRole"`
 
 	PipelineExtension *PipelineExtension `json:"pipelineExtension,omitempty" protobuf:"bytes,3,opt,name=pipelineExtension"`
+
+	// PreInstall the hooks to run before the App is installed or upgraded
+	PreInstall []AppHook `json:"preInstall,omitempty" protobuf:"bytes,4,rep,name=preInstall"`
+	// PostInstall the hooks to run after the App is installed or upgraded such as database migrations or smoke tests
+	PostInstall []AppHook `json:"postInstall,omitempty" protobuf:"bytes,5,rep,name=postInstall"`
+	// Compatibility the versions of Kubernetes and the Jenkins X platform this version of the App works with
+	Compatibility *AppCompatibility `json:"compatibility,omitempty" protobuf:"bytes,6,opt,name=compatibility"`
+}
+
+// AppCompatibility declares the versions an App is compatible with as semantic version constraints such as
+// '>= 1.13, < 1.16'. An empty constraint matches any version
+type AppCompatibility struct {
+	// KubernetesVersion the versions of Kubernetes the App can be installed on
+	KubernetesVersion string `json:"kubernetesVersion,omitempty" protobuf:"bytes,1,opt,name=kubernetesVersion"`
+	// PlatformVersion the versions of the jenkins-x-platform chart the App can be installed with
+	PlatformVersion string `json:"platformVersion,omitempty" protobuf:"bytes,2,opt,name=platformVersion"`
+}
+
+// AppHookType is the type of an App hook
+type AppHookType string
+
+const (
+	// AppHookTypePreInstall hooks run before an App is installed or upgraded
+	AppHookTypePreInstall AppHookType = "preInstall"
+	// AppHookTypePostInstall hooks run after an App is installed or upgraded
+	AppHookTypePostInstall AppHookType = "postInstall"
+)
+
+// AppHook is a pipeline of steps run as a pod when an App is installed or upgraded
+type AppHook struct {
+	// Name the name of the hook
+	Name string `json:"name" protobuf:"bytes,1,opt,name=name"`
+	// Steps the containers to run in order. The hook fails if any step fails
+	Steps []corev1.Container `json:"steps" protobuf:"bytes,2,rep,name=steps"`
+	// ServiceAccountName the service account to run the steps as
+	ServiceAccountName string `json:"serviceAccountName,omitempty" protobuf:"bytes,3,opt,name=serviceAccountName"`
+	// Timeout how long to wait for the hook to complete such as 10m. Defaults to the install timeout
+	Timeout string `json:"timeout,omitempty" protobuf:"bytes,4,opt,name=timeout"`
+}
+
+// AppStatus is the status of an App
+type AppStatus struct {
+	// Hooks the status of the hooks run for the current version of the App
+	Hooks []AppHookStatus `json:"hooks,omitempty" protobuf:"bytes,1,rep,name=hooks"`
+}
+
+// AppHookStatus is the result of running a hook of an App
+type AppHookStatus struct {
+	Name          string             `json:"name" protobuf:"bytes,1,opt,name=name"`
+	Type          AppHookType        `json:"type" protobuf:"bytes,2,opt,name=type"`
+	Version       string             `json:"version,omitempty" protobuf:"bytes,3,opt,name=version"`
+	Status        ActivityStatusType `json:"status,omitempty" protobuf:"bytes,4,opt,name=status"`
+	Message       string             `json:"message,omitempty" protobuf:"bytes,5,opt,name=message"`
+	StartedTime   *metav1.Time       `json:"startedTimestamp,omitempty" protobuf:"bytes,6,opt,name=startedTimestamp"`
+	CompletedTime *metav1.Time       `json:"completedTimestamp,omitempty" protobuf:"bytes,7,opt,name=completedTimestamp"`
 }
 
 // PipelineExtension defines the image and command of an app which wants to modify/extend the pipeline
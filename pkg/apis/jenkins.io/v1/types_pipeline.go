@@ -62,6 +62,17 @@ type PipelineActivitySpec struct {
 	BatchPipelineActivity BatchPipelineActivity  `json:"batchPipelineActivity,omitempty" protobuf:"bytes,25,opt,name=batchPipelineActivity"`
 	Context               string                 `json:"context,omitempty" protobuf:"bytes,26,opt,name=context"`
 	BaseSHA               string                 `json:"baseSHA,omitempty" protobuf:"bytes,27,opt,name=baseSHA"`
+	Message               string                 `json:"message,omitempty" protobuf:"bytes,28,opt,name=message"`
+	TestSummary           *TestSummary           `json:"testSummary,omitempty" protobuf:"bytes,29,opt,name=testSummary"`
+}
+
+// TestSummary contains the number of tests run by a pipeline and where the full test report is stored
+type TestSummary struct {
+	Tests     int    `json:"tests" protobuf:"varint,1,opt,name=tests"`
+	Passed    int    `json:"passed" protobuf:"varint,2,opt,name=passed"`
+	Failed    int    `json:"failed" protobuf:"varint,3,opt,name=failed"`
+	Skipped   int    `json:"skipped" protobuf:"varint,4,opt,name=skipped"`
+	ReportURL string `json:"reportURL,omitempty" protobuf:"bytes,5,opt,name=reportURL"`
 }
 
 // BatchPipelineActivity contains information about a batch build, used by both the batch build and its comprising PRs for linking them together
@@ -364,6 +364,21 @@ type Merger struct {
 	// combined status; otherwise it may apply the branch protection setting or let user
 	// define their own options in case branch protection is not used.
 	ContextPolicy *ContextPolicy `json:"policy,omitempty"`
+
+	// MergeQueue defines how approved pull requests are tested together in batches before they are merged
+	MergeQueue *MergeQueue `json:"mergeQueue,omitempty" protobuf:"bytes,3,opt,name=mergeQueue"`
+}
+
+// MergeQueue defines how the approved pull requests of a repository are tested together in batches before they are
+// merged
+type MergeQueue struct {
+	// BatchSizeLimit is the maximum number of pull requests tested together in a batch. 0 means no limit and 1
+	// disables batching so that pull requests are tested and merged one at a time.
+	BatchSizeLimit *int `json:"batchSizeLimit,omitempty" protobuf:"varint,1,opt,name=batchSizeLimit"`
+
+	// Comment enables the comments on the pull requests of a batch when its tests start and complete.
+	// Defaults to true.
+	Comment *bool `json:"comment,omitempty" protobuf:"varint,2,opt,name=comment"`
 }
 
 // RepoContextPolicy overrides the policy for repo, and any branch overrides.
@@ -35,6 +35,7 @@ func (in *App) DeepCopyInto(out *App) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 	return
 }
 
@@ -56,6 +57,77 @@ func (in *App) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AppCompatibility) DeepCopyInto(out *AppCompatibility) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AppCompatibility.
+func (in *AppCompatibility) DeepCopy() *AppCompatibility {
+	if in == nil {
+		return nil
+	}
+	out := new(AppCompatibility)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AppHook) DeepCopyInto(out *AppHook) {
+	*out = *in
+	if in.Steps != nil {
+		in, out := &in.Steps, &out.Steps
+		*out = make([]core_v1.Container, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AppHook.
+func (in *AppHook) DeepCopy() *AppHook {
+	if in == nil {
+		return nil
+	}
+	out := new(AppHook)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AppHookStatus) DeepCopyInto(out *AppHookStatus) {
+	*out = *in
+	if in.StartedTime != nil {
+		in, out := &in.StartedTime, &out.StartedTime
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = (*in).DeepCopy()
+		}
+	}
+	if in.CompletedTime != nil {
+		in, out := &in.CompletedTime, &out.CompletedTime
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = (*in).DeepCopy()
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AppHookStatus.
+func (in *AppHookStatus) DeepCopy() *AppHookStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(AppHookStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AppList) DeepCopyInto(out *AppList) {
 	*out = *in
@@ -119,6 +191,29 @@ func (in *AppSpec) DeepCopyInto(out *AppSpec) {
 			(*in).DeepCopyInto(*out)
 		}
 	}
+	if in.PreInstall != nil {
+		in, out := &in.PreInstall, &out.PreInstall
+		*out = make([]AppHook, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.PostInstall != nil {
+		in, out := &in.PostInstall, &out.PostInstall
+		*out = make([]AppHook, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Compatibility != nil {
+		in, out := &in.Compatibility, &out.Compatibility
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(AppCompatibility)
+			**out = **in
+		}
+	}
 	return
 }
 
@@ -132,6 +227,29 @@ func (in *AppSpec) DeepCopy() *AppSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AppStatus) DeepCopyInto(out *AppStatus) {
+	*out = *in
+	if in.Hooks != nil {
+		in, out := &in.Hooks, &out.Hooks
+		*out = make([]AppHookStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AppStatus.
+func (in *AppStatus) DeepCopy() *AppStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(AppStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Approve) DeepCopyInto(out *Approve) {
 	*out = *in
@@ -205,6 +323,111 @@ func (in *Attachment) DeepCopy() *Attachment {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AuditEvent) DeepCopyInto(out *AuditEvent) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AuditEvent.
+func (in *AuditEvent) DeepCopy() *AuditEvent {
+	if in == nil {
+		return nil
+	}
+	out := new(AuditEvent)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *AuditEvent) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AuditEventList) DeepCopyInto(out *AuditEventList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	out.ListMeta = in.ListMeta
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]AuditEvent, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AuditEventList.
+func (in *AuditEventList) DeepCopy() *AuditEventList {
+	if in == nil {
+		return nil
+	}
+	out := new(AuditEventList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *AuditEventList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AuditEventSpec) DeepCopyInto(out *AuditEventSpec) {
+	*out = *in
+	if in.Details != nil {
+		in, out := &in.Details, &out.Details
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	in.Timestamp.DeepCopyInto(&out.Timestamp)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AuditEventSpec.
+func (in *AuditEventSpec) DeepCopy() *AuditEventSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AuditEventSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AuditSettings) DeepCopyInto(out *AuditSettings) {
+	*out = *in
+	if in.Sinks != nil {
+		in, out := &in.Sinks, &out.Sinks
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AuditSettings.
+func (in *AuditSettings) DeepCopy() *AuditSettings {
+	if in == nil {
+		return nil
+	}
+	out := new(AuditSettings)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BatchPipelineActivity) DeepCopyInto(out *BatchPipelineActivity) {
 	*out = *in
@@ -393,6 +616,47 @@ func (in *BuildPackSpec) DeepCopy() *BuildPackSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BuildPodPolicy) DeepCopyInto(out *BuildPodPolicy) {
+	*out = *in
+	in.Resources.DeepCopyInto(&out.Resources)
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]core_v1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.SecurityContext != nil {
+		in, out := &in.SecurityContext, &out.SecurityContext
+		*out = new(core_v1.SecurityContext)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Spot != nil {
+		in, out := &in.Spot, &out.Spot
+		*out = new(bool)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BuildPodPolicy.
+func (in *BuildPodPolicy) DeepCopy() *BuildPodPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(BuildPodPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ChartRef) DeepCopyInto(out *ChartRef) {
 	*out = *in
@@ -409,6 +673,50 @@ func (in *ChartRef) DeepCopy() *ChartRef {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CloudEventSink) DeepCopyInto(out *CloudEventSink) {
+	*out = *in
+	if in.Types != nil {
+		in, out := &in.Types, &out.Types
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudEventSink.
+func (in *CloudEventSink) DeepCopy() *CloudEventSink {
+	if in == nil {
+		return nil
+	}
+	out := new(CloudEventSink)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CloudEventsSettings) DeepCopyInto(out *CloudEventsSettings) {
+	*out = *in
+	if in.Sinks != nil {
+		in, out := &in.Sinks, &out.Sinks
+		*out = make([]CloudEventSink, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudEventsSettings.
+func (in *CloudEventsSettings) DeepCopy() *CloudEventsSettings {
+	if in == nil {
+		return nil
+	}
+	out := new(CloudEventsSettings)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CommitStatus) DeepCopyInto(out *CommitStatus) {
 	*out = *in
@@ -801,7 +1109,7 @@ func (in *Environment) DeepCopyInto(out *Environment) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 	return
 }
 
@@ -1010,6 +1318,16 @@ func (in *EnvironmentSpec) DeepCopyInto(out *EnvironmentSpec) {
 	out.Source = in.Source
 	in.TeamSettings.DeepCopyInto(&out.TeamSettings)
 	out.PreviewGitSpec = in.PreviewGitSpec
+	if in.PromotionWindows != nil {
+		in, out := &in.PromotionWindows, &out.PromotionWindows
+		*out = new(PromotionWindowSettings)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ImagePromotion != nil {
+		in, out := &in.ImagePromotion, &out.ImagePromotion
+		*out = new(ImagePromotionSettings)
+		**out = **in
+	}
 	return
 }
 
@@ -1026,6 +1344,22 @@ func (in *EnvironmentSpec) DeepCopy() *EnvironmentSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EnvironmentStatus) DeepCopyInto(out *EnvironmentStatus) {
 	*out = *in
+	if in.LastAttemptedTime != nil {
+		in, out := &in.LastAttemptedTime, &out.LastAttemptedTime
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = (*in).DeepCopy()
+		}
+	}
+	if in.LastAppliedTime != nil {
+		in, out := &in.LastAppliedTime, &out.LastAppliedTime
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = (*in).DeepCopy()
+		}
+	}
 	return
 }
 
@@ -1694,6 +2028,48 @@ func (in *GlobalProtectionPolicy) DeepCopy() *GlobalProtectionPolicy {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImagePromotionSettings) DeepCopyInto(out *ImagePromotionSettings) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImagePromotionSettings.
+func (in *ImagePromotionSettings) DeepCopy() *ImagePromotionSettings {
+	if in == nil {
+		return nil
+	}
+	out := new(ImagePromotionSettings)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImageScanPolicy) DeepCopyInto(out *ImageScanPolicy) {
+	*out = *in
+	if in.IgnoredVulnerabilities != nil {
+		in, out := &in.IgnoredVulnerabilities, &out.IgnoredVulnerabilities
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ProtectedEnvironments != nil {
+		in, out := &in.ProtectedEnvironments, &out.ProtectedEnvironments
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageScanPolicy.
+func (in *ImageScanPolicy) DeepCopy() *ImageScanPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(ImageScanPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *IssueLabel) DeepCopyInto(out *IssueLabel) {
 	*out = *in
@@ -1907,6 +2283,40 @@ func (in *Measurement) DeepCopy() *Measurement {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MergeQueue) DeepCopyInto(out *MergeQueue) {
+	*out = *in
+	if in.BatchSizeLimit != nil {
+		in, out := &in.BatchSizeLimit, &out.BatchSizeLimit
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(int)
+			**out = **in
+		}
+	}
+	if in.Comment != nil {
+		in, out := &in.Comment, &out.Comment
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(bool)
+			**out = **in
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MergeQueue.
+func (in *MergeQueue) DeepCopy() *MergeQueue {
+	if in == nil {
+		return nil
+	}
+	out := new(MergeQueue)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Merger) DeepCopyInto(out *Merger) {
 	*out = *in
@@ -1991,6 +2401,15 @@ func (in *Merger) DeepCopyInto(out *Merger) {
 			(*in).DeepCopyInto(*out)
 		}
 	}
+	if in.MergeQueue != nil {
+		in, out := &in.MergeQueue, &out.MergeQueue
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(MergeQueue)
+			(*in).DeepCopyInto(*out)
+		}
+	}
 	return
 }
 
@@ -2207,6 +2626,15 @@ func (in *PipelineActivitySpec) DeepCopyInto(out *PipelineActivitySpec) {
 		}
 	}
 	in.BatchPipelineActivity.DeepCopyInto(&out.BatchPipelineActivity)
+	if in.TestSummary != nil {
+		in, out := &in.TestSummary, &out.TestSummary
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(TestSummary)
+			**out = **in
+		}
+	}
 	return
 }
 
@@ -2279,6 +2707,29 @@ func (in *PipelineActivityStep) DeepCopy() *PipelineActivityStep {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PipelineConcurrency) DeepCopyInto(out *PipelineConcurrency) {
+	*out = *in
+	if in.Priorities != nil {
+		in, out := &in.Priorities, &out.Priorities
+		*out = make(map[string]int, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PipelineConcurrency.
+func (in *PipelineConcurrency) DeepCopy() *PipelineConcurrency {
+	if in == nil {
+		return nil
+	}
+	out := new(PipelineConcurrency)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PipelineExtension) DeepCopyInto(out *PipelineExtension) {
 	*out = *in
@@ -2944,6 +3395,48 @@ func (in *PromoteWorkflowStep) DeepCopy() *PromoteWorkflowStep {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PromotionWindow) DeepCopyInto(out *PromotionWindow) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PromotionWindow.
+func (in *PromotionWindow) DeepCopy() *PromotionWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(PromotionWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PromotionWindowSettings) DeepCopyInto(out *PromotionWindowSettings) {
+	*out = *in
+	if in.Allowed != nil {
+		in, out := &in.Allowed, &out.Allowed
+		*out = make([]PromotionWindow, len(*in))
+		copy(*out, *in)
+	}
+	if in.Freezes != nil {
+		in, out := &in.Freezes, &out.Freezes
+		*out = make([]PromotionWindow, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PromotionWindowSettings.
+func (in *PromotionWindowSettings) DeepCopy() *PromotionWindowSettings {
+	if in == nil {
+		return nil
+	}
+	out := new(PromotionWindowSettings)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ProtectionPolicies) DeepCopyInto(out *ProtectionPolicies) {
 	*out = *in
@@ -3042,6 +3535,32 @@ func (in *ProtectionPolicy) DeepCopy() *ProtectionPolicy {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProvenancePolicy) DeepCopyInto(out *ProvenancePolicy) {
+	*out = *in
+	if in.ProtectedEnvironments != nil {
+		in, out := &in.ProtectedEnvironments, &out.ProtectedEnvironments
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.TrustedBuilders != nil {
+		in, out := &in.TrustedBuilders, &out.TrustedBuilders
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProvenancePolicy.
+func (in *ProvenancePolicy) DeepCopy() *ProvenancePolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(ProvenancePolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PullRequestInfo) DeepCopyInto(out *PullRequestInfo) {
 	*out = *in
@@ -3991,6 +4510,27 @@ func (in *StorageLocation) DeepCopy() *StorageLocation {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TagPolicy) DeepCopyInto(out *TagPolicy) {
+	*out = *in
+	if in.ProtectedEnvironments != nil {
+		in, out := &in.ProtectedEnvironments, &out.ProtectedEnvironments
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TagPolicy.
+func (in *TagPolicy) DeepCopy() *TagPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(TagPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Team) DeepCopyInto(out *Team) {
 	*out = *in
@@ -4080,6 +4620,43 @@ func (in *TeamSettings) DeepCopyInto(out *TeamSettings) {
 		copy(*out, *in)
 	}
 	out.DefaultScheduler = in.DefaultScheduler
+	if in.BuildPodPolicies != nil {
+		in, out := &in.BuildPodPolicies, &out.BuildPodPolicies
+		*out = make([]BuildPodPolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.PipelineConcurrency != nil {
+		in, out := &in.PipelineConcurrency, &out.PipelineConcurrency
+		*out = new(PipelineConcurrency)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ImageScanPolicy != nil {
+		in, out := &in.ImageScanPolicy, &out.ImageScanPolicy
+		*out = new(ImageScanPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Audit != nil {
+		in, out := &in.Audit, &out.Audit
+		*out = new(AuditSettings)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ProvenancePolicy != nil {
+		in, out := &in.ProvenancePolicy, &out.ProvenancePolicy
+		*out = new(ProvenancePolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TagPolicy != nil {
+		in, out := &in.TagPolicy, &out.TagPolicy
+		*out = new(TagPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CloudEvents != nil {
+		in, out := &in.CloudEvents, &out.CloudEvents
+		*out = new(CloudEventsSettings)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -4130,6 +4707,22 @@ func (in *TeamStatus) DeepCopy() *TeamStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TestSummary) DeepCopyInto(out *TestSummary) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TestSummary.
+func (in *TestSummary) DeepCopy() *TestSummary {
+	if in == nil {
+		return nil
+	}
+	out := new(TestSummary)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Trigger) DeepCopyInto(out *Trigger) {
 	*out = *in
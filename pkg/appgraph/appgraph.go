@@ -0,0 +1,171 @@
+package appgraph
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jenkins-x/jx/pkg/util"
+)
+
+// Graph the dependencies between applications
+type Graph struct {
+	dependencies map[string][]string
+}
+
+// NewGraph creates an empty graph
+func NewGraph() *Graph {
+	return &Graph{
+		dependencies: map[string][]string{},
+	}
+}
+
+// Add adds the application and the applications it depends on to the graph
+func (g *Graph) Add(app string, dependencies ...string) {
+	existing := g.dependencies[app]
+	for _, dep := range dependencies {
+		if dep == "" || dep == app || util.StringArrayIndex(existing, dep) >= 0 {
+			continue
+		}
+		existing = append(existing, dep)
+		if _, ok := g.dependencies[dep]; !ok {
+			g.dependencies[dep] = nil
+		}
+	}
+	sort.Strings(existing)
+	g.dependencies[app] = existing
+}
+
+// Apps returns the sorted names of the applications in the graph
+func (g *Graph) Apps() []string {
+	answer := make([]string, 0, len(g.dependencies))
+	for app := range g.dependencies {
+		answer = append(answer, app)
+	}
+	sort.Strings(answer)
+	return answer
+}
+
+// Dependencies returns the applications the application directly depends on
+func (g *Graph) Dependencies(app string) []string {
+	return g.dependencies[app]
+}
+
+// TransitiveDependencies returns the sorted names of all the applications the application depends on directly or
+// indirectly
+func (g *Graph) TransitiveDependencies(app string) []string {
+	visited := map[string]bool{}
+	var visit func(string)
+	visit = func(name string) {
+		for _, dep := range g.dependencies[name] {
+			if !visited[dep] {
+				visited[dep] = true
+				visit(dep)
+			}
+		}
+	}
+	visit(app)
+	delete(visited, app)
+
+	answer := make([]string, 0, len(visited))
+	for name := range visited {
+		answer = append(answer, name)
+	}
+	sort.Strings(answer)
+	return answer
+}
+
+// Waves returns the given applications ordered into waves such that every application only depends on applications
+// in earlier waves. Dependencies on applications which are not in the given list are ignored. Returns an error if
+// the applications have a dependency cycle
+func (g *Graph) Waves(apps []string) ([][]string, error) {
+	remaining := map[string]bool{}
+	for _, app := range apps {
+		remaining[app] = true
+	}
+	waves := [][]string{}
+	for len(remaining) > 0 {
+		wave := []string{}
+		for app := range remaining {
+			ready := true
+			for _, dep := range g.dependencies[app] {
+				if remaining[dep] {
+					ready = false
+					break
+				}
+			}
+			if ready {
+				wave = append(wave, app)
+			}
+		}
+		if len(wave) == 0 {
+			cycle := make([]string, 0, len(remaining))
+			for app := range remaining {
+				cycle = append(cycle, app)
+			}
+			sort.Strings(cycle)
+			return nil, fmt.Errorf("dependency cycle between applications %s", strings.Join(cycle, ", "))
+		}
+		sort.Strings(wave)
+		for _, app := range wave {
+			delete(remaining, app)
+		}
+		waves = append(waves, wave)
+	}
+	return waves, nil
+}
+
+// DOT returns the graph in the Graphviz DOT format with edges pointing from applications to their dependencies
+func (g *Graph) DOT() string {
+	var builder strings.Builder
+	builder.WriteString("digraph apps {\n")
+	builder.WriteString("  rankdir=LR;\n")
+	for _, app := range g.Apps() {
+		builder.WriteString(fmt.Sprintf("  %q;\n", app))
+	}
+	for _, app := range g.Apps() {
+		for _, dep := range g.dependencies[app] {
+			builder.WriteString(fmt.Sprintf("  %q -> %q;\n", app, dep))
+		}
+	}
+	builder.WriteString("}\n")
+	return builder.String()
+}
+
+// Mermaid returns the graph as a mermaid flowchart with edges pointing from applications to their dependencies
+func (g *Graph) Mermaid() string {
+	ids := map[string]string{}
+	for i, app := range g.Apps() {
+		ids[app] = fmt.Sprintf("app%d", i)
+	}
+	var builder strings.Builder
+	builder.WriteString("graph LR\n")
+	for _, app := range g.Apps() {
+		builder.WriteString(fmt.Sprintf("  %s[\"%s\"]\n", ids[app], strings.Replace(app, `"`, "#quot;", -1)))
+	}
+	for _, app := range g.Apps() {
+		for _, dep := range g.dependencies[app] {
+			builder.WriteString(fmt.Sprintf("  %s --> %s\n", ids[app], ids[dep]))
+		}
+	}
+	return builder.String()
+}
+
+// Subgraph returns the graph of the application and all of the applications it depends on
+func (g *Graph) Subgraph(app string) *Graph {
+	answer := NewGraph()
+	answer.Add(app, g.dependencies[app]...)
+	for _, dep := range g.TransitiveDependencies(app) {
+		answer.Add(dep, g.dependencies[dep]...)
+	}
+	return answer
+}
+
+// Map returns the direct dependencies of each application indexed by application name
+func (g *Graph) Map() map[string][]string {
+	answer := map[string][]string{}
+	for app, deps := range g.dependencies {
+		answer[app] = append([]string{}, deps...)
+	}
+	return answer
+}
@@ -0,0 +1,83 @@
+package appgraph_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x/jx/pkg/appgraph"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testGraph() *appgraph.Graph {
+	graph := appgraph.NewGraph()
+	graph.Add("frontend", "orders", "auth")
+	graph.Add("orders", "db", "auth")
+	graph.Add("auth", "db")
+	graph.Add("reports")
+	return graph
+}
+
+func TestWaves(t *testing.T) {
+	t.Parallel()
+
+	graph := testGraph()
+	assert.Equal(t, []string{"auth", "db", "frontend", "orders", "reports"}, graph.Apps())
+
+	deps := graph.TransitiveDependencies("frontend")
+	assert.Equal(t, []string{"auth", "db", "orders"}, deps)
+
+	waves, err := graph.Waves(deps)
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"db"}, {"auth"}, {"orders"}}, waves)
+
+	waves, err = graph.Waves([]string{"orders", "reports", "db"})
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"db", "reports"}, {"orders"}}, waves, "dependencies outside of the list should be ignored")
+
+	graph.Add("db", "frontend")
+	_, err = graph.Waves(graph.Apps())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "dependency cycle")
+}
+
+func TestRenderGraph(t *testing.T) {
+	t.Parallel()
+
+	graph := testGraph().Subgraph("orders")
+	assert.Equal(t, []string{"auth", "db", "orders"}, graph.Apps())
+
+	assert.Equal(t, `digraph apps {
+  rankdir=LR;
+  "auth";
+  "db";
+  "orders";
+  "auth" -> "db";
+  "orders" -> "auth";
+  "orders" -> "db";
+}
+`, graph.DOT())
+
+	assert.Equal(t, `graph LR
+  app0["auth"]
+  app1["db"]
+  app2["orders"]
+  app0 --> app1
+  app2 --> app0
+  app2 --> app1
+`, graph.Mermaid())
+}
+
+func TestLoadDependencies(t *testing.T) {
+	t.Parallel()
+
+	deps, err := appgraph.LoadDependencies(filepath.Join("test_data", "myapp"))
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"db-service", "auth-service", "db-service"}, deps)
+
+	graph := appgraph.NewGraph()
+	graph.Add("myapp", deps...)
+	assert.Equal(t, []string{"auth-service", "db-service"}, graph.Dependencies("myapp"))
+
+	assert.Equal(t, []string{"a", "b"}, appgraph.ParseDependenciesAnnotation(" a, ,b "))
+}
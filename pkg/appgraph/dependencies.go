@@ -0,0 +1,142 @@
+package appgraph
+
+import (
+	"encoding/base64"
+	"io/ioutil"
+	"path"
+	"path/filepath"
+	"strings"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/config"
+	"github.com/jenkins-x/jx/pkg/gits"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// DependenciesAnnotation the annotation of the Chart.yaml of an application containing the comma separated names of
+	// the applications it depends on
+	DependenciesAnnotation = "jenkins.io/dependencies"
+)
+
+// chartMetadata the subset of the Chart.yaml used to find the dependencies
+type chartMetadata struct {
+	Name        string            `json:"name"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// ParseDependenciesAnnotation returns the application names of the value of a dependencies annotation
+func ParseDependenciesAnnotation(value string) []string {
+	answer := []string{}
+	for _, name := range strings.Split(value, ",") {
+		name = strings.TrimSpace(name)
+		if name != "" {
+			answer = append(answer, name)
+		}
+	}
+	return answer
+}
+
+// LoadDependencies returns the applications the project in the given directory depends on from its
+// `.jx/dependencies.yaml` file and the dependencies annotation of its charts
+func LoadDependencies(dir string) ([]string, error) {
+	answer := []string{}
+	cfg, err := config.LoadDependenciesConfig(dir)
+	if err != nil {
+		return nil, err
+	}
+	if cfg != nil {
+		answer = append(answer, cfg.Names()...)
+	}
+	chartFiles, err := filepath.Glob(filepath.Join(dir, "charts", "*", "Chart.yaml"))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to find the charts in %s", dir)
+	}
+	for _, chartFile := range chartFiles {
+		data, err := ioutil.ReadFile(chartFile)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to load file %s", chartFile)
+		}
+		deps, err := chartDependencies(data)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse %s", chartFile)
+		}
+		answer = append(answer, deps...)
+	}
+	return answer, nil
+}
+
+// FetchDependencies returns the applications the repository depends on by reading its `.jx/dependencies.yaml` file
+// and the dependencies annotation of its chart from the git provider
+func FetchDependencies(provider gits.GitProvider, owner string, repo string, ref string) ([]string, error) {
+	answer := []string{}
+	fileName := path.Join(".jx", config.DependenciesConfigFileName)
+	data, err := getContent(provider, owner, repo, fileName, ref)
+	if err != nil {
+		return nil, err
+	}
+	if data != nil {
+		cfg, err := config.ParseDependenciesConfig(data)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid dependencies in %s of %s/%s", fileName, owner, repo)
+		}
+		answer = append(answer, cfg.Names()...)
+	}
+	chartFile := path.Join("charts", repo, "Chart.yaml")
+	data, err = getContent(provider, owner, repo, chartFile, ref)
+	if err != nil {
+		return nil, err
+	}
+	if data != nil {
+		deps, err := chartDependencies(data)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse %s of %s/%s", chartFile, owner, repo)
+		}
+		answer = append(answer, deps...)
+	}
+	return answer, nil
+}
+
+// BuildGraph creates the graph of the dependencies of the given repositories. Repositories whose dependencies cannot
+// be fetched are added without dependencies
+func BuildGraph(repositories []v1.SourceRepository, fetch func(*v1.SourceRepository) ([]string, error)) *Graph {
+	graph := NewGraph()
+	for i := range repositories {
+		sr := &repositories[i]
+		deps, err := fetch(sr)
+		if err != nil {
+			log.Logger().Warnf("Failed to find the dependencies of %s/%s: %s", sr.Spec.Org, sr.Spec.Repo, err)
+		}
+		graph.Add(sr.Spec.Repo, deps...)
+	}
+	return graph
+}
+
+func chartDependencies(data []byte) ([]string, error) {
+	metadata := &chartMetadata{}
+	err := yaml.Unmarshal(data, metadata)
+	if err != nil {
+		return nil, err
+	}
+	return ParseDependenciesAnnotation(metadata.Annotations[DependenciesAnnotation]), nil
+}
+
+// getContent returns the decoded content of the file or nil if it does not exist. Git providers do not return a
+// common error for missing files so any error is treated as a missing file
+func getContent(provider gits.GitProvider, owner string, repo string, fileName string, ref string) ([]byte, error) {
+	content, err := provider.GetContent(owner, repo, fileName, ref)
+	if err != nil {
+		log.Logger().Debugf("no %s in %s/%s: %s", fileName, owner, repo, err)
+		return nil, nil
+	}
+	if content == nil {
+		return nil, nil
+	}
+	data, err := base64.StdEncoding.DecodeString(content.Content)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to decode %s of %s/%s", fileName, owner, repo)
+	}
+	return data, nil
+}
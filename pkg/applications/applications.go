@@ -126,19 +126,21 @@ func GetApplications(factory clients.Factory) (List, error) {
 	}
 
 	kubeClient, _, err := factory.CreateKubeClient()
+	if err != nil {
+		return list, errors.Wrap(err, "failed to create a kube client from applications.GetApplications")
+	}
 
 	// fetch deployments by environment (excluding dev)
-	deployments := make(map[string]map[string]v1beta1.Deployment)
+	namespaces := []string{}
 	for _, env := range permanentEnvsMap {
 		if env.Spec.Kind != v1.EnvironmentKindTypeDevelopment {
-			envDeployments, err := kube.GetDeployments(kubeClient, env.Spec.Namespace)
-			if err != nil {
-				return list, err
-			}
-
-			deployments[env.Spec.Namespace] = envDeployments
+			namespaces = append(namespaces, env.Spec.Namespace)
 		}
 	}
+	deployments, err := getDeploymentsFromInformers(kubeClient, namespaces)
+	if err != nil {
+		return list, errors.Wrap(err, "failed to fetch the deployments of the environments")
+	}
 
 	err = list.appendMatchingDeployments(permanentEnvsMap, deployments)
 	if err != nil {
@@ -0,0 +1,64 @@
+package applications
+
+import (
+	"fmt"
+	"time"
+
+	"k8s.io/api/apps/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+// informerSyncTimeout the maximum time to wait for the informer caches of the environments to sync
+const informerSyncTimeout = 30 * time.Second
+
+// getDeploymentsFromInformers returns the deployments of the given namespaces indexed by namespace then deployment
+// name. The informer caches of all the namespaces are populated concurrently which is much faster than listing the
+// deployments of one environment after another on clusters with many environments
+func getDeploymentsFromInformers(kubeClient kubernetes.Interface, namespaces []string) (map[string]map[string]v1beta1.Deployment, error) {
+	stop := make(chan struct{})
+	defer close(stop)
+
+	stores := map[string]cache.Store{}
+	synced := []cache.InformerSynced{}
+	for _, ns := range namespaces {
+		deployments := kubeClient.AppsV1beta1().Deployments(ns)
+		listWatch := &cache.ListWatch{
+			ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+				return deployments.List(options)
+			},
+			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+				return deployments.Watch(options)
+			},
+		}
+		store, controller := cache.NewInformer(listWatch, &v1beta1.Deployment{}, 0, cache.ResourceEventHandlerFuncs{})
+		go controller.Run(stop)
+		stores[ns] = store
+		synced = append(synced, controller.HasSynced)
+	}
+
+	timeout := make(chan struct{})
+	timer := time.AfterFunc(informerSyncTimeout, func() {
+		close(timeout)
+	})
+	defer timer.Stop()
+	if !cache.WaitForCacheSync(timeout, synced...) {
+		return nil, fmt.Errorf("timed out after %s waiting for the deployments of namespaces %v", informerSyncTimeout.String(), namespaces)
+	}
+
+	answer := map[string]map[string]v1beta1.Deployment{}
+	for ns, store := range stores {
+		deployments := map[string]v1beta1.Deployment{}
+		for _, obj := range store.List() {
+			d, ok := obj.(*v1beta1.Deployment)
+			if ok && d != nil {
+				deployments[d.Name] = *d
+			}
+		}
+		answer[ns] = deployments
+	}
+	return answer, nil
+}
@@ -0,0 +1,217 @@
+package applications
+
+import (
+	"encoding/base64"
+	"path"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/flagger"
+	"github.com/jenkins-x/jx/pkg/gits"
+	"github.com/jenkins-x/jx/pkg/helm"
+	"github.com/jenkins-x/jx/pkg/kube"
+	"github.com/pkg/errors"
+	"k8s.io/api/apps/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// HealthHealthy all the replicas of the deployment are ready
+	HealthHealthy = "Healthy"
+	// HealthProgressing some of the replicas of the deployment are not ready yet
+	HealthProgressing = "Progressing"
+	// HealthUnavailable none of the replicas of the deployment are ready
+	HealthUnavailable = "Unavailable"
+	// HealthScaledDown the deployment has no replicas
+	HealthScaledDown = "ScaledDown"
+)
+
+// Matrix the versions of the applications in each environment
+type Matrix struct {
+	Environments []MatrixEnvironment `json:"environments"`
+	Applications []MatrixApplication `json:"applications"`
+}
+
+// MatrixEnvironment an environment of the matrix
+type MatrixEnvironment struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Kind      string `json:"kind,omitempty"`
+}
+
+// MatrixApplication the deployments of an application indexed by environment name
+type MatrixApplication struct {
+	Name         string                 `json:"name"`
+	Environments map[string]*MatrixCell `json:"environments"`
+}
+
+// MatrixCell the deployment of an application in an environment
+type MatrixCell struct {
+	// Version the version running in the environment
+	Version string `json:"version,omitempty"`
+	// DesiredVersion the version in the git repository of the environment
+	DesiredVersion string `json:"desiredVersion,omitempty"`
+	// Drift is true if the running version is not the version in the git repository of the environment
+	Drift        bool         `json:"drift"`
+	ReadyPods    int32        `json:"readyPods"`
+	Replicas     int32        `json:"replicas"`
+	Health       string       `json:"health"`
+	LastDeployed *metav1.Time `json:"lastDeployed,omitempty"`
+	URL          string       `json:"url,omitempty"`
+}
+
+// Health returns the health of the deployment based on the readiness of its pods
+func (d Deployment) Health() string {
+	replicas := int32(1)
+	if d.Deployment.Spec.Replicas != nil {
+		replicas = *d.Deployment.Spec.Replicas
+	}
+	ready := d.Deployment.Status.ReadyReplicas
+	switch {
+	case replicas == 0:
+		return HealthScaledDown
+	case ready >= replicas:
+		return HealthHealthy
+	case ready > 0:
+		return HealthProgressing
+	default:
+		return HealthUnavailable
+	}
+}
+
+// LastDeployed returns the time the deployment last rolled out a new version
+func (d Deployment) LastDeployed() *metav1.Time {
+	var answer *metav1.Time
+	for i := range d.Deployment.Status.Conditions {
+		c := &d.Deployment.Status.Conditions[i]
+		if c.Type == v1beta1.DeploymentProgressing && !c.LastUpdateTime.IsZero() {
+			if answer == nil || answer.Before(&c.LastUpdateTime) {
+				answer = &c.LastUpdateTime
+			}
+		}
+	}
+	if answer == nil && !d.Deployment.CreationTimestamp.IsZero() {
+		answer = &d.Deployment.CreationTimestamp
+	}
+	return answer
+}
+
+// NewMatrix creates the matrix of the given applications in the given environments. The desired versions are indexed
+// by environment name then application name. The URL function is only invoked for deployed applications and may be nil
+func NewMatrix(list List, envs []v1.Environment, desiredVersions map[string]map[string]string, url func(Deployment, Application) string) *Matrix {
+	matrix := &Matrix{
+		Environments: []MatrixEnvironment{},
+		Applications: []MatrixApplication{},
+	}
+	for _, env := range envs {
+		matrix.Environments = append(matrix.Environments, MatrixEnvironment{
+			Name:      env.Name,
+			Namespace: env.Spec.Namespace,
+			Kind:      string(env.Spec.Kind),
+		})
+	}
+	for _, a := range list.Items {
+		if len(a.Environments) == 0 {
+			continue
+		}
+		row := MatrixApplication{
+			Name:         a.Name(),
+			Environments: map[string]*MatrixCell{},
+		}
+		for _, env := range envs {
+			desired := desiredVersions[env.Name][a.Name()]
+			ae, ok := a.Environments[env.Name]
+			if !ok || len(ae.Deployments) == 0 {
+				if desired != "" {
+					row.Environments[env.Name] = &MatrixCell{
+						DesiredVersion: desired,
+						Drift:          true,
+						Health:         HealthUnavailable,
+					}
+				}
+				continue
+			}
+			d := ae.Deployments[0]
+			cell := &MatrixCell{
+				Version:        d.Version(),
+				DesiredVersion: desired,
+				ReadyPods:      d.Deployment.Status.ReadyReplicas,
+				Health:         d.Health(),
+				LastDeployed:   d.LastDeployed(),
+			}
+			if d.Deployment.Spec.Replicas != nil {
+				cell.Replicas = *d.Deployment.Spec.Replicas
+			}
+			cell.Drift = desired != "" && desired != cell.Version
+			if url != nil {
+				cell.URL = url(d, a)
+			}
+			row.Environments[env.Name] = cell
+		}
+		matrix.Applications = append(matrix.Applications, row)
+	}
+	return matrix
+}
+
+// CountDrift returns the number of applications of the desired versions whose deployment in the environment is
+// missing or runs another version
+func CountDrift(env *v1.Environment, deployments []v1beta1.Deployment, desiredVersions map[string]string) int {
+	running := map[string]string{}
+	for _, d := range deployments {
+		if flagger.IsCanaryAuxiliaryDeployment(d) {
+			continue
+		}
+		name, err := getDeploymentAppNameInEnvironment(d, env)
+		if err != nil || name == "" {
+			continue
+		}
+		running[name] = kube.GetVersion(&d.ObjectMeta)
+	}
+	answer := 0
+	for name, version := range desiredVersions {
+		if running[name] != version {
+			answer++
+		}
+	}
+	return answer
+}
+
+// GetEnvironmentVersions returns the versions of the applications in the helm requirements of the git repository of
+// the environment indexed by application name
+func GetEnvironmentVersions(provider gits.GitProvider, env *v1.Environment) (map[string]string, error) {
+	answer := map[string]string{}
+	source := env.Spec.Source
+	if source.URL == "" {
+		return answer, nil
+	}
+	gitInfo, err := gits.ParseGitURL(source.URL)
+	if err != nil {
+		return answer, errors.Wrapf(err, "failed to parse the git URL %s of environment %s", source.URL, env.Name)
+	}
+	fileName := path.Join(helm.DefaultEnvironmentChartDir, helm.RequirementsFileName)
+	content, err := provider.GetContent(gitInfo.Organisation, gitInfo.Name, fileName, source.Ref)
+	if err != nil {
+		return answer, errors.Wrapf(err, "failed to get %s from %s", fileName, source.URL)
+	}
+	if content == nil {
+		return answer, nil
+	}
+	data, err := base64.StdEncoding.DecodeString(content.Content)
+	if err != nil {
+		return answer, errors.Wrapf(err, "failed to decode %s from %s", fileName, source.URL)
+	}
+	requirements, err := helm.LoadRequirements(data)
+	if err != nil {
+		return answer, errors.Wrapf(err, "failed to parse %s from %s", fileName, source.URL)
+	}
+	for _, dep := range requirements.Dependencies {
+		if dep == nil {
+			continue
+		}
+		name := dep.Alias
+		if name == "" {
+			name = dep.Name
+		}
+		answer[name] = dep.Version
+	}
+	return answer, nil
+}
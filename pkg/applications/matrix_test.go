@@ -0,0 +1,115 @@
+package applications
+
+import (
+	"testing"
+	"time"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/api/apps/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func testDeployment(ns string, version string, replicas int32, ready int32) *v1beta1.Deployment {
+	return &v1beta1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "jx-myapp",
+			Namespace: ns,
+			Labels: map[string]string{
+				"version": version,
+			},
+		},
+		Spec: v1beta1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					"app": "myapp",
+				},
+			},
+		},
+		Status: v1beta1.DeploymentStatus{
+			ReadyReplicas: ready,
+		},
+	}
+}
+
+func TestNewMatrix(t *testing.T) {
+	t.Parallel()
+
+	deployed := metav1.NewTime(time.Now().Add(-time.Hour))
+	staging := testDeployment("jx-staging", "1.0.2", 2, 2)
+	staging.Status.Conditions = []v1beta1.DeploymentCondition{
+		{Type: v1beta1.DeploymentProgressing, LastUpdateTime: deployed},
+	}
+	production := testDeployment("jx-production", "1.0.1", 2, 1)
+	envs := []v1.Environment{
+		{ObjectMeta: metav1.ObjectMeta{Name: "staging"}, Spec: v1.EnvironmentSpec{Namespace: "jx-staging"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "production"}, Spec: v1.EnvironmentSpec{Namespace: "jx-production"}},
+	}
+	list := List{
+		Items: []Application{
+			{
+				&v1.SourceRepository{Spec: v1.SourceRepositorySpec{Repo: "myapp"}},
+				map[string]Environment{
+					"staging":    {envs[0], []Deployment{{staging}}},
+					"production": {envs[1], []Deployment{{production}}},
+				},
+			},
+			{
+				&v1.SourceRepository{Spec: v1.SourceRepositorySpec{Repo: "undeployed"}},
+				map[string]Environment{},
+			},
+		},
+	}
+	desired := map[string]map[string]string{
+		"staging":    {"myapp": "1.0.2"},
+		"production": {"myapp": "1.0.2"},
+	}
+
+	matrix := NewMatrix(list, envs, desired, nil)
+	require.Len(t, matrix.Environments, 2)
+	require.Len(t, matrix.Applications, 1, "applications which are not deployed should be omitted")
+	app := matrix.Applications[0]
+	assert.Equal(t, "myapp", app.Name)
+
+	cell := app.Environments["staging"]
+	require.NotNil(t, cell)
+	assert.Equal(t, "1.0.2", cell.Version)
+	assert.False(t, cell.Drift)
+	assert.Equal(t, HealthHealthy, cell.Health)
+	require.NotNil(t, cell.LastDeployed)
+	assert.True(t, cell.LastDeployed.Equal(&deployed))
+
+	cell = app.Environments["production"]
+	require.NotNil(t, cell)
+	assert.Equal(t, "1.0.1", cell.Version)
+	assert.Equal(t, "1.0.2", cell.DesiredVersion)
+	assert.True(t, cell.Drift)
+	assert.Equal(t, HealthProgressing, cell.Health)
+	assert.Equal(t, int32(1), cell.ReadyPods)
+	assert.Equal(t, int32(2), cell.Replicas)
+}
+
+func TestCountDrift(t *testing.T) {
+	t.Parallel()
+
+	env := &v1.Environment{ObjectMeta: metav1.ObjectMeta{Name: "staging"}, Spec: v1.EnvironmentSpec{Namespace: "jx-staging"}}
+	deployments := []v1beta1.Deployment{*testDeployment("jx-staging", "1.0.2", 1, 1)}
+
+	assert.Equal(t, 0, CountDrift(env, deployments, map[string]string{"myapp": "1.0.2"}))
+	assert.Equal(t, 1, CountDrift(env, deployments, map[string]string{"myapp": "1.0.3"}))
+	assert.Equal(t, 2, CountDrift(env, deployments, map[string]string{"myapp": "1.0.3", "missing": "0.0.1"}))
+}
+
+func TestGetDeploymentsFromInformers(t *testing.T) {
+	t.Parallel()
+
+	kubeClient := fake.NewSimpleClientset(testDeployment("jx-staging", "1.0.2", 1, 1), testDeployment("jx-production", "1.0.1", 1, 1))
+	deployments, err := getDeploymentsFromInformers(kubeClient, []string{"jx-staging", "jx-production"})
+	require.NoError(t, err)
+	require.Len(t, deployments, 2)
+	assert.Equal(t, "1.0.2", deployments["jx-staging"]["jx-myapp"].Labels["version"])
+	assert.Equal(t, "1.0.1", deployments["jx-production"]["jx-myapp"].Labels["version"])
+}
@@ -0,0 +1,65 @@
+package apps
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Masterminds/semver"
+	jenkinsv1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/pkg/errors"
+)
+
+// BlockedApp is an App which could not be upgraded as the new version is not compatible with the cluster
+type BlockedApp struct {
+	Name           string
+	CurrentVersion string
+	Version        string
+	Reason         string
+}
+
+// CheckCompatibility checks the compatibility constraints declared by the App against the versions of Kubernetes and
+// the Jenkins X platform. Returns the reason the App is not compatible or an empty string if it is compatible.
+// Empty versions are not checked
+func CheckCompatibility(app *jenkinsv1.App, kubernetesVersion string, platformVersion string) (string, error) {
+	if app == nil || app.Spec.Compatibility == nil {
+		return "", nil
+	}
+	c := app.Spec.Compatibility
+	reasons := make([]string, 0)
+	ok, err := versionMatches(c.KubernetesVersion, kubernetesVersion)
+	if err != nil {
+		return "", errors.Wrapf(err, "checking kubernetes version constraint of app %s", app.Name)
+	}
+	if !ok {
+		reasons = append(reasons, fmt.Sprintf("requires kubernetes %s but the cluster is %s", c.KubernetesVersion, kubernetesVersion))
+	}
+	ok, err = versionMatches(c.PlatformVersion, platformVersion)
+	if err != nil {
+		return "", errors.Wrapf(err, "checking platform version constraint of app %s", app.Name)
+	}
+	if !ok {
+		reasons = append(reasons, fmt.Sprintf("requires jenkins-x-platform %s but the cluster has %s", c.PlatformVersion, platformVersion))
+	}
+	return strings.Join(reasons, " and "), nil
+}
+
+// versionMatches returns true if the version matches the constraint. Pre-release and build metadata of the version
+// are ignored so that versions such as v1.14.8-gke.12 match a constraint of >= 1.14
+func versionMatches(constraint string, version string) (bool, error) {
+	if constraint == "" || version == "" {
+		return true, nil
+	}
+	c, err := semver.NewConstraint(constraint)
+	if err != nil {
+		return false, errors.Wrapf(err, "parsing version constraint %s", constraint)
+	}
+	v, err := semver.NewVersion(strings.TrimPrefix(version, "v"))
+	if err != nil {
+		return false, errors.Wrapf(err, "parsing version %s", version)
+	}
+	release, err := semver.NewVersion(fmt.Sprintf("%d.%d.%d", v.Major(), v.Minor(), v.Patch()))
+	if err != nil {
+		return false, err
+	}
+	return c.Check(release), nil
+}
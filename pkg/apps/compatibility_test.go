@@ -0,0 +1,48 @@
+package apps_test
+
+import (
+	"testing"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/apps"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckCompatibility(t *testing.T) {
+	t.Parallel()
+	app := &v1.App{
+		Spec: v1.AppSpec{
+			Compatibility: &v1.AppCompatibility{
+				KubernetesVersion: ">= 1.13, < 1.16",
+				PlatformVersion:   ">= 2.0.1000",
+			},
+		},
+	}
+
+	testCases := []struct {
+		kubernetesVersion string
+		platformVersion   string
+		compatible        bool
+	}{
+		{"v1.14.8-gke.12", "2.0.1100", true},
+		{"v1.15.0", "", true},
+		{"", "2.0.1000", true},
+		{"v1.12.10", "2.0.1100", false},
+		{"v1.16.2", "2.0.1100", false},
+		{"v1.14.8", "2.0.900", false},
+	}
+	for _, tc := range testCases {
+		reason, err := apps.CheckCompatibility(app, tc.kubernetesVersion, tc.platformVersion)
+		require.NoError(t, err)
+		assert.Equal(t, tc.compatible, reason == "", "kubernetes %s platform %s: %s", tc.kubernetesVersion, tc.platformVersion, reason)
+	}
+
+	reason, err := apps.CheckCompatibility(&v1.App{}, "v1.10.0", "1.0.0")
+	require.NoError(t, err)
+	assert.Empty(t, reason, "an app without constraints is always compatible")
+
+	app.Spec.Compatibility.KubernetesVersion = "not a constraint"
+	_, err = apps.CheckCompatibility(app, "v1.14.0", "")
+	assert.Error(t, err)
+}
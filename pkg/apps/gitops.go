@@ -6,6 +6,10 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/jenkins-x/jx/pkg/dependencyupdates"
+	"github.com/jenkins-x/jx/pkg/platform"
+	"github.com/jenkins-x/jx/pkg/versionstream"
+
 	"github.com/jenkins-x/jx/pkg/gits"
 
 	"github.com/jenkins-x/jx/pkg/helm"
@@ -111,6 +115,123 @@ func (o *GitOpsOptions) UpgradeApp(app string, version string, repository string
 	return nil
 }
 
+// UpgradeAllApps upgrades all the apps in the environment to the versions in the version stream in versionsDir using a
+// single Pull Request. Apps whose new version declares compatibility constraints that the versions of Kubernetes or
+// the Jenkins X platform do not satisfy are not upgraded and are returned as blocked apps
+func (o *GitOpsOptions) UpgradeAllApps(versionsDir string, kubernetesVersion string, username string, password string,
+	interrogateChartFunc func(dir string, existing map[string]interface{}) (*ChartDetails, error),
+	autoMerge bool) ([]BlockedApp, error) {
+	rand, err := util.RandStringBytesMaskImprSrc(5)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to generate a random string")
+	}
+	details := gits.PullRequestDetails{
+		BranchName: fmt.Sprintf("upgrade-all-apps-%s", rand),
+		Title:      "Upgrade all apps",
+		Message:    "Upgrade all apps to the versions in the version stream:\n",
+	}
+	prefixes, err := versionstream.GetRepositoryPrefixes(versionsDir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "loading repository prefixes from the version stream")
+	}
+
+	blocked := make([]BlockedApp, 0)
+	cleanups := make([]func(), 0)
+	defer func() {
+		for _, cleanup := range cleanups {
+			cleanup()
+		}
+	}()
+	modifyChartFn := func(requirements *helm.Requirements, metadata *chart.Metadata, values map[string]interface{},
+		templates map[string]string, envDir string, details *gits.PullRequestDetails) error {
+		platformVersion := ""
+		for _, d := range requirements.Dependencies {
+			if d.Name == platform.JenkinsXPlatformChartName {
+				platformVersion = d.Version
+			}
+		}
+		upgraded := false
+		for _, d := range requirements.Dependencies {
+			if d.Name == platform.JenkinsXPlatformChartName {
+				continue
+			}
+			prefix := prefixes.PrefixForURL(d.Repository)
+			if prefix == "" {
+				log.Logger().Debugf("Ignoring app %s as the repository %s is not in the version stream", d.Name, d.Repository)
+				continue
+			}
+			version, err := versionstream.LoadStableVersionNumber(versionsDir, versionstream.KindChart, prefix+"/"+d.Name)
+			if err != nil {
+				return errors.Wrapf(err, "loading the version of %s from the version stream", d.Name)
+			}
+			if version == "" || !dependencyupdates.IsNewerVersion(version, d.Version) {
+				continue
+			}
+			var reason string
+			err = helm.InspectChart(d.Name, version, d.Repository, username, password, o.Helmer,
+				func(chartDir string) error {
+					appResource, _, err := environments.LocateAppResource(o.Helmer, chartDir, d.Name)
+					if err != nil {
+						return errors.Wrapf(err, "locating app resource in %s", chartDir)
+					}
+					reason, err = CheckCompatibility(appResource, kubernetesVersion, platformVersion)
+					if err != nil || reason != "" {
+						return err
+					}
+					existing, _ := values[d.Name].(map[string]interface{})
+					chartDetails, err := interrogateChartFunc(chartDir, existing)
+					if chartDetails != nil {
+						cleanups = append(cleanups, chartDetails.Cleanup)
+					}
+					if err != nil {
+						return errors.Wrapf(err, "asking questions for %s", d.Name)
+					}
+					return environments.CreateNestedRequirementDir(envDir, d.Name, chartDir, version, d.Repository,
+						o.Verbose, o.valuesFiles, o.Helmer)
+				})
+			if err != nil {
+				return errors.Wrapf(err, "inspecting chart %s", d.Name)
+			}
+			if reason != "" {
+				blocked = append(blocked, BlockedApp{
+					Name:           d.Name,
+					CurrentVersion: d.Version,
+					Version:        version,
+					Reason:         reason,
+				})
+				continue
+			}
+			details.Message = fmt.Sprintf("%s\n* %s from %s to %s", details.Message, d.Name, d.Version, version)
+			d.Version = version
+			upgraded = true
+		}
+		if len(blocked) > 0 {
+			details.Message = fmt.Sprintf("%s\n\nThe following apps were not upgraded as they are not compatible:\n", details.Message)
+			for _, b := range blocked {
+				details.Message = fmt.Sprintf("%s\n* %s %s %s", details.Message, b.Name, b.Version, b.Reason)
+			}
+		}
+		if !upgraded {
+			log.Logger().Infof("No upgrades available")
+		}
+		return nil
+	}
+
+	options := environments.EnvironmentPullRequestOptions{
+		Gitter:        o.Gitter,
+		ModifyChartFn: modifyChartFn,
+		GitProvider:   o.GitProvider,
+	}
+	info, err := options.Create(o.DevEnv, o.EnvironmentsDir, &details, nil, "", autoMerge)
+	if err != nil {
+		return blocked, err
+	}
+	if info != nil && info.PullRequest != nil {
+		log.Logger().Infof("Upgrading apps via Pull Request %s", info.PullRequest.URL)
+	}
+	return blocked, nil
+}
+
 // DeleteApp deletes the app with alias
 func (o *GitOpsOptions) DeleteApp(app string, alias string, autoMerge bool) error {
 
@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/environments"
 	"github.com/jenkins-x/jx/pkg/helm"
 	"github.com/jenkins-x/jx/pkg/log"
 	"github.com/jenkins-x/jx/pkg/util"
@@ -26,8 +27,18 @@ func (o *HelmOpsOptions) AddApp(app string, chart string, name string, version s
 	for _, vs := range setValues {
 		parsedSetValues = append(parsedSetValues, strings.Split(vs, ",")...)
 	}
+	appCRDName := fmt.Sprintf("%s-%s", releaseName, name)
 
-	err := helm.InstallFromChartOptions(helm.InstallChartOptions{
+	hooksApp, err := o.locateAppHooks(chart, name, appCRDName)
+	if err != nil {
+		return err
+	}
+	preStatuses, err := o.runAppHooks(hooksApp, v1.AppHookTypePreInstall, version, appCRDName)
+	if err != nil {
+		return err
+	}
+
+	err = helm.InstallFromChartOptions(helm.InstallChartOptions{
 		ReleaseName: releaseName,
 		Chart:       chart,
 		Version:     version,
@@ -43,8 +54,6 @@ func (o *HelmOpsOptions) AddApp(app string, chart string, name string, version s
 		return errors.Wrapf(err, "failed to install app %s", app)
 	}
 	// Attach the current values.yaml
-	appCRDName := fmt.Sprintf("%s-%s", releaseName, name)
-
 	create, appObj, err := StashValues(values, appCRDName, o.JxClient, o.Namespace, chart, repository)
 	if err != nil {
 		return errors.Wrapf(err, "attaching values.yaml to %s", appCRDName)
@@ -54,6 +63,10 @@ func (o *HelmOpsOptions) AddApp(app string, chart string, name string, version s
 	if err != nil {
 		return errors.Wrapf(err, "creating the app %s in the Apps CRD", appCRDName)
 	}
+	err = o.runPostInstallHooks(hooksApp, version, appCRDName, preStatuses)
+	if err != nil {
+		return err
+	}
 	log.Logger().Infof("Successfully installed %s %s", util.ColorInfo(name), util.ColorInfo(version))
 	return nil
 }
@@ -64,70 +77,115 @@ func (o *HelmOpsOptions) DeleteApp(app string, releaseName string, purge bool) e
 	return o.Helmer.DeleteRelease(o.Namespace, releaseName, purge)
 }
 
-//UpgradeApp upgrades the app with releaseName (or all apps if the app name is empty) to the specified version (
-// or the latest version if the version is empty) using the repository with username and password
-func (o *HelmOpsOptions) UpgradeApp(app string, version string, repository string, username string, password string,
-	releaseName string, alias string, helmUpdate bool) error {
+// UpgradeApp upgrades the app with releaseName to the specified version using the unpacked chart. The values answered
+// for the chart are attached to the App CRD so that only new questions are asked by the next upgrade
+func (o *HelmOpsOptions) UpgradeApp(app string, chart string, name string, version string, values []byte, repository string,
+	username string, password string, releaseName string, helmUpdate bool) error {
+	appCRDName := fmt.Sprintf("%s-%s", releaseName, name)
 
-	// TODO implement this!
-	/*var branchNameText string
-	var title string
-	var message string
-	all := true
+	hooksApp, err := o.locateAppHooks(chart, name, appCRDName)
+	if err != nil {
+		return err
+	}
+	preStatuses, err := o.runAppHooks(hooksApp, v1.AppHookTypePreInstall, version, appCRDName)
+	if err != nil {
+		return err
+	}
 
-	if app != "" {
-		all = false
-		if version == "" {
-			version = "latest"
+	err = helm.InstallFromChartOptions(helm.InstallChartOptions{
+		ReleaseName: releaseName,
+		Chart:       chart,
+		Version:     version,
+		Ns:          o.Namespace,
+		HelmUpdate:  helmUpdate,
+		ValueFiles:  o.valuesFiles.Items,
+		Repository:  repository,
+		Username:    username,
+		Password:    password,
+		UpgradeOnly: true,
+	}, o.Helmer, o.KubeClient, o.InstallTimeout, o.VaultClient)
+	if err != nil {
+		return errors.Wrapf(err, "failed to upgrade app %s", app)
+	}
+	if values != nil {
+		create, appObj, err := StashValues(values, appCRDName, o.JxClient, o.Namespace, chart, repository)
+		if err != nil {
+			return errors.Wrapf(err, "attaching values.yaml to %s", appCRDName)
+		}
+		if appObj.Labels == nil {
+			appObj.Labels = map[string]string{}
+		}
+		appObj.Labels[helm.LabelReleaseName] = releaseName
+		err = addApp(create, o.JxClient, appObj)
+		if err != nil {
+			return errors.Wrapf(err, "updating the app %s in the Apps CRD", appCRDName)
 		}
-		branchNameText = fmt.Sprintf("upgrade-app-%s-%s", app, version)
 	}
+	err = o.runPostInstallHooks(hooksApp, version, appCRDName, preStatuses)
+	if err != nil {
+		return err
+	}
+	log.Logger().Infof("Successfully upgraded %s to version %s", util.ColorInfo(name), util.ColorInfo(version))
+	return nil
+}
 
-	branchNameText = fmt.Sprintf("upgrade-all-apps")
-	title = fmt.Sprintf("Upgrade all apps")
-	message = fmt.Sprintf("Upgrade all apps:")
-
-	for _, d := range requirements.Dependencies {
-		upgrade := false
-		// We need to ignore the platform
-		if d.Name == "jenkins-x-platform" {
-			upgrade = false
-		} else if all {
-			upgrade = true
-		} else {
-			if d.Name == app && d.Alias == alias {
-				upgrade = true
+// locateAppHooks returns the App resource in the chart if it declares any hooks, with the status of the hooks which
+// have already been run for the installed App so they are not run again. Returns nil if the App has no hooks
+func (o *HelmOpsOptions) locateAppHooks(chartDir string, name string, appCRDName string) (*v1.App, error) {
+	appResource, _, err := environments.LocateAppResource(o.Helmer, chartDir, name)
+	if err != nil {
+		return nil, errors.Wrapf(err, "locating app resource in %s", chartDir)
+	}
+	if len(appResource.Spec.PreInstall) == 0 && len(appResource.Spec.PostInstall) == 0 {
+		return nil, nil
+	}
+	existing, err := o.JxClient.JenkinsV1().Apps(o.Namespace).Get(appCRDName, metav1.GetOptions{})
+	if err == nil {
+		appResource.Status = existing.Status
+	}
+	appResource.Name = appCRDName
+	return appResource, nil
+}
 
-			}
-		}
-		if upgrade {
-			upgraded = true
-			if all || version == "" {
-				var err error
-				version, err = helm.GetLatestVersion(d.Name, repository, username, password, o.Helmer)
-				if err != nil {
-					return err
-				}
-				if o.Verbose {
-					log.Logger().Infof("No version specified so using latest version which is %s", util.ColorInfo(version))
-				}
-			}
-			// Do the upgrade
-			oldVersion := d.Version
-			d.Version = version
-			if !all {
-				title = fmt.Sprintf("Upgrade %s to %s", app, version)
-				message = fmt.Sprintf("Upgrade %s from %s to %s", app, oldVersion, version)
-			} else {
-				message = fmt.Sprintf("%s* %s from %s to %s", message, d.Name, oldVersion, version)
-			}
+// runAppHooks runs the hooks of the given type, recording their status on the App CRD if the hooks fail
+func (o *HelmOpsOptions) runAppHooks(hooksApp *v1.App, hookType v1.AppHookType, version string,
+	appCRDName string) ([]v1.AppHookStatus, error) {
+	if hooksApp == nil {
+		return nil, nil
+	}
+	timeout, err := ParseHookTimeout(o.InstallTimeout)
+	if err != nil {
+		return nil, err
+	}
+	runner := AppHookRunner{
+		KubeClient:    o.KubeClient,
+		JxClient:      o.JxClient,
+		Namespace:     o.Namespace,
+		Timeout:       timeout,
+		IOFileHandles: o.IOFileHandles,
+	}
+	statuses, err := runner.RunHooks(hooksApp, hookType, version)
+	if err != nil {
+		recordErr := RecordHookStatuses(o.JxClient, o.Namespace, appCRDName, statuses)
+		if recordErr != nil {
+			log.Logger().Debugf("Unable to record the hook status of %s: %v", appCRDName, recordErr)
 		}
+		return statuses, err
 	}
+	return statuses, nil
+}
 
-	if !upgraded {
-		log.Logger().Infof("No upgrades available")
-	}*/
-	return nil
+// runPostInstallHooks runs the post-install hooks and records the status of all the hooks run on the App CRD
+func (o *HelmOpsOptions) runPostInstallHooks(hooksApp *v1.App, version string, appCRDName string,
+	preStatuses []v1.AppHookStatus) error {
+	if hooksApp == nil {
+		return nil
+	}
+	postStatuses, err := o.runAppHooks(hooksApp, v1.AppHookTypePostInstall, version, appCRDName)
+	if err != nil {
+		return err
+	}
+	return RecordHookStatuses(o.JxClient, o.Namespace, appCRDName, append(preStatuses, postStatuses...))
 }
 
 func (o *HelmOpsOptions) getAppsFromCRDAPI(appNames []string) (*v1.AppList, error) {
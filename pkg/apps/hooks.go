@@ -0,0 +1,293 @@
+package apps
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	jenkinsv1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/client/clientset/versioned"
+	"github.com/jenkins-x/jx/pkg/environments"
+	"github.com/jenkins-x/jx/pkg/helm"
+	"github.com/jenkins-x/jx/pkg/kube"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/mholt/archiver"
+	"github.com/pborman/uuid"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// AppHookRunner runs the pre-install and post-install hooks of Apps as pods in the namespace the Apps are installed in
+type AppHookRunner struct {
+	KubeClient    kubernetes.Interface
+	JxClient      versioned.Interface
+	Namespace     string
+	Timeout       time.Duration
+	IOFileHandles util.IOFileHandles
+}
+
+// RunHooks runs the hooks of the given type of the app for the version in order, stopping at the first hook which
+// fails. Hooks which have already succeeded for the version are skipped. The statuses of the hooks which were run are
+// returned even if a hook fails
+func (r *AppHookRunner) RunHooks(app *jenkinsv1.App, hookType jenkinsv1.AppHookType, version string) ([]jenkinsv1.AppHookStatus, error) {
+	hooks := app.Spec.PreInstall
+	if hookType == jenkinsv1.AppHookTypePostInstall {
+		hooks = app.Spec.PostInstall
+	}
+	statuses := make([]jenkinsv1.AppHookStatus, 0)
+	for i := range hooks {
+		hook := &hooks[i]
+		if HookSucceeded(app, hookType, hook.Name, version) {
+			log.Logger().Debugf("Skipping %s hook %s of app %s as it has already succeeded for version %s", hookType, hook.Name, app.Name, version)
+			continue
+		}
+		log.Logger().Infof("Running %s hook %s of app %s", hookType, util.ColorInfo(hook.Name), util.ColorInfo(app.Name))
+		started := metav1.Now()
+		status := jenkinsv1.AppHookStatus{
+			Name:        hook.Name,
+			Type:        hookType,
+			Version:     version,
+			StartedTime: &started,
+		}
+		err := r.runHook(app.Name, hook, hookType)
+		completed := metav1.Now()
+		status.CompletedTime = &completed
+		if err != nil {
+			status.Status = jenkinsv1.ActivityStatusTypeFailed
+			status.Message = err.Error()
+			statuses = append(statuses, status)
+			return statuses, errors.Wrapf(err, "running %s hook %s of app %s", hookType, hook.Name, app.Name)
+		}
+		status.Status = jenkinsv1.ActivityStatusTypeSucceeded
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// HookSucceeded returns true if the status of the app records the hook as having succeeded for the version
+func HookSucceeded(app *jenkinsv1.App, hookType jenkinsv1.AppHookType, name string, version string) bool {
+	for _, s := range app.Status.Hooks {
+		if s.Type == hookType && s.Name == name && s.Version == version {
+			return s.Status == jenkinsv1.ActivityStatusTypeSucceeded
+		}
+	}
+	return false
+}
+
+// RecordHookStatuses records the statuses of hooks on the App with the given name replacing any previous status of
+// the same hooks
+func RecordHookStatuses(jxClient versioned.Interface, ns string, name string, statuses []jenkinsv1.AppHookStatus) error {
+	if len(statuses) == 0 {
+		return nil
+	}
+	app, err := jxClient.JenkinsV1().Apps(ns).Get(name, metav1.GetOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "getting App %s", name)
+	}
+	for _, s := range statuses {
+		found := false
+		for i := range app.Status.Hooks {
+			existing := &app.Status.Hooks[i]
+			if existing.Type == s.Type && existing.Name == s.Name {
+				*existing = s
+				found = true
+				break
+			}
+		}
+		if !found {
+			app.Status.Hooks = append(app.Status.Hooks, s)
+		}
+	}
+	_, err = jxClient.JenkinsV1().Apps(ns).Update(app)
+	if err != nil {
+		return errors.Wrapf(err, "updating the hook status of App %s", name)
+	}
+	return nil
+}
+
+// RunPostInstallHooks runs the post-install hooks of all the Apps in the namespace which have not yet succeeded for
+// the installed version of the App, recording their status on the Apps. This is used by the GitOps pipeline after
+// the environment has been applied
+func (r *AppHookRunner) RunPostInstallHooks() error {
+	appList, err := r.JxClient.JenkinsV1().Apps(r.Namespace).List(metav1.ListOptions{})
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			// the App CRD is not installed yet so there are no hooks to run
+			log.Logger().Debugf("Not running app post-install hooks as Apps cannot be listed in namespace %s: %s", r.Namespace, err)
+			return nil
+		}
+		return errors.Wrapf(err, "listing Apps in namespace %s", r.Namespace)
+	}
+	var failed []string
+	for i := range appList.Items {
+		app := &appList.Items[i]
+		if len(app.Spec.PostInstall) == 0 {
+			continue
+		}
+		statuses, err := r.RunHooks(app, jenkinsv1.AppHookTypePostInstall, app.Labels[helm.LabelAppVersion])
+		if err != nil {
+			log.Logger().Errorf("%s", err)
+			failed = append(failed, app.Name)
+		}
+		err = RecordHookStatuses(r.JxClient, r.Namespace, app.Name, statuses)
+		if err != nil {
+			return err
+		}
+	}
+	if len(failed) > 0 {
+		return errors.Errorf("post-install hooks failed for apps %v", failed)
+	}
+	return nil
+}
+
+// LocateAppResourcesInCharts finds the App resources of the app charts packaged in the charts directory of an
+// environment chart. The returned Apps are labelled with the name and version of their chart
+func LocateAppResourcesInCharts(helmer helm.Helmer, chartsDir string) ([]*jenkinsv1.App, error) {
+	archives, err := filepath.Glob(filepath.Join(chartsDir, "*.tgz"))
+	if err != nil {
+		return nil, errors.Wrapf(err, "listing charts in %s", chartsDir)
+	}
+	answer := make([]*jenkinsv1.App, 0)
+	if len(archives) == 0 {
+		return answer, nil
+	}
+	tmpDir, err := ioutil.TempDir("", "app-hooks-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmpDir)
+	for i, archive := range archives {
+		dir := filepath.Join(tmpDir, strconv.Itoa(i))
+		err = archiver.Unarchive(archive, dir)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unpacking chart %s", archive)
+		}
+		chartFiles, err := filepath.Glob(filepath.Join(dir, "*", helm.ChartFileName))
+		if err != nil || len(chartFiles) != 1 {
+			log.Logger().Debugf("Ignoring %s as it does not contain a single chart", archive)
+			continue
+		}
+		name, version, err := helm.LoadChartNameAndVersion(chartFiles[0])
+		if err != nil {
+			return nil, errors.Wrapf(err, "loading chart %s", archive)
+		}
+		app, _, err := environments.LocateAppResource(helmer, filepath.Dir(chartFiles[0]), name)
+		if err != nil {
+			return nil, errors.Wrapf(err, "locating app resource in %s", archive)
+		}
+		if app.Labels == nil {
+			app.Labels = map[string]string{}
+		}
+		app.Labels[helm.LabelAppName] = name
+		app.Labels[helm.LabelAppVersion] = version
+		answer = append(answer, app)
+	}
+	return answer, nil
+}
+
+// ParseHookTimeout parses the timeout of hooks which is either a number of seconds, as used for helm install
+// timeouts, or a duration such as '10m'
+func ParseHookTimeout(timeout string) (time.Duration, error) {
+	seconds, err := strconv.Atoi(timeout)
+	if err == nil {
+		return time.Duration(seconds) * time.Second, nil
+	}
+	d, err := time.ParseDuration(timeout)
+	if err != nil {
+		return 0, errors.Errorf("invalid timeout %s should be a number of seconds or a duration such as 10m", timeout)
+	}
+	return d, nil
+}
+
+func (r *AppHookRunner) runHook(appName string, hook *jenkinsv1.AppHook, hookType jenkinsv1.AppHookType) error {
+	if len(hook.Steps) == 0 {
+		return errors.Errorf("hook %s has no steps", hook.Name)
+	}
+	timeout := r.Timeout
+	if hook.Timeout != "" {
+		var err error
+		timeout, err = time.ParseDuration(hook.Timeout)
+		if err != nil {
+			return errors.Wrapf(err, "invalid timeout %s", hook.Timeout)
+		}
+	}
+
+	// the steps run in order as init containers with the last step as the container of the pod
+	steps := make([]corev1.Container, 0, len(hook.Steps))
+	for i := range hook.Steps {
+		step := hook.Steps[i].DeepCopy()
+		if step.Name == "" {
+			step.Name = fmt.Sprintf("step%d", i+1)
+		}
+		steps = append(steps, *step)
+	}
+	last := len(steps) - 1
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: toValidName(appName, string(hookType)+"-"+hook.Name, uuid.New()),
+			Labels: map[string]string{
+				helm.LabelAppName: appName,
+			},
+		},
+		Spec: corev1.PodSpec{
+			InitContainers:     steps[:last],
+			Containers:         steps[last:],
+			ServiceAccountName: hook.ServiceAccountName,
+			RestartPolicy:      corev1.RestartPolicyNever,
+		},
+	}
+	pods := r.KubeClient.CoreV1().Pods(r.Namespace)
+	_, err := pods.Create(pod)
+	if err != nil {
+		return errors.Wrapf(err, "creating pod %s", pod.Name)
+	}
+	defer func() {
+		err := pods.Delete(pod.Name, &metav1.DeleteOptions{})
+		if err != nil {
+			log.Logger().Errorf("Error deleting pod %s for hook %s: %v", pod.Name, hook.Name, err)
+		}
+	}()
+	err = kube.WaitForPodNameToBeComplete(r.KubeClient, r.Namespace, pod.Name, timeout)
+	if err != nil {
+		return errors.Wrapf(err, "waiting for pod %s to complete", pod.Name)
+	}
+	completePod, err := pods.Get(pod.Name, metav1.GetOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "getting pod %s", pod.Name)
+	}
+	if kube.PodStatus(completePod) == string(corev1.PodFailed) {
+		container := failedContainerName(completePod)
+		log.Logger().Errorf("Pod Log")
+		log.Logger().Errorf("-----------")
+		err := kube.TailLogs(r.Namespace, pod.Name, container, r.IOFileHandles.Err, r.IOFileHandles.Out)
+		log.Logger().Errorf("-----------")
+		if err != nil {
+			log.Logger().Warnf("Failed to get the logs of pod %s container %s: %s", pod.Name, container, err)
+		}
+		return errors.Errorf("step %s failed", container)
+	}
+	return nil
+}
+
+// failedContainerName returns the name of the first container of the pod which terminated with a non zero exit code
+func failedContainerName(pod *corev1.Pod) string {
+	statuses := make([]corev1.ContainerStatus, 0, len(pod.Status.InitContainerStatuses)+len(pod.Status.ContainerStatuses))
+	statuses = append(statuses, pod.Status.InitContainerStatuses...)
+	statuses = append(statuses, pod.Status.ContainerStatuses...)
+	for _, s := range statuses {
+		if s.State.Terminated != nil && s.State.Terminated.ExitCode != 0 {
+			return s.Name
+		}
+	}
+	if len(pod.Spec.Containers) > 0 {
+		return pod.Spec.Containers[0].Name
+	}
+	return ""
+}
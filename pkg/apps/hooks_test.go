@@ -0,0 +1,100 @@
+package apps_test
+
+import (
+	"testing"
+	"time"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/apps"
+	"github.com/jenkins-x/jx/pkg/client/clientset/versioned/fake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestRecordHookStatuses(t *testing.T) {
+	t.Parallel()
+	ns := "jx"
+	app := &v1.App{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "jx-app-db",
+			Namespace: ns,
+		},
+		Status: v1.AppStatus{
+			Hooks: []v1.AppHookStatus{
+				{
+					Name:    "migrate",
+					Type:    v1.AppHookTypePreInstall,
+					Version: "1.0.0",
+					Status:  v1.ActivityStatusTypeSucceeded,
+				},
+			},
+		},
+	}
+	jxClient := fake.NewSimpleClientset(app)
+
+	err := apps.RecordHookStatuses(jxClient, ns, app.Name, []v1.AppHookStatus{
+		{
+			Name:    "migrate",
+			Type:    v1.AppHookTypePreInstall,
+			Version: "1.1.0",
+			Status:  v1.ActivityStatusTypeFailed,
+			Message: "step step1 failed",
+		},
+		{
+			Name:    "smoke-test",
+			Type:    v1.AppHookTypePostInstall,
+			Version: "1.1.0",
+			Status:  v1.ActivityStatusTypeSucceeded,
+		},
+	})
+	require.NoError(t, err)
+
+	updated, err := jxClient.JenkinsV1().Apps(ns).Get(app.Name, metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Len(t, updated.Status.Hooks, 2)
+	assert.Equal(t, v1.ActivityStatusTypeFailed, updated.Status.Hooks[0].Status)
+	assert.Equal(t, "1.1.0", updated.Status.Hooks[0].Version)
+
+	assert.False(t, apps.HookSucceeded(updated, v1.AppHookTypePreInstall, "migrate", "1.1.0"))
+	assert.False(t, apps.HookSucceeded(updated, v1.AppHookTypePreInstall, "migrate", "1.0.0"))
+	assert.True(t, apps.HookSucceeded(updated, v1.AppHookTypePostInstall, "smoke-test", "1.1.0"))
+	assert.False(t, apps.HookSucceeded(updated, v1.AppHookTypePreInstall, "smoke-test", "1.1.0"))
+}
+
+func TestRunPostInstallHooksWithoutAppCRD(t *testing.T) {
+	t.Parallel()
+	jxClient := fake.NewSimpleClientset()
+	jxClient.PrependReactor("list", "apps", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, k8serrors.NewNotFound(v1.Resource("apps"), "")
+	})
+	runner := &apps.AppHookRunner{
+		JxClient:  jxClient,
+		Namespace: "jx",
+	}
+
+	err := runner.RunPostInstallHooks()
+	assert.NoError(t, err)
+}
+
+func TestParseHookTimeout(t *testing.T) {
+	t.Parallel()
+
+	timeout, err := apps.ParseHookTimeout("600")
+	require.NoError(t, err)
+	assert.Equal(t, 10*time.Minute, timeout)
+
+	timeout, err = apps.ParseHookTimeout("90s")
+	require.NoError(t, err)
+	assert.Equal(t, 90*time.Second, timeout)
+
+	timeout, err = apps.ParseHookTimeout("1h")
+	require.NoError(t, err)
+	assert.Equal(t, time.Hour, timeout)
+
+	_, err = apps.ParseHookTimeout("soon")
+	assert.Error(t, err)
+}
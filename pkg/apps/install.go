@@ -2,7 +2,6 @@ package apps
 
 import (
 	"encoding/base64"
-	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -10,6 +9,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/ghodss/yaml"
 	"github.com/jenkins-x/jx/pkg/kube/naming"
 	rbacv1 "k8s.io/api/rbac/v1"
 
@@ -22,6 +22,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	jenkinsv1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/audit"
 	"github.com/jenkins-x/jx/pkg/client/clientset/versioned"
 	"github.com/jenkins-x/jx/pkg/environments"
 	"github.com/jenkins-x/jx/pkg/gits"
@@ -140,7 +141,12 @@ func (o *InstallOptions) AddApp(app string, version string, repository string, u
 	}
 
 	// Do the actual work
-	return helm.InspectChart(chartName, version, repository, username, password, o.Helmer, installAppFunc)
+	err = helm.InspectChart(chartName, version, repository, username, password, o.Helmer, installAppFunc)
+	if err != nil {
+		return err
+	}
+	o.recordAppAudit(chartName, version, repository, "added")
+	return nil
 }
 
 //GetApps gets a list of installed apps
@@ -256,12 +262,15 @@ func (o *InstallOptions) UpgradeApp(app string, version string, repository strin
 			return err
 		}
 	} else {
+		if app == "" {
+			return errors.Errorf("upgrading all apps is only supported when using GitOps, please specify the app to upgrade")
+		}
 		upgradeAppFunc := func(dir string) error {
-			// Try to load existing answers from the apps CRD
+			// Try to load existing answers from the apps CRD so that only new questions are asked
 			appCrdName := fmt.Sprintf("%s-%s", releaseName, chartName)
 			appResource, err := o.JxClient.JenkinsV1().Apps(o.Namespace).Get(appCrdName, metav1.GetOptions{})
 			if err != nil {
-				return errors.Wrapf(err, "getting App CRD %s", appResource.Name)
+				return errors.Wrapf(err, "getting App CRD %s", appCrdName)
 			}
 			var existingValues map[string]interface{}
 			if appResource.Annotations != nil {
@@ -271,7 +280,7 @@ func (o *InstallOptions) UpgradeApp(app string, version string, repository strin
 						log.Logger().Warnf("Error decoding base64 encoded string from %s on %s\n%s", ValuesAnnotation,
 							appCrdName, encodedValues)
 					}
-					err = json.Unmarshal(existingValuesBytes, &existingValues)
+					err = yaml.Unmarshal(existingValuesBytes, &existingValues)
 					if err != nil {
 						return errors.Wrapf(err, "unmarshaling %s", string(existingValuesBytes))
 					}
@@ -288,7 +297,15 @@ func (o *InstallOptions) UpgradeApp(app string, version string, repository strin
 			opts := HelmOpsOptions{
 				InstallOptions: o,
 			}
-			err = opts.UpgradeApp(chartName, version, repository, username, password, releaseName, alias, update)
+			if helm.IsLocal(chartName) {
+				// We need to manually build the dependencies
+				err = opts.Helmer.BuildDependency()
+				if err != nil {
+					return errors.Wrapf(err, "building dependencies for %s", chartName)
+				}
+			}
+			err = opts.UpgradeApp(chartName, dir, chartDetails.Name, chartDetails.Version, chartDetails.Values, repository,
+				username, password, releaseName, update)
 			if err != nil {
 				return err
 			}
@@ -300,10 +317,45 @@ func (o *InstallOptions) UpgradeApp(app string, version string, repository strin
 			return err
 		}
 	}
+	o.recordAppAudit(chartName, version, repository, "upgraded")
 	return nil
 
 }
 
+// recordAppAudit records the audit event of an app being added or upgraded
+func (o *InstallOptions) recordAppAudit(chartName string, version string, repository string, verb string) {
+	resource := chartName
+	if resource == "" {
+		resource = "all apps"
+	}
+	if version == "" {
+		version = "latest"
+	}
+	audit.Record(jenkinsv1.AuditActionAppUpgraded, resource, o.Namespace, fmt.Sprintf("%s app %s version %s", verb, resource, version), map[string]string{
+		"version":    version,
+		"repository": repository,
+		"gitops":     fmt.Sprintf("%t", o.GitOps),
+	})
+}
+
+// UpgradeAllApps upgrades all the apps to the versions in the version stream in versionsDir using a single GitOps Pull
+// Request, returning the apps which were not upgraded as the new version is not compatible with the versions of
+// Kubernetes or the Jenkins X platform
+func (o *InstallOptions) UpgradeAllApps(versionsDir string, kubernetesVersion string, username string, password string,
+	askExisting bool) ([]BlockedApp, error) {
+	if !o.GitOps {
+		return nil, errors.Errorf("upgrading all apps is only supported when using GitOps")
+	}
+	o.valuesFiles = &environments.ValuesFiles{
+		Items: make([]string, 0),
+	}
+	interrogateChartFunc := o.createInterrogateChartFn("", "", "", username, password, "", askExisting)
+	opts := GitOpsOptions{
+		InstallOptions: o,
+	}
+	return opts.UpgradeAllApps(versionsDir, kubernetesVersion, username, password, interrogateChartFunc, o.AutoMerge)
+}
+
 // ChartDetails are details about a chart returned by the chart interrogator
 type ChartDetails struct {
 	Values  []byte
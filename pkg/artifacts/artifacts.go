@@ -0,0 +1,384 @@
+package artifacts
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/jenkins-x/jx/pkg/config"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// CredentialsSecret the Secret in the dev namespace containing the credentials of the artifact repository
+	CredentialsSecret = "jx-artifact-repository"
+	// UsernameKey the key of the user name in the credentials Secret
+	UsernameKey = "username"
+	// PasswordKey the key of the password or token in the credentials Secret
+	PasswordKey = "password"
+
+	// SettingsSecret the Secret mounted into the build pods containing the maven settings and the npmrc
+	SettingsSecret = "jenkins-maven-settings"
+	// MavenSettingsKey the key of the maven settings in the settings Secret
+	MavenSettingsKey = "settings.xml"
+	// NpmrcKey the key of the npmrc in the settings Secret
+	NpmrcKey = "npmrc"
+	// SettingsMountPath the path the settings Secret is mounted in the build pods
+	SettingsMountPath = "/root/.m2/"
+
+	// EnvMavenURL the environment variable of the build pods with the URL of the maven repository
+	EnvMavenURL = "ARTIFACT_REPOSITORY_MAVEN_URL"
+	// EnvNpmURL the environment variable of the build pods with the URL of the npm registry
+	EnvNpmURL = "ARTIFACT_REPOSITORY_NPM_URL"
+	// EnvUsername the environment variable of the build pods with the user name of the artifact repository
+	EnvUsername = "ARTIFACT_REPOSITORY_USERNAME"
+	// EnvPassword the environment variable of the build pods with the password or token of the artifact repository
+	EnvPassword = "ARTIFACT_REPOSITORY_PASSWORD"
+
+	// ServerID the id of the maven server of the artifact repository
+	ServerID = "artifacts"
+
+	defaultRepository = "maven"
+	codeArtifactUser  = "aws"
+	npmEmail          = "jenkins-x@googlegroups.com"
+)
+
+// Endpoints the URLs of an artifact repository
+type Endpoints struct {
+	// Kind the kind of artifact repository
+	Kind config.RepositoryType
+	// MavenURL the URL maven artifacts are resolved from
+	MavenURL string
+	// MavenReleasesURL the URL maven releases are deployed to
+	MavenReleasesURL string
+	// MavenSnapshotsURL the URL maven snapshots are deployed to
+	MavenSnapshotsURL string
+	// NpmURL the URL of the npm registry
+	NpmURL string
+	// NpmScope the scope the npm registry is used for. If blank it is the default registry
+	NpmScope string
+}
+
+// Credentials the credentials of an artifact repository
+type Credentials struct {
+	Username string
+	Password string
+}
+
+// IsEnabled returns true if the pipelines use an artifact repository
+func IsEnabled(kind config.RepositoryType) bool {
+	return kind != config.RepositoryTypeNone && kind != config.RepositoryTypeUnknown
+}
+
+// GetEndpoints returns the URLs of the kind of artifact repository from its configuration
+func GetEndpoints(requirements *config.RequirementsConfig) (*Endpoints, error) {
+	kind := requirements.Repository
+	repo := requirements.ArtifactRepository
+	if repo == nil {
+		repo = &config.ArtifactRepositoryConfig{}
+	}
+	mavenRepo := repo.MavenRepository
+	npmRepo := repo.NpmRepository
+	if npmRepo == "" {
+		npmRepo = mavenRepo
+	}
+	answer := &Endpoints{Kind: kind}
+	switch kind {
+	case config.RepositoryTypeNexus:
+		base := repo.URL
+		if base == "" {
+			base = "http://nexus"
+		}
+		answer.MavenURL = util.UrlJoin(base, "repository/maven-group/")
+		answer.MavenReleasesURL = util.UrlJoin(base, "repository/maven-releases/")
+		answer.MavenSnapshotsURL = util.UrlJoin(base, "repository/maven-snapshots/")
+		answer.NpmURL = util.UrlJoin(base, "repository/npm-group/")
+		return answer, nil
+	case config.RepositoryTypeBucketRepo:
+		base := repo.URL
+		if base == "" {
+			base = "http://bucketrepo/bucketrepo"
+		}
+		answer.MavenURL = util.UrlJoin(base, "/")
+		return answer.withDeployURL(answer.MavenURL), nil
+	case config.RepositoryTypeArtifactory:
+		if repo.URL == "" {
+			return nil, fmt.Errorf("the artifactRepository.url of the Artifactory server is required")
+		}
+		if mavenRepo == "" {
+			mavenRepo = defaultRepository
+		}
+		if npmRepo == "" {
+			npmRepo = "npm"
+		}
+		answer.MavenURL = util.UrlJoin(repo.URL, mavenRepo, "/")
+		answer.NpmURL = util.UrlJoin(repo.URL, "api/npm", npmRepo, "/")
+		return answer.withDeployURL(answer.MavenURL), nil
+	case config.RepositoryTypeGitHubPackages:
+		if repo.Owner == "" || mavenRepo == "" {
+			return nil, fmt.Errorf("the artifactRepository.owner and artifactRepository.mavenRepository of the GitHub Packages are required")
+		}
+		answer.MavenURL = util.UrlJoin("https://maven.pkg.github.com", repo.Owner, mavenRepo)
+		answer.NpmURL = "https://npm.pkg.github.com/"
+		answer.NpmScope = "@" + strings.ToLower(repo.Owner)
+		return answer.withDeployURL(answer.MavenURL), nil
+	case config.RepositoryTypeCodeArtifact:
+		region := repo.Region
+		if region == "" {
+			region = requirements.Cluster.Region
+		}
+		if repo.Domain == "" || repo.Owner == "" || region == "" || mavenRepo == "" {
+			return nil, fmt.Errorf("the artifactRepository.domain, artifactRepository.owner, artifactRepository.region and artifactRepository.mavenRepository of the CodeArtifact repository are required")
+		}
+		base := fmt.Sprintf("https://%s-%s.d.codeartifact.%s.amazonaws.com", repo.Domain, repo.Owner, region)
+		answer.MavenURL = util.UrlJoin(base, "maven", mavenRepo, "/")
+		answer.NpmURL = util.UrlJoin(base, "npm", npmRepo, "/")
+		return answer.withDeployURL(answer.MavenURL), nil
+	case config.RepositoryTypeAzureArtifacts:
+		if repo.Organisation == "" || mavenRepo == "" {
+			return nil, fmt.Errorf("the artifactRepository.organisation and artifactRepository.mavenRepository of the Azure Artifacts feed are required")
+		}
+		base := util.UrlJoin("https://pkgs.dev.azure.com", repo.Organisation)
+		if repo.Project != "" {
+			base = util.UrlJoin(base, repo.Project)
+		}
+		answer.MavenURL = util.UrlJoin(base, "_packaging", mavenRepo, "maven/v1")
+		answer.NpmURL = util.UrlJoin(base, "_packaging", npmRepo, "npm/registry/")
+		return answer.withDeployURL(answer.MavenURL), nil
+	case config.RepositoryTypeNone, config.RepositoryTypeUnknown:
+		return answer, nil
+	default:
+		return nil, util.InvalidOption("repository", string(kind), config.RepositoryTypeValues)
+	}
+}
+
+// withDeployURL uses the URL for deploying both releases and snapshots
+func (e *Endpoints) withDeployURL(u string) *Endpoints {
+	e.MavenReleasesURL = u
+	e.MavenSnapshotsURL = u
+	return e
+}
+
+// GetCredentials returns the credentials of the artifact repository from the credentials Secret. CodeArtifact uses
+// the AWS credentials of the caller to fetch a short lived token instead
+func GetCredentials(kubeClient kubernetes.Interface, ns string, requirements *config.RequirementsConfig) (*Credentials, error) {
+	if requirements.Repository == config.RepositoryTypeCodeArtifact {
+		return getCodeArtifactCredentials(requirements)
+	}
+	secret, err := kubeClient.CoreV1().Secrets(ns).Get(CredentialsSecret, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) && !requiresCredentials(requirements.Repository) {
+			return &Credentials{}, nil
+		}
+		return nil, errors.Wrapf(err, "failed to find the artifact repository credentials in Secret %s in namespace %s", CredentialsSecret, ns)
+	}
+	return &Credentials{
+		Username: string(secret.Data[UsernameKey]),
+		Password: string(secret.Data[PasswordKey]),
+	}, nil
+}
+
+// requiresCredentials returns true if the kind of artifact repository is not installed by Jenkins X and so always
+// requires credentials
+func requiresCredentials(kind config.RepositoryType) bool {
+	return kind != config.RepositoryTypeNexus && kind != config.RepositoryTypeBucketRepo
+}
+
+// getCodeArtifactCredentials fetches an authorization token of the CodeArtifact domain
+func getCodeArtifactCredentials(requirements *config.RequirementsConfig) (*Credentials, error) {
+	repo := requirements.ArtifactRepository
+	if repo == nil {
+		return nil, fmt.Errorf("no artifactRepository configured for the CodeArtifact repository")
+	}
+	region := repo.Region
+	if region == "" {
+		region = requirements.Cluster.Region
+	}
+	cmd := util.Command{
+		Name: "aws",
+		Args: []string{"codeartifact", "get-authorization-token", "--domain", repo.Domain, "--domain-owner", repo.Owner,
+			"--region", region, "--query", "authorizationToken", "--output", "text"},
+	}
+	token, err := cmd.RunWithoutRetry()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get the authorization token of CodeArtifact domain %s", repo.Domain)
+	}
+	return &Credentials{Username: codeArtifactUser, Password: strings.TrimSpace(token)}, nil
+}
+
+// MavenSettingsXML generates the maven settings resolving and deploying artifacts with the artifact repository
+func MavenSettingsXML(endpoints *Endpoints, credentials *Credentials) string {
+	var buf bytes.Buffer
+	buf.WriteString(`<settings>
+  <!-- sets the local maven repository outside of the ~/.m2 folder for easier mounting of secrets and repo -->
+  <localRepository>${user.home}/.mvnrepository</localRepository>
+  <!-- lets disable the download progress indicator that fills up logs -->
+  <interactiveMode>false</interactiveMode>
+`)
+	if endpoints.MavenURL != "" {
+		fmt.Fprintf(&buf, `  <mirrors>
+    <mirror>
+      <id>%s</id>
+      <mirrorOf>external:*</mirrorOf>
+      <url>%s</url>
+    </mirror>
+  </mirrors>
+`, ServerID, escapeXML(endpoints.MavenURL))
+	}
+	if credentials != nil && (credentials.Username != "" || credentials.Password != "") {
+		fmt.Fprintf(&buf, `  <servers>
+    <server>
+      <id>%s</id>
+      <username>%s</username>
+      <password>%s</password>
+    </server>
+  </servers>
+`, ServerID, escapeXML(credentials.Username), escapeXML(credentials.Password))
+	}
+	buf.WriteString("  <profiles>\n")
+	if endpoints.MavenReleasesURL != "" {
+		fmt.Fprintf(&buf, `    <profile>
+      <id>%s</id>
+      <properties>
+        <altDeploymentRepository>%s::default::%s</altDeploymentRepository>
+        <altReleaseDeploymentRepository>%s::default::%s</altReleaseDeploymentRepository>
+        <altSnapshotDeploymentRepository>%s::default::%s</altSnapshotDeploymentRepository>
+      </properties>
+    </profile>
+`, ServerID, ServerID, escapeXML(endpoints.MavenSnapshotsURL), ServerID, escapeXML(endpoints.MavenReleasesURL), ServerID, escapeXML(endpoints.MavenSnapshotsURL))
+	}
+	buf.WriteString(`    <profile>
+      <id>release</id>
+      <properties>
+        <gpg.executable>gpg</gpg.executable>
+      </properties>
+    </profile>
+  </profiles>
+`)
+	if endpoints.MavenReleasesURL != "" {
+		fmt.Fprintf(&buf, `  <activeProfiles>
+    <!--make the profile active all the time -->
+    <activeProfile>%s</activeProfile>
+  </activeProfiles>
+`, ServerID)
+	}
+	buf.WriteString("</settings>\n")
+	return buf.String()
+}
+
+// Npmrc generates the npm configuration resolving and publishing packages with the artifact repository
+func Npmrc(endpoints *Endpoints, credentials *Credentials) (string, error) {
+	if endpoints.NpmURL == "" {
+		return "", nil
+	}
+	u, err := url.Parse(endpoints.NpmURL)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to parse the npm registry URL %s", endpoints.NpmURL)
+	}
+	registry := strings.TrimSuffix(endpoints.NpmURL, "/") + "/"
+	authPrefix := "//" + u.Host + strings.TrimSuffix(u.Path, "/") + "/:"
+
+	var buf bytes.Buffer
+	if endpoints.NpmScope != "" {
+		fmt.Fprintf(&buf, "%s:registry=%s\n", endpoints.NpmScope, registry)
+	} else {
+		fmt.Fprintf(&buf, "registry=%s\n", registry)
+	}
+	if credentials != nil && credentials.Password != "" {
+		if endpoints.Kind == config.RepositoryTypeAzureArtifacts || endpoints.Kind == config.RepositoryTypeNexus {
+			// these registries only support basic authentication with a base64 encoded password
+			username := credentials.Username
+			if username == "" {
+				username = "jenkins-x"
+			}
+			fmt.Fprintf(&buf, "%susername=%s\n", authPrefix, username)
+			fmt.Fprintf(&buf, "%s_password=%s\n", authPrefix, base64.StdEncoding.EncodeToString([]byte(credentials.Password)))
+			fmt.Fprintf(&buf, "%semail=%s\n", authPrefix, npmEmail)
+		} else {
+			fmt.Fprintf(&buf, "%s_authToken=%s\n", authPrefix, credentials.Password)
+		}
+		fmt.Fprintf(&buf, "%salways-auth=true\n", authPrefix)
+	}
+	return buf.String(), nil
+}
+
+// BuildPodEnv returns the environment variables of the build pods describing the artifact repository. The
+// credentials are referenced from the credentials Secret so that they are never part of the pipeline
+func BuildPodEnv(endpoints *Endpoints) []corev1.EnvVar {
+	answer := []corev1.EnvVar{}
+	if endpoints.MavenURL != "" {
+		answer = append(answer, corev1.EnvVar{Name: EnvMavenURL, Value: endpoints.MavenURL})
+	}
+	if endpoints.NpmURL != "" {
+		answer = append(answer,
+			corev1.EnvVar{Name: EnvNpmURL, Value: endpoints.NpmURL},
+			corev1.EnvVar{Name: "NPM_CONFIG_USERCONFIG", Value: SettingsMountPath + NpmrcKey},
+		)
+	}
+	if endpoints.Kind == config.RepositoryTypeCodeArtifact {
+		// the short lived CodeArtifact token is only available in the generated settings
+		return answer
+	}
+	optional := true
+	for _, e := range []struct {
+		name string
+		key  string
+	}{{EnvUsername, UsernameKey}, {EnvPassword, PasswordKey}} {
+		answer = append(answer, corev1.EnvVar{
+			Name: e.name,
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: CredentialsSecret},
+					Key:                  e.key,
+					Optional:             &optional,
+				},
+			},
+		})
+	}
+	return answer
+}
+
+// Verify checks the maven repository and npm registry of the artifact repository are reachable and accept the
+// credentials
+func Verify(client *http.Client, endpoints *Endpoints, credentials *Credentials) error {
+	for _, u := range []string{endpoints.MavenURL, endpoints.NpmURL} {
+		if u == "" {
+			continue
+		}
+		req, err := http.NewRequest(http.MethodGet, u, nil)
+		if err != nil {
+			return errors.Wrapf(err, "failed to create the request for %s", u)
+		}
+		if credentials != nil && (credentials.Username != "" || credentials.Password != "") {
+			req.SetBasicAuth(credentials.Username, credentials.Password)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return errors.Wrapf(err, "failed to connect to the %s artifact repository at %s", endpoints.Kind, u)
+		}
+		resp.Body.Close()
+		switch {
+		case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+			return fmt.Errorf("the %s artifact repository at %s rejected the credentials with status %d. Check the Secret %s", endpoints.Kind, u, resp.StatusCode, CredentialsSecret)
+		case resp.StatusCode >= 500:
+			return fmt.Errorf("the %s artifact repository at %s returned status %d", endpoints.Kind, u, resp.StatusCode)
+		}
+	}
+	return nil
+}
+
+// escapeXML escapes the text for use in the maven settings
+func escapeXML(text string) string {
+	var buf bytes.Buffer
+	_ = xml.EscapeText(&buf, []byte(text))
+	return buf.String()
+}
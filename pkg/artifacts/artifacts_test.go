@@ -0,0 +1,177 @@
+package artifacts_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jenkins-x/jx/pkg/artifacts"
+	"github.com/jenkins-x/jx/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestGetEndpoints(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name         string
+		kind         config.RepositoryType
+		repo         *config.ArtifactRepositoryConfig
+		region       string
+		expectedMvn  string
+		expectedNpm  string
+		expectedFail bool
+	}{
+		{
+			name:        "nexus",
+			kind:        config.RepositoryTypeNexus,
+			expectedMvn: "http://nexus/repository/maven-group/",
+			expectedNpm: "http://nexus/repository/npm-group/",
+		},
+		{
+			name:        "artifactory",
+			kind:        config.RepositoryTypeArtifactory,
+			repo:        &config.ArtifactRepositoryConfig{URL: "https://acme.jfrog.io/artifactory/", MavenRepository: "libs", NpmRepository: "npm-local"},
+			expectedMvn: "https://acme.jfrog.io/artifactory/libs/",
+			expectedNpm: "https://acme.jfrog.io/artifactory/api/npm/npm-local/",
+		},
+		{
+			name:        "github packages",
+			kind:        config.RepositoryTypeGitHubPackages,
+			repo:        &config.ArtifactRepositoryConfig{Owner: "Acme", MavenRepository: "packages"},
+			expectedMvn: "https://maven.pkg.github.com/Acme/packages",
+			expectedNpm: "https://npm.pkg.github.com/",
+		},
+		{
+			name:        "codeartifact",
+			kind:        config.RepositoryTypeCodeArtifact,
+			repo:        &config.ArtifactRepositoryConfig{Domain: "acme", Owner: "123456789012", MavenRepository: "releases"},
+			region:      "eu-west-1",
+			expectedMvn: "https://acme-123456789012.d.codeartifact.eu-west-1.amazonaws.com/maven/releases/",
+			expectedNpm: "https://acme-123456789012.d.codeartifact.eu-west-1.amazonaws.com/npm/releases/",
+		},
+		{
+			name:        "azure artifacts",
+			kind:        config.RepositoryTypeAzureArtifacts,
+			repo:        &config.ArtifactRepositoryConfig{Organisation: "acme", Project: "apps", MavenRepository: "feed"},
+			expectedMvn: "https://pkgs.dev.azure.com/acme/apps/_packaging/feed/maven/v1",
+			expectedNpm: "https://pkgs.dev.azure.com/acme/apps/_packaging/feed/npm/registry/",
+		},
+		{
+			name:         "artifactory without url",
+			kind:         config.RepositoryTypeArtifactory,
+			expectedFail: true,
+		},
+		{
+			name:         "codeartifact without region",
+			kind:         config.RepositoryTypeCodeArtifact,
+			repo:         &config.ArtifactRepositoryConfig{Domain: "acme", Owner: "123456789012", MavenRepository: "releases"},
+			expectedFail: true,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			requirements := config.NewRequirementsConfig()
+			requirements.Repository = tc.kind
+			requirements.ArtifactRepository = tc.repo
+			requirements.Cluster.Region = tc.region
+
+			endpoints, err := artifacts.GetEndpoints(requirements)
+			if tc.expectedFail {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedMvn, endpoints.MavenURL)
+			assert.Equal(t, tc.expectedNpm, endpoints.NpmURL)
+		})
+	}
+}
+
+func TestMavenSettingsXML(t *testing.T) {
+	t.Parallel()
+
+	endpoints := &artifacts.Endpoints{
+		Kind:              config.RepositoryTypeArtifactory,
+		MavenURL:          "https://acme.jfrog.io/artifactory/libs/",
+		MavenReleasesURL:  "https://acme.jfrog.io/artifactory/libs/",
+		MavenSnapshotsURL: "https://acme.jfrog.io/artifactory/libs/",
+	}
+	settings := artifacts.MavenSettingsXML(endpoints, &artifacts.Credentials{Username: "bot", Password: "p<&>ss"})
+
+	assert.Contains(t, settings, "<url>https://acme.jfrog.io/artifactory/libs/</url>")
+	assert.Contains(t, settings, "<password>p&lt;&amp;&gt;ss</password>")
+	assert.Contains(t, settings, "<altReleaseDeploymentRepository>artifacts::default::https://acme.jfrog.io/artifactory/libs/</altReleaseDeploymentRepository>")
+	assert.Contains(t, settings, "<activeProfile>artifacts</activeProfile>")
+}
+
+func TestNpmrc(t *testing.T) {
+	t.Parallel()
+
+	npmrc, err := artifacts.Npmrc(&artifacts.Endpoints{
+		Kind:     config.RepositoryTypeGitHubPackages,
+		NpmURL:   "https://npm.pkg.github.com/",
+		NpmScope: "@acme",
+	}, &artifacts.Credentials{Username: "bot", Password: "token"})
+	require.NoError(t, err)
+	assert.Equal(t, "@acme:registry=https://npm.pkg.github.com/\n//npm.pkg.github.com/:_authToken=token\n//npm.pkg.github.com/:always-auth=true\n", npmrc)
+
+	npmrc, err = artifacts.Npmrc(&artifacts.Endpoints{
+		Kind:   config.RepositoryTypeAzureArtifacts,
+		NpmURL: "https://pkgs.dev.azure.com/acme/_packaging/feed/npm/registry/",
+	}, &artifacts.Credentials{Username: "acme", Password: "pat"})
+	require.NoError(t, err)
+	assert.Contains(t, npmrc, "registry=https://pkgs.dev.azure.com/acme/_packaging/feed/npm/registry/\n")
+	assert.Contains(t, npmrc, "//pkgs.dev.azure.com/acme/_packaging/feed/npm/registry/:_password=cGF0\n")
+}
+
+func TestGetCredentials(t *testing.T) {
+	t.Parallel()
+
+	kubeClient := fake.NewSimpleClientset(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: artifacts.CredentialsSecret, Namespace: "jx"},
+		Data:       map[string][]byte{artifacts.UsernameKey: []byte("bot"), artifacts.PasswordKey: []byte("s3cr3t")},
+	})
+	requirements := config.NewRequirementsConfig()
+	requirements.Repository = config.RepositoryTypeArtifactory
+
+	credentials, err := artifacts.GetCredentials(kubeClient, "jx", requirements)
+	require.NoError(t, err)
+	assert.Equal(t, &artifacts.Credentials{Username: "bot", Password: "s3cr3t"}, credentials)
+
+	_, err = artifacts.GetCredentials(kubeClient, "other", requirements)
+	assert.Error(t, err, "should require the credentials of an external repository")
+
+	requirements.Repository = config.RepositoryTypeNexus
+	credentials, err = artifacts.GetCredentials(kubeClient, "other", requirements)
+	require.NoError(t, err)
+	assert.Equal(t, &artifacts.Credentials{}, credentials)
+}
+
+func TestVerify(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, password, ok := r.BasicAuth()
+		if !ok || user != "bot" || password != "s3cr3t" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	endpoints := &artifacts.Endpoints{Kind: config.RepositoryTypeArtifactory, MavenURL: server.URL + "/libs/"}
+	err := artifacts.Verify(server.Client(), endpoints, &artifacts.Credentials{Username: "bot", Password: "s3cr3t"})
+	assert.NoError(t, err)
+
+	err = artifacts.Verify(server.Client(), endpoints, &artifacts.Credentials{Username: "bot", Password: "wrong"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,280 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/client/clientset/versioned"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// SinkCRD records the audit events as AuditEvent resources in the dev namespace
+	SinkCRD = "crd"
+	// SinkBucket records the audit events as JSON files in the storage location of the audit classifier
+	SinkBucket = "bucket"
+	// SinkWebhook posts the audit events as JSON to a webhook
+	SinkWebhook = "webhook"
+
+	// LabelAction the label of the AuditEvent resources with the action
+	LabelAction = "jenkins.io/audit-action"
+	// LabelUser the label of the AuditEvent resources with the user
+	LabelUser = "jenkins.io/audit-user"
+
+	// EnvVarAuditUser overrides the user recorded on the audit events
+	EnvVarAuditUser = "JX_AUDIT_USER"
+)
+
+var (
+	// Sinks the supported sinks
+	Sinks = []string{SinkCRD, SinkBucket, SinkWebhook}
+
+	recorderFactory func() (*Recorder, error)
+	recorder        *Recorder
+	recorderOnce    sync.Once
+	recorderLock    sync.Mutex
+)
+
+// Sink records audit events
+type Sink interface {
+	Record(event *v1.AuditEvent) error
+}
+
+// DataCollector stores data at a path returning the URL of the stored data
+type DataCollector interface {
+	CollectData(data []byte, path string) (string, error)
+}
+
+// CRDSink records the audit events as AuditEvent resources
+type CRDSink struct {
+	JXClient  versioned.Interface
+	Namespace string
+}
+
+// Record creates the AuditEvent resource
+func (s *CRDSink) Record(event *v1.AuditEvent) error {
+	_, err := s.JXClient.JenkinsV1().AuditEvents(s.Namespace).Create(event)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create the AuditEvent %s in namespace %s", event.Name, s.Namespace)
+	}
+	return nil
+}
+
+// BucketSink records the audit events as JSON files using a collector
+type BucketSink struct {
+	Collector DataCollector
+}
+
+// Record stores the audit event as a JSON file in a directory per day
+func (s *BucketSink) Record(event *v1.AuditEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal the AuditEvent %s", event.Name)
+	}
+	storagePath := filepath.Join("jenkins-x", "audit", event.Spec.Timestamp.Format("2006/01/02"), event.Name+".json")
+	_, err = s.Collector.CollectData(data, storagePath)
+	if err != nil {
+		return errors.Wrapf(err, "failed to store the AuditEvent at %s", storagePath)
+	}
+	return nil
+}
+
+// WebhookSink posts the audit events as JSON to a URL
+type WebhookSink struct {
+	URL    string
+	Client *http.Client
+}
+
+// Record posts the audit event to the webhook
+func (s *WebhookSink) Record(event *v1.AuditEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal the AuditEvent %s", event.Name)
+	}
+	client := s.Client
+	if client == nil {
+		client = util.GetClientWithTimeout(10 * time.Second)
+	}
+	resp, err := client.Post(s.URL, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return errors.Wrapf(err, "failed to post the AuditEvent to %s", s.URL)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("failed to post the AuditEvent to %s: status %s", s.URL, resp.Status)
+	}
+	return nil
+}
+
+// Recorder records audit events to its sinks
+type Recorder struct {
+	Sinks    []Sink
+	User     string
+	Command  string
+	Pipeline string
+}
+
+// NewEvent creates an audit event for the recorder's user, command and pipeline
+func (r *Recorder) NewEvent(action string, resource string, namespace string, message string, details map[string]string) *v1.AuditEvent {
+	now := time.Now()
+	event := &v1.AuditEvent{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: fmt.Sprintf("%s-%d", action, now.UnixNano()),
+			Labels: map[string]string{
+				LabelAction: action,
+			},
+		},
+		Spec: v1.AuditEventSpec{
+			Action:    action,
+			User:      r.User,
+			Resource:  resource,
+			Namespace: namespace,
+			Message:   message,
+			Command:   r.Command,
+			Pipeline:  r.Pipeline,
+			Details:   details,
+			Timestamp: metav1.NewTime(now),
+		},
+	}
+	if r.User != "" {
+		event.Labels[LabelUser] = toLabelValue(r.User)
+	}
+	return event
+}
+
+// Record records the audit event in all the sinks
+func (r *Recorder) Record(event *v1.AuditEvent) error {
+	errs := []error{}
+	for _, sink := range r.Sinks {
+		errs = append(errs, sink.Record(event))
+	}
+	return util.CombineErrors(errs...)
+}
+
+// SetRecorderFactory sets the function which lazily creates the recorder used by Record the first time an event is
+// recorded
+func SetRecorderFactory(factory func() (*Recorder, error)) {
+	recorderLock.Lock()
+	defer recorderLock.Unlock()
+	recorderFactory = factory
+	recorder = nil
+	recorderOnce = sync.Once{}
+}
+
+// Record records an audit event for a change made by jx. Auditing never fails the change so any failure to record
+// the event is logged as a warning
+func Record(action string, resource string, namespace string, message string, details map[string]string) {
+	recorderLock.Lock()
+	defer recorderLock.Unlock()
+	recorderOnce.Do(func() {
+		if recorderFactory == nil {
+			return
+		}
+		r, err := recorderFactory()
+		if err != nil {
+			log.Logger().Warnf("Unable to record audit events: %s", err.Error())
+			return
+		}
+		recorder = r
+	})
+	if recorder == nil || len(recorder.Sinks) == 0 {
+		return
+	}
+	event := recorder.NewEvent(action, resource, namespace, message, details)
+	err := recorder.Record(event)
+	if err != nil {
+		log.Logger().Warnf("Failed to record the %s audit event for %s: %s", action, resource, err.Error())
+	}
+}
+
+// CurrentUser returns the user recorded on the audit events which is $JX_AUDIT_USER or the user running jx outside of
+// a pipeline
+func CurrentUser(inCluster bool) string {
+	answer := os.Getenv(EnvVarAuditUser)
+	if answer == "" && !inCluster {
+		answer = os.Getenv("USER")
+	}
+	return answer
+}
+
+// CurrentPipeline returns the name and build number of the pipeline running jx if any
+func CurrentPipeline() string {
+	owner := os.Getenv("REPO_OWNER")
+	repository := os.Getenv("REPO_NAME")
+	branch := os.Getenv(util.EnvVarBranchName)
+	if owner == "" || repository == "" || branch == "" {
+		return ""
+	}
+	answer := strings.Join([]string{owner, repository, branch}, "/")
+	build := os.Getenv("BUILD_NUMBER")
+	if build != "" {
+		answer += " #" + build
+	}
+	return answer
+}
+
+// ListEvents returns the audit events in the namespace since the given time sorted by time
+func ListEvents(jxClient versioned.Interface, ns string, since time.Time, action string) ([]v1.AuditEvent, error) {
+	listOptions := metav1.ListOptions{}
+	if action != "" {
+		listOptions.LabelSelector = LabelAction + "=" + action
+	}
+	list, err := jxClient.JenkinsV1().AuditEvents(ns).List(listOptions)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list the AuditEvents in namespace %s", ns)
+	}
+	answer := []v1.AuditEvent{}
+	for _, event := range list.Items {
+		if !since.IsZero() && event.Spec.Timestamp.Time.Before(since) {
+			continue
+		}
+		answer = append(answer, event)
+	}
+	sort.Slice(answer, func(i, j int) bool {
+		return answer[i].Spec.Timestamp.Time.Before(answer[j].Spec.Timestamp.Time)
+	})
+	return answer, nil
+}
+
+// ParseSince parses a duration such as 7d, 12h or 30m
+func ParseSince(text string) (time.Duration, error) {
+	if strings.HasSuffix(text, "d") {
+		days, err := strconv.Atoi(strings.TrimSuffix(text, "d"))
+		if err != nil {
+			return 0, errors.Wrapf(err, "failed to parse the number of days %s", text)
+		}
+		return time.Duration(days) * 24 * time.Hour, nil
+	}
+	answer, err := time.ParseDuration(text)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to parse the duration %s", text)
+	}
+	return answer, nil
+}
+
+// toLabelValue converts the user into a valid label value
+func toLabelValue(text string) string {
+	answer := strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '-' || r == '_' || r == '.' {
+			return r
+		}
+		return '-'
+	}, text)
+	if len(answer) > 63 {
+		answer = answer[:63]
+	}
+	return strings.Trim(answer, "-_.")
+}
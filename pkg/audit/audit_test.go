@@ -0,0 +1,114 @@
+package audit_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/audit"
+	"github.com/jenkins-x/jx/pkg/client/clientset/versioned/fake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeCollector struct {
+	data map[string][]byte
+}
+
+func (c *fakeCollector) CollectData(data []byte, path string) (string, error) {
+	c.data[path] = data
+	return "file://" + path, nil
+}
+
+func TestRecordAuditEvents(t *testing.T) {
+	jxClient := fake.NewSimpleClientset()
+	coll := &fakeCollector{data: map[string][]byte{}}
+	var posted *v1.AuditEvent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		posted = &v1.AuditEvent{}
+		require.NoError(t, json.Unmarshal(data, posted))
+	}))
+	defer server.Close()
+
+	recorder := &audit.Recorder{
+		Sinks: []audit.Sink{
+			&audit.CRDSink{JXClient: jxClient, Namespace: "jx"},
+			&audit.BucketSink{Collector: coll},
+			&audit.WebhookSink{URL: server.URL},
+		},
+		User:     "jstrachan",
+		Command:  "jx step helm apply",
+		Pipeline: "myorg/environment-staging/master #3",
+	}
+	audit.SetRecorderFactory(func() (*audit.Recorder, error) {
+		return recorder, nil
+	})
+	defer audit.SetRecorderFactory(nil)
+
+	audit.Record(v1.AuditActionEnvironmentApplied, "jx-staging", "jx-staging", "applied the helm chart in env", map[string]string{"release": "jx-staging"})
+
+	events, err := audit.ListEvents(jxClient, "jx", time.Time{}, "")
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	event := events[0]
+	assert.Equal(t, v1.AuditActionEnvironmentApplied, event.Spec.Action)
+	assert.Equal(t, "jstrachan", event.Spec.User)
+	assert.Equal(t, "jx step helm apply", event.Spec.Command)
+	assert.Equal(t, "jx-staging", event.Spec.Details["release"])
+	assert.Equal(t, v1.AuditActionEnvironmentApplied, event.Labels[audit.LabelAction])
+
+	assert.Len(t, coll.data, 1, "the event should be stored in the bucket")
+	require.NotNil(t, posted, "the event should be posted to the webhook")
+	assert.Equal(t, event.Name, posted.Name)
+}
+
+func TestListAuditEvents(t *testing.T) {
+	t.Parallel()
+	jxClient := fake.NewSimpleClientset()
+	recorder := &audit.Recorder{
+		Sinks: []audit.Sink{&audit.CRDSink{JXClient: jxClient, Namespace: "jx"}},
+	}
+	old := recorder.NewEvent(v1.AuditActionSecretWritten, "vault:jx-pipeline-git", "", "wrote the secret", nil)
+	old.Name = "old"
+	old.Spec.Timestamp.Time = time.Now().Add(-10 * 24 * time.Hour)
+	require.NoError(t, recorder.Record(old))
+	pr := recorder.NewEvent(v1.AuditActionPullRequestRaised, "https://github.com/myorg/environment-staging/pull/1", "", "chore: promote myapp", nil)
+	pr.Name = "pr"
+	require.NoError(t, recorder.Record(pr))
+	app := recorder.NewEvent(v1.AuditActionAppUpgraded, "jx-app-sso", "jx", "upgraded app jx-app-sso", nil)
+	app.Name = "app"
+	app.Spec.Timestamp.Time = time.Now().Add(-time.Hour)
+	require.NoError(t, recorder.Record(app))
+
+	events, err := audit.ListEvents(jxClient, "jx", time.Now().Add(-7*24*time.Hour), "")
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	assert.Equal(t, "app", events[0].Name, "the events should be sorted by time")
+	assert.Equal(t, "pr", events[1].Name)
+
+	events, err = audit.ListEvents(jxClient, "jx", time.Time{}, v1.AuditActionSecretWritten)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, "old", events[0].Name)
+}
+
+func TestParseSince(t *testing.T) {
+	t.Parallel()
+	for text, expected := range map[string]time.Duration{
+		"7d":  7 * 24 * time.Hour,
+		"12h": 12 * time.Hour,
+		"30m": 30 * time.Minute,
+	} {
+		actual, err := audit.ParseSince(text)
+		require.NoError(t, err, "parsing %s", text)
+		assert.Equal(t, expected, actual, "parsing %s", text)
+	}
+	_, err := audit.ParseSince("a week")
+	assert.Error(t, err)
+}
@@ -3,6 +3,8 @@ package boot
 const (
 	// PullRequestLabel is the label used on pull requests created by boot
 	PullRequestLabel = "jx/boot"
+	// UpgradeBranchName is the name of the branch of the pull requests created by boot upgrades
+	UpgradeBranchName = "jx_boot_upgrade"
 	// OverrideTLSWarningEnvVarName is an environment variable set in BDD tests to override the error (in batch mode)
 	// that is created if TLS is not enabled
 	OverrideTLSWarningEnvVarName = "TESTING_ONLY_OVERRIDE_TLS_WARNING"
@@ -0,0 +1,434 @@
+package boot
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jenkins-x/jx/pkg/helm"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/secreturl"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/yaml"
+)
+
+// SecretIssueKind the kind of a difference between the expected secrets and the actual secrets
+type SecretIssueKind string
+
+const (
+	// SecretIssueMissing the secret or key is expected but does not exist
+	SecretIssueMissing SecretIssueKind = "Missing"
+	// SecretIssueStale the value of the key in the cluster differs from the value in the secret backend
+	SecretIssueStale SecretIssueKind = "Stale"
+	// SecretIssueOrphaned the key exists but is not referenced by any template
+	SecretIssueOrphaned SecretIssueKind = "Orphaned"
+
+	// SecretLocationBackend the secret backend such as Vault or the local file system
+	SecretLocationBackend = "backend"
+	// SecretLocationCluster the kubernetes cluster
+	SecretLocationCluster = "cluster"
+
+	templatePlaceholderPrefix = "__jx_template_"
+)
+
+var (
+	secretURIRegex         = regexp.MustCompile(`\b(vault|local):([-_\w\/]+):([-_\w]+)`)
+	templateActionRegex    = regexp.MustCompile(`\{\{.*?\}\}`)
+	templateLineRegex      = regexp.MustCompile(`^\s*\{\{.*\}\}\s*$`)
+	templatePlaceholderRex = regexp.MustCompile(templatePlaceholderPrefix + `(\d+)__`)
+	parameterRegex         = regexp.MustCompile(`\.Parameters\.([\w.]+)`)
+	documentSeparatorRegex = regexp.MustCompile(`(?m)^---\s*$`)
+)
+
+// SecretReference a reference to a key of a secret in the secret backend such as `vault:path:key`
+type SecretReference struct {
+	Scheme string
+	Path   string
+	Key    string
+	File   string
+}
+
+// URI returns the URI of the reference
+func (r *SecretReference) URI() string {
+	return secreturl.ToURI(r.Path, r.Key, r.Scheme)
+}
+
+// ExpectedClusterSecret a kubernetes Secret created from a template
+type ExpectedClusterSecret struct {
+	Name      string
+	Namespace string
+	File      string
+	// Keys the keys of the secret and their template expressions
+	Keys map[string]string
+}
+
+// ExpectedSecrets the secrets expected by the templates of a boot configuration
+type ExpectedSecrets struct {
+	References     []SecretReference
+	ClusterSecrets []ExpectedClusterSecret
+	Parameters     map[string]interface{}
+}
+
+// SecretIssue a difference between the expected secrets and the actual secrets
+type SecretIssue struct {
+	Kind     SecretIssueKind `json:"kind"`
+	Location string          `json:"location"`
+	Secret   string          `json:"secret"`
+	Key      string          `json:"key,omitempty"`
+	File     string          `json:"file,omitempty"`
+	Message  string          `json:"message"`
+}
+
+// SecretsDiffOptions the options to compare the expected secrets with the actual secrets
+type SecretsDiffOptions struct {
+	// Backend the client of the secret backend
+	Backend secreturl.Client
+	// KubeClient the client of the cluster, if nil the cluster secrets are not compared
+	KubeClient kubernetes.Interface
+	// Namespace the namespace of the cluster secrets whose template does not specify one
+	Namespace string
+	// Diff reports stale and orphaned secrets as well as missing secrets
+	Diff bool
+}
+
+// FindExpectedSecrets finds the secrets expected by the `*.tmpl` and `*.tmpl.yaml` templates and the
+// `parameters.yaml` files in the given directory
+func FindExpectedSecrets(dir string) (*ExpectedSecrets, error) {
+	answer := &ExpectedSecrets{
+		Parameters: map[string]interface{}{},
+	}
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		name := info.Name()
+		if info.IsDir() {
+			if name == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		isTemplate := strings.HasSuffix(name, ".tmpl") || strings.HasSuffix(name, ".tmpl.yaml")
+		if !isTemplate && name != helm.ParametersYAMLFile {
+			return nil
+		}
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return errors.Wrapf(err, "reading %s", path)
+		}
+		fileName, err := filepath.Rel(dir, path)
+		if err != nil {
+			fileName = path
+		}
+		answer.References = append(answer.References, findSecretReferences(string(data), fileName)...)
+		if name == helm.ParametersYAMLFile {
+			parameters, err := helm.LoadValues(data)
+			if err != nil {
+				return errors.Wrapf(err, "parsing %s", path)
+			}
+			util.CombineMapTrees(answer.Parameters, parameters)
+			return nil
+		}
+		answer.ClusterSecrets = append(answer.ClusterSecrets, findClusterSecrets(string(data), fileName)...)
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "finding the secret templates in %s", dir)
+	}
+	return answer, nil
+}
+
+// Diff compares the expected secrets with the secrets in the secret backend and in the cluster
+func (e *ExpectedSecrets) Diff(o SecretsDiffOptions) ([]SecretIssue, error) {
+	issues := []SecretIssue{}
+	backendSecrets := map[string]map[string]interface{}{}
+	referencedKeys := map[string]map[string]bool{}
+	readBackend := func(path string) map[string]interface{} {
+		values, ok := backendSecrets[path]
+		if !ok {
+			var err error
+			values, err = o.Backend.Read(path)
+			if err != nil {
+				log.Logger().Debugf("failed to read secret %s: %s", path, err)
+				values = nil
+			}
+			backendSecrets[path] = values
+		}
+		return values
+	}
+
+	reported := map[string]bool{}
+	for _, ref := range e.References {
+		uri := ref.URI()
+		if reported[uri] {
+			continue
+		}
+		reported[uri] = true
+		if referencedKeys[ref.Path] == nil {
+			referencedKeys[ref.Path] = map[string]bool{}
+		}
+		referencedKeys[ref.Path][ref.Key] = true
+		values := readBackend(ref.Path)
+		if values == nil {
+			issues = append(issues, SecretIssue{
+				Kind:     SecretIssueMissing,
+				Location: SecretLocationBackend,
+				Secret:   ref.Path,
+				Key:      ref.Key,
+				File:     ref.File,
+				Message:  fmt.Sprintf("secret %s does not exist", ref.Path),
+			})
+			continue
+		}
+		if _, ok := values[ref.Key]; !ok {
+			issues = append(issues, SecretIssue{
+				Kind:     SecretIssueMissing,
+				Location: SecretLocationBackend,
+				Secret:   ref.Path,
+				Key:      ref.Key,
+				File:     ref.File,
+				Message:  fmt.Sprintf("secret %s has no key %s", ref.Path, ref.Key),
+			})
+		}
+	}
+	if o.Diff {
+		paths := []string{}
+		for path := range referencedKeys {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+		for _, path := range paths {
+			keys := []string{}
+			for key := range backendSecrets[path] {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				if !referencedKeys[path][key] {
+					issues = append(issues, SecretIssue{
+						Kind:     SecretIssueOrphaned,
+						Location: SecretLocationBackend,
+						Secret:   path,
+						Key:      key,
+						Message:  fmt.Sprintf("key %s of secret %s is not referenced by any template", key, path),
+					})
+				}
+			}
+		}
+	}
+
+	if o.KubeClient == nil {
+		return issues, nil
+	}
+	for _, expected := range e.ClusterSecrets {
+		ns := expected.Namespace
+		if ns == "" {
+			ns = o.Namespace
+		}
+		secretName := ns + "/" + expected.Name
+		secret, err := o.KubeClient.CoreV1().Secrets(ns).Get(expected.Name, metav1.GetOptions{})
+		if err != nil {
+			if !k8serrors.IsNotFound(err) {
+				return issues, errors.Wrapf(err, "getting Secret %s", secretName)
+			}
+			issues = append(issues, SecretIssue{
+				Kind:     SecretIssueMissing,
+				Location: SecretLocationCluster,
+				Secret:   secretName,
+				File:     expected.File,
+				Message:  fmt.Sprintf("Secret %s does not exist", secretName),
+			})
+			continue
+		}
+		actual := secretData(secret)
+		for _, key := range sortedKeys(expected.Keys) {
+			value, ok := actual[key]
+			if !ok {
+				issues = append(issues, SecretIssue{
+					Kind:     SecretIssueMissing,
+					Location: SecretLocationCluster,
+					Secret:   secretName,
+					Key:      key,
+					File:     expected.File,
+					Message:  fmt.Sprintf("Secret %s has no key %s", secretName, key),
+				})
+				continue
+			}
+			if !o.Diff {
+				continue
+			}
+			ref := e.resolveReference(expected.Keys[key])
+			if ref == nil {
+				continue
+			}
+			backendValue, ok := readBackend(ref.Path)[ref.Key]
+			if !ok {
+				continue
+			}
+			text, err := util.AsString(backendValue)
+			if err != nil {
+				continue
+			}
+			if text != value {
+				issues = append(issues, SecretIssue{
+					Kind:     SecretIssueStale,
+					Location: SecretLocationCluster,
+					Secret:   secretName,
+					Key:      key,
+					File:     expected.File,
+					Message:  fmt.Sprintf("key %s of Secret %s differs from %s", key, secretName, ref.URI()),
+				})
+			}
+		}
+		if o.Diff {
+			for _, key := range sortedKeys(actual) {
+				if _, ok := expected.Keys[key]; !ok {
+					issues = append(issues, SecretIssue{
+						Kind:     SecretIssueOrphaned,
+						Location: SecretLocationCluster,
+						Secret:   secretName,
+						Key:      key,
+						File:     expected.File,
+						Message:  fmt.Sprintf("key %s of Secret %s is not in its template", key, secretName),
+					})
+				}
+			}
+		}
+	}
+	return issues, nil
+}
+
+// resolveReference returns the secret backend reference of a template expression which is either a secret URI or a
+// single template action referencing a parameter whose value is a secret URI
+func (e *ExpectedSecrets) resolveReference(expression string) *SecretReference {
+	expression = strings.TrimSpace(expression)
+	if refs := findSecretReferences(expression, ""); len(refs) == 1 && refs[0].URI() == expression {
+		return &refs[0]
+	}
+	if !templateLineRegex.MatchString(expression) || len(templateActionRegex.FindAllString(expression, -1)) != 1 {
+		return nil
+	}
+	matches := parameterRegex.FindAllStringSubmatch(expression, -1)
+	if len(matches) != 1 {
+		return nil
+	}
+	value := util.GetMapValueAsStringViaPath(e.Parameters, matches[0][1])
+	refs := findSecretReferences(value, "")
+	if len(refs) == 1 && refs[0].URI() == strings.TrimSpace(value) {
+		return &refs[0]
+	}
+	return nil
+}
+
+func findSecretReferences(text string, fileName string) []SecretReference {
+	answer := []SecretReference{}
+	for _, match := range secretURIRegex.FindAllStringSubmatch(text, -1) {
+		answer = append(answer, SecretReference{
+			Scheme: match[1],
+			Path:   match[2],
+			Key:    match[3],
+			File:   fileName,
+		})
+	}
+	return answer
+}
+
+// templateSecret the subset of a templated kubernetes Secret used to find the expected keys
+type templateSecret struct {
+	Kind     string `json:"kind"`
+	Metadata struct {
+		Name      string `json:"name"`
+		Namespace string `json:"namespace,omitempty"`
+	} `json:"metadata"`
+	Data       map[string]string `json:"data,omitempty"`
+	StringData map[string]string `json:"stringData,omitempty"`
+}
+
+// findClusterSecrets finds the kubernetes Secrets in a template. Lines which only contain template actions are
+// ignored and inline template actions are replaced by placeholders so that the template can be parsed as YAML
+func findClusterSecrets(text string, fileName string) []ExpectedClusterSecret {
+	answer := []ExpectedClusterSecret{}
+	if !strings.Contains(text, "Secret") {
+		return answer
+	}
+	actions := []string{}
+	lines := []string{}
+	for _, line := range strings.Split(text, "\n") {
+		if templateLineRegex.MatchString(line) && strings.TrimSpace(line) == templateActionRegex.FindString(line) {
+			continue
+		}
+		line = templateActionRegex.ReplaceAllStringFunc(line, func(action string) string {
+			actions = append(actions, action)
+			return fmt.Sprintf("%s%d__", templatePlaceholderPrefix, len(actions)-1)
+		})
+		lines = append(lines, line)
+	}
+	restore := func(value string) string {
+		return templatePlaceholderRex.ReplaceAllStringFunc(value, func(placeholder string) string {
+			idx, err := strconv.Atoi(templatePlaceholderRex.FindStringSubmatch(placeholder)[1])
+			if err != nil || idx >= len(actions) {
+				return placeholder
+			}
+			return actions[idx]
+		})
+	}
+	for _, doc := range documentSeparatorRegex.Split(strings.Join(lines, "\n"), -1) {
+		secret := &templateSecret{}
+		err := yaml.Unmarshal([]byte(doc), secret)
+		if err != nil {
+			log.Logger().Debugf("ignoring template %s which cannot be parsed as YAML: %s", fileName, err)
+			continue
+		}
+		if secret.Kind != "Secret" || secret.Metadata.Name == "" {
+			continue
+		}
+		if strings.Contains(secret.Metadata.Name, templatePlaceholderPrefix) || strings.Contains(secret.Metadata.Namespace, templatePlaceholderPrefix) {
+			log.Logger().Debugf("ignoring Secret %s in %s as its name is templated", restore(secret.Metadata.Name), fileName)
+			continue
+		}
+		keys := map[string]string{}
+		for k, v := range secret.Data {
+			keys[k] = restore(v)
+		}
+		for k, v := range secret.StringData {
+			keys[k] = restore(v)
+		}
+		answer = append(answer, ExpectedClusterSecret{
+			Name:      secret.Metadata.Name,
+			Namespace: secret.Metadata.Namespace,
+			File:      fileName,
+			Keys:      keys,
+		})
+	}
+	return answer
+}
+
+func secretData(secret *corev1.Secret) map[string]string {
+	answer := map[string]string{}
+	for k, v := range secret.Data {
+		answer[k] = string(v)
+	}
+	for k, v := range secret.StringData {
+		answer[k] = v
+	}
+	return answer
+}
+
+func sortedKeys(m map[string]string) []string {
+	answer := []string{}
+	for k := range m {
+		answer = append(answer, k)
+	}
+	sort.Strings(answer)
+	return answer
+}
@@ -0,0 +1,107 @@
+package boot_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x/jx/pkg/boot"
+	"github.com/jenkins-x/jx/pkg/secreturl/localvault"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestFindExpectedSecrets(t *testing.T) {
+	t.Parallel()
+
+	expected, err := boot.FindExpectedSecrets(filepath.Join("test_data", "secrets"))
+	require.NoError(t, err)
+
+	uris := []string{}
+	for _, ref := range expected.References {
+		uris = append(uris, ref.URI())
+	}
+	assert.ElementsMatch(t, []string{
+		"local:mycluster/pipelineUser:token",
+		"local:mycluster/adminUser:password",
+		"local:mycluster/lighthouse:hmac",
+	}, uris)
+
+	require.Len(t, expected.ClusterSecrets, 2)
+	names := map[string]boot.ExpectedClusterSecret{}
+	for _, secret := range expected.ClusterSecrets {
+		names[secret.Name] = secret
+	}
+	pipelineSecret := names["jx-pipeline-git"]
+	assert.Equal(t, "", pipelineSecret.Namespace)
+	assert.Equal(t, filepath.Join("env", "templates", "pipeline-secret.tmpl.yaml"), pipelineSecret.File)
+	assert.Equal(t, "{{ .Parameters.pipelineUser.token }}", pipelineSecret.Keys["password"])
+	assert.Equal(t, "jx-system", names["jx-basic-auth"].Namespace)
+}
+
+func TestSecretsDiff(t *testing.T) {
+	t.Parallel()
+
+	expected, err := boot.FindExpectedSecrets(filepath.Join("test_data", "secrets"))
+	require.NoError(t, err)
+
+	dir, err := ioutil.TempDir("", "test-secrets-diff")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	backend := localvault.NewFileSystemClient(dir)
+	_, err = backend.Write("mycluster/pipelineUser", map[string]interface{}{"token": "new-token", "oldToken": "unused"})
+	require.NoError(t, err)
+	_, err = backend.Write("mycluster/adminUser", map[string]interface{}{"username": "admin"})
+	require.NoError(t, err)
+
+	kubeClient := fake.NewSimpleClientset(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "jx-pipeline-git", Namespace: "jx"},
+		Data: map[string][]byte{
+			"username": []byte("jenkins-x-bot"),
+			"password": []byte("old-token"),
+			"extra":    []byte("value"),
+		},
+	})
+
+	issues, err := expected.Diff(boot.SecretsDiffOptions{
+		Backend:    backend,
+		KubeClient: kubeClient,
+		Namespace:  "jx",
+	})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{
+		"Missing backend mycluster/adminUser password",
+		"Missing backend mycluster/lighthouse hmac",
+		"Missing cluster jx-system/jx-basic-auth ",
+	}, issueNames(issues), "without diff only missing secrets are reported")
+
+	issues, err = expected.Diff(boot.SecretsDiffOptions{
+		Backend:    backend,
+		KubeClient: kubeClient,
+		Namespace:  "jx",
+		Diff:       true,
+	})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{
+		"Missing backend mycluster/adminUser password",
+		"Missing backend mycluster/lighthouse hmac",
+		"Orphaned backend mycluster/pipelineUser oldToken",
+		"Orphaned backend mycluster/adminUser username",
+		"Stale cluster jx/jx-pipeline-git password",
+		"Orphaned cluster jx/jx-pipeline-git extra",
+		"Missing cluster jx-system/jx-basic-auth ",
+	}, issueNames(issues))
+}
+
+func issueNames(issues []boot.SecretIssue) []string {
+	answer := []string{}
+	for _, issue := range issues {
+		answer = append(answer, string(issue.Kind)+" "+issue.Location+" "+issue.Secret+" "+issue.Key)
+	}
+	return answer
+}
@@ -0,0 +1,42 @@
+package v1
+
+import (
+	"bytes"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	util "github.com/jenkins-x/jx/pkg/util/json"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// AuditEventExpansion expands the default CRUD interface for AuditEvent.
+type AuditEventExpansion interface {
+	PatchUpdate(auditEvent *v1.AuditEvent) (result *v1.AuditEvent, err error)
+}
+
+// PatchUpdate takes the representation of a auditEvent and updates using Patch generating a JSON patch to do so.
+// Returns the server's representation of the auditEvent, and an error, if there is any.
+func (c *auditEvents) PatchUpdate(auditEvent *v1.AuditEvent) (*v1.AuditEvent, error) {
+	resourceName := auditEvent.ObjectMeta.Name
+
+	// force retrieval from cache
+	options := metav1.GetOptions{ResourceVersion: "0"}
+	orig, err := c.Get(resourceName, options)
+	if err != nil {
+		return nil, err
+	}
+
+	patch, err := util.CreatePatch(orig, auditEvent)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.Equal(patch, []byte("[]")) {
+		return orig, nil
+	}
+	patched, err := c.Patch(resourceName, types.JSONPatchType, patch)
+	if err != nil {
+		return nil, err
+	}
+
+	return patched, nil
+}
@@ -0,0 +1,9 @@
+package fake
+
+import v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+
+// PatchUpdate takes the representation of a auditEvent and updates using Patch generating a JSON patch to do so.
+// Returns the server's representation of the auditEvent, and an error, if there is any.
+func (c *FakeAuditEvents) PatchUpdate(app *v1.AuditEvent) (*v1.AuditEvent, error) {
+	return c.Update(app)
+}
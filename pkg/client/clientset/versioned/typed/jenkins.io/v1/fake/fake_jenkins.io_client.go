@@ -16,6 +16,10 @@ func (c *FakeJenkinsV1) Apps(namespace string) v1.AppInterface {
 	return &FakeApps{c, namespace}
 }
 
+func (c *FakeJenkinsV1) AuditEvents(namespace string) v1.AuditEventInterface {
+	return &FakeAuditEvents{c, namespace}
+}
+
 func (c *FakeJenkinsV1) BuildPacks(namespace string) v1.BuildPackInterface {
 	return &FakeBuildPacks{c, namespace}
 }
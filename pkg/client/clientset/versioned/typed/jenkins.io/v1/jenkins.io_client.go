@@ -12,6 +12,7 @@ import (
 type JenkinsV1Interface interface {
 	RESTClient() rest.Interface
 	AppsGetter
+	AuditEventsGetter
 	BuildPacksGetter
 	CommitStatusesGetter
 	EnvironmentsGetter
@@ -40,6 +41,10 @@ func (c *JenkinsV1Client) Apps(namespace string) AppInterface {
 	return newApps(c, namespace)
 }
 
+func (c *JenkinsV1Client) AuditEvents(namespace string) AuditEventInterface {
+	return newAuditEvents(c, namespace)
+}
+
 func (c *JenkinsV1Client) BuildPacks(namespace string) BuildPackInterface {
 	return newBuildPacks(c, namespace)
 }
@@ -39,6 +39,8 @@ func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource
 	// Group=jenkins.io, Version=v1
 	case v1.SchemeGroupVersion.WithResource("apps"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Jenkins().V1().Apps().Informer()}, nil
+	case v1.SchemeGroupVersion.WithResource("auditevents"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Jenkins().V1().AuditEvents().Informer()}, nil
 	case v1.SchemeGroupVersion.WithResource("buildpacks"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Jenkins().V1().BuildPacks().Informer()}, nil
 	case v1.SchemeGroupVersion.WithResource("commitstatuses"):
@@ -10,6 +10,8 @@ import (
 type Interface interface {
 	// Apps returns a AppInformer.
 	Apps() AppInformer
+	// AuditEvents returns a AuditEventInformer.
+	AuditEvents() AuditEventInformer
 	// BuildPacks returns a BuildPackInformer.
 	BuildPacks() BuildPackInformer
 	// CommitStatuses returns a CommitStatusInformer.
@@ -62,6 +64,11 @@ func (v *version) Apps() AppInformer {
 	return &appInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
 
+// AuditEvents returns a AuditEventInformer.
+func (v *version) AuditEvents() AuditEventInformer {
+	return &auditEventInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
 // BuildPacks returns a BuildPackInformer.
 func (v *version) BuildPacks() BuildPackInformer {
 	return &buildPackInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
@@ -10,6 +10,14 @@ type AppListerExpansion interface{}
 // AppNamespaceLister.
 type AppNamespaceListerExpansion interface{}
 
+// AuditEventListerExpansion allows custom methods to be added to
+// AuditEventLister.
+type AuditEventListerExpansion interface{}
+
+// AuditEventNamespaceListerExpansion allows custom methods to be added to
+// AuditEventNamespaceLister.
+type AuditEventNamespaceListerExpansion interface{}
+
 // BuildPackListerExpansion allows custom methods to be added to
 // BuildPackLister.
 type BuildPackListerExpansion interface{}
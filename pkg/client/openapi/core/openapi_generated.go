@@ -15,10 +15,18 @@ func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenA
 	return map[string]common.OpenAPIDefinition{
 		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.AccountReference":                    schema_pkg_apis_jenkinsio_v1_AccountReference(ref),
 		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.App":                                 schema_pkg_apis_jenkinsio_v1_App(ref),
+		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.AppCompatibility":                    schema_pkg_apis_jenkinsio_v1_AppCompatibility(ref),
+		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.AppHook":                             schema_pkg_apis_jenkinsio_v1_AppHook(ref),
+		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.AppHookStatus":                       schema_pkg_apis_jenkinsio_v1_AppHookStatus(ref),
 		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.AppList":                             schema_pkg_apis_jenkinsio_v1_AppList(ref),
 		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.AppSpec":                             schema_pkg_apis_jenkinsio_v1_AppSpec(ref),
+		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.AppStatus":                           schema_pkg_apis_jenkinsio_v1_AppStatus(ref),
 		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.Approve":                             schema_pkg_apis_jenkinsio_v1_Approve(ref),
 		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.Attachment":                          schema_pkg_apis_jenkinsio_v1_Attachment(ref),
+		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.AuditEvent":                          schema_pkg_apis_jenkinsio_v1_AuditEvent(ref),
+		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.AuditEventList":                      schema_pkg_apis_jenkinsio_v1_AuditEventList(ref),
+		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.AuditEventSpec":                      schema_pkg_apis_jenkinsio_v1_AuditEventSpec(ref),
+		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.AuditSettings":                       schema_pkg_apis_jenkinsio_v1_AuditSettings(ref),
 		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.BatchPipelineActivity":               schema_pkg_apis_jenkinsio_v1_BatchPipelineActivity(ref),
 		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.Binary":                              schema_pkg_apis_jenkinsio_v1_Binary(ref),
 		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.BranchProtectionContextPolicy":       schema_pkg_apis_jenkinsio_v1_BranchProtectionContextPolicy(ref),
@@ -26,7 +34,10 @@ func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenA
 		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.BuildPack":                           schema_pkg_apis_jenkinsio_v1_BuildPack(ref),
 		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.BuildPackList":                       schema_pkg_apis_jenkinsio_v1_BuildPackList(ref),
 		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.BuildPackSpec":                       schema_pkg_apis_jenkinsio_v1_BuildPackSpec(ref),
+		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.BuildPodPolicy":                      schema_pkg_apis_jenkinsio_v1_BuildPodPolicy(ref),
 		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.ChartRef":                            schema_pkg_apis_jenkinsio_v1_ChartRef(ref),
+		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.CloudEventSink":                      schema_pkg_apis_jenkinsio_v1_CloudEventSink(ref),
+		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.CloudEventsSettings":                 schema_pkg_apis_jenkinsio_v1_CloudEventsSettings(ref),
 		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.CommitStatus":                        schema_pkg_apis_jenkinsio_v1_CommitStatus(ref),
 		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.CommitStatusCommitReference":         schema_pkg_apis_jenkinsio_v1_CommitStatusCommitReference(ref),
 		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.CommitStatusDetails":                 schema_pkg_apis_jenkinsio_v1_CommitStatusDetails(ref),
@@ -76,11 +87,14 @@ func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenA
 		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.GitServiceSpec":                      schema_pkg_apis_jenkinsio_v1_GitServiceSpec(ref),
 		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.GitStatus":                           schema_pkg_apis_jenkinsio_v1_GitStatus(ref),
 		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.GlobalProtectionPolicy":              schema_pkg_apis_jenkinsio_v1_GlobalProtectionPolicy(ref),
+		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.ImagePromotionSettings":              schema_pkg_apis_jenkinsio_v1_ImagePromotionSettings(ref),
+		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.ImageScanPolicy":                     schema_pkg_apis_jenkinsio_v1_ImageScanPolicy(ref),
 		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.IssueLabel":                          schema_pkg_apis_jenkinsio_v1_IssueLabel(ref),
 		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.IssueSummary":                        schema_pkg_apis_jenkinsio_v1_IssueSummary(ref),
 		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.JobBase":                             schema_pkg_apis_jenkinsio_v1_JobBase(ref),
 		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.Lgtm":                                schema_pkg_apis_jenkinsio_v1_Lgtm(ref),
 		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.Measurement":                         schema_pkg_apis_jenkinsio_v1_Measurement(ref),
+		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.MergeQueue":                          schema_pkg_apis_jenkinsio_v1_MergeQueue(ref),
 		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.Merger":                              schema_pkg_apis_jenkinsio_v1_Merger(ref),
 		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.Original":                            schema_pkg_apis_jenkinsio_v1_Original(ref),
 		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.Periodic":                            schema_pkg_apis_jenkinsio_v1_Periodic(ref),
@@ -90,6 +104,7 @@ func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenA
 		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.PipelineActivitySpec":                schema_pkg_apis_jenkinsio_v1_PipelineActivitySpec(ref),
 		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.PipelineActivityStatus":              schema_pkg_apis_jenkinsio_v1_PipelineActivityStatus(ref),
 		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.PipelineActivityStep":                schema_pkg_apis_jenkinsio_v1_PipelineActivityStep(ref),
+		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.PipelineConcurrency":                 schema_pkg_apis_jenkinsio_v1_PipelineConcurrency(ref),
 		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.PipelineExtension":                   schema_pkg_apis_jenkinsio_v1_PipelineExtension(ref),
 		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.PipelineStructure":                   schema_pkg_apis_jenkinsio_v1_PipelineStructure(ref),
 		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.PipelineStructureList":               schema_pkg_apis_jenkinsio_v1_PipelineStructureList(ref),
@@ -107,8 +122,11 @@ func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenA
 		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.PromotePullRequestStep":              schema_pkg_apis_jenkinsio_v1_PromotePullRequestStep(ref),
 		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.PromoteUpdateStep":                   schema_pkg_apis_jenkinsio_v1_PromoteUpdateStep(ref),
 		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.PromoteWorkflowStep":                 schema_pkg_apis_jenkinsio_v1_PromoteWorkflowStep(ref),
+		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.PromotionWindow":                     schema_pkg_apis_jenkinsio_v1_PromotionWindow(ref),
+		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.PromotionWindowSettings":             schema_pkg_apis_jenkinsio_v1_PromotionWindowSettings(ref),
 		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.ProtectionPolicies":                  schema_pkg_apis_jenkinsio_v1_ProtectionPolicies(ref),
 		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.ProtectionPolicy":                    schema_pkg_apis_jenkinsio_v1_ProtectionPolicy(ref),
+		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.ProvenancePolicy":                    schema_pkg_apis_jenkinsio_v1_ProvenancePolicy(ref),
 		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.PullRequestInfo":                     schema_pkg_apis_jenkinsio_v1_PullRequestInfo(ref),
 		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.Query":                               schema_pkg_apis_jenkinsio_v1_Query(ref),
 		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.QuickStartLocation":                  schema_pkg_apis_jenkinsio_v1_QuickStartLocation(ref),
@@ -138,11 +156,13 @@ func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenA
 		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.StageActivityStep":                   schema_pkg_apis_jenkinsio_v1_StageActivityStep(ref),
 		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.Statement":                           schema_pkg_apis_jenkinsio_v1_Statement(ref),
 		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.StorageLocation":                     schema_pkg_apis_jenkinsio_v1_StorageLocation(ref),
+		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.TagPolicy":                           schema_pkg_apis_jenkinsio_v1_TagPolicy(ref),
 		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.Team":                                schema_pkg_apis_jenkinsio_v1_Team(ref),
 		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.TeamList":                            schema_pkg_apis_jenkinsio_v1_TeamList(ref),
 		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.TeamSettings":                        schema_pkg_apis_jenkinsio_v1_TeamSettings(ref),
 		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.TeamSpec":                            schema_pkg_apis_jenkinsio_v1_TeamSpec(ref),
 		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.TeamStatus":                          schema_pkg_apis_jenkinsio_v1_TeamStatus(ref),
+		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.TestSummary":                         schema_pkg_apis_jenkinsio_v1_TestSummary(ref),
 		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.Trigger":                             schema_pkg_apis_jenkinsio_v1_Trigger(ref),
 		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.User":                                schema_pkg_apis_jenkinsio_v1_User(ref),
 		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.UserDetails":                         schema_pkg_apis_jenkinsio_v1_UserDetails(ref),
@@ -216,11 +236,150 @@ func schema_pkg_apis_jenkinsio_v1_App(ref common.ReferenceCallback) common.OpenA
 							Ref: ref("github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.AppSpec"),
 						},
 					},
+					"status": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Status the status of the App such as the results of its hooks",
+							Ref:         ref("github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.AppStatus"),
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.AppSpec", "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.AppStatus", "k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"},
+	}
+}
+
+func schema_pkg_apis_jenkinsio_v1_AppCompatibility(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "AppCompatibility declares the versions an App is compatible with as semantic version constraints such as '>= 1.13, < 1.16'. An empty constraint matches any version",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"kubernetesVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "KubernetesVersion the versions of Kubernetes the App can be installed on",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"platformVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PlatformVersion the versions of the jenkins-x-platform chart the App can be installed with",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_jenkinsio_v1_AppHook(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "AppHook is a pipeline of steps run as a pod when an App is installed or upgraded",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"name": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Name the name of the hook",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"steps": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Steps the containers to run in order. The hook fails if any step fails",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/api/core/v1.Container"),
+									},
+								},
+							},
+						},
+					},
+					"serviceAccountName": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ServiceAccountName the service account to run the steps as",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"timeout": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Timeout how long to wait for the hook to complete such as 10m. Defaults to the install timeout",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
 				},
+				Required: []string{"name", "steps"},
 			},
 		},
 		Dependencies: []string{
-			"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.AppSpec", "k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"},
+			"k8s.io/api/core/v1.Container"},
+	}
+}
+
+func schema_pkg_apis_jenkinsio_v1_AppHookStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "AppHookStatus is the result of running a hook of an App",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"name": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"type": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"version": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"status": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"message": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"startedTimestamp": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+					"completedTimestamp": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+				},
+				Required: []string{"name", "type"},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
 	}
 }
 
@@ -293,11 +452,71 @@ func schema_pkg_apis_jenkinsio_v1_AppSpec(ref common.ReferenceCallback) common.O
 							Ref: ref("github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.PipelineExtension"),
 						},
 					},
+					"preInstall": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PreInstall the hooks to run before the App is installed or upgraded",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.AppHook"),
+									},
+								},
+							},
+						},
+					},
+					"postInstall": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PostInstall the hooks to run after the App is installed or upgraded such as database migrations or smoke tests",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.AppHook"),
+									},
+								},
+							},
+						},
+					},
+					"compatibility": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Compatibility the versions of Kubernetes and the Jenkins X platform this version of the App works with",
+							Ref:         ref("github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.AppCompatibility"),
+						},
+					},
 				},
 			},
 		},
 		Dependencies: []string{
-			"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.PipelineExtension", "k8s.io/api/core/v1.Container", "k8s.io/api/rbac/v1.Role"},
+			"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.AppCompatibility", "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.AppHook", "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.PipelineExtension", "k8s.io/api/core/v1.Container", "k8s.io/api/rbac/v1.Role"},
+	}
+}
+
+func schema_pkg_apis_jenkinsio_v1_AppStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "AppStatus is the status of an App",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"hooks": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Hooks the status of the hooks run for the current version of the App",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.AppHookStatus"),
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.AppHookStatus"},
 	}
 }
 
@@ -375,6 +594,219 @@ func schema_pkg_apis_jenkinsio_v1_Attachment(ref common.ReferenceCallback) commo
 	}
 }
 
+func schema_pkg_apis_jenkinsio_v1_AuditEvent(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "AuditEvent records a change which was made by jx so that it can be determined who changed what and when",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"kind": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#types-kinds",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"apiVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#resources",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"metadata": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Standard object's metadata. More info: http://releases.k8s.io/HEAD/docs/devel/api-conventions.md#metadata",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"),
+						},
+					},
+					"spec": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.AuditEventSpec"),
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.AuditEventSpec", "k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"},
+	}
+}
+
+func schema_pkg_apis_jenkinsio_v1_AuditEventList(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "AuditEventList is a list of AuditEvent resources",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"kind": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#types-kinds",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"apiVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#resources",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"metadata": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("k8s.io/apimachinery/pkg/apis/meta/v1.ListMeta"),
+						},
+					},
+					"items": {
+						SchemaProps: spec.SchemaProps{
+							Type: []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.AuditEvent"),
+									},
+								},
+							},
+						},
+					},
+				},
+				Required: []string{"metadata", "items"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.AuditEvent", "k8s.io/apimachinery/pkg/apis/meta/v1.ListMeta"},
+	}
+}
+
+func schema_pkg_apis_jenkinsio_v1_AuditEventSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "AuditEventSpec the details of a change made by jx",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"action": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Action the kind of change such as pr-raised, environment-applied, secret-written or app-upgraded",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"user": {
+						SchemaProps: spec.SchemaProps{
+							Description: "User the user or service account which made the change",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"resource": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Resource the resource which was changed such as a pull request URL, an environment, a secret or an app",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"namespace": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Namespace the namespace of the change if any",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"message": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Message a description of the change",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"command": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Command the jx command which made the change",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"pipeline": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Pipeline the pipeline which made the change if it was made from a pipeline",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"details": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Details any additional details of the change",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+					"timestamp": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Timestamp when the change was made",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+				},
+				Required: []string{"action"},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
+	}
+}
+
+func schema_pkg_apis_jenkinsio_v1_AuditSettings(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "AuditSettings configures the sinks the audit events of the changes made by jx are recorded to",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"disabled": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Disabled disables recording audit events",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"sinks": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Sinks the sinks the audit events are recorded to. The sinks are crd, bucket and webhook. Defaults to crd",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+					"webhookUrl": {
+						SchemaProps: spec.SchemaProps{
+							Description: "WebhookURL the URL the audit events are posted to by the webhook sink",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
 func schema_pkg_apis_jenkinsio_v1_BatchPipelineActivity(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -528,139 +960,299 @@ func schema_pkg_apis_jenkinsio_v1_BuildPack(ref common.ReferenceCallback) common
 							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"),
 						},
 					},
-					"spec": {
+					"spec": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.BuildPackSpec"),
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.BuildPackSpec", "k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"},
+	}
+}
+
+func schema_pkg_apis_jenkinsio_v1_BuildPackList(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "BuildPackList is a list of TypeMeta resources",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"kind": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#types-kinds",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"apiVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#resources",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"metadata": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("k8s.io/apimachinery/pkg/apis/meta/v1.ListMeta"),
+						},
+					},
+					"items": {
+						SchemaProps: spec.SchemaProps{
+							Type: []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.BuildPack"),
+									},
+								},
+							},
+						},
+					},
+				},
+				Required: []string{"metadata", "items"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.BuildPack", "k8s.io/apimachinery/pkg/apis/meta/v1.ListMeta"},
+	}
+}
+
+func schema_pkg_apis_jenkinsio_v1_BuildPackSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "BuildPackSpec is the specification of an BuildPack",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"label": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"gitUrl": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"gitRef": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"quickstartLocations": {
+						SchemaProps: spec.SchemaProps{
+							Type: []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.QuickStartLocation"),
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.QuickStartLocation"},
+	}
+}
+
+func schema_pkg_apis_jenkinsio_v1_BuildPodPolicy(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "BuildPodPolicy the defaults applied to the build pods of the pipelines of a kind",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"pipelineKind": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PipelineKind the kind of pipeline such as release, pullrequest or feature. Applies to all kinds if empty",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"resources": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Resources the default CPU and memory requests and limits of the steps of the build pods",
+							Ref:         ref("k8s.io/api/core/v1.ResourceRequirements"),
+						},
+					},
+					"nodeSelector": {
+						SchemaProps: spec.SchemaProps{
+							Description: "NodeSelector the node selector of the build pods",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+					"tolerations": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Tolerations the tolerations of the build pods",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/api/core/v1.Toleration"),
+									},
+								},
+							},
+						},
+					},
+					"securityContext": {
+						SchemaProps: spec.SchemaProps{
+							Description: "SecurityContext the default security context of the steps of the build pods",
+							Ref:         ref("k8s.io/api/core/v1.SecurityContext"),
+						},
+					},
+					"spot": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Spot schedules the build pods on the spot/preemptible build node pool when it has capacity",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"preemptionRetries": {
 						SchemaProps: spec.SchemaProps{
-							Ref: ref("github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.BuildPackSpec"),
+							Description: "PreemptionRetries the number of times the tasks of a pipeline running on spot nodes are retried if they fail",
+							Type:        []string{"integer"},
+							Format:      "int32",
 						},
 					},
 				},
 			},
 		},
 		Dependencies: []string{
-			"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.BuildPackSpec", "k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"},
+			"k8s.io/api/core/v1.ResourceRequirements", "k8s.io/api/core/v1.SecurityContext", "k8s.io/api/core/v1.Toleration"},
 	}
 }
 
-func schema_pkg_apis_jenkinsio_v1_BuildPackList(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_jenkinsio_v1_ChartRef(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "BuildPackList is a list of TypeMeta resources",
-				Type:        []string{"object"},
+				Type: []string{"object"},
 				Properties: map[string]spec.Schema{
-					"kind": {
-						SchemaProps: spec.SchemaProps{
-							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#types-kinds",
-							Type:        []string{"string"},
-							Format:      "",
-						},
-					},
-					"apiVersion": {
+					"repo": {
 						SchemaProps: spec.SchemaProps{
-							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#resources",
-							Type:        []string{"string"},
-							Format:      "",
+							Type:   []string{"string"},
+							Format: "",
 						},
 					},
-					"metadata": {
+					"repoName": {
 						SchemaProps: spec.SchemaProps{
-							Ref: ref("k8s.io/apimachinery/pkg/apis/meta/v1.ListMeta"),
+							Type:   []string{"string"},
+							Format: "",
 						},
 					},
-					"items": {
+					"name": {
 						SchemaProps: spec.SchemaProps{
-							Type: []string{"array"},
-							Items: &spec.SchemaOrArray{
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Ref: ref("github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.BuildPack"),
-									},
-								},
-							},
+							Type:   []string{"string"},
+							Format: "",
 						},
 					},
 				},
-				Required: []string{"metadata", "items"},
 			},
 		},
-		Dependencies: []string{
-			"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.BuildPack", "k8s.io/apimachinery/pkg/apis/meta/v1.ListMeta"},
+		Dependencies: []string{},
 	}
 }
 
-func schema_pkg_apis_jenkinsio_v1_BuildPackSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_jenkinsio_v1_CloudEventSink(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "BuildPackSpec is the specification of an BuildPack",
+				Description: "CloudEventSink a sink the CloudEvents are sent to",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"label": {
+					"kind": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
+							Description: "Kind the kind of the sink which is http, kafka or knative",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"gitUrl": {
+					"url": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
+							Description: "URL the URL the events are posted to. For kafka sinks the URL of the Kafka REST proxy. Defaults to the broker ingress of the dev namespace for knative sinks",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"gitRef": {
+					"topic": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
+							Description: "Topic the Kafka topic the events are published to",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"quickstartLocations": {
+					"broker": {
 						SchemaProps: spec.SchemaProps{
-							Type: []string{"array"},
+							Description: "Broker the name of the Knative broker. Defaults to default",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"types": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Types the types of the events sent to the sink. All the events are sent if empty",
+							Type:        []string{"array"},
 							Items: &spec.SchemaOrArray{
 								Schema: &spec.Schema{
 									SchemaProps: spec.SchemaProps{
-										Ref: ref("github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.QuickStartLocation"),
+										Type:   []string{"string"},
+										Format: "",
 									},
 								},
 							},
 						},
 					},
 				},
+				Required: []string{"kind"},
 			},
 		},
-		Dependencies: []string{
-			"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.QuickStartLocation"},
+		Dependencies: []string{},
 	}
 }
 
-func schema_pkg_apis_jenkinsio_v1_ChartRef(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_jenkinsio_v1_CloudEventsSettings(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Type: []string{"object"},
+				Description: "CloudEventsSettings configures the sinks the lifecycle events published by the controllers as CloudEvents are sent to",
+				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"repo": {
-						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
-						},
-					},
-					"repoName": {
-						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
-						},
-					},
-					"name": {
+					"sinks": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
+							Description: "Sinks the sinks the events are sent to",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.CloudEventSink"),
+									},
+								},
+							},
 						},
 					},
 				},
 			},
 		},
-		Dependencies: []string{},
+		Dependencies: []string{
+			"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.CloudEventSink"},
 	}
 }
 
@@ -1723,11 +2315,23 @@ func schema_pkg_apis_jenkinsio_v1_EnvironmentSpec(ref common.ReferenceCallback)
 							Format:      "",
 						},
 					},
+					"promotionWindows": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PromotionWindows restricts when applications can be promoted to the Environment",
+							Ref:         ref("github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.PromotionWindowSettings"),
+						},
+					},
+					"imagePromotion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ImagePromotion copies the images of the applications promoted to the Environment to its own container registry",
+							Ref:         ref("github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.ImagePromotionSettings"),
+						},
+					},
 				},
 			},
 		},
 		Dependencies: []string{
-			"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.EnvironmentRepository", "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.PreviewGitSpec", "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.TeamSettings"},
+			"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.EnvironmentRepository", "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.ImagePromotionSettings", "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.PreviewGitSpec", "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.PromotionWindowSettings", "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.TeamSettings"},
 	}
 }
 
@@ -1744,10 +2348,58 @@ func schema_pkg_apis_jenkinsio_v1_EnvironmentStatus(ref common.ReferenceCallback
 							Format: "",
 						},
 					},
+					"syncStatus": {
+						SchemaProps: spec.SchemaProps{
+							Description: "SyncStatus the status of the last apply of the git repository of the environment",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"lastAttemptedCommit": {
+						SchemaProps: spec.SchemaProps{
+							Description: "LastAttemptedCommit the commit of the git repository of the environment which was last applied or is being applied",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"lastAttemptedTime": {
+						SchemaProps: spec.SchemaProps{
+							Description: "LastAttemptedTime the time the last apply started",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+					"lastAppliedCommit": {
+						SchemaProps: spec.SchemaProps{
+							Description: "LastAppliedCommit the commit of the git repository of the environment which was last applied successfully",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"lastAppliedTime": {
+						SchemaProps: spec.SchemaProps{
+							Description: "LastAppliedTime the time the last successful apply completed",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+					"message": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Message describes why the last apply failed",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"driftedResources": {
+						SchemaProps: spec.SchemaProps{
+							Description: "DriftedResources the number of applications whose running version differs from the git repository of the environment after the last apply",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
 				},
 			},
 		},
-		Dependencies: []string{},
+		Dependencies: []string{
+			"k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
 	}
 }
 
@@ -2826,6 +3478,105 @@ func schema_pkg_apis_jenkinsio_v1_GlobalProtectionPolicy(ref common.ReferenceCal
 	}
 }
 
+func schema_pkg_apis_jenkinsio_v1_ImagePromotionSettings(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "ImagePromotionSettings configures copying the images of the applications promoted to an Environment from the registry of the development environment to the registry of the Environment",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"registry": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Registry the host of the container registry of the Environment such as prod.azurecr.io",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"organisation": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Organisation the organisation of the images in the registry of the Environment. Defaults to the organisation of the images in the registry of the development environment",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"repositoryValue": {
+						SchemaProps: spec.SchemaProps{
+							Description: "RepositoryValue the path of the helm value of the image repository of the applications. Defaults to image.repository",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"registry"},
+			},
+		},
+		Dependencies: []string{},
+	}
+}
+
+func schema_pkg_apis_jenkinsio_v1_ImageScanPolicy(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "ImageScanPolicy the severity thresholds applied to the vulnerabilities found by scanning the images built by the pipelines. The severities are UNKNOWN, LOW, MEDIUM, HIGH and CRITICAL",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"failSeverity": {
+						SchemaProps: spec.SchemaProps{
+							Description: "FailSeverity the minimum severity of the vulnerabilities which fail the scan. The scan never fails if empty",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"warnSeverity": {
+						SchemaProps: spec.SchemaProps{
+							Description: "WarnSeverity the minimum severity of the vulnerabilities which are reported as warnings. Defaults to LOW",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"ignoreUnfixed": {
+						SchemaProps: spec.SchemaProps{
+							Description: "IgnoreUnfixed ignores the vulnerabilities which have no fixed version yet",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"ignoredVulnerabilities": {
+						SchemaProps: spec.SchemaProps{
+							Description: "IgnoredVulnerabilities the IDs of the vulnerabilities which have been accepted such as CVE-2019-1234",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+					"protectedEnvironments": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ProtectedEnvironments the environments images with unresolved critical vulnerabilities cannot be promoted to",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{},
+	}
+}
+
 func schema_pkg_apis_jenkinsio_v1_IssueLabel(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -3072,19 +3823,47 @@ func schema_pkg_apis_jenkinsio_v1_Measurement(ref common.ReferenceCallback) comm
 					},
 					"tags": {
 						SchemaProps: spec.SchemaProps{
-							Type: []string{"array"},
-							Items: &spec.SchemaOrArray{
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Type:   []string{"string"},
-										Format: "",
-									},
-								},
-							},
+							Type: []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+				},
+				Required: []string{"name", "measurementType", "measurementValue"},
+			},
+		},
+		Dependencies: []string{},
+	}
+}
+
+func schema_pkg_apis_jenkinsio_v1_MergeQueue(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "MergeQueue defines how the approved pull requests of a repository are tested together in batches before they are merged",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"batchSizeLimit": {
+						SchemaProps: spec.SchemaProps{
+							Description: "BatchSizeLimit is the maximum number of pull requests tested together in a batch. 0 means no limit and 1 disables batching so that pull requests are tested and merged one at a time.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"comment": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Comment enables the comments on the pull requests of a batch when its tests start and complete. Defaults to true.",
+							Type:        []string{"boolean"},
+							Format:      "",
 						},
 					},
 				},
-				Required: []string{"name", "measurementType", "measurementValue"},
 			},
 		},
 		Dependencies: []string{},
@@ -3146,11 +3925,17 @@ func schema_pkg_apis_jenkinsio_v1_Merger(ref common.ReferenceCallback) common.Op
 							Ref:         ref("github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.ContextPolicy"),
 						},
 					},
+					"mergeQueue": {
+						SchemaProps: spec.SchemaProps{
+							Description: "MergeQueue defines how approved pull requests are tested together in batches before they are merged",
+							Ref:         ref("github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.MergeQueue"),
+						},
+					},
 				},
 			},
 		},
 		Dependencies: []string{
-			"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.ContextPolicy"},
+			"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.ContextPolicy", "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.MergeQueue"},
 	}
 }
 
@@ -3545,11 +4330,22 @@ func schema_pkg_apis_jenkinsio_v1_PipelineActivitySpec(ref common.ReferenceCallb
 							Format: "",
 						},
 					},
+					"message": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"testSummary": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.TestSummary"),
+						},
+					},
 				},
 			},
 		},
 		Dependencies: []string{
-			"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.Attachment", "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.BatchPipelineActivity", "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.ExtensionExecution", "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.PipelineActivityStep", "k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
+			"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.Attachment", "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.BatchPipelineActivity", "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.ExtensionExecution", "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.PipelineActivityStep", "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.TestSummary", "k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
 	}
 }
 
@@ -3609,6 +4405,55 @@ func schema_pkg_apis_jenkinsio_v1_PipelineActivityStep(ref common.ReferenceCallb
 	}
 }
 
+func schema_pkg_apis_jenkinsio_v1_PipelineConcurrency(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "PipelineConcurrency limits the number of PipelineRuns running at the same time. The PipelineRuns which cannot start are queued until a running PipelineRun completes",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"maxRuns": {
+						SchemaProps: spec.SchemaProps{
+							Description: "MaxRuns the maximum number of PipelineRuns running in the cluster. Unlimited if 0",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"maxRunsPerRepository": {
+						SchemaProps: spec.SchemaProps{
+							Description: "MaxRunsPerRepository the maximum number of PipelineRuns running for a repository. Unlimited if 0",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"priorities": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Priorities the priorities of the kinds of pipeline such as release or pullrequest. Queued pipelines with a higher priority start first. Release pipelines default to DefaultReleasePipelinePriority",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"integer"},
+										Format: "int32",
+									},
+								},
+							},
+						},
+					},
+					"preempt": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Preempt cancels the running pipelines with a lower priority when a queued pipeline cannot start",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{},
+	}
+}
+
 func schema_pkg_apis_jenkinsio_v1_PipelineExtension(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -4537,6 +5382,90 @@ func schema_pkg_apis_jenkinsio_v1_PromoteWorkflowStep(ref common.ReferenceCallba
 	}
 }
 
+func schema_pkg_apis_jenkinsio_v1_PromotionWindow(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "PromotionWindow a period of time which either recurs weekly such as from 'Fri 16:00' to 'Mon 09:00' or happens once between two RFC 3339 timestamps such as from '2019-12-20T18:00:00Z' to '2020-01-06T09:00:00Z'",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"name": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Name the description of the window such as 'weekend' or 'end of year freeze'",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"start": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Start the start of the window",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"end": {
+						SchemaProps: spec.SchemaProps{
+							Description: "End the end of the window",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"start", "end"},
+			},
+		},
+		Dependencies: []string{},
+	}
+}
+
+func schema_pkg_apis_jenkinsio_v1_PromotionWindowSettings(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "PromotionWindowSettings restricts promotions to an Environment to release train windows and blocks them during freezes",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"timeZone": {
+						SchemaProps: spec.SchemaProps{
+							Description: "TimeZone the IANA time zone of the weekly windows such as Europe/London. Defaults to UTC",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"allowed": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Allowed the windows promotions are allowed in such as a weekly release train. Promotions are allowed at any time outside of the freezes if empty",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.PromotionWindow"),
+									},
+								},
+							},
+						},
+					},
+					"freezes": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Freezes the windows promotions are not allowed in",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.PromotionWindow"),
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.PromotionWindow"},
+	}
+}
+
 func schema_pkg_apis_jenkinsio_v1_ProtectionPolicies(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -4622,6 +5551,48 @@ func schema_pkg_apis_jenkinsio_v1_ProtectionPolicy(ref common.ReferenceCallback)
 	}
 }
 
+func schema_pkg_apis_jenkinsio_v1_ProvenancePolicy(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "ProvenancePolicy the requirements on the signed SLSA provenance generated by the release pipelines",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"protectedEnvironments": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ProtectedEnvironments the environments which releases without valid signed provenance cannot be promoted to",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+					"trustedBuilders": {
+						SchemaProps: spec.SchemaProps{
+							Description: "TrustedBuilders the builder identities the provenance must have been produced by. Any builder if empty",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{},
+	}
+}
+
 func schema_pkg_apis_jenkinsio_v1_PullRequestInfo(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -4746,6 +5717,13 @@ func schema_pkg_apis_jenkinsio_v1_QuickStartLocation(ref common.ReferenceCallbac
 							},
 						},
 					},
+					"catalog": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Catalog the name of a repository of the owner containing a quickstarts.yml catalog of versioned quickstarts. If specified the quickstarts are loaded from the catalog rather than from the repositories of the owner",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
 				},
 			},
 		},
@@ -5918,6 +6896,34 @@ func schema_pkg_apis_jenkinsio_v1_StorageLocation(ref common.ReferenceCallback)
 	}
 }
 
+func schema_pkg_apis_jenkinsio_v1_TagPolicy(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "TagPolicy the consistency checks of the git tags, charts and images of the releases",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"protectedEnvironments": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ProtectedEnvironments the environments which releases whose git tag, chart version and image tag do not refer to the same commit cannot be promoted to",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{},
+	}
+}
+
 func schema_pkg_apis_jenkinsio_v1_Team(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -6247,11 +7253,60 @@ func schema_pkg_apis_jenkinsio_v1_TeamSettings(ref common.ReferenceCallback) com
 							Format:      "",
 						},
 					},
+					"buildPodPolicies": {
+						SchemaProps: spec.SchemaProps{
+							Description: "BuildPodPolicies the default resources, node selectors, tolerations and security context of the build pods",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.BuildPodPolicy"),
+									},
+								},
+							},
+						},
+					},
+					"pipelineConcurrency": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PipelineConcurrency limits the number of pipelines running at the same time, queueing the others",
+							Ref:         ref("github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.PipelineConcurrency"),
+						},
+					},
+					"imageScanPolicy": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ImageScanPolicy the severity thresholds of the vulnerability scans of the images built by the pipelines",
+							Ref:         ref("github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.ImageScanPolicy"),
+						},
+					},
+					"audit": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Audit configures where the changes made by jx are recorded",
+							Ref:         ref("github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.AuditSettings"),
+						},
+					},
+					"provenancePolicy": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ProvenancePolicy the environments which require the releases promoted to them to have signed build provenance",
+							Ref:         ref("github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.ProvenancePolicy"),
+						},
+					},
+					"tagPolicy": {
+						SchemaProps: spec.SchemaProps{
+							Description: "TagPolicy the environments which require the git tag, chart and image of the releases promoted to them to refer to the same commit",
+							Ref:         ref("github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.TagPolicy"),
+						},
+					},
+					"cloudEvents": {
+						SchemaProps: spec.SchemaProps{
+							Description: "CloudEvents the sinks the lifecycle events of the pipelines, releases, promotions and previews are published to",
+							Ref:         ref("github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.CloudEventsSettings"),
+						},
+					},
 				},
 			},
 		},
 		Dependencies: []string{
-			"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.QuickStartLocation", "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.ResourceReference", "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.StorageLocation", "k8s.io/api/batch/v1.Job"},
+			"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.AuditSettings", "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.BuildPodPolicy", "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.CloudEventsSettings", "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.ImageScanPolicy", "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.PipelineConcurrency", "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.ProvenancePolicy", "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.QuickStartLocation", "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.ResourceReference", "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.StorageLocation", "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.TagPolicy", "k8s.io/api/batch/v1.Job"},
 	}
 }
 
@@ -6320,6 +7375,51 @@ func schema_pkg_apis_jenkinsio_v1_TeamStatus(ref common.ReferenceCallback) commo
 	}
 }
 
+func schema_pkg_apis_jenkinsio_v1_TestSummary(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "TestSummary contains the number of tests run by a pipeline and where the full test report is stored",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"tests": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"integer"},
+							Format: "int32",
+						},
+					},
+					"passed": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"integer"},
+							Format: "int32",
+						},
+					},
+					"failed": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"integer"},
+							Format: "int32",
+						},
+					},
+					"skipped": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"integer"},
+							Format: "int32",
+						},
+					},
+					"reportURL": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+				},
+				Required: []string{"tests", "passed", "failed", "skipped"},
+			},
+		},
+		Dependencies: []string{},
+	}
+}
+
 func schema_pkg_apis_jenkinsio_v1_Trigger(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -1,6 +1,7 @@
 package amazon
 
 import (
+	"encoding/base64"
 	"fmt"
 	"regexp"
 	"strings"
@@ -16,6 +17,7 @@ import (
 	"github.com/aws/aws-sdk-go/service/sts"
 	"github.com/jenkins-x/jx/pkg/log"
 	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
 )
 
 // GetAccountIDAndRegion returns the current account ID and region
@@ -128,3 +130,32 @@ func LazyCreateRegistry(kube kubernetes.Interface, namespace string, region stri
 	}
 	return nil
 }
+
+// GetECRAuthorizationToken returns the user name and password to login to the ECR registry of the region. The
+// credentials of the session are used so that IAM roles for service accounts work in the build pods
+func GetECRAuthorizationToken(region string) (string, string, error) {
+	sess, err := session.NewAwsSession("", region)
+	if err != nil {
+		return "", "", err
+	}
+	svc := ecr.New(sess)
+	result, err := svc.GetAuthorizationToken(&ecr.GetAuthorizationTokenInput{})
+	if err != nil {
+		return "", "", errors.Wrap(err, "failed to get the ECR authorization token")
+	}
+	for _, data := range result.AuthorizationData {
+		if data.AuthorizationToken == nil {
+			continue
+		}
+		decoded, err := base64.StdEncoding.DecodeString(*data.AuthorizationToken)
+		if err != nil {
+			return "", "", errors.Wrap(err, "failed to decode the ECR authorization token")
+		}
+		values := strings.SplitN(string(decoded), ":", 2)
+		if len(values) != 2 {
+			return "", "", fmt.Errorf("the ECR authorization token is not of the form user:password")
+		}
+		return values[0], values[1], nil
+	}
+	return "", "", fmt.Errorf("no ECR authorization token returned for region %s", region)
+}
@@ -0,0 +1,212 @@
+package amazon
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/eks"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/route53"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/jenkins-x/jx/pkg/cloud"
+	"github.com/jenkins-x/jx/pkg/cloud/amazon/session"
+	"github.com/pkg/errors"
+)
+
+// GetCallerAccountID returns the AWS account ID of the current credentials
+func GetCallerAccountID(profile string, region string) (string, error) {
+	sess, err := session.NewAwsSession(profile, region)
+	if err != nil {
+		return "", err
+	}
+	result, err := sts.New(sess).GetCallerIdentity(&sts.GetCallerIdentityInput{})
+	if err != nil {
+		return "", errors.Wrap(err, "getting the caller identity")
+	}
+	if result.Account == nil {
+		return "", fmt.Errorf("could not find the AWS account ID")
+	}
+	return *result.Account, nil
+}
+
+// S3BucketAvailable returns true if the bucket does not exist or is owned by the current account so it can be used
+func S3BucketAvailable(bucketName string, profile string, region string) (bool, error) {
+	sess, err := session.NewAwsSession(profile, region)
+	if err != nil {
+		return false, err
+	}
+	_, err = s3.New(sess).HeadBucket(&s3.HeadBucketInput{
+		Bucket: aws.String(bucketName),
+	})
+	if err == nil {
+		return true, nil
+	}
+	if reqErr, ok := err.(awserr.RequestFailure); ok {
+		switch reqErr.StatusCode() {
+		case http.StatusNotFound:
+			return true, nil
+		case http.StatusForbidden, http.StatusMovedPermanently:
+			return false, nil
+		}
+	}
+	return false, errors.Wrapf(err, "checking bucket %s", bucketName)
+}
+
+// HostedZoneExists returns true if there is a Route 53 hosted zone for the domain or its parent domain
+func HostedZoneExists(domain string, profile string, region string) (bool, error) {
+	sess, err := session.NewAwsSession(profile, region)
+	if err != nil {
+		return false, err
+	}
+	domain = strings.TrimSuffix(domain, ".")
+	names := []string{domain + "."}
+	if idx := strings.Index(domain, "."); idx > 0 {
+		names = append(names, domain[idx+1:]+".")
+	}
+	found := false
+	err = route53.New(sess).ListHostedZonesPages(&route53.ListHostedZonesInput{}, func(page *route53.ListHostedZonesOutput, lastPage bool) bool {
+		for _, zone := range page.HostedZones {
+			for _, name := range names {
+				if zone != nil && aws.StringValue(zone.Name) == name {
+					found = true
+					return false
+				}
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return false, errors.Wrap(err, "listing the Route 53 hosted zones")
+	}
+	return found, nil
+}
+
+// ClusterOIDCProviderExists returns true if the EKS cluster has an OIDC issuer registered as an IAM OIDC provider
+// which is required to use IAM roles for service accounts
+func ClusterOIDCProviderExists(clusterName string, profile string, region string) (bool, error) {
+	sess, err := session.NewAwsSession(profile, region)
+	if err != nil {
+		return false, err
+	}
+	result, err := eks.New(sess).DescribeCluster(&eks.DescribeClusterInput{
+		Name: aws.String(clusterName),
+	})
+	if err != nil {
+		return false, errors.Wrapf(err, "describing EKS cluster %s", clusterName)
+	}
+	if result.Cluster == nil || result.Cluster.Identity == nil || result.Cluster.Identity.Oidc == nil {
+		return false, nil
+	}
+	issuer := strings.TrimPrefix(aws.StringValue(result.Cluster.Identity.Oidc.Issuer), "https://")
+	if issuer == "" {
+		return false, nil
+	}
+	providers, err := iam.New(sess).ListOpenIDConnectProviders(&iam.ListOpenIDConnectProvidersInput{})
+	if err != nil {
+		return false, errors.Wrap(err, "listing the IAM OIDC providers")
+	}
+	for _, provider := range providers.OpenIDConnectProviderList {
+		if provider != nil && strings.HasSuffix(aws.StringValue(provider.Arn), "oidc-provider/"+issuer) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// SimulatePermissions returns the subset of the IAM actions which the current credentials are allowed to perform using
+// the IAM policy simulator so that no resources are created
+func SimulatePermissions(actions []string, profile string, region string) ([]string, error) {
+	sess, err := session.NewAwsSession(profile, region)
+	if err != nil {
+		return nil, err
+	}
+	identity, err := sts.New(sess).GetCallerIdentity(&sts.GetCallerIdentityInput{})
+	if err != nil {
+		return nil, errors.Wrap(err, "getting the caller identity")
+	}
+	principal := principalARN(aws.StringValue(identity.Arn))
+	allowed := []string{}
+	err = iam.New(sess).SimulatePrincipalPolicyPages(&iam.SimulatePrincipalPolicyInput{
+		PolicySourceArn: aws.String(principal),
+		ActionNames:     aws.StringSlice(actions),
+	}, func(page *iam.SimulatePolicyResponse, lastPage bool) bool {
+		for _, result := range page.EvaluationResults {
+			if result != nil && aws.StringValue(result.EvalDecision) == iam.PolicyEvaluationDecisionTypeAllowed {
+				allowed = append(allowed, aws.StringValue(result.EvalActionName))
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "simulating the IAM policies of %s", principal)
+	}
+	return allowed, nil
+}
+
+// EC2Quotas returns the EC2 account limits of the region for instances and elastic IP addresses with their usage
+func EC2Quotas(profile string, region string) ([]cloud.Quota, error) {
+	sess, err := session.NewAwsSession(profile, region)
+	if err != nil {
+		return nil, err
+	}
+	svc := ec2.New(sess)
+	attributes, err := svc.DescribeAccountAttributes(&ec2.DescribeAccountAttributesInput{
+		AttributeNames: aws.StringSlice([]string{"max-instances", "vpc-max-elastic-ips"}),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "describing the EC2 account attributes")
+	}
+	instances := 0
+	err = svc.DescribeInstancesPages(&ec2.DescribeInstancesInput{
+		Filters: []*ec2.Filter{{Name: aws.String("instance-state-name"), Values: aws.StringSlice([]string{"pending", "running"})}},
+	}, func(page *ec2.DescribeInstancesOutput, lastPage bool) bool {
+		for _, r := range page.Reservations {
+			if r != nil {
+				instances += len(r.Instances)
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "describing the EC2 instances")
+	}
+	addresses, err := svc.DescribeAddresses(&ec2.DescribeAddressesInput{
+		Filters: []*ec2.Filter{{Name: aws.String("domain"), Values: aws.StringSlice([]string{"vpc"})}},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "describing the elastic IP addresses")
+	}
+	usage := map[string]float64{
+		"max-instances":       float64(instances),
+		"vpc-max-elastic-ips": float64(len(addresses.Addresses)),
+	}
+	answer := []cloud.Quota{}
+	for _, attribute := range attributes.AccountAttributes {
+		if attribute == nil || len(attribute.AttributeValues) == 0 || attribute.AttributeValues[0] == nil {
+			continue
+		}
+		name := aws.StringValue(attribute.AttributeName)
+		limit, err := strconv.ParseFloat(aws.StringValue(attribute.AttributeValues[0].AttributeValue), 64)
+		if err != nil {
+			continue
+		}
+		answer = append(answer, cloud.Quota{Metric: name, Limit: limit, Usage: usage[name]})
+	}
+	return answer, nil
+}
+
+// principalARN converts the ARN of an assumed role session to the ARN of the role which the IAM policy simulator expects
+func principalARN(arn string) string {
+	parts := strings.Split(arn, ":")
+	if len(parts) != 6 || parts[2] != "sts" || !strings.HasPrefix(parts[5], "assumed-role/") {
+		return arn
+	}
+	names := strings.Split(strings.TrimPrefix(parts[5], "assumed-role/"), "/")
+	return fmt.Sprintf("arn:%s:iam::%s:role/%s", parts[1], parts[4], names[0])
+}
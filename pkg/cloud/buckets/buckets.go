@@ -3,6 +3,7 @@ package buckets
 import (
 	"context"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/url"
 	"strings"
@@ -125,6 +126,43 @@ func WriteBucket(bucketURL string, key string, data []byte, timeout time.Duratio
 	return nil
 }
 
+// ReadBucket reads the data of the key in a bucket URL of the form 's3://bucketName' with the given timeout
+func ReadBucket(bucketURL string, key string, timeout time.Duration) ([]byte, error) {
+	ctx, _ := context.WithTimeout(context.Background(), timeout)
+	bucket, err := blob.Open(ctx, bucketURL)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to open bucket %s", bucketURL)
+	}
+	data, err := bucket.ReadAll(ctx, key)
+	if err != nil {
+		return data, errors.Wrapf(err, "failed to read key %s in bucket %s", key, bucketURL)
+	}
+	return data, nil
+}
+
+// ListBucket returns the keys in a bucket URL of the form 's3://bucketName' which start with the given prefix
+func ListBucket(bucketURL string, prefix string, timeout time.Duration) ([]string, error) {
+	ctx, _ := context.WithTimeout(context.Background(), timeout)
+	bucket, err := blob.Open(ctx, bucketURL)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to open bucket %s", bucketURL)
+	}
+	keys := []string{}
+	iter := bucket.List(&blob.ListOptions{Prefix: prefix})
+	for {
+		obj, err := iter.Next(ctx)
+		if err == io.EOF {
+			return keys, nil
+		}
+		if err != nil {
+			return keys, errors.Wrapf(err, "failed to list keys with prefix %s in bucket %s", prefix, bucketURL)
+		}
+		if !obj.IsDir {
+			keys = append(keys, obj.Key)
+		}
+	}
+}
+
 // SplitBucketURL splits the full bucket URL into the URL to open the bucket and the file name to refer to
 // within the bucket
 func SplitBucketURL(u *url.URL) (string, string) {
@@ -20,10 +20,25 @@ const (
 	ICP        = "icp"
 	JX_INFRA   = "jx-infra"
 	ALIBABA    = "alibaba"
+	KIND       = "kind"
+	K3D        = "k3d"
 )
 
 // KubernetesProviders list of all available Kubernetes providers
-var KubernetesProviders = []string{MINIKUBE, GKE, OKE, AKS, AWS, EKS, KUBERNETES, IKS, OPENSHIFT, MINISHIFT, JX_INFRA, PKS, ICP, ALIBABA}
+var KubernetesProviders = []string{MINIKUBE, GKE, OKE, AKS, AWS, EKS, KUBERNETES, IKS, OPENSHIFT, MINISHIFT, JX_INFRA, PKS, ICP, ALIBABA, KIND, K3D}
+
+// LocalProviders the Kubernetes providers which run a cluster on the local machine
+var LocalProviders = []string{MINIKUBE, MINISHIFT, KIND, K3D}
+
+// IsLocal returns true if the Kubernetes provider runs a cluster on the local machine
+func IsLocal(provider string) bool {
+	for _, p := range LocalProviders {
+		if p == provider {
+			return true
+		}
+	}
+	return false
+}
 
 // KubernetesProviderOptions returns all the Kubernetes providers as a string
 func KubernetesProviderOptions() string {
@@ -2,9 +2,11 @@ package gke
 
 import (
 	"bufio"
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"net/http"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -14,6 +16,7 @@ import (
 
 	osUser "os/user"
 
+	"github.com/jenkins-x/jx/pkg/cloud"
 	"github.com/jenkins-x/jx/pkg/log"
 	"github.com/jenkins-x/jx/pkg/util"
 	"github.com/pkg/errors"
@@ -1450,6 +1453,77 @@ type project struct {
 	ProjectNumber string `json:"projectNumber"`
 }
 
+// TestPermissions returns the subset of the permissions which the current credentials are granted on the project
+// using the testIamPermissions API so that no resources are created
+func (g *GCloud) TestPermissions(projectID string, permissions []string) ([]string, error) {
+	if projectID == "" {
+		return nil, errors.New("cannot test permissions without a projectId")
+	}
+	cmd := util.Command{
+		Name: "gcloud",
+		Args: []string{"auth", "print-access-token"},
+	}
+	token, err := cmd.RunWithoutRetry()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get an access token")
+	}
+	body, err := json.Marshal(map[string][]string{"permissions": permissions})
+	if err != nil {
+		return nil, err
+	}
+	u := fmt.Sprintf("https://cloudresourcemanager.googleapis.com/v1/projects/%s:testIamPermissions", projectID)
+	req, err := http.NewRequest(http.MethodPost, u, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(token))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to test the permissions on project %s", projectID)
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to test the permissions on project %s: status %d %s", projectID, resp.StatusCode, string(data))
+	}
+	result := struct {
+		Permissions []string `json:"permissions"`
+	}{}
+	err = json.Unmarshal(data, &result)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal %s", string(data))
+	}
+	return result.Permissions, nil
+}
+
+// RegionQuotas returns the compute quotas of the region in the project
+func (g *GCloud) RegionQuotas(projectID string, region string) ([]cloud.Quota, error) {
+	args := []string{"compute", "regions", "describe", region, "--format=json"}
+	if projectID != "" {
+		args = append(args, "--project", projectID)
+	}
+	cmd := util.Command{
+		Name: "gcloud",
+		Args: args,
+	}
+	output, err := cmd.RunWithoutRetry()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to describe region %s", region)
+	}
+	result := struct {
+		Quotas []cloud.Quota `json:"quotas"`
+	}{}
+	err = json.Unmarshal([]byte(output), &result)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal %s", output)
+	}
+	return result.Quotas, nil
+}
+
 func addDomainSuffix(domain string) string {
 	if domain[len(domain)-1:] != "." {
 		return fmt.Sprintf("%s.", domain)
@@ -1,6 +1,9 @@
 package gke
 
-import "k8s.io/client-go/kubernetes"
+import (
+	"github.com/jenkins-x/jx/pkg/cloud"
+	"k8s.io/client-go/kubernetes"
+)
 
 // GClouder interface to define interactions with the gcloud command
 //go:generate pegomock generate github.com/jenkins-x/jx/pkg/cloud/gke GClouder -o mocks/gclouder.go
@@ -29,6 +32,8 @@ type GClouder interface {
 	EnableAPIs(projectID string, apis ...string) error
 	Login(serviceAccountKeyPath string, skipLogin bool) error
 	CheckPermission(perm string, projectID string) (bool, error)
+	TestPermissions(projectID string, permissions []string) ([]string, error)
+	RegionQuotas(projectID string, region string) ([]cloud.Quota, error)
 	CreateKmsKeyring(keyringName string, projectID string) error
 	IsKmsKeyringAvailable(keyringName string, projectID string) bool
 	CreateKmsKey(keyName string, keyringName string, projectID string) error
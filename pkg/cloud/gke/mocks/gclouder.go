@@ -7,6 +7,7 @@ import (
 	"reflect"
 	"time"
 
+	cloud "github.com/jenkins-x/jx/pkg/cloud"
 	gke "github.com/jenkins-x/jx/pkg/cloud/gke"
 	pegomock "github.com/petergtz/pegomock"
 	kubernetes "k8s.io/client-go/kubernetes"
@@ -578,6 +579,44 @@ func (mock *MockGClouder) Login(_param0 string, _param1 bool) error {
 	return ret0
 }
 
+func (mock *MockGClouder) RegionQuotas(_param0 string, _param1 string) ([]cloud.Quota, error) {
+	if mock == nil {
+		panic("mock must not be nil. Use myMock := NewMockGClouder().")
+	}
+	params := []pegomock.Param{_param0, _param1}
+	result := pegomock.GetGenericMockFrom(mock).Invoke("RegionQuotas", params, []reflect.Type{reflect.TypeOf((*[]cloud.Quota)(nil)).Elem(), reflect.TypeOf((*error)(nil)).Elem()})
+	var ret0 []cloud.Quota
+	var ret1 error
+	if len(result) != 0 {
+		if result[0] != nil {
+			ret0 = result[0].([]cloud.Quota)
+		}
+		if result[1] != nil {
+			ret1 = result[1].(error)
+		}
+	}
+	return ret0, ret1
+}
+
+func (mock *MockGClouder) TestPermissions(_param0 string, _param1 []string) ([]string, error) {
+	if mock == nil {
+		panic("mock must not be nil. Use myMock := NewMockGClouder().")
+	}
+	params := []pegomock.Param{_param0, _param1}
+	result := pegomock.GetGenericMockFrom(mock).Invoke("TestPermissions", params, []reflect.Type{reflect.TypeOf((*[]string)(nil)).Elem(), reflect.TypeOf((*error)(nil)).Elem()})
+	var ret0 []string
+	var ret1 error
+	if len(result) != 0 {
+		if result[0] != nil {
+			ret0 = result[0].([]string)
+		}
+		if result[1] != nil {
+			ret1 = result[1].(error)
+		}
+	}
+	return ret0, ret1
+}
+
 func (mock *MockGClouder) UpdateGkeClusterLabels(_param0 string, _param1 string, _param2 string, _param3 []string) error {
 	if mock == nil {
 		panic("mock must not be nil. Use myMock := NewMockGClouder().")
@@ -1728,6 +1767,68 @@ func (c *MockGClouder_Login_OngoingVerification) GetAllCapturedArguments() (_par
 	return
 }
 
+func (verifier *VerifierMockGClouder) RegionQuotas(_param0 string, _param1 string) *MockGClouder_RegionQuotas_OngoingVerification {
+	params := []pegomock.Param{_param0, _param1}
+	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "RegionQuotas", params, verifier.timeout)
+	return &MockGClouder_RegionQuotas_OngoingVerification{mock: verifier.mock, methodInvocations: methodInvocations}
+}
+
+type MockGClouder_RegionQuotas_OngoingVerification struct {
+	mock              *MockGClouder
+	methodInvocations []pegomock.MethodInvocation
+}
+
+func (c *MockGClouder_RegionQuotas_OngoingVerification) GetCapturedArguments() (string, string) {
+	_param0, _param1 := c.GetAllCapturedArguments()
+	return _param0[len(_param0)-1], _param1[len(_param1)-1]
+}
+
+func (c *MockGClouder_RegionQuotas_OngoingVerification) GetAllCapturedArguments() (_param0 []string, _param1 []string) {
+	params := pegomock.GetGenericMockFrom(c.mock).GetInvocationParams(c.methodInvocations)
+	if len(params) > 0 {
+		_param0 = make([]string, len(c.methodInvocations))
+		for u, param := range params[0] {
+			_param0[u] = param.(string)
+		}
+		_param1 = make([]string, len(c.methodInvocations))
+		for u, param := range params[1] {
+			_param1[u] = param.(string)
+		}
+	}
+	return
+}
+
+func (verifier *VerifierMockGClouder) TestPermissions(_param0 string, _param1 []string) *MockGClouder_TestPermissions_OngoingVerification {
+	params := []pegomock.Param{_param0, _param1}
+	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "TestPermissions", params, verifier.timeout)
+	return &MockGClouder_TestPermissions_OngoingVerification{mock: verifier.mock, methodInvocations: methodInvocations}
+}
+
+type MockGClouder_TestPermissions_OngoingVerification struct {
+	mock              *MockGClouder
+	methodInvocations []pegomock.MethodInvocation
+}
+
+func (c *MockGClouder_TestPermissions_OngoingVerification) GetCapturedArguments() (string, []string) {
+	_param0, _param1 := c.GetAllCapturedArguments()
+	return _param0[len(_param0)-1], _param1[len(_param1)-1]
+}
+
+func (c *MockGClouder_TestPermissions_OngoingVerification) GetAllCapturedArguments() (_param0 []string, _param1 [][]string) {
+	params := pegomock.GetGenericMockFrom(c.mock).GetInvocationParams(c.methodInvocations)
+	if len(params) > 0 {
+		_param0 = make([]string, len(c.methodInvocations))
+		for u, param := range params[0] {
+			_param0[u] = param.(string)
+		}
+		_param1 = make([][]string, len(c.methodInvocations))
+		for u, param := range params[1] {
+			_param1[u] = param.([]string)
+		}
+	}
+	return
+}
+
 func (verifier *VerifierMockGClouder) UpdateGkeClusterLabels(_param0 string, _param1 string, _param2 string, _param3 []string) *MockGClouder_UpdateGkeClusterLabels_OngoingVerification {
 	params := []pegomock.Param{_param0, _param1, _param2, _param3}
 	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "UpdateGkeClusterLabels", params, verifier.timeout)
@@ -0,0 +1,63 @@
+package preflight
+
+import (
+	"fmt"
+
+	"github.com/jenkins-x/jx/pkg/versionstream"
+	"github.com/pkg/errors"
+)
+
+const (
+	// KubernetesClusterPackage the name of the package in the version stream which defines the versions of kubernetes
+	// clusters which are supported. A provider specific package such as 'kubernetesCluster-gke' takes precedence
+	KubernetesClusterPackage = "kubernetesCluster"
+)
+
+// CheckKubernetesVersion checks the version of the kubernetes cluster is at least the version of the kubernetes cluster
+// package of the provider in the version stream and earlier than its upper limit
+func CheckKubernetesVersion(versionsDir string, provider string, serverVersion string) (Result, error) {
+	r := Result{Check: "kubernetes version"}
+	var data *versionstream.StableVersion
+	for _, name := range []string{KubernetesClusterPackage + "-" + provider, KubernetesClusterPackage} {
+		var err error
+		data, err = versionstream.LoadStableVersion(versionsDir, versionstream.KindPackage, name)
+		if err != nil {
+			return r, err
+		}
+		if data.Version != "" {
+			break
+		}
+	}
+	if data.Version == "" {
+		r.Passed = true
+		r.Message = "the version stream does not specify the supported kubernetes versions"
+		return r, nil
+	}
+	current, err := versionstream.ParseKubernetesVersion(serverVersion)
+	if err != nil {
+		return r, err
+	}
+	minimum, err := versionstream.ParseKubernetesVersion(data.Version)
+	if err != nil {
+		return r, errors.Wrapf(err, "failed to parse the kubernetes version of the version stream")
+	}
+	if current.LT(minimum) {
+		r.Message = fmt.Sprintf("the cluster is on version %s but the version stream requires at least %s", current, minimum)
+		r.Remediation = fmt.Sprintf("Upgrade the cluster to kubernetes %s or later", minimum)
+		return r, nil
+	}
+	if data.UpperLimit != "" {
+		limit, err := versionstream.ParseKubernetesVersion(data.UpperLimit)
+		if err != nil {
+			return r, errors.Wrapf(err, "failed to parse the kubernetes upper limit of the version stream")
+		}
+		if current.GE(limit) {
+			r.Message = fmt.Sprintf("the cluster is on version %s but the version stream supports versions earlier than %s", current, limit)
+			r.Remediation = fmt.Sprintf("Use a cluster with a kubernetes version earlier than %s or upgrade the version stream", limit)
+			return r, nil
+		}
+	}
+	r.Passed = true
+	r.Message = fmt.Sprintf("the cluster is on the supported version %s", current)
+	return r, nil
+}
@@ -0,0 +1,303 @@
+package preflight
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jenkins-x/jx/pkg/cloud"
+	"github.com/jenkins-x/jx/pkg/cloud/amazon"
+	"github.com/jenkins-x/jx/pkg/cloud/gke"
+	"github.com/jenkins-x/jx/pkg/config"
+	"github.com/pkg/errors"
+)
+
+// Result the outcome of a preflight check
+type Result struct {
+	// Check the name of the check such as 'permission storage.buckets.create'
+	Check string
+	// Passed the check passed
+	Passed bool
+	// Message describes the outcome of the check
+	Message string
+	// Remediation describes how to fix a failed check
+	Remediation string
+}
+
+// FailedError the error returned when preflight checks fail
+type FailedError struct {
+	Results []Result
+}
+
+// Error lists the failed checks and how to fix them
+func (e *FailedError) Error() string {
+	lines := []string{fmt.Sprintf("%d preflight checks failed:", len(e.Results))}
+	for _, r := range e.Results {
+		line := fmt.Sprintf("  %s: %s", r.Check, r.Message)
+		if r.Remediation != "" {
+			line += ". " + r.Remediation
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Failed returns a FailedError for the failed results or nil if all of the checks passed
+func Failed(results []Result) error {
+	failed := []Result{}
+	for _, r := range results {
+		if !r.Passed {
+			failed = append(failed, r)
+		}
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+	return &FailedError{Results: failed}
+}
+
+// Prober probes the prerequisites of the cloud provider of a cluster without creating any resources
+type Prober interface {
+	// TestPermissions returns the subset of the permissions which the current credentials are granted
+	TestPermissions(permissions []string) ([]string, error)
+	// Quotas returns the quotas of the region of the cluster
+	Quotas() ([]cloud.Quota, error)
+}
+
+// Permission a cloud permission required to boot Jenkins X
+type Permission struct {
+	// Name the name of the permission such as 'storage.buckets.create' or 's3:CreateBucket'
+	Name string
+	// Reason why the permission is required
+	Reason string
+}
+
+// QuotaRequirement the headroom a quota must have to boot Jenkins X
+type QuotaRequirement struct {
+	// Metric the name of the quota such as 'INSTANCES'
+	Metric string
+	// Required the amount of the resource which must be available
+	Required float64
+	// Reason why the resource is required
+	Reason string
+}
+
+// Options the options of the preflight checks
+type Options struct {
+	// LazyCreate the missing cloud resources are lazily created so the permissions to create them are required
+	LazyCreate bool
+	// Nodes the number of nodes the quotas must have headroom for such as to scale up or surge during upgrades
+	Nodes int
+	// LoadBalancers the number of load balancer IP addresses the quotas must have headroom for
+	LoadBalancers int
+}
+
+// NewProber creates a Prober for the cloud provider of the requirements or returns nil if the provider is not supported
+func NewProber(requirements *config.RequirementsConfig, gcloud gke.GClouder, awsProfile string) Prober {
+	switch requirements.Cluster.Provider {
+	case cloud.GKE:
+		return &gkeProber{gcloud: gcloud, projectID: requirements.Cluster.ProjectID, region: gkeRegion(requirements)}
+	case cloud.EKS:
+		return &eksProber{profile: awsProfile, region: requirements.Cluster.Region}
+	default:
+		return nil
+	}
+}
+
+// RequiredPermissions returns the cloud permissions which are required to boot Jenkins X with the requirements
+func RequiredPermissions(requirements *config.RequirementsConfig, options Options) []Permission {
+	storage := requirements.Storage.Logs.Enabled || requirements.Storage.Reports.Enabled ||
+		requirements.Storage.Repository.Enabled || requirements.Storage.Backup.Enabled
+	externalDNS := requirements.Ingress.ExternalDNS
+	vault := requirements.SecretStorage == config.SecretStorageTypeVault
+	answer := []Permission{}
+	switch requirements.Cluster.Provider {
+	case cloud.GKE:
+		if externalDNS {
+			answer = append(answer,
+				Permission{Name: "dns.changes.create", Reason: "to create the DNS records of the domain"},
+				Permission{Name: "dns.managedZones.list", Reason: "to find the managed zone of the domain"},
+			)
+		}
+		if !options.LazyCreate {
+			break
+		}
+		if storage {
+			answer = append(answer, Permission{Name: "storage.buckets.create", Reason: "to create the storage buckets"})
+		}
+		if storage || externalDNS || vault || requirements.Kaniko || requirements.Velero.Namespace != "" {
+			answer = append(answer,
+				Permission{Name: "iam.serviceAccounts.create", Reason: "to create the service accounts"},
+				Permission{Name: "iam.serviceAccountKeys.create", Reason: "to create the keys of the service accounts"},
+				Permission{Name: "resourcemanager.projects.setIamPolicy", Reason: "to grant roles to the service accounts"},
+			)
+		}
+		if vault {
+			answer = append(answer, Permission{Name: "cloudkms.cryptoKeys.create", Reason: "to create the KMS key of vault"})
+		}
+	case cloud.EKS:
+		if externalDNS {
+			answer = append(answer,
+				Permission{Name: "route53:ChangeResourceRecordSets", Reason: "to create the DNS records of the domain"},
+				Permission{Name: "route53:ListHostedZones", Reason: "to find the hosted zone of the domain"},
+			)
+		}
+		if !options.LazyCreate {
+			break
+		}
+		if storage {
+			answer = append(answer, Permission{Name: "s3:CreateBucket", Reason: "to create the storage buckets"})
+		}
+		if requirements.Cluster.EKSConfig != nil && requirements.Cluster.EKSConfig.IRSA {
+			answer = append(answer,
+				Permission{Name: "cloudformation:CreateStack", Reason: "to create the policies of the service accounts"},
+				Permission{Name: "iam:CreateRole", Reason: "to create the roles of the service accounts"},
+				Permission{Name: "iam:CreatePolicy", Reason: "to create the policies of the service accounts"},
+			)
+		}
+		if vault {
+			answer = append(answer,
+				Permission{Name: "dynamodb:CreateTable", Reason: "to create the storage of vault"},
+				Permission{Name: "kms:CreateKey", Reason: "to create the KMS key of vault"},
+			)
+		}
+	}
+	return answer
+}
+
+// RequiredQuotas returns the quota headroom which is required to boot Jenkins X on the provider
+func RequiredQuotas(provider string, options Options) []QuotaRequirement {
+	nodes := float64(options.Nodes)
+	loadBalancers := float64(options.LoadBalancers)
+	answer := []QuotaRequirement{}
+	switch provider {
+	case cloud.GKE:
+		if nodes > 0 {
+			answer = append(answer, QuotaRequirement{Metric: "INSTANCES", Required: nodes, Reason: "for the nodes"})
+		}
+		if nodes+loadBalancers > 0 {
+			answer = append(answer, QuotaRequirement{Metric: "IN_USE_ADDRESSES", Required: nodes + loadBalancers, Reason: "for the nodes and load balancers"})
+		}
+	case cloud.EKS:
+		if nodes > 0 {
+			answer = append(answer, QuotaRequirement{Metric: "max-instances", Required: nodes, Reason: "for the nodes"})
+		}
+		if loadBalancers > 0 {
+			answer = append(answer, QuotaRequirement{Metric: "vpc-max-elastic-ips", Required: loadBalancers, Reason: "for the load balancers"})
+		}
+	}
+	return answer
+}
+
+// CheckPermissions checks the current credentials are granted the permissions
+func CheckPermissions(prober Prober, permissions []Permission) ([]Result, error) {
+	if len(permissions) == 0 {
+		return nil, nil
+	}
+	names := []string{}
+	for _, p := range permissions {
+		names = append(names, p.Name)
+	}
+	granted, err := prober.TestPermissions(names)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to test the permissions of the current credentials")
+	}
+	grantedSet := map[string]bool{}
+	for _, name := range granted {
+		grantedSet[name] = true
+	}
+	results := []Result{}
+	for _, p := range permissions {
+		r := Result{Check: "permission " + p.Name, Passed: grantedSet[p.Name]}
+		if r.Passed {
+			r.Message = "granted " + p.Reason
+		} else {
+			r.Message = "the current credentials are not granted the permission which is required " + p.Reason
+			r.Remediation = fmt.Sprintf("Grant a role with the %s permission to the current credentials or create the resources up front and disable lazy create", p.Name)
+		}
+		results = append(results, r)
+	}
+	return results, nil
+}
+
+// CheckQuotas checks the quotas have the required headroom
+func CheckQuotas(prober Prober, required []QuotaRequirement) ([]Result, error) {
+	if len(required) == 0 {
+		return nil, nil
+	}
+	quotas, err := prober.Quotas()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to find the quotas")
+	}
+	quotaMap := map[string]cloud.Quota{}
+	for _, q := range quotas {
+		quotaMap[q.Metric] = q
+	}
+	results := []Result{}
+	for _, req := range required {
+		r := Result{Check: "quota " + req.Metric}
+		q, ok := quotaMap[req.Metric]
+		switch {
+		case !ok:
+			r.Passed = true
+			r.Message = "no quota found"
+		case q.Headroom() >= req.Required:
+			r.Passed = true
+			r.Message = fmt.Sprintf("%g of %g used which leaves room for %g more %s", q.Usage, q.Limit, req.Required, req.Reason)
+		default:
+			r.Message = fmt.Sprintf("%g of %g used which does not leave room for %g more %s", q.Usage, q.Limit, req.Required, req.Reason)
+			r.Remediation = fmt.Sprintf("Request a quota increase of %s to at least %g", req.Metric, q.Usage+req.Required)
+		}
+		results = append(results, r)
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Check < results[j].Check
+	})
+	return results, nil
+}
+
+// gkeRegion returns the region of the cluster defaulting it from the zone
+func gkeRegion(requirements *config.RequirementsConfig) string {
+	if requirements.Cluster.Region != "" {
+		return requirements.Cluster.Region
+	}
+	zone := requirements.Cluster.Zone
+	if idx := strings.LastIndex(zone, "-"); idx > 0 {
+		return zone[:idx]
+	}
+	return zone
+}
+
+type gkeProber struct {
+	gcloud    gke.GClouder
+	projectID string
+	region    string
+}
+
+// TestPermissions tests the permissions on the project
+func (p *gkeProber) TestPermissions(permissions []string) ([]string, error) {
+	return p.gcloud.TestPermissions(p.projectID, permissions)
+}
+
+// Quotas returns the compute quotas of the region
+func (p *gkeProber) Quotas() ([]cloud.Quota, error) {
+	if p.region == "" {
+		return nil, errors.New("no region or zone in the requirements")
+	}
+	return p.gcloud.RegionQuotas(p.projectID, p.region)
+}
+
+type eksProber struct {
+	profile string
+	region  string
+}
+
+// TestPermissions simulates the IAM actions
+func (p *eksProber) TestPermissions(permissions []string) ([]string, error) {
+	return amazon.SimulatePermissions(permissions, p.profile, p.region)
+}
+
+// Quotas returns the EC2 account limits of the region
+func (p *eksProber) Quotas() ([]cloud.Quota, error) {
+	return amazon.EC2Quotas(p.profile, p.region)
+}
@@ -0,0 +1,123 @@
+package preflight_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x/jx/pkg/cloud"
+	"github.com/jenkins-x/jx/pkg/cloud/preflight"
+	"github.com/jenkins-x/jx/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeProber struct {
+	granted []string
+	quotas  []cloud.Quota
+}
+
+func (p *fakeProber) TestPermissions(permissions []string) ([]string, error) {
+	return p.granted, nil
+}
+
+func (p *fakeProber) Quotas() ([]cloud.Quota, error) {
+	return p.quotas, nil
+}
+
+func TestRequiredPermissions(t *testing.T) {
+	t.Parallel()
+
+	requirements := config.NewRequirementsConfig()
+	requirements.Cluster.Provider = cloud.GKE
+	requirements.Ingress.ExternalDNS = true
+	requirements.Storage.Logs.Enabled = true
+
+	names := func(permissions []preflight.Permission) []string {
+		answer := []string{}
+		for _, p := range permissions {
+			answer = append(answer, p.Name)
+		}
+		return answer
+	}
+
+	assert.Equal(t, []string{"dns.changes.create", "dns.managedZones.list"}, names(preflight.RequiredPermissions(requirements, preflight.Options{})))
+
+	lazy := names(preflight.RequiredPermissions(requirements, preflight.Options{LazyCreate: true}))
+	assert.Contains(t, lazy, "storage.buckets.create")
+	assert.Contains(t, lazy, "iam.serviceAccounts.create")
+
+	requirements.Cluster.Provider = cloud.EKS
+	assert.Contains(t, names(preflight.RequiredPermissions(requirements, preflight.Options{LazyCreate: true})), "s3:CreateBucket")
+
+	requirements.Cluster.Provider = cloud.KIND
+	assert.Empty(t, preflight.RequiredPermissions(requirements, preflight.Options{LazyCreate: true}))
+}
+
+func TestCheckPermissionsAndQuotas(t *testing.T) {
+	t.Parallel()
+
+	prober := &fakeProber{
+		granted: []string{"dns.changes.create"},
+		quotas: []cloud.Quota{
+			{Metric: "INSTANCES", Limit: 24, Usage: 3},
+			{Metric: "IN_USE_ADDRESSES", Limit: 8, Usage: 7},
+		},
+	}
+
+	results, err := preflight.CheckPermissions(prober, []preflight.Permission{
+		{Name: "dns.changes.create", Reason: "to create the DNS records of the domain"},
+		{Name: "storage.buckets.create", Reason: "to create the storage buckets"},
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.True(t, results[0].Passed)
+	assert.False(t, results[1].Passed)
+	assert.Contains(t, results[1].Remediation, "storage.buckets.create")
+
+	quotaResults, err := preflight.CheckQuotas(prober, preflight.RequiredQuotas(cloud.GKE, preflight.Options{Nodes: 1, LoadBalancers: 1}))
+	require.NoError(t, err)
+	require.Len(t, quotaResults, 2)
+	assert.Equal(t, "quota INSTANCES", quotaResults[0].Check)
+	assert.True(t, quotaResults[0].Passed)
+	assert.Equal(t, "quota IN_USE_ADDRESSES", quotaResults[1].Check)
+	assert.False(t, quotaResults[1].Passed)
+	assert.Equal(t, "Request a quota increase of IN_USE_ADDRESSES to at least 9", quotaResults[1].Remediation)
+
+	err = preflight.Failed(append(results, quotaResults...))
+	require.Error(t, err)
+	failed, ok := err.(*preflight.FailedError)
+	require.True(t, ok)
+	assert.Len(t, failed.Results, 2)
+	assert.Nil(t, preflight.Failed(quotaResults[:1]))
+}
+
+func TestCheckKubernetesVersion(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "test-check-kubernetes-version")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	r, err := preflight.CheckKubernetesVersion(dir, cloud.GKE, "v1.13.11-gke.14")
+	require.NoError(t, err)
+	assert.True(t, r.Passed)
+
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "packages"), 0700))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "packages", "kubernetesCluster.yml"), []byte("version: 1.12.0\nupperLimit: 1.16.0\n"), 0600))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "packages", "kubernetesCluster-gke.yml"), []byte("version: 1.14.0\nupperLimit: 1.16.0\n"), 0600))
+
+	r, err = preflight.CheckKubernetesVersion(dir, cloud.GKE, "v1.13.11-gke.14")
+	require.NoError(t, err)
+	assert.False(t, r.Passed)
+	assert.Equal(t, "Upgrade the cluster to kubernetes 1.14.0 or later", r.Remediation)
+
+	r, err = preflight.CheckKubernetesVersion(dir, cloud.EKS, "v1.13.11-eks-5876d6")
+	require.NoError(t, err)
+	assert.True(t, r.Passed)
+
+	r, err = preflight.CheckKubernetesVersion(dir, cloud.EKS, "v1.16.2")
+	require.NoError(t, err)
+	assert.False(t, r.Passed)
+}
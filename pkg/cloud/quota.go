@@ -0,0 +1,16 @@
+package cloud
+
+// Quota the limit and current usage of a quota of a cloud resource such as the number of VM instances in a region
+type Quota struct {
+	// Metric the name of the quota such as 'INSTANCES'
+	Metric string `json:"metric"`
+	// Limit the maximum amount of the resource
+	Limit float64 `json:"limit"`
+	// Usage the amount of the resource which is currently used
+	Usage float64 `json:"usage"`
+}
+
+// Headroom returns the amount of the resource which can still be used
+func (q *Quota) Headroom() float64 {
+	return q.Limit - q.Usage
+}
@@ -0,0 +1,256 @@
+package cloudevents
+
+import (
+	"time"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/util"
+)
+
+const (
+	// SpecVersion the version of the CloudEvents specification of the events
+	SpecVersion = "1.0"
+
+	// TypePipelineStarted the event published when the first step of a pipeline starts
+	TypePipelineStarted = "io.jenkins-x.pipeline.started"
+	// TypePipelineFinished the event published when a pipeline succeeds, fails or is aborted
+	TypePipelineFinished = "io.jenkins-x.pipeline.finished"
+	// TypeReleaseCreated the event published when a Release is created for a new version of an application
+	TypeReleaseCreated = "io.jenkins-x.release.created"
+	// TypePromotionMerged the event published when the promotion Pull Request of a version is merged
+	TypePromotionMerged = "io.jenkins-x.promotion.merged"
+	// TypePreviewCreated the event published when the preview environment of a Pull Request is deployed
+	TypePreviewCreated = "io.jenkins-x.preview.created"
+)
+
+// Types the types of the events published by the controllers
+var Types = []string{TypePipelineStarted, TypePipelineFinished, TypeReleaseCreated, TypePromotionMerged, TypePreviewCreated}
+
+// Event a CloudEvent in the structured JSON format
+type Event struct {
+	SpecVersion     string    `json:"specversion"`
+	ID              string    `json:"id"`
+	Source          string    `json:"source"`
+	Type            string    `json:"type"`
+	Subject         string    `json:"subject,omitempty"`
+	Time            time.Time `json:"time"`
+	DataContentType string    `json:"datacontenttype,omitempty"`
+	Data            *Data     `json:"data,omitempty"`
+}
+
+// Data the data of the events
+type Data struct {
+	Owner          string     `json:"owner,omitempty"`
+	Repository     string     `json:"repository,omitempty"`
+	Branch         string     `json:"branch,omitempty"`
+	Build          string     `json:"build,omitempty"`
+	Version        string     `json:"version,omitempty"`
+	Status         string     `json:"status,omitempty"`
+	Author         string     `json:"author,omitempty"`
+	Environment    string     `json:"environment,omitempty"`
+	PullRequestURL string     `json:"pullRequestURL,omitempty"`
+	MergeCommitSHA string     `json:"mergeCommitSHA,omitempty"`
+	ApplicationURL string     `json:"applicationURL,omitempty"`
+	URL            string     `json:"url,omitempty"`
+	Started        *time.Time `json:"started,omitempty"`
+	Completed      *time.Time `json:"completed,omitempty"`
+}
+
+// Route a sink and the types of the events sent to it
+type Route struct {
+	Sink Sink
+	// Types the types of the events sent to the sink. All the events are sent if empty
+	Types []string
+}
+
+// Matches returns true if the event is sent to the sink of the route
+func (r *Route) Matches(event *Event) bool {
+	return len(r.Types) == 0 || util.StringArrayIndex(r.Types, event.Type) >= 0
+}
+
+// Emitter publishes the events to the sinks of its routes
+type Emitter struct {
+	// Source the source of the events such as /jenkins-x/jx
+	Source string
+	Routes []Route
+
+	now func() time.Time
+}
+
+// NewEmitter creates an emitter of the events of the namespace
+func NewEmitter(ns string, routes ...Route) *Emitter {
+	return &Emitter{
+		Source: "/jenkins-x/" + ns,
+		Routes: routes,
+		now:    time.Now,
+	}
+}
+
+// Emit sends the event to the sinks of the matching routes logging any failure as a warning
+func (e *Emitter) Emit(event *Event) {
+	if e == nil {
+		return
+	}
+	for _, route := range e.Routes {
+		if !route.Matches(event) {
+			continue
+		}
+		err := route.Sink.Send(event)
+		if err != nil {
+			log.Logger().Warnf("Failed to send the %s event %s: %s", event.Type, event.ID, err)
+		}
+	}
+}
+
+// OnActivity publishes the events caused by the change of a PipelineActivity
+func (e *Emitter) OnActivity(old *v1.PipelineActivity, activity *v1.PipelineActivity) {
+	if e == nil || len(e.Routes) == 0 {
+		return
+	}
+	for _, event := range e.ActivityEvents(old, activity) {
+		e.Emit(event)
+	}
+}
+
+// OnRelease publishes the event of the creation of a Release
+func (e *Emitter) OnRelease(release *v1.Release) {
+	if e == nil || len(e.Routes) == 0 {
+		return
+	}
+	e.Emit(e.ReleaseEvent(release))
+}
+
+// ActivityEvents returns the events caused by the change of a PipelineActivity. The old activity is nil if the
+// activity was created. The IDs of the events are derived from the activity so that consumers can ignore the events
+// published again by another replica or after a restart of the controller
+func (e *Emitter) ActivityEvents(old *v1.PipelineActivity, activity *v1.PipelineActivity) []*Event {
+	if old == nil {
+		old = &v1.PipelineActivity{}
+	}
+	spec := &activity.Spec
+	newEvent := func(eventType string, id string) *Event {
+		branch := activity.BranchName()
+		if branch == "" {
+			branch = spec.GitBranch
+		}
+		return e.newEvent(eventType, activity.Name+"/"+id, activity.Name, &Data{
+			Owner:      activity.RepositoryOwner(),
+			Repository: activity.RepositoryName(),
+			Branch:     branch,
+			Build:      spec.Build,
+			Version:    spec.Version,
+			Author:     spec.Author,
+		})
+	}
+
+	answer := []*Event{}
+	if old.Spec.StartedTimestamp == nil && spec.StartedTimestamp != nil {
+		event := newEvent(TypePipelineStarted, "started")
+		event.Data.Started = &spec.StartedTimestamp.Time
+		event.Data.URL = spec.BuildURL
+		answer = append(answer, event)
+	}
+	if !old.Spec.Status.IsTerminated() && spec.Status.IsTerminated() {
+		event := newEvent(TypePipelineFinished, "finished")
+		event.Data.Status = string(spec.Status)
+		event.Data.URL = spec.BuildLogsURL
+		if event.Data.URL == "" {
+			event.Data.URL = spec.BuildURL
+		}
+		if spec.StartedTimestamp != nil {
+			event.Data.Started = &spec.StartedTimestamp.Time
+		}
+		if spec.CompletedTimestamp != nil {
+			event.Data.Completed = &spec.CompletedTimestamp.Time
+		}
+		answer = append(answer, event)
+	}
+
+	oldMerged := mergedPromotions(old)
+	for _, promote := range promoteSteps(activity) {
+		pr := promote.PullRequest
+		if pr == nil || pr.MergeCommitSHA == "" || oldMerged[promote.Environment] != "" {
+			continue
+		}
+		event := newEvent(TypePromotionMerged, "promotion/"+promote.Environment)
+		event.Data.Environment = promote.Environment
+		event.Data.PullRequestURL = pr.PullRequestURL
+		event.Data.MergeCommitSHA = pr.MergeCommitSHA
+		answer = append(answer, event)
+	}
+
+	oldPreviews := previewApplicationURLs(old)
+	for _, step := range spec.Steps {
+		preview := step.Preview
+		if preview == nil || preview.ApplicationURL == "" || oldPreviews[preview.Environment] != "" {
+			continue
+		}
+		event := newEvent(TypePreviewCreated, "preview")
+		event.Data.Environment = preview.Environment
+		event.Data.PullRequestURL = preview.PullRequestURL
+		event.Data.ApplicationURL = preview.ApplicationURL
+		answer = append(answer, event)
+	}
+	return answer
+}
+
+// ReleaseEvent returns the event of the creation of a Release
+func (e *Emitter) ReleaseEvent(release *v1.Release) *Event {
+	spec := &release.Spec
+	return e.newEvent(TypeReleaseCreated, release.Name+"/created", release.Name, &Data{
+		Owner:      spec.GitOwner,
+		Repository: spec.GitRepository,
+		Version:    spec.Version,
+		URL:        spec.ReleaseNotesURL,
+	})
+}
+
+func (e *Emitter) newEvent(eventType string, id string, subject string, data *Data) *Event {
+	now := time.Now
+	if e.now != nil {
+		now = e.now
+	}
+	return &Event{
+		SpecVersion:     SpecVersion,
+		ID:              id,
+		Source:          e.Source,
+		Type:            eventType,
+		Subject:         subject,
+		Time:            now().UTC(),
+		DataContentType: "application/json",
+		Data:            data,
+	}
+}
+
+func promoteSteps(activity *v1.PipelineActivity) []*v1.PromoteActivityStep {
+	answer := []*v1.PromoteActivityStep{}
+	for _, step := range activity.Spec.Steps {
+		if step.Promote != nil {
+			answer = append(answer, step.Promote)
+		}
+	}
+	return answer
+}
+
+// mergedPromotions returns the merge commit SHAs of the merged promotion Pull Requests indexed by environment
+func mergedPromotions(activity *v1.PipelineActivity) map[string]string {
+	answer := map[string]string{}
+	for _, promote := range promoteSteps(activity) {
+		if promote.PullRequest != nil && promote.PullRequest.MergeCommitSHA != "" {
+			answer[promote.Environment] = promote.PullRequest.MergeCommitSHA
+		}
+	}
+	return answer
+}
+
+// previewApplicationURLs returns the URLs of the deployed previews indexed by environment
+func previewApplicationURLs(activity *v1.PipelineActivity) map[string]string {
+	answer := map[string]string{}
+	for _, step := range activity.Spec.Steps {
+		if step.Preview != nil && step.Preview.ApplicationURL != "" {
+			answer[step.Preview.Environment] = step.Preview.ApplicationURL
+		}
+	}
+	return answer
+}
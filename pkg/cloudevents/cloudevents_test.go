@@ -0,0 +1,185 @@
+package cloudevents_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/cloudevents"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestActivityEvents(t *testing.T) {
+	t.Parallel()
+	emitter := cloudevents.NewEmitter("jx")
+
+	pending := &v1.PipelineActivity{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "myorg-myapp-master-1",
+		},
+		Spec: v1.PipelineActivitySpec{
+			GitOwner:      "myorg",
+			GitRepository: "myapp",
+			GitBranch:     "master",
+			Build:         "1",
+			Status:        v1.ActivityStatusTypePending,
+		},
+	}
+	assert.Empty(t, emitter.ActivityEvents(nil, pending))
+
+	running := pending.DeepCopy()
+	started := metav1.NewTime(time.Date(2020, 1, 2, 10, 0, 0, 0, time.UTC))
+	running.Spec.StartedTimestamp = &started
+	running.Spec.Status = v1.ActivityStatusTypeRunning
+	events := emitter.ActivityEvents(pending, running)
+	require.Len(t, events, 1)
+	assert.Equal(t, cloudevents.TypePipelineStarted, events[0].Type)
+	assert.Equal(t, "myorg-myapp-master-1/started", events[0].ID)
+	assert.Equal(t, "/jenkins-x/jx", events[0].Source)
+	assert.Equal(t, "myorg-myapp-master-1", events[0].Subject)
+	assert.Equal(t, "myapp", events[0].Data.Repository)
+
+	promoted := running.DeepCopy()
+	promoted.Spec.Version = "1.0.1"
+	promoted.Spec.Status = v1.ActivityStatusTypeSucceeded
+	promoted.Spec.Steps = []v1.PipelineActivityStep{
+		{
+			Kind: v1.ActivityStepKindTypePromote,
+			Promote: &v1.PromoteActivityStep{
+				Environment: "staging",
+				PullRequest: &v1.PromotePullRequestStep{
+					PullRequestURL: "https://github.com/myorg/environment-staging/pull/3",
+					MergeCommitSHA: "abc123",
+				},
+			},
+		},
+	}
+	events = emitter.ActivityEvents(running, promoted)
+	require.Len(t, events, 2)
+	assert.Equal(t, cloudevents.TypePipelineFinished, events[0].Type)
+	assert.Equal(t, "Succeeded", events[0].Data.Status)
+	assert.Equal(t, cloudevents.TypePromotionMerged, events[1].Type)
+	assert.Equal(t, "myorg-myapp-master-1/promotion/staging", events[1].ID)
+	assert.Equal(t, "staging", events[1].Data.Environment)
+	assert.Equal(t, "abc123", events[1].Data.MergeCommitSHA)
+
+	// unchanged activities publish no events
+	assert.Empty(t, emitter.ActivityEvents(promoted, promoted.DeepCopy()))
+
+	preview := running.DeepCopy()
+	preview.Spec.Steps = []v1.PipelineActivityStep{
+		{
+			Kind: v1.ActivityStepKindTypePreview,
+			Preview: &v1.PreviewActivityStep{
+				Environment:    "myorg-myapp-pr-4",
+				PullRequestURL: "https://github.com/myorg/myapp/pull/4",
+				ApplicationURL: "http://myapp.jx-myorg-myapp-pr-4.example.com",
+			},
+		},
+	}
+	events = emitter.ActivityEvents(running, preview)
+	require.Len(t, events, 1)
+	assert.Equal(t, cloudevents.TypePreviewCreated, events[0].Type)
+	assert.Equal(t, "http://myapp.jx-myorg-myapp-pr-4.example.com", events[0].Data.ApplicationURL)
+}
+
+func TestSinks(t *testing.T) {
+	t.Parallel()
+	requests := map[string]*http.Request{}
+	bodies := map[string][]byte{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		requests[r.URL.Path] = r
+		bodies[r.URL.Path] = body
+	}))
+	defer server.Close()
+
+	routes, err := cloudevents.NewRoutes(&v1.CloudEventsSettings{
+		Sinks: []v1.CloudEventSink{
+			{
+				Kind: cloudevents.SinkHTTP,
+				URL:  server.URL + "/events",
+			},
+			{
+				Kind:  cloudevents.SinkKafka,
+				URL:   server.URL,
+				Topic: "jx-events",
+				Types: []string{cloudevents.TypeReleaseCreated},
+			},
+		},
+	}, "jx", server.Client())
+	require.NoError(t, err)
+	emitter := cloudevents.NewEmitter("jx", routes...)
+
+	emitter.OnRelease(&v1.Release{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "myapp-1.0.1",
+		},
+		Spec: v1.ReleaseSpec{
+			GitOwner:      "myorg",
+			GitRepository: "myapp",
+			Version:       "1.0.1",
+		},
+	})
+
+	req := requests["/events"]
+	require.NotNil(t, req)
+	assert.Equal(t, cloudevents.SpecVersion, req.Header.Get("ce-specversion"))
+	assert.Equal(t, cloudevents.TypeReleaseCreated, req.Header.Get("ce-type"))
+	assert.Equal(t, "myapp-1.0.1/created", req.Header.Get("ce-id"))
+	assert.Equal(t, "/jenkins-x/jx", req.Header.Get("ce-source"))
+	data := &cloudevents.Data{}
+	require.NoError(t, json.Unmarshal(bodies["/events"], data))
+	assert.Equal(t, "1.0.1", data.Version)
+
+	req = requests["/topics/jx-events"]
+	require.NotNil(t, req)
+	assert.Equal(t, "application/vnd.kafka.json.v2+json", req.Header.Get("Content-Type"))
+	records := struct {
+		Records []struct {
+			Key   string            `json:"key"`
+			Value cloudevents.Event `json:"value"`
+		} `json:"records"`
+	}{}
+	require.NoError(t, json.Unmarshal(bodies["/topics/jx-events"], &records))
+	require.Len(t, records.Records, 1)
+	assert.Equal(t, "myapp-1.0.1", records.Records[0].Key)
+	assert.Equal(t, cloudevents.TypeReleaseCreated, records.Records[0].Value.Type)
+	assert.Equal(t, "myapp", records.Records[0].Value.Data.Repository)
+
+	// the kafka sink only receives releases
+	delete(requests, "/topics/jx-events")
+	emitter.Emit(&cloudevents.Event{ID: "1", Type: cloudevents.TypePipelineStarted, Data: &cloudevents.Data{}})
+	assert.Nil(t, requests["/topics/jx-events"])
+}
+
+func TestNewRoutesValidation(t *testing.T) {
+	t.Parallel()
+	routes, err := cloudevents.NewRoutes(&v1.CloudEventsSettings{
+		Sinks: []v1.CloudEventSink{{Kind: cloudevents.SinkKnative}},
+	}, "jx", nil)
+	require.NoError(t, err)
+	require.Len(t, routes, 1)
+	assert.Equal(t, "http://broker-ingress.knative-eventing.svc.cluster.local/jx/default", routes[0].Sink.(*cloudevents.HTTPSink).URL)
+
+	_, err = cloudevents.NewRoutes(&v1.CloudEventsSettings{
+		Sinks: []v1.CloudEventSink{{Kind: cloudevents.SinkKafka, URL: "http://kafka-rest:8082"}},
+	}, "jx", nil)
+	assert.Error(t, err)
+
+	_, err = cloudevents.NewRoutes(&v1.CloudEventsSettings{
+		Sinks: []v1.CloudEventSink{{Kind: "smtp"}},
+	}, "jx", nil)
+	assert.Error(t, err)
+
+	_, err = cloudevents.NewRoutes(&v1.CloudEventsSettings{
+		Sinks: []v1.CloudEventSink{{Kind: cloudevents.SinkHTTP, URL: "http://example.com", Types: []string{"io.jenkins-x.unknown"}}},
+	}, "jx", nil)
+	assert.Error(t, err)
+}
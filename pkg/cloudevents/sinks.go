@@ -0,0 +1,161 @@
+package cloudevents
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+)
+
+const (
+	// SinkHTTP posts the events to a URL in the binary content mode of the CloudEvents HTTP binding
+	SinkHTTP = "http"
+	// SinkKafka publishes the events to a Kafka topic via a Kafka REST proxy in the structured content mode
+	SinkKafka = "kafka"
+	// SinkKnative posts the events to a Knative eventing broker
+	SinkKnative = "knative"
+
+	// DefaultKnativeBroker the name of the Knative broker the events are sent to by default
+	DefaultKnativeBroker = "default"
+
+	kafkaContentType = "application/vnd.kafka.json.v2+json"
+)
+
+// Sinks the kinds of the sinks
+var Sinks = []string{SinkHTTP, SinkKafka, SinkKnative}
+
+// Sink sends events
+type Sink interface {
+	Send(event *Event) error
+}
+
+// HTTPSink posts the events to a URL with the attributes of the event as ce- headers and the data as the body
+type HTTPSink struct {
+	URL    string
+	Client *http.Client
+}
+
+// Send posts the event
+func (s *HTTPSink) Send(event *Event) error {
+	data, err := json.Marshal(event.Data)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal the data of the event %s", event.ID)
+	}
+	req, err := http.NewRequest(http.MethodPost, s.URL, bytes.NewReader(data))
+	if err != nil {
+		return errors.Wrapf(err, "failed to create the request to %s", s.URL)
+	}
+	req.Header.Set("Content-Type", event.DataContentType)
+	req.Header.Set("ce-specversion", event.SpecVersion)
+	req.Header.Set("ce-id", event.ID)
+	req.Header.Set("ce-source", event.Source)
+	req.Header.Set("ce-type", event.Type)
+	req.Header.Set("ce-time", event.Time.Format(time.RFC3339Nano))
+	if event.Subject != "" {
+		req.Header.Set("ce-subject", event.Subject)
+	}
+	return post(s.Client, req)
+}
+
+// KafkaSink publishes the events to a topic of a Kafka REST proxy keyed by their subject
+type KafkaSink struct {
+	URL    string
+	Topic  string
+	Client *http.Client
+}
+
+// Send publishes the event
+func (s *KafkaSink) Send(event *Event) error {
+	payload := map[string]interface{}{
+		"records": []map[string]interface{}{
+			{
+				"key":   event.Subject,
+				"value": event,
+			},
+		},
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal the event %s", event.ID)
+	}
+	u := util.UrlJoin(s.URL, "topics", s.Topic)
+	req, err := http.NewRequest(http.MethodPost, u, bytes.NewReader(data))
+	if err != nil {
+		return errors.Wrapf(err, "failed to create the request to %s", u)
+	}
+	req.Header.Set("Content-Type", kafkaContentType)
+	req.Header.Set("Accept", "application/vnd.kafka.v2+json")
+	return post(s.Client, req)
+}
+
+// KnativeBrokerURL returns the URL of the ingress of the Knative broker in the namespace
+func KnativeBrokerURL(ns string, broker string) string {
+	if broker == "" {
+		broker = DefaultKnativeBroker
+	}
+	return fmt.Sprintf("http://broker-ingress.knative-eventing.svc.cluster.local/%s/%s", ns, broker)
+}
+
+// NewRoutes creates the routes of the sinks configured in the CloudEvents settings of a team whose dev namespace is ns
+func NewRoutes(settings *v1.CloudEventsSettings, ns string, client *http.Client) ([]Route, error) {
+	answer := []Route{}
+	if settings == nil {
+		return answer, nil
+	}
+	if client == nil {
+		client = util.GetClientWithTimeout(10 * time.Second)
+	}
+	for i, config := range settings.Sinks {
+		for _, t := range config.Types {
+			if util.StringArrayIndex(Types, t) < 0 {
+				return nil, fmt.Errorf("unknown event type %s of CloudEvents sink %d. Supported types are: %s", t, i, strings.Join(Types, ", "))
+			}
+		}
+		var sink Sink
+		switch config.Kind {
+		case SinkHTTP:
+			if config.URL == "" {
+				return nil, fmt.Errorf("no URL configured for the %s CloudEvents sink %d", config.Kind, i)
+			}
+			sink = &HTTPSink{URL: config.URL, Client: client}
+		case SinkKnative:
+			u := config.URL
+			if u == "" {
+				u = KnativeBrokerURL(ns, config.Broker)
+			}
+			sink = &HTTPSink{URL: u, Client: client}
+		case SinkKafka:
+			if config.URL == "" || config.Topic == "" {
+				return nil, fmt.Errorf("the %s CloudEvents sink %d requires the URL of the Kafka REST proxy and a topic", config.Kind, i)
+			}
+			sink = &KafkaSink{URL: config.URL, Topic: config.Topic, Client: client}
+		default:
+			return nil, fmt.Errorf("unknown kind %s of CloudEvents sink %d. Supported kinds are: %s", config.Kind, i, strings.Join(Sinks, ", "))
+		}
+		answer = append(answer, Route{Sink: sink, Types: config.Types})
+	}
+	return answer, nil
+}
+
+func post(client *http.Client, req *http.Request) error {
+	if client == nil {
+		client = util.GetClientWithTimeout(10 * time.Second)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return errors.Wrapf(err, "failed to post to %s", req.URL.String())
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("failed to post to %s: status %s: %s", req.URL.String(), resp.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
@@ -53,6 +53,10 @@ const (
 var (
 	add_app_long = templates.LongDesc(`
 		Adds an App to Jenkins X (an app is similar to an addon),
+
+		Apps can declare preInstall and postInstall hooks in their app.yaml, such as database migration Jobs or smoke
+		tests. When not using GitOps the hooks are run by this command, otherwise they are run by the pipeline which
+		applies the environment. The result of each hook is recorded on the status of the App.
 `)
 	add_app_example = templates.Examples(`
 		# Add an app
@@ -0,0 +1,37 @@
+package admin
+
+import (
+	"github.com/jenkins-x/jx/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/pkg/cmd/opts"
+	"github.com/spf13/cobra"
+)
+
+// AdminOptions contains the command line options
+type AdminOptions struct {
+	*opts.CommonOptions
+}
+
+// NewCmdAdmin creates the command
+func NewCmdAdmin(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &AdminOptions{
+		CommonOptions: commonOpts,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "admin",
+		Short: "Commands for the administrators of a Jenkins X installation",
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.AddCommand(NewCmdAdminLog(commonOpts))
+	return cmd
+}
+
+// Run implements this command
+func (o *AdminOptions) Run() error {
+	return o.Cmd.Help()
+}
@@ -0,0 +1,230 @@
+package admin
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/client/clientset/versioned"
+	"github.com/jenkins-x/jx/pkg/cmd/get"
+	"github.com/jenkins-x/jx/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/pkg/gits"
+	"github.com/jenkins-x/jx/pkg/kube"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/logs"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// AdminLogOptions contains the command line options
+type AdminLogOptions struct {
+	*opts.CommonOptions
+
+	WaitForCommit string
+	Timeout       time.Duration
+	PollInterval  time.Duration
+	MaxReconnects int
+}
+
+var (
+	adminLogLong = templates.LongDesc(`
+		Tails the log of the boot pipeline of the development environment running inside the cluster.
+
+		The boot pipeline is triggered when a change, such as the Pull Request created by 'jx upgrade boot', is merged
+		into the master branch of the development environment git repository. If the pod of the pipeline is restarted
+		the log is reconnected until the pipeline completes.
+
+		Use --wait-for-commit to wait for the pipeline of a specific commit of the development environment git repository
+		to start and to fail if the pipeline does not succeed.
+`)
+
+	adminLogExample = templates.Examples(`
+		# Tails the log of the latest boot pipeline
+		jx admin log
+
+		# Waits for the boot pipeline of the merge commit of a boot upgrade Pull Request and reports its outcome
+		jx admin log --wait-for-commit 3b5e2c1
+	`)
+)
+
+// NewCmdAdminLog creates the command
+func NewCmdAdminLog(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &AdminLogOptions{
+		CommonOptions: commonOpts,
+	}
+
+	cmd := &cobra.Command{
+		Use:     "log",
+		Short:   "Tails the log of the boot pipeline running inside the cluster",
+		Long:    adminLogLong,
+		Example: adminLogExample,
+		Aliases: []string{"logs"},
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.Flags().StringVarP(&options.WaitForCommit, "wait-for-commit", "", "", "The SHA of the commit of the development environment git repository whose boot pipeline is waited for")
+	cmd.Flags().DurationVarP(&options.Timeout, "timeout", "t", 30*time.Minute, "The maximum time to wait for the boot pipeline to start and complete")
+	cmd.Flags().DurationVarP(&options.PollInterval, "poll-interval", "", 5*time.Second, "The time between checks of the boot pipeline")
+	cmd.Flags().IntVarP(&options.MaxReconnects, "max-reconnects", "", 5, "The maximum number of times the log is reconnected when the pod of the pipeline restarts")
+	return cmd
+}
+
+// Run implements this command
+func (o *AdminLogOptions) Run() error {
+	jxClient, ns, err := o.JXClientAndDevNamespace()
+	if err != nil {
+		return err
+	}
+	kubeClient, err := o.KubeClient()
+	if err != nil {
+		return err
+	}
+	tektonClient, _, err := o.TektonClient()
+	if err != nil {
+		return err
+	}
+	devEnv, err := kube.GetDevEnvironment(jxClient, ns)
+	if err != nil {
+		return errors.Wrap(err, "failed to find the development environment")
+	}
+	if devEnv == nil || devEnv.Spec.Source.URL == "" {
+		return fmt.Errorf("the development environment in namespace %s has no git repository so it is not installed via boot", ns)
+	}
+	gitInfo, err := gits.ParseGitURL(devEnv.Spec.Source.URL)
+	if err != nil {
+		return errors.Wrapf(err, "failed to parse the development environment git URL %s", devEnv.Spec.Source.URL)
+	}
+
+	deadline := time.Now().Add(o.Timeout)
+	pa, err := o.waitForBootActivity(jxClient, ns, gitInfo.Organisation, gitInfo.Name, deadline)
+	if err != nil {
+		return err
+	}
+	log.Logger().Infof("Tailing the log of the boot pipeline %s for commit %s", util.ColorInfo(pa.Name), util.ColorInfo(pa.Spec.LastCommitSHA))
+
+	tektonLogger := &logs.TektonLogger{
+		KubeClient:   kubeClient,
+		TektonClient: tektonClient,
+		JXClient:     jxClient,
+		Namespace:    ns,
+		LogWriter: &get.CLILogWriter{
+			CommonOptions: o.CommonOptions,
+		},
+	}
+	buildName := fmt.Sprintf("%s/%s/%s #%s", pa.Spec.GitOwner, pa.Spec.GitRepository, pa.Spec.GitBranch, pa.Spec.Build)
+	reconnects := 0
+	for {
+		err = tektonLogger.GetRunningBuildLogs(pa, buildName, false)
+		pa, err = o.refreshActivity(jxClient, ns, pa, err)
+		if err != nil {
+			return err
+		}
+		if pa.Spec.Status.IsTerminated() {
+			break
+		}
+		if reconnects >= o.MaxReconnects || time.Now().After(deadline) {
+			return fmt.Errorf("the boot pipeline %s has not completed after %d reconnects", pa.Name, reconnects)
+		}
+		reconnects++
+		log.Logger().Warnf("The log of the boot pipeline %s was interrupted, reconnecting", pa.Name)
+		time.Sleep(o.PollInterval)
+	}
+
+	// the activity is updated asynchronously once the pods complete so wait for its final status
+	for pa.Spec.Status == v1.ActivityStatusTypeRunning && time.Now().Before(deadline) {
+		time.Sleep(o.PollInterval)
+		pa, err = o.refreshActivity(jxClient, ns, pa, nil)
+		if err != nil {
+			return err
+		}
+	}
+	if pa.Spec.Status != v1.ActivityStatusTypeSucceeded {
+		return fmt.Errorf("the boot pipeline %s completed with status %s", pa.Name, pa.Spec.Status.String())
+	}
+	log.Logger().Infof("The boot pipeline %s succeeded", util.ColorInfo(pa.Name))
+	return nil
+}
+
+func (o *AdminLogOptions) waitForBootActivity(jxClient versioned.Interface, ns string, owner string, repository string, deadline time.Time) (*v1.PipelineActivity, error) {
+	if o.WaitForCommit != "" {
+		log.Logger().Infof("Waiting for the boot pipeline of commit %s", util.ColorInfo(o.WaitForCommit))
+	}
+	for {
+		pa, err := FindBootActivity(jxClient, ns, owner, repository, o.WaitForCommit)
+		if err != nil {
+			return nil, err
+		}
+		if pa != nil {
+			return pa, nil
+		}
+		if o.WaitForCommit == "" {
+			return nil, fmt.Errorf("no boot pipeline has run for the git repository %s/%s", owner, repository)
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("timed out waiting for the boot pipeline of commit %s", o.WaitForCommit)
+		}
+		time.Sleep(o.PollInterval)
+	}
+}
+
+// refreshActivity reloads the activity returning the streaming error if the activity cannot be reloaded
+func (o *AdminLogOptions) refreshActivity(jxClient versioned.Interface, ns string, pa *v1.PipelineActivity, streamErr error) (*v1.PipelineActivity, error) {
+	answer, err := jxClient.JenkinsV1().PipelineActivities(ns).Get(pa.Name, metav1.GetOptions{})
+	if err != nil {
+		if streamErr != nil {
+			return nil, errors.Wrapf(streamErr, "failed to tail the log of the boot pipeline %s", pa.Name)
+		}
+		return nil, errors.Wrapf(err, "failed to get the PipelineActivity %s", pa.Name)
+	}
+	if streamErr != nil {
+		log.Logger().Debugf("failed to tail the log of %s: %s", pa.Name, streamErr.Error())
+	}
+	return answer, nil
+}
+
+// FindBootActivity returns the latest PipelineActivity of the master branch of the development environment git
+// repository. If a commit SHA is specified only the activities of commits starting with it are considered. Returns nil
+// if there is no matching activity
+func FindBootActivity(jxClient versioned.Interface, ns string, owner string, repository string, commit string) (*v1.PipelineActivity, error) {
+	list, err := jxClient.JenkinsV1().PipelineActivities(ns).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list the PipelineActivities in namespace %s", ns)
+	}
+	matches := []v1.PipelineActivity{}
+	for _, pa := range list.Items {
+		spec := &pa.Spec
+		if !strings.EqualFold(spec.GitOwner, owner) || !strings.EqualFold(spec.GitRepository, repository) || spec.GitBranch != "master" {
+			continue
+		}
+		if commit != "" && (spec.LastCommitSHA == "" || !strings.HasPrefix(spec.LastCommitSHA, commit)) {
+			continue
+		}
+		matches = append(matches, pa)
+	}
+	if len(matches) == 0 {
+		return nil, nil
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		return buildNumber(matches[i]) > buildNumber(matches[j])
+	})
+	return &matches[0], nil
+}
+
+func buildNumber(pa v1.PipelineActivity) int {
+	n, err := strconv.Atoi(pa.Spec.Build)
+	if err != nil {
+		return 0
+	}
+	return n
+}
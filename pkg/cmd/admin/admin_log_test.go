@@ -0,0 +1,49 @@
+package admin_test
+
+import (
+	"testing"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	jxfake "github.com/jenkins-x/jx/pkg/client/clientset/versioned/fake"
+	"github.com/jenkins-x/jx/pkg/cmd/admin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestFindBootActivity(t *testing.T) {
+	t.Parallel()
+	ns := "jx"
+	activity := func(name string, repository string, branch string, build string, sha string) *v1.PipelineActivity {
+		return &v1.PipelineActivity{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: ns},
+			Spec: v1.PipelineActivitySpec{
+				GitOwner:      "myorg",
+				GitRepository: repository,
+				GitBranch:     branch,
+				Build:         build,
+				LastCommitSHA: sha,
+			},
+		}
+	}
+	jxClient := jxfake.NewSimpleClientset(
+		activity("myorg-environment-dev-master-2", "environment-dev", "master", "2", "3b5e2c1aa"),
+		activity("myorg-environment-dev-master-10", "environment-dev", "master", "10", "9f0c7d2bb"),
+		activity("myorg-environment-dev-pr-11-1", "environment-dev", "PR-11", "1", "7a7a7a7cc"),
+		activity("myorg-myapp-master-12", "myapp", "master", "12", "3b5e2c1aa"),
+	)
+
+	pa, err := admin.FindBootActivity(jxClient, ns, "myorg", "environment-dev", "")
+	require.NoError(t, err)
+	require.NotNil(t, pa)
+	assert.Equal(t, "myorg-environment-dev-master-10", pa.Name)
+
+	pa, err = admin.FindBootActivity(jxClient, ns, "MyOrg", "environment-dev", "3b5e2c1")
+	require.NoError(t, err)
+	require.NotNil(t, pa)
+	assert.Equal(t, "myorg-environment-dev-master-2", pa.Name)
+
+	pa, err = admin.FindBootActivity(jxClient, ns, "myorg", "environment-dev", "7a7a7a7")
+	require.NoError(t, err)
+	assert.Nil(t, pa)
+}
@@ -0,0 +1,37 @@
+package approve
+
+import (
+	"github.com/jenkins-x/jx/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/pkg/cmd/opts"
+	"github.com/spf13/cobra"
+)
+
+// ApproveOptions contains the command line options
+type ApproveOptions struct {
+	*opts.CommonOptions
+}
+
+// NewCmdApprove creates the command
+func NewCmdApprove(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &ApproveOptions{
+		CommonOptions: commonOpts,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "approve",
+		Short: "Approves or rejects Jenkins X resources which are waiting for an approval",
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.AddCommand(NewCmdApprovePipeline(commonOpts))
+	return cmd
+}
+
+// Run implements this command
+func (o *ApproveOptions) Run() error {
+	return o.Cmd.Help()
+}
@@ -0,0 +1,191 @@
+package approve
+
+import (
+	"fmt"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/client/clientset/versioned"
+	"github.com/jenkins-x/jx/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/pkg/kube"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ApprovePipelineOptions contains the command line options
+type ApprovePipelineOptions struct {
+	*opts.CommonOptions
+
+	Name   string
+	Reject bool
+}
+
+// pendingApproval an approval a pipeline is waiting for
+type pendingApproval struct {
+	Activity string
+	Approval *kube.PipelineApproval
+}
+
+var (
+	approvePipelineLong = templates.LongDesc(`
+		Approves or rejects an approval step of a pipeline so that the pipeline continues or fails.
+
+		The approval is decided as the user of the git provider of the repository. The pipeline ignores decisions by
+		users who are not one of the approvers of the step. If no pipeline is specified the pipelines waiting for an
+		approval are listed.
+`)
+
+	approvePipelineExample = templates.Examples(`
+		# List the pipelines waiting for an approval
+		jx approve pipeline
+
+		# Approve the pipeline
+		jx approve pipeline myorg-myapp-master-3 --name production
+
+		# Reject the pipeline
+		jx approve pipeline myorg-myapp-master-3 --name production --reject
+	`)
+)
+
+// NewCmdApprovePipeline creates the command
+func NewCmdApprovePipeline(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &ApprovePipelineOptions{
+		CommonOptions: commonOpts,
+	}
+
+	cmd := &cobra.Command{
+		Use:     "pipeline [activity]",
+		Short:   "Approves or rejects an approval step of a pipeline",
+		Long:    approvePipelineLong,
+		Example: approvePipelineExample,
+		Aliases: []string{"pipelines"},
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.Flags().StringVarP(&options.Name, "name", "n", "", "The name of the approval step. Defaults to the approval the pipeline is waiting for")
+	cmd.Flags().BoolVarP(&options.Reject, "reject", "", false, "Rejects the approval so that the pipeline fails")
+	return cmd
+}
+
+// Run implements this command
+func (o *ApprovePipelineOptions) Run() error {
+	jxClient, ns, err := o.JXClientAndDevNamespace()
+	if err != nil {
+		return err
+	}
+	if len(o.Args) == 0 {
+		return o.listPendingApprovals(jxClient, ns)
+	}
+	activityName := o.Args[0]
+	activity, err := jxClient.JenkinsV1().PipelineActivities(ns).Get(activityName, metav1.GetOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "failed to find the PipelineActivity %s", activityName)
+	}
+	if activity.Spec.GitURL == "" {
+		return fmt.Errorf("the PipelineActivity %s has no git URL to find the approver", activityName)
+	}
+	provider, err := o.GitProviderForURL(activity.Spec.GitURL, "git user")
+	if err != nil {
+		return err
+	}
+	return o.decide(jxClient, ns, activity, provider.CurrentUsername())
+}
+
+// decide approves or rejects the approval of the activity as the user
+func (o *ApprovePipelineOptions) decide(jxClient versioned.Interface, ns string, activity *v1.PipelineActivity, user string) error {
+	if user == "" {
+		return errors.New("could not find the git user to approve as")
+	}
+	approval, err := o.findApproval(activity)
+	if err != nil {
+		return err
+	}
+	state := kube.ApprovalStateApproved
+	if o.Reject {
+		state = kube.ApprovalStateRejected
+	}
+	approval.State = state
+	approval.User = user
+	err = kube.SetPipelineApproval(activity, approval)
+	if err != nil {
+		return err
+	}
+	_, err = jxClient.JenkinsV1().PipelineActivities(ns).PatchUpdate(activity)
+	if err != nil {
+		return errors.Wrapf(err, "failed to update the approval %s on PipelineActivity %s", approval.Name, activity.Name)
+	}
+	log.Logger().Infof("The approval %s of pipeline %s is %s by %s", util.ColorInfo(approval.Name), util.ColorInfo(activity.Name),
+		util.ColorInfo(state), util.ColorInfo(user))
+	return nil
+}
+
+func (o *ApprovePipelineOptions) findApproval(activity *v1.PipelineActivity) (*kube.PipelineApproval, error) {
+	if o.Name != "" {
+		approval := kube.GetPipelineApproval(activity, o.Name)
+		if approval == nil {
+			return nil, fmt.Errorf("the pipeline %s has no approval %s", activity.Name, o.Name)
+		}
+		if !approval.IsPending() {
+			return nil, fmt.Errorf("the approval %s of pipeline %s is not pending as it is %s", o.Name, activity.Name, approval.State)
+		}
+		return approval, nil
+	}
+	pending := []*kube.PipelineApproval{}
+	for _, approval := range kube.GetPipelineApprovals(activity) {
+		if approval.IsPending() {
+			pending = append(pending, approval)
+		}
+	}
+	if len(pending) != 1 {
+		return nil, fmt.Errorf("the pipeline %s is waiting for %d approvals so please specify the --name option", activity.Name, len(pending))
+	}
+	return pending[0], nil
+}
+
+func (o *ApprovePipelineOptions) listPendingApprovals(jxClient versioned.Interface, ns string) error {
+	activities, err := jxClient.JenkinsV1().PipelineActivities(ns).List(metav1.ListOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "failed to list the PipelineActivities in namespace %s", ns)
+	}
+	pending := pendingApprovals(activities.Items)
+	if len(pending) == 0 {
+		log.Logger().Info("No pipelines are waiting for an approval")
+		return nil
+	}
+	table := o.CreateTable()
+	table.AddRow("PIPELINE", "APPROVAL", "APPROVERS", "DEADLINE", "MESSAGE")
+	for _, p := range pending {
+		approvers := "any"
+		if len(p.Approval.Approvers) > 0 {
+			approvers = fmt.Sprintf("%v", p.Approval.Approvers)
+		}
+		table.AddRow(p.Activity, p.Approval.Name, approvers, p.Approval.Deadline.Local().Format("2006-01-02 15:04"), p.Approval.Message)
+	}
+	table.Render()
+	return nil
+}
+
+// pendingApprovals returns the approvals the running pipelines are waiting for
+func pendingApprovals(activities []v1.PipelineActivity) []pendingApproval {
+	answer := []pendingApproval{}
+	for i := range activities {
+		activity := &activities[i]
+		if activity.Spec.Status.IsTerminated() {
+			continue
+		}
+		for _, approval := range kube.GetPipelineApprovals(activity) {
+			if approval.IsPending() {
+				answer = append(answer, pendingApproval{Activity: activity.Name, Approval: approval})
+			}
+		}
+	}
+	return answer
+}
@@ -0,0 +1,81 @@
+package approve
+
+import (
+	"testing"
+	"time"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/client/clientset/versioned/fake"
+	"github.com/jenkins-x/jx/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/pkg/kube"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newApprovalActivity(t *testing.T, name string, status v1.ActivityStatusType, approvals ...string) *v1.PipelineActivity {
+	activity := &v1.PipelineActivity{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "jx"},
+		Spec:       v1.PipelineActivitySpec{Status: status},
+	}
+	requested := time.Date(2019, 11, 4, 10, 0, 0, 0, time.UTC)
+	for _, approval := range approvals {
+		err := kube.SetPipelineApproval(activity, &kube.PipelineApproval{
+			Name:      approval,
+			State:     kube.ApprovalStatePending,
+			Requested: requested,
+			Deadline:  requested.Add(time.Hour),
+		})
+		require.NoError(t, err)
+	}
+	return activity
+}
+
+func TestPendingApprovals(t *testing.T) {
+	t.Parallel()
+	activities := []v1.PipelineActivity{
+		*newApprovalActivity(t, "myorg-myapp-master-1", v1.ActivityStatusTypeAborted, "production"),
+		*newApprovalActivity(t, "myorg-myapp-master-2", v1.ActivityStatusTypeRunning, "production", "staging"),
+		*newApprovalActivity(t, "myorg-other-master-1", v1.ActivityStatusTypeRunning),
+	}
+	pending := pendingApprovals(activities)
+	require.Len(t, pending, 2)
+	assert.Equal(t, "myorg-myapp-master-2", pending[0].Activity)
+	assert.Equal(t, "production", pending[0].Approval.Name)
+	assert.Equal(t, "staging", pending[1].Approval.Name)
+}
+
+func TestDecideApproval(t *testing.T) {
+	t.Parallel()
+	ns := "jx"
+	activity := newApprovalActivity(t, "myorg-myapp-master-2", v1.ActivityStatusTypeRunning, "production", "staging")
+	jxClient := fake.NewSimpleClientset(activity)
+	o := &ApprovePipelineOptions{CommonOptions: &opts.CommonOptions{}}
+
+	err := o.decide(jxClient, ns, activity.DeepCopy(), "jstrachan")
+	require.Error(t, err, "the approval must be named when several are pending")
+
+	o.Name = "staging"
+	o.Reject = true
+	err = o.decide(jxClient, ns, activity.DeepCopy(), "jstrachan")
+	require.NoError(t, err)
+
+	updated, err := jxClient.JenkinsV1().PipelineActivities(ns).Get(activity.Name, metav1.GetOptions{})
+	require.NoError(t, err)
+	approval := kube.GetPipelineApproval(updated, "staging")
+	require.NotNil(t, approval)
+	assert.Equal(t, kube.ApprovalStateRejected, approval.State)
+	assert.Equal(t, "jstrachan", approval.User)
+	assert.True(t, kube.GetPipelineApproval(updated, "production").IsPending())
+
+	err = o.decide(jxClient, ns, updated, "jstrachan")
+	assert.Error(t, err, "the approval is no longer pending")
+
+	o.Name = ""
+	o.Reject = false
+	err = o.decide(jxClient, ns, updated, "jstrachan")
+	require.NoError(t, err)
+	updated, err = jxClient.JenkinsV1().PipelineActivities(ns).Get(activity.Name, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, kube.ApprovalStateApproved, kube.GetPipelineApproval(updated, "production").State)
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/google/uuid"
 
@@ -20,6 +21,7 @@ import (
 	"github.com/jenkins-x/jx/pkg/config"
 	"github.com/jenkins-x/jx/pkg/gits"
 	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/terraform"
 	"github.com/jenkins-x/jx/pkg/util"
 	"github.com/pkg/errors"
 
@@ -48,6 +50,11 @@ type BootOptions struct {
 	// RequirementsFile provided by the user to override the default requirements file from repository
 	RequirementsFile string
 
+	// TerraformOutputsFile a terraform state or `terraform output -json` file whose outputs are mapped to the requirements
+	TerraformOutputsFile string
+	// TerraformMappingFile the file mapping the terraform outputs to the requirements
+	TerraformMappingFile string
+
 	AttemptRestore bool
 }
 
@@ -66,6 +73,13 @@ var (
 		# now lets boot up Jenkins X installing/upgrading whatever is needed
 		jx boot 
 
+		# create the jx-requirements.yml file via a wizard
+		jx boot init
+
+		# boot using the outputs of the terraform which created the cluster
+		terraform output -json > outputs.json
+		jx boot --requirements-from-terraform outputs.json
+
 		# if we have already booted and just want to apply some environment changes without 
         # re-applying ingress and so forth we can start at the environment step:
 		jx boot --start-step install-env
@@ -100,8 +114,12 @@ func NewCmdBoot(commonOpts *opts.CommonOptions) *cobra.Command {
 	cmd.Flags().StringVarP(&options.EndStep, "end-step", "e", "", "the step in the pipeline to end at")
 	cmd.Flags().StringVarP(&options.HelmLogLevel, "helm-log", "v", "", "sets the helm logging level from 0 to 9. Passed into the helm CLI via the '-v' argument. Useful to diagnose helm related issues")
 	cmd.Flags().StringVarP(&options.RequirementsFile, "requirements", "r", "", "requirements file which will overwrite the default requirements file")
+	cmd.Flags().StringVarP(&options.TerraformOutputsFile, "requirements-from-terraform", "", "", "a terraform state file or the output of 'terraform output -json' whose outputs are mapped into the requirements")
+	cmd.Flags().StringVarP(&options.TerraformMappingFile, "terraform-mapping", "", "", fmt.Sprintf("the file mapping the terraform outputs to the requirements. Defaults to .jx/%s in the boot configuration or the mappings of the Jenkins X terraform modules", config.TerraformOutputsConfigFileName))
 	cmd.Flags().BoolVarP(&options.AttemptRestore, "attempt-restore", "a", false, "attempt to boot from an existing dev environment repository")
 
+	cmd.AddCommand(NewCmdBootInit(commonOpts))
+
 	return cmd
 }
 
@@ -494,6 +512,13 @@ func (o *BootOptions) overrideRequirements(defaultBootConfigURL string) error {
 		*requirements = *providedRequirements
 	}
 
+	if o.TerraformOutputsFile != "" {
+		err = o.applyTerraformOutputs(requirements)
+		if err != nil {
+			return err
+		}
+	}
+
 	o.defaultVersionStream(requirements)
 	if requirements.BootConfigURL == "" {
 		requirements.BootConfigURL = defaultBootConfigURL
@@ -506,6 +531,36 @@ func (o *BootOptions) overrideRequirements(defaultBootConfigURL string) error {
 	return nil
 }
 
+// applyTerraformOutputs sets the requirements from the outputs of terraform using the configured mappings
+func (o *BootOptions) applyTerraformOutputs(requirements *config.RequirementsConfig) error {
+	outputs, err := terraform.LoadOutputs(o.TerraformOutputsFile)
+	if err != nil {
+		return err
+	}
+	var mappings *config.TerraformOutputsConfig
+	if o.TerraformMappingFile != "" {
+		mappings, err = config.LoadTerraformOutputsConfigFile(o.TerraformMappingFile)
+	} else {
+		mappings, err = config.LoadTerraformOutputsConfig(o.Dir)
+	}
+	if err != nil {
+		return err
+	}
+	if mappings == nil {
+		mappings = config.DefaultTerraformOutputsConfig()
+	}
+	applied, err := mappings.Apply(requirements, outputs)
+	if err != nil {
+		return errors.Wrapf(err, "applying the terraform outputs of %s to the requirements", o.TerraformOutputsFile)
+	}
+	if len(applied) == 0 {
+		log.Logger().Warnf("None of the terraform outputs in %s are mapped to the requirements", o.TerraformOutputsFile)
+		return nil
+	}
+	log.Logger().Infof("Set the requirements %s from the terraform outputs in %s", util.ColorInfo(strings.Join(applied, ", ")), util.ColorInfo(o.TerraformOutputsFile))
+	return nil
+}
+
 func (o *BootOptions) determineGitRef(resolver *versionstream.VersionResolver, requirements *config.RequirementsConfig, gitURL string) (string, error) {
 	// If the GitRef is not overridden and is set to it's default value then look up the version number
 	log.Logger().Infof("Attempting to resolve version for boot config %s from %s", util.ColorInfo(gitURL), util.ColorInfo(requirements.VersionStream.URL))
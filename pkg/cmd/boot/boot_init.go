@@ -0,0 +1,514 @@
+package boot
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+
+	"github.com/jenkins-x/jx/pkg/cloud"
+	"github.com/jenkins-x/jx/pkg/cloud/amazon"
+	"github.com/jenkins-x/jx/pkg/cloud/amazon/session"
+	"github.com/jenkins-x/jx/pkg/cloud/gke"
+	"github.com/jenkins-x/jx/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/pkg/config"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// BootInitOptions options for the command
+type BootInitOptions struct {
+	*opts.CommonOptions
+
+	Dir           string
+	Overwrite     bool
+	NoValidate    bool
+	Requirements  config.RequirementsConfig
+	LogsBucketURL string
+	AWSProfile    string
+	AWSAccountID  string
+	IRSA          bool
+
+	// validator validates the requirements against the cloud provider, exposed for testing
+	validator requirementsValidator
+}
+
+// requirementsValidator validates the requirements against the APIs of a cloud provider before boot runs
+type requirementsValidator interface {
+	// ValidateCluster validates the project, account and cluster settings
+	ValidateCluster(requirements *config.RequirementsConfig) error
+	// ValidateBucket validates the bucket can be used for storage
+	ValidateBucket(requirements *config.RequirementsConfig, bucketURL string) error
+	// ValidateDomain validates there is a DNS zone for the domain
+	ValidateDomain(requirements *config.RequirementsConfig, domain string) error
+}
+
+var (
+	bootInitLong = templates.LongDesc(`
+		Creates the 'jx-requirements.yml' file used by 'jx boot' from scratch by asking questions specific to the Kubernetes provider.
+
+		The answers are validated against the APIs of the cloud provider, such as whether the project or account is accessible, the storage buckets are available and the DNS zone of the domain exists, so that mistakes are found before 'jx boot' runs.
+`)
+
+	bootInitExample = templates.Examples(`
+		# create the jx-requirements.yml file in the current directory
+		jx boot init
+
+		# create the requirements for an EKS cluster using IAM roles for service accounts without prompting
+		jx boot init -b --provider eks --cluster-name mycluster --region us-east-1 --irsa --git-owner myorg
+`)
+)
+
+// NewCmdBootInit creates the command
+func NewCmdBootInit(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &BootInitOptions{
+		CommonOptions: commonOpts,
+	}
+	cmd := &cobra.Command{
+		Use:     "init",
+		Short:   "Creates the jx-requirements.yml file for jx boot via a wizard which validates the answers against the cloud provider",
+		Long:    bootInitLong,
+		Example: bootInitExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	r := &options.Requirements
+	cmd.Flags().StringVarP(&options.Dir, "dir", "d", ".", "the directory to create the requirements file in")
+	cmd.Flags().BoolVarP(&options.Overwrite, "overwrite", "", false, "overwrite an existing requirements file")
+	cmd.Flags().BoolVarP(&options.NoValidate, "no-validate", "", false, "disables the validation of the answers against the cloud provider")
+	cmd.Flags().StringVarP(&r.Cluster.Provider, "provider", "", "", "the Kubernetes provider such as gke or eks")
+	cmd.Flags().StringVarP(&r.Cluster.ClusterName, "cluster-name", "", "", "the name of the cluster")
+	cmd.Flags().StringVarP(&r.Cluster.ProjectID, "project", "", "", "the GCP project of the cluster")
+	cmd.Flags().StringVarP(&r.Cluster.Zone, "zone", "", "", "the zone of the cluster")
+	cmd.Flags().StringVarP(&r.Cluster.Region, "region", "", "", "the region of the cluster")
+	cmd.Flags().StringVarP(&r.Cluster.EnvironmentGitOwner, "git-owner", "", "", "the git user or organisation of the environment repositories")
+	cmd.Flags().StringVarP(&r.Ingress.Domain, "domain", "", "", "the domain to expose ingress endpoints, if empty a domain is generated from the IP address of the load balancer")
+	cmd.Flags().StringVarP(&options.LogsBucketURL, "logs-bucket", "", "", "the URL of the bucket to store build logs such as gs://mybucket or s3://mybucket")
+	cmd.Flags().StringVarP(&options.AWSProfile, "aws-profile", "", "", "the AWS profile to use to validate the answers")
+	cmd.Flags().StringVarP(&options.AWSAccountID, "account-id", "", "", "the AWS account ID of the cluster, defaults to the account of the current credentials")
+	cmd.Flags().BoolVarP(&options.IRSA, "irsa", "", false, "use IAM roles for service accounts on EKS")
+	cmd.Flags().StringVarP((*string)(&r.SecretStorage), "secret-storage", "", "", fmt.Sprintf("how the secrets are stored, one of: %v", config.SecretStorageTypeValues))
+	return cmd
+}
+
+// Run runs this command
+func (o *BootInitOptions) Run() error {
+	fileName := filepath.Join(o.Dir, config.RequirementsConfigFileName)
+	exists, err := util.FileExists(fileName)
+	if err != nil {
+		return errors.Wrapf(err, "checking if %s exists", fileName)
+	}
+	if exists && !o.Overwrite {
+		return fmt.Errorf("the requirements file %s already exists. Use --overwrite to replace it", fileName)
+	}
+
+	requirements, err := o.askRequirements()
+	if err != nil {
+		return err
+	}
+	err = requirements.SaveConfig(fileName)
+	if err != nil {
+		return err
+	}
+	log.Logger().Infof("Created %s. You can now run %s", util.ColorInfo(fileName), util.ColorInfo("jx boot"))
+	return nil
+}
+
+// askRequirements asks the questions for the requirements validating the answers as they are given
+func (o *BootInitOptions) askRequirements() (*config.RequirementsConfig, error) {
+	requirements := config.NewRequirementsConfig()
+	requirements.Cluster = o.Requirements.Cluster
+	requirements.Ingress.Domain = o.Requirements.Ingress.Domain
+	if o.Requirements.SecretStorage != "" {
+		requirements.SecretStorage = o.Requirements.SecretStorage
+	}
+	c := &requirements.Cluster
+
+	var err error
+	c.Provider, err = o.pick(cloud.KubernetesProviders, "Kubernetes provider:", c.Provider, cloud.GKE, "provider")
+	if err != nil {
+		return nil, err
+	}
+	c.ClusterName, err = o.ask("Cluster name:", c.ClusterName, "the name of the Kubernetes cluster", "cluster-name", nil)
+	if err != nil {
+		return nil, err
+	}
+	validator := o.requirementsValidator(c.Provider)
+
+	switch c.Provider {
+	case cloud.GKE:
+		err = o.askGKE(requirements, validator)
+	case cloud.EKS:
+		err = o.askEKS(requirements, validator)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	c.EnvironmentGitOwner, err = o.ask("Git user or organisation for the environment repositories:", c.EnvironmentGitOwner, "", "git-owner", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	storageTypes := config.SecretStorageTypeValues
+	if !requirements.IsCloudProvider() {
+		storageTypes = []string{string(config.SecretStorageTypeLocal)}
+	}
+	storage, err := o.pick(storageTypes, "Secret storage:", string(requirements.SecretStorage), string(config.SecretStorageTypeLocal), "secret-storage")
+	if err != nil {
+		return nil, err
+	}
+	requirements.SecretStorage = config.SecretStorageType(storage)
+
+	err = o.askIngress(requirements, validator)
+	if err != nil {
+		return nil, err
+	}
+
+	if requirements.IsCloudProvider() {
+		logsURL, err := o.askOptional("Bucket URL for build logs (leave empty to disable):", o.LogsBucketURL, "such as gs://mybucket or s3://mybucket", func(value string) error {
+			return validator.ValidateBucket(requirements, value)
+		})
+		if err != nil {
+			return nil, err
+		}
+		if logsURL != "" {
+			requirements.Storage.Logs = config.StorageEntryConfig{
+				Enabled: true,
+				URL:     logsURL,
+			}
+		}
+	}
+	return requirements, nil
+}
+
+func (o *BootInitOptions) askGKE(requirements *config.RequirementsConfig, validator requirementsValidator) error {
+	c := &requirements.Cluster
+	var err error
+	c.ProjectID, err = o.ask("GCP project:", c.ProjectID, "the GCP project of the cluster", "project", nil)
+	if err != nil {
+		return err
+	}
+	c.Zone, err = o.ask("Zone:", c.Zone, "the zone of the cluster such as us-central1-a", "zone", nil)
+	if err != nil {
+		return err
+	}
+	if c.Region == "" {
+		c.Region = gke.GetRegionFromZone(c.Zone)
+	}
+	return o.validate(func() error {
+		return validator.ValidateCluster(requirements)
+	})
+}
+
+func (o *BootInitOptions) askEKS(requirements *config.RequirementsConfig, validator requirementsValidator) error {
+	c := &requirements.Cluster
+	c.EKSConfig = &config.EKSConfig{
+		AccountID: o.AWSAccountID,
+		IRSA:      o.IRSA,
+	}
+	var err error
+	if c.Region == "" {
+		c.Region, _ = session.ResolveRegion(o.AWSProfile, "")
+	}
+	c.Region, err = o.ask("AWS region:", c.Region, "the AWS region of the cluster", "region", nil)
+	if err != nil {
+		return err
+	}
+	if c.EKSConfig.AccountID == "" && !o.NoValidate {
+		c.EKSConfig.AccountID, err = amazon.GetCallerAccountID(o.AWSProfile, c.Region)
+		if err != nil {
+			log.Logger().Warnf("Failed to find the AWS account ID of the current credentials: %s", err)
+		}
+	}
+	c.EKSConfig.AccountID, err = o.ask("AWS account ID:", c.EKSConfig.AccountID, "the AWS account of the cluster", "account-id", nil)
+	if err != nil {
+		return err
+	}
+	if !o.BatchMode {
+		c.EKSConfig.IRSA = util.Confirm("Use IAM roles for service accounts (IRSA)?", true,
+			"IRSA gives the Jenkins X service accounts their own IAM roles rather than using the roles of the nodes", o.GetIOFileHandles())
+	}
+	return o.validate(func() error {
+		return validator.ValidateCluster(requirements)
+	})
+}
+
+func (o *BootInitOptions) askIngress(requirements *config.RequirementsConfig, validator requirementsValidator) error {
+	domain, err := o.askOptional("Domain (leave empty to use a domain generated from the load balancer IP address):", requirements.Ingress.Domain, "", func(value string) error {
+		return validator.ValidateDomain(requirements, value)
+	})
+	if err != nil {
+		return err
+	}
+	requirements.Ingress.Domain = domain
+	if domain == "" || requirements.Ingress.IsAutoDNSDomain() {
+		return nil
+	}
+	if o.BatchMode {
+		requirements.Ingress.ExternalDNS = true
+		return nil
+	}
+	requirements.Ingress.ExternalDNS = util.Confirm("Manage the DNS records of the domain with external-dns?", true, "", o.GetIOFileHandles())
+	requirements.Ingress.TLS.Enabled = util.Confirm("Enable TLS with Let's Encrypt certificates?", true, "", o.GetIOFileHandles())
+	if requirements.Ingress.TLS.Enabled {
+		requirements.Ingress.TLS.Email, err = util.PickValue("Email address to register with Let's Encrypt:", "", true, "", o.GetIOFileHandles())
+		if err != nil {
+			return err
+		}
+		requirements.Ingress.TLS.Production = util.Confirm("Use the production Let's Encrypt server?", true,
+			"the staging server issues untrusted certificates but has higher rate limits", o.GetIOFileHandles())
+	}
+	return nil
+}
+
+// ask asks for a required value validating it. In batch mode the current value is validated without prompting
+func (o *BootInitOptions) ask(message string, value string, help string, flag string, validate func(string) error) (string, error) {
+	if o.BatchMode {
+		if value == "" {
+			return "", util.MissingOption(flag)
+		}
+		return value, o.validateValue(validate, value)
+	}
+	for {
+		answer, err := util.PickValue(message, value, true, help, o.GetIOFileHandles())
+		if err != nil {
+			return "", err
+		}
+		err = o.validateValue(validate, answer)
+		if err == nil {
+			return answer, nil
+		}
+		log.Logger().Warnf("%s", err)
+		value = answer
+	}
+}
+
+// askOptional asks for an optional value validating it if it is not empty
+func (o *BootInitOptions) askOptional(message string, value string, help string, validate func(string) error) (string, error) {
+	if o.BatchMode {
+		if value == "" {
+			return "", nil
+		}
+		return value, o.validateValue(validate, value)
+	}
+	for {
+		answer, err := util.PickValue(message, value, false, help, o.GetIOFileHandles())
+		if err != nil {
+			return "", err
+		}
+		if answer == "" {
+			return "", nil
+		}
+		err = o.validateValue(validate, answer)
+		if err == nil {
+			return answer, nil
+		}
+		log.Logger().Warnf("%s", err)
+		value = answer
+	}
+}
+
+func (o *BootInitOptions) pick(names []string, message string, value string, defaultValue string, flag string) (string, error) {
+	if value != "" {
+		if util.StringArrayIndex(names, value) < 0 {
+			return "", util.InvalidOption(flag, value, names)
+		}
+		if o.BatchMode {
+			return value, nil
+		}
+		defaultValue = value
+	}
+	if o.BatchMode {
+		return defaultValue, nil
+	}
+	return util.PickNameWithDefault(names, message, defaultValue, "", o.GetIOFileHandles())
+}
+
+func (o *BootInitOptions) validateValue(validate func(string) error, value string) error {
+	if validate == nil {
+		return nil
+	}
+	return o.validate(func() error {
+		return validate(value)
+	})
+}
+
+func (o *BootInitOptions) validate(fn func() error) error {
+	if o.NoValidate {
+		return nil
+	}
+	return fn()
+}
+
+func (o *BootInitOptions) requirementsValidator(provider string) requirementsValidator {
+	if o.validator != nil {
+		return o.validator
+	}
+	switch provider {
+	case cloud.GKE:
+		return &gkeRequirementsValidator{gcloud: o.GCloud()}
+	case cloud.EKS:
+		return &eksRequirementsValidator{profile: o.AWSProfile}
+	default:
+		return &noopRequirementsValidator{}
+	}
+}
+
+// gkeRequirementsValidator validates the requirements against GCP
+type gkeRequirementsValidator struct {
+	gcloud gke.GClouder
+}
+
+// ValidateCluster validates the project is accessible and defaults the project number
+func (v *gkeRequirementsValidator) ValidateCluster(requirements *config.RequirementsConfig) error {
+	projectID := requirements.Cluster.ProjectID
+	_, err := v.gcloud.GetEnabledApis(projectID)
+	if err != nil {
+		return errors.Wrapf(err, "GCP project %s is not accessible", projectID)
+	}
+	projectNumber, err := v.gcloud.GetProjectNumber(projectID)
+	if err != nil {
+		return errors.Wrapf(err, "getting the number of GCP project %s", projectID)
+	}
+	requirements.Cluster.GKEConfig = &config.GKEConfig{
+		ProjectNumber: projectNumber,
+	}
+	return nil
+}
+
+// ValidateBucket validates the bucket either does not exist or belongs to the project
+func (v *gkeRequirementsValidator) ValidateBucket(requirements *config.RequirementsConfig, bucketURL string) error {
+	bucketName, err := bucketNameFromURL(bucketURL, "gs")
+	if err != nil {
+		return err
+	}
+	if !v.gcloud.FindBucket(bucketName) {
+		return nil
+	}
+	exists, err := v.gcloud.BucketExists(requirements.Cluster.ProjectID, bucketName)
+	if err != nil {
+		return errors.Wrapf(err, "checking bucket %s", bucketName)
+	}
+	if !exists {
+		return fmt.Errorf("bucket %s is already used by another project, please choose another name", bucketName)
+	}
+	return nil
+}
+
+// ValidateDomain validates there is a Cloud DNS managed zone for the domain
+func (v *gkeRequirementsValidator) ValidateDomain(requirements *config.RequirementsConfig, domain string) error {
+	if isAutoDNSDomain(domain) {
+		return nil
+	}
+	zone, _, err := v.gcloud.GetManagedZoneNameServers(requirements.Cluster.ProjectID, domain)
+	if err != nil {
+		return errors.Wrapf(err, "finding the managed zone of domain %s", domain)
+	}
+	if zone == "" {
+		return fmt.Errorf("there is no Cloud DNS managed zone for domain %s in project %s", domain, requirements.Cluster.ProjectID)
+	}
+	return nil
+}
+
+// eksRequirementsValidator validates the requirements against AWS
+type eksRequirementsValidator struct {
+	profile string
+}
+
+// ValidateCluster validates the account matches the credentials and the cluster supports IRSA if enabled
+func (v *eksRequirementsValidator) ValidateCluster(requirements *config.RequirementsConfig) error {
+	c := requirements.Cluster
+	accountID, err := amazon.GetCallerAccountID(v.profile, c.Region)
+	if err != nil {
+		return err
+	}
+	if c.EKSConfig == nil {
+		return nil
+	}
+	if c.EKSConfig.AccountID != "" && c.EKSConfig.AccountID != accountID {
+		return fmt.Errorf("the AWS credentials are for account %s rather than account %s", accountID, c.EKSConfig.AccountID)
+	}
+	if c.EKSConfig.IRSA {
+		exists, err := amazon.ClusterOIDCProviderExists(c.ClusterName, v.profile, c.Region)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return fmt.Errorf("EKS cluster %s has no IAM OIDC provider which is required for IRSA. Create one with: eksctl utils associate-iam-oidc-provider --cluster %s --approve", c.ClusterName, c.ClusterName)
+		}
+	}
+	return nil
+}
+
+// ValidateBucket validates the bucket either does not exist or belongs to the account
+func (v *eksRequirementsValidator) ValidateBucket(requirements *config.RequirementsConfig, bucketURL string) error {
+	bucketName, err := bucketNameFromURL(bucketURL, "s3")
+	if err != nil {
+		return err
+	}
+	available, err := amazon.S3BucketAvailable(bucketName, v.profile, requirements.Cluster.Region)
+	if err != nil {
+		return err
+	}
+	if !available {
+		return fmt.Errorf("bucket %s is already used by another account, please choose another name", bucketName)
+	}
+	return nil
+}
+
+// ValidateDomain validates there is a Route 53 hosted zone for the domain
+func (v *eksRequirementsValidator) ValidateDomain(requirements *config.RequirementsConfig, domain string) error {
+	if isAutoDNSDomain(domain) {
+		return nil
+	}
+	exists, err := amazon.HostedZoneExists(domain, v.profile, requirements.Cluster.Region)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("there is no Route 53 hosted zone for domain %s", domain)
+	}
+	return nil
+}
+
+// noopRequirementsValidator is used for providers whose APIs are not validated
+type noopRequirementsValidator struct{}
+
+// ValidateCluster does nothing
+func (v *noopRequirementsValidator) ValidateCluster(*config.RequirementsConfig) error {
+	return nil
+}
+
+// ValidateBucket does nothing
+func (v *noopRequirementsValidator) ValidateBucket(*config.RequirementsConfig, string) error {
+	return nil
+}
+
+// ValidateDomain does nothing
+func (v *noopRequirementsValidator) ValidateDomain(*config.RequirementsConfig, string) error {
+	return nil
+}
+
+func bucketNameFromURL(bucketURL string, scheme string) (string, error) {
+	u, err := url.Parse(bucketURL)
+	if err != nil {
+		return "", errors.Wrapf(err, "parsing bucket URL %s", bucketURL)
+	}
+	if u.Scheme != scheme || u.Host == "" {
+		return "", fmt.Errorf("invalid bucket URL %s which should be of the form %s://bucket-name", bucketURL, scheme)
+	}
+	return u.Host, nil
+}
+
+func isAutoDNSDomain(domain string) bool {
+	ingress := config.IngressConfig{Domain: domain}
+	return ingress.IsAutoDNSDomain()
+}
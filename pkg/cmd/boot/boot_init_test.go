@@ -0,0 +1,123 @@
+package boot
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x/jx/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeRequirementsValidator struct {
+	takenBuckets []string
+	domains      []string
+}
+
+func (v *fakeRequirementsValidator) ValidateCluster(requirements *config.RequirementsConfig) error {
+	requirements.Cluster.GKEConfig = &config.GKEConfig{ProjectNumber: "1234"}
+	return nil
+}
+
+func (v *fakeRequirementsValidator) ValidateBucket(requirements *config.RequirementsConfig, bucketURL string) error {
+	for _, bucket := range v.takenBuckets {
+		if bucket == bucketURL {
+			return fmt.Errorf("bucket %s is already used", bucketURL)
+		}
+	}
+	return nil
+}
+
+func (v *fakeRequirementsValidator) ValidateDomain(requirements *config.RequirementsConfig, domain string) error {
+	for _, d := range v.domains {
+		if d == domain {
+			return nil
+		}
+	}
+	return fmt.Errorf("there is no DNS zone for domain %s", domain)
+}
+
+func TestBootInitGKE(t *testing.T) {
+	dir, err := ioutil.TempDir("", "test-boot-init")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	o := &BootInitOptions{
+		CommonOptions: &opts.CommonOptions{BatchMode: true},
+		Dir:           dir,
+		LogsBucketURL: "gs://mylogs",
+		validator: &fakeRequirementsValidator{
+			domains: []string{"example.com"},
+		},
+	}
+	o.Requirements.Cluster.Provider = "gke"
+	o.Requirements.Cluster.ClusterName = "mycluster"
+	o.Requirements.Cluster.ProjectID = "myproject"
+	o.Requirements.Cluster.Zone = "europe-west1-b"
+	o.Requirements.Cluster.EnvironmentGitOwner = "myorg"
+	o.Requirements.Ingress.Domain = "example.com"
+	o.Requirements.SecretStorage = config.SecretStorageTypeVault
+
+	err = o.Run()
+	require.NoError(t, err)
+
+	requirements, err := config.LoadRequirementsConfigFile(filepath.Join(dir, config.RequirementsConfigFileName))
+	require.NoError(t, err)
+	assert.Equal(t, "europe-west1", requirements.Cluster.Region)
+	assert.Equal(t, "1234", requirements.Cluster.GKEConfig.ProjectNumber)
+	assert.Equal(t, config.SecretStorageTypeVault, requirements.SecretStorage)
+	assert.True(t, requirements.Ingress.ExternalDNS)
+	assert.True(t, requirements.Storage.Logs.Enabled)
+	assert.Equal(t, "gs://mylogs", requirements.Storage.Logs.URL)
+
+	err = o.Run()
+	assert.Error(t, err, "an existing requirements file should not be overwritten")
+}
+
+func TestBootInitValidationFailures(t *testing.T) {
+	dir, err := ioutil.TempDir("", "test-boot-init")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	newOptions := func() *BootInitOptions {
+		o := &BootInitOptions{
+			CommonOptions: &opts.CommonOptions{BatchMode: true},
+			Dir:           dir,
+			validator: &fakeRequirementsValidator{
+				takenBuckets: []string{"gs://taken"},
+			},
+		}
+		o.Requirements.Cluster.Provider = "gke"
+		o.Requirements.Cluster.ClusterName = "mycluster"
+		o.Requirements.Cluster.ProjectID = "myproject"
+		o.Requirements.Cluster.Zone = "europe-west1-b"
+		o.Requirements.Cluster.EnvironmentGitOwner = "myorg"
+		return o
+	}
+
+	o := newOptions()
+	o.Requirements.Ingress.Domain = "unknown.com"
+	err = o.Run()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no DNS zone")
+
+	o = newOptions()
+	o.LogsBucketURL = "gs://taken"
+	err = o.Run()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "already used")
+
+	o = newOptions()
+	o.Requirements.Cluster.Provider = "not-a-provider"
+	err = o.Run()
+	assert.Error(t, err)
+
+	o = newOptions()
+	o.Requirements.Cluster.ClusterName = ""
+	err = o.Run()
+	assert.Error(t, err)
+}
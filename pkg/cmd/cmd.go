@@ -24,6 +24,7 @@ import (
 	"github.com/jenkins-x/jx/pkg/cmd/ui"
 	"github.com/spf13/viper"
 
+	"github.com/jenkins-x/jx/pkg/cmd/approve"
 	"github.com/jenkins-x/jx/pkg/cmd/boot"
 	"github.com/jenkins-x/jx/pkg/cmd/compliance"
 	"github.com/jenkins-x/jx/pkg/cmd/controller"
@@ -42,6 +43,7 @@ import (
 	"github.com/jenkins-x/jx/pkg/cmd/uninstall"
 	"github.com/jenkins-x/jx/pkg/cmd/update"
 	"github.com/jenkins-x/jx/pkg/cmd/upgrade"
+	"github.com/jenkins-x/jx/pkg/cmd/wait"
 
 	"io"
 	"os"
@@ -52,15 +54,19 @@ import (
 	"syscall"
 
 	"github.com/jenkins-x/jx/pkg/cmd/add"
+	"github.com/jenkins-x/jx/pkg/cmd/admin"
 	"github.com/jenkins-x/jx/pkg/cmd/namespace"
+	"github.com/jenkins-x/jx/pkg/cmd/plugin"
 	"github.com/jenkins-x/jx/pkg/cmd/promote"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	"github.com/jenkins-x/jx/pkg/audit"
 	"github.com/jenkins-x/jx/pkg/extensions"
 
 	"github.com/jenkins-x/jx/pkg/features"
 	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/util"
 
 	"github.com/jenkins-x/jx/pkg/cmd/clients"
 	"github.com/jenkins-x/jx/pkg/cmd/opts"
@@ -76,6 +82,7 @@ func NewJXCommand(f clients.Factory, in terminal.FileReader, out terminal.FileWr
 	err io.Writer, args []string) *cobra.Command {
 
 	configureViper()
+	util.ConfigureDefaultTransport()
 	rootCommand := &cobra.Command{
 		Use:              "jx",
 		Short:            "jx is a command line tool for working with Jenkins X",
@@ -87,6 +94,8 @@ func NewJXCommand(f clients.Factory, in terminal.FileReader, out terminal.FileWr
 
 	commonOpts := opts.NewCommonOptionsWithTerm(f, in, out, err)
 	commonOpts.AddBaseFlags(rootCommand)
+	// the audit recorder is only created if the command makes a change which is audited
+	audit.SetRecorderFactory(commonOpts.NewAuditRecorder)
 
 	addCommands := add.NewCmdAdd(commonOpts)
 	createCommands := create.NewCmdCreate(commonOpts)
@@ -97,6 +106,7 @@ func NewJXCommand(f clients.Factory, in terminal.FileReader, out terminal.FileWr
 
 	installCommands := []*cobra.Command{
 		profile.NewCmdProfile(commonOpts),
+		admin.NewCmdAdmin(commonOpts),
 		boot.NewCmdBoot(commonOpts),
 		create.NewCmdInstall(commonOpts),
 		uninstall.NewCmdUninstall(commonOpts),
@@ -186,8 +196,11 @@ func NewJXCommand(f clients.Factory, in terminal.FileReader, out terminal.FileWr
 				updateCommands,
 				deleteCommands,
 				addCommands,
+				plugin.NewCmdPlugin(commonOpts),
 				start.NewCmdStart(commonOpts),
 				stop.NewCmdStop(commonOpts),
+				wait.NewCmdWait(commonOpts),
+				approve.NewCmdApprove(commonOpts),
 			},
 		},
 		{
@@ -251,13 +264,14 @@ func NewJXCommand(f clients.Factory, in terminal.FileReader, out terminal.FileWr
 		// only look for suitable executables if
 		// the specified command does not already exist
 		if _, _, err := rootCommand.Find(cmdPathPieces); err != nil {
+			environment := extensions.PluginEnvironment(pluginContext(commonOpts))
 			if _, managedPluginsEnabled := getPluginCommandGroups(); managedPluginsEnabled {
-				if err := handleEndpointExtensions(managedPlugins, cmdPathPieces); err != nil {
+				if err := handleEndpointExtensions(managedPlugins, cmdPathPieces, environment); err != nil {
 					log.Logger().Errorf("%v", err)
 					os.Exit(1)
 				}
 			} else {
-				if err := handleEndpointExtensions(localPlugins, cmdPathPieces); err != nil {
+				if err := handleEndpointExtensions(localPlugins, cmdPathPieces, environment); err != nil {
 					log.Logger().Errorf("%v", err)
 					os.Exit(1)
 				}
@@ -309,30 +323,53 @@ func setLoggingLevel(cmd *cobra.Command, args []string) {
 	if err != nil {
 		log.Logger().Errorf("Unable to check if the verbose flag is set")
 	}
+	quiet, err := strconv.ParseBool(cmd.Flag(opts.OptionQuiet).Value.String())
+	if err != nil {
+		log.Logger().Errorf("Unable to check if the quiet flag is set")
+	}
 
-	level := os.Getenv("JX_LOG_LEVEL")
-	if level != "" {
-		if verbose {
-			log.Logger().Trace("The JX_LOG_LEVEL environment variable took precedence over the verbose flag")
+	format := cmd.Flag(opts.OptionLogFormat).Value.String()
+	if format != "" {
+		err := log.SetFormat(format)
+		if err != nil {
+			log.Logger().Errorf("Unable to set log format: %s", err)
 		}
+	}
 
-		err := log.SetLevel(level)
-		if err != nil {
-			log.Logger().Errorf("Unable to set log level to %s", level)
+	level := cmd.Flag(opts.OptionLogLevel).Value.String()
+	if level == "" {
+		level = os.Getenv("JX_LOG_LEVEL")
+		if level != "" && verbose {
+			log.Logger().Trace("The JX_LOG_LEVEL environment variable took precedence over the verbose flag")
 		}
-	} else {
-		if verbose {
-			err := log.SetLevel("debug")
-			if err != nil {
-				log.Logger().Errorf("Unable to set log level to debug")
-			}
-		} else {
-			err := log.SetLevel("info")
-			if err != nil {
-				log.Logger().Errorf("Unable to set log level to info")
+	}
+	if level == "" {
+		switch {
+		case verbose:
+			level = "debug"
+			if quiet {
+				log.Logger().Warnf("Ignoring the --%s flag as the --%s flag is also set", opts.OptionQuiet, opts.OptionVerbose)
 			}
+		case quiet:
+			level = "warn"
+		default:
+			level = "info"
 		}
 	}
+	err = log.SetLevel(level)
+	if err != nil {
+		log.Logger().Errorf("Unable to set log level to %s", level)
+	}
+
+	fields := map[string]interface{}{
+		"command": cmd.CommandPath(),
+	}
+	owner := os.Getenv("REPO_OWNER")
+	repo := os.Getenv("REPO_NAME")
+	if owner != "" && repo != "" {
+		fields["repo"] = owner + "/" + repo
+	}
+	log.AddFields(fields)
 }
 
 func runHelp(cmd *cobra.Command, args []string) {
@@ -393,6 +430,12 @@ type localPluginHandler struct{}
 
 // Lookup implements PluginHandler
 func (h *localPluginHandler) Lookup(filename string) (string, error) {
+	// plugins installed via 'jx plugin install' take precedence over those on the PATH
+	path, err := extensions.LookupInstalledPlugin(filename)
+	if err == nil && path != "" {
+		return path, nil
+	}
+
 	// if on Windows, append the "exe" extension
 	// to the filename that we are looking up.
 	if runtime.GOOS == "windows" {
@@ -407,7 +450,35 @@ func (h *localPluginHandler) Execute(executablePath string, cmdArgs, environment
 	return syscall.Exec(executablePath, cmdArgs, environment)
 }
 
-func handleEndpointExtensions(pluginHandler PluginHandler, cmdArgs []string) error {
+// pluginContext returns the context of the current jx session to pass to plugins as environment variables.
+// Any context which cannot be determined, such as when not connected to a cluster, is omitted
+func pluginContext(commonOpts *opts.CommonOptions) map[string]string {
+	context := map[string]string{}
+	kubeConfig := util.KubeConfigFile()
+	if exists, err := util.FileExists(kubeConfig); err == nil && exists {
+		context["KUBECONFIG"] = kubeConfig
+	}
+	if configDir, err := util.ConfigDir(); err == nil {
+		context["JX_HOME"] = configDir
+	}
+	if _, ns, err := commonOpts.KubeClientAndDevNamespace(); err == nil {
+		context["JX_TEAM_NAMESPACE"] = ns
+	}
+	if authConfigSvc, err := commonOpts.GitAuthConfigService(); err == nil {
+		server := authConfigSvc.Config().CurrentAuthServer()
+		if server != nil {
+			context["JX_GIT_SERVER"] = server.URL
+			auth := server.CurrentAuth()
+			if auth != nil {
+				context["JX_GIT_USERNAME"] = auth.Username
+				context["JX_GIT_TOKEN"] = auth.ApiToken
+			}
+		}
+	}
+	return context
+}
+
+func handleEndpointExtensions(pluginHandler PluginHandler, cmdArgs []string, environment []string) error {
 	remainingArgs := []string{} // all "non-flag" arguments
 
 	for idx := range cmdArgs {
@@ -440,10 +511,10 @@ func handleEndpointExtensions(pluginHandler PluginHandler, cmdArgs []string) err
 		return nil
 	}
 
-	// invoke cmd binary relaying the current environment and args given
+	// invoke cmd binary relaying the given environment and args given
 	// remainingArgs will always have at least one element.
 	// execve will make remainingArgs[0] the "binary name".
-	if err := pluginHandler.Execute(foundBinaryPath, append([]string{foundBinaryPath}, cmdArgs[len(remainingArgs):]...), os.Environ()); err != nil {
+	if err := pluginHandler.Execute(foundBinaryPath, append([]string{foundBinaryPath}, cmdArgs[len(remainingArgs):]...), environment); err != nil {
 		return err
 	}
 
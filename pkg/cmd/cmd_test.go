@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/jenkins-x/jx/pkg/cmd/clients/fake"
+	"github.com/jenkins-x/jx/pkg/cmd/opts"
 	"github.com/jenkins-x/jx/pkg/log"
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
@@ -82,3 +83,79 @@ func TestLoggingSetup(t *testing.T) {
 		})
 	}
 }
+
+func TestQuietAndVerboseFlags(t *testing.T) {
+	origLogLevel, exists := os.LookupEnv("JX_LOG_LEVEL")
+	err := os.Unsetenv("JX_LOG_LEVEL")
+	assert.NoError(t, err)
+	defer func() {
+		if exists {
+			_ = os.Setenv("JX_LOG_LEVEL", origLogLevel)
+		}
+		_ = log.SetLevel("info")
+	}()
+
+	var flagTests = []struct {
+		args           []string
+		expectedOutput string
+	}{
+		{nil, "info\nWARNING: warn\nERROR: error\n"},
+		{[]string{"--quiet"}, "WARNING: warn\nERROR: error\n"},
+		{[]string{"-q"}, "WARNING: warn\nERROR: error\n"},
+		{[]string{"--verbose"}, "DEBUG: debug\ninfo\nWARNING: warn\nERROR: error\n"},
+		{[]string{"--quiet", "--log-level", "error"}, "ERROR: error\n"},
+	}
+
+	testCommandName := "flagtest"
+	for _, flagTest := range flagTests {
+		t.Run(fmt.Sprintf("%v", flagTest.args), func(t *testing.T) {
+			flagCommand := &cobra.Command{
+				Use:   testCommandName,
+				Short: "dummy test command",
+				Run: func(cmd *cobra.Command, args []string) {
+					out := log.CaptureOutput(func() {
+						log.Logger().Debug("debug")
+						log.Logger().Info("info")
+						log.Logger().Warn("warn")
+						log.Logger().Error("error")
+					})
+
+					assert.Equal(t, flagTest.expectedOutput, out)
+				},
+			}
+
+			rootCmd := NewJXCommand(fake.NewFakeFactory(), os.Stdin, os.Stdout, os.Stderr, nil)
+			rootCmd.AddCommand(flagCommand)
+			rootCmd.SetArgs(append([]string{testCommandName}, flagTest.args...))
+			_ = log.CaptureOutput(func() {
+				err := rootCmd.Execute()
+				assert.NoError(t, err)
+			})
+		})
+	}
+}
+
+func TestAllCommandsSupportQuietAndVerbose(t *testing.T) {
+	rootCmd := NewJXCommand(fake.NewFakeFactory(), os.Stdin, os.Stdout, os.Stderr, nil)
+	quiet := rootCmd.PersistentFlags().Lookup(opts.OptionQuiet)
+	verbose := rootCmd.PersistentFlags().Lookup(opts.OptionVerbose)
+	assert.NotNil(t, quiet)
+	assert.NotNil(t, verbose)
+
+	var checkCommand func(cmd *cobra.Command)
+	checkCommand = func(cmd *cobra.Command) {
+		path := cmd.CommandPath()
+		// the flags must not be shadowed by local flags of the same name
+		assert.True(t, quiet == cmd.Flag(opts.OptionQuiet), "command %s does not inherit the --%s flag", path, opts.OptionQuiet)
+		assert.True(t, verbose == cmd.Flag(opts.OptionVerbose), "command %s does not inherit the --%s flag", path, opts.OptionVerbose)
+		if cmd != rootCmd {
+			// a persistent pre run would stop the logging level being configured from the flags
+			assert.Nil(t, cmd.PersistentPreRun, "command %s overrides the logging configuration", path)
+			assert.Nil(t, cmd.PersistentPreRunE, "command %s overrides the logging configuration", path)
+		}
+		for _, child := range cmd.Commands() {
+			checkCommand(child)
+		}
+	}
+	checkCommand(rootCmd)
+}
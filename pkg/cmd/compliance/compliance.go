@@ -1,9 +1,18 @@
 package compliance
 
 import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/ghodss/yaml"
 	"github.com/heptio/sonobuoy/pkg/buildinfo"
 	"github.com/jenkins-x/jx/pkg/cmd/helper"
 	"github.com/jenkins-x/jx/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/pkg/compliance"
+	"github.com/jenkins-x/jx/pkg/kube"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 )
 
@@ -45,6 +54,7 @@ func NewCompliance(commonOpts *opts.CommonOptions) *cobra.Command {
 	cmd.AddCommand(NewCmdComplianceRun(commonOpts))
 	cmd.AddCommand(NewCmdComplianceDelete(commonOpts))
 	cmd.AddCommand(NewCmdComplianceLogs(commonOpts))
+	cmd.AddCommand(NewCmdComplianceHistory(commonOpts))
 
 	return cmd
 }
@@ -53,3 +63,35 @@ func NewCompliance(commonOpts *opts.CommonOptions) *cobra.Command {
 func (o *ComplianceOptions) Run() error {
 	return o.Cmd.Help()
 }
+
+// newComplianceStore returns the store of the compliance reports in the bucket of the compliance storage location
+func newComplianceStore(o *opts.CommonOptions) (*compliance.Store, error) {
+	teamSettings, err := o.TeamSettings()
+	if err != nil {
+		return nil, errors.Wrap(err, "loading the team settings")
+	}
+	location := teamSettings.StorageLocationOrDefault(kube.ClassificationCompliance)
+	if location.BucketURL == "" {
+		return nil, fmt.Errorf("no bucket URL is configured for the %s storage location. Use 'jx edit storage -c %s' to configure it", util.ColorInfo(kube.ClassificationCompliance), kube.ClassificationCompliance)
+	}
+	return compliance.NewStore(location.BucketURL), nil
+}
+
+// renderResult renders the result in a given output format
+func renderResult(out io.Writer, value interface{}, format string) error {
+	var data []byte
+	var err error
+	switch format {
+	case "json":
+		data, err = json.MarshalIndent(value, "", "  ")
+	case "yaml":
+		data, err = yaml.Marshal(value)
+	default:
+		return fmt.Errorf("unsupported output format: %s", format)
+	}
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(out, string(data))
+	return err
+}
@@ -0,0 +1,132 @@
+package compliance
+
+import (
+	"strconv"
+
+	"github.com/jenkins-x/jx/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/pkg/compliance"
+	"github.com/jenkins-x/jx/pkg/kube"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var (
+	complianceHistoryLong = templates.LongDesc(`
+		Shows the history of the compliance results stored with 'jx compliance results --store'
+
+		The number of new failures of each run is the number of tests which failed in the run but not in the
+		previous run.
+	`)
+
+	complianceHistoryExample = templates.Examples(`
+		# Show the history of the compliance results
+		jx compliance history
+
+		# Output the history of the compliance results as JSON
+		jx compliance history -o json
+	`)
+)
+
+// ComplianceHistoryOptions options for "compliance history" command
+type ComplianceHistoryOptions struct {
+	*opts.CommonOptions
+
+	Output string
+	Limit  int
+}
+
+// complianceHistoryEntry the summary of a stored run compared with the previous stored run
+type complianceHistoryEntry struct {
+	Name        string             `json:"name"`
+	Status      string             `json:"status"`
+	Summary     compliance.Summary `json:"summary"`
+	NewFailures int                `json:"newFailures"`
+	Fixed       int                `json:"fixed"`
+}
+
+// NewCmdComplianceHistory creates a command object for the "compliance history" action, which
+// shows the history of the stored compliance results
+func NewCmdComplianceHistory(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &ComplianceHistoryOptions{
+		CommonOptions: commonOpts,
+	}
+
+	cmd := &cobra.Command{
+		Use:     "history",
+		Short:   "Shows the history of the stored compliance results",
+		Long:    complianceHistoryLong,
+		Example: complianceHistoryExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.Flags().StringVarP(&options.Output, "output", "o", "", "The output format such as 'json' or 'yaml'")
+	cmd.Flags().IntVarP(&options.Limit, "limit", "l", 20, "The maximum number of the most recent runs to show. Shows all the runs if 0")
+	return cmd
+}
+
+// Run implements the "compliance history" command
+func (o *ComplianceHistoryOptions) Run() error {
+	if o.Output != "" && o.Output != "json" && o.Output != "yaml" {
+		return util.InvalidOption("output", o.Output, []string{"json", "yaml"})
+	}
+	store, err := newComplianceStore(o.CommonOptions)
+	if err != nil {
+		return err
+	}
+	names, err := store.List()
+	if err != nil {
+		return errors.Wrapf(err, "listing the compliance results in %s", store.BucketURL)
+	}
+	start := 0
+	if o.Limit > 0 && len(names) > o.Limit {
+		// load one more run so that the oldest run shown can be compared with its previous run
+		start = len(names) - o.Limit - 1
+	}
+
+	history := []complianceHistoryEntry{}
+	var previous *compliance.Report
+	for i := start; i < len(names); i++ {
+		report, err := store.Load(names[i])
+		if err != nil {
+			return errors.Wrapf(err, "loading the compliance results %s", names[i])
+		}
+		entry := complianceHistoryEntry{
+			Name:    report.Name,
+			Status:  report.Status,
+			Summary: report.Summary,
+		}
+		if previous != nil {
+			comparison := compliance.Compare(previous, report)
+			entry.NewFailures = len(comparison.NewFailures)
+			entry.Fixed = len(comparison.Fixed)
+		}
+		previous = report
+		if o.Limit > 0 && len(names)-i > o.Limit {
+			continue
+		}
+		history = append(history, entry)
+	}
+
+	if o.Output != "" {
+		return renderResult(o.Out, history, o.Output)
+	}
+	if len(history) == 0 {
+		log.Logger().Infof("No compliance results are stored in the %s storage location. Use %s to store them.", util.ColorInfo(kube.ClassificationCompliance), util.ColorInfo("jx compliance results --store"))
+		return nil
+	}
+	table := o.CreateTable()
+	table.AddRow("NAME", "STATUS", "PASSED", "FAILED", "SKIPPED", "NEW FAILURES", "FIXED")
+	for _, entry := range history {
+		table.AddRow(entry.Name, entry.Status, strconv.Itoa(entry.Summary.Passed), strconv.Itoa(entry.Summary.Failed), strconv.Itoa(entry.Summary.Skipped), strconv.Itoa(entry.NewFailures), strconv.Itoa(entry.Fixed))
+	}
+	table.Render()
+	return nil
+}
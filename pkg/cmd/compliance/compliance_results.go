@@ -2,19 +2,18 @@ package compliance
 
 import (
 	"archive/tar"
-	"compress/gzip"
 	"io"
 	"os"
-	"sort"
 	"strings"
-
-	"github.com/jenkins-x/jx/pkg/cmd/helper"
+	"time"
 
 	"github.com/heptio/sonobuoy/pkg/client"
-	"github.com/heptio/sonobuoy/pkg/client/results"
 	"github.com/heptio/sonobuoy/pkg/plugin/aggregation"
+	"github.com/jenkins-x/jx/pkg/cmd/helper"
 	"github.com/jenkins-x/jx/pkg/cmd/opts"
 	"github.com/jenkins-x/jx/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/pkg/compliance"
+	"github.com/jenkins-x/jx/pkg/kube"
 	"github.com/jenkins-x/jx/pkg/log"
 	"github.com/jenkins-x/jx/pkg/util"
 	"github.com/pkg/errors"
@@ -25,17 +24,36 @@ import (
 var (
 	complianceResultsLong = templates.LongDesc(`
 		Shows the results of the compliance tests
+
+		The results can be stored in the '` + kube.ClassificationCompliance + `' storage location so that they can be
+		compared with later runs. Use 'jx edit storage' to configure the bucket of the storage location.
 	`)
 
 	complianceResultsExample = templates.Examples(`
 		# Show the compliance results
 		jx compliance results
+
+		# Output the compliance results as JSON for a compliance reporting pipeline
+		jx compliance results -o json
+
+		# Store the compliance results and show the differences with the previously stored results
+		jx compliance results --store --compare
 	`)
 )
 
 // ComplianceResultsOptions options for "compliance results" command
 type ComplianceResultsOptions struct {
 	*opts.CommonOptions
+
+	Output  string
+	Store   bool
+	Compare bool
+}
+
+// complianceResults the results of a run optionally compared with the previous run
+type complianceResults struct {
+	*compliance.Report
+	Comparison *compliance.Comparison `json:"comparison,omitempty"`
 }
 
 // NewCmdComplianceResults creates a command object for the "compliance results" action, which
@@ -57,12 +75,17 @@ func NewCmdComplianceResults(commonOpts *opts.CommonOptions) *cobra.Command {
 			helper.CheckErr(err)
 		},
 	}
-
+	cmd.Flags().StringVarP(&options.Output, "output", "o", "", "The output format such as 'json' or 'yaml'")
+	cmd.Flags().BoolVarP(&options.Store, "store", "s", false, "Stores the results in the '"+kube.ClassificationCompliance+"' storage location")
+	cmd.Flags().BoolVarP(&options.Compare, "compare", "c", false, "Compares the results with the latest results in the '"+kube.ClassificationCompliance+"' storage location")
 	return cmd
 }
 
 // Run implements the "compliance results" command
 func (o *ComplianceResultsOptions) Run() error {
+	if o.Output != "" && o.Output != "json" && o.Output != "yaml" {
+		return util.InvalidOption("output", o.Output, []string{"json", "yaml"})
+	}
 	cc, err := o.ComplianceClient()
 	if err != nil {
 		return errors.Wrap(err, "could not create the compliance client")
@@ -90,25 +113,16 @@ func (o *ComplianceResultsOptions) Run() error {
 	if err != nil {
 		return errors.Wrap(err, "retrieving the compliance results")
 	}
+	var testResults []compliance.TestResult
 	eg := &errgroup.Group{}
 	eg.Go(func() error { return <-errch })
 	eg.Go(func() error {
 		resultsReader, ec := untarResults(reader)
-		gzr, err := gzip.NewReader(resultsReader)
-		if err != nil {
-			return errors.Wrap(err, "could not create a gzip reader for compliance results ")
-		}
-
-		testResults, err := cc.GetTests(gzr, "all")
+		tests, err := compliance.ParseResults(resultsReader)
 		if err != nil {
 			return errors.Wrap(err, "could not get the results of the compliance tests from the archive")
 		}
-		testResults = filterTests(
-			func(tc results.JUnitTestCase) bool {
-				return !results.JUnitSkipped(tc)
-			}, testResults)
-		sort.Sort(StatusSortedTestCases(testResults))
-		o.printResults(testResults)
+		testResults = tests
 
 		err = <-ec
 		if err != nil {
@@ -121,6 +135,43 @@ func (o *ComplianceResultsOptions) Run() error {
 	if err != nil {
 		log.Logger().Infof("No compliance results found. Use %s command to start the compliance tests.", util.ColorInfo("jx compliance run"))
 		log.Logger().Infof("You can watch the logs with %s command.", util.ColorInfo("jx compliance logs -f"))
+		return nil
+	}
+
+	results := &complianceResults{
+		Report: compliance.NewReport(status.Status, testResults, time.Now()),
+	}
+	if o.Store || o.Compare {
+		store, err := newComplianceStore(o.CommonOptions)
+		if err != nil {
+			return err
+		}
+		if o.Compare {
+			previous, err := store.Latest()
+			if err != nil {
+				return errors.Wrapf(err, "loading the latest compliance results from %s", store.BucketURL)
+			}
+			if previous == nil {
+				log.Logger().Warnf("There are no stored compliance results to compare with")
+			} else {
+				results.Comparison = compliance.Compare(previous, results.Report)
+			}
+		}
+		if o.Store {
+			key, err := store.Write(results.Report)
+			if err != nil {
+				return err
+			}
+			log.Logger().Infof("Stored the compliance results %s in %s", util.ColorInfo(key), util.ColorInfo(store.BucketURL))
+		}
+	}
+
+	if o.Output != "" {
+		return renderResult(o.Out, results, o.Output)
+	}
+	o.printResults(results.Report)
+	if results.Comparison != nil {
+		o.printComparison(results.Comparison)
 	}
 	return nil
 }
@@ -130,45 +181,35 @@ func (o *ComplianceResultsOptions) Exit(status int) {
 	os.Exit(status)
 }
 
-// StatusSortedTestCases implements Sort by status of a list of test case
-type StatusSortedTestCases []results.JUnitTestCase
-
-var statuses = map[string]int{
-	"FAILED":  0,
-	"PASSED":  1,
-	"SKIPPED": 2,
-	"UNKNOWN": 3,
-}
-
-func (s StatusSortedTestCases) Len() int { return len(s) }
-func (s StatusSortedTestCases) Less(i, j int) bool {
-	si := statuses[status(s[i])]
-	sj := statuses[status(s[j])]
-	return si < sj
-}
-func (s StatusSortedTestCases) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
-
-func (o *ComplianceResultsOptions) printResults(junitResults []results.JUnitTestCase) {
+func (o *ComplianceResultsOptions) printResults(report *compliance.Report) {
 	table := o.CreateTable()
 	table.SetColumnAlign(1, util.ALIGN_LEFT)
 	table.SetColumnAlign(2, util.ALIGN_LEFT)
-	table.AddRow("STATUS", "TEST", "TEST-CLASS")
-	for _, t := range junitResults {
-		table.AddRow(status(t), t.Name, t.Classname)
+	table.SetColumnAlign(3, util.ALIGN_LEFT)
+	table.AddRow("STATUS", "PLUGIN", "TEST", "TEST-CLASS")
+	for _, t := range report.Tests {
+		table.AddRow(t.Status, t.Plugin, t.Name, t.Class)
 	}
 	table.Render()
+	log.Logger().Infof("Passed: %d, failed: %d, skipped: %d", report.Summary.Passed, report.Summary.Failed, report.Summary.Skipped)
 }
 
-func status(junitResult results.JUnitTestCase) string {
-	if results.JUnitSkipped(junitResult) {
-		return "SKIPPED"
-	} else if results.JUnitFailed(junitResult) {
-		return "FAILED"
-	} else if results.JUnitPassed(junitResult) {
-		return "PASSED"
-	} else {
-		return "UNKNOWN"
+func (o *ComplianceResultsOptions) printComparison(comparison *compliance.Comparison) {
+	log.Logger().Infof("\nCompared with the results %s:", util.ColorInfo(comparison.Previous))
+	table := o.CreateTable()
+	table.SetColumnAlign(1, util.ALIGN_LEFT)
+	table.SetColumnAlign(2, util.ALIGN_LEFT)
+	table.AddRow("CHANGE", "PLUGIN", "TEST")
+	for _, t := range comparison.NewFailures {
+		table.AddRow(util.ColorError("NEW FAILURE"), t.Plugin, t.Name)
+	}
+	for _, t := range comparison.Fixed {
+		table.AddRow(util.ColorInfo("FIXED"), t.Plugin, t.Name)
+	}
+	for _, t := range comparison.StillFailing {
+		table.AddRow("STILL FAILING", t.Plugin, t.Name)
 	}
+	table.Render()
 }
 
 func untarResults(src io.Reader) (io.Reader, <-chan error) {
@@ -199,13 +240,3 @@ func untarResults(src io.Reader) (io.Reader, <-chan error) {
 	}
 	return reader, ec
 }
-
-func filterTests(predicate func(testCase results.JUnitTestCase) bool, testCases []results.JUnitTestCase) []results.JUnitTestCase {
-	out := make([]results.JUnitTestCase, 0)
-	for _, tc := range testCases {
-		if predicate(tc) {
-			out = append(out, tc)
-		}
-	}
-	return out
-}
@@ -1,31 +1,59 @@
 package compliance
 
 import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/ghodss/yaml"
 	"github.com/heptio/sonobuoy/pkg/client"
 	"github.com/heptio/sonobuoy/pkg/config"
+	"github.com/heptio/sonobuoy/pkg/plugin"
+	"github.com/heptio/sonobuoy/pkg/plugin/manifest"
 	"github.com/jenkins-x/jx/pkg/cmd/helper"
 	"github.com/jenkins-x/jx/pkg/cmd/opts"
 	"github.com/jenkins-x/jx/pkg/cmd/templates"
 	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/util"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	v1 "k8s.io/api/core/v1"
 )
 
+// compliancePluginsDir the directory of the dev environment repository containing the custom compliance plugins
+const compliancePluginsDir = "compliance/plugins"
+
 var (
 	complianceRuntLong = templates.LongDesc(`
 		Runs the compliance tests
+
+		As well as the Kubernetes conformance tests, custom suites such as CIS benchmarks or the checks of your
+		organisation can be run as Sonobuoy plugins. Any plugin definitions in the ` + compliancePluginsDir + ` directory of
+		the development environment repository are run along with the plugins specified with --plugin.
+
+		See https://github.com/heptio/sonobuoy/blob/master/docs/plugins.md for how to define a plugin.
 	`)
 
 	complianceRunExample = templates.Examples(`
 		# Run the compliance tests
 		jx compliance run
+
+		# Run the conformance tests and the custom plugins of a clone of the development environment repository
+		jx compliance run --dir ./environment-mycluster-dev
+
+		# Run only a custom plugin
+		jx compliance run --conformance=false --plugin cis-benchmark.yaml
 	`)
 )
 
 // ComplianceRunOptions options for "compliance run" command
 type ComplianceRunOptions struct {
 	*opts.CommonOptions
+
+	Dir         string
+	Plugins     []string
+	Conformance bool
 }
 
 // NewCmdComplianceRun creates a command object for the "compliance run" action, which
@@ -47,7 +75,9 @@ func NewCmdComplianceRun(commonOpts *opts.CommonOptions) *cobra.Command {
 			helper.CheckErr(err)
 		},
 	}
-
+	cmd.Flags().StringVarP(&options.Dir, "dir", "d", ".", "The directory of the development environment repository containing the custom plugins in "+compliancePluginsDir)
+	cmd.Flags().StringArrayVarP(&options.Plugins, "plugin", "p", nil, "The files of the custom plugins to run")
+	cmd.Flags().BoolVarP(&options.Conformance, "conformance", "", true, "Runs the Kubernetes conformance tests")
 	return cmd
 }
 
@@ -57,7 +87,14 @@ func (o *ComplianceRunOptions) Run() error {
 	if err != nil {
 		return errors.Wrap(err, "could not create the compliance client")
 	}
-	cfg := o.config()
+	plugins, err := o.loadPlugins()
+	if err != nil {
+		return err
+	}
+	if !o.Conformance && len(plugins) == 0 {
+		return fmt.Errorf("no compliance tests to run as the conformance tests are disabled and there are no custom plugins")
+	}
+	cfg := o.config(plugins)
 	if err := cc.Run(cfg); err != nil {
 		return errors.Wrap(err, "failed to start the compliance tests")
 	}
@@ -67,7 +104,7 @@ func (o *ComplianceRunOptions) Run() error {
 	return nil
 }
 
-func (o *ComplianceRunOptions) config() *client.RunConfig {
+func (o *ComplianceRunOptions) config(plugins []*manifest.Manifest) *client.RunConfig {
 	modeName := client.CertifiedConformance
 	mode := modeName.Get()
 	genCfg := &client.GenConfig{
@@ -76,6 +113,17 @@ func (o *ComplianceRunOptions) config() *client.RunConfig {
 		EnableRBAC:           true,
 		ImagePullPolicy:      string(v1.PullAlways),
 		KubeConformanceImage: kubeConformanceImage,
+		StaticPlugins:        plugins,
+	}
+	if o.Conformance {
+		for _, selection := range mode.Selectors {
+			genCfg.DynamicPlugins = append(genCfg.DynamicPlugins, selection.Name)
+		}
+	} else {
+		genCfg.Config.PluginSelections = nil
+	}
+	for _, m := range plugins {
+		genCfg.Config.PluginSelections = append(genCfg.Config.PluginSelections, plugin.Selection{Name: m.SonobuoyConfig.PluginName})
 	}
 	return &client.RunConfig{
 		GenConfig: *genCfg,
@@ -92,3 +140,65 @@ func (o *ComplianceRunOptions) getConfigWithMode(mode client.Mode) *config.Confi
 	}
 	return cfg
 }
+
+// loadPlugins loads the definitions of the custom plugins in the development environment repository and the
+// plugin files
+func (o *ComplianceRunOptions) loadPlugins() ([]*manifest.Manifest, error) {
+	files := []string{}
+	if o.Dir != "" {
+		dir := filepath.Join(o.Dir, compliancePluginsDir)
+		exists, err := util.DirExists(dir)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			fileInfos, err := ioutil.ReadDir(dir)
+			if err != nil {
+				return nil, errors.Wrapf(err, "reading the compliance plugins directory %s", dir)
+			}
+			for _, fileInfo := range fileInfos {
+				ext := filepath.Ext(fileInfo.Name())
+				if !fileInfo.IsDir() && (ext == ".yaml" || ext == ".yml") {
+					files = append(files, filepath.Join(dir, fileInfo.Name()))
+				}
+			}
+		}
+	}
+	files = append(files, o.Plugins...)
+
+	answer := []*manifest.Manifest{}
+	names := []string{}
+	for _, file := range files {
+		m, err := loadPlugin(file)
+		if err != nil {
+			return nil, err
+		}
+		name := m.SonobuoyConfig.PluginName
+		if util.StringArrayIndex(names, name) >= 0 {
+			return nil, fmt.Errorf("duplicate compliance plugin %s in %s", name, file)
+		}
+		names = append(names, name)
+		answer = append(answer, m)
+	}
+	if len(names) > 0 {
+		log.Logger().Infof("Running the custom compliance plugins %s", util.ColorInfo(strings.Join(names, ", ")))
+	}
+	return answer, nil
+}
+
+// loadPlugin loads the definition of a Sonobuoy plugin from a YAML file
+func loadPlugin(file string) (*manifest.Manifest, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading the compliance plugin %s", file)
+	}
+	m := &manifest.Manifest{}
+	err = yaml.Unmarshal(data, m)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parsing the compliance plugin %s", file)
+	}
+	if m.SonobuoyConfig.PluginName == "" {
+		return nil, fmt.Errorf("the compliance plugin %s has no sonobuoy-config.plugin-name", file)
+	}
+	return m, nil
+}
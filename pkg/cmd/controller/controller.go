@@ -43,10 +43,15 @@ func NewCmdController(commonOpts *opts.CommonOptions) *cobra.Command {
 		},
 	}
 
+	cmd.AddCommand(NewCmdControllerAutoMerge(commonOpts))
 	cmd.AddCommand(NewCmdControllerBackup(commonOpts))
 	cmd.AddCommand(NewCmdControllerBuild(commonOpts))
 	cmd.AddCommand(NewCmdControllerBuildNumbers(commonOpts))
+	cmd.AddCommand(NewCmdControllerCronPipelines(commonOpts))
+	cmd.AddCommand(NewCmdControllerDependencyUpdate(commonOpts))
+	cmd.AddCommand(NewCmdControllerDiscovery(commonOpts))
 	cmd.AddCommand(NewCmdControllerEnvironment(commonOpts))
+	cmd.AddCommand(NewCmdControllerGC(commonOpts))
 	cmd.AddCommand(pipeline.NewCmdControllerPipelineRunner(commonOpts))
 	cmd.AddCommand(NewCmdControllerRole(commonOpts))
 	cmd.AddCommand(NewCmdControllerTeam(commonOpts))
@@ -0,0 +1,170 @@
+package controller
+
+import (
+	"strings"
+	"time"
+
+	"github.com/jenkins-x/jx/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/pkg/gits"
+	"github.com/jenkins-x/jx/pkg/kube"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ControllerAutoMergeOptions the options for the auto merge controller
+type ControllerAutoMergeOptions struct {
+	ControllerOptions
+
+	Label          string
+	RequiredChecks []string
+	PollPeriod     time.Duration
+	Once           bool
+}
+
+var (
+	controllerAutoMergeLong = templates.LongDesc(`
+		Runs the auto merge controller which merges any open Pull Requests with the auto merge label
+		once all of their required checks have passed.
+
+		The Pull Requests are looked up on the git repositories of all the SourceRepository resources in the
+		development namespace. The label is added by commands such as 'jx promote --auto-merge' when the git
+		provider does not support native auto merge.
+`)
+
+	controllerAutoMergeExample = templates.Examples(`
+		# merge labelled Pull Requests once all their checks pass
+		jx controller automerge
+
+		# only wait for the given checks
+		jx controller automerge --required-check pr-build --required-check integration
+	`)
+)
+
+// NewCmdControllerAutoMerge creates the command for the auto merge controller
+func NewCmdControllerAutoMerge(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &ControllerAutoMergeOptions{
+		ControllerOptions: ControllerOptions{
+			CommonOptions: commonOpts,
+		},
+	}
+
+	cmd := &cobra.Command{
+		Use:     "automerge",
+		Short:   "Merges labelled Pull Requests once their required checks pass",
+		Long:    controllerAutoMergeLong,
+		Example: controllerAutoMergeExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.Flags().StringVarP(&options.Label, "label", "l", gits.LabelAutoMerge, "The label of the Pull Requests to merge")
+	cmd.Flags().StringArrayVarP(&options.RequiredChecks, "required-check", "c", []string{}, "The names of the checks which must pass before merging. If none are specified all of the checks on the last commit must pass")
+	cmd.Flags().DurationVarP(&options.PollPeriod, "poll-period", "p", time.Minute, "The period between checking the Pull Requests")
+	cmd.Flags().BoolVarP(&options.Once, "once", "", false, "Only check the Pull Requests once and then terminate")
+	return cmd
+}
+
+// Run implements this command
+func (o *ControllerAutoMergeOptions) Run() error {
+	// Always run in batch mode as a controller is never run interactively
+	o.BatchMode = true
+
+	for {
+		err := o.mergePullRequests()
+		if err != nil {
+			log.Logger().Warnf("Failed to merge Pull Requests: %s", err)
+		}
+		if o.Once {
+			return err
+		}
+		time.Sleep(o.PollPeriod)
+	}
+}
+
+func (o *ControllerAutoMergeOptions) mergePullRequests() error {
+	jxClient, ns, err := o.JXClientAndDevNamespace()
+	if err != nil {
+		return err
+	}
+	repositories, err := jxClient.JenkinsV1().SourceRepositories(ns).List(metav1.ListOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "listing SourceRepositories in namespace %s", ns)
+	}
+	for i := range repositories.Items {
+		sr := &repositories.Items[i]
+		gitURL, err := kube.GetRepositoryGitURL(sr)
+		if err != nil {
+			log.Logger().Warnf("Ignoring SourceRepository %s: %s", sr.Name, err)
+			continue
+		}
+		provider, err := o.GitProviderForURL(gitURL, "git provider")
+		if err != nil {
+			log.Logger().Warnf("Failed to create git provider for %s: %s", gitURL, err)
+			continue
+		}
+		err = o.mergeRepositoryPullRequests(provider, sr.Spec.Org, sr.Spec.Repo)
+		if err != nil {
+			log.Logger().Warnf("Failed to merge Pull Requests on %s/%s: %s", sr.Spec.Org, sr.Spec.Repo, err)
+		}
+	}
+	return nil
+}
+
+func (o *ControllerAutoMergeOptions) mergeRepositoryPullRequests(provider gits.GitProvider, owner string, repo string) error {
+	prs, err := gits.FilterOpenPullRequests(provider, owner, repo, gits.PullRequestFilter{
+		Labels: []string{o.Label},
+	})
+	if err != nil {
+		return err
+	}
+	for _, pr := range prs {
+		if pr.Owner == "" {
+			pr.Owner = owner
+		}
+		if pr.Repo == "" {
+			pr.Repo = repo
+		}
+		err = provider.UpdatePullRequestStatus(pr)
+		if err != nil {
+			log.Logger().Warnf("Failed to query Pull Request %s: %s", pr.URL, err)
+			continue
+		}
+		if pr.Mergeable != nil && !*pr.Mergeable {
+			log.Logger().Infof("Pull Request %s is not mergeable", util.ColorInfo(pr.URL))
+			continue
+		}
+		passed, waiting, err := gits.RequiredChecksPassed(provider, pr, o.RequiredChecks)
+		if err != nil {
+			log.Logger().Warnf("Not merging Pull Request %s: %s", pr.URL, err)
+			continue
+		}
+		if !passed {
+			log.Logger().Debugf("Pull Request %s is waiting for checks: %s", pr.URL, strings.Join(waiting, ", "))
+			continue
+		}
+		openTasks, err := gits.PullRequestOpenTasks(provider, pr)
+		if err != nil {
+			log.Logger().Warnf("Failed to count the open tasks of Pull Request %s: %s", pr.URL, err)
+			continue
+		}
+		if openTasks > 0 {
+			log.Logger().Debugf("Pull Request %s is waiting for %d open tasks to be resolved", pr.URL, openTasks)
+			continue
+		}
+		err = provider.MergePullRequest(pr, "jx automatically merged the Pull Request as all its required checks passed")
+		if err != nil {
+			log.Logger().Warnf("Failed to merge Pull Request %s: %s", pr.URL, err)
+			continue
+		}
+		log.Logger().Infof("Merged Pull Request %s", util.ColorInfo(pr.URL))
+	}
+	return nil
+}
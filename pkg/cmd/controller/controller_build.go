@@ -13,8 +13,14 @@ import (
 	"github.com/jenkins-x/jx/pkg/cmd/helper"
 	"github.com/jenkins-x/jx/pkg/cmd/opts"
 	"github.com/jenkins-x/jx/pkg/cmd/step/git"
+	"github.com/jenkins-x/jx/pkg/eventbus"
 	"github.com/jenkins-x/jx/pkg/gits"
+	"github.com/jenkins-x/jx/pkg/kube/leaderelection"
 	"github.com/jenkins-x/jx/pkg/logs"
+	"github.com/jenkins-x/jx/pkg/mergequeue"
+	"github.com/jenkins-x/jx/pkg/notify"
+	"github.com/jenkins-x/jx/pkg/observability"
+	"github.com/prometheus/client_golang/prometheus"
 	"k8s.io/apimachinery/pkg/fields"
 
 	"github.com/jenkins-x/jx/pkg/collector"
@@ -29,6 +35,7 @@ import (
 	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
 	"github.com/jenkins-x/jx/pkg/builds"
 	"github.com/jenkins-x/jx/pkg/client/clientset/versioned"
+	"github.com/jenkins-x/jx/pkg/cloudevents"
 	"github.com/jenkins-x/jx/pkg/log"
 	"github.com/jenkins-x/jx/pkg/util"
 	"github.com/spf13/cobra"
@@ -45,11 +52,18 @@ import (
 type ControllerBuildOptions struct {
 	ControllerOptions
 
-	Namespace           string
-	InitGitCredentials  bool
-	GitReporting        bool
-	TargetURLTemplate   string
-	FailIfNoGitProvider bool
+	Namespace            string
+	InitGitCredentials   bool
+	GitReporting         bool
+	GitChecks            bool
+	ChecksAnnotationPath string
+	TargetURLTemplate    string
+	FailIfNoGitProvider  bool
+	QueueInterval        time.Duration
+	NotifyRefresh        time.Duration
+	MetricsPort          int
+	EventBus             eventbus.Options
+	LeaderElection       leaderelection.Options
 
 	EnvironmentCache *kube.EnvironmentNamespaceCache
 
@@ -125,10 +139,18 @@ func NewCmdControllerBuild(commonOpts *opts.CommonOptions) *cobra.Command {
 	cmd.Flags().StringVarP(&options.Namespace, "namespace", "n", "", "The namespace to watch or defaults to the current namespace")
 	cmd.Flags().BoolVarP(&options.InitGitCredentials, "git-credentials", "", false, "If enable then lets run the 'jx step git credentials' step to initialise git credentials")
 	cmd.Flags().BoolVarP(&options.FailIfNoGitProvider, "fail-on-git-provider-error", "", false, "If enable then lets terminate quickly if we cannot create a git provider")
+	cmd.Flags().DurationVarP(&options.QueueInterval, "queue-interval", "", 10*time.Second, "The interval between checks of the pipeline queue for PipelineRuns which can start")
+	cmd.Flags().DurationVarP(&options.NotifyRefresh, "notify-refresh", "", 5*time.Minute, "The period between reloading the notifications.yaml file from the dev environment repository")
+	cmd.Flags().IntVarP(&options.MetricsPort, "metrics-port", "", defaultMetricsPort, "The port to serve the Prometheus metrics of the pipelines on. Use 0 to disable the metrics")
+	options.EventBus.AddFlags(cmd)
+	options.LeaderElection.AddFlags(cmd)
+	options.LeaderElection.AddShardFlags(cmd)
 
 	// optional git reporting flags
 	cmd.Flags().StringVarP(&options.TargetURLTemplate, "target-url-template", "", "", "The Go template for generating the target URL of pipeline logs/views if git reporting is enabled")
 	cmd.Flags().BoolVarP(&options.GitReporting, "git-reporting", "", false, "If enabled then lets report pipeline success/failures to the git provider. Note this is purely tactical until we can do this natively inside tekton")
+	cmd.Flags().BoolVarP(&options.GitChecks, "git-checks", "", false, "If enabled then lets report pipelines as check runs with per stage annotations, failed step logs and a re-run button on git providers which support them such as GitHub. Other git providers use commit statuses")
+	cmd.Flags().StringVarP(&options.ChecksAnnotationPath, "checks-annotation-path", "", defaultChecksAnnotationPath, "The file in the repository the stage annotations of check runs are shown on")
 	return cmd
 }
 
@@ -172,6 +194,9 @@ func (o *ControllerBuildOptions) Run() error {
 		if o.TargetURLTemplate == "" {
 			o.TargetURLTemplate = os.Getenv("TARGET_URL_TEMPLATE")
 		}
+		if !o.GitChecks && strings.ToLower(os.Getenv("GIT_CHECKS")) == "true" {
+			o.GitChecks = true
+		}
 	}
 
 	ns := o.Namespace
@@ -198,58 +223,182 @@ func (o *ControllerBuildOptions) Run() error {
 		log.Logger().Warnf("failed to label the legacy PipelineActivity resources: %s", err)
 	}
 
+	registry := prometheus.NewRegistry()
+	pipelineMetrics, err := observability.NewPipelineMetrics(registry)
+	if err != nil {
+		return errors.Wrap(err, "registering the pipeline metrics")
+	}
+	controllerMetrics, err := observability.NewControllerMetrics(registry)
+	if err != nil {
+		return errors.Wrap(err, "registering the controller metrics")
+	}
+	serveMetrics(o.MetricsPort, registry)
+
+	elector, err := o.LeaderElection.NewElector(kubeClient, ns, "build", controllerMetrics)
+	if err != nil {
+		return err
+	}
+
+	emitter, err := createCloudEventsEmitter(jxClient, devNs)
+	if err != nil {
+		log.Logger().Warnf("Not publishing CloudEvents: %s", err)
+	}
+	if emitter != nil {
+		go watchReleases(jxClient, ns, emitter, elector)
+	}
+
+	bus, err := o.EventBus.NewBus()
+	if err != nil {
+		return err
+	}
+
+	go o.watchActivityNotifications(jxClient, devNs, ns, pipelineMetrics, emitter, bus, elector)
+
 	if tektonEnabled {
 		pod := &corev1.Pod{}
 		log.Logger().Infof("Watching for Pods in namespace %s", util.ColorInfo(ns))
 		listWatch := cache.NewListWatchFromClient(kubeClient.CoreV1().RESTClient(), "pods", ns, fields.Everything())
 		kube.SortListWatchByName(listWatch)
-		_, controller := cache.NewInformer(
+		handler := func(obj interface{}) {
+			o.onPipelinePod(obj, kubeClient, jxClient, tektonClient, ns)
+		}
+		store, controller := cache.NewInformer(
 			listWatch,
 			pod,
 			time.Minute*10,
 			cache.ResourceEventHandlerFuncs{
 				AddFunc: func(obj interface{}) {
-					o.onPipelinePod(obj, kubeClient, jxClient, tektonClient, ns)
+					if ownsPod(elector, obj) {
+						handler(obj)
+					}
 				},
 				UpdateFunc: func(oldObj, newObj interface{}) {
-					o.onPipelinePod(newObj, kubeClient, jxClient, tektonClient, ns)
+					if ownsPod(elector, newObj) {
+						handler(newObj)
+					}
 				},
 				DeleteFunc: func(obj interface{}) {
 				},
 			},
 		)
+		reprocessOnShardChange(elector, store, handler)
 
 		stop := make(chan struct{})
 		go controller.Run(stop)
+
+		go o.processPipelineQueue(jxClient, tektonClient, devNs, ns, elector, controllerMetrics)
 	} else {
 		pod := &corev1.Pod{}
 		log.Logger().Infof("Watching for Knative build pods in namespace %s", util.ColorInfo(ns))
 		listWatch := cache.NewListWatchFromClient(kubeClient.CoreV1().RESTClient(), "pods", ns, fields.Everything())
 		kube.SortListWatchByName(listWatch)
-		_, controller := cache.NewInformer(
+		handler := func(obj interface{}) {
+			o.onPod(obj, kubeClient, jxClient, ns)
+		}
+		store, controller := cache.NewInformer(
 			listWatch,
 			pod,
 			time.Minute*10,
 			cache.ResourceEventHandlerFuncs{
 				AddFunc: func(obj interface{}) {
-					o.onPod(obj, kubeClient, jxClient, ns)
+					if ownsPod(elector, obj) {
+						handler(obj)
+					}
 				},
 				UpdateFunc: func(oldObj, newObj interface{}) {
-					o.onPod(newObj, kubeClient, jxClient, ns)
+					if ownsPod(elector, newObj) {
+						handler(newObj)
+					}
 				},
 				DeleteFunc: func(obj interface{}) {
 				},
 			},
 		)
+		reprocessOnShardChange(elector, store, handler)
 
 		stop := make(chan struct{})
 		go controller.Run(stop)
 	}
 
+	go elector.Run(make(chan struct{}))
+
 	// Wait forever
 	select {}
 }
 
+// watchActivityNotifications sends the notifications configured in the notifications.yaml file of the dev environment
+// repository when PipelineActivities fail, publish releases or raise promotion Pull Requests, records the pipeline
+// metrics and publishes the CloudEvents of the pipelines, promotions and previews. The notifications are distributed
+// via the event bus if one is configured. Each PipelineActivity is only handled by the replica owning its shard
+func (o *ControllerBuildOptions) watchActivityNotifications(jxClient versioned.Interface, devNs string, ns string, pipelineMetrics *observability.PipelineMetrics, emitter *cloudevents.Emitter, bus eventbus.Bus, elector *leaderelection.Elector) {
+	notifier := &notify.Notifier{
+		Gitter:        o.Git(),
+		JXClient:      jxClient,
+		Namespace:     devNs,
+		RefreshPeriod: o.NotifyRefresh,
+	}
+	err := subscribeNotifications(notifier, bus)
+	if err != nil {
+		log.Logger().Errorf("Not dispatching the notifications of the event bus: %s", err)
+	}
+	activity := &v1.PipelineActivity{}
+	listWatch := cache.NewListWatchFromClient(jxClient.JenkinsV1().RESTClient(), "pipelineactivities", ns, fields.Everything())
+	kube.SortListWatchByName(listWatch)
+	_, controller := cache.NewInformer(
+		listWatch,
+		activity,
+		time.Minute*10,
+		cache.ResourceEventHandlerFuncs{
+			// only changes are notified so that existing activities are not notified again when the controller starts
+			UpdateFunc: func(oldObj, newObj interface{}) {
+				oldActivity, ok1 := oldObj.(*v1.PipelineActivity)
+				newActivity, ok2 := newObj.(*v1.PipelineActivity)
+				if ok1 && ok2 && elector.Owns(activityShardKey(newActivity)) {
+					notifyActivity(notifier, bus, oldActivity, newActivity)
+					pipelineMetrics.OnActivity(oldActivity, newActivity)
+					emitter.OnActivity(oldActivity, newActivity)
+				}
+			},
+		},
+	)
+	stop := make(chan struct{})
+	controller.Run(stop)
+}
+
+// processPipelineQueue periodically starts the queued PipelineRuns which no longer exceed the pipeline concurrency
+// limits of the team. Only the leader processes the queue
+func (o *ControllerBuildOptions) processPipelineQueue(jxClient versioned.Interface, tektonClient tektonclient.Interface, devNs string, ns string, elector *leaderelection.Elector, controllerMetrics *observability.ControllerMetrics) {
+	interval := o.QueueInterval
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+	for {
+		if !elector.IsLeader() {
+			controllerMetrics.SetQueueDepth("build", "pipelines", 0)
+			time.Sleep(interval)
+			continue
+		}
+		var concurrency *v1.PipelineConcurrency
+		devEnv, err := kube.GetDevEnvironment(jxClient, devNs)
+		if devEnv != nil {
+			concurrency = devEnv.Spec.TeamSettings.PipelineConcurrency
+		}
+		if err != nil {
+			log.Logger().Warnf("Failed to get the dev environment in namespace %s: %s", devNs, err)
+		} else {
+			err = tekton.ProcessPipelineQueue(jxClient, tektonClient, ns, concurrency)
+			if err != nil {
+				log.Logger().Warnf("Failed to process the pipeline queue: %s", err)
+			}
+			queue, err := tekton.GetPipelineQueue(jxClient, ns, concurrency)
+			if err == nil {
+				controllerMetrics.SetQueueDepth("build", "pipelines", len(queue))
+			}
+		}
+		time.Sleep(interval)
+	}
+}
+
 func (o *ControllerBuildOptions) onPod(obj interface{}, kubeClient kubernetes.Interface, jxClient versioned.Interface, ns string) {
 	pod, ok := obj.(*corev1.Pod)
 	if !ok {
@@ -670,6 +819,8 @@ func (o *ControllerBuildOptions) updatePipelineActivity(kubeClient kubernetes.In
 
 func (o *ControllerBuildOptions) updatePipelineActivityForRun(kubeClient kubernetes.Interface, ns string, activity *v1.PipelineActivity, pri *tekton.PipelineRunInfo, pod *corev1.Pod) bool {
 	originYaml := toYamlString(activity)
+	// an aborted pipeline stays aborted while the pods of its cancelled PipelineRun terminate
+	aborted := activity.Spec.Status == v1.ActivityStatusTypeAborted
 	for _, stage := range pri.Stages {
 		updateForStage(stage, activity)
 	}
@@ -791,6 +942,10 @@ func (o *ControllerBuildOptions) updatePipelineActivityForRun(kubeClient kuberne
 		}
 	}
 
+	if aborted {
+		spec.Status = v1.ActivityStatusTypeAborted
+	}
+
 	if spec.Author == "" && !o.DryRun {
 		err := o.completeBuildSourceInfo(activity)
 		if err != nil {
@@ -1301,6 +1456,7 @@ func (o *ControllerBuildOptions) reportStatus(kubeClient kubernetes.Interface, n
 		return
 	}
 
+	previousState := activity.Annotations[kube.AnnotationGitReportState]
 	activity.Annotations[kube.AnnotationGitReportState] = string(activityStatus)
 
 	pipelineContext := pri.Context
@@ -1327,6 +1483,22 @@ func (o *ControllerBuildOptions) reportStatus(kubeClient kubernetes.Interface, n
 		return
 	}
 
+	if mergequeue.IsBatch(activity) {
+		o.commentOnBatch(gitProvider, ns, activity, previousState)
+	}
+
+	if o.GitChecks {
+		checksProvider, ok := gitProvider.(gits.GitCheckRunProvider)
+		if ok {
+			err = o.reportCheckRun(checksProvider, kubeClient, ns, activity, pri, pipelineContext, targetURL)
+			if err == nil {
+				log.Logger().WithFields(fields).Info("reported git check run")
+				return
+			}
+			log.Logger().WithFields(fields).WithError(err).Warnf("failed to report git check run so reporting a git status instead")
+		}
+	}
+
 	_, err = gitProvider.UpdateCommitStatus(owner, repo, sha, gitRepoStatus)
 	if err != nil {
 		log.Logger().WithFields(fields).WithError(err).Warnf("failed to report git status")
@@ -1335,6 +1507,44 @@ func (o *ControllerBuildOptions) reportStatus(kubeClient kubernetes.Interface, n
 	}
 }
 
+// commentOnBatch comments on the pull requests of a merge queue batch when its tests start and complete
+func (o *ControllerBuildOptions) commentOnBatch(gitProvider gits.GitProvider, ns string, activity *v1.PipelineActivity, previousState string) {
+	status := activity.Spec.Status
+	if previousState != "" && !status.IsTerminated() {
+		return
+	}
+	comment := mergequeue.BatchComment(activity, status)
+	if comment == "" {
+		return
+	}
+	owner := activity.Spec.GitOwner
+	repo := activity.Spec.GitRepository
+	jxClient, _, err := o.JXClient()
+	if err != nil {
+		log.Logger().WithError(err).Warnf("failed to create the jx client so cannot comment on the batch %s", activity.Name)
+		return
+	}
+	queue, err := mergequeue.LoadConfig(jxClient, ns, owner, repo)
+	if err != nil {
+		log.Logger().WithError(err).Warnf("failed to load the merge queue of %s/%s", owner, repo)
+	}
+	if !mergequeue.CommentsEnabled(queue) {
+		return
+	}
+	for _, n := range mergequeue.PullRequestNumbers(activity) {
+		number := n
+		pr := &gits.GitPullRequest{
+			Owner:  owner,
+			Repo:   repo,
+			Number: &number,
+		}
+		err = gitProvider.AddPRComment(pr, comment)
+		if err != nil {
+			log.Logger().WithError(err).Warnf("failed to comment on pull request %d of %s/%s", number, owner, repo)
+		}
+	}
+}
+
 // ReportParams contains the parameters for target URL templates
 type ReportParams struct {
 	Owner, Repository, Branch, Build, Context string
@@ -0,0 +1,203 @@
+package controller
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/gits"
+	"github.com/jenkins-x/jx/pkg/kube"
+	"github.com/jenkins-x/jx/pkg/tekton"
+	"github.com/jenkins-x/jx/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// defaultChecksAnnotationPath the file the stage annotations of check runs are shown on
+	defaultChecksAnnotationPath = "jenkins-x.yml"
+
+	// checkRunLogLines the number of lines of the log of a failed step included in a check run
+	checkRunLogLines = int64(30)
+)
+
+// stepLogFunc returns the end of the log of a step of a stage
+type stepLogFunc func(stage string, step string) string
+
+// reportCheckRun creates or updates the check run of the pipeline activity
+func (o *ControllerBuildOptions) reportCheckRun(provider gits.GitCheckRunProvider, kubeClient kubernetes.Interface, ns string, activity *v1.PipelineActivity, pri *tekton.PipelineRunInfo, name string, detailsURL string) error {
+	stepLog := func(stage string, step string) string {
+		return failedStepLog(kubeClient, ns, pri, stage, step)
+	}
+	checkRun := createCheckRun(activity, name, detailsURL, o.ChecksAnnotationPath, stepLog)
+	owner := activity.Spec.GitOwner
+	repo := activity.Spec.GitRepository
+
+	idText := activity.Annotations[kube.AnnotationGitCheckRunID]
+	if idText != "" {
+		id, err := strconv.ParseInt(idText, 10, 64)
+		if err == nil {
+			checkRun.ID = id
+			_, err = provider.UpdateCheckRun(owner, repo, checkRun)
+			return err
+		}
+	}
+	created, err := provider.CreateCheckRun(owner, repo, checkRun)
+	if err != nil {
+		return err
+	}
+	activity.Annotations[kube.AnnotationGitCheckRunID] = strconv.FormatInt(created.ID, 10)
+	return nil
+}
+
+// createCheckRun creates the check run for the current state of the pipeline activity. Once the pipeline completes
+// the check run has an annotation per stage, the end of the logs of the failed steps and a re-run action
+func createCheckRun(activity *v1.PipelineActivity, name string, detailsURL string, annotationPath string, stepLog stepLogFunc) *gits.GitCheckRun {
+	spec := &activity.Spec
+	if annotationPath == "" {
+		annotationPath = defaultChecksAnnotationPath
+	}
+	checkRun := &gits.GitCheckRun{
+		Name:       name,
+		HeadSHA:    spec.LastCommitSHA,
+		ExternalID: activity.Name,
+		DetailsURL: detailsURL,
+	}
+	if spec.StartedTimestamp != nil {
+		checkRun.StartedAt = &spec.StartedTimestamp.Time
+	}
+
+	switch spec.Status {
+	case v1.ActivityStatusTypeSucceeded:
+		checkRun.Conclusion = gits.CheckRunConclusionSuccess
+	case v1.ActivityStatusTypeFailed, v1.ActivityStatusTypeError:
+		checkRun.Conclusion = gits.CheckRunConclusionFailure
+	case v1.ActivityStatusTypeAborted:
+		checkRun.Conclusion = gits.CheckRunConclusionCancelled
+	case v1.ActivityStatusTypeRunning:
+		checkRun.Status = gits.CheckRunStatusInProgress
+	default:
+		checkRun.Status = gits.CheckRunStatusQueued
+	}
+	checkRun.Title = fmt.Sprintf("Pipeline %s", strings.ToLower(string(spec.Status)))
+	if spec.Status == v1.ActivityStatusTypeNone {
+		checkRun.Title = "Pipeline pending"
+	}
+
+	summary := []string{fmt.Sprintf("Build #%s of %s/%s on branch %s", spec.Build, spec.GitOwner, spec.GitRepository, spec.GitBranch)}
+	table := []string{"| Stage | Status | Duration |", "| --- | --- | --- |"}
+	logs := []string{}
+	for _, step := range spec.Steps {
+		stage := step.Stage
+		if stage == nil {
+			continue
+		}
+		duration := util.DurationString(stage.StartedTimestamp, stage.CompletedTimestamp)
+		table = append(table, fmt.Sprintf("| %s | %s | %s |", stage.Name, string(stage.Status), duration))
+		if checkRun.Conclusion == "" || !stage.Status.IsTerminated() {
+			continue
+		}
+		annotation := gits.GitCheckRunAnnotation{
+			Path:      annotationPath,
+			StartLine: 1,
+			EndLine:   1,
+			Level:     gits.CheckRunAnnotationNotice,
+			Title:     fmt.Sprintf("Stage %s", stage.Name),
+			Message:   fmt.Sprintf("Stage %s %s", stage.Name, strings.ToLower(string(stage.Status))),
+		}
+		if duration != "" {
+			annotation.Message += " in " + duration
+		}
+		if stage.Status == v1.ActivityStatusTypeAborted {
+			annotation.Level = gits.CheckRunAnnotationWarning
+		}
+		failedSteps := []string{}
+		for _, s := range stage.Steps {
+			if s.Status != v1.ActivityStatusTypeFailed && s.Status != v1.ActivityStatusTypeError {
+				continue
+			}
+			failedSteps = append(failedSteps, s.Name)
+			text := ""
+			if stepLog != nil {
+				text = strings.TrimSpace(stepLog(stage.Name, s.Name))
+			}
+			if text == "" {
+				text = "no log available"
+			}
+			logs = append(logs, fmt.Sprintf("<details>\n<summary>%s / %s</summary>\n\n```\n%s\n```\n</details>", stage.Name, s.Name, text))
+		}
+		if stage.Status == v1.ActivityStatusTypeFailed || len(failedSteps) > 0 {
+			annotation.Level = gits.CheckRunAnnotationFailure
+			if len(failedSteps) > 0 {
+				annotation.Message += ": failed steps " + strings.Join(failedSteps, ", ")
+			}
+		}
+		checkRun.Annotations = append(checkRun.Annotations, annotation)
+	}
+	if len(table) > 2 {
+		summary = append(summary, strings.Join(table, "\n"))
+	}
+	checkRun.Summary = strings.Join(summary, "\n\n")
+
+	if checkRun.Conclusion != "" {
+		checkRun.Status = gits.CheckRunStatusCompleted
+		if spec.CompletedTimestamp != nil {
+			checkRun.CompletedAt = &spec.CompletedTimestamp.Time
+		}
+		if len(logs) > 0 {
+			checkRun.Text = "## Failed steps\n\n" + strings.Join(logs, "\n\n")
+		}
+		checkRun.Actions = []gits.GitCheckRunAction{
+			{
+				Label:       "Re-run",
+				Description: "Re-run the pipeline",
+				Identifier:  gits.CheckRunActionRerun,
+			},
+		}
+	}
+	return checkRun
+}
+
+// failedStepLog returns the end of the log of the container of a step of a stage of the pipeline
+func failedStepLog(kubeClient kubernetes.Interface, ns string, pri *tekton.PipelineRunInfo, stageName string, stepName string) string {
+	if pri == nil {
+		return ""
+	}
+	si := findStageInfo(pri.Stages, stageName)
+	if si == nil || si.Pod == nil {
+		return ""
+	}
+	for _, container := range si.Pod.Spec.Containers {
+		if getStepTitle(container.Name) != stepName {
+			continue
+		}
+		tailLines := checkRunLogLines
+		data, err := kubeClient.CoreV1().Pods(ns).GetLogs(si.Pod.Name, &corev1.PodLogOptions{
+			Container: container.Name,
+			TailLines: &tailLines,
+		}).Do().Raw()
+		if err != nil {
+			return ""
+		}
+		return string(data)
+	}
+	return ""
+}
+
+// findStageInfo finds the stage with the name used in the PipelineActivity searching nested stages
+func findStageInfo(stages []*tekton.StageInfo, name string) *tekton.StageInfo {
+	for _, si := range stages {
+		if si.GetStageNameIncludingParents() == name {
+			return si
+		}
+		answer := findStageInfo(si.Stages, name)
+		if answer == nil {
+			answer = findStageInfo(si.Parallel, name)
+		}
+		if answer != nil {
+			return answer
+		}
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package controller
+
+import (
+	"testing"
+	"time"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/gits"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCreateCheckRun(t *testing.T) {
+	t.Parallel()
+
+	started := metav1.NewTime(time.Now().Add(-2 * time.Minute))
+	completed := metav1.NewTime(started.Add(time.Minute))
+	activity := &v1.PipelineActivity{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "myorg-myapp-pr-1-3",
+		},
+		Spec: v1.PipelineActivitySpec{
+			GitOwner:         "myorg",
+			GitRepository:    "myapp",
+			GitBranch:        "PR-1",
+			Build:            "3",
+			LastCommitSHA:    "abc123",
+			Status:           v1.ActivityStatusTypeRunning,
+			StartedTimestamp: &started,
+			Steps: []v1.PipelineActivityStep{
+				{
+					Kind: v1.ActivityStepKindTypeStage,
+					Stage: &v1.StageActivityStep{
+						CoreActivityStep: v1.CoreActivityStep{
+							Name:               "Build",
+							Status:             v1.ActivityStatusTypeFailed,
+							StartedTimestamp:   &started,
+							CompletedTimestamp: &completed,
+						},
+						Steps: []v1.CoreActivityStep{
+							{Name: "Build Make", Status: v1.ActivityStatusTypeSucceeded},
+							{Name: "Test Make", Status: v1.ActivityStatusTypeFailed},
+						},
+					},
+				},
+			},
+		},
+	}
+	stepLog := func(stage string, step string) string {
+		return "FAIL: TestSomething " + stage + " " + step
+	}
+
+	checkRun := createCheckRun(activity, "pr-build", "https://dashboard/myorg/myapp/PR-1/3", "", stepLog)
+	assert.Equal(t, gits.CheckRunStatusInProgress, checkRun.Status)
+	assert.Equal(t, "", checkRun.Conclusion)
+	assert.Equal(t, "abc123", checkRun.HeadSHA)
+	assert.Equal(t, "myorg-myapp-pr-1-3", checkRun.ExternalID)
+	assert.Equal(t, "Pipeline running", checkRun.Title)
+	assert.Empty(t, checkRun.Annotations, "annotations are only added once the pipeline completes")
+	assert.Empty(t, checkRun.Actions)
+
+	activity.Spec.Status = v1.ActivityStatusTypeFailed
+	activity.Spec.CompletedTimestamp = &completed
+	checkRun = createCheckRun(activity, "pr-build", "https://dashboard/myorg/myapp/PR-1/3", "", stepLog)
+	assert.Equal(t, gits.CheckRunStatusCompleted, checkRun.Status)
+	assert.Equal(t, gits.CheckRunConclusionFailure, checkRun.Conclusion)
+	assert.Contains(t, checkRun.Summary, "| Build | Failed | 1m0s |")
+	require.Len(t, checkRun.Annotations, 1)
+	annotation := checkRun.Annotations[0]
+	assert.Equal(t, defaultChecksAnnotationPath, annotation.Path)
+	assert.Equal(t, gits.CheckRunAnnotationFailure, annotation.Level)
+	assert.Equal(t, "Stage Build failed in 1m0s: failed steps Test Make", annotation.Message)
+	assert.Contains(t, checkRun.Text, "<summary>Build / Test Make</summary>")
+	assert.Contains(t, checkRun.Text, "FAIL: TestSomething Build Test Make")
+	assert.NotContains(t, checkRun.Text, "Build Make</summary>")
+	require.Len(t, checkRun.Actions, 1)
+	assert.Equal(t, gits.CheckRunActionRerun, checkRun.Actions[0].Identifier)
+}
@@ -0,0 +1,61 @@
+package controller
+
+import (
+	"time"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/client/clientset/versioned"
+	"github.com/jenkins-x/jx/pkg/cloudevents"
+	"github.com/jenkins-x/jx/pkg/kube"
+	"github.com/jenkins-x/jx/pkg/kube/leaderelection"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/tools/cache"
+)
+
+// createCloudEventsEmitter creates the emitter of the CloudEvents sinks configured in the team settings. Returns nil
+// if no sinks are configured
+func createCloudEventsEmitter(jxClient versioned.Interface, devNs string) (*cloudevents.Emitter, error) {
+	devEnv, err := kube.GetDevEnvironment(jxClient, devNs)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to find the dev environment in namespace %s", devNs)
+	}
+	if devEnv == nil || devEnv.Spec.TeamSettings.CloudEvents == nil {
+		return nil, nil
+	}
+	routes, err := cloudevents.NewRoutes(devEnv.Spec.TeamSettings.CloudEvents, devNs, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid CloudEvents settings")
+	}
+	if len(routes) == 0 {
+		return nil, nil
+	}
+	log.Logger().Infof("Publishing CloudEvents to %s sinks", util.ColorInfo(len(routes)))
+	return cloudevents.NewEmitter(devNs, routes...), nil
+}
+
+// watchReleases publishes the CloudEvents of the Releases created after the controller started. Each Release is only
+// published by the replica owning its shard
+func watchReleases(jxClient versioned.Interface, ns string, emitter *cloudevents.Emitter, elector *leaderelection.Elector) {
+	started := time.Now()
+	release := &v1.Release{}
+	listWatch := cache.NewListWatchFromClient(jxClient.JenkinsV1().RESTClient(), "releases", ns, fields.Everything())
+	kube.SortListWatchByName(listWatch)
+	_, controller := cache.NewInformer(
+		listWatch,
+		release,
+		time.Minute*10,
+		cache.ResourceEventHandlerFuncs{
+			AddFunc: func(obj interface{}) {
+				r, ok := obj.(*v1.Release)
+				if ok && r.CreationTimestamp.Time.After(started) && elector.Owns(shardKey(r.Spec.GitOwner, r.Spec.GitRepository, "")) {
+					emitter.OnRelease(r)
+				}
+			},
+		},
+	)
+	stop := make(chan struct{})
+	controller.Run(stop)
+}
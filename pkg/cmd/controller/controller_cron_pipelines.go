@@ -0,0 +1,113 @@
+package controller
+
+import (
+	"time"
+
+	"github.com/jenkins-x/jx/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/tekton/cronpipelines"
+	"github.com/jenkins-x/jx/pkg/tekton/metapipeline"
+	"github.com/spf13/cobra"
+)
+
+// ControllerCronPipelinesOptions the options for the cron pipelines controller
+type ControllerCronPipelinesOptions struct {
+	ControllerOptions
+
+	ServiceAccount string
+	PollPeriod     time.Duration
+	Once           bool
+}
+
+var (
+	controllerCronPipelinesLong = templates.LongDesc(`
+		Runs the cron pipelines controller which triggers the pipelines scheduled by the 'triggers' section of the
+		jenkins-x.yml of the repositories such as:
+
+		    triggers:
+		      cron: "0 4 * * *"
+
+		The schedules are registered on the SourceRepository resources by the release pipeline of the master branch.
+		Missed runs are coalesced into a single pipeline. If the previous scheduled pipeline is still running the
+		overlap policy of the triggers either skips the new pipeline or cancels the running one.
+
+		Use 'jx get cron-pipelines' to view the schedules.
+`)
+
+	controllerCronPipelinesExample = templates.Examples(`
+		# trigger the scheduled pipelines when they are due
+		jx controller cron-pipelines
+	`)
+)
+
+// NewCmdControllerCronPipelines creates the command for the cron pipelines controller
+func NewCmdControllerCronPipelines(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &ControllerCronPipelinesOptions{
+		ControllerOptions: ControllerOptions{
+			CommonOptions: commonOpts,
+		},
+	}
+
+	cmd := &cobra.Command{
+		Use:     "cron-pipelines",
+		Short:   "Triggers the pipelines scheduled by the cron triggers in jenkins-x.yml",
+		Long:    controllerCronPipelinesLong,
+		Example: controllerCronPipelinesExample,
+		Aliases: []string{"cron"},
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.Flags().StringVarP(&options.ServiceAccount, "service-account", "", "tekton-bot", "The Kubernetes ServiceAccount to use to run the pipelines")
+	cmd.Flags().DurationVarP(&options.PollPeriod, "poll-period", "p", time.Minute, "The period between checking the schedules")
+	cmd.Flags().BoolVarP(&options.Once, "once", "", false, "Only check the schedules once and then terminate")
+	return cmd
+}
+
+// Run implements this command
+func (o *ControllerCronPipelinesOptions) Run() error {
+	// Always run in batch mode as a controller is never run interactively
+	o.BatchMode = true
+
+	jxClient, ns, err := o.JXClientAndDevNamespace()
+	if err != nil {
+		return err
+	}
+	tektonClient, _, err := o.TektonClient()
+	if err != nil {
+		return err
+	}
+	metaPipelineClient, err := metapipeline.NewMetaPipelineClient()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err := metaPipelineClient.Close()
+		if err != nil {
+			log.Logger().Errorf("unable to close meta pipeline client: %s", err.Error())
+		}
+	}()
+
+	reconciler := &cronpipelines.Reconciler{
+		JXClient:           jxClient,
+		TektonClient:       tektonClient,
+		MetaPipelineClient: metaPipelineClient,
+		Namespace:          ns,
+		ServiceAccount:     o.ServiceAccount,
+	}
+	for {
+		err = reconciler.Reconcile()
+		if err != nil {
+			log.Logger().Warnf("Failed to trigger the scheduled pipelines: %s", err)
+		}
+		if o.Once {
+			return err
+		}
+		time.Sleep(o.PollPeriod)
+	}
+}
@@ -0,0 +1,302 @@
+package controller
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/jenkins-x/jx/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/pkg/config"
+	"github.com/jenkins-x/jx/pkg/dependencyupdates"
+	"github.com/jenkins-x/jx/pkg/gits"
+	"github.com/jenkins-x/jx/pkg/kube"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ControllerDependencyUpdateOptions the options for the dependency update controller
+type ControllerDependencyUpdateOptions struct {
+	ControllerOptions
+
+	PollPeriod         time.Duration
+	VersionsRepository string
+	VersionsGitRef     string
+	AllRepositories    bool
+	AutoMerge          bool
+	Once               bool
+	DryRun             bool
+	RecheckPeriod      time.Duration
+
+	lastChecks map[string]*dependencyUpdateCheck
+}
+
+// dependencyUpdateCheck records when a repository is next due to be checked for dependency updates
+type dependencyUpdateCheck struct {
+	next time.Time
+}
+
+var (
+	controllerDependencyUpdateLong = templates.LongDesc(`
+		Runs the dependency update controller which raises Pull Requests on application repositories
+		to upgrade the charts and images they use to the versions in the version stream.
+
+		Each repository is configured via the '.jx/updates.yaml' file in its source code which can specify
+		the schedule, the dependencies to ignore and groups of dependencies to upgrade in a single Pull Request.
+		By default only repositories with a '.jx/updates.yaml' file are updated.
+`)
+
+	controllerDependencyUpdateExample = templates.Examples(`
+		# raise dependency update Pull Requests on the repositories which have a .jx/updates.yaml file
+		jx controller dependencyupdate
+
+		# check all the repositories once and exit
+		jx controller dependencyupdate --all --once
+
+	An example .jx/updates.yaml file:
+
+		schedule: weekly
+		kinds:
+		- charts
+		- docker
+		ignore:
+		- stable/*
+		groups:
+		- name: jenkins-x
+		  patterns:
+		  - jenkins-x/*
+	`)
+)
+
+// NewCmdControllerDependencyUpdate creates the command for the dependency update controller
+func NewCmdControllerDependencyUpdate(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &ControllerDependencyUpdateOptions{
+		ControllerOptions: ControllerOptions{
+			CommonOptions: commonOpts,
+		},
+	}
+
+	cmd := &cobra.Command{
+		Use:     "dependencyupdate",
+		Short:   "Raises Pull Requests to upgrade the dependencies of application repositories",
+		Long:    controllerDependencyUpdateLong,
+		Example: controllerDependencyUpdateExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.Flags().DurationVarP(&options.PollPeriod, "poll-period", "p", time.Hour, "The period between checking if any repositories are due a dependency update")
+	cmd.Flags().StringVarP(&options.VersionsRepository, "versions-repo", "", "", "The git repository of the version stream. Defaults to the one in the team settings")
+	cmd.Flags().StringVarP(&options.VersionsGitRef, "versions-ref", "", "", "The git ref of the version stream. Defaults to the one in the team settings")
+	cmd.Flags().BoolVarP(&options.AllRepositories, "all", "", false, "Update all repositories using the default configuration if they have no .jx/updates.yaml file")
+	cmd.Flags().BoolVarP(&options.AutoMerge, "auto-merge", "", false, "Merge the Pull Requests once their checks pass")
+	cmd.Flags().BoolVarP(&options.Once, "once", "", false, "Only check the repositories once and then terminate")
+	cmd.Flags().BoolVarP(&options.DryRun, "dry-run", "", false, "Commit the changes locally but do not push them or create Pull Requests")
+	cmd.Flags().DurationVarP(&options.RecheckPeriod, "recheck-period", "", 24*time.Hour, "The period after which repositories without a .jx/updates.yaml file are checked again")
+	return cmd
+}
+
+// Run implements this command
+func (o *ControllerDependencyUpdateOptions) Run() error {
+	// Always run in batch mode as a controller is never run interactively
+	o.BatchMode = true
+	o.lastChecks = map[string]*dependencyUpdateCheck{}
+
+	for {
+		err := o.checkRepositories()
+		if err != nil {
+			log.Logger().Warnf("Failed to check for dependency updates: %s", err)
+		}
+		if o.Once {
+			return err
+		}
+		time.Sleep(o.PollPeriod)
+	}
+}
+
+func (o *ControllerDependencyUpdateOptions) checkRepositories() error {
+	jxClient, ns, err := o.JXClientAndDevNamespace()
+	if err != nil {
+		return err
+	}
+	repositories, err := jxClient.JenkinsV1().SourceRepositories(ns).List(metav1.ListOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "listing SourceRepositories in namespace %s", ns)
+	}
+	versionsDir := ""
+	now := time.Now()
+	for i := range repositories.Items {
+		sr := &repositories.Items[i]
+		gitURL, err := kube.GetRepositoryGitURL(sr)
+		if err != nil {
+			log.Logger().Warnf("Ignoring SourceRepository %s: %s", sr.Name, err)
+			continue
+		}
+		lastCheck := o.lastChecks[gitURL]
+		if lastCheck != nil && now.Before(lastCheck.next) {
+			continue
+		}
+		// lets only clone the version stream when a repository is due a check
+		if versionsDir == "" {
+			versionsDir, _, err = o.CloneJXVersionsRepo(o.VersionsRepository, o.VersionsGitRef)
+			if err != nil {
+				return errors.Wrap(err, "cloning the version stream")
+			}
+		}
+		cfg, err := o.checkRepository(gitURL, versionsDir)
+		if err != nil {
+			log.Logger().Warnf("Failed to update the dependencies of %s: %s", gitURL, err)
+		}
+		o.lastChecks[gitURL] = &dependencyUpdateCheck{
+			next: nextDependencyUpdateCheck(cfg, err, now, o.RecheckPeriod),
+		}
+	}
+	return nil
+}
+
+// nextDependencyUpdateCheck returns when a repository should next be checked. Failed checks are retried on the next
+// poll and repositories without any configuration are checked again after the recheck period in case one is added
+func nextDependencyUpdateCheck(cfg *config.UpdatesConfig, checkErr error, now time.Time, recheckPeriod time.Duration) time.Time {
+	if checkErr != nil {
+		return now
+	}
+	if cfg == nil {
+		return now.Add(recheckPeriod)
+	}
+	d, err := cfg.ScheduleDuration()
+	if err != nil {
+		return now.Add(recheckPeriod)
+	}
+	return now.Add(d)
+}
+
+// checkRepository raises Pull Requests for any dependency updates of the repository returning the configuration
+// of the repository or nil if it is not enabled for dependency updates
+func (o *ControllerDependencyUpdateOptions) checkRepository(gitURL string, versionsDir string) (*config.UpdatesConfig, error) {
+	dir, err := ioutil.TempDir("", "dependency-update")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	provider, _, err := o.CreateGitProviderForURLWithoutKind(gitURL)
+	if err != nil {
+		return nil, errors.Wrapf(err, "creating git provider for %s", gitURL)
+	}
+	err = o.Git().Clone(gitURL, dir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cloning %s", gitURL)
+	}
+	cfg, err := config.LoadUpdatesConfig(dir)
+	if err != nil {
+		return nil, err
+	}
+	if cfg == nil {
+		if !o.AllRepositories {
+			return nil, nil
+		}
+		cfg = &config.UpdatesConfig{}
+	}
+	updates, err := dependencyupdates.FindUpdates(dir, versionsDir, cfg)
+	if err != nil {
+		return cfg, errors.Wrapf(err, "finding dependency updates")
+	}
+	if len(updates) == 0 {
+		log.Logger().Debugf("No dependency updates for %s", gitURL)
+		return cfg, nil
+	}
+	for _, set := range dependencyupdates.GroupUpdates(updates) {
+		err = o.raisePullRequest(gitURL, provider, set)
+		if err != nil {
+			log.Logger().Warnf("Failed to raise a Pull Request on %s: %s", gitURL, err)
+		}
+	}
+	return cfg, nil
+}
+
+func (o *ControllerDependencyUpdateOptions) raisePullRequest(gitURL string, provider gits.GitProvider, updates []*dependencyupdates.Update) error {
+	details, err := dependencyUpdatePullRequestDetails(updates)
+	if err != nil {
+		return err
+	}
+
+	gitInfo, err := gits.ParseGitURL(gitURL)
+	if err != nil {
+		return err
+	}
+	openPRs, err := provider.ListOpenPullRequests(gitInfo.Organisation, gitInfo.Name)
+	if err != nil {
+		return errors.Wrapf(err, "listing open Pull Requests on %s", gitURL)
+	}
+	for _, pr := range openPRs {
+		if pr.Title == details.Title {
+			log.Logger().Debugf("Pull Request %s is already open for %s", pr.URL, details.Title)
+			return nil
+		}
+	}
+
+	dir, err := ioutil.TempDir("", "dependency-update")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	dir, _, upstreamInfo, forkInfo, err := gits.ForkAndPullRepo(gitURL, dir, "master", "master", provider, o.Git(), "")
+	if err != nil {
+		return errors.Wrapf(err, "failed to fork and pull %s", gitURL)
+	}
+	err = dependencyupdates.ApplyUpdates(dir, updates)
+	if err != nil {
+		return errors.Wrapf(err, "updating dependencies")
+	}
+	info, err := gits.PushRepoAndCreatePullRequest(dir, upstreamInfo, forkInfo, "master", details, nil, true, details.Title, true, o.DryRun, o.Git(), provider)
+	if err != nil {
+		return errors.Wrapf(err, "creating Pull Request on %s", gitURL)
+	}
+	if o.AutoMerge && info != nil && info.PullRequest != nil {
+		_, err = gits.EnableAutoMerge(provider, info.PullRequest, details.Title)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dependencyUpdatePullRequestDetails creates the Pull Request details for the given set of updates
+func dependencyUpdatePullRequestDetails(updates []*dependencyupdates.Update) (*gits.PullRequestDetails, error) {
+	var title, branchName string
+	if len(updates) == 1 {
+		u := updates[0]
+		title = fmt.Sprintf("chore(deps): bump %s", u.String())
+		branchName = fmt.Sprintf("bump-%s-%s", u.Name, u.ToVersion)
+	} else {
+		group := updates[0].Group
+		title = fmt.Sprintf("chore(deps): bump %s dependencies", group)
+		branchName = fmt.Sprintf("bump-%s-dependencies", group)
+	}
+
+	var message strings.Builder
+	message.WriteString("Update dependencies to the versions in the version stream:\n\n")
+	for _, u := range updates {
+		message.WriteString(fmt.Sprintf("* %s %s from %s to %s\n", strings.TrimSuffix(string(u.Kind), "s"), u.Name, u.FromVersion, u.ToVersion))
+	}
+
+	suffix, err := util.RandStringBytesMaskImprSrc(5)
+	if err != nil {
+		return nil, err
+	}
+	return &gits.PullRequestDetails{
+		BranchName: strings.Replace(branchName, "/", "-", -1) + "-" + suffix,
+		Title:      title,
+		Message:    message.String(),
+		Labels:     []string{gits.LabelUpdatebot},
+	}, nil
+}
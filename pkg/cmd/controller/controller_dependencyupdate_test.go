@@ -0,0 +1,23 @@
+package controller
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jenkins-x/jx/pkg/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNextDependencyUpdateCheck(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	recheck := 24 * time.Hour
+
+	assert.Equal(t, now, nextDependencyUpdateCheck(&config.UpdatesConfig{}, fmt.Errorf("clone failed"), now, recheck), "failed checks should be retried on the next poll")
+	assert.Equal(t, now, nextDependencyUpdateCheck(nil, fmt.Errorf("clone failed"), now, recheck), "failed checks should be retried on the next poll")
+	assert.Equal(t, now.Add(recheck), nextDependencyUpdateCheck(nil, nil, now, recheck), "repositories without configuration should be rechecked")
+	assert.Equal(t, now.Add(time.Hour), nextDependencyUpdateCheck(&config.UpdatesConfig{Schedule: config.UpdatesScheduleHourly}, nil, now, recheck))
+	assert.Equal(t, now.Add(7*24*time.Hour), nextDependencyUpdateCheck(&config.UpdatesConfig{Schedule: config.UpdatesScheduleWeekly}, nil, now, recheck))
+}
@@ -0,0 +1,255 @@
+package controller
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/client/clientset/versioned"
+	"github.com/jenkins-x/jx/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/pkg/cmd/importcmd"
+	"github.com/jenkins-x/jx/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/pkg/gits"
+	"github.com/jenkins-x/jx/pkg/kube"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ControllerDiscoveryOptions the options for the discovery controller
+type ControllerDiscoveryOptions struct {
+	ControllerOptions
+
+	GitServerURL    string
+	GitKind         string
+	Organisations   []string
+	Filters         []string
+	Excludes        []string
+	IncludeForks    bool
+	RemoveArchived  bool
+	DisableWebhooks bool
+	SchedulerName   string
+	PollPeriod      time.Duration
+	Once            bool
+	DryRun          bool
+}
+
+// DiscoveryResult the SourceRepositories created and removed when reconciling an organisation
+type DiscoveryResult struct {
+	Created []string
+	Removed []string
+}
+
+var (
+	controllerDiscoveryLong = templates.LongDesc(`
+		Runs the discovery controller which periodically scans git organisations for repositories and creates a
+		SourceRepository for each new repository which matches the filters so that it is onboarded into Jenkins X
+		without a manual 'jx import'.
+
+		A webhook is created for each new repository when using Prow or Lighthouse without a GitHub App. The
+		SourceRepositories created by this controller are removed once their repositories are archived or deleted,
+		unless --remove-archived=false is specified. SourceRepositories created in any other way are never removed.
+
+		The controller requires the team to use the scheduler configuration of Prow or Lighthouse.
+`)
+
+	controllerDiscoveryExample = templates.Examples(`
+		# onboard the new service repositories of an organisation
+		jx controller discovery --org myorg --filter 'service-*'
+
+		# check which repositories would be onboarded or removed without changing anything
+		jx controller discovery --org myorg --once --dry-run
+	`)
+)
+
+// NewCmdControllerDiscovery creates the command for the discovery controller
+func NewCmdControllerDiscovery(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &ControllerDiscoveryOptions{
+		ControllerOptions: ControllerOptions{
+			CommonOptions: commonOpts,
+		},
+	}
+
+	cmd := &cobra.Command{
+		Use:     "discovery",
+		Short:   "Creates and removes SourceRepositories for the repositories of git organisations",
+		Long:    controllerDiscoveryLong,
+		Example: controllerDiscoveryExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.Flags().StringVarP(&options.GitServerURL, "git-server", "", gits.GitHubURL, "The git server of the organisations")
+	cmd.Flags().StringVarP(&options.GitKind, "git-kind", "", "", "The kind of the git server. Should be one of: "+strings.Join(gits.KindGits, ", "))
+	cmd.Flags().StringArrayVarP(&options.Organisations, "org", "o", nil, "The git organisations to scan for repositories")
+	cmd.Flags().StringArrayVarP(&options.Filters, "filter", "f", nil, "The repository names to onboard such as 'service-*'. Defaults to all of them")
+	cmd.Flags().StringArrayVarP(&options.Excludes, "exclude", "x", nil, "The repository names to ignore such as 'docs-*'")
+	cmd.Flags().BoolVarP(&options.IncludeForks, "include-forks", "", false, "Onboards forked repositories")
+	cmd.Flags().BoolVarP(&options.RemoveArchived, "remove-archived", "", true, "Removes the SourceRepositories created by this controller when their repositories are archived or deleted")
+	cmd.Flags().BoolVarP(&options.DisableWebhooks, "no-webhooks", "", false, "Disables creating webhooks for the new repositories")
+	cmd.Flags().StringVarP(&options.SchedulerName, "scheduler", "", "", "The name of the Scheduler configuration of the new SourceRepositories. Defaults to the team one")
+	cmd.Flags().DurationVarP(&options.PollPeriod, "poll-period", "p", 5*time.Minute, "The period between scanning the organisations")
+	cmd.Flags().BoolVarP(&options.Once, "once", "", false, "Only scan the organisations once and then terminate")
+	cmd.Flags().BoolVarP(&options.DryRun, "dry-run", "", false, "Logs the SourceRepositories which would be created or removed without changing them")
+	return cmd
+}
+
+// Run implements this command
+func (o *ControllerDiscoveryOptions) Run() error {
+	if len(o.Organisations) == 0 {
+		return util.MissingOption("org")
+	}
+	// Always run in batch mode as a controller is never run interactively
+	o.BatchMode = true
+
+	for {
+		err := o.discover()
+		if err != nil {
+			log.Logger().Warnf("Failed to discover repositories: %s", err)
+		}
+		if o.Once {
+			return err
+		}
+		time.Sleep(o.PollPeriod)
+	}
+}
+
+func (o *ControllerDiscoveryOptions) discover() error {
+	settings, err := o.TeamSettings()
+	if err != nil {
+		return err
+	}
+	if !settings.IsSchedulerMode() {
+		return fmt.Errorf("the discovery controller requires the team to use the scheduler configuration of Prow or Lighthouse")
+	}
+	jxClient, ns, err := o.JXClientAndDevNamespace()
+	if err != nil {
+		return err
+	}
+	provider, err := o.GitProviderForGitServerURL(o.GitServerURL, o.GitKind, "")
+	if err != nil {
+		return errors.Wrapf(err, "creating the git provider for %s", o.GitServerURL)
+	}
+	createWebhooks := false
+	if !o.DisableWebhooks && !o.DryRun {
+		isProw, err := o.IsProw()
+		if err != nil {
+			return err
+		}
+		gha, err := o.IsGitHubAppMode()
+		if err != nil {
+			return err
+		}
+		createWebhooks = isProw && !gha
+	}
+	for _, org := range o.Organisations {
+		_, err := o.ReconcileOrganisation(jxClient, ns, provider, org, createWebhooks)
+		if err != nil {
+			log.Logger().Warnf("Failed to discover the repositories of organisation %s: %s", org, err)
+		}
+	}
+	return nil
+}
+
+// ReconcileOrganisation creates a SourceRepository for each repository of the organisation which matches the filters
+// and does not have one yet and removes the SourceRepositories created by this controller whose repositories have been
+// archived or deleted
+func (o *ControllerDiscoveryOptions) ReconcileOrganisation(jxClient versioned.Interface, ns string, provider gits.GitProvider, org string, createWebhooks bool) (*DiscoveryResult, error) {
+	result := &DiscoveryResult{}
+	repos, err := provider.ListRepositories(org)
+	if err != nil {
+		// lets not remove anything if we cannot tell which repositories exist
+		return result, errors.Wrapf(err, "listing the repositories of organisation %s", org)
+	}
+	active := importcmd.FilterOrgRepositories(repos, o.Filters, o.Excludes, false, o.IncludeForks)
+	for _, repo := range active {
+		if _, err := kube.FindSourceRepository(jxClient, ns, org, repo.Name); err == nil {
+			continue
+		}
+		if o.DryRun {
+			log.Logger().Infof("Would create a SourceRepository for %s/%s", org, util.ColorInfo(repo.Name))
+			result.Created = append(result.Created, repo.Name)
+			continue
+		}
+		err := o.createSourceRepository(jxClient, ns, provider, org, repo)
+		if err != nil {
+			log.Logger().Warnf("Failed to create a SourceRepository for %s/%s: %s", org, repo.Name, err)
+			continue
+		}
+		log.Logger().Infof("Created a SourceRepository for %s/%s", org, util.ColorInfo(repo.Name))
+		result.Created = append(result.Created, repo.Name)
+		if createWebhooks {
+			err = o.CreateWebhookProw(repo.CloneURL, provider)
+			if err != nil {
+				log.Logger().Warnf("Failed to create the webhook for %s/%s: %s", org, repo.Name, err)
+			}
+		}
+	}
+	if !o.RemoveArchived {
+		return result, nil
+	}
+
+	repoMap := map[string]*gits.GitRepository{}
+	for _, repo := range repos {
+		if repo != nil {
+			repoMap[repo.Name] = repo
+		}
+	}
+	selector := kube.LabelCreatedBy + "=" + kube.ValueCreatedByDiscovery
+	srs, err := jxClient.JenkinsV1().SourceRepositories(ns).List(metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return result, errors.Wrapf(err, "listing SourceRepositories in namespace %s", ns)
+	}
+	for _, sr := range srs.Items {
+		if sr.Spec.Org != org {
+			continue
+		}
+		repo := repoMap[sr.Spec.Repo]
+		if repo != nil && !repo.Archived {
+			continue
+		}
+		if o.DryRun {
+			log.Logger().Infof("Would remove the SourceRepository %s", util.ColorInfo(sr.Name))
+			result.Removed = append(result.Removed, sr.Spec.Repo)
+			continue
+		}
+		err = jxClient.JenkinsV1().SourceRepositories(ns).Delete(sr.Name, &metav1.DeleteOptions{})
+		if err != nil {
+			log.Logger().Warnf("Failed to remove the SourceRepository %s: %s", sr.Name, err)
+			continue
+		}
+		// the git providers do not support removing webhooks so they are left on the archived repositories which
+		// no longer trigger any events
+		log.Logger().Infof("Removed the SourceRepository %s as its repository is archived or deleted", util.ColorInfo(sr.Name))
+		result.Removed = append(result.Removed, sr.Spec.Repo)
+	}
+	return result, nil
+}
+
+func (o *ControllerDiscoveryOptions) createSourceRepository(jxClient versioned.Interface, ns string, provider gits.GitProvider, org string, repo *gits.GitRepository) error {
+	callback := func(sr *v1.SourceRepository) {
+		// only label the resources we create so that we never remove ones which were imported
+		if sr.ResourceVersion == "" {
+			if sr.Labels == nil {
+				sr.Labels = map[string]string{}
+			}
+			sr.Labels[kube.LabelCreatedBy] = kube.ValueCreatedByDiscovery
+		}
+		sr.Spec.ProviderKind = provider.Kind()
+		sr.Spec.URL = repo.HTMLURL
+		sr.Spec.HTTPCloneURL = repo.CloneURL
+		sr.Spec.SSHCloneURL = repo.SSHURL
+		if o.SchedulerName != "" {
+			sr.Spec.Scheduler.Name = o.SchedulerName
+		}
+	}
+	_, err := kube.GetOrCreateSourceRepositoryCallback(jxClient, ns, repo.Name, org, gits.SourceRepositoryProviderURL(provider), callback)
+	return err
+}
@@ -0,0 +1,114 @@
+package controller_test
+
+import (
+	"testing"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/client/clientset/versioned/fake"
+	"github.com/jenkins-x/jx/pkg/cmd/controller"
+	"github.com/jenkins-x/jx/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/pkg/gits"
+	"github.com/jenkins-x/jx/pkg/kube"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestDiscoveryReconcileOrganisation(t *testing.T) {
+	t.Parallel()
+	ns := "jx"
+	org := "myorg"
+
+	var repos []*gits.FakeRepository
+	for _, name := range []string{"service-new", "service-imported", "service-old", "service-legacy", "docs"} {
+		repo, err := gits.NewFakeRepository(org, name, nil, nil)
+		require.NoError(t, err)
+		repo.GitRepo.Archived = name == "service-old" || name == "service-legacy"
+		repos = append(repos, repo)
+	}
+	provider := gits.NewFakeProvider(repos...)
+
+	discovered := map[string]string{kube.LabelCreatedBy: kube.ValueCreatedByDiscovery}
+	jxClient := fake.NewSimpleClientset(
+		sourceRepository(ns, org, "service-imported", nil),
+		sourceRepository(ns, org, "service-legacy", nil),
+		sourceRepository(ns, org, "service-old", discovered),
+		sourceRepository(ns, org, "service-gone", discovered),
+		sourceRepository(ns, "otherorg", "service-gone", discovered),
+	)
+
+	o := &controller.ControllerDiscoveryOptions{
+		ControllerOptions: controller.ControllerOptions{
+			CommonOptions: &opts.CommonOptions{},
+		},
+		Filters:        []string{"service-*"},
+		RemoveArchived: true,
+	}
+	result, err := o.ReconcileOrganisation(jxClient, ns, provider, org, false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"service-new"}, result.Created)
+	assert.ElementsMatch(t, []string{"service-old", "service-gone"}, result.Removed)
+
+	sr, err := kube.FindSourceRepository(jxClient, ns, org, "service-new")
+	require.NoError(t, err)
+	assert.Equal(t, kube.ValueCreatedByDiscovery, sr.Labels[kube.LabelCreatedBy])
+	assert.Equal(t, "https://fake.git/myorg/service-new.git", sr.Spec.HTTPCloneURL)
+
+	for _, name := range []string{"service-imported", "service-legacy"} {
+		_, err = kube.FindSourceRepository(jxClient, ns, org, name)
+		assert.NoError(t, err, "SourceRepository for %s should not be removed", name)
+	}
+	_, err = kube.FindSourceRepository(jxClient, ns, "otherorg", "service-gone")
+	assert.NoError(t, err, "SourceRepository of another organisation should not be removed")
+
+	// lets check a second reconcile does nothing
+	result, err = o.ReconcileOrganisation(jxClient, ns, provider, org, false)
+	require.NoError(t, err)
+	assert.Empty(t, result.Created)
+	assert.Empty(t, result.Removed)
+}
+
+func TestDiscoveryReconcileOrganisationDryRun(t *testing.T) {
+	t.Parallel()
+	ns := "jx"
+	org := "myorg"
+
+	repo, err := gits.NewFakeRepository(org, "service-new", nil, nil)
+	require.NoError(t, err)
+	provider := gits.NewFakeProvider(repo)
+	jxClient := fake.NewSimpleClientset(
+		sourceRepository(ns, org, "service-gone", map[string]string{kube.LabelCreatedBy: kube.ValueCreatedByDiscovery}),
+	)
+
+	o := &controller.ControllerDiscoveryOptions{
+		ControllerOptions: controller.ControllerOptions{
+			CommonOptions: &opts.CommonOptions{},
+		},
+		RemoveArchived: true,
+		DryRun:         true,
+	}
+	result, err := o.ReconcileOrganisation(jxClient, ns, provider, org, false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"service-new"}, result.Created)
+	assert.Equal(t, []string{"service-gone"}, result.Removed)
+
+	list, err := jxClient.JenkinsV1().SourceRepositories(ns).List(metav1.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, list.Items, 1)
+	assert.Equal(t, "service-gone", list.Items[0].Spec.Repo)
+}
+
+func sourceRepository(ns string, org string, name string, labels map[string]string) *v1.SourceRepository {
+	return &v1.SourceRepository{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      org + "-" + name,
+			Namespace: ns,
+			Labels:    labels,
+		},
+		Spec: v1.SourceRepositorySpec{
+			Org:      org,
+			Repo:     name,
+			Provider: gits.FakeGitURL,
+		},
+	}
+}
@@ -21,16 +21,28 @@ import (
 	"github.com/jenkins-x/jx/pkg/cmd/step/create"
 	"github.com/jenkins-x/jx/pkg/cmd/step/git"
 
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/applications"
+	"github.com/jenkins-x/jx/pkg/audit"
 	"github.com/jenkins-x/jx/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/pkg/environments"
 	"github.com/jenkins-x/jx/pkg/gits"
 	"github.com/jenkins-x/jx/pkg/jenkinsfile"
+	"github.com/jenkins-x/jx/pkg/kube"
+	"github.com/jenkins-x/jx/pkg/kube/leaderelection"
 	"github.com/jenkins-x/jx/pkg/kube/services"
 	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/observability"
+	"github.com/jenkins-x/jx/pkg/tekton"
 	"github.com/jenkins-x/jx/pkg/util"
+	knativeapis "github.com/knative/pkg/apis"
 	"github.com/sirupsen/logrus"
+	pipelineapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
 	"k8s.io/test-infra/prow/github"
 
 	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 
 	"github.com/jenkins-x/jx/pkg/cmd/templates"
 	"github.com/spf13/cobra"
@@ -48,6 +60,10 @@ const (
 	environmentControllerHmacSecret    = "environment-controller-hmac"
 	environmentControllerHmacSecretKey = "hmac"
 	helloMessage                       = "hello from the Jenkins X Environment Controller\n"
+
+	// freezeOverridePrefix the prefix of the line of a commit message which forces a deploy outside of the promotion
+	// windows of the environment along with the reason
+	freezeOverridePrefix = "freeze-override:"
 )
 
 // ControllerEnvironmentOptions holds the command line arguments
@@ -67,18 +83,44 @@ type ControllerEnvironmentOptions struct {
 	WebHookURL            string
 	Branch                string
 	PushRef               string
+	Environment           string
+	ApplyTimeout          time.Duration
 	Labels                map[string]string
+	LeaderElection        leaderelection.Options
 
 	StepCreateTaskOptions create.StepCreateTaskOptions
 	secret                []byte
+	elector               *leaderelection.Elector
+	metrics               *observability.ControllerMetrics
 }
 
 var (
-	controllerEnvironmentsLong = templates.LongDesc(`A controller which takes a webhook and updates the environment via GitOps for remote clusters`)
+	controllerEnvironmentsLong = templates.LongDesc(`
+		A controller which takes a webhook and updates the environment via GitOps for remote clusters.
+
+		If the --environment is specified then pushes outside of the promotion windows of the Environment are not
+		deployed unless the last commit message contains a line such as 'freeze-override: fix the checkout outage'.
+		Overrides are recorded in the audit log. If the promotion windows of the Environment cannot be checked then
+		pushes are not deployed.
+
+		Pushes which are not deployed are not queued. Once the promotion window opens the next push deploys the latest
+		commit of the branch, including any commits whose pushes were not deployed.
+
+		If the --environment is specified then the status of the Environment records the commit being applied, the last
+		commit applied, the reason of the last failure and the number of applications which do not run the versions of
+		the git repository. Kubernetes events are recorded for the Environment when an apply starts, succeeds or fails.
+
+		If --leader-elect is specified several replicas can run for high availability. Only the leader is ready and
+		applies the environment so the other replicas take over if it stops. The leadership and the number of webhooks
+		waiting to apply the environment are exposed as Prometheus metrics on /metrics.
+`)
 
 	controllerEnvironmentsExample = templates.Examples(`
 			# run the environment controller
 			jx controller environment
+
+			# run one of several replicas of the environment controller
+			jx controller environment --leader-elect
 		`)
 
 	pipelineLock sync.Mutex
@@ -117,6 +159,9 @@ func NewCmdControllerEnvironment(commonOpts *opts.CommonOptions) *cobra.Command
 	cmd.Flags().StringVarP(&options.GitRepo, "repo", "", "", "The git repository name. If not specified defaults to $REPO")
 	cmd.Flags().StringVarP(&options.WebHookURL, "webhook-url", "w", "", "The external WebHook URL of this controller to register with the git provider. If not specified defaults to $WEBHOOK_URL")
 	cmd.Flags().StringVarP(&options.PushRef, "push-ref", "", "refs/heads/master", "The git ref passed from the WebHook which should trigger a new deploy pipeline to trigger. Defaults to only webhooks from the master branch")
+	cmd.Flags().StringVarP(&options.Environment, "environment", "", "", "The name of the Environment whose promotion windows are enforced and whose status is updated. If not specified pushes are always deployed")
+	cmd.Flags().DurationVarP(&options.ApplyTimeout, "apply-timeout", "", 30*time.Minute, "The maximum time to wait for the pipeline applying the environment to complete before reporting it as failed")
+	options.LeaderElection.AddFlags(cmd)
 
 	so := &options.StepCreateTaskOptions
 	so.CommonOptions = commonOpts
@@ -232,9 +277,27 @@ func (o *ControllerEnvironmentOptions) Run() error {
 		}
 	}
 
+	registry := prometheus.NewRegistry()
+	o.metrics, err = observability.NewControllerMetrics(registry)
+	if err != nil {
+		return errors.Wrap(err, "registering the controller metrics")
+	}
+	if o.LeaderElection.Enabled {
+		kubeClient, ns, err := o.KubeClientAndNamespace()
+		if err != nil {
+			return err
+		}
+		o.elector, err = o.LeaderElection.NewElector(kubeClient, ns, "environment-"+o.GitRepo, o.metrics)
+		if err != nil {
+			return err
+		}
+		go o.elector.Run(make(chan struct{}))
+	}
+
 	mux := http.NewServeMux()
 	mux.Handle(healthPath, http.HandlerFunc(o.health))
 	mux.Handle(readyPath, http.HandlerFunc(o.ready))
+	mux.Handle(metricsPath, promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
 
 	indexPaths := []string{"/", "/index.html"}
 	for _, p := range indexPaths {
@@ -271,7 +334,7 @@ func (o *ControllerEnvironmentOptions) getIndex(w http.ResponseWriter, r *http.R
 }
 
 // handle request for pipeline runs
-func (o *ControllerEnvironmentOptions) startPipelineRun(w http.ResponseWriter, r *http.Request) {
+func (o *ControllerEnvironmentOptions) startPipelineRun(w http.ResponseWriter, r *http.Request, commit string) {
 	err := o.stepGitCredentials()
 	if err != nil {
 		log.Logger().Warn(err.Error())
@@ -301,15 +364,25 @@ func (o *ControllerEnvironmentOptions) startPipelineRun(w http.ResponseWriter, r
 		pr.CustomLabels = append(pr.CustomLabels, fmt.Sprintf("%s=%s", key, value))
 	}
 
+	o.updateEnvironmentStatus(func(env *v1.Environment) *corev1.Event {
+		return environments.StartApply(env, commit, time.Now())
+	})
+
+	o.metrics.AddQueueDepth("environment", "applies", 1)
 	pipelineLock.Lock()
+	o.metrics.AddQueueDepth("environment", "applies", -1)
 	log.Logger().Infof("triggering pipeline for repo %s branch %s revision %s", sourceURL, branch, revision)
 
 	err = pr.Run()
 	pipelineLock.Unlock()
 	if err != nil {
+		o.updateEnvironmentStatus(func(env *v1.Environment) *corev1.Event {
+			return environments.ApplyFailed(env, fmt.Sprintf("failed to start the pipeline: %s", err), time.Now())
+		})
 		o.returnError(err, err.Error(), w, r)
 		return
 	}
+	go o.waitForApply(pr.Results.PipelineRun())
 	results := &pipeline.PipelineRunResponse{
 		Resources: pr.Results.ObjectReferences(),
 	}
@@ -319,6 +392,109 @@ func (o *ControllerEnvironmentOptions) startPipelineRun(w http.ResponseWriter, r
 	}
 }
 
+// waitForApply waits for the PipelineRun applying the environment to complete then reports its result along with the
+// number of applications which do not run the versions of the environment git repository
+func (o *ControllerEnvironmentOptions) waitForApply(pipelineRun *pipelineapi.PipelineRun) {
+	if o.Environment == "" || pipelineRun == nil {
+		return
+	}
+	tektonClient, ns, err := o.TektonClient()
+	if err != nil {
+		log.Logger().Warnf("failed to create the tekton client so cannot report the status of environment %s: %s", o.Environment, err)
+		return
+	}
+	if pipelineRun.Namespace != "" {
+		ns = pipelineRun.Namespace
+	}
+	var completed *pipelineapi.PipelineRun
+	err = util.Retry(o.ApplyTimeout, func() error {
+		pr, err := tektonClient.TektonV1alpha1().PipelineRuns(ns).Get(pipelineRun.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		if !tekton.PipelineRunIsComplete(pr) {
+			return fmt.Errorf("the PipelineRun %s has not completed", pr.Name)
+		}
+		completed = pr
+		return nil
+	})
+	if err != nil {
+		o.updateEnvironmentStatus(func(env *v1.Environment) *corev1.Event {
+			return environments.ApplyFailed(env, fmt.Sprintf("the PipelineRun %s did not complete within %s: %s", pipelineRun.Name, o.ApplyTimeout, err), time.Now())
+		})
+		return
+	}
+	if !tekton.PipelineRunSucceeded(completed) {
+		message := fmt.Sprintf("the PipelineRun %s failed", completed.Name)
+		condition := completed.Status.GetCondition(knativeapis.ConditionSucceeded)
+		if condition != nil && condition.Message != "" {
+			message += ": " + condition.Message
+		}
+		o.updateEnvironmentStatus(func(env *v1.Environment) *corev1.Event {
+			return environments.ApplyFailed(env, message, time.Now())
+		})
+		return
+	}
+	drifted, err := o.countDrift()
+	if err != nil {
+		log.Logger().Warnf("failed to compare the applications of environment %s with its git repository: %s", o.Environment, err)
+	}
+	o.updateEnvironmentStatus(func(env *v1.Environment) *corev1.Event {
+		return environments.ApplySucceeded(env, drifted, time.Now())
+	})
+}
+
+// countDrift returns the number of applications of the environment which do not run the versions of its git repository
+func (o *ControllerEnvironmentOptions) countDrift() (int, error) {
+	jxClient, ns, err := o.JXClientAndDevNamespace()
+	if err != nil {
+		return 0, err
+	}
+	kubeClient, err := o.KubeClient()
+	if err != nil {
+		return 0, err
+	}
+	env, err := jxClient.JenkinsV1().Environments(ns).Get(o.Environment, metav1.GetOptions{})
+	if err != nil {
+		return 0, err
+	}
+	provider, err := o.gitProvider()
+	if err != nil {
+		return 0, err
+	}
+	desired, err := applications.GetEnvironmentVersions(provider, env)
+	if err != nil {
+		return 0, err
+	}
+	deployments, err := kubeClient.AppsV1beta1().Deployments(env.Spec.Namespace).List(metav1.ListOptions{})
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to list the deployments in namespace %s", env.Spec.Namespace)
+	}
+	return applications.CountDrift(env, deployments.Items, desired), nil
+}
+
+// updateEnvironmentStatus updates the status of the Environment if there is one. Failures are only logged as the
+// status does not affect the deploys
+func (o *ControllerEnvironmentOptions) updateEnvironmentStatus(callback func(env *v1.Environment) *corev1.Event) {
+	if o.Environment == "" {
+		return
+	}
+	kubeClient, err := o.KubeClient()
+	if err != nil {
+		log.Logger().Warnf("failed to create the kube client so cannot report the status of environment %s: %s", o.Environment, err)
+		return
+	}
+	jxClient, ns, err := o.JXClientAndDevNamespace()
+	if err != nil {
+		log.Logger().Warnf("failed to create the jx client so cannot report the status of environment %s: %s", o.Environment, err)
+		return
+	}
+	err = environments.UpdateStatus(kubeClient, jxClient, ns, o.Environment, callback)
+	if err != nil {
+		log.Logger().Warnf("%s", err)
+	}
+}
+
 // discoverWebHookURL lets try discover the webhook URL from the Service
 func (o *ControllerEnvironmentOptions) discoverWebHookURL() (string, error) {
 	kubeCtl, ns, err := o.KubeClientAndNamespace()
@@ -425,8 +601,8 @@ func (o *ControllerEnvironmentOptions) ensureHmacTokenPopulated() error {
 }
 
 func (o *ControllerEnvironmentOptions) isReady() bool {
-	// TODO a better readiness check
-	return true
+	// only the leader receives the webhooks when several replicas run
+	return o.elector.IsLeader()
 }
 
 func (o *ControllerEnvironmentOptions) unmarshalBody(w http.ResponseWriter, r *http.Request, result interface{}) error {
@@ -488,6 +664,10 @@ func (o *ControllerEnvironmentOptions) handleWebHookRequests(w http.ResponseWrit
 	if !valid {
 		return
 	}
+	if !o.elector.IsLeader() {
+		responseHTTPError(w, http.StatusServiceUnavailable, "503 Service Unavailable: this replica is not the leader")
+		return
+	}
 	if eventType != "push" {
 		w.Write([]byte(helloMessage + "ignoring webhook event type: " + eventType))
 		return
@@ -508,39 +688,84 @@ func (o *ControllerEnvironmentOptions) handleWebHookRequests(w http.ResponseWrit
 		w.Write([]byte(helloMessage + "ignoring webhook event type: " + eventType + " on refs: " + event.Ref))
 		return
 	}
+	if reason := o.checkPromotionWindow(&event); reason != "" {
+		log.Logger().Warnf("not deploying the push to %s as %s", event.Ref, reason)
+		w.Write([]byte(helloMessage + "not deploying as " + reason + ". The commits will be deployed by the next push once the promotion window opens"))
+		return
+	}
 
 	log.Logger().Infof("starting pipeline from event type %s UID %s valid %s method %s", eventType, eventGUID, strconv.FormatBool(valid), r.Method)
 	w.Write([]byte("OK"))
 
-	go o.startPipelineRun(w, r)
+	go o.startPipelineRun(w, r, event.After)
+}
+
+// checkPromotionWindow returns the reason the push should not be deployed if the environment is outside of its
+// promotion windows and the last commit does not override them. If the promotion windows cannot be evaluated the push
+// is not deployed
+func (o *ControllerEnvironmentOptions) checkPromotionWindow(event *github.PushEvent) string {
+	if o.Environment == "" {
+		return ""
+	}
+	jxClient, ns, err := o.JXClientAndDevNamespace()
+	if err != nil {
+		return fmt.Sprintf("the promotion windows of environment %s cannot be checked: failed to create the jx client: %s", o.Environment, err)
+	}
+	env, err := kube.GetEnvironment(jxClient, ns, o.Environment)
+	if err != nil {
+		return fmt.Sprintf("the promotion windows of environment %s cannot be checked: %s", o.Environment, err)
+	}
+	return checkEnvironmentPromotionWindow(env, event, time.Now())
+}
+
+// checkEnvironmentPromotionWindow returns the reason the push should not be deployed to the environment at the given
+// time, recording an audit event if the last commit of the push overrides the promotion windows
+func checkEnvironmentPromotionWindow(env *v1.Environment, event *github.PushEvent, now time.Time) string {
+	reason, err := environments.CheckPromotionWindow(env.Spec.PromotionWindows, now)
+	if err != nil {
+		return fmt.Sprintf("the promotion windows of environment %s are invalid: %s", env.Name, err)
+	}
+	if reason == "" {
+		return ""
+	}
+	if len(event.Commits) == 0 {
+		return reason
+	}
+	commit := event.Commits[len(event.Commits)-1]
+	override := freezeOverride(commit.Message)
+	if override == "" {
+		return reason + ". Add a '" + freezeOverridePrefix + " <reason>' line to the commit message to override"
+	}
+	log.Logger().Warnf("deploying commit %s although %s: %s", commit.ID, reason, override)
+	details := map[string]string{
+		"environment": env.Name,
+		"window":      reason,
+		"reason":      override,
+		"commit":      commit.ID,
+	}
+	audit.Record(v1.AuditActionPromotionWindowOverridden, env.Name, env.Namespace, fmt.Sprintf("forced the deploy of commit %s outside of the promotion windows: %s", commit.ID, override), details)
+	return ""
+}
+
+// freezeOverride returns the reason given on the freeze override line of the commit message or an empty string if
+// there is none
+func freezeOverride(message string) string {
+	for _, line := range strings.Split(message, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, freezeOverridePrefix) {
+			return strings.TrimSpace(strings.TrimPrefix(line, freezeOverridePrefix))
+		}
+	}
+	return ""
 }
 
 func (o *ControllerEnvironmentOptions) registerWebHook(webhookURL string, secret []byte) error {
 	gitURL := o.SourceURL
 	log.Logger().Infof("verifying that the webhook is registered for the git repository %s", util.ColorInfo(gitURL))
 
-	var provider gits.GitProvider
-	var err error
-
-	if o.GitKind != "" {
-		gitInfo, err := gits.ParseGitURL(gitURL)
-		if err != nil {
-			return err
-		}
-		gitHostURL := gitInfo.HostURL()
-		ghOwner, err := o.GetGitHubAppOwner(gitInfo)
-		if err != nil {
-			return err
-		}
-		provider, err = o.GitProviderForGitServerURL(gitHostURL, o.GitKind, ghOwner)
-		if err != nil {
-			return errors.Wrapf(err, "failed to create git provider for git URL %s kind %s", gitHostURL, o.GitKind)
-		}
-	} else {
-		provider, err = o.GitProviderForURL(gitURL, "creating webhook git provider")
-		if err != nil {
-			return errors.Wrapf(err, "failed to create git provider for git URL %s", gitURL)
-		}
+	provider, err := o.gitProvider()
+	if err != nil {
+		return err
 	}
 	isInsecureSSL, err := o.IsInsecureSSLWebhooks()
 	if err != nil {
@@ -562,6 +787,32 @@ func (o *ControllerEnvironmentOptions) registerWebHook(webhookURL string, secret
 	return nil
 }
 
+// gitProvider creates the git provider of the environment git repository
+func (o *ControllerEnvironmentOptions) gitProvider() (gits.GitProvider, error) {
+	gitURL := o.SourceURL
+	if o.GitKind != "" {
+		gitInfo, err := gits.ParseGitURL(gitURL)
+		if err != nil {
+			return nil, err
+		}
+		gitHostURL := gitInfo.HostURL()
+		ghOwner, err := o.GetGitHubAppOwner(gitInfo)
+		if err != nil {
+			return nil, err
+		}
+		provider, err := o.GitProviderForGitServerURL(gitHostURL, o.GitKind, ghOwner)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to create git provider for git URL %s kind %s", gitHostURL, o.GitKind)
+		}
+		return provider, nil
+	}
+	provider, err := o.GitProviderForURL(gitURL, "creating webhook git provider")
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create git provider for git URL %s", gitURL)
+	}
+	return provider, nil
+}
+
 // ValidateWebhook ensures that the provided request conforms to the
 // format of a Github webhook and the payload can be validated with
 // the provided hmac secret. It returns the event type, the event guid,
@@ -0,0 +1,67 @@
+package controller
+
+import (
+	"testing"
+	"time"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/audit"
+	"github.com/jenkins-x/jx/pkg/client/clientset/versioned/fake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/test-infra/prow/github"
+)
+
+func TestFreezeOverride(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "fix the checkout outage", freezeOverride("fix: checkout\n\nfreeze-override: fix the checkout outage\n"))
+	assert.Equal(t, "hotfix", freezeOverride("  freeze-override:   hotfix  "))
+	assert.Equal(t, "", freezeOverride("fix: checkout\n\nmentions freeze-override: in the middle of a line"))
+	assert.Equal(t, "", freezeOverride(""))
+}
+
+func TestCheckEnvironmentPromotionWindow(t *testing.T) {
+	jxClient := fake.NewSimpleClientset()
+	audit.SetRecorderFactory(func() (*audit.Recorder, error) {
+		return &audit.Recorder{
+			Sinks: []audit.Sink{&audit.CRDSink{JXClient: jxClient, Namespace: "jx"}},
+		}, nil
+	})
+	defer audit.SetRecorderFactory(nil)
+
+	env := &v1.Environment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "production",
+			Namespace: "jx",
+		},
+		Spec: v1.EnvironmentSpec{
+			PromotionWindows: &v1.PromotionWindowSettings{
+				Freezes: []v1.PromotionWindow{{Name: "weekend", Start: "Fri 16:00", End: "Mon 09:00"}},
+			},
+		},
+	}
+	saturday := time.Date(2019, 12, 7, 12, 0, 0, 0, time.UTC)
+	tuesday := time.Date(2019, 12, 10, 12, 0, 0, 0, time.UTC)
+	push := &github.PushEvent{Commits: []github.Commit{{ID: "abc123", Message: "fix: checkout"}}}
+	overridePush := &github.PushEvent{Commits: []github.Commit{{ID: "def456", Message: "fix: checkout\n\nfreeze-override: fix the checkout outage"}}}
+
+	assert.Equal(t, "", checkEnvironmentPromotionWindow(env, push, tuesday))
+	assert.Contains(t, checkEnvironmentPromotionWindow(env, push, saturday), "promotions are frozen during weekend")
+
+	events, err := audit.ListEvents(jxClient, "jx", time.Time{}, "")
+	require.NoError(t, err)
+	assert.Empty(t, events)
+
+	assert.Equal(t, "", checkEnvironmentPromotionWindow(env, overridePush, saturday))
+	events, err = audit.ListEvents(jxClient, "jx", time.Time{}, "")
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, v1.AuditActionPromotionWindowOverridden, events[0].Spec.Action)
+	assert.Equal(t, "fix the checkout outage", events[0].Spec.Details["reason"])
+	assert.Equal(t, "def456", events[0].Spec.Details["commit"])
+
+	invalid := env.DeepCopy()
+	invalid.Spec.PromotionWindows.Freezes[0].Start = "Someday 16:00"
+	assert.Contains(t, checkEnvironmentPromotionWindow(invalid, push, tuesday), "are invalid", "invalid windows should block the deploy")
+}
@@ -0,0 +1,48 @@
+package controller
+
+import (
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/eventbus"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/notify"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+)
+
+// notifyActivity notifies the events caused by the change of a PipelineActivity. If an event bus is configured the
+// events are published to the bus so that they are dispatched by any replica of the build controller
+func notifyActivity(notifier *notify.Notifier, bus eventbus.Bus, old *v1.PipelineActivity, activity *v1.PipelineActivity) {
+	if bus == nil {
+		notifier.OnActivity(old, activity)
+		return
+	}
+	for _, event := range notify.ActivityEvents(old, activity) {
+		err := bus.Publish(eventbus.TopicNotifications, event.Repository, event)
+		if err != nil {
+			log.Logger().Warnf("Failed to publish the %s notification of %s, dispatching it directly: %s", event.Kind, event.Repository, err)
+			notifier.Notify(event)
+		}
+	}
+}
+
+// subscribeNotifications dispatches the notifications published to the event bus
+func subscribeNotifications(notifier *notify.Notifier, bus eventbus.Bus) error {
+	if bus == nil {
+		return nil
+	}
+	err := bus.Subscribe(eventbus.TopicNotifications, eventbus.GroupNotifications, func(message *eventbus.Message) error {
+		event := &notify.Event{}
+		err := message.Unmarshal(event)
+		if err != nil {
+			log.Logger().Warnf("Ignoring invalid notification: %s", err)
+			return nil
+		}
+		notifier.Notify(event)
+		return nil
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to subscribe to the notifications")
+	}
+	log.Logger().Infof("Dispatching the notifications of topic %s", util.ColorInfo(eventbus.TopicNotifications))
+	return nil
+}
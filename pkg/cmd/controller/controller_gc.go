@@ -0,0 +1,146 @@
+package controller
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/jenkins-x/jx/pkg/cmd/gc"
+	"github.com/jenkins-x/jx/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/spf13/cobra"
+)
+
+// ControllerGCOptions the options for the garbage collection controller
+type ControllerGCOptions struct {
+	ControllerOptions
+
+	Period                  time.Duration
+	DryRun                  bool
+	ReleaseHistoryLimit     int
+	PullRequestHistoryLimit int
+	ReleaseAgeLimit         time.Duration
+	PullRequestAgeLimit     time.Duration
+	PipelineRunAgeLimit     time.Duration
+	Archive                 bool
+	OrphanedNamespaceAge    time.Duration
+	SkipBranches            bool
+}
+
+var (
+	controllerGCLong = templates.LongDesc(`
+		Runs the garbage collection controller which periodically removes:
+
+		* completed PipelineRuns and PipelineActivities beyond the retention policy, optionally archiving the activities
+		* preview environments of closed pull requests and preview namespaces with no preview environment
+		* temporary branches of the dev environment repository left by failed runs such as boot upgrades
+`)
+
+	controllerGCExample = templates.Examples(`
+		# garbage collect every hour
+		jx controller gc
+
+		# only list what would be removed every 10 minutes
+		jx controller gc --period 10m --dry-run
+`)
+)
+
+// NewCmdControllerGC creates the command
+func NewCmdControllerGC(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &ControllerGCOptions{
+		ControllerOptions: ControllerOptions{
+			CommonOptions: commonOpts,
+		},
+	}
+
+	cmd := &cobra.Command{
+		Use:     "gc",
+		Short:   "Runs the garbage collection controller",
+		Long:    controllerGCLong,
+		Example: controllerGCExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+
+	cmd.Flags().DurationVarP(&options.Period, "period", "", time.Hour, "The period between garbage collections")
+	cmd.Flags().BoolVarP(&options.DryRun, "dry-run", "d", false, "Dry run mode. If enabled just list the resources that would be removed")
+	cmd.Flags().IntVarP(&options.ReleaseHistoryLimit, "release-history-limit", "l", 5, "Maximum number of PipelineActivities to keep around per repository release")
+	cmd.Flags().IntVarP(&options.PullRequestHistoryLimit, "pr-history-limit", "", 2, "Minimum number of PipelineActivities to keep around per repository Pull Request")
+	cmd.Flags().DurationVarP(&options.PullRequestAgeLimit, "pull-request-age", "p", time.Hour*48, "Maximum age to keep PipelineActivities for Pull Requests")
+	cmd.Flags().DurationVarP(&options.ReleaseAgeLimit, "release-age", "r", time.Hour*24*30, "Maximum age to keep PipelineActivities for Releases")
+	cmd.Flags().DurationVarP(&options.PipelineRunAgeLimit, "pipelinerun-age", "", time.Hour*2, "Maximum age to keep completed PipelineRuns for all pipelines")
+	cmd.Flags().BoolVarP(&options.Archive, "archive", "", false, "Archives the pruned PipelineActivities to the bucket of the activities storage location before deleting them")
+	cmd.Flags().DurationVarP(&options.OrphanedNamespaceAge, "orphaned-namespace-age", "", time.Hour, "The minimum age of a preview namespace without a preview environment before it is deleted. Zero disables deleting orphaned namespaces")
+	cmd.Flags().BoolVarP(&options.SkipBranches, "skip-branches", "", false, "Do not delete the temporary branches of the dev environment repository")
+	return cmd
+}
+
+// Run implements this command
+func (o *ControllerGCOptions) Run() error {
+	if o.Period <= 0 {
+		return util.InvalidOptionf("period", o.Period, "the period must be positive")
+	}
+	log.Logger().Infof("Garbage collecting every %s", util.ColorInfo(o.Period.String()))
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	ticker := time.NewTicker(o.Period)
+	defer ticker.Stop()
+
+	for {
+		o.collect()
+		select {
+		case <-ticker.C:
+		case <-signals:
+			return nil
+		}
+	}
+}
+
+// collect runs each garbage collection logging failures so that later collections still run
+func (o *ControllerGCOptions) collect() {
+	activities := &gc.GCActivitiesOptions{
+		CommonOptions:           o.CommonOptions,
+		DryRun:                  o.DryRun,
+		ReleaseHistoryLimit:     o.ReleaseHistoryLimit,
+		PullRequestHistoryLimit: o.PullRequestHistoryLimit,
+		ReleaseAgeLimit:         o.ReleaseAgeLimit,
+		PullRequestAgeLimit:     o.PullRequestAgeLimit,
+		PipelineRunAgeLimit:     o.PipelineRunAgeLimit,
+		Archive:                 o.Archive,
+	}
+	err := activities.Run()
+	if err != nil {
+		log.Logger().Warnf("failed to garbage collect the pipeline activities: %s", err)
+	}
+
+	previews := &gc.GCPreviewsOptions{
+		CommonOptions:        o.CommonOptions,
+		OrphanedNamespaceAge: o.OrphanedNamespaceAge,
+		DryRun:               o.DryRun,
+	}
+	err = previews.Run()
+	if err != nil {
+		log.Logger().Warnf("failed to garbage collect the previews: %s", err)
+	}
+
+	if o.SkipBranches {
+		return
+	}
+	branches := &gc.GCBranchesOptions{
+		CommonOptions: o.CommonOptions,
+		DryRun:        o.DryRun,
+	}
+	err = branches.Run()
+	if err != nil {
+		log.Logger().Warnf("failed to garbage collect the temporary branches: %s", err)
+	}
+}
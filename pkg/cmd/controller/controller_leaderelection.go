@@ -0,0 +1,58 @@
+package controller
+
+import (
+	"strings"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/builds"
+	"github.com/jenkins-x/jx/pkg/kube/leaderelection"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+// shardKey returns the key of the shard of the work of a branch so that the pods, PipelineActivities and Releases of a
+// branch are processed by the same replica
+func shardKey(owner string, repository string, branch string) string {
+	return strings.Join([]string{owner, repository, branch}, "/")
+}
+
+// podShardKey returns the key of the shard of the work of a pipeline pod
+func podShardKey(pod *corev1.Pod) string {
+	labels := pod.Labels
+	if labels[v1.LabelOwner] != "" && labels[v1.LabelRepository] != "" {
+		return shardKey(labels[v1.LabelOwner], labels[v1.LabelRepository], labels[v1.LabelBranch])
+	}
+	if labels[builds.LabelPipelineRunName] != "" {
+		return labels[builds.LabelPipelineRunName]
+	}
+	return pod.Name
+}
+
+// activityShardKey returns the key of the shard of the work of a PipelineActivity
+func activityShardKey(activity *v1.PipelineActivity) string {
+	return shardKey(activity.Spec.GitOwner, activity.Spec.GitRepository, activity.Spec.GitBranch)
+}
+
+// ownsPod returns true if the replica processes the pod
+func ownsPod(elector *leaderelection.Elector, obj interface{}) bool {
+	pod, ok := obj.(*corev1.Pod)
+	return ok && pod != nil && elector.Owns(podShardKey(pod))
+}
+
+// reprocessOnShardChange processes the pods of the store again when the replica acquires shards so that the work of
+// a replica which stopped is not lost
+func reprocessOnShardChange(elector *leaderelection.Elector, store cache.Store, handler func(obj interface{})) {
+	if elector == nil {
+		return
+	}
+	elector.OnChange = func() {
+		// the pods are processed in the background so that the Leases are renewed in time
+		go func() {
+			for _, obj := range store.List() {
+				if ownsPod(elector, obj) {
+					handler(obj)
+				}
+			}
+		}()
+	}
+}
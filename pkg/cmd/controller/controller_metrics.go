@@ -0,0 +1,35 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const (
+	metricsPath = "/metrics"
+
+	// defaultMetricsPort the default port the controllers serve their Prometheus metrics on
+	defaultMetricsPort = 9090
+)
+
+// serveMetrics serves the metrics of the registry on the port until the process exits. A port of 0 disables the
+// metrics
+func serveMetrics(port int, registry *prometheus.Registry) {
+	if port <= 0 {
+		return
+	}
+	mux := http.NewServeMux()
+	mux.Handle(metricsPath, promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+	log.Logger().Infof("Serving the Prometheus metrics on port %s", util.ColorInfo(strconv.Itoa(port)))
+	go func() {
+		err := http.ListenAndServe(":"+strconv.Itoa(port), mux)
+		if err != nil {
+			log.Logger().Errorf("Failed to serve the Prometheus metrics on port %d: %s", port, err)
+		}
+	}()
+}
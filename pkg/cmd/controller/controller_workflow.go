@@ -41,7 +41,6 @@ type ControllerWorkflowOptions struct {
 	Namespace               string
 	NoWatch                 bool
 	NoMergePullRequest      bool
-	Verbose                 bool
 	LocalHelmRepoName       string
 	PullRequestPollTime     string
 	NoWaitForUpdatePipeline bool
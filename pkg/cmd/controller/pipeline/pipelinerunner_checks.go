@@ -0,0 +1,157 @@
+package pipeline
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/google/go-github/github"
+	"github.com/jenkins-x/jx/pkg/gits"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	prowapi "k8s.io/test-infra/prow/apis/prowjobs/v1"
+)
+
+const (
+	// checkRunEventType the GitHub webhook event type of check runs
+	checkRunEventType = "check_run"
+
+	// checkRunActionRerequested the user clicked the built in re-run button of the check run
+	checkRunActionRerequested = "rerequested"
+	// checkRunActionRequestedAction the user clicked one of the actions of the check run
+	checkRunActionRequestedAction = "requested_action"
+)
+
+// checkRunEvent the subset of the GitHub check_run webhook payload used to re-run pipelines
+type checkRunEvent struct {
+	Action   string `json:"action"`
+	CheckRun struct {
+		Name       string `json:"name"`
+		HeadSHA    string `json:"head_sha"`
+		ExternalID string `json:"external_id"`
+		CheckSuite struct {
+			HeadBranch string `json:"head_branch"`
+		} `json:"check_suite"`
+		PullRequests []struct {
+			Number int `json:"number"`
+			Head   struct {
+				Ref string `json:"ref"`
+				SHA string `json:"sha"`
+			} `json:"head"`
+			Base struct {
+				Ref string `json:"ref"`
+				SHA string `json:"sha"`
+			} `json:"base"`
+		} `json:"pull_requests"`
+	} `json:"check_run"`
+	RequestedAction *struct {
+		Identifier string `json:"identifier"`
+	} `json:"requested_action"`
+	Repository struct {
+		Name  string `json:"name"`
+		Owner struct {
+			Login string `json:"login"`
+		} `json:"owner"`
+	} `json:"repository"`
+}
+
+// checks handles the GitHub check_run webhooks which request a pipeline is re-run
+func (c *controller) checks(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	var data []byte
+	var err error
+	if len(c.hmacToken) > 0 {
+		data, err = github.ValidatePayload(r, c.hmacToken)
+	} else {
+		data, err = ioutil.ReadAll(r.Body)
+	}
+	if err != nil {
+		c.returnStatusBadRequest(err, "invalid webhook payload: "+err.Error(), w)
+		return
+	}
+	if github.WebHookType(r) != checkRunEventType {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	event := &checkRunEvent{}
+	err = json.Unmarshal(data, event)
+	if err != nil {
+		c.returnStatusBadRequest(err, "failed to unmarshal the check_run webhook: "+err.Error(), w)
+		return
+	}
+	request, err := checkRunPipelineRequest(event)
+	if err != nil {
+		c.returnStatusBadRequest(err, "cannot re-run the pipeline: "+err.Error(), w)
+		return
+	}
+	if request == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	logger.Infof("re-running pipeline %s of %s/%s for check run %s", request.ProwJobSpec.Context, event.Repository.Owner.Login, event.Repository.Name, event.CheckRun.ExternalID)
+	c.schedulePipeline(*request, w)
+}
+
+// checkRunPipelineRequest creates the request to re-run the pipeline of a check run. Returns nil if the check run
+// event is not a request to re-run the pipeline
+func checkRunPipelineRequest(event *checkRunEvent) (*PipelineRunRequest, error) {
+	switch event.Action {
+	case checkRunActionRerequested:
+	case checkRunActionRequestedAction:
+		if event.RequestedAction == nil || event.RequestedAction.Identifier != gits.CheckRunActionRerun {
+			return nil, nil
+		}
+	default:
+		return nil, nil
+	}
+	checkRun := &event.CheckRun
+	owner := event.Repository.Owner.Login
+	repo := event.Repository.Name
+	if owner == "" || repo == "" {
+		return nil, errors.New("the check_run webhook has no repository")
+	}
+	if checkRun.Name == "" {
+		return nil, errors.New("the check run has no name")
+	}
+	spec := prowapi.ProwJobSpec{
+		Job:     checkRun.Name,
+		Context: checkRun.Name,
+		Refs: &prowapi.Refs{
+			Org:  owner,
+			Repo: repo,
+		},
+	}
+	if len(checkRun.PullRequests) > 0 {
+		pr := checkRun.PullRequests[0]
+		spec.Type = prowapi.PresubmitJob
+		spec.Refs.BaseRef = pr.Base.Ref
+		spec.Refs.BaseSHA = pr.Base.SHA
+		sha := pr.Head.SHA
+		if sha == "" {
+			sha = checkRun.HeadSHA
+		}
+		spec.Refs.Pulls = []prowapi.Pull{
+			{
+				Number: pr.Number,
+				SHA:    sha,
+			},
+		}
+	} else {
+		if checkRun.CheckSuite.HeadBranch == "" {
+			return nil, fmt.Errorf("check run %s has no pull request or branch", checkRun.ExternalID)
+		}
+		spec.Type = prowapi.PostsubmitJob
+		spec.Refs.BaseRef = checkRun.CheckSuite.HeadBranch
+		spec.Refs.BaseSHA = checkRun.HeadSHA
+	}
+	return &PipelineRunRequest{
+		Labels: map[string]string{
+			jobLabel: string(uuid.NewUUID()),
+		},
+		ProwJobSpec: spec,
+	}, nil
+}
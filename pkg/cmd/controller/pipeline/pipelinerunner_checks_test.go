@@ -0,0 +1,91 @@
+package pipeline
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	prowapi "k8s.io/test-infra/prow/apis/prowjobs/v1"
+)
+
+const (
+	testPullRequestCheckRunEvent = `
+{
+  "action": "requested_action",
+  "requested_action": {
+    "identifier": "rerun"
+  },
+  "check_run": {
+    "name": "pr-build",
+    "head_sha": "abc123",
+    "external_id": "myorg-myapp-pr-7-2",
+    "check_suite": {
+      "head_branch": "feature"
+    },
+    "pull_requests": [
+      {
+        "number": 7,
+        "head": {"ref": "feature", "sha": "abc123"},
+        "base": {"ref": "master", "sha": "def456"}
+      }
+    ]
+  },
+  "repository": {
+    "name": "myapp",
+    "owner": {"login": "myorg"}
+  }
+}`
+
+	testReleaseCheckRunEvent = `
+{
+  "action": "rerequested",
+  "check_run": {
+    "name": "release",
+    "head_sha": "def456",
+    "check_suite": {
+      "head_branch": "master"
+    }
+  },
+  "repository": {
+    "name": "myapp",
+    "owner": {"login": "myorg"}
+  }
+}`
+)
+
+func TestCheckRunPipelineRequest(t *testing.T) {
+	t.Parallel()
+
+	event := &checkRunEvent{}
+	require.NoError(t, json.Unmarshal([]byte(testPullRequestCheckRunEvent), event))
+	request, err := checkRunPipelineRequest(event)
+	require.NoError(t, err)
+	require.NotNil(t, request)
+	spec := request.ProwJobSpec
+	assert.Equal(t, prowapi.PresubmitJob, spec.Type)
+	assert.Equal(t, "pr-build", spec.Context)
+	assert.Equal(t, "myorg", spec.Refs.Org)
+	assert.Equal(t, "myapp", spec.Refs.Repo)
+	assert.Equal(t, "master", spec.Refs.BaseRef)
+	require.Len(t, spec.Refs.Pulls, 1)
+	assert.Equal(t, 7, spec.Refs.Pulls[0].Number)
+	assert.Equal(t, "abc123", spec.Refs.Pulls[0].SHA)
+	assert.NotEmpty(t, request.Labels[jobLabel])
+
+	event = &checkRunEvent{}
+	require.NoError(t, json.Unmarshal([]byte(testReleaseCheckRunEvent), event))
+	request, err = checkRunPipelineRequest(event)
+	require.NoError(t, err)
+	require.NotNil(t, request)
+	spec = request.ProwJobSpec
+	assert.Equal(t, prowapi.PostsubmitJob, spec.Type)
+	assert.Equal(t, "master", spec.Refs.BaseRef)
+	assert.Equal(t, "def456", spec.Refs.BaseSHA)
+	assert.Empty(t, spec.Refs.Pulls)
+
+	event.Action = "completed"
+	request, err = checkRunPipelineRequest(event)
+	require.NoError(t, err)
+	assert.Nil(t, request, "only re-run requests should trigger pipelines")
+}
@@ -1,11 +1,16 @@
 package pipeline
 
 import (
+	"os"
+
 	"github.com/jenkins-x/jx/pkg/cmd/helper"
 	"github.com/jenkins-x/jx/pkg/cmd/opts/step"
 	"github.com/jenkins-x/jx/pkg/cmd/step/git"
+	"github.com/jenkins-x/jx/pkg/eventbus"
+	"github.com/jenkins-x/jx/pkg/observability"
 	"github.com/jenkins-x/jx/pkg/tekton/metapipeline"
 	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/spf13/viper"
 
 	"github.com/jenkins-x/jx/pkg/cmd/opts"
@@ -27,19 +32,37 @@ type PipelineRunnerOptions struct {
 	*opts.CommonOptions
 	BindAddress          string
 	Path                 string
+	ChecksPath           string
+	TriggerPath          string
 	Port                 int
 	NoGitCredentialsInit bool
 	UseMetaPipeline      bool
 	MetaPipelineImage    string
 	SemanticRelease      bool
+	EventBus             eventbus.Options
 }
 
 var (
-	controllerPipelineRunnersLong = templates.LongDesc(`Runs the service to generate Tekton resources from source code webhooks such as from Prow`)
+	controllerPipelineRunnersLong = templates.LongDesc(`
+		Runs the service to generate Tekton resources from source code webhooks such as from Prow
+
+		If the $TRIGGER_TOKEN environment variable is set external systems such as cron services or other CI servers can
+		trigger the pipeline of a branch by POSTing a JSON request to the --trigger-path with the token as its bearer token:
+
+		    {"owner": "myorg", "repository": "myapp", "branch": "master", "parameters": {"target": "staging"}}
+
+		For large installations the webhooks can be published to a Kafka event bus with --event-bus so that the pipelines
+		are started by any replica of the pipeline runner. The webhooks are then answered with 202 Accepted.
+
+		The number of pipelines being started is exposed as a Prometheus metric on /metrics.
+`)
 
 	controllerPipelineRunnersExample = templates.Examples(`
 			# run the pipeline runner controller
 			jx controller pipelinerunner
+
+			# start the pipelines of the webhooks published to the topics of a Kafka REST proxy
+			jx controller pipelinerunner --event-bus kafka --event-bus-url http://kafka-rest:8082
 		`)
 )
 
@@ -64,6 +87,8 @@ func NewCmdControllerPipelineRunner(commonOpts *opts.CommonOptions) *cobra.Comma
 	cmd.Flags().IntVar(&options.Port, portOptionName, 8080, "The TCP port to listen on.")
 	cmd.Flags().StringVar(&options.BindAddress, bindOptionName, "0.0.0.0", "The interface address to bind to (by default, will listen on all interfaces/addresses).")
 	cmd.Flags().StringVar(&options.Path, "path", "/", "The path to listen on for requests to trigger a pipeline run.")
+	cmd.Flags().StringVar(&options.ChecksPath, "checks-path", "/checks", "The path to listen on for GitHub check_run webhooks which re-run the pipeline of a check run. The webhook signature is verified using the $HMAC_TOKEN environment variable if it is set. Disabled if empty.")
+	cmd.Flags().StringVar(&options.TriggerPath, "trigger-path", "/trigger", "The path to listen on for requests of external systems to trigger the pipeline of a branch with parameters. Requests must have the $TRIGGER_TOKEN environment variable as their bearer token. Disabled if empty or the environment variable is not set.")
 	cmd.Flags().StringVar(&options.ServiceAccount, "service-account", "tekton-bot", "The Kubernetes ServiceAccount to use to run the pipeline.")
 	cmd.Flags().BoolVar(&options.NoGitCredentialsInit, "no-git-init", false, "Disables checking we have setup git credentials on startup.")
 	cmd.Flags().BoolVar(&options.SemanticRelease, "semantic-release", false, "Enable semantic releases")
@@ -72,6 +97,8 @@ func NewCmdControllerPipelineRunner(commonOpts *opts.CommonOptions) *cobra.Comma
 	cmd.Flags().BoolVar(&options.UseMetaPipeline, useMetaPipelineOptionName, true, "Uses the meta pipeline to create the pipeline.")
 	cmd.Flags().StringVar(&options.MetaPipelineImage, metaPipelineImageOptionName, "", "Specify the docker image to use if there is no image specified for a step.")
 
+	options.EventBus.AddFlags(cmd)
+
 	options.bindViper(cmd)
 	return cmd
 }
@@ -110,9 +137,24 @@ func (o *PipelineRunnerOptions) Run() error {
 		return err
 	}
 
+	bus, err := o.EventBus.NewBus()
+	if err != nil {
+		return err
+	}
+
+	registry := prometheus.NewRegistry()
+	metrics, err := observability.NewControllerMetrics(registry)
+	if err != nil {
+		return errors.Wrap(err, "registering the controller metrics")
+	}
+
 	controller := controller{
 		bindAddress:        o.BindAddress,
 		path:               o.Path,
+		checksPath:         o.ChecksPath,
+		hmacToken:          []byte(os.Getenv("HMAC_TOKEN")),
+		triggerPath:        o.TriggerPath,
+		triggerToken:       []byte(os.Getenv("TRIGGER_TOKEN")),
 		port:               o.Port,
 		useMetaPipeline:    useMetaPipeline,
 		metaPipelineImage:  viper.GetString(metaPipelineImageOptionName),
@@ -121,6 +163,9 @@ func (o *PipelineRunnerOptions) Run() error {
 		jxClient:           jxClient,
 		ns:                 ns,
 		metaPipelineClient: metapipelineClient,
+		bus:                bus,
+		registry:           registry,
+		metrics:            metrics,
 	}
 
 	controller.Start()
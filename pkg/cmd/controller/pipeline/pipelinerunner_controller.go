@@ -7,6 +7,9 @@ import (
 
 	"github.com/jenkins-x/jx/pkg/cmd/clients"
 	"github.com/jenkins-x/jx/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/pkg/eventbus"
+	"github.com/jenkins-x/jx/pkg/mergequeue"
+	"github.com/jenkins-x/jx/pkg/observability"
 	"github.com/jenkins-x/jx/pkg/tekton"
 	"github.com/jenkins-x/jx/pkg/tekton/metapipeline"
 
@@ -22,6 +25,8 @@ import (
 
 	"github.com/jenkins-x/jx/pkg/prow"
 	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/sirupsen/logrus"
 
 	"github.com/jenkins-x/jx/pkg/cmd/step/create"
@@ -43,6 +48,8 @@ const (
 	healthPath = "/health"
 	// readyPath URL path for the HTTP endpoint that returns ready status.
 	readyPath = "/ready"
+	// metricsPath URL path for the HTTP endpoint that returns the Prometheus metrics.
+	metricsPath = "/metrics"
 
 	// jobLabel is the label name used to identify the Prow job within PipelineRunRequest.Labels
 	jobLabel = "prowJobName"
@@ -79,6 +86,10 @@ type ObjectReference struct {
 type controller struct {
 	bindAddress        string
 	path               string
+	checksPath         string
+	hmacToken          []byte
+	triggerPath        string
+	triggerToken       []byte
 	port               int
 	useMetaPipeline    bool
 	metaPipelineImage  string
@@ -87,12 +98,20 @@ type controller struct {
 	ns                 string
 	jxClient           jxclient.Interface
 	metaPipelineClient metapipeline.Client
+	bus                eventbus.Bus
+	registry           *prometheus.Registry
+	metrics            *observability.ControllerMetrics
 }
 
 func (c *controller) Start() {
 	var wg sync.WaitGroup
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
+	err := c.subscribe()
+	if err != nil {
+		logger.Errorf("unable to consume the event bus: %s", err.Error())
+		return
+	}
 	c.startWorkers(ctx, &wg, cancel)
 	c.setupSignalChannel(cancel)
 	wg.Wait()
@@ -104,8 +123,17 @@ func (c *controller) startWorkers(ctx context.Context, wg *sync.WaitGroup, cance
 		defer wg.Done()
 		mux := http.NewServeMux()
 		mux.Handle(c.path, http.HandlerFunc(c.pipeline))
+		if c.checksPath != "" {
+			mux.Handle(c.checksPath, http.HandlerFunc(c.checks))
+		}
+		if c.triggerPath != "" && len(c.triggerToken) > 0 {
+			mux.Handle(c.triggerPath, http.HandlerFunc(c.trigger))
+		}
 		mux.Handle(healthPath, http.HandlerFunc(c.health))
 		mux.Handle(readyPath, http.HandlerFunc(c.ready))
+		if c.registry != nil {
+			mux.Handle(metricsPath, promhttp.HandlerFor(c.registry, promhttp.HandlerOpts{}))
+		}
 		srv := &http.Server{
 			Addr:    fmt.Sprintf("%s:%d", c.bindAddress, c.port),
 			Handler: mux,
@@ -138,6 +166,12 @@ func (c *controller) startWorkers(ctx context.Context, wg *sync.WaitGroup, cance
 					err := c.metaPipelineClient.Close()
 					logger.Error(errors.Wrap(err, "Error closing the meta pipeline client"))
 				}
+				if c.bus != nil {
+					err := c.bus.Close()
+					if err != nil {
+						logger.Errorf("error closing the event bus: %s", err.Error())
+					}
+				}
 				cancel()
 				return
 			}
@@ -182,21 +216,7 @@ func (c *controller) handlePostRequest(r *http.Request, w http.ResponseWriter) {
 		return
 	}
 
-	pipelineRunResponse, err := c.startPipeline(requestParams)
-	if err != nil {
-		c.returnStatusBadRequest(err, "could not start pipeline: "+err.Error(), w)
-		return
-	}
-
-	data, err := c.marshalPayload(pipelineRunResponse)
-	if err != nil {
-		c.returnStatusBadRequest(err, "failed to marshal payload", w)
-		return
-	}
-	_, err = w.Write(data)
-	if err != nil {
-		logger.Errorf("error writing PipelineRunResponse: %s", err.Error())
-	}
+	c.schedulePipeline(requestParams, w)
 }
 
 func (c *controller) parseStartPipelineRequestParameters(r *http.Request) (PipelineRunRequest, error) {
@@ -215,6 +235,9 @@ func (c *controller) parseStartPipelineRequestParameters(r *http.Request) (Pipel
 
 // startPipeline handles an incoming request to start a pipeline.
 func (c *controller) startPipeline(pipelineRun PipelineRunRequest) (PipelineRunResponse, error) {
+	c.metrics.AddQueueDepth("pipelinerunner", "requests", 1)
+	defer c.metrics.AddQueueDepth("pipelinerunner", "requests", -1)
+
 	response := PipelineRunResponse{}
 	var revision string
 	var prNumber string
@@ -224,6 +247,13 @@ func (c *controller) startPipeline(pipelineRun PipelineRunRequest) (PipelineRunR
 		return response, errors.New(fmt.Sprintf("no prowJobSpec.refs passed: %s", util.PrettyPrint(pipelineRun)))
 	}
 
+	if prowJobSpec.Type == prowapi.BatchJob {
+		err := c.verifyBatch(prowJobSpec)
+		if err != nil {
+			return response, err
+		}
+	}
+
 	// Only if there is one Pull in Refs, it's a PR build so we are going to pass it
 	if len(prowJobSpec.Refs.Pulls) == 1 {
 		revision = prowJobSpec.Refs.Pulls[0].SHA
@@ -375,6 +405,18 @@ func (c *controller) getBranch(spec prowapi.ProwJobSpec) string {
 	return branch
 }
 
+// verifyBatch returns an error if the batch of pull requests is not allowed by the merge queue of the repository
+func (c *controller) verifyBatch(spec prowapi.ProwJobSpec) error {
+	owner := spec.Refs.Org
+	repo := spec.Refs.Repo
+	queue, err := mergequeue.LoadConfig(c.jxClient, c.ns, owner, repo)
+	if err != nil {
+		logger.WithError(err).Warnf("failed to load the merge queue of %s/%s so allowing the batch", owner, repo)
+		return nil
+	}
+	return mergequeue.VerifyBatch(queue, owner, repo, len(spec.Refs.Pulls))
+}
+
 func (c *controller) getPullRefs(spec prowapi.ProwJobSpec) prow.PullRefs {
 	toMerge := make(map[string]string)
 	for _, pull := range spec.Refs.Pulls {
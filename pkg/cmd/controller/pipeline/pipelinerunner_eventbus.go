@@ -0,0 +1,78 @@
+package pipeline
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/jenkins-x/jx/pkg/eventbus"
+	"github.com/pkg/errors"
+)
+
+// schedulePipeline starts the pipeline of the request and writes the response. If an event bus is configured the
+// request is published to the bus instead so that the pipeline is started by any replica of the pipeline runner
+func (c *controller) schedulePipeline(request PipelineRunRequest, w http.ResponseWriter) {
+	if c.bus == nil {
+		response, err := c.startPipeline(request)
+		if err != nil {
+			c.returnStatusBadRequest(err, "could not start pipeline: "+err.Error(), w)
+			return
+		}
+		data, err := c.marshalPayload(response)
+		if err != nil {
+			c.returnStatusBadRequest(err, "failed to marshal payload", w)
+			return
+		}
+		_, err = w.Write(data)
+		if err != nil {
+			logger.Errorf("error writing PipelineRunResponse: %s", err.Error())
+		}
+		return
+	}
+
+	refs := request.ProwJobSpec.Refs
+	if refs == nil {
+		err := errors.New("no prowJobSpec.refs passed")
+		c.returnStatusBadRequest(err, "could not start pipeline: "+err.Error(), w)
+		return
+	}
+	// the requests of a repository are keyed by the repository so that they are started in order
+	err := c.bus.Publish(eventbus.TopicPipelineRequests, fmt.Sprintf("%s/%s", refs.Org, refs.Repo), &request)
+	if err != nil {
+		logger.Errorf("failed to publish the pipeline request of %s/%s: %s", refs.Org, refs.Repo, err.Error())
+		http.Error(w, "could not schedule pipeline: "+err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// subscribe starts the pipelines of the requests published to the event bus
+func (c *controller) subscribe() error {
+	if c.bus == nil {
+		return nil
+	}
+	err := c.bus.Subscribe(eventbus.TopicPipelineRequests, eventbus.GroupPipelineScheduler, c.onPipelineRequest)
+	if err != nil {
+		return errors.Wrap(err, "failed to subscribe to the pipeline requests")
+	}
+	logger.Infof("starting the pipelines of the requests of topic %s", eventbus.TopicPipelineRequests)
+	return nil
+}
+
+// onPipelineRequest starts the pipeline of a request received from the event bus. The request is received again if
+// the pipeline cannot be started
+func (c *controller) onPipelineRequest(message *eventbus.Message) error {
+	request := PipelineRunRequest{}
+	err := message.Unmarshal(&request)
+	if err != nil {
+		logger.Errorf("ignoring invalid pipeline request: %s", err.Error())
+		return nil
+	}
+	response, err := c.startPipeline(request)
+	if err != nil {
+		return errors.Wrapf(err, "failed to start the pipeline of request %s", message.ID)
+	}
+	for _, resource := range response.Resources {
+		logger.Debugf("created %s %s for request %s", resource.Kind, resource.Name, message.ID)
+	}
+	return nil
+}
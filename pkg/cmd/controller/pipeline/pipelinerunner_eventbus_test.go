@@ -0,0 +1,68 @@
+package pipeline
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jenkins-x/jx/pkg/eventbus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	prowapi "k8s.io/test-infra/prow/apis/prowjobs/v1"
+)
+
+type fakeBus struct {
+	topic string
+	key   string
+	value interface{}
+}
+
+func (b *fakeBus) Publish(topic string, key string, value interface{}) error {
+	b.topic = topic
+	b.key = key
+	b.value = value
+	return nil
+}
+
+func (b *fakeBus) Subscribe(topic string, group string, handler eventbus.Handler) error {
+	return nil
+}
+
+func (b *fakeBus) Close() error {
+	return nil
+}
+
+func TestSchedulePipelineViaEventBus(t *testing.T) {
+	t.Parallel()
+	bus := &fakeBus{}
+	c := &controller{bus: bus}
+
+	request := PipelineRunRequest{
+		ProwJobSpec: prowapi.ProwJobSpec{
+			Type:    prowapi.PostsubmitJob,
+			Context: "release",
+			Refs: &prowapi.Refs{
+				Org:     "myorg",
+				Repo:    "myapp",
+				BaseRef: "master",
+			},
+		},
+	}
+	w := httptest.NewRecorder()
+	c.schedulePipeline(request, w)
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.Equal(t, eventbus.TopicPipelineRequests, bus.topic)
+	assert.Equal(t, "myorg/myapp", bus.key)
+
+	// the replicas receive the request as JSON
+	data, err := json.Marshal(bus.value)
+	require.NoError(t, err)
+	received := PipelineRunRequest{}
+	require.NoError(t, (&eventbus.Message{Data: data}).Unmarshal(&received))
+	assert.Equal(t, request, received)
+
+	w = httptest.NewRecorder()
+	c.schedulePipeline(PipelineRunRequest{}, w)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
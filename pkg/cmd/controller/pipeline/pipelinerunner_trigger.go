@@ -0,0 +1,142 @@
+package pipeline
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/jenkins-x/jx/pkg/tekton/metapipeline"
+	"github.com/jenkins-x/jx/pkg/tekton/syntax"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// TriggerRequest the request of an external system such as a cron service or another CI server to trigger the
+// pipeline of a branch
+type TriggerRequest struct {
+	// Owner the owner of the git repository
+	Owner string `json:"owner"`
+	// Repository the name of the git repository
+	Repository string `json:"repository"`
+	// Branch the branch to build. Defaults to master
+	Branch string `json:"branch,omitempty"`
+	// Revision the optional SHA of the commit to build. Defaults to the head of the branch
+	Revision string `json:"revision,omitempty"`
+	// Context the optional context of the pipeline
+	Context string `json:"context,omitempty"`
+	// Kind the kind of pipeline such as release or feature. Defaults to release for master and feature otherwise
+	Kind string `json:"kind,omitempty"`
+	// Parameters the custom params passed to the pipeline
+	Parameters map[string]string `json:"parameters,omitempty"`
+	// Env the custom environment variables of the pipeline steps
+	Env map[string]string `json:"env,omitempty"`
+	// Labels the custom labels of the PipelineRun
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// trigger handles the authenticated requests of external systems to trigger pipelines
+func (c *controller) trigger(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	if !authorizedTrigger(r, c.triggerToken) {
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+	data, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		c.returnStatusBadRequest(err, "could not read the JSON request body: "+err.Error(), w)
+		return
+	}
+	request := TriggerRequest{}
+	err = json.Unmarshal(data, &request)
+	if err != nil {
+		c.returnStatusBadRequest(err, "failed to unmarshal the JSON request body: "+err.Error(), w)
+		return
+	}
+	sourceURL := c.getSourceURL(request.Owner, request.Repository)
+	if sourceURL == "" {
+		// fallback to GutHub provider
+		sourceURL = fmt.Sprintf("https://github.com/%s/%s.git", request.Owner, request.Repository)
+	}
+	param, err := triggerPipelineCreateParam(&request, sourceURL, c.serviceAccount, c.metaPipelineImage)
+	if err != nil {
+		c.returnStatusBadRequest(err, "invalid trigger request: "+err.Error(), w)
+		return
+	}
+	logger.WithFields(logrus.Fields{"sourceURL": sourceURL, "branch": param.PullRef.BaseBranch(), "context": param.Context}).Info("triggering pipeline from trigger request")
+
+	pipelineActivity, crds, err := c.metaPipelineClient.Create(param)
+	if err != nil {
+		c.returnStatusBadRequest(err, "could not start pipeline: "+err.Error(), w)
+		return
+	}
+	err = c.metaPipelineClient.Apply(pipelineActivity, crds)
+	if err != nil {
+		c.returnStatusBadRequest(err, "could not start pipeline: "+err.Error(), w)
+		return
+	}
+	data, err = c.marshalPayload(PipelineRunResponse{Resources: crds.ObjectReferences()})
+	if err != nil {
+		c.returnStatusBadRequest(err, "failed to marshal payload", w)
+		return
+	}
+	_, err = w.Write(data)
+	if err != nil {
+		logger.Errorf("error writing PipelineRunResponse: %s", err.Error())
+	}
+}
+
+// authorizedTrigger returns true if the request has the bearer token. Requests are always rejected if there is no
+// token
+func authorizedTrigger(r *http.Request, token []byte) bool {
+	if len(token) == 0 {
+		return false
+	}
+	header := r.Header.Get("Authorization")
+	prefix := "Bearer "
+	if !strings.HasPrefix(header, prefix) {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(strings.TrimPrefix(header, prefix)), token) == 1
+}
+
+// triggerPipelineCreateParam validates the trigger request and creates the parameters of the meta pipeline
+func triggerPipelineCreateParam(request *TriggerRequest, sourceURL string, serviceAccount string, defaultImage string) (metapipeline.PipelineCreateParam, error) {
+	answer := metapipeline.PipelineCreateParam{}
+	if request.Owner == "" || request.Repository == "" {
+		return answer, errors.New("the owner and repository must be specified")
+	}
+	for name := range request.Parameters {
+		err := syntax.ValidatePipelineParamName(name)
+		if err != nil {
+			return answer, err
+		}
+	}
+	branch := request.Branch
+	if branch == "" {
+		branch = "master"
+	}
+	kind := metapipeline.StringToPipelineKind(request.Kind)
+	if request.Kind == "" {
+		kind = metapipeline.FeaturePipeline
+		if branch == "master" {
+			kind = metapipeline.ReleasePipeline
+		}
+	}
+	answer = metapipeline.PipelineCreateParam{
+		PullRef:        metapipeline.NewPullRef(sourceURL, branch, request.Revision),
+		PipelineKind:   kind,
+		Context:        request.Context,
+		EnvVariables:   request.Env,
+		Parameters:     request.Parameters,
+		Labels:         request.Labels,
+		ServiceAccount: serviceAccount,
+		DefaultImage:   defaultImage,
+	}
+	return answer, nil
+}
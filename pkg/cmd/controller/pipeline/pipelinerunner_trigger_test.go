@@ -0,0 +1,100 @@
+package pipeline
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jenkins-x/jx/pkg/client/clientset/versioned/fake"
+	"github.com/jenkins-x/jx/pkg/kube"
+	"github.com/jenkins-x/jx/pkg/tekton"
+	"github.com/jenkins-x/jx/pkg/tekton/metapipeline"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeMetaPipelineClient struct {
+	params []metapipeline.PipelineCreateParam
+}
+
+func (f *fakeMetaPipelineClient) Create(param metapipeline.PipelineCreateParam) (kube.PromoteStepActivityKey, tekton.CRDWrapper, error) {
+	f.params = append(f.params, param)
+	return kube.PromoteStepActivityKey{}, tekton.CRDWrapper{}, nil
+}
+
+func (f *fakeMetaPipelineClient) Apply(pipelineActivity kube.PromoteStepActivityKey, crds tekton.CRDWrapper) error {
+	return nil
+}
+
+func (f *fakeMetaPipelineClient) Close() error {
+	return nil
+}
+
+func TestTriggerPipelineCreateParam(t *testing.T) {
+	t.Parallel()
+
+	request := &TriggerRequest{
+		Owner:      "myorg",
+		Repository: "myapp",
+		Parameters: map[string]string{"target": "staging"},
+	}
+	param, err := triggerPipelineCreateParam(request, "https://github.com/myorg/myapp.git", "tekton-bot", "")
+	require.NoError(t, err)
+	assert.Equal(t, "master", param.PullRef.BaseBranch())
+	assert.Equal(t, metapipeline.ReleasePipeline, param.PipelineKind)
+	assert.Equal(t, map[string]string{"target": "staging"}, param.Parameters)
+	assert.Equal(t, "tekton-bot", param.ServiceAccount)
+
+	request.Branch = "feature"
+	param, err = triggerPipelineCreateParam(request, "https://github.com/myorg/myapp.git", "tekton-bot", "")
+	require.NoError(t, err)
+	assert.Equal(t, metapipeline.FeaturePipeline, param.PipelineKind)
+
+	request.Parameters["build_id"] = "7"
+	_, err = triggerPipelineCreateParam(request, "https://github.com/myorg/myapp.git", "tekton-bot", "")
+	assert.Error(t, err)
+
+	_, err = triggerPipelineCreateParam(&TriggerRequest{Owner: "myorg"}, "https://github.com/myorg/myapp.git", "tekton-bot", "")
+	assert.Error(t, err)
+}
+
+func TestTrigger(t *testing.T) {
+	t.Parallel()
+
+	metaPipelineClient := &fakeMetaPipelineClient{}
+	c := &controller{
+		triggerToken:       []byte("s3cr3t"),
+		serviceAccount:     "tekton-bot",
+		jxClient:           fake.NewSimpleClientset(),
+		ns:                 "jx",
+		metaPipelineClient: metaPipelineClient,
+	}
+	body := `{"owner": "myorg", "repository": "myapp", "parameters": {"target": "staging"}}`
+
+	for _, token := range []string{"", "Bearer wrong", "s3cr3t"} {
+		r := httptest.NewRequest(http.MethodPost, "/trigger", bytes.NewBufferString(body))
+		if token != "" {
+			r.Header.Set("Authorization", token)
+		}
+		w := httptest.NewRecorder()
+		c.trigger(w, r)
+		assert.Equal(t, http.StatusUnauthorized, w.Code, "authorization %s", token)
+	}
+	assert.Empty(t, metaPipelineClient.params)
+
+	r := httptest.NewRequest(http.MethodPost, "/trigger", bytes.NewBufferString(body))
+	r.Header.Set("Authorization", "Bearer s3cr3t")
+	w := httptest.NewRecorder()
+	c.trigger(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+	require.Len(t, metaPipelineClient.params, 1)
+	assert.Equal(t, "https://github.com/myorg/myapp.git", metaPipelineClient.params[0].PullRef.SourceURL())
+	assert.Equal(t, "staging", metaPipelineClient.params[0].Parameters["target"])
+
+	r = httptest.NewRequest(http.MethodPost, "/trigger", bytes.NewBufferString(`{"owner": "myorg"}`))
+	r.Header.Set("Authorization", "Bearer s3cr3t")
+	w = httptest.NewRecorder()
+	c.trigger(w, r)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -87,6 +87,7 @@ func NewCmdCreate(commonOpts *opts.CommonOptions) *cobra.Command {
 	cmd.AddCommand(NewCmdCreateTerraform(commonOpts))
 	cmd.AddCommand(NewCmdCreateToken(commonOpts))
 	cmd.AddCommand(NewCmdCreateTracker(commonOpts))
+	cmd.AddCommand(NewCmdCreateTunnel(commonOpts))
 	cmd.AddCommand(NewCmdCreateUser(commonOpts))
 	cmd.AddCommand(vault.NewCmdCreateVault(commonOpts))
 	cmd.AddCommand(NewCmdCreateVariable(commonOpts))
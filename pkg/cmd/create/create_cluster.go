@@ -2,6 +2,14 @@ package create
 
 import (
 	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/cloud"
+	"github.com/jenkins-x/jx/pkg/terraform"
+	"github.com/jenkins-x/jx/pkg/util"
 
 	"github.com/jenkins-x/jx/pkg/cmd/create/options"
 
@@ -27,6 +35,7 @@ type CreateClusterOptions struct {
 	Flags            initcmd.InitFlags
 	Provider         string
 	SkipInstallation bool `mapstructure:"skip-installation"`
+	ApplyTerraform   string
 }
 
 const (
@@ -36,6 +45,10 @@ const (
 	optionClusterName       = "cluster-name"
 	optionCloudProvider     = "cloud-provider"
 	optionSkipInstallation  = "skip-installation"
+	optionSpotBuildPool     = "spot-build-pool"
+
+	// spotBuildPoolName the name of the spot/preemptible node pool created for builds
+	spotBuildPoolName = "jx-builds-spot"
 )
 
 const (
@@ -49,6 +62,8 @@ const (
     * iks (IBM Cloud Kubernetes Service - https://console.bluemix.net/docs/containers)
     * oke (Oracle Cloud Infrastructure Container Engine for Kubernetes - https://docs.cloud.oracle.com/iaas/Content/ContEng/Concepts/contengoverview.htm)
     * kubernetes for custom installations of Kubernetes
+    * kind (local Kubernetes cluster inside docker containers on your laptop)
+    * k3d (local k3s cluster inside docker containers on your laptop)
     * minikube (single-node Kubernetes cluster inside a VM on your laptop)
 	* minishift (single-node OpenShift cluster inside a VM on your laptop)
 	* openshift for installing on 3.9.x or later clusters of OpenShift
@@ -87,6 +102,9 @@ var (
 
 		jx create cluster minikube
 
+		# to apply a terraform module generated via 'jx create cluster eks --terraform-out ./infra'
+		jx create cluster --apply-terraform ./infra
+
 `)
 )
 
@@ -116,7 +134,10 @@ func NewCmdCreateCluster(commonOpts *opts.CommonOptions) *cobra.Command {
 	cmd.AddCommand(NewCmdCreateClusterMinishift(commonOpts))
 	cmd.AddCommand(NewCmdCreateClusterOKE(commonOpts))
 	cmd.AddCommand(NewCmdCreateClusterIKS(commonOpts))
+	cmd.AddCommand(NewCmdCreateClusterKind(commonOpts))
+	cmd.AddCommand(NewCmdCreateClusterK3D(commonOpts))
 
+	cmd.Flags().StringVarP(&options.ApplyTerraform, "apply-terraform", "", "", "Plans and applies the terraform module in the directory generated via the --terraform-out flag of a provider")
 	return cmd
 }
 
@@ -161,7 +182,98 @@ func (o *CreateClusterOptions) initAndInstall(provider string) error {
 	return nil
 }
 
+// enableSpotBuilds configures the team to schedule the build pods on the spot build node pool of the new cluster
+func (o *CreateClusterOptions) enableSpotBuilds() error {
+	if o.SkipInstallation {
+		log.Logger().Infof("Run %s once Jenkins X is installed to schedule the builds on the spot build node pool", util.ColorInfo("jx edit buildpod --spot"))
+		return nil
+	}
+	callback := func(env *v1.Environment) error {
+		teamSettings := &env.Spec.TeamSettings
+		spot := true
+		for i := range teamSettings.BuildPodPolicies {
+			if teamSettings.BuildPodPolicies[i].PipelineKind == "" {
+				teamSettings.BuildPodPolicies[i].Spot = &spot
+				return nil
+			}
+		}
+		teamSettings.BuildPodPolicies = append(teamSettings.BuildPodPolicies, v1.BuildPodPolicy{Spot: &spot})
+		return nil
+	}
+	err := o.ModifyDevEnvironment(callback)
+	if err != nil {
+		return errors.Wrap(err, "enabling spot builds for the team")
+	}
+	log.Logger().Infof("Builds will be scheduled on the spot build node pool %s", util.ColorInfo(spotBuildPoolName))
+	return nil
+}
+
 // Run returns help if function is run without any argument
 func (o *CreateClusterOptions) Run() error {
+	if o.ApplyTerraform != "" {
+		return o.applyTerraform(o.ApplyTerraform)
+	}
 	return o.Cmd.Help()
 }
+
+// applyTerraform plans and applies the terraform module in the given directory. The outputs are written to a file so
+// that they can be mapped into the requirements via 'jx boot --requirements-from-terraform'
+func (o *CreateClusterOptions) applyTerraform(dir string) error {
+	exists, err := util.DirExists(dir)
+	if err != nil {
+		return errors.Wrapf(err, "checking if directory %s exists", dir)
+	}
+	if !exists {
+		return util.InvalidOptionf("apply-terraform", dir, "the directory does not exist")
+	}
+	err = terraform.CheckVersion()
+	if err != nil {
+		return err
+	}
+	err = terraform.InitDir(dir)
+	if err != nil {
+		return err
+	}
+	planFile := "jx.tfplan"
+	plan, err := terraform.PlanDir(dir, planFile)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(filepath.Join(dir, planFile))
+	log.Logger().Info(plan)
+
+	if !o.BatchMode && !util.Confirm("Would you like to apply this plan?", false, "The plan above is applied exactly as shown", o.GetIOFileHandles()) {
+		log.Logger().Infof("Terraform plan not applied")
+		return nil
+	}
+	err = terraform.ApplyPlan(dir, planFile, o.Out, o.Err)
+	if err != nil {
+		return err
+	}
+
+	data, err := terraform.OutputJSON(dir)
+	if err != nil {
+		return err
+	}
+	outputsFile := filepath.Join(dir, terraform.OutputsFileName)
+	err = ioutil.WriteFile(outputsFile, []byte(data), util.DefaultFileWritePermissions)
+	if err != nil {
+		return errors.Wrapf(err, "writing the terraform outputs to %s", outputsFile)
+	}
+	outputs, err := terraform.ParseOutputs([]byte(data))
+	if err != nil {
+		return errors.Wrap(err, "parsing the terraform outputs")
+	}
+
+	clusterName, _ := outputs["cluster_name"].(string)
+	region, _ := outputs["region"].(string)
+	if outputs["cloud_provider"] == cloud.EKS && clusterName != "" && region != "" {
+		err = o.RunCommandVerbose("aws", "eks", "update-kubeconfig", "--name", clusterName, "--region", region)
+		if err != nil {
+			return errors.Wrapf(err, "connecting to EKS cluster %s", clusterName)
+		}
+	}
+	log.Logger().Infof("Wrote the terraform outputs to %s", util.ColorInfo(outputsFile))
+	log.Logger().Infof("Install Jenkins X via: %s", util.ColorInfo("jx boot --requirements-from-terraform "+outputsFile))
+	return nil
+}
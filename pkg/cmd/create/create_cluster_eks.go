@@ -1,6 +1,7 @@
 package create
 
 import (
+	"io/ioutil"
 	"os"
 	"strconv"
 	"strings"
@@ -21,11 +22,15 @@ import (
 	"github.com/jenkins-x/jx/pkg/features"
 	"github.com/jenkins-x/jx/pkg/kube"
 	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/terraform"
 	"github.com/jenkins-x/jx/pkg/util"
 
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
 	"github.com/jenkins-x/jx/pkg/cmd/opts"
 	"github.com/jenkins-x/jx/pkg/cmd/templates"
 	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/yaml"
 )
 
 // CreateClusterEKSOptions contains the CLI flags
@@ -49,6 +54,42 @@ type CreateClusterEKSFlags struct {
 	Verbose             int
 	AWSOperationTimeout time.Duration
 	Tags                string
+	SpotBuildPool       bool
+	SpotNodeTypes       string
+	SpotNodesMax        int
+	TerraformOut        string
+}
+
+// eksctlConfig the eksctl configuration file used to create the spot build node group
+type eksctlConfig struct {
+	APIVersion string            `json:"apiVersion"`
+	Kind       string            `json:"kind"`
+	Metadata   eksctlMetadata    `json:"metadata"`
+	NodeGroups []eksctlNodeGroup `json:"nodeGroups"`
+}
+
+type eksctlMetadata struct {
+	Name   string `json:"name"`
+	Region string `json:"region"`
+}
+
+type eksctlNodeGroup struct {
+	Name                  string                      `json:"name"`
+	MinSize               int                         `json:"minSize"`
+	MaxSize               int                         `json:"maxSize"`
+	DesiredCapacity       int                         `json:"desiredCapacity"`
+	VolumeSize            int                         `json:"volumeSize,omitempty"`
+	InstancesDistribution eksctlInstancesDistribution `json:"instancesDistribution"`
+	Labels                map[string]string           `json:"labels"`
+	Taints                map[string]string           `json:"taints"`
+	Tags                  map[string]string           `json:"tags,omitempty"`
+}
+
+type eksctlInstancesDistribution struct {
+	InstanceTypes                       []string `json:"instanceTypes"`
+	OnDemandBaseCapacity                int      `json:"onDemandBaseCapacity"`
+	OnDemandPercentageAboveBaseCapacity int      `json:"onDemandPercentageAboveBaseCapacity"`
+	SpotInstancePools                   int      `json:"spotInstancePools"`
 }
 
 var (
@@ -66,6 +107,13 @@ var (
 
 		# to specify the zones
 		jx create cluster eks --zones us-west-2a,us-west-2b,us-west-2c
+
+		# to create a dedicated node group of spot instances for the build pods
+		jx create cluster eks --cluster-name mycluster --spot-build-pool --spot-node-types m5.large,m5a.large,m4.large
+
+		# to generate a terraform module to review and commit instead of creating the cluster, then apply it
+		jx create cluster eks --cluster-name mycluster --terraform-out ./infra
+		jx create cluster --apply-terraform ./infra
 `)
 )
 
@@ -107,12 +155,20 @@ func NewCmdCreateClusterEKS(commonOpts *opts.CommonOptions) *cobra.Command {
 	cmd.Flags().StringVarP(&options.Flags.Zones, optionZones, "z", "", "Availability Zones. Auto-select if not specified. If provided, this overrides the $EKS_AVAILABILITY_ZONES environment variable")
 	cmd.Flags().StringVarP(&options.Flags.Profile, "profile", "p", "", "AWS profile to use. If provided, this overrides the AWS_PROFILE environment variable")
 	cmd.Flags().StringVarP(&options.Flags.SshPublicKey, "ssh-public-key", "", "", "SSH public key to use for nodes (import from local path, or use existing EC2 key pair) (default \"~/.ssh/id_rsa.pub\")")
+	cmd.Flags().BoolVarP(&options.Flags.SpotBuildPool, optionSpotBuildPool, "", false, "Creates a dedicated node group of spot instances which the build pods are scheduled on")
+	cmd.Flags().StringVarP(&options.Flags.SpotNodeTypes, "spot-node-types", "", "", "The comma separated instance types of the spot build node group. Defaults to the node type")
+	cmd.Flags().IntVarP(&options.Flags.SpotNodesMax, "spot-nodes-max", "", 5, "The maximum number of nodes of the spot build node group")
 	cmd.Flags().StringVarP(&options.Flags.Tags, "tags", "", "CreatedBy=JenkinsX", "A list of KV pairs used to tag all instance groups in AWS (eg \"Owner=John Doe,Team=Some Team\").")
+	cmd.Flags().StringVarP(&options.Flags.TerraformOut, "terraform-out", "", "", "Writes a terraform module creating the cluster, node groups, IAM roles, buckets and DNS zone to the directory instead of creating the cluster. Use 'jx create cluster --apply-terraform' to apply it")
 	return cmd
 }
 
 // Runs the command logic (including installing required binaries, parsing options and aggregating eksctl command)
 func (o *CreateClusterEKSOptions) Run() error {
+	if o.Flags.TerraformOut != "" {
+		return o.writeTerraformModule()
+	}
+
 	var deps []string
 
 	d := packages.BinaryShouldBeInstalled("eksctl")
@@ -138,6 +194,10 @@ func (o *CreateClusterEKSOptions) Run() error {
 	}
 	flags := &o.Flags
 
+	if flags.SpotBuildPool && flags.ClusterName == "" {
+		return util.MissingOption(optionClusterName)
+	}
+
 	zones := flags.Zones
 	if zones == "" {
 		zones = os.Getenv("EKS_AVAILABILITY_ZONES")
@@ -224,10 +284,202 @@ cluster provisioning. Cleaning up stack %s and recreating it with eksctl.`,
 	}
 	log.Blank()
 
+	if flags.SpotBuildPool {
+		err = o.createSpotBuildPool(region)
+		if err != nil {
+			return err
+		}
+	}
+
 	o.InstallOptions.SetInstallValues(map[string]string{
 		kube.Region: region,
 	})
 
 	log.Logger().Info("Initialising cluster ...")
-	return o.initAndInstall(cloud.EKS)
+	err = o.initAndInstall(cloud.EKS)
+	if err != nil {
+		return err
+	}
+	if flags.SpotBuildPool {
+		return o.enableSpotBuilds()
+	}
+	return nil
+}
+
+// createSpotBuildPool creates a node group of spot instances which is labelled and tainted so that only the build pods
+// are scheduled on it. The node group scales down to zero nodes when there are no builds
+func (o *CreateClusterEKSOptions) createSpotBuildPool(region string) error {
+	data, err := o.spotBuildPoolConfig(region)
+	if err != nil {
+		return err
+	}
+	file, err := ioutil.TempFile("", "jx-eksctl-spot-")
+	if err != nil {
+		return errors.Wrap(err, "creating the eksctl configuration file")
+	}
+	defer os.Remove(file.Name())
+	_, err = file.Write(data)
+	file.Close()
+	if err != nil {
+		return errors.Wrapf(err, "writing the eksctl configuration file %s", file.Name())
+	}
+
+	args := []string{"create", "nodegroup", "--config-file", file.Name()}
+	if o.Flags.Profile != "" {
+		args = append(args, "--profile", o.Flags.Profile)
+	}
+	log.Logger().Infof("Creating the spot build node group %s...", util.ColorInfo(spotBuildPoolName))
+	err = o.RunCommandVerbose("eksctl", args...)
+	if err != nil {
+		return errors.Wrapf(err, "creating the spot build node group %s", spotBuildPoolName)
+	}
+	return nil
+}
+
+// spotBuildPoolConfig returns the eksctl configuration of the spot build node group
+func (o *CreateClusterEKSOptions) spotBuildPoolConfig(region string) ([]byte, error) {
+	flags := &o.Flags
+	nodeTypes := flags.SpotNodeTypes
+	if nodeTypes == "" {
+		nodeTypes = flags.NodeType
+	}
+	instanceTypes := splitList(nodeTypes)
+	spotInstancePools := len(instanceTypes)
+	if spotInstancePools > 20 {
+		spotInstancePools = 20
+	}
+	maxSize := flags.SpotNodesMax
+	if maxSize <= 0 {
+		maxSize = 5
+	}
+	tags := parseTags(flags.Tags)
+	config := eksctlConfig{
+		APIVersion: "eksctl.io/v1alpha5",
+		Kind:       "ClusterConfig",
+		Metadata: eksctlMetadata{
+			Name:   flags.ClusterName,
+			Region: region,
+		},
+		NodeGroups: []eksctlNodeGroup{
+			{
+				Name:            spotBuildPoolName,
+				MinSize:         0,
+				MaxSize:         maxSize,
+				DesiredCapacity: 0,
+				VolumeSize:      flags.NodeVolumeSize,
+				InstancesDistribution: eksctlInstancesDistribution{
+					InstanceTypes:     instanceTypes,
+					SpotInstancePools: spotInstancePools,
+				},
+				Labels: map[string]string{
+					v1.SpotBuildPoolLabel: v1.SpotBuildPoolValue,
+				},
+				Taints: map[string]string{
+					v1.SpotBuildPoolLabel: v1.SpotBuildPoolValue + ":" + string(corev1.TaintEffectNoSchedule),
+				},
+				Tags: tags,
+			},
+		},
+	}
+	data, err := yaml.Marshal(config)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshalling the eksctl configuration")
+	}
+	return data, nil
+}
+
+// writeTerraformModule writes the terraform module which creates the cluster so that it can be reviewed and applied
+// later rather than creating the cluster with eksctl
+func (o *CreateClusterEKSOptions) writeTerraformModule() error {
+	module, err := o.terraformModule()
+	if err != nil {
+		return err
+	}
+	files, err := terraform.WriteEKSModule(o.Flags.TerraformOut, module)
+	if err != nil {
+		return errors.Wrapf(err, "writing the terraform module to %s", o.Flags.TerraformOut)
+	}
+	for _, f := range files {
+		log.Logger().Infof("Created %s", util.ColorInfo(f))
+	}
+	log.Logger().Infof("\nReview the module, commit it to version control then create the cluster via: %s", util.ColorInfo("jx create cluster --apply-terraform "+o.Flags.TerraformOut))
+	return nil
+}
+
+// terraformModule returns the variables of the terraform module from the flags
+func (o *CreateClusterEKSOptions) terraformModule() (*terraform.EKSModule, error) {
+	flags := &o.Flags
+	if flags.ClusterName == "" {
+		return nil, util.MissingOption(optionClusterName)
+	}
+	region, err := session.ResolveRegion(flags.Profile, flags.Region)
+	if err != nil {
+		return nil, err
+	}
+	zones := flags.Zones
+	if zones == "" {
+		zones = os.Getenv("EKS_AVAILABILITY_ZONES")
+	}
+	desired := flags.NodeCount
+	if desired < 0 {
+		desired = 3
+	}
+	nodesMin := flags.NodesMin
+	if nodesMin < 0 {
+		nodesMin = desired
+	}
+	nodesMax := flags.NodesMax
+	if nodesMax < 0 {
+		nodesMax = desired
+	}
+	spotNodeTypes := []string{}
+	if flags.SpotBuildPool {
+		spotNodeTypes = splitList(flags.SpotNodeTypes)
+		if len(spotNodeTypes) == 0 {
+			spotNodeTypes = []string{flags.NodeType}
+		}
+	}
+	spotNodesMax := flags.SpotNodesMax
+	if spotNodesMax <= 0 {
+		spotNodesMax = 5
+	}
+	return &terraform.EKSModule{
+		ClusterName:       flags.ClusterName,
+		Region:            region,
+		AvailabilityZones: splitList(zones),
+		NodeType:          flags.NodeType,
+		NodesDesired:      desired,
+		NodesMin:          nodesMin,
+		NodesMax:          nodesMax,
+		NodeVolumeSize:    flags.NodeVolumeSize,
+		SpotBuildPool:     flags.SpotBuildPool,
+		SpotNodeTypes:     spotNodeTypes,
+		SpotNodesMax:      spotNodesMax,
+		Domain:            o.InstallOptions.Flags.Domain,
+		Tags:              parseTags(flags.Tags),
+	}, nil
+}
+
+// parseTags parses the comma separated list of key=value tags
+func parseTags(text string) map[string]string {
+	tags := map[string]string{}
+	for _, tag := range splitList(text) {
+		paths := strings.SplitN(tag, "=", 2)
+		if len(paths) == 2 {
+			tags[paths[0]] = paths[1]
+		}
+	}
+	return tags
+}
+
+// splitList splits the comma separated list ignoring empty values
+func splitList(text string) []string {
+	answer := []string{}
+	for _, value := range strings.Split(text, ",") {
+		value = strings.TrimSpace(value)
+		if value != "" {
+			answer = append(answer, value)
+		}
+	}
+	return answer
 }
@@ -0,0 +1,78 @@
+package create
+
+import (
+	"testing"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/yaml"
+)
+
+func TestSpotBuildPoolConfig(t *testing.T) {
+	o := &CreateClusterEKSOptions{
+		Flags: CreateClusterEKSFlags{
+			ClusterName:    "mycluster",
+			NodeType:       "m5.large",
+			NodeVolumeSize: 20,
+			SpotNodeTypes:  "m5.large, m5a.large,m4.large",
+			SpotNodesMax:   10,
+			Tags:           "CreatedBy=JenkinsX,Team=Some Team",
+		},
+	}
+
+	data, err := o.spotBuildPoolConfig("us-west-2")
+	require.NoError(t, err)
+
+	config := eksctlConfig{}
+	err = yaml.Unmarshal(data, &config)
+	require.NoError(t, err)
+
+	assert.Equal(t, "mycluster", config.Metadata.Name)
+	assert.Equal(t, "us-west-2", config.Metadata.Region)
+	require.Len(t, config.NodeGroups, 1)
+	nodeGroup := config.NodeGroups[0]
+	assert.Equal(t, spotBuildPoolName, nodeGroup.Name)
+	assert.Equal(t, 0, nodeGroup.MinSize)
+	assert.Equal(t, 10, nodeGroup.MaxSize)
+	assert.Equal(t, []string{"m5.large", "m5a.large", "m4.large"}, nodeGroup.InstancesDistribution.InstanceTypes)
+	assert.Equal(t, 0, nodeGroup.InstancesDistribution.OnDemandPercentageAboveBaseCapacity)
+	assert.Equal(t, v1.SpotBuildPoolValue, nodeGroup.Labels[v1.SpotBuildPoolLabel])
+	assert.Equal(t, "spot:NoSchedule", nodeGroup.Taints[v1.SpotBuildPoolLabel])
+	assert.Equal(t, "Some Team", nodeGroup.Tags["Team"])
+}
+
+func TestTerraformModule(t *testing.T) {
+	o := &CreateClusterEKSOptions{
+		Flags: CreateClusterEKSFlags{
+			ClusterName:    "mycluster",
+			Region:         "eu-west-1",
+			Zones:          "eu-west-1a,eu-west-1b",
+			NodeType:       "m5.large",
+			NodeCount:      4,
+			NodesMin:       -1,
+			NodesMax:       6,
+			NodeVolumeSize: 50,
+			SpotBuildPool:  true,
+			Tags:           "CreatedBy=JenkinsX",
+		},
+	}
+
+	module, err := o.terraformModule()
+	require.NoError(t, err)
+
+	assert.Equal(t, "mycluster", module.ClusterName)
+	assert.Equal(t, "eu-west-1", module.Region)
+	assert.Equal(t, []string{"eu-west-1a", "eu-west-1b"}, module.AvailabilityZones)
+	assert.Equal(t, 4, module.NodesDesired)
+	assert.Equal(t, 4, module.NodesMin)
+	assert.Equal(t, 6, module.NodesMax)
+	assert.Equal(t, []string{"m5.large"}, module.SpotNodeTypes)
+	assert.Equal(t, 5, module.SpotNodesMax)
+	assert.Equal(t, map[string]string{"CreatedBy": "JenkinsX"}, module.Tags)
+	assert.NoError(t, module.Validate())
+
+	o.Flags.ClusterName = ""
+	_, err = o.terraformModule()
+	assert.Error(t, err)
+}
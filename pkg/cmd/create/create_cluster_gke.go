@@ -6,6 +6,7 @@ import (
 	"time"
 
 	randomdata "github.com/Pallinder/go-randomdata"
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
 	"github.com/jenkins-x/jx/pkg/cmd/helper"
 	"github.com/pkg/errors"
 	survey "gopkg.in/AlecAivazis/survey.v1"
@@ -23,6 +24,7 @@ import (
 	"github.com/jenkins-x/jx/pkg/util"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	corev1 "k8s.io/api/core/v1"
 )
 
 // CreateClusterOptions the flags for running create cluster
@@ -53,8 +55,11 @@ type CreateClusterGKEFlags struct {
 	EnhancedScopes           bool `mapstructure:"enhanced-scopes"`
 	Scopes                   []string
 	Preemptible              bool
-	EnhancedApis             bool `mapstructure:"enhanced-apis"`
-	UseStackDriverMonitoring bool `mapstructure:"use-stackdriver-monitoring"`
+	EnhancedApis             bool   `mapstructure:"enhanced-apis"`
+	UseStackDriverMonitoring bool   `mapstructure:"use-stackdriver-monitoring"`
+	SpotBuildPool            bool   `mapstructure:"spot-build-pool"`
+	SpotBuildPoolMachineType string `mapstructure:"spot-build-pool-machine-type"`
+	SpotBuildPoolMaxNodes    string `mapstructure:"spot-build-pool-max-nodes"`
 }
 
 const (
@@ -78,6 +83,8 @@ const (
 	labelsFlagName            = "labels"
 	scopeFlagName             = "scope"
 	stackDriverFlagName       = "use-stackdriver-monitoring"
+	spotMachineTypeFlagName   = "spot-build-pool-machine-type"
+	spotMaxNodesFlagName      = "spot-build-pool-max-nodes"
 )
 
 var (
@@ -100,6 +107,9 @@ var (
 
 		jx create cluster gke
 
+		# create a cluster with a dedicated preemptible node pool for the build pods
+		jx create cluster gke --spot-build-pool --spot-build-pool-max-nodes 10
+
 `)
 )
 
@@ -151,6 +161,9 @@ func NewCmdCreateClusterGKE(commonOpts *opts.CommonOptions) *cobra.Command {
 	cmd.Flags().BoolVarP(&options.Flags.EnhancedScopes, enhancedScopesFlagName, "", false, "Use enhanced Oauth scopes for access to GCS/GCR")
 	cmd.Flags().BoolVarP(&options.Flags.EnhancedApis, enhancedAPIFlagName, "", false, "Enable enhanced APIs to utilise Container Registry & Cloud Build")
 	cmd.Flags().BoolVarP(&options.Flags.UseStackDriverMonitoring, stackDriverFlagName, "", true, "Enable Stackdriver Kubernetes Engine Monitoring")
+	cmd.Flags().BoolVarP(&options.Flags.SpotBuildPool, optionSpotBuildPool, "", false, "Creates a dedicated node pool of preemptible VMs which the build pods are scheduled on")
+	cmd.Flags().StringVarP(&options.Flags.SpotBuildPoolMachineType, spotMachineTypeFlagName, "", "", "The type of machine of the spot build node pool. Defaults to the machine type of the cluster")
+	cmd.Flags().StringVarP(&options.Flags.SpotBuildPoolMaxNodes, spotMaxNodesFlagName, "", "5", "The maximum number of nodes of the spot build node pool in each of the cluster's zones")
 	bindGKEConfigToFlags(cmd)
 
 	return cmd
@@ -178,6 +191,9 @@ func bindGKEConfigToFlags(cmd *cobra.Command) {
 	_ = viper.BindPFlag(clusterConfigKey(enhancedScopesFlagName), cmd.Flags().Lookup(enhancedScopesFlagName))
 	_ = viper.BindPFlag(clusterConfigKey(enhancedAPIFlagName), cmd.Flags().Lookup(enhancedAPIFlagName))
 	_ = viper.BindPFlag(clusterConfigKey(stackDriverFlagName), cmd.Flags().Lookup(stackDriverFlagName))
+	_ = viper.BindPFlag(clusterConfigKey(optionSpotBuildPool), cmd.Flags().Lookup(optionSpotBuildPool))
+	_ = viper.BindPFlag(clusterConfigKey(spotMachineTypeFlagName), cmd.Flags().Lookup(spotMachineTypeFlagName))
+	_ = viper.BindPFlag(clusterConfigKey(spotMaxNodesFlagName), cmd.Flags().Lookup(spotMaxNodesFlagName))
 }
 
 func (o *CreateClusterGKEOptions) Run() error {
@@ -592,6 +608,13 @@ func (o *CreateClusterGKEOptions) createClusterGKE() error {
 		return err
 	}
 
+	if o.Flags.SpotBuildPool {
+		err = o.createSpotBuildPool(machineType, zone, region, projectID)
+		if err != nil {
+			return err
+		}
+	}
+
 	log.Logger().Info("Initialising cluster ...")
 
 	o.InstallOptions.SetInstallValues(map[string]string{
@@ -606,6 +629,13 @@ func (o *CreateClusterGKEOptions) createClusterGKE() error {
 		return err
 	}
 
+	if o.Flags.SpotBuildPool {
+		err = o.enableSpotBuilds()
+		if err != nil {
+			return err
+		}
+	}
+
 	getCredsCommand := []string{"container", "clusters", "get-credentials", o.Flags.ClusterName}
 	if "" != zone {
 		getCredsCommand = append(getCredsCommand, "--zone", zone)
@@ -646,6 +676,55 @@ func (o *CreateClusterGKEOptions) createClusterGKE() error {
 	return nil
 }
 
+// createSpotBuildPool creates a node pool of preemptible VMs which is labelled and tainted so that only the build pods
+// are scheduled on it. The pool scales down to zero nodes when there are no builds
+func (o *CreateClusterGKEOptions) createSpotBuildPool(machineType string, zone string, region string, projectID string) error {
+	if o.Flags.SpotBuildPoolMachineType != "" {
+		machineType = o.Flags.SpotBuildPoolMachineType
+	}
+	maxNodes := o.Flags.SpotBuildPoolMaxNodes
+	if maxNodes == "" {
+		maxNodes = "5"
+	}
+	spotLabel := v1.SpotBuildPoolLabel + "=" + v1.SpotBuildPoolValue
+	args := []string{"container", "node-pools", "create", spotBuildPoolName,
+		"--cluster", o.Flags.ClusterName,
+		"--project", projectID,
+		"--machine-type", machineType,
+		"--preemptible",
+		"--num-nodes", "0",
+		"--enable-autoscaling",
+		"--min-nodes", "0",
+		"--max-nodes", maxNodes,
+		"--node-labels", spotLabel,
+		"--node-taints", spotLabel + ":" + string(corev1.TaintEffectNoSchedule)}
+
+	if region != "" {
+		args = append(args, "--region", region)
+	} else {
+		args = append(args, "--zone", zone)
+	}
+
+	if o.Flags.DiskSize != "" {
+		args = append(args, "--disk-size", o.Flags.DiskSize)
+	}
+
+	if o.Flags.ImageType != "" {
+		args = append(args, "--image-type", o.Flags.ImageType)
+	}
+
+	if len(o.Flags.Scopes) > 0 {
+		args = append(args, fmt.Sprintf("--scopes=%s", strings.Join(o.Flags.Scopes, ",")))
+	}
+
+	log.Logger().Infof("Creating the spot build node pool %s...", util.ColorInfo(spotBuildPoolName))
+	err := o.RunCommand("gcloud", args...)
+	if err != nil {
+		return errors.Wrapf(err, "creating the spot build node pool %s", spotBuildPoolName)
+	}
+	return nil
+}
+
 // AddLabel adds the given label key and value to the label string
 func AddLabel(labels string, name string, value string) string {
 	username := util.SanitizeLabel(value)
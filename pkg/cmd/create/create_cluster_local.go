@@ -0,0 +1,305 @@
+package create
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/jenkins-x/jx/pkg/cloud"
+	"github.com/jenkins-x/jx/pkg/cmd/create/options"
+	"github.com/jenkins-x/jx/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/pkg/config"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/packages"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
+)
+
+// CreateClusterLocalOptions the options for creating a local kind or k3d cluster
+type CreateClusterLocalOptions struct {
+	options.CreateOptions
+
+	Provider string
+	Flags    CreateClusterLocalFlags
+}
+
+// CreateClusterLocalFlags the flags for creating a local kind or k3d cluster
+type CreateClusterLocalFlags struct {
+	ClusterName  string
+	Workers      int
+	Image        string
+	RegistryName string
+	RegistryPort int
+	HTTPPort     int
+	HTTPSPort    int
+}
+
+// kindConfig the kind configuration file of the cluster
+type kindConfig struct {
+	Kind                    string     `json:"kind"`
+	APIVersion              string     `json:"apiVersion"`
+	ContainerdConfigPatches []string   `json:"containerdConfigPatches,omitempty"`
+	Nodes                   []kindNode `json:"nodes"`
+}
+
+type kindNode struct {
+	Role                 string            `json:"role"`
+	Image                string            `json:"image,omitempty"`
+	KubeadmConfigPatches []string          `json:"kubeadmConfigPatches,omitempty"`
+	ExtraPortMappings    []kindPortMapping `json:"extraPortMappings,omitempty"`
+}
+
+type kindPortMapping struct {
+	ContainerPort int    `json:"containerPort"`
+	HostPort      int    `json:"hostPort"`
+	Protocol      string `json:"protocol,omitempty"`
+}
+
+const (
+	// kindNetwork the docker network of the kind nodes which the registry is connected to
+	kindNetwork = "kind"
+
+	kindIngressReadyPatch = `kind: InitConfiguration
+nodeRegistration:
+  kubeletExtraArgs:
+    node-labels: "ingress-ready=true"
+`
+	kindJoinIngressReadyPatch = `kind: JoinConfiguration
+nodeRegistration:
+  kubeletExtraArgs:
+    node-labels: "ingress-ready=true"
+`
+)
+
+var (
+	createClusterLocalLong = templates.LongDesc(`
+		This command creates a new Kubernetes cluster on your laptop using %[1]s for developing and demoing Jenkins X
+
+		A container registry is started alongside the cluster and the ports of the ingress controller are mapped to
+		localhost so that the applications can be reached via the domain 127.0.0.1.nip.io.
+
+		Once the cluster is created boot Jenkins X with the %[1]s kubernetes provider which uses a lightweight profile
+		of the components. To receive webhooks from your git provider start a tunnel such as ngrok to the ingress
+		controller and set 'cluster.local.tunnel' and 'cluster.local.tunnelURL' in the 'jx-requirements.yml' file.
+
+`)
+
+	createClusterLocalExample = templates.Examples(`
+		# to create a local cluster
+		jx create cluster %[1]s
+
+		# then boot Jenkins X
+		jx boot init --provider %[1]s
+		jx boot
+`)
+)
+
+// NewCmdCreateClusterKind creates the command to create a local kind cluster
+func NewCmdCreateClusterKind(commonOpts *opts.CommonOptions) *cobra.Command {
+	return newCmdCreateClusterLocal(commonOpts, cloud.KIND, "kind (Kubernetes in Docker)")
+}
+
+// NewCmdCreateClusterK3D creates the command to create a local k3d cluster
+func NewCmdCreateClusterK3D(commonOpts *opts.CommonOptions) *cobra.Command {
+	return newCmdCreateClusterLocal(commonOpts, cloud.K3D, "k3d (k3s in Docker)")
+}
+
+func newCmdCreateClusterLocal(commonOpts *opts.CommonOptions, provider string, description string) *cobra.Command {
+	options := &CreateClusterLocalOptions{
+		CreateOptions: options.CreateOptions{
+			CommonOptions: commonOpts,
+		},
+		Provider: provider,
+	}
+	cmd := &cobra.Command{
+		Use:     provider,
+		Short:   "Create a new local Kubernetes cluster using " + description,
+		Long:    fmt.Sprintf(createClusterLocalLong, provider),
+		Example: fmt.Sprintf(createClusterLocalExample, provider),
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.Flags().StringVarP(&options.Flags.ClusterName, optionClusterName, "n", provider, "The name of the cluster")
+	cmd.Flags().IntVarP(&options.Flags.Workers, "workers", "", 0, "The number of worker nodes")
+	cmd.Flags().StringVarP(&options.Flags.Image, "image", "", "", "The node image of the cluster to choose the Kubernetes version")
+	cmd.Flags().StringVarP(&options.Flags.RegistryName, "registry-name", "", "", "The host name of the local container registry")
+	cmd.Flags().IntVarP(&options.Flags.RegistryPort, "registry-port", "", config.DefaultLocalRegistryPort, "The port of the local container registry")
+	cmd.Flags().IntVarP(&options.Flags.HTTPPort, "http-port", "", 80, "The port on localhost to expose the ingress controller on")
+	cmd.Flags().IntVarP(&options.Flags.HTTPSPort, "https-port", "", 443, "The port on localhost to expose the ingress controller on for TLS")
+	return cmd
+}
+
+// Run implements the command
+func (o *CreateClusterLocalOptions) Run() error {
+	requirements := &config.RequirementsConfig{}
+	requirements.Cluster.Provider = o.Provider
+	requirements.Cluster.ClusterName = o.Flags.ClusterName
+	requirements.Cluster.LocalConfig = &config.LocalClusterConfig{
+		RegistryName: o.Flags.RegistryName,
+		RegistryPort: o.Flags.RegistryPort,
+		HTTPPort:     o.Flags.HTTPPort,
+		HTTPSPort:    o.Flags.HTTPSPort,
+	}
+	err := requirements.ApplyLocalProfile()
+	if err != nil {
+		return err
+	}
+	local := requirements.Cluster.LocalConfig
+
+	for _, binary := range []string{"docker", o.Provider} {
+		_, err = packages.LookupForBinary(binary)
+		if err != nil {
+			return fmt.Errorf("could not find the %s binary on the PATH, please install it and try again", binary)
+		}
+	}
+
+	switch o.Provider {
+	case cloud.KIND:
+		err = o.createKindCluster(local)
+	case cloud.K3D:
+		err = o.createK3DCluster(local)
+	default:
+		err = fmt.Errorf("unsupported local kubernetes provider %s", o.Provider)
+	}
+	if err != nil {
+		return err
+	}
+
+	log.Logger().Infof("Created the %s cluster %s with the container registry %s", o.Provider, util.ColorInfo(o.Flags.ClusterName), util.ColorInfo(requirements.Cluster.Registry))
+	if o.Provider == cloud.K3D {
+		log.Logger().Infof("Connect to the cluster via: %s", util.ColorInfo(fmt.Sprintf("export KUBECONFIG=\"$(k3d get-kubeconfig --name='%s')\"", o.Flags.ClusterName)))
+	}
+	log.Logger().Infof("Boot Jenkins X via: %s", util.ColorInfo(fmt.Sprintf("jx boot init --provider %s && jx boot", o.Provider)))
+	return nil
+}
+
+// createKindCluster starts the registry and creates the kind cluster which uses it as a mirror
+func (o *CreateClusterLocalOptions) createKindCluster(local *config.LocalClusterConfig) error {
+	err := o.startRegistry(local)
+	if err != nil {
+		return err
+	}
+	data, err := o.kindClusterConfig(local)
+	if err != nil {
+		return err
+	}
+	file, err := ioutil.TempFile("", "jx-kind-")
+	if err != nil {
+		return errors.Wrap(err, "creating the kind configuration file")
+	}
+	defer os.Remove(file.Name())
+	_, err = file.Write(data)
+	file.Close()
+	if err != nil {
+		return errors.Wrapf(err, "writing the kind configuration file %s", file.Name())
+	}
+
+	log.Logger().Infof("Creating the kind cluster %s...", util.ColorInfo(o.Flags.ClusterName))
+	err = o.RunCommandVerbose("kind", "create", "cluster", "--name", o.Flags.ClusterName, "--config", file.Name())
+	if err != nil {
+		return errors.Wrapf(err, "creating the kind cluster %s", o.Flags.ClusterName)
+	}
+
+	// the nodes resolve the registry via its container name on the kind network
+	output, err := o.GetCommandOutput("", "docker", "network", "connect", kindNetwork, local.RegistryName)
+	if err != nil && !strings.Contains(output+err.Error(), "already exists") {
+		return errors.Wrapf(err, "connecting the registry %s to the %s network", local.RegistryName, kindNetwork)
+	}
+	return nil
+}
+
+// kindClusterConfig returns the kind configuration which maps the ingress ports to localhost and mirrors the registry
+func (o *CreateClusterLocalOptions) kindClusterConfig(local *config.LocalClusterConfig) ([]byte, error) {
+	registry := fmt.Sprintf("%s:%d", local.RegistryName, local.RegistryPort)
+	controlPlane := kindNode{
+		Role:  "control-plane",
+		Image: o.Flags.Image,
+	}
+	workers := []kindNode{}
+	for i := 0; i < o.Flags.Workers; i++ {
+		workers = append(workers, kindNode{
+			Role:  "worker",
+			Image: o.Flags.Image,
+		})
+	}
+
+	// the ingress controller runs on the first schedulable node so map the ports of that node to localhost
+	ingressNode := &controlPlane
+	patch := kindIngressReadyPatch
+	if len(workers) > 0 {
+		ingressNode = &workers[0]
+		patch = kindJoinIngressReadyPatch
+	}
+	ingressNode.KubeadmConfigPatches = []string{patch}
+	ingressNode.ExtraPortMappings = []kindPortMapping{
+		{ContainerPort: 80, HostPort: local.HTTPPort, Protocol: "TCP"},
+		{ContainerPort: 443, HostPort: local.HTTPSPort, Protocol: "TCP"},
+	}
+
+	cfg := kindConfig{
+		Kind:       "Cluster",
+		APIVersion: "kind.x-k8s.io/v1alpha4",
+		ContainerdConfigPatches: []string{
+			fmt.Sprintf("[plugins.\"io.containerd.grpc.v1.cri\".registry.mirrors.\"%s\"]\n  endpoint = [\"http://%s\"]", registry, registry),
+		},
+		Nodes: append([]kindNode{controlPlane}, workers...),
+	}
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshalling the kind configuration")
+	}
+	return data, nil
+}
+
+// startRegistry starts the local container registry unless it is already running
+func (o *CreateClusterLocalOptions) startRegistry(local *config.LocalClusterConfig) error {
+	running, err := o.GetCommandOutput("", "docker", "inspect", "-f", "{{.State.Running}}", local.RegistryName)
+	if err == nil && strings.TrimSpace(running) == "true" {
+		log.Logger().Infof("Using the running container registry %s", util.ColorInfo(local.RegistryName))
+		return nil
+	}
+	log.Logger().Infof("Starting the container registry %s...", util.ColorInfo(local.RegistryName))
+	port := strconv.Itoa(local.RegistryPort)
+	_, err = o.GetCommandOutput("", "docker", "run", "-d", "--restart=always", "-p", "127.0.0.1:"+port+":5000", "--name", local.RegistryName, "registry:2")
+	if err != nil {
+		return errors.Wrapf(err, "starting the container registry %s", local.RegistryName)
+	}
+	return nil
+}
+
+// createK3DCluster creates the k3d cluster together with its registry. Traefik is disabled as the ingress controller
+// is installed by jx boot
+func (o *CreateClusterLocalOptions) createK3DCluster(local *config.LocalClusterConfig) error {
+	args := []string{"create",
+		"--name", o.Flags.ClusterName,
+		"--publish", fmt.Sprintf("%d:80", local.HTTPPort),
+		"--publish", fmt.Sprintf("%d:443", local.HTTPSPort),
+		"--enable-registry",
+		"--registry-name", local.RegistryName,
+		"--registry-port", strconv.Itoa(local.RegistryPort),
+		"--server-arg", "--no-deploy=traefik",
+		"--wait", "300",
+	}
+	if o.Flags.Workers > 0 {
+		args = append(args, "--workers", strconv.Itoa(o.Flags.Workers))
+	}
+	if o.Flags.Image != "" {
+		args = append(args, "--image", o.Flags.Image)
+	}
+	log.Logger().Infof("Creating the k3d cluster %s...", util.ColorInfo(o.Flags.ClusterName))
+	err := o.RunCommandVerbose("k3d", args...)
+	if err != nil {
+		return errors.Wrapf(err, "creating the k3d cluster %s", o.Flags.ClusterName)
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package create
+
+import (
+	"testing"
+
+	"github.com/jenkins-x/jx/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/yaml"
+)
+
+func TestKindClusterConfig(t *testing.T) {
+	local := &config.LocalClusterConfig{
+		RegistryName: "kind-registry",
+		RegistryPort: 5000,
+		HTTPPort:     8080,
+		HTTPSPort:    8443,
+	}
+
+	o := &CreateClusterLocalOptions{}
+	data, err := o.kindClusterConfig(local)
+	require.NoError(t, err)
+
+	cfg := kindConfig{}
+	err = yaml.Unmarshal(data, &cfg)
+	require.NoError(t, err)
+	require.Len(t, cfg.Nodes, 1)
+	assert.Equal(t, "control-plane", cfg.Nodes[0].Role)
+	assert.Equal(t, []kindPortMapping{
+		{ContainerPort: 80, HostPort: 8080, Protocol: "TCP"},
+		{ContainerPort: 443, HostPort: 8443, Protocol: "TCP"},
+	}, cfg.Nodes[0].ExtraPortMappings)
+	require.Len(t, cfg.ContainerdConfigPatches, 1)
+	assert.Contains(t, cfg.ContainerdConfigPatches[0], `endpoint = ["http://kind-registry:5000"]`)
+
+	o.Flags.Workers = 2
+	data, err = o.kindClusterConfig(local)
+	require.NoError(t, err)
+	cfg = kindConfig{}
+	err = yaml.Unmarshal(data, &cfg)
+	require.NoError(t, err)
+	require.Len(t, cfg.Nodes, 3)
+	assert.Empty(t, cfg.Nodes[0].ExtraPortMappings)
+	assert.Len(t, cfg.Nodes[1].ExtraPortMappings, 2)
+	assert.Empty(t, cfg.Nodes[2].ExtraPortMappings)
+}
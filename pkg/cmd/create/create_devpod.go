@@ -27,6 +27,7 @@ import (
 	"github.com/jenkins-x/jx/pkg/cmd/opts"
 	"github.com/jenkins-x/jx/pkg/cmd/templates"
 	"github.com/jenkins-x/jx/pkg/config"
+	"github.com/jenkins-x/jx/pkg/devsync"
 	"github.com/jenkins-x/jx/pkg/helm"
 	"github.com/jenkins-x/jx/pkg/kube"
 	"github.com/jenkins-x/jx/pkg/kube/serviceaccount"
@@ -62,6 +63,12 @@ var (
 
 		# creates a new Maven DevPod 
 		jx create devpod -l maven
+
+		# creates a new DevPod and prints the command to connect VS Code to it
+		jx create devpod --ide vscode
+
+		# creates a new DevPod running the GoLand backend for JetBrains Gateway which is deleted after 4 hours of inactivity
+		jx create devpod --ide goland --idle-timeout 4h
 	`)
 )
 
@@ -91,6 +98,8 @@ type CreateDevPodOptions struct {
 	Import          bool
 	TempDir         bool
 	Theia           bool
+	IDE             string
+	IdleTimeout     time.Duration
 	ShellCmd        string
 	DockerRegistry  string
 	TillerNamespace string
@@ -142,6 +151,8 @@ func NewCmdCreateDevPod(commonOpts *opts.CommonOptions) *cobra.Command {
 	cmd.Flags().BoolVarP(&options.Import, "import", "", true, "Detect if there is a Git repository in the current directory and attempt to clone it into the DevPod. Ignored if used with --sync")
 	cmd.Flags().BoolVarP(&options.TempDir, "temp-dir", "", false, "If enabled and --import-url is supplied then create a temporary directory to clone the source to detect what kind of DevPod to create")
 	cmd.Flags().BoolVarP(&options.Theia, "theia", "", false, "If enabled use Eclipse Theia as the web based IDE")
+	cmd.Flags().StringVarP(&options.IDE, "ide", "", "", "The desktop IDE to connect to the DevPod remotely instead of using a web based IDE. One of: "+strings.Join(DevPodIDEs, ", "))
+	cmd.Flags().DurationVarP(&options.IdleTimeout, "idle-timeout", "", 0, fmt.Sprintf("The duration after which the DevPod is deleted by 'jx gc devpods' if it is idle. Defaults to %s when using --ide", DefaultDevPodIdleTimeout.String()))
 	cmd.Flags().StringVarP(&options.ShellCmd, "shell", "", "", "The name of the shell to invoke in the DevPod. If nothing is specified it will use 'bash'")
 	cmd.Flags().StringVarP(&options.DockerRegistry, "docker-registry", "", "", "The Docker registry to use within the DevPod. If not specified, default to the built-in registry or $DOCKER_REGISTRY")
 	cmd.Flags().StringVarP(&options.TillerNamespace, "tiller-namespace", "", "", "The optional tiller namespace to use within the DevPod.")
@@ -164,6 +175,18 @@ func (o *CreateDevPodOptions) Run() error {
 		return errors.New("Cannot specify --import-url && --sync")
 	}
 
+	if o.IDE != "" {
+		if util.StringArrayIndex(DevPodIDEs, o.IDE) < 0 {
+			return util.InvalidOption("ide", o.IDE, DevPodIDEs)
+		}
+		if o.Theia {
+			return errors.New("Cannot specify --ide and --theia")
+		}
+		if o.IdleTimeout == 0 {
+			o.IdleTimeout = DefaultDevPodIdleTimeout
+		}
+	}
+
 	client, curNs, err := o.KubeClientAndNamespace()
 	if err != nil {
 		return err
@@ -432,7 +455,9 @@ func (o *CreateDevPodOptions) Run() error {
 			o.BatchMode = batch
 
 			// web IDEs  won't work in --sync mode as we can't share a volume
-			if o.Theia {
+			if o.IDE != "" {
+				log.Logger().Debugf("not adding a web IDE container as %s attaches to the DevPod container", o.IDE)
+			} else if o.Theia {
 				image, err := resolver.ResolveDockerImage("theiaide/theia-full")
 				if err != nil {
 					return err
@@ -540,6 +565,12 @@ func (o *CreateDevPodOptions) Run() error {
 			Value: devPodGoPath,
 		})
 		pod.Annotations[kube.AnnotationWorkingDir] = workingDir
+		if o.IDE != "" {
+			pod.Annotations[kube.AnnotationDevPodIDE] = o.IDE
+		}
+		if o.IdleTimeout > 0 {
+			pod.Annotations[kube.AnnotationDevPodIdleTimeout] = o.IdleTimeout.String()
+		}
 		if importURL != "" {
 			gitURLs := pod.Annotations[kube.AnnotationGitURLs]
 			if gitURLs == "" {
@@ -732,7 +763,7 @@ func (o *CreateDevPodOptions) Run() error {
 			}
 			addedServices = true
 		}
-		if !o.Sync {
+		if !o.Sync && o.IDE == "" {
 
 			// Create a service for the IDE
 			ideService := corev1.Service{
@@ -778,7 +809,14 @@ func (o *CreateDevPodOptions) Run() error {
 	o.NotifyProgress(opts.LogInfo, "Pod %s is now ready!\n", util.ColorInfo(pod.Name))
 	log.Logger().Infof("You can open other shells into this DevPod via %s", util.ColorInfo("jx create devpod"))
 
-	if !o.Sync {
+	if !create {
+		err = kube.UpdateDevPodLastActivity(client, ns, name)
+		if err != nil {
+			log.Logger().Warnf("failed to record the activity of DevPod %s: %s", name, err.Error())
+		}
+	}
+
+	if !o.Sync && o.IDE == "" {
 		ideServiceURL, err := services.FindServiceURL(client, curNs, ideServiceName)
 		if err != nil {
 			return err
@@ -819,7 +857,20 @@ func (o *CreateDevPodOptions) Run() error {
 		o.Results.ExposePortURLs = exposePortURLs
 	}
 
-	if o.Sync {
+	if o.Sync && o.IDE != "" {
+		syncer := &devsync.Syncer{
+			Dir:       dir,
+			Namespace: ns,
+			Pod:       pod.Name,
+			Container: devPodContainerName,
+			RemoteDir: workingDir,
+		}
+		_, err = syncer.Sync()
+		if err != nil {
+			return err
+		}
+		log.Logger().Infof("To keep synchronising your changes into the DevPod run: %s", util.ColorInfo(fmt.Sprintf("jx sync --mode native --pod %s --remote-dir %s", pod.Name, workingDir)))
+	} else if o.Sync {
 		syncOptions := &sync.SyncOptions{
 			CommonOptions: o.CommonOptions,
 			Namespace:     ns,
@@ -845,7 +896,7 @@ func (o *CreateDevPodOptions) Run() error {
 		)
 
 		// Only add git secrets to the Theia container when sync flag is missing (otherwise Theia container won't exist)
-		if !o.Sync {
+		if !o.Sync && o.IDE == "" {
 			// Add Git Secrets to Theia container
 			gitAuthSvc, err := o.GitAuthConfigService()
 			if err != nil {
@@ -907,6 +958,13 @@ func (o *CreateDevPodOptions) Run() error {
 		}
 	}
 
+	if o.IDE != "" {
+		err = o.setupRemoteIDE(pod, ns, userName, workingDir, create)
+		if err != nil {
+			return err
+		}
+	}
+
 	// Only want to shell into the DevPod if the batch flag isn't set
 	if !o.BatchMode {
 		shellCommand := o.ShellCmd
@@ -0,0 +1,137 @@
+package create
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jenkins-x/jx/pkg/cmd/rsh"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// DevPodIDEVSCode connects VS Code to the DevPod via the Remote - Kubernetes extension
+	DevPodIDEVSCode = "vscode"
+	// DevPodIDEGoLand connects GoLand to the DevPod via JetBrains Gateway
+	DevPodIDEGoLand = "goland"
+	// DevPodIDEIntelliJ connects IntelliJ IDEA to the DevPod via JetBrains Gateway
+	DevPodIDEIntelliJ = "intellij"
+
+	// DefaultDevPodIdleTimeout the default duration after which a remote IDE DevPod is deleted if it is idle
+	DefaultDevPodIdleTimeout = 2 * time.Hour
+
+	jetBrainsDir     = "/opt/jetbrains"
+	jetBrainsLogFile = "/tmp/jetbrains-remote-dev.log"
+	jetBrainsPort    = 5990
+)
+
+// DevPodIDEs the remote IDEs which can connect to a DevPod
+var DevPodIDEs = []string{DevPodIDEVSCode, DevPodIDEGoLand, DevPodIDEIntelliJ}
+
+// jetBrainsProductCodes the product codes used to download the JetBrains IDE backends
+var jetBrainsProductCodes = map[string]string{
+	DevPodIDEGoLand:   "GO",
+	DevPodIDEIntelliJ: "IIU",
+}
+
+// isJetBrainsIDE returns true if the IDE connects via JetBrains Gateway
+func isJetBrainsIDE(ide string) bool {
+	return jetBrainsProductCodes[ide] != ""
+}
+
+// remoteIDESetupCommands returns the shell commands run in the DevPod to install and start the server of the remote IDE
+func remoteIDESetupCommands(ide string, workingDir string) ([]string, error) {
+	installPackages := func(packages ...string) []string {
+		pkgs := strings.Join(packages, " ")
+		return []string{
+			fmt.Sprintf("if which yum &> /dev/null; then yum install -q -y %s; fi", pkgs),
+			fmt.Sprintf("if which apt-get &> /dev/null; then apt-get update -qq && apt-get install -qq -y %s; fi", pkgs),
+			fmt.Sprintf("if which apk &> /dev/null; then apk add -q %s; fi", pkgs),
+		}
+	}
+	switch ide {
+	case DevPodIDEVSCode:
+		// the VS Code server is installed by the client when it attaches so we only need its prerequisites
+		return installPackages("tar", "gzip", "curl", "git", "procps"), nil
+	case DevPodIDEGoLand, DevPodIDEIntelliJ:
+		downloadURL := fmt.Sprintf("https://download.jetbrains.com/product?code=%s&latest&distribution=linux", jetBrainsProductCodes[ide])
+		commands := installPackages("tar", "gzip", "curl", "git")
+		return append(commands,
+			fmt.Sprintf("if ! [ -x %s/bin/remote-dev-server.sh ]; then mkdir -p %s && curl -fsSL \"%s\" | tar xz --strip-components=1 -C %s; fi", jetBrainsDir, jetBrainsDir, downloadURL, jetBrainsDir),
+			fmt.Sprintf("nohup %s/bin/remote-dev-server.sh run %s --listenOn 0.0.0.0 --port %d > %s 2>&1 &", jetBrainsDir, workingDir, jetBrainsPort, jetBrainsLogFile),
+		), nil
+	default:
+		return nil, util.InvalidOption("ide", ide, DevPodIDEs)
+	}
+}
+
+// vsCodeFolderURI returns the URI VS Code opens to attach to the container of the DevPod
+func vsCodeFolderURI(ns string, podName string, container string, image string, workingDir string) string {
+	return fmt.Sprintf("vscode-remote://k8s-container+namespace=%s+podname=%s+name=%s+image=%s%s", ns, podName, container, image, workingDir)
+}
+
+// jetBrainsGatewayLinkCommand returns the command run in the DevPod to find the JetBrains Gateway link of the backend
+func jetBrainsGatewayLinkCommand() string {
+	return fmt.Sprintf("grep -o 'jetbrains-gateway://[^ ]*' %s | tail -1", jetBrainsLogFile)
+}
+
+// setupRemoteIDE installs and starts the server of the remote IDE in a new DevPod then prints how to connect to it
+func (o *CreateDevPodOptions) setupRemoteIDE(pod *corev1.Pod, ns string, userName string, workingDir string, create bool) error {
+	if create {
+		commands, err := remoteIDESetupCommands(o.IDE, workingDir)
+		if err != nil {
+			return err
+		}
+		log.Logger().Infof("Installing the %s remote development prerequisites into the DevPod", util.ColorInfo(o.IDE))
+		options := &rsh.RshOptions{
+			CommonOptions: o.CommonOptions,
+			Namespace:     ns,
+			Pod:           pod.Name,
+			Container:     devPodContainerName,
+			DevPod:        true,
+			ExecCmd:       strings.Join(commands, " && "),
+			Username:      userName,
+		}
+		options.Args = []string{}
+		err = options.Run()
+		if err != nil {
+			return errors.Wrapf(err, "setting up %s in DevPod %s", o.IDE, pod.Name)
+		}
+	}
+
+	info := util.ColorInfo
+	if isJetBrainsIDE(o.IDE) {
+		link := ""
+		err := util.Retry(3*time.Minute, func() error {
+			out, err := o.GetCommandOutput("", "kubectl", "exec", "-n", ns, pod.Name, "-c", devPodContainerName, "--", "/bin/sh", "-c", jetBrainsGatewayLinkCommand())
+			if err != nil {
+				return err
+			}
+			link = strings.TrimSpace(out)
+			if link == "" {
+				return fmt.Errorf("the %s backend has not started yet", o.IDE)
+			}
+			return nil
+		})
+		log.Logger().Infof("\nTo connect %s to the DevPod forward its port by running:\n\n\t%s\n", o.IDE, info(fmt.Sprintf("kubectl port-forward -n %s %s %d:%d", ns, pod.Name, jetBrainsPort, jetBrainsPort)))
+		if err != nil || link == "" {
+			log.Logger().Warnf("Could not find the JetBrains Gateway link yet. You can find it later in %s in the DevPod", jetBrainsLogFile)
+		} else {
+			log.Logger().Infof("then open this link in JetBrains Gateway:\n\n\t%s\n", info(link))
+		}
+		return nil
+	}
+
+	image := ""
+	for _, c := range pod.Spec.Containers {
+		if c.Name == devPodContainerName {
+			image = c.Image
+		}
+	}
+	uri := vsCodeFolderURI(ns, pod.Name, devPodContainerName, image, workingDir)
+	log.Logger().Infof("\nTo connect VS Code to the DevPod install the Remote - Kubernetes extension then run:\n\n\t%s\n", info(fmt.Sprintf("code --folder-uri \"%s\"", uri)))
+	return nil
+}
@@ -0,0 +1,40 @@
+package create
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRemoteIDESetupCommands(t *testing.T) {
+	t.Parallel()
+
+	commands, err := remoteIDESetupCommands(DevPodIDEVSCode, "/workspace")
+	require.NoError(t, err)
+	assert.NotEmpty(t, commands)
+	for _, c := range commands {
+		assert.NotContains(t, c, "remote-dev-server")
+	}
+
+	commands, err = remoteIDESetupCommands(DevPodIDEGoLand, "/workspace/myapp")
+	require.NoError(t, err)
+	script := strings.Join(commands, "\n")
+	assert.Contains(t, script, "code=GO&")
+	assert.Contains(t, script, "remote-dev-server.sh run /workspace/myapp --listenOn 0.0.0.0 --port 5990")
+
+	commands, err = remoteIDESetupCommands(DevPodIDEIntelliJ, "/workspace")
+	require.NoError(t, err)
+	assert.Contains(t, strings.Join(commands, "\n"), "code=IIU&")
+
+	_, err = remoteIDESetupCommands("notepad", "/workspace")
+	assert.Error(t, err)
+}
+
+func TestVSCodeFolderURI(t *testing.T) {
+	t.Parallel()
+
+	uri := vsCodeFolderURI("jx", "myuser-go", "devpod", "gcr.io/jenkinsxio/builder-go:0.1.1", "/workspace")
+	assert.Equal(t, "vscode-remote://k8s-container+namespace=jx+podname=myuser-go+name=devpod+image=gcr.io/jenkinsxio/builder-go:0.1.1/workspace", uri)
+}
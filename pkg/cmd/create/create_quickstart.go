@@ -7,6 +7,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/jenkins-x/jx/pkg/cmd/importcmd"
 
@@ -17,6 +18,7 @@ import (
 	"github.com/jenkins-x/jx/pkg/github"
 	"github.com/jenkins-x/jx/pkg/gits"
 	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 
 	"github.com/jenkins-x/jx/pkg/cmd/opts"
@@ -49,6 +51,12 @@ var (
 		jx create quickstart
 
 		jx create quickstart -f http
+
+		# Create a project from a parameterized quickstart specifying its parameters
+		jx create quickstart -f spring-postgres --param groupId=com.acme --param port=8081
+
+		# Create a project using only the quickstarts cached by 'jx update catalog'
+		jx create quickstart --offline
 	`)
 )
 
@@ -61,6 +69,10 @@ type CreateQuickstartOptions struct {
 	GitProvider         gits.GitProvider
 	GitHost             string
 	IgnoreTeam          bool
+	TemplateValues      []string
+	Offline             bool
+
+	cache *quickstarts.Cache
 }
 
 // NewCmdCreateQuickstart creates a command object for the "create" command
@@ -95,16 +107,19 @@ func NewCmdCreateQuickstart(commonOpts *opts.CommonOptions) *cobra.Command {
 	cmd.Flags().StringVarP(&options.Filter.Framework, "framework", "", "", "The framework to filter on")
 	cmd.Flags().StringVarP(&options.GitHost, "git-host", "", "", "The Git server host if not using GitHub when pushing created project")
 	cmd.Flags().StringVarP(&options.Filter.Text, "filter", "f", "", "The text filter")
+	cmd.Flags().StringVarP(&options.Filter.Catalog, "catalog", "", "", "The quickstart catalog to filter on")
 	cmd.Flags().StringVarP(&options.Filter.ProjectName, "project-name", "p", "", "The project name (for use with -b batch mode)")
 	cmd.Flags().BoolVarP(&options.Filter.AllowML, "machine-learning", "", false, "Allow machine-learning quickstarts in results")
+	cmd.Flags().StringArrayVarP(&options.TemplateValues, "param", "", []string{}, "The values of the parameters defined in the quickstart.yaml file of the quickstart in the form name=value")
+	cmd.Flags().BoolVarP(&options.Offline, "offline", "", false, "Only use the quickstarts cached by 'jx update catalog' rather than querying the quickstart locations")
 	return cmd
 }
 
 // Run implements the generic Create command
 func (o *CreateQuickstartOptions) Run() error {
-	model, err := o.LoadQuickStartsModel(o.GitHubOrganisations, o.IgnoreTeam)
+	model, err := o.loadQuickstartsModel()
 	if err != nil {
-		return fmt.Errorf("failed to load quickstarts: %s", err)
+		return err
 	}
 
 	q, err := model.CreateSurvey(&o.Filter, o.BatchMode, o.GetIOFileHandles())
@@ -160,7 +175,7 @@ func (o *CreateQuickstartOptions) Run() error {
 	}
 
 	// Prevent accidental attempts to use ML Project Sets in create quickstart
-	if isMLProjectSet(q.Quickstart) {
+	if !o.Offline && isMLProjectSet(q.Quickstart) {
 		return fmt.Errorf("you have tried to select a machine-learning quickstart projectset please try again using jx create mlquickstart instead")
 	}
 	dir := o.OutDir
@@ -174,6 +189,10 @@ func (o *CreateQuickstartOptions) Run() error {
 	if err != nil {
 		return err
 	}
+	err = o.applyQuickstartTemplates(q, genDir)
+	if err != nil {
+		return err
+	}
 
 	// if there is a charts folder named after the app name, lets rename it to the generated app name
 	folder := ""
@@ -211,6 +230,9 @@ func (o *CreateQuickstartOptions) Run() error {
 		}
 	}
 	log.Logger().Infof("Created project at %s\n", util.ColorInfo(genDir))
+	if q.Quickstart != nil && len(q.Quickstart.RequiredSecrets) > 0 {
+		log.Logger().Warnf("The quickstart %s requires the secrets: %s", q.Quickstart.ID, util.ColorInfo(strings.Join(q.Quickstart.RequiredSecrets, ", ")))
+	}
 
 	o.CreateProjectOptions.ImportOptions.GitProvider = o.GitProvider
 
@@ -224,31 +246,7 @@ func (o *CreateQuickstartOptions) Run() error {
 func (o *CreateQuickstartOptions) createQuickstart(f *quickstarts.QuickstartForm, dir string) (string, error) {
 	q := f.Quickstart
 	answer := filepath.Join(dir, f.Name)
-	u := q.DownloadZipURL
-	if u == "" {
-		return answer, fmt.Errorf("quickstart %s does not have a download zip URL", q.ID)
-	}
-	client := http.Client{}
-
-	req, err := http.NewRequest(http.MethodGet, u, strings.NewReader(""))
-	if err != nil {
-		return answer, err
-	}
-	gitProvider := q.GitProvider
-	if gitProvider != nil {
-		userAuth := gitProvider.UserAuth()
-		token := userAuth.ApiToken
-		username := userAuth.Username
-		if token != "" && username != "" {
-			log.Logger().Debugf("Downloading Quickstart source zip from %s with basic auth for user: %s", u, username)
-			req.SetBasicAuth(username, token)
-		}
-	}
-	res, err := client.Do(req)
-	if err != nil {
-		return answer, err
-	}
-	body, err := ioutil.ReadAll(res.Body)
+	body, err := o.quickstartZip(q)
 	if err != nil {
 		return answer, err
 	}
@@ -282,6 +280,105 @@ func (o *CreateQuickstartOptions) createQuickstart(f *quickstarts.QuickstartForm
 	return answer, nil
 }
 
+// loadQuickstartsModel loads the quickstarts from the quickstart locations falling back to the offline cache populated
+// by 'jx update catalog' if they cannot be loaded or if running offline
+func (o *CreateQuickstartOptions) loadQuickstartsModel() (*quickstarts.QuickstartModel, error) {
+	cache, err := quickstarts.DefaultCache()
+	if err != nil {
+		return nil, err
+	}
+	o.cache = cache
+
+	var loadErr error
+	if !o.Offline {
+		model, err := o.LoadQuickStartsModel(o.GitHubOrganisations, o.IgnoreTeam)
+		if err == nil && len(model.Quickstarts) > 0 {
+			return model, nil
+		}
+		loadErr = err
+		if loadErr == nil {
+			loadErr = fmt.Errorf("no quickstarts found")
+		}
+	}
+	model, catalog, err := cache.LoadModel()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load the cached quickstarts")
+	}
+	if model == nil {
+		if o.Offline {
+			return nil, fmt.Errorf("no quickstarts are cached in %s. Run 'jx update catalog' to populate the cache", cache.Dir)
+		}
+		return nil, fmt.Errorf("failed to load quickstarts: %s", loadErr)
+	}
+	if loadErr != nil {
+		log.Logger().Warnf("Failed to load quickstarts: %s", loadErr)
+	}
+	log.Logger().Infof("Using the quickstart catalog cached on %s", util.ColorInfo(catalog.Updated.Format(time.RFC1123)))
+	return model, nil
+}
+
+// quickstartZip returns the source zip of the quickstart downloading it unless running offline in which case, or if
+// the download fails, the zip cached by 'jx update catalog' is used
+func (o *CreateQuickstartOptions) quickstartZip(q *quickstarts.Quickstart) ([]byte, error) {
+	var downloadErr error
+	if !o.Offline {
+		body, err := quickstarts.DownloadZip(q)
+		if err == nil {
+			return body, nil
+		}
+		downloadErr = err
+	}
+	if o.cache != nil {
+		fileName, exists, err := o.cache.CachedZip(q)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			if downloadErr != nil {
+				log.Logger().Warnf("Failed to download quickstart %s so using the cached source: %s", q.ID, downloadErr)
+			}
+			return ioutil.ReadFile(fileName)
+		}
+	}
+	if downloadErr != nil {
+		return nil, errors.Wrapf(downloadErr, "downloading quickstart %s", q.ID)
+	}
+	return nil, fmt.Errorf("the source of quickstart %s is not cached. Run 'jx update catalog' to populate the cache", q.ID)
+}
+
+// applyQuickstartTemplates renders the files of the generated project if the quickstart has a quickstart.yaml file
+func (o *CreateQuickstartOptions) applyQuickstartTemplates(f *quickstarts.QuickstartForm, genDir string) error {
+	config, err := quickstarts.LoadTemplateConfig(genDir)
+	if err != nil {
+		return err
+	}
+	if config == nil {
+		return nil
+	}
+	values := map[string]string{}
+	for _, v := range o.TemplateValues {
+		parts := strings.SplitN(v, "=", 2)
+		if len(parts) != 2 {
+			return util.InvalidOptionf("param", v, "expected the form name=value")
+		}
+		values[parts[0]] = parts[1]
+	}
+	values, err = config.ResolveValues(values, o.BatchMode, o.GetIOFileHandles())
+	if err != nil {
+		return errors.Wrapf(err, "resolving the parameters of quickstart %s", f.Quickstart.ID)
+	}
+	_, name := filepath.Split(genDir)
+	data := &quickstarts.TemplateData{
+		Name:   name,
+		Values: values,
+	}
+	err = config.ApplyTemplates(genDir, data)
+	if err != nil {
+		return errors.Wrapf(err, "applying the templates of quickstart %s", f.Quickstart.ID)
+	}
+	return nil
+}
+
 func findFirstDirectory(dir string) (string, error) {
 	files, err := ioutil.ReadDir(dir)
 	if err != nil {
@@ -309,12 +406,14 @@ func isMLProjectSet(q *quickstarts.Quickstart) bool {
 	if err != nil {
 		log.Logger().Warnf("Problem creating request %s: %s ", u, err)
 	}
-	userAuth := q.GitProvider.UserAuth()
-	token := userAuth.ApiToken
-	username := userAuth.Username
-	if token != "" && username != "" {
-		log.Logger().Debugf("Trying to pull projectset file from %s with basic auth for user: %s", u, username)
-		req.SetBasicAuth(username, token)
+	if q.GitProvider != nil {
+		userAuth := q.GitProvider.UserAuth()
+		token := userAuth.ApiToken
+		username := userAuth.Username
+		if token != "" && username != "" {
+			log.Logger().Debugf("Trying to pull projectset file from %s with basic auth for user: %s", u, username)
+			req.SetBasicAuth(username, token)
+		}
 	}
 	res, err := client.Do(req)
 	if err != nil {
@@ -38,6 +38,9 @@ var (
 		# Create a quickstart location for your Git repo and organisation 
 		jx create quickstartlocation --url https://mygit.server.com --owner my-quickstarts
 
+		# Create a quickstart location using the quickstarts.yml catalog in a private repository of an organisation
+		jx create quickstartlocation --owner my-org --catalog quickstart-catalog
+
 	`)
 )
 
@@ -50,6 +53,7 @@ type CreateQuickstartLocationOptions struct {
 	Owner    string
 	Includes []string
 	Excludes []string
+	Catalog  string
 }
 
 // NewCmdCreateQuickstartLocation creates a command object for the "create" command
@@ -79,6 +83,7 @@ func NewCmdCreateQuickstartLocation(commonOpts *opts.CommonOptions) *cobra.Comma
 	cmd.Flags().StringVarP(&options.Owner, optionOwner, "o", "", "The owner is the user or organisation of the Git provider used to find repositories")
 	cmd.Flags().StringArrayVarP(&options.Includes, "includes", "i", []string{"*"}, "The patterns to include repositories")
 	cmd.Flags().StringArrayVarP(&options.Excludes, "excludes", "x", []string{"WIP-*"}, "The patterns to exclude repositories")
+	cmd.Flags().StringVarP(&options.Catalog, "catalog", "", "", "The repository of the owner containing a quickstarts.yml catalog of versioned quickstarts to use rather than the repositories of the owner")
 
 	return cmd
 }
@@ -117,7 +122,7 @@ func (o *CreateQuickstartLocationOptions) Run() error {
 
 	var location *v1.QuickStartLocation
 	for i, l := range locations {
-		if l.GitURL == o.GitUrl && l.Owner == o.Owner {
+		if l.GitURL == o.GitUrl && l.Owner == o.Owner && l.Catalog == o.Catalog {
 			location = &locations[i]
 		}
 	}
@@ -126,6 +131,7 @@ func (o *CreateQuickstartLocationOptions) Run() error {
 			GitURL:  o.GitUrl,
 			GitKind: o.GitKind,
 			Owner:   o.Owner,
+			Catalog: o.Catalog,
 		})
 	}
 	location = &locations[len(locations)-1]
@@ -0,0 +1,227 @@
+package create
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/cmd/create/options"
+	"github.com/jenkins-x/jx/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/pkg/cmd/update"
+	"github.com/jenkins-x/jx/pkg/config"
+	"github.com/jenkins-x/jx/pkg/kube/services"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/tunnel"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
+)
+
+var (
+	createTunnelLong = templates.LongDesc(`
+		Creates a tunnel which forwards the webhooks of the git provider to a cluster without a public ingress such as
+		a kind or k3d cluster on your laptop.
+
+		The tunnel uses either ngrok or a smee compatible relay service. Whenever the tunnel is started it gets a public
+		URL which is stored in the requirements of the team and the webhooks of all the source repositories are updated
+		to use it. If the tunnel exits it is restarted and the webhooks are updated again.
+
+		The command runs until it is interrupted.
+`)
+
+	createTunnelExample = templates.Examples(`
+		# tunnel webhooks to the hook service of the dev environment via ngrok
+		jx create tunnel
+
+		# relay webhooks via a new smee.io channel
+		jx create tunnel --kind smee
+
+		# relay webhooks via an existing channel
+		jx create tunnel --kind smee --url https://smee.io/abc123
+	`)
+)
+
+// CreateTunnelOptions the options for the create tunnel command
+type CreateTunnelOptions struct {
+	options.CreateOptions
+
+	Kind         string
+	Target       string
+	URL          string
+	NgrokAPI     string
+	Timeout      time.Duration
+	RestartDelay time.Duration
+	NoWebhooks   bool
+}
+
+// NewCmdCreateTunnel creates a command object for the "create tunnel" command
+func NewCmdCreateTunnel(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &CreateTunnelOptions{
+		CreateOptions: options.CreateOptions{
+			CommonOptions: commonOpts,
+		},
+	}
+
+	cmd := &cobra.Command{
+		Use:     "tunnel",
+		Short:   "Creates a tunnel which forwards webhooks to a cluster without a public ingress",
+		Aliases: []string{"tunnels"},
+		Long:    createTunnelLong,
+		Example: createTunnelExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+
+	cmd.Flags().StringVarP(&options.Kind, "kind", "k", "", "The kind of tunnel to create. Defaults to the tunnel in the requirements of the team or ngrok. One of: "+util.ColorInfo(tunnel.Kinds))
+	cmd.Flags().StringVarP(&options.Target, "target", "t", "", "The URL the tunnel forwards to. Defaults to the URL of the hook service in the dev environment")
+	cmd.Flags().StringVarP(&options.URL, "url", "u", "", "The URL of the channel of the relay service. A new channel is created if not specified")
+	cmd.Flags().StringVarP(&options.NgrokAPI, "ngrok-api", "", tunnel.DefaultNgrokAPI, "The URL of the local API of the ngrok agent")
+	cmd.Flags().DurationVarP(&options.Timeout, "timeout", "", 30*time.Second, "How long to wait for the tunnel to start")
+	cmd.Flags().DurationVarP(&options.RestartDelay, "restart-delay", "", 5*time.Second, "How long to wait before restarting the tunnel if it exits")
+	cmd.Flags().BoolVarP(&options.NoWebhooks, "no-webhooks", "", false, "Disables updating the webhooks of the source repositories to use the tunnel")
+	return cmd
+}
+
+// Run implements the command
+func (o *CreateTunnelOptions) Run() error {
+	requirements, err := o.teamRequirements()
+	if err != nil {
+		return err
+	}
+	if o.Kind == "" && requirements != nil && requirements.Cluster.LocalConfig != nil {
+		if util.StringArrayIndex(tunnel.Kinds, requirements.Cluster.LocalConfig.Tunnel) >= 0 {
+			o.Kind = requirements.Cluster.LocalConfig.Tunnel
+		}
+	}
+	if o.Kind == "" {
+		o.Kind = tunnel.KindNgrok
+	}
+	if o.Target == "" {
+		client, ns, err := o.KubeClientAndDevNamespace()
+		if err != nil {
+			return errors.Wrap(err, "failed to get kube client")
+		}
+		o.Target, err = services.FindServiceURL(client, ns, "hook")
+		if err != nil {
+			return errors.Wrapf(err, "finding the URL of the hook service in namespace %s", ns)
+		}
+		if o.Target == "" {
+			return util.MissingOption("target")
+		}
+	}
+	t, err := tunnel.NewTunnel(o.Kind, o.Target, o.URL)
+	if err != nil {
+		return err
+	}
+	if ngrok, ok := t.(*tunnel.NgrokTunnel); ok {
+		ngrok.API = o.NgrokAPI
+		ngrok.Timeout = o.Timeout
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	for {
+		publicURL, err := t.Start()
+		if err != nil {
+			return errors.Wrapf(err, "starting the %s tunnel to %s", o.Kind, o.Target)
+		}
+		log.Logger().Infof("Tunnelling %s to %s", util.ColorInfo(publicURL), util.ColorInfo(o.Target))
+
+		err = o.updateTunnelURL(publicURL)
+		if err != nil {
+			t.Stop()
+			return err
+		}
+
+		exited := make(chan error, 1)
+		go func() {
+			exited <- t.Wait()
+		}()
+		select {
+		case <-signals:
+			log.Logger().Infof("Stopping the %s tunnel", o.Kind)
+			return t.Stop()
+		case err := <-exited:
+			if err != nil {
+				log.Logger().Warnf("The %s tunnel exited: %s", o.Kind, err.Error())
+			}
+			log.Logger().Infof("Restarting the %s tunnel in %s", o.Kind, o.RestartDelay.String())
+			time.Sleep(o.RestartDelay)
+		}
+	}
+}
+
+// updateTunnelURL stores the public URL of the tunnel in the requirements of the team and updates the webhooks of the
+// source repositories to use it if it has changed
+func (o *CreateTunnelOptions) updateTunnelURL(publicURL string) error {
+	if o.NoWebhooks {
+		return nil
+	}
+	previousEndpoint, err := o.GetWebHookEndpoint()
+	if err != nil {
+		return errors.Wrap(err, "finding the current webhook endpoint")
+	}
+
+	requirements, err := o.teamRequirements()
+	if err != nil {
+		return err
+	}
+	if requirements == nil {
+		log.Logger().Warnf("No requirements found in the team settings so the tunnel URL %s is not stored", publicURL)
+	} else {
+		if requirements.Cluster.LocalConfig == nil {
+			requirements.Cluster.LocalConfig = &config.LocalClusterConfig{}
+		}
+		requirements.Cluster.LocalConfig.Tunnel = o.Kind
+		requirements.Cluster.LocalConfig.TunnelURL = publicURL
+		err = o.ModifyDevEnvironment(func(env *v1.Environment) error {
+			data, err := yaml.Marshal(requirements)
+			if err != nil {
+				return errors.Wrap(err, "marshalling the requirements")
+			}
+			env.Spec.TeamSettings.BootRequirements = string(data)
+			return nil
+		})
+		if err != nil {
+			return errors.Wrap(err, "storing the tunnel URL in the team settings")
+		}
+	}
+
+	endpoint := util.UrlJoin(publicURL, "hook")
+	if endpoint == previousEndpoint {
+		log.Logger().Infof("The webhooks already use %s", util.ColorInfo(endpoint))
+		return nil
+	}
+	log.Logger().Infof("Updating the webhooks from %s to %s", util.ColorInfo(previousEndpoint), util.ColorInfo(endpoint))
+	updateWebhooks := &update.UpdateWebhooksOptions{
+		CommonOptions:   o.CommonOptions,
+		ExactHookMatch:  true,
+		PreviousHookUrl: previousEndpoint,
+		Endpoint:        endpoint,
+		WarnOnFail:      true,
+	}
+	return updateWebhooks.Run()
+}
+
+func (o *CreateTunnelOptions) teamRequirements() (*config.RequirementsConfig, error) {
+	teamSettings, err := o.TeamSettings()
+	if err != nil {
+		return nil, errors.Wrap(err, "loading the team settings")
+	}
+	requirements, err := config.GetRequirementsConfigFromTeamSettings(teamSettings)
+	if err != nil {
+		return nil, errors.Wrap(err, "getting the requirements from the team settings")
+	}
+	return requirements, nil
+}
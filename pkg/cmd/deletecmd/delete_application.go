@@ -6,8 +6,6 @@ import (
 	"strings"
 	"time"
 
-	"k8s.io/apimachinery/pkg/fields"
-
 	"github.com/jenkins-x/jx/pkg/cmd/helper"
 	"github.com/jenkins-x/jx/pkg/kube/naming"
 
@@ -44,7 +42,12 @@ var (
 	deleteApplicationLong = templates.LongDesc(`
 		Deletes one or more Applications
 
-		Note that this command does not remove the underlying Git Repositories. 
+		A Pull Request removing the application is raised in each environment it is deployed to. Use --complete to also
+		delete its preview environments, Release resources and webhooks and --archive to archive its git repository.
+
+		Use --dry-run to report everything which would be removed without removing it.
+
+		Note that this command does not delete the underlying Git Repositories. 
 
 		For that see the [jx delete repo](https://jenkins-x.io/commands/jx_delete_repo/) command.
 
@@ -56,6 +59,9 @@ var (
 
 		# delete a specific app 
 		jx delete application cheese
+
+		# report everything which a complete teardown of an app would remove
+		jx delete application myorg/cheese --complete --archive --dry-run
 	`)
 )
 
@@ -71,6 +77,12 @@ type DeleteApplicationOptions struct {
 	PullRequestPollTime string
 	Org                 string
 	AutoMerge           bool
+	Complete            bool
+	DeletePreviews      bool
+	DeleteReleases      bool
+	DeleteWebHooks      bool
+	ArchiveRepository   bool
+	DryRun              bool
 
 	// calculated fields
 	TimeoutDuration         *time.Duration
@@ -104,6 +116,12 @@ func NewCmdDeleteApplication(commonOpts *opts.CommonOptions) *cobra.Command {
 	cmd.Flags().StringVarP(&options.PullRequestPollTime, optionPullRequestPollTime, "", "20s", "Poll time when waiting for a Pull Request to merge")
 	cmd.Flags().StringVarP(&options.Org, "org", "o", "", "github organisation/project name that source code resides in")
 	cmd.Flags().BoolVarP(&options.AutoMerge, "auto-merge", "", false, "Automatically merge GitOps pull requests that pass CI")
+	cmd.Flags().BoolVarP(&options.Complete, "complete", "", false, "Completely tears down the application deleting its previews, releases and webhooks too")
+	cmd.Flags().BoolVarP(&options.DeletePreviews, "previews", "", false, "Deletes the preview environments of the application")
+	cmd.Flags().BoolVarP(&options.DeleteReleases, "releases", "", false, "Deletes the Release resources of the application")
+	cmd.Flags().BoolVarP(&options.DeleteWebHooks, "webhooks", "", false, "Removes the webhooks of the application git repository which point at this cluster")
+	cmd.Flags().BoolVarP(&options.ArchiveRepository, "archive", "", false, "Archives the git repository of the application if the git provider supports it")
+	cmd.Flags().BoolVarP(&options.DryRun, "dry-run", "", false, "Reports everything which would be removed without removing it")
 	return cmd
 }
 
@@ -135,6 +153,10 @@ func (o *DeleteApplicationOptions) Run() error {
 	if err != nil {
 		return errors.Wrapf(err, "deleting application")
 	}
+	if o.DryRun {
+		log.Logger().Infof("Dry run so nothing was deleted for Application(s): %s", util.ColorInfo(strings.Join(deletedApplications, ",")))
+		return nil
+	}
 	log.Logger().Infof("Deleted Application(s): %s", util.ColorInfo(strings.Join(deletedApplications, ",")))
 	return nil
 }
@@ -255,6 +277,15 @@ func (o *DeleteApplicationOptions) deleteProwApplication(repoService jenkinsv1.S
 
 		deletedApplications = append(deletedApplications, applicationName)
 
+		teardown, err := o.planApplicationTeardown(jxClient, kubeClient, ns, envMap, org, applicationName)
+		if err != nil {
+			return deletedApplications, errors.Wrapf(err, "finding the resources of application %s", repo)
+		}
+		if o.DryRun {
+			o.reportApplicationTeardown(teardown)
+			continue
+		}
+
 		srName := naming.ToValidName(org + "-" + applicationName)
 		err = repoService.Delete(srName, nil)
 		if err != nil {
@@ -278,14 +309,22 @@ func (o *DeleteApplicationOptions) deleteProwApplication(repoService jenkinsv1.S
 			log.Logger().Warnf("failed to remove PipelineActivities in namespace %s: %s", ns, err.Error())
 		}
 
-		for _, env := range envMap {
-			if env.Spec.Kind == v1.EnvironmentKindTypePermanent {
-				err = o.deleteApplicationFromEnvironment(env, applicationName, username)
-				if err != nil {
-					return deletedApplications, errors.Wrapf(err, "deleting application %s from environment %s", applicationName, env.Name)
-				}
+		err = o.deleteApplicationResources(jxClient, ns, teardown)
+		if err != nil {
+			return deletedApplications, errors.Wrapf(err, "deleting the resources of application %s", repo)
+		}
+
+		for _, env := range teardown.Environments {
+			err = o.deleteApplicationFromEnvironment(env, applicationName, username)
+			if err != nil {
+				return deletedApplications, errors.Wrapf(err, "deleting application %s from environment %s", applicationName, env.Name)
 			}
 		}
+
+		err = o.archiveApplicationRepository(teardown)
+		if err != nil {
+			return deletedApplications, errors.Wrapf(err, "archiving the git repository of application %s", repo)
+		}
 	}
 	return
 }
@@ -335,6 +374,13 @@ func (o *DeleteApplicationOptions) deleteJenkinsApplication() (deletedApplicatio
 	}
 	deleteMessage := strings.Join(args, ", ")
 
+	if o.DryRun {
+		for _, name := range args {
+			log.Logger().Infof("Deleting application %s would remove its Jenkins job and raise a Pull Request in each permanent environment", util.ColorInfo(name))
+		}
+		return args, nil
+	}
+
 	if !o.BatchMode {
 		if !util.Confirm("You are about to delete these Applications from Jenkins: "+deleteMessage, false, "The list of Applications names to be deleted from Jenkins", o.GetIOFileHandles()) {
 			return deletedApplications, err
@@ -504,6 +550,12 @@ func (o *DeleteApplicationOptions) init() error {
 		return errors.Wrap(err, "getting jx client")
 	}
 
+	if o.Complete {
+		o.DeletePreviews = true
+		o.DeleteReleases = true
+		o.DeleteWebHooks = true
+	}
+
 	if o.PullRequestPollTime != "" {
 		duration, err := time.ParseDuration(o.PullRequestPollTime)
 		if err != nil {
@@ -522,16 +574,12 @@ func (o *DeleteApplicationOptions) init() error {
 }
 
 func (o *DeleteApplicationOptions) deletePipelineActivitiesForSourceRepository(jxClient versioned.Interface, ns string, gitOwner string, gitRepo string) error {
-	gitOwnerSelector := fields.OneTermEqualSelector("spec.gitOwner", gitOwner)
-	gitRepoSelector := fields.OneTermEqualSelector("spec.gitRepository", gitRepo)
-	fieldSelector := fields.AndSelectors(gitOwnerSelector, gitRepoSelector)
-
-	pipelineInterface := jxClient.JenkinsV1().PipelineActivities(ns)
-	paList, err := kube.ListSelectedPipelineActivities(pipelineInterface, nil, fieldSelector)
+	activities, err := listPipelineActivitiesForSourceRepository(jxClient, ns, gitOwner, gitRepo)
 	if err != nil {
-		return errors.Wrapf(err, "failed to list PipelineActivity resource in namespace %s with selector %s", ns, fieldSelector.String())
+		return err
 	}
-	for _, pa := range paList.Items {
+	pipelineInterface := jxClient.JenkinsV1().PipelineActivities(ns)
+	for _, pa := range activities {
 		err := pipelineInterface.Delete(pa.Name, &metav1.DeleteOptions{})
 		if err != nil {
 			return err
@@ -0,0 +1,290 @@
+package deletecmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/client/clientset/versioned"
+	"github.com/jenkins-x/jx/pkg/gits"
+	"github.com/jenkins-x/jx/pkg/kube"
+	"github.com/jenkins-x/jx/pkg/kube/naming"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/kubernetes"
+)
+
+// applicationTeardown the resources of an application which are removed when the application is deleted
+type applicationTeardown struct {
+	Owner              string
+	Name               string
+	GitURL             string
+	SourceRepository   string
+	PipelineActivities []string
+	Environments       []*v1.Environment
+	Previews           []*v1.Environment
+	Releases           []*v1.Release
+	WebHooks           []*gits.GitWebHookArguments
+	Archive            bool
+
+	gitProvider gits.GitProvider
+}
+
+// planApplicationTeardown finds the resources of the application which should be removed
+func (o *DeleteApplicationOptions) planApplicationTeardown(jxClient versioned.Interface, kubeClient kubernetes.Interface, ns string,
+	envMap map[string]*v1.Environment, owner string, name string) (*applicationTeardown, error) {
+	teardown := &applicationTeardown{
+		Owner: owner,
+		Name:  name,
+	}
+	srName := naming.ToValidName(owner + "-" + name)
+	sr, err := jxClient.JenkinsV1().SourceRepositories(ns).Get(srName, metav1.GetOptions{})
+	if err == nil {
+		teardown.SourceRepository = sr.Name
+		teardown.GitURL, err = kube.GetRepositoryGitURL(sr)
+		if err != nil {
+			log.Logger().Warnf("Failed to find the git URL of SourceRepository %s: %s", srName, err)
+		}
+	}
+
+	activities, err := listPipelineActivitiesForSourceRepository(jxClient, ns, owner, name)
+	if err != nil {
+		log.Logger().Warnf("Failed to list the PipelineActivities of %s/%s: %s", owner, name, err)
+	}
+	for _, pa := range activities {
+		teardown.PipelineActivities = append(teardown.PipelineActivities, pa.Name)
+	}
+
+	namespaces := []string{ns}
+	for _, env := range sortedEnvironments(envMap) {
+		switch env.Spec.Kind {
+		case v1.EnvironmentKindTypePermanent:
+			if env.Spec.Namespace != "" && util.StringArrayIndex(namespaces, env.Spec.Namespace) < 0 {
+				namespaces = append(namespaces, env.Spec.Namespace)
+			}
+			if o.IgnoreEnvironments || env.Spec.Source.URL == "" {
+				continue
+			}
+			if isDeployedToEnvironment(jxClient, kubeClient, env, owner, name) {
+				teardown.Environments = append(teardown.Environments, env)
+			}
+		case v1.EnvironmentKindTypePreview:
+			if o.DeletePreviews && isPreviewOfApplication(env, owner, name) {
+				teardown.Previews = append(teardown.Previews, env)
+			}
+		}
+	}
+
+	if o.DeleteReleases {
+		for _, releaseNs := range namespaces {
+			releases, err := jxClient.JenkinsV1().Releases(releaseNs).List(metav1.ListOptions{})
+			if err != nil {
+				return teardown, errors.Wrapf(err, "listing the Releases in namespace %s", releaseNs)
+			}
+			for i := range releases.Items {
+				release := &releases.Items[i]
+				if isReleaseOfApplication(release, owner, name) {
+					teardown.Releases = append(teardown.Releases, release)
+				}
+			}
+		}
+	}
+
+	if teardown.GitURL != "" && (o.DeleteWebHooks || o.ArchiveRepository) {
+		teardown.gitProvider, _, err = o.CreateGitProviderForURLWithoutKind(teardown.GitURL)
+		if err != nil {
+			return teardown, errors.Wrapf(err, "creating git provider for %s", teardown.GitURL)
+		}
+		teardown.Archive = o.ArchiveRepository
+	}
+	if o.DeleteWebHooks && teardown.gitProvider != nil {
+		teardown.WebHooks, err = o.findApplicationWebHooks(teardown.gitProvider, teardown.GitURL, owner, name)
+		if err != nil {
+			return teardown, err
+		}
+	}
+	return teardown, nil
+}
+
+// findApplicationWebHooks returns the webhooks of the repository which point at this cluster
+func (o *DeleteApplicationOptions) findApplicationWebHooks(gitProvider gits.GitProvider, gitURL string, owner string, name string) ([]*gits.GitWebHookArguments, error) {
+	webhook, err := o.ProwWebhookArguments(gitURL)
+	if err != nil {
+		log.Logger().Warnf("Failed to find the webhook URL of the cluster so not removing the webhooks of %s: %s", gitURL, err)
+		return nil, nil
+	}
+	hooks, err := gitProvider.ListWebHooks(owner, name)
+	if err != nil {
+		return nil, errors.Wrapf(err, "listing the webhooks of %s", gitURL)
+	}
+	answer := []*gits.GitWebHookArguments{}
+	for _, hook := range hooks {
+		if hook != nil && strings.TrimSuffix(hook.URL, "/") == strings.TrimSuffix(webhook.URL, "/") {
+			answer = append(answer, hook)
+		}
+	}
+	return answer, nil
+}
+
+// describe returns a line for each of the resources which are removed
+func (t *applicationTeardown) describe() []string {
+	answer := []string{}
+	if t.SourceRepository != "" {
+		answer = append(answer, fmt.Sprintf("SourceRepository %s", t.SourceRepository))
+	}
+	if len(t.PipelineActivities) > 0 {
+		answer = append(answer, fmt.Sprintf("%d PipelineActivities", len(t.PipelineActivities)))
+	}
+	for _, env := range t.Environments {
+		answer = append(answer, fmt.Sprintf("Pull Request removing the application from environment %s at %s", env.Name, env.Spec.Source.URL))
+	}
+	for _, env := range t.Previews {
+		answer = append(answer, fmt.Sprintf("preview environment %s in namespace %s", env.Name, env.Spec.Namespace))
+	}
+	for _, release := range t.Releases {
+		answer = append(answer, fmt.Sprintf("Release %s in namespace %s", release.Name, release.Namespace))
+	}
+	for _, hook := range t.WebHooks {
+		answer = append(answer, fmt.Sprintf("webhook %s", hook.URL))
+	}
+	if t.Archive {
+		answer = append(answer, fmt.Sprintf("archive git repository %s", t.GitURL))
+	}
+	return answer
+}
+
+// reportApplicationTeardown logs the resources which would be removed without removing them
+func (o *DeleteApplicationOptions) reportApplicationTeardown(teardown *applicationTeardown) {
+	log.Logger().Infof("Deleting application %s would remove:", util.ColorInfo(teardown.Owner+"/"+teardown.Name))
+	for _, line := range teardown.describe() {
+		log.Logger().Infof("  %s", line)
+	}
+}
+
+// deleteApplicationResources removes the previews, releases and webhooks of the application
+func (o *DeleteApplicationOptions) deleteApplicationResources(jxClient versioned.Interface, ns string, teardown *applicationTeardown) error {
+	for _, env := range teardown.Previews {
+		err := o.DeletePreviewEnvironment(ns, env)
+		if err != nil {
+			return errors.Wrapf(err, "deleting preview environment %s", env.Name)
+		}
+	}
+	for _, release := range teardown.Releases {
+		err := jxClient.JenkinsV1().Releases(release.Namespace).Delete(release.Name, &metav1.DeleteOptions{})
+		if err != nil {
+			return errors.Wrapf(err, "deleting Release %s in namespace %s", release.Name, release.Namespace)
+		}
+		log.Logger().Infof("Deleted Release %s in namespace %s", util.ColorInfo(release.Name), release.Namespace)
+	}
+	if len(teardown.WebHooks) == 0 {
+		return nil
+	}
+	deleter, ok := teardown.gitProvider.(gits.WebHookDeleter)
+	if !ok {
+		log.Logger().Warnf("The git provider %s does not support deleting webhooks so please remove them from %s manually", teardown.gitProvider.Kind(), teardown.GitURL)
+		return nil
+	}
+	for _, hook := range teardown.WebHooks {
+		err := deleter.DeleteWebHook(teardown.Owner, teardown.Name, hook.ID)
+		if err != nil {
+			return errors.Wrapf(err, "deleting webhook %s from %s", hook.URL, teardown.GitURL)
+		}
+		log.Logger().Infof("Deleted webhook %s from %s", util.ColorInfo(hook.URL), teardown.GitURL)
+	}
+	return nil
+}
+
+// archiveApplicationRepository archives the git repository of the application
+func (o *DeleteApplicationOptions) archiveApplicationRepository(teardown *applicationTeardown) error {
+	if !teardown.Archive {
+		return nil
+	}
+	archiver, ok := teardown.gitProvider.(gits.RepositoryArchiver)
+	if !ok {
+		log.Logger().Warnf("The git provider %s does not support archiving repositories so please archive %s manually", teardown.gitProvider.Kind(), teardown.GitURL)
+		return nil
+	}
+	err := archiver.ArchiveRepository(teardown.Owner, teardown.Name)
+	if err != nil {
+		return err
+	}
+	log.Logger().Infof("Archived git repository %s", util.ColorInfo(teardown.GitURL))
+	return nil
+}
+
+// isDeployedToEnvironment returns true if the environment has a Release or Deployment of the application. If the
+// environment cannot be queried we assume the application is deployed so that it still gets removed
+func isDeployedToEnvironment(jxClient versioned.Interface, kubeClient kubernetes.Interface, env *v1.Environment, owner string, name string) bool {
+	envNs := env.Spec.Namespace
+	if envNs == "" {
+		return true
+	}
+	releases, err := jxClient.JenkinsV1().Releases(envNs).List(metav1.ListOptions{})
+	if err != nil {
+		log.Logger().Debugf("Failed to list the Releases in namespace %s: %s", envNs, err)
+		return true
+	}
+	for i := range releases.Items {
+		if isReleaseOfApplication(&releases.Items[i], owner, name) {
+			return true
+		}
+	}
+	deployments, err := kube.GetDeployments(kubeClient, envNs)
+	if err != nil {
+		log.Logger().Debugf("Failed to list the Deployments in namespace %s: %s", envNs, err)
+		return true
+	}
+	for deploymentName := range deployments {
+		if kube.GetAppName(deploymentName, envNs) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// isPreviewOfApplication returns true if the preview environment was created for a pull request of the application
+func isPreviewOfApplication(env *v1.Environment, owner string, name string) bool {
+	if env.Spec.Source.URL != "" {
+		gitInfo, err := gits.ParseGitURL(env.Spec.Source.URL)
+		if err == nil {
+			return strings.EqualFold(gitInfo.Organisation, owner) && gitInfo.Name == name
+		}
+	}
+	return env.Spec.PreviewGitSpec.ApplicationName == name
+}
+
+// isReleaseOfApplication returns true if the Release was created by the pipeline of the application
+func isReleaseOfApplication(release *v1.Release, owner string, name string) bool {
+	return strings.EqualFold(release.Spec.GitOwner, owner) && release.Spec.GitRepository == name
+}
+
+// sortedEnvironments returns the environments in promotion order so that the teardown is repeatable
+func sortedEnvironments(envMap map[string]*v1.Environment) []*v1.Environment {
+	envs := []*v1.Environment{}
+	for _, env := range envMap {
+		envs = append(envs, env)
+	}
+	sort.Slice(envs, func(i, j int) bool {
+		if envs[i].Spec.Order != envs[j].Spec.Order {
+			return envs[i].Spec.Order < envs[j].Spec.Order
+		}
+		return envs[i].Name < envs[j].Name
+	})
+	return envs
+}
+
+func listPipelineActivitiesForSourceRepository(jxClient versioned.Interface, ns string, gitOwner string, gitRepo string) ([]v1.PipelineActivity, error) {
+	gitOwnerSelector := fields.OneTermEqualSelector("spec.gitOwner", gitOwner)
+	gitRepoSelector := fields.OneTermEqualSelector("spec.gitRepository", gitRepo)
+	fieldSelector := fields.AndSelectors(gitOwnerSelector, gitRepoSelector)
+
+	paList, err := kube.ListSelectedPipelineActivities(jxClient.JenkinsV1().PipelineActivities(ns), nil, fieldSelector)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list PipelineActivity resource in namespace %s with selector %s", ns, fieldSelector.String())
+	}
+	return paList.Items, nil
+}
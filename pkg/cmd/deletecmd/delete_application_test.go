@@ -17,6 +17,9 @@ import (
 	"github.com/petergtz/pegomock"
 	uuid "github.com/satori/go.uuid"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1beta1 "k8s.io/api/apps/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -107,3 +110,148 @@ func TestRemoveEnvrionmentFromJobs(t *testing.T) {
 		assert.Equal(t, test.want, got, test.name)
 	}
 }
+
+func TestPlanApplicationTeardown(t *testing.T) {
+	t.Parallel()
+
+	staging := kube.NewPermanentEnvironmentWithGit("staging", "https://github.com/myorg/environment-staging.git")
+	production := kube.NewPermanentEnvironmentWithGit("production", "https://github.com/myorg/environment-production.git")
+	production.Spec.Order = 200
+	preview := kube.NewPreviewEnvironment("myorg-myapp-pr-1")
+	preview.Spec.Source.URL = "https://github.com/myorg/myapp.git"
+	otherPreview := kube.NewPreviewEnvironment("myorg-other-pr-2")
+	otherPreview.Spec.Source.URL = "https://github.com/myorg/other.git"
+	sr := &v1.SourceRepository{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "myorg-myapp",
+			Namespace: "jx",
+		},
+		Spec: v1.SourceRepositorySpec{
+			Provider: "https://github.com",
+			Org:      "myorg",
+			Repo:     "myapp",
+		},
+	}
+	release := &v1.Release{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "myapp-0.0.1",
+			Namespace: "jx",
+		},
+		Spec: v1.ReleaseSpec{
+			GitOwner:      "myorg",
+			GitRepository: "myapp",
+		},
+	}
+	otherRelease := &v1.Release{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "other-0.0.1",
+			Namespace: "jx",
+		},
+		Spec: v1.ReleaseSpec{
+			GitOwner:      "myorg",
+			GitRepository: "other",
+		},
+	}
+	deployment := &appsv1beta1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "jx-staging-myapp",
+			Namespace: "jx-staging",
+		},
+	}
+
+	commonOpts := opts.NewCommonOptionsWithFactory(nil)
+	testhelpers.ConfigureTestOptionsWithResources(&commonOpts,
+		[]runtime.Object{deployment},
+		[]runtime.Object{staging, production, preview, otherPreview, sr, release, otherRelease},
+		gits.NewGitFake(),
+		nil,
+		helm_test.NewMockHelmer(),
+		resources_test.NewMockInstaller(),
+	)
+	jxClient, ns, err := commonOpts.JXClientAndDevNamespace()
+	require.NoError(t, err)
+	kubeClient, err := commonOpts.KubeClient()
+	require.NoError(t, err)
+	envMap, _, err := kube.GetOrderedEnvironments(jxClient, ns)
+	require.NoError(t, err)
+
+	o := &DeleteApplicationOptions{
+		CommonOptions:  &commonOpts,
+		DeletePreviews: true,
+		DeleteReleases: true,
+	}
+	teardown, err := o.planApplicationTeardown(jxClient, kubeClient, ns, envMap, "myorg", "myapp")
+	require.NoError(t, err)
+
+	assert.Equal(t, "https://github.com/myorg/myapp.git", teardown.GitURL)
+	assert.Equal(t, []string{
+		"SourceRepository myorg-myapp",
+		"Pull Request removing the application from environment staging at https://github.com/myorg/environment-staging.git",
+		"preview environment myorg-myapp-pr-1 in namespace jx-preview-myorg-myapp-pr-1",
+		"Release myapp-0.0.1 in namespace jx",
+	}, teardown.describe())
+
+	o.IgnoreEnvironments = true
+	o.DeletePreviews = false
+	o.DeleteReleases = false
+	teardown, err = o.planApplicationTeardown(jxClient, kubeClient, ns, envMap, "myorg", "myapp")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"SourceRepository myorg-myapp"}, teardown.describe())
+}
+
+func TestDeleteApplicationWebHooksAndArchive(t *testing.T) {
+	t.Parallel()
+
+	repo, err := gits.NewFakeRepository("myorg", "myapp", nil, nil)
+	require.NoError(t, err)
+	gitProvider := gits.NewFakeProvider(repo)
+	err = gitProvider.CreateWebHook(&gits.GitWebHookArguments{
+		ID:    1,
+		Owner: "myorg",
+		Repo:  repo.GitRepo,
+		URL:   "http://hook.jx.example.com/hook",
+	})
+	require.NoError(t, err)
+	hooks, err := gitProvider.ListWebHooks("myorg", "myapp")
+	require.NoError(t, err)
+	require.Len(t, hooks, 1)
+
+	commonOpts := opts.NewCommonOptionsWithFactory(nil)
+	testhelpers.ConfigureTestOptionsWithResources(&commonOpts, nil, nil, gits.NewGitFake(), gitProvider, helm_test.NewMockHelmer(), resources_test.NewMockInstaller())
+	jxClient, ns, err := commonOpts.JXClientAndDevNamespace()
+	require.NoError(t, err)
+
+	o := &DeleteApplicationOptions{
+		CommonOptions: &commonOpts,
+	}
+	teardown := &applicationTeardown{
+		Owner:       "myorg",
+		Name:        "myapp",
+		GitURL:      "https://fake.git/myorg/myapp.git",
+		WebHooks:    hooks,
+		Archive:     true,
+		gitProvider: gitProvider,
+	}
+	err = o.deleteApplicationResources(jxClient, ns, teardown)
+	require.NoError(t, err)
+	hooks, err = gitProvider.ListWebHooks("myorg", "myapp")
+	require.NoError(t, err)
+	assert.Empty(t, hooks)
+
+	err = o.archiveApplicationRepository(teardown)
+	require.NoError(t, err)
+	assert.True(t, repo.GitRepo.Archived)
+}
+
+func TestIsPreviewOfApplication(t *testing.T) {
+	t.Parallel()
+
+	preview := kube.NewPreviewEnvironment("myorg-myapp-pr-1")
+	preview.Spec.Source.URL = "https://github.com/MyOrg/myapp.git"
+	assert.True(t, isPreviewOfApplication(preview, "myorg", "myapp"))
+	assert.False(t, isPreviewOfApplication(preview, "myorg", "other"))
+
+	preview.Spec.Source.URL = ""
+	preview.Spec.PreviewGitSpec.ApplicationName = "myapp"
+	assert.True(t, isPreviewOfApplication(preview, "myorg", "myapp"))
+}
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"github.com/jenkins-x/jx/pkg/cmd/diagnose"
 	"github.com/jenkins-x/jx/pkg/cmd/helper"
 	"github.com/jenkins-x/jx/pkg/cmd/opts"
 	"github.com/jenkins-x/jx/pkg/health"
@@ -33,6 +34,9 @@ func NewCmdDiagnose(commonOpts *opts.CommonOptions) *cobra.Command {
 	}
 	cmd.Flags().StringVarP(&options.Namespace, "namespace", "n", "", "The namespace to display the kube resources from. If left out, defaults to the current namespace")
 	cmd.Flags().StringArrayVarP(&options.Show, "show", "", []string{"version", "status", "pvc", "pods", "ingresses", "secrets"}, "Determine what information to diagnose")
+
+	cmd.AddCommand(diagnose.NewCmdDiagnoseAddons(commonOpts))
+	cmd.AddCommand(diagnose.NewCmdDiagnoseCollect(commonOpts))
 	return cmd
 }
 
@@ -0,0 +1,166 @@
+package diagnose
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/jenkins-x/jx/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/pkg/health"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// DiagnoseAddonsOptions the options for the diagnose addons command
+type DiagnoseAddonsOptions struct {
+	*opts.CommonOptions
+
+	Namespace              string
+	Output                 string
+	Addons                 []string
+	VolumeWarningPercent   int
+	CertificateWarningDays int
+}
+
+var (
+	diagnoseAddonsLong = templates.LongDesc(`
+		Reports the health of the addons installed in the Jenkins X namespace such as nexus, chartmuseum, lighthouse,
+		tekton and vault.
+
+		For each addon the readiness and restarts of its pods, its version compared to the version stream, the usage of
+		its persistent volumes and the expiry of the TLS certificates of its ingresses are checked.
+`)
+
+	diagnoseAddonsExample = templates.Examples(`
+		# report the health of the addons
+		jx diagnose addons
+
+		# output the health of the addons as JSON for a monitoring system
+		jx diagnose addons -o json
+
+		# only check lighthouse and tekton
+		jx diagnose addons --addon lighthouse --addon tekton
+	`)
+)
+
+// NewCmdDiagnoseAddons creates the command
+func NewCmdDiagnoseAddons(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &DiagnoseAddonsOptions{
+		CommonOptions: commonOpts,
+	}
+	cmd := &cobra.Command{
+		Use:     "addons",
+		Short:   "Reports the health of the installed addons",
+		Aliases: []string{"addon"},
+		Long:    diagnoseAddonsLong,
+		Example: diagnoseAddonsExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.Flags().StringVarP(&options.Namespace, "namespace", "n", "", "The namespace of the addons. Defaults to the dev namespace")
+	cmd.Flags().StringVarP(&options.Output, "output", "o", "", "The output format. Use 'json' for monitoring integrations")
+	cmd.Flags().StringArrayVarP(&options.Addons, "addon", "a", nil, "The names of the addons to check. Defaults to all the known addons")
+	cmd.Flags().IntVarP(&options.VolumeWarningPercent, "volume-warning-percent", "", health.DefaultVolumeWarningPercent, "The percentage of a persistent volume used at which a warning is reported")
+	cmd.Flags().IntVarP(&options.CertificateWarningDays, "cert-warning-days", "", health.DefaultCertificateWarningDays, "The number of days before a certificate expires at which a warning is reported")
+	return cmd
+}
+
+// Run implements this command
+func (o *DiagnoseAddonsOptions) Run() error {
+	if o.Output != "" && o.Output != "json" {
+		return util.InvalidOption("output", o.Output, []string{"json"})
+	}
+	kubeClient, ns, err := o.KubeClientAndDevNamespace()
+	if err != nil {
+		return errors.Wrap(err, "failed to create kubeClient")
+	}
+	if o.Namespace != "" {
+		ns = o.Namespace
+	}
+
+	addons, err := o.selectAddons()
+	if err != nil {
+		return err
+	}
+
+	checker := &health.AddonChecker{
+		KubeClient:             kubeClient,
+		Namespace:              ns,
+		VolumeWarningPercent:   o.VolumeWarningPercent,
+		CertificateWarningDays: o.CertificateWarningDays,
+	}
+	resolver, err := o.GetVersionResolver()
+	if err != nil {
+		log.Logger().Warnf("Unable to load the version stream so not checking addon versions: %s", err)
+	} else {
+		checker.VersionsDir = resolver.VersionsDir
+	}
+
+	statuses, err := checker.CheckAddons(addons)
+	if err != nil {
+		return err
+	}
+
+	if o.Output == "json" {
+		data, err := json.MarshalIndent(statuses, "", "  ")
+		if err != nil {
+			return errors.Wrap(err, "marshalling the addon health to JSON")
+		}
+		_, err = fmt.Fprintln(o.Out, string(data))
+		return err
+	}
+
+	table := o.CreateTable()
+	table.AddRow("ADDON", "HEALTH", "PODS", "RESTARTS", "VERSION", "VERSION STREAM", "DETAILS")
+	for _, s := range statuses {
+		pods := ""
+		restarts := ""
+		if s.Health != health.LevelNotInstalled {
+			pods = fmt.Sprintf("%d/%d", s.ReadyPods, s.Pods)
+			restarts = fmt.Sprintf("%d", s.Restarts)
+		}
+		table.AddRow(s.Name, colorHealth(s.Health), pods, restarts, s.Version, s.ExpectedVersion, strings.Join(s.Messages, "; "))
+	}
+	table.Render()
+	return nil
+}
+
+func (o *DiagnoseAddonsOptions) selectAddons() ([]health.Addon, error) {
+	if len(o.Addons) == 0 {
+		return health.DefaultAddons, nil
+	}
+	names := make([]string, 0, len(health.DefaultAddons))
+	for _, addon := range health.DefaultAddons {
+		names = append(names, addon.Name)
+	}
+	answer := make([]health.Addon, 0, len(o.Addons))
+	for _, name := range o.Addons {
+		idx := util.StringArrayIndex(names, name)
+		if idx < 0 {
+			return nil, util.InvalidOption("addon", name, names)
+		}
+		answer = append(answer, health.DefaultAddons[idx])
+	}
+	return answer, nil
+}
+
+func colorHealth(level health.Level) string {
+	switch level {
+	case health.LevelOK:
+		return util.ColorInfo(level)
+	case health.LevelWarning:
+		return util.ColorWarning(level)
+	case health.LevelError:
+		return util.ColorError(level)
+	default:
+		return string(level)
+	}
+}
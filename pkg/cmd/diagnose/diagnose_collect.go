@@ -0,0 +1,354 @@
+package diagnose
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ghodss/yaml"
+	"github.com/jenkins-x/jx/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/pkg/config"
+	"github.com/jenkins-x/jx/pkg/kube"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/mholt/archiver"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// DiagnoseCollectOptions the options for the diagnose collect command
+type DiagnoseCollectOptions struct {
+	*opts.CommonOptions
+
+	Namespace    string
+	Output       string
+	LogLines     int64
+	PipelineRuns int
+
+	files      map[string][]byte
+	failures   []string
+	masker     *kube.LogMasker
+	kubeClient kubernetes.Interface
+}
+
+var (
+	diagnoseCollectLong = templates.LongDesc(`
+		Collects a support bundle of diagnostic information about the Jenkins X installation which can be attached to
+		bug reports and support requests.
+
+		The bundle contains the Jenkins X custom resources, the logs of the controllers, the webhook configuration,
+		recent PipelineRuns, the helm releases and the requirements and configuration of the installation.
+
+		The values of all the Secrets in the namespace, the values of the environment variables of pods and any values
+		which look like passwords, tokens or credentials are redacted from the bundle. Secrets themselves are never
+		collected and only the Jenkins X ConfigMaps are included.
+`)
+
+	diagnoseCollectExample = templates.Examples(`
+		# collect a support bundle
+		jx diagnose collect --output bundle.tgz
+	`)
+
+	// controllerPodPrefixes the prefixes of the names of the pods whose logs are collected
+	controllerPodPrefixes = []string{"jenkins-x-controller", "jx-controller", "controllerbuild", "controllerrole",
+		"controllerteam", "lighthouse", "hook", "tekton-pipelines", "pipelinerunner", "tide", "crier", "plank",
+		"deck", "jenkins-x-chartmuseum", "jenkins-x-docker-registry"}
+
+	// webhookConfigMaps the names of the ConfigMaps which configure the handling of webhooks
+	webhookConfigMaps = []string{"config", "plugins", "lighthouse-config", "lighthouse-plugins"}
+
+	// jxConfigMapPrefixes the prefixes of the names of the Jenkins X ConfigMaps which are collected
+	jxConfigMapPrefixes = []string{"jenkins-x-", "jx-", kube.ConfigMapExposecontroller, kube.ConfigMapIngressConfig}
+
+	sensitiveKeyRegex   = regexp.MustCompile(`(?im)^([ \t]*-?[ \t]*"?[\w.-]*(password|passwd|secret|token|apikey|api_key|api-key|credentials|privatekey|private_key)[\w.-]*"?[ \t]*[:=][ \t]*).+$`)
+	urlCredentialsRegex = regexp.MustCompile(`(\w+://)[^/\s:@]+:[^/\s@]+@`)
+)
+
+// NewCmdDiagnoseCollect creates the command
+func NewCmdDiagnoseCollect(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &DiagnoseCollectOptions{
+		CommonOptions: commonOpts,
+	}
+	cmd := &cobra.Command{
+		Use:     "collect",
+		Short:   "Collects a redacted support bundle of diagnostic information",
+		Long:    diagnoseCollectLong,
+		Example: diagnoseCollectExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.Flags().StringVarP(&options.Namespace, "namespace", "n", "", "The namespace to collect from. Defaults to the dev namespace")
+	cmd.Flags().StringVarP(&options.Output, "output", "o", "jx-support-bundle.tgz", "The support bundle file to create")
+	cmd.Flags().Int64VarP(&options.LogLines, "log-lines", "", 1000, "The number of lines of the logs of each controller container to collect")
+	cmd.Flags().IntVarP(&options.PipelineRuns, "pipelineruns", "", 20, "The number of the most recent PipelineRuns to collect")
+	return cmd
+}
+
+// Run implements this command
+func (o *DiagnoseCollectOptions) Run() error {
+	if !strings.HasSuffix(o.Output, ".tgz") && !strings.HasSuffix(o.Output, ".tar.gz") {
+		return util.InvalidOptionf("output", o.Output, "the support bundle must be a .tgz file")
+	}
+	kubeClient, ns, err := o.KubeClientAndDevNamespace()
+	if err != nil {
+		return errors.Wrap(err, "failed to create kubeClient")
+	}
+	if o.Namespace != "" {
+		ns = o.Namespace
+	}
+	o.kubeClient = kubeClient
+	o.files = map[string][]byte{}
+	o.failures = nil
+
+	o.masker, err = kube.NewLogMasker(kubeClient, ns)
+	if err != nil {
+		return errors.Wrapf(err, "loading the Secrets in namespace %s to redact", ns)
+	}
+
+	log.Logger().Infof("Collecting diagnostic information from namespace %s", util.ColorInfo(ns))
+	o.collectCustomResources(ns)
+	o.collectControllerLogs(ns)
+	o.collectWebhookConfiguration(ns)
+	o.collectPipelineRuns(ns)
+	o.collectHelmReleases(ns)
+	o.collectConfiguration(ns)
+
+	summary := fmt.Sprintf("namespace: %s\ncollected: %s\n", ns, time.Now().UTC().Format(time.RFC3339))
+	if len(o.failures) > 0 {
+		summary += "failures:\n"
+		for _, f := range o.failures {
+			summary += fmt.Sprintf("- %s\n", f)
+		}
+	}
+	o.files["summary.yaml"] = []byte(summary)
+
+	err = writeBundle(o.Output, o.files, o.masker)
+	if err != nil {
+		return err
+	}
+	log.Logger().Infof("Created support bundle %s with %d files", util.ColorInfo(o.Output), len(o.files))
+	return nil
+}
+
+func (o *DiagnoseCollectOptions) collectCustomResources(ns string) {
+	jxClient, _, err := o.JXClient()
+	if err != nil {
+		o.fail("creating the jx client", err)
+		return
+	}
+	jenkins := jxClient.JenkinsV1()
+	environments, err := jenkins.Environments(ns).List(metav1.ListOptions{})
+	o.addYAML("jx/environments.yaml", environments, err)
+	sourceRepositories, err := jenkins.SourceRepositories(ns).List(metav1.ListOptions{})
+	o.addYAML("jx/sourcerepositories.yaml", sourceRepositories, err)
+	apps, err := jenkins.Apps(ns).List(metav1.ListOptions{})
+	o.addYAML("jx/apps.yaml", apps, err)
+	teams, err := jenkins.Teams(ns).List(metav1.ListOptions{})
+	o.addYAML("jx/teams.yaml", teams, err)
+	users, err := jenkins.Users(ns).List(metav1.ListOptions{})
+	o.addYAML("jx/users.yaml", users, err)
+	activities, err := jenkins.PipelineActivities(ns).List(metav1.ListOptions{})
+	if err == nil {
+		items := activities.Items
+		sort.Slice(items, func(i, j int) bool {
+			return items[j].CreationTimestamp.Before(&items[i].CreationTimestamp)
+		})
+		if len(items) > o.PipelineRuns {
+			activities.Items = items[:o.PipelineRuns]
+		}
+	}
+	o.addYAML("jx/pipelineactivities.yaml", activities, err)
+}
+
+func (o *DiagnoseCollectOptions) collectControllerLogs(ns string) {
+	pods, err := o.kubeClient.CoreV1().Pods(ns).List(metav1.ListOptions{})
+	if err != nil {
+		o.fail("listing pods", err)
+		return
+	}
+	o.addYAML("kubernetes/pods.yaml", RedactPodEnv(pods), nil)
+	for _, pod := range pods.Items {
+		if !util.StartsWithAny(pod.Name, controllerPodPrefixes) {
+			continue
+		}
+		for _, c := range pod.Spec.Containers {
+			tailLines := o.LogLines
+			data, err := o.kubeClient.CoreV1().Pods(ns).GetLogs(pod.Name, &corev1.PodLogOptions{
+				Container: c.Name,
+				TailLines: &tailLines,
+			}).Do().Raw()
+			if err != nil {
+				o.fail(fmt.Sprintf("getting the logs of pod %s container %s", pod.Name, c.Name), err)
+				continue
+			}
+			o.files[filepath.Join("logs", pod.Name, c.Name+".log")] = data
+		}
+	}
+}
+
+func (o *DiagnoseCollectOptions) collectWebhookConfiguration(ns string) {
+	for _, name := range webhookConfigMaps {
+		cm, err := o.kubeClient.CoreV1().ConfigMaps(ns).Get(name, metav1.GetOptions{})
+		if err != nil {
+			continue
+		}
+		o.addYAML(filepath.Join("webhooks", "configmap-"+name+".yaml"), cm, nil)
+	}
+	validating, err := o.kubeClient.AdmissionregistrationV1beta1().ValidatingWebhookConfigurations().List(metav1.ListOptions{})
+	o.addYAML("webhooks/validatingwebhookconfigurations.yaml", validating, err)
+	mutating, err := o.kubeClient.AdmissionregistrationV1beta1().MutatingWebhookConfigurations().List(metav1.ListOptions{})
+	o.addYAML("webhooks/mutatingwebhookconfigurations.yaml", mutating, err)
+}
+
+func (o *DiagnoseCollectOptions) collectPipelineRuns(ns string) {
+	tektonClient, _, err := o.TektonClient()
+	if err != nil {
+		o.fail("creating the tekton client", err)
+		return
+	}
+	pipelineRuns, err := tektonClient.TektonV1alpha1().PipelineRuns(ns).List(metav1.ListOptions{})
+	if err == nil {
+		items := pipelineRuns.Items
+		sort.Slice(items, func(i, j int) bool {
+			return items[j].CreationTimestamp.Before(&items[i].CreationTimestamp)
+		})
+		if len(items) > o.PipelineRuns {
+			pipelineRuns.Items = items[:o.PipelineRuns]
+		}
+	}
+	o.addYAML("tekton/pipelineruns.yaml", pipelineRuns, err)
+}
+
+func (o *DiagnoseCollectOptions) collectHelmReleases(ns string) {
+	releases, _, err := o.Helm().ListReleases(ns)
+	o.addYAML("helm/releases.yaml", releases, err)
+}
+
+func (o *DiagnoseCollectOptions) collectConfiguration(ns string) {
+	settings, err := o.TeamSettings()
+	if err != nil {
+		o.fail("getting the team settings", err)
+	} else {
+		o.addYAML("config/teamsettings.yaml", settings, nil)
+		requirements, err := config.GetRequirementsConfigFromTeamSettings(settings)
+		if err == nil && requirements != nil {
+			o.addYAML("config/"+config.RequirementsConfigFileName, requirements, nil)
+		}
+	}
+	configMaps, err := o.kubeClient.CoreV1().ConfigMaps(ns).List(metav1.ListOptions{})
+	if err == nil {
+		configMaps.Items = JXConfigMaps(configMaps.Items)
+	}
+	o.addYAML("config/configmaps.yaml", configMaps, err)
+}
+
+// addYAML adds the value as a YAML file to the bundle or records the failure to collect it
+func (o *DiagnoseCollectOptions) addYAML(name string, value interface{}, err error) {
+	if err != nil {
+		o.fail("collecting "+name, err)
+		return
+	}
+	data, err := yaml.Marshal(value)
+	if err != nil {
+		o.fail("marshalling "+name, err)
+		return
+	}
+	o.files[name] = data
+}
+
+func (o *DiagnoseCollectOptions) fail(message string, err error) {
+	log.Logger().Warnf("Failed %s: %s", message, err)
+	o.failures = append(o.failures, fmt.Sprintf("%s: %s", message, err))
+}
+
+// writeBundle redacts the files and writes them to a tgz file
+func writeBundle(output string, files map[string][]byte, masker *kube.LogMasker) error {
+	tmpDir, err := ioutil.TempDir("", "jx-support-bundle-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	dir := filepath.Join(tmpDir, "jx-support-bundle")
+	for name, data := range files {
+		fileName := filepath.Join(dir, name)
+		err = os.MkdirAll(filepath.Dir(fileName), util.DefaultWritePermissions)
+		if err != nil {
+			return errors.Wrapf(err, "creating directory for %s", fileName)
+		}
+		err = ioutil.WriteFile(fileName, Redact(data, masker), util.DefaultFileWritePermissions)
+		if err != nil {
+			return errors.Wrapf(err, "writing %s", fileName)
+		}
+	}
+	if _, err := os.Stat(output); err == nil {
+		err = os.Remove(output)
+		if err != nil {
+			return errors.Wrapf(err, "removing existing bundle %s", output)
+		}
+	}
+	err = archiver.Archive([]string{dir}, output)
+	if err != nil {
+		return errors.Wrapf(err, "creating support bundle %s", output)
+	}
+	return nil
+}
+
+// Redact masks the values of secrets known to the masker, the values of keys which look like passwords, tokens or
+// credentials and the credentials in URLs
+func Redact(data []byte, masker *kube.LogMasker) []byte {
+	text := string(data)
+	if masker != nil {
+		text = masker.MaskLog(text)
+	}
+	text = sensitiveKeyRegex.ReplaceAllString(text, "${1}********")
+	text = urlCredentialsRegex.ReplaceAllString(text, "${1}****:****@")
+	return []byte(text)
+}
+
+// RedactPodEnv returns a copy of the pods with the values of the environment variables of their containers redacted
+func RedactPodEnv(pods *corev1.PodList) *corev1.PodList {
+	answer := pods.DeepCopy()
+	for i := range answer.Items {
+		spec := &answer.Items[i].Spec
+		redactContainerEnv(spec.InitContainers)
+		redactContainerEnv(spec.Containers)
+	}
+	return answer
+}
+
+func redactContainerEnv(containers []corev1.Container) {
+	for i := range containers {
+		env := containers[i].Env
+		for j := range env {
+			if env[j].Value != "" {
+				env[j].Value = "********"
+			}
+		}
+	}
+}
+
+// JXConfigMaps returns the Jenkins X ConfigMaps so that the configuration of other applications is not collected
+func JXConfigMaps(configMaps []corev1.ConfigMap) []corev1.ConfigMap {
+	answer := []corev1.ConfigMap{}
+	for _, cm := range configMaps {
+		if util.StartsWithAny(cm.Name, jxConfigMapPrefixes) {
+			answer = append(answer, cm)
+		}
+	}
+	return answer
+}
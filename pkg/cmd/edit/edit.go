@@ -57,10 +57,13 @@ func NewCmdEdit(commonOpts *opts.CommonOptions) *cobra.Command {
 	cmd.AddCommand(NewCmdEditAddon(commonOpts))
 	cmd.AddCommand(NewCmdEditAppJenkinsPlugins(commonOpts))
 	cmd.AddCommand(NewCmdEditBuildpack(commonOpts))
+	cmd.AddCommand(NewCmdEditBuildPod(commonOpts))
 	cmd.AddCommand(NewCmdEditConfig(commonOpts))
 	cmd.AddCommand(NewCmdEditDeployKind(commonOpts))
 	cmd.AddCommand(NewCmdEditEnv(commonOpts))
+	cmd.AddCommand(NewCmdEditEnvVars(commonOpts))
 	cmd.AddCommand(NewCmdEditHelmBin(commonOpts))
+	cmd.AddCommand(NewCmdEditPipelineConcurrency(commonOpts))
 	cmd.AddCommand(requirements.NewCmdEditRequirements(commonOpts))
 	cmd.AddCommand(NewCmdEditStorage(commonOpts))
 	cmd.AddCommand(NewCmdEditUserRole(commonOpts))
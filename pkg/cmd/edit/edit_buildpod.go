@@ -0,0 +1,253 @@
+package edit
+
+import (
+	"fmt"
+	"strings"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+var (
+	editBuildPodLong = templates.LongDesc(`
+		Edits the build pod policy for your team.
+
+		The policy defines the default CPU and memory requests and limits, node selectors, tolerations and security context
+		of the pods created for your pipelines. Values specified by a pipeline always take precedence over the policy.
+
+		Spot builds schedule the build pods on the spot/preemptible build node pool created with the --spot-build-pool option
+		of 'jx create cluster gke' or 'jx create cluster eks'. The tasks of the pipelines are retried so that builds
+		survive the preemption of a node.
+
+		A policy without a kind applies to all pipelines. A policy for a kind of pipeline (release, pullrequest or feature)
+		overrides the values of the policy for all pipelines.
+`)
+
+	editBuildPodExample = templates.Examples(`
+		# Set the default resources of all the build pods of your team
+		jx edit buildpod --request-cpu 400m --request-memory 512Mi --limit-cpu 2 --limit-memory 2Gi
+
+		# Run the release pipelines on dedicated build nodes
+		jx edit buildpod --kind release --node-selector jenkins-x.io/pool=builds --toleration jenkins-x.io/pool=builds:NoSchedule
+
+		# Run all the build pods as a non root user
+		jx edit buildpod --run-as-user 1000 --run-as-non-root
+
+		# Run the pull request pipelines on the spot/preemptible build node pool
+		jx edit buildpod --kind pullrequest --spot
+
+		# Remove the build pod policy for pull request pipelines
+		jx edit buildpod --kind pullrequest --clear
+	`)
+)
+
+// EditBuildPodOptions the options for the edit buildpod command
+type EditBuildPodOptions struct {
+	EditOptions
+
+	Kind          string
+	RequestCPU    string
+	RequestMemory string
+	LimitCPU      string
+	LimitMemory   string
+	NodeSelectors []string
+	Tolerations   []string
+	RunAsUser     int64
+	RunAsNonRoot  bool
+	Spot          bool
+	Retries       int
+	Clear         bool
+}
+
+// NewCmdEditBuildPod creates a command object for the "edit buildpod" command
+func NewCmdEditBuildPod(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &EditBuildPodOptions{
+		EditOptions: EditOptions{
+			CommonOptions: commonOpts,
+		},
+	}
+
+	cmd := &cobra.Command{
+		Use:     "buildpod",
+		Short:   "Edits the build pod policy for your team",
+		Aliases: []string{"buildpods", "build pod"},
+		Long:    editBuildPodLong,
+		Example: editBuildPodExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.Flags().StringVarP(&options.Kind, "kind", "k", "", "The kind of pipeline the policy applies to such as release, pullrequest or feature. Defaults to all kinds")
+	cmd.Flags().StringVarP(&options.RequestCPU, "request-cpu", "", "", "The default CPU request of the build steps")
+	cmd.Flags().StringVarP(&options.RequestMemory, "request-memory", "", "", "The default memory request of the build steps")
+	cmd.Flags().StringVarP(&options.LimitCPU, "limit-cpu", "", "", "The default CPU limit of the build steps")
+	cmd.Flags().StringVarP(&options.LimitMemory, "limit-memory", "", "", "The default memory limit of the build steps")
+	cmd.Flags().StringArrayVarP(&options.NodeSelectors, "node-selector", "", nil, "The node selectors of the build pods in the form key=value")
+	cmd.Flags().StringArrayVarP(&options.Tolerations, "toleration", "", nil, "The tolerations of the build pods in the form key[=value][:Effect]")
+	cmd.Flags().Int64VarP(&options.RunAsUser, "run-as-user", "", -1, "The user ID the build steps run as")
+	cmd.Flags().BoolVarP(&options.RunAsNonRoot, "run-as-non-root", "", false, "Requires the build steps to run as a non root user")
+	cmd.Flags().BoolVarP(&options.Spot, "spot", "", false, "Schedules the build pods on the spot/preemptible build node pool when it has capacity. Use --spot=false to disable it for a kind of pipeline")
+	cmd.Flags().IntVarP(&options.Retries, "preemption-retries", "", 0, fmt.Sprintf("The number of times the tasks of a pipeline running on spot nodes are retried. Defaults to %d", v1.DefaultPreemptionRetries))
+	cmd.Flags().BoolVarP(&options.Clear, "clear", "", false, "Removes the build pod policy for the kind of pipeline")
+	return cmd
+}
+
+// Run implements the command
+func (o *EditBuildPodOptions) Run() error {
+	kinds := []string{"", "release", "pullrequest", "feature"}
+	if util.StringArrayIndex(kinds, o.Kind) < 0 {
+		return util.InvalidOption("kind", o.Kind, kinds[1:])
+	}
+	resources := corev1.ResourceRequirements{}
+	quantities := []struct {
+		flag  string
+		value string
+		list  *corev1.ResourceList
+		name  corev1.ResourceName
+	}{
+		{"request-cpu", o.RequestCPU, &resources.Requests, corev1.ResourceCPU},
+		{"request-memory", o.RequestMemory, &resources.Requests, corev1.ResourceMemory},
+		{"limit-cpu", o.LimitCPU, &resources.Limits, corev1.ResourceCPU},
+		{"limit-memory", o.LimitMemory, &resources.Limits, corev1.ResourceMemory},
+	}
+	for _, q := range quantities {
+		if q.value == "" {
+			continue
+		}
+		quantity, err := resource.ParseQuantity(q.value)
+		if err != nil {
+			return util.InvalidOptionError(q.flag, q.value, err)
+		}
+		if *q.list == nil {
+			*q.list = corev1.ResourceList{}
+		}
+		(*q.list)[q.name] = quantity
+	}
+	nodeSelector := map[string]string{}
+	for _, s := range o.NodeSelectors {
+		paths := strings.SplitN(s, "=", 2)
+		if len(paths) != 2 || paths[0] == "" {
+			return fmt.Errorf("invalid node selector %s which should be in the form key=value", s)
+		}
+		nodeSelector[paths[0]] = paths[1]
+	}
+	tolerations := []corev1.Toleration{}
+	for _, s := range o.Tolerations {
+		toleration, err := ParseToleration(s)
+		if err != nil {
+			return err
+		}
+		tolerations = append(tolerations, toleration)
+	}
+	var securityContext *corev1.SecurityContext
+	if o.RunAsUser >= 0 || o.RunAsNonRoot {
+		securityContext = &corev1.SecurityContext{}
+		if o.RunAsUser >= 0 {
+			runAsUser := o.RunAsUser
+			securityContext.RunAsUser = &runAsUser
+		}
+		if o.RunAsNonRoot {
+			runAsNonRoot := true
+			securityContext.RunAsNonRoot = &runAsNonRoot
+		}
+	}
+
+	kindName := o.Kind
+	if kindName == "" {
+		kindName = "all"
+	}
+	callback := func(env *v1.Environment) error {
+		teamSettings := &env.Spec.TeamSettings
+		policies := []v1.BuildPodPolicy{}
+		var policy *v1.BuildPodPolicy
+		for _, p := range teamSettings.BuildPodPolicies {
+			if p.PipelineKind != o.Kind {
+				policies = append(policies, p)
+			} else if !o.Clear && policy == nil {
+				policy = p.DeepCopy()
+			}
+		}
+		if o.Clear {
+			teamSettings.BuildPodPolicies = policies
+			log.Logger().Infof("Removed the build pod policy for %s pipelines", util.ColorInfo(kindName))
+			return nil
+		}
+		if policy == nil {
+			policy = &v1.BuildPodPolicy{PipelineKind: o.Kind}
+		}
+		for name, quantity := range resources.Requests {
+			if policy.Resources.Requests == nil {
+				policy.Resources.Requests = corev1.ResourceList{}
+			}
+			policy.Resources.Requests[name] = quantity
+		}
+		for name, quantity := range resources.Limits {
+			if policy.Resources.Limits == nil {
+				policy.Resources.Limits = corev1.ResourceList{}
+			}
+			policy.Resources.Limits[name] = quantity
+		}
+		for k, v := range nodeSelector {
+			if policy.NodeSelector == nil {
+				policy.NodeSelector = map[string]string{}
+			}
+			policy.NodeSelector[k] = v
+		}
+		if len(tolerations) > 0 {
+			policy.Tolerations = tolerations
+		}
+		if securityContext != nil {
+			policy.SecurityContext = securityContext
+		}
+		if o.Cmd != nil && o.Cmd.Flags().Changed("spot") {
+			spot := o.Spot
+			policy.Spot = &spot
+		}
+		if o.Retries > 0 {
+			policy.PreemptionRetries = o.Retries
+		}
+		teamSettings.BuildPodPolicies = append(policies, *policy)
+		log.Logger().Infof("Updated the build pod policy for %s pipelines", util.ColorInfo(kindName))
+		return nil
+	}
+	return o.ModifyDevEnvironment(callback)
+}
+
+// ParseToleration parses a toleration in the form key[=value][:Effect]
+func ParseToleration(text string) (corev1.Toleration, error) {
+	toleration := corev1.Toleration{}
+	keyValue := text
+	idx := strings.LastIndex(text, ":")
+	if idx >= 0 {
+		keyValue = text[0:idx]
+		toleration.Effect = corev1.TaintEffect(text[idx+1:])
+		switch toleration.Effect {
+		case corev1.TaintEffectNoSchedule, corev1.TaintEffectPreferNoSchedule, corev1.TaintEffectNoExecute:
+		default:
+			return toleration, fmt.Errorf("invalid toleration %s as the effect should be one of %s, %s or %s", text,
+				corev1.TaintEffectNoSchedule, corev1.TaintEffectPreferNoSchedule, corev1.TaintEffectNoExecute)
+		}
+	}
+	paths := strings.SplitN(keyValue, "=", 2)
+	toleration.Key = paths[0]
+	if toleration.Key == "" {
+		return toleration, fmt.Errorf("invalid toleration %s which should be in the form key[=value][:Effect]", text)
+	}
+	if len(paths) == 2 {
+		toleration.Operator = corev1.TolerationOpEqual
+		toleration.Value = paths[1]
+	} else {
+		toleration.Operator = corev1.TolerationOpExists
+	}
+	return toleration, nil
+}
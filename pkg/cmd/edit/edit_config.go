@@ -2,6 +2,7 @@ package edit
 
 import (
 	"fmt"
+	"path/filepath"
 	"strings"
 
 	"github.com/jenkins-x/jx/pkg/cmd/helper"
@@ -25,11 +26,21 @@ const (
 var (
 	editConfigLong = templates.LongDesc(`
 		Edits the project configuration
+
+		The --ca-file option configures a bundle of custom certificate authorities which are trusted by all the HTTP
+		clients of jx in addition to the system ones, which is useful behind TLS intercepting proxies. The proxy itself
+		is configured via the standard $HTTP_PROXY, $HTTPS_PROXY and $NO_PROXY environment variables.
 `)
 
 	editConfigExample = templates.Examples(`
 		# Edit the project configuration for the current directory
 		jx edit config
+
+		# Trust the certificate authorities of the given PEM file for all HTTP requests
+		jx edit config --ca-file ~/certs/corporate-ca.pem
+
+		# Remove the custom certificate authorities
+		jx edit config --ca-file ""
 	`)
 
 	configKinds = []string{
@@ -43,8 +54,9 @@ var (
 type EditConfigOptions struct {
 	EditOptions
 
-	Dir  string
-	Kind string
+	Dir    string
+	Kind   string
+	CAFile string
 
 	IssuesAuthConfigSvc auth.ConfigService
 	ChatAuthConfigSvc   auth.ConfigService
@@ -73,12 +85,16 @@ func NewCmdEditConfig(commonOpts *opts.CommonOptions) *cobra.Command {
 	}
 	cmd.Flags().StringVarP(&options.Dir, "dir", "d", "", "The root project directory. Defaults to the current dir")
 	cmd.Flags().StringVarP(&options.Kind, "kind", "k", "", "The kind of configuration to edit root project directory. Possible values "+strings.Join(configKinds, ", "))
+	cmd.Flags().StringVarP(&options.CAFile, "ca-file", "", "", "The PEM encoded bundle of custom certificate authorities trusted by all HTTP requests. Specify an empty value to remove it")
 
 	return cmd
 }
 
 // Run implements the command
 func (o *EditConfigOptions) Run() error {
+	if o.Cmd != nil && o.Cmd.Flags().Changed("ca-file") {
+		return o.EditCAFile()
+	}
 	pc, fileName, err := config.LoadProjectConfig(o.Dir)
 	if err != nil {
 		return err
@@ -127,6 +143,37 @@ func (o *EditConfigOptions) Run() error {
 	return nil
 }
 
+// EditCAFile saves the custom CA bundle trusted by the HTTP clients of jx
+func (o *EditConfigOptions) EditCAFile() error {
+	caFile := o.CAFile
+	if caFile != "" {
+		var err error
+		caFile, err = filepath.Abs(caFile)
+		if err != nil {
+			return err
+		}
+		_, err = util.LoadCertPool(caFile)
+		if err != nil {
+			return util.InvalidOptionError("ca-file", o.CAFile, err)
+		}
+	}
+	httpConfig, err := util.LoadHTTPConfig()
+	if err != nil {
+		return err
+	}
+	httpConfig.CAFile = caFile
+	err = util.SaveHTTPConfig(httpConfig)
+	if err != nil {
+		return err
+	}
+	if caFile == "" {
+		log.Logger().Infof("Removed the custom certificate authorities")
+		return nil
+	}
+	log.Logger().Infof("HTTP requests now trust the certificate authorities in %s", util.ColorInfo(caFile))
+	return nil
+}
+
 func (o *EditConfigOptions) EditIssueTracker(pc *config.ProjectConfig) (bool, error) {
 	answer := false
 	if pc.IssueTracker == nil {
@@ -0,0 +1,259 @@
+package edit
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/pkg/environments"
+	"github.com/jenkins-x/jx/pkg/gits"
+	"github.com/jenkins-x/jx/pkg/helm"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/helm/pkg/proto/hapi/chart"
+)
+
+const (
+	// valuesSchemaFileName the file name of the JSON schema of the values of a chart
+	valuesSchemaFileName = "values.schema.json"
+)
+
+var (
+	editEnvVarsLong = templates.LongDesc(`
+		Edits the environment variables of an application in an environment via GitOps.
+
+		The environment variables are stored in the 'env' values of the application in the git repository of the environment and a Pull Request is created with the change.
+
+		Secret values are written to the secret backend of the team, such as Vault, and only a reference to the secret is stored in the git repository.
+
+		The values of the application are validated against the 'values.schema.json' file of its chart if it has one.
+`)
+
+	editEnvVarsExample = templates.Examples(`
+		# Set environment variables of myapp in staging
+		jx edit envvars --app myapp --env staging LOG_LEVEL=debug FEATURE_X=true
+
+		# Store a secret environment variable in the secret backend
+		jx edit envvars --app myapp --env production --secret DATABASE_PASSWORD=s3cr3t
+
+		# Remove an environment variable
+		jx edit envvars --app myapp --env staging --unset FEATURE_X
+	`)
+)
+
+// EditEnvVarsOptions the options for the edit envvars command
+type EditEnvVarsOptions struct {
+	EditOptions
+
+	Application string
+	Environment string
+	Alias       string
+	Secrets     []string
+	Unset       []string
+	NoValidate  bool
+	AutoMerge   bool
+}
+
+// NewCmdEditEnvVars creates a command object for the "edit envvars" command
+func NewCmdEditEnvVars(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &EditEnvVarsOptions{
+		EditOptions: EditOptions{
+			CommonOptions: commonOpts,
+		},
+	}
+
+	cmd := &cobra.Command{
+		Use:     "envvars [KEY=value]...",
+		Short:   "Edits the environment variables of an application in an environment via a Pull Request",
+		Aliases: []string{"env-vars", "envvar"},
+		Long:    editEnvVarsLong,
+		Example: editEnvVarsExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.Flags().StringVarP(&options.Application, opts.OptionApplication, "a", "", "The application to edit")
+	cmd.Flags().StringVarP(&options.Environment, opts.OptionEnvironment, "e", "", "The environment to edit")
+	cmd.Flags().StringVarP(&options.Alias, "alias", "", "", "The alias of the application in the 'requirements.yaml' file of the environment")
+	cmd.Flags().StringArrayVarP(&options.Secrets, "secret", "s", nil, "A secret environment variable of the form KEY=value which is stored in the secret backend")
+	cmd.Flags().StringArrayVarP(&options.Unset, "unset", "u", nil, "The name of an environment variable to remove")
+	cmd.Flags().BoolVarP(&options.NoValidate, "no-validate", "", false, "Disables the validation of the values against the values schema of the chart")
+	cmd.Flags().BoolVarP(&options.AutoMerge, "auto-merge", "", false, "Labels the Pull Request so that it is merged automatically once its checks pass")
+	return cmd
+}
+
+// Run implements the command
+func (o *EditEnvVarsOptions) Run() error {
+	if o.Application == "" {
+		return util.MissingOption(opts.OptionApplication)
+	}
+	if o.Environment == "" {
+		return util.MissingOption(opts.OptionEnvironment)
+	}
+	envVars, err := environments.ParseEnvVars(o.Args)
+	if err != nil {
+		return err
+	}
+	secretVars, err := environments.ParseEnvVars(o.Secrets)
+	if err != nil {
+		return util.InvalidOptionError("secret", strings.Join(o.Secrets, " "), err)
+	}
+	for _, name := range o.Unset {
+		err = environments.ValidateEnvVarName(name)
+		if err != nil {
+			return util.InvalidOptionError("unset", name, err)
+		}
+	}
+	if len(envVars) == 0 && len(secretVars) == 0 && len(o.Unset) == 0 {
+		return fmt.Errorf("no environment variables specified. Specify them as KEY=value arguments, --secret or --unset options")
+	}
+
+	jxClient, ns, err := o.JXClientAndDevNamespace()
+	if err != nil {
+		return err
+	}
+	env, err := jxClient.JenkinsV1().Environments(ns).Get(o.Environment, metav1.GetOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "failed to find environment %s in namespace %s", o.Environment, ns)
+	}
+	if env.Spec.Source.URL == "" {
+		return fmt.Errorf("environment %s has no git repository so is not managed via GitOps", env.Name)
+	}
+
+	if len(secretVars) > 0 {
+		references, err := o.storeSecrets(env, secretVars)
+		if err != nil {
+			return err
+		}
+		for name, reference := range references {
+			envVars[name] = reference
+		}
+	}
+	return o.createPullRequest(env, envVars)
+}
+
+// storeSecrets writes the secret environment variables to the secret backend and returns the URIs which reference
+// them from the values of the environment
+func (o *EditEnvVarsOptions) storeSecrets(env *v1.Environment, secretVars map[string]string) (map[string]string, error) {
+	client, scheme, err := o.GetSecretURLClientAndScheme()
+	if err != nil {
+		return nil, errors.Wrap(err, "creating the client of the secret backend")
+	}
+	path := fmt.Sprintf("environments/%s/%s", env.Name, o.appKey())
+	data, err := client.Read(path)
+	if err != nil || data == nil {
+		data = map[string]interface{}{}
+	}
+	references := map[string]string{}
+	for name, value := range secretVars {
+		data[name] = value
+		references[name] = scheme + path + ":" + name
+	}
+	_, err = client.Write(path, data)
+	if err != nil {
+		return nil, errors.Wrapf(err, "writing the secrets to %s", path)
+	}
+	log.Logger().Infof("Stored the secret environment variables in %s", util.ColorInfo(scheme+path))
+	return references, nil
+}
+
+func (o *EditEnvVarsOptions) createPullRequest(env *v1.Environment, envVars map[string]string) error {
+	app := o.Application
+	names := []string{}
+	for name := range envVars {
+		names = append(names, name)
+	}
+	names = append(names, o.Unset...)
+	sort.Strings(names)
+	branchSuffix, err := util.RandStringBytesMaskImprSrc(5)
+	if err != nil {
+		return err
+	}
+	details := gits.PullRequestDetails{
+		BranchName: "env-vars-" + app + "-" + strings.ToLower(branchSuffix),
+		Title:      fmt.Sprintf("chore: edit environment variables of %s", app),
+		Message:    fmt.Sprintf("chore: edit the environment variables %s of %s in %s", strings.Join(names, ", "), app, env.Name),
+	}
+
+	modifyChartFn := func(requirements *helm.Requirements, metadata *chart.Metadata, values map[string]interface{},
+		templates map[string]string, dir string, details *gits.PullRequestDetails) error {
+		key := o.appKey()
+		dep := environments.FindAppDependency(requirements, key)
+		if dep == nil {
+			return fmt.Errorf("application %s is not deployed in environment %s", key, env.Name)
+		}
+		appValues := environments.ModifyAppEnvVars(values, key, envVars, o.Unset)
+		if !o.NoValidate {
+			err := o.validateValues(dep, appValues)
+			if err != nil {
+				return err
+			}
+		}
+		return helm.SaveFile(filepath.Join(dir, helm.ValuesFileName), values)
+	}
+
+	gitProvider, _, err := o.CreateGitProviderForURLWithoutKind(env.Spec.Source.URL)
+	if err != nil {
+		return errors.Wrapf(err, "creating git provider for %s", env.Spec.Source.URL)
+	}
+	environmentsDir, err := o.EnvironmentsDir()
+	if err != nil {
+		return errors.Wrapf(err, "getting environments dir")
+	}
+	options := environments.EnvironmentPullRequestOptions{
+		Gitter:        o.Git(),
+		ModifyChartFn: modifyChartFn,
+		GitProvider:   gitProvider,
+	}
+	info, err := options.Create(env, environmentsDir, &details, nil, "", o.AutoMerge)
+	if err != nil {
+		return errors.Wrapf(err, "creating the Pull Request on %s", env.Spec.Source.URL)
+	}
+	if info != nil && info.PullRequest != nil {
+		log.Logger().Infof("Created Pull Request %s to edit the environment variables of %s in %s", util.ColorInfo(info.PullRequest.URL), util.ColorInfo(app), util.ColorInfo(env.Name))
+	}
+	return nil
+}
+
+// validateValues validates the values of the application against the values schema of its chart if it has one
+func (o *EditEnvVarsOptions) validateValues(dep *helm.Dependency, appValues map[string]interface{}) error {
+	return helm.InspectChart(dep.Name, dep.Version, dep.Repository, "", "", o.Helm(), func(dir string) error {
+		schemaFile := filepath.Join(dir, valuesSchemaFileName)
+		exists, err := util.FileExists(schemaFile)
+		if err != nil {
+			return errors.Wrapf(err, "checking if %s exists", schemaFile)
+		}
+		if !exists {
+			log.Logger().Debugf("chart %s %s has no %s so not validating the values", dep.Name, dep.Version, valuesSchemaFileName)
+			return nil
+		}
+		schema, err := ioutil.ReadFile(schemaFile)
+		if err != nil {
+			return errors.Wrapf(err, "reading %s", schemaFile)
+		}
+		err = environments.ValidateValuesSchema(schema, appValues)
+		if err != nil {
+			return errors.Wrapf(err, "validating the values of %s against the schema of chart %s %s", o.appKey(), dep.Name, dep.Version)
+		}
+		return nil
+	})
+}
+
+func (o *EditEnvVarsOptions) appKey() string {
+	if o.Alias != "" {
+		return o.Alias
+	}
+	return o.Application
+}
@@ -0,0 +1,130 @@
+package edit
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/spf13/cobra"
+)
+
+var (
+	editPipelineConcurrencyLong = templates.LongDesc(`
+		Edits the pipeline concurrency limits of your team.
+
+		When a limit is reached new pipelines are queued until the running pipelines complete. The queued pipelines
+		start in order of priority then first in first out. Release pipelines have a priority of %d by default and
+		the other kinds of pipeline a priority of 0.
+
+		With preemption enabled the running pipelines with a lower priority are cancelled so that a queued pipeline
+		with a higher priority can start.
+
+		Use 'jx get pipeline-queue' to view the queued pipelines.
+`)
+
+	editPipelineConcurrencyExample = templates.Examples(`
+		# Run at most 20 pipelines in the cluster and 3 pipelines per repository
+		jx edit pipeline-concurrency --max-runs 20 --max-runs-per-repo 3
+
+		# Let release pipelines cancel running pull request pipelines when the limits are reached
+		jx edit pipeline-concurrency --priority release=100 --priority pullrequest=0 --preempt
+
+		# Remove the limits
+		jx edit pipeline-concurrency --clear
+	`)
+)
+
+// EditPipelineConcurrencyOptions the options for the edit pipeline-concurrency command
+type EditPipelineConcurrencyOptions struct {
+	EditOptions
+
+	MaxRuns              int
+	MaxRunsPerRepository int
+	Priorities           []string
+	Preempt              bool
+	Clear                bool
+}
+
+// NewCmdEditPipelineConcurrency creates a command object for the "edit pipeline-concurrency" command
+func NewCmdEditPipelineConcurrency(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &EditPipelineConcurrencyOptions{
+		EditOptions: EditOptions{
+			CommonOptions: commonOpts,
+		},
+	}
+
+	cmd := &cobra.Command{
+		Use:     "pipeline-concurrency",
+		Short:   "Edits the pipeline concurrency limits of your team",
+		Aliases: []string{"concurrency"},
+		Long:    fmt.Sprintf(editPipelineConcurrencyLong, v1.DefaultReleasePipelinePriority),
+		Example: editPipelineConcurrencyExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.Flags().IntVarP(&options.MaxRuns, "max-runs", "", -1, "The maximum number of pipelines running in the cluster. Use 0 for unlimited")
+	cmd.Flags().IntVarP(&options.MaxRunsPerRepository, "max-runs-per-repo", "", -1, "The maximum number of pipelines running for a repository. Use 0 for unlimited")
+	cmd.Flags().StringArrayVarP(&options.Priorities, "priority", "", nil, "The priority of a kind of pipeline in the form kind=priority such as release=100")
+	cmd.Flags().BoolVarP(&options.Preempt, "preempt", "", false, "Cancels running pipelines with a lower priority to start queued pipelines with a higher priority. Use --preempt=false to disable it")
+	cmd.Flags().BoolVarP(&options.Clear, "clear", "", false, "Removes the pipeline concurrency limits")
+	return cmd
+}
+
+// Run implements the command
+func (o *EditPipelineConcurrencyOptions) Run() error {
+	priorities := map[string]int{}
+	for _, text := range o.Priorities {
+		paths := strings.SplitN(text, "=", 2)
+		if len(paths) != 2 || paths[0] == "" {
+			return util.InvalidOptionf("priority", text, "the priority should be in the form kind=priority")
+		}
+		priority, err := strconv.Atoi(paths[1])
+		if err != nil {
+			return util.InvalidOptionError("priority", text, err)
+		}
+		priorities[paths[0]] = priority
+	}
+
+	callback := func(env *v1.Environment) error {
+		teamSettings := &env.Spec.TeamSettings
+		if o.Clear {
+			teamSettings.PipelineConcurrency = nil
+			log.Logger().Info("Removed the pipeline concurrency limits")
+			return nil
+		}
+		concurrency := teamSettings.PipelineConcurrency
+		if concurrency == nil {
+			concurrency = &v1.PipelineConcurrency{}
+		}
+		if o.MaxRuns >= 0 {
+			concurrency.MaxRuns = o.MaxRuns
+		}
+		if o.MaxRunsPerRepository >= 0 {
+			concurrency.MaxRunsPerRepository = o.MaxRunsPerRepository
+		}
+		for kind, priority := range priorities {
+			if concurrency.Priorities == nil {
+				concurrency.Priorities = map[string]int{}
+			}
+			concurrency.Priorities[kind] = priority
+		}
+		if o.Cmd != nil && o.Cmd.Flags().Changed("preempt") {
+			concurrency.Preempt = o.Preempt
+		}
+		teamSettings.PipelineConcurrency = concurrency
+		log.Logger().Infof("Set the pipeline concurrency limits to %s pipelines in the cluster and %s pipelines per repository",
+			util.ColorInfo(concurrency.MaxRuns), util.ColorInfo(concurrency.MaxRunsPerRepository))
+		return nil
+	}
+	return o.ModifyDevEnvironment(callback)
+}
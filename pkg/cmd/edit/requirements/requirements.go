@@ -34,7 +34,7 @@ type RequirementsOptions struct {
 // RequirementBools for the boolean flags we only update if specified on the CLI
 type RequirementBools struct {
 	AutoUpgrade, EnvironmentGitPublic, GitOps, Kaniko, Terraform bool
-	VaultRecreateBucket, VaultDisableURLDiscover                 bool
+	Observability, VaultRecreateBucket, VaultDisableURLDiscover  bool
 }
 
 var (
@@ -78,6 +78,7 @@ func NewCmdEditRequirements(commonOpts *opts.CommonOptions) *cobra.Command {
 	cmd.Flags().BoolVarP(&options.Flags.EnvironmentGitPublic, "env-git-public", "", false, "enables or disables whether the environment repositories should be public")
 	cmd.Flags().BoolVarP(&options.Flags.GitOps, "gitops", "g", false, "enables or disables the use of gitops")
 	cmd.Flags().BoolVarP(&options.Flags.Kaniko, "kaniko", "", false, "enables or disables the use of kaniko")
+	cmd.Flags().BoolVarP(&options.Flags.Observability, "observability", "", false, "enables or disables the Prometheus, Grafana and Loki observability stack")
 	cmd.Flags().BoolVarP(&options.Flags.Terraform, "terraform", "", false, "enables or disables the use of terraform")
 	cmd.Flags().BoolVarP(&options.Flags.VaultRecreateBucket, "vault-recreate-bucket", "", false, "enables or disables whether to rereate the secret bucket on boot")
 	cmd.Flags().BoolVarP(&options.Flags.VaultDisableURLDiscover, "vault-disable-url-discover", "", false, "override the default lookup of the Vault URL, could be incluster service or external ingress")
@@ -188,6 +189,9 @@ func (o *RequirementsOptions) applyDefaults() error {
 	if o.Cmd.Flag("kaniko").Changed {
 		r.Kaniko = o.Flags.Kaniko
 	}
+	if o.Cmd.Flag("observability").Changed {
+		r.Observability = o.Flags.Observability
+	}
 	if o.Cmd.Flag("terraform").Changed {
 		r.Terraform = o.Flags.Terraform
 	}
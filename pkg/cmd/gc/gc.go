@@ -20,9 +20,12 @@ const (
 	valid_gc_resources = `Valid resource types include:
 
     * activities
+	* branches
+	* devpods
 	* helm
 	* previews
 	* releases
+	* resources
     `
 )
 
@@ -36,10 +39,13 @@ var (
 
 	gc_example = templates.Examples(`
 		jx gc activities
+		jx gc branches
+		jx gc devpods
 		jx gc gke
 		jx gc helm
 		jx gc previews
 		jx gc releases
+		jx gc resources
 
 	`)
 )
@@ -65,11 +71,14 @@ func NewCmdGC(commonOpts *opts.CommonOptions) *cobra.Command {
 	}
 
 	cmd.AddCommand(NewCmdGCActivities(commonOpts))
+	cmd.AddCommand(NewCmdGCBranches(commonOpts))
 	cmd.AddCommand(NewCmdGCPreviews(commonOpts))
+	cmd.AddCommand(NewCmdGCDevPods(commonOpts))
 	cmd.AddCommand(NewCmdGCGKE(commonOpts))
 	cmd.AddCommand(NewCmdGCHelm(commonOpts))
 	cmd.AddCommand(NewCmdGCPods(commonOpts))
 	cmd.AddCommand(NewCmdGCReleases(commonOpts))
+	cmd.AddCommand(NewCmdGCResources(commonOpts))
 
 	return cmd
 }
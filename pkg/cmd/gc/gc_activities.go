@@ -6,8 +6,12 @@ import (
 	"time"
 
 	gojenkins "github.com/jenkins-x/golang-jenkins"
+	"github.com/jenkins-x/jx/pkg/activities"
 	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
 	"github.com/jenkins-x/jx/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/pkg/config"
+	"github.com/jenkins-x/jx/pkg/kube"
+	"github.com/pkg/errors"
 
 	jv1 "github.com/jenkins-x/jx/pkg/client/clientset/versioned/typed/jenkins.io/v1"
 	"github.com/jenkins-x/jx/pkg/util"
@@ -32,6 +36,7 @@ type GCActivitiesOptions struct {
 	ReleaseAgeLimit         time.Duration
 	PullRequestAgeLimit     time.Duration
 	PipelineRunAgeLimit     time.Duration
+	Archive                 bool
 	jclient                 gojenkins.JenkinsClient
 }
 
@@ -39,6 +44,11 @@ var (
 	GCActivitiesLong = templates.LongDesc(`
 		Garbage collect the Jenkins X PipelineActivity and PipelineRun resources
 
+		The retention policy of the activities defaults to the 'activityRetention' section of the requirements of the
+		team. Any flags specified on the command line override the requirements.
+
+		If archiving is enabled the pruned activities are written as JSON lines files to the bucket of the 'activities'
+		storage location before they are deleted. Use 'jx get activities --archived' to include them in the history.
 `)
 
 	GCActivitiesExample = templates.Examples(`
@@ -102,6 +112,7 @@ func NewCmdGCActivities(commonOpts *opts.CommonOptions) *cobra.Command {
 	cmd.Flags().DurationVarP(&options.PullRequestAgeLimit, "pull-request-age", "p", time.Hour*48, "Maximum age to keep PipelineActivities for Pull Requests")
 	cmd.Flags().DurationVarP(&options.ReleaseAgeLimit, "release-age", "r", time.Hour*24*30, "Maximum age to keep PipelineActivities for Releases")
 	cmd.Flags().DurationVarP(&options.PipelineRunAgeLimit, "pipelinerun-age", "", time.Hour*2, "Maximum age to keep completed PipelineRuns for all pipelines")
+	cmd.Flags().BoolVarP(&options.Archive, "archive", "", false, "Archives the pruned PipelineActivities to the bucket of the activities storage location before deleting them")
 	return cmd
 }
 
@@ -148,8 +159,14 @@ func (o *GCActivitiesOptions) Run() error {
 		}
 	}
 
+	err = o.applyRetentionPolicy()
+	if err != nil {
+		return err
+	}
+
 	now := time.Now()
 	counters := &buildsCount{}
+	var pruned []v1.PipelineActivity
 
 	var completedActivities []v1.PipelineActivity
 
@@ -172,20 +189,14 @@ func (o *GCActivitiesOptions) Run() error {
 		maxAge, revisionHistory := o.ageAndHistoryLimits(isPR, isBatch)
 		// lets remove activities that are too old
 		if a.Spec.CompletedTimestamp != nil && a.Spec.CompletedTimestamp.Add(maxAge).Before(now) {
-			err = o.deleteActivity(activityInterface, &a)
-			if err != nil {
-				return err
-			}
+			pruned = append(pruned, a)
 			continue
 		}
 
 		repoBranchAndContext := a.RepositoryOwner() + "/" + a.RepositoryName() + "/" + a.BranchName() + "/" + a.Spec.Context
 		c := counters.AddBuild(repoBranchAndContext, isPR)
 		if c > revisionHistory && a.Spec.CompletedTimestamp != nil {
-			err = o.deleteActivity(activityInterface, &a)
-			if err != nil {
-				return err
-			}
+			pruned = append(pruned, a)
 			continue
 		}
 
@@ -199,14 +210,24 @@ func (o *GCActivitiesOptions) Run() error {
 				}
 			}
 			if !matched {
-				err = o.deleteActivity(activityInterface, &a)
-				if err != nil {
-					return err
-				}
+				pruned = append(pruned, a)
 			}
 		}
 	}
 
+	if o.Archive && len(pruned) > 0 {
+		err = o.archiveActivities(pruned, now)
+		if err != nil {
+			return err
+		}
+	}
+	for i := range pruned {
+		err = o.deleteActivity(activityInterface, &pruned[i])
+		if err != nil {
+			return err
+		}
+	}
+
 	// Clean up completed PipelineRuns
 	err = o.gcPipelineRuns(currentNs)
 	if err != nil {
@@ -216,6 +237,73 @@ func (o *GCActivitiesOptions) Run() error {
 	return nil
 }
 
+// applyRetentionPolicy defaults the limits which were not specified on the command line from the activity
+// retention policy in the requirements of the team
+func (o *GCActivitiesOptions) applyRetentionPolicy() error {
+	teamSettings, err := o.TeamSettings()
+	if err != nil {
+		return errors.Wrap(err, "loading the team settings")
+	}
+	requirements, err := config.GetRequirementsConfigFromTeamSettings(teamSettings)
+	if err != nil {
+		return errors.Wrap(err, "getting the requirements from the team settings")
+	}
+	if requirements == nil || requirements.ActivityRetention == nil {
+		return nil
+	}
+	retention := requirements.ActivityRetention
+	maxAge, err := retention.MaxAgeDuration()
+	if err != nil {
+		return err
+	}
+	prMaxAge, err := retention.PullRequestMaxAgeDuration()
+	if err != nil {
+		return err
+	}
+	if retention.KeepLastN > 0 && !o.flagChanged("release-history-limit") {
+		o.ReleaseHistoryLimit = retention.KeepLastN
+	}
+	if maxAge > 0 && !o.flagChanged("release-age") {
+		o.ReleaseAgeLimit = maxAge
+	}
+	if retention.PullRequestKeepLastN > 0 && !o.flagChanged("pr-history-limit") {
+		o.PullRequestHistoryLimit = retention.PullRequestKeepLastN
+	}
+	if prMaxAge > 0 && !o.flagChanged("pull-request-age") {
+		o.PullRequestAgeLimit = prMaxAge
+	}
+	if retention.Archive && !o.flagChanged("archive") {
+		o.Archive = true
+	}
+	return nil
+}
+
+func (o *GCActivitiesOptions) flagChanged(name string) bool {
+	return o.Cmd != nil && o.Cmd.Flags().Changed(name)
+}
+
+// archiveActivities writes the activities to the bucket of the activities storage location
+func (o *GCActivitiesOptions) archiveActivities(pruned []v1.PipelineActivity, now time.Time) error {
+	teamSettings, err := o.TeamSettings()
+	if err != nil {
+		return errors.Wrap(err, "loading the team settings")
+	}
+	location := teamSettings.StorageLocationOrDefault(kube.ClassificationActivities)
+	if location.BucketURL == "" {
+		return errors.Errorf("cannot archive the activities as no bucket URL is configured for the %s storage location. Use 'jx edit storage -c %s --bucket-url ...'", kube.ClassificationActivities, kube.ClassificationActivities)
+	}
+	if o.DryRun {
+		log.Logger().Infof("not archiving %d PipelineActivities to %s", len(pruned), util.ColorInfo(location.BucketURL))
+		return nil
+	}
+	key, err := activities.NewArchive(location.BucketURL).Write(pruned, now)
+	if err != nil {
+		return err
+	}
+	log.Logger().Infof("archived %d PipelineActivities to %s", len(pruned), util.ColorInfo(util.UrlJoin(location.BucketURL, key)))
+	return nil
+}
+
 func (o *GCActivitiesOptions) deleteActivity(activityInterface jv1.PipelineActivityInterface, a *v1.PipelineActivity) error {
 	prefix := ""
 	if o.DryRun {
@@ -4,14 +4,17 @@ import (
 	"testing"
 	"time"
 
+	"github.com/ghodss/yaml"
 	jenkinsv1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
 	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
 	"github.com/jenkins-x/jx/pkg/cmd/clients/fake"
 	"github.com/jenkins-x/jx/pkg/cmd/testhelpers"
+	"github.com/jenkins-x/jx/pkg/config"
 	tektonv1alpha1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/jenkins-x/jx/pkg/cmd/opts"
 )
@@ -193,3 +196,42 @@ func TestGCPipelineActivitiesWithBatchAndPRBuilds(t *testing.T) {
 	assert.NotNil(t, remainingRun.Status.CompletionTime)
 	assert.Equal(t, nowMinusOneHour, remainingRun.Status.CompletionTime.Time, "Expected completion time for remaining PipelineRun of %s, but is %s", nowMinusOneHour, remainingRun.Status.CompletionTime.Time)
 }
+
+func TestGCActivitiesRetentionPolicyFromRequirements(t *testing.T) {
+	t.Parallel()
+
+	commonOpts := opts.NewCommonOptionsWithFactory(fake.NewFakeFactory())
+	options := &commonOpts
+	testhelpers.ConfigureTestOptions(options, options.Git(), options.Helm())
+
+	requirements := config.NewRequirementsConfig()
+	requirements.ActivityRetention = &config.ActivityRetentionConfig{
+		KeepLastN:         10,
+		MaxAge:            "720h",
+		PullRequestMaxAge: "24h",
+		Archive:           true,
+	}
+	data, err := yaml.Marshal(requirements)
+	require.NoError(t, err)
+	err = options.ModifyDevEnvironment(func(env *v1.Environment) error {
+		env.Spec.TeamSettings.BootRequirements = string(data)
+		return nil
+	})
+	require.NoError(t, err)
+
+	o := &GCActivitiesOptions{
+		CommonOptions:           options,
+		PullRequestAgeLimit:     time.Hour * 48,
+		ReleaseAgeLimit:         time.Hour * 24 * 30,
+		ReleaseHistoryLimit:     5,
+		PullRequestHistoryLimit: 2,
+	}
+	err = o.applyRetentionPolicy()
+	require.NoError(t, err)
+
+	assert.Equal(t, 10, o.ReleaseHistoryLimit)
+	assert.Equal(t, time.Hour*720, o.ReleaseAgeLimit)
+	assert.Equal(t, 2, o.PullRequestHistoryLimit)
+	assert.Equal(t, time.Hour*24, o.PullRequestAgeLimit)
+	assert.True(t, o.Archive)
+}
@@ -0,0 +1,146 @@
+package gc
+
+import (
+	"io/ioutil"
+	"os"
+	"regexp"
+
+	"github.com/jenkins-x/jx/pkg/boot"
+	"github.com/jenkins-x/jx/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/pkg/gits"
+	"github.com/jenkins-x/jx/pkg/kube"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// GCBranchesOptions contains the CLI options
+type GCBranchesOptions struct {
+	*opts.CommonOptions
+
+	GitURL string
+	DryRun bool
+}
+
+var (
+	gcBranchesLong = templates.LongDesc(`
+		Garbage collect the temporary branches left in the dev environment repository by failed runs
+
+		Branches named by a UUID are temporary branches which are never used by pull requests. The boot upgrade branch
+		is deleted if it has no open pull request.
+`)
+
+	gcBranchesExample = templates.Examples(`
+		# garbage collect the temporary branches of the dev environment repository
+		jx gc branches
+
+		# only list the temporary branches of a repository which would be deleted
+		jx gc branches --git-url https://github.com/myorg/environment-mycluster-dev.git --dry-run
+`)
+
+	uuidBranchRegex = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)
+)
+
+// NewCmdGCBranches creates the command object
+func NewCmdGCBranches(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &GCBranchesOptions{
+		CommonOptions: commonOpts,
+	}
+
+	cmd := &cobra.Command{
+		Use:     "branches",
+		Short:   "garbage collection for temporary git branches",
+		Aliases: []string{"branch"},
+		Long:    gcBranchesLong,
+		Example: gcBranchesExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.Flags().StringVarP(&options.GitURL, "git-url", "u", "", "The git URL of the repository. Defaults to the dev environment repository")
+	cmd.Flags().BoolVarP(&options.DryRun, "dry-run", "d", false, "Dry run mode. If enabled just list the branches that would be removed")
+	return cmd
+}
+
+// Run implements this command
+func (o *GCBranchesOptions) Run() error {
+	gitURL := o.GitURL
+	if gitURL == "" {
+		jxClient, ns, err := o.JXClientAndDevNamespace()
+		if err != nil {
+			return err
+		}
+		devEnv, err := kube.GetDevEnvironment(jxClient, ns)
+		if err != nil {
+			return errors.Wrapf(err, "finding the dev environment in namespace %s", ns)
+		}
+		if devEnv == nil || devEnv.Spec.Source.URL == "" {
+			log.Logger().Debug("the dev environment has no git repository")
+			return nil
+		}
+		gitURL = devEnv.Spec.Source.URL
+	}
+
+	dir, err := ioutil.TempDir("", "jx-gc-branches-")
+	if err != nil {
+		return errors.Wrap(err, "creating a temporary directory")
+	}
+	defer os.RemoveAll(dir)
+
+	provider, gitInfo, err := o.CreateGitProviderForURLWithoutKind(gitURL)
+	if err != nil {
+		return errors.Wrapf(err, "creating the git provider for %s", gitURL)
+	}
+	userAuth := provider.UserAuth()
+	cloneURL, err := o.Git().CreateAuthenticatedURL(gitURL, &userAuth)
+	if err != nil {
+		return errors.Wrapf(err, "creating the authenticated URL for %s", gitURL)
+	}
+	err = o.Git().Clone(cloneURL, dir)
+	if err != nil {
+		return errors.Wrapf(err, "cloning %s", gitURL)
+	}
+	branches, err := o.Git().RemoteBranchNames(dir, "remotes/origin/")
+	if err != nil {
+		return errors.Wrapf(err, "listing the branches of %s", gitURL)
+	}
+
+	openBranches := map[string]bool{}
+	prs, err := gits.FilterOpenPullRequests(provider, gitInfo.Organisation, gitInfo.Name, gits.PullRequestFilter{
+		Labels: []string{boot.PullRequestLabel},
+	})
+	if err != nil {
+		return errors.Wrapf(err, "listing the open pull requests of %s", gitURL)
+	}
+	for _, pr := range prs {
+		openBranches[util.DereferenceString(pr.HeadRef)] = true
+	}
+
+	for _, branch := range branches {
+		if !IsTemporaryBranch(branch) || openBranches[branch] {
+			continue
+		}
+		if o.DryRun {
+			log.Logger().Infof("not deleting branch %s of %s", util.ColorInfo(branch), gitURL)
+			continue
+		}
+		log.Logger().Infof("deleting branch %s of %s", util.ColorInfo(branch), gitURL)
+		err = o.Git().DeleteRemoteBranch(dir, "origin", branch)
+		if err != nil {
+			return errors.Wrapf(err, "deleting branch %s of %s", branch, gitURL)
+		}
+	}
+	return nil
+}
+
+// IsTemporaryBranch returns true if the branch is a temporary branch created by jx such as a UUID branch or the boot
+// upgrade branch
+func IsTemporaryBranch(branch string) bool {
+	return uuidBranchRegex.MatchString(branch) || branch == boot.UpgradeBranchName
+}
@@ -0,0 +1,123 @@
+package gc
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jenkins-x/jx/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/pkg/kube"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// GCDevPodsOptions containers the CLI options
+type GCDevPodsOptions struct {
+	*opts.CommonOptions
+
+	Namespace string
+	DryRun    bool
+}
+
+var (
+	gcDevPodsLong = templates.LongDesc(`
+		Garbage collect DevPods which have been idle for longer than their idle timeout
+
+		A DevPod is idle if no jx command has used it and no files in its working directory have been modified
+		within its idle timeout. DevPods created without an idle timeout are never deleted.
+`)
+
+	gcDevPodsExample = templates.Examples(`
+		# garbage collect idle DevPods
+		jx gc devpods
+
+		# lists the idle DevPods which would be garbage collected
+		jx gc devpods --dry-run
+`)
+)
+
+// NewCmdGCDevPods creates the command object
+func NewCmdGCDevPods(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &GCDevPodsOptions{
+		CommonOptions: commonOpts,
+	}
+
+	cmd := &cobra.Command{
+		Use:     "devpods",
+		Short:   "garbage collection for idle DevPods",
+		Aliases: []string{"devpod"},
+		Long:    gcDevPodsLong,
+		Example: gcDevPodsExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.Flags().StringVarP(&options.Namespace, "namespace", "n", "", "The namespace to look for the DevPods. Defaults to the dev namespace")
+	cmd.Flags().BoolVarP(&options.DryRun, "dry-run", "", false, "Only log the idle DevPods rather than deleting them")
+	return cmd
+}
+
+// Run implements this command
+func (o *GCDevPodsOptions) Run() error {
+	kubeClient, ns, err := o.KubeClientAndDevNamespace()
+	if err != nil {
+		return err
+	}
+	if o.Namespace != "" {
+		ns = o.Namespace
+	}
+
+	_, pods, err := kube.GetDevPodNames(kubeClient, ns, "")
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	errors := []error{}
+	for name, pod := range pods {
+		idle, timeout := kube.DevPodIdleTime(pod, now)
+		if timeout <= 0 || idle < timeout {
+			continue
+		}
+		if o.hasRecentFileChanges(ns, pod, timeout) {
+			continue
+		}
+		idleText := strings.TrimSuffix(idle.Round(time.Minute).String(), "0s")
+		if o.DryRun {
+			log.Logger().Infof("DevPod %s in namespace %s has been idle for %s", util.ColorInfo(name), ns, idleText)
+			continue
+		}
+		err := kubeClient.CoreV1().Pods(ns).Delete(name, &metav1.DeleteOptions{})
+		if err != nil {
+			log.Logger().Warnf("Failed to delete DevPod %s in namespace %s: %s", name, ns, err)
+			errors = append(errors, err)
+		} else {
+			log.Logger().Infof("Deleted DevPod %s in namespace %s as it has been idle for %s", util.ColorInfo(name), ns, idleText)
+		}
+	}
+	return util.CombineErrors(errors...)
+}
+
+// hasRecentFileChanges returns true if any files in the working directory of the DevPod were modified within the
+// timeout, such as by a remote IDE
+func (o *GCDevPodsOptions) hasRecentFileChanges(ns string, pod *corev1.Pod, timeout time.Duration) bool {
+	workingDir := pod.Annotations[kube.AnnotationWorkingDir]
+	if workingDir == "" || pod.Status.Phase != corev1.PodRunning {
+		return false
+	}
+	script := fmt.Sprintf("find %s -mmin -%d -print 2>/dev/null | head -1", workingDir, int(timeout.Minutes()))
+	out, err := o.GetCommandOutput("", "kubectl", "exec", "-n", ns, pod.Name, "--", "/bin/sh", "-c", script)
+	if err != nil {
+		log.Logger().Debugf("failed to check for file changes in DevPod %s: %s", pod.Name, err.Error())
+		return false
+	}
+	return strings.TrimSpace(out) != ""
+}
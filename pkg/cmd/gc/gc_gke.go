@@ -116,7 +116,6 @@ func NewCmdGCGKE(commonOpts *opts.CommonOptions) *cobra.Command {
 		},
 	}
 
-	options.CommonOptions.AddBaseFlags(cmd)
 	options.addFlags(cmd)
 
 	return cmd
@@ -15,12 +15,17 @@ import (
 	"strconv"
 
 	"strings"
+	"time"
 
 	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
 	"github.com/jenkins-x/jx/pkg/cmd/opts"
 	"github.com/jenkins-x/jx/pkg/cmd/templates"
 	"github.com/jenkins-x/jx/pkg/gits"
+	"github.com/jenkins-x/jx/pkg/kube"
 	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
 )
 
 // GetOptions is the start of the data required to perform the operation.  As new fields are added, add them here instead of
@@ -28,8 +33,10 @@ import (
 type GCPreviewsOptions struct {
 	*opts.CommonOptions
 
-	DisableImport bool
-	OutDir        string
+	DisableImport        bool
+	OutDir               string
+	OrphanedNamespaceAge time.Duration
+	DryRun               bool
 }
 
 var (
@@ -37,11 +44,17 @@ var (
 		Garbage collect Jenkins X preview environments.  If a pull request is merged or closed the associated preview
 		environment will be deleted.
 
+		Preview namespaces whose preview environment no longer exists, such as when the deletion of a preview failed
+		part way through, are deleted once they are older than the orphaned namespace age.
+
 `)
 
 	GCPreviewsExample = templates.Examples(`
 		jx garbage collect previews
 		jx gc previews
+
+		# only list the orphaned preview namespaces which would be deleted
+		jx gc previews --dry-run
 `)
 )
 
@@ -63,6 +76,8 @@ func NewCmdGCPreviews(commonOpts *opts.CommonOptions) *cobra.Command {
 			helper.CheckErr(err)
 		},
 	}
+	cmd.Flags().DurationVarP(&options.OrphanedNamespaceAge, "orphaned-namespace-age", "", time.Hour, "The minimum age of a preview namespace without a preview environment before it is deleted. Zero disables deleting orphaned namespaces")
+	cmd.Flags().BoolVarP(&options.DryRun, "dry-run", "d", false, "Dry run mode. If enabled just list the orphaned namespaces that would be removed")
 	return cmd
 }
 
@@ -81,7 +96,6 @@ func (o *GCPreviewsOptions) Run() error {
 	if len(envs.Items) == 0 {
 		// no environments found so lets return gracefully
 		log.Logger().Debug("no environments found")
-		return nil
 	}
 
 	var previewFound bool
@@ -142,5 +156,64 @@ func (o *GCPreviewsOptions) Run() error {
 	if !previewFound {
 		log.Logger().Debug("no preview environments found")
 	}
+	return o.gcOrphanedNamespaces(currentNs)
+}
+
+// gcOrphanedNamespaces deletes the preview namespaces of the team whose preview environment no longer exists
+func (o *GCPreviewsOptions) gcOrphanedNamespaces(devNs string) error {
+	if o.OrphanedNamespaceAge <= 0 {
+		return nil
+	}
+	kubeClient, err := o.KubeClient()
+	if err != nil {
+		return err
+	}
+	jxClient, _, err := o.JXClient()
+	if err != nil {
+		return err
+	}
+	// lets list the environments again as previews may have been deleted
+	envs, err := jxClient.JenkinsV1().Environments(devNs).List(metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	envNames := map[string]bool{}
+	for _, e := range envs.Items {
+		envNames[e.Name] = true
+	}
+	namespaces, err := kubeClient.CoreV1().Namespaces().List(metav1.ListOptions{
+		LabelSelector: kube.LabelTeam + "=" + devNs,
+	})
+	if err != nil {
+		return errors.Wrapf(err, "listing the namespaces of team %s", devNs)
+	}
+	now := time.Now()
+	for _, n := range namespaces.Items {
+		if !IsOrphanedPreviewNamespace(&n, devNs, envNames, now, o.OrphanedNamespaceAge) {
+			continue
+		}
+		if o.DryRun {
+			log.Logger().Infof("not deleting orphaned preview namespace %s", util.ColorInfo(n.Name))
+			continue
+		}
+		log.Logger().Infof("deleting orphaned preview namespace %s", util.ColorInfo(n.Name))
+		err = kubeClient.CoreV1().Namespaces().Delete(n.Name, &metav1.DeleteOptions{})
+		if err != nil {
+			return errors.Wrapf(err, "deleting namespace %s", n.Name)
+		}
+	}
 	return nil
 }
+
+// IsOrphanedPreviewNamespace returns true if the namespace was created for a preview environment of the team which
+// no longer exists and is older than the minimum age
+func IsOrphanedPreviewNamespace(namespace *corev1.Namespace, devNs string, envNames map[string]bool, now time.Time, minAge time.Duration) bool {
+	if namespace.Name == devNs || namespace.DeletionTimestamp != nil || !strings.HasPrefix(namespace.Name, devNs+"-") {
+		return false
+	}
+	envName := namespace.Labels[kube.LabelEnvironment]
+	if envName == "" || envName == kube.LabelValueDevEnvironment || envName == kube.LabelValueThisEnvironment || envNames[envName] {
+		return false
+	}
+	return namespace.CreationTimestamp.Add(minAge).Before(now)
+}
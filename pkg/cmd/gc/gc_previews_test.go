@@ -0,0 +1,59 @@
+package gc_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jenkins-x/jx/pkg/cmd/gc"
+	"github.com/jenkins-x/jx/pkg/kube"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestIsOrphanedPreviewNamespace(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	old := now.Add(-2 * time.Hour)
+	envNames := map[string]bool{"staging": true}
+
+	testCases := []struct {
+		name     string
+		ns       string
+		env      string
+		created  time.Time
+		expected bool
+	}{
+		{"orphaned preview", "jx-myorg-myapp-pr-1", "myorg-myapp-pr-1", old, true},
+		{"existing environment", "jx-staging", "staging", old, false},
+		{"too new", "jx-myorg-myapp-pr-2", "myorg-myapp-pr-2", now, false},
+		{"dev environment", "jx-dev", kube.LabelValueDevEnvironment, old, false},
+		{"no environment label", "jx-other", "", old, false},
+		{"other namespace", "kube-system", "myorg-myapp-pr-3", old, false},
+		{"dev namespace", "jx", "myorg-myapp-pr-4", old, false},
+	}
+	for _, tc := range testCases {
+		labels := map[string]string{}
+		if tc.env != "" {
+			labels[kube.LabelEnvironment] = tc.env
+		}
+		namespace := &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              tc.ns,
+				Labels:            labels,
+				CreationTimestamp: metav1.NewTime(tc.created),
+			},
+		}
+		assert.Equal(t, tc.expected, gc.IsOrphanedPreviewNamespace(namespace, "jx", envNames, now, time.Hour), tc.name)
+	}
+}
+
+func TestIsTemporaryBranch(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, gc.IsTemporaryBranch("0f8fad5b-d9cb-469f-a165-70867728950e"))
+	assert.True(t, gc.IsTemporaryBranch("jx_boot_upgrade"))
+	assert.False(t, gc.IsTemporaryBranch("master"))
+	assert.False(t, gc.IsTemporaryBranch("feature-0f8fad5b"))
+}
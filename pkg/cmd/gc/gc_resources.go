@@ -0,0 +1,320 @@
+package gc
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jenkins-x/jx/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/pkg/kube"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/tekton"
+	"github.com/jenkins-x/jx/pkg/util"
+	knativeapis "github.com/knative/pkg/apis"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	gcKindPods         = "pods"
+	gcKindJobs         = "jobs"
+	gcKindPipelineRuns = "pipelineruns"
+)
+
+var (
+	// gcResourceKinds the kinds of resources which can be garbage collected
+	gcResourceKinds = []string{gcKindPods, gcKindJobs, gcKindPipelineRuns}
+
+	// gcDefaultSelectors select the resources created by Jenkins X when no selector is specified
+	gcDefaultSelectors = map[string]string{
+		gcKindPods:         tekton.LabelType,
+		gcKindJobs:         kube.LabelCreatedBy + "=" + kube.ValueCreatedByJX,
+		gcKindPipelineRuns: tekton.LabelType,
+	}
+)
+
+// GCResourcesOptions contains the CLI options
+type GCResourcesOptions struct {
+	*opts.CommonOptions
+
+	Selector  string
+	Namespace string
+	OlderThan time.Duration
+	Kinds     []string
+	DryRun    bool
+}
+
+// completedResource a resource which has completed so that it can be garbage collected
+type completedResource struct {
+	Name     string
+	Status   string
+	Finished time.Time
+}
+
+// resourceCollector lists the completed resources of a kind and deletes them
+type resourceCollector struct {
+	list   func(selector string) ([]completedResource, error)
+	delete func(name string) error
+}
+
+var (
+	gcResourcesLong = templates.LongDesc(`
+		Garbage collect the resources which completed a while ago such as the pods and PipelineRuns of old builds.
+
+		Only resources which have completed, such as pods which succeeded or failed, jobs which completed or failed and
+		PipelineRuns which finished, are deleted. Running resources are always kept.
+
+		If no selector is specified only the resources created by Jenkins X are deleted:
+
+		    * pods: the pods of the pipelines with the label ` + tekton.LabelType + `
+		    * jobs: the jobs with the label ` + kube.LabelCreatedBy + `=` + kube.ValueCreatedByJX + `
+		    * pipelineruns: the PipelineRuns with the label ` + tekton.LabelType + `
+`)
+
+	gcResourcesExample = templates.Examples(`
+		# list the resources which would be garbage collected
+		jx gc resources --dry-run
+
+		# garbage collect the pods and jobs which completed more than 3 days ago
+		jx gc resources --kinds pods,jobs --older-than 72h
+
+		# garbage collect the PipelineRuns of a repository
+		jx gc resources --kinds pipelineruns --selector repository=myapp
+	`)
+)
+
+// NewCmdGCResources creates the command object
+func NewCmdGCResources(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &GCResourcesOptions{
+		CommonOptions: commonOpts,
+	}
+
+	cmd := &cobra.Command{
+		Use:     "resources",
+		Short:   "garbage collection for completed pods, jobs and PipelineRuns",
+		Aliases: []string{"resource"},
+		Long:    gcResourcesLong,
+		Example: gcResourcesExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.Flags().StringVarP(&options.Selector, "selector", "s", "", "The label selector of the resources. Defaults to the resources created by Jenkins X")
+	cmd.Flags().StringVarP(&options.Namespace, "namespace", "n", "", "The namespace of the resources. Defaults to the current namespace")
+	cmd.Flags().DurationVarP(&options.OlderThan, "older-than", "a", 72*time.Hour, "The minimum time since the resources completed. Any resources which completed more recently are kept")
+	cmd.Flags().StringSliceVarP(&options.Kinds, "kinds", "k", gcResourceKinds, "The kinds of resources to garbage collect: "+strings.Join(gcResourceKinds, ", "))
+	cmd.Flags().BoolVarP(&options.DryRun, "dry-run", "d", false, "Dry run mode. If enabled just list the resources that would be removed")
+	return cmd
+}
+
+// Run implements this command
+func (o *GCResourcesOptions) Run() error {
+	for _, kind := range o.Kinds {
+		if util.StringArrayIndex(gcResourceKinds, kind) < 0 {
+			return util.InvalidOption("kinds", kind, gcResourceKinds)
+		}
+	}
+	_, ns, err := o.KubeClientAndNamespace()
+	if err != nil {
+		return err
+	}
+	if o.Namespace != "" {
+		ns = o.Namespace
+	}
+
+	errs := []error{}
+	for _, kind := range o.Kinds {
+		collector, err := o.collector(kind, ns)
+		if err != nil {
+			return err
+		}
+		selector := o.Selector
+		if selector == "" {
+			selector = gcDefaultSelectors[kind]
+		}
+		resources, err := collector.list(selector)
+		if err != nil {
+			errs = append(errs, errors.Wrapf(err, "failed to list the %s in namespace %s", kind, ns))
+			continue
+		}
+		now := time.Now()
+		count := 0
+		for _, resource := range resources {
+			age := now.Sub(resource.Finished)
+			if age <= o.OlderThan {
+				continue
+			}
+			count++
+			ageText := strings.TrimSuffix(age.Round(time.Minute).String(), "0s")
+			if o.DryRun {
+				log.Logger().Infof("Would delete %s %s in namespace %s with status %s as it completed %s ago", kind, util.ColorInfo(resource.Name), ns, resource.Status, ageText)
+				continue
+			}
+			err = collector.delete(resource.Name)
+			if err != nil {
+				log.Logger().Warnf("Failed to delete %s %s in namespace %s: %s", kind, resource.Name, ns, err)
+				errs = append(errs, err)
+				continue
+			}
+			log.Logger().Infof("Deleted %s %s in namespace %s with status %s as it completed %s ago", kind, util.ColorInfo(resource.Name), ns, resource.Status, ageText)
+		}
+		if count == 0 {
+			log.Logger().Debugf("No %s in namespace %s completed more than %s ago", kind, ns, o.OlderThan.String())
+		}
+	}
+	return util.CombineErrors(errs...)
+}
+
+// collector returns the collector of the kind of resources in the namespace
+func (o *GCResourcesOptions) collector(kind string, ns string) (*resourceCollector, error) {
+	background := metav1.DeletePropagationBackground
+	deleteOptions := &metav1.DeleteOptions{
+		PropagationPolicy: &background,
+	}
+	switch kind {
+	case gcKindPods:
+		kubeClient, err := o.KubeClient()
+		if err != nil {
+			return nil, err
+		}
+		pods := kubeClient.CoreV1().Pods(ns)
+		return &resourceCollector{
+			list: func(selector string) ([]completedResource, error) {
+				list, err := pods.List(metav1.ListOptions{LabelSelector: selector})
+				if err != nil {
+					return nil, err
+				}
+				answer := []completedResource{}
+				for i := range list.Items {
+					if resource, ok := completedPod(&list.Items[i]); ok {
+						answer = append(answer, resource)
+					}
+				}
+				return sortCompletedResources(answer), nil
+			},
+			delete: func(name string) error {
+				return pods.Delete(name, deleteOptions)
+			},
+		}, nil
+	case gcKindJobs:
+		kubeClient, err := o.KubeClient()
+		if err != nil {
+			return nil, err
+		}
+		jobs := kubeClient.BatchV1().Jobs(ns)
+		return &resourceCollector{
+			list: func(selector string) ([]completedResource, error) {
+				list, err := jobs.List(metav1.ListOptions{LabelSelector: selector})
+				if err != nil {
+					return nil, err
+				}
+				answer := []completedResource{}
+				for i := range list.Items {
+					if resource, ok := completedJob(&list.Items[i]); ok {
+						answer = append(answer, resource)
+					}
+				}
+				return sortCompletedResources(answer), nil
+			},
+			delete: func(name string) error {
+				return jobs.Delete(name, deleteOptions)
+			},
+		}, nil
+	case gcKindPipelineRuns:
+		tektonClient, _, err := o.TektonClient()
+		if err != nil {
+			return nil, err
+		}
+		pipelineRuns := tektonClient.TektonV1alpha1().PipelineRuns(ns)
+		return &resourceCollector{
+			list: func(selector string) ([]completedResource, error) {
+				list, err := pipelineRuns.List(metav1.ListOptions{LabelSelector: selector})
+				if err != nil {
+					return nil, err
+				}
+				answer := []completedResource{}
+				for i := range list.Items {
+					pr := &list.Items[i]
+					if !tekton.PipelineRunIsComplete(pr) {
+						continue
+					}
+					status := "Completed"
+					if condition := pr.Status.GetCondition(knativeapis.ConditionSucceeded); condition != nil {
+						status = condition.Reason
+					}
+					answer = append(answer, completedResource{
+						Name:     pr.Name,
+						Status:   status,
+						Finished: pr.Status.CompletionTime.Time,
+					})
+				}
+				return sortCompletedResources(answer), nil
+			},
+			delete: func(name string) error {
+				return pipelineRuns.Delete(name, deleteOptions)
+			},
+		}, nil
+	default:
+		return nil, util.InvalidOption("kinds", kind, gcResourceKinds)
+	}
+}
+
+// completedPod returns the pod as a completed resource if it succeeded or failed
+func completedPod(pod *corev1.Pod) (completedResource, bool) {
+	phase := pod.Status.Phase
+	if phase != corev1.PodSucceeded && phase != corev1.PodFailed {
+		return completedResource{}, false
+	}
+	finished := pod.CreationTimestamp.Time
+	if pod.Status.StartTime != nil {
+		finished = pod.Status.StartTime.Time
+	}
+	for _, s := range pod.Status.ContainerStatuses {
+		terminated := s.State.Terminated
+		if terminated != nil && terminated.FinishedAt.After(finished) {
+			finished = terminated.FinishedAt.Time
+		}
+	}
+	return completedResource{
+		Name:     pod.Name,
+		Status:   string(phase),
+		Finished: finished,
+	}, true
+}
+
+// completedJob returns the job as a completed resource if it completed or failed
+func completedJob(job *batchv1.Job) (completedResource, bool) {
+	if job.Status.CompletionTime != nil {
+		return completedResource{
+			Name:     job.Name,
+			Status:   "Complete",
+			Finished: job.Status.CompletionTime.Time,
+		}, true
+	}
+	for _, condition := range job.Status.Conditions {
+		if condition.Type == batchv1.JobFailed && condition.Status == corev1.ConditionTrue {
+			return completedResource{
+				Name:     job.Name,
+				Status:   "Failed",
+				Finished: condition.LastTransitionTime.Time,
+			}, true
+		}
+	}
+	return completedResource{}, false
+}
+
+// sortCompletedResources sorts the resources so that the oldest are deleted first
+func sortCompletedResources(resources []completedResource) []completedResource {
+	sort.Slice(resources, func(i, j int) bool {
+		return resources[i].Finished.Before(resources[j].Finished)
+	})
+	return resources
+}
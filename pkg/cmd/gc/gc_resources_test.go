@@ -0,0 +1,144 @@
+package gc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jenkins-x/jx/pkg/cmd/clients/fake"
+	"github.com/jenkins-x/jx/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/pkg/kube"
+	"github.com/jenkins-x/jx/pkg/tekton"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	tektonv1alpha1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func createGCResourcesTestPod(name string, labels map[string]string, phase corev1.PodPhase, finished time.Time) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Labels:            labels,
+			CreationTimestamp: metav1.Time{Time: finished.Add(-10 * time.Minute)},
+		},
+		Status: corev1.PodStatus{
+			Phase: phase,
+			ContainerStatuses: []corev1.ContainerStatus{
+				{
+					State: corev1.ContainerState{
+						Terminated: &corev1.ContainerStateTerminated{
+							FinishedAt: metav1.Time{Time: finished},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestGCResources(t *testing.T) {
+	t.Parallel()
+	commonOpts := opts.NewCommonOptionsWithFactory(fake.NewFakeFactory())
+	options := &commonOpts
+
+	kubeClient, ns, err := options.KubeClientAndNamespace()
+	require.NoError(t, err)
+	tektonClient, _, err := options.TektonClient()
+	require.NoError(t, err)
+
+	old := time.Now().Add(-96 * time.Hour)
+	recent := time.Now().Add(-time.Hour)
+	buildLabels := map[string]string{tekton.LabelType: "build"}
+	pods := []*corev1.Pod{
+		createGCResourcesTestPod("old-build", buildLabels, corev1.PodSucceeded, old),
+		createGCResourcesTestPod("old-failed-build", buildLabels, corev1.PodFailed, old),
+		createGCResourcesTestPod("recent-build", buildLabels, corev1.PodSucceeded, recent),
+		createGCResourcesTestPod("running-build", buildLabels, corev1.PodRunning, old),
+		createGCResourcesTestPod("old-app", map[string]string{"app": "myapp"}, corev1.PodSucceeded, old),
+	}
+	for _, pod := range pods {
+		_, err = kubeClient.CoreV1().Pods(ns).Create(pod)
+		require.NoError(t, err)
+	}
+	_, err = kubeClient.BatchV1().Jobs(ns).Create(&batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "old-job",
+			Labels: map[string]string{kube.LabelCreatedBy: kube.ValueCreatedByJX},
+		},
+		Status: batchv1.JobStatus{
+			CompletionTime: &metav1.Time{Time: old},
+		},
+	})
+	require.NoError(t, err)
+	_, err = kubeClient.BatchV1().Jobs(ns).Create(&batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "running-job",
+			Labels: map[string]string{kube.LabelCreatedBy: kube.ValueCreatedByJX},
+		},
+	})
+	require.NoError(t, err)
+	_, err = tektonClient.TektonV1alpha1().PipelineRuns(ns).Create(&tektonv1alpha1.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "old-pipelinerun",
+			Labels: buildLabels,
+		},
+		Status: tektonv1alpha1.PipelineRunStatus{
+			CompletionTime: &metav1.Time{Time: old},
+		},
+	})
+	require.NoError(t, err)
+	_, err = tektonClient.TektonV1alpha1().PipelineRuns(ns).Create(&tektonv1alpha1.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "running-pipelinerun",
+			Labels: buildLabels,
+		},
+	})
+	require.NoError(t, err)
+
+	o := &GCResourcesOptions{
+		CommonOptions: options,
+		OlderThan:     72 * time.Hour,
+		Kinds:         gcResourceKinds,
+		DryRun:        true,
+	}
+	err = o.Run()
+	require.NoError(t, err)
+	podList, err := kubeClient.CoreV1().Pods(ns).List(metav1.ListOptions{})
+	require.NoError(t, err)
+	assert.Len(t, podList.Items, len(pods), "no pods should be deleted in dry run mode")
+
+	o.DryRun = false
+	err = o.Run()
+	require.NoError(t, err)
+
+	podList, err = kubeClient.CoreV1().Pods(ns).List(metav1.ListOptions{})
+	require.NoError(t, err)
+	podNames := []string{}
+	for _, pod := range podList.Items {
+		podNames = append(podNames, pod.Name)
+	}
+	assert.ElementsMatch(t, []string{"recent-build", "running-build", "old-app"}, podNames)
+
+	jobList, err := kubeClient.BatchV1().Jobs(ns).List(metav1.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, jobList.Items, 1)
+	assert.Equal(t, "running-job", jobList.Items[0].Name)
+
+	prList, err := tektonClient.TektonV1alpha1().PipelineRuns(ns).List(metav1.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, prList.Items, 1)
+	assert.Equal(t, "running-pipelinerun", prList.Items[0].Name)
+}
+
+func TestGCResourcesInvalidKind(t *testing.T) {
+	t.Parallel()
+	commonOpts := opts.NewCommonOptionsWithFactory(fake.NewFakeFactory())
+	o := &GCResourcesOptions{
+		CommonOptions: &commonOpts,
+		Kinds:         []string{"services"},
+	}
+	err := o.Run()
+	assert.Error(t, err)
+}
@@ -72,8 +72,10 @@ func NewCmdGet(commonOpts *opts.CommonOptions) *cobra.Command {
 
 	cmd.AddCommand(NewCmdGetActivity(commonOpts))
 	cmd.AddCommand(NewCmdGetAddon(commonOpts))
+	cmd.AddCommand(NewCmdGetAppGraph(commonOpts))
 	cmd.AddCommand(NewCmdGetApps(commonOpts))
 	cmd.AddCommand(NewCmdGetApplications(commonOpts))
+	cmd.AddCommand(NewCmdGetAudit(commonOpts))
 	cmd.AddCommand(NewCmdGetAWSInfo(commonOpts))
 	cmd.AddCommand(NewCmdGetBranchPattern(commonOpts))
 	cmd.AddCommand(NewCmdGetBuild(commonOpts))
@@ -81,8 +83,12 @@ func NewCmdGet(commonOpts *opts.CommonOptions) *cobra.Command {
 	cmd.AddCommand(NewCmdGetChat(commonOpts))
 	cmd.AddCommand(NewCmdGetConfig(commonOpts))
 	cmd.AddCommand(NewCmdGetCluster(commonOpts))
+	cmd.AddCommand(NewCmdGetCost(commonOpts))
+	cmd.AddCommand(NewCmdGetCronPipelines(commonOpts))
+	cmd.AddCommand(NewCmdGetCoverage(commonOpts))
 	cmd.AddCommand(NewCmdGetCVE(commonOpts))
 	cmd.AddCommand(NewCmdGetDevPod(commonOpts))
+	cmd.AddCommand(NewCmdGetDora(commonOpts))
 	cmd.AddCommand(NewCmdGetEks(commonOpts))
 	cmd.AddCommand(NewCmdGetEnv(commonOpts))
 	cmd.AddCommand(NewCmdGetGit(commonOpts))
@@ -91,12 +97,15 @@ func NewCmdGet(commonOpts *opts.CommonOptions) *cobra.Command {
 	cmd.AddCommand(NewCmdGetIssues(commonOpts))
 	cmd.AddCommand(NewCmdGetLimits(commonOpts))
 	cmd.AddCommand(NewCmdGetLang(commonOpts))
+	cmd.AddCommand(NewCmdGetMergeQueue(commonOpts))
 	cmd.AddCommand(NewCmdGetPipeline(commonOpts))
+	cmd.AddCommand(NewCmdGetPipelineQueue(commonOpts))
 	cmd.AddCommand(NewCmdGetPostPreviewJob(commonOpts))
 	cmd.AddCommand(NewCmdGetPreview(commonOpts))
 	cmd.AddCommand(NewCmdGetQuickstartLocation(commonOpts))
 	cmd.AddCommand(NewCmdGetQuickstarts(commonOpts))
 	cmd.AddCommand(NewCmdGetRelease(commonOpts))
+	cmd.AddCommand(NewCmdGetRegistry(commonOpts))
 	cmd.AddCommand(NewCmdGetStorage(commonOpts))
 	cmd.AddCommand(NewCmdGetTeam(commonOpts))
 	cmd.AddCommand(NewCmdGetTeamRole(commonOpts))
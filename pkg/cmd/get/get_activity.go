@@ -7,6 +7,7 @@ import (
 	"github.com/jenkins-x/jx/pkg/cmd/helper"
 
 	"github.com/ghodss/yaml"
+	"github.com/jenkins-x/jx/pkg/activities"
 	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
 	"github.com/jenkins-x/jx/pkg/client/clientset/versioned"
 	"github.com/jenkins-x/jx/pkg/cmd/opts"
@@ -15,6 +16,7 @@ import (
 	"github.com/jenkins-x/jx/pkg/log"
 	tbl "github.com/jenkins-x/jx/pkg/table"
 	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/fields"
@@ -33,11 +35,15 @@ type GetActivityOptions struct {
 	BuildNumber string
 	Watch       bool
 	Sort        bool
+	Archived    bool
 }
 
 var (
 	get_activity_long = templates.LongDesc(`
 		Display the current activities for one or more projects.
+
+		Use --archived to also include the activities which were archived to the bucket of the 'activities' storage
+		location by 'jx gc activities --archive'.
 `)
 
 	get_activity_example = templates.Examples(`
@@ -49,6 +55,9 @@ var (
 
 		# Watch the activities for application 'foo'
 		jx get act -f foo -w
+
+		# List the current and archived activities for application 'foo' sorted by time
+		jx get act -f foo --archived --sort
 	`)
 )
 
@@ -74,6 +83,7 @@ func NewCmdGetActivity(commonOpts *opts.CommonOptions) *cobra.Command {
 	cmd.Flags().StringVarP(&options.BuildNumber, "build", "", "", "The build number to filter on")
 	cmd.Flags().BoolVarP(&options.Watch, "watch", "w", false, "Whether to watch the activities for changes")
 	cmd.Flags().BoolVarP(&options.Sort, "sort", "s", false, "Sort activities by timestamp")
+	cmd.Flags().BoolVarP(&options.Archived, "archived", "", false, "Includes the activities archived to the bucket of the activities storage location")
 	return cmd
 }
 
@@ -97,6 +107,9 @@ func (o *GetActivityOptions) Run() error {
 	table.AddRow("STEP", "STARTED AGO", "DURATION", "STATUS")
 
 	if o.Watch {
+		if o.Archived {
+			return util.InvalidOptionf("archived", o.Archived, "cannot watch archived activities")
+		}
 		return o.WatchActivities(&table, client, ns)
 	}
 
@@ -104,6 +117,13 @@ func (o *GetActivityOptions) Run() error {
 	if err != nil {
 		return err
 	}
+	if o.Archived {
+		archived, err := o.loadArchivedActivities()
+		if err != nil {
+			return err
+		}
+		list.Items = MergeArchivedActivities(list.Items, archived)
+	}
 	if o.Sort {
 		kube.SortActivities(list.Items)
 	}
@@ -116,6 +136,39 @@ func (o *GetActivityOptions) Run() error {
 	return nil
 }
 
+func (o *GetActivityOptions) loadArchivedActivities() ([]v1.PipelineActivity, error) {
+	teamSettings, err := o.TeamSettings()
+	if err != nil {
+		return nil, errors.Wrap(err, "loading the team settings")
+	}
+	location := teamSettings.StorageLocationOrDefault(kube.ClassificationActivities)
+	if location.BucketURL == "" {
+		log.Logger().Warnf("No bucket URL is configured for the %s storage location so there are no archived activities", util.ColorInfo(kube.ClassificationActivities))
+		return nil, nil
+	}
+	archived, err := activities.NewArchive(location.BucketURL).Load()
+	if err != nil {
+		return nil, errors.Wrapf(err, "loading the archived activities from %s", location.BucketURL)
+	}
+	return archived, nil
+}
+
+// MergeArchivedActivities appends the archived activities which are not in the current activities
+func MergeArchivedActivities(current []v1.PipelineActivity, archived []v1.PipelineActivity) []v1.PipelineActivity {
+	names := map[string]bool{}
+	for _, a := range current {
+		names[a.Name] = true
+	}
+	answer := current
+	for _, a := range archived {
+		if !names[a.Name] {
+			names[a.Name] = true
+			answer = append(answer, a)
+		}
+	}
+	return answer
+}
+
 func (o *GetActivityOptions) addTableRow(table *tbl.Table, activity *v1.PipelineActivity) bool {
 	if o.matches(activity) {
 		spec := &activity.Spec
@@ -0,0 +1,99 @@
+package get
+
+import (
+	"fmt"
+
+	"github.com/jenkins-x/jx/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/spf13/cobra"
+)
+
+const (
+	appGraphFormatDOT     = "dot"
+	appGraphFormatMermaid = "mermaid"
+)
+
+var appGraphFormats = []string{appGraphFormatDOT, appGraphFormatMermaid}
+
+// GetAppGraphOptions the command line options
+type GetAppGraphOptions struct {
+	GetOptions
+	Format string
+}
+
+var (
+	getAppGraphLong = templates.LongDesc(`
+		Display the graph of the dependencies between the applications of the team.
+
+		Applications declare the other applications they depend on in the '.jx/dependencies.yaml' file of their repository or with the 'jenkins.io/dependencies' annotation of their chart containing comma separated application names.
+
+		The graph is output in the Graphviz DOT format or as a mermaid flowchart so that it can be rendered or embedded in docs.
+`)
+
+	getAppGraphExample = templates.Examples(`
+		# Display the dependencies of all the applications in the DOT format
+		jx get app-graph
+
+		# Render the dependencies of the myapp application as an image
+		jx get app-graph myapp | dot -Tpng > myapp.png
+
+		# Display the dependencies as a mermaid flowchart
+		jx get app-graph --format mermaid
+	`)
+)
+
+// NewCmdGetAppGraph creates the command
+func NewCmdGetAppGraph(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &GetAppGraphOptions{
+		GetOptions: GetOptions{
+			CommonOptions: commonOpts,
+		},
+	}
+
+	cmd := &cobra.Command{
+		Use:     "app-graph [application]",
+		Short:   "Display the graph of the dependencies between applications",
+		Aliases: []string{"app-graphs", "application-graph"},
+		Long:    getAppGraphLong,
+		Example: getAppGraphExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	options.AddGetFlags(cmd)
+	cmd.Flags().StringVarP(&options.Format, "format", "f", appGraphFormatDOT, fmt.Sprintf("The format of the graph. One of %v", appGraphFormats))
+	return cmd
+}
+
+// Run implements this command
+func (o *GetAppGraphOptions) Run() error {
+	if util.StringArrayIndex(appGraphFormats, o.Format) < 0 {
+		return util.InvalidOption("format", o.Format, appGraphFormats)
+	}
+	graph, err := o.AppGraph()
+	if err != nil {
+		return err
+	}
+	if len(o.Args) > 0 {
+		app := o.Args[0]
+		if util.StringArrayIndex(graph.Apps(), app) < 0 {
+			return util.InvalidArg(app, graph.Apps())
+		}
+		graph = graph.Subgraph(app)
+	}
+	if o.Output != "" {
+		return o.renderResult(graph.Map(), o.Output)
+	}
+	switch o.Format {
+	case appGraphFormatMermaid:
+		_, err = fmt.Fprint(o.Out, graph.Mermaid())
+	default:
+		_, err = fmt.Fprint(o.Out, graph.DOT())
+	}
+	return err
+}
@@ -4,33 +4,34 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/jenkins-x/jx/pkg/applications"
 	"github.com/jenkins-x/jx/pkg/cmd/helper"
 
 	"github.com/jenkins-x/jx/pkg/table"
 	"github.com/pkg/errors"
-	"k8s.io/client-go/kubernetes"
 
 	"github.com/spf13/cobra"
 
 	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
 	"github.com/jenkins-x/jx/pkg/cmd/opts"
 	"github.com/jenkins-x/jx/pkg/cmd/templates"
-	"github.com/jenkins-x/jx/pkg/kube"
 	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/util"
 	"k8s.io/api/apps/v1beta1"
 )
 
 // GetApplicationsOptions containers the CLI options
 type GetApplicationsOptions struct {
-	*opts.CommonOptions
+	GetOptions
 
 	Namespace   string
 	Environment string
 	HideUrl     bool
 	HidePod     bool
 	Previews    bool
+	Drift       bool
 }
 
 // Applications is a map indexed by the application name then the environment name
@@ -53,6 +54,12 @@ type ApplicationEnvironmentInfo struct {
 var (
 	getVersionLong = templates.LongDesc(`
 		Display applications across environments.
+
+		For each environment the version, the number of ready pods, the time of the last deployment and the URL of the application are displayed.
+
+		The running versions are compared with the versions in the git repositories of the environments so that any drift is highlighted, for example when a promotion has been merged but not applied yet or when an application was changed manually. The comparison can be disabled with '--drift=false'.
+
+		Use '--output json' to consume the matrix from dashboards.
 `)
 
 	getVersionExample = templates.Examples(`
@@ -73,13 +80,18 @@ var (
 
 		# List applications just showing the versions (hiding urls and pod counts)
 		jx get applications -u -p
+
+		# Output the application matrix as JSON
+		jx get applications -o json
 	`)
 )
 
 // NewCmdGetApplications creates the new command for: jx get version
 func NewCmdGetApplications(commonOpts *opts.CommonOptions) *cobra.Command {
 	options := &GetApplicationsOptions{
-		CommonOptions: commonOpts,
+		GetOptions: GetOptions{
+			CommonOptions: commonOpts,
+		},
 	}
 	cmd := &cobra.Command{
 		Use:     "applications",
@@ -94,11 +106,13 @@ func NewCmdGetApplications(commonOpts *opts.CommonOptions) *cobra.Command {
 			helper.CheckErr(err)
 		},
 	}
+	options.AddGetFlags(cmd)
 	cmd.Flags().BoolVarP(&options.HideUrl, "url", "u", false, "Hide the URLs")
 	cmd.Flags().BoolVarP(&options.HidePod, "pod", "p", false, "Hide the pod counts")
 	cmd.Flags().BoolVarP(&options.Previews, "preview", "w", false, "Show preview environments only")
 	cmd.Flags().StringVarP(&options.Environment, "env", "e", "", "Filter applications in the given environment")
 	cmd.Flags().StringVarP(&options.Namespace, "namespace", "n", "", "Filter applications in the given namespace")
+	cmd.Flags().BoolVarP(&options.Drift, "drift", "", true, "Compare the running versions with the versions in the git repositories of the environments")
 	return cmd
 }
 
@@ -113,7 +127,7 @@ func (o *GetApplicationsOptions) Run() error {
 	if err != nil {
 		return errors.Wrap(err, "fetching applications")
 	}
-	if len(list.Items) == 0 {
+	if len(list.Items) == 0 && o.Output == "" {
 		log.Logger().Infof("No applications found")
 		return nil
 	}
@@ -122,70 +136,108 @@ func (o *GetApplicationsOptions) Run() error {
 	if err != nil {
 		return err
 	}
-	table := o.generateTable(kubeClient, list)
+	envs := o.sortedEnvironments(list.Environments())
+	desiredVersions := map[string]map[string]string{}
+	if o.Drift {
+		desiredVersions = o.environmentVersions(envs)
+	}
+	var url func(applications.Deployment, applications.Application) string
+	if !o.HideUrl || o.Output != "" {
+		url = func(d applications.Deployment, a applications.Application) string {
+			return d.URL(kubeClient, a)
+		}
+	}
+	matrix := applications.NewMatrix(list, envs, desiredVersions, url)
+	if o.Output != "" {
+		return o.renderResult(matrix, o.Output)
+	}
+	table := o.generateTable(matrix)
 	table.Render()
-
 	return nil
 }
 
-func (o *GetApplicationsOptions) generateTable(kubeClient kubernetes.Interface, list applications.List) table.Table {
-	table := o.generateTableHeaders(list)
-
-	for _, a := range list.Items {
-		row := []string{}
-		name := a.Name()
-
-		if len(a.Environments) > 0 {
-
-			for _, k := range o.sortedKeys(list.Environments()) {
-
-				if ae, ok := a.Environments[k]; ok {
-					for _, d := range ae.Deployments {
-						name = kube.GetAppName(d.Deployment.Name, k)
-						if ae.Environment.Spec.Kind == v1.EnvironmentKindTypeEdit {
-							name = kube.GetEditAppName(name)
-						} else if ae.Environment.Spec.Kind == v1.EnvironmentKindTypePreview {
-							name = ae.Environment.Spec.PullRequestURL
-						}
-						if !ae.IsPreview() {
-							row = append(row, d.Version())
-						}
-						if !o.HidePod {
-							row = append(row, d.Pods())
-						}
-						if !o.HideUrl {
-							row = append(row, d.URL(kubeClient, a))
-						}
-					}
-				} else {
-					if !ae.IsPreview() {
-						row = append(row, "")
-					}
-					if !o.HidePod {
-						row = append(row, "")
-					}
-					if !o.HideUrl {
-						row = append(row, "")
-					}
-				}
-			}
-			row = append([]string{name}, row...)
+// environmentVersions returns the versions of the applications in the git repositories of the environments indexed
+// by environment name then application name
+func (o *GetApplicationsOptions) environmentVersions(envs []v1.Environment) map[string]map[string]string {
+	answer := map[string]map[string]string{}
+	for i := range envs {
+		env := &envs[i]
+		if env.Spec.Source.URL == "" {
+			continue
+		}
+		provider, err := o.GitProviderForURL(env.Spec.Source.URL, "environment git repository")
+		if err != nil {
+			log.Logger().Warnf("Cannot detect drift in environment %s: %s", env.Name, err)
+			continue
+		}
+		versions, err := applications.GetEnvironmentVersions(provider, env)
+		if err != nil {
+			log.Logger().Warnf("Cannot detect drift in environment %s: %s", env.Name, err)
+			continue
+		}
+		answer[env.Name] = versions
+	}
+	return answer
+}
 
-			table.AddRow(row...)
+func (o *GetApplicationsOptions) generateTable(matrix *applications.Matrix) table.Table {
+	table := o.generateTableHeaders(matrix)
+
+	for _, a := range matrix.Applications {
+		row := []string{a.Name}
+		for _, env := range matrix.Environments {
+			cell := a.Environments[env.Name]
+			if cell == nil {
+				cell = &applications.MatrixCell{}
+			}
+			row = append(row, formatMatrixVersion(cell))
+			if !o.HidePod {
+				row = append(row, formatMatrixPods(cell))
+			}
+			row = append(row, formatMatrixLastDeployed(cell))
+			if !o.HideUrl {
+				row = append(row, cell.URL)
+			}
 		}
+		table.AddRow(row...)
 	}
 	return table
 }
 
-func envTitleName(e v1.Environment) string {
-	if e.Spec.Kind == v1.EnvironmentKindTypeEdit {
+func formatMatrixVersion(cell *applications.MatrixCell) string {
+	if !cell.Drift {
+		return cell.Version
+	}
+	return cell.Version + util.ColorWarning(" (env repo "+cell.DesiredVersion+")")
+}
+
+func formatMatrixPods(cell *applications.MatrixCell) string {
+	if cell.Version == "" {
+		return ""
+	}
+	pods := util.Int32ToA(cell.ReadyPods) + "/" + util.Int32ToA(cell.Replicas)
+	if cell.Health == applications.HealthUnavailable || cell.Health == applications.HealthProgressing {
+		return util.ColorWarning(pods)
+	}
+	return pods
+}
+
+func formatMatrixLastDeployed(cell *applications.MatrixCell) string {
+	if cell.LastDeployed == nil {
+		return ""
+	}
+	return time.Since(cell.LastDeployed.Time).Round(time.Minute).String()
+}
+
+func envTitleName(e applications.MatrixEnvironment) string {
+	if e.Kind == string(v1.EnvironmentKindTypeEdit) {
 		return "Edit"
 	}
 
 	return e.Name
 }
 
-func (o *GetApplicationsOptions) sortedKeys(envs map[string]v1.Environment) []string {
+func (o *GetApplicationsOptions) sortedEnvironments(envs map[string]v1.Environment) []v1.Environment {
 	keys := make([]string, 0, len(envs))
 	for k, env := range envs {
 		if (o.Environment == "" || o.Environment == k) && (o.Namespace == "" || o.Namespace == env.Spec.Namespace) {
@@ -194,22 +246,25 @@ func (o *GetApplicationsOptions) sortedKeys(envs map[string]v1.Environment) []st
 	}
 	sort.Sort(sort.Reverse(sort.StringSlice(keys)))
 
-	return keys
+	answer := make([]v1.Environment, 0, len(keys))
+	for _, k := range keys {
+		answer = append(answer, envs[k])
+	}
+	return answer
 }
 
-func (o *GetApplicationsOptions) generateTableHeaders(list applications.List) table.Table {
+func (o *GetApplicationsOptions) generateTableHeaders(matrix *applications.Matrix) table.Table {
 	t := o.CreateTable()
 	title := "APPLICATION"
 	titles := []string{title}
 
-	envs := list.Environments()
-
-	for _, k := range o.sortedKeys(envs) {
-		titles = append(titles, strings.ToUpper(envTitleName(envs[k])))
+	for _, env := range matrix.Environments {
+		titles = append(titles, strings.ToUpper(envTitleName(env)))
 
 		if !o.HidePod {
 			titles = append(titles, "PODS")
 		}
+		titles = append(titles, "DEPLOYED")
 		if !o.HideUrl {
 			titles = append(titles, "URL")
 		}
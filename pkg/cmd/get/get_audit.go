@@ -0,0 +1,116 @@
+package get
+
+import (
+	"time"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/audit"
+	"github.com/jenkins-x/jx/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/spf13/cobra"
+)
+
+// GetAuditOptions the command line options
+type GetAuditOptions struct {
+	GetOptions
+	Since  string
+	Action string
+	User   string
+}
+
+var (
+	getAuditLong = templates.LongDesc(`
+		Display the audit events of the changes made by jx such as raised pull requests, applied environments, written
+		secrets and upgraded apps so that you can see who changed what and when.
+
+		The audit events are read from the AuditEvent resources of the dev namespace which are recorded by the 'crd' sink
+		configured in the audit settings of the team.
+`)
+
+	getAuditExample = templates.Examples(`
+		# Display the changes made in the last 7 days
+		jx get audit --since 7d
+
+		# Display the environments applied in the last 12 hours
+		jx get audit --since 12h --action ` + v1.AuditActionEnvironmentApplied + `
+	`)
+)
+
+// NewCmdGetAudit creates the command
+func NewCmdGetAudit(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &GetAuditOptions{
+		GetOptions: GetOptions{
+			CommonOptions: commonOpts,
+		},
+	}
+
+	cmd := &cobra.Command{
+		Use:     "audit",
+		Short:   "Display the audit events of the changes made by jx",
+		Long:    getAuditLong,
+		Example: getAuditExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	options.AddGetFlags(cmd)
+	cmd.Flags().StringVarP(&options.Since, "since", "s", "7d", "Only display the changes made since the duration such as 7d, 12h or 30m. Displays all the changes if empty")
+	cmd.Flags().StringVarP(&options.Action, "action", "a", "", "Only display the changes of the action such as "+v1.AuditActionPullRequestRaised)
+	cmd.Flags().StringVarP(&options.User, "user", "u", "", "Only display the changes made by the user")
+	return cmd
+}
+
+// Run implements this command
+func (o *GetAuditOptions) Run() error {
+	since := time.Time{}
+	if o.Since != "" {
+		duration, err := audit.ParseSince(o.Since)
+		if err != nil {
+			return util.InvalidOptionError("since", o.Since, err)
+		}
+		since = time.Now().Add(-duration)
+	}
+	jxClient, ns, err := o.JXClientAndDevNamespace()
+	if err != nil {
+		return err
+	}
+	events, err := audit.ListEvents(jxClient, ns, since, o.Action)
+	if err != nil {
+		return err
+	}
+	if o.User != "" {
+		filtered := []v1.AuditEvent{}
+		for _, event := range events {
+			if event.Spec.User == o.User {
+				filtered = append(filtered, event)
+			}
+		}
+		events = filtered
+	}
+	if o.Output != "" {
+		return o.renderResult(events, o.Output)
+	}
+	if len(events) == 0 {
+		log.Logger().Infof("No audit events found in namespace %s", util.ColorInfo(ns))
+		return nil
+	}
+
+	table := o.CreateTable()
+	table.AddRow("TIME", "ACTION", "USER", "RESOURCE", "NAMESPACE", "MESSAGE")
+	for _, event := range events {
+		spec := event.Spec
+		user := spec.User
+		if user == "" {
+			user = spec.Pipeline
+		}
+		table.AddRow(spec.Timestamp.Format("2006-01-02 15:04:05"), spec.Action, user, spec.Resource, spec.Namespace, spec.Message)
+	}
+	table.Render()
+	return nil
+}
@@ -0,0 +1,230 @@
+package get
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/jenkins-x/jx/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/pkg/config"
+	"github.com/jenkins-x/jx/pkg/cost"
+	"github.com/jenkins-x/jx/pkg/kube"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/notify"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// GetCostOptions the command line options
+type GetCostOptions struct {
+	GetOptions
+
+	Provider    string
+	PricingFile string
+	All         bool
+	Notify      bool
+}
+
+// CostReport the estimated cost of the namespaces of a cluster
+type CostReport struct {
+	Namespaces  []*cost.NamespaceCost `json:"namespaces"`
+	HourlyCost  float64               `json:"hourlyCost"`
+	MonthlyCost float64               `json:"monthlyCost"`
+}
+
+var (
+	getCostLong = templates.LongDesc(`
+		Estimates the cost of the environments and previews of the team
+
+		The cost of each namespace is estimated by charging each running pod the larger of its share of the requested
+		CPU or memory of its node multiplied by the hourly price of the node. The prices of the common node types of
+		GKE, EKS and AKS are built in and can be replaced with a YAML file of instance type prices via --pricing-file.
+
+		Use --notify to send the report to the channels of the 'cost-report' event in the notifications.yaml file of
+		the dev environment repository such as a Slack channel.
+`)
+
+	getCostExample = templates.Examples(`
+		# estimate the cost of the environments and previews
+		jx get cost
+
+		# estimate the cost of every namespace as JSON
+		jx get cost --all -o json
+
+		# use your own prices and send the report to Slack
+		jx get cost --pricing-file prices.yaml --notify
+	`)
+)
+
+// NewCmdGetCost creates the command
+func NewCmdGetCost(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &GetCostOptions{
+		GetOptions: GetOptions{
+			CommonOptions: commonOpts,
+		},
+	}
+
+	cmd := &cobra.Command{
+		Use:     "cost [flags]",
+		Short:   "Estimates the cost of the environments and previews",
+		Long:    getCostLong,
+		Example: getCostExample,
+		Aliases: []string{"costs"},
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+
+	options.AddGetFlags(cmd)
+	cmd.Flags().StringVarP(&options.Provider, "provider", "", "", "The kubernetes provider whose prices are used for nodes whose provider cannot be detected. Defaults to the provider of the team")
+	cmd.Flags().StringVarP(&options.PricingFile, "pricing-file", "", "", "A YAML file of the hourly prices of instance types used instead of the built in prices")
+	cmd.Flags().BoolVarP(&options.All, "all", "a", false, "Includes every namespace rather than only the environments and previews")
+	cmd.Flags().BoolVarP(&options.Notify, "notify", "", false, "Sends the report to the channels configured for the cost-report event")
+	return cmd
+}
+
+// Run implements this command
+func (o *GetCostOptions) Run() error {
+	kubeClient, err := o.KubeClient()
+	if err != nil {
+		return err
+	}
+	jxClient, ns, err := o.JXClientAndDevNamespace()
+	if err != nil {
+		return err
+	}
+
+	pricing, err := o.pricing()
+	if err != nil {
+		return err
+	}
+
+	nodes, err := kubeClient.CoreV1().Nodes().List(metav1.ListOptions{})
+	if err != nil {
+		return errors.Wrap(err, "listing the nodes")
+	}
+	pods, err := kubeClient.CoreV1().Pods("").List(metav1.ListOptions{})
+	if err != nil {
+		return errors.Wrap(err, "listing the pods")
+	}
+	envMap, _, err := kube.GetEnvironments(jxClient, ns)
+	if err != nil {
+		return errors.Wrapf(err, "listing the environments in namespace %s", ns)
+	}
+
+	report := &CostReport{}
+	for _, nc := range cost.Estimate(nodes.Items, pods.Items, pricing) {
+		for _, env := range envMap {
+			if env.Spec.Namespace == nc.Namespace {
+				nc.Environment = env.Name
+				nc.Kind = string(env.Spec.Kind)
+			}
+		}
+		if nc.Environment == "" && !o.All {
+			continue
+		}
+		report.Namespaces = append(report.Namespaces, nc)
+		report.HourlyCost += nc.HourlyCost
+		report.MonthlyCost += nc.MonthlyCost
+	}
+
+	if o.Notify {
+		err = o.notify(report, ns)
+		if err != nil {
+			return err
+		}
+	}
+	if o.Output != "" {
+		return o.renderResult(report, o.Output)
+	}
+	if len(report.Namespaces) == 0 {
+		return outputEmptyListWarning(o.Out)
+	}
+
+	table := o.CreateTable()
+	table.AddRow("ENVIRONMENT", "KIND", "NAMESPACE", "PODS", "CPU", "MEMORY", "HOURLY", "MONTHLY")
+	for _, nc := range report.Namespaces {
+		table.AddRow(nc.Environment, nc.Kind, nc.Namespace, fmt.Sprintf("%d", nc.Pods), fmt.Sprintf("%.2f", nc.CPU), fmt.Sprintf("%.2fGi", nc.MemoryGiB), formatCost(nc.HourlyCost), formatCost(nc.MonthlyCost))
+	}
+	table.AddRow("TOTAL", "", "", "", "", "", formatCost(report.HourlyCost), formatCost(report.MonthlyCost))
+	table.Render()
+	return nil
+}
+
+func (o *GetCostOptions) pricing() (cost.Pricing, error) {
+	if o.PricingFile != "" {
+		table, err := cost.LoadPriceTable(o.PricingFile)
+		if err != nil {
+			return nil, err
+		}
+		return table, nil
+	}
+	provider := o.Provider
+	if provider == "" {
+		teamSettings, err := o.TeamSettings()
+		if err != nil {
+			return nil, errors.Wrap(err, "loading the team settings")
+		}
+		requirements, err := config.GetRequirementsConfigFromTeamSettings(teamSettings)
+		if err != nil {
+			return nil, errors.Wrap(err, "getting the requirements from the team settings")
+		}
+		if requirements != nil {
+			provider = requirements.Cluster.Provider
+		}
+	}
+	return &cost.ProviderPricing{
+		Tables:          cost.DefaultPriceTables,
+		DefaultProvider: provider,
+	}, nil
+}
+
+func (o *GetCostOptions) notify(report *CostReport, ns string) error {
+	jxClient, _, err := o.JXClient()
+	if err != nil {
+		return err
+	}
+	cfg, err := notify.LoadDevEnvironmentConfig(o.Git(), jxClient, ns)
+	if err != nil {
+		return errors.Wrap(err, "loading the notifications configuration")
+	}
+	if cfg == nil || len(cfg.MatchingRoutes(config.NotificationCostReport, "", "")) == 0 {
+		log.Logger().Warnf("No notification channels are configured for the %s event", util.ColorInfo(config.NotificationCostReport))
+		return nil
+	}
+	dispatcher := &notify.Dispatcher{Config: cfg}
+	return dispatcher.Dispatch(&notify.Event{
+		Kind:   config.NotificationCostReport,
+		Report: CostReportText(report),
+	})
+}
+
+// CostReportText renders the report as text suitable for a chat message
+func CostReportText(report *CostReport) string {
+	var buffer bytes.Buffer
+	fmt.Fprintf(&buffer, "Estimated cost: %s per month\n```\n", formatCost(report.MonthlyCost))
+	w := tabwriter.NewWriter(&buffer, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ENVIRONMENT\tNAMESPACE\tMONTHLY")
+	for _, nc := range report.Namespaces {
+		name := nc.Environment
+		if name == "" {
+			name = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", name, nc.Namespace, formatCost(nc.MonthlyCost))
+	}
+	w.Flush()
+	buffer.WriteString("```")
+	return strings.TrimSpace(buffer.String())
+}
+
+func formatCost(value float64) string {
+	return fmt.Sprintf("$%.2f", value)
+}
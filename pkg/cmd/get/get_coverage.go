@@ -0,0 +1,125 @@
+package get
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jenkins-x/jx/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/reports"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// GetCoverageOptions the command line options
+type GetCoverageOptions struct {
+	GetOptions
+	Branch string
+}
+
+var (
+	getCoverageLong = templates.LongDesc(`
+		Display the code coverage history of a repository as reported by 'jx step report coverage'.
+
+		The delta is the change of the coverage compared to the previous build of the same branch.
+`)
+
+	getCoverageExample = templates.Examples(`
+		# Display the coverage history of the repository in the current directory
+		jx get coverage
+
+		# Display the coverage history of the master branch of a repository
+		jx get coverage myorg/myapp --branch master
+	`)
+)
+
+// NewCmdGetCoverage creates the command
+func NewCmdGetCoverage(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &GetCoverageOptions{
+		GetOptions: GetOptions{
+			CommonOptions: commonOpts,
+		},
+	}
+
+	cmd := &cobra.Command{
+		Use:     "coverage [owner/repository]",
+		Short:   "Display the code coverage history of a repository",
+		Long:    getCoverageLong,
+		Example: getCoverageExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	options.AddGetFlags(cmd)
+	cmd.Flags().StringVarP(&options.Branch, "branch", "b", "", "Only display the coverage of the branch")
+	return cmd
+}
+
+// Run implements this command
+func (o *GetCoverageOptions) Run() error {
+	repository := ""
+	if len(o.Args) > 0 {
+		repository = o.Args[0]
+		if !strings.Contains(repository, "/") {
+			return util.InvalidArgf(repository, "Expected the repository as owner/repository")
+		}
+	} else {
+		gitInfo, err := o.FindGitInfo("")
+		if err != nil {
+			return errors.Wrap(err, "failed to find the git repository of the current directory. Specify the repository as owner/repository")
+		}
+		repository = gitInfo.Organisation + "/" + gitInfo.Name
+	}
+
+	kubeClient, ns, err := o.KubeClientAndDevNamespace()
+	if err != nil {
+		return err
+	}
+	history, err := reports.LoadCoverageHistory(kubeClient, ns, repository)
+	if err != nil {
+		return err
+	}
+	records := []reports.CoverageRecord{}
+	for _, record := range history.Records {
+		if o.Branch == "" || record.Branch == o.Branch {
+			records = append(records, record)
+		}
+	}
+	if o.Output != "" {
+		return o.renderResult(records, o.Output)
+	}
+	if len(records) == 0 {
+		log.Logger().Infof("No coverage has been reported for %s", util.ColorInfo(repository))
+		return nil
+	}
+
+	table := o.CreateTable()
+	table.AddRow("SHA", "BRANCH", "BUILD", "COVERAGE", "DELTA", "DATE")
+	previous := map[string]float64{}
+	rows := [][]string{}
+	for _, record := range records {
+		coverage := record.Percent()
+		delta := ""
+		if p, ok := previous[record.Branch]; ok {
+			delta = fmt.Sprintf("%+.1f%%", coverage-p)
+		}
+		previous[record.Branch] = coverage
+		sha := record.SHA
+		if len(sha) > 7 {
+			sha = sha[:7]
+		}
+		rows = append(rows, []string{sha, record.Branch, record.Build, fmt.Sprintf("%.1f%%", coverage), delta, record.Timestamp.Format("2006-01-02 15:04")})
+	}
+	// display the most recent builds first
+	for i := len(rows) - 1; i >= 0; i-- {
+		table.AddRow(rows[i]...)
+	}
+	table.Render()
+	return nil
+}
@@ -0,0 +1,116 @@
+package get
+
+import (
+	"time"
+
+	"github.com/jenkins-x/jx/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/tekton/cronpipelines"
+	"github.com/spf13/cobra"
+)
+
+// GetCronPipelinesOptions the command line options
+type GetCronPipelinesOptions struct {
+	GetOptions
+}
+
+// CronPipelineEntry a scheduled pipeline
+type CronPipelineEntry struct {
+	Name          string            `json:"name"`
+	Schedule      string            `json:"schedule"`
+	Branch        string            `json:"branch"`
+	OverlapPolicy string            `json:"overlapPolicy"`
+	Parameters    map[string]string `json:"parameters,omitempty"`
+	LastScheduled time.Time         `json:"lastScheduled"`
+	Next          *time.Time        `json:"next,omitempty"`
+}
+
+var (
+	getCronPipelinesLong = templates.LongDesc(`
+		Display the pipelines scheduled by the cron triggers in the jenkins-x.yml of the repositories.
+
+		The pipelines are triggered by 'jx controller cron-pipelines' when they are due.
+`)
+
+	getCronPipelinesExample = templates.Examples(`
+		# Display the scheduled pipelines
+		jx get cron-pipelines
+
+		# Display the scheduled pipelines as YAML
+		jx get cron-pipelines -o yaml
+	`)
+)
+
+// NewCmdGetCronPipelines creates the command
+func NewCmdGetCronPipelines(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &GetCronPipelinesOptions{
+		GetOptions: GetOptions{
+			CommonOptions: commonOpts,
+		},
+	}
+
+	cmd := &cobra.Command{
+		Use:     "cron-pipelines",
+		Short:   "Display the pipelines scheduled by cron triggers",
+		Long:    getCronPipelinesLong,
+		Example: getCronPipelinesExample,
+		Aliases: []string{"cron-pipeline", "cronpipelines", "cron"},
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	options.AddGetFlags(cmd)
+	return cmd
+}
+
+// Run implements this command
+func (o *GetCronPipelinesOptions) Run() error {
+	jxClient, ns, err := o.JXClientAndDevNamespace()
+	if err != nil {
+		return err
+	}
+	pipelines, err := cronpipelines.List(jxClient, ns)
+	if err != nil {
+		return err
+	}
+	entries := []CronPipelineEntry{}
+	for _, cp := range pipelines {
+		entry := CronPipelineEntry{
+			Name:          cp.Name(),
+			Schedule:      cp.Triggers.Cron,
+			Branch:        cp.Triggers.GetBranch(),
+			OverlapPolicy: cp.Triggers.GetOverlapPolicy(),
+			Parameters:    cp.Triggers.Parameters,
+			LastScheduled: cp.LastScheduled,
+		}
+		next := cp.Next()
+		if !next.IsZero() {
+			entry.Next = &next
+		}
+		entries = append(entries, entry)
+	}
+	if o.Output != "" {
+		return o.renderResult(entries, o.Output)
+	}
+	if len(entries) == 0 {
+		log.Logger().Info("No pipelines are scheduled")
+		return nil
+	}
+
+	table := o.CreateTable()
+	table.AddRow("NAME", "SCHEDULE", "BRANCH", "OVERLAP", "LAST", "NEXT")
+	for _, entry := range entries {
+		next := "never"
+		if entry.Next != nil {
+			next = entry.Next.Local().Format(time.RFC3339)
+		}
+		table.AddRow(entry.Name, entry.Schedule, entry.Branch, entry.OverlapPolicy, entry.LastScheduled.Local().Format(time.RFC3339), next)
+	}
+	table.Render()
+	return nil
+}
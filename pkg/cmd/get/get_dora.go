@@ -0,0 +1,171 @@
+package get
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jenkins-x/jx/pkg/audit"
+	"github.com/jenkins-x/jx/pkg/client/clientset/versioned"
+	"github.com/jenkins-x/jx/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/pkg/dora"
+	"github.com/jenkins-x/jx/pkg/kube"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// GetDoraOptions the command line options
+type GetDoraOptions struct {
+	GetOptions
+
+	Since       string
+	Environment string
+	Application string
+}
+
+var (
+	getDoraLong = templates.LongDesc(`
+		Displays the DORA metrics of the applications of the team:
+
+		* deployment frequency: the successful deployments per day
+		* lead time for changes: the median time from the earliest pull request of a version to its deployment
+		* change failure rate: the ratio of the deployments which failed
+		* time to restore: the mean time from a failed deployment to the next successful deployment
+
+		The deployments are the promotions to the environment recorded in the PipelineActivities of the release
+		pipelines. The pull requests of each version are read from its Release. The environment defaults to the
+		permanent environment which is promoted to last such as production.
+
+		Use '-o csv' or '-o json' to export the metrics for reporting.
+`)
+
+	getDoraExample = templates.Examples(`
+		# Display the DORA metrics of the last 90 days
+		jx get dora --since 90d
+
+		# Export the DORA metrics of the staging environment as CSV
+		jx get dora --env staging -o csv > dora.csv
+	`)
+)
+
+// NewCmdGetDora creates the command
+func NewCmdGetDora(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &GetDoraOptions{
+		GetOptions: GetOptions{
+			CommonOptions: commonOpts,
+		},
+	}
+
+	cmd := &cobra.Command{
+		Use:     "dora",
+		Short:   "Displays the DORA metrics of the applications of the team",
+		Long:    getDoraLong,
+		Example: getDoraExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.Flags().StringVarP(&options.Output, "output", "o", "", "The output format: json, yaml or csv")
+	cmd.Flags().StringVarP(&options.Since, "since", "s", "90d", "The duration the metrics are calculated over such as 90d or 12h")
+	cmd.Flags().StringVarP(&options.Environment, "env", "e", "", "The environment the deployments are promoted to. Defaults to the permanent environment which is promoted to last")
+	cmd.Flags().StringVarP(&options.Application, "app", "a", "", "Only display the metrics of the application")
+	return cmd
+}
+
+// Run implements this command
+func (o *GetDoraOptions) Run() error {
+	duration, err := audit.ParseSince(o.Since)
+	if err != nil {
+		return util.InvalidOptionError("since", o.Since, err)
+	}
+	until := time.Now()
+	since := until.Add(-duration)
+
+	jxClient, ns, err := o.JXClientAndDevNamespace()
+	if err != nil {
+		return err
+	}
+	env := o.Environment
+	if env == "" {
+		env, err = lastEnvironment(jxClient, ns)
+		if err != nil {
+			return err
+		}
+	}
+
+	activities, err := jxClient.JenkinsV1().PipelineActivities(ns).List(metav1.ListOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "listing the PipelineActivities in namespace %s", ns)
+	}
+	releases, err := jxClient.JenkinsV1().Releases(ns).List(metav1.ListOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "listing the Releases in namespace %s", ns)
+	}
+	deployments := dora.Deployments(activities.Items, releases.Items, env)
+	if o.Application != "" {
+		filtered := []*dora.Deployment{}
+		for _, d := range deployments {
+			if d.Repository == o.Application || d.Application() == o.Application {
+				filtered = append(filtered, d)
+			}
+		}
+		deployments = filtered
+	}
+	report := dora.Calculate(deployments, ns, since, until)
+	report.Environment = env
+
+	switch o.Output {
+	case "":
+	case "csv":
+		return dora.WriteCSV(o.Out, report)
+	default:
+		return o.renderResult(report, o.Output)
+	}
+	if len(report.Applications) == 0 {
+		return outputEmptyListWarning(o.Out)
+	}
+
+	table := o.CreateTable()
+	table.AddRow("APPLICATION", "DEPLOYMENTS", "FAILED", "PER DAY", "LEAD TIME", "FAILURE RATE", "TIME TO RESTORE")
+	for _, m := range append(report.Applications, report.Team) {
+		table.AddRow(m.Name, fmt.Sprintf("%d", m.Deployments), fmt.Sprintf("%d", m.FailedDeployments),
+			fmt.Sprintf("%.2f", m.DeploymentFrequency), formatHours(m.LeadTimeHours),
+			fmt.Sprintf("%.0f%%", m.ChangeFailureRate*100), formatHours(m.TimeToRestoreHours))
+	}
+	table.Render()
+	return nil
+}
+
+// lastEnvironment returns the name of the permanent environment with the highest promotion order
+func lastEnvironment(jxClient versioned.Interface, ns string) (string, error) {
+	envs, err := kube.GetPermanentEnvironments(jxClient, ns)
+	if err != nil {
+		return "", err
+	}
+	answer := ""
+	order := int32(0)
+	for _, env := range envs {
+		if answer == "" || env.Spec.Order > order {
+			answer = env.Name
+			order = env.Spec.Order
+		}
+	}
+	if answer == "" {
+		return "", fmt.Errorf("no permanent environments found in namespace %s. Please specify one via --env", ns)
+	}
+	return answer, nil
+}
+
+// formatHours formats the hours as a duration rounded to the minute
+func formatHours(hours float64) string {
+	if hours == 0 {
+		return "-"
+	}
+	return time.Duration(hours * float64(time.Hour)).Round(time.Minute).String()
+}
@@ -94,9 +94,13 @@ func (o *GetEnvOptions) Run() error {
 		spec := &env.Spec
 
 		table := o.CreateTable()
-		table.AddRow("NAME", "LABEL", "KIND", "NAMESPACE", "SOURCE", "REF", "PR")
-		table.AddRow(e, spec.Label, spec.Namespace, kindString(spec), spec.Source.URL, spec.Source.Ref, spec.PullRequestURL)
+		table.AddRow("NAME", "LABEL", "KIND", "NAMESPACE", "SOURCE", "REF", "PR", "STATUS", "APPLIED")
+		table.AddRow(e, spec.Label, spec.Namespace, kindString(spec), spec.Source.URL, spec.Source.Ref, spec.PullRequestURL,
+			syncStatusString(&env.Status), env.Status.LastAppliedCommit)
 		table.Render()
+		if env.Status.Message != "" {
+			log.Logger().Warnf("the last apply of commit %s failed: %s", env.Status.LastAttemptedCommit, env.Status.Message)
+		}
 		log.Blank()
 
 		ens := env.Spec.Namespace
@@ -138,7 +142,7 @@ func (o *GetEnvOptions) Run() error {
 		if o.PreviewOnly {
 			table.AddRow("PULL REQUEST", "NAMESPACE", "APPLICATION")
 		} else {
-			table.AddRow("NAME", "LABEL", "KIND", "PROMOTE", "NAMESPACE", "ORDER", "CLUSTER", "SOURCE", "REF", "PR")
+			table.AddRow("NAME", "LABEL", "KIND", "PROMOTE", "NAMESPACE", "ORDER", "CLUSTER", "SOURCE", "REF", "PR", "STATUS")
 		}
 
 		for _, env := range environments {
@@ -146,7 +150,7 @@ func (o *GetEnvOptions) Run() error {
 			if o.PreviewOnly {
 				table.AddRow(spec.PullRequestURL, spec.Namespace, util.ColorInfo(spec.PreviewGitSpec.ApplicationURL))
 			} else {
-				table.AddRow(env.Name, spec.Label, kindString(spec), string(spec.PromotionStrategy), spec.Namespace, util.Int32ToA(spec.Order), spec.Cluster, spec.Source.URL, spec.Source.Ref, spec.PullRequestURL)
+				table.AddRow(env.Name, spec.Label, kindString(spec), string(spec.PromotionStrategy), spec.Namespace, util.Int32ToA(spec.Order), spec.Cluster, spec.Source.URL, spec.Source.Ref, spec.PullRequestURL, syncStatusString(&env.Status))
 			}
 		}
 		table.Render()
@@ -162,6 +166,17 @@ func kindString(spec *v1.EnvironmentSpec) string {
 	return answer
 }
 
+// syncStatusString returns the sync status of the environment including the number of drifted applications
+func syncStatusString(status *v1.EnvironmentStatus) string {
+	switch status.SyncStatus {
+	case v1.EnvironmentSyncStatusFailed:
+		return util.ColorError(string(status.SyncStatus))
+	case v1.EnvironmentSyncStatusDrifted:
+		return util.ColorWarning(fmt.Sprintf("%s (%d)", status.SyncStatus, status.DriftedResources))
+	}
+	return string(status.SyncStatus)
+}
+
 func (o *GetEnvOptions) filterEnvironments(envs []v1.Environment) []v1.Environment {
 	answer := []v1.Environment{}
 	for _, e := range envs {
@@ -0,0 +1,159 @@
+package get
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/pkg/kube/services"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/mergequeue"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// GetMergeQueueOptions the command line options
+type GetMergeQueueOptions struct {
+	GetOptions
+
+	URL        string
+	Repository string
+}
+
+var (
+	getMergeQueueLong = templates.LongDesc(`
+		Display the merge queues of the repositories: the approved pull requests waiting to be merged.
+
+		Approved pull requests are tested together in a batch before they are merged. The pull requests of the current batch are displayed first followed by the queued pull requests in the order they are merged.
+		Use the 'mergeQueue' of the merger of a scheduler to limit the size of the batches of a repository.
+`)
+
+	getMergeQueueExample = templates.Examples(`
+		# Display the merge queues of all the repositories
+		jx get merge-queue
+
+		# Display the merge queue of a repository
+		jx get merge-queue --repo myorg/myapp
+
+		# Display the merge queues as YAML
+		jx get merge-queue -o yaml
+	`)
+)
+
+// NewCmdGetMergeQueue creates the command
+func NewCmdGetMergeQueue(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &GetMergeQueueOptions{
+		GetOptions: GetOptions{
+			CommonOptions: commonOpts,
+		},
+	}
+
+	cmd := &cobra.Command{
+		Use:     "merge-queue",
+		Short:   "Display the approved pull requests waiting to be tested and merged",
+		Long:    getMergeQueueLong,
+		Example: getMergeQueueExample,
+		Aliases: []string{"mergequeue", "mq"},
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.Flags().StringVarP(&options.URL, "url", "u", "", "The URL of the merge controller serving the merge pools. Defaults to the URL of the tide or lighthouse-keeper service")
+	cmd.Flags().StringVarP(&options.Repository, "repo", "r", "", "Only display the merge queue of the repository, either as name or owner/name")
+	options.AddGetFlags(cmd)
+	return cmd
+}
+
+// Run implements this command
+func (o *GetMergeQueueOptions) Run() error {
+	kubeClient, ns, err := o.KubeClientAndDevNamespace()
+	if err != nil {
+		return err
+	}
+	jxClient, _, err := o.JXClient()
+	if err != nil {
+		return err
+	}
+	u := o.URL
+	if u == "" {
+		u, err = o.findMergeControllerURL(kubeClient, ns)
+		if err != nil {
+			return err
+		}
+	}
+	pools, err := mergequeue.LoadPools(nil, u)
+	if err != nil {
+		return err
+	}
+	activities, err := jxClient.JenkinsV1().PipelineActivities(ns).List(metav1.ListOptions{
+		LabelSelector: v1.LabelBranch + "=" + mergequeue.BatchBranch,
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to list the batch PipelineActivities in namespace %s", ns)
+	}
+	queues := []mergequeue.Entry{}
+	for _, entry := range mergequeue.BuildQueues(pools, activities.Items) {
+		if o.matchesRepository(&entry) {
+			queues = append(queues, entry)
+		}
+	}
+	if o.Output != "" {
+		return o.renderResult(queues, o.Output)
+	}
+	if len(queues) == 0 {
+		log.Logger().Info("No pull requests are waiting to be merged")
+		return nil
+	}
+
+	table := o.CreateTable()
+	table.AddRow("REPOSITORY", "BRANCH", "ACTION", "BATCH", "BUILD", "STATUS", "QUEUED")
+	for _, entry := range queues {
+		action := entry.Action
+		if entry.Error != "" {
+			action = util.ColorWarning(entry.Error)
+		}
+		table.AddRow(entry.Owner+"/"+entry.Repository, entry.Branch, action, pullRequestNumbers(entry.Batch),
+			entry.BatchBuild, string(entry.BatchStatus), pullRequestNumbers(entry.Queued))
+	}
+	table.Render()
+	return nil
+}
+
+func (o *GetMergeQueueOptions) findMergeControllerURL(kubeClient kubernetes.Interface, ns string) (string, error) {
+	for _, name := range mergequeue.ServiceNames {
+		u, err := services.FindServiceURL(kubeClient, ns, name)
+		if err == nil && u != "" {
+			return u, nil
+		}
+	}
+	return "", fmt.Errorf("could not find the URL of the %s service in namespace %s so please specify it via --url",
+		strings.Join(mergequeue.ServiceNames, " or "), ns)
+}
+
+func (o *GetMergeQueueOptions) matchesRepository(entry *mergequeue.Entry) bool {
+	if o.Repository == "" {
+		return true
+	}
+	if strings.Contains(o.Repository, "/") {
+		return o.Repository == entry.Owner+"/"+entry.Repository
+	}
+	return o.Repository == entry.Repository
+}
+
+func pullRequestNumbers(numbers []int) string {
+	answer := []string{}
+	for _, n := range numbers {
+		answer = append(answer, "#"+strconv.Itoa(n))
+	}
+	return strings.Join(answer, " ")
+}
@@ -0,0 +1,152 @@
+package get
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/jenkins-x/jx/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/tekton"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/spf13/cobra"
+)
+
+// GetPipelineQueueOptions the command line options
+type GetPipelineQueueOptions struct {
+	GetOptions
+}
+
+// PipelineQueueEntry a queued pipeline
+type PipelineQueueEntry struct {
+	Position    int       `json:"position"`
+	PipelineRun string    `json:"pipelineRun"`
+	Activity    string    `json:"activity"`
+	Owner       string    `json:"owner,omitempty"`
+	Repository  string    `json:"repository,omitempty"`
+	Branch      string    `json:"branch,omitempty"`
+	Build       string    `json:"build,omitempty"`
+	Kind        string    `json:"kind,omitempty"`
+	Priority    int       `json:"priority"`
+	QueuedTime  time.Time `json:"queuedTime"`
+}
+
+var (
+	getPipelineQueueLong = templates.LongDesc(`
+		Display the pipelines waiting for running pipelines to complete as the pipeline concurrency limits of the team are reached.
+
+		The queued pipelines start in the order they are displayed: highest priority first then first in first out.
+		Use 'jx edit pipeline-concurrency' to change the limits and priorities.
+`)
+
+	getPipelineQueueExample = templates.Examples(`
+		# Display the pipeline queue
+		jx get pipeline-queue
+
+		# Display the pipeline queue as YAML
+		jx get pipeline-queue -o yaml
+	`)
+)
+
+// NewCmdGetPipelineQueue creates the command
+func NewCmdGetPipelineQueue(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &GetPipelineQueueOptions{
+		GetOptions: GetOptions{
+			CommonOptions: commonOpts,
+		},
+	}
+
+	cmd := &cobra.Command{
+		Use:     "pipeline-queue",
+		Short:   "Display the pipelines waiting for running pipelines to complete",
+		Long:    getPipelineQueueLong,
+		Example: getPipelineQueueExample,
+		Aliases: []string{"pipelinequeue", "queue"},
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	options.AddGetFlags(cmd)
+	return cmd
+}
+
+// Run implements this command
+func (o *GetPipelineQueueOptions) Run() error {
+	jxClient, ns, err := o.JXClientAndDevNamespace()
+	if err != nil {
+		return err
+	}
+	tektonClient, _, err := o.TektonClient()
+	if err != nil {
+		return err
+	}
+	settings, err := o.TeamSettings()
+	if err != nil {
+		return err
+	}
+	concurrency := settings.PipelineConcurrency
+
+	queue, err := tekton.GetPipelineQueue(jxClient, ns, concurrency)
+	if err != nil {
+		return err
+	}
+	entries := []PipelineQueueEntry{}
+	for i, queued := range queue {
+		labels := queued.PipelineRun.Labels
+		entries = append(entries, PipelineQueueEntry{
+			Position:    i + 1,
+			PipelineRun: queued.PipelineRun.Name,
+			Activity:    queued.Activity.Name,
+			Owner:       labels[tekton.LabelOwner],
+			Repository:  labels[tekton.LabelRepo],
+			Branch:      labels[tekton.LabelBranch],
+			Build:       labels[tekton.LabelBuild],
+			Kind:        labels[tekton.LabelKind],
+			Priority:    queued.Priority,
+			QueuedTime:  queued.QueuedTime,
+		})
+	}
+	if o.Output != "" {
+		return o.renderResult(entries, o.Output)
+	}
+
+	running, err := tekton.GetRunningPipelineRuns(tektonClient, ns)
+	if err != nil {
+		return err
+	}
+	if concurrency.IsLimited() {
+		log.Logger().Infof("Running pipelines: %s maximum: %s maximum per repository: %s", util.ColorInfo(len(running)),
+			util.ColorInfo(limitText(concurrency.MaxRuns)), util.ColorInfo(limitText(concurrency.MaxRunsPerRepository)))
+	} else {
+		log.Logger().Infof("Running pipelines: %s. The pipeline concurrency is not limited", util.ColorInfo(len(running)))
+	}
+	if len(entries) == 0 {
+		log.Logger().Info("No pipelines are queued")
+		return nil
+	}
+
+	table := o.CreateTable()
+	table.AddRow("POSITION", "REPOSITORY", "BRANCH", "BUILD", "KIND", "PRIORITY", "WAITING")
+	for _, entry := range entries {
+		repository := entry.Repository
+		if entry.Owner != "" {
+			repository = entry.Owner + "/" + repository
+		}
+		waiting := time.Since(entry.QueuedTime).Round(time.Second)
+		table.AddRow(strconv.Itoa(entry.Position), repository, entry.Branch, entry.Build, entry.Kind, strconv.Itoa(entry.Priority), waiting.String())
+	}
+	table.Render()
+	return nil
+}
+
+func limitText(limit int) string {
+	if limit <= 0 {
+		return "unlimited"
+	}
+	return fmt.Sprintf("%d", limit)
+}
@@ -2,9 +2,17 @@ package get
 
 import (
 	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/jenkins-x/jx/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/pkg/gits"
+	"github.com/jenkins-x/jx/pkg/kube"
 	"github.com/jenkins-x/jx/pkg/kube/naming"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/util"
 
 	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
 	"github.com/jenkins-x/jx/pkg/cmd/opts"
@@ -13,22 +21,66 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+const (
+	// PreviewSortName sorts the previews by name
+	PreviewSortName = "name"
+	// PreviewSortAge sorts the previews by age, oldest first
+	PreviewSortAge = "age"
+	// PreviewSortCPU sorts the previews by CPU usage, highest first
+	PreviewSortCPU = "cpu"
+	// PreviewSortMemory sorts the previews by memory usage, highest first
+	PreviewSortMemory = "memory"
+
+	// PullRequestStateOpen the Pull Request of a preview is open
+	PullRequestStateOpen = "open"
+	// PullRequestStateMerged the Pull Request of a preview is merged
+	PullRequestStateMerged = "merged"
+	// PullRequestStateClosed the Pull Request of a preview is closed without being merged
+	PullRequestStateClosed = "closed"
+)
+
+// PreviewSortValues the possible values of the --sort option
+var PreviewSortValues = []string{PreviewSortName, PreviewSortAge, PreviewSortCPU, PreviewSortMemory}
+
 // GetPreviewOptions containers the CLI options
 type GetPreviewOptions struct {
 	GetEnvOptions
 
-	Current bool
+	Current      bool
+	Sort         string
+	DeleteMerged bool
+}
+
+// PreviewInfo the details of a preview environment
+type PreviewInfo struct {
+	Environment      *v1.Environment
+	PullRequestState string
+	Commit           string
+	CPUMillis        int64
+	MemoryBytes      int64
+	HasMetrics       bool
 }
 
 var (
 	getPreviewLong = templates.LongDesc(`
 		Display one or more preview environments.
-` + helper.SeeAlsoText("jx get env"))
+
+		For each preview the state of its Pull Request, the last deployed commit, its age and the CPU and memory
+		usage of its pods are displayed. The resource usage requires the metrics server to be installed in the cluster.
+
+		The --delete-merged option deletes the previews whose Pull Requests have been merged or closed.
+` + helper.SeeAlsoText("jx get env", "jx delete preview", "jx gc previews"))
 
 	getPreviewExample = templates.Examples(`
 		# List all preview environments
 		jx get previews
 
+		# List the preview environments using the most memory first
+		jx get previews --sort memory
+
+		# Delete the preview environments whose Pull Requests are merged or closed
+		jx get previews --delete-merged
+
 		# View the current preview environment URL
 		# inside a CI pipeline
 		jx get preview --current
@@ -59,6 +111,8 @@ func NewCmdGetPreview(commonOpts *opts.CommonOptions) *cobra.Command {
 	}
 
 	cmd.Flags().BoolVarP(&options.Current, "current", "c", false, "Output the URL of the current Preview application the current pipeline just deployed")
+	cmd.Flags().StringVarP(&options.Sort, "sort", "", PreviewSortName, "The order of the previews. Possible values: "+strings.Join(PreviewSortValues, ", "))
+	cmd.Flags().BoolVarP(&options.DeleteMerged, "delete-merged", "", false, "Deletes the previews whose Pull Requests are merged or closed")
 
 	options.AddGetFlags(cmd)
 	return cmd
@@ -70,7 +124,222 @@ func (o *GetPreviewOptions) Run() error {
 		return o.CurrentPreviewUrl()
 	}
 	o.PreviewOnly = true
-	return o.GetEnvOptions.Run()
+	if len(o.Args) > 0 {
+		return o.GetEnvOptions.Run()
+	}
+	if o.Sort == "" {
+		o.Sort = PreviewSortName
+	}
+	if util.StringArrayIndex(PreviewSortValues, o.Sort) < 0 {
+		return util.InvalidOption("sort", o.Sort, PreviewSortValues)
+	}
+
+	jxClient, ns, err := o.JXClientAndDevNamespace()
+	if err != nil {
+		return err
+	}
+	envs, err := jxClient.JenkinsV1().Environments(ns).List(metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	environments := o.filterEnvironments(envs.Items)
+	if len(environments) == 0 {
+		log.Logger().Infof("No preview environments found")
+		return nil
+	}
+	kube.SortEnvironments(environments)
+
+	previews := make([]*PreviewInfo, 0, len(environments))
+	for i := range environments {
+		env := &environments[i]
+		previews = append(previews, &PreviewInfo{
+			Environment: env,
+			Commit:      env.Annotations[kube.AnnotationPreviewCommit],
+		})
+	}
+	o.addResourceUsage(previews)
+	o.addPullRequestStates(previews)
+
+	if o.DeleteMerged {
+		previews, err = o.deleteMergedPreviews(ns, previews)
+		if err != nil {
+			return err
+		}
+	}
+	SortPreviews(previews, o.Sort)
+
+	if o.Output != "" {
+		envs.Items = nil
+		for _, p := range previews {
+			envs.Items = append(envs.Items, *p.Environment)
+		}
+		return o.renderResult(envs, o.Output)
+	}
+
+	now := time.Now()
+	table := o.CreateTable()
+	table.AddRow("PULL REQUEST", "STATE", "COMMIT", "NAMESPACE", "AGE", "CPU", "MEMORY", "APPLICATION")
+	for _, p := range previews {
+		env := p.Environment
+		cpu := ""
+		memory := ""
+		if p.HasMetrics {
+			cpu = fmt.Sprintf("%dm", p.CPUMillis)
+			memory = fmt.Sprintf("%dMi", p.MemoryBytes/(1024*1024))
+		}
+		commit := p.Commit
+		if len(commit) > 7 {
+			commit = commit[:7]
+		}
+		table.AddRow(env.Spec.PullRequestURL, p.PullRequestState, commit, env.Spec.Namespace,
+			previewAge(now.Sub(env.CreationTimestamp.Time)), cpu, memory, util.ColorInfo(env.Spec.PreviewGitSpec.ApplicationURL))
+	}
+	table.Render()
+	return nil
+}
+
+// addResourceUsage adds the CPU and memory usage of the pods of each preview if the metrics server is available
+func (o *GetPreviewOptions) addResourceUsage(previews []*PreviewInfo) {
+	metricsClient, err := o.GetFactory().CreateMetricsClient()
+	if err != nil {
+		log.Logger().Debugf("Not displaying the resource usage of the previews: %s", err)
+		return
+	}
+	for _, p := range previews {
+		ns := p.Environment.Spec.Namespace
+		if ns == "" {
+			continue
+		}
+		podMetrics, err := metricsClient.MetricsV1beta1().PodMetricses(ns).List(metav1.ListOptions{})
+		if err != nil {
+			// the metrics server is most likely not installed so lets not try the other previews
+			log.Logger().Debugf("Not displaying the resource usage of the previews as the pod metrics of namespace %s are not available: %s", ns, err)
+			return
+		}
+		p.HasMetrics = true
+		for _, pm := range podMetrics.Items {
+			for _, c := range pm.Containers {
+				p.CPUMillis += c.Usage.Cpu().MilliValue()
+				p.MemoryBytes += c.Usage.Memory().Value()
+			}
+		}
+	}
+}
+
+// addPullRequestStates adds the state of the Pull Request of each preview
+func (o *GetPreviewOptions) addPullRequestStates(previews []*PreviewInfo) {
+	providers := map[string]gits.GitProvider{}
+	for _, p := range previews {
+		env := p.Environment
+		prNumber, err := strconv.Atoi(env.Spec.PreviewGitSpec.Name)
+		if err != nil {
+			continue
+		}
+		gitInfo, err := gits.ParseGitURL(env.Spec.Source.URL)
+		if err != nil {
+			log.Logger().Debugf("Ignoring the git URL %s of preview %s: %s", env.Spec.Source.URL, env.Name, err)
+			continue
+		}
+		provider := providers[gitInfo.Host]
+		if provider == nil {
+			provider, err = o.GitProviderForURL(env.Spec.Source.URL, "git provider")
+			if err != nil {
+				log.Logger().Warnf("Failed to create the git provider for %s: %s", env.Spec.Source.URL, err)
+				continue
+			}
+			providers[gitInfo.Host] = provider
+		}
+		pr, err := provider.GetPullRequest(gitInfo.Organisation, gitInfo, prNumber)
+		if err != nil {
+			log.Logger().Warnf("Failed to get Pull Request %s of preview %s: %s", env.Spec.PreviewGitSpec.Name, env.Name, err)
+			continue
+		}
+		p.PullRequestState = pullRequestState(pr)
+		if p.Commit == "" {
+			p.Commit = pr.LastCommitSha
+		}
+	}
+}
+
+// deleteMergedPreviews deletes the previews whose Pull Requests are merged or closed returning the remaining ones
+func (o *GetPreviewOptions) deleteMergedPreviews(ns string, previews []*PreviewInfo) ([]*PreviewInfo, error) {
+	var answer []*PreviewInfo
+	var merged []*PreviewInfo
+	for _, p := range previews {
+		if p.PullRequestState == PullRequestStateMerged || p.PullRequestState == PullRequestStateClosed {
+			merged = append(merged, p)
+		} else {
+			answer = append(answer, p)
+		}
+	}
+	if len(merged) == 0 {
+		log.Logger().Infof("No previews with merged or closed Pull Requests found")
+		return answer, nil
+	}
+	if !o.BatchMode {
+		for _, p := range merged {
+			log.Logger().Infof("  %s (%s)", p.Environment.Spec.PullRequestURL, p.PullRequestState)
+		}
+		if !util.Confirm(fmt.Sprintf("Delete the %d previews of merged or closed Pull Requests?", len(merged)), true, "The helm releases, namespaces and environments of the previews are deleted", o.GetIOFileHandles()) {
+			return previews, nil
+		}
+	}
+	for _, p := range merged {
+		err := o.DeletePreviewEnvironment(ns, p.Environment)
+		if err != nil {
+			return answer, err
+		}
+	}
+	return answer, nil
+}
+
+// SortPreviews sorts the previews by the given sort option
+func SortPreviews(previews []*PreviewInfo, sortBy string) {
+	sort.SliceStable(previews, func(i, j int) bool {
+		a := previews[i]
+		b := previews[j]
+		switch sortBy {
+		case PreviewSortAge:
+			return a.Environment.CreationTimestamp.Before(&b.Environment.CreationTimestamp)
+		case PreviewSortCPU:
+			return a.CPUMillis > b.CPUMillis
+		case PreviewSortMemory:
+			return a.MemoryBytes > b.MemoryBytes
+		default:
+			return a.Environment.Name < b.Environment.Name
+		}
+	})
+}
+
+func pullRequestState(pr *gits.GitPullRequest) string {
+	if pr.Merged != nil && *pr.Merged {
+		return PullRequestStateMerged
+	}
+	if pr.State == nil {
+		return ""
+	}
+	state := strings.ToLower(*pr.State)
+	switch {
+	case strings.HasPrefix(state, "open"):
+		return PullRequestStateOpen
+	case strings.HasPrefix(state, "merged"):
+		return PullRequestStateMerged
+	case strings.HasPrefix(state, "clos"), strings.HasPrefix(state, "declined"), strings.HasPrefix(state, "superseded"):
+		return PullRequestStateClosed
+	}
+	return state
+}
+
+// previewAge returns a short description of the age of a preview such as 5d or 3h
+func previewAge(d time.Duration) string {
+	switch {
+	case d >= 24*time.Hour:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	case d >= time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	}
 }
 
 func (o *GetPreviewOptions) CurrentPreviewUrl() error {
@@ -4,6 +4,7 @@ import (
 	"io/ioutil"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/jenkins-x/jx/pkg/kube"
 
@@ -21,6 +22,7 @@ import (
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestGetPreview(t *testing.T) {
@@ -145,3 +147,37 @@ var _ = Describe("get preview", func() {
 		})
 	})
 })
+
+func TestSortPreviews(t *testing.T) {
+	t.Parallel()
+	now := time.Now()
+	preview := func(name string, age time.Duration, cpu int64, memory int64) *get.PreviewInfo {
+		env := kube.NewPreviewEnvironment(name)
+		env.CreationTimestamp = v1.NewTime(now.Add(-age))
+		return &get.PreviewInfo{Environment: env, CPUMillis: cpu, MemoryBytes: memory, HasMetrics: true}
+	}
+	previews := []*get.PreviewInfo{
+		preview("b", time.Hour, 300, 100),
+		preview("c", 3*time.Hour, 100, 300),
+		preview("a", 2*time.Hour, 200, 200),
+	}
+	names := func() []string {
+		var answer []string
+		for _, p := range previews {
+			answer = append(answer, p.Environment.Name)
+		}
+		return answer
+	}
+
+	get.SortPreviews(previews, get.PreviewSortName)
+	assert.Equal(t, []string{"a", "b", "c"}, names())
+
+	get.SortPreviews(previews, get.PreviewSortAge)
+	assert.Equal(t, []string{"c", "a", "b"}, names())
+
+	get.SortPreviews(previews, get.PreviewSortCPU)
+	assert.Equal(t, []string{"b", "a", "c"}, names())
+
+	get.SortPreviews(previews, get.PreviewSortMemory)
+	assert.Equal(t, []string{"c", "a", "b"}, names())
+}
@@ -73,14 +73,14 @@ func (o *GetQuickstartLocationOptions) Run() error {
 	}
 
 	table := o.CreateTable()
-	table.AddRow("GIT SERVER", "KIND", "OWNER", "INCLUDES", "EXCLUDES")
+	table.AddRow("GIT SERVER", "KIND", "OWNER", "CATALOG", "INCLUDES", "EXCLUDES")
 
 	for _, location := range locations {
 		kind := location.GitKind
 		if kind == "" {
 			kind = gits.KindGitHub
 		}
-		table.AddRow(location.GitURL, kind, location.Owner, strings.Join(location.Includes, ", "), strings.Join(location.Excludes, ", "))
+		table.AddRow(location.GitURL, kind, location.Owner, location.Catalog, strings.Join(location.Includes, ", "), strings.Join(location.Excludes, ", "))
 	}
 	table.Render()
 	return nil
@@ -30,6 +30,9 @@ var (
 	getQuickstartsExample = templates.Examples(`
 		# List all the available quickstarts
 		jx get quickstarts
+
+		# Search the quickstarts of a catalog by text and tag
+		jx get quickstarts --catalog myorg/quickstarts --filter database --tag java
 	`)
 )
 
@@ -58,6 +61,7 @@ func NewCmdGetQuickstarts(commonOpts *opts.CommonOptions) *cobra.Command {
 	cmd.Flags().StringArrayVarP(&options.GitHubOrganisations, "organisations", "g", []string{}, "The GitHub organisations to query for quickstarts")
 	cmd.Flags().StringArrayVarP(&options.Filter.Tags, "tag", "t", []string{}, "The tags on the quickstarts to filter")
 	cmd.Flags().StringVarP(&options.Filter.Text, "filter", "f", "", "The text filter")
+	cmd.Flags().StringVarP(&options.Filter.Catalog, "catalog", "", "", "The quickstart catalog to filter on")
 	cmd.Flags().StringVarP(&options.Filter.Owner, "owner", "", "", "The owner to filter on")
 	cmd.Flags().StringVarP(&options.Filter.Language, "language", "l", "", "The language to filter on")
 	cmd.Flags().StringVarP(&options.Filter.Framework, "framework", "", "", "The framework to filter on")
@@ -81,14 +85,14 @@ func (o *GetQuickstartsOptions) Run() error {
 	if o.ShortFormat {
 		table.AddRow("NAME")
 	} else {
-		table.AddRow("NAME", "OWNER", "VERSION", "LANGUAGE", "URL")
+		table.AddRow("NAME", "OWNER", "VERSION", "LANGUAGE", "CATALOG", "DESCRIPTION", "URL")
 	}
 
 	for _, qs := range filteredQuickstarts {
 		if o.ShortFormat {
 			table.AddRow(qs.Name)
 		} else {
-			table.AddRow(qs.Name, qs.Owner, qs.Version, qs.Language, qs.DownloadZipURL)
+			table.AddRow(qs.Name, qs.Owner, qs.Version, qs.Language, qs.Catalog, qs.Description, qs.DownloadZipURL)
 		}
 	}
 	table.Render()
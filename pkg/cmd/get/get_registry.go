@@ -0,0 +1,99 @@
+package get
+
+import (
+	"github.com/jenkins-x/jx/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/pkg/kube"
+	"github.com/jenkins-x/jx/pkg/registry"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/spf13/cobra"
+)
+
+// GetRegistryOptions contains the CLI options
+type GetRegistryOptions struct {
+	GetOptions
+}
+
+// RegistryConfig the effective container registry configuration of the team
+type RegistryConfig struct {
+	Host               string        `json:"host"`
+	Kind               registry.Kind `json:"kind"`
+	Organisation       string        `json:"organisation,omitempty"`
+	Region             string        `json:"region,omitempty"`
+	Identity           string        `json:"identity"`
+	CreateRepositories bool          `json:"createRepositories"`
+}
+
+var (
+	getRegistryLong = templates.LongDesc(`
+		Display the effective container registry configuration of the current team.
+
+		Shows the kind of registry, how the pipelines authenticate to it and whether repositories are created before pushing images.
+` + helper.SeeAlsoText("jx step pre build"))
+
+	getRegistryExample = templates.Examples(`
+		# Display the container registry of the current team
+		jx get registry
+
+		# Display the container registry as YAML
+		jx get registry -o yaml
+	`)
+)
+
+// NewCmdGetRegistry creates the new command for: jx get registry
+func NewCmdGetRegistry(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &GetRegistryOptions{
+		GetOptions: GetOptions{
+			CommonOptions: commonOpts,
+		},
+	}
+	cmd := &cobra.Command{
+		Use:     "registry",
+		Short:   "Display the container registry configuration of the current team",
+		Long:    getRegistryLong,
+		Example: getRegistryExample,
+		Aliases: []string{"registries"},
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+
+	options.AddGetFlags(cmd)
+	return cmd
+}
+
+// Run implements this command
+func (o *GetRegistryOptions) Run() error {
+	kubeClient, ns, err := o.KubeClientAndDevNamespace()
+	if err != nil {
+		return err
+	}
+	host := o.GetDockerRegistry(nil)
+	region, _ := kube.ReadRegion(kubeClient, ns)
+	reg := registry.NewRegistry(host, region)
+	if reg.Kind() != registry.KindECR {
+		region = ""
+	} else if r := registry.ECRRegion(host); r != "" {
+		region = r
+	}
+	answer := &RegistryConfig{
+		Host:               host,
+		Kind:               reg.Kind(),
+		Organisation:       o.GetDockerRegistryOrg(nil, nil),
+		Region:             region,
+		Identity:           reg.Identity(),
+		CreateRepositories: registry.RequiresRepositories(reg.Kind()),
+	}
+	if o.Output != "" {
+		return o.renderResult(answer, o.Output)
+	}
+	table := o.CreateTable()
+	table.AddRow("HOST", "KIND", "ORGANISATION", "REGION", "IDENTITY", "CREATE REPOSITORIES")
+	table.AddRow(answer.Host, string(answer.Kind), answer.Organisation, answer.Region, answer.Identity, util.YesNo(answer.CreateRepositories))
+	table.Render()
+	return nil
+}
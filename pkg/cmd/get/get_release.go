@@ -2,9 +2,16 @@ package get
 
 import (
 	"fmt"
+	"strings"
 
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
 	"github.com/jenkins-x/jx/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/pkg/environments"
+	"github.com/jenkins-x/jx/pkg/helm"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	"github.com/jenkins-x/jx/pkg/client/clientset/versioned"
 	"github.com/jenkins-x/jx/pkg/cmd/opts"
 	"github.com/jenkins-x/jx/pkg/cmd/templates"
 	"github.com/jenkins-x/jx/pkg/kube"
@@ -19,6 +26,18 @@ type GetReleaseOptions struct {
 
 	Filter    string
 	Namespace string
+	App       string
+}
+
+// AppRelease represents a release of an application along with its build provenance and the environments running it
+type AppRelease struct {
+	Version         string   `json:"version"`
+	ReleaseNotesURL string   `json:"releaseNotesURL,omitempty"`
+	Pipeline        string   `json:"pipeline,omitempty"`
+	Build           string   `json:"build,omitempty"`
+	BuildURL        string   `json:"buildURL,omitempty"`
+	Commit          string   `json:"commit,omitempty"`
+	Environments    []string `json:"environments,omitempty"`
 }
 
 var (
@@ -32,6 +51,9 @@ var (
 
 		# Filter the releases 
 		jx get release -f myapp
+
+		# Display the releases of an app along with their pipeline, commit and the environments running them
+		jx get releases --app myapp
 	`)
 )
 
@@ -57,6 +79,7 @@ func NewCmdGetRelease(commonOpts *opts.CommonOptions) *cobra.Command {
 	}
 	cmd.Flags().StringVarP(&options.Filter, "filter", "f", "", "Filter the releases with the given text")
 	cmd.Flags().StringVarP(&options.Namespace, "namespace", "n", "", "The namespace to view or defaults to the current namespace")
+	cmd.Flags().StringVarP(&options.App, "app", "a", "", "Display the releases of the given app along with their build provenance and the environments running them")
 
 	options.AddGetFlags(cmd)
 	return cmd
@@ -72,6 +95,9 @@ func (o *GetReleaseOptions) Run() error {
 	if ns == "" {
 		ns = curNs
 	}
+	if o.App != "" {
+		return o.showAppReleases(jxClient, ns)
+	}
 	releases, err := kube.GetOrderedReleases(jxClient, ns, o.Filter)
 	if err != nil {
 		return err
@@ -93,3 +119,153 @@ func (o *GetReleaseOptions) Run() error {
 	table.Render()
 	return nil
 }
+
+func (o *GetReleaseOptions) showAppReleases(jxClient versioned.Interface, ns string) error {
+	releases, err := kube.GetOrderedReleases(jxClient, ns, o.App)
+	if err != nil {
+		return err
+	}
+	activities, err := jxClient.JenkinsV1().PipelineActivities(ns).List(metav1.ListOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "listing the PipelineActivities in namespace %s", ns)
+	}
+	envMap, envNames, err := kube.GetOrderedEnvironments(jxClient, ns)
+	if err != nil {
+		return errors.Wrapf(err, "listing the environments in namespace %s", ns)
+	}
+	envVersions := map[string]string{}
+	deployedEnvNames := []string{}
+	for _, name := range envNames {
+		env := envMap[name]
+		if !kube.IsPermanentEnvironment(env) || env.Spec.Source.URL == "" {
+			continue
+		}
+		requirements, err := environments.LoadEnvironmentRequirements(o.Git(), env)
+		if err != nil {
+			log.Logger().Warnf("Failed to find the versions deployed in environment %s: %s", name, err)
+			continue
+		}
+		version := appVersionInRequirements(requirements, o.App)
+		if version != "" {
+			envVersions[name] = version
+			deployedEnvNames = append(deployedEnvNames, name)
+		}
+	}
+
+	appReleases := CreateAppReleases(o.App, releases, activities.Items, envVersions, deployedEnvNames)
+	if o.Output != "" {
+		return o.renderResult(appReleases, o.Output)
+	}
+	if len(appReleases) == 0 {
+		log.Logger().Infof("No Releases found for app %s in namespace %s.", util.ColorInfo(o.App), util.ColorInfo(ns))
+		return nil
+	}
+	table := o.CreateTable()
+	table.AddRow("VERSION", "ENVIRONMENTS", "PIPELINE", "BUILD", "COMMIT", "RELEASE NOTES")
+	for _, r := range appReleases {
+		table.AddRow(r.Version, strings.Join(r.Environments, ", "), r.Pipeline, r.Build, shortCommit(r.Commit), r.ReleaseNotesURL)
+	}
+	table.Render()
+	return nil
+}
+
+// CreateAppReleases correlates the Release resources of the app with the PipelineActivities which built them and the
+// versions of the app in each environment. The envNames are used to order the environments of each release.
+// Versions running in an environment without a Release resource are included at the end
+func CreateAppReleases(app string, releases []v1.Release, activities []v1.PipelineActivity, envVersions map[string]string, envNames []string) []AppRelease {
+	answer := []AppRelease{}
+	found := map[string]bool{}
+	for _, release := range releases {
+		if release.Spec.Name != app {
+			continue
+		}
+		version := release.Spec.Version
+		r := AppRelease{
+			Version:         version,
+			ReleaseNotesURL: release.Spec.ReleaseNotesURL,
+		}
+		if len(release.Spec.Commits) > 0 {
+			r.Commit = release.Spec.Commits[0].SHA
+		}
+		activity := findReleaseActivity(&release, activities)
+		if activity != nil {
+			r.Pipeline = activity.Spec.Pipeline
+			r.Build = activity.Spec.Build
+			r.BuildURL = activity.Spec.BuildURL
+			if activity.Spec.LastCommitSHA != "" {
+				r.Commit = activity.Spec.LastCommitSHA
+			}
+			if r.ReleaseNotesURL == "" {
+				r.ReleaseNotesURL = activity.Spec.ReleaseNotesURL
+			}
+		}
+		r.Environments = environmentsRunningVersion(version, envVersions, envNames)
+		found[trimVersionPrefix(version)] = true
+		answer = append(answer, r)
+	}
+	for _, name := range envNames {
+		version := envVersions[name]
+		if version == "" || found[trimVersionPrefix(version)] {
+			continue
+		}
+		found[trimVersionPrefix(version)] = true
+		answer = append(answer, AppRelease{
+			Version:      version,
+			Environments: environmentsRunningVersion(version, envVersions, envNames),
+		})
+	}
+	return answer
+}
+
+// findReleaseActivity returns the latest PipelineActivity which built the version of the release or nil if there is none
+func findReleaseActivity(release *v1.Release, activities []v1.PipelineActivity) *v1.PipelineActivity {
+	var answer *v1.PipelineActivity
+	version := trimVersionPrefix(release.Spec.Version)
+	for i := range activities {
+		activity := &activities[i]
+		spec := &activity.Spec
+		if trimVersionPrefix(spec.Version) != version {
+			continue
+		}
+		if release.Spec.GitRepository != "" && spec.GitRepository != release.Spec.GitRepository {
+			continue
+		}
+		if release.Spec.GitOwner != "" && spec.GitOwner != release.Spec.GitOwner {
+			continue
+		}
+		if answer == nil || activity.Spec.StartedTimestamp != nil && (answer.Spec.StartedTimestamp == nil || answer.Spec.StartedTimestamp.Before(activity.Spec.StartedTimestamp)) {
+			answer = activity
+		}
+	}
+	return answer
+}
+
+func environmentsRunningVersion(version string, envVersions map[string]string, envNames []string) []string {
+	answer := []string{}
+	for _, name := range envNames {
+		if trimVersionPrefix(envVersions[name]) == trimVersionPrefix(version) {
+			answer = append(answer, name)
+		}
+	}
+	return answer
+}
+
+func appVersionInRequirements(requirements *helm.Requirements, app string) string {
+	for _, dep := range requirements.Dependencies {
+		if dep != nil && (dep.Name == app || dep.Alias == app) {
+			return dep.Version
+		}
+	}
+	return ""
+}
+
+func trimVersionPrefix(version string) string {
+	return strings.TrimPrefix(version, "v")
+}
+
+func shortCommit(sha string) string {
+	if len(sha) > 7 {
+		return sha[0:7]
+	}
+	return sha
+}
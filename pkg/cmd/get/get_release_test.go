@@ -0,0 +1,104 @@
+package get_test
+
+import (
+	"testing"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/cmd/get"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCreateAppReleases(t *testing.T) {
+	t.Parallel()
+
+	releases := []v1.Release{
+		{
+			Spec: v1.ReleaseSpec{
+				Name:            "myapp",
+				Version:         "v1.0.2",
+				GitOwner:        "myorg",
+				GitRepository:   "myapp",
+				ReleaseNotesURL: "https://github.com/myorg/myapp/releases/tag/v1.0.2",
+			},
+		},
+		{
+			Spec: v1.ReleaseSpec{
+				Name:          "myapp",
+				Version:       "v1.0.1",
+				GitOwner:      "myorg",
+				GitRepository: "myapp",
+				Commits:       []v1.CommitSummary{{SHA: "abc1234567"}},
+			},
+		},
+		{
+			Spec: v1.ReleaseSpec{
+				Name:    "myapp-other",
+				Version: "v3.0.0",
+			},
+		},
+	}
+	older := metav1.Unix(1000, 0)
+	newer := metav1.Unix(2000, 0)
+	activities := []v1.PipelineActivity{
+		{
+			Spec: v1.PipelineActivitySpec{
+				Pipeline:         "myorg/myapp/master",
+				Build:            "3",
+				Version:          "1.0.2",
+				GitOwner:         "myorg",
+				GitRepository:    "myapp",
+				LastCommitSHA:    "def4567890",
+				StartedTimestamp: &older,
+			},
+		},
+		{
+			Spec: v1.PipelineActivitySpec{
+				Pipeline:         "myorg/myapp/master",
+				Build:            "4",
+				Version:          "1.0.2",
+				GitOwner:         "myorg",
+				GitRepository:    "myapp",
+				LastCommitSHA:    "fed4567890",
+				StartedTimestamp: &newer,
+			},
+		},
+		{
+			Spec: v1.PipelineActivitySpec{
+				Pipeline:      "myorg/another/master",
+				Build:         "1",
+				Version:       "1.0.1",
+				GitOwner:      "myorg",
+				GitRepository: "another",
+			},
+		},
+	}
+	envVersions := map[string]string{
+		"staging":    "1.0.2",
+		"production": "1.0.1",
+		"demo":       "0.9.0",
+	}
+	envNames := []string{"staging", "production", "demo"}
+
+	appReleases := get.CreateAppReleases("myapp", releases, activities, envVersions, envNames)
+
+	assert.Equal(t, []get.AppRelease{
+		{
+			Version:         "v1.0.2",
+			ReleaseNotesURL: "https://github.com/myorg/myapp/releases/tag/v1.0.2",
+			Pipeline:        "myorg/myapp/master",
+			Build:           "4",
+			Commit:          "fed4567890",
+			Environments:    []string{"staging"},
+		},
+		{
+			Version:      "v1.0.1",
+			Commit:       "abc1234567",
+			Environments: []string{"production"},
+		},
+		{
+			Version:      "0.9.0",
+			Environments: []string{"demo"},
+		},
+	}, appReleases)
+}
@@ -1,9 +1,18 @@
 package get
 
 import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
 	"github.com/jenkins-x/jx/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/pkg/kube"
 	"github.com/jenkins-x/jx/pkg/kube/services"
+	"github.com/jenkins-x/jx/pkg/log"
 	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
 
 	"github.com/spf13/cobra"
 
@@ -15,20 +24,38 @@ import (
 type GetURLOptions struct {
 	GetOptions
 
-	Namespace    string
-	Environment  string
-	OnlyViewHost bool
+	Namespace        string
+	Environment      string
+	OnlyViewHost     bool
+	AllEnvironments  bool
+	HealthCheck      bool
+	HealthTimeout    time.Duration
+	TLSExpiryWarning time.Duration
+}
+
+// EnvironmentNamespace a namespace to look for services in
+type EnvironmentNamespace struct {
+	Environment string
+	Namespace   string
 }
 
 var (
 	get_url_long = templates.LongDesc(`
 		Display one or more URLs from the running services.
 
+		Use '--all-envs' to list the URLs of all the environments and preview environments in one view.
+
+		Use '--health' to resolve the host of each URL, check it does not respond with a server error and report the
+		issuer and expiry of its TLS certificate. Certificates which are invalid or expire soon are flagged.
+
 `)
 
 	get_url_example = templates.Examples(`
 		# List all URLs in this namespace
 		jx get url
+
+		# List the URLs of all the environments and previews with their TLS certificates and any problems as JSON
+		jx get urls --all-envs --health -o json
 	`)
 )
 
@@ -54,6 +81,11 @@ func NewCmdGetURL(commonOpts *opts.CommonOptions) *cobra.Command {
 		},
 	}
 	options.AddGetUrlFlags(cmd)
+	options.AddGetFlags(cmd)
+	cmd.Flags().BoolVarP(&options.AllEnvironments, "all-envs", "", false, "Lists the URLs of all the environments and preview environments rather than just the current namespace")
+	cmd.Flags().BoolVarP(&options.HealthCheck, "health", "", false, "Checks the DNS, HTTP status and TLS certificate of each URL")
+	cmd.Flags().DurationVarP(&options.HealthTimeout, "health-timeout", "", 5*time.Second, "The timeout of the health check of each URL")
+	cmd.Flags().DurationVarP(&options.TLSExpiryWarning, "tls-expiry-warning", "", 14*24*time.Hour, "Flags TLS certificates which expire within this duration")
 	return cmd
 }
 
@@ -65,36 +97,147 @@ func (o *GetURLOptions) AddGetUrlFlags(cmd *cobra.Command) {
 
 // Run implements this command
 func (o *GetURLOptions) Run() error {
-	client, ns, err := o.KubeClientAndNamespace()
+	client, err := o.KubeClient()
 	if err != nil {
 		return err
 	}
-	if o.Namespace != "" {
-		ns = o.Namespace
-	} else if o.Environment != "" {
-		ns, err = o.FindEnvironmentNamespace(o.Environment)
+	namespaces, err := o.EnvironmentNamespaces(o.AllEnvironments)
+	if err != nil {
+		return err
+	}
+	endpoints := []URLEndpoint{}
+	for _, en := range namespaces {
+		urls, err := services.FindServiceURLs(client, en.Namespace)
 		if err != nil {
-			return err
+			if len(namespaces) == 1 {
+				return err
+			}
+			log.Logger().Debugf("failed to find the URLs in namespace %s: %s", en.Namespace, err)
+			continue
+		}
+		for _, u := range urls {
+			endpoints = append(endpoints, URLEndpoint{
+				Name:        u.Name,
+				Environment: en.Environment,
+				Namespace:   en.Namespace,
+				URL:         u.URL,
+				Host:        util.URLToHostName(u.URL),
+			})
 		}
 	}
-	urls, err := services.FindServiceURLs(client, ns)
-	if err != nil {
-		return err
+	if o.HealthCheck {
+		newEndpointChecker(o.HealthTimeout, o.TLSExpiryWarning).checkAll(endpoints)
+	}
+	if o.Output != "" {
+		return o.renderResult(endpoints, o.Output)
 	}
+
 	table := o.CreateTable()
 	header := "URL"
 	if o.OnlyViewHost {
 		header = "HOST"
 	}
-	table.AddRow("NAME", header)
+	titles := []string{"NAME"}
+	if o.AllEnvironments {
+		titles = append(titles, "ENVIRONMENT")
+	}
+	titles = append(titles, header)
+	if o.HealthCheck {
+		titles = append(titles, "STATUS", "TLS ISSUER", "TLS EXPIRY", "PROBLEMS")
+	}
+	table.AddRow(titles...)
 
-	for _, u := range urls {
-		text := u.URL
+	for _, e := range endpoints {
+		text := e.URL
 		if o.OnlyViewHost {
-			text = util.URLToHostName(text)
+			text = e.Host
+		}
+		row := []string{e.Name}
+		if o.AllEnvironments {
+			row = append(row, e.Environment)
+		}
+		row = append(row, text)
+		if o.HealthCheck {
+			status := ""
+			if e.Status > 0 {
+				status = strconv.Itoa(e.Status)
+			}
+			expiry := ""
+			if e.TLSExpiry != nil {
+				expiry = e.TLSExpiry.Format("2006-01-02")
+			}
+			problems := ""
+			if len(e.Problems) > 0 {
+				problems = util.ColorError(strings.Join(e.Problems, "; "))
+			}
+			row = append(row, status, e.TLSIssuer, expiry, problems)
 		}
-		table.AddRow(u.Name, text)
+		table.AddRow(row...)
 	}
 	table.Render()
 	return nil
 }
+
+// EnvironmentNamespaces returns the namespaces to look for services in. The current namespace is always first and the
+// environments are only included if all is true
+func (o *GetURLOptions) EnvironmentNamespaces(all bool) ([]EnvironmentNamespace, error) {
+	if o.Namespace != "" {
+		return []EnvironmentNamespace{{Namespace: o.Namespace}}, nil
+	}
+	if o.Environment != "" {
+		ns, err := o.FindEnvironmentNamespace(o.Environment)
+		if err != nil {
+			return nil, err
+		}
+		return []EnvironmentNamespace{{Environment: o.Environment, Namespace: ns}}, nil
+	}
+	kubeClient, currentNs, err := o.KubeClientAndNamespace()
+	if err != nil {
+		return nil, err
+	}
+	if !all {
+		return []EnvironmentNamespace{{Namespace: currentNs}}, nil
+	}
+	jxClient, _, err := o.JXClient()
+	if err != nil {
+		return nil, err
+	}
+	devNs, _, err := kube.GetDevNamespace(kubeClient, currentNs)
+	if err != nil {
+		return nil, err
+	}
+	envMap, _, err := kube.GetEnvironments(jxClient, devNs)
+	if err != nil {
+		return nil, errors.Wrapf(err, "listing environments in namespace %s", devNs)
+	}
+	envs := make([]*v1.Environment, 0, len(envMap))
+	for _, env := range envMap {
+		envs = append(envs, env)
+	}
+	// the permanent environments in promotion order followed by the previews
+	sort.Slice(envs, func(i, j int) bool {
+		pi := envs[i].Spec.Kind == v1.EnvironmentKindTypePreview
+		pj := envs[j].Spec.Kind == v1.EnvironmentKindTypePreview
+		if pi != pj {
+			return pj
+		}
+		if envs[i].Spec.Order != envs[j].Spec.Order {
+			return envs[i].Spec.Order < envs[j].Spec.Order
+		}
+		return envs[i].Name < envs[j].Name
+	})
+
+	answer := []EnvironmentNamespace{{Namespace: currentNs}}
+	for _, env := range envs {
+		ns := env.Spec.Namespace
+		if ns == "" {
+			continue
+		}
+		if ns == currentNs {
+			answer[0].Environment = env.Name
+			continue
+		}
+		answer = append(answer, EnvironmentNamespace{Environment: env.Name, Namespace: ns})
+	}
+	return answer, nil
+}
@@ -0,0 +1,130 @@
+package get
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// URLEndpoint an endpoint exposed by a service of an environment along with the results of its health check
+type URLEndpoint struct {
+	Name        string     `json:"name"`
+	Environment string     `json:"environment,omitempty"`
+	Namespace   string     `json:"namespace"`
+	URL         string     `json:"url"`
+	Host        string     `json:"host"`
+	Status      int        `json:"status,omitempty"`
+	TLSIssuer   string     `json:"tlsIssuer,omitempty"`
+	TLSExpiry   *time.Time `json:"tlsExpiry,omitempty"`
+	Problems    []string   `json:"problems,omitempty"`
+}
+
+// endpointChecker checks the DNS, HTTP status and TLS certificate of endpoints
+type endpointChecker struct {
+	client        *http.Client
+	roots         *x509.CertPool
+	lookupHost    func(host string) ([]string, error)
+	expiryWarning time.Duration
+	now           func() time.Time
+}
+
+func newEndpointChecker(timeout time.Duration, expiryWarning time.Duration) *endpointChecker {
+	return &endpointChecker{
+		client: &http.Client{
+			Timeout: timeout,
+			Transport: &http.Transport{
+				Proxy: http.ProxyFromEnvironment,
+				// the certificates are verified by the checker so that the issuer and expiry of invalid
+				// certificates can still be reported
+				TLSClientConfig: &tls.Config{InsecureSkipVerify: true}, // #nosec
+			},
+		},
+		lookupHost:    net.LookupHost,
+		expiryWarning: expiryWarning,
+		now:           time.Now,
+	}
+}
+
+// checkAll checks the endpoints concurrently
+func (c *endpointChecker) checkAll(endpoints []URLEndpoint) {
+	var wg sync.WaitGroup
+	for i := range endpoints {
+		wg.Add(1)
+		go func(e *URLEndpoint) {
+			defer wg.Done()
+			c.check(e)
+		}(&endpoints[i])
+	}
+	wg.Wait()
+}
+
+// check resolves the host of the endpoint, requests it and verifies its TLS certificate recording any problems
+func (c *endpointChecker) check(e *URLEndpoint) {
+	u, err := url.Parse(e.URL)
+	if err != nil {
+		e.Problems = append(e.Problems, fmt.Sprintf("invalid URL: %s", err))
+		return
+	}
+	host := u.Hostname()
+	if net.ParseIP(host) == nil {
+		_, err = c.lookupHost(host)
+		if err != nil {
+			e.Problems = append(e.Problems, fmt.Sprintf("DNS resolution failed: %s", err))
+			return
+		}
+	}
+	resp, err := c.client.Get(e.URL)
+	if err != nil {
+		e.Problems = append(e.Problems, fmt.Sprintf("request failed: %s", err))
+		return
+	}
+	defer resp.Body.Close()
+	e.Status = resp.StatusCode
+	if resp.StatusCode >= http.StatusInternalServerError {
+		e.Problems = append(e.Problems, fmt.Sprintf("responded with %s", resp.Status))
+	}
+	if resp.TLS == nil || len(resp.TLS.PeerCertificates) == 0 {
+		return
+	}
+	certs := resp.TLS.PeerCertificates
+	cert := certs[0]
+	expiry := cert.NotAfter
+	e.TLSIssuer = certificateIssuer(cert)
+	e.TLSExpiry = &expiry
+
+	intermediates := x509.NewCertPool()
+	for _, intermediate := range certs[1:] {
+		intermediates.AddCert(intermediate)
+	}
+	now := c.now()
+	_, err = cert.Verify(x509.VerifyOptions{
+		DNSName:       resp.Request.URL.Hostname(),
+		Roots:         c.roots,
+		Intermediates: intermediates,
+		CurrentTime:   now,
+	})
+	if err != nil {
+		e.Problems = append(e.Problems, fmt.Sprintf("invalid TLS certificate: %s", err))
+		return
+	}
+	remaining := expiry.Sub(now)
+	if remaining < c.expiryWarning {
+		e.Problems = append(e.Problems, fmt.Sprintf("TLS certificate expires in %d days", int(remaining.Hours()/24)))
+	}
+}
+
+// certificateIssuer returns a short name for the issuer of the certificate
+func certificateIssuer(cert *x509.Certificate) string {
+	if cert.Issuer.CommonName != "" {
+		return cert.Issuer.CommonName
+	}
+	if len(cert.Issuer.Organization) > 0 {
+		return cert.Issuer.Organization[0]
+	}
+	return cert.Issuer.String()
+}
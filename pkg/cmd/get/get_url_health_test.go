@@ -0,0 +1,77 @@
+package get
+
+import (
+	"crypto/x509"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEndpointCheckerTLS(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	roots := x509.NewCertPool()
+	roots.AddCert(server.Certificate())
+
+	checker := newEndpointChecker(5*time.Second, 14*24*time.Hour)
+	checker.roots = roots
+	endpoints := []URLEndpoint{{Name: "myapp", URL: server.URL}}
+	checker.checkAll(endpoints)
+	e := endpoints[0]
+	assert.Equal(t, http.StatusOK, e.Status)
+	assert.NotEmpty(t, e.TLSIssuer)
+	require.NotNil(t, e.TLSExpiry)
+	assert.Equal(t, server.Certificate().NotAfter, *e.TLSExpiry)
+	assert.Empty(t, e.Problems)
+
+	// the certificate is reported even if it cannot be verified
+	checker.roots = x509.NewCertPool()
+	e = URLEndpoint{Name: "myapp", URL: server.URL}
+	checker.check(&e)
+	require.NotNil(t, e.TLSExpiry)
+	require.Len(t, e.Problems, 1)
+	assert.True(t, strings.HasPrefix(e.Problems[0], "invalid TLS certificate"), e.Problems[0])
+
+	// certificates close to expiry are flagged
+	checker.roots = roots
+	checker.now = func() time.Time {
+		return server.Certificate().NotAfter.Add(-72 * time.Hour)
+	}
+	e = URLEndpoint{Name: "myapp", URL: server.URL}
+	checker.check(&e)
+	assert.Equal(t, []string{"TLS certificate expires in 3 days"}, e.Problems)
+}
+
+func TestEndpointCheckerProblems(t *testing.T) {
+	t.Parallel()
+	broken := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer broken.Close()
+
+	checker := newEndpointChecker(5*time.Second, 14*24*time.Hour)
+	checker.lookupHost = func(host string) ([]string, error) {
+		return nil, errors.New("no such host")
+	}
+	endpoints := []URLEndpoint{
+		{Name: "broken", URL: broken.URL},
+		{Name: "missing", URL: "http://myapp.jx-staging.example.com"},
+	}
+	checker.checkAll(endpoints)
+
+	assert.Equal(t, http.StatusBadGateway, endpoints[0].Status)
+	assert.Equal(t, []string{"responded with 502 Bad Gateway"}, endpoints[0].Problems)
+	assert.Nil(t, endpoints[0].TLSExpiry)
+
+	assert.Equal(t, 0, endpoints[1].Status)
+	assert.Equal(t, []string{"DNS resolution failed: no such host"}, endpoints[1].Problems)
+}
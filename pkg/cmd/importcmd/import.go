@@ -17,7 +17,6 @@ import (
 	gojenkins "github.com/jenkins-x/golang-jenkins"
 	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
 	"github.com/jenkins-x/jx/pkg/auth"
-	"github.com/jenkins-x/jx/pkg/cloud/amazon"
 	"github.com/jenkins-x/jx/pkg/cmd/edit"
 	"github.com/jenkins-x/jx/pkg/cmd/helper"
 	"github.com/jenkins-x/jx/pkg/cmd/initcmd"
@@ -32,6 +31,7 @@ import (
 	"github.com/jenkins-x/jx/pkg/kube/naming"
 	"github.com/jenkins-x/jx/pkg/log"
 	"github.com/jenkins-x/jx/pkg/prow"
+	"github.com/jenkins-x/jx/pkg/registry"
 	"github.com/jenkins-x/jx/pkg/util"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
@@ -87,6 +87,9 @@ type ImportOptions struct {
 	ImportMode            string
 	UseDefaultGit         bool
 	GithubAppInstalled    bool
+	Repair                bool
+
+	sourceRepository *v1.SourceRepository
 }
 
 const (
@@ -128,11 +131,17 @@ var (
 
         # Import all repositories from a GitHub organisation which contain the text foo
 		jx import --github --org myname --all --filter foo 
+
+		# Import the repositories of an organisation in parallel
+		jx import org --org myname --filter 'service-*' --batch-mode
+
+		# Re-run an import which failed part way through or reconcile the webhook and configuration of an imported project
+		jx import --repair
 		`)
 
 	deployKinds = []string{DeployKindKnative, DeployKindDefault}
 
-	removeSourceRepositoryAnnotations = []string{"kubectl.kubernetes.io/last-applied-configuration", "jenkins.io/chart"}
+	removeSourceRepositoryAnnotations = []string{"kubectl.kubernetes.io/last-applied-configuration", "jenkins.io/chart", AnnotationImportedSteps}
 )
 
 // NewCmdImport the cobra command for jx import
@@ -156,8 +165,10 @@ func NewCmdImport(commonOpts *opts.CommonOptions) *cobra.Command {
 	cmd.Flags().BoolVarP(&options.GitHub, "github", "", false, "If you wish to pick the repositories from GitHub to import")
 	cmd.Flags().BoolVarP(&options.SelectAll, "all", "", false, "If selecting projects to import from a Git provider this defaults to selecting them all")
 	cmd.Flags().StringVarP(&options.SelectFilter, "filter", "", "", "If selecting projects to import from a Git provider this filters the list of repositories")
+	cmd.Flags().BoolVarP(&options.Repair, "repair", "", false, "If the project was already imported then reconcile its SourceRepository, webhook and pipeline configuration rather than skipping the steps which completed")
 	options.AddImportFlags(cmd, false)
 
+	cmd.AddCommand(NewCmdImportOrg(commonOpts))
 	return cmd
 }
 
@@ -236,76 +247,9 @@ func (options *ImportOptions) Run() error {
 		return err
 	}
 
-	var userAuth *auth.UserAuth
-	if options.GitProvider == nil {
-		authConfigSvc, err := options.GitLocalAuthConfigService()
-		if err != nil {
-			return err
-		}
-		config := authConfigSvc.Config()
-		var server *auth.AuthServer
-		if options.RepoURL != "" {
-			gitInfo, err := gits.ParseGitURL(options.RepoURL)
-			if err != nil {
-				return err
-			}
-			serverURL := gitInfo.HostURLWithoutUser()
-			server = config.GetOrCreateServer(serverURL)
-		} else {
-			server, err = config.PickOrCreateServer(gits.GitHubURL, options.GitRepositoryOptions.ServerURL, "Which Git service do you wish to use", options.BatchMode, options.GetIOFileHandles())
-			if err != nil {
-				return err
-			}
-		}
-
-		if options.UseDefaultGit {
-			userAuth = config.CurrentUser(server, options.CommonOptions.InCluster())
-		} else if options.GitRepositoryOptions.Username != "" {
-			userAuth = config.GetOrCreateUserAuth(server.URL, options.GitRepositoryOptions.Username)
-			log.Logger().Infof("Using Git user name: %s", options.GitRepositoryOptions.Username)
-		} else {
-			// Get the org in case there is more than one user auth on the server and batchMode is true
-			org := options.getOrganisationOrCurrentUser()
-			userAuth, err = config.PickServerUserAuth(server, "Git user name:", options.BatchMode, org, options.GetIOFileHandles())
-			if err != nil {
-				return err
-			}
-		}
-		if server.Kind == "" {
-			server.Kind, err = options.GitServerHostURLKind(server.URL)
-			if err != nil {
-				return err
-			}
-		}
-		if userAuth.IsInvalid() {
-			f := func(username string) error {
-				options.Git().PrintCreateRepositoryGenerateAccessToken(server, username, options.Out)
-				return nil
-			}
-			if options.GitRepositoryOptions.ApiToken != "" {
-				userAuth.ApiToken = options.GitRepositoryOptions.ApiToken
-			}
-			err = config.EditUserAuth(server.Label(), userAuth, userAuth.Username, false, options.BatchMode, f, options.GetIOFileHandles())
-			if err != nil {
-				return err
-			}
-
-			// TODO lets verify the auth works?
-			if userAuth.IsInvalid() {
-				return fmt.Errorf("Authentication has failed for user %v. Please check the user's access credentials and try again", userAuth.Username)
-			}
-		}
-		err = authConfigSvc.SaveUserAuth(server.URL, userAuth)
-		if err != nil {
-			return fmt.Errorf("Failed to store git auth configuration %s", err)
-		}
-
-		options.GitServer = server
-		options.GitUserAuth = userAuth
-		options.GitProvider, err = gits.CreateProvider(server, userAuth, options.Git())
-		if err != nil {
-			return err
-		}
+	err = options.InitGitProvider()
+	if err != nil {
+		return err
 	}
 
 	if options.GitHub {
@@ -378,12 +322,36 @@ func (options *ImportOptions) Run() error {
 	}
 	options.AppName = naming.ToValidName(strings.ToLower(options.AppName))
 
-	if !options.DisableDraft {
-		err = options.DraftCreate()
+	if !options.DryRun {
+		options.sourceRepository, err = options.findImportedSourceRepository()
 		if err != nil {
-			return err
+			return errors.Wrapf(err, "checking if %s was already imported", options.AppName)
 		}
+		if options.sourceRepository != nil {
+			log.Logger().Infof("%s was already imported so only the steps which did not complete will be run", util.ColorInfo(options.AppName))
+		}
+	}
 
+	if !options.DisableDraft {
+		generated := false
+		if options.sourceRepository != nil {
+			generated, err = options.hasGeneratedBuildPack()
+			if err != nil {
+				return err
+			}
+		}
+		if generated {
+			log.Logger().Infof("Skipping generating the build pack files as %s already has a pipeline and chart", util.ColorInfo(options.Dir))
+			err = options.defaultDraftPackFromProjectConfig()
+			if err != nil {
+				return err
+			}
+		} else {
+			err = options.DraftCreate()
+			if err != nil {
+				return err
+			}
+		}
 	}
 	err = options.fixDockerIgnoreFile()
 	if err != nil {
@@ -426,7 +394,7 @@ func (options *ImportOptions) Run() error {
 		}
 	}
 
-	_, err = kube.GetOrCreateSourceRepository(jxClient, ns, options.AppName, options.Organisation, gits.SourceRepositoryProviderURL(options.GitProvider))
+	options.sourceRepository, err = kube.GetOrCreateSourceRepository(jxClient, ns, options.AppName, options.Organisation, gits.SourceRepositoryProviderURL(options.GitProvider))
 	if err != nil {
 		return errors.Wrapf(err, "creating application resource for %s", util.ColorInfo(options.AppName))
 	}
@@ -453,6 +421,83 @@ func (options *ImportOptions) Run() error {
 	return options.doImport()
 }
 
+// InitGitProvider creates the git provider for the git server and user to import into unless one is already set
+func (options *ImportOptions) InitGitProvider() error {
+	if options.GitProvider != nil {
+		return nil
+	}
+	var userAuth *auth.UserAuth
+	authConfigSvc, err := options.GitLocalAuthConfigService()
+	if err != nil {
+		return err
+	}
+	config := authConfigSvc.Config()
+	var server *auth.AuthServer
+	if options.RepoURL != "" {
+		gitInfo, err := gits.ParseGitURL(options.RepoURL)
+		if err != nil {
+			return err
+		}
+		serverURL := gitInfo.HostURLWithoutUser()
+		server = config.GetOrCreateServer(serverURL)
+	} else {
+		server, err = config.PickOrCreateServer(gits.GitHubURL, options.GitRepositoryOptions.ServerURL, "Which Git service do you wish to use", options.BatchMode, options.GetIOFileHandles())
+		if err != nil {
+			return err
+		}
+	}
+
+	if options.UseDefaultGit {
+		userAuth = config.CurrentUser(server, options.CommonOptions.InCluster())
+	} else if options.GitRepositoryOptions.Username != "" {
+		userAuth = config.GetOrCreateUserAuth(server.URL, options.GitRepositoryOptions.Username)
+		log.Logger().Infof("Using Git user name: %s", options.GitRepositoryOptions.Username)
+	} else {
+		// Get the org in case there is more than one user auth on the server and batchMode is true
+		org := options.getOrganisationOrCurrentUser()
+		userAuth, err = config.PickServerUserAuth(server, "Git user name:", options.BatchMode, org, options.GetIOFileHandles())
+		if err != nil {
+			return err
+		}
+	}
+	if server.Kind == "" {
+		server.Kind, err = options.GitServerHostURLKind(server.URL)
+		if err != nil {
+			return err
+		}
+	}
+	if userAuth.IsInvalid() {
+		f := func(username string) error {
+			options.Git().PrintCreateRepositoryGenerateAccessToken(server, username, options.Out)
+			return nil
+		}
+		if options.GitRepositoryOptions.ApiToken != "" {
+			userAuth.ApiToken = options.GitRepositoryOptions.ApiToken
+		}
+		err = config.EditUserAuth(server.Label(), userAuth, userAuth.Username, false, options.BatchMode, f, options.GetIOFileHandles())
+		if err != nil {
+			return err
+		}
+
+		// TODO lets verify the auth works?
+		if userAuth.IsInvalid() {
+			return fmt.Errorf("Authentication has failed for user %v. Please check the user's access credentials and try again", userAuth.Username)
+		}
+	}
+	err = authConfigSvc.SaveUserAuth(server.URL, userAuth)
+	if err != nil {
+		return fmt.Errorf("Failed to store git auth configuration %s", err)
+	}
+
+	options.GitServer = server
+	options.GitUserAuth = userAuth
+	options.GitProvider, err = gits.CreateProvider(server, userAuth, options.Git())
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
 // ImportProjectsFromGitHub import projects from github
 func (options *ImportOptions) ImportProjectsFromGitHub() error {
 	repos, err := gits.PickRepositories(options.GitProvider, options.Organisation, "Which repositories do you want to import", options.SelectAll, options.SelectFilter, options.GetIOFileHandles())
@@ -961,8 +1006,8 @@ func (options *ImportOptions) doImport() error {
 
 	if isProw {
 		if !options.DisableWebhooks && !githubAppMode {
-			// register the webhook
-			err = options.CreateWebhookProw(gitURL, gitProvider)
+			// register the webhook unless a previous import already registered it
+			err = options.ensureWebhookProw(gitURL, gitProvider)
 			if err != nil {
 				return err
 			}
@@ -970,7 +1015,14 @@ func (options *ImportOptions) doImport() error {
 		return options.addProwConfig(gitURL, gitProvider.Kind())
 	}
 
-	return options.ImportProject(gitURL, options.Dir, jenkinsfile, options.BranchPattern, options.Credentials, false, gitProvider, authConfigSvc, false, options.BatchMode)
+	if options.importStepDone(importStepConfig) {
+		return nil
+	}
+	err = options.ImportProject(gitURL, options.Dir, jenkinsfile, options.BranchPattern, options.Credentials, false, gitProvider, authConfigSvc, false, options.BatchMode)
+	if err != nil {
+		return err
+	}
+	return options.markImportStepDone(importStepConfig)
 }
 
 func (options *ImportOptions) addProwConfig(gitURL string, gitKind string) error {
@@ -997,86 +1049,92 @@ func (options *ImportOptions) addProwConfig(gitURL string, gitKind string) error
 		return err
 	}
 
-	if settings.IsSchedulerMode() {
-		jxClient, _, err := options.JXClient()
-		if err != nil {
-			return err
-		}
-		callback := func(sr *v1.SourceRepository) {
-			u := gitInfo.URLWithoutUser()
-			sr.Spec.ProviderKind = gitKind
-			sr.Spec.URL = u
-			if sr.Spec.URL == "" {
-				sr.Spec.URL = gitInfo.HTMLURL
+	if !options.importStepDone(importStepConfig) {
+		if settings.IsSchedulerMode() {
+			jxClient, _, err := options.JXClient()
+			if err != nil {
+				return err
 			}
-			sr.Spec.HTTPCloneURL = u
-			if sr.Spec.HTTPCloneURL == "" {
-				sr.Spec.HTTPCloneURL = gitInfo.HttpCloneURL()
+			callback := func(sr *v1.SourceRepository) {
+				u := gitInfo.URLWithoutUser()
+				sr.Spec.ProviderKind = gitKind
+				sr.Spec.URL = u
+				if sr.Spec.URL == "" {
+					sr.Spec.URL = gitInfo.HTMLURL
+				}
+				sr.Spec.HTTPCloneURL = u
+				if sr.Spec.HTTPCloneURL == "" {
+					sr.Spec.HTTPCloneURL = gitInfo.HttpCloneURL()
+				}
+				sr.Spec.SSHCloneURL = gitInfo.SSHURL
+			}
+			sr, err := kube.GetOrCreateSourceRepositoryCallback(jxClient, currentNamespace, gitInfo.Name, gitInfo.Organisation, gitInfo.HostURLWithoutUser(), callback)
+			log.Logger().Debugf("have SourceRepository: %s\n", sr.Name)
+
+			// lets update the Scheduler if one is specified and its different to the default
+			schedulerName := options.SchedulerName
+			if schedulerName != "" && schedulerName != sr.Spec.Scheduler.Name {
+				sr.Spec.Scheduler.Name = schedulerName
+				_, err = jxClient.JenkinsV1().SourceRepositories(currentNamespace).Update(sr)
+				if err != nil {
+					log.Logger().Warnf("failed to update the SourceRepository %s to add the Scheduler name %s due to: %s\n", sr.Name, schedulerName, err.Error())
+				}
 			}
-			sr.Spec.SSHCloneURL = gitInfo.SSHURL
-		}
-		sr, err := kube.GetOrCreateSourceRepositoryCallback(jxClient, currentNamespace, gitInfo.Name, gitInfo.Organisation, gitInfo.HostURLWithoutUser(), callback)
-		log.Logger().Debugf("have SourceRepository: %s\n", sr.Name)
 
-		// lets update the Scheduler if one is specified and its different to the default
-		schedulerName := options.SchedulerName
-		if schedulerName != "" && schedulerName != sr.Spec.Scheduler.Name {
-			sr.Spec.Scheduler.Name = schedulerName
-			_, err = jxClient.JenkinsV1().SourceRepositories(currentNamespace).Update(sr)
+			sourceGitURL, err := kube.GetRepositoryGitURL(sr)
 			if err != nil {
-				log.Logger().Warnf("failed to update the SourceRepository %s to add the Scheduler name %s due to: %s\n", sr.Name, schedulerName, err.Error())
+				return errors.Wrapf(err, "failed to get the git URL for SourceRepository %s", sr.Name)
 			}
-		}
 
-		sourceGitURL, err := kube.GetRepositoryGitURL(sr)
-		if err != nil {
-			return errors.Wrapf(err, "failed to get the git URL for SourceRepository %s", sr.Name)
-		}
+			devGitURL := devEnv.Spec.Source.URL
+			if devGitURL != "" && !gha {
+				// lets generate a PR
+				base := devEnv.Spec.Source.Ref
+				if base == "" {
+					base = "master"
+				}
+				pro := &pr.StepCreatePrOptions{
+					SrcGitURL:  sourceGitURL,
+					GitURLs:    []string{devGitURL},
+					Base:       base,
+					Fork:       true,
+					BranchName: sr.Name,
+				}
+				pro.CommonOptions = options.CommonOptions
 
-		devGitURL := devEnv.Spec.Source.URL
-		if devGitURL != "" && !gha {
-			// lets generate a PR
-			base := devEnv.Spec.Source.Ref
-			if base == "" {
-				base = "master"
-			}
-			pro := &pr.StepCreatePrOptions{
-				SrcGitURL:  sourceGitURL,
-				GitURLs:    []string{devGitURL},
-				Base:       base,
-				Fork:       true,
-				BranchName: sr.Name,
-			}
-			pro.CommonOptions = options.CommonOptions
+				changeFn := func(dir string, gitInfo *gits.GitRepository) ([]string, error) {
+					return nil, writeSourceRepoToYaml(dir, sr)
+				}
 
-			changeFn := func(dir string, gitInfo *gits.GitRepository) ([]string, error) {
-				return nil, writeSourceRepoToYaml(dir, sr)
+				err := pro.CreatePullRequest("resource", changeFn)
+				if err != nil {
+					return errors.Wrapf(err, "failed to create Pull Request on the development environment git repository %s", devGitURL)
+				}
+				info := util.ColorInfo
+				prURL := ""
+				if pro.Results != nil && pro.Results.PullRequest != nil {
+					prURL = pro.Results.PullRequest.URL
+				}
+				log.Logger().Infof("created pull request %s on the development git repository %s", info(prURL), info(devGitURL))
 			}
 
-			err := pro.CreatePullRequest("resource", changeFn)
+			err = options.GenerateProwConfig(currentNamespace, devEnv)
 			if err != nil {
-				return errors.Wrapf(err, "failed to create Pull Request on the development environment git repository %s", devGitURL)
+				return err
 			}
-			info := util.ColorInfo
-			prURL := ""
-			if pro.Results != nil && pro.Results.PullRequest != nil {
-				prURL = pro.Results.PullRequest.URL
+		} else {
+			err = prow.AddApplication(client, []string{repo}, currentNamespace, options.DraftPack, settings)
+			if err != nil {
+				return err
 			}
-			log.Logger().Infof("created pull request %s on the development git repository %s", info(prURL), info(devGitURL))
 		}
-
-		err = options.GenerateProwConfig(currentNamespace, devEnv)
-		if err != nil {
-			return err
-		}
-	} else {
-		err = prow.AddApplication(client, []string{repo}, currentNamespace, options.DraftPack, settings)
+		err = options.markImportStepDone(importStepConfig)
 		if err != nil {
 			return err
 		}
 	}
 
-	if !gha {
+	if !gha && !options.importStepDone(importStepPipeline) {
 		startBuildOptions := start.StartPipelineOptions{
 			CommonOptions: options.CommonOptions,
 		}
@@ -1085,6 +1143,10 @@ func (options *ImportOptions) addProwConfig(gitURL string, gitKind string) error
 		if err != nil {
 			return fmt.Errorf("failed to start pipeline build")
 		}
+		err = options.markImportStepDone(importStepPipeline)
+		if err != nil {
+			return err
+		}
 	}
 
 	options.LogImportedProject(false, gitInfo)
@@ -1165,9 +1227,8 @@ func (options *ImportOptions) ensureDockerRepositoryExists() error {
 	if cm.Data != nil {
 		dockerRegistry := cm.Data["docker.registry"]
 		if dockerRegistry != "" {
-			if strings.HasSuffix(dockerRegistry, ".amazonaws.com") && strings.Index(dockerRegistry, ".ecr.") > 0 {
-				return amazon.LazyCreateRegistry(kubeClient, ns, region, dockerRegistry, options.getDockerRegistryOrg(), appName)
-			}
+			image := strings.Join([]string{dockerRegistry, options.getDockerRegistryOrg(), appName}, "/")
+			return registry.NewRegistry(dockerRegistry, region).EnsureRepository(image)
 		}
 	}
 	return nil
@@ -1424,8 +1485,8 @@ func (options *ImportOptions) CreateProwOwnersFile() error {
 	}
 	if options.GitUserAuth != nil && options.GitUserAuth.Username != "" {
 		data := prow.Owners{
-			[]string{options.GitUserAuth.Username},
-			[]string{options.GitUserAuth.Username},
+			Approvers: []string{options.GitUserAuth.Username},
+			Reviewers: []string{options.GitUserAuth.Username},
 		}
 		yaml, err := yaml.Marshal(&data)
 		if err != nil {
@@ -0,0 +1,312 @@
+package importcmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/jenkins-x/jx/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/pkg/gits"
+	"github.com/jenkins-x/jx/pkg/kube"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// ImportStatusImported the repository was imported
+	ImportStatusImported = "imported"
+	// ImportStatusSkipped the repository was not imported as it has already been imported
+	ImportStatusSkipped = "skipped"
+	// ImportStatusFailed the import of the repository failed
+	ImportStatusFailed = "failed"
+)
+
+// ImportOrgOptions the options for the import org command
+type ImportOrgOptions struct {
+	ImportOptions
+
+	Filters         []string
+	Excludes        []string
+	Parallelism     int
+	IncludeArchived bool
+	IncludeForks    bool
+	ReportFile      string
+}
+
+// ImportOrgResult the result of importing a repository of an organisation
+type ImportOrgResult struct {
+	Repository string `json:"repository"`
+	URL        string `json:"url,omitempty"`
+	Status     string `json:"status"`
+	BuildPack  string `json:"buildPack,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+var (
+	importOrgLong = templates.LongDesc(`
+		Imports the repositories of a Git provider organisation into Jenkins X in parallel.
+
+		The repositories are listed via the Git provider API and filtered by name. Each matching repository has its
+		build pack detected and is imported, creating its pipeline, webhooks and SourceRepository. Repositories which
+		have already been imported are skipped.
+
+		A report of the imported, skipped and failed repositories is displayed at the end and can be saved via
+		--report-file.
+`)
+
+	importOrgExample = templates.Examples(`
+		# Import all of the service repositories of an organisation without prompting
+		jx import org --org myorg --filter 'service-*' --batch-mode
+
+		# Import the repositories of an organisation except the ones for documentation saving a report
+		jx import org --org myorg --exclude 'docs-*' --report-file import-report.yaml
+	`)
+)
+
+// NewCmdImportOrg creates the command for importing the repositories of an organisation
+func NewCmdImportOrg(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &ImportOrgOptions{
+		ImportOptions: ImportOptions{
+			CommonOptions: commonOpts,
+		},
+	}
+	cmd := &cobra.Command{
+		Use:     "org",
+		Aliases: []string{"organisation", "organization"},
+		Short:   "Imports the repositories of a Git provider organisation into Jenkins X in parallel",
+		Long:    importOrgLong,
+		Example: importOrgExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.Flags().StringVarP(&options.Organisation, "org", "", "", "The Git provider organisation whose repositories are imported")
+	cmd.Flags().StringArrayVarP(&options.Filters, "filter", "f", nil, "The repository names to import such as 'service-*'. Defaults to all of them")
+	cmd.Flags().StringArrayVarP(&options.Excludes, "exclude", "x", nil, "The repository names to exclude such as 'docs-*'")
+	cmd.Flags().IntVarP(&options.Parallelism, "parallel", "", 4, "The number of repositories to import in parallel")
+	cmd.Flags().BoolVarP(&options.IncludeArchived, "include-archived", "", false, "Imports archived repositories")
+	cmd.Flags().BoolVarP(&options.IncludeForks, "include-forks", "", false, "Imports forked repositories")
+	cmd.Flags().StringVarP(&options.ReportFile, "report-file", "", "", "The YAML file to save the report of the imports to")
+	cmd.Flags().BoolVarP(&options.DryRun, "dry-run", "", false, "Performs local changes to the repos but skips the import into Jenkins X")
+	cmd.Flags().BoolVarP(&options.DisableDraft, "no-draft", "", false, "Disable Draft from trying to default a Dockerfile and Helm Chart")
+	cmd.Flags().BoolVarP(&options.DisableJenkinsfileCheck, "no-jenkinsfile", "", false, "Disable defaulting a Jenkinsfile if its missing")
+	cmd.Flags().StringVarP(&options.DraftPack, "pack", "", "", "The name of the pack to use for all repositories rather than detecting it")
+	cmd.Flags().StringVarP(&options.SchedulerName, "scheduler", "", "", "The name of the Scheduler configuration to use for ChatOps when using Prow")
+	cmd.Flags().StringVarP(&options.DeployKind, "deploy-kind", "", "", fmt.Sprintf("The kind of deployment to use for the projects. Should be one of %s", strings.Join(deployKinds, ", ")))
+	opts.AddGitRepoOptionsArguments(cmd, &options.GitRepositoryOptions)
+	return cmd
+}
+
+// Run implements this command
+func (o *ImportOrgOptions) Run() error {
+	if o.Organisation == "" {
+		return util.MissingOption("org")
+	}
+	if o.Parallelism < 1 {
+		return util.InvalidOptionf("parallel", o.Parallelism, "should be at least 1")
+	}
+	err := o.InitGitProvider()
+	if err != nil {
+		return err
+	}
+	repos, err := o.GitProvider.ListRepositories(o.Organisation)
+	if err != nil {
+		return errors.Wrapf(err, "listing the repositories of organisation %s", o.Organisation)
+	}
+	repos = FilterOrgRepositories(repos, o.Filters, o.Excludes, o.IncludeArchived, o.IncludeForks)
+	if len(repos) == 0 {
+		log.Logger().Warnf("No repositories of organisation %s match the filters", util.ColorInfo(o.Organisation))
+		return nil
+	}
+
+	var results []*ImportOrgResult
+	var toImport []*gits.GitRepository
+	if o.DryRun {
+		toImport = repos
+	} else {
+		jxClient, ns, err := o.JXClientAndDevNamespace()
+		if err != nil {
+			return err
+		}
+		for _, r := range repos {
+			if _, err := kube.FindSourceRepository(jxClient, ns, o.Organisation, r.Name); err == nil {
+				results = append(results, &ImportOrgResult{Repository: r.Name, URL: r.HTMLURL, Status: ImportStatusSkipped})
+				continue
+			}
+			toImport = append(toImport, r)
+		}
+	}
+
+	if len(toImport) > 0 && !o.BatchMode {
+		log.Logger().Infof("The following repositories of %s will be imported:", util.ColorInfo(o.Organisation))
+		for _, r := range toImport {
+			log.Logger().Infof("  %s", r.Name)
+		}
+		if !util.Confirm(fmt.Sprintf("Import %d repositories?", len(toImport)), true, "The repositories are imported in parallel without any further prompts", o.GetIOFileHandles()) {
+			return nil
+		}
+	}
+	// the imports run in parallel so lets not prompt
+	o.BatchMode = true
+
+	err = o.initClients()
+	if err != nil {
+		return err
+	}
+	dir, err := ioutil.TempDir("", "jx-import-org-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	results = append(results, o.importRepositories(toImport, dir)...)
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Repository < results[j].Repository
+	})
+	return o.report(results)
+}
+
+// initClients creates the clients which are shared by the parallel imports up front
+func (o *ImportOrgOptions) initClients() error {
+	if o.DryRun {
+		return nil
+	}
+	_, err := o.KubeClient()
+	if err != nil {
+		return err
+	}
+	isProw, err := o.IsProw()
+	if err != nil {
+		return err
+	}
+	if !isProw {
+		o.Jenkins, err = o.JenkinsClient()
+		if err != nil {
+			return err
+		}
+	}
+	_, err = o.TeamSettings()
+	return err
+}
+
+// importRepositories imports the repositories in parallel returning the result of each import
+func (o *ImportOrgOptions) importRepositories(repos []*gits.GitRepository, dir string) []*ImportOrgResult {
+	results := make([]*ImportOrgResult, len(repos))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < o.Parallelism; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				results[i] = o.importRepository(repos[i], dir)
+			}
+		}()
+	}
+	for i := range repos {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+	return results
+}
+
+func (o *ImportOrgOptions) importRepository(repo *gits.GitRepository, dir string) *ImportOrgResult {
+	result := &ImportOrgResult{
+		Repository: repo.Name,
+		URL:        repo.HTMLURL,
+	}
+	importOptions := &ImportOptions{
+		CommonOptions:           o.CommonOptions,
+		Dir:                     dir,
+		RepoURL:                 repo.CloneURL,
+		Organisation:            o.Organisation,
+		Repository:              repo.Name,
+		DryRun:                  o.DryRun,
+		DisableDraft:            o.DisableDraft,
+		DisableJenkinsfileCheck: o.DisableJenkinsfileCheck,
+		DraftPack:               o.DraftPack,
+		SchedulerName:           o.SchedulerName,
+		DeployKind:              o.DeployKind,
+		Jenkins:                 o.Jenkins,
+		GitServer:               o.GitServer,
+		GitUserAuth:             o.GitUserAuth,
+		GitProvider:             o.GitProvider,
+		GitRepositoryOptions:    o.GitRepositoryOptions,
+	}
+	log.Logger().Infof("Importing repository %s", util.ColorInfo(repo.Name))
+	err := importOptions.Run()
+	result.BuildPack = importOptions.DraftPack
+	if err != nil {
+		log.Logger().Warnf("Failed to import repository %s: %s", repo.Name, err)
+		result.Status = ImportStatusFailed
+		result.Error = err.Error()
+		return result
+	}
+	result.Status = ImportStatusImported
+	return result
+}
+
+// report displays the results and saves them to the report file returning an error if any imports failed
+func (o *ImportOrgOptions) report(results []*ImportOrgResult) error {
+	table := o.CreateTable()
+	table.AddRow("REPOSITORY", "STATUS", "BUILD PACK", "ERROR")
+	failed := 0
+	for _, r := range results {
+		status := r.Status
+		switch r.Status {
+		case ImportStatusImported:
+			status = util.ColorInfo(status)
+		case ImportStatusFailed:
+			status = util.ColorError(status)
+			failed++
+		}
+		table.AddRow(r.Repository, status, r.BuildPack, r.Error)
+	}
+	table.Render()
+
+	if o.ReportFile != "" {
+		data, err := yaml.Marshal(results)
+		if err != nil {
+			return errors.Wrap(err, "marshalling the import report")
+		}
+		err = ioutil.WriteFile(o.ReportFile, data, util.DefaultWritePermissions)
+		if err != nil {
+			return errors.Wrapf(err, "saving the import report to %s", o.ReportFile)
+		}
+		log.Logger().Infof("Saved the import report to %s", util.ColorInfo(o.ReportFile))
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to import %d of %d repositories of organisation %s", failed, len(results), o.Organisation)
+	}
+	return nil
+}
+
+// FilterOrgRepositories returns the repositories sorted by name whose names match the filters and do not match the
+// excludes, omitting archived and forked repositories unless they are included
+func FilterOrgRepositories(repos []*gits.GitRepository, filters []string, excludes []string, includeArchived bool, includeForks bool) []*gits.GitRepository {
+	var answer []*gits.GitRepository
+	for _, r := range repos {
+		if r == nil || (r.Archived && !includeArchived) || (r.Fork && !includeForks) {
+			continue
+		}
+		if util.StringMatchesAny(r.Name, filters, excludes) {
+			answer = append(answer, r)
+		}
+	}
+	sort.Slice(answer, func(i, j int) bool {
+		return answer[i].Name < answer[j].Name
+	})
+	return answer
+}
@@ -0,0 +1,65 @@
+package importcmd
+
+import (
+	"testing"
+
+	"github.com/jenkins-x/jx/pkg/gits"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilterOrgRepositories(t *testing.T) {
+	t.Parallel()
+
+	repos := []*gits.GitRepository{
+		{Name: "service-orders"},
+		{Name: "docs-site"},
+		{Name: "service-archived", Archived: true},
+		{Name: "service-fork", Fork: true},
+		{Name: "service-billing"},
+		{Name: "service-billing-docs"},
+	}
+	names := func(repos []*gits.GitRepository) []string {
+		var answer []string
+		for _, r := range repos {
+			answer = append(answer, r.Name)
+		}
+		return answer
+	}
+
+	testCases := []struct {
+		name            string
+		filters         []string
+		excludes        []string
+		includeArchived bool
+		includeForks    bool
+		expected        []string
+	}{
+		{
+			name:     "all",
+			expected: []string{"docs-site", "service-billing", "service-billing-docs", "service-orders"},
+		},
+		{
+			name:     "filter",
+			filters:  []string{"service-*"},
+			expected: []string{"service-billing", "service-billing-docs", "service-orders"},
+		},
+		{
+			name:     "filter and exclude",
+			filters:  []string{"service-*"},
+			excludes: []string{"service-billing-docs"},
+			expected: []string{"service-billing", "service-orders"},
+		},
+		{
+			name:            "include archived and forks",
+			filters:         []string{"service-*"},
+			excludes:        []string{"service-billing*"},
+			includeArchived: true,
+			includeForks:    true,
+			expected:        []string{"service-archived", "service-fork", "service-orders"},
+		},
+	}
+	for _, tc := range testCases {
+		actual := FilterOrgRepositories(repos, tc.filters, tc.excludes, tc.includeArchived, tc.includeForks)
+		assert.Equal(t, tc.expected, names(actual), tc.name)
+	}
+}
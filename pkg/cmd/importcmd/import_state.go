@@ -0,0 +1,176 @@
+package importcmd
+
+import (
+	"path/filepath"
+	"strings"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/config"
+	"github.com/jenkins-x/jx/pkg/gits"
+	"github.com/jenkins-x/jx/pkg/jenkinsfile"
+	"github.com/jenkins-x/jx/pkg/kube"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// AnnotationImportedSteps the annotation on the SourceRepository of an imported project which records the import
+	// steps which completed so that an import which failed part way through can be resumed
+	AnnotationImportedSteps = "jenkins.io/imported-steps"
+
+	// importStepConfig the project was added to the prow, scheduler or Jenkins configuration
+	importStepConfig = "config"
+	// importStepPipeline the first pipeline of the project was triggered
+	importStepPipeline = "pipeline"
+)
+
+// findImportedSourceRepository returns the SourceRepository of a previous import of the project or nil if the project
+// has not been imported
+func (options *ImportOptions) findImportedSourceRepository() (*v1.SourceRepository, error) {
+	if options.Organisation == "" || options.AppName == "" {
+		return nil, nil
+	}
+	jxClient, ns, err := options.JXClientAndDevNamespace()
+	if err != nil {
+		return nil, err
+	}
+	sr, err := kube.FindSourceRepository(jxClient, ns, options.Organisation, options.AppName)
+	if err != nil {
+		if apierrors.IsNotFound(errors.Cause(err)) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return sr, nil
+}
+
+// hasGeneratedBuildPack returns true if a previous import already generated the pipeline and chart of the project
+func (options *ImportOptions) hasGeneratedBuildPack() (bool, error) {
+	pipeline := false
+	for _, name := range []string{config.ProjectConfigFileName, jenkinsfile.Name} {
+		exists, err := util.FileExists(filepath.Join(options.Dir, name))
+		if err != nil {
+			return false, err
+		}
+		pipeline = pipeline || exists
+	}
+	if !pipeline {
+		return false, nil
+	}
+	charts, err := filepath.Glob(filepath.Join(options.Dir, "charts", "*", "Chart.yaml"))
+	if err != nil {
+		return false, err
+	}
+	return len(charts) > 0, nil
+}
+
+// defaultDraftPackFromProjectConfig defaults the draft pack to the build pack of the generated project configuration
+func (options *ImportOptions) defaultDraftPackFromProjectConfig() error {
+	if options.DraftPack != "" {
+		return nil
+	}
+	projectConfig, _, err := config.LoadProjectConfig(options.Dir)
+	if err != nil {
+		return errors.Wrapf(err, "failed to load the project configuration in %s", options.Dir)
+	}
+	options.DraftPack = projectConfig.BuildPack
+	return nil
+}
+
+// importedSteps returns the import steps which completed for the SourceRepository
+func importedSteps(sr *v1.SourceRepository) []string {
+	if sr == nil || sr.Annotations == nil {
+		return nil
+	}
+	answer := []string{}
+	for _, step := range strings.Split(sr.Annotations[AnnotationImportedSteps], ",") {
+		step = strings.TrimSpace(step)
+		if step != "" {
+			answer = append(answer, step)
+		}
+	}
+	return answer
+}
+
+// importStepDone returns true if a previous import completed the step and it should be skipped. Repairing runs the
+// configuration steps again but does not trigger another pipeline
+func (options *ImportOptions) importStepDone(step string) bool {
+	if options.Repair && step != importStepPipeline {
+		return false
+	}
+	if util.StringArrayIndex(importedSteps(options.sourceRepository), step) < 0 {
+		return false
+	}
+	log.Logger().Infof("Skipping the %s step of the import as it completed previously", util.ColorInfo(step))
+	return true
+}
+
+// markImportStepDone records on the SourceRepository that the import step completed
+func (options *ImportOptions) markImportStepDone(step string) error {
+	if options.sourceRepository == nil {
+		return nil
+	}
+	steps := importedSteps(options.sourceRepository)
+	if util.StringArrayIndex(steps, step) >= 0 {
+		return nil
+	}
+	jxClient, ns, err := options.JXClientAndDevNamespace()
+	if err != nil {
+		return err
+	}
+	repositories := jxClient.JenkinsV1().SourceRepositories(ns)
+	sr, err := repositories.Get(options.sourceRepository.Name, metav1.GetOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "failed to get SourceRepository %s", options.sourceRepository.Name)
+	}
+	if sr.Annotations == nil {
+		sr.Annotations = map[string]string{}
+	}
+	sr.Annotations[AnnotationImportedSteps] = strings.Join(append(importedSteps(sr), step), ",")
+	sr, err = repositories.Update(sr)
+	if err != nil {
+		return errors.Wrapf(err, "failed to record the import step %s on SourceRepository %s", step, options.sourceRepository.Name)
+	}
+	options.sourceRepository = sr
+	return nil
+}
+
+// ensureWebhookProw registers the prow webhook of the repository unless it is already registered. If repairing, an
+// existing webhook is updated so that its secret and settings match the cluster
+func (options *ImportOptions) ensureWebhookProw(gitURL string, gitProvider gits.GitProvider) error {
+	webhook, err := options.ProwWebhookArguments(gitURL)
+	if err != nil {
+		return err
+	}
+	hooks, err := gitProvider.ListWebHooks(webhook.Owner, webhook.Repo.Name)
+	if err != nil {
+		log.Logger().Warnf("Failed to list the webhooks of %s so creating the webhook: %s", gitURL, err)
+		return gitProvider.CreateWebHook(webhook)
+	}
+	existing := findWebhook(hooks, webhook.URL)
+	if existing == nil {
+		return gitProvider.CreateWebHook(webhook)
+	}
+	if !options.Repair {
+		log.Logger().Infof("The webhook %s is already registered on %s", util.ColorInfo(webhook.URL), util.ColorInfo(gitURL))
+		return nil
+	}
+	webhook.ID = existing.ID
+	webhook.ExistingURL = existing.URL
+	log.Logger().Infof("Repairing the webhook %s on %s", util.ColorInfo(webhook.URL), util.ColorInfo(gitURL))
+	return gitProvider.UpdateWebHook(webhook)
+}
+
+// findWebhook returns the webhook for the URL or nil if there is none
+func findWebhook(hooks []*gits.GitWebHookArguments, url string) *gits.GitWebHookArguments {
+	url = strings.TrimSuffix(url, "/")
+	for _, hook := range hooks {
+		if hook != nil && strings.TrimSuffix(hook.URL, "/") == url {
+			return hook
+		}
+	}
+	return nil
+}
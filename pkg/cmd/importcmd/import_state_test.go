@@ -0,0 +1,105 @@
+package importcmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	v1fake "github.com/jenkins-x/jx/pkg/client/clientset/versioned/fake"
+	"github.com/jenkins-x/jx/pkg/cmd/clients/fake"
+	"github.com/jenkins-x/jx/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/pkg/gits"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestImportStepsResume(t *testing.T) {
+	t.Parallel()
+	sr := &v1.SourceRepository{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "myorg-myapp",
+			Namespace: "jx",
+		},
+		Spec: v1.SourceRepositorySpec{
+			Org:  "myorg",
+			Repo: "myapp",
+		},
+	}
+	commonOpts := opts.NewCommonOptionsWithFactory(fake.NewFakeFactory())
+	jxClient := v1fake.NewSimpleClientset(sr)
+	commonOpts.SetJxClient(jxClient)
+	commonOpts.SetDevNamespace("jx")
+	options := &ImportOptions{
+		CommonOptions: &commonOpts,
+		Organisation:  "myorg",
+		AppName:       "myapp",
+	}
+
+	found, err := options.findImportedSourceRepository()
+	require.NoError(t, err)
+	require.NotNil(t, found, "the project was already imported")
+	options.sourceRepository = found
+	assert.False(t, options.importStepDone(importStepConfig))
+
+	err = options.markImportStepDone(importStepConfig)
+	require.NoError(t, err)
+	err = options.markImportStepDone(importStepConfig)
+	require.NoError(t, err)
+	stored, err := jxClient.JenkinsV1().SourceRepositories("jx").Get("myorg-myapp", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "config", stored.Annotations[AnnotationImportedSteps])
+	assert.True(t, options.importStepDone(importStepConfig))
+	assert.False(t, options.importStepDone(importStepPipeline))
+
+	err = options.markImportStepDone(importStepPipeline)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"config", "pipeline"}, importedSteps(options.sourceRepository))
+
+	options.Repair = true
+	assert.False(t, options.importStepDone(importStepConfig), "repairing runs the configuration again")
+	assert.True(t, options.importStepDone(importStepPipeline), "repairing does not trigger another pipeline")
+
+	options.AppName = "other"
+	found, err = options.findImportedSourceRepository()
+	require.NoError(t, err)
+	assert.Nil(t, found)
+}
+
+func TestHasGeneratedBuildPack(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "import-state")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	options := &ImportOptions{Dir: dir}
+
+	generated, err := options.hasGeneratedBuildPack()
+	require.NoError(t, err)
+	assert.False(t, generated)
+
+	err = ioutil.WriteFile(filepath.Join(dir, "jenkins-x.yml"), []byte("buildPack: go\n"), 0644)
+	require.NoError(t, err)
+	generated, err = options.hasGeneratedBuildPack()
+	require.NoError(t, err)
+	assert.False(t, generated, "the chart is missing")
+
+	err = os.MkdirAll(filepath.Join(dir, "charts", "myapp"), 0755)
+	require.NoError(t, err)
+	err = ioutil.WriteFile(filepath.Join(dir, "charts", "myapp", "Chart.yaml"), []byte("name: myapp\n"), 0644)
+	require.NoError(t, err)
+	generated, err = options.hasGeneratedBuildPack()
+	require.NoError(t, err)
+	assert.True(t, generated)
+}
+
+func TestFindWebhook(t *testing.T) {
+	t.Parallel()
+	hooks := []*gits.GitWebHookArguments{
+		{ID: 1, URL: "http://jenkins.jx.example.com/github-webhook/"},
+		{ID: 2, URL: "http://hook.jx.example.com/hook/"},
+	}
+	assert.Equal(t, int64(2), findWebhook(hooks, "http://hook.jx.example.com/hook").ID)
+	assert.Nil(t, findWebhook(hooks, "http://hook.jx.other.com/hook"))
+}
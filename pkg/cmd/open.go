@@ -1,8 +1,23 @@
 package cmd
 
 import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
 	"github.com/jenkins-x/jx/pkg/cmd/get"
 	"github.com/jenkins-x/jx/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/pkg/kube"
+	"github.com/jenkins-x/jx/pkg/kube/services"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/browser"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 
 	"github.com/jenkins-x/jx/pkg/cmd/opts"
@@ -11,12 +26,42 @@ import (
 
 type OpenOptions struct {
 	ConsoleOptions
+
+	Copy     bool
+	JSON     bool
+	Username string
+
+	password       string
+	passwordLoaded bool
 }
 
+// OpenURL is the URL of a service resolved in an environment
+type OpenURL struct {
+	Name        string `json:"name"`
+	Environment string `json:"environment,omitempty"`
+	Namespace   string `json:"namespace"`
+	URL         string `json:"url"`
+	BasicAuth   bool   `json:"basicAuth,omitempty"`
+	StatusCode  int    `json:"statusCode,omitempty"`
+	Health      string `json:"health,omitempty"`
+}
+
+const (
+	// healthOK the health of a URL which responded successfully
+	healthOK = "OK"
+)
+
 var (
 	open_long = templates.LongDesc(`
 		Opens a named service in the browser.
 
+		If no namespace or environment is specified the service is looked for in the current namespace and, if it is
+		not found there, in each environment including the preview environments. Use '--all-envs' to list the
+		services of all the environments.
+
+		Use '--health' to check the URLs are responding. If the ingress of a service requires basic authentication
+		then the admin credentials of the installation are used for the check.
+
 		You can use the '--url' argument to just display the URL without opening it`)
 
 	open_example = templates.Examples(`
@@ -26,8 +71,14 @@ var (
 		# Print the Nexus console URL but do not open a browser
 		jx open jenkins-x-sonatype-nexus -u
 
+		# Copy the URL of an application in the staging environment to the clipboard
+		jx open myapp --env staging -u --copy
+
 		# List all the service URLs
-		jx open`)
+		jx open
+
+		# List all the service URLs of all the environments and previews with their health as JSON
+		jx open --all-envs --health --json`)
 )
 
 func NewCmdOpen(commonOpts *opts.CommonOptions) *cobra.Command {
@@ -53,13 +104,259 @@ func NewCmdOpen(commonOpts *opts.CommonOptions) *cobra.Command {
 		},
 	}
 	options.addConsoleFlags(cmd)
+	cmd.Flags().BoolVarP(&options.Copy, "copy", "", false, "Copies the URL to the clipboard")
+	cmd.Flags().BoolVarP(&options.JSON, "json", "", false, "Outputs the resolved URLs and their health as JSON and does not open the browser")
+	cmd.Flags().BoolVarP(&options.AllEnvironments, "all-envs", "", false, "Looks for services in all the environments and preview environments rather than just the current namespace")
+	cmd.Flags().BoolVarP(&options.HealthCheck, "health", "", false, "Checks that the URLs are responding")
+	cmd.Flags().DurationVarP(&options.HealthTimeout, "health-timeout", "", 5*time.Second, "The timeout of the health check of each URL")
+	cmd.Flags().StringVarP(&options.Username, "username", "", "admin", "The username used for the health check of URLs which require basic authentication")
 	return cmd
 }
 
 func (o *OpenOptions) Run() error {
+	if o.Copy && len(o.Args) == 0 {
+		return errors.New("the --copy flag requires the name of a service")
+	}
+	namespaces, err := o.EnvironmentNamespaces(o.AllEnvironments)
+	if err != nil {
+		return err
+	}
 	if len(o.Args) == 0 {
-		return o.GetURLOptions.Run()
+		urls, err := o.resolveURLs(namespaces, "")
+		if err != nil {
+			return err
+		}
+		return o.printURLs(urls)
 	}
+
 	name := o.Args[0]
-	return o.ConsoleOptions.Open(name, name)
+	urls, err := o.resolveURLs(namespaces, name)
+	if err != nil {
+		return err
+	}
+	if len(urls) == 0 && !o.AllEnvironments && o.Namespace == "" && o.Environment == "" {
+		// lets look in the other environments if the service is not in the current namespace
+		namespaces, err = o.EnvironmentNamespaces(true)
+		if err != nil {
+			return err
+		}
+		urls, err = o.resolveURLs(namespaces[1:], name)
+		if err != nil {
+			return err
+		}
+	}
+	if len(urls) == 0 {
+		log.Logger().Infof("If the app %s is running in a different environment you could try: %s", util.ColorInfo(name), util.ColorInfo("jx get applications"))
+		return fmt.Errorf("no URL found for service %s", name)
+	}
+	u := urls[0]
+	if len(urls) > 1 {
+		log.Logger().Debugf("service %s found in %d namespaces, using namespace %s", name, len(urls), u.Namespace)
+	}
+	fullURL := u.URL
+	if name == kube.ServiceJenkins {
+		fullURL = o.urlForMode(u.URL)
+	}
+
+	if o.JSON {
+		data, err := json.MarshalIndent(u, "", "  ")
+		if err != nil {
+			return errors.Wrap(err, "marshalling the URL to JSON")
+		}
+		fmt.Fprintln(o.Out, string(data))
+	} else {
+		text := fullURL
+		if o.OnlyViewHost {
+			text = util.URLToHostName(fullURL)
+		}
+		fmt.Fprintf(o.Out, "%s: %s\n", name, util.ColorInfo(text))
+		if u.Health != "" && u.Health != healthOK {
+			log.Logger().Warnf("%s is not healthy: %s", fullURL, u.Health)
+		}
+	}
+	if o.Copy {
+		err = copyToClipboard(fullURL)
+		if err != nil {
+			return err
+		}
+		log.Logger().Infof("copied %s to the clipboard", util.ColorInfo(fullURL))
+	}
+	if !o.OnlyViewURL && !o.OnlyViewHost && !o.JSON {
+		return browser.OpenURL(fullURL)
+	}
+	return nil
+}
+
+// resolveURLs resolves the URLs of the services in the namespaces, or only the service with the given name, and
+// checks their health if enabled
+func (o *OpenOptions) resolveURLs(namespaces []get.EnvironmentNamespace, name string) ([]OpenURL, error) {
+	kubeClient, err := o.KubeClient()
+	if err != nil {
+		return nil, err
+	}
+	answer := []OpenURL{}
+	for _, en := range namespaces {
+		svcs, err := services.GetServices(kubeClient, en.Namespace)
+		if err != nil {
+			log.Logger().Debugf("failed to list services in namespace %s: %s", en.Namespace, err)
+			continue
+		}
+		names := make([]string, 0, len(svcs))
+		for n := range svcs {
+			if name == "" || n == name {
+				names = append(names, n)
+			}
+		}
+		sort.Strings(names)
+		for _, n := range names {
+			svc := svcs[n]
+			u := services.GetServiceURL(svc)
+			if u == "" {
+				u, _ = services.FindServiceURL(kubeClient, en.Namespace, n)
+			}
+			if u == "" {
+				continue
+			}
+			answer = append(answer, OpenURL{
+				Name:        n,
+				Environment: en.Environment,
+				Namespace:   en.Namespace,
+				URL:         u,
+				BasicAuth:   services.RequiresBasicAuth(svc),
+			})
+		}
+	}
+	if o.HealthCheck {
+		o.checkHealth(answer)
+	}
+	return answer, nil
+}
+
+// checkHealth checks the health of the URLs concurrently
+func (o *OpenOptions) checkHealth(urls []OpenURL) {
+	password := ""
+	for _, u := range urls {
+		if u.BasicAuth {
+			password = o.basicAuthPassword()
+			break
+		}
+	}
+	client := &http.Client{Timeout: o.HealthTimeout}
+	var wg sync.WaitGroup
+	for i := range urls {
+		wg.Add(1)
+		go func(u *OpenURL) {
+			defer wg.Done()
+			p := ""
+			if u.BasicAuth {
+				p = password
+			}
+			u.StatusCode, u.Health = checkURLHealth(client, u.URL, o.Username, p)
+		}(&urls[i])
+	}
+	wg.Wait()
+}
+
+func (o *OpenOptions) printURLs(urls []OpenURL) error {
+	if o.JSON {
+		data, err := json.MarshalIndent(urls, "", "  ")
+		if err != nil {
+			return errors.Wrap(err, "marshalling the URLs to JSON")
+		}
+		_, err = fmt.Fprintln(o.Out, string(data))
+		return err
+	}
+	table := o.CreateTable()
+	header := "URL"
+	if o.OnlyViewHost {
+		header = "HOST"
+	}
+	if o.HealthCheck {
+		table.AddRow("NAME", "ENVIRONMENT", header, "HEALTH")
+	} else {
+		table.AddRow("NAME", "ENVIRONMENT", header)
+	}
+	for _, u := range urls {
+		text := u.URL
+		if o.OnlyViewHost {
+			text = util.URLToHostName(text)
+		}
+		health := u.Health
+		if health == healthOK {
+			health = util.ColorInfo(health)
+		} else if health != "" {
+			health = util.ColorError(health)
+		}
+		if o.HealthCheck {
+			table.AddRow(u.Name, u.Environment, text, health)
+		} else {
+			table.AddRow(u.Name, u.Environment, text)
+		}
+	}
+	table.Render()
+	return nil
+}
+
+// basicAuthPassword lazily loads the admin password of the installation used for basic authentication
+func (o *OpenOptions) basicAuthPassword() string {
+	if !o.passwordLoaded {
+		o.passwordLoaded = true
+		_, devNs, err := o.KubeClientAndDevNamespace()
+		if err == nil {
+			o.password, err = o.GetDefaultAdminPassword(devNs)
+		}
+		if err != nil {
+			log.Logger().Debugf("failed to load the admin password for basic authentication: %s", err)
+		}
+	}
+	return o.password
+}
+
+// checkURLHealth checks the URL responds successfully using basic authentication if a password is given. Returns the
+// status code and a description of the health
+func checkURLHealth(client *http.Client, u string, username string, password string) (int, string) {
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return 0, err.Error()
+	}
+	if password != "" {
+		req.SetBasicAuth(username, password)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err.Error()
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 200 && resp.StatusCode < 400 {
+		return resp.StatusCode, healthOK
+	}
+	return resp.StatusCode, resp.Status
+}
+
+// copyToClipboard copies the text to the clipboard using the clipboard command of the operating system
+func copyToClipboard(text string) error {
+	var candidates [][]string
+	switch runtime.GOOS {
+	case "darwin":
+		candidates = [][]string{{"pbcopy"}}
+	case "windows":
+		candidates = [][]string{{"clip"}}
+	default:
+		candidates = [][]string{{"wl-copy"}, {"xclip", "-selection", "clipboard"}, {"xsel", "--clipboard", "--input"}}
+	}
+	names := []string{}
+	for _, c := range candidates {
+		names = append(names, c[0])
+		cmd := util.Command{
+			Name: c[0],
+			Args: c[1:],
+			In:   strings.NewReader(text),
+		}
+		_, err := cmd.RunWithoutRetry()
+		if err == nil {
+			return nil
+		}
+		log.Logger().Debugf("failed to copy to the clipboard using %s: %s", c[0], err)
+	}
+	return fmt.Errorf("failed to copy to the clipboard. Install one of: %s", strings.Join(names, ", "))
 }
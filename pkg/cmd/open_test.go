@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckURLHealth(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username, password, ok := r.BasicAuth()
+		if !ok || username != "admin" || password != "s3cr3t" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	client := &http.Client{Timeout: 5 * time.Second}
+
+	code, health := checkURLHealth(client, server.URL, "admin", "s3cr3t")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, healthOK, health)
+
+	code, health = checkURLHealth(client, server.URL, "admin", "")
+	assert.Equal(t, http.StatusUnauthorized, code)
+	assert.Equal(t, "401 Unauthorized", health)
+
+	code, health = checkURLHealth(client, "http://127.0.0.1:0", "admin", "")
+	assert.Equal(t, 0, code)
+	assert.NotEmpty(t, health)
+}
+
+func TestCheckHealthConcurrently(t *testing.T) {
+	t.Parallel()
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(500 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer slow.Close()
+	broken := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer broken.Close()
+
+	o := &OpenOptions{}
+	o.HealthTimeout = 5 * time.Second
+	urls := []OpenURL{{Name: "a", URL: slow.URL}, {Name: "b", URL: slow.URL}, {Name: "c", URL: slow.URL}, {Name: "d", URL: broken.URL}}
+	start := time.Now()
+	o.checkHealth(urls)
+
+	assert.True(t, time.Since(start) < 1400*time.Millisecond, "the URLs should be checked concurrently")
+	for _, u := range urls[:3] {
+		assert.Equal(t, healthOK, u.Health)
+	}
+	assert.Equal(t, http.StatusServiceUnavailable, urls[3].StatusCode)
+}
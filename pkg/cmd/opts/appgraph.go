@@ -0,0 +1,51 @@
+package opts
+
+import (
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/appgraph"
+	"github.com/jenkins-x/jx/pkg/gits"
+	"github.com/jenkins-x/jx/pkg/kube"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// AppGraph creates the graph of the dependencies between the applications of the team. The dependencies of each
+// application are read from the `.jx/dependencies.yaml` file and the chart annotations in its git repository
+func (o *CommonOptions) AppGraph() (*appgraph.Graph, error) {
+	jxClient, ns, err := o.JXClientAndDevNamespace()
+	if err != nil {
+		return nil, err
+	}
+	srList, err := jxClient.JenkinsV1().SourceRepositories(ns).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list the SourceRepositories in namespace %s", ns)
+	}
+	envMap, _, err := kube.GetOrderedEnvironments(jxClient, ns)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to fetch environments in namespace %s", ns)
+	}
+	repositories := []v1.SourceRepository{}
+	for _, sr := range srList.Items {
+		if !kube.IsIncludedInTheGivenEnvs(envMap, &sr) {
+			repositories = append(repositories, sr)
+		}
+	}
+
+	providers := map[string]gits.GitProvider{}
+	fetch := func(sr *v1.SourceRepository) ([]string, error) {
+		gitURL, err := kube.GetRepositoryGitURL(sr)
+		if err != nil {
+			return nil, err
+		}
+		provider := providers[sr.Spec.Provider]
+		if provider == nil {
+			provider, _, err = o.CreateGitProviderForURLWithoutKind(gitURL)
+			if err != nil {
+				return nil, errors.Wrapf(err, "creating git provider for %s", gitURL)
+			}
+			providers[sr.Spec.Provider] = provider
+		}
+		return appgraph.FetchDependencies(provider, sr.Spec.Org, sr.Spec.Repo, "")
+	}
+	return appgraph.BuildGraph(repositories, fetch), nil
+}
@@ -0,0 +1,79 @@
+package opts
+
+import (
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/audit"
+	"github.com/jenkins-x/jx/pkg/collector"
+	"github.com/jenkins-x/jx/pkg/kube"
+	"github.com/jenkins-x/jx/pkg/kube/cluster"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/pkg/errors"
+)
+
+// NewAuditRecorder creates the recorder of the audit events using the sinks configured in the audit settings of the
+// team. The events are recorded as AuditEvent resources if no sinks are configured
+func (o *CommonOptions) NewAuditRecorder() (*audit.Recorder, error) {
+	jxClient, ns, err := o.JXClientAndDevNamespace()
+	if err != nil {
+		return nil, err
+	}
+	recorder := &audit.Recorder{
+		User:     audit.CurrentUser(cluster.IsInCluster()),
+		Pipeline: audit.CurrentPipeline(),
+	}
+	if o.Cmd != nil {
+		recorder.Command = o.Cmd.CommandPath()
+	}
+	devEnv, err := kube.GetDevEnvironment(jxClient, ns)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to find the dev environment in namespace %s", ns)
+	}
+	if devEnv == nil {
+		return recorder, nil
+	}
+	settings := &devEnv.Spec.TeamSettings
+	auditSettings := settings.Audit
+	if auditSettings == nil {
+		auditSettings = &v1.AuditSettings{}
+	}
+	if auditSettings.Disabled {
+		return recorder, nil
+	}
+	sinks := auditSettings.Sinks
+	if len(sinks) == 0 {
+		sinks = []string{audit.SinkCRD}
+	}
+	for _, sink := range sinks {
+		switch sink {
+		case audit.SinkCRD:
+			recorder.Sinks = append(recorder.Sinks, &audit.CRDSink{
+				JXClient:  jxClient,
+				Namespace: ns,
+			})
+		case audit.SinkBucket:
+			storageLocation := settings.StorageLocationOrDefault(kube.ClassificationAudit)
+			if storageLocation.IsEmpty() {
+				log.Logger().Warnf("No storage location is configured for classifier %s so audit events are not stored in a bucket", kube.ClassificationAudit)
+				continue
+			}
+			coll, err := collector.NewCollector(storageLocation, o.Git())
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to create the collector for storage settings %s", storageLocation.Description())
+			}
+			recorder.Sinks = append(recorder.Sinks, &audit.BucketSink{
+				Collector: coll,
+			})
+		case audit.SinkWebhook:
+			if auditSettings.WebhookURL == "" {
+				log.Logger().Warnf("No webhookUrl is configured in the audit settings so audit events are not posted to a webhook")
+				continue
+			}
+			recorder.Sinks = append(recorder.Sinks, &audit.WebhookSink{
+				URL: auditSettings.WebhookURL,
+			})
+		default:
+			log.Logger().Warnf("Ignoring the unknown audit sink %s. The supported sinks are: %v", sink, audit.Sinks)
+		}
+	}
+	return recorder, nil
+}
@@ -5,6 +5,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sync"
 
 	"github.com/jenkins-x/jx/pkg/buildpacks"
 
@@ -20,6 +21,9 @@ import (
 	"github.com/jenkins-x/jx/pkg/util"
 )
 
+// buildPacksLock serialises the cloning and pulling of the build packs so that projects can be imported in parallel
+var buildPacksLock sync.Mutex
+
 // InvokeDraftPack used to pass arguments into the draft pack invocation
 type InvokeDraftPack struct {
 	Dir                         string
@@ -45,6 +49,8 @@ func (o *CommonOptions) InitBuildPacks(i *InvokeDraftPack) (string, *v1.TeamSett
 	if i != nil && i.ProjectConfig != nil && i.ProjectConfig.BuildPackGitURL != "" {
 		buildPackURL = i.ProjectConfig.BuildPackGitURL
 	}
+	buildPacksLock.Lock()
+	defer buildPacksLock.Unlock()
 	dir, err := gitresolver.InitBuildPack(o.Git(), buildPackURL, settings.BuildPackRef)
 	return dir, settings, err
 }
@@ -21,6 +21,7 @@ func (o *CommonOptions) RunCommandFromDir(dir, name string, args ...string) erro
 	e.Stdout = o.Out
 	e.Stderr = o.Err
 	os.Setenv("PATH", util.PathWithBinary())
+	log.Logger().Debugf("running command: %s %s", name, strings.Join(util.RedactArgs(args), " "))
 	err := e.Run()
 	if err != nil {
 		log.Logger().Errorf("Error: Command failed  %s %s", name, strings.Join(args, " "))
@@ -35,6 +36,7 @@ func (o *CommonOptions) RunCommand(name string, args ...string) error {
 	e.Stdout = o.Out
 	e.Stderr = o.Err
 	os.Setenv("PATH", util.PathWithBinary())
+	log.Logger().Debugf("running command: %s %s", name, strings.Join(util.RedactArgs(args), " "))
 	err := e.Run()
 	if err != nil {
 		log.Logger().Errorf("Error: Command failed  %s %s", name, strings.Join(args, " "))
@@ -49,6 +51,7 @@ func (o *CommonOptions) RunCommandVerbose(name string, args ...string) error {
 	e.Stdout = o.Out
 	e.Stderr = o.Err
 	os.Setenv("PATH", util.PathWithBinary())
+	log.Logger().Debugf("running command: %s %s", name, strings.Join(util.RedactArgs(args), " "))
 	err := e.Run()
 	if err != nil {
 		log.Logger().Errorf("Error: Command failed  %s %s", name, strings.Join(args, " "))
@@ -66,6 +69,7 @@ func (o *CommonOptions) RunCommandVerboseAt(dir string, name string, args ...str
 	e.Stdout = o.Out
 	e.Stderr = o.Err
 	os.Setenv("PATH", util.PathWithBinary())
+	log.Logger().Debugf("running command: %s %s", name, strings.Join(util.RedactArgs(args), " "))
 	err := e.Run()
 	if err != nil {
 		log.Logger().Errorf("Error: Command failed  %s %s", name, strings.Join(args, " "))
@@ -62,12 +62,15 @@ const (
 	OptionEnvironment      = "env"
 	OptionInstallDeps      = "install-dependencies"
 	OptionLabel            = "label"
+	OptionLogFormat        = "log-format"
+	OptionLogLevel         = "log-level"
 	OptionName             = "name"
 	OptionNamespace        = "namespace"
 	OptionNoBrew           = "no-brew"
 	OptionRelease          = "release"
 	OptionServerName       = "name"
 	OptionOutputDir        = "output-dir"
+	OptionQuiet            = "quiet"
 	OptionServerURL        = "url"
 	OptionSkipAuthSecMerge = "skip-auth-secrets-merge"
 	OptionTimeout          = "timeout"
@@ -116,12 +119,15 @@ type CommonOptions struct {
 	ExternalJenkinsBaseURL string
 	In                     terminal.FileReader
 	InstallDependencies    bool
+	LogFormat              string
+	LogLevel               string
 	ModifyDevEnvironmentFn ModifyDevEnvironmentFn
 	ModifyEnvironmentFn    ModifyEnvironmentFn
 	NameServers            []string
 	NoBrew                 bool
 	RemoteCluster          bool
 	Out                    terminal.FileWriter
+	Quiet                  bool
 	ServiceAccount         string
 	SkipAuthSecretsMerge   bool
 	Username               string
@@ -222,6 +228,7 @@ func (o *CommonOptions) SetDevNamespace(ns string) {
 	o.devNamespace = ns
 	o.currentNamespace = ns
 	o.kubeClient = nil
+	log.AddFields(map[string]interface{}{"namespace": ns})
 	log.Logger().Debugf("Setting the dev namespace to: %s", util.ColorInfo(ns))
 }
 
@@ -238,7 +245,10 @@ func (o *CommonOptions) AddBaseFlags(cmd *cobra.Command) {
 		defaultBatchMode = true
 	}
 	cmd.PersistentFlags().BoolVarP(&o.BatchMode, OptionBatchMode, "b", defaultBatchMode, "Runs in batch mode without prompting for user input")
-	cmd.PersistentFlags().BoolVarP(&o.Verbose, OptionVerbose, "", false, "Enables verbose output")
+	cmd.PersistentFlags().BoolVarP(&o.Verbose, OptionVerbose, "", false, "Enables verbose output including the git, helm and kubectl command lines which are run")
+	cmd.PersistentFlags().BoolVarP(&o.Quiet, OptionQuiet, "q", false, "Suppresses informational output so that only warnings and errors are logged")
+	cmd.PersistentFlags().StringVarP(&o.LogLevel, OptionLogLevel, "", "", fmt.Sprintf("Sets the logging level. Overrides --verbose and $JX_LOG_LEVEL. One of: %s", strings.Join(log.GetLevels(), ", ")))
+	cmd.PersistentFlags().StringVarP(&o.LogFormat, OptionLogFormat, "", "", fmt.Sprintf("Sets the layout of the logging. Overrides $JX_LOG_FORMAT. One of: %s", strings.Join(log.GetFormats(), ", ")))
 
 	o.Cmd = cmd
 }
@@ -336,6 +346,9 @@ func (o *CommonOptions) KubeClientAndDevNamespace() (kubernetes.Interface, strin
 	}
 	if o.devNamespace == "" {
 		o.devNamespace, _, err = kube.GetDevNamespace(kubeClient, curNs)
+		if o.devNamespace != "" {
+			log.AddFields(map[string]interface{}{"namespace": o.devNamespace})
+		}
 	}
 	return kubeClient, o.devNamespace, err
 }
@@ -414,6 +427,11 @@ func (o *CommonOptions) TektonClient() (tektonclient.Interface, string, error) {
 	return o.tektonClient, o.currentNamespace, nil
 }
 
+// SetTektonClient sets the tekton client
+func (o *CommonOptions) SetTektonClient(client tektonclient.Interface) {
+	o.tektonClient = client
+}
+
 // KnativeBuildClient returns or creates the knative build client
 func (o *CommonOptions) KnativeBuildClient() (buildclient.Interface, string, error) {
 	if o.factory == nil {
@@ -1085,10 +1103,16 @@ func (o *CommonOptions) GetWebHookEndpoint() (string, error) {
 	var webHookUrl string
 
 	if isProwEnabled {
-		baseURL, err := services.GetServiceURLFromName(o.kubeClient, "hook", ns)
+		baseURL, err := o.WebhookTunnelURL()
 		if err != nil {
 			return "", err
 		}
+		if baseURL == "" {
+			baseURL, err = services.GetServiceURLFromName(o.kubeClient, "hook", ns)
+			if err != nil {
+				return "", err
+			}
+		}
 
 		webHookUrl = util.UrlJoin(baseURL, "hook")
 	} else {
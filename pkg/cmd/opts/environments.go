@@ -5,6 +5,9 @@ import (
 	"github.com/jenkins-x/jx/pkg/kube"
 	"github.com/jenkins-x/jx/pkg/kube/services"
 	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // RegisterEnvironmentCRD registers the CRD for environmnt
@@ -53,3 +56,39 @@ func (o *CommonOptions) ResolveChartMuseumURL() (string, error) {
 	}
 	return services.FindServiceURL(kubeClient, ns, kube.ServiceChartMuseum)
 }
+
+// DeletePreviewEnvironment deletes the helm release, the namespace and the Environment of the given preview environment
+// in the given development namespace
+func (o *CommonOptions) DeletePreviewEnvironment(ns string, env *jenkinsv1.Environment) error {
+	releaseName := kube.GetPreviewEnvironmentReleaseName(env)
+	if releaseName != "" {
+		log.Logger().Infof("Deleting helm release: %s", util.ColorInfo(releaseName))
+		err := o.Helm().DeleteRelease(ns, releaseName, true)
+		if err != nil {
+			return errors.Wrapf(err, "deleting helm release %s", releaseName)
+		}
+	}
+	jxClient, _, err := o.JXClient()
+	if err != nil {
+		return err
+	}
+	err = jxClient.JenkinsV1().Environments(ns).Delete(env.Name, &metav1.DeleteOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "deleting preview environment %s", env.Name)
+	}
+	log.Logger().Infof("Deleted preview environment %s", util.ColorInfo(env.Name))
+
+	envNs := env.Spec.Namespace
+	if envNs == "" {
+		return nil
+	}
+	kubeClient, err := o.KubeClient()
+	if err != nil {
+		return err
+	}
+	err = kubeClient.CoreV1().Namespaces().Delete(envNs, &metav1.DeleteOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "deleting namespace %s of preview environment %s", envNs, env.Name)
+	}
+	return nil
+}
@@ -285,6 +285,23 @@ func (o *CommonOptions) IsGitHubAppMode() (bool, error) {
 	return requirements != nil && requirements.GithubApp != nil && requirements.GithubApp.Enabled, nil
 }
 
+// WebhookTunnelURL returns the public URL of the tunnel which forwards webhooks to a local cluster or an empty
+// string if webhooks are not tunnelled
+func (o *CommonOptions) WebhookTunnelURL() (string, error) {
+	teamSettings, err := o.TeamSettings()
+	if err != nil {
+		return "", errors.Wrap(err, "error loading TeamSettings to determine the webhook tunnel")
+	}
+	requirements, err := config.GetRequirementsConfigFromTeamSettings(teamSettings)
+	if err != nil {
+		return "", errors.Wrap(err, "error getting Requirements from TeamSettings to determine the webhook tunnel")
+	}
+	if requirements == nil || requirements.Cluster.LocalConfig == nil || requirements.Cluster.LocalConfig.Tunnel == config.LocalTunnelNone {
+		return "", nil
+	}
+	return requirements.Cluster.LocalConfig.TunnelURL, nil
+}
+
 // InitGitConfigAndUser validates we have git setup
 func (o *CommonOptions) InitGitConfigAndUser() error {
 	// lets validate we have git configured
@@ -426,7 +426,15 @@ func (o *CommonOptions) GetSecretURLClient(location secrets.SecretsLocationKind)
 		if err != nil {
 			return o.secretURLClient, errors.Wrapf(err, "getting the file system secrets directory")
 		}
-		o.secretURLClient = localvault.NewFileSystemClient(dir)
+		encryptionKey, err := localvault.LoadEncryptionKey()
+		if err != nil {
+			return o.secretURLClient, errors.Wrapf(err, "loading the local secrets encryption key")
+		}
+		if encryptionKey != "" {
+			o.secretURLClient = localvault.NewEncryptedFileSystemClient(dir, encryptionKey)
+		} else {
+			o.secretURLClient = localvault.NewFileSystemClient(dir)
+		}
 	case secrets.AutoLocationKind:
 		location := o.detectSecretsLocation()
 		o.secretURLClient, err = o.GetSecretURLClient(location)
@@ -437,6 +445,23 @@ func (o *CommonOptions) GetSecretURLClient(location secrets.SecretsLocationKind)
 	return o.secretURLClient, err
 }
 
+// GetSecretURLClientAndScheme creates the secret URL client of the secrets location of the team along with the URL
+// scheme, such as 'vault:', used to reference its secrets from helm values
+func (o *CommonOptions) GetSecretURLClientAndScheme() (secreturl.Client, string, error) {
+	location := o.GetSecretsLocation()
+	if location == secrets.AutoLocationKind {
+		location = o.detectSecretsLocation()
+	}
+	client, err := o.GetSecretURLClient(location)
+	if err != nil {
+		return nil, "", err
+	}
+	if location == secrets.VaultLocationKind {
+		return client, "vault:", nil
+	}
+	return client, "local:", nil
+}
+
 // detectSecretsLocation detects dynamically the secrets location by trying to create a vault client
 func (o *CommonOptions) detectSecretsLocation() secrets.SecretsLocationKind {
 	_, err := o.SystemVaultClient(o.devNamespace)
@@ -815,6 +840,31 @@ func (o *CommonOptions) ReleaseChartRepositoryURL() string {
 	return chartRepo
 }
 
+// ReleaseChartRepository returns the chart repository the release pipelines publish charts to from the chartRepository
+// of the requirements. Defaults to the ChartMuseum at the ReleaseChartRepositoryURL
+func (o *CommonOptions) ReleaseChartRepository() *config.ChartRepositoryConfig {
+	answer := &config.ChartRepositoryConfig{}
+	teamSettings, err := o.TeamSettings()
+	if err != nil {
+		log.Logger().Warnf("failed to get the team settings: %s", err.Error())
+	} else {
+		requirements, err := config.GetRequirementsConfigFromTeamSettings(teamSettings)
+		if err != nil {
+			log.Logger().Warnf("failed to get the requirements from team settings: %s", err.Error())
+		} else if requirements != nil && requirements.ChartRepository != nil {
+			repository := *requirements.ChartRepository
+			answer = &repository
+		}
+	}
+	if answer.Type == "" {
+		answer.Type = config.ChartRepositoryTypeChartMuseum
+	}
+	if answer.URL == "" {
+		answer.URL = o.ReleaseChartRepositoryURL()
+	}
+	return answer
+}
+
 // EnsureHelm ensures helm is installed
 func (o *CommonOptions) EnsureHelm() error {
 	_, err := o.Helm().Version(false)
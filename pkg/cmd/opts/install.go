@@ -610,15 +610,10 @@ func (o *CommonOptions) InstallJx(upgrade bool, version string) error {
 			return o.RunCommand("brew", "install", "jx")
 		}
 	}
-	binDir, err := util.JXBinLocation()
+	binDir, err := jxBinDir()
 	if err != nil {
 		return err
 	}
-	// Check for jx binary in non standard path and install there instead if found...
-	nonStandardBinDir, err := util.JXBinaryLocation()
-	if err == nil && binDir != nonStandardBinDir {
-		binDir = nonStandardBinDir
-	}
 	binary := "jx"
 	fileName := binary
 	if !upgrade {
@@ -637,11 +632,7 @@ func (o *CommonOptions) InstallJx(upgrade bool, version string) error {
 		}
 		version = fmt.Sprintf("%s", latestVersion)
 	}
-	extension := "tar.gz"
-	if runtime.GOOS == "windows" {
-		extension = "zip"
-	}
-	clientURL := fmt.Sprintf("%s%s/"+binary+"-%s-%s.%s", config.BinaryDownloadBaseURL, version, runtime.GOOS, runtime.GOARCH, extension)
+	clientURL := fmt.Sprintf("%s%s/%s", config.BinaryDownloadBaseURL, version, JxArchiveName())
 	fullPath := filepath.Join(binDir, fileName)
 	if runtime.GOOS == "windows" {
 		fullPath += ".exe"
@@ -651,6 +642,47 @@ func (o *CommonOptions) InstallJx(upgrade bool, version string) error {
 	if err != nil {
 		return err
 	}
+	return installJxArchive(tmpArchiveFile, binDir, fileName, o.Verbose)
+}
+
+// JxArchiveName returns the name of the release archive of jx for the current operating system and architecture
+func JxArchiveName() string {
+	extension := "tar.gz"
+	if runtime.GOOS == "windows" {
+		extension = "zip"
+	}
+	return fmt.Sprintf("jx-%s-%s.%s", runtime.GOOS, runtime.GOARCH, extension)
+}
+
+// InstallJxArchive replaces the jx binary with the binary in the downloaded release archive which is removed
+func (o *CommonOptions) InstallJxArchive(archiveFile string) error {
+	binDir, err := jxBinDir()
+	if err != nil {
+		return err
+	}
+	return installJxArchive(archiveFile, binDir, "jx", o.Verbose)
+}
+
+// jxBinDir returns the directory jx is installed in
+func jxBinDir() (string, error) {
+	binDir, err := util.JXBinLocation()
+	if err != nil {
+		return "", err
+	}
+	// Check for jx binary in non standard path and install there instead if found...
+	nonStandardBinDir, err := util.JXBinaryLocation()
+	if err == nil && binDir != nonStandardBinDir {
+		binDir = nonStandardBinDir
+	}
+	return binDir, nil
+}
+
+func installJxArchive(tmpArchiveFile string, binDir string, fileName string, verbose bool) error {
+	binary := "jx"
+	fullPath := filepath.Join(binDir, fileName)
+	if runtime.GOOS == "windows" {
+		fullPath += ".exe"
+	}
 	// Untar the new binary into a temp directory
 	jxHome, err := util.ConfigDir()
 	if err != nil {
@@ -667,7 +699,7 @@ func (o *CommonOptions) InstallJx(upgrade bool, version string) error {
 			return err
 		}
 		err = os.Remove(filepath.Join(binDir, "jx"))
-		if err != nil && o.Verbose {
+		if err != nil && verbose {
 			log.Logger().Infof("Skipping removal of old jx binary: %s", err)
 		}
 		// Copy over the new binary
@@ -1167,43 +1199,57 @@ func (o *CommonOptions) InstallProw(useTekton bool, useExternalDNS bool, isGitOp
 
 // CreateWebhookProw create a webhook for prow using the given git provider
 func (o *CommonOptions) CreateWebhookProw(gitURL string, gitProvider gits.GitProvider) error {
-	client, err := o.KubeClient()
+	webhook, err := o.ProwWebhookArguments(gitURL)
 	if err != nil {
 		return err
 	}
+	return gitProvider.CreateWebHook(webhook)
+}
+
+// ProwWebhookArguments returns the arguments of the prow webhook of the git repository
+func (o *CommonOptions) ProwWebhookArguments(gitURL string) (*gits.GitWebHookArguments, error) {
+	client, err := o.KubeClient()
+	if err != nil {
+		return nil, err
+	}
 	ns, _, err := kube.GetDevNamespace(client, o.currentNamespace)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	gitInfo, err := gits.ParseGitURL(gitURL)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	baseURL, err := services.FindServiceURL(client, ns, "hook")
+	baseURL, err := o.WebhookTunnelURL()
 	if err != nil {
-		return errors.Wrapf(err, "in namespace %s", ns)
+		return nil, err
 	}
 	if baseURL == "" {
-		return fmt.Errorf("failed to find external URL of service hook in namespace %s", ns)
+		baseURL, err = services.FindServiceURL(client, ns, "hook")
+		if err != nil {
+			return nil, errors.Wrapf(err, "in namespace %s", ns)
+		}
+	}
+	if baseURL == "" {
+		return nil, fmt.Errorf("failed to find external URL of service hook in namespace %s", ns)
 	}
 	webhookUrl := util.UrlJoin(baseURL, "hook")
 
 	hmacToken, err := client.CoreV1().Secrets(ns).Get("hmac-token", metav1.GetOptions{})
 	if err != nil {
-		return err
+		return nil, err
 	}
 	isInsecureSSL, err := o.IsInsecureSSLWebhooks()
 	if err != nil {
-		return errors.Wrapf(err, "failed to check if we need to setup insecure SSL webhook")
+		return nil, errors.Wrapf(err, "failed to check if we need to setup insecure SSL webhook")
 	}
-	webhook := &gits.GitWebHookArguments{
+	return &gits.GitWebHookArguments{
 		Owner:       gitInfo.Organisation,
 		Repo:        gitInfo,
 		URL:         webhookUrl,
 		Secret:      string(hmacToken.Data["hmac"]),
 		InsecureSSL: isInsecureSSL,
-	}
-	return gitProvider.CreateWebHook(webhook)
+	}, nil
 }
 
 // IsProw checks if prow is available in the cluster
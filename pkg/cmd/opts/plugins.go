@@ -88,6 +88,9 @@ func (o *CommonOptions) GetPluginCommandGroups(verifier extensions.PathVerifier)
 	}
 
 	paths := sets.NewString(filepath.SplitList(os.Getenv(path))...)
+	if installedDir, err := extensions.InstalledPluginBinDir(); err == nil {
+		paths.Insert(installedDir)
+	}
 	for _, dir := range paths.List() {
 		files, err := ioutil.ReadDir(dir)
 		if err != nil {
@@ -54,7 +54,7 @@ func (o *CommonOptions) LoadQuickStartsFromLocations(locations []v1.QuickStartLo
 			m = map[string]v1.QuickStartLocation{}
 			gitMap[loc.GitURL] = m
 		}
-		m[loc.Owner] = loc
+		m[loc.Owner+"/"+loc.Catalog] = loc
 	}
 	model := quickstarts.NewQuickstartModel()
 
@@ -68,6 +68,14 @@ func (o *CommonOptions) LoadQuickStartsFromLocations(locations []v1.QuickStartLo
 			if err != nil {
 				return model, err
 			}
+			if location.Catalog != "" {
+				log.Logger().Debugf("Loading quickstart catalog %s/%s in Git server %s as user %s ", location.Owner, location.Catalog, gitProvider.ServerURL(), gitProvider.CurrentUsername())
+				err = model.LoadCatalogQuickstarts(gitProvider, o.Git(), location.Owner, location.Catalog, location.Includes, location.Excludes)
+				if err != nil {
+					log.Logger().Warnf("Quickstart catalog %s/%s load error: %s", location.Owner, location.Catalog, err.Error())
+				}
+				continue
+			}
 			log.Logger().Debugf("Searching for repositories in Git server %s owner %s includes %s excludes %s as user %s ", gitProvider.ServerURL(), location.Owner, strings.Join(location.Includes, ", "), strings.Join(location.Excludes, ", "), gitProvider.CurrentUsername())
 			err = model.LoadGithubQuickstarts(gitProvider, location.Owner, location.Includes, location.Excludes)
 			if err != nil {
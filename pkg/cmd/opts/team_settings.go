@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"os/user"
+	"path/filepath"
 	"reflect"
 
 	"github.com/jenkins-x/jx/pkg/kube/cluster"
@@ -17,7 +18,9 @@ import (
 	"github.com/jenkins-x/jx/pkg/log"
 
 	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/config"
 	"github.com/jenkins-x/jx/pkg/kube"
+	"github.com/jenkins-x/jx/pkg/util"
 	"github.com/pkg/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -42,6 +45,31 @@ func (o *CommonOptions) TeamSettings() (*v1.TeamSettings, error) {
 	return teamSettings, nil
 }
 
+// TeamRequirements returns the requirements from the requirements file in the directory if there is one otherwise
+// from the team settings
+func (o *CommonOptions) TeamRequirements(dir string) (*config.RequirementsConfig, error) {
+	fileName := filepath.Join(dir, config.RequirementsConfigFileName)
+	exists, err := util.FileExists(fileName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to check if file %s exists", fileName)
+	}
+	if exists {
+		return config.LoadRequirementsConfigFile(fileName)
+	}
+	settings, err := o.TeamSettings()
+	if err != nil {
+		return nil, err
+	}
+	requirements, err := config.GetRequirementsConfigFromTeamSettings(settings)
+	if err != nil {
+		return nil, err
+	}
+	if requirements == nil {
+		return nil, fmt.Errorf("no %s in directory %s or in the team settings", config.RequirementsConfigFileName, dir)
+	}
+	return requirements, nil
+}
+
 // DevEnvAndTeamSettings returns the Dev Environment and Team settings
 func (o *CommonOptions) DevEnvAndTeamSettings() (*v1.Environment, *v1.TeamSettings, error) {
 	var teamSettings *v1.TeamSettings
@@ -1,6 +1,7 @@
 package opts
 
 import (
+	"fmt"
 	"strings"
 
 	"github.com/jenkins-x/jx/pkg/versionstream/versionstreamrepo"
@@ -11,6 +12,8 @@ import (
 	"github.com/jenkins-x/jx/pkg/table"
 	"github.com/jenkins-x/jx/pkg/util"
 	"github.com/jenkins-x/jx/pkg/version"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // CreateVersionResolver creates a new VersionResolver service
@@ -149,3 +152,77 @@ func (o *CommonOptions) CloneJXVersionsRepo(versionRepository string, versionRef
 	}
 	return versionstreamrepo.CloneJXVersionsRepo(versionRepository, versionRef, settings, o.Git(), o.BatchMode, o.AdvancedMode, o.GetIOFileHandles())
 }
+
+// VerifyPlatformCompatibility verifies the current cluster is compatible with the platform version using the
+// compatibility manifest of the version stream so that an upgrade which would break the cluster is blocked
+func (o *CommonOptions) VerifyPlatformCompatibility(versionsDir string, platformVersion string) error {
+	compatibility, err := versionstream.LoadCompatibility(versionsDir)
+	if err != nil {
+		return err
+	}
+	platform, err := compatibility.ForPlatform(platformVersion)
+	if err != nil {
+		return err
+	}
+	if platform == nil {
+		log.Logger().Debugf("the version stream has no compatibility manifest for platform version %s", platformVersion)
+		return nil
+	}
+
+	kubeClient, err := o.KubeClient()
+	if err != nil {
+		return err
+	}
+	kubernetesVersion := ""
+	serverVersion, err := kubeClient.Discovery().ServerVersion()
+	if err != nil {
+		log.Logger().Warnf("Failed to get Kubernetes server version so not checking Kubernetes compatibility: %s", err)
+	} else if serverVersion != nil {
+		kubernetesVersion = serverVersion.String()
+	}
+
+	crdVersions := map[string][]string{}
+	if len(platform.CRDs) > 0 {
+		crdVersions, err = o.ServedCRDVersions()
+		if err != nil {
+			return err
+		}
+	}
+
+	problems, err := platform.Incompatibilities(kubernetesVersion, crdVersions)
+	if err != nil {
+		return err
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("the upgrade to platform version %s is blocked as the cluster is not compatible:\n  %s",
+			platformVersion, strings.Join(problems, "\n  "))
+	}
+	log.Logger().Infof("The cluster is compatible with platform version %s", util.ColorInfo(platformVersion))
+	return nil
+}
+
+// ServedCRDVersions returns the versions served by each CustomResourceDefinition in the cluster by name
+func (o *CommonOptions) ServedCRDVersions() (map[string][]string, error) {
+	apisClient, err := o.ApiExtensionsClient()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create the API extensions client")
+	}
+	crds, err := apisClient.ApiextensionsV1beta1().CustomResourceDefinitions().List(metav1.ListOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list the CustomResourceDefinitions")
+	}
+	answer := map[string][]string{}
+	for _, crd := range crds.Items {
+		served := []string{}
+		for _, v := range crd.Spec.Versions {
+			if v.Served {
+				served = append(served, v.Name)
+			}
+		}
+		if len(served) == 0 && crd.Spec.Version != "" {
+			served = append(served, crd.Spec.Version)
+		}
+		answer[crd.Name] = served
+	}
+	return answer, nil
+}
@@ -0,0 +1,69 @@
+package plugin
+
+import (
+	"github.com/jenkins-x/jx/pkg/cmd/helper"
+	"github.com/spf13/cobra"
+
+	"github.com/jenkins-x/jx/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/pkg/cmd/templates"
+)
+
+// PluginOptions contains the command line options
+type PluginOptions struct {
+	*opts.CommonOptions
+}
+
+var (
+	pluginLong = templates.LongDesc(`
+		Installs and lists plugins which extend jx with additional commands.
+
+		A plugin is any executable called 'jx-foo' which is then available as 'jx foo'. Plugins are found on the PATH
+		or are installed by 'jx plugin install'.
+
+		Plugins are invoked with the context of the current jx session in the environment variables:
+
+		* KUBECONFIG the kubernetes configuration file
+		* JX_HOME the jx configuration directory
+		* JX_TEAM_NAMESPACE the development namespace of the current team
+		* JX_GIT_SERVER, JX_GIT_USERNAME and JX_GIT_TOKEN the current git server and credentials
+`)
+
+	pluginExample = templates.Examples(`
+		# Install a plugin
+		jx plugin install https://example.com/releases/download/v{{.Version}}/jx-foo-{{.OS}}-{{.Arch}}.tar.gz
+
+		# List the available plugins
+		jx plugin list
+	`)
+)
+
+// NewCmdPlugin creates the command object
+func NewCmdPlugin(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &PluginOptions{
+		commonOpts,
+	}
+
+	cmd := &cobra.Command{
+		Use:     "plugin ACTION [flags]",
+		Short:   "Installs and lists plugins which add commands to jx",
+		Long:    pluginLong,
+		Example: pluginExample,
+		Aliases: []string{"plugins"},
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+
+	cmd.AddCommand(NewCmdPluginInstall(commonOpts))
+	cmd.AddCommand(NewCmdPluginList(commonOpts))
+	cmd.AddCommand(NewCmdPluginUninstall(commonOpts))
+	return cmd
+}
+
+// Run implements this command
+func (o *PluginOptions) Run() error {
+	return o.Cmd.Help()
+}
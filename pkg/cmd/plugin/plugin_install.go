@@ -0,0 +1,185 @@
+package plugin
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/jenkins-x/jx/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/pkg/extensions"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/packages"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/jenkins-x/jx/pkg/versionstream"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// PluginInstallOptions the options for the command
+type PluginInstallOptions struct {
+	*opts.CommonOptions
+
+	URL     string
+	Name    string
+	Version string
+}
+
+var (
+	pluginInstallLong = templates.LongDesc(`
+		Installs a plugin from a download URL so that it can be invoked as a jx command.
+
+		The URL is a template which can refer to {{.Version}}, {{.OS}} and {{.Arch}}. It can point either at the
+		executable itself or at a .tar.gz, .tgz or .zip archive containing it.
+
+		If no version is specified the version is pinned from the 'packages' folder of the version stream using
+		the name of the plugin such as 'packages/jx-foo.yml'.
+`)
+
+	pluginInstallExample = templates.Examples(`
+		# Install the jx-foo plugin at the version in the version stream so it can be invoked as 'jx foo'
+		jx plugin install https://example.com/releases/download/v{{.Version}}/jx-foo-{{.OS}}-{{.Arch}}.tar.gz
+
+		# Install a specific version of a plugin
+		jx plugin install --version 1.2.3 https://example.com/releases/download/v{{.Version}}/jx-foo-{{.OS}}-{{.Arch}}.tar.gz
+	`)
+)
+
+// NewCmdPluginInstall creates the command object
+func NewCmdPluginInstall(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &PluginInstallOptions{
+		CommonOptions: commonOpts,
+	}
+
+	cmd := &cobra.Command{
+		Use:     "install URL",
+		Short:   "Installs a plugin from a download URL",
+		Long:    pluginInstallLong,
+		Example: pluginInstallExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.Flags().StringVarP(&options.Name, "name", "n", "", "The name of the plugin executable such as 'jx-foo'. Defaults to the name in the URL")
+	cmd.Flags().StringVarP(&options.Version, "version", "v", "", "The version of the plugin to install. Defaults to the version in the version stream")
+	return cmd
+}
+
+// Run implements this command
+func (o *PluginInstallOptions) Run() error {
+	if o.URL == "" && len(o.Args) > 0 {
+		o.URL = o.Args[0]
+	}
+	if o.URL == "" {
+		return util.MissingArgument("URL")
+	}
+	if o.Name == "" {
+		o.Name = extensions.PluginNameFromURL(o.URL)
+		if o.Name == "" {
+			return util.MissingOption("name")
+		}
+	}
+	if !strings.HasPrefix(o.Name, extensions.PluginPrefix) {
+		o.Name = extensions.PluginPrefix + o.Name
+	}
+
+	if o.Version == "" && strings.Contains(o.URL, ".Version") {
+		resolver, err := o.GetVersionResolver()
+		if err != nil {
+			return errors.Wrap(err, "failed to create the version resolver")
+		}
+		o.Version, err = resolver.StableVersionNumber(versionstream.KindPackage, o.Name)
+		if err != nil {
+			return errors.Wrapf(err, "failed to find the version of plugin %s in the version stream", o.Name)
+		}
+		if o.Version == "" {
+			return fmt.Errorf("no version of plugin %s found in the version stream. Please specify one via the --version option", o.Name)
+		}
+	}
+
+	downloadURL, err := extensions.PluginDownloadURL(o.URL, o.Version)
+	if err != nil {
+		return err
+	}
+	binDir, err := extensions.InstalledPluginBinDir()
+	if err != nil {
+		return errors.Wrap(err, "failed to find the plugins directory")
+	}
+	fileName, err := installPlugin(downloadURL, binDir, o.Name)
+	if err != nil {
+		return err
+	}
+
+	installed, err := extensions.LoadInstalledPlugins()
+	if err != nil {
+		return err
+	}
+	installed.SetPlugin(extensions.InstalledPlugin{
+		Name:    o.Name,
+		Version: o.Version,
+		URL:     o.URL,
+	})
+	err = installed.Save()
+	if err != nil {
+		return errors.Wrap(err, "failed to save the installed plugins")
+	}
+
+	command := strings.Replace(strings.TrimPrefix(o.Name, extensions.PluginPrefix), "-", " ", -1)
+	log.Logger().Infof("Installed plugin %s to %s so it can be invoked via: %s", util.ColorInfo(o.Name), util.ColorInfo(fileName), util.ColorInfo("jx "+command))
+	return nil
+}
+
+// installPlugin downloads the plugin executable, extracting it if its an archive, into the directory returning
+// the path of the executable
+func installPlugin(downloadURL string, binDir string, name string) (string, error) {
+	if runtime.GOOS == "windows" && !strings.HasSuffix(name, ".exe") {
+		name += ".exe"
+	}
+	fileName := filepath.Join(binDir, name)
+
+	if !extensions.IsArchive(downloadURL) {
+		tmpFile := fileName + ".tmp"
+		err := packages.DownloadFile(downloadURL, tmpFile)
+		if err != nil {
+			return "", err
+		}
+		err = os.Rename(tmpFile, fileName)
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to move %s to %s", tmpFile, fileName)
+		}
+	} else {
+		archiveFile := fileName + ".tmp" + strings.TrimPrefix(downloadURL, extensions.TrimArchiveExtension(downloadURL))
+		err := packages.DownloadFile(downloadURL, archiveFile)
+		if err != nil {
+			return "", err
+		}
+		defer os.Remove(archiveFile)
+		if strings.HasSuffix(archiveFile, ".zip") {
+			err = util.UnzipSpecificFiles(archiveFile, binDir, name)
+		} else {
+			err = util.UnTargz(archiveFile, binDir, []string{name})
+		}
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to extract %s from %s", name, downloadURL)
+		}
+	}
+
+	exists, err := util.FileExists(fileName)
+	if err != nil {
+		return "", err
+	}
+	if !exists {
+		return "", fmt.Errorf("no executable %s found in %s", name, downloadURL)
+	}
+	err = os.Chmod(fileName, 0755)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to make %s executable", fileName)
+	}
+	return fileName, nil
+}
@@ -0,0 +1,43 @@
+package plugin
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstallPlugin(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("skipping on windows as the executable name has an .exe suffix")
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("#!/bin/sh\necho foo\n"))
+	}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "test-install-plugin-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	fileName, err := installPlugin(server.URL+"/jx-foo-linux-amd64", dir, "jx-foo")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "jx-foo"), fileName)
+
+	info, err := os.Stat(fileName)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0755), info.Mode().Perm())
+}
+
+func TestPluginCommand(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "jx foo", pluginCommand("jx-foo"))
+	assert.Equal(t, "jx foo bar", pluginCommand("jx-foo-bar"))
+	assert.Equal(t, "jx foo", pluginCommand("jx-foo.exe"))
+}
@@ -0,0 +1,104 @@
+package plugin
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/jenkins-x/jx/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/pkg/extensions"
+	"github.com/spf13/cobra"
+)
+
+// PluginListOptions the options for the command
+type PluginListOptions struct {
+	*opts.CommonOptions
+}
+
+var (
+	pluginListLong = templates.LongDesc(`
+		Lists the plugins installed by 'jx plugin install' along with their versions and any 'jx-' executables
+		found on the PATH.
+`)
+
+	pluginListExample = templates.Examples(`
+		# List the available plugins
+		jx plugin list
+	`)
+)
+
+// NewCmdPluginList creates the command object
+func NewCmdPluginList(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &PluginListOptions{
+		CommonOptions: commonOpts,
+	}
+
+	cmd := &cobra.Command{
+		Use:     "list",
+		Short:   "Lists the available plugins",
+		Long:    pluginListLong,
+		Example: pluginListExample,
+		Aliases: []string{"ls"},
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	return cmd
+}
+
+// Run implements this command
+func (o *PluginListOptions) Run() error {
+	installed, err := extensions.LoadInstalledPlugins()
+	if err != nil {
+		return err
+	}
+
+	table := o.CreateTable()
+	table.AddRow("COMMAND", "VERSION", "SOURCE")
+	for _, plugin := range installed.Plugins {
+		table.AddRow(pluginCommand(plugin.Name), plugin.Version, plugin.URL)
+	}
+	for _, fileName := range pathPlugins() {
+		table.AddRow(pluginCommand(filepath.Base(fileName)), "", fileName)
+	}
+	table.Render()
+	return nil
+}
+
+// pluginCommand returns the jx command which invokes the plugin executable
+func pluginCommand(name string) string {
+	name = strings.TrimSuffix(name, ".exe")
+	return "jx " + strings.Replace(strings.TrimPrefix(name, extensions.PluginPrefix), "-", " ", -1)
+}
+
+// pathPlugins returns the plugin executables found on the PATH
+func pathPlugins() []string {
+	answer := []string{}
+	path := "PATH"
+	if runtime.GOOS == "windows" {
+		path = "path"
+	}
+	seen := map[string]bool{}
+	for _, dir := range filepath.SplitList(os.Getenv(path)) {
+		files, err := ioutil.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, f := range files {
+			name := f.Name()
+			if f.IsDir() || !strings.HasPrefix(name, extensions.PluginPrefix) || seen[name] {
+				continue
+			}
+			seen[name] = true
+			answer = append(answer, filepath.Join(dir, name))
+		}
+	}
+	return answer
+}
@@ -0,0 +1,85 @@
+package plugin
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/jenkins-x/jx/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/pkg/extensions"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// PluginUninstallOptions the options for the command
+type PluginUninstallOptions struct {
+	*opts.CommonOptions
+}
+
+var (
+	pluginUninstallLong = templates.LongDesc(`
+		Removes a plugin installed by 'jx plugin install'.
+`)
+
+	pluginUninstallExample = templates.Examples(`
+		# Uninstall the jx-foo plugin
+		jx plugin uninstall jx-foo
+	`)
+)
+
+// NewCmdPluginUninstall creates the command object
+func NewCmdPluginUninstall(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &PluginUninstallOptions{
+		CommonOptions: commonOpts,
+	}
+
+	cmd := &cobra.Command{
+		Use:     "uninstall NAME",
+		Short:   "Removes a plugin installed by 'jx plugin install'",
+		Long:    pluginUninstallLong,
+		Example: pluginUninstallExample,
+		Aliases: []string{"remove", "rm"},
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	return cmd
+}
+
+// Run implements this command
+func (o *PluginUninstallOptions) Run() error {
+	if len(o.Args) == 0 {
+		return util.MissingArgument("NAME")
+	}
+	installed, err := extensions.LoadInstalledPlugins()
+	if err != nil {
+		return err
+	}
+	for _, arg := range o.Args {
+		name := arg
+		if !installed.RemovePlugin(name) {
+			name = extensions.PluginPrefix + arg
+			if !installed.RemovePlugin(name) {
+				return fmt.Errorf("plugin %s is not installed", util.ColorInfo(arg))
+			}
+		}
+		fileName, err := extensions.LookupInstalledPlugin(name)
+		if err != nil {
+			return err
+		}
+		if fileName != "" {
+			err = os.Remove(fileName)
+			if err != nil {
+				return errors.Wrapf(err, "failed to remove %s", fileName)
+			}
+		}
+		log.Logger().Infof("Uninstalled plugin %s", util.ColorInfo(name))
+	}
+	return installed.Save()
+}
@@ -51,11 +51,18 @@ var (
 
 		For more documentation on Preview Environments see: [https://jenkins-x.io/about/features/#preview-environments](https://jenkins-x.io/about/features/#preview-environments)
 
+		Once the preview is available the paths given via --check-path are requested and their HTTP status is added to
+		the Pull Request comment along with a screenshot captured by the --screenshot-command, so that reviewers can see
+		whether the preview came up healthy.
+
 `)
 
 	previewExample = templates.Examples(`
 		# Create or updates the Preview Environment for the Pull Request
 		jx preview
+
+		# Check the home page and the health endpoint of the preview and add a screenshot to the Pull Request comment
+		jx preview --check-path / --check-path /actuator/health --screenshot-command 'chromium --headless --screenshot=$SCREENSHOT_FILE $PREVIEW_URL'
 	`)
 )
 
@@ -99,6 +106,9 @@ type PreviewOptions struct {
 	GitInfo         *gits.GitRepository
 	NoComment       bool
 
+	CheckPaths        []string
+	ScreenshotCommand string
+
 	// calculated fields
 	PostPreviewJobTimeoutDuration time.Duration
 	PostPreviewJobPollDuration    time.Duration
@@ -159,6 +169,8 @@ func (o *PreviewOptions) AddPreviewOptions(cmd *cobra.Command) {
 	cmd.Flags().StringVarP(&o.PostPreviewJobPollTime, optionPostPreviewJobPollTime, "", "10s", "The amount of time between polls for the post preview Job status")
 	cmd.Flags().StringVarP(&o.PreviewHealthTimeout, optionPreviewHealthTimeout, "", "5m", "The amount of time to wait for the preview application to become healthy")
 	cmd.Flags().BoolVarP(&o.NoComment, "no-comment", "", false, "Disables commenting on the Pull Request after preview is created.")
+	cmd.Flags().StringArrayVarP(&o.CheckPaths, "check-path", "", nil, "The paths of the preview application to check after it is deployed such as '/' or '/health'. The results are added to the Pull Request comment")
+	cmd.Flags().StringVarP(&o.ScreenshotCommand, "screenshot-command", "", "", fmt.Sprintf("The shell command which captures a screenshot of the preview application at $%s to the PNG file $%s. The screenshot is stored in the '%s' storage location and added to the Pull Request comment", ScreenshotURLEnvVar, ScreenshotFileEnvVar, kube.ClassificationPreviews))
 }
 
 // Run implements the command
@@ -345,12 +357,25 @@ func (o *PreviewOptions) Run() error {
 		}
 	}
 
+	commitSha := ""
+	if pullRequest != nil {
+		commitSha = pullRequest.LastCommitSha
+	}
+
 	environmentsResource := jxClient.JenkinsV1().Environments(ns)
 	env, err := environmentsResource.Get(o.Name, metav1.GetOptions{})
 	if err == nil {
 		// lets check for updates...
 		update := false
 
+		if commitSha != "" && env.Annotations[kube.AnnotationPreviewCommit] != commitSha {
+			if env.Annotations == nil {
+				env.Annotations = map[string]string{}
+			}
+			env.Annotations[kube.AnnotationPreviewCommit] = commitSha
+			update = true
+		}
+
 		spec := &env.Spec
 		source := &spec.Source
 		if spec.Label != o.Label {
@@ -463,6 +488,9 @@ func (o *PreviewOptions) Run() error {
 				PreviewGitSpec: previewGitSpec,
 			},
 		}
+		if commitSha != "" {
+			env.Annotations[kube.AnnotationPreviewCommit] = commitSha
+		}
 		_, err = environmentsResource.Create(env)
 		if err != nil {
 			return fmt.Errorf("Failed to create environment in namespace %s due to: %s", ns, err)
@@ -624,6 +652,10 @@ func (o *PreviewOptions) Run() error {
 			}
 		}
 		log.Logger().Infof("Preview application is now available at: %s\n", util.ColorInfo(url))
+
+		if len(o.CheckPaths) > 0 || o.ScreenshotCommand != "" {
+			comment += o.checkPreview(url)
+		}
 	}
 
 	stepPRCommentOptions := pr.StepPRCommentOptions{
@@ -0,0 +1,176 @@
+package preview
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jenkins-x/jx/pkg/builds"
+	"github.com/jenkins-x/jx/pkg/collector"
+	"github.com/jenkins-x/jx/pkg/kube"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+)
+
+const (
+	// ScreenshotURLEnvVar the environment variable containing the preview URL for the screenshot command
+	ScreenshotURLEnvVar = "PREVIEW_URL"
+	// ScreenshotFileEnvVar the environment variable containing the file the screenshot command should write to
+	ScreenshotFileEnvVar = "SCREENSHOT_FILE"
+
+	defaultCheckTimeout = 30 * time.Second
+)
+
+// PreviewCheckResult the result of checking an endpoint of a preview application
+type PreviewCheckResult struct {
+	Path       string
+	URL        string
+	StatusCode int
+	Status     string
+	Duration   time.Duration
+	Error      string
+}
+
+// Healthy returns true if the endpoint responded with a successful status code
+func (r *PreviewCheckResult) Healthy() bool {
+	return r.Error == "" && r.StatusCode >= 200 && r.StatusCode < 400
+}
+
+// CheckPreviewEndpoints requests each path relative to the preview URL returning the results
+func CheckPreviewEndpoints(client *http.Client, previewURL string, paths []string) []*PreviewCheckResult {
+	var answer []*PreviewCheckResult
+	for _, path := range paths {
+		u := previewURL
+		if path != "" && path != "/" {
+			u = util.UrlJoin(previewURL, path)
+		}
+		result := &PreviewCheckResult{
+			Path: path,
+			URL:  u,
+		}
+		start := time.Now()
+		resp, err := client.Get(u) // #nosec
+		result.Duration = time.Since(start)
+		if err != nil {
+			result.Error = err.Error()
+		} else {
+			resp.Body.Close()
+			result.StatusCode = resp.StatusCode
+			result.Status = resp.Status
+		}
+		answer = append(answer, result)
+	}
+	return answer
+}
+
+// PreviewCheckComment returns the markdown for the Pull Request comment describing the results of the checks and the
+// screenshot of the preview, if any
+func PreviewCheckComment(results []*PreviewCheckResult, screenshotURL string) string {
+	var buffer strings.Builder
+	if len(results) > 0 {
+		unhealthy := 0
+		for _, r := range results {
+			if !r.Healthy() {
+				unhealthy++
+			}
+		}
+		if unhealthy == 0 {
+			buffer.WriteString(fmt.Sprintf("\n\n:white_check_mark: All %d checked endpoints of the preview are healthy\n", len(results)))
+		} else {
+			buffer.WriteString(fmt.Sprintf("\n\n:x: %d of %d checked endpoints of the preview are unhealthy\n", unhealthy, len(results)))
+		}
+		buffer.WriteString("\n| Endpoint | Status | Time |\n| --- | --- | --- |\n")
+		for _, r := range results {
+			status := r.Status
+			if r.Error != "" {
+				status = r.Error
+			}
+			icon := ":white_check_mark:"
+			if !r.Healthy() {
+				icon = ":x:"
+			}
+			buffer.WriteString(fmt.Sprintf("| [%s](%s) | %s %s | %s |\n", r.Path, r.URL, icon, status, r.Duration.Round(time.Millisecond)))
+		}
+	}
+	if screenshotURL != "" {
+		buffer.WriteString(fmt.Sprintf("\n\n![preview screenshot](%s)\n", screenshotURL))
+	}
+	return buffer.String()
+}
+
+// checkPreview runs the configured post deploy checks against the preview URL returning the markdown to add to the
+// Pull Request comment
+func (o *PreviewOptions) checkPreview(previewURL string) string {
+	var results []*PreviewCheckResult
+	if len(o.CheckPaths) > 0 {
+		client := util.GetClientWithTimeout(defaultCheckTimeout)
+		results = CheckPreviewEndpoints(client, previewURL, o.CheckPaths)
+		for _, r := range results {
+			if r.Healthy() {
+				log.Logger().Infof("Preview endpoint %s returned %s", util.ColorInfo(r.URL), r.Status)
+			} else {
+				log.Logger().Warnf("Preview endpoint %s is unhealthy: %s%s", r.URL, r.Status, r.Error)
+			}
+		}
+	}
+	screenshotURL := ""
+	if o.ScreenshotCommand != "" {
+		var err error
+		screenshotURL, err = o.captureScreenshot(previewURL)
+		if err != nil {
+			log.Logger().Warnf("Failed to capture a screenshot of the preview: %s", err)
+		}
+	}
+	return PreviewCheckComment(results, screenshotURL)
+}
+
+// captureScreenshot runs the screenshot command and stores the screenshot in the storage location of the previews
+// returning its URL
+func (o *PreviewOptions) captureScreenshot(previewURL string) (string, error) {
+	settings, err := o.TeamSettings()
+	if err != nil {
+		return "", err
+	}
+	storageLocation := settings.StorageLocationOrDefault(kube.ClassificationPreviews)
+	if storageLocation.IsEmpty() {
+		return "", fmt.Errorf("no storage location is configured for classifier %s", kube.ClassificationPreviews)
+	}
+	coll, err := collector.NewCollector(storageLocation, o.Git())
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to create the collector for storage settings %s", storageLocation.Description())
+	}
+
+	dir, err := ioutil.TempDir("", "jx-preview-screenshot-")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(dir)
+	fileName := filepath.Join(dir, "screenshot.png")
+	cmd := util.Command{
+		Name: "sh",
+		Args: []string{"-c", o.ScreenshotCommand},
+		Env: map[string]string{
+			ScreenshotURLEnvVar:  previewURL,
+			ScreenshotFileEnvVar: fileName,
+		},
+	}
+	_, err = cmd.RunWithoutRetry()
+	if err != nil {
+		return "", errors.Wrapf(err, "running the screenshot command %s", o.ScreenshotCommand)
+	}
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return "", errors.Wrapf(err, "the screenshot command did not write the screenshot to $%s", ScreenshotFileEnvVar)
+	}
+	build := builds.GetBuildNumber()
+	if build == "" {
+		build = time.Now().Format("20060102150405")
+	}
+	outputName := filepath.Join("previews", o.GitInfo.Organisation, o.GitInfo.Name, o.PullRequestName, build, "screenshot.png")
+	return coll.CollectData(data, outputName)
+}
@@ -0,0 +1,49 @@
+package preview_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jenkins-x/jx/pkg/cmd/preview"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckPreviewEndpoints(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/", "/health":
+			w.WriteHeader(http.StatusOK)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	results := preview.CheckPreviewEndpoints(server.Client(), server.URL, []string{"/", "/health", "/broken"})
+	require.Len(t, results, 3)
+	assert.True(t, results[0].Healthy())
+	assert.Equal(t, server.URL, results[0].URL)
+	assert.True(t, results[1].Healthy())
+	assert.Equal(t, server.URL+"/health", results[1].URL)
+	assert.False(t, results[2].Healthy())
+	assert.Equal(t, http.StatusInternalServerError, results[2].StatusCode)
+
+	comment := preview.PreviewCheckComment(results, "https://storage.example.com/screenshot.png")
+	assert.Contains(t, comment, ":x: 1 of 3 checked endpoints of the preview are unhealthy")
+	assert.Contains(t, comment, "| [/health]("+server.URL+"/health) | :white_check_mark: 200 OK |")
+	assert.Contains(t, comment, "![preview screenshot](https://storage.example.com/screenshot.png)")
+}
+
+func TestPreviewCheckCommentHealthy(t *testing.T) {
+	t.Parallel()
+	results := []*preview.PreviewCheckResult{
+		{Path: "/", URL: "http://preview.example.com", StatusCode: 200, Status: "200 OK"},
+	}
+	comment := preview.PreviewCheckComment(results, "")
+	assert.Contains(t, comment, ":white_check_mark: All 1 checked endpoints of the preview are healthy")
+	assert.NotContains(t, comment, "screenshot")
+	assert.Empty(t, preview.PreviewCheckComment(nil, ""))
+}
@@ -3,6 +3,7 @@ package promote
 import (
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
 	"strconv"
 	"strings"
@@ -17,6 +18,7 @@ import (
 	survey "gopkg.in/AlecAivazis/survey.v1"
 
 	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/audit"
 	"github.com/jenkins-x/jx/pkg/environments"
 
 	"k8s.io/helm/pkg/proto/hapi/chart"
@@ -27,13 +29,18 @@ import (
 	typev1 "github.com/jenkins-x/jx/pkg/client/clientset/versioned/typed/jenkins.io/v1"
 	"github.com/jenkins-x/jx/pkg/cmd/opts"
 	"github.com/jenkins-x/jx/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/pkg/cve"
 	"github.com/jenkins-x/jx/pkg/gits"
 	"github.com/jenkins-x/jx/pkg/helm"
 	"github.com/jenkins-x/jx/pkg/kube"
 	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/provenance"
+	"github.com/jenkins-x/jx/pkg/registry"
+	"github.com/jenkins-x/jx/pkg/tagging"
 	"github.com/jenkins-x/jx/pkg/util"
 	"github.com/spf13/cobra"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
 )
 
 const (
@@ -62,6 +69,7 @@ type PromoteOptions struct {
 	NoHelmUpdate            bool
 	AllAutomatic            bool
 	NoMergePullRequest      bool
+	AutoMerge               bool
 	NoPoll                  bool
 	NoWaitAfterMerge        bool
 	IgnoreLocalFiles        bool
@@ -70,6 +78,10 @@ type PromoteOptions struct {
 	PullRequestPollTime     string
 	Filter                  string
 	Alias                   string
+	WithDependencies        bool
+	FromEnvironment         string
+	Force                   bool
+	Reason                  string
 
 	// calculated fields
 	TimeoutDuration         *time.Duration
@@ -87,12 +99,22 @@ type ReleaseInfo struct {
 	FullAppName     string
 	Version         string
 	PullRequestInfo *gits.PullRequestInfo
+	// ImageRepository the repository of the image in the registry of the environment if it was copied there
+	ImageRepository string
 }
 
 var (
 	promote_long = templates.LongDesc(`
 		Promotes a version of an application to zero to many permanent environments.
 
+		Applications can declare the other applications they depend on in the '.jx/dependencies.yaml' file of their repository or with the 'jenkins.io/dependencies' chart annotation. With '--with-dependencies' the latest versions of the dependencies are promoted first in waves in dependency order, verifying that each wave is ready before promoting the next one. Use 'jx get app-graph' to view the dependencies.
+
+		With '--from-env' the exact versions of all the applications deployed in an environment such as staging are promoted to the target environment via a single Pull Request which lists the version changes, so that what was tested is what gets promoted. The versions are read from the requirements.yaml in the git repository of each environment at the ref of its source, which defaults to master, so any changes which have been merged but not yet deployed are included. Any applications which would be downgraded are highlighted in the Pull Request.
+
+		Environments can restrict promotions to release train windows and block them during freezes with the 'promotionWindows' of their spec. Use '--force' with a '--reason' to promote outside of the windows in an emergency. Forced promotions are recorded in the audit log.
+
+		Environments with their own container registry configured in the 'imagePromotion' of their spec have the image of the application copied from the registry of the development environment to their registry on promotion, including all of its platforms, and the image repository value of the application in the environment is pointed at their registry.
+
 		For more documentation see: [https://jenkins-x.io/about/features/#promotion](https://jenkins-x.io/about/features/#promotion)
 
 `)
@@ -112,6 +134,15 @@ var (
 		# To promote a postgres chart using an alias
 		jx promote -f postgres --alias mydb
 
+		# Promote the latest versions of the applications myapp depends on in dependency order before myapp
+		jx promote myapp --version 1.2.3 --env production --with-dependencies
+
+		# Promote the versions of all the applications deployed in staging to production
+		jx promote --from-env staging --env production
+
+		# Promote a hotfix to production during a freeze
+		jx promote myapp --version 1.2.4 --env production --force --reason "fix the checkout outage"
+
 		# To create or update a Preview Environment please see the 'jx preview' command
 		jx preview
 	`)
@@ -138,6 +169,10 @@ func NewCmdPromote(commonOpts *opts.CommonOptions) *cobra.Command {
 	cmd.Flags().StringVarP(&options.Namespace, "namespace", "n", "", "The Namespace to promote to")
 	cmd.Flags().StringVarP(&options.Environment, opts.OptionEnvironment, "e", "", "The Environment to promote to")
 	cmd.Flags().BoolVarP(&options.AllAutomatic, "all-auto", "", false, "Promote to all automatic environments in order")
+	cmd.Flags().BoolVarP(&options.WithDependencies, "with-dependencies", "", false, "Promote the latest versions of the applications the application depends on first in dependency order")
+	cmd.Flags().StringVarP(&options.FromEnvironment, "from-env", "", "", "Promote the exact versions of all the applications deployed in the given Environment via a single Pull Request")
+	cmd.Flags().BoolVarP(&options.Force, "force", "", false, "Promote even if the Environment is outside of its promotion windows or frozen. Requires --reason")
+	cmd.Flags().StringVarP(&options.Reason, "reason", "", "", "The reason for forcing the promotion which is recorded in the audit log")
 
 	options.AddPromoteOptions(cmd)
 	return cmd
@@ -158,6 +193,7 @@ func (o *PromoteOptions) AddPromoteOptions(cmd *cobra.Command) {
 	cmd.Flags().StringVarP(&o.PullRequestPollTime, optionPullRequestPollTime, "", "20s", "Poll time when waiting for a Pull Request to merge")
 	cmd.Flags().BoolVarP(&o.NoHelmUpdate, "no-helm-update", "", false, "Allows the 'helm repo update' command if you are sure your local helm cache is up to date with the version you wish to promote")
 	cmd.Flags().BoolVarP(&o.NoMergePullRequest, "no-merge", "", false, "Disables automatic merge of promote Pull Requests")
+	cmd.Flags().BoolVarP(&o.AutoMerge, "auto-merge", "", false, "Leaves merging the promote Pull Request to the git provider's native auto merge if available or the auto merge controller otherwise, once its checks pass")
 	cmd.Flags().BoolVarP(&o.NoPoll, "no-poll", "", false, "Disables polling for Pull Request or Pipeline status")
 	cmd.Flags().BoolVarP(&o.NoWaitAfterMerge, "no-wait", "", false, "Disables waiting for completing promotion after the Pull request is merged")
 	cmd.Flags().BoolVarP(&o.IgnoreLocalFiles, "ignore-local-file", "", false, "Ignores the local file system when deducing the Git repository")
@@ -248,6 +284,9 @@ func (o *PromoteOptions) EnsureApplicationNameIsDefined(sf searchForChartFn, df
 
 // Run implements this command
 func (o *PromoteOptions) Run() error {
+	if o.FromEnvironment != "" {
+		return o.PromoteFromEnvironment()
+	}
 	err := o.EnsureApplicationNameIsDefined(o.SearchForChart, o.DiscoverAppName)
 	if err != nil {
 		return err
@@ -336,6 +375,12 @@ func (o *PromoteOptions) Run() error {
 			return fmt.Errorf("Could not find an Environment called %s", o.Environment)
 		}
 	}
+	if o.WithDependencies {
+		err = o.promoteDependencies(targetNS, env)
+		if err != nil {
+			return err
+		}
+	}
 	releaseInfo, err := o.Promote(targetNS, env, true)
 	if err != nil {
 		return err
@@ -383,6 +428,12 @@ func (o *PromoteOptions) PromoteAllAutomatic() error {
 			if ns == "" {
 				return fmt.Errorf("No namespace for environment %s", env.Name)
 			}
+			if o.WithDependencies {
+				err = o.promoteDependencies(ns, &env)
+				if err != nil {
+					return err
+				}
+			}
 			releaseInfo, err := o.Promote(ns, &env, false)
 			if err != nil {
 				return err
@@ -444,6 +495,14 @@ func (o *PromoteOptions) Promote(targetNS string, env *v1.Environment, warnIfAut
 			return releaseInfo, nil
 		}
 	}
+	promotion := app
+	if version != "" {
+		promotion += " " + version
+	}
+	err := o.CheckPromotionWindow(env, promotion)
+	if err != nil {
+		return releaseInfo, err
+	}
 
 	jxClient, _, err := o.JXClient()
 	if err != nil {
@@ -453,6 +512,24 @@ func (o *PromoteOptions) Promote(targetNS string, env *v1.Environment, warnIfAut
 	if err != nil {
 		return releaseInfo, err
 	}
+	if env != nil {
+		err = o.verifyImageScan(kubeClient, env, app, version)
+		if err != nil {
+			return releaseInfo, err
+		}
+		err = o.verifyProvenance(kubeClient, env, app, version)
+		if err != nil {
+			return releaseInfo, err
+		}
+		err = o.verifyReleaseTag(kubeClient, env, app, version)
+		if err != nil {
+			return releaseInfo, err
+		}
+		releaseInfo.ImageRepository, err = o.promoteImage(kubeClient, env, app, version)
+		if err != nil {
+			return releaseInfo, err
+		}
+	}
 	promoteKey := o.CreatePromoteKey(env)
 	if env != nil {
 		source := &env.Spec.Source
@@ -512,6 +589,9 @@ func (o *PromoteOptions) Promote(targetNS string, env *v1.Environment, warnIfAut
 		NoForce:     true,
 		Wait:        true,
 	}
+	if releaseInfo.ImageRepository != "" {
+		helmOptions.SetValues = append(helmOptions.SetValues, imageRepositoryValue(env)+"="+releaseInfo.ImageRepository)
+	}
 	err = o.InstallChartWithOptions(helmOptions)
 	if err == nil {
 		err = o.CommentOnIssues(targetNS, env, promoteKey)
@@ -549,6 +629,14 @@ func (o *PromoteOptions) PromoteViaPullRequest(env *v1.Environment, releaseInfo
 			}
 		}
 		requirements.SetAppVersion(app, version, o.HelmRepositoryURL, o.Alias)
+		if releaseInfo.ImageRepository != "" {
+			key := app
+			if o.Alias != "" {
+				key = o.Alias
+			}
+			util.SetMapValueViaPath(values, key+"."+imageRepositoryValue(env), releaseInfo.ImageRepository)
+			return helm.SaveFile(filepath.Join(dir, helm.ValuesFileName), values)
+		}
 		return nil
 	}
 	gitProvider, _, err := o.CreateGitProviderForURLWithoutKind(env.Spec.Source.URL)
@@ -571,7 +659,16 @@ func (o *PromoteOptions) PromoteViaPullRequest(env *v1.Environment, releaseInfo
 	}
 	info, err := options.Create(env, environmentsDir, &details, filter, "", true)
 	releaseInfo.PullRequestInfo = info
-	return err
+	if err != nil {
+		return err
+	}
+	if o.AutoMerge && info != nil && info.PullRequest != nil {
+		_, err = gits.EnableAutoMerge(gitProvider, info.PullRequest, details.Message)
+		if err != nil {
+			return errors.Wrapf(err, "enabling auto merge of %s", info.PullRequest.URL)
+		}
+	}
+	return nil
 }
 
 func (o *PromoteOptions) GetTargetNamespace(ns string, env string) (string, *v1.Environment, error) {
@@ -663,6 +760,7 @@ func (o *PromoteOptions) waitForGitOpsPullRequest(ns string, env *v1.Environment
 	logHasMergeSha := false
 	logMergeStatusError := false
 	logNoMergeStatuses := false
+	logOpenTasks := false
 	urlStatusMap := map[string]string{}
 	urlStatusTargetURLMap := map[string]string{}
 
@@ -801,7 +899,7 @@ func (o *PromoteOptions) waitForGitOpsPullRequest(ns string, env *v1.Environment
 						log.Logger().Info("The build for the Pull Request last commit is currently in progress.")
 					} else {
 						if status == "success" {
-							if !(o.NoMergePullRequest) {
+							if !o.NoMergePullRequest && !o.AutoMerge {
 								tideMerge := false
 								// Now check if tide is running or not
 								commitStatues, err := gitProvider.ListCommitStatus(pr.Owner, pr.Repo, pr.LastCommitSha)
@@ -815,7 +913,14 @@ func (o *PromoteOptions) waitForGitOpsPullRequest(ns string, env *v1.Environment
 										}
 									}
 								}
-								if !tideMerge {
+								openTasks, err := gits.PullRequestOpenTasks(gitProvider, pr)
+								if err != nil {
+									log.Logger().Warnf("Failed to count the open tasks of the Pull Request %s: %s", pr.URL, err)
+								} else if openTasks > 0 && !logOpenTasks {
+									logOpenTasks = true
+									log.Logger().Infof("Waiting for the %d open tasks on the Pull Request %s to be resolved before merging", openTasks, util.ColorInfo(pr.URL))
+								}
+								if !tideMerge && err == nil && openTasks == 0 {
 									err = gitProvider.MergePullRequest(pr, "jx promote automatically merged promotion PR")
 									if err != nil {
 										if !logMergeFailure {
@@ -850,6 +955,206 @@ func (o *PromoteOptions) waitForGitOpsPullRequest(ns string, env *v1.Environment
 	return nil
 }
 
+// verifyImageScan returns an error if the environment is protected by the image scan policy of the team and the image
+// of the version of the application has unresolved critical vulnerabilities
+func (o *PromoteOptions) verifyImageScan(kubeClient kubernetes.Interface, env *v1.Environment, app string, version string) error {
+	settings, err := o.TeamSettings()
+	if err != nil {
+		return err
+	}
+	policy := settings.ImageScanPolicy
+	if !cve.IsProtectedEnvironment(policy, env.Name) {
+		return nil
+	}
+	if version == "" {
+		version, err = o.findLatestVersion(app)
+		if err != nil {
+			return err
+		}
+	}
+	devNs, _, err := kube.GetDevNamespace(kubeClient, o.Namespace)
+	if err != nil {
+		return err
+	}
+	summary, err := cve.GetImageScanSummary(kubeClient, devNs, app, version)
+	if err != nil {
+		return err
+	}
+	if summary == nil {
+		log.Logger().Warnf("The image of %s version %s has not been scanned for vulnerabilities", app, version)
+		return nil
+	}
+	unresolved := summary.UnresolvedCritical(policy)
+	if len(unresolved) > 0 {
+		return fmt.Errorf("cannot promote %s version %s to the protected environment %s as image %s has %d unresolved critical vulnerabilities: %s. "+
+			"Fix them or accept them in the ignoredVulnerabilities of the image scan policy of the team", app, version, env.Name, summary.Image,
+			len(unresolved), cve.VulnerabilitiesDescription(unresolved))
+	}
+	log.Logger().Infof("The image %s has no unresolved critical vulnerabilities", util.ColorInfo(summary.Image))
+	return nil
+}
+
+// verifyProvenance returns an error if the environment is protected by the provenance policy of the team and the
+// version of the application has no valid signed provenance
+func (o *PromoteOptions) verifyProvenance(kubeClient kubernetes.Interface, env *v1.Environment, app string, version string) error {
+	settings, err := o.TeamSettings()
+	if err != nil {
+		return err
+	}
+	policy := settings.ProvenancePolicy
+	if !provenance.IsProtectedEnvironment(policy, env.Name) {
+		return nil
+	}
+	if version == "" {
+		version, err = o.findLatestVersion(app)
+		if err != nil {
+			return err
+		}
+	}
+	devNs, _, err := kube.GetDevNamespace(kubeClient, o.Namespace)
+	if err != nil {
+		return err
+	}
+	statement, err := provenance.VerifyAttestation(kubeClient, devNs, app, version, policy)
+	if err != nil {
+		return errors.Wrapf(err, "cannot promote %s version %s to the protected environment %s", app, version, env.Name)
+	}
+	log.Logger().Infof("%s version %s has valid provenance built by %s", app, version, util.ColorInfo(statement.Predicate.Builder.ID))
+	return nil
+}
+
+// verifyReleaseTag returns an error if the environment is protected by the tag policy of the team and the git tag,
+// chart version and image tag of the version of the application do not all refer to the same commit
+func (o *PromoteOptions) verifyReleaseTag(kubeClient kubernetes.Interface, env *v1.Environment, app string, version string) error {
+	settings, err := o.TeamSettings()
+	if err != nil {
+		return err
+	}
+	if !tagging.IsProtectedEnvironment(settings.TagPolicy, env.Name) {
+		return nil
+	}
+	if version == "" {
+		version, err = o.findLatestVersion(app)
+		if err != nil {
+			return err
+		}
+	}
+	release := &tagging.Release{
+		App:     app,
+		Version: version,
+		Tag:     tagging.VersionTag(version),
+	}
+	err = o.Git().FetchTags("")
+	if err != nil {
+		log.Logger().Warnf("failed to fetch the git tags: %s", err)
+	}
+	release.TagCommit, err = o.Git().GetCommitPointedToByTag("", release.Tag)
+	if err != nil {
+		log.Logger().Debugf("failed to find the commit of git tag %s: %s", release.Tag, err)
+		release.TagCommit = ""
+	}
+	err = helm.InspectChart(app, version, o.HelmRepositoryURL, "", "", o.Helm(), func(dir string) error {
+		metadata, err := helm.LoadChartFile(filepath.Join(dir, helm.ChartFileName))
+		if err != nil {
+			return err
+		}
+		values, err := helm.LoadValuesFile(filepath.Join(dir, helm.ValuesFileName))
+		if err != nil {
+			return err
+		}
+		release.ChartVersion = metadata.Version
+		release.ChartAppVersion = metadata.AppVersion
+		release.ImageTag = tagging.ImageTag(values)
+		return nil
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to inspect the chart of %s version %s", app, version)
+	}
+
+	image := &registry.ImageReference{
+		Host:       o.GetDockerRegistry(nil),
+		Repository: path.Join(o.GetDockerRegistryOrg(nil, o.GitInfo), app),
+		Reference:  release.ImageTag,
+	}
+	release.Image = image.String()
+	devNs, _, err := kube.GetDevNamespace(kubeClient, o.Namespace)
+	if err != nil {
+		return err
+	}
+	region, _ := kube.ReadRegion(kubeClient, devNs)
+	client := &registry.CopyOptions{
+		Credentials: registry.DefaultCredentials(region),
+	}
+	labels, err := client.Labels(image)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get the labels of image %s", release.Image)
+	}
+	release.ImageRevision = labels[registry.RevisionLabel]
+
+	err = release.Verify()
+	if err != nil {
+		return errors.Wrapf(err, "cannot promote to the protected environment %s", env.Name)
+	}
+	log.Logger().Infof("The git tag, chart and image of %s version %s refer to commit %s", app, version, util.ColorInfo(release.TagCommit))
+	return nil
+}
+
+// promoteImage copies the image of the version of the application from the registry of the development environment
+// to the registry of the environment if it has its own registry. Returns the repository of the image in the registry
+// of the environment or blank if the image was not copied
+func (o *PromoteOptions) promoteImage(kubeClient kubernetes.Interface, env *v1.Environment, app string, version string) (string, error) {
+	settings := env.Spec.ImagePromotion
+	if settings == nil || settings.Registry == "" {
+		return "", nil
+	}
+	var err error
+	if version == "" {
+		version, err = o.findLatestVersion(app)
+		if err != nil {
+			return "", err
+		}
+	}
+	devRegistry := o.GetDockerRegistry(nil)
+	if devRegistry == "" {
+		return "", fmt.Errorf("no container registry is configured for the development environment so cannot copy the image of %s to %s", app, settings.Registry)
+	}
+	org := o.GetDockerRegistryOrg(nil, o.GitInfo)
+	targetOrg := settings.Organisation
+	if targetOrg == "" {
+		targetOrg = org
+	}
+	src := &registry.ImageReference{Host: devRegistry, Repository: path.Join(org, app), Reference: version}
+	dst := &registry.ImageReference{Host: settings.Registry, Repository: path.Join(strings.ToLower(targetOrg), app), Reference: version}
+
+	devNs, _, err := kube.GetDevNamespace(kubeClient, o.Namespace)
+	if err != nil {
+		return "", err
+	}
+	region, _ := kube.ReadRegion(kubeClient, devNs)
+	err = registry.NewRegistry(settings.Registry, region).EnsureRepository(dst.String())
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to create the repository of image %s", dst.String())
+	}
+	copier := &registry.CopyOptions{
+		Credentials: registry.DefaultCredentials(region),
+	}
+	digest, err := copier.Copy(src, dst)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to copy the image of %s version %s to the registry of environment %s", app, version, env.Name)
+	}
+	log.Logger().Infof("Copied image %s to %s with digest %s", util.ColorInfo(src.String()), util.ColorInfo(dst.String()), digest)
+	return dst.Host + "/" + dst.Repository, nil
+}
+
+// imageRepositoryValue returns the path of the helm value of the image repository of the applications in the
+// environment
+func imageRepositoryValue(env *v1.Environment) string {
+	if env.Spec.ImagePromotion != nil && env.Spec.ImagePromotion.RepositoryValue != "" {
+		return env.Spec.ImagePromotion.RepositoryValue
+	}
+	return "image.repository"
+}
+
 func (o *PromoteOptions) findLatestVersion(app string) (string, error) {
 	charts, err := o.Helm().SearchCharts(app, true)
 	if err != nil {
@@ -1171,3 +1476,38 @@ func (o *PromoteOptions) SearchForChart(filter string) (string, error) {
 	o.HelmRepositoryURL = repoUrl
 	return appName, nil
 }
+
+// CheckPromotionWindow returns an error if the promotion windows of the environment do not allow promotions now unless
+// the promotion is forced in which case the override is recorded in the audit log
+func (o *PromoteOptions) CheckPromotionWindow(env *v1.Environment, promotion string) error {
+	if env == nil {
+		return nil
+	}
+	reason, err := environments.CheckPromotionWindow(env.Spec.PromotionWindows, time.Now())
+	if err != nil {
+		return errors.Wrapf(err, "checking the promotion windows of environment %s", env.Name)
+	}
+	if reason == "" {
+		return nil
+	}
+	if !o.Force {
+		return fmt.Errorf("cannot promote to environment %s as %s. Use --force --reason to override", env.Name, reason)
+	}
+	if o.Reason == "" {
+		return util.MissingOption("reason")
+	}
+	log.Logger().Warnf("Forcing the promotion to environment %s although %s: %s", env.Name, reason, o.Reason)
+	details := map[string]string{
+		"environment": env.Name,
+		"window":      reason,
+		"reason":      o.Reason,
+	}
+	if o.Application != "" {
+		details["application"] = o.Application
+	}
+	if o.Version != "" {
+		details["version"] = o.Version
+	}
+	audit.Record(v1.AuditActionPromotionWindowOverridden, env.Name, env.Namespace, fmt.Sprintf("forced the promotion of %s outside of the promotion windows: %s", promotion, o.Reason), details)
+	return nil
+}
@@ -0,0 +1,127 @@
+package promote
+
+import (
+	"os"
+	"strings"
+	"time"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/appgraph"
+	"github.com/jenkins-x/jx/pkg/kube"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// defaultWaveVerifyTimeout the time to wait for the applications of a wave to become ready if no timeout is specified
+const defaultWaveVerifyTimeout = 10 * time.Minute
+
+// promoteDependencies promotes the latest versions of the applications the application depends on in waves in the
+// topological order of the dependency graph. The applications of each wave are verified to be ready in the target
+// namespace before the next wave is promoted
+func (o *PromoteOptions) promoteDependencies(targetNS string, env *v1.Environment) error {
+	graph, err := o.AppGraph()
+	if err != nil {
+		return errors.Wrap(err, "building the application dependency graph")
+	}
+	// lets include the dependencies declared in the current directory which may not have been pushed yet
+	if !o.IgnoreLocalFiles {
+		dir, err := os.Getwd()
+		if err != nil {
+			return err
+		}
+		deps, err := appgraph.LoadDependencies(dir)
+		if err != nil {
+			return err
+		}
+		graph.Add(o.Application, deps...)
+	}
+	deps := graph.TransitiveDependencies(o.Application)
+	if len(deps) == 0 {
+		log.Logger().Infof("Application %s has no dependencies to promote", util.ColorInfo(o.Application))
+		return nil
+	}
+	waves, err := graph.Waves(deps)
+	if err != nil {
+		return errors.Wrapf(err, "ordering the dependencies of %s", o.Application)
+	}
+	if o.NoPoll {
+		log.Logger().Warnf("The dependencies of %s cannot be verified between waves as polling is disabled", o.Application)
+	}
+	kubeClient, err := o.KubeClient()
+	if err != nil {
+		return err
+	}
+	for i, wave := range waves {
+		log.Logger().Infof("Promoting wave %d of %d of the dependencies of %s: %s", i+1, len(waves), util.ColorInfo(o.Application), util.ColorInfo(strings.Join(wave, ", ")))
+		for _, app := range wave {
+			depOptions := o.dependencyPromoteOptions(app)
+			releaseInfo, err := depOptions.Promote(targetNS, env, false)
+			if err != nil {
+				return errors.Wrapf(err, "promoting dependency %s of %s", app, o.Application)
+			}
+			if !o.NoPoll {
+				err = depOptions.WaitForPromotion(targetNS, env, releaseInfo)
+				if err != nil {
+					return errors.Wrapf(err, "waiting for the promotion of dependency %s of %s", app, o.Application)
+				}
+			}
+		}
+		if !o.NoPoll {
+			err = o.verifyWave(kubeClient, targetNS, wave)
+			if err != nil {
+				return errors.Wrapf(err, "verifying wave %d of the dependencies of %s", i+1, o.Application)
+			}
+		}
+	}
+	return nil
+}
+
+// dependencyPromoteOptions returns the options to promote the latest version of a dependency
+func (o *PromoteOptions) dependencyPromoteOptions(app string) *PromoteOptions {
+	depOptions := *o
+	depOptions.Application = app
+	depOptions.Version = ""
+	depOptions.Alias = ""
+	depOptions.ReleaseName = ""
+	depOptions.Pipeline = ""
+	depOptions.Build = ""
+	depOptions.IgnoreLocalFiles = true
+	depOptions.WithDependencies = false
+	depOptions.GitInfo = nil
+	depOptions.ReleaseInfo = nil
+	depOptions.releaseResource = nil
+	return &depOptions
+}
+
+// verifyWave waits for the deployments of the applications of a wave to be ready in the target namespace
+func (o *PromoteOptions) verifyWave(kubeClient kubernetes.Interface, targetNS string, wave []string) error {
+	timeout := defaultWaveVerifyTimeout
+	if o.TimeoutDuration != nil {
+		timeout = *o.TimeoutDuration
+	}
+	deployments, err := kubeClient.AppsV1beta1().Deployments(targetNS).List(metav1.ListOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "listing the deployments in namespace %s", targetNS)
+	}
+	for _, app := range wave {
+		found := false
+		for _, d := range deployments.Items {
+			if kube.GetAppName(d.Name, targetNS) != app {
+				continue
+			}
+			found = true
+			err = kube.WaitForDeploymentToBeReady(kubeClient, d.Name, targetNS, timeout)
+			if err != nil {
+				return errors.Wrapf(err, "waiting for deployment %s of %s to be ready", d.Name, app)
+			}
+		}
+		if !found {
+			return errors.Errorf("no deployment of %s found in namespace %s", app, targetNS)
+		}
+		log.Logger().Infof("Dependency %s is ready in namespace %s", util.ColorInfo(app), util.ColorInfo(targetNS))
+	}
+	return nil
+}
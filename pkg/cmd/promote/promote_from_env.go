@@ -0,0 +1,232 @@
+package promote
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/pkg/dependencyupdates"
+	"github.com/jenkins-x/jx/pkg/environments"
+	"github.com/jenkins-x/jx/pkg/gits"
+	"github.com/jenkins-x/jx/pkg/helm"
+	"github.com/jenkins-x/jx/pkg/kube"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+	"k8s.io/helm/pkg/proto/hapi/chart"
+)
+
+// VersionChange the change of the version of an application when promoting from one environment to another
+type VersionChange struct {
+	App         string
+	Alias       string
+	Repository  string
+	FromVersion string
+	ToVersion   string
+	// Downgrade is true if the target environment is running a newer version than the one being promoted
+	Downgrade bool
+}
+
+// Name returns the name the application is deployed as which is its alias if it has one
+func (c *VersionChange) Name() string {
+	if c.Alias != "" {
+		return c.Alias
+	}
+	return c.App
+}
+
+// DiffRequirements returns the changes sorted by application name required to deploy the versions of the source
+// requirements in the target requirements. Applications are matched by their alias, if they have one, so that aliased
+// copies of the same chart are promoted separately. Applications which are only in the target requirements are left
+// unchanged
+func DiffRequirements(source *helm.Requirements, target *helm.Requirements) []*VersionChange {
+	targetVersions := map[string]string{}
+	if target != nil {
+		for _, dep := range target.Dependencies {
+			if dep != nil {
+				targetVersions[dependencyKey(dep)] = dep.Version
+			}
+		}
+	}
+	var answer []*VersionChange
+	if source == nil {
+		return answer
+	}
+	for _, dep := range source.Dependencies {
+		if dep == nil || dep.Version == "" {
+			continue
+		}
+		from := targetVersions[dependencyKey(dep)]
+		if from == dep.Version {
+			continue
+		}
+		answer = append(answer, &VersionChange{
+			App:         dep.Name,
+			Alias:       dep.Alias,
+			Repository:  dep.Repository,
+			FromVersion: from,
+			ToVersion:   dep.Version,
+			Downgrade:   from != "" && dependencyupdates.IsNewerVersion(from, dep.Version),
+		})
+	}
+	sort.Slice(answer, func(i, j int) bool {
+		return answer[i].Name() < answer[j].Name()
+	})
+	return answer
+}
+
+func dependencyKey(dep *helm.Dependency) string {
+	if dep.Alias != "" {
+		return dep.Alias
+	}
+	return dep.Name
+}
+
+// FormatVersionChanges returns a markdown table of the version changes for the body of a promotion Pull Request
+func FormatVersionChanges(sourceEnv string, targetEnv string, changes []*VersionChange) string {
+	var buffer strings.Builder
+	buffer.WriteString(fmt.Sprintf("Promotes the versions of the applications deployed in **%s** to **%s**\n\n", sourceEnv, targetEnv))
+	downgrades := 0
+	for _, c := range changes {
+		if c.Downgrade {
+			downgrades++
+		}
+	}
+	if downgrades > 0 {
+		buffer.WriteString(fmt.Sprintf(":warning: **%d applications are downgraded** as %s is running newer versions than %s\n\n", downgrades, targetEnv, sourceEnv))
+	}
+	buffer.WriteString(fmt.Sprintf("| Application | %s | %s |\n| --- | --- | --- |\n", targetEnv, sourceEnv))
+	for _, c := range changes {
+		name := c.App
+		if c.Alias != "" && c.Alias != c.App {
+			name = fmt.Sprintf("%s (%s)", c.Alias, c.App)
+		}
+		from := c.FromVersion
+		if from == "" {
+			from = "_not deployed_"
+		}
+		to := c.ToVersion
+		if c.Downgrade {
+			to += " :warning: downgrade"
+		}
+		buffer.WriteString(fmt.Sprintf("| %s | %s | %s |\n", name, from, to))
+	}
+	return buffer.String()
+}
+
+// PromoteFromEnvironment promotes the exact versions of the applications deployed in the source environment to the
+// target environment via a single Pull Request on the git repository of the target environment
+func (o *PromoteOptions) PromoteFromEnvironment() error {
+	jxClient, ns, err := o.JXClientAndDevNamespace()
+	if err != nil {
+		return err
+	}
+	if o.Environment == "" {
+		if o.BatchMode {
+			return util.MissingOption(opts.OptionEnvironment)
+		}
+		envMap, envNames, err := kube.GetOrderedEnvironments(jxClient, ns)
+		if err != nil {
+			return err
+		}
+		names := []string{}
+		for _, n := range envNames {
+			if n != o.FromEnvironment && envMap[n].Spec.Kind == v1.EnvironmentKindTypePermanent {
+				names = append(names, n)
+			}
+		}
+		o.Environment, err = kube.PickEnvironment(names, "", o.GetIOFileHandles())
+		if err != nil {
+			return err
+		}
+	}
+	if o.Environment == o.FromEnvironment {
+		return util.InvalidOptionf("from-env", o.FromEnvironment, "should be different to the target environment")
+	}
+	sourceEnv, err := kube.GetEnvironment(jxClient, ns, o.FromEnvironment)
+	if err != nil {
+		return errors.Wrapf(err, "finding the source environment %s", o.FromEnvironment)
+	}
+	targetEnv, err := kube.GetEnvironment(jxClient, ns, o.Environment)
+	if err != nil {
+		return errors.Wrapf(err, "finding the target environment %s", o.Environment)
+	}
+	err = o.CheckPromotionWindow(targetEnv, "the versions of "+sourceEnv.Name)
+	if err != nil {
+		return err
+	}
+	for _, env := range []*v1.Environment{sourceEnv, targetEnv} {
+		if env.Spec.Source.URL == "" {
+			return fmt.Errorf("environment %s has no git repository so its versions cannot be promoted via a Pull Request", env.Name)
+		}
+	}
+
+	sourceRequirements, err := environments.LoadEnvironmentRequirements(o.Git(), sourceEnv)
+	if err != nil {
+		return err
+	}
+	targetRequirements, err := environments.LoadEnvironmentRequirements(o.Git(), targetEnv)
+	if err != nil {
+		return err
+	}
+	changes := DiffRequirements(sourceRequirements, targetRequirements)
+	if len(changes) == 0 {
+		log.Logger().Infof("Environment %s already has the versions deployed in %s", util.ColorInfo(targetEnv.Name), util.ColorInfo(sourceEnv.Name))
+		return nil
+	}
+	log.Logger().Infof("Promoting from %s to %s:", util.ColorInfo(sourceEnv.Name), util.ColorInfo(targetEnv.Name))
+	for _, c := range changes {
+		from := c.FromVersion
+		if from == "" {
+			from = "none"
+		}
+		if c.Downgrade {
+			log.Logger().Warnf("  %s: %s => %s is a downgrade", c.Name(), from, c.ToVersion)
+			continue
+		}
+		log.Logger().Infof("  %s: %s => %s", c.Name(), from, util.ColorInfo(c.ToVersion))
+	}
+
+	details := gits.PullRequestDetails{
+		BranchName: "promote-" + sourceEnv.Name + "-to-" + targetEnv.Name,
+		Title:      fmt.Sprintf("chore: promote the versions of %s to %s", sourceEnv.Name, targetEnv.Name),
+		Message:    FormatVersionChanges(sourceEnv.Name, targetEnv.Name, changes),
+	}
+	modifyChartFn := func(requirements *helm.Requirements, metadata *chart.Metadata, values map[string]interface{},
+		templates map[string]string, dir string, details *gits.PullRequestDetails) error {
+		for _, c := range changes {
+			requirements.SetAppVersion(c.App, c.ToVersion, c.Repository, c.Alias)
+		}
+		return nil
+	}
+	gitProvider, _, err := o.CreateGitProviderForURLWithoutKind(targetEnv.Spec.Source.URL)
+	if err != nil {
+		return errors.Wrapf(err, "creating git provider for %s", targetEnv.Spec.Source.URL)
+	}
+	environmentsDir, err := o.EnvironmentsDir()
+	if err != nil {
+		return errors.Wrapf(err, "getting environments dir")
+	}
+	options := environments.EnvironmentPullRequestOptions{
+		Gitter:        o.Git(),
+		ModifyChartFn: modifyChartFn,
+		GitProvider:   gitProvider,
+	}
+	info, err := options.Create(targetEnv, environmentsDir, &details, &gits.PullRequestFilter{}, "", false)
+	if err != nil {
+		return errors.Wrapf(err, "creating the Pull Request to promote %s to %s", sourceEnv.Name, targetEnv.Name)
+	}
+	if info == nil || info.PullRequest == nil {
+		return nil
+	}
+	log.Logger().Infof("Created Pull Request %s to promote %d applications", util.ColorInfo(info.PullRequest.URL), len(changes))
+	if o.AutoMerge {
+		_, err = gits.EnableAutoMerge(gitProvider, info.PullRequest, details.Title)
+		if err != nil {
+			return errors.Wrapf(err, "enabling auto merge of %s", info.PullRequest.URL)
+		}
+	}
+	return nil
+}
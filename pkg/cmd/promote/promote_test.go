@@ -17,6 +17,7 @@ import (
 	"github.com/jenkins-x/jx/pkg/cmd/controller"
 	"github.com/jenkins-x/jx/pkg/cmd/promote"
 	"github.com/jenkins-x/jx/pkg/cmd/testhelpers"
+	"github.com/jenkins-x/jx/pkg/cve"
 
 	"github.com/petergtz/pegomock"
 
@@ -91,6 +92,63 @@ func TestPromoteToProductionRun(t *testing.T) {
 
 }
 
+func TestPromoteToProtectedEnvironmentWithCriticalVulnerabilities(t *testing.T) {
+	testEnv, err := prepareInitialPromotionEnv(t, true)
+	assert.NoError(t, err)
+
+	promoteOptions := &promote.PromoteOptions{
+		Environment:         "production",
+		Application:         "my-app",
+		Pipeline:            testEnv.Activity.Spec.Pipeline,
+		Build:               testEnv.Activity.Spec.Build,
+		Version:             "1.2.0",
+		NoHelmUpdate:        true,
+		NoPoll:              true,
+		IgnoreLocalFiles:    true,
+		Timeout:             "1h",
+		PullRequestPollTime: "20s",
+		Namespace:           "jx",
+	}
+	commonOpts := *testEnv.CommonOptions
+	promoteOptions.CommonOptions = &commonOpts
+	promoteOptions.BatchMode = true
+
+	policy := &v1.ImageScanPolicy{
+		ProtectedEnvironments: []string{"production"},
+	}
+	err = promoteOptions.ModifyDevEnvironment(func(env *v1.Environment) error {
+		env.Spec.TeamSettings.ImageScanPolicy = policy
+		return nil
+	})
+	assert.NoError(t, err)
+	kubeClient, err := promoteOptions.KubeClient()
+	assert.NoError(t, err)
+	err = cve.SaveImageScanSummary(kubeClient, "jx", &cve.ImageScanSummary{
+		Image:    "gcr.io/myorg/my-app:1.2.0",
+		Scanner:  cve.ScannerTrivy,
+		Critical: []cve.ImageVulnerability{{ID: "CVE-2019-5482", Package: "curl", Severity: cve.SeverityCritical}},
+	})
+	assert.NoError(t, err)
+
+	err = promoteOptions.Run()
+	if assert.Error(t, err, "the promotion to a protected environment should be blocked") {
+		assert.Contains(t, err.Error(), "CVE-2019-5482 (curl)")
+	}
+	jxClient, ns, err := promoteOptions.JXClientAndDevNamespace()
+	assert.NoError(t, err)
+	testhelpers.AssertHasNoPullRequestForEnv(t, jxClient.JenkinsV1().PipelineActivities(ns), testEnv.Activity.Name, "production")
+
+	policy.IgnoredVulnerabilities = []string{"CVE-2019-5482"}
+	err = promoteOptions.ModifyDevEnvironment(func(env *v1.Environment) error {
+		env.Spec.TeamSettings.ImageScanPolicy = policy
+		return nil
+	})
+	assert.NoError(t, err)
+	err = promoteOptions.Run()
+	assert.NoError(t, err, "the promotion should not be blocked by accepted vulnerabilities")
+	testhelpers.AssertHasPullRequestForEnv(t, jxClient.JenkinsV1().PipelineActivities(ns), testEnv.Activity.Name, "production")
+}
+
 func TestPromoteToProductionNoMergeRun(t *testing.T) {
 
 	// prepare the initial setup for testing
@@ -520,3 +578,62 @@ func pollGitStatusAndReactToPipelineChanges(t *testing.T, o *controller.Controll
 	assert.NoError(t, err, "Failed to react to PipelineActivity changes")
 	return err
 }
+
+func TestDiffRequirements(t *testing.T) {
+	t.Parallel()
+	source := &helm.Requirements{
+		Dependencies: []*helm.Dependency{
+			{Name: "exposecontroller", Version: "2.3.89", Repository: "https://chartmuseum.example.com"},
+			{Name: "orders", Version: "1.2.0", Repository: "http://jenkins-x-chartmuseum:8080"},
+			{Name: "billing", Version: "0.5.1", Repository: "http://jenkins-x-chartmuseum:8080", Alias: "payments"},
+		},
+	}
+	target := &helm.Requirements{
+		Dependencies: []*helm.Dependency{
+			{Name: "exposecontroller", Version: "2.3.89", Repository: "https://chartmuseum.example.com"},
+			{Name: "orders", Version: "1.1.0", Repository: "http://jenkins-x-chartmuseum:8080"},
+			{Name: "legacy", Version: "3.0.0", Repository: "http://jenkins-x-chartmuseum:8080"},
+		},
+	}
+
+	changes := promote.DiffRequirements(source, target)
+	assert.Equal(t, []*promote.VersionChange{
+		{App: "orders", Repository: "http://jenkins-x-chartmuseum:8080", FromVersion: "1.1.0", ToVersion: "1.2.0"},
+		{App: "billing", Alias: "payments", Repository: "http://jenkins-x-chartmuseum:8080", ToVersion: "0.5.1"},
+	}, changes)
+
+	body := promote.FormatVersionChanges("staging", "production", changes)
+	assert.Contains(t, body, "| payments (billing) | _not deployed_ | 0.5.1 |")
+	assert.Contains(t, body, "| orders | 1.1.0 | 1.2.0 |")
+	assert.NotContains(t, body, "downgrade")
+
+	assert.Empty(t, promote.DiffRequirements(source, source))
+}
+
+func TestDiffRequirementsAliasesAndDowngrades(t *testing.T) {
+	t.Parallel()
+	source := &helm.Requirements{
+		Dependencies: []*helm.Dependency{
+			{Name: "postgresql", Version: "6.0.0", Alias: "orders-db"},
+			{Name: "postgresql", Version: "6.1.0", Alias: "billing-db"},
+			{Name: "orders", Version: "1.2.0"},
+		},
+	}
+	target := &helm.Requirements{
+		Dependencies: []*helm.Dependency{
+			{Name: "postgresql", Version: "6.0.0", Alias: "orders-db"},
+			{Name: "postgresql", Version: "5.0.0", Alias: "billing-db"},
+			{Name: "orders", Version: "1.3.0"},
+		},
+	}
+
+	changes := promote.DiffRequirements(source, target)
+	assert.Equal(t, []*promote.VersionChange{
+		{App: "postgresql", Alias: "billing-db", FromVersion: "5.0.0", ToVersion: "6.1.0"},
+		{App: "orders", FromVersion: "1.3.0", ToVersion: "1.2.0", Downgrade: true},
+	}, changes)
+
+	body := promote.FormatVersionChanges("staging", "production", changes)
+	assert.Contains(t, body, "**1 applications are downgraded**")
+	assert.Contains(t, body, "| orders | 1.3.0 | 1.2.0 :warning: downgrade |")
+}
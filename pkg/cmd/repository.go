@@ -19,14 +19,14 @@ type RepoOptions struct {
 
 	Dir         string
 	OnlyViewURL bool
-	Quiet       bool
 }
 
 var (
 	repoLong = templates.LongDesc(`
 		Opens the web page for the current Git repository in a browser
 
-		You can use the '--url' argument to just display the URL without opening it`)
+		You can use the '--url' argument to just display the URL without opening it or the '--quiet' argument to only
+		display the URL for use in scripts`)
 
 	repoExample = templates.Examples(`
 		# Open the Git repository in a browser
@@ -58,7 +58,6 @@ func NewCmdRepo(commonOpts *opts.CommonOptions) *cobra.Command {
 		},
 	}
 	cmd.Flags().BoolVarP(&options.OnlyViewURL, "url", "u", false, "Only displays and the URL and does not open the browser")
-	cmd.Flags().BoolVarP(&options.Quiet, "quiet", "q", false, "Quiet mode just displays the git URL only for use in scripts")
 	return cmd
 }
 
@@ -4,10 +4,13 @@ import (
 	"fmt"
 	"net/url"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/jenkins-x/jx/pkg/tekton"
 	"github.com/jenkins-x/jx/pkg/tekton/metapipeline"
+	"github.com/jenkins-x/jx/pkg/tekton/syntax"
 	"github.com/pkg/errors"
 
 	"github.com/jenkins-x/jx/pkg/cmd/helper"
@@ -25,7 +28,9 @@ import (
 	"github.com/jenkins-x/jx/pkg/log"
 	"github.com/jenkins-x/jx/pkg/util"
 	build "github.com/knative/build/pkg/apis/build/v1alpha1"
+	pipelineapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
 	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	prowjobv1 "k8s.io/test-infra/prow/apis/prowjobs/v1"
 )
 
@@ -45,6 +50,9 @@ type StartPipelineOptions struct {
 	Filter          string
 	Branch          string
 	PipelineKind    string
+	RerunFailed     bool
+	Build           int
+	Retries         int
 	JenkinsSelector opts.JenkinsSelectorOptions
 
 	Jobs map[string]gojenkins.Job
@@ -55,12 +63,19 @@ type StartPipelineOptions struct {
 	Context      string
 	CustomLabels []string
 	CustomEnvs   []string
+	CustomParams []string
 }
 
 var (
 	startPipelineLong = templates.LongDesc(`
 		Starts the pipeline build.
 
+		Parameters specified with --param are passed to Tekton pipelines as params which are also available to the steps
+		as upper case environment variables, to Jenkins jobs as build parameters and to Prow builds as environment variables.
+
+		For Tekton pipelines a failed or stopped build can be restarted from its failed stages with --rerun-failed.
+		The stages which succeeded are skipped unless a rerun stage uses their workspace.
+
 `)
 
 	startPipelineExample = templates.Examples(`
@@ -72,6 +87,15 @@ var (
 
 		# Select the pipeline to start and tail the log
 		jx start pipeline -t
+
+		# Start a pipeline passing parameters to it
+		jx start pipeline foo/bar/master --param target=staging --param dryRun=true
+
+		# Rerun the failed stages of the latest failed build of a pipeline
+		jx start pipeline foo/bar/master --rerun-failed
+
+		# Rerun the failed stages of a build retrying each stage once more if it fails again
+		jx start pipeline foo/bar/master --rerun-failed --build 3 --retries 1
 	`)
 )
 
@@ -102,6 +126,10 @@ func NewCmdStartPipeline(commonOpts *opts.CommonOptions) *cobra.Command {
 	cmd.Flags().StringVar(&options.ServiceAccount, "service-account", "tekton-bot", "The Kubernetes ServiceAccount to use to run the meta pipeline")
 	cmd.Flags().StringArrayVarP(&options.CustomLabels, "label", "l", nil, "List of custom labels to be applied to the generated PipelineRun (can be use multiple times)")
 	cmd.Flags().StringArrayVarP(&options.CustomEnvs, "env", "e", nil, "List of custom environment variables to be applied to the generated PipelineRun that are created (can be use multiple times)")
+	cmd.Flags().StringArrayVarP(&options.CustomParams, "param", "", nil, "List of custom 'name=value' parameters passed to the pipeline (can be use multiple times)")
+	cmd.Flags().BoolVarP(&options.RerunFailed, "rerun-failed", "", false, "Restarts a failed Tekton pipeline from its failed stages")
+	cmd.Flags().IntVarP(&options.Build, "build", "", 0, "The build number of the failed pipeline to rerun. Defaults to the latest failed build")
+	cmd.Flags().IntVarP(&options.Retries, "retries", "", 0, "The number of times Tekton retries each rerun stage which fails")
 
 	options.JenkinsSelector.AddFlags(cmd)
 
@@ -110,6 +138,13 @@ func NewCmdStartPipeline(commonOpts *opts.CommonOptions) *cobra.Command {
 
 // Run implements this command
 func (o *StartPipelineOptions) Run() error {
+	if o.RerunFailed {
+		return o.rerunFailedPipelines()
+	}
+	params, err := syntax.ParsePipelineParams(o.CustomParams)
+	if err != nil {
+		return util.InvalidOptionError("param", strings.Join(o.CustomParams, " "), err)
+	}
 	kubeClient, currentNamespace, err := o.KubeClientAndNamespace()
 	if err != nil {
 		return err
@@ -174,17 +209,17 @@ func (o *StartPipelineOptions) Run() error {
 	}
 	for _, a := range args {
 		if devEnv.Spec.IsLighthouse() {
-			err = o.createMetaPipeline(a)
+			err = o.createMetaPipeline(a, params)
 			if err != nil {
 				return err
 			}
 		} else if isProw {
-			err = o.createProwJob(a)
+			err = o.createProwJob(a, params)
 			if err != nil {
 				return err
 			}
 		} else {
-			err = o.startJenkinsJob(a)
+			err = o.startJenkinsJob(a, params)
 			if err != nil {
 				return err
 			}
@@ -193,7 +228,86 @@ func (o *StartPipelineOptions) Run() error {
 	return nil
 }
 
-func (o *StartPipelineOptions) createMetaPipeline(jobName string) error {
+func (o *StartPipelineOptions) rerunFailedPipelines() error {
+	tektonClient, ns, err := o.TektonClient()
+	if err != nil {
+		return errors.Wrap(err, "could not create tekton client")
+	}
+	jxClient, _, err := o.JXClient()
+	if err != nil {
+		return errors.Wrap(err, "could not create jx client")
+	}
+	prList, err := tektonClient.TektonV1alpha1().PipelineRuns(ns).List(metav1.ListOptions{
+		LabelSelector: tekton.LabelType + "=" + tekton.BuildPipeline.String(),
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to list PipelineRuns in namespace %s", ns)
+	}
+
+	// the latest failed PipelineRun of each pipeline
+	m := map[string]*pipelineapi.PipelineRun{}
+	for i := range prList.Items {
+		pr := &prList.Items[i]
+		labels := pr.Labels
+		if !tekton.PipelineRunIsComplete(pr) || tekton.PipelineRunSucceeded(pr) || labels[tekton.LabelOwner] == "" || labels[tekton.LabelRepo] == "" {
+			continue
+		}
+		if o.Context != "" && labels[tekton.LabelContext] != o.Context {
+			continue
+		}
+		buildNumber, err := strconv.Atoi(labels[tekton.LabelBuild])
+		if err != nil || (o.Build > 0 && buildNumber != o.Build) {
+			continue
+		}
+		name := fmt.Sprintf("%s/%s/%s", labels[tekton.LabelOwner], labels[tekton.LabelRepo], labels[tekton.LabelBranch])
+		previous := m[name]
+		if previous != nil {
+			previousBuild, _ := strconv.Atoi(previous.Labels[tekton.LabelBuild])
+			if previousBuild > buildNumber || (previousBuild == buildNumber && previous.CreationTimestamp.After(pr.CreationTimestamp.Time)) {
+				continue
+			}
+		}
+		m[name] = pr
+	}
+	names := []string{}
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	names = util.StringsContaining(names, o.Filter)
+	if len(names) == 0 {
+		return errors.New("no failed Tekton pipelines found to rerun")
+	}
+
+	args := o.Args
+	if len(args) == 0 {
+		name, err := util.PickName(names, "Which failed pipeline do you want to rerun: ", "", o.GetIOFileHandles())
+		if err != nil {
+			return err
+		}
+		args = []string{name}
+	}
+	for _, a := range args {
+		pr := m[a]
+		if pr == nil {
+			return fmt.Errorf("no failed PipelineRun found for pipeline %s", a)
+		}
+		run, skipped, err := tekton.RerunPipelineRun(jxClient, tektonClient, ns, pr, o.Retries)
+		if err != nil {
+			return errors.Wrapf(err, "failed to rerun pipeline %s", a)
+		}
+		if len(skipped) > 0 {
+			log.Logger().Infof("Rerunning build %s of %s as PipelineRun %s skipping the succeeded stages %s", util.ColorInfo(pr.Labels[tekton.LabelBuild]),
+				util.ColorInfo(a), util.ColorInfo(run.Name), util.ColorInfo(strings.Join(skipped, ", ")))
+		} else {
+			log.Logger().Infof("Rerunning all the stages of build %s of %s as PipelineRun %s", util.ColorInfo(pr.Labels[tekton.LabelBuild]),
+				util.ColorInfo(a), util.ColorInfo(run.Name))
+		}
+	}
+	return nil
+}
+
+func (o *StartPipelineOptions) createMetaPipeline(jobName string, params map[string]string) error {
 	parts := strings.Split(jobName, "/")
 	if len(parts) != 3 {
 		return fmt.Errorf("job name [%s] does not match org/repo/branch format", jobName)
@@ -246,6 +360,7 @@ func (o *StartPipelineOptions) createMetaPipeline(jobName string) error {
 		PipelineKind:   pipelineKind,
 		Context:        o.Context,
 		EnvVariables:   envVarMap,
+		Parameters:     params,
 		Labels:         labelMap,
 		ServiceAccount: o.ServiceAccount,
 	}
@@ -268,7 +383,7 @@ func (o *StartPipelineOptions) createMetaPipeline(jobName string) error {
 	return nil
 }
 
-func (o *StartPipelineOptions) createProwJob(jobname string) error {
+func (o *StartPipelineOptions) createProwJob(jobname string, params map[string]string) error {
 	settings, err := o.TeamSettings()
 	if err != nil {
 		return err
@@ -315,6 +430,9 @@ func (o *StartPipelineOptions) createProwJob(jobname string) error {
 		env[jmbrSourceURL] = jobSpec.BuildSpec.Source.Git.Url
 		env[repoOwnerEnv] = org
 		env[repoNameEnv] = repo
+		for k, v := range params {
+			env[strings.ToUpper(k)] = v
+		}
 
 		for i, step := range jobSpec.BuildSpec.Steps {
 			if len(step.Env) == 0 {
@@ -394,7 +512,7 @@ func (o *StartPipelineOptions) createProwJob(jobname string) error {
 	return err
 }
 
-func (o *StartPipelineOptions) startJenkinsJob(name string) error {
+func (o *StartPipelineOptions) startJenkinsJob(name string, buildParams map[string]string) error {
 	job := o.Jobs[name]
 
 	jenkinsClient, err := o.CreateCustomJenkinsClient(&o.JenkinsSelector)
@@ -407,6 +525,9 @@ func (o *StartPipelineOptions) startJenkinsJob(name string) error {
 	previous, _ := jenkinsClient.GetLastBuild(job)
 
 	params := url.Values{}
+	for k, v := range buildParams {
+		params.Set(k, v)
+	}
 	err = jenkinsClient.Build(job, params)
 	if err != nil {
 		return err
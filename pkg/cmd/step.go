@@ -6,6 +6,7 @@ import (
 	"github.com/jenkins-x/jx/pkg/cmd/opts"
 	step2 "github.com/jenkins-x/jx/pkg/cmd/opts/step"
 	"github.com/jenkins-x/jx/pkg/cmd/step"
+	"github.com/jenkins-x/jx/pkg/cmd/step/attest"
 	"github.com/jenkins-x/jx/pkg/cmd/step/bdd"
 	"github.com/jenkins-x/jx/pkg/cmd/step/boot"
 	"github.com/jenkins-x/jx/pkg/cmd/step/buildpack"
@@ -17,12 +18,15 @@ import (
 	"github.com/jenkins-x/jx/pkg/cmd/step/get"
 	"github.com/jenkins-x/jx/pkg/cmd/step/git"
 	"github.com/jenkins-x/jx/pkg/cmd/step/helm"
+	"github.com/jenkins-x/jx/pkg/cmd/step/ingress"
 	"github.com/jenkins-x/jx/pkg/cmd/step/nexus"
 	"github.com/jenkins-x/jx/pkg/cmd/step/post"
 	"github.com/jenkins-x/jx/pkg/cmd/step/pr"
 	"github.com/jenkins-x/jx/pkg/cmd/step/pre"
+	"github.com/jenkins-x/jx/pkg/cmd/step/render"
 	"github.com/jenkins-x/jx/pkg/cmd/step/report"
 	"github.com/jenkins-x/jx/pkg/cmd/step/restore"
+	"github.com/jenkins-x/jx/pkg/cmd/step/scan"
 	"github.com/jenkins-x/jx/pkg/cmd/step/scheduler"
 	"github.com/jenkins-x/jx/pkg/cmd/step/syntax"
 	"github.com/jenkins-x/jx/pkg/cmd/step/update"
@@ -64,6 +68,7 @@ func NewCmdStep(commonOpts *opts.CommonOptions) *cobra.Command {
 	cmd.AddCommand(git.NewCmdStepGit(commonOpts))
 	cmd.AddCommand(step.NewCmdStepGpgCredentials(commonOpts))
 	cmd.AddCommand(helm.NewCmdStepHelm(commonOpts))
+	cmd.AddCommand(ingress.NewCmdStepIngress(commonOpts))
 	cmd.AddCommand(step.NewCmdStepLinkServices(commonOpts))
 	cmd.AddCommand(nexus.NewCmdStepNexus(commonOpts))
 	cmd.AddCommand(step.NewCmdStepNextVersion(commonOpts))
@@ -72,12 +77,14 @@ func NewCmdStep(commonOpts *opts.CommonOptions) *cobra.Command {
 	cmd.AddCommand(pr.NewCmdStepPR(commonOpts))
 	cmd.AddCommand(post.NewCmdStepPost(commonOpts))
 	cmd.AddCommand(step.NewCmdStepRelease(commonOpts))
+	cmd.AddCommand(render.NewCmdStepRender(commonOpts))
 	cmd.AddCommand(step.NewCmdStepReplicate(commonOpts))
 	cmd.AddCommand(step.NewCmdStepSplitMonorepo(commonOpts))
 	cmd.AddCommand(syntax.NewCmdStepSyntax(commonOpts))
 	cmd.AddCommand(step.NewCmdStepTag(commonOpts))
 	cmd.AddCommand(step.NewCmdStepValidate(commonOpts))
 	cmd.AddCommand(verify.NewCmdStepVerify(commonOpts))
+	cmd.AddCommand(step.NewCmdStepWaitForApproval(commonOpts))
 	cmd.AddCommand(step.NewCmdStepWaitForArtifact(commonOpts))
 	cmd.AddCommand(step.NewCmdStepWaitForChart(commonOpts))
 	cmd.AddCommand(step.NewCmdStepStash(commonOpts))
@@ -89,6 +96,8 @@ func NewCmdStep(commonOpts *opts.CommonOptions) *cobra.Command {
 	cmd.AddCommand(report.NewCmdStepReport(commonOpts))
 	cmd.AddCommand(step.NewCmdStepOverrideRequirements(commonOpts))
 	cmd.AddCommand(restore.NewCmdStepRestore(commonOpts))
+	cmd.AddCommand(scan.NewCmdStepScan(commonOpts))
+	cmd.AddCommand(attest.NewCmdStepAttest(commonOpts))
 
 	return cmd
 }
@@ -0,0 +1,40 @@
+package attest
+
+import (
+	"github.com/jenkins-x/jx/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/pkg/cmd/opts/step"
+	"github.com/spf13/cobra"
+)
+
+// StepAttestOptions contains the command line flags
+type StepAttestOptions struct {
+	step.StepOptions
+}
+
+// NewCmdStepAttest Steps a command object for the "attest" command
+func NewCmdStepAttest(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &StepAttestOptions{
+		StepOptions: step.StepOptions{
+			CommonOptions: commonOpts,
+		},
+	}
+
+	cmd := &cobra.Command{
+		Use:   "attest",
+		Short: "attest [command]",
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.AddCommand(NewCmdStepAttestProvenance(commonOpts))
+	return cmd
+}
+
+// Run implements this command
+func (o *StepAttestOptions) Run() error {
+	return o.Cmd.Help()
+}
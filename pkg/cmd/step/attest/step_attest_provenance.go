@@ -0,0 +1,280 @@
+package attest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/audit"
+	"github.com/jenkins-x/jx/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/pkg/cmd/opts/step"
+	"github.com/jenkins-x/jx/pkg/cmd/step/scan"
+	"github.com/jenkins-x/jx/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/pkg/collector"
+	"github.com/jenkins-x/jx/pkg/kube"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/packages"
+	"github.com/jenkins-x/jx/pkg/provenance"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var (
+	stepAttestProvenanceLong = templates.LongDesc(`
+		Generates the SLSA provenance of the artifacts built by a release pipeline.
+
+		The provenance records the identity of the builder, the source repository and commit, the parameters of the
+		pipeline and the materials used by the build. It is signed with the signing key of the team which is generated
+		in the Secret ` + provenance.SigningKeySecret + ` the first time provenance is signed.
+
+		The signed provenance is stored in the ConfigMap ` + provenance.AttestationsConfigMap + ` and in the storage location of the
+		'` + kube.ClassificationReports + `' classifier along with the other reports of the release. 'jx promote' refuses to promote a
+		release without valid provenance to the protected environments of the provenancePolicy of the team settings.
+`)
+
+	stepAttestProvenanceExample = templates.Examples(`
+		# Generate the provenance of the image built by the pipeline
+		jx step attest provenance --digest sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef
+
+		# Generate the provenance of the image and the chart built by the pipeline recording the base image as a material
+		jx step attest provenance --digest $IMAGE_DIGEST --file charts/myapp-1.2.3.tgz --material docker.io/golang:1.13@sha256:0123456789abcdef
+`)
+)
+
+// StepAttestProvenanceOptions contains the command line flags
+type StepAttestProvenanceOptions struct {
+	step.StepOptions
+	App        string
+	Version    string
+	Image      string
+	Digest     string
+	Files      []string
+	Materials  []string
+	BuilderID  string
+	Parameters []string
+	OutputFile string
+	NoUpload   bool
+}
+
+// NewCmdStepAttestProvenance creates the command
+func NewCmdStepAttestProvenance(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &StepAttestProvenanceOptions{
+		StepOptions: step.StepOptions{
+			CommonOptions: commonOpts,
+		},
+	}
+
+	cmd := &cobra.Command{
+		Use:     "provenance",
+		Short:   "Generates and signs the SLSA provenance of the artifacts of a release",
+		Long:    stepAttestProvenanceLong,
+		Example: stepAttestProvenanceExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.Flags().StringVarP(&options.App, "app", "a", "", "The name of the application released. Defaults to $APP_NAME")
+	cmd.Flags().StringVarP(&options.Version, "version", "v", "", "The version released. Defaults to $VERSION")
+	cmd.Flags().StringVarP(&options.Image, "image", "i", "", "The image built by the pipeline. Defaults to $DOCKER_REGISTRY/$DOCKER_REGISTRY_ORG/$APP_NAME:$VERSION")
+	cmd.Flags().StringVarP(&options.Digest, "digest", "d", "", "The digest of the image such as sha256:abc123. Defaults to $IMAGE_DIGEST")
+	cmd.Flags().StringArrayVarP(&options.Files, "file", "f", []string{}, "The files built by the pipeline such as packaged charts or binaries to include as subjects of the provenance")
+	cmd.Flags().StringArrayVarP(&options.Materials, "material", "m", []string{}, "The materials used by the build in the form uri@algorithm:digest such as a base image")
+	cmd.Flags().StringVarP(&options.BuilderID, "builder-id", "", "", "The identity of the builder. Defaults to the pipelines of the dev namespace")
+	cmd.Flags().StringArrayVarP(&options.Parameters, "param", "p", []string{}, "Additional build parameters to record in the form name=value")
+	cmd.Flags().StringVarP(&options.OutputFile, "output", "o", "", "Also writes the signed provenance to the file")
+	cmd.Flags().BoolVarP(&options.NoUpload, "no-upload", "", false, "Disables storing the signed provenance in the storage location")
+	return cmd
+}
+
+// Run implements this command
+func (o *StepAttestProvenanceOptions) Run() error {
+	if o.App == "" {
+		o.App = os.Getenv("APP_NAME")
+	}
+	if o.App == "" {
+		return util.MissingOption("app")
+	}
+	if o.Version == "" {
+		o.Version = os.Getenv("VERSION")
+	}
+	if o.Version == "" {
+		return util.MissingOption("version")
+	}
+	kubeClient, ns, err := o.KubeClientAndDevNamespace()
+	if err != nil {
+		return err
+	}
+
+	subjects, err := o.subjects()
+	if err != nil {
+		return err
+	}
+	predicate, err := o.predicate(ns)
+	if err != nil {
+		return err
+	}
+	statement := provenance.NewStatement(subjects, *predicate)
+
+	key, err := provenance.GetOrCreateSigningKey(kubeClient, ns)
+	if err != nil {
+		return err
+	}
+	envelope, err := provenance.Sign(statement, key)
+	if err != nil {
+		return err
+	}
+	err = provenance.SaveAttestation(kubeClient, ns, o.App, o.Version, envelope)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(envelope, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal the signed provenance")
+	}
+	if o.OutputFile != "" {
+		err = ioutil.WriteFile(o.OutputFile, data, util.DefaultFileWritePermissions)
+		if err != nil {
+			return errors.Wrapf(err, "failed to write the provenance to %s", o.OutputFile)
+		}
+	}
+	if !o.NoUpload {
+		settings, err := o.TeamSettings()
+		if err != nil {
+			return err
+		}
+		err = o.uploadAttestation(settings, data)
+		if err != nil {
+			return err
+		}
+	}
+	log.Logger().Infof("Signed the provenance of %s version %s with key %s", util.ColorInfo(o.App), util.ColorInfo(o.Version), util.ColorInfo(key.KeyID()))
+	return nil
+}
+
+// subjects returns the image and files built by the pipeline with their digests
+func (o *StepAttestProvenanceOptions) subjects() ([]provenance.Subject, error) {
+	answer := []provenance.Subject{}
+	if o.Image == "" {
+		o.Image = scan.DefaultImage()
+	}
+	if o.Digest == "" {
+		o.Digest = os.Getenv("IMAGE_DIGEST")
+	}
+	if o.Image != "" && o.Digest != "" {
+		digest, err := provenance.ParseDigest(o.Digest)
+		if err != nil {
+			return nil, util.InvalidOptionError("digest", o.Digest, err)
+		}
+		answer = append(answer, provenance.Subject{Name: o.Image, Digest: digest})
+	} else if o.Image != "" {
+		log.Logger().Warnf("No digest of image %s so it is not included in the provenance. Use --digest to include it", o.Image)
+	}
+	for _, file := range o.Files {
+		checksum, err := packages.FileChecksum(file)
+		if err != nil {
+			return nil, err
+		}
+		answer = append(answer, provenance.Subject{Name: filepath.Base(file), Digest: provenance.Digest{"sha256": checksum}})
+	}
+	if len(answer) == 0 {
+		return nil, fmt.Errorf("no subjects for the provenance. Use --digest for the image or --file for the files built by the pipeline")
+	}
+	return answer, nil
+}
+
+// predicate returns the provenance of the build from the source repository and the environment of the pipeline
+func (o *StepAttestProvenanceOptions) predicate(ns string) (*provenance.Predicate, error) {
+	builderID := o.BuilderID
+	if builderID == "" {
+		builderID = "https://jenkins-x.io/pipelines/" + ns
+	}
+	predicate := &provenance.Predicate{
+		Builder: provenance.Builder{ID: builderID},
+		Invocation: provenance.Invocation{
+			Parameters: pipelineParameters(),
+		},
+		Metadata: provenance.Metadata{
+			BuildInvocationID: audit.CurrentPipeline(),
+		},
+	}
+	finished := time.Now().UTC()
+	predicate.Metadata.BuildFinishedOn = &finished
+
+	gitInfo, err := o.Git().Info("")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to find the source repository")
+	}
+	sha, err := o.Git().GetLatestCommitSha("")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to find the source commit")
+	}
+	source := provenance.Material{URI: "git+" + gitInfo.HttpsURL(), Digest: provenance.Digest{"sha1": sha}}
+	predicate.Invocation.ConfigSource = provenance.ConfigSource{
+		URI:        source.URI,
+		Digest:     source.Digest,
+		EntryPoint: "jenkins-x.yml",
+	}
+	predicate.Materials = append(predicate.Materials, source)
+
+	for _, material := range o.Materials {
+		idx := strings.LastIndex(material, "@")
+		if idx <= 0 {
+			return nil, util.InvalidOptionf("material", material, "expected the form uri@algorithm:digest")
+		}
+		digest, err := provenance.ParseDigest(material[idx+1:])
+		if err != nil {
+			return nil, util.InvalidOptionError("material", material, err)
+		}
+		predicate.Materials = append(predicate.Materials, provenance.Material{URI: material[:idx], Digest: digest})
+	}
+	for _, param := range o.Parameters {
+		values := strings.SplitN(param, "=", 2)
+		if len(values) != 2 || values[0] == "" {
+			return nil, util.InvalidOptionf("param", param, "expected the form name=value")
+		}
+		predicate.Invocation.Parameters[values[0]] = values[1]
+	}
+	return predicate, nil
+}
+
+// pipelineParameters returns the parameters of the pipeline from its environment variables
+func pipelineParameters() map[string]string {
+	answer := map[string]string{}
+	for _, name := range []string{"PIPELINE_KIND", "REPO_OWNER", "REPO_NAME", "BRANCH_NAME", "BUILD_NUMBER", "PULL_BASE_REF", "PULL_BASE_SHA", "PULL_NUMBER", "VERSION"} {
+		value := os.Getenv(name)
+		if value != "" {
+			answer[name] = value
+		}
+	}
+	return answer
+}
+
+// uploadAttestation stores the signed provenance in the storage location of the reports classifier
+func (o *StepAttestProvenanceOptions) uploadAttestation(settings *v1.TeamSettings, data []byte) error {
+	storageLocation := settings.StorageLocationOrDefault(kube.ClassificationReports)
+	if storageLocation.IsEmpty() {
+		log.Logger().Warnf("No storage location is configured for classifier %s so the provenance is not stored", kube.ClassificationReports)
+		return nil
+	}
+	coll, err := collector.NewCollector(storageLocation, o.Git())
+	if err != nil {
+		return errors.Wrapf(err, "failed to create the collector for storage settings %s", storageLocation.Description())
+	}
+	storagePath := filepath.Join("jenkins-x", kube.ClassificationReports, "provenance", o.App, o.Version+".intoto.json")
+	u, err := coll.CollectData(data, storagePath)
+	if err != nil {
+		return errors.Wrapf(err, "failed to store the provenance at %s", storagePath)
+	}
+	log.Logger().Infof("stored provenance at %s", util.ColorInfo(u))
+	return nil
+}
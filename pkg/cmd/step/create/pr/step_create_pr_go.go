@@ -23,16 +23,22 @@ var (
 	createtPullRequestGoLong = templates.LongDesc(`
 		Creates a Pull Request to change a go module dependency, updating the go.mod and go.sum files to use a new version
 
-		Files named Makefile or Makefile.* will be updated
+		The new version is resolved with 'go get' and 'go mod tidy'. Use --repos to raise Pull Requests on every Go repository
+		in an organisation matching a pattern so that a library upgrade can be rolled out across many microservices at once.
 `)
 
 	createtPullRequestGoExample = templates.Examples(`
 		# update a go dependency 
 		jx step create pr go --name github.com/myorg/myrepo --version v1.2.3 --repo https://github.com/jenkins-x/cloud-environments.git
 
-		# update a go dependency using a custom build step (to update the 'go.sum' file) 
+		# update a go dependency in all the go repositories in an organisation
+		jx step create pr go --name github.com/myorg/mylib --version v1.2.3 --repos myorg/*
+
+		# update a go dependency running a custom build step after the go.mod file has been updated
 		jx step create pr go --name github.com/myorg/myrepo --version v1.2.3 --build "make something" --repo https://github.com/jenkins-x/cloud-environments.git
 					`)
+
+	errGoModuleNotUsed = errors.New("go module dependency not used")
 )
 
 // StepCreatetPullRequestGoOptions contains the command line flags
@@ -42,6 +48,8 @@ type StepCreatetPullRequestGoOptions struct {
 	Name         string
 	BuildCommand string
 	FailOnBuild  bool
+	RepoPatterns []string
+	GitServerURL string
 }
 
 // NewCmdStepCreatePullRequestGo Creates a new Command object
@@ -71,7 +79,9 @@ func NewCmdStepCreatePullRequestGo(commonOpts *opts.CommonOptions) *cobra.Comman
 	}
 	AddStepCreatePrFlags(cmd, &options.StepCreatePrOptions)
 	cmd.Flags().StringVarP(&options.Name, "name", "", "", "The name of the go module dependency to use when doing updates")
-	cmd.Flags().StringVarP(&options.BuildCommand, "build", "", "make build", "The build command to update the 'go.sum' file after the change to the source")
+	cmd.Flags().StringVarP(&options.BuildCommand, "build", "", "make build", "The build command to run after the go module dependency has been updated. Use an empty value to skip the build")
+	cmd.Flags().StringArrayVarP(&options.RepoPatterns, "repos", "", []string{}, "The go repositories to update in the form 'organisation/name' where the name may end with a '*' wildcard such as 'myorg/*'")
+	cmd.Flags().StringVarP(&options.GitServerURL, "git-server", "", gits.GitHubURL, "The git server of the repositories matched by --repos")
 	cmd.Flags().BoolVarP(&options.FailOnBuild, "fail-on-build", "", false, "Should we fail to create the Pull Request if the build command fails. Its common for incompatible changes to the go code to fail to build so we usually want to go ahead with the Pull Request anyway")
 	return cmd
 }
@@ -97,6 +107,13 @@ func (o *StepCreatetPullRequestGoOptions) Run() error {
 	if !strings.HasPrefix(o.Version, "v") {
 		o.Version = "v" + o.Version
 	}
+	if len(o.RepoPatterns) > 0 {
+		gitURLs, err := o.findGoRepositories()
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		o.GitURLs = append(o.GitURLs, gitURLs...)
+	}
 	if err := o.ValidateGoOptions(); err != nil {
 		return errors.WithStack(err)
 	}
@@ -110,6 +127,14 @@ func (o *StepCreatetPullRequestGoOptions) Run() error {
 		if err != nil {
 			return nil, errors.WithStack(err)
 		}
+		if len(answer) == 0 {
+			return nil, errGoModuleNotUsed
+		}
+		err = o.runGoModUpdate(dir)
+		if err != nil {
+			log.Logger().Errorf("failed to update the go module dependency %s", o.Name)
+			return nil, errors.WithStack(err)
+		}
 		err = o.runGoBuild(dir)
 		if err != nil {
 			log.Logger().Errorf("failed to run build after modifying go.mod")
@@ -117,9 +142,110 @@ func (o *StepCreatetPullRequestGoOptions) Run() error {
 		}
 		return answer, nil
 	}
-	err = o.CreatePullRequest("go", fn)
+
+	// lets create the Pull Requests one repository at a time so that a failure on one does not stop the others
+	gitURLs := o.GitURLs
+	failed := []string{}
+	for _, gitURL := range gitURLs {
+		o.GitURLs = []string{gitURL}
+		err = o.CreatePullRequest("go", fn)
+		if err != nil {
+			if errors.Cause(err) == errGoModuleNotUsed {
+				log.Logger().Infof("ignoring %s as it does not use the go module %s", util.ColorInfo(gitURL), o.Name)
+				continue
+			}
+			if len(gitURLs) == 1 {
+				return errors.WithStack(err)
+			}
+			log.Logger().Warnf("failed to create a Pull Request on %s: %s", gitURL, err.Error())
+			failed = append(failed, gitURL)
+		}
+	}
+	o.GitURLs = gitURLs
+	if len(failed) > 0 {
+		return errors.Errorf("failed to create Pull Requests on %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// findGoRepositories returns the git URLs of the go repositories matching the --repos patterns
+func (o *StepCreatetPullRequestGoOptions) findGoRepositories() ([]string, error) {
+	kind, err := o.GitServerHostURLKind(o.GitServerURL)
+	if err != nil {
+		return nil, errors.Wrapf(err, "finding the kind of git server %s", o.GitServerURL)
+	}
+	provider, err := o.GitProviderForGitServerURL(o.GitServerURL, kind, "")
 	if err != nil {
-		return errors.WithStack(err)
+		return nil, errors.Wrapf(err, "creating git provider for %s", o.GitServerURL)
+	}
+	answer, err := FindGoRepositories(provider, o.RepoPatterns)
+	if err != nil {
+		return nil, err
+	}
+	log.Logger().Infof("found %d go repositories to update", len(answer))
+	return answer, nil
+}
+
+// FindGoRepositories returns the git URLs of the go repositories which match the patterns of the form
+// 'organisation/name' where the name may end with a '*' wildcard. Archived repositories and forks are ignored
+func FindGoRepositories(provider gits.GitProvider, patterns []string) ([]string, error) {
+	answer := []string{}
+	for _, pattern := range patterns {
+		paths := strings.SplitN(pattern, "/", 2)
+		if len(paths) != 2 || paths[0] == "" || paths[1] == "" {
+			return nil, util.InvalidOptionf("repos", pattern, "expected the form organisation/name")
+		}
+		repos, err := provider.ListRepositories(paths[0])
+		if err != nil {
+			return nil, errors.Wrapf(err, "listing repositories in %s", paths[0])
+		}
+		for _, repo := range repos {
+			if repo.Archived || repo.Fork || !strings.EqualFold(repo.Language, "go") {
+				continue
+			}
+			if !util.StringMatchesPattern(repo.Name, paths[1]) {
+				continue
+			}
+			gitURL := repo.CloneURL
+			if gitURL == "" {
+				gitURL = repo.HTMLURL
+			}
+			if util.StringArrayIndex(answer, gitURL) < 0 {
+				answer = append(answer, gitURL)
+			}
+		}
+	}
+	if len(answer) == 0 {
+		return nil, errors.Errorf("no go repositories found matching %s", strings.Join(patterns, ", "))
+	}
+	return answer, nil
+}
+
+// runGoModUpdate uses go get and go mod tidy to update the go.mod and go.sum files to the new version
+func (o *StepCreatetPullRequestGoOptions) runGoModUpdate(dir string) error {
+	commands := []util.Command{
+		{
+			Dir:  dir,
+			Name: "go",
+			Args: []string{"get", o.Name + "@" + o.Version},
+		},
+		{
+			Dir:  dir,
+			Name: "go",
+			Args: []string{"mod", "tidy"},
+		},
+	}
+	for _, cmd := range commands {
+		cmd.Env = map[string]string{
+			"GO111MODULE": "on",
+		}
+		_, err := cmd.RunWithoutRetry()
+		if err != nil {
+			if o.FailOnBuild {
+				return errors.Wrapf(err, "running %s", cmd.String())
+			}
+			log.Logger().Warnf("failed to run %s so the Pull Request will probably need some manual work to make it pass the CI tests. Failure: %s", cmd.String(), err.Error())
+		}
 	}
 	return nil
 }
@@ -127,10 +253,9 @@ func (o *StepCreatetPullRequestGoOptions) Run() error {
 func (o *StepCreatetPullRequestGoOptions) runGoBuild(dir string) error {
 	build := o.BuildCommand
 	if build == "" {
-		log.Logger().Warn("no build command so we will not change the 'go.sum' command")
 		return nil
 	}
-	log.Logger().Infof("running the build command: %s in the directory %s to update the 'go.sum' file\n", util.ColorInfo(build), dir)
+	log.Logger().Infof("running the build command: %s in the directory %s\n", util.ColorInfo(build), dir)
 
 	values := strings.Split(build, " ")
 	cmd := util.Command{
@@ -0,0 +1,42 @@
+package pr_test
+
+import (
+	"testing"
+
+	"github.com/jenkins-x/jx/pkg/cmd/step/create/pr"
+	"github.com/jenkins-x/jx/pkg/gits"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindGoRepositories(t *testing.T) {
+	t.Parallel()
+
+	newRepo := func(name string, language string, configure func(repo *gits.GitRepository)) *gits.FakeRepository {
+		repo, err := gits.NewFakeRepository("acme", name, nil, nil)
+		require.NoError(t, err)
+		repo.GitRepo.Language = language
+		if configure != nil {
+			configure(repo.GitRepo)
+		}
+		return repo
+	}
+	provider := gits.NewFakeProvider(
+		newRepo("service-a", "Go", nil),
+		newRepo("service-b", "go", nil),
+		newRepo("service-ui", "JavaScript", nil),
+		newRepo("service-old", "Go", func(repo *gits.GitRepository) { repo.Archived = true }),
+		newRepo("service-fork", "Go", func(repo *gits.GitRepository) { repo.Fork = true }),
+		newRepo("library", "Go", nil),
+	)
+
+	gitURLs, err := pr.FindGoRepositories(provider, []string{"acme/service-*", "acme/service-a"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"https://fake.git/acme/service-a.git", "https://fake.git/acme/service-b.git"}, gitURLs)
+
+	_, err = pr.FindGoRepositories(provider, []string{"acme/nothing-*"})
+	assert.Error(t, err)
+
+	_, err = pr.FindGoRepositories(provider, []string{"service-*"})
+	assert.Error(t, err)
+}
@@ -26,8 +26,10 @@ func NewCmdStepCreate(commonOpts *opts.CommonOptions) *cobra.Command {
 			helper.CheckErr(err)
 		},
 	}
+	cmd.AddCommand(NewCmdStepCreateArtifactSettings(commonOpts))
 	cmd.AddCommand(NewCmdStepCreateDevPodWorkpace(commonOpts))
 	cmd.AddCommand(NewCmdStepCreateJenkinsConfig(commonOpts))
+	cmd.AddCommand(NewCmdStepCreatePipelineDashboards(commonOpts))
 	cmd.AddCommand(NewCmdStepCreateTask(commonOpts))
 	cmd.AddCommand(NewCmdStepCreateInstallValues(commonOpts))
 	cmd.AddCommand(NewCmdStepCreateValues(commonOpts))
@@ -0,0 +1,114 @@
+package create
+
+import (
+	"fmt"
+
+	"github.com/jenkins-x/jx/pkg/artifacts"
+	"github.com/jenkins-x/jx/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/pkg/cmd/opts/step"
+	"github.com/jenkins-x/jx/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/pkg/config"
+	"github.com/jenkins-x/jx/pkg/kube"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+)
+
+var (
+	createArtifactSettingsLong = templates.LongDesc(`
+		Generates the maven settings.xml and the npmrc used by the build pods for the artifact repository of the team.
+
+		The artifact repository is selected by the 'repository' of the requirements which can be one of: ` + fmt.Sprintf("%v", config.RepositoryTypeValues) + `
+		The details of repositories which are not installed by Jenkins X are configured in the 'artifactRepository' of the requirements
+		and their credentials are read from the Secret ` + artifacts.CredentialsSecret + ` in the dev namespace.
+
+		The settings are stored in the Secret ` + artifacts.SettingsSecret + ` which is mounted into the build pods at ` + artifacts.SettingsMountPath + `
+
+		The CodeArtifact authorization token expires after 12 hours so this command should be run periodically when using CodeArtifact.
+`)
+
+	createArtifactSettingsExample = templates.Examples(`
+		# generates the settings for the artifact repository of the jx-requirements.yml in the current directory or the team settings
+		jx step create artifact-settings
+`)
+)
+
+// StepCreateArtifactSettingsOptions contains the command line flags
+type StepCreateArtifactSettingsOptions struct {
+	step.StepOptions
+
+	Dir string
+}
+
+// NewCmdStepCreateArtifactSettings creates the command
+func NewCmdStepCreateArtifactSettings(commonOpts *opts.CommonOptions) *cobra.Command {
+	o := &StepCreateArtifactSettingsOptions{
+		StepOptions: step.StepOptions{
+			CommonOptions: commonOpts,
+		},
+	}
+	cmd := &cobra.Command{
+		Use:     "artifact-settings",
+		Short:   "Generates the maven settings.xml and npmrc of the artifact repository used by the build pods",
+		Long:    createArtifactSettingsLong,
+		Example: createArtifactSettingsExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			o.Cmd = cmd
+			o.Args = args
+			err := o.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.Flags().StringVarP(&o.Dir, "dir", "d", ".", "the directory to look for the jx-requirements.yml file")
+	return cmd
+}
+
+// Run implements the command
+func (o *StepCreateArtifactSettingsOptions) Run() error {
+	requirements, err := o.TeamRequirements(o.Dir)
+	if err != nil {
+		return err
+	}
+	if !artifacts.IsEnabled(requirements.Repository) {
+		log.Logger().Infof("No artifact repository is used so not generating the settings")
+		return nil
+	}
+	endpoints, err := artifacts.GetEndpoints(requirements)
+	if err != nil {
+		return err
+	}
+	kubeClient, ns, err := o.KubeClientAndDevNamespace()
+	if err != nil {
+		return err
+	}
+	credentials, err := artifacts.GetCredentials(kubeClient, ns, requirements)
+	if err != nil {
+		return err
+	}
+	npmrc, err := artifacts.Npmrc(endpoints, credentials)
+	if err != nil {
+		return err
+	}
+	settings := artifacts.MavenSettingsXML(endpoints, credentials)
+
+	_, err = kube.DefaultModifySecret(kubeClient, ns, artifacts.SettingsSecret, func(secret *corev1.Secret) error {
+		if secret.Data == nil {
+			secret.Data = map[string][]byte{}
+		}
+		secret.Data[artifacts.MavenSettingsKey] = []byte(settings)
+		if npmrc != "" {
+			secret.Data[artifacts.NpmrcKey] = []byte(npmrc)
+		} else {
+			delete(secret.Data, artifacts.NpmrcKey)
+		}
+		return nil
+	}, nil)
+	if err != nil {
+		return errors.Wrapf(err, "failed to store the artifact repository settings in Secret %s", artifacts.SettingsSecret)
+	}
+	log.Logger().Infof("Stored the settings of the %s artifact repository %s in Secret %s", util.ColorInfo(requirements.Repository), util.ColorInfo(endpoints.MavenURL), util.ColorInfo(artifacts.SettingsSecret))
+	return nil
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/jenkins-x/jx/pkg/cloud/gke/externaldns"
 	"github.com/jenkins-x/jx/pkg/config"
 	"github.com/jenkins-x/jx/pkg/kube"
+	"github.com/jenkins-x/jx/pkg/kube/pki"
 	"github.com/jenkins-x/jx/pkg/util"
 
 	"github.com/jenkins-x/jx/pkg/cloud"
@@ -180,8 +181,22 @@ func (o *StepCreateInstallValuesOptions) Run() error {
 		}
 	}
 
-	// TLS uses cert-manager to ask LetsEncrypt for a signed certificate
-	if requirements.Ingress.TLS.Enabled {
+	// local clusters cannot be reached by LetsEncrypt so use a self signed certificate
+	if requirements.IsSelfSignedTLS() {
+		kubeClient, err := o.KubeClient()
+		if err != nil {
+			return errors.Wrap(err, "creating kubernetes client")
+		}
+		secretNamespace := ns
+		if secretNamespace == "" {
+			secretNamespace = requirements.Cluster.Namespace
+		}
+		_, err = pki.EnsureSelfSignedTLSSecret(kubeClient, secretNamespace, requirements.Ingress.TLS.SecretName, requirements.Ingress.Domain)
+		if err != nil {
+			return errors.Wrap(err, "creating the self signed TLS secret")
+		}
+	} else if requirements.Ingress.TLS.Enabled {
+		// TLS uses cert-manager to ask LetsEncrypt for a signed certificate
 		if requirements.Cluster.Provider != cloud.GKE {
 			log.Logger().Warnf("Note that we have only tested TLS support on Google Container Engine with external-dns so far. This may not work!")
 		}
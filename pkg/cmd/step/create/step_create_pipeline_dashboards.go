@@ -0,0 +1,151 @@
+package create
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/jenkins-x/jx/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/pkg/cmd/opts/step"
+	"github.com/jenkins-x/jx/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/pkg/helm"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/observability"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"k8s.io/helm/pkg/proto/hapi/chart"
+)
+
+const (
+	pipelineDashboardsFileName = "dashboards.yaml"
+	pipelineAlertsFileName     = "prometheusrule.yaml"
+)
+
+var (
+	createPipelineDashboardsLong = templates.LongDesc(`
+		Generates the chart of the ` + observability.PipelineDashboardsAppName + ` app which contains a Grafana dashboard
+		and Prometheus alert rules for the service level objectives of the pipelines:
+
+		* the success rate of the release pipelines of each repository
+		* the duration of the pipelines of each repository
+		* the time the queued pipelines wait for the pipeline concurrency limits of the team
+		* the time from a webhook to the first step of its pipeline starting
+
+		The objectives are calculated from the metrics the build controller records from the PipelineActivities.
+		The dashboard is loaded by the Grafana of the observability stack and the alert rules require the prometheus-operator.
+
+		Once the chart is released the app is installed with:
+
+			jx add app ` + observability.PipelineDashboardsAppName + `
+`)
+
+	createPipelineDashboardsExample = templates.Examples(`
+		# generates the chart in the charts/jx-pipeline-dashboards directory
+		jx step create pipeline-dashboards
+
+		# generates the chart with stricter objectives
+		jx step create pipeline-dashboards --success-rate 0.95 --duration 15m --queue-time 1m
+`)
+)
+
+// StepCreatePipelineDashboardsOptions contains the command line flags
+type StepCreatePipelineDashboardsOptions struct {
+	step.StepOptions
+
+	Dir     string
+	Version string
+	SLO     observability.PipelineSLO
+}
+
+// NewCmdStepCreatePipelineDashboards creates the command
+func NewCmdStepCreatePipelineDashboards(commonOpts *opts.CommonOptions) *cobra.Command {
+	o := &StepCreatePipelineDashboardsOptions{
+		StepOptions: step.StepOptions{
+			CommonOptions: commonOpts,
+		},
+	}
+	defaults := observability.DefaultPipelineSLO()
+	cmd := &cobra.Command{
+		Use:     "pipeline-dashboards",
+		Short:   "Generates the chart of the Grafana dashboard and Prometheus alert rules of the pipeline SLOs",
+		Long:    createPipelineDashboardsLong,
+		Example: createPipelineDashboardsExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			o.Cmd = cmd
+			o.Args = args
+			err := o.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.Flags().StringVarP(&o.Dir, "dir", "d", filepath.Join("charts", observability.PipelineDashboardsAppName), "the directory to generate the chart in")
+	cmd.Flags().StringVarP(&o.Version, "version", "v", "0.0.1-SNAPSHOT", "the version of the chart")
+	cmd.Flags().Float64VarP(&o.SLO.SuccessRate, "success-rate", "", defaults.SuccessRate, "the minimum ratio of the release pipelines of a repository which succeed")
+	cmd.Flags().Float64VarP(&o.SLO.Percentile, "percentile", "", defaults.Percentile, "the percentile of the durations, queue times and webhook to start times the objectives apply to")
+	cmd.Flags().DurationVarP(&o.SLO.Duration, "duration", "", defaults.Duration, "the maximum time the pipelines of a repository take")
+	cmd.Flags().DurationVarP(&o.SLO.QueueTime, "queue-time", "", defaults.QueueTime, "the maximum time the queued pipelines wait")
+	cmd.Flags().DurationVarP(&o.SLO.WebHookToStart, "webhook-to-start", "", defaults.WebHookToStart, "the maximum time from a webhook to the first step of its pipeline starting")
+	cmd.Flags().StringVarP(&o.SLO.Window, "window", "", defaults.Window, "the range the objectives are calculated over")
+	cmd.Flags().StringVarP(&o.SLO.For, "for", "", defaults.For, "the time an objective must be missed before its alert fires")
+	return cmd
+}
+
+// Run implements the command
+func (o *StepCreatePipelineDashboardsOptions) Run() error {
+	if o.SLO.SuccessRate <= 0 || o.SLO.SuccessRate > 1 {
+		return util.InvalidOptionf("success-rate", o.SLO.SuccessRate, "must be greater than 0 and at most 1")
+	}
+	if o.SLO.Percentile <= 0 || o.SLO.Percentile >= 1 {
+		return util.InvalidOptionf("percentile", o.SLO.Percentile, "must be between 0 and 1")
+	}
+	for name, d := range map[string]time.Duration{"duration": o.SLO.Duration, "queue-time": o.SLO.QueueTime, "webhook-to-start": o.SLO.WebHookToStart} {
+		if d <= 0 {
+			return util.InvalidOptionf(name, d, "must be positive")
+		}
+	}
+	err := os.MkdirAll(o.Dir, util.DefaultWritePermissions)
+	if err != nil {
+		return errors.Wrapf(err, "creating dir %s", o.Dir)
+	}
+
+	chartFile := filepath.Join(o.Dir, helm.ChartFileName)
+	metadata := &chart.Metadata{
+		ApiVersion:  "v1",
+		Name:        observability.PipelineDashboardsAppName,
+		Version:     o.Version,
+		Description: "Grafana dashboard and Prometheus alert rules of the Jenkins X pipeline SLOs",
+		Keywords:    []string{"jenkins-x", "grafana", "prometheus", "slo"},
+	}
+	err = helm.SaveFile(chartFile, metadata)
+	if err != nil {
+		return errors.Wrapf(err, "saving %s", chartFile)
+	}
+	valuesFile := filepath.Join(o.Dir, helm.ValuesFileName)
+	exists, err := util.FileExists(valuesFile)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		err = helm.SaveFile(valuesFile, map[string]interface{}{})
+		if err != nil {
+			return errors.Wrapf(err, "saving %s", valuesFile)
+		}
+	}
+
+	configMaps, err := observability.DashboardConfigMaps([]*observability.Dashboard{observability.PipelineSLODashboard(o.SLO)})
+	if err != nil {
+		return err
+	}
+	err = observability.WriteTemplate(o.Dir, pipelineDashboardsFileName, []interface{}{configMaps[0]})
+	if err != nil {
+		return err
+	}
+	rules := observability.PipelineSLORules(o.SLO, observability.PipelineDashboardsAppName)
+	err = observability.WriteTemplate(o.Dir, pipelineAlertsFileName, []interface{}{rules})
+	if err != nil {
+		return err
+	}
+	log.Logger().Infof("Generated the chart of the %s app in %s", util.ColorInfo(observability.PipelineDashboardsAppName), util.ColorInfo(o.Dir))
+	return nil
+}
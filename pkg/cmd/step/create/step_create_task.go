@@ -20,20 +20,25 @@ import (
 	"github.com/jenkins-x/jx/pkg/cmd/step/git"
 
 	"github.com/ghodss/yaml"
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/artifacts"
 	jxclient "github.com/jenkins-x/jx/pkg/client/clientset/versioned"
 	"github.com/jenkins-x/jx/pkg/cmd/helper"
 	"github.com/jenkins-x/jx/pkg/cmd/opts"
 	syntaxstep "github.com/jenkins-x/jx/pkg/cmd/step/syntax"
 	"github.com/jenkins-x/jx/pkg/cmd/templates"
 	"github.com/jenkins-x/jx/pkg/config"
+	"github.com/jenkins-x/jx/pkg/envfrom"
 	"github.com/jenkins-x/jx/pkg/gits"
 	"github.com/jenkins-x/jx/pkg/jenkinsfile"
 	"github.com/jenkins-x/jx/pkg/jenkinsfile/gitresolver"
 	"github.com/jenkins-x/jx/pkg/kube"
 	"github.com/jenkins-x/jx/pkg/log"
 	"github.com/jenkins-x/jx/pkg/tekton"
+	"github.com/jenkins-x/jx/pkg/tekton/cronpipelines"
 	"github.com/jenkins-x/jx/pkg/tekton/syntax"
 	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/jenkins-x/jx/pkg/vault"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	pipelineapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
@@ -86,6 +91,7 @@ type StepCreateTaskOptions struct {
 	Context             string
 	CustomLabels        []string
 	CustomEnvs          []string
+	CustomParams        []string
 	NoApply             *bool
 	DryRun              bool
 	InterpretMode       bool
@@ -165,6 +171,7 @@ func NewCmdStepCreateTaskAndOption(commonOpts *opts.CommonOptions) (*cobra.Comma
 	cmd.Flags().StringVarP(&options.PipelineKind, "kind", "k", "release", "The kind of pipeline to create such as: "+strings.Join(jenkinsfile.PipelineKinds, ", "))
 	cmd.Flags().StringArrayVarP(&options.CustomLabels, "label", "l", nil, "List of custom labels to be applied to resources that are created")
 	cmd.Flags().StringArrayVarP(&options.CustomEnvs, "env", "e", nil, "List of custom environment variables to be applied to resources that are created")
+	cmd.Flags().StringArrayVarP(&options.CustomParams, "param", "", nil, "List of custom 'name=value' params passed to the pipeline which are also available to the steps as upper case environment variables (can be use multiple times)")
 	cmd.Flags().StringVarP(&options.CloneGitURL, "clone-git-url", "", "", "Specify the git URL to clone to a temporary directory to get the source code")
 	cmd.Flags().StringVarP(&options.CloneDir, "clone-dir", "", "", "Specify the directory of the directory containing the git clone")
 	cmd.Flags().StringVarP(&options.PullRequestNumber, "pr-number", "", "", "If a Pull Request this is it's number")
@@ -333,6 +340,11 @@ func (o *StepCreateTaskOptions) Run() error {
 		return errors.Wrapf(err, "failed to set the version on release pipelines")
 	}
 
+	err = o.addCustomParams()
+	if err != nil {
+		return err
+	}
+
 	log.Logger().Debug("Creating Tekton CRDs")
 	tektonCRDs, err := o.generateTektonCRDs(effectiveProjectConfig, ns, pipelineName, resourceName)
 	if err != nil {
@@ -367,18 +379,58 @@ func (o *StepCreateTaskOptions) Run() error {
 		if o.DisableConcurrent {
 			o.waitForPreviousPipeline(tektonClient, ns, 10*time.Minute)
 		}
+		var concurrency *v1.PipelineConcurrency
+		settings, err := o.TeamSettings()
+		if err != nil {
+			log.Logger().Warnf("Unable to load the team settings so not limiting the pipeline concurrency: %s", err)
+		} else {
+			concurrency = settings.PipelineConcurrency
+		}
+		run := tektonCRDs.PipelineRun()
+		if o.PipelineKind != "" {
+			if run.Labels == nil {
+				run.Labels = map[string]string{}
+			}
+			run.Labels[tekton.LabelKind] = o.PipelineKind
+		}
+
+		err = o.applyEnvFrom(kubeClient, ns, resourceName, effectiveProjectConfig, tektonCRDs.Tasks())
+		if err != nil {
+			return errors.Wrapf(err, "failed to load the environment variables of the pipeline")
+		}
+
 		log.Logger().Infof("Applying changes ")
-		err := tekton.ApplyPipeline(jxClient, tektonClient, tektonCRDs, ns, activityKey)
+		queued, err := tekton.ApplyPipelineWithConcurrency(jxClient, tektonClient, tektonCRDs, ns, activityKey, concurrency)
 		if err != nil {
 			return errors.Wrapf(err, "failed to apply Tekton CRDs")
 		}
+		if queued {
+			log.Logger().Infof("The pipeline will start once the running pipelines complete. See %s", util.ColorInfo("jx get pipeline-queue"))
+		}
 		tektonCRDs.AddLabels(o.labels)
 
 		log.Logger().Debugf(" for %s", tektonCRDs.PipelineRun().Name)
+
+		if o.PipelineKind == jenkinsfile.PipelineKindRelease && o.Branch == "master" {
+			o.registerTriggers(jxClient, ns, effectiveProjectConfig.PipelineConfig)
+		}
 	}
 	return nil
 }
 
+// registerTriggers registers the cron triggers of the pipeline configuration so the scheduled pipelines are created
+// by 'jx controller cron-pipelines'
+func (o *StepCreateTaskOptions) registerTriggers(jxClient jxclient.Interface, ns string, pipelineConfig *jenkinsfile.PipelineConfig) {
+	var triggers *jenkinsfile.Triggers
+	if pipelineConfig != nil {
+		triggers = pipelineConfig.Triggers
+	}
+	err := cronpipelines.Register(jxClient, ns, o.GitInfo.Organisation, o.GitInfo.Name, triggers, time.Now())
+	if err != nil {
+		log.Logger().Warnf("Failed to register the cron triggers of %s/%s: %s", o.GitInfo.Organisation, o.GitInfo.Name, err)
+	}
+}
+
 func (o *StepCreateTaskOptions) waitForPreviousPipeline(tektonClient tektonclient.Interface, ns string, defaultWait time.Duration) {
 	fallbackWait := true
 	labelSelector := fmt.Sprintf("owner=%s,repository=%s,branch=%s", o.GitInfo.Organisation, o.GitInfo.Name, o.Branch)
@@ -578,11 +630,17 @@ func (o *StepCreateTaskOptions) createEffectiveProjectConfigFromOptions(tektonCl
 
 // createEffectiveProjectConfig creates the effective parsed pipeline which is then used to generate the Tekton CRDs.
 func (o *StepCreateTaskOptions) createEffectiveProjectConfig(packsDir string, projectConfig *config.ProjectConfig, projectConfigFile string, resolver jenkinsfile.ImportFileResolver, ns string) (*config.ProjectConfig, error) {
+	// the branch overrides of pull requests match the source branch of the pull request
+	branch := os.Getenv("PULL_HEAD_REF")
+	if branch == "" {
+		branch = o.Branch
+	}
 	createEffective := &syntaxstep.StepSyntaxEffectiveOptions{
 		Pack:              o.Pack,
 		BuildPackURL:      o.BuildPackURL,
 		BuildPackRef:      o.BuildPackRef,
 		Context:           o.Context,
+		Branch:            branch,
 		CustomImage:       o.CustomImage,
 		DefaultImage:      o.DefaultImage,
 		UseKaniko:         !o.NoKaniko,
@@ -599,6 +657,14 @@ func (o *StepCreateTaskOptions) createEffectiveProjectConfig(packsDir string, pr
 	commonCopy := *o.CommonOptions
 	createEffective.CommonOptions = &commonCopy
 
+	if !o.InterpretMode {
+		var err error
+		createEffective.PipelineExtensions, err = createEffective.LoadPipelineExtensions()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to load the pipeline extensions")
+		}
+	}
+
 	effectiveProjectConfig, err := createEffective.CreateEffectivePipeline(packsDir, projectConfig, projectConfigFile, resolver)
 	if err != nil {
 		return nil, errors.Wrapf(err, "effective pipeline creation failed")
@@ -668,6 +734,14 @@ func (o *StepCreateTaskOptions) generateTektonCRDs(effectiveProjectConfig *confi
 	prLabels := util.MergeMaps(o.labels, effectivePipeline.GetPodLabels())
 	run := tekton.CreatePipelineRun(resources, pipeline.Name, pipeline.APIVersion, prLabels, o.ServiceAccount, o.pipelineParams, timeout, effectivePipeline.GetPossibleAffinityPolicy(pipeline.Name), effectivePipeline.GetTolerations())
 
+	settings, err := o.TeamSettings()
+	if err != nil {
+		log.Logger().Warnf("Unable to load the team settings so not applying the build pod policy: %s", err)
+	} else {
+		tekton.ApplyBuildPodPolicy(settings.GetBuildPodPolicy(o.PipelineKind), pipeline, tasks, run)
+		applyArtifactRepository(settings, tasks)
+	}
+
 	tektonCRDs, err := tekton.NewCRDWrapper(pipeline, tasks, resources, structure, run)
 	if err != nil {
 		return nil, err
@@ -676,6 +750,59 @@ func (o *StepCreateTaskOptions) generateTektonCRDs(effectiveProjectConfig *confi
 	return tektonCRDs, nil
 }
 
+// applyArtifactRepository adds the details of the artifact repository of the team to the build pods
+func applyArtifactRepository(settings *v1.TeamSettings, tasks []*pipelineapi.Task) {
+	requirements, err := config.GetRequirementsConfigFromTeamSettings(settings)
+	if err != nil {
+		log.Logger().Warnf("Unable to load the requirements so not applying the artifact repository: %s", err)
+		return
+	}
+	if requirements == nil || !artifacts.IsEnabled(requirements.Repository) {
+		return
+	}
+	endpoints, err := artifacts.GetEndpoints(requirements)
+	if err != nil {
+		log.Logger().Warnf("Unable to find the artifact repository %s so not applying it: %s", requirements.Repository, err)
+		return
+	}
+	tekton.ApplyDefaultEnv(artifacts.BuildPodEnv(endpoints), tasks)
+}
+
+// applyEnvFrom resolves the environment variables of the external config stores of the project into a secret which
+// is referenced by the steps of the tasks
+func (o *StepCreateTaskOptions) applyEnvFrom(kubeClient kubeclient.Interface, ns string, resourceName string, projectConfig *config.ProjectConfig, tasks []*pipelineapi.Task) error {
+	if len(projectConfig.EnvFrom) == 0 {
+		return nil
+	}
+	region, _ := kube.ReadRegion(kubeClient, ns)
+	resolver := envfrom.NewResolver(func() (vault.Client, error) {
+		return o.SystemVaultClient(ns)
+	}, region)
+	values, err := resolver.Resolve(projectConfig.EnvFrom)
+	if err != nil {
+		return err
+	}
+	name := envfrom.SecretName(resourceName)
+	secret := envfrom.Secret(name, values, o.labels)
+	secrets := kubeClient.CoreV1().Secrets(ns)
+	_, err = secrets.Create(secret)
+	if err != nil {
+		existing, err := secrets.Get(name, metav1.GetOptions{})
+		if err != nil {
+			return errors.Wrapf(err, "failed to create the secret %s", name)
+		}
+		existing.Data = secret.Data
+		existing.Labels = util.MergeMaps(existing.Labels, secret.Labels)
+		_, err = secrets.Update(existing)
+		if err != nil {
+			return errors.Wrapf(err, "failed to update the secret %s", name)
+		}
+	}
+	log.Logger().Infof("Loaded %d environment variables from the config stores into secret %s", len(values), util.ColorInfo(name))
+	tekton.ApplyDefaultEnv(envfrom.EnvVars(name, values), tasks)
+	return nil
+}
+
 func (o *StepCreateTaskOptions) loadProjectConfig() (*config.ProjectConfig, string, error) {
 	if o.Context != "" {
 		fileName := filepath.Join(o.CloneDir, fmt.Sprintf("jenkins-x-%s.yml", o.Context))
@@ -1372,6 +1499,21 @@ func (o *StepCreateTaskOptions) setRevisionForReleasePipeline(version string) {
 	}
 }
 
+// addCustomParams adds the custom params to the pipeline params which are passed to every task and exposed to the
+// steps as upper case environment variables
+func (o *StepCreateTaskOptions) addCustomParams() error {
+	params, err := syntax.ParsePipelineParams(o.CustomParams)
+	if err != nil {
+		return errors.Wrap(err, "invalid pipeline param")
+	}
+	for _, param := range syntax.ToPipelineParams(params) {
+		if !hasParam(o.pipelineParams, param.Name) {
+			o.pipelineParams = append(o.pipelineParams, param)
+		}
+	}
+	return nil
+}
+
 func hasParam(params []pipelineapi.Param, name string) bool {
 	for _, param := range params {
 		if param.Name == name {
@@ -6,6 +6,8 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 
 	"github.com/jenkins-x/jx/pkg/kube/cluster"
 	v1 "k8s.io/api/core/v1"
@@ -19,6 +21,7 @@ import (
 	"github.com/jenkins-x/jx/pkg/io/secrets"
 	"github.com/jenkins-x/jx/pkg/kube"
 	"github.com/jenkins-x/jx/pkg/secreturl"
+	"github.com/jenkins-x/jx/pkg/secreturl/localvault"
 
 	"github.com/jenkins-x/jx/pkg/config"
 	"github.com/jenkins-x/jx/pkg/helm"
@@ -347,6 +350,7 @@ func (o *StepCreateValuesOptions) createLocalSecretFilesSecret(requirements *con
 		if err != nil {
 			return errors.Wrap(err, "there was a problem obtaining the local secret files")
 		}
+		warnIfLocalSecretFilesNotEncrypted(secretFiles)
 		existingSecret, err := kubeClient.CoreV1().Secrets(ns).Get(localSecretsSecretName, metav1.GetOptions{})
 		if err != nil {
 			secret := &v1.Secret{
@@ -399,6 +403,26 @@ func getLocalSecretFilesAsMap(requirements *config.RequirementsConfig) (map[stri
 	return secretFiles, nil
 }
 
+// warnIfLocalSecretFilesNotEncrypted warns if the local secret files are stored in plain text in the cluster
+func warnIfLocalSecretFilesNotEncrypted(secretFiles map[string][]byte) {
+	if len(secretFiles) == 0 {
+		return
+	}
+	plainText := []string{}
+	for name, data := range secretFiles {
+		if !localvault.IsEncrypted(data) {
+			plainText = append(plainText, name)
+		}
+	}
+	if len(plainText) == 0 {
+		log.Logger().Infof("The local secret files are encrypted so the pipeline needs the encryption key in $%s", localvault.EncryptionKeyEnvVar)
+		return
+	}
+	sort.Strings(plainText)
+	log.Logger().Warnf("The local secret files %s are stored in plain text in Secret %s. Set $%s to encrypt them at rest",
+		strings.Join(plainText, ", "), localSecretsSecretName, localvault.EncryptionKeyEnvVar)
+}
+
 func (o *StepCreateValuesOptions) enableOpenShiftRegistryPermissions(ns string, registry string) (string, error) {
 	log.Logger().Infof("Enabling permissions for OpenShift registry in namespace %s", ns)
 	// Open the registry so any authenticated user can pull images from the jx namespace
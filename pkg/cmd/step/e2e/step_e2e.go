@@ -32,6 +32,7 @@ func NewCmdStepE2E(commonOpts *opts.CommonOptions) *cobra.Command {
 	}
 	cmd.AddCommand(NewCmdStepE2ELabel(commonOpts))
 	cmd.AddCommand(NewCmdStepE2EGC(commonOpts))
+	cmd.AddCommand(NewCmdStepE2ERun(commonOpts))
 	return cmd
 }
 
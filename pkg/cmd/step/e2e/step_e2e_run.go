@@ -0,0 +1,417 @@
+package e2e
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jenkins-x/jx/pkg/builds"
+	"github.com/jenkins-x/jx/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/pkg/cmd/opts/step"
+	"github.com/jenkins-x/jx/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/pkg/collector"
+	"github.com/jenkins-x/jx/pkg/helm"
+	"github.com/jenkins-x/jx/pkg/kube"
+	"github.com/jenkins-x/jx/pkg/kube/naming"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// LabelE2E the label of the ephemeral namespaces created for the end to end tests of an app with the name of the app
+	LabelE2E = "jenkins.io/e2e"
+
+	e2eTestContainer    = "e2e"
+	e2eReportsContainer = "reports"
+	e2eReportsVolume    = "reports"
+	e2eReportsImage     = "busybox"
+)
+
+// StepE2ERunOptions contains the command line flags
+type StepE2ERunOptions struct {
+	step.StepOptions
+
+	App             string
+	Chart           string
+	Version         string
+	SetValues       []string
+	ValueFiles      []string
+	Dependencies    []string
+	Image           string
+	Command         []string
+	Env             []string
+	ReportsDir      string
+	NamespacePrefix string
+	Timeout         time.Duration
+	Classifier      string
+	Keep            bool
+}
+
+// e2eDependency a chart deployed into the namespace of the end to end tests
+type e2eDependency struct {
+	Chart   string
+	Version string
+}
+
+var (
+	stepE2ERunLong = templates.LongDesc(`
+		This pipeline step runs the end to end tests of an application in an ephemeral namespace.
+
+		The step:
+
+		    * creates a new namespace labelled with ` + LabelE2E + `
+		    * deploys the declared dependencies at the specified versions and then the chart of the application
+		    * runs the end to end test container against the application and prints its logs
+		    * stores the reports the test container writes to the reports directory in the '` + kube.ClassificationTests + `' storage location
+		    * deletes the releases and the namespace
+
+		The test container has the environment variables E2E_NAMESPACE, APP_NAME and APP_URL, which is the URL of the
+		service of the application in the namespace.
+
+		The step fails if the test container fails.
+`)
+
+	stepE2ERunExample = templates.Examples(`
+		# run the end to end tests of the chart in the current directory
+		jx step e2e run --image gcr.io/myproject/myapp-e2e:0.0.1
+
+		# deploy a database and the application with a specific image tag and run the tests with a specific command
+		jx step e2e run --dependency stable/postgresql=6.5.0 --set image.tag=$VERSION --image gcr.io/myproject/myapp-e2e:0.0.1 --command "make,e2e"
+`)
+)
+
+// NewCmdStepE2ERun creates the CLI command
+func NewCmdStepE2ERun(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := StepE2ERunOptions{
+		StepOptions: step.StepOptions{
+			CommonOptions: commonOpts,
+		},
+	}
+	cmd := &cobra.Command{
+		Use:     "run",
+		Short:   "Runs the end to end tests of an application in an ephemeral namespace",
+		Long:    stepE2ERunLong,
+		Example: stepE2ERunExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.Flags().StringVarP(&options.App, "app", "a", "", "The name of the application. Defaults to $APP_NAME or $REPO_NAME")
+	cmd.Flags().StringVarP(&options.Chart, "chart", "", ".", "The chart of the application")
+	cmd.Flags().StringVarP(&options.Version, "version", "", "", "The version of the chart of the application if the chart is in a chart repository")
+	cmd.Flags().StringArrayVarP(&options.SetValues, "set", "", nil, "The helm values of the application such as image.tag=1.2.3")
+	cmd.Flags().StringArrayVarP(&options.ValueFiles, "values", "f", nil, "The helm values files of the application")
+	cmd.Flags().StringArrayVarP(&options.Dependencies, "dependency", "d", nil, "A chart to deploy before the application in the format repo/chart=version")
+	cmd.Flags().StringVarP(&options.Image, "image", "i", "", "The image of the end to end test container")
+	cmd.Flags().StringSliceVarP(&options.Command, "command", "", nil, "The command of the end to end test container. Defaults to the entrypoint of the image")
+	cmd.Flags().StringArrayVarP(&options.Env, "env", "e", nil, "The environment variables of the end to end test container in the format NAME=value")
+	cmd.Flags().StringVarP(&options.ReportsDir, "reports-dir", "r", "/reports", "The directory of the end to end test container the reports are written to")
+	cmd.Flags().StringVarP(&options.NamespacePrefix, "namespace-prefix", "", "e2e-", "The prefix of the name of the ephemeral namespace")
+	cmd.Flags().DurationVarP(&options.Timeout, "timeout", "t", 30*time.Minute, "The maximum time to wait for the end to end tests")
+	cmd.Flags().StringVarP(&options.Classifier, "classifier", "c", kube.ClassificationTests, "The storage location classifier of the reports")
+	cmd.Flags().BoolVarP(&options.Keep, "keep", "k", false, "Keeps the namespace after the tests so that it can be inspected")
+	return cmd
+}
+
+// Run runs the command
+func (o *StepE2ERunOptions) Run() error {
+	if o.Image == "" {
+		return util.MissingOption("image")
+	}
+	if o.App == "" {
+		o.App = os.Getenv("APP_NAME")
+	}
+	if o.App == "" {
+		o.App = os.Getenv("REPO_NAME")
+	}
+	if o.App == "" {
+		return util.MissingOption("app")
+	}
+	dependencies, err := parseE2EDependencies(o.Dependencies)
+	if err != nil {
+		return err
+	}
+	env, err := parseE2EEnv(o.Env)
+	if err != nil {
+		return err
+	}
+	kubeClient, err := o.KubeClient()
+	if err != nil {
+		return err
+	}
+
+	ns := e2eNamespace(o.NamespacePrefix, o.App, builds.GetBuildNumber(), time.Now())
+	labels := map[string]string{
+		LabelE2E:            naming.ToValidValue(o.App),
+		kube.LabelCreatedBy: kube.ValueCreatedByJX,
+	}
+	log.Logger().Infof("Creating the namespace %s for the end to end tests of %s", util.ColorInfo(ns), util.ColorInfo(o.App))
+	err = kube.EnsureNamespaceCreated(kubeClient, ns, labels, nil)
+	if err != nil {
+		return errors.Wrapf(err, "creating the namespace %s", ns)
+	}
+	releases := []string{}
+	defer func() {
+		if o.Keep {
+			log.Logger().Infof("Keeping the namespace %s", util.ColorInfo(ns))
+			return
+		}
+		o.tearDown(kubeClient, ns, releases)
+	}()
+
+	for _, dependency := range dependencies {
+		releaseName := naming.ToValidNameTruncated(ns+"-"+filepath.Base(dependency.Chart), 53)
+		log.Logger().Infof("Deploying %s %s", util.ColorInfo(dependency.Chart), util.ColorInfo(dependency.Version))
+		releases = append(releases, releaseName)
+		err = o.InstallChartWithOptions(helm.InstallChartOptions{
+			Chart:       dependency.Chart,
+			Version:     dependency.Version,
+			ReleaseName: releaseName,
+			Ns:          ns,
+			Wait:        true,
+		})
+		if err != nil {
+			return errors.Wrapf(err, "deploying the dependency %s", dependency.Chart)
+		}
+	}
+
+	releaseName := naming.ToValidNameTruncated(ns+"-"+o.App, 53)
+	log.Logger().Infof("Deploying %s", util.ColorInfo(o.App))
+	releases = append(releases, releaseName)
+	err = o.InstallChartWithOptions(helm.InstallChartOptions{
+		Chart:       o.Chart,
+		Version:     o.Version,
+		ReleaseName: releaseName,
+		Ns:          ns,
+		SetValues:   o.SetValues,
+		ValueFiles:  o.ValueFiles,
+		Wait:        true,
+	})
+	if err != nil {
+		return errors.Wrapf(err, "deploying %s", o.App)
+	}
+
+	pod := o.createE2EPod(ns, env)
+	pod, err = kubeClient.CoreV1().Pods(ns).Create(pod)
+	if err != nil {
+		return errors.Wrapf(err, "creating the end to end test pod in namespace %s", ns)
+	}
+	log.Logger().Infof("Running the end to end tests in pod %s", util.ColorInfo(pod.Name))
+	exitCode, err := waitForE2EContainer(kubeClient, ns, pod.Name, o.Timeout)
+	o.printLogs(kubeClient, ns, pod.Name)
+	if err != nil {
+		return err
+	}
+
+	err = o.collectReports(ns, pod.Name)
+	if err != nil {
+		log.Logger().Warnf("Failed to collect the end to end test reports: %s", err)
+	}
+	if exitCode != 0 {
+		return fmt.Errorf("the end to end tests of %s failed with exit code %d", o.App, exitCode)
+	}
+	log.Logger().Infof("The end to end tests of %s passed", util.ColorInfo(o.App))
+	return nil
+}
+
+// createE2EPod creates the pod which runs the end to end test container along with a container which keeps the
+// reports volume available until the reports are collected
+func (o *StepE2ERunOptions) createE2EPod(ns string, env []corev1.EnvVar) *corev1.Pod {
+	env = append([]corev1.EnvVar{
+		{Name: "E2E_NAMESPACE", Value: ns},
+		{Name: "APP_NAME", Value: o.App},
+		{Name: "APP_URL", Value: fmt.Sprintf("http://%s.%s.svc.cluster.local", o.App, ns)},
+	}, env...)
+	volumeMounts := []corev1.VolumeMount{
+		{Name: e2eReportsVolume, MountPath: o.ReportsDir},
+	}
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: naming.ToValidNameTruncated(o.App, 40) + "-e2e-",
+			Labels: map[string]string{
+				LabelE2E: naming.ToValidValue(o.App),
+			},
+		},
+		Spec: corev1.PodSpec{
+			RestartPolicy: corev1.RestartPolicyNever,
+			Containers: []corev1.Container{
+				{
+					Name:         e2eTestContainer,
+					Image:        o.Image,
+					Command:      o.Command,
+					Env:          env,
+					VolumeMounts: volumeMounts,
+				},
+				{
+					Name:         e2eReportsContainer,
+					Image:        e2eReportsImage,
+					Command:      []string{"sh", "-c", fmt.Sprintf("sleep %d", int(o.Timeout.Seconds())+600)},
+					VolumeMounts: volumeMounts,
+				},
+			},
+			Volumes: []corev1.Volume{
+				{
+					Name: e2eReportsVolume,
+					VolumeSource: corev1.VolumeSource{
+						EmptyDir: &corev1.EmptyDirVolumeSource{},
+					},
+				},
+			},
+		},
+	}
+}
+
+// waitForE2EContainer waits for the end to end test container to terminate returning its exit code
+func waitForE2EContainer(kubeClient kubernetes.Interface, ns string, name string, timeout time.Duration) (int32, error) {
+	var exitCode int32
+	err := wait.PollImmediate(2*time.Second, timeout, func() (bool, error) {
+		pod, err := kubeClient.CoreV1().Pods(ns).Get(name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		if pod.Status.Phase == corev1.PodFailed || pod.Status.Phase == corev1.PodSucceeded {
+			exitCode = 1
+			if pod.Status.Phase == corev1.PodSucceeded {
+				exitCode = 0
+			}
+		}
+		for _, status := range pod.Status.ContainerStatuses {
+			if status.Name == e2eTestContainer && status.State.Terminated != nil {
+				exitCode = status.State.Terminated.ExitCode
+				return true, nil
+			}
+		}
+		return pod.Status.Phase == corev1.PodFailed || pod.Status.Phase == corev1.PodSucceeded, nil
+	})
+	if err != nil {
+		return 0, errors.Wrapf(err, "waiting for the end to end tests in pod %s", name)
+	}
+	return exitCode, nil
+}
+
+// printLogs prints the logs of the end to end test container
+func (o *StepE2ERunOptions) printLogs(kubeClient kubernetes.Interface, ns string, name string) {
+	data, err := kubeClient.CoreV1().Pods(ns).GetLogs(name, &corev1.PodLogOptions{Container: e2eTestContainer}).DoRaw()
+	if err != nil {
+		log.Logger().Warnf("Failed to get the logs of the end to end tests in pod %s: %s", name, err)
+		return
+	}
+	fmt.Fprintln(o.Out, string(data))
+}
+
+// collectReports copies the reports from the pod and stores them in the storage location of the classifier
+func (o *StepE2ERunOptions) collectReports(ns string, name string) error {
+	settings, err := o.TeamSettings()
+	if err != nil {
+		return err
+	}
+	location := settings.StorageLocationOrDefault(o.Classifier)
+	if location.IsEmpty() {
+		log.Logger().Warnf("Not storing the end to end test reports as there is no storage location for %s. Use 'jx edit storage' to configure it", util.ColorInfo(o.Classifier))
+		return nil
+	}
+	dir, err := ioutil.TempDir("", "jx-e2e-reports-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	err = o.RunCommandQuietly("kubectl", "cp", "-c", e2eReportsContainer, fmt.Sprintf("%s/%s:%s", ns, name, o.ReportsDir), dir)
+	if err != nil {
+		return errors.Wrapf(err, "copying the reports from pod %s", name)
+	}
+
+	coll, err := collector.NewCollector(location, o.Git())
+	if err != nil {
+		return errors.Wrapf(err, "failed to create the collector for storage settings %s", location.Description())
+	}
+	storagePath := e2eStoragePath(o.Classifier, os.Getenv("REPO_OWNER"), os.Getenv("REPO_NAME"), builds.GetBranchName(), builds.GetBuildNumber(), o.App)
+	urls, err := coll.CollectFiles([]string{filepath.Join(dir, "*")}, storagePath, dir)
+	if err != nil {
+		return errors.Wrapf(err, "storing the reports in %s", storagePath)
+	}
+	for _, u := range urls {
+		log.Logger().Infof("stored report: %s", util.ColorInfo(u))
+	}
+	return nil
+}
+
+// tearDown deletes the releases and the namespace of the end to end tests
+func (o *StepE2ERunOptions) tearDown(kubeClient kubernetes.Interface, ns string, releases []string) {
+	for i := len(releases) - 1; i >= 0; i-- {
+		err := o.Helm().DeleteRelease(ns, releases[i], true)
+		if err != nil {
+			log.Logger().Warnf("Failed to delete the release %s: %s", releases[i], err)
+		}
+	}
+	err := kubeClient.CoreV1().Namespaces().Delete(ns, &metav1.DeleteOptions{})
+	if err != nil {
+		log.Logger().Warnf("Failed to delete the namespace %s: %s", ns, err)
+		return
+	}
+	log.Logger().Infof("Deleted the namespace %s", util.ColorInfo(ns))
+}
+
+// e2eNamespace returns the name of the ephemeral namespace of the end to end tests of a build
+func e2eNamespace(prefix string, app string, build string, now time.Time) string {
+	if build == "" {
+		build = fmt.Sprintf("%d", now.Unix())
+	}
+	return naming.ToValidNameTruncated(prefix+app+"-"+build, 63)
+}
+
+// e2eStoragePath returns the path the reports of the end to end tests of a build are stored in
+func e2eStoragePath(classifier string, owner string, repo string, branch string, build string, app string) string {
+	if repo == "" {
+		repo = app
+	}
+	answer := []string{"jenkins-x", classifier}
+	for _, path := range []string{owner, repo, branch, build} {
+		if path != "" {
+			answer = append(answer, path)
+		}
+	}
+	return filepath.Join(append(answer, "e2e")...)
+}
+
+// parseE2EDependencies parses the dependencies in the format repo/chart=version
+func parseE2EDependencies(values []string) ([]e2eDependency, error) {
+	answer := []e2eDependency{}
+	for _, value := range values {
+		parts := strings.SplitN(value, "=", 2)
+		dependency := e2eDependency{Chart: strings.TrimSpace(parts[0])}
+		if len(parts) > 1 {
+			dependency.Version = strings.TrimSpace(parts[1])
+		}
+		if dependency.Chart == "" || (len(parts) > 1 && dependency.Version == "") {
+			return nil, util.InvalidOptionf("dependency", value, "the dependency should be in the format repo/chart=version")
+		}
+		answer = append(answer, dependency)
+	}
+	return answer, nil
+}
+
+// parseE2EEnv parses the environment variables in the format NAME=value
+func parseE2EEnv(values []string) ([]corev1.EnvVar, error) {
+	answer := []corev1.EnvVar{}
+	for _, value := range values {
+		parts := strings.SplitN(value, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, util.InvalidOptionf("env", value, "the environment variable should be in the format NAME=value")
+		}
+		answer = append(answer, corev1.EnvVar{Name: parts[0], Value: parts[1]})
+	}
+	return answer, nil
+}
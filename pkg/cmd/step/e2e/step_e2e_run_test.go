@@ -0,0 +1,97 @@
+package e2e
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestParseE2EDependencies(t *testing.T) {
+	t.Parallel()
+	dependencies, err := parseE2EDependencies([]string{"stable/postgresql=6.5.0", "jenkins-x/nexus"})
+	require.NoError(t, err)
+	assert.Equal(t, []e2eDependency{
+		{Chart: "stable/postgresql", Version: "6.5.0"},
+		{Chart: "jenkins-x/nexus"},
+	}, dependencies)
+
+	_, err = parseE2EDependencies([]string{"stable/postgresql="})
+	assert.Error(t, err)
+
+	env, err := parseE2EEnv([]string{"DB_URL=postgres://db:5432/test?sslmode=disable"})
+	require.NoError(t, err)
+	assert.Equal(t, []corev1.EnvVar{{Name: "DB_URL", Value: "postgres://db:5432/test?sslmode=disable"}}, env)
+
+	_, err = parseE2EEnv([]string{"DB_URL"})
+	assert.Error(t, err)
+}
+
+func TestE2ENamespaceAndStoragePath(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "e2e-myapp-12", e2eNamespace("e2e-", "myapp", "12", time.Now()))
+	assert.Equal(t, "e2e-myapp-1573000000", e2eNamespace("e2e-", "myapp", "", time.Unix(1573000000, 0)))
+
+	assert.Equal(t, "jenkins-x/tests/myorg/myrepo/PR-3/12/e2e", e2eStoragePath("tests", "myorg", "myrepo", "PR-3", "12", "myapp"))
+	assert.Equal(t, "jenkins-x/tests/myapp/e2e", e2eStoragePath("tests", "", "", "", "", "myapp"))
+}
+
+func TestCreateE2EPod(t *testing.T) {
+	t.Parallel()
+	o := &StepE2ERunOptions{
+		App:        "myapp",
+		Image:      "gcr.io/myproject/myapp-e2e:0.0.1",
+		Command:    []string{"make", "e2e"},
+		ReportsDir: "/reports",
+		Timeout:    10 * time.Minute,
+	}
+	pod := o.createE2EPod("e2e-myapp-12", []corev1.EnvVar{{Name: "FOO", Value: "bar"}})
+	require.Len(t, pod.Spec.Containers, 2)
+	test := pod.Spec.Containers[0]
+	assert.Equal(t, e2eTestContainer, test.Name)
+	assert.Equal(t, o.Image, test.Image)
+	assert.Equal(t, o.Command, test.Command)
+	assert.Equal(t, []corev1.EnvVar{
+		{Name: "E2E_NAMESPACE", Value: "e2e-myapp-12"},
+		{Name: "APP_NAME", Value: "myapp"},
+		{Name: "APP_URL", Value: "http://myapp.e2e-myapp-12.svc.cluster.local"},
+		{Name: "FOO", Value: "bar"},
+	}, test.Env)
+	assert.Equal(t, "/reports", test.VolumeMounts[0].MountPath)
+	assert.Equal(t, test.VolumeMounts, pod.Spec.Containers[1].VolumeMounts)
+	assert.Equal(t, corev1.RestartPolicyNever, pod.Spec.RestartPolicy)
+}
+
+func TestWaitForE2EContainer(t *testing.T) {
+	t.Parallel()
+	kubeClient := fake.NewSimpleClientset(&corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "myapp-e2e-abcde",
+			Namespace: "e2e-myapp-12",
+		},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodRunning,
+			ContainerStatuses: []corev1.ContainerStatus{
+				{
+					Name: e2eTestContainer,
+					State: corev1.ContainerState{
+						Terminated: &corev1.ContainerStateTerminated{ExitCode: 2},
+					},
+				},
+				{
+					Name: e2eReportsContainer,
+					State: corev1.ContainerState{
+						Running: &corev1.ContainerStateRunning{},
+					},
+				},
+			},
+		},
+	})
+	exitCode, err := waitForE2EContainer(kubeClient, "e2e-myapp-12", "myapp-e2e-abcde", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), exitCode)
+}
@@ -0,0 +1,336 @@
+package env
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/pkg/cmd/opts/step"
+	"github.com/jenkins-x/jx/pkg/cmd/step/pr"
+	"github.com/jenkins-x/jx/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/pkg/gits"
+	"github.com/jenkins-x/jx/pkg/kube"
+	"github.com/jenkins-x/jx/pkg/kube/naming"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// StepEnvPreviewOptions contains the command line flags
+type StepEnvPreviewOptions struct {
+	StepEnvOptions
+
+	Environment string
+	PullRequest string
+	Namespace   string
+	Dir         string
+	Wait        bool
+	Force       bool
+	Vault       bool
+	NoComment   bool
+}
+
+var (
+	stepEnvPreviewLong = templates.LongDesc(`
+		Deploys a Pull Request of an environment git repository into a temporary namespace such as 'env-staging-pr-123'
+		and comments the details on the Pull Request, so that configuration changes to an environment can be validated
+		before they are merged just like the previews of applications.
+
+		The temporary namespace is registered as a preview environment so it is removed by 'jx gc previews' once the
+		Pull Request is closed or merged. It can also be removed at any time via 'jx delete preview'.
+
+		Add this step to the pull request pipeline of the environment repository.
+`)
+
+	stepEnvPreviewExample = templates.Examples(`
+		# deploy the current Pull Request of the environment repository into a temporary namespace
+		jx step env preview
+
+		# deploy Pull Request 123 of the staging environment repository
+		jx step env preview --env staging --pr 123
+`)
+)
+
+// NewCmdStepEnvPreview registers the command
+func NewCmdStepEnvPreview(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := StepEnvPreviewOptions{
+		StepEnvOptions: StepEnvOptions{
+			StepOptions: step.StepOptions{
+				CommonOptions: commonOpts,
+			},
+		},
+	}
+	cmd := &cobra.Command{
+		Use:     "preview",
+		Short:   "Deploys a Pull Request of an environment git repository into a temporary namespace",
+		Long:    stepEnvPreviewLong,
+		Example: stepEnvPreviewExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.Flags().StringVarP(&options.Environment, "env", "e", "", "The name of the environment. Defaults to the environment whose git repository is in the directory")
+	cmd.Flags().StringVarP(&options.PullRequest, "pr", "", "", "The number of the Pull Request. Defaults to $PULL_NUMBER or the $BRANCH_NAME of the pipeline")
+	cmd.Flags().StringVarP(&options.Namespace, "namespace", "n", "", "The temporary namespace to deploy to. Defaults to 'env-<environment>-pr-<number>'")
+	cmd.Flags().StringVarP(&options.Dir, "dir", "d", "", "The directory of the environment git repository")
+	cmd.Flags().BoolVarP(&options.Vault, "vault", "", false, "Environment secrets are stored in vault")
+	cmd.Flags().BoolVarP(&options.NoComment, "no-comment", "", false, "Disables commenting on the Pull Request after the environment is deployed")
+
+	// step helm apply flags
+	cmd.Flags().BoolVarP(&options.Wait, "wait", "", true, "Wait for Kubernetes readiness probe to confirm deployment")
+	cmd.Flags().BoolVarP(&options.Force, "force", "f", true, "Whether to to pass '--force' to helm to help deal with upgrading if a previous deploy failed")
+	return cmd
+}
+
+// Run performs the command
+func (o *StepEnvPreviewOptions) Run() error {
+	var err error
+	if o.Dir == "" {
+		o.Dir, err = os.Getwd()
+		if err != nil {
+			return errors.Wrap(err, "getting the working directory")
+		}
+	}
+	for _, devEnvFile := range []string{filepath.Join(o.Dir, "templates", "dev-env.yaml"), filepath.Join(o.Dir, "env", "templates", "dev-env.yaml")} {
+		exists, err := util.FileExists(devEnvFile)
+		if err != nil {
+			return errors.Wrapf(err, "checking if %s exists", devEnvFile)
+		}
+		if exists {
+			return fmt.Errorf("the development environment cannot be previewed as %s exists", devEnvFile)
+		}
+	}
+
+	prNumber := previewPullRequestNumber(o.PullRequest)
+	if prNumber == "" {
+		return util.MissingOption("pr")
+	}
+
+	jxClient, devNs, err := o.JXClientAndDevNamespace()
+	if err != nil {
+		return err
+	}
+	envMap, envNames, err := kube.GetEnvironments(jxClient, devNs)
+	if err != nil {
+		return errors.Wrap(err, "loading the environments")
+	}
+	env := envMap[o.Environment]
+	if o.Environment == "" {
+		gitInfo, err := o.FindGitInfo(o.Dir)
+		if err != nil {
+			return errors.Wrapf(err, "finding the git repository in %s, use --env to specify the environment", o.Dir)
+		}
+		env = findEnvironmentForRepository(envMap, gitInfo)
+		if env == nil {
+			return fmt.Errorf("no environment uses the git repository %s, use --env to specify the environment", gitInfo.URL)
+		}
+	}
+	if env == nil {
+		return util.InvalidOption("env", o.Environment, envNames)
+	}
+	if env.Spec.Kind != v1.EnvironmentKindTypePermanent {
+		return fmt.Errorf("environment %s is not a permanent environment", env.Name)
+	}
+	if o.Namespace == "" {
+		o.Namespace = previewNamespace(env.Name, prNumber)
+	}
+
+	pullRequest := o.findPullRequest(env, prNumber)
+	err = o.registerPreviewEnvironment(env, prNumber, pullRequest)
+	if err != nil {
+		return err
+	}
+
+	apply := &StepEnvApplyOptions{
+		StepEnvOptions: o.StepEnvOptions,
+		Namespace:      o.Namespace,
+		Dir:            o.Dir,
+		ReleaseName:    o.Namespace,
+		Wait:           o.Wait,
+		Force:          o.Force,
+		Vault:          o.Vault,
+	}
+	err = apply.Run()
+	if err != nil {
+		return errors.Wrapf(err, "deploying Pull Request %s of environment %s to namespace %s", prNumber, env.Name, o.Namespace)
+	}
+	log.Logger().Infof("Pull Request %s of environment %s is deployed in namespace %s", prNumber, util.ColorInfo(env.Name), util.ColorInfo(o.Namespace))
+
+	if o.NoComment {
+		return nil
+	}
+	return o.commentOnPullRequest(env, prNumber)
+}
+
+// findPullRequest returns the Pull Request of the environment repository or nil if it cannot be found
+func (o *StepEnvPreviewOptions) findPullRequest(env *v1.Environment, prNumber string) *gits.GitPullRequest {
+	number, err := strconv.Atoi(prNumber)
+	if err != nil {
+		log.Logger().Warnf("Unable to convert Pull Request %s to a number", prNumber)
+		return nil
+	}
+	gitProvider, gitInfo, err := o.CreateGitProviderForURLWithoutKind(env.Spec.Source.URL)
+	if err != nil {
+		log.Logger().Warnf("Failed to create the git provider for %s: %s", env.Spec.Source.URL, err)
+		return nil
+	}
+	pullRequest, err := gitProvider.GetPullRequest(gitInfo.Organisation, gitInfo, number)
+	if err != nil {
+		log.Logger().Warnf("Failed to find Pull Request %s of %s: %s", prNumber, env.Spec.Source.URL, err)
+		return nil
+	}
+	return pullRequest
+}
+
+// registerPreviewEnvironment creates or updates the preview environment of the temporary namespace so that it gets
+// garbage collected with the other previews once the Pull Request is closed
+func (o *StepEnvPreviewOptions) registerPreviewEnvironment(env *v1.Environment, prNumber string, pullRequest *gits.GitPullRequest) error {
+	jxClient, devNs, err := o.JXClientAndDevNamespace()
+	if err != nil {
+		return err
+	}
+	preview := newEnvironmentPreview(env, o.Namespace, prNumber, pullRequest)
+	environments := jxClient.JenkinsV1().Environments(devNs)
+	existing, err := environments.Get(preview.Name, metav1.GetOptions{})
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return errors.Wrapf(err, "getting the preview environment %s", preview.Name)
+		}
+		_, err = environments.Create(preview)
+		if err != nil {
+			return errors.Wrapf(err, "creating the preview environment %s", preview.Name)
+		}
+		log.Logger().Infof("Created preview environment %s", util.ColorInfo(preview.Name))
+		return nil
+	}
+	existing.Annotations = preview.Annotations
+	existing.Spec = preview.Spec
+	_, err = environments.PatchUpdate(existing)
+	if err != nil {
+		return errors.Wrapf(err, "updating the preview environment %s", preview.Name)
+	}
+	return nil
+}
+
+// commentOnPullRequest adds a comment with the details of the temporary namespace to the Pull Request
+func (o *StepEnvPreviewOptions) commentOnPullRequest(env *v1.Environment, prNumber string) error {
+	gitInfo, err := gits.ParseGitURL(env.Spec.Source.URL)
+	if err != nil {
+		return errors.Wrapf(err, "parsing the git URL %s of environment %s", env.Spec.Source.URL, env.Name)
+	}
+	stepPRCommentOptions := pr.StepPRCommentOptions{
+		Flags: pr.StepPRCommentFlags{
+			Owner:      gitInfo.Organisation,
+			Repository: gitInfo.Name,
+			Comment:    environmentPreviewComment(env, o.Namespace),
+			PR:         prNumber,
+		},
+		StepPROptions: pr.StepPROptions{
+			StepOptions: step.StepOptions{
+				CommonOptions: o.CommonOptions,
+			},
+		},
+	}
+	stepPRCommentOptions.BatchMode = true
+	err = stepPRCommentOptions.Run()
+	if err != nil {
+		log.Logger().Warnf("Failed to comment on the Pull Request with owner %s repo %s: %s", gitInfo.Organisation, gitInfo.Name, err)
+	}
+	return nil
+}
+
+// newEnvironmentPreview creates the preview environment of the temporary namespace of a Pull Request
+func newEnvironmentPreview(env *v1.Environment, ns string, prNumber string, pullRequest *gits.GitPullRequest) *v1.Environment {
+	gitSpec := v1.PreviewGitSpec{
+		ApplicationName: env.Name,
+		Name:            prNumber,
+	}
+	if pullRequest != nil {
+		gitSpec.URL = pullRequest.URL
+		gitSpec.Title = pullRequest.Title
+		gitSpec.Description = pullRequest.Body
+		if pullRequest.Author != nil {
+			gitSpec.User = v1.UserSpec{
+				Username: pullRequest.Author.Login,
+				Name:     pullRequest.Author.Name,
+				LinkURL:  pullRequest.Author.URL,
+				ImageURL: pullRequest.Author.AvatarURL,
+			}
+		}
+	}
+	return &v1.Environment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: ns,
+			Annotations: map[string]string{
+				kube.AnnotationReleaseName: ns,
+			},
+		},
+		Spec: v1.EnvironmentSpec{
+			Namespace:         ns,
+			Label:             fmt.Sprintf("%s PR-%s", env.Spec.Label, prNumber),
+			Kind:              v1.EnvironmentKindTypePreview,
+			PromotionStrategy: v1.PromotionStrategyTypeNever,
+			PullRequestURL:    gitSpec.URL,
+			Order:             999,
+			Source: v1.EnvironmentRepository{
+				Kind: v1.EnvironmentRepositoryTypeGit,
+				URL:  env.Spec.Source.URL,
+				Ref:  env.Spec.Source.Ref,
+			},
+			PreviewGitSpec: gitSpec,
+		},
+	}
+}
+
+// environmentPreviewComment returns the Pull Request comment describing the temporary namespace
+func environmentPreviewComment(env *v1.Environment, ns string) string {
+	return fmt.Sprintf(":construction: The changes to environment **%s** are deployed in the temporary namespace **%s** so they can be validated before merging.\n\n"+
+		"The namespace is removed once this Pull Request is closed or via `jx delete preview %s`", env.Name, ns, ns)
+}
+
+// previewNamespace returns the temporary namespace of the Pull Request of the environment
+func previewNamespace(envName string, prNumber string) string {
+	return naming.ToValidName(fmt.Sprintf("env-%s-pr-%s", envName, prNumber))
+}
+
+// previewPullRequestNumber returns the number of the Pull Request defaulting it from the pipeline
+func previewPullRequestNumber(prNumber string) string {
+	if prNumber == "" {
+		prNumber = os.Getenv("PULL_NUMBER")
+	}
+	if prNumber == "" {
+		branch := os.Getenv(util.EnvVarBranchName)
+		if strings.HasPrefix(branch, "PR-") {
+			prNumber = branch
+		}
+	}
+	return strings.TrimPrefix(prNumber, "PR-")
+}
+
+// findEnvironmentForRepository returns the permanent environment whose source is the git repository or nil
+func findEnvironmentForRepository(envMap map[string]*v1.Environment, gitInfo *gits.GitRepository) *v1.Environment {
+	for _, env := range envMap {
+		if env.Spec.Kind != v1.EnvironmentKindTypePermanent || env.Spec.Source.URL == "" {
+			continue
+		}
+		envGitInfo, err := gits.ParseGitURL(env.Spec.Source.URL)
+		if err != nil {
+			continue
+		}
+		if strings.EqualFold(envGitInfo.Host, gitInfo.Host) && strings.EqualFold(envGitInfo.Organisation, gitInfo.Organisation) && envGitInfo.Name == gitInfo.Name {
+			return env
+		}
+	}
+	return nil
+}
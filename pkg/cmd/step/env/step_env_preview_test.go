@@ -0,0 +1,65 @@
+package env
+
+import (
+	"testing"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/gits"
+	"github.com/jenkins-x/jx/pkg/kube"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreviewNamespace(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "env-staging-pr-123", previewNamespace("staging", "123"))
+	assert.Equal(t, "123", previewPullRequestNumber("PR-123"))
+	assert.Equal(t, "45", previewPullRequestNumber("45"))
+}
+
+func TestFindEnvironmentForRepository(t *testing.T) {
+	t.Parallel()
+	staging := kube.NewPermanentEnvironmentWithGit("staging", "https://github.com/myorg/environment-mycluster-staging.git")
+	production := kube.NewPermanentEnvironmentWithGit("production", "https://github.com/myorg/environment-mycluster-production.git")
+	preview := kube.NewPreviewEnvironment("myorg-myapp-pr-1")
+	preview.Spec.Source.URL = "https://github.com/myorg/environment-mycluster-staging.git"
+	envMap := map[string]*v1.Environment{
+		staging.Name:    staging,
+		production.Name: production,
+		preview.Name:    preview,
+	}
+
+	gitInfo, err := gits.ParseGitURL("git@github.com:myorg/environment-mycluster-staging.git")
+	require.NoError(t, err)
+	assert.Equal(t, staging, findEnvironmentForRepository(envMap, gitInfo))
+
+	gitInfo, err = gits.ParseGitURL("https://github.com/myorg/myapp.git")
+	require.NoError(t, err)
+	assert.Nil(t, findEnvironmentForRepository(envMap, gitInfo))
+}
+
+func TestNewEnvironmentPreview(t *testing.T) {
+	t.Parallel()
+	staging := kube.NewPermanentEnvironmentWithGit("staging", "https://github.com/myorg/environment-mycluster-staging.git")
+	pullRequest := &gits.GitPullRequest{
+		URL:   "https://github.com/myorg/environment-mycluster-staging/pull/123",
+		Title: "scale up",
+		Author: &gits.GitUser{
+			Login: "jstrachan",
+		},
+	}
+
+	preview := newEnvironmentPreview(staging, "env-staging-pr-123", "123", pullRequest)
+	assert.Equal(t, "env-staging-pr-123", preview.Name)
+	assert.Equal(t, "env-staging-pr-123", kube.GetPreviewEnvironmentReleaseName(preview))
+	assert.Equal(t, v1.EnvironmentKindTypePreview, preview.Spec.Kind)
+	assert.Equal(t, "env-staging-pr-123", preview.Spec.Namespace)
+	assert.Equal(t, staging.Spec.Source.URL, preview.Spec.Source.URL)
+	assert.Equal(t, "123", preview.Spec.PreviewGitSpec.Name)
+	assert.Equal(t, pullRequest.URL, preview.Spec.PullRequestURL)
+	assert.Equal(t, "jstrachan", preview.Spec.PreviewGitSpec.User.Username)
+
+	preview = newEnvironmentPreview(staging, "env-staging-pr-124", "124", nil)
+	assert.Equal(t, "Staging PR-124", preview.Spec.Label)
+	assert.Empty(t, preview.Spec.PullRequestURL)
+}
@@ -2,15 +2,19 @@ package helm
 
 import (
 	"fmt"
+	"io/ioutil"
 	"path/filepath"
+	"strings"
 	"text/template"
 
 	"github.com/jenkins-x/jx/pkg/cmd/opts/step"
 
 	"github.com/ghodss/yaml"
 	"github.com/jenkins-x/jx/pkg/config"
+	"github.com/jenkins-x/jx/pkg/secreturl"
 	"github.com/jenkins-x/jx/pkg/versionstream"
 	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/helm/pkg/chartutil"
 
 	"github.com/jenkins-x/jx/pkg/cmd/helper"
@@ -68,6 +72,7 @@ func NewCmdStepHelm(commonOpts *opts.CommonOptions) *cobra.Command {
 	cmd.AddCommand(NewCmdStepHelmInstall(commonOpts))
 	cmd.AddCommand(NewCmdStepHelmList(commonOpts))
 	cmd.AddCommand(NewCmdStepHelmRelease(commonOpts))
+	cmd.AddCommand(NewCmdStepHelmValues(commonOpts))
 	cmd.AddCommand(NewCmdStepHelmVersion(commonOpts))
 	return cmd
 }
@@ -319,8 +324,9 @@ func (o *StepHelmOptions) replaceMissingVersionsFromVersionStream(requirementsCo
 	return nil
 }
 
-func (o *StepHelmOptions) createFuncMap(requirementsConfig *config.RequirementsConfig) (template.FuncMap, error) {
+func (o *StepHelmOptions) createFuncMap(requirementsConfig *config.RequirementsConfig, secretURLClient secreturl.Client) (template.FuncMap, error) {
 	funcMap := helm.NewFunctionMap()
+	helm.AddSecretURLFunctions(funcMap, secretURLClient)
 	resolver, err := o.getOrCreateVersionResolver(requirementsConfig)
 
 	if err != nil {
@@ -329,61 +335,160 @@ func (o *StepHelmOptions) createFuncMap(requirementsConfig *config.RequirementsC
 
 	// represents the helm template function
 	// which can be used like: `{{ versionStream "chart" "foo/bar" }}
-	funcMap["versionStream"] = func(kindString, name string) string {
-		kind := versionstream.VersionKind(kindString)
-		version, err := resolver.StableVersionNumber(kind, name)
-		if err != nil {
-			log.Logger().Errorf("failed to find %s version for %s in the version stream due to: %s\n", kindString, name, err.Error())
-		}
-		return version
-	}
+	helm.AddVersionStreamFunctions(funcMap, resolver)
 	return funcMap, nil
 }
 
-func (o *StepHelmOptions) overwriteProviderValues(requirements *config.RequirementsConfig, requirementsFileName string, valuesData []byte, params chartutil.Values, providersValuesDir string) ([]byte, error) {
+func (o *StepHelmOptions) overwriteProviderValues(requirements *config.RequirementsConfig, requirementsFileName string, valuesData []byte, params chartutil.Values, providersValuesDir string, secretURLClient secreturl.Client) ([]byte, error) {
+	overrideData, _, err := o.providerValues(requirements, requirementsFileName, params, providersValuesDir, secretURLClient)
+	if err != nil {
+		return valuesData, err
+	}
+	if len(overrideData) == 0 {
+		return valuesData, nil
+	}
+
+	// now lets apply the overrides
+	values, err := helm.LoadValues(valuesData)
+	if err != nil {
+		return valuesData, errors.Wrapf(err, "failed to unmarshal the default helm values")
+	}
+
+	overrides, err := helm.LoadValues(overrideData)
+	if err != nil {
+		return valuesData, errors.Wrapf(err, "failed to unmarshal the default helm values")
+	}
+
+	util.CombineMapTrees(values, overrides)
+
+	data, err := yaml.Marshal(values)
+	return data, err
+}
+
+// providerValues returns the values of the kubernetes provider of the cluster and the file they were loaded from
+func (o *StepHelmOptions) providerValues(requirements *config.RequirementsConfig, requirementsFileName string, params chartutil.Values, providersValuesDir string, secretURLClient secreturl.Client) ([]byte, string, error) {
 	provider := requirements.Cluster.Provider
 	if provider == "" {
 		log.Logger().Warnf("No provider in the requirements file %s\n", requirementsFileName)
-		return valuesData, nil
+		return nil, "", nil
 	}
 	valuesTmplYamlFile := filepath.Join(providersValuesDir, provider, "values.tmpl.yaml")
 	exists, err := util.FileExists(valuesTmplYamlFile)
 	if err != nil {
-		return valuesData, errors.Wrapf(err, "failed to check if file exists: %s", valuesTmplYamlFile)
+		return nil, "", errors.Wrapf(err, "failed to check if file exists: %s", valuesTmplYamlFile)
 	}
-	log.Logger().Infof("Applying the kubernetes overrides at %s\n", util.ColorInfo(valuesTmplYamlFile))
-
 	if !exists {
-		log.Logger().Warnf("No provider specific values overrides exist in file %s\n", valuesTmplYamlFile)
-		return valuesData, nil
-
+		if !useLocalClusterValues(requirements) {
+			log.Logger().Warnf("No provider specific values overrides exist in file %s\n", valuesTmplYamlFile)
+			return nil, "", nil
+		}
+		log.Logger().Infof("Applying the lightweight overrides of the local %s cluster\n", util.ColorInfo(provider))
+		return []byte(config.LocalClusterValues), "local " + provider + " cluster", nil
 	}
-	funcMap, err := o.createFuncMap(requirements)
+	log.Logger().Infof("Applying the kubernetes overrides at %s\n", util.ColorInfo(valuesTmplYamlFile))
+
+	funcMap, err := o.createFuncMap(requirements, secretURLClient)
 	if err != nil {
-		return valuesData, err
+		return nil, "", err
 	}
 
 	overrideData, err := helm.ReadValuesYamlFileTemplateOutput(valuesTmplYamlFile, params, funcMap, requirements)
 	if err != nil {
-		return valuesData, errors.Wrapf(err, "failed to load provider specific helm value overrides %s", valuesTmplYamlFile)
-	}
-	if len(overrideData) == 0 {
-		return valuesData, nil
+		return nil, "", errors.Wrapf(err, "failed to load provider specific helm value overrides %s", valuesTmplYamlFile)
 	}
+	return overrideData, valuesTmplYamlFile, nil
+}
 
-	// now lets apply the overrides
-	values, err := helm.LoadValues(valuesData)
+// valuesLayers generates the base, environment and cluster layers of the values of the chart in the directory. The
+// base layer is the values.yaml or values.tmpl.yaml tree of the chart, the environment layer is the
+// 'values.<environment>.tmpl.yaml' or 'values.<environment>.yaml' file of the chart and the cluster layer is the
+// values of the kubernetes provider in the provider values directory
+func (o *StepHelmOptions) valuesLayers(requirements *config.RequirementsConfig, requirementsFileName string, dir string, environment string,
+	providerValuesDir string, secretURLClient secreturl.Client) ([]helm.ValuesLayer, chartutil.Values, error) {
+	funcMap, err := o.createFuncMap(requirements, secretURLClient)
 	if err != nil {
-		return valuesData, errors.Wrapf(err, "failed to unmarshal the default helm values")
+		return nil, nil, err
+	}
+	baseData, params, err := helm.GenerateValues(requirements, funcMap, dir, nil, true, secretURLClient)
+	if err != nil {
+		return nil, params, errors.Wrapf(err, "generating values.yaml for tree from %s", dir)
+	}
+	baseValues, err := helm.LoadValues(baseData)
+	if err != nil {
+		return nil, params, errors.Wrapf(err, "failed to parse the values generated from %s", dir)
+	}
+	layers := []helm.ValuesLayer{
+		{Name: helm.ValuesLayerBase, Values: baseValues},
 	}
 
-	overrides, err := helm.LoadValues(overrideData)
+	envFile, err := helm.EnvironmentValuesFile(dir, environment)
 	if err != nil {
-		return valuesData, errors.Wrapf(err, "failed to unmarshal the default helm values")
+		return nil, params, err
+	}
+	if envFile != "" {
+		var envData []byte
+		if strings.HasSuffix(envFile, ".tmpl.yaml") {
+			envData, err = helm.ReadValuesYamlFileTemplateOutput(envFile, params, funcMap, requirements)
+		} else {
+			envData, err = ioutil.ReadFile(envFile)
+		}
+		if err != nil {
+			return nil, params, errors.Wrapf(err, "failed to load the values of environment %s from %s", environment, envFile)
+		}
+		if secretURLClient != nil {
+			text, err := secretURLClient.ReplaceURIs(string(envData))
+			if err != nil {
+				return nil, params, errors.Wrapf(err, "failed to replace the secret URLs of %s", envFile)
+			}
+			envData = []byte(text)
+		}
+		envValues, err := helm.LoadValues(envData)
+		if err != nil {
+			return nil, params, errors.Wrapf(err, "failed to parse the values of environment %s from %s", environment, envFile)
+		}
+		layers = append(layers, helm.ValuesLayer{Name: helm.ValuesLayerEnvironment, Source: filepath.Base(envFile), Values: envValues})
 	}
 
-	util.CombineMapTrees(values, overrides)
+	if providerValuesDir != "" {
+		clusterData, source, err := o.providerValues(requirements, requirementsFileName, params, providerValuesDir, secretURLClient)
+		if err != nil {
+			return nil, params, errors.Wrapf(err, "failed to load the provider values in dir: %s", providerValuesDir)
+		}
+		if len(clusterData) > 0 {
+			clusterValues, err := helm.LoadValues(clusterData)
+			if err != nil {
+				return nil, params, errors.Wrapf(err, "failed to parse the provider values of %s", source)
+			}
+			layers = append(layers, helm.ValuesLayer{Name: helm.ValuesLayerCluster, Source: source, Values: clusterValues})
+		}
+	}
+	return layers, params, nil
+}
 
-	data, err := yaml.Marshal(values)
-	return data, err
+// environmentName returns the name of the environment whose namespace is the namespace or an empty string if there is
+// none
+func (o *StepHelmOptions) environmentName(ns string) string {
+	jxClient, devNs, err := o.JXClientAndDevNamespace()
+	if err != nil {
+		log.Logger().Debugf("failed to create the jx client to find the environment of namespace %s: %s", ns, err)
+		return ""
+	}
+	envs, err := jxClient.JenkinsV1().Environments(devNs).List(metav1.ListOptions{})
+	if err != nil {
+		log.Logger().Debugf("failed to list the environments in namespace %s: %s", devNs, err)
+		return ""
+	}
+	for _, env := range envs.Items {
+		if env.Spec.Namespace == ns {
+			return env.Name
+		}
+	}
+	return ""
+}
+
+// useLocalClusterValues returns true if the lightweight values of a local cluster should be used when the boot
+// configuration has no values for the kubernetes provider
+func useLocalClusterValues(requirements *config.RequirementsConfig) bool {
+	local := requirements.Cluster.LocalConfig
+	return local != nil && local.Resources == config.LocalResourcesLightweight
 }
@@ -6,28 +6,38 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/jenkins-x/jx/pkg/cmd/opts/step"
 	"github.com/jenkins-x/jx/pkg/platform"
 
+	"github.com/ghodss/yaml"
 	"github.com/google/uuid"
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/apps"
+	"github.com/jenkins-x/jx/pkg/audit"
 	"github.com/jenkins-x/jx/pkg/cmd/helper"
 	"github.com/jenkins-x/jx/pkg/cmd/opts"
 	"github.com/jenkins-x/jx/pkg/cmd/templates"
 	"github.com/jenkins-x/jx/pkg/config"
 	"github.com/jenkins-x/jx/pkg/gits"
+	"github.com/jenkins-x/jx/pkg/health"
 	"github.com/jenkins-x/jx/pkg/helm"
 	configio "github.com/jenkins-x/jx/pkg/io"
 	"github.com/jenkins-x/jx/pkg/io/secrets"
 	"github.com/jenkins-x/jx/pkg/kube"
 	"github.com/jenkins-x/jx/pkg/kube/naming"
 	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/observability"
+	"github.com/jenkins-x/jx/pkg/policies"
 	"github.com/jenkins-x/jx/pkg/secreturl/fakevault"
 	"github.com/jenkins-x/jx/pkg/util"
 	"github.com/jenkins-x/jx/pkg/vault"
 	"github.com/mholt/archiver"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
 )
 
 // StepHelmApplyOptions contains the command line flags
@@ -44,6 +54,16 @@ type StepHelmApplyOptions struct {
 	NoVault            bool
 	NoMasking          bool
 	ProviderValuesDir  string
+	NoAppHooks         bool
+	AppHookTimeout     time.Duration
+	NoPolicies         bool
+	PolicyDir          string
+	ServerSide         bool
+	FieldManager       string
+	ForceConflicts     bool
+	ForceAll           bool
+	HealthTimeout      time.Duration
+	Environment        string
 }
 
 var (
@@ -51,12 +71,45 @@ var (
 		Applies the helm chart in a given directory.
 
 		This step is usually used to apply any GitOps promotion changes into a Staging or Production cluster.
+
+		Any pre-install hooks declared in the App resources of the apps in the chart are run before the chart is applied
+		and any post-install hooks are run afterwards. Hooks which have already succeeded for the version of an App are
+		not run again. The results of the hooks are recorded on the status of the App resources.
+
+		If the git repository of the chart or the dev environment repository contains a 'policies' directory the rendered
+		manifests are verified against its OPA/Rego policies before the chart is applied. See 'jx step verify policies'.
+
+		With --server-side, or 'apply.serverSide' in the 'jx-requirements.yml', the rendered manifests are applied with
+		Kubernetes server-side apply using a stable field manager. The resources applied are recorded in an inventory
+		ConfigMap so that the resources removed from the chart are pruned by the next apply. If a field was changed by
+		another field manager, such as a manual 'kubectl edit', the apply fails listing the conflicting fields unless
+		--force-conflicts is specified.
+
+		The chart version and a hash of the values and templates applied are recorded for each release. If neither changed
+		since the last apply the chart is not applied again which speeds up boot and environment pipelines with small
+		changes. Use --force-all to apply the chart anyway, e.g. after changing secrets in Vault.
+
+		With --wait the step waits for the resources of the chart to become ready: Deployments, StatefulSets and
+		DaemonSets rolled out, Jobs completed, CustomResourceDefinitions established and Ingresses assigned an address.
+		If they are not ready within the --health-timeout the step fails naming the resources which are not ready.
+
+		The values of the chart are merged from layers where each layer overrides the previous ones: the base values of
+		the values.yaml or values.tmpl.yaml tree of the chart, the values of the environment in the
+		'values.<environment>.tmpl.yaml' or 'values.<environment>.yaml' file of the chart, the values of the kubernetes
+		provider in the --provider-values-dir and finally the secrets files. Use 'jx step helm values --show-effective'
+		to see the merged values and the layer of each value.
 `)
 
 	StepHelmApplyExample = templates.Examples(`
 		# apply the chart in the env folder to namespace jx-staging 
 		jx step helm apply --dir env --namespace jx-staging
 
+		# apply the chart with server-side apply pruning the resources removed from the chart
+		jx step helm apply --dir env --namespace jx-staging --server-side
+
+		# apply the chart even if it has not changed since the last apply
+		jx step helm apply --dir env --namespace jx-staging --force-all
+
 `)
 
 	defaultValueFileNames = []string{"values.yaml", "myvalues.yaml", helm.SecretsFileName, filepath.Join("env", helm.SecretsFileName)}
@@ -95,6 +148,16 @@ func NewCmdStepHelmApply(commonOpts *opts.CommonOptions) *cobra.Command {
 	cmd.Flags().BoolVarP(&options.NoVault, "no-vault", "", false, "Disables loading secrets from Vault. e.g. if bootstrapping core services like Ingress before we have a Vault")
 	cmd.Flags().BoolVarP(&options.NoMasking, "no-masking", "", false, "The effective 'values.yaml' file is output to the console with parameters masked. Enabling this flag will show the unmasked secrets in the console output")
 	cmd.Flags().StringVarP(&options.ProviderValuesDir, "provider-values-dir", "", "", "The optional directory of kubernetes provider specific override values.tmpl.yaml files a kubernetes provider specific folder")
+	cmd.Flags().BoolVarP(&options.NoAppHooks, "no-app-hooks", "", false, "Disables running the pre-install and post-install hooks of apps")
+	cmd.Flags().DurationVarP(&options.AppHookTimeout, "app-hook-timeout", "", 10*time.Minute, "The default time to wait for each app hook to complete")
+	cmd.Flags().BoolVarP(&options.NoPolicies, "no-policies", "", false, "Disables verifying the rendered manifests against the policies of the team")
+	cmd.Flags().StringVarP(&options.PolicyDir, "policy-dir", "", "", "The directory of the policies to verify the rendered manifests against. Defaults to the 'policies' directory of the git repository or the dev environment repository")
+	cmd.Flags().BoolVarP(&options.ServerSide, "server-side", "", false, "Applies the manifests with Kubernetes server-side apply and prunes the resources removed from the chart. Defaults to 'apply.serverSide' in the 'jx-requirements.yml'")
+	cmd.Flags().StringVarP(&options.FieldManager, "field-manager", "", "", "The field manager of server-side apply. Defaults to 'apply.fieldManager' in the 'jx-requirements.yml' or "+helm.DefaultFieldManager)
+	cmd.Flags().BoolVarP(&options.ForceConflicts, "force-conflicts", "", false, "Takes the ownership of the fields changed by other field managers on server-side apply instead of failing")
+	cmd.Flags().BoolVarP(&options.ForceAll, "force-all", "", false, "Applies the chart even if its version, values and templates have not changed since the last apply")
+	cmd.Flags().DurationVarP(&options.HealthTimeout, "health-timeout", "", 10*time.Minute, "The maximum time to wait for the resources of the chart to become ready when using --wait")
+	cmd.Flags().StringVarP(&options.Environment, "environment", "e", "", "The name of the environment whose values layer is used. Defaults to the environment of the namespace")
 
 	return cmd
 }
@@ -256,20 +319,26 @@ func (o *StepHelmApplyOptions) Run() error {
 
 	DefaultEnvironments(requirements, devGitInfo)
 
-	funcMap, err := o.createFuncMap(requirements)
-	if err != nil {
-		return err
+	environment := o.Environment
+	if environment == "" {
+		environment = o.environmentName(ns)
 	}
-	chartValues, params, err := helm.GenerateValues(requirements, funcMap, dir, nil, true, secretURLClient)
+	layers, params, err := o.valuesLayers(requirements, requirementsFileName, dir, environment, o.ProviderValuesDir, secretURLClient)
 	if err != nil {
-		return errors.Wrapf(err, "generating values.yaml for tree from %s", dir)
+		return err
 	}
-	if o.ProviderValuesDir != "" {
-		chartValues, err = o.overwriteProviderValues(requirements, requirementsFileName, chartValues, params, o.ProviderValuesDir)
+	if requirements.Observability && ns == devNs {
+		layers = append([]helm.ValuesLayer{{Name: helm.ValuesLayerDefaults, Values: observability.DefaultValues(releaseName)}}, layers...)
+		err = o.addObservability(requirements, dir, devNs)
 		if err != nil {
-			return errors.Wrapf(err, "failed to overwrite provider values in dir: %s", dir)
+			return errors.Wrapf(err, "adding the observability stack to the chart in %s", dir)
 		}
 	}
+	values, _ := helm.MergeValuesLayers(layers)
+	chartValues, err := yaml.Marshal(values)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal the values of the chart in %s", dir)
+	}
 
 	chartValuesFile := filepath.Join(dir, helm.ValuesFileName)
 	err = ioutil.WriteFile(chartValuesFile, chartValues, 0755)
@@ -302,6 +371,16 @@ func (o *StepHelmApplyOptions) Run() error {
 		}
 	}
 
+	releaseState, unchanged, err := o.detectChanges(kubeClient, dir, ns, releaseName, valueFiles)
+	if err != nil {
+		return err
+	}
+	if unchanged {
+		log.Logger().Infof("Skipping release %s in namespace %s as its chart version %s, values and templates have not changed since the last apply",
+			info(releaseName), info(ns), info(releaseState.ChartVersion))
+		return nil
+	}
+
 	_, err = o.HelmInitDependencyBuild(dir, o.DefaultReleaseCharts(), valueFiles)
 	if err != nil {
 		return err
@@ -356,6 +435,38 @@ func (o *StepHelmApplyOptions) Run() error {
 		return errors.Wrap(err, "applying chart overrides")
 	}
 
+	if !o.NoPolicies {
+		err = o.verifyPolicies(path, dir, releaseName, ns, valueFiles)
+		if err != nil {
+			return err
+		}
+	}
+
+	var hookRunner *apps.AppHookRunner
+	var preInstallStatuses map[string][]v1.AppHookStatus
+	if !o.NoAppHooks {
+		jxClient, _, err := o.JXClient()
+		if err != nil {
+			return errors.Wrap(err, "creating the jx client to run app hooks")
+		}
+		hookRunner = &apps.AppHookRunner{
+			KubeClient:    kubeClient,
+			JxClient:      jxClient,
+			Namespace:     ns,
+			Timeout:       o.AppHookTimeout,
+			IOFileHandles: o.GetIOFileHandles(),
+		}
+		preInstallStatuses, err = o.runAppPreInstallHooks(hookRunner, dir)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = o.configureServerSideApply(requirements.Apply, kubeClient, ns)
+	if err != nil {
+		return err
+	}
+
 	helmOptions := helm.InstallChartOptions{
 		Chart:       chartName,
 		ReleaseName: releaseName,
@@ -378,9 +489,243 @@ func (o *StepHelmApplyOptions) Run() error {
 	if err != nil {
 		return errors.Wrapf(err, "upgrading helm chart '%s'", chartName)
 	}
+	details := map[string]string{
+		"release": releaseName,
+	}
+	if devGitInfo != nil {
+		details["repository"] = devGitInfo.URL
+	}
+	audit.Record(v1.AuditActionEnvironmentApplied, releaseName, ns, fmt.Sprintf("applied the helm chart in %s", filepath.Base(path)), details)
+
+	if o.Wait {
+		err = o.waitForResources(kubeClient, dir, releaseName, ns, valueFiles)
+		if err != nil {
+			return errors.Wrapf(err, "waiting for the resources of release %s to become ready", releaseName)
+		}
+	}
+
+	if releaseState != nil {
+		err = helm.SaveReleaseState(kubeClient, ns, releaseName, releaseState)
+		if err != nil {
+			log.Logger().Warnf("Failed to record the state of release %s so it will be applied again next time: %s", releaseName, err)
+		}
+	}
+
+	if hookRunner != nil {
+		for name, statuses := range preInstallStatuses {
+			err = apps.RecordHookStatuses(hookRunner.JxClient, ns, name, statuses)
+			if err != nil {
+				log.Logger().Warnf("Failed to record the pre-install hook status of app %s: %s", name, err)
+			}
+		}
+		err = hookRunner.RunPostInstallHooks()
+		if err != nil {
+			return errors.Wrap(err, "running app post-install hooks")
+		}
+	}
+	return nil
+}
+
+// waitForResources renders the chart to find its resources then waits for them to become ready
+func (o *StepHelmApplyOptions) waitForResources(kubeClient kubernetes.Interface, dir string, releaseName string, ns string, valueFiles []string) error {
+	outputDir, err := ioutil.TempDir("", "jx-helm-apply-health-")
+	if err != nil {
+		return errors.Wrap(err, "creating a temporary directory to render the chart")
+	}
+	defer os.RemoveAll(outputDir)
+	err = o.Helm().Template(dir, releaseName, ns, outputDir, false, nil, valueFiles)
+	if err != nil {
+		return errors.Wrapf(err, "rendering the chart %s to find its resources", dir)
+	}
+	entries, err := helm.BuildInventory(outputDir, ns)
+	if err != nil {
+		return err
+	}
+	refs := []health.ResourceRef{}
+	for _, e := range entries {
+		refs = append(refs, health.ResourceRef{Kind: e.Kind, Namespace: e.Namespace, Name: e.Name})
+	}
+	apiClient, err := o.ApiExtensionsClient()
+	if err != nil {
+		return err
+	}
+	checker := &health.ResourceChecker{
+		KubeClient:          kubeClient,
+		APIExtensionsClient: apiClient,
+	}
+	log.Logger().Infof("Waiting up to %s for the %d resources of release %s to become ready", o.HealthTimeout, len(refs), util.ColorInfo(releaseName))
+	return checker.WaitForResources(refs, o.HealthTimeout)
+}
+
+// detectChanges returns the chart version and hash of the values and templates of the chart and whether they are the
+// same as the last apply of the release
+// addObservability adds the charts of the observability stack to the requirements of the chart and the ConfigMaps
+// of the dashboards to its templates
+func (o *StepHelmApplyOptions) addObservability(requirements *config.RequirementsConfig, dir string, devNs string) error {
+	fileName := filepath.Join(dir, helm.RequirementsFileName)
+	chartRequirements, err := helm.LoadRequirementsFile(fileName)
+	if err != nil {
+		return errors.Wrapf(err, "loading %s", fileName)
+	}
+	if observability.AddDependencies(chartRequirements) {
+		err = helm.SaveFile(fileName, chartRequirements)
+		if err != nil {
+			return errors.Wrapf(err, "saving %s", fileName)
+		}
+	}
+
+	dashboards := observability.Dashboards(devNs, observability.EnvironmentNamespaces(requirements, devNs))
+	configMaps, err := observability.DashboardConfigMaps(dashboards)
+	if err != nil {
+		return err
+	}
+	resources := []interface{}{}
+	for _, cm := range configMaps {
+		resources = append(resources, cm)
+	}
+	err = observability.WriteTemplate(dir, observability.DashboardsFileName, resources)
+	if err != nil {
+		return err
+	}
+	log.Logger().Infof("Added the observability stack with %d dashboards", len(dashboards))
+	return nil
+}
+
+func (o *StepHelmApplyOptions) detectChanges(kubeClient kubernetes.Interface, dir string, ns string, releaseName string, valueFiles []string) (*helm.ReleaseState, bool, error) {
+	_, version, err := helm.LoadChartNameAndVersion(filepath.Join(dir, helm.ChartFileName))
+	if err != nil {
+		return nil, false, errors.Wrapf(err, "failed to load the chart version in %s", dir)
+	}
+	hash, err := helm.HashChartInputs(dir, valueFiles)
+	if err != nil {
+		return nil, false, errors.Wrapf(err, "failed to hash the values and templates of the chart in %s", dir)
+	}
+	current := &helm.ReleaseState{
+		ChartVersion: version,
+		ValuesHash:   hash,
+	}
+	if o.ForceAll {
+		return current, false, nil
+	}
+	previous, err := helm.LoadReleaseState(kubeClient, ns, releaseName)
+	if err != nil {
+		log.Logger().Warnf("Failed to load the state of release %s so applying it: %s", releaseName, err)
+		return current, false, nil
+	}
+	if !current.Equal(previous) {
+		return current, false, nil
+	}
+	// the release may have been deleted since it was applied
+	err = o.Helm().StatusRelease(ns, releaseName)
+	if err != nil {
+		log.Logger().Debugf("Applying release %s as its status could not be found: %s", releaseName, err)
+		return current, false, nil
+	}
+	return current, true, nil
+}
+
+// configureServerSideApply switches the helmer to apply the rendered manifests with server-side apply if it is enabled
+// by the flags or the requirements
+func (o *StepHelmApplyOptions) configureServerSideApply(applyConfig *config.ApplyConfig, kubeClient kubernetes.Interface, ns string) error {
+	if applyConfig == nil {
+		applyConfig = &config.ApplyConfig{}
+	}
+	if !o.ServerSide && !applyConfig.ServerSide {
+		return nil
+	}
+	var helmTemplate *helm.HelmTemplate
+	switch h := o.Helm().(type) {
+	case *helm.HelmTemplate:
+		helmTemplate = h
+	case *helm.HelmCLI:
+		helmTemplate = helm.NewHelmTemplate(h, "", kubeClient, ns)
+		o.SetHelm(helmTemplate)
+	default:
+		return fmt.Errorf("server-side apply is not supported by the helm client %T", h)
+	}
+	helmTemplate.ServerSideApply = true
+	helmTemplate.FieldManager = o.FieldManager
+	if helmTemplate.FieldManager == "" {
+		helmTemplate.FieldManager = applyConfig.FieldManager
+	}
+	helmTemplate.ForceConflicts = o.ForceConflicts || applyConfig.ForceConflicts
+	log.Logger().Debugf("Applying with server-side apply as field manager %s", util.ColorInfo(helmTemplate.FieldManager))
 	return nil
 }
 
+// verifyPolicies renders the chart and verifies the manifests against the policies of the git repository of the
+// source directory or the dev environment repository
+func (o *StepHelmApplyOptions) verifyPolicies(sourceDir string, dir string, releaseName string, ns string, valueFiles []string) error {
+	policyDir := o.PolicyDir
+	if policyDir == "" {
+		jxClient, devNs, err := o.JXClientAndDevNamespace()
+		if err != nil {
+			return err
+		}
+		var cleanup func()
+		policyDir, cleanup, err = policies.FindPolicyDir(o.Git(), jxClient, devNs, sourceDir)
+		defer cleanup()
+		if err != nil {
+			log.Logger().Warnf("Unable to find the policies to verify the manifests of %s: %s", sourceDir, err.Error())
+			return nil
+		}
+		if policyDir == "" {
+			log.Logger().Debugf("no %s directory found so not verifying the manifests of %s", policies.PoliciesDir, sourceDir)
+			return nil
+		}
+	}
+	outputDir, err := ioutil.TempDir("", "jx-helm-apply-policies-")
+	if err != nil {
+		return errors.Wrap(err, "creating a temporary directory to render the chart")
+	}
+	defer os.RemoveAll(outputDir)
+	err = o.Helm().Template(dir, releaseName, ns, outputDir, false, nil, valueFiles)
+	if err != nil {
+		return errors.Wrapf(err, "rendering the chart %s to verify the policies", dir)
+	}
+
+	verifier := &policies.Verifier{
+		Engine:    policies.DetectEngine(),
+		PolicyDir: policyDir,
+	}
+	log.Logger().Infof("Verifying the manifests of release %s against the policies in %s using %s", util.ColorInfo(releaseName), util.ColorInfo(policyDir), util.ColorInfo(verifier.Engine))
+	result, err := verifier.Verify(outputDir)
+	if err != nil {
+		return errors.Wrapf(err, "verifying the manifests of release %s against the policies", releaseName)
+	}
+	return result.Check()
+}
+
+// runAppPreInstallHooks runs the pre-install hooks of the apps in the chart which have not yet succeeded for the
+// version being applied, returning the statuses of the hooks by App name so they can be recorded once the Apps are
+// installed
+func (o *StepHelmApplyOptions) runAppPreInstallHooks(runner *apps.AppHookRunner, dir string) (map[string][]v1.AppHookStatus, error) {
+	appResources, err := apps.LocateAppResourcesInCharts(o.Helm(), filepath.Join(dir, "charts"))
+	if err != nil {
+		return nil, errors.Wrap(err, "locating the App resources of the charts")
+	}
+	answer := map[string][]v1.AppHookStatus{}
+	for _, app := range appResources {
+		if len(app.Spec.PreInstall) == 0 {
+			continue
+		}
+		existing, err := runner.JxClient.JenkinsV1().Apps(runner.Namespace).Get(app.Name, metav1.GetOptions{})
+		if err == nil {
+			app.Status = existing.Status
+		}
+		statuses, err := runner.RunHooks(app, v1.AppHookTypePreInstall, app.Labels[helm.LabelAppVersion])
+		if err != nil {
+			recordErr := apps.RecordHookStatuses(runner.JxClient, runner.Namespace, app.Name, statuses)
+			if recordErr != nil {
+				log.Logger().Debugf("Unable to record the hook status of %s: %v", app.Name, recordErr)
+			}
+			return nil, err
+		}
+		answer[app.Name] = statuses
+	}
+	return answer, nil
+}
+
 // DefaultEnvironments ensures we have valid values for environment owner and repository names.
 // if none are configured lets default them from smart defaults
 func DefaultEnvironments(c *config.RequirementsConfig, devGitInfo *gits.GitRepository) {
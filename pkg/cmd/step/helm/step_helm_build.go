@@ -108,7 +108,7 @@ func (o *StepHelmBuildOptions) Run() error {
 
 		DefaultEnvironments(requirements, devGitInfo)
 
-		funcMap, err := o.createFuncMap(requirements)
+		funcMap, err := o.createFuncMap(requirements, secretURLClient)
 		if err != nil {
 			return err
 		}
@@ -117,7 +117,7 @@ func (o *StepHelmBuildOptions) Run() error {
 			return errors.Wrapf(err, "generating values.yaml for tree from %s", dir)
 		}
 		if o.ProviderValuesDir != "" {
-			chartValues, err = o.overwriteProviderValues(requirements, requirementsFileName, chartValues, params, o.ProviderValuesDir)
+			chartValues, err = o.overwriteProviderValues(requirements, requirementsFileName, chartValues, params, o.ProviderValuesDir, secretURLClient)
 			if err != nil {
 				return errors.Wrapf(err, "failed to overwrite provider values in dir: %s", dir)
 			}
@@ -1,10 +1,7 @@
 package helm
 
 import (
-	"bufio"
 	"fmt"
-	"io/ioutil"
-	"net/http"
 	"os"
 	"path/filepath"
 
@@ -14,6 +11,7 @@ import (
 
 	"github.com/jenkins-x/jx/pkg/cmd/opts"
 	"github.com/jenkins-x/jx/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/pkg/config"
 	"github.com/jenkins-x/jx/pkg/helm"
 	"github.com/jenkins-x/jx/pkg/kube"
 	"github.com/jenkins-x/jx/pkg/log"
@@ -31,6 +29,10 @@ type StepHelmReleaseOptions struct {
 var (
 	StepHelmReleaseLong = templates.LongDesc(`
 		This pipeline step releases the Helm chart in the current directory
+
+		The chart is published to the chart repository configured by the chartRepository of the jx-requirements.yml file
+		which can be ChartMuseum, a static chart repository in a S3, GCS or Azure bucket, Harbor, Nexus, Artifactory
+		or an OCI registry. Defaults to the ChartMuseum at the cluster.chartRepository URL.
 `)
 
 	StepHelmReleaseExample = templates.Examples(`
@@ -103,15 +105,44 @@ func (o *StepHelmReleaseOptions) Run() error {
 	}
 	defer os.Remove(tarball)
 
-	chartRepo := o.ReleaseChartRepositoryURL()
+	repository := o.ReleaseChartRepository()
+	userName, password, err := o.chartRepositoryCredentials()
+	if err != nil {
+		return err
+	}
+	if repository.Type == config.ChartRepositoryTypeChartMuseum || repository.Type == "" {
+		if userName == "" {
+			return fmt.Errorf("No environment variable $CHARTMUSEUM_CREDS_USR defined")
+		}
+		if password == "" {
+			return fmt.Errorf("No environment variable CHARTMUSEUM_CREDS_PSW defined")
+		}
+	}
+	publisher, err := helm.NewChartPublisher(repository, helm.ChartPublisherOptions{
+		HelmBinary: o.Helm().HelmBinary(),
+		Username:   userName,
+		Password:   password,
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to create the chart publisher")
+	}
+	err = publisher.Publish(tarball)
+	if err != nil {
+		return errors.Wrapf(err, "failed to publish the chart to the %s chart repository", repository.Type)
+	}
+	return nil
+}
 
+// chartRepositoryCredentials returns the credentials of the chart repository from the environment variables or the
+// ChartMuseum or bucketrepo Secret
+func (o *StepHelmReleaseOptions) chartRepositoryCredentials() (string, string, error) {
 	userName := os.Getenv("CHARTMUSEUM_CREDS_USR")
 	password := os.Getenv("CHARTMUSEUM_CREDS_PSW")
 	if userName == "" || password == "" {
 		// lets try load them from the secret directly
 		client, ns, err := o.KubeClientAndNamespace()
 		if err != nil {
-			return errors.Wrap(err, "failed to create the kube client")
+			return "", "", errors.Wrap(err, "failed to create the kube client")
 		}
 		secret, err := client.CoreV1().Secrets(ns).Get(kube.SecretJenkinsChartMuseum, metav1.GetOptions{})
 		if err != nil {
@@ -130,46 +161,5 @@ func (o *StepHelmReleaseOptions) Run() error {
 			}
 		}
 	}
-	if userName == "" {
-		return fmt.Errorf("No environment variable $CHARTMUSEUM_CREDS_USR defined")
-	}
-	if password == "" {
-		return fmt.Errorf("No environment variable CHARTMUSEUM_CREDS_PSW defined")
-	}
-
-	// post the tarball to the chart repository
-	client := http.Client{}
-
-	u := util.UrlJoin(chartRepo, "/api/charts")
-
-	file, err := os.Open(tarball)
-	if err != nil {
-		return errors.Wrapf(err, "failed to open the chart archive '%s'", tarball)
-	}
-	log.Logger().Infof("Uploading chart file %s to %s", util.ColorInfo(tarball), util.ColorInfo(u))
-	req, err := http.NewRequest(http.MethodPost, u, bufio.NewReader(file))
-	if err != nil {
-		return errors.Wrapf(err, "failed to build the chart upload request for endpoint '%s'", u)
-	}
-	req.SetBasicAuth(userName, password)
-	req.Header.Set("Content-Type", "application/gzip")
-	res, err := client.Do(req)
-	if err != nil {
-		if res == nil {
-			return errors.Wrapf(err, "failed to execute the chart upload HTTP request, url: '%s', error: '%v'", u, err)
-		}
-		errRes, _ := ioutil.ReadAll(res.Body)
-		return errors.Wrapf(err, "failed to execute the chart upload HTTP request, url: '%s', status: '%s', response: '%s'", u, res.Status, string(errRes))
-	}
-	body, err := ioutil.ReadAll(res.Body)
-	if err != nil {
-		return errors.Wrap(err, "failed to read the response body of chart upload request")
-	}
-	responseMessage := string(body)
-	statusCode := res.StatusCode
-	log.Logger().Infof("Received %d response: %s", statusCode, responseMessage)
-	if statusCode >= 300 {
-		return fmt.Errorf("Failed to post chart to %s due to response %d: %s", u, statusCode, responseMessage)
-	}
-	return nil
+	return userName, password, nil
 }
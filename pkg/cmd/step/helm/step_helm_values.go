@@ -0,0 +1,192 @@
+package helm
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/jenkins-x/jx/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/pkg/cmd/opts/step"
+	"github.com/jenkins-x/jx/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/pkg/config"
+	"github.com/jenkins-x/jx/pkg/helm"
+	"github.com/jenkins-x/jx/pkg/io/secrets"
+	"github.com/jenkins-x/jx/pkg/kube"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// StepHelmValuesOptions contains the command line flags
+type StepHelmValuesOptions struct {
+	StepHelmOptions
+
+	Namespace         string
+	Environment       string
+	ProviderValuesDir string
+	ShowEffective     bool
+	NoMasking         bool
+	OutputFile        string
+}
+
+const maskedValue = "****"
+
+var (
+	stepHelmValuesLong = templates.LongDesc(`
+		Generates the values of the helm chart in a given directory by merging its values layers in order:
+
+		* base: the values.yaml or values.tmpl.yaml tree of the chart
+		* environment: the 'values.<environment>.tmpl.yaml' or 'values.<environment>.yaml' file of the chart
+		* cluster: the values.tmpl.yaml of the kubernetes provider in the --provider-values-dir
+		* secrets: the secrets files of the chart
+
+		Each layer overrides the values of the previous layers. Use --show-effective to print the merged values with the
+		layer which set each value. The values of the secrets are masked unless --no-masking is specified.
+`)
+
+	stepHelmValuesExample = templates.Examples(`
+		# print the effective values of the chart in the env folder for the staging environment
+		jx step helm values --dir env --environment staging --show-effective
+
+		# generate the values.yaml of the chart in the env folder
+		jx step helm values --dir env --output-file values.yaml
+`)
+)
+
+// NewCmdStepHelmValues creates the command
+func NewCmdStepHelmValues(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := StepHelmValuesOptions{
+		StepHelmOptions: StepHelmOptions{
+			StepOptions: step.StepOptions{
+				CommonOptions: commonOpts,
+			},
+		},
+	}
+	cmd := &cobra.Command{
+		Use:     "values",
+		Short:   "Generates the values of the helm chart in a given directory from its values layers",
+		Long:    stepHelmValuesLong,
+		Example: stepHelmValuesExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	options.addStepHelmFlags(cmd)
+
+	cmd.Flags().StringVarP(&options.Namespace, "namespace", "", "", "The Kubernetes namespace the chart is applied to which is used to find the environment")
+	cmd.Flags().StringVarP(&options.Environment, "environment", "e", "", "The name of the environment whose values layer is used. Defaults to the environment of the namespace")
+	cmd.Flags().StringVarP(&options.ProviderValuesDir, "provider-values-dir", "", "", "The optional directory of kubernetes provider specific override values.tmpl.yaml files a kubernetes provider specific folder")
+	cmd.Flags().BoolVarP(&options.ShowEffective, "show-effective", "", false, "Prints the merged values with the layer which set each value")
+	cmd.Flags().BoolVarP(&options.NoMasking, "no-masking", "", false, "Prints the values of the secrets instead of masking them")
+	cmd.Flags().StringVarP(&options.OutputFile, "output-file", "o", "", "The file to write the merged values to. Defaults to printing them")
+	return cmd
+}
+
+// Run implements this command
+func (o *StepHelmValuesOptions) Run() error {
+	dir := o.Dir
+	if dir == "" {
+		var err error
+		dir, err = os.Getwd()
+		if err != nil {
+			return err
+		}
+	}
+	requirements, requirementsFileName, err := config.LoadRequirementsConfig(dir)
+	if err != nil {
+		return err
+	}
+	secretURLClient, err := o.GetSecretURLClient(secrets.ToSecretsLocation(string(requirements.SecretStorage)))
+	if err != nil {
+		return errors.Wrap(err, "failed to create a Secret URL client")
+	}
+	devGitInfo, err := o.FindGitInfo(dir)
+	if err != nil {
+		log.Logger().Debugf("could not find a git repository in the directory %s: %s", dir, err.Error())
+	}
+	DefaultEnvironments(requirements, devGitInfo)
+
+	environment := o.Environment
+	if environment == "" {
+		ns, err := o.GetDeployNamespace(o.Namespace)
+		if err != nil {
+			return err
+		}
+		environment = o.environmentName(ns)
+	}
+	layers, params, err := o.valuesLayers(requirements, requirementsFileName, dir, environment, o.ProviderValuesDir, secretURLClient)
+	if err != nil {
+		return err
+	}
+	for _, name := range defaultValueFileNames {
+		if filepath.Base(name) != helm.SecretsFileName {
+			continue
+		}
+		file := filepath.Join(dir, name)
+		exists, err := util.FileExists(file)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			continue
+		}
+		secretValues, err := helm.LoadValuesFile(file)
+		if err != nil {
+			return err
+		}
+		layers = append(layers, helm.ValuesLayer{Name: helm.ValuesLayerSecrets, Source: name, Values: secretValues})
+	}
+
+	values, sources := helm.MergeValuesLayers(layers)
+	if !o.NoMasking {
+		maskLayerValues(values, sources, "", helm.ValuesLayerSecrets)
+	}
+	var data []byte
+	if o.ShowEffective {
+		data = helm.EffectiveValuesYAML(values, sources)
+	} else {
+		data, err = yaml.Marshal(values)
+		if err != nil {
+			return errors.Wrap(err, "failed to marshal the values")
+		}
+	}
+	text := string(data)
+	if !o.NoMasking {
+		text = kube.NewLogMaskerFromMap(params.AsMap()).MaskLog(text)
+	}
+	if o.OutputFile != "" {
+		err = ioutil.WriteFile(o.OutputFile, []byte(text), util.DefaultWritePermissions)
+		if err != nil {
+			return errors.Wrapf(err, "failed to write the values to %s", o.OutputFile)
+		}
+		log.Logger().Infof("Wrote the values of the chart in %s to %s", util.ColorInfo(dir), util.ColorInfo(o.OutputFile))
+		return nil
+	}
+	fmt.Fprint(o.Out, text)
+	return nil
+}
+
+// maskLayerValues masks the values set by the layer
+func maskLayerValues(values map[string]interface{}, sources map[string]string, prefix string, layer string) {
+	for k, v := range values {
+		path := k
+		if prefix != "" {
+			path = prefix + "." + k
+		}
+		if m, ok := v.(map[string]interface{}); ok && len(m) > 0 {
+			maskLayerValues(m, sources, path, layer)
+			continue
+		}
+		if strings.HasPrefix(sources[path], layer) {
+			values[k] = maskedValue
+		}
+	}
+}
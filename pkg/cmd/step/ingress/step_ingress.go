@@ -0,0 +1,40 @@
+package ingress
+
+import (
+	"github.com/jenkins-x/jx/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/pkg/cmd/opts/step"
+	"github.com/spf13/cobra"
+)
+
+// StepIngressOptions contains the command line flags
+type StepIngressOptions struct {
+	step.StepOptions
+}
+
+// NewCmdStepIngress Steps a command object for the "step" command
+func NewCmdStepIngress(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &StepIngressOptions{
+		StepOptions: step.StepOptions{
+			CommonOptions: commonOpts,
+		},
+	}
+
+	cmd := &cobra.Command{
+		Use:   "ingress",
+		Short: "ingress [command]",
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.AddCommand(NewCmdStepIngressMigrate(commonOpts))
+	return cmd
+}
+
+// Run implements this command
+func (o *StepIngressOptions) Run() error {
+	return o.Cmd.Help()
+}
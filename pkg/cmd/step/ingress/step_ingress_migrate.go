@@ -0,0 +1,462 @@
+package ingress
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/client/clientset/versioned"
+	"github.com/jenkins-x/jx/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/pkg/cmd/opts/step"
+	"github.com/jenkins-x/jx/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/pkg/cmd/update"
+	"github.com/jenkins-x/jx/pkg/config"
+	"github.com/jenkins-x/jx/pkg/environments"
+	"github.com/jenkins-x/jx/pkg/gits"
+	"github.com/jenkins-x/jx/pkg/helm"
+	"github.com/jenkins-x/jx/pkg/kube"
+	"github.com/jenkins-x/jx/pkg/kube/pki"
+	"github.com/jenkins-x/jx/pkg/kube/services"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/helm/pkg/proto/hapi/chart"
+)
+
+// StepIngressMigrateOptions contains the command line flags
+type StepIngressMigrateOptions struct {
+	step.StepOptions
+
+	NewDomain      string
+	OldDomain      string
+	TLS            bool
+	TLSEmail       string
+	TLSProduction  bool
+	SkipPRs        bool
+	SkipWebHooks   bool
+	SkipVerify     bool
+	VerifyTimeout  time.Duration
+	VerifyInterval time.Duration
+	AutoMerge      bool
+	DryRun         bool
+}
+
+// migrateEnvironment an environment whose git repository is migrated to the new domain
+type migrateEnvironment struct {
+	Environment *v1.Environment
+	PullRequest *gits.PullRequestInfo
+}
+
+var (
+	stepIngressMigrateLong = templates.LongDesc(`
+		Migrates the ingress of Jenkins X and all the environments to a new domain and/or to TLS.
+
+		The command:
+
+		* raises a Pull Request on the git repository of the development environment and each permanent environment
+		  which updates the ingress domain and TLS settings in 'jx-requirements.yml' and the exposecontroller
+		  configuration in 'env/values.yaml'
+		* once the Pull Requests are merged and deployed, verifies that the ingress hosts of the environments use the
+		  new domain and respond
+		* updates the webhooks of the git repositories to the new webhook URL
+
+		The webhooks are only updated after the new endpoints are verified so that the pipelines applying the
+		Pull Requests are still triggered. If the verification times out run the command again with '--skip-prs'.
+`)
+
+	stepIngressMigrateExample = templates.Examples(`
+		# migrate to a new domain using TLS certificates from the production LetsEncrypt server
+		jx step ingress migrate --new-domain example.dev --tls --tls-email admin@example.dev --tls-production
+
+		# report what would change without raising any Pull Requests or modifying webhooks
+		jx step ingress migrate --new-domain example.dev --tls --dry-run
+`)
+)
+
+// NewCmdStepIngressMigrate creates the command
+func NewCmdStepIngressMigrate(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := StepIngressMigrateOptions{
+		StepOptions: step.StepOptions{
+			CommonOptions: commonOpts,
+		},
+	}
+	cmd := &cobra.Command{
+		Use:     "migrate",
+		Short:   "Migrates the ingress of Jenkins X and all the environments to a new domain and/or to TLS",
+		Long:    stepIngressMigrateLong,
+		Example: stepIngressMigrateExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.Flags().StringVarP(&options.NewDomain, "new-domain", "", "", "The new domain to expose the ingress endpoints on. Defaults to the current domain if only enabling TLS")
+	cmd.Flags().StringVarP(&options.OldDomain, "old-domain", "", "", "The current domain. Defaults to the domain in the requirements of the development environment")
+	cmd.Flags().BoolVarP(&options.TLS, "tls", "", false, "Enables TLS for the ingress endpoints using cert-manager")
+	cmd.Flags().StringVarP(&options.TLSEmail, "tls-email", "", "", "The email address to register with LetsEncrypt")
+	cmd.Flags().BoolVarP(&options.TLSProduction, "tls-production", "", false, "Uses the production LetsEncrypt server rather than the staging server which issues untrusted certificates")
+	cmd.Flags().BoolVarP(&options.SkipPRs, "skip-prs", "", false, "Does not raise the Pull Requests on the environments, such as when they were raised by a previous run")
+	cmd.Flags().BoolVarP(&options.SkipWebHooks, "skip-webhooks", "", false, "Does not update the webhooks of the git repositories")
+	cmd.Flags().BoolVarP(&options.SkipVerify, "skip-verify", "", false, "Does not wait for the new endpoints to be deployed and respond before updating the webhooks")
+	cmd.Flags().DurationVarP(&options.VerifyTimeout, "verify-timeout", "", 30*time.Minute, "The time to wait for the new endpoints to be deployed and respond")
+	cmd.Flags().DurationVarP(&options.VerifyInterval, "verify-interval", "", 15*time.Second, "The time between checks of the new endpoints")
+	cmd.Flags().BoolVarP(&options.AutoMerge, "auto-merge", "", false, "Automatically merge the Pull Requests when they pass CI")
+	cmd.Flags().BoolVarP(&options.DryRun, "dry-run", "", false, "Reports the changes which would be made without making them")
+	return cmd
+}
+
+// Run performs the command
+func (o *StepIngressMigrateOptions) Run() error {
+	jxClient, devNs, err := o.JXClientAndDevNamespace()
+	if err != nil {
+		return err
+	}
+	devEnv, err := kube.GetDevEnvironment(jxClient, devNs)
+	if err != nil {
+		return errors.Wrap(err, "finding the development environment")
+	}
+	if devEnv == nil {
+		return fmt.Errorf("no development environment found in namespace %s", devNs)
+	}
+	if o.OldDomain == "" {
+		requirements, err := config.GetRequirementsConfigFromTeamSettings(&devEnv.Spec.TeamSettings)
+		if err != nil {
+			return errors.Wrap(err, "loading the requirements of the development environment")
+		}
+		if requirements != nil {
+			o.OldDomain = requirements.Ingress.Domain
+		}
+	}
+	if o.OldDomain == "" {
+		return util.MissingOption("old-domain")
+	}
+	if o.NewDomain == "" {
+		if !o.TLS {
+			return util.MissingOption("new-domain")
+		}
+		o.NewDomain = o.OldDomain
+	}
+
+	envs, err := o.migrateEnvironments(jxClient, devNs, devEnv)
+	if err != nil {
+		return err
+	}
+	log.Logger().Infof("Migrating the ingress from domain %s to %s", util.ColorInfo(o.OldDomain), util.ColorInfo(o.NewDomain))
+
+	oldHookURL, err := o.GetWebHookEndpoint()
+	if err != nil {
+		return errors.Wrap(err, "finding the webhook endpoint")
+	}
+	newHookURL := migrateURL(oldHookURL, o.OldDomain, o.NewDomain, o.TLS)
+
+	if o.DryRun {
+		for _, env := range envs {
+			log.Logger().Infof("Would raise a Pull Request on %s to migrate environment %s", env.Environment.Spec.Source.URL, util.ColorInfo(env.Environment.Name))
+		}
+		if !o.SkipWebHooks {
+			log.Logger().Infof("Would update the webhooks from %s to %s", util.ColorInfo(oldHookURL), util.ColorInfo(newHookURL))
+		}
+		return nil
+	}
+
+	if !o.SkipPRs {
+		err = o.raisePullRequests(envs)
+		if err != nil {
+			return err
+		}
+	}
+
+	if !o.SkipVerify {
+		kubeClient, err := o.KubeClient()
+		if err != nil {
+			return err
+		}
+		namespaces := []string{devNs}
+		for _, env := range envs {
+			ns := env.Environment.Spec.Namespace
+			if ns != "" && util.StringArrayIndex(namespaces, ns) < 0 {
+				namespaces = append(namespaces, ns)
+			}
+		}
+		err = o.verifyEndpoints(kubeClient, namespaces)
+		if err != nil {
+			return errors.Wrapf(err, "verifying the new endpoints, once they are deployed run the command again with --skip-prs")
+		}
+	}
+
+	if !o.SkipWebHooks {
+		log.Logger().Infof("Updating the webhooks from %s to %s", util.ColorInfo(oldHookURL), util.ColorInfo(newHookURL))
+		updateWebHooks := update.UpdateWebhooksOptions{
+			CommonOptions:   o.CommonOptions,
+			Endpoint:        newHookURL,
+			PreviousHookUrl: oldHookURL,
+			WarnOnFail:      true,
+		}
+		err = updateWebHooks.Run()
+		if err != nil {
+			return errors.Wrap(err, "updating the webhooks")
+		}
+	}
+	log.Logger().Infof("Migrated the ingress to domain %s", util.ColorInfo(o.NewDomain))
+	return nil
+}
+
+// migrateEnvironments returns the development environment and the permanent environments in promotion order which
+// have a git repository
+func (o *StepIngressMigrateOptions) migrateEnvironments(jxClient versioned.Interface, devNs string, devEnv *v1.Environment) ([]*migrateEnvironment, error) {
+	envMap, _, err := kube.GetEnvironments(jxClient, devNs)
+	if err != nil {
+		return nil, errors.Wrap(err, "loading the environments")
+	}
+	answer := []*migrateEnvironment{}
+	if devEnv.Spec.Source.URL != "" {
+		answer = append(answer, &migrateEnvironment{Environment: devEnv})
+	}
+	envs := []*v1.Environment{}
+	for _, env := range envMap {
+		if env.Spec.Kind == v1.EnvironmentKindTypePermanent && env.Spec.Source.URL != "" && env.Spec.Source.URL != devEnv.Spec.Source.URL {
+			envs = append(envs, env)
+		}
+	}
+	sort.Slice(envs, func(i, j int) bool {
+		if envs[i].Spec.Order != envs[j].Spec.Order {
+			return envs[i].Spec.Order < envs[j].Spec.Order
+		}
+		return envs[i].Name < envs[j].Name
+	})
+	for _, env := range envs {
+		answer = append(answer, &migrateEnvironment{Environment: env})
+	}
+	return answer, nil
+}
+
+// raisePullRequests raises the Pull Requests which migrate the git repositories of the environments
+func (o *StepIngressMigrateOptions) raisePullRequests(envs []*migrateEnvironment) error {
+	environmentsDir, err := o.EnvironmentsDir()
+	if err != nil {
+		return errors.Wrap(err, "getting the environments dir")
+	}
+	for _, env := range envs {
+		gitURL := env.Environment.Spec.Source.URL
+		gitProvider, _, err := o.CreateGitProviderForURLWithoutKind(gitURL)
+		if err != nil {
+			return errors.Wrapf(err, "creating the git provider for %s", gitURL)
+		}
+		details := gits.PullRequestDetails{
+			BranchName: "ingress-" + o.NewDomain,
+			Title:      fmt.Sprintf("Migrate the ingress to domain %s", o.NewDomain),
+			Message:    fmt.Sprintf("Migrates the ingress from domain %s to %s. The command `jx step ingress migrate` generated this Pull Request", o.OldDomain, o.NewDomain),
+		}
+		options := environments.EnvironmentPullRequestOptions{
+			Gitter:        o.Git(),
+			GitProvider:   gitProvider,
+			ModifyChartFn: o.migrateChartFn(),
+		}
+		env.PullRequest, err = options.Create(env.Environment, environmentsDir, &details, nil, "", o.AutoMerge)
+		if err != nil {
+			return errors.Wrapf(err, "raising the Pull Request to migrate environment %s", env.Environment.Name)
+		}
+		if env.PullRequest != nil && env.PullRequest.PullRequest != nil {
+			log.Logger().Infof("Raised Pull Request %s to migrate environment %s", util.ColorInfo(env.PullRequest.PullRequest.URL), env.Environment.Name)
+		}
+	}
+	return nil
+}
+
+// migrateChartFn returns the function which migrates the requirements and the exposecontroller configuration of an
+// environment git repository
+func (o *StepIngressMigrateOptions) migrateChartFn() environments.ModifyChartFn {
+	return func(requirements *helm.Requirements, metadata *chart.Metadata, values map[string]interface{},
+		templates map[string]string, dir string, details *gits.PullRequestDetails) error {
+		for _, requirementsFile := range []string{filepath.Join(dir, config.RequirementsConfigFileName), filepath.Join(filepath.Dir(filepath.Clean(dir)), config.RequirementsConfigFileName)} {
+			exists, err := util.FileExists(requirementsFile)
+			if err != nil {
+				return err
+			}
+			if !exists {
+				continue
+			}
+			requirementsConfig, err := config.LoadRequirementsConfigFile(requirementsFile)
+			if err != nil {
+				return errors.Wrapf(err, "loading %s", requirementsFile)
+			}
+			o.migrateRequirements(requirementsConfig)
+			err = requirementsConfig.SaveConfig(requirementsFile)
+			if err != nil {
+				return errors.Wrapf(err, "saving %s", requirementsFile)
+			}
+			break
+		}
+
+		if util.GetMapValueViaPath(values, "expose") == nil {
+			return nil
+		}
+		o.migrateExposeValues(values)
+		valuesFile := filepath.Join(dir, helm.ValuesFileName)
+		err := helm.SaveFile(valuesFile, values)
+		if err != nil {
+			return errors.Wrapf(err, "saving %s", valuesFile)
+		}
+		return nil
+	}
+}
+
+// migrateRequirements migrates the ingress of the requirements and of any environments which use a sub domain of
+// the old domain
+func (o *StepIngressMigrateOptions) migrateRequirements(requirements *config.RequirementsConfig) {
+	o.migrateIngressConfig(&requirements.Ingress)
+	for i := range requirements.Environments {
+		ingress := &requirements.Environments[i].Ingress
+		if ingress.Domain != "" {
+			o.migrateIngressConfig(ingress)
+		}
+	}
+}
+
+func (o *StepIngressMigrateOptions) migrateIngressConfig(ingress *config.IngressConfig) {
+	ingress.Domain = migrateDomain(ingress.Domain, o.OldDomain, o.NewDomain)
+	if o.TLS {
+		ingress.TLS.Enabled = true
+		ingress.TLS.Production = o.TLSProduction
+		if o.TLSEmail != "" {
+			ingress.TLS.Email = o.TLSEmail
+		}
+	}
+}
+
+// migrateExposeValues migrates the exposecontroller configuration in the values of an environment chart
+func (o *StepIngressMigrateOptions) migrateExposeValues(values map[string]interface{}) {
+	domain := util.GetMapValueAsStringViaPath(values, "expose.config.domain")
+	if domain == "" {
+		domain = o.OldDomain
+	}
+	util.SetMapValueViaPath(values, "expose.config.domain", migrateDomain(domain, o.OldDomain, o.NewDomain))
+	if o.TLS {
+		issuer := pki.CertManagerIssuerStaging
+		if o.TLSProduction {
+			issuer = pki.CertManagerIssuerProd
+		}
+		util.SetMapValueViaPath(values, "expose.config.http", "false")
+		util.SetMapValueViaPath(values, "expose.config.tlsacme", "true")
+		expose := util.GetMapValueAsMapViaPath(values, "expose")
+		annotations, ok := expose["Annotations"].(map[string]interface{})
+		if !ok {
+			annotations = map[string]interface{}{}
+			expose["Annotations"] = annotations
+		}
+		annotations[services.CertManagerAnnotation] = issuer
+	}
+}
+
+// verifyEndpoints waits until the ingress hosts in the namespaces have moved to the new domain and respond
+func (o *StepIngressMigrateOptions) verifyEndpoints(kubeClient kubernetes.Interface, namespaces []string) error {
+	client := &http.Client{
+		Timeout: 10 * time.Second,
+		Transport: &http.Transport{
+			Proxy: http.ProxyFromEnvironment,
+			// the staging LetsEncrypt server issues untrusted certificates
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: !o.TLSProduction}, // #nosec
+		},
+	}
+	log.Logger().Infof("Waiting for the endpoints of namespaces %s to be available on domain %s", strings.Join(namespaces, ", "), util.ColorInfo(o.NewDomain))
+	end := time.Now().Add(o.VerifyTimeout)
+	for {
+		pending, err := o.pendingEndpoints(kubeClient, client, namespaces)
+		if err != nil {
+			return err
+		}
+		if len(pending) == 0 {
+			log.Logger().Infof("All the endpoints are available on domain %s", util.ColorInfo(o.NewDomain))
+			return nil
+		}
+		if time.Now().After(end) {
+			return fmt.Errorf("timed out after %s waiting for the endpoints: %s", o.VerifyTimeout.String(), strings.Join(pending, ", "))
+		}
+		log.Logger().Debugf("waiting for the endpoints: %s", strings.Join(pending, ", "))
+		time.Sleep(o.VerifyInterval)
+	}
+}
+
+// pendingEndpoints returns a description of each ingress host which is not yet on the new domain or does not respond
+func (o *StepIngressMigrateOptions) pendingEndpoints(kubeClient kubernetes.Interface, client *http.Client, namespaces []string) ([]string, error) {
+	pending := []string{}
+	for _, ns := range namespaces {
+		ingresses, err := kubeClient.ExtensionsV1beta1().Ingresses(ns).List(metav1.ListOptions{})
+		if err != nil {
+			return nil, errors.Wrapf(err, "listing the ingresses in namespace %s", ns)
+		}
+		for i := range ingresses.Items {
+			ing := &ingresses.Items[i]
+			host := services.IngressHost(ing)
+			if host == "" {
+				continue
+			}
+			if !isOnDomain(host, o.NewDomain) {
+				pending = append(pending, fmt.Sprintf("%s is on the old domain", host))
+				continue
+			}
+			if o.TLS && len(ing.Spec.TLS) == 0 {
+				pending = append(pending, fmt.Sprintf("%s has no TLS", host))
+				continue
+			}
+			resp, err := client.Get(services.IngressURL(ing))
+			if err != nil {
+				pending = append(pending, fmt.Sprintf("%s does not respond", host))
+				continue
+			}
+			resp.Body.Close()
+			if resp.StatusCode >= http.StatusInternalServerError {
+				pending = append(pending, fmt.Sprintf("%s responds with %s", host, resp.Status))
+			}
+		}
+	}
+	return pending, nil
+}
+
+// migrateDomain replaces the old domain of the domain, or of a sub domain of it, with the new domain
+func migrateDomain(domain string, oldDomain string, newDomain string) string {
+	if domain == oldDomain || domain == "" {
+		return newDomain
+	}
+	if strings.HasSuffix(domain, "."+oldDomain) {
+		return strings.TrimSuffix(domain, oldDomain) + newDomain
+	}
+	return domain
+}
+
+// migrateURL migrates the host of the URL to the new domain switching to https if TLS is enabled
+func migrateURL(u string, oldDomain string, newDomain string, enableTLS bool) string {
+	parsed, err := url.Parse(u)
+	if err != nil || parsed.Host == "" {
+		return u
+	}
+	host := parsed.Hostname()
+	if isOnDomain(host, oldDomain) {
+		host = strings.TrimSuffix(host, oldDomain) + newDomain
+	}
+	if port := parsed.Port(); port != "" {
+		host += ":" + port
+	}
+	parsed.Host = host
+	if enableTLS {
+		parsed.Scheme = "https"
+	}
+	return parsed.String()
+}
+
+// isOnDomain returns true if the host is the domain or a sub domain of it
+func isOnDomain(host string, domain string) bool {
+	return host == domain || strings.HasSuffix(host, "."+domain)
+}
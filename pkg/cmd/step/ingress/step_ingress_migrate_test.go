@@ -0,0 +1,79 @@
+package ingress
+
+import (
+	"testing"
+
+	"github.com/jenkins-x/jx/pkg/config"
+	"github.com/jenkins-x/jx/pkg/kube/pki"
+	"github.com/jenkins-x/jx/pkg/kube/services"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMigrateDomain(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "new.dev", migrateDomain("old.com", "old.com", "new.dev"))
+	assert.Equal(t, "jx.new.dev", migrateDomain("jx.old.com", "old.com", "new.dev"))
+	assert.Equal(t, "new.dev", migrateDomain("", "old.com", "new.dev"))
+	assert.Equal(t, "notold.com", migrateDomain("notold.com", "old.com", "new.dev"))
+	assert.Equal(t, "other.io", migrateDomain("other.io", "old.com", "new.dev"))
+}
+
+func TestMigrateURL(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "https://hook-jx.new.dev/hook", migrateURL("http://hook-jx.old.com/hook", "old.com", "new.dev", true))
+	assert.Equal(t, "http://hook-jx.new.dev:8080/hook", migrateURL("http://hook-jx.old.com:8080/hook", "old.com", "new.dev", false))
+	assert.Equal(t, "https://hook.other.io/hook", migrateURL("http://hook.other.io/hook", "old.com", "new.dev", true))
+	assert.Equal(t, "not a url", migrateURL("not a url", "old.com", "new.dev", true))
+}
+
+func TestMigrateRequirements(t *testing.T) {
+	t.Parallel()
+	o := &StepIngressMigrateOptions{
+		OldDomain:     "old.com",
+		NewDomain:     "new.dev",
+		TLS:           true,
+		TLSEmail:      "admin@new.dev",
+		TLSProduction: true,
+	}
+	requirements := config.NewRequirementsConfig()
+	requirements.Ingress.Domain = "old.com"
+	requirements.Environments = []config.EnvironmentConfig{
+		{Key: "dev"},
+		{Key: "production", Ingress: config.IngressConfig{Domain: "prod.old.com"}},
+	}
+
+	o.migrateRequirements(requirements)
+	assert.Equal(t, "new.dev", requirements.Ingress.Domain)
+	assert.True(t, requirements.Ingress.TLS.Enabled)
+	assert.True(t, requirements.Ingress.TLS.Production)
+	assert.Equal(t, "admin@new.dev", requirements.Ingress.TLS.Email)
+	assert.Equal(t, "", requirements.Environments[0].Ingress.Domain)
+	assert.False(t, requirements.Environments[0].Ingress.TLS.Enabled)
+	assert.Equal(t, "prod.new.dev", requirements.Environments[1].Ingress.Domain)
+	assert.True(t, requirements.Environments[1].Ingress.TLS.Enabled)
+}
+
+func TestMigrateExposeValues(t *testing.T) {
+	t.Parallel()
+	o := &StepIngressMigrateOptions{
+		OldDomain: "old.com",
+		NewDomain: "new.dev",
+		TLS:       true,
+	}
+	values := map[string]interface{}{
+		"expose": map[string]interface{}{
+			"config": map[string]interface{}{
+				"domain": "staging.old.com",
+				"http":   "true",
+			},
+		},
+	}
+
+	o.migrateExposeValues(values)
+	assert.Equal(t, "staging.new.dev", util.GetMapValueAsStringViaPath(values, "expose.config.domain"))
+	assert.Equal(t, "false", util.GetMapValueAsStringViaPath(values, "expose.config.http"))
+	assert.Equal(t, "true", util.GetMapValueAsStringViaPath(values, "expose.config.tlsacme"))
+	annotations := util.GetMapValueAsMapViaPath(values, "expose.Annotations")
+	assert.Equal(t, pki.CertManagerIssuerStaging, annotations[services.CertManagerAnnotation])
+}
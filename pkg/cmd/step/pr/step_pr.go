@@ -32,8 +32,10 @@ func NewCmdStepPR(commonOpts *opts.CommonOptions) *cobra.Command {
 		},
 	}
 
+	cmd.AddCommand(NewCmdStepPRApprove(commonOpts))
 	cmd.AddCommand(NewCmdStepPRComment(commonOpts))
 	cmd.AddCommand(NewCmdStepPRLabels(commonOpts))
+	cmd.AddCommand(NewCmdStepPRReview(commonOpts))
 
 	return cmd
 }
@@ -0,0 +1,131 @@
+package pr
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/jenkins-x/jx/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/pkg/cmd/opts/step"
+	"github.com/jenkins-x/jx/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/pkg/gits"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/spf13/cobra"
+)
+
+// StepPRApproveOptions contains the command line flags
+type StepPRApproveOptions struct {
+	StepPROptions
+
+	Owner      string
+	Repository string
+	PR         string
+}
+
+var (
+	stepPRApproveLong = templates.LongDesc(`
+		Approves a Pull Request as the current git user on git providers, such as Bitbucket Server, whose merge checks
+		require approvals rather than the approval being handled via comments by prow or lighthouse.
+`)
+
+	stepPRApproveExample = templates.Examples(`
+		# approve the Pull Request of the current pipeline
+		jx step pr approve
+
+		# approve a specific Pull Request
+		jx step pr approve --owner myproject --repository myrepo --pull-request 12
+	`)
+)
+
+// NewCmdStepPRApprove creates the command for "step pr approve"
+func NewCmdStepPRApprove(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &StepPRApproveOptions{
+		StepPROptions: StepPROptions{
+			StepOptions: step.StepOptions{
+				CommonOptions: commonOpts,
+			},
+		},
+	}
+
+	cmd := &cobra.Command{
+		Use:     "approve",
+		Short:   "Approves a Pull Request on git providers which support approvals",
+		Long:    stepPRApproveLong,
+		Example: stepPRApproveExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+
+	cmd.Flags().StringVarP(&options.Owner, "owner", "o", "", "Git organisation / owner. Defaults to $REPO_OWNER")
+	cmd.Flags().StringVarP(&options.Repository, "repository", "r", "", "Git repository. Defaults to $REPO_NAME")
+	cmd.Flags().StringVarP(&options.PR, "pull-request", "p", "", "Git Pull Request number. Defaults to $PULL_NUMBER")
+	return cmd
+}
+
+// Run implements this command
+func (o *StepPRApproveOptions) Run() error {
+	if o.PR == "" {
+		o.PR = os.Getenv("PULL_NUMBER")
+	}
+	if o.PR == "" {
+		return util.MissingOption("pull-request")
+	}
+	if o.Owner == "" {
+		o.Owner = os.Getenv("REPO_OWNER")
+	}
+	if o.Owner == "" {
+		return util.MissingOption("owner")
+	}
+	if o.Repository == "" {
+		o.Repository = os.Getenv("REPO_NAME")
+	}
+	if o.Repository == "" {
+		return util.MissingOption("repository")
+	}
+	prNumber, err := strconv.Atoi(o.PR)
+	if err != nil {
+		return util.InvalidOptionError("pull-request", o.PR, err)
+	}
+
+	authConfigSvc, err := o.GitAuthConfigService()
+	if err != nil {
+		return err
+	}
+	gitInfo, err := o.Git().Info("")
+	if err != nil {
+		return err
+	}
+	gitKind, err := o.GitServerKind(gitInfo)
+	if err != nil {
+		return err
+	}
+	ghOwner, err := o.GetGitHubAppOwner(gitInfo)
+	if err != nil {
+		return err
+	}
+	provider, err := o.NewGitProvider(gitInfo.URL, "user name to approve the Pull Request as", authConfigSvc, gitKind, ghOwner, o.BatchMode, o.Git())
+	if err != nil {
+		return err
+	}
+	approver, ok := provider.(gits.PullRequestApprover)
+	if !ok {
+		return fmt.Errorf("the git provider %s does not support approving Pull Requests", gitKind)
+	}
+
+	pr, err := provider.GetPullRequest(o.Owner, &gits.GitRepository{Name: o.Repository}, prNumber)
+	if err != nil {
+		return err
+	}
+	err = approver.ApprovePullRequest(pr)
+	if err != nil {
+		return err
+	}
+	log.Logger().Infof("Approved Pull Request %s", util.ColorInfo(pr.URL))
+	return nil
+}
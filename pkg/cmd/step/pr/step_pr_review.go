@@ -0,0 +1,267 @@
+package pr
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/jenkins-x/jx/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/pkg/cmd/opts/step"
+	"github.com/jenkins-x/jx/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/pkg/gits"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/prow"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// DefaultReviewContext the default context of the commit status reporting the review of a Pull Request
+	DefaultReviewContext = "review"
+
+	// maxSuggestedUsers the maximum number of users listed in the description of the commit status
+	maxSuggestedUsers = 5
+)
+
+// StepPRReviewOptions contains the command line flags
+type StepPRReviewOptions struct {
+	StepPROptions
+
+	Owner      string
+	Repository string
+	PR         string
+	Base       string
+	Dir        string
+	Context    string
+}
+
+var (
+	stepPRReviewLong = templates.LongDesc(`
+		Checks the approvals of a Pull Request against the OWNERS files and blockades of the repository and reports
+		the result as a commit status which can be required before merging.
+
+		This applies the rules of the prow approve, lgtm and blockade plugins on git providers, such as GitLab,
+		Bitbucket Cloud, Bitbucket Server and Gitea, where the Pull Requests are approved with the native approvals of
+		the git provider rather than with prow comments. On Gitea, which has no approvals, /approve and /lgtm comments
+		are used.
+
+		The Pull Request is approved when every changed file is approved by one of the approvers in the OWNERS files of
+		its directory or parent directories, and when an approver or reviewer other than the author has approved it.
+		Changes to the files blocked by the blockades in the prow plugins configuration fail the commit status.
+
+		Run this step again, such as by triggering the Pull Request pipeline, after the Pull Request is approved.
+`)
+
+	stepPRReviewExample = templates.Examples(`
+		# check the approvals of the Pull Request of the current pipeline
+		jx step pr review
+
+		# check the approvals of a specific Pull Request
+		jx step pr review --owner myproject --repository myrepo --pull-request 12 --base origin/master
+	`)
+)
+
+// NewCmdStepPRReview creates the command for "step pr review"
+func NewCmdStepPRReview(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &StepPRReviewOptions{
+		StepPROptions: StepPROptions{
+			StepOptions: step.StepOptions{
+				CommonOptions: commonOpts,
+			},
+		},
+	}
+
+	cmd := &cobra.Command{
+		Use:     "review",
+		Short:   "Checks the approvals of a Pull Request against the OWNERS files of the repository",
+		Long:    stepPRReviewLong,
+		Example: stepPRReviewExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+
+	cmd.Flags().StringVarP(&options.Owner, "owner", "o", "", "Git organisation / owner. Defaults to $REPO_OWNER")
+	cmd.Flags().StringVarP(&options.Repository, "repository", "r", "", "Git repository. Defaults to $REPO_NAME")
+	cmd.Flags().StringVarP(&options.PR, "pull-request", "p", "", "Git Pull Request number. Defaults to $PULL_NUMBER")
+	cmd.Flags().StringVarP(&options.Base, "base", "b", "", "The git reference the Pull Request is compared to. Defaults to $PULL_BASE_SHA")
+	cmd.Flags().StringVarP(&options.Dir, "dir", "d", ".", "The directory of the source code containing the OWNERS files")
+	cmd.Flags().StringVarP(&options.Context, "context", "c", DefaultReviewContext, "The context of the commit status")
+	return cmd
+}
+
+// Run implements this command
+func (o *StepPRReviewOptions) Run() error {
+	if o.PR == "" {
+		o.PR = os.Getenv("PULL_NUMBER")
+	}
+	if o.PR == "" {
+		return util.MissingOption("pull-request")
+	}
+	if o.Owner == "" {
+		o.Owner = os.Getenv("REPO_OWNER")
+	}
+	if o.Owner == "" {
+		return util.MissingOption("owner")
+	}
+	if o.Repository == "" {
+		o.Repository = os.Getenv("REPO_NAME")
+	}
+	if o.Repository == "" {
+		return util.MissingOption("repository")
+	}
+	if o.Base == "" {
+		o.Base = os.Getenv("PULL_BASE_SHA")
+	}
+	if o.Base == "" {
+		return util.MissingOption("base")
+	}
+	prNumber, err := strconv.Atoi(o.PR)
+	if err != nil {
+		return util.InvalidOptionError("pull-request", o.PR, err)
+	}
+
+	gitInfo, err := o.FindGitInfo(o.Dir)
+	if err != nil {
+		return err
+	}
+	provider, err := o.GitProviderForURL(gitInfo.URL, "git provider")
+	if err != nil {
+		return err
+	}
+	pr, err := provider.GetPullRequest(o.Owner, &gits.GitRepository{Name: o.Repository, Organisation: o.Owner, Project: o.Owner}, prNumber)
+	if err != nil {
+		return errors.Wrapf(err, "failed to find Pull Request %d on %s/%s", prNumber, o.Owner, o.Repository)
+	}
+	pr.Owner = o.Owner
+	pr.Repo = o.Repository
+	sha := os.Getenv("PULL_PULL_SHA")
+	if sha == "" {
+		sha = pr.LastCommitSha
+	}
+	if sha == "" {
+		return fmt.Errorf("could not find the last commit of Pull Request %s", pr.URL)
+	}
+
+	changes, err := o.Git().ListChangedFilesFromBranch(o.Dir, o.Base)
+	if err != nil {
+		return errors.Wrapf(err, "failed to list the files changed since %s", o.Base)
+	}
+	files := parseChangedFiles(changes)
+	owners, err := prow.LoadRepoOwners(o.Dir)
+	if err != nil {
+		return err
+	}
+	blockades := o.loadBlockades()
+	approvals, err := gits.ListPullRequestApprovals(provider, pr)
+	if err != nil {
+		return err
+	}
+	author := ""
+	if pr.Author != nil {
+		author = pr.Author.Login
+	}
+
+	state, description, err := reviewPullRequest(owners, blockades, o.Owner, o.Repository, files, author, approvals)
+	if err != nil {
+		return err
+	}
+	_, err = provider.UpdateCommitStatus(o.Owner, o.Repository, sha, &gits.GitRepoStatus{
+		State:       state,
+		Context:     o.Context,
+		Description: description,
+		TargetURL:   pr.URL,
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to update the %s status of Pull Request %s", o.Context, pr.URL)
+	}
+	log.Logger().Infof("Pull Request %s is %s: %s", util.ColorInfo(pr.URL), util.ColorInfo(state), description)
+	return nil
+}
+
+// loadBlockades loads the blockades from the prow plugins configuration in the development namespace
+func (o *StepPRReviewOptions) loadBlockades() []prow.Blockade {
+	kubeClient, ns, err := o.KubeClientAndDevNamespace()
+	if err != nil {
+		log.Logger().Warnf("Ignoring any blockades as the development namespace could not be found: %s", err)
+		return nil
+	}
+	prowOptions := prow.Options{
+		KubeClient: kubeClient,
+		NS:         ns,
+	}
+	blockades, err := prowOptions.LoadBlockades()
+	if err != nil {
+		log.Logger().Warnf("Ignoring any blockades as they could not be loaded: %s", err)
+		return nil
+	}
+	return blockades
+}
+
+// reviewPullRequest returns the state and description of the commit status for the review of the changed files
+func reviewPullRequest(owners *prow.RepoOwners, blockades []prow.Blockade, org string, repo string, files []string, author string, approvals []string) (string, string, error) {
+	blocked, err := prow.MatchBlockades(blockades, org, repo, files)
+	if err != nil {
+		return "", "", err
+	}
+	if len(blocked) > 0 {
+		explanations := []string{}
+		for _, b := range blocked {
+			log.Logger().Infof("The files %s are blocked: %s", strings.Join(b.Files, ", "), b.Explanation)
+			if b.Explanation != "" {
+				explanations = append(explanations, b.Explanation)
+			}
+		}
+		if len(explanations) == 0 {
+			explanations = append(explanations, "changes to the files are blocked")
+		}
+		return "failure", "Blocked: " + strings.Join(explanations, ", "), nil
+	}
+
+	status := owners.Review(files, author, approvals)
+	if !status.Approved {
+		log.Logger().Infof("The files %s are not approved", strings.Join(status.UnapprovedFiles, ", "))
+		if len(status.SuggestedApprovers) == 0 {
+			return "pending", "Waiting for an approval", nil
+		}
+		return "pending", "Waiting for an approval by one of: " + suggestedUsers(status.SuggestedApprovers), nil
+	}
+	if !status.LGTM {
+		if len(status.SuggestedReviewers) == 0 {
+			return "pending", "Waiting for a review", nil
+		}
+		return "pending", "Waiting for a review by one of: " + suggestedUsers(status.SuggestedReviewers), nil
+	}
+	return "success", "Approved according to the OWNERS files", nil
+}
+
+func suggestedUsers(users []string) string {
+	if len(users) > maxSuggestedUsers {
+		return strings.Join(users[0:maxSuggestedUsers], ", ") + "..."
+	}
+	return strings.Join(users, ", ")
+}
+
+// parseChangedFiles returns the file names of the output of git diff --name-status. Both the old and new names of
+// renamed files are included
+func parseChangedFiles(changes string) []string {
+	answer := []string{}
+	for _, line := range strings.Split(changes, "\n") {
+		fields := strings.Split(strings.TrimSpace(line), "\t")
+		if len(fields) < 2 {
+			continue
+		}
+		for _, fileName := range fields[1:] {
+			if fileName != "" && util.StringArrayIndex(answer, fileName) < 0 {
+				answer = append(answer, fileName)
+			}
+		}
+	}
+	return answer
+}
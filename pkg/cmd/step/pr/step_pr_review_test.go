@@ -0,0 +1,51 @@
+package pr
+
+import (
+	"testing"
+
+	"github.com/jenkins-x/jx/pkg/prow"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseChangedFiles(t *testing.T) {
+	t.Parallel()
+	changes := "M\tmain.go\nA\tdocs/index.md\nR100\told/name.go\tnew/name.go\nD\tmain.go\n\n"
+
+	assert.Equal(t, []string{"main.go", "docs/index.md", "old/name.go", "new/name.go"}, parseChangedFiles(changes))
+}
+
+func TestReviewPullRequest(t *testing.T) {
+	t.Parallel()
+	owners := prow.NewRepoOwners(map[string]*prow.Owners{
+		".":    {Approvers: []string{"alice"}, Reviewers: []string{"bob"}},
+		"docs": {Approvers: []string{"carol"}},
+	}, nil)
+	blockades := []prow.Blockade{
+		{
+			Repos:        []string{"myorg/myrepo"},
+			BlockRegexps: []string{`^charts/`},
+			Explanation:  "the charts are frozen",
+		},
+	}
+	files := []string{"main.go", "docs/index.md"}
+
+	state, description, err := reviewPullRequest(owners, blockades, "myorg", "myrepo", files, "frank", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "pending", state)
+	assert.Equal(t, "Waiting for an approval by one of: alice, carol", description)
+
+	state, description, err = reviewPullRequest(owners, blockades, "myorg", "myrepo", []string{"docs/index.md"}, "carol", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "pending", state)
+	assert.Equal(t, "Waiting for a review by one of: alice, bob", description)
+
+	state, _, err = reviewPullRequest(owners, blockades, "myorg", "myrepo", files, "frank", []string{"alice"})
+	require.NoError(t, err)
+	assert.Equal(t, "success", state)
+
+	state, description, err = reviewPullRequest(owners, blockades, "myorg", "myrepo", append(files, "charts/values.yaml"), "frank", []string{"alice"})
+	require.NoError(t, err)
+	assert.Equal(t, "failure", state)
+	assert.Equal(t, "Blocked: the charts are frozen", description)
+}
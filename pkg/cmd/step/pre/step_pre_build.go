@@ -1,15 +1,14 @@
 package pre
 
 import (
-	"strings"
-
 	"github.com/jenkins-x/jx/pkg/cmd/opts/step"
+	"github.com/jenkins-x/jx/pkg/registry"
+	"github.com/pkg/errors"
 
 	"github.com/jenkins-x/jx/pkg/cmd/helper"
 
 	"github.com/jenkins-x/jx/pkg/kube"
 
-	"github.com/jenkins-x/jx/pkg/cloud/amazon"
 	"github.com/jenkins-x/jx/pkg/cmd/opts"
 	"github.com/jenkins-x/jx/pkg/cmd/templates"
 	"github.com/jenkins-x/jx/pkg/log"
@@ -25,16 +24,24 @@ const (
 type StepPreBuildOptions struct {
 	step.StepOptions
 
-	Image string
+	Image        string
+	Login        bool
+	DockerConfig string
 }
 
 var (
 	StepPreBuildLong = templates.LongDesc(`
 		This pipeline step performs pre build actions such as ensuring that a Docker registry is available in the cloud
+
+		Repositories are created in the registries which require them before pushing such as ECR and Google Artifact Registry.
+		With --login the short lived credentials of ECR, ACR, GCR and Google Artifact Registry are refreshed using the identity of the pod.
 `)
 
 	StepPreBuildExample = templates.Examples(`
 		jx step pre build ${DOCKER_REGISTRY}/someorg/myapp
+
+		# ensure the repository exists and refresh the credentials kaniko uses to push the image
+		jx step pre build --login --docker-config /kaniko/.docker/config.json ${DOCKER_REGISTRY}/someorg/myapp
 `)
 )
 
@@ -57,9 +64,12 @@ func NewCmdStepPreBuild(commonOpts *opts.CommonOptions) *cobra.Command {
 		},
 	}
 	cmd.Flags().StringVarP(&options.Image, optionImage, "i", "", "The image name that is about to be built")
+	cmd.Flags().BoolVarP(&options.Login, "login", "", false, "Refreshes the credentials of the registry using the identity of the pod such as IAM roles for service accounts, workload identity or a managed identity")
+	cmd.Flags().StringVarP(&options.DockerConfig, "docker-config", "", "", "The docker config file to write the credentials of the registry to. Defaults to $DOCKER_CONFIG/config.json")
 	return cmd
 }
 
+// Run implements this command
 func (o *StepPreBuildOptions) Run() error {
 	imageName := o.Image
 	if imageName == "" {
@@ -70,23 +80,40 @@ func (o *StepPreBuildOptions) Run() error {
 			imageName = args[0]
 		}
 	}
-	paths := strings.Split(imageName, "/")
-	l := len(paths)
-	if l > 2 {
-		dockerRegistry := paths[0]
-		orgName := paths[l-2]
-		appName := paths[l-1]
-
-		log.Logger().Infof("Docker registry host: %s app name %s/%s", util.ColorInfo(dockerRegistry), util.ColorInfo(orgName), util.ColorInfo(appName))
+	dockerRegistry, _ := registry.SplitImage(imageName)
+	if dockerRegistry == "" {
+		return nil
+	}
+	kubeClient, currentNamespace, err := o.KubeClientAndNamespace()
+	if err != nil {
+		return err
+	}
+	region, _ := kube.ReadRegion(kubeClient, currentNamespace)
+	reg := registry.NewRegistry(dockerRegistry, region)
 
-		kubeClient, currentNamespace, err := o.KubeClientAndNamespace()
-		if err != nil {
-			return err
-		}
-		region, _ := kube.ReadRegion(kubeClient, currentNamespace)
-		if strings.HasSuffix(dockerRegistry, ".amazonaws.com") && strings.Index(dockerRegistry, ".ecr.") > 0 {
-			return amazon.LazyCreateRegistry(kubeClient, currentNamespace, region, dockerRegistry, orgName, appName)
-		}
+	log.Logger().Infof("Docker registry host: %s kind %s image %s", util.ColorInfo(dockerRegistry), util.ColorInfo(reg.Kind()), util.ColorInfo(imageName))
+	err = reg.EnsureRepository(imageName)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create the repository of image %s", imageName)
+	}
+	if !o.Login {
+		return nil
+	}
+	credentials, err := reg.Credentials()
+	if err != nil {
+		return errors.Wrapf(err, "failed to get the credentials of registry %s using the %s", dockerRegistry, reg.Identity())
+	}
+	if credentials == nil {
+		return nil
+	}
+	fileName := o.DockerConfig
+	if fileName == "" {
+		fileName = registry.DockerConfigFile()
+	}
+	err = registry.WriteDockerConfig(fileName, dockerRegistry, credentials)
+	if err != nil {
+		return err
 	}
+	log.Logger().Infof("Logged into registry %s using the %s", util.ColorInfo(dockerRegistry), reg.Identity())
 	return nil
 }
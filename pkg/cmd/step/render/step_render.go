@@ -0,0 +1,40 @@
+package render
+
+import (
+	"github.com/jenkins-x/jx/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/pkg/cmd/opts/step"
+	"github.com/spf13/cobra"
+)
+
+// StepRenderOptions contains the command line flags
+type StepRenderOptions struct {
+	step.StepOptions
+}
+
+// NewCmdStepRender Steps a command object for the "render" command
+func NewCmdStepRender(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &StepRenderOptions{
+		StepOptions: step.StepOptions{
+			CommonOptions: commonOpts,
+		},
+	}
+
+	cmd := &cobra.Command{
+		Use:   "render",
+		Short: "render [command]",
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.AddCommand(NewCmdStepRenderTemplate(commonOpts))
+	return cmd
+}
+
+// Run implements this command
+func (o *StepRenderOptions) Run() error {
+	return o.Cmd.Help()
+}
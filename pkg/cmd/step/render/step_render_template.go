@@ -0,0 +1,154 @@
+package render
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/jenkins-x/jx/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/pkg/cmd/opts/step"
+	stephelm "github.com/jenkins-x/jx/pkg/cmd/step/helm"
+	"github.com/jenkins-x/jx/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/pkg/config"
+	"github.com/jenkins-x/jx/pkg/helm"
+	"github.com/jenkins-x/jx/pkg/io/secrets"
+	"github.com/jenkins-x/jx/pkg/kube"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// StepRenderTemplateOptions contains the command line flags
+type StepRenderTemplateOptions struct {
+	step.StepOptions
+
+	File       string
+	Dir        string
+	OutputFile string
+	NoMasking  bool
+}
+
+var (
+	stepRenderTemplateLong = templates.LongDesc(`
+		Evaluates a values.tmpl.yaml or secrets template file standalone so that the template can be debugged.
+
+		The template is evaluated with the same data and functions as when a chart is built or applied:
+
+		* .Parameters the parameters of the chart loaded from the parameters.yaml file
+		* .Requirements the jx-requirements.yml file
+		* .Environments the environments of the requirements by their key
+		* the sprig functions including env and expandenv
+		* requirement, requirementOrDefault and hasRequirement to access the requirements by a dotted path
+		* vault and vaultOrDefault to read the key of a secret from the secret storage
+		* pemBase64, pemFromBase64 and pemBlock to convert PEM certificates and keys
+		* versionStream to look up a version in the version stream
+
+		The values of the parameters are masked in the output unless --no-masking is specified.
+`)
+
+	stepRenderTemplateExample = templates.Examples(`
+		# evaluates the template using the jx-requirements.yml and parameters.yaml in the current directory
+		jx step render template --file env/values.tmpl.yaml
+
+		# evaluates the template using the requirements and parameters in a different directory
+		jx step render template --file values.tmpl.yaml --dir ../environment-mycluster-dev/env
+`)
+)
+
+// NewCmdStepRenderTemplate creates the command
+func NewCmdStepRenderTemplate(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := StepRenderTemplateOptions{
+		StepOptions: step.StepOptions{
+			CommonOptions: commonOpts,
+		},
+	}
+	cmd := &cobra.Command{
+		Use:     "template",
+		Short:   "Evaluates a values.tmpl.yaml or secrets template file to debug the template",
+		Long:    stepRenderTemplateLong,
+		Example: stepRenderTemplateExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.Flags().StringVarP(&options.File, "file", "f", "", "The template file to evaluate")
+	cmd.Flags().StringVarP(&options.Dir, "dir", "d", "", "The directory used to find the jx-requirements.yml and parameters.yaml files. Defaults to the directory of the template file")
+	cmd.Flags().StringVarP(&options.OutputFile, "output-file", "o", "", "The file to write the output to. Defaults to printing it")
+	cmd.Flags().BoolVarP(&options.NoMasking, "no-masking", "", false, "Prints the values of the parameters instead of masking them")
+	return cmd
+}
+
+// Run implements this command
+func (o *StepRenderTemplateOptions) Run() error {
+	if o.File == "" {
+		return util.MissingOption("file")
+	}
+	exists, err := util.FileExists(o.File)
+	if err != nil {
+		return errors.Wrapf(err, "failed to check if file exists: %s", o.File)
+	}
+	if !exists {
+		return fmt.Errorf("the template file %s does not exist", o.File)
+	}
+	dir := o.Dir
+	if dir == "" {
+		dir = filepath.Dir(o.File)
+	}
+
+	requirements, requirementsFileName, err := config.LoadRequirementsConfig(dir)
+	if err != nil {
+		return err
+	}
+	log.Logger().Debugf("evaluating the template %s with the requirements %s", o.File, requirementsFileName)
+
+	secretURLClient, err := o.GetSecretURLClient(secrets.ToSecretsLocation(string(requirements.SecretStorage)))
+	if err != nil {
+		return errors.Wrap(err, "failed to create a Secret URL client")
+	}
+	devGitInfo, err := o.FindGitInfo(dir)
+	if err != nil {
+		log.Logger().Debugf("could not find a git repository in the directory %s: %s", dir, err.Error())
+	}
+	stephelm.DefaultEnvironments(requirements, devGitInfo)
+
+	params, err := helm.LoadParameters(dir, secretURLClient)
+	if err != nil {
+		return errors.Wrapf(err, "failed to load the parameters in %s", dir)
+	}
+
+	funcMap := helm.NewFunctionMap()
+	helm.AddSecretURLFunctions(funcMap, secretURLClient)
+	resolver, err := o.CreateVersionResolver(requirements.VersionStream.URL, requirements.VersionStream.Ref)
+	if err != nil {
+		return errors.Wrap(err, "failed to create the version resolver")
+	}
+	helm.AddVersionStreamFunctions(funcMap, resolver)
+
+	data, err := helm.ReadValuesYamlFileTemplateOutput(o.File, params, funcMap, requirements)
+	if err != nil {
+		return err
+	}
+	if _, err := helm.LoadValues(data); err != nil {
+		log.Logger().Warnf("the output of the template %s is not valid YAML: %s", o.File, err.Error())
+	}
+
+	text := string(data)
+	if !o.NoMasking {
+		text = kube.NewLogMaskerFromMap(params.AsMap()).MaskLog(text)
+	}
+	if o.OutputFile != "" {
+		err = ioutil.WriteFile(o.OutputFile, []byte(text), util.DefaultWritePermissions)
+		if err != nil {
+			return errors.Wrapf(err, "failed to write the output to %s", o.OutputFile)
+		}
+		log.Logger().Infof("Wrote the output of the template %s to %s", util.ColorInfo(o.File), util.ColorInfo(o.OutputFile))
+		return nil
+	}
+	fmt.Fprint(o.Out, text)
+	return nil
+}
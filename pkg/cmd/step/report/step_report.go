@@ -4,11 +4,13 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strconv"
 
 	"github.com/ghodss/yaml"
 	"github.com/jenkins-x/jx/pkg/cmd/helper"
 	"github.com/jenkins-x/jx/pkg/cmd/opts"
 	"github.com/jenkins-x/jx/pkg/cmd/opts/step"
+	"github.com/jenkins-x/jx/pkg/gits"
 	"github.com/jenkins-x/jx/pkg/log"
 	"github.com/jenkins-x/jx/pkg/util"
 	"github.com/pkg/errors"
@@ -40,8 +42,11 @@ func NewCmdStepReport(commonOpts *opts.CommonOptions) *cobra.Command {
 		},
 	}
 	cmd.AddCommand(NewCmdStepReportChart(commonOpts))
+	cmd.AddCommand(NewCmdStepReportCoverage(commonOpts))
+	cmd.AddCommand(NewCmdStepReportFlaky(commonOpts))
 	cmd.AddCommand(NewCmdStepReportImageVersion(commonOpts))
 	cmd.AddCommand(NewCmdStepReportJUnit(commonOpts))
+	cmd.AddCommand(NewCmdStepReportTests(commonOpts))
 	cmd.AddCommand(NewCmdStepReportVersion(commonOpts))
 	return cmd
 }
@@ -81,3 +86,38 @@ func (o *StepReportOptions) OutputReport(report interface{}, fileName string, ou
 	log.Logger().Infof("generated report at %s", util.ColorInfo(yamlFile))
 	return nil
 }
+
+// GitProvider creates the git provider of the repository of $SOURCE_URL or the current directory
+func (o *StepReportOptions) GitProvider() (gits.GitProvider, error) {
+	gitURL := os.Getenv("SOURCE_URL")
+	if gitURL == "" {
+		gitInfo, err := o.Git().Info("")
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to find the git repository")
+		}
+		gitURL = gitInfo.URL
+	}
+	provider, _, err := o.CreateGitProviderForURLWithoutKind(gitURL)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create the git provider for %s", gitURL)
+	}
+	return provider, nil
+}
+
+// CommentOnPullRequest adds the comment to the pull request of the repository
+func (o *StepReportOptions) CommentOnPullRequest(owner string, repository string, pullRequest string, comment string) error {
+	provider, err := o.GitProvider()
+	if err != nil {
+		return err
+	}
+	prNumber, err := strconv.Atoi(pullRequest)
+	if err != nil {
+		return util.InvalidOptionError("pull-request", pullRequest, err)
+	}
+	pr := &gits.GitPullRequest{
+		Owner:  owner,
+		Repo:   repository,
+		Number: &prNumber,
+	}
+	return provider.AddPRComment(pr, comment)
+}
@@ -0,0 +1,269 @@
+package report
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/jenkins-x/jx/pkg/builds"
+	"github.com/jenkins-x/jx/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/pkg/cmd/opts/step"
+	"github.com/jenkins-x/jx/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/pkg/gits"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/reports"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+const (
+	defaultCoverageContext = "coverage"
+	defaultTargetBranch    = "master"
+)
+
+var (
+	stepReportCoverageLong = templates.LongDesc(`
+		Tracks the code coverage of the tests of the pipeline across builds.
+
+		The supported coverage reports are lcov tracefiles, Cobertura XML reports and go cover profiles generated by
+		'go test -coverprofile'. The format is detected from the content of the reports unless --format is specified.
+
+		The coverage of each commit is stored in the ConfigMap ` + reports.CoverageHistoryConfigMap + ` and can be viewed with 'jx get coverage'.
+		The coverage is compared to the latest coverage of the target branch and reported as a commit status. The commit
+		status fails if the coverage is below --min-coverage or decreased by more than --max-decrease percentage points.
+`)
+
+	stepReportCoverageExample = templates.Examples(`
+		# Report the coverage of the go tests
+		go test -coverprofile=coverage.out ./...
+		jx step report coverage coverage.out
+
+		# Fail the commit status if the coverage is below 60% or decreased by more than 1%
+		jx step report coverage --min-coverage 60 --max-decrease 1 coverage/lcov.info
+`)
+)
+
+// StepReportCoverageOptions contains the command line flags and other helper objects
+type StepReportCoverageOptions struct {
+	StepReportOptions
+	Format       string
+	Owner        string
+	Repository   string
+	Branch       string
+	Build        string
+	PullRequest  string
+	SHA          string
+	TargetBranch string
+	Context      string
+	MinCoverage  float64
+	MaxDecrease  float64
+	NoStatus     bool
+}
+
+// CoverageResult the coverage of a build compared to the target branch
+type CoverageResult struct {
+	Coverage     float64  `json:"coverage"`
+	TargetBranch string   `json:"targetBranch,omitempty"`
+	Delta        *float64 `json:"delta,omitempty"`
+	Failures     []string `json:"failures,omitempty"`
+}
+
+// NewCmdStepReportCoverage Creates a new Command object
+func NewCmdStepReportCoverage(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &StepReportCoverageOptions{
+		StepReportOptions: StepReportOptions{
+			StepOptions: step.StepOptions{
+				CommonOptions: commonOpts,
+			},
+		},
+	}
+
+	cmd := &cobra.Command{
+		Use:     "coverage [files]",
+		Short:   "Tracks the code coverage across builds and reports it as a commit status",
+		Long:    stepReportCoverageLong,
+		Example: stepReportCoverageExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+
+	options.StepReportOptions.AddReportFlags(cmd)
+
+	cmd.Flags().StringVarP(&options.Format, "format", "", "", fmt.Sprintf("The format of the coverage reports. Supported formats are: %s. Detected if not specified", strings.Join(reports.CoverageFormats, ", ")))
+	cmd.Flags().StringVarP(&options.Owner, "owner", "", "", "The Git organisation / owner. Defaults to $REPO_OWNER")
+	cmd.Flags().StringVarP(&options.Repository, "repository", "r", "", "The Git repository. Defaults to $REPO_NAME")
+	cmd.Flags().StringVarP(&options.Branch, "branch", "b", "", "The branch of the pipeline. Defaults to $BRANCH_NAME")
+	cmd.Flags().StringVarP(&options.Build, "build", "", "", "The build number of the pipeline. Defaults to $BUILD_NUMBER")
+	cmd.Flags().StringVarP(&options.PullRequest, "pull-request", "p", "", "The pull request number. Defaults to $PULL_NUMBER")
+	cmd.Flags().StringVarP(&options.SHA, "sha", "", "", "The commit SHA which was tested. Defaults to $PULL_PULL_SHA or the current commit")
+	cmd.Flags().StringVarP(&options.TargetBranch, "target-branch", "", "", "The branch to compare the coverage with. Defaults to $PULL_BASE_REF or "+defaultTargetBranch)
+	cmd.Flags().StringVarP(&options.Context, "context", "", defaultCoverageContext, "The context of the commit status")
+	cmd.Flags().Float64VarP(&options.MinCoverage, "min-coverage", "", 0, "The minimum coverage percentage. The commit status fails if the coverage is lower")
+	cmd.Flags().Float64VarP(&options.MaxDecrease, "max-decrease", "", -1, "The maximum decrease in percentage points compared to the target branch. The commit status fails if the coverage decreased more. Negative values disable the check")
+	cmd.Flags().BoolVarP(&options.NoStatus, "no-status", "", false, "Disables reporting the coverage as a commit status")
+	return cmd
+}
+
+// Run tracks the coverage
+func (o *StepReportCoverageOptions) Run() error {
+	o.defaultFromEnvironment()
+	if len(o.Args) == 0 {
+		return util.MissingArgument("files")
+	}
+	if o.Owner == "" {
+		return util.MissingOption("owner")
+	}
+	if o.Repository == "" {
+		return util.MissingOption("repository")
+	}
+	if o.Format != "" && util.StringArrayIndex(reports.CoverageFormats, o.Format) < 0 {
+		return util.InvalidOption("format", o.Format, reports.CoverageFormats)
+	}
+	if o.SHA == "" {
+		sha, err := o.Git().GetLatestCommitSha("")
+		if err != nil {
+			return errors.Wrap(err, "failed to find the current commit")
+		}
+		o.SHA = sha
+	}
+
+	coverage := &reports.CoverageReport{}
+	for _, fileName := range o.Args {
+		data, err := ioutil.ReadFile(fileName)
+		if err != nil {
+			return errors.Wrapf(err, "failed to read the coverage report %s", fileName)
+		}
+		report, err := reports.ParseCoverage(o.Format, data)
+		if err != nil {
+			return errors.Wrapf(err, "failed to parse the coverage report %s", fileName)
+		}
+		coverage.Add(report)
+	}
+
+	kubeClient, ns, err := o.KubeClientAndDevNamespace()
+	if err != nil {
+		return err
+	}
+	repository := o.Owner + "/" + o.Repository
+	history, err := reports.LoadCoverageHistory(kubeClient, ns, repository)
+	if err != nil {
+		return err
+	}
+	result := o.compareCoverage(coverage, history)
+	history.Add(reports.CoverageRecord{
+		SHA:         o.SHA,
+		Branch:      o.Branch,
+		Build:       o.Build,
+		PullRequest: o.PullRequest,
+		Lines:       coverage.Lines,
+		Covered:     coverage.Covered,
+		Timestamp:   time.Now(),
+	})
+	err = reports.SaveCoverageHistory(kubeClient, ns, repository, history)
+	if err != nil {
+		return err
+	}
+
+	description := CoverageDescription(result)
+	log.Logger().Infof("%s", description)
+	for _, failure := range result.Failures {
+		log.Logger().Warnf("%s", failure)
+	}
+	if o.OutputDir != "" {
+		err = o.OutputReport(result, "coverage.yaml", o.OutputDir)
+		if err != nil {
+			return err
+		}
+	}
+	if o.NoStatus {
+		return nil
+	}
+	return o.updateCommitStatus(result, description)
+}
+
+// compareCoverage compares the coverage with the latest coverage of the target branch and checks the thresholds
+func (o *StepReportCoverageOptions) compareCoverage(coverage *reports.CoverageReport, history *reports.CoverageHistory) *CoverageResult {
+	answer := &CoverageResult{
+		Coverage: coverage.Percent(),
+	}
+	if o.Branch != o.TargetBranch {
+		target := history.LatestForBranch(o.TargetBranch)
+		if target != nil {
+			delta := answer.Coverage - target.Percent()
+			answer.TargetBranch = o.TargetBranch
+			answer.Delta = &delta
+		}
+	}
+	if o.MinCoverage > 0 && answer.Coverage < o.MinCoverage {
+		answer.Failures = append(answer.Failures, fmt.Sprintf("coverage %.1f%% is below the minimum of %.1f%%", answer.Coverage, o.MinCoverage))
+	}
+	if o.MaxDecrease >= 0 && answer.Delta != nil && -*answer.Delta > o.MaxDecrease {
+		answer.Failures = append(answer.Failures, fmt.Sprintf("coverage decreased by %.1f%% which is more than the maximum of %.1f%%", -*answer.Delta, o.MaxDecrease))
+	}
+	return answer
+}
+
+// CoverageDescription returns the description of the coverage used in the commit status
+func CoverageDescription(result *CoverageResult) string {
+	description := fmt.Sprintf("Coverage %.1f%%", result.Coverage)
+	if result.Delta != nil {
+		description += fmt.Sprintf(" (%+.1f%% compared to %s)", *result.Delta, result.TargetBranch)
+	}
+	return description
+}
+
+func (o *StepReportCoverageOptions) updateCommitStatus(result *CoverageResult, description string) error {
+	provider, err := o.GitProvider()
+	if err != nil {
+		return err
+	}
+	status := &gits.GitRepoStatus{
+		Context:     o.Context,
+		State:       "success",
+		Description: description,
+	}
+	if len(result.Failures) > 0 {
+		status.State = "failure"
+		status.Description = description + ": " + strings.Join(result.Failures, ", ")
+	}
+	_, err = provider.UpdateCommitStatus(o.Owner, o.Repository, o.SHA, status)
+	if err != nil {
+		return errors.Wrapf(err, "failed to update the %s commit status of %s", o.Context, o.SHA)
+	}
+	return nil
+}
+
+func (o *StepReportCoverageOptions) defaultFromEnvironment() {
+	if o.Owner == "" {
+		o.Owner = os.Getenv("REPO_OWNER")
+	}
+	if o.Repository == "" {
+		o.Repository = os.Getenv("REPO_NAME")
+	}
+	if o.Branch == "" {
+		o.Branch = os.Getenv(util.EnvVarBranchName)
+	}
+	if o.Build == "" {
+		o.Build = builds.GetBuildNumber()
+	}
+	if o.PullRequest == "" {
+		o.PullRequest = os.Getenv("PULL_NUMBER")
+	}
+	if o.SHA == "" {
+		o.SHA = os.Getenv("PULL_PULL_SHA")
+	}
+	if o.TargetBranch == "" {
+		o.TargetBranch = os.Getenv("PULL_BASE_REF")
+	}
+	if o.TargetBranch == "" {
+		o.TargetBranch = defaultTargetBranch
+	}
+}
@@ -0,0 +1,35 @@
+package report
+
+import (
+	"testing"
+
+	"github.com/jenkins-x/jx/pkg/reports"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompareCoverage(t *testing.T) {
+	history := &reports.CoverageHistory{}
+	history.Add(reports.CoverageRecord{SHA: "sha1", Branch: "master", Lines: 100, Covered: 80})
+	options := &StepReportCoverageOptions{
+		Branch:       "PR-1",
+		TargetBranch: "master",
+		MinCoverage:  50,
+		MaxDecrease:  1,
+	}
+
+	result := options.compareCoverage(&reports.CoverageReport{Lines: 100, Covered: 82}, history)
+	require.NotNil(t, result.Delta)
+	assert.InDelta(t, 2.0, *result.Delta, 0.001)
+	assert.Empty(t, result.Failures)
+	assert.Equal(t, "Coverage 82.0% (+2.0% compared to master)", CoverageDescription(result))
+
+	result = options.compareCoverage(&reports.CoverageReport{Lines: 100, Covered: 40}, history)
+	assert.Len(t, result.Failures, 2, "the coverage is below the minimum and decreased too much")
+	assert.Equal(t, "Coverage 40.0% (-40.0% compared to master)", CoverageDescription(result))
+
+	options.Branch = "master"
+	result = options.compareCoverage(&reports.CoverageReport{Lines: 100, Covered: 70}, history)
+	assert.Nil(t, result.Delta, "the target branch is not compared with itself")
+	assert.Equal(t, "Coverage 70.0%", CoverageDescription(result))
+}
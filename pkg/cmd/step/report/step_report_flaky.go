@@ -0,0 +1,312 @@
+package report
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jenkins-x/jx/pkg/builds"
+	"github.com/jenkins-x/jx/pkg/cloud/buckets"
+	"github.com/jenkins-x/jx/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/pkg/cmd/opts/step"
+	stepcmd "github.com/jenkins-x/jx/pkg/cmd/step"
+	"github.com/jenkins-x/jx/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/pkg/kube"
+	"github.com/jenkins-x/jx/pkg/kube/naming"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/reports"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	defaultRetriggerComment = "/retest"
+	flakyReportName         = "flaky-tests.yaml"
+)
+
+var (
+	stepReportFlakyLong = templates.LongDesc(`
+		Analyses the test reports of the pipeline to detect the failures of known flaky tests.
+
+		The JUnit XML reports (*.xml) and 'go test -json' reports (*.json) are read from --in-dir or $REPORTS_DIR. If no
+		directory is specified the reports stashed with 'jx step stash' are downloaded from the storage location.
+
+		The failure history of each test of the repository is stored in the ConfigMap ` + reports.TestHistoryConfigMap + `.
+		A test which has both failed and passed for the same commit is known to be flaky.
+
+		For pull requests the analysis is commented on the pull request. With --retrigger the pull request pipeline is
+		retriggered once for a commit when only known flaky tests failed.
+`)
+
+	stepReportFlakyExample = templates.Examples(`
+		# Analyse the test reports in $REPORTS_DIR
+		jx step report flaky
+
+		# Analyse the test reports stashed in the reports bucket and retrigger the pull request if only flaky tests failed
+		jx step report flaky --classifier tests --retrigger
+`)
+)
+
+// StepReportFlakyOptions contains the command line flags and other helper objects
+type StepReportFlakyOptions struct {
+	StepReportOptions
+	ReportsDir       string
+	Classifier       string
+	Owner            string
+	Repository       string
+	Branch           string
+	Build            string
+	PullRequest      string
+	SHA              string
+	Retrigger        bool
+	RetriggerComment string
+	NoComment        bool
+	Timeout          time.Duration
+}
+
+// NewCmdStepReportFlaky Creates a new Command object
+func NewCmdStepReportFlaky(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &StepReportFlakyOptions{
+		StepReportOptions: StepReportOptions{
+			StepOptions: step.StepOptions{
+				CommonOptions: commonOpts,
+			},
+		},
+	}
+
+	cmd := &cobra.Command{
+		Use:     "flaky",
+		Short:   "Detects the failures of known flaky tests in the test reports",
+		Long:    stepReportFlakyLong,
+		Example: stepReportFlakyExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+
+	options.StepReportOptions.AddReportFlags(cmd)
+
+	cmd.Flags().StringVarP(&options.ReportsDir, "in-dir", "f", "", "The directory to get the reports from. Defaults to $REPORTS_DIR")
+	cmd.Flags().StringVarP(&options.Classifier, "classifier", "c", kube.ClassificationTests, "The classifier of the reports stashed in the storage location used if no reports directory is specified")
+	cmd.Flags().StringVarP(&options.Owner, "owner", "", "", "The Git organisation / owner. Defaults to $REPO_OWNER")
+	cmd.Flags().StringVarP(&options.Repository, "repository", "r", "", "The Git repository. Defaults to $REPO_NAME")
+	cmd.Flags().StringVarP(&options.Branch, "branch", "b", "", "The branch of the pipeline. Defaults to $BRANCH_NAME")
+	cmd.Flags().StringVarP(&options.Build, "build", "", "", "The build number of the pipeline. Defaults to $BUILD_NUMBER")
+	cmd.Flags().StringVarP(&options.PullRequest, "pull-request", "p", "", "The pull request number. Defaults to $PULL_NUMBER")
+	cmd.Flags().StringVarP(&options.SHA, "sha", "", "", "The commit SHA which was tested. Defaults to $PULL_PULL_SHA or the current commit")
+	cmd.Flags().BoolVarP(&options.Retrigger, "retrigger", "", false, "Retriggers the pull request pipeline once for a commit when only known flaky tests failed")
+	cmd.Flags().StringVarP(&options.RetriggerComment, "retrigger-comment", "", defaultRetriggerComment, "The pull request comment used to retrigger the pipeline")
+	cmd.Flags().BoolVarP(&options.NoComment, "no-comment", "", false, "Disables commenting the analysis on the pull request")
+	cmd.Flags().DurationVarP(&options.Timeout, "timeout", "t", time.Second*30, "The timeout to download each stashed report")
+	return cmd
+}
+
+// Run analyses the test reports
+func (o *StepReportFlakyOptions) Run() error {
+	o.defaultFromEnvironment()
+	if o.Owner == "" {
+		return util.MissingOption("owner")
+	}
+	if o.Repository == "" {
+		return util.MissingOption("repository")
+	}
+	if o.SHA == "" {
+		sha, err := o.Git().GetLatestCommitSha("")
+		if err != nil {
+			log.Logger().Warnf("Could not find the current commit so flaky tests cannot be detected for this run: %s", err)
+		}
+		o.SHA = sha
+	}
+
+	results, err := o.loadTestResults()
+	if err != nil {
+		return err
+	}
+	if len(results) == 0 {
+		log.Logger().Warnf("No test results found for %s/%s", o.Owner, o.Repository)
+		return nil
+	}
+
+	kubeClient, ns, err := o.KubeClientAndDevNamespace()
+	if err != nil {
+		return err
+	}
+	repository := o.Owner + "/" + o.Repository
+	history, err := reports.LoadTestHistory(kubeClient, ns, repository)
+	if err != nil {
+		return err
+	}
+	analysis := history.Analyse(results)
+	history.Record(o.SHA, results)
+
+	retrigger := false
+	if o.Retrigger && o.PullRequest != "" && o.SHA != "" && analysis.OnlyFlakyFailures() {
+		if history.Retriggered[o.PullRequest] == o.SHA {
+			log.Logger().Infof("Not retriggering pull request %s as it was already retriggered for commit %s", o.PullRequest, o.SHA)
+		} else {
+			if history.Retriggered == nil {
+				history.Retriggered = map[string]string{}
+			}
+			history.Retriggered[o.PullRequest] = o.SHA
+			retrigger = true
+		}
+	}
+	err = reports.SaveTestHistory(kubeClient, ns, repository, history)
+	if err != nil {
+		return err
+	}
+
+	log.Logger().Infof("%s tests ran, %s failed of which %s are known to be flaky", util.ColorInfo(analysis.Tests),
+		util.ColorInfo(len(analysis.Failed)), util.ColorInfo(len(analysis.Flaky)))
+	for _, name := range analysis.Flaky {
+		log.Logger().Infof("flaky test failed: %s", util.ColorWarning(name))
+	}
+	if o.OutputDir != "" {
+		err = o.OutputReport(analysis, flakyReportName, o.OutputDir)
+		if err != nil {
+			return err
+		}
+	}
+
+	if o.PullRequest == "" || (len(analysis.Failed) == 0 && !retrigger) {
+		return nil
+	}
+	if !o.NoComment && len(analysis.Failed) > 0 {
+		err = o.CommentOnPullRequest(o.Owner, o.Repository, o.PullRequest, FlakyTestsComment(analysis, retrigger))
+		if err != nil {
+			return err
+		}
+	}
+	if retrigger {
+		err = o.CommentOnPullRequest(o.Owner, o.Repository, o.PullRequest, o.RetriggerComment)
+		if err != nil {
+			return errors.Wrapf(err, "failed to retrigger pull request %s", o.PullRequest)
+		}
+		log.Logger().Infof("Retriggered pull request %s as only known flaky tests failed", util.ColorInfo(o.PullRequest))
+	}
+	return nil
+}
+
+// FlakyTestsComment returns the markdown of the pull request comment describing the analysis
+func FlakyTestsComment(analysis *reports.FlakyTestAnalysis, retrigger bool) string {
+	flaky := map[string]bool{}
+	for _, name := range analysis.Flaky {
+		flaky[name] = true
+	}
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("**%d of %d tests failed, %d of them are known to be flaky**\n\n", len(analysis.Failed), analysis.Tests, len(analysis.Flaky)))
+	sb.WriteString("| Test | Known flaky |\n")
+	sb.WriteString("| --- | --- |\n")
+	for _, name := range analysis.Failed {
+		known := "no"
+		if flaky[name] {
+			known = "yes"
+		}
+		sb.WriteString(fmt.Sprintf("| `%s` | %s |\n", name, known))
+	}
+	if retrigger {
+		sb.WriteString("\nOnly known flaky tests failed so the pipeline is retriggered.\n")
+	} else if analysis.OnlyFlakyFailures() {
+		sb.WriteString("\nOnly known flaky tests failed but the pipeline was already retriggered for this commit.\n")
+	}
+	return sb.String()
+}
+
+func (o *StepReportFlakyOptions) defaultFromEnvironment() {
+	if o.Owner == "" {
+		o.Owner = os.Getenv("REPO_OWNER")
+	}
+	if o.Repository == "" {
+		o.Repository = os.Getenv("REPO_NAME")
+	}
+	if o.Branch == "" {
+		o.Branch = os.Getenv(util.EnvVarBranchName)
+	}
+	if o.Build == "" {
+		o.Build = builds.GetBuildNumber()
+	}
+	if o.PullRequest == "" {
+		o.PullRequest = os.Getenv("PULL_NUMBER")
+	}
+	if o.SHA == "" {
+		o.SHA = os.Getenv("PULL_PULL_SHA")
+	}
+	if o.ReportsDir == "" {
+		o.ReportsDir = os.Getenv("REPORTS_DIR")
+	}
+}
+
+// loadTestResults parses the test reports of the reports directory or the reports stashed for the pipeline
+func (o *StepReportFlakyOptions) loadTestResults() ([]reports.TestCaseResult, error) {
+	answer := []reports.TestCaseResult{}
+	if o.ReportsDir != "" {
+		files, err := ioutil.ReadDir(o.ReportsDir)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read the reports directory %s", o.ReportsDir)
+		}
+		for _, f := range files {
+			ext := strings.ToLower(filepath.Ext(f.Name()))
+			if f.IsDir() || (ext != ".xml" && ext != ".json") {
+				continue
+			}
+			fileName := filepath.Join(o.ReportsDir, f.Name())
+			data, err := ioutil.ReadFile(fileName)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to read the report %s", fileName)
+			}
+			results, err := reports.ParseTestReport(fileName, data)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to parse the report %s", fileName)
+			}
+			answer = append(answer, results...)
+		}
+		return answer, nil
+	}
+
+	if o.Branch == "" || o.Build == "" {
+		return nil, fmt.Errorf("no reports directory specified and the branch and build of the pipeline are unknown to find the stashed reports")
+	}
+	jxClient, ns, err := o.JXClientAndDevNamespace()
+	if err != nil {
+		return nil, err
+	}
+	name := naming.ToValidName(fmt.Sprintf("%s-%s-%s-%s", o.Owner, o.Repository, o.Branch, o.Build))
+	activity, err := jxClient.JenkinsV1().PipelineActivities(ns).Get(name, metav1.GetOptions{})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get the PipelineActivity %s to find the stashed reports", name)
+	}
+	authSvc, err := o.GitAuthConfigService()
+	if err != nil {
+		return nil, err
+	}
+	for _, attachment := range activity.Spec.Attachments {
+		if attachment.Name != o.Classifier {
+			continue
+		}
+		for _, u := range attachment.URLs {
+			ext := strings.ToLower(filepath.Ext(u))
+			if ext != ".xml" && ext != ".json" {
+				continue
+			}
+			data, err := buckets.ReadURL(u, o.Timeout, stepcmd.CreateBucketHTTPFn(authSvc))
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to download the report %s", u)
+			}
+			results, err := reports.ParseTestReport(u, data)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to parse the report %s", u)
+			}
+			answer = append(answer, results...)
+		}
+	}
+	return answer, nil
+}
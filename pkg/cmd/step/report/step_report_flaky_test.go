@@ -0,0 +1,25 @@
+package report
+
+import (
+	"testing"
+
+	"github.com/jenkins-x/jx/pkg/reports"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFlakyTestsComment(t *testing.T) {
+	analysis := &reports.FlakyTestAnalysis{
+		Tests:  10,
+		Failed: []string{"TestCreate", "TestDelete"},
+		Flaky:  []string{"TestDelete"},
+	}
+	comment := FlakyTestsComment(analysis, false)
+	assert.Contains(t, comment, "**2 of 10 tests failed, 1 of them are known to be flaky**")
+	assert.Contains(t, comment, "| `TestCreate` | no |")
+	assert.Contains(t, comment, "| `TestDelete` | yes |")
+	assert.NotContains(t, comment, "retriggered")
+
+	analysis.Failed = []string{"TestDelete"}
+	assert.Contains(t, FlakyTestsComment(analysis, true), "the pipeline is retriggered")
+	assert.Contains(t, FlakyTestsComment(analysis, false), "already retriggered for this commit")
+}
@@ -0,0 +1,290 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jenkins-x/jx/pkg/builds"
+	"github.com/jenkins-x/jx/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/pkg/cmd/opts/step"
+	"github.com/jenkins-x/jx/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/pkg/collector"
+	"github.com/jenkins-x/jx/pkg/kube"
+	"github.com/jenkins-x/jx/pkg/kube/naming"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/reports"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	testReportName = "test-report.json"
+
+	// maxCommentedTests the maximum number of failed tests detailed in the pull request comment
+	maxCommentedTests = 20
+
+	// maxCommentedMessage the maximum length of the failure message of a test in the pull request comment
+	maxCommentedMessage = 1000
+)
+
+var (
+	stepReportTestsLong = templates.LongDesc(`
+		Publishes the results of the tests run by the pipeline.
+
+		The test output is parsed from the given files or from the files of --in-dir or $REPORTS_DIR. The supported
+		formats are JUnit XML reports (*.xml) and the output of 'go test -json' or 'go test -v' (*.json, *.log, *.txt).
+
+		A structured JSON report of the tests is stored in the storage location of the '` + kube.ClassificationReports + `' classifier
+		and the test counts are recorded on the PipelineActivity of the pipeline so they can be compared across builds.
+
+		For pull requests a summary of the tests with the details of the failed tests is commented on the pull request.
+`)
+
+	stepReportTestsExample = templates.Examples(`
+		# Publish the JUnit reports in $REPORTS_DIR
+		jx step report tests
+
+		# Publish the output of go test
+		go test -json ./... > test-output.json
+		jx step report tests --format gotest test-output.json
+`)
+)
+
+// StepReportTestsOptions contains the command line flags and other helper objects
+type StepReportTestsOptions struct {
+	StepReportOptions
+	Format      string
+	ReportsDir  string
+	Owner       string
+	Repository  string
+	Branch      string
+	Build       string
+	PullRequest string
+	NoComment   bool
+	NoUpload    bool
+}
+
+// NewCmdStepReportTests Creates a new Command object
+func NewCmdStepReportTests(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &StepReportTestsOptions{
+		StepReportOptions: StepReportOptions{
+			StepOptions: step.StepOptions{
+				CommonOptions: commonOpts,
+			},
+		},
+	}
+
+	cmd := &cobra.Command{
+		Use:     "tests [files]",
+		Short:   "Publishes the test results of the pipeline and comments the failed tests on the pull request",
+		Long:    stepReportTestsLong,
+		Example: stepReportTestsExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+
+	options.StepReportOptions.AddReportFlags(cmd)
+
+	cmd.Flags().StringVarP(&options.Format, "format", "", reports.TestReportFormatJUnit, fmt.Sprintf("The format of the test output. Supported formats are: %s", strings.Join(reports.TestReportFormats, ", ")))
+	cmd.Flags().StringVarP(&options.ReportsDir, "in-dir", "f", "", "The directory to get the test output from if no files are specified. Defaults to $REPORTS_DIR")
+	cmd.Flags().StringVarP(&options.Owner, "owner", "", "", "The Git organisation / owner. Defaults to $REPO_OWNER")
+	cmd.Flags().StringVarP(&options.Repository, "repository", "r", "", "The Git repository. Defaults to $REPO_NAME")
+	cmd.Flags().StringVarP(&options.Branch, "branch", "b", "", "The branch of the pipeline. Defaults to $BRANCH_NAME")
+	cmd.Flags().StringVarP(&options.Build, "build", "", "", "The build number of the pipeline. Defaults to $BUILD_NUMBER")
+	cmd.Flags().StringVarP(&options.PullRequest, "pull-request", "p", "", "The pull request number. Defaults to $PULL_NUMBER")
+	cmd.Flags().BoolVarP(&options.NoComment, "no-comment", "", false, "Disables commenting the test results on the pull request")
+	cmd.Flags().BoolVarP(&options.NoUpload, "no-upload", "", false, "Disables storing the test report in the storage location")
+	return cmd
+}
+
+// Run publishes the test results
+func (o *StepReportTestsOptions) Run() error {
+	o.defaultFromEnvironment()
+	if util.StringArrayIndex(reports.TestReportFormats, o.Format) < 0 {
+		return util.InvalidOption("format", o.Format, reports.TestReportFormats)
+	}
+
+	results, err := o.parseTestOutput()
+	if err != nil {
+		return err
+	}
+	if len(results) == 0 {
+		log.Logger().Warnf("No %s test results found", o.Format)
+		return nil
+	}
+	report := reports.NewTestReport(results)
+	log.Logger().Infof("%s tests ran: %s passed, %s failed, %s skipped", util.ColorInfo(report.Tests),
+		util.ColorInfo(report.Passed), util.ColorInfo(report.Failed), util.ColorInfo(report.Skipped))
+	for _, result := range report.FailedTests() {
+		log.Logger().Infof("failed test: %s", util.ColorWarning(result.Name))
+	}
+	if o.OutputDir != "" {
+		err = o.OutputReport(report, "test-report.yaml", o.OutputDir)
+		if err != nil {
+			return err
+		}
+	}
+
+	reportURL := ""
+	if !o.NoUpload {
+		reportURL, err = o.uploadReport(report)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = o.recordTestSummary(report, reportURL)
+	if err != nil {
+		return err
+	}
+
+	if o.PullRequest != "" && !o.NoComment {
+		err = o.CommentOnPullRequest(o.Owner, o.Repository, o.PullRequest, TestReportComment(report, reportURL))
+		if err != nil {
+			return errors.Wrapf(err, "failed to comment the test results on pull request %s", o.PullRequest)
+		}
+	}
+	return nil
+}
+
+// TestReportComment returns the markdown of the pull request comment summarising the test report
+func TestReportComment(report *reports.TestReport, reportURL string) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("**%d tests ran: %d passed, %d failed, %d skipped**\n", report.Tests, report.Passed, report.Failed, report.Skipped))
+	failed := report.FailedTests()
+	for i, result := range failed {
+		if i == maxCommentedTests {
+			sb.WriteString(fmt.Sprintf("\n... and %d more failed tests\n", len(failed)-maxCommentedTests))
+			break
+		}
+		sb.WriteString(fmt.Sprintf("\n`%s`\n", result.Name))
+		message := result.Message
+		if message == "" {
+			continue
+		}
+		if len(message) > maxCommentedMessage {
+			message = message[:maxCommentedMessage] + "..."
+		}
+		sb.WriteString(fmt.Sprintf("```\n%s\n```\n", message))
+	}
+	if reportURL != "" {
+		sb.WriteString(fmt.Sprintf("\nThe full test report is stored at %s\n", reportURL))
+	}
+	return sb.String()
+}
+
+func (o *StepReportTestsOptions) defaultFromEnvironment() {
+	if o.Owner == "" {
+		o.Owner = os.Getenv("REPO_OWNER")
+	}
+	if o.Repository == "" {
+		o.Repository = os.Getenv("REPO_NAME")
+	}
+	if o.Branch == "" {
+		o.Branch = os.Getenv(util.EnvVarBranchName)
+	}
+	if o.Build == "" {
+		o.Build = builds.GetBuildNumber()
+	}
+	if o.PullRequest == "" {
+		o.PullRequest = os.Getenv("PULL_NUMBER")
+	}
+	if o.ReportsDir == "" {
+		o.ReportsDir = os.Getenv("REPORTS_DIR")
+	}
+}
+
+// parseTestOutput parses the test output of the given files or of the files in the reports directory
+func (o *StepReportTestsOptions) parseTestOutput() ([]reports.TestCaseResult, error) {
+	fileNames := o.Args
+	if len(fileNames) == 0 {
+		if o.ReportsDir == "" {
+			return nil, fmt.Errorf("no test output files specified and no reports directory specified with --in-dir or $REPORTS_DIR")
+		}
+		files, err := ioutil.ReadDir(o.ReportsDir)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read the reports directory %s", o.ReportsDir)
+		}
+		for _, f := range files {
+			if !f.IsDir() && reports.IsTestOutputFile(o.Format, f.Name()) {
+				fileNames = append(fileNames, filepath.Join(o.ReportsDir, f.Name()))
+			}
+		}
+	}
+	answer := []reports.TestCaseResult{}
+	for _, fileName := range fileNames {
+		data, err := ioutil.ReadFile(fileName)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read the test output %s", fileName)
+		}
+		results, err := reports.ParseTestOutput(o.Format, data)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse the test output %s", fileName)
+		}
+		answer = append(answer, results...)
+	}
+	return answer, nil
+}
+
+// uploadReport stores the JSON test report in the storage location of the reports classifier and returns its URL
+func (o *StepReportTestsOptions) uploadReport(report *reports.TestReport) (string, error) {
+	settings, err := o.TeamSettings()
+	if err != nil {
+		return "", err
+	}
+	storageLocation := settings.StorageLocationOrDefault(kube.ClassificationReports)
+	if storageLocation.IsEmpty() {
+		log.Logger().Warnf("No storage location is configured for classifier %s so the test report is not stored", kube.ClassificationReports)
+		return "", nil
+	}
+	coll, err := collector.NewCollector(storageLocation, o.Git())
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to create the collector for storage settings %s", storageLocation.Description())
+	}
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return "", errors.Wrap(err, "failed to marshal the test report")
+	}
+	storagePath := filepath.Join("jenkins-x", kube.ClassificationReports, o.Owner, o.Repository, o.Branch, o.Build, testReportName)
+	u, err := coll.CollectData(data, storagePath)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to store the test report at %s", storagePath)
+	}
+	log.Logger().Infof("stored test report at %s", util.ColorInfo(u))
+	return u, nil
+}
+
+// recordTestSummary records the test counts on the PipelineActivity of the pipeline
+func (o *StepReportTestsOptions) recordTestSummary(report *reports.TestReport, reportURL string) error {
+	if o.Owner == "" || o.Repository == "" || o.Branch == "" || o.Build == "" {
+		log.Logger().Warnf("The pipeline is unknown so the test results are not recorded on its PipelineActivity")
+		return nil
+	}
+	jxClient, ns, err := o.JXClientAndDevNamespace()
+	if err != nil {
+		return err
+	}
+	activities := jxClient.JenkinsV1().PipelineActivities(ns)
+	name := naming.ToValidName(fmt.Sprintf("%s-%s-%s-%s", o.Owner, o.Repository, o.Branch, o.Build))
+	activity, err := activities.Get(name, metav1.GetOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "failed to get the PipelineActivity %s", name)
+	}
+	activity.Spec.TestSummary = report.Summary(reportURL)
+	_, err = activities.PatchUpdate(activity)
+	if err != nil {
+		return errors.Wrapf(err, "failed to record the test results on the PipelineActivity %s", name)
+	}
+	return nil
+}
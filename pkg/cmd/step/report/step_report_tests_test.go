@@ -0,0 +1,29 @@
+package report
+
+import (
+	"testing"
+
+	"github.com/jenkins-x/jx/pkg/reports"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTestReportComment(t *testing.T) {
+	report := reports.NewTestReport([]reports.TestCaseResult{
+		{Name: "TestCreate"},
+		{Name: "TestDelete", Failed: true, Message: "expected 0 but was 1"},
+		{Name: "TestImport", Failed: true},
+		{Name: "TestUpgrade", Skipped: true},
+	})
+	comment := TestReportComment(report, "gs://reports/jenkins-x/reports/acme/app/PR-1/2/test-report.json")
+	assert.Contains(t, comment, "**4 tests ran: 1 passed, 2 failed, 1 skipped**")
+	assert.Contains(t, comment, "`TestDelete`\n```\nexpected 0 but was 1\n```")
+	assert.Contains(t, comment, "`TestImport`")
+	assert.NotContains(t, comment, "TestUpgrade")
+	assert.Contains(t, comment, "gs://reports/jenkins-x/reports/acme/app/PR-1/2/test-report.json")
+
+	results := []reports.TestCaseResult{}
+	for i := 0; i < maxCommentedTests+5; i++ {
+		results = append(results, reports.TestCaseResult{Name: "TestFails", Failed: true})
+	}
+	assert.Contains(t, TestReportComment(reports.NewTestReport(results), ""), "... and 5 more failed tests")
+}
@@ -0,0 +1,234 @@
+package scan
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/pkg/cmd/opts/step"
+	"github.com/jenkins-x/jx/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/pkg/collector"
+	"github.com/jenkins-x/jx/pkg/cve"
+	"github.com/jenkins-x/jx/pkg/kube"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var (
+	stepScanImageLong = templates.LongDesc(`
+		Scans an image built by the pipeline for vulnerabilities using Trivy or Grype.
+
+		The severity thresholds are read from the imageScanPolicy of the team settings configured in the dev environment
+		repository and can be overridden with the command line flags. The step fails if vulnerabilities of the fail severity
+		or higher are found and warns about the vulnerabilities of the warn severity or higher.
+
+		The scan report is stored in the storage location of the '` + kube.ClassificationReports + `' classifier and the critical
+		vulnerabilities are stored in the ConfigMap ` + cve.ImageScansConfigMap + ` so that 'jx promote' can block the promotion of the
+		image to the protected environments of the policy.
+`)
+
+	stepScanImageExample = templates.Examples(`
+		# Scan the image of the application built by the pipeline
+		jx step scan image --image $DOCKER_REGISTRY/$ORG/$APP_NAME:$VERSION
+
+		# Scan an image with Grype failing if critical vulnerabilities are found
+		jx step scan image --scanner grype --fail-severity CRITICAL --image gcr.io/myorg/myapp:1.2.3
+`)
+)
+
+// StepScanImageOptions contains the command line flags
+type StepScanImageOptions struct {
+	step.StepOptions
+	Image         string
+	Scanner       string
+	ReportFile    string
+	FailSeverity  string
+	WarnSeverity  string
+	IgnoreUnfixed bool
+	NoUpload      bool
+}
+
+// NewCmdStepScanImage creates the command
+func NewCmdStepScanImage(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &StepScanImageOptions{
+		StepOptions: step.StepOptions{
+			CommonOptions: commonOpts,
+		},
+	}
+
+	cmd := &cobra.Command{
+		Use:     "image",
+		Short:   "Scans an image for vulnerabilities failing or warning based on their severity",
+		Long:    stepScanImageLong,
+		Example: stepScanImageExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.Flags().StringVarP(&options.Image, "image", "i", "", "The image to scan. Defaults to $DOCKER_REGISTRY/$DOCKER_REGISTRY_ORG/$APP_NAME:$VERSION")
+	cmd.Flags().StringVarP(&options.Scanner, "scanner", "s", cve.ScannerTrivy, fmt.Sprintf("The image scanner. Supported scanners are: %s", strings.Join(cve.Scanners, ", ")))
+	cmd.Flags().StringVarP(&options.ReportFile, "report-file", "f", "", "Reads the JSON report of a scan which has already been run by the scanner instead of scanning the image")
+	cmd.Flags().StringVarP(&options.FailSeverity, "fail-severity", "", "", "The minimum severity of the vulnerabilities which fail the step. Defaults to the failSeverity of the image scan policy")
+	cmd.Flags().StringVarP(&options.WarnSeverity, "warn-severity", "", "", "The minimum severity of the vulnerabilities which are reported as warnings. Defaults to the warnSeverity of the image scan policy")
+	cmd.Flags().BoolVarP(&options.IgnoreUnfixed, "ignore-unfixed", "", false, "Ignores the vulnerabilities which have no fixed version yet")
+	cmd.Flags().BoolVarP(&options.NoUpload, "no-upload", "", false, "Disables storing the scan report in the storage location")
+	return cmd
+}
+
+// Run implements this command
+func (o *StepScanImageOptions) Run() error {
+	if o.Image == "" {
+		o.Image = DefaultImage()
+	}
+	if o.Image == "" {
+		return util.MissingOption("image")
+	}
+	if util.StringArrayIndex(cve.Scanners, o.Scanner) < 0 {
+		return util.InvalidOption("scanner", o.Scanner, cve.Scanners)
+	}
+	settings, err := o.TeamSettings()
+	if err != nil {
+		return err
+	}
+	policy := o.imageScanPolicy(settings)
+	for name, severity := range map[string]string{"fail-severity": policy.FailSeverity, "warn-severity": policy.WarnSeverity} {
+		if severity != "" && cve.SeverityLevel(severity) < 0 {
+			return util.InvalidOption(name, severity, cve.Severities)
+		}
+	}
+
+	var report *cve.ImageScanReport
+	if o.ReportFile != "" {
+		data, err := ioutil.ReadFile(o.ReportFile)
+		if err != nil {
+			return errors.Wrapf(err, "failed to read the scan report %s", o.ReportFile)
+		}
+		report, err = cve.ParseImageScanReport(o.Scanner, o.Image, data)
+		if err != nil {
+			return err
+		}
+	} else {
+		log.Logger().Infof("Scanning image %s with %s", util.ColorInfo(o.Image), util.ColorInfo(o.Scanner))
+		report, err = cve.ScanImage(o.Scanner, o.Image)
+		if err != nil {
+			return err
+		}
+	}
+	report.ApplyPolicy(policy)
+
+	kubeClient, ns, err := o.KubeClientAndDevNamespace()
+	if err != nil {
+		return err
+	}
+	summary := report.Summary()
+	err = cve.SaveImageScanSummary(kubeClient, ns, summary)
+	if err != nil {
+		return err
+	}
+	if !o.NoUpload {
+		err = o.uploadReport(settings, report)
+		if err != nil {
+			return err
+		}
+	}
+
+	counts := []string{}
+	for i := len(cve.Severities) - 1; i >= 0; i-- {
+		severity := cve.Severities[i]
+		counts = append(counts, fmt.Sprintf("%s %s", util.ColorInfo(summary.Counts[severity]), strings.ToLower(severity)))
+	}
+	log.Logger().Infof("Found %s vulnerabilities in image %s: %s", util.ColorInfo(len(report.Vulnerabilities)), util.ColorInfo(o.Image), strings.Join(counts, ", "))
+
+	warnSeverity := policy.WarnSeverity
+	if warnSeverity == "" {
+		warnSeverity = cve.SeverityLow
+	}
+	for _, v := range report.VulnerabilitiesAtLeast(warnSeverity) {
+		fixed := "no fix available"
+		if v.FixedVersion != "" {
+			fixed = "fixed in " + v.FixedVersion
+		}
+		log.Logger().Warnf("%s %s in %s %s, %s", util.ColorWarning(v.Severity), v.ID, v.Package, v.InstalledVersion, fixed)
+	}
+
+	if policy.FailSeverity != "" {
+		failed := report.VulnerabilitiesAtLeast(policy.FailSeverity)
+		if len(failed) > 0 {
+			return fmt.Errorf("image %s has %d vulnerabilities of severity %s or higher: %s", o.Image, len(failed),
+				strings.ToUpper(policy.FailSeverity), cve.VulnerabilitiesDescription(failed))
+		}
+	}
+	return nil
+}
+
+// imageScanPolicy returns the image scan policy of the team overridden by the command line flags
+func (o *StepScanImageOptions) imageScanPolicy(settings *v1.TeamSettings) *v1.ImageScanPolicy {
+	policy := &v1.ImageScanPolicy{}
+	if settings.ImageScanPolicy != nil {
+		policy = settings.ImageScanPolicy.DeepCopy()
+	}
+	if o.FailSeverity != "" {
+		policy.FailSeverity = o.FailSeverity
+	}
+	if o.WarnSeverity != "" {
+		policy.WarnSeverity = o.WarnSeverity
+	}
+	if o.IgnoreUnfixed {
+		policy.IgnoreUnfixed = true
+	}
+	return policy
+}
+
+// uploadReport stores the JSON scan report in the storage location of the reports classifier
+func (o *StepScanImageOptions) uploadReport(settings *v1.TeamSettings, report *cve.ImageScanReport) error {
+	storageLocation := settings.StorageLocationOrDefault(kube.ClassificationReports)
+	if storageLocation.IsEmpty() {
+		log.Logger().Warnf("No storage location is configured for classifier %s so the scan report is not stored", kube.ClassificationReports)
+		return nil
+	}
+	coll, err := collector.NewCollector(storageLocation, o.Git())
+	if err != nil {
+		return errors.Wrapf(err, "failed to create the collector for storage settings %s", storageLocation.Description())
+	}
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal the scan report")
+	}
+	name, tag := cve.ImageNameAndTag(report.Image)
+	storagePath := filepath.Join("jenkins-x", kube.ClassificationReports, "images", name, tag, report.Scanner+".json")
+	u, err := coll.CollectData(data, storagePath)
+	if err != nil {
+		return errors.Wrapf(err, "failed to store the scan report at %s", storagePath)
+	}
+	log.Logger().Infof("stored scan report at %s", util.ColorInfo(u))
+	return nil
+}
+
+// DefaultImage returns the image built by the pipeline from the environment variables of the pipeline
+func DefaultImage() string {
+	registry := os.Getenv("DOCKER_REGISTRY")
+	app := os.Getenv("APP_NAME")
+	version := os.Getenv("VERSION")
+	if registry == "" || app == "" || version == "" {
+		return ""
+	}
+	org := os.Getenv("DOCKER_REGISTRY_ORG")
+	if org == "" {
+		org = os.Getenv("REPO_OWNER")
+	}
+	if org == "" {
+		return fmt.Sprintf("%s/%s:%s", registry, app, version)
+	}
+	return fmt.Sprintf("%s/%s/%s:%s", registry, org, app, version)
+}
@@ -0,0 +1,71 @@
+package scan_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/pkg/cmd/opts/step"
+	"github.com/jenkins-x/jx/pkg/cmd/step/scan"
+	"github.com/jenkins-x/jx/pkg/cmd/testhelpers"
+	"github.com/jenkins-x/jx/pkg/cve"
+	"github.com/jenkins-x/jx/pkg/gits"
+	"github.com/jenkins-x/jx/pkg/helm"
+	"github.com/jenkins-x/jx/pkg/kube"
+	resources_test "github.com/jenkins-x/jx/pkg/kube/resources/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestStepScanImage(t *testing.T) {
+	t.Parallel()
+	devEnv := kube.NewPermanentEnvironment("dev")
+	devEnv.Spec.Namespace = "jx"
+	devEnv.Spec.Kind = v1.EnvironmentKindTypeDevelopment
+	devEnv.Spec.TeamSettings.ImageScanPolicy = &v1.ImageScanPolicy{
+		FailSeverity:           cve.SeverityCritical,
+		IgnoredVulnerabilities: []string{"CVE-2019-12900"},
+	}
+
+	options := &scan.StepScanImageOptions{
+		StepOptions: step.StepOptions{
+			CommonOptions: &opts.CommonOptions{},
+		},
+		Image:      "gcr.io/myorg/myapp:0.0.1",
+		Scanner:    cve.ScannerTrivy,
+		ReportFile: filepath.Join("test_data", "trivy.json"),
+		NoUpload:   true,
+	}
+	testhelpers.ConfigureTestOptionsWithResources(options.CommonOptions,
+		[]runtime.Object{},
+		[]runtime.Object{devEnv},
+		gits.NewGitCLI(),
+		nil,
+		helm.NewHelmCLI("helm", helm.V2, "", true),
+		resources_test.NewMockInstaller(),
+	)
+
+	err := options.Run()
+	require.Error(t, err, "the step should fail as the image has a critical vulnerability")
+	assert.Contains(t, err.Error(), "CVE-2019-5482")
+	assert.NotContains(t, err.Error(), "CVE-2019-12900", "ignored vulnerabilities should not fail the step")
+
+	kubeClient, err := options.KubeClient()
+	require.NoError(t, err)
+	summary, err := cve.GetImageScanSummary(kubeClient, "jx", "myapp", "0.0.1")
+	require.NoError(t, err)
+	require.NotNil(t, summary)
+	assert.Equal(t, 1, summary.Counts[cve.SeverityCritical])
+	assert.Equal(t, 1, summary.Counts[cve.SeverityMedium])
+
+	options.FailSeverity = "SEVERE"
+	assert.Error(t, options.Run(), "invalid severities should be rejected")
+
+	options.FailSeverity = cve.SeverityHigh
+	options.IgnoreUnfixed = true
+	err = options.Run()
+	require.Error(t, err, "the fail severity flag should override the team settings")
+	assert.Contains(t, err.Error(), "severity HIGH or higher")
+}
@@ -31,10 +31,12 @@ import (
 	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
 	"github.com/jenkins-x/jx/pkg/cmd/opts"
 	"github.com/jenkins-x/jx/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/pkg/config"
 	"github.com/jenkins-x/jx/pkg/gits"
 	"github.com/jenkins-x/jx/pkg/issues"
 	"github.com/jenkins-x/jx/pkg/kube"
 	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/releasenotes"
 	"github.com/jenkins-x/jx/pkg/util"
 	"github.com/spf13/cobra"
 	"gopkg.in/src-d/go-git.v4/plumbing/object"
@@ -68,6 +70,8 @@ type StepChangelogOptions struct {
 	NoReleaseInDev      bool
 	IncludeMergeCommits bool
 	FailIfFindCommits   bool
+	Publish             bool
+	PublishTargets      []string
 	State               StepChangelogState
 }
 
@@ -131,6 +135,8 @@ e.g. define environment variables GIT_USERNAME and GIT_API_TOKEN
 		This command also generates a Release Custom Resource Definition you can include in your helm chart to give metadata about the changelog of the application along with metadata about the release (git tag, url, commits, issues fixed etc). Including this metadata in a helm charts means we can do things like automatically comment on issues when they hit Staging or Production; or give detailed descriptions of what things have changed when using GitOps to update versions in an environment by referencing the fixed issues in the Pull Request.
 
 		You can opt out of the release YAML generation via the '--generate-yaml=false' option
+
+		The release notes can also be published to the targets defined in the '.jx/release-notes.yaml' file of the project such as a Slack channel, a Confluence page or a branch of a static docs site. Each target can define a go template of the release notes. A target of kind 'release' customises the body of the GitHub / GitLab release. You can disable publishing via the '--publish=false' option
 		
 		To update the release notes on GitHub / Gitea this command needs a git API token.
 
@@ -146,6 +152,9 @@ e.g. define environment variables GIT_USERNAME and GIT_API_TOKEN
 		# specify the version and a header template
 		jx step changelog --header-file docs/dev/changelog-header.md --version 1.2.3
 
+		# only publish the release notes to the 'docs' target of the .jx/release-notes.yaml file
+		jx step changelog --version 1.2.3 --publish-target docs
+
 `)
 
 	GitHubIssueRegex = regexp.MustCompile(`(\#\d+)`)
@@ -189,6 +198,8 @@ func NewCmdStepChangelog(commonOpts *opts.CommonOptions) *cobra.Command {
 	cmd.Flags().BoolVarP(&options.NoReleaseInDev, "no-dev-release", "", false, "Disables the generation of Release CRDs in the development namespace to track releases being performed")
 	cmd.Flags().BoolVarP(&options.IncludeMergeCommits, "include-merge-commits", "", false, "Include merge commits when generating the changelog")
 	cmd.Flags().BoolVarP(&options.FailIfFindCommits, "fail-if-no-commits", "", false, "Do we want to fail the build if we don't find any commits to generate the changelog")
+	cmd.Flags().BoolVarP(&options.Publish, "publish", "", true, "Publish the release notes to the targets defined in the .jx/release-notes.yaml file of the project")
+	cmd.Flags().StringArrayVarP(&options.PublishTargets, "publish-target", "", nil, "The names of the targets in the .jx/release-notes.yaml file to publish to. Defaults to all of the targets")
 
 	cmd.Flags().StringVarP(&options.Header, "header", "", "", "The changelog header in markdown for the changelog. Can use go template expressions on the ReleaseSpec object: https://golang.org/pkg/text/template/")
 	cmd.Flags().StringVarP(&options.HeaderFile, "header-file", "", "", "The file name of the changelog header in markdown for the changelog. Can use go template expressions on the ReleaseSpec object: https://golang.org/pkg/text/template/")
@@ -426,6 +437,21 @@ func (o *StepChangelogOptions) Run() error {
 
 	log.Logger().Debugf("Generated release notes:\n\n%s\n", markdown)
 
+	var releaseNotesConfig *config.ReleaseNotesConfig
+	if o.Publish {
+		releaseNotesConfig, err = config.LoadReleaseNotesConfig(dir)
+		if err != nil {
+			return err
+		}
+	}
+	notes := &releasenotes.Notes{
+		Name:     gitInfo.Name,
+		Version:  strings.TrimPrefix(version, "v"),
+		TagName:  version,
+		Markdown: markdown,
+		Release:  &release.Spec,
+	}
+
 	if version != "" && o.UpdateRelease && foundGitProvider {
 		tags, err := o.Git().FilterTags(o.Dir, version)
 		if err != nil {
@@ -455,17 +481,29 @@ func (o *StepChangelogOptions) Run() error {
 		if foundVTag && !foundTag {
 			tagName = vVersion
 		}
+		notes.TagName = tagName
+		notes.URL = util.UrlJoin(gitInfo.HttpsURL(), "releases/tag", tagName)
+		body := markdown
+		for _, target := range o.releaseNotesTargets(releaseNotesConfig) {
+			if target.Kind == config.ReleaseNotesTargetRelease {
+				body, err = releasenotes.Render(target, notes)
+				if err != nil {
+					return err
+				}
+				break
+			}
+		}
 		releaseInfo := &gits.GitRelease{
 			Name:    version,
 			TagName: tagName,
-			Body:    markdown,
+			Body:    body,
 		}
 		url := releaseInfo.HTMLURL
 		if url == "" {
 			url = releaseInfo.URL
 		}
 		if url == "" {
-			url = util.UrlJoin(gitInfo.HttpsURL(), "releases/tag", tagName)
+			url = notes.URL
 		}
 		err = gitProvider.UpdateRelease(gitInfo.Organisation, gitInfo.Name, tagName, releaseInfo)
 		if err != nil {
@@ -530,6 +568,8 @@ func (o *StepChangelogOptions) Run() error {
 		log.Logger().Infof("%s\n", markdown)
 	}
 
+	o.publishReleaseNotes(releaseNotesConfig, gitInfo, notes)
+
 	o.State.Release = release
 	// now lets marshal the release YAML
 	data, err := yaml.Marshal(release)
@@ -639,6 +679,46 @@ func (o *StepChangelogOptions) Run() error {
 	return nil
 }
 
+// releaseNotesTargets returns the targets of the release notes configuration filtered by the --publish-target flags
+func (o *StepChangelogOptions) releaseNotesTargets(releaseNotesConfig *config.ReleaseNotesConfig) []*config.ReleaseNotesTarget {
+	answer := []*config.ReleaseNotesTarget{}
+	if releaseNotesConfig == nil {
+		return answer
+	}
+	for i := range releaseNotesConfig.Targets {
+		target := &releaseNotesConfig.Targets[i]
+		if len(o.PublishTargets) == 0 || util.StringArrayIndex(o.PublishTargets, target.Name) >= 0 {
+			answer = append(answer, target)
+		}
+	}
+	return answer
+}
+
+// publishReleaseNotes publishes the release notes to the targets other than the release. Failures are only logged
+// so that they do not fail the release
+func (o *StepChangelogOptions) publishReleaseNotes(releaseNotesConfig *config.ReleaseNotesConfig, gitInfo *gits.GitRepository, notes *releasenotes.Notes) {
+	for _, target := range o.releaseNotesTargets(releaseNotesConfig) {
+		if target.Kind == config.ReleaseNotesTargetRelease {
+			continue
+		}
+		publisher, err := releasenotes.NewPublisher(target, o.Git(), gitInfo.URL)
+		if err != nil {
+			log.Logger().Warnf("Failed to create the publisher of the release notes target %s: %s", target.Name, err)
+			continue
+		}
+		u, err := publisher.Publish(notes)
+		if err != nil {
+			log.Logger().Warnf("Failed to publish the release notes to target %s: %s", target.Name, err)
+			continue
+		}
+		if u != "" {
+			log.Logger().Infof("Published the release notes to target %s at %s", util.ColorInfo(target.Name), util.ColorInfo(u))
+		} else {
+			log.Logger().Infof("Published the release notes to target %s", util.ColorInfo(target.Name))
+		}
+	}
+}
+
 func (o *StepChangelogOptions) addCommit(spec *v1.ReleaseSpec, commit *object.Commit, resolver *users.GitUserResolver) {
 	// TODO
 	url := ""
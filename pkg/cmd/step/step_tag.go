@@ -45,6 +45,9 @@ type StepTagFlags struct {
 	ChartsDir            string
 	ChartValueRepository string
 	NoApply              bool
+	Sign                 bool
+	SignKey              string
+	Force                bool
 }
 
 var (
@@ -58,12 +61,21 @@ var (
 		    $ git tag -fa v$(VERSION) -m "Release version $(VERSION)"
 		    $ git push origin v$(VERSION)
 
+		Tags are immutable: if the tag of the version already exists the command fails rather than moving the tag to a
+		different commit, unless '--force' is specified. Use '--sign' to create a GPG signed tag.
+
+		Promotions to the environments protected by the tag policy of the team verify that the git tag, the chart version
+		and the image tag of the release all refer to the same commit.
+
 `)
 
 	stepTagExample = templates.Examples(`
 
 		jx step tag --version 1.0.0
 
+		# create a GPG signed tag
+		jx step tag --version 1.0.0 --sign
+
 `)
 )
 
@@ -94,6 +106,9 @@ func NewCmdStepTag(commonOpts *opts.CommonOptions) *cobra.Command {
 	cmd.Flags().StringVarP(&options.Flags.ChartValueRepository, "charts-value-repository", "r", "", "the fully qualified image name without the version tag. e.g. 'dockerregistry/myorg/myapp'")
 
 	cmd.Flags().BoolVarP(&options.Flags.NoApply, "no-apply", "", false, "Do not push the tag to the server, this is used for example in dry runs")
+	cmd.Flags().BoolVarP(&options.Flags.Sign, "sign", "", false, "Creates a GPG signed tag using the default signing key of the committer")
+	cmd.Flags().StringVarP(&options.Flags.SignKey, "sign-key", "", "", "The GPG key used to sign the tag. Implies --sign")
+	cmd.Flags().BoolVarP(&options.Flags.Force, "force", "f", false, "Moves the tag if it already exists rather than failing")
 
 	return cmd
 }
@@ -117,6 +132,11 @@ func (o *StepTagOptions) Run() error {
 	if o.Flags.Version == "" {
 		return errors.New("No version flag")
 	}
+	tag := "v" + o.Flags.Version
+	err := o.verifyTagDoesNotExist(tag)
+	if err != nil {
+		return err
+	}
 	log.Logger().Debug("looking for charts folder...")
 	chartsDir := o.Flags.ChartsDir
 	if chartsDir == "" {
@@ -130,7 +150,7 @@ func (o *StepTagOptions) Run() error {
 		}
 	}
 	log.Logger().Debugf("updating chart if it exists")
-	err := o.updateChart(o.Flags.Version, chartsDir)
+	err = o.updateChart(o.Flags.Version, chartsDir)
 	if err != nil {
 		return err
 	}
@@ -139,14 +159,18 @@ func (o *StepTagOptions) Run() error {
 		return err
 	}
 
-	tag := "v" + o.Flags.Version
 	log.Logger().Debugf("performing git commit")
 	err = o.Git().AddCommit("", fmt.Sprintf("release %s", o.Flags.Version))
 	if err != nil {
 		return err
 	}
 
-	err = o.Git().CreateTag("", tag, fmt.Sprintf("release %s", o.Flags.Version))
+	message := fmt.Sprintf("release %s", o.Flags.Version)
+	if o.Flags.Sign || o.Flags.SignKey != "" {
+		err = o.Git().CreateSignedTag("", tag, message, o.Flags.SignKey)
+	} else {
+		err = o.Git().CreateTag("", tag, message)
+	}
 	if err != nil {
 		return err
 	}
@@ -166,6 +190,26 @@ func (o *StepTagOptions) Run() error {
 	return nil
 }
 
+// verifyTagDoesNotExist returns an error if the tag already exists locally or in the remote repository unless the
+// tag is forced
+func (o *StepTagOptions) verifyTagDoesNotExist(tag string) error {
+	if o.Flags.Force {
+		return nil
+	}
+	err := o.Git().FetchTags("")
+	if err != nil {
+		log.Logger().Warnf("failed to fetch the git tags so only checking the local tags: %s", err)
+	}
+	tags, err := o.Git().FilterTags("", tag)
+	if err != nil {
+		return err
+	}
+	if util.StringArrayIndex(tags, tag) >= 0 {
+		return fmt.Errorf("the git tag %s already exists. Released versions are immutable so release a new version or use --force to move the tag", tag)
+	}
+	return nil
+}
+
 func (o *StepTagOptions) updateChart(version string, chartsDir string) error {
 	chartFile := filepath.Join(chartsDir, "Chart.yaml")
 
@@ -16,6 +16,7 @@ import (
 	"github.com/jenkins-x/jx/pkg/gits"
 	"github.com/jenkins-x/jx/pkg/util"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"k8s.io/helm/pkg/chartutil"
 )
 
@@ -91,3 +92,29 @@ func TestStepTagCharts(t *testing.T) {
 	assert.True(t, foundRepo, "Failed to find tag '%s' in file %s", step.ValuesYamlRepositoryPrefix, valuesFile)
 	assert.True(t, foundVersion, "Failed to find tag '%s' in file %s", step.ValuesYamlTagPrefix, valuesFile)
 }
+
+// existingTagsGitter a fake git whose tags all exist
+type existingTagsGitter struct {
+	gits.GitFake
+}
+
+func (g *existingTagsGitter) FilterTags(dir string, filter string) ([]string, error) {
+	return []string{filter}, nil
+}
+
+func TestStepTagDeniesRetag(t *testing.T) {
+	t.Parallel()
+
+	o := step.StepTagOptions{
+		StepOptions: step2.StepOptions{
+			CommonOptions: &opts.CommonOptions{},
+		},
+	}
+	o.Flags.Version = "1.2.3"
+	gitter := &existingTagsGitter{}
+	o.SetGit(gitter)
+	err := o.Run()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "v1.2.3 already exists")
+	assert.Empty(t, gitter.GitTags, "should not have created a tag")
+}
@@ -0,0 +1,289 @@
+package step
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/ghodss/yaml"
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/builds"
+	"github.com/jenkins-x/jx/pkg/client/clientset/versioned"
+	"github.com/jenkins-x/jx/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/pkg/cmd/opts/step"
+	"github.com/jenkins-x/jx/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/pkg/kube"
+	"github.com/jenkins-x/jx/pkg/kube/naming"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/prow"
+	"github.com/jenkins-x/jx/pkg/tekton/syntax"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// StepWaitForApprovalOptions contains the command line flags
+type StepWaitForApprovalOptions struct {
+	step.StepOptions
+
+	Name      string
+	Message   string
+	Approvers []string
+	Timeout   string
+	OnTimeout string
+	PollTime  string
+	Dir       string
+	Activity  string
+
+	now   func() time.Time
+	sleep func(time.Duration)
+}
+
+var (
+	stepWaitForApprovalLong = templates.LongDesc(`
+		Pauses the pipeline until the approval step is approved or rejected with 'jx approve pipeline'.
+
+		This command is generated for the approval steps of the pipelines in jenkins-x.yml such as:
+
+		    steps:
+		    - name: production
+		      approval:
+		        message: Release to production?
+		        approvers:
+		        - OWNERS
+		        timeout: 4h
+
+		The approvers are git user names, OWNERS for the approvers in the OWNERS file of the repository or team for
+		any user of the team. Decisions by other users are ignored.
+`)
+
+	stepWaitForApprovalExample = templates.Examples(`
+		# wait up to 4 hours for an approver in the OWNERS file to approve the pipeline
+		jx step wait-for-approval --name production --approver OWNERS --timeout 4h
+`)
+)
+
+// NewCmdStepWaitForApproval creates the CLI command
+func NewCmdStepWaitForApproval(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := StepWaitForApprovalOptions{
+		StepOptions: step.StepOptions{
+			CommonOptions: commonOpts,
+		},
+	}
+	cmd := &cobra.Command{
+		Use:     "wait-for-approval",
+		Short:   "Pauses the pipeline until it is approved",
+		Long:    stepWaitForApprovalLong,
+		Example: stepWaitForApprovalExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.Flags().StringVarP(&options.Name, "name", "n", "", "The name of the approval step [required]")
+	cmd.Flags().StringVarP(&options.Message, "message", "m", "", "The message displayed to the approvers")
+	cmd.Flags().StringArrayVarP(&options.Approvers, "approver", "a", []string{}, "The git user names which can approve, OWNERS for the approvers of the repository or team for any user of the team. Defaults to any user")
+	cmd.Flags().StringVarP(&options.Timeout, opts.OptionTimeout, "t", syntax.DefaultApprovalTimeout, "The duration to wait for the approval")
+	cmd.Flags().StringVarP(&options.OnTimeout, "on-timeout", "", syntax.OnTimeoutFail, "What happens when the timeout expires: fail or approve")
+	cmd.Flags().StringVarP(&options.PollTime, optionPollTime, "", "10s", "The amount of time between checking the approval")
+	cmd.Flags().StringVarP(&options.Dir, "dir", "d", ".", "The directory of the source code containing the OWNERS file")
+	cmd.Flags().StringVarP(&options.Activity, "activity", "", "", "The name of the PipelineActivity of the pipeline. Defaults to the activity of the current build")
+	return cmd
+}
+
+// Run runs the command
+func (o *StepWaitForApprovalOptions) Run() error {
+	if o.Name == "" {
+		return util.MissingOption("name")
+	}
+	timeout, err := time.ParseDuration(o.Timeout)
+	if err != nil {
+		return util.InvalidOptionf(opts.OptionTimeout, o.Timeout, "invalid duration: %s", err)
+	}
+	pollTime, err := time.ParseDuration(o.PollTime)
+	if err != nil {
+		return util.InvalidOptionf(optionPollTime, o.PollTime, "invalid duration: %s", err)
+	}
+	if o.OnTimeout != syntax.OnTimeoutFail && o.OnTimeout != syntax.OnTimeoutApprove {
+		return util.InvalidOption("on-timeout", o.OnTimeout, []string{syntax.OnTimeoutFail, syntax.OnTimeoutApprove})
+	}
+	jxClient, ns, err := o.JXClientAndDevNamespace()
+	if err != nil {
+		return err
+	}
+	if o.Activity == "" {
+		o.Activity, err = o.currentActivityName()
+		if err != nil {
+			return err
+		}
+	}
+	owners, err := o.loadOwners()
+	if err != nil {
+		return err
+	}
+	teamUsers := []string{}
+	if util.StringArrayIndex(o.Approvers, syntax.ApproversTeam) >= 0 {
+		users, err := jxClient.JenkinsV1().Users(ns).List(metav1.ListOptions{})
+		if err != nil {
+			return errors.Wrapf(err, "failed to list the users in namespace %s", ns)
+		}
+		teamUsers = kube.TeamUserLogins(users.Items)
+	}
+	o.commentOnPullRequest()
+	return o.waitForApproval(jxClient, ns, owners, teamUsers, timeout, pollTime)
+}
+
+func (o *StepWaitForApprovalOptions) waitForApproval(jxClient versioned.Interface, ns string, owners []string, teamUsers []string, timeout time.Duration, pollTime time.Duration) error {
+	now := o.now
+	if now == nil {
+		now = time.Now
+	}
+	sleep := o.sleep
+	if sleep == nil {
+		sleep = time.Sleep
+	}
+	activities := jxClient.JenkinsV1().PipelineActivities(ns)
+	activity, err := activities.Get(o.Activity, metav1.GetOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "failed to find the PipelineActivity %s", o.Activity)
+	}
+	requested := now()
+	approval := &kube.PipelineApproval{
+		Name:      o.Name,
+		Message:   o.Message,
+		Approvers: o.Approvers,
+		State:     kube.ApprovalStatePending,
+		Requested: requested,
+		Deadline:  requested.Add(timeout),
+	}
+	err = o.updateApproval(jxClient, ns, activity, approval)
+	if err != nil {
+		return err
+	}
+	log.Logger().Infof("Waiting for the approval %s. To approve run: %s", util.ColorInfo(o.Name),
+		util.ColorInfo(fmt.Sprintf("jx approve pipeline %s --name %s", o.Activity, o.Name)))
+
+	for {
+		activity, err = activities.Get(o.Activity, metav1.GetOptions{})
+		if err != nil {
+			return errors.Wrapf(err, "failed to find the PipelineActivity %s", o.Activity)
+		}
+		current := kube.GetPipelineApproval(activity, o.Name)
+		if current != nil && !current.IsPending() {
+			if kube.IsApprover(current.User, o.Approvers, owners, teamUsers) {
+				if current.State == kube.ApprovalStateApproved {
+					log.Logger().Infof("The approval %s was approved by %s", util.ColorInfo(o.Name), util.ColorInfo(current.User))
+					return nil
+				}
+				return fmt.Errorf("the approval %s was rejected by %s", o.Name, current.User)
+			}
+			log.Logger().Warnf("Ignoring the decision of %s on the approval %s as they are not an approver", current.User, o.Name)
+			err = o.updateApproval(jxClient, ns, activity, approval)
+			if err != nil {
+				return err
+			}
+		}
+		if !now().Before(approval.Deadline) {
+			approval.State = kube.ApprovalStateTimedOut
+			err = o.updateApproval(jxClient, ns, activity, approval)
+			if err != nil {
+				return err
+			}
+			if o.OnTimeout == syntax.OnTimeoutApprove {
+				log.Logger().Infof("The approval %s timed out after %s so the pipeline continues", util.ColorInfo(o.Name), timeout.String())
+				return nil
+			}
+			return fmt.Errorf("the approval %s timed out after %s", o.Name, timeout.String())
+		}
+		sleep(pollTime)
+	}
+}
+
+func (o *StepWaitForApprovalOptions) updateApproval(jxClient versioned.Interface, ns string, activity *v1.PipelineActivity, approval *kube.PipelineApproval) error {
+	err := kube.SetPipelineApproval(activity, approval)
+	if err != nil {
+		return err
+	}
+	_, err = jxClient.JenkinsV1().PipelineActivities(ns).PatchUpdate(activity)
+	if err != nil {
+		return errors.Wrapf(err, "failed to update the approval %s on PipelineActivity %s", approval.Name, activity.Name)
+	}
+	return nil
+}
+
+// currentActivityName returns the name of the PipelineActivity of the current build
+func (o *StepWaitForApprovalOptions) currentActivityName() (string, error) {
+	gitInfo, err := o.FindGitInfo(o.Dir)
+	if err != nil {
+		log.Logger().Debugf("could not find the git repository in %s: %s", o.Dir, err)
+	}
+	appName := ""
+	if gitInfo != nil {
+		appName = gitInfo.Name
+	}
+	pipeline, build := o.GetPipelineName(gitInfo, "", builds.GetBuildNumber(), appName)
+	if pipeline == "" || build == "" {
+		return "", util.MissingOption("activity")
+	}
+	return naming.ToValidName(pipeline + "-" + build), nil
+}
+
+// loadOwners returns the approvers in the OWNERS file of the source code if it exists
+func (o *StepWaitForApprovalOptions) loadOwners() ([]string, error) {
+	if util.StringArrayIndex(o.Approvers, syntax.ApproversOwners) < 0 {
+		return nil, nil
+	}
+	fileName := filepath.Join(o.Dir, "OWNERS")
+	exists, err := util.FileExists(fileName)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		log.Logger().Warnf("There is no OWNERS file in %s so there are no approvers from the OWNERS file", o.Dir)
+		return nil, nil
+	}
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read %s", fileName)
+	}
+	owners := prow.Owners{}
+	err = yaml.Unmarshal(data, &owners)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal %s", fileName)
+	}
+	return owners.Approvers, nil
+}
+
+// commentOnPullRequest lets the approvers know how to approve the pipeline of a Pull Request
+func (o *StepWaitForApprovalOptions) commentOnPullRequest() {
+	number, err := strconv.Atoi(os.Getenv("PULL_NUMBER"))
+	if err != nil || number <= 0 {
+		return
+	}
+	gitInfo, err := o.FindGitInfo(o.Dir)
+	if err != nil {
+		log.Logger().Warnf("Not commenting on the Pull Request as the git repository could not be found: %s", err)
+		return
+	}
+	provider, err := o.GitProviderForURL(gitInfo.URL, "git provider")
+	if err != nil {
+		log.Logger().Warnf("Not commenting on the Pull Request as the git provider could not be created: %s", err)
+		return
+	}
+	comment := fmt.Sprintf("The pipeline is waiting for the approval **%s**.", o.Name)
+	if o.Message != "" {
+		comment += "\n\n" + o.Message
+	}
+	comment += fmt.Sprintf("\n\nTo approve run `jx approve pipeline %s --name %s` or add `--reject` to reject it.", o.Activity, o.Name)
+	err = provider.CreateIssueComment(gitInfo.Organisation, gitInfo.Name, number, comment)
+	if err != nil {
+		log.Logger().Warnf("Failed to comment on Pull Request %d: %s", number, err)
+	}
+}
@@ -0,0 +1,98 @@
+package step
+
+import (
+	"testing"
+	"time"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/client/clientset/versioned/fake"
+	"github.com/jenkins-x/jx/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/pkg/cmd/opts/step"
+	"github.com/jenkins-x/jx/pkg/kube"
+	"github.com/jenkins-x/jx/pkg/tekton/syntax"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestWaitForApproval(t *testing.T) {
+	t.Parallel()
+	ns := "jx"
+	activityName := "myorg-myapp-master-1"
+	testCases := []struct {
+		name      string
+		decisions []kube.PipelineApproval
+		onTimeout string
+		approved  bool
+	}{
+		{
+			name:      "approved by an owner",
+			decisions: []kube.PipelineApproval{{State: kube.ApprovalStateApproved, User: "jstrachan"}},
+			onTimeout: syntax.OnTimeoutFail,
+			approved:  true,
+		},
+		{
+			name: "approval by another user is ignored",
+			decisions: []kube.PipelineApproval{
+				{State: kube.ApprovalStateApproved, User: "someone"},
+				{State: kube.ApprovalStateRejected, User: "jstrachan"},
+			},
+			onTimeout: syntax.OnTimeoutFail,
+			approved:  false,
+		},
+		{
+			name:      "fails on timeout",
+			onTimeout: syntax.OnTimeoutFail,
+			approved:  false,
+		},
+		{
+			name:      "approves on timeout",
+			onTimeout: syntax.OnTimeoutApprove,
+			approved:  true,
+		},
+	}
+	for _, tc := range testCases {
+		jxClient := fake.NewSimpleClientset(&v1.PipelineActivity{
+			ObjectMeta: metav1.ObjectMeta{Name: activityName, Namespace: ns},
+		})
+		now := time.Date(2019, 11, 4, 10, 0, 0, 0, time.UTC)
+		decisions := tc.decisions
+		o := &StepWaitForApprovalOptions{
+			StepOptions: step.StepOptions{
+				CommonOptions: &opts.CommonOptions{},
+			},
+			Name:      "production",
+			Approvers: []string{syntax.ApproversOwners},
+			OnTimeout: tc.onTimeout,
+			Activity:  activityName,
+			now: func() time.Time {
+				return now
+			},
+			sleep: func(d time.Duration) {
+				now = now.Add(d)
+				if len(decisions) == 0 {
+					return
+				}
+				activity, err := jxClient.JenkinsV1().PipelineActivities(ns).Get(activityName, metav1.GetOptions{})
+				require.NoError(t, err)
+				approval := kube.GetPipelineApproval(activity, "production")
+				require.NotNil(t, approval, tc.name)
+				require.True(t, approval.IsPending(), tc.name)
+				approval.State = decisions[0].State
+				approval.User = decisions[0].User
+				decisions = decisions[1:]
+				require.NoError(t, kube.SetPipelineApproval(activity, approval))
+				_, err = jxClient.JenkinsV1().PipelineActivities(ns).Update(activity)
+				require.NoError(t, err)
+			},
+		}
+
+		err := o.waitForApproval(jxClient, ns, []string{"jstrachan"}, nil, time.Hour, time.Minute)
+		if tc.approved {
+			assert.NoError(t, err, tc.name)
+		} else {
+			assert.Error(t, err, tc.name)
+		}
+		assert.Empty(t, decisions, tc.name)
+	}
+}
@@ -33,6 +33,7 @@ func NewCmdStepSyntax(commonOpts *opts.CommonOptions) *cobra.Command {
 	cmd.AddCommand(NewCmdStepSyntaxValidate(commonOpts))
 	cmd.AddCommand(NewCmdStepSyntaxSchema(commonOpts))
 	cmd.AddCommand(NewCmdStepSyntaxEffective(commonOpts))
+	cmd.AddCommand(NewCmdStepSyntaxGraph(commonOpts))
 	return cmd
 }
 
@@ -37,6 +37,7 @@ type StepSyntaxEffectiveOptions struct {
 	BuildPackURL      string
 	BuildPackRef      string
 	Context           string
+	Branch            string
 	CustomImage       string
 	DefaultImage      string
 	UseKaniko         bool
@@ -49,6 +50,9 @@ type StepSyntaxEffectiveOptions struct {
 	OutputFile        string
 	ShortView         bool
 
+	PipelineExtensionsFile string
+	PipelineExtensions     *jenkinsfile.PipelineExtensions
+
 	PodTemplates map[string]*corev1.Pod
 
 	GitInfo         *gits.GitRepository
@@ -58,6 +62,8 @@ type StepSyntaxEffectiveOptions struct {
 var (
 	stepSyntaxEffectiveLong = templates.LongDesc(`
 		Reads the appropriate jenkins-x.yml, depending on context, from the current directory, if one exists, and outputs an effective representation of the pipelines
+
+		Any steps injected into the pipelines of all apps by the pipeline-extensions.yaml file in the team's dev environment repository are included.
 `)
 
 	stepSyntaxEffectiveExample = templates.Examples(`
@@ -67,6 +73,9 @@ var (
 		# view the short version of the effective pipeline
 		jx step syntax effective -s
 
+		# view the effective pipeline using a local pipeline extensions file
+		jx step syntax effective --pipeline-extensions pipeline-extensions.yaml
+
 `)
 )
 
@@ -104,6 +113,7 @@ func (o *StepSyntaxEffectiveOptions) addFlags(cmd *cobra.Command) {
 	cmd.Flags().StringVarP(&o.BuildPackURL, "url", "u", "", "The URL for the build pack Git repository")
 	cmd.Flags().StringVarP(&o.BuildPackRef, "ref", "r", "", "The Git reference (branch,tag,sha) in the Git repository to use")
 	cmd.Flags().StringVarP(&o.Context, "context", "c", "", "The pipeline context if there are multiple separate pipelines for a given branch")
+	cmd.Flags().StringVarP(&o.Branch, "branch", "", "", "The branch the pipelines are for which selects the branch overrides to apply. Defaults to the source branch of the pull request or the current branch")
 	cmd.Flags().StringVarP(&o.ServiceAccount, "service-account", "", "tekton-bot", "The Kubernetes ServiceAccount to use to run the pipeline")
 	cmd.Flags().StringVarP(&o.SourceName, "source", "", "source", "The name of the source repository")
 	cmd.Flags().StringVarP(&o.CustomImage, "image", "", "", "Specify a custom image to use for the steps which overrides the image in the PodTemplates")
@@ -114,25 +124,81 @@ func (o *StepSyntaxEffectiveOptions) addFlags(cmd *cobra.Command) {
 	cmd.Flags().StringVarP(&o.ProjectID, "project-id", "", "", "The cloud project ID. If not specified we default to the install project")
 	cmd.Flags().StringVarP(&o.DockerRegistry, "docker-registry", "", "", "The Docker Registry host name to use which is added as a prefix to docker images")
 	cmd.Flags().StringVarP(&o.DockerRegistryOrg, "docker-registry-org", "", "", "The Docker registry organisation. If blank the git repository owner is used")
+	cmd.Flags().StringVarP(&o.PipelineExtensionsFile, "pipeline-extensions", "", "", "The pipeline extensions file to inject steps into the pipelines. If not specified the "+jenkinsfile.PipelineExtensionsFileName+" file in the dev environment repository is used")
 }
 
 // Run implements this command
 func (o *StepSyntaxEffectiveOptions) Run() error {
-	settings, err := o.TeamSettings()
+	effectiveConfig, err := o.LoadEffectivePipeline()
 	if err != nil {
 		return err
 	}
 
+	if o.ShortView {
+		effectiveConfig = o.makeConcisePipeline(effectiveConfig)
+	}
+
+	effectiveYaml, err := yaml.Marshal(effectiveConfig)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal effective pipeline")
+	}
+	if o.OutDir == "" && o.OutputFile == "" {
+		if o.ShortView {
+			for _, line := range strings.Split(string(effectiveYaml), "\n") {
+				prefix := "command: "
+				idx := strings.Index(line, prefix)
+				if idx >= 0 {
+					line = line[0:idx] + prefix + util.ColorInfo(line[idx+len(prefix):])
+				}
+				fmt.Printf("%s\n", line)
+			}
+		} else {
+			fmt.Printf("%s\n", effectiveYaml)
+		}
+	} else {
+		outputDir := o.OutDir
+		if outputDir == "" {
+			outputDir, err = os.Getwd()
+			if err != nil {
+				return errors.Wrap(err, "failed to get current directory")
+			}
+		}
+		outputFilename := o.OutputFile
+		if outputFilename == "" {
+			outputFilename = "jenkins-x"
+			if o.Context != "" {
+				outputFilename += "-" + o.Context
+			}
+			outputFilename += "-effective.yml"
+		}
+		outputFile := filepath.Join(outputDir, outputFilename)
+		err = ioutil.WriteFile(outputFile, effectiveYaml, util.DefaultWritePermissions)
+		if err != nil {
+			return errors.Wrapf(err, "failed to write effective pipeline to %s", outputFile)
+		}
+		log.Logger().Infof("Effective pipeline written to %s", outputFile)
+	}
+	return nil
+}
+
+// LoadEffectivePipeline loads the project configuration in the current directory and generates the effective version
+// of its pipeline using the build pack, team settings and pipeline extensions
+func (o *StepSyntaxEffectiveOptions) LoadEffectivePipeline() (*config.ProjectConfig, error) {
+	settings, err := o.TeamSettings()
+	if err != nil {
+		return nil, err
+	}
+
 	kubeClient, ns, err := o.KubeClientAndDevNamespace()
 	if err != nil {
-		return errors.Wrap(err, "unable to create Kube client")
+		return nil, errors.Wrap(err, "unable to create Kube client")
 	}
 
 	if o.ProjectID == "" {
 		if !o.RemoteCluster {
 			data, err := kube.ReadInstallValues(kubeClient, ns)
 			if err != nil {
-				return errors.Wrapf(err, "failed to read install values from namespace %s", ns)
+				return nil, errors.Wrapf(err, "failed to read install values from namespace %s", ns)
 			}
 			o.ProjectID = data["projectID"]
 		}
@@ -146,7 +212,7 @@ func (o *StepSyntaxEffectiveOptions) Run() error {
 	if o.VersionResolver == nil {
 		o.VersionResolver, err = o.GetVersionResolver()
 		if err != nil {
-			return err
+			return nil, err
 		}
 	}
 	if o.KanikoImage == "" {
@@ -154,7 +220,7 @@ func (o *StepSyntaxEffectiveOptions) Run() error {
 	}
 	o.KanikoImage, err = o.VersionResolver.ResolveDockerImage(o.KanikoImage)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	if o.Verbose {
 		log.Logger().Info("setting up docker registry\n")
@@ -163,25 +229,34 @@ func (o *StepSyntaxEffectiveOptions) Run() error {
 	if o.DockerRegistry == "" {
 		data, err := kube.GetConfigMapData(kubeClient, kube.ConfigMapJenkinsDockerRegistry, ns)
 		if err != nil {
-			return fmt.Errorf("could not find ConfigMap %s in namespace %s: %s", kube.ConfigMapJenkinsDockerRegistry, ns, err)
+			return nil, fmt.Errorf("could not find ConfigMap %s in namespace %s: %s", kube.ConfigMapJenkinsDockerRegistry, ns, err)
 		}
 		o.DockerRegistry = data["docker.registry"]
 		if o.DockerRegistry == "" {
-			return util.MissingOption("docker-registry")
+			return nil, util.MissingOption("docker-registry")
 		}
 	}
 
 	workingDir, err := os.Getwd()
 	if err != nil {
-		return err
+		return nil, err
 	}
 	o.GitInfo, err = o.FindGitInfo(workingDir)
 	if err != nil {
-		return errors.Wrapf(err, "failed to find git information from dir %s", workingDir)
+		return nil, errors.Wrapf(err, "failed to find git information from dir %s", workingDir)
 	}
 	projectConfig, projectConfigFile, err := o.loadProjectConfig(workingDir)
 	if err != nil {
-		return errors.Wrapf(err, "failed to load project config in dir %s", workingDir)
+		return nil, errors.Wrapf(err, "failed to load project config in dir %s", workingDir)
+	}
+	if o.Branch == "" {
+		o.Branch = os.Getenv("PULL_HEAD_REF")
+	}
+	if o.Branch == "" {
+		o.Branch, err = o.Git().Branch(workingDir)
+		if err != nil {
+			log.Logger().Warnf("failed to find the git branch so not applying any branch overrides: %s", err)
+		}
 	}
 	if o.BuildPackURL == "" || o.BuildPackRef == "" {
 		if projectConfig.BuildPackGitURL != "" {
@@ -196,10 +271,10 @@ func (o *StepSyntaxEffectiveOptions) Run() error {
 		}
 	}
 	if o.BuildPackURL == "" {
-		return util.MissingOption("url")
+		return nil, util.MissingOption("url")
 	}
 	if o.BuildPackRef == "" {
-		return util.MissingOption("ref")
+		return nil, util.MissingOption("ref")
 	}
 
 	if o.Pack == "" {
@@ -208,79 +283,37 @@ func (o *StepSyntaxEffectiveOptions) Run() error {
 	if o.Pack == "" {
 		o.Pack, err = o.DiscoverBuildPack(workingDir, projectConfig, o.Pack)
 		if err != nil {
-			return errors.Wrapf(err, "failed to discover the build pack")
+			return nil, errors.Wrapf(err, "failed to discover the build pack")
 		}
 	}
 
 	if o.Pack == "" {
-		return util.MissingOption("pack")
+		return nil, util.MissingOption("pack")
 	}
 
 	o.PodTemplates, err = kube.LoadPodTemplates(kubeClient, ns)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	packsDir, err := gitresolver.InitBuildPack(o.Git(), o.BuildPackURL, o.BuildPackRef)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	resolver, err := gitresolver.CreateResolver(packsDir, o.Git())
 	if err != nil {
-		return err
-	}
-
-	effectiveConfig, err := o.CreateEffectivePipeline(packsDir, projectConfig, projectConfigFile, resolver)
-	if err != nil {
-		return err
-	}
-
-	if o.ShortView {
-		effectiveConfig = o.makeConcisePipeline(effectiveConfig)
+		return nil, err
 	}
 
-	effectiveYaml, err := yaml.Marshal(effectiveConfig)
-	if err != nil {
-		return errors.Wrap(err, "failed to marshal effective pipeline")
-	}
-	if o.OutDir == "" && o.OutputFile == "" {
-		if o.ShortView {
-			for _, line := range strings.Split(string(effectiveYaml), "\n") {
-				prefix := "command: "
-				idx := strings.Index(line, prefix)
-				if idx >= 0 {
-					line = line[0:idx] + prefix + util.ColorInfo(line[idx+len(prefix):])
-				}
-				fmt.Printf("%s\n", line)
-			}
-		} else {
-			fmt.Printf("%s\n", effectiveYaml)
-		}
-	} else {
-		outputDir := o.OutDir
-		if outputDir == "" {
-			outputDir, err = os.Getwd()
-			if err != nil {
-				return errors.Wrap(err, "failed to get current directory")
-			}
-		}
-		outputFilename := o.OutputFile
-		if outputFilename == "" {
-			outputFilename = "jenkins-x"
-			if o.Context != "" {
-				outputFilename += "-" + o.Context
-			}
-			outputFilename += "-effective.yml"
-		}
-		outputFile := filepath.Join(outputDir, outputFilename)
-		err = ioutil.WriteFile(outputFile, effectiveYaml, util.DefaultWritePermissions)
+	if o.PipelineExtensions == nil {
+		o.PipelineExtensions, err = o.LoadPipelineExtensions()
 		if err != nil {
-			return errors.Wrapf(err, "failed to write effective pipeline to %s", outputFile)
+			return nil, errors.Wrap(err, "failed to load the pipeline extensions")
 		}
-		log.Logger().Infof("Effective pipeline written to %s", outputFile)
 	}
-	return nil
+
+	return o.CreateEffectivePipeline(packsDir, projectConfig, projectConfigFile, resolver)
 }
 
 // CreateEffectivePipeline takes a project config and generates the effective version of the pipeline for it, including
@@ -289,6 +322,15 @@ func (o *StepSyntaxEffectiveOptions) CreateEffectivePipeline(packsDir string, pr
 	name := o.Pack
 	packDir := filepath.Join(packsDir, name)
 
+	noPreview := false
+	if projectConfig.PipelineConfig != nil {
+		var err error
+		noPreview, err = projectConfig.PipelineConfig.ApplyBranchOverrides(o.Branch)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid branch overrides in file %s", projectConfigFile)
+		}
+	}
+
 	pipelineConfig := projectConfig.PipelineConfig
 	if name != "none" {
 		pipelineFile := filepath.Join(packDir, jenkinsfile.PipelineConfigFileName)
@@ -314,11 +356,19 @@ func (o *StepSyntaxEffectiveOptions) CreateEffectivePipeline(packsDir string, pr
 		}
 	} else {
 		pipelineConfig.PopulatePipelinesFromDefault()
+		err := pipelineConfig.Pipelines.ValidateOverrides(nil)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid overrides in file %s", projectConfigFile)
+		}
 	}
 
 	if pipelineConfig == nil {
 		return nil, fmt.Errorf("failed to find PipelineConfig in file %s", projectConfigFile)
 	}
+	if noPreview {
+		log.Logger().Infof("Removing the preview environment steps from the pipelines of branch %s", util.ColorInfo(o.Branch))
+		pipelineConfig.Pipelines.RemovePreviewSteps()
+	}
 
 	err := o.combineEnvVars(pipelineConfig)
 	if err != nil {
@@ -441,6 +491,9 @@ func (o *StepSyntaxEffectiveOptions) createPipelineForKind(kind string, lifecycl
 		}
 	}
 
+	// lets inject any steps the team adds to the pipelines of all apps
+	parsed = o.PipelineExtensions.ApplyToPipeline(kind, parsed)
+
 	// TODO: Seeing weird behavior seemingly related to https://golang.org/doc/faq#nil_error
 	// if err is reused, maybe we need to switch return types (perhaps upstream in build-pipeline)?
 	ctx := context.Background()
@@ -455,6 +508,59 @@ func (o *StepSyntaxEffectiveOptions) createPipelineForKind(kind string, lifecycl
 	return parsed, nil
 }
 
+// LoadPipelineExtensions loads the pipeline extensions from the file specified on the command line or from the dev
+// environment repository, returning nil if there are none
+func (o *StepSyntaxEffectiveOptions) LoadPipelineExtensions() (*jenkinsfile.PipelineExtensions, error) {
+	if o.PipelineExtensionsFile != "" {
+		exists, err := util.FileExists(o.PipelineExtensionsFile)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			return nil, fmt.Errorf("pipeline extensions file %s does not exist", o.PipelineExtensionsFile)
+		}
+		return jenkinsfile.LoadPipelineExtensions(o.PipelineExtensionsFile)
+	}
+
+	jxClient, ns, err := o.JXClientAndDevNamespace()
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to create the jx client")
+	}
+	devEnv, err := kube.GetDevEnvironment(jxClient, ns)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to find the dev environment in namespace %s", ns)
+	}
+	if devEnv == nil || devEnv.Spec.Source.URL == "" {
+		return nil, nil
+	}
+	dir, err := ioutil.TempDir("", "jx-dev-env-")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create a temporary directory")
+	}
+	defer os.RemoveAll(dir)
+
+	gitURL := devEnv.Spec.Source.URL
+	err = o.Git().Clone(gitURL, dir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to clone the dev environment repository %s", gitURL)
+	}
+	ref := devEnv.Spec.Source.Ref
+	if ref != "" && ref != "master" {
+		err = o.Git().Checkout(dir, ref)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to checkout %s of the dev environment repository %s", ref, gitURL)
+		}
+	}
+	extensions, err := jenkinsfile.LoadPipelineExtensionsFromDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	if extensions != nil && o.Verbose {
+		log.Logger().Infof("Injecting %d pipeline extensions from %s", len(extensions.Extensions), util.ColorInfo(gitURL))
+	}
+	return extensions, nil
+}
+
 func (o *StepSyntaxEffectiveOptions) combineEnvVars(projectConfig *jenkinsfile.PipelineConfig) error {
 	// add any custom env vars
 	envMap := make(map[string]corev1.EnvVar)
@@ -0,0 +1,324 @@
+package syntax
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/jenkins-x/jx/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/pkg/cmd/opts/step"
+	"github.com/jenkins-x/jx/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/pkg/config"
+	"github.com/jenkins-x/jx/pkg/jenkinsfile"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/tekton/syntax"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// GraphFormatMermaid renders the graph as a mermaid flowchart
+	GraphFormatMermaid = "mermaid"
+	// GraphFormatDot renders the graph in the graphviz DOT language
+	GraphFormatDot = "dot"
+
+	maxGraphLabelLength = 60
+)
+
+// GraphFormats the supported graph formats
+var GraphFormats = []string{GraphFormatMermaid, GraphFormatDot}
+
+// StepSyntaxGraphOptions contains the command line flags
+type StepSyntaxGraphOptions struct {
+	StepSyntaxEffectiveOptions
+
+	Format string
+	Kind   string
+}
+
+var (
+	stepSyntaxGraphLong = templates.LongDesc(`
+		Renders the effective pipeline, after build pack inheritance, overrides and pipeline extensions, as a graph of
+		its stages and steps so you can see what the pipeline actually executes.
+
+		The graph is written to STDOUT or to the file specified via --output-file.
+`)
+
+	stepSyntaxGraphExample = templates.Examples(`
+		# renders the effective pipelines as a mermaid flowchart
+		jx step syntax graph
+
+		# renders the effective release pipeline as a graphviz image
+		jx step syntax graph --format dot --kind release | dot -Tpng > pipeline.png
+	`)
+)
+
+// NewCmdStepSyntaxGraph Creates a new Command object
+func NewCmdStepSyntaxGraph(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &StepSyntaxGraphOptions{
+		StepSyntaxEffectiveOptions: StepSyntaxEffectiveOptions{
+			StepOptions: step.StepOptions{
+				CommonOptions: commonOpts,
+			},
+		},
+	}
+
+	cmd := &cobra.Command{
+		Use:     "graph",
+		Short:   "Renders the effective pipeline as a graph of its stages and steps",
+		Long:    stepSyntaxGraphLong,
+		Example: stepSyntaxGraphExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+
+	cmd.Flags().StringArrayVarP(&options.CustomEnvs, "env", "e", nil, "List of custom environment variables to be applied to resources that are created")
+	cmd.Flags().StringVarP(&options.Format, "format", "f", GraphFormatMermaid, fmt.Sprintf("The format of the graph. Possible values: %s", strings.Join(GraphFormats, ", ")))
+	cmd.Flags().StringVarP(&options.Kind, "kind", "k", "", fmt.Sprintf("The kind of pipeline to render. Possible values: %s. Defaults to all of them", strings.Join(jenkinsfile.PipelineKinds, ", ")))
+
+	options.addFlags(cmd)
+	return cmd
+}
+
+// Run implements this command
+func (o *StepSyntaxGraphOptions) Run() error {
+	if util.StringArrayIndex(GraphFormats, o.Format) < 0 {
+		return util.InvalidOption("format", o.Format, GraphFormats)
+	}
+	if o.Kind != "" && util.StringArrayIndex(jenkinsfile.PipelineKinds, o.Kind) < 0 {
+		return util.InvalidOption("kind", o.Kind, jenkinsfile.PipelineKinds)
+	}
+
+	effectiveConfig, err := o.LoadEffectivePipeline()
+	if err != nil {
+		return err
+	}
+	graph := CreatePipelineGraph(effectiveConfig, o.Kind)
+	output := graph.Render(o.Format)
+
+	if o.OutputFile == "" {
+		fmt.Fprint(o.Out, output)
+		return nil
+	}
+	err = ioutil.WriteFile(o.OutputFile, []byte(output), util.DefaultWritePermissions)
+	if err != nil {
+		return errors.Wrapf(err, "failed to write the graph to %s", o.OutputFile)
+	}
+	log.Logger().Infof("Pipeline graph written to %s", util.ColorInfo(o.OutputFile))
+	return nil
+}
+
+// PipelineGraph a graph of the stages and steps of pipelines
+type PipelineGraph struct {
+	Clusters []*GraphCluster
+	Edges    []GraphEdge
+}
+
+// GraphCluster a group of nodes such as the steps of a stage
+type GraphCluster struct {
+	ID       string
+	Label    string
+	Nodes    []GraphNode
+	Clusters []*GraphCluster
+}
+
+// GraphNode a node in the graph such as a step
+type GraphNode struct {
+	ID    string
+	Label string
+}
+
+// GraphEdge an edge between two nodes in the graph
+type GraphEdge struct {
+	From string
+	To   string
+}
+
+// CreatePipelineGraph creates the graph of the effective pipelines of the given kind or of all kinds if blank
+func CreatePipelineGraph(projectConfig *config.ProjectConfig, kind string) *PipelineGraph {
+	graph := &PipelineGraph{}
+	if projectConfig == nil || projectConfig.PipelineConfig == nil {
+		return graph
+	}
+	pipelines := projectConfig.PipelineConfig.Pipelines
+	for _, k := range jenkinsfile.PipelineKinds {
+		if kind != "" && k != kind {
+			continue
+		}
+		lifecycles := pipelines.AllMap()[k]
+		if lifecycles == nil || lifecycles.Pipeline == nil {
+			continue
+		}
+		cluster := &GraphCluster{
+			ID:    graphID(k),
+			Label: k,
+		}
+		graph.Clusters = append(graph.Clusters, cluster)
+		graph.addStages(cluster, lifecycles.Pipeline.Stages, false)
+	}
+	return graph
+}
+
+// addStages adds the stages to the cluster returning the IDs of the nodes the stages start and finish with
+func (g *PipelineGraph) addStages(parent *GraphCluster, stages []syntax.Stage, parallel bool) ([]string, []string) {
+	var entries, exits []string
+	for i := range stages {
+		stageEntries, stageExits := g.addStage(parent, &stages[i])
+		if parallel {
+			entries = append(entries, stageEntries...)
+			exits = append(exits, stageExits...)
+			continue
+		}
+		if i == 0 {
+			entries = stageEntries
+		} else {
+			g.connect(exits, stageEntries)
+		}
+		exits = stageExits
+	}
+	return entries, exits
+}
+
+func (g *PipelineGraph) addStage(parent *GraphCluster, stage *syntax.Stage) ([]string, []string) {
+	cluster := &GraphCluster{
+		ID:    graphID(parent.ID, stage.Name),
+		Label: stage.Name,
+	}
+	parent.Clusters = append(parent.Clusters, cluster)
+
+	switch {
+	case len(stage.Steps) > 0:
+		var previous string
+		for i := range stage.Steps {
+			step := &stage.Steps[i]
+			node := GraphNode{
+				ID:    fmt.Sprintf("%s_%d", cluster.ID, i),
+				Label: stepLabel(step),
+			}
+			cluster.Nodes = append(cluster.Nodes, node)
+			if previous != "" {
+				g.Edges = append(g.Edges, GraphEdge{From: previous, To: node.ID})
+			}
+			previous = node.ID
+		}
+		return []string{cluster.Nodes[0].ID}, []string{previous}
+	case len(stage.Stages) > 0:
+		return g.addStages(cluster, stage.Stages, false)
+	case len(stage.Parallel) > 0:
+		return g.addStages(cluster, stage.Parallel, true)
+	default:
+		node := GraphNode{
+			ID:    cluster.ID + "_empty",
+			Label: "(no steps)",
+		}
+		cluster.Nodes = append(cluster.Nodes, node)
+		return []string{node.ID}, []string{node.ID}
+	}
+}
+
+func (g *PipelineGraph) connect(from []string, to []string) {
+	for _, f := range from {
+		for _, t := range to {
+			g.Edges = append(g.Edges, GraphEdge{From: f, To: t})
+		}
+	}
+}
+
+// Render renders the graph in the given format
+func (g *PipelineGraph) Render(format string) string {
+	var buffer bytes.Buffer
+	if format == GraphFormatDot {
+		buffer.WriteString("digraph pipeline {\n")
+		buffer.WriteString("  node [shape=box];\n")
+		for _, c := range g.Clusters {
+			renderDotCluster(&buffer, c, "  ")
+		}
+		for _, e := range g.Edges {
+			buffer.WriteString(fmt.Sprintf("  %s -> %s;\n", e.From, e.To))
+		}
+		buffer.WriteString("}\n")
+		return buffer.String()
+	}
+	buffer.WriteString("graph TD\n")
+	for _, c := range g.Clusters {
+		renderMermaidCluster(&buffer, c, "  ")
+	}
+	for _, e := range g.Edges {
+		buffer.WriteString(fmt.Sprintf("  %s --> %s\n", e.From, e.To))
+	}
+	return buffer.String()
+}
+
+func renderMermaidCluster(buffer *bytes.Buffer, cluster *GraphCluster, indent string) {
+	buffer.WriteString(fmt.Sprintf("%ssubgraph %s[\"%s\"]\n", indent, cluster.ID, mermaidEscape(cluster.Label)))
+	for _, n := range cluster.Nodes {
+		buffer.WriteString(fmt.Sprintf("%s  %s[\"%s\"]\n", indent, n.ID, mermaidEscape(n.Label)))
+	}
+	for _, c := range cluster.Clusters {
+		renderMermaidCluster(buffer, c, indent+"  ")
+	}
+	buffer.WriteString(indent + "end\n")
+}
+
+func renderDotCluster(buffer *bytes.Buffer, cluster *GraphCluster, indent string) {
+	buffer.WriteString(fmt.Sprintf("%ssubgraph cluster_%s {\n", indent, cluster.ID))
+	buffer.WriteString(fmt.Sprintf("%s  label=\"%s\";\n", indent, dotEscape(cluster.Label)))
+	for _, n := range cluster.Nodes {
+		buffer.WriteString(fmt.Sprintf("%s  %s [label=\"%s\"];\n", indent, n.ID, dotEscape(n.Label)))
+	}
+	for _, c := range cluster.Clusters {
+		renderDotCluster(buffer, c, indent+"  ")
+	}
+	buffer.WriteString(indent + "}\n")
+}
+
+// stepLabel returns the label of a step using its name and command
+func stepLabel(step *syntax.Step) string {
+	command := strings.TrimSpace(strings.Join(append([]string{step.Command}, step.Arguments...), " "))
+	if step.Loop != nil {
+		command = fmt.Sprintf("loop %s in %s", step.Loop.Variable, strings.Join(step.Loop.Values, ", "))
+	}
+	if step.Approval != nil {
+		command = "approval"
+		if len(step.Approval.Approvers) > 0 {
+			command = fmt.Sprintf("approval by %s", strings.Join(step.Approval.Approvers, ", "))
+		}
+	}
+	command = strings.Join(strings.Fields(command), " ")
+	if len(command) > maxGraphLabelLength {
+		command = command[:maxGraphLabelLength-3] + "..."
+	}
+	if step.Name == "" {
+		return command
+	}
+	if command == "" {
+		return step.Name
+	}
+	return step.Name + ": " + command
+}
+
+// graphID returns an identifier which is valid in both mermaid and DOT for the given names
+func graphID(names ...string) string {
+	id := strings.Join(names, "_")
+	return strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '_' {
+			return r
+		}
+		return '_'
+	}, id)
+}
+
+func mermaidEscape(text string) string {
+	return strings.Replace(text, "\"", "#quot;", -1)
+}
+
+func dotEscape(text string) string {
+	return strings.Replace(strings.Replace(text, "\\", "\\\\", -1), "\"", "\\\"", -1)
+}
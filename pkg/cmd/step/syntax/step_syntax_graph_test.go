@@ -0,0 +1,87 @@
+package syntax
+
+import (
+	"testing"
+
+	"github.com/jenkins-x/jx/pkg/config"
+	"github.com/jenkins-x/jx/pkg/jenkinsfile"
+	"github.com/jenkins-x/jx/pkg/tekton/syntax"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreatePipelineGraph(t *testing.T) {
+	t.Parallel()
+	projectConfig := &config.ProjectConfig{
+		PipelineConfig: &jenkinsfile.PipelineConfig{
+			Pipelines: jenkinsfile.Pipelines{
+				Release: &jenkinsfile.PipelineLifecycles{
+					Pipeline: &syntax.ParsedPipeline{
+						Stages: []syntax.Stage{
+							{
+								Name: "build",
+								Steps: []syntax.Step{
+									{Name: "compile", Command: "make", Arguments: []string{"build"}},
+									{Name: "image", Command: "skaffold build"},
+								},
+							},
+							{
+								Name: "checks",
+								Parallel: []syntax.Stage{
+									{Name: "test", Steps: []syntax.Step{{Command: "make test"}}},
+									{Name: "lint", Steps: []syntax.Step{{Command: "make \"lint\""}}},
+								},
+							},
+							{
+								Name:  "promote",
+								Steps: []syntax.Step{{Name: "promote", Command: "jx step helm release"}},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	graph := CreatePipelineGraph(projectConfig, "")
+	assert.Equal(t, []GraphEdge{
+		{From: "release_build_0", To: "release_build_1"},
+		{From: "release_build_1", To: "release_checks_test_0"},
+		{From: "release_build_1", To: "release_checks_lint_0"},
+		{From: "release_checks_test_0", To: "release_promote_0"},
+		{From: "release_checks_lint_0", To: "release_promote_0"},
+	}, graph.Edges)
+
+	expectedMermaid := `graph TD
+  subgraph release["release"]
+    subgraph release_build["build"]
+      release_build_0["compile: make build"]
+      release_build_1["image: skaffold build"]
+    end
+    subgraph release_checks["checks"]
+      subgraph release_checks_test["test"]
+        release_checks_test_0["make test"]
+      end
+      subgraph release_checks_lint["lint"]
+        release_checks_lint_0["make #quot;lint#quot;"]
+      end
+    end
+    subgraph release_promote["promote"]
+      release_promote_0["promote: jx step helm release"]
+    end
+  end
+  release_build_0 --> release_build_1
+  release_build_1 --> release_checks_test_0
+  release_build_1 --> release_checks_lint_0
+  release_checks_test_0 --> release_promote_0
+  release_checks_lint_0 --> release_promote_0
+`
+	assert.Equal(t, expectedMermaid, graph.Render(GraphFormatMermaid))
+
+	dot := graph.Render(GraphFormatDot)
+	assert.Contains(t, dot, "digraph pipeline {")
+	assert.Contains(t, dot, "subgraph cluster_release_build {")
+	assert.Contains(t, dot, `release_checks_lint_0 [label="make \"lint\""];`)
+	assert.Contains(t, dot, "release_build_1 -> release_checks_test_0;")
+
+	assert.Empty(t, CreatePipelineGraph(projectConfig, jenkinsfile.PipelineKindPullRequest).Clusters)
+}
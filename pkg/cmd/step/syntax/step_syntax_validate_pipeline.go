@@ -6,6 +6,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/jenkins-x/jx/pkg/cmd/opts/step"
 
@@ -14,10 +15,14 @@ import (
 	"github.com/jenkins-x/jx/pkg/cmd/opts"
 	"github.com/jenkins-x/jx/pkg/cmd/templates"
 	"github.com/jenkins-x/jx/pkg/config"
+	"github.com/jenkins-x/jx/pkg/jenkinsfile"
+	"github.com/jenkins-x/jx/pkg/kube"
 	"github.com/jenkins-x/jx/pkg/log"
 	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/jenkins-x/jx/pkg/versionstream"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
 	"sigs.k8s.io/yaml"
 )
 
@@ -29,15 +34,35 @@ var (
 		# validates the jenkins-x-bdd.yml file in the current directory
 		jx step syntax validate pipeline --context bdd
 
+		# validates the jenkins-x.yml from a git pre-commit hook using a local clone of the version stream
+		jx step syntax validate pipeline --versions-dir ~/jenkins-x-versions
+
 			`)
+
+	validatePipelineLong = templates.LongDesc(`
+		Validates the pipeline YAML file in the current directory for the given context, or jenkins-x.yml by default.
+
+		The file is validated against the schema and then the following semantic checks are performed:
+
+		* the agent and step images are either pod templates, have an explicit version or are in the version stream
+		* the overrides refer to known pipelines and to stages which are defined
+		* environment variables are not defined more than once in the same scope
+		* when expressions are supported
+
+		The command fails if there are any problems so it can be used as a git pre-commit hook or as a pull request check.
+`)
 )
 
 // StepSyntaxValidatePipelineOptions contains the command line flags
 type StepSyntaxValidatePipelineOptions struct {
 	step.StepOptions
 
-	Context string
-	Dir     string
+	Context        string
+	Dir            string
+	VersionsDir    string
+	SkipImageCheck bool
+
+	PodTemplates map[string]*corev1.Pod
 }
 
 // NewCmdStepSyntaxValidatePipeline Creates a new Command object
@@ -51,7 +76,7 @@ func NewCmdStepSyntaxValidatePipeline(commonOpts *opts.CommonOptions) *cobra.Com
 	cmd := &cobra.Command{
 		Use:     "pipeline",
 		Short:   "Validates a pipeline YAML file",
-		Long:    "Validates the pipeline YAML file in the current directory for the given context, or jenkins-x.yml by default",
+		Long:    validatePipelineLong,
 		Example: validatePipeline,
 		Run: func(cmd *cobra.Command, args []string) {
 			options.Cmd = cmd
@@ -63,6 +88,8 @@ func NewCmdStepSyntaxValidatePipeline(commonOpts *opts.CommonOptions) *cobra.Com
 
 	cmd.Flags().StringVarP(&options.Context, "context", "c", "", "The context for the pipeline YAML to validate instead of the default.")
 	cmd.Flags().StringVarP(&options.Dir, "dir", "d", "", "The directory to query to find the pipeline YAML file")
+	cmd.Flags().StringVarP(&options.VersionsDir, "versions-dir", "", "", "The directory containing the version stream used to check images. If not specified the version stream is cloned")
+	cmd.Flags().BoolVarP(&options.SkipImageCheck, "skip-image-check", "", false, "Skips checking the agent and step images are known")
 
 	return cmd
 }
@@ -140,6 +167,24 @@ func (o *StepSyntaxValidatePipelineOptions) Run() error {
 		}
 	}
 
+	if projectConfig.PipelineConfig != nil {
+		var imageChecker jenkinsfile.ImageChecker
+		if !o.SkipImageCheck {
+			imageChecker, err = o.createImageChecker()
+			if err != nil {
+				return err
+			}
+		}
+		semanticErrors := projectConfig.PipelineConfig.ValidateSemantics(imageChecker)
+		if len(semanticErrors) > 0 {
+			hasErrors = true
+			log.Logger().Errorf("One or more semantic validation errors for %s:", pipelineFile)
+			for _, e := range semanticErrors {
+				log.Logger().Errorf("\t%s", e)
+			}
+		}
+	}
+
 	if hasErrors {
 		return errors.New("FAILURE")
 	}
@@ -147,3 +192,60 @@ func (o *StepSyntaxValidatePipelineOptions) Run() error {
 
 	return nil
 }
+
+// createImageChecker creates the function to check that images are either pod templates, explicitly versioned or in the
+// version stream
+func (o *StepSyntaxValidatePipelineOptions) createImageChecker() (jenkinsfile.ImageChecker, error) {
+	versionsDir := o.VersionsDir
+	if versionsDir == "" {
+		resolver, err := o.GetVersionResolver()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to clone the version stream. Use --versions-dir to specify a local clone or --skip-image-check")
+		}
+		versionsDir = resolver.VersionsDir
+	}
+
+	podTemplates := o.PodTemplates
+	if podTemplates == nil {
+		kubeClient, ns, err := o.KubeClientAndDevNamespace()
+		if err == nil {
+			podTemplates, err = kube.LoadPodTemplates(kubeClient, ns)
+		}
+		if err != nil {
+			log.Logger().Warnf("Unable to load the pod templates so assuming any image without a registry is a pod template: %s", err)
+			podTemplates = nil
+		}
+	}
+
+	return func(image string) bool {
+		if podTemplates != nil {
+			if _, ok := podTemplates[image]; ok {
+				return true
+			}
+		} else if !strings.Contains(image, "/") {
+			return true
+		}
+		if isVersionedImage(image) {
+			return true
+		}
+		for _, name := range []string{image, strings.TrimPrefix(image, "docker.io/")} {
+			stableVersion, err := versionstream.LoadStableVersion(versionsDir, versionstream.KindDocker, name)
+			if err == nil && stableVersion.Version != "" {
+				return true
+			}
+		}
+		return false
+	}, nil
+}
+
+// isVersionedImage returns true if the image has an explicit tag or digest
+func isVersionedImage(image string) bool {
+	if strings.Contains(image, "@") {
+		return true
+	}
+	name := image
+	if idx := strings.LastIndex(name, "/"); idx >= 0 {
+		name = name[idx+1:]
+	}
+	return strings.Contains(name, ":")
+}
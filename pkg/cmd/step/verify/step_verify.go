@@ -29,6 +29,7 @@ func NewCmdStepVerify(commonOpts *opts.CommonOptions) *cobra.Command {
 			helper.CheckErr(err)
 		},
 	}
+	cmd.AddCommand(NewCmdStepVerifyArtifactRepository(commonOpts))
 	cmd.AddCommand(NewCmdStepVerifyBehavior(commonOpts))
 	cmd.AddCommand(NewCmdStepVerifyDependencies(commonOpts))
 	cmd.AddCommand(NewCmdStepVerifyEnvironments(commonOpts))
@@ -36,8 +37,10 @@ func NewCmdStepVerify(commonOpts *opts.CommonOptions) *cobra.Command {
 	cmd.AddCommand(NewCmdStepVerifyInstall(commonOpts))
 	cmd.AddCommand(NewCmdStepVerifyPackages(commonOpts))
 	cmd.AddCommand(NewCmdStepVerifyPod(commonOpts))
+	cmd.AddCommand(NewCmdStepVerifyPolicies(commonOpts))
 	cmd.AddCommand(NewCmdStepVerifyPreInstall(commonOpts))
 	cmd.AddCommand(NewCmdStepVerifyRequirements(commonOpts))
+	cmd.AddCommand(NewCmdStepVerifySecrets(commonOpts))
 	cmd.AddCommand(NewCmdStepVerifyURL(commonOpts))
 	cmd.AddCommand(NewCmdStepVerifyValues(commonOpts))
 
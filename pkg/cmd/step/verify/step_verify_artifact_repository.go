@@ -0,0 +1,101 @@
+package verify
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/jenkins-x/jx/pkg/artifacts"
+	"github.com/jenkins-x/jx/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/pkg/cmd/opts/step"
+	"github.com/jenkins-x/jx/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var (
+	stepVerifyArtifactRepositoryLong = templates.LongDesc(`
+		Verifies the artifact repository selected by the 'repository' of the requirements is reachable and accepts the
+		credentials in the Secret ` + artifacts.CredentialsSecret + `
+`)
+
+	stepVerifyArtifactRepositoryExample = templates.Examples(`
+		jx step verify artifact-repository
+`)
+)
+
+// StepVerifyArtifactRepositoryOptions contains the command line flags
+type StepVerifyArtifactRepositoryOptions struct {
+	step.StepOptions
+
+	Dir     string
+	Timeout time.Duration
+}
+
+// NewCmdStepVerifyArtifactRepository creates the command
+func NewCmdStepVerifyArtifactRepository(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &StepVerifyArtifactRepositoryOptions{
+		StepOptions: step.StepOptions{
+			CommonOptions: commonOpts,
+		},
+	}
+	cmd := &cobra.Command{
+		Use:     "artifact-repository",
+		Short:   "Verifies the artifact repository is reachable with its credentials",
+		Long:    stepVerifyArtifactRepositoryLong,
+		Example: stepVerifyArtifactRepositoryExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.Flags().StringVarP(&options.Dir, "dir", "d", ".", "the directory to look for the jx-requirements.yml file")
+	cmd.Flags().DurationVarP(&options.Timeout, optionTimeout, "t", 2*time.Minute, "The timeout for the artifact repository to become reachable")
+	return cmd
+}
+
+// Run implements this command
+func (o *StepVerifyArtifactRepositoryOptions) Run() error {
+	requirements, err := o.TeamRequirements(o.Dir)
+	if err != nil {
+		return err
+	}
+	if !artifacts.IsEnabled(requirements.Repository) {
+		log.Logger().Infof("No artifact repository is used so there is nothing to verify")
+		return nil
+	}
+	endpoints, err := artifacts.GetEndpoints(requirements)
+	if err != nil {
+		return err
+	}
+	kubeClient, ns, err := o.KubeClientAndDevNamespace()
+	if err != nil {
+		return err
+	}
+	credentials, err := artifacts.GetCredentials(kubeClient, ns, requirements)
+	if err != nil {
+		return err
+	}
+	tr, err := util.NewTransport()
+	if err != nil {
+		return errors.Wrap(err, "creating the HTTP transport")
+	}
+	client := &http.Client{Transport: tr, Timeout: 30 * time.Second}
+
+	err = util.Retry(o.Timeout, func() error {
+		err := artifacts.Verify(client, endpoints, credentials)
+		if err != nil {
+			log.Logger().Infof("Retrying due to: %s", err)
+		}
+		return err
+	})
+	if err != nil {
+		return errors.Wrapf(err, "verifying the %s artifact repository", requirements.Repository)
+	}
+	log.Logger().Infof("The %s artifact repository %s is reachable", util.ColorInfo(requirements.Repository), util.ColorInfo(endpoints.MavenURL))
+	return nil
+}
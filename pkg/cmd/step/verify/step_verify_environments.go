@@ -491,6 +491,10 @@ func (o *StepVerifyEnvironmentsOptions) createEnvironmentHelmValues(requirements
 	if envCfg.Ingress.TLS.Enabled {
 		useHTTP = "false"
 		tlsAcme = "true"
+		// self signed certificates are created by jx rather than cert-manager
+		if requirements.IsSelfSignedTLS() {
+			tlsAcme = "false"
+		}
 	}
 	exposer := "Ingress"
 	helmValues := config.HelmValuesConfig{
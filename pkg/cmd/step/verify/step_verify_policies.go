@@ -0,0 +1,133 @@
+package verify
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/jenkins-x/jx/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/pkg/cmd/opts/step"
+	"github.com/jenkins-x/jx/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/policies"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var (
+	stepVerifyPoliciesLong = templates.LongDesc(`
+		Verifies rendered Kubernetes manifests against the OPA/Rego policies of the team using conftest or opa.
+
+		The policies are loaded from the '` + policies.PoliciesDir + `' directory of the git repository of the manifests or, if it has
+		none, from the '` + policies.PoliciesDir + `' directory of the dev environment repository. This lets the team define guardrails
+		such as "no :latest images" or "resources are required" which every boot and environment apply has to satisfy.
+
+		Any 'deny' or 'violation' rules of the policies fail the step and 'warn' rules are reported as warnings.
+
+		The policies are verified automatically by 'jx step helm apply' when a '` + policies.PoliciesDir + `' directory is found.
+`)
+
+	stepVerifyPoliciesExample = templates.Examples(`
+		# verify the rendered manifests in the output directory
+		jx step verify policies --dir output
+
+		# verify the manifests with opa using the policies in the 'k8s' Rego package
+		jx step verify policies --dir output --engine opa --namespace k8s
+`)
+)
+
+// StepVerifyPoliciesOptions contains the command line flags
+type StepVerifyPoliciesOptions struct {
+	step.StepOptions
+
+	Dir       string
+	PolicyDir string
+	Engine    string
+	Namespace string
+}
+
+// NewCmdStepVerifyPolicies creates the command
+func NewCmdStepVerifyPolicies(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &StepVerifyPoliciesOptions{
+		StepOptions: step.StepOptions{
+			CommonOptions: commonOpts,
+		},
+	}
+
+	cmd := &cobra.Command{
+		Use:     "policies",
+		Short:   "Verifies rendered manifests against the OPA/Rego policies of the team",
+		Long:    stepVerifyPoliciesLong,
+		Example: stepVerifyPoliciesExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.Flags().StringVarP(&options.Dir, "dir", "d", "", "The directory or file of the rendered manifests to verify. Defaults to the current directory")
+	cmd.Flags().StringVarP(&options.PolicyDir, "policy-dir", "p", "", "The directory of the policies. Defaults to the '"+policies.PoliciesDir+"' directory of the git repository or the dev environment repository")
+	cmd.Flags().StringVarP(&options.Engine, "engine", "e", "", fmt.Sprintf("The policy engine. Supported engines are: %s. Defaults to the first one found on the PATH", strings.Join(policies.Engines, ", ")))
+	cmd.Flags().StringVarP(&options.Namespace, "namespace", "n", policies.DefaultNamespace, "The Rego package of the policies")
+	return cmd
+}
+
+// Run implements this command
+func (o *StepVerifyPoliciesOptions) Run() error {
+	var err error
+	if o.Dir == "" {
+		o.Dir, err = os.Getwd()
+		if err != nil {
+			return err
+		}
+	}
+	if o.Engine == "" {
+		o.Engine = policies.DetectEngine()
+	}
+	if util.StringArrayIndex(policies.Engines, o.Engine) < 0 {
+		return util.InvalidOption("engine", o.Engine, policies.Engines)
+	}
+	if o.PolicyDir == "" {
+		jxClient, devNs, err := o.JXClientAndDevNamespace()
+		if err != nil {
+			return err
+		}
+		policyDir, cleanup, err := policies.FindPolicyDir(o.Git(), jxClient, devNs, o.Dir)
+		defer cleanup()
+		if err != nil {
+			return err
+		}
+		if policyDir == "" {
+			log.Logger().Infof("No %s directory found so there are no policies to verify", util.ColorInfo(policies.PoliciesDir))
+			return nil
+		}
+		o.PolicyDir = policyDir
+	}
+	exists, err := util.DirExists(o.PolicyDir)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("the policy directory %s does not exist", o.PolicyDir)
+	}
+
+	log.Logger().Infof("Verifying the manifests in %s against the policies in %s using %s", util.ColorInfo(o.Dir), util.ColorInfo(o.PolicyDir), util.ColorInfo(o.Engine))
+	verifier := &policies.Verifier{
+		Engine:    o.Engine,
+		PolicyDir: o.PolicyDir,
+		Namespace: o.Namespace,
+	}
+	result, err := verifier.Verify(o.Dir)
+	if err != nil {
+		return errors.Wrapf(err, "failed to verify the manifests in %s", o.Dir)
+	}
+	err = result.Check()
+	if err != nil {
+		return err
+	}
+	log.Logger().Infof("The manifests satisfy the policies")
+	return nil
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/jenkins-x/jx/pkg/cloud/buckets"
 	"github.com/jenkins-x/jx/pkg/cloud/factory"
 	"github.com/jenkins-x/jx/pkg/cloud/gke"
+	"github.com/jenkins-x/jx/pkg/cloud/preflight"
 	"github.com/jenkins-x/jx/pkg/cmd/create"
 	"github.com/jenkins-x/jx/pkg/cmd/helper"
 	"github.com/jenkins-x/jx/pkg/cmd/namespace"
@@ -47,6 +48,10 @@ type StepVerifyPreInstallOptions struct {
 	TestKanikoSecretData string
 	TestVeleroSecretData string
 	WorkloadIdentity     bool
+	SkipPreflight        bool
+	PreflightNodes       int
+	PreflightIPs         int
+	AWSProfile           string
 }
 
 // NewCmdStepVerifyPreInstall creates the `jx step verify pod` command
@@ -77,6 +82,10 @@ func NewCmdStepVerifyPreInstall(commonOpts *opts.CommonOptions) *cobra.Command {
 	cmd.Flags().StringVarP(&options.Namespace, "namespace", "", "", "the namespace that Jenkins X will be booted into. If not specified it defaults to $DEPLOY_NAMESPACE")
 	cmd.Flags().StringVarP(&options.ProviderValuesDir, "provider-values-dir", "", "", "The optional directory of kubernetes provider specific files")
 	cmd.Flags().BoolVarP(&options.WorkloadIdentity, "workload-identity", "", false, "Enable this if using GKE Workload Identity to avoid reconnecting to the Cluster.")
+	cmd.Flags().BoolVarP(&options.SkipPreflight, "skip-preflight", "", false, "Skips probing the cloud permissions, quotas and kubernetes version before booting")
+	cmd.Flags().IntVarP(&options.PreflightNodes, "preflight-nodes", "", 1, "The number of additional nodes the cloud quotas must have room for such as to surge during node upgrades")
+	cmd.Flags().IntVarP(&options.PreflightIPs, "preflight-ips", "", 1, "The number of additional load balancer IP addresses the cloud quotas must have room for")
+	cmd.Flags().StringVarP(&options.AWSProfile, "aws-profile", "", "", "The AWS profile used to probe the permissions and quotas of EKS clusters")
 
 	return cmd
 }
@@ -119,6 +128,13 @@ func (o *StepVerifyPreInstallOptions) Run() error {
 		return errors.WithStack(err)
 	}
 
+	if !o.SkipPreflight {
+		err = o.verifyCloudPrerequisites(kubeClient, requirements)
+		if err != nil {
+			return err
+		}
+	}
+
 	o.SetDevNamespace(ns)
 
 	log.Logger().Infof("Verifying the kubernetes cluster before we try to boot Jenkins X in namespace: %s", info(ns))
@@ -265,6 +281,60 @@ func (o *StepVerifyPreInstallOptions) Run() error {
 	return nil
 }
 
+// verifyCloudPrerequisites probes the cloud permissions, quotas and the kubernetes version of the cluster without
+// creating any resources so that boot fails early rather than part way through
+func (o *StepVerifyPreInstallOptions) verifyCloudPrerequisites(kubeClient kubernetes.Interface, requirements *config.RequirementsConfig) error {
+	log.Logger().Debug("Verifying the cloud prerequisites...")
+	options := preflight.Options{
+		LazyCreate:    o.LazyCreate,
+		Nodes:         o.PreflightNodes,
+		LoadBalancers: o.PreflightIPs,
+	}
+	results := []preflight.Result{}
+
+	serverVersion, err := kubeClient.Discovery().ServerVersion()
+	if err != nil {
+		log.Logger().Warnf("Failed to get the Kubernetes server version so not checking it is supported: %s", err.Error())
+	} else {
+		resolver, err := o.CreateVersionResolver(requirements.VersionStream.URL, requirements.VersionStream.Ref)
+		if err != nil {
+			return errors.Wrap(err, "failed to create the version resolver")
+		}
+		r, err := preflight.CheckKubernetesVersion(resolver.VersionsDir, requirements.Cluster.Provider, serverVersion.String())
+		if err != nil {
+			return err
+		}
+		results = append(results, r)
+	}
+
+	prober := preflight.NewProber(requirements, o.GCloud(), o.AWSProfile)
+	if prober != nil {
+		permissionResults, err := preflight.CheckPermissions(prober, preflight.RequiredPermissions(requirements, options))
+		if err != nil {
+			return err
+		}
+		results = append(results, permissionResults...)
+
+		quotaResults, err := preflight.CheckQuotas(prober, preflight.RequiredQuotas(requirements.Cluster.Provider, options))
+		if err != nil {
+			return err
+		}
+		results = append(results, quotaResults...)
+	}
+
+	for _, r := range results {
+		if r.Passed {
+			log.Logger().Infof("%s: %s", util.ColorInfo(r.Check), r.Message)
+		} else {
+			log.Logger().Errorf("%s: %s", util.ColorError(r.Check), r.Message)
+			if r.Remediation != "" {
+				log.Logger().Errorf("  %s", r.Remediation)
+			}
+		}
+	}
+	return preflight.Failed(results)
+}
+
 // EnsureHelm ensures helm is installed
 func (o *StepVerifyPreInstallOptions) verifyHelm(ns string) error {
 	log.Logger().Debug("Verifying Helm...")
@@ -598,6 +668,11 @@ func (o *StepVerifyPreInstallOptions) gatherRequirements(requirements *config.Re
 		}
 	}
 
+	err = requirements.ApplyLocalProfile()
+	if err != nil {
+		return nil, errors.Wrap(err, "applying the local cluster profile")
+	}
+
 	if requirements.Cluster.ClusterName == "" && !o.BatchMode {
 		requirements.Cluster.ClusterName, err = util.PickValue("Cluster name", "", true,
 			"The name for your cluster", o.GetIOFileHandles())
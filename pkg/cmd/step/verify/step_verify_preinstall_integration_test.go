@@ -242,6 +242,7 @@ func TestStepVerifyPreInstallSetClusterRequirementsViaEnvars(t *testing.T) {
 func createTestStepVerifyPreInstallOptions(dir string) *verify.StepVerifyPreInstallOptions {
 	options := &verify.StepVerifyPreInstallOptions{
 		DisableVerifyHelm:    true,
+		SkipPreflight:        true,
 		TestKanikoSecretData: "test-kaniko-secret",
 	}
 	// fake the output stream to be checked later
@@ -0,0 +1,139 @@
+package verify
+
+import (
+	"fmt"
+
+	"github.com/jenkins-x/jx/pkg/boot"
+	"github.com/jenkins-x/jx/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/pkg/cmd/opts/step"
+	"github.com/jenkins-x/jx/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/pkg/config"
+	"github.com/jenkins-x/jx/pkg/io/secrets"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/secreturl"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var (
+	verifySecretsLong = templates.LongDesc(`
+		Verifies the secrets expected by the boot configuration exist in the secret backend and in the cluster.
+
+		The expected secrets are found in the '*.tmpl' and '*.tmpl.yaml' templates and the 'parameters.yaml' files of the boot configuration. Secret URIs such as 'vault:path:key' or 'local:path:key' must exist in the secret backend and the Kubernetes Secrets of the templates must exist in the cluster with all their keys.
+
+		With the --diff option the stale values of the Secrets in the cluster which differ from the secret backend and the orphaned keys which are not referenced by any template are reported too.
+`)
+
+	verifySecretsExample = templates.Examples(`
+		# verify the secrets of the boot configuration in the current directory exist
+		jx step verify secrets
+
+		# report missing, stale and orphaned secrets
+		jx step verify secrets --diff
+	`)
+)
+
+// StepVerifySecretsOptions contains the command line flags
+type StepVerifySecretsOptions struct {
+	step.StepOptions
+
+	Dir       string
+	Namespace string
+	Diff      bool
+	NoCluster bool
+
+	// SecretClient secrets URL client (added as a field to be able to easy mock it)
+	SecretClient secreturl.Client
+}
+
+// NewCmdStepVerifySecrets creates the `jx step verify secrets` command
+func NewCmdStepVerifySecrets(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &StepVerifySecretsOptions{
+		StepOptions: step.StepOptions{
+			CommonOptions: commonOpts,
+		},
+	}
+
+	cmd := &cobra.Command{
+		Use:     "secrets",
+		Aliases: []string{"secret"},
+		Short:   "Verifies the secrets expected by the boot configuration exist in the secret backend and in the cluster",
+		Long:    verifySecretsLong,
+		Example: verifySecretsExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.Flags().StringVarP(&options.Dir, "dir", "d", ".", "the directory of the boot configuration to recursively look for secret templates")
+	cmd.Flags().StringVarP(&options.Namespace, "namespace", "n", "", "the namespace of the Secrets whose template does not specify one. Defaults to the development namespace")
+	cmd.Flags().BoolVarP(&options.Diff, "diff", "", false, "reports the stale and orphaned secrets as well as the missing secrets")
+	cmd.Flags().BoolVarP(&options.NoCluster, "no-cluster", "", false, "only verifies the secret backend and not the Secrets in the cluster")
+	return cmd
+}
+
+// Run implements this command
+func (o *StepVerifySecretsOptions) Run() error {
+	requirements, _, err := config.LoadRequirementsConfig(o.Dir)
+	if err != nil {
+		return errors.Wrapf(err, "failed to load boot requirements")
+	}
+	expected, err := boot.FindExpectedSecrets(o.Dir)
+	if err != nil {
+		return err
+	}
+
+	diffOptions := boot.SecretsDiffOptions{
+		Diff: o.Diff,
+	}
+	diffOptions.Backend, err = o.secretClient(requirements.SecretStorage)
+	if err != nil {
+		return errors.Wrap(err, "creating secret client")
+	}
+	if !o.NoCluster {
+		kubeClient, ns, err := o.KubeClientAndDevNamespace()
+		if err != nil {
+			return err
+		}
+		if o.Namespace == "" {
+			o.Namespace = ns
+		}
+		diffOptions.KubeClient = kubeClient
+		diffOptions.Namespace = o.Namespace
+	}
+
+	issues, err := expected.Diff(diffOptions)
+	if err != nil {
+		return errors.Wrap(err, "comparing the expected secrets")
+	}
+	if len(issues) == 0 {
+		log.Logger().Infof("The %d secret references and %d Secrets of the boot configuration are %s", len(expected.References), len(expected.ClusterSecrets), util.ColorInfo("valid"))
+		return nil
+	}
+
+	table := o.CreateTable()
+	table.AddRow("ISSUE", "LOCATION", "SECRET", "KEY", "FILE")
+	for _, issue := range issues {
+		kind := string(issue.Kind)
+		if issue.Kind == boot.SecretIssueMissing {
+			kind = util.ColorError(kind)
+		} else {
+			kind = util.ColorWarning(kind)
+		}
+		table.AddRow(kind, issue.Location, issue.Secret, issue.Key, issue.File)
+	}
+	table.Render()
+	return fmt.Errorf("found %d secret issues", len(issues))
+}
+
+func (o *StepVerifySecretsOptions) secretClient(secretStorage config.SecretStorageType) (secreturl.Client, error) {
+	if o.SecretClient != nil {
+		return o.SecretClient, nil
+	}
+	location := secrets.ToSecretsLocation(string(secretStorage))
+	return o.GetSecretURLClient(location)
+}
@@ -90,7 +90,10 @@ func (o *StepVerifyURLOptions) Run() error {
 		return errors.Wrap(err, "checking flags")
 	}
 
-	tr := &http.Transport{}
+	tr, err := util.NewTransport()
+	if err != nil {
+		return errors.Wrap(err, "creating the HTTP transport")
+	}
 	if o.InsecureSkipVerify {
 		tr.TLSClientConfig = &tls.Config{InsecureSkipVerify: true} // #nosec
 	}
@@ -100,7 +103,7 @@ func (o *StepVerifyURLOptions) Run() error {
 
 	start := time.Now()
 
-	err := util.Retry(o.Timeout, func() error {
+	err = util.Retry(o.Timeout, func() error {
 		resp, err := client.Get(o.Endpoint)
 		if err != nil {
 			return o.logError(err)
@@ -3,6 +3,7 @@ package stop
 import (
 	"fmt"
 	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/jenkins-x/jx/pkg/cmd/get"
@@ -28,6 +29,7 @@ type StopPipelineOptions struct {
 
 	Build           int
 	Filter          string
+	Reason          string
 	JenkinsSelector opts.JenkinsSelectorOptions
 
 	Jobs map[string]gojenkins.Job
@@ -37,11 +39,17 @@ var (
 	stopPipelineLong = templates.LongDesc(`
 		Stops the pipeline build.
 
+		For Tekton pipelines the PipelineRun is cancelled, its running pods are deleted and its PipelineActivity is
+		marked as Aborted with the reason. Queued pipelines are removed from the pipeline queue.
+
 `)
 
 	stopPipelineExample = templates.Examples(`
 		# Stop a pipeline
-		jx stop pipeline foo/bar/master -b 2
+		jx stop pipeline foo/bar/master --build 2
+
+		# Stop the latest build of a pipeline recording why it was stopped
+		jx stop pipeline foo/bar/master --reason "superseded by a newer commit"
 
 		# Select the pipeline to stop
 		jx stop pipeline
@@ -71,6 +79,7 @@ func NewCmdStopPipeline(commonOpts *opts.CommonOptions) *cobra.Command {
 	}
 	cmd.Flags().IntVarP(&options.Build, "build", "", 0, "The build number to stop")
 	cmd.Flags().StringVarP(&options.Filter, "filter", "f", "", "Filters all the available jobs by those that contain the given text")
+	cmd.Flags().StringVarP(&options.Reason, "reason", "", "", "The reason the pipeline is stopped which is recorded on its PipelineActivity")
 	options.JenkinsSelector.AddFlags(cmd)
 
 	return cmd
@@ -155,6 +164,14 @@ func (o *StopPipelineOptions) cancelPipelineRun() error {
 	if err != nil {
 		return errors.Wrap(err, "could not create tekton client")
 	}
+	jxClient, _, err := o.JXClient()
+	if err != nil {
+		return errors.Wrap(err, "could not create jx client")
+	}
+	kubeClient, err := o.KubeClient()
+	if err != nil {
+		return errors.Wrap(err, "could not create kube client")
+	}
 	prList, err := tektonClient.TektonV1alpha1().PipelineRuns(ns).List(metav1.ListOptions{})
 	if err != nil {
 		return errors.Wrapf(err, "failed to list PipelineRuns in namespace %s", ns)
@@ -165,38 +182,29 @@ func (o *StopPipelineOptions) cancelPipelineRun() error {
 	for _, p := range prList.Items {
 		pr := p
 		if !tekton.PipelineRunIsComplete(&pr) {
-			labels := pr.Labels
-			if labels == nil {
+			name := pipelineRunName(&pr)
+			if name == "" {
 				continue
 			}
-			owner := labels[tekton.LabelOwner]
-			repo := labels[tekton.LabelRepo]
-			branch := labels[tekton.LabelBranch]
-			context := labels[tekton.LabelContext]
-			buildNumber := labels[tekton.LabelBuild]
-
-			if owner == "" {
-				log.Logger().Warnf("missing label %s on PipelineRun %s has labels %#v", tekton.LabelOwner, pr.Name, labels)
-				continue
-			}
-			if repo == "" {
-				log.Logger().Warnf("missing label %s on PipelineRun %s has labels %#v", tekton.LabelRepo, pr.Name, labels)
-				continue
-			}
-			if branch == "" {
-				log.Logger().Warnf("missing label %s on PipelineRun %s has labels %#v", tekton.LabelBranch, pr.Name, labels)
-				continue
-			}
-
-			name := fmt.Sprintf("%s/%s/%s #%s", owner, repo, branch, buildNumber)
-
-			if context != "" {
-				name = fmt.Sprintf("%s-%s", name, context)
-			}
 			allNames = append(allNames, name)
 			m[name] = &pr
 		}
 	}
+
+	queue, err := tekton.GetPipelineQueue(jxClient, ns, nil)
+	if err != nil {
+		return err
+	}
+	queued := map[string]*tekton.QueuedPipelineRun{}
+	for _, q := range queue {
+		name := pipelineRunName(q.PipelineRun)
+		if name == "" {
+			continue
+		}
+		allNames = append(allNames, name)
+		queued[name] = q
+	}
+
 	sort.Strings(allNames)
 	names := util.StringsContaining(allNames, o.Filter)
 	if len(names) == 0 {
@@ -216,12 +224,25 @@ func (o *StopPipelineOptions) cancelPipelineRun() error {
 		}
 		args = []string{name}
 	}
+	reason := o.Reason
+	if reason == "" {
+		reason = "stopped by 'jx stop pipeline'"
+	}
 	for _, a := range args {
+		a = o.runNameForArgument(a, names)
+		if q := queued[a]; q != nil {
+			err = tekton.AbortPipelineActivity(jxClient, ns, q.Activity, reason)
+			if err != nil {
+				return errors.Wrapf(err, "failed to remove the queued pipeline %s", a)
+			}
+			log.Logger().Infof("removed the queued PipelineRun %s", util.ColorInfo(q.PipelineRun.Name))
+			continue
+		}
 		pr := m[a]
 		if pr == nil {
 			return fmt.Errorf("no PipelineRun found for name %s", a)
 		}
-		err = tekton.CancelPipelineRun(tektonClient, ns, pr)
+		err = tekton.AbortPipelineRun(kubeClient, tektonClient, jxClient, ns, pr, reason)
 		if err != nil {
 			return errors.Wrapf(err, "failed to cancel pipeline %s in namespace %s", pr.Name, ns)
 		}
@@ -229,3 +250,61 @@ func (o *StopPipelineOptions) cancelPipelineRun() error {
 	}
 	return nil
 }
+
+// runNameForArgument returns the name of the running pipeline for the argument. The argument can be the pipeline name
+// without the build number such as owner/repo/branch in which case the build specified with --build or the latest
+// running build is used
+func (o *StopPipelineOptions) runNameForArgument(arg string, names []string) string {
+	if strings.Contains(arg, " #") {
+		return arg
+	}
+	if o.Build > 0 {
+		return fmt.Sprintf("%s #%d", arg, o.Build)
+	}
+	answer := arg
+	latest := 0
+	for _, name := range names {
+		if !strings.HasPrefix(name, arg+" #") {
+			continue
+		}
+		build, err := strconv.Atoi(strings.SplitN(strings.TrimPrefix(name, arg+" #"), "-", 2)[0])
+		if err == nil && build > latest {
+			latest = build
+			answer = name
+		}
+	}
+	return answer
+}
+
+// pipelineRunName returns the name of the pipeline and build of the PipelineRun or an empty string if it is not labelled
+func pipelineRunName(pr *pipelineapi.PipelineRun) string {
+	labels := pr.Labels
+	if labels == nil {
+		return ""
+	}
+	owner := labels[tekton.LabelOwner]
+	repo := labels[tekton.LabelRepo]
+	branch := labels[tekton.LabelBranch]
+	context := labels[tekton.LabelContext]
+	buildNumber := labels[tekton.LabelBuild]
+
+	if owner == "" {
+		log.Logger().Warnf("missing label %s on PipelineRun %s has labels %#v", tekton.LabelOwner, pr.Name, labels)
+		return ""
+	}
+	if repo == "" {
+		log.Logger().Warnf("missing label %s on PipelineRun %s has labels %#v", tekton.LabelRepo, pr.Name, labels)
+		return ""
+	}
+	if branch == "" {
+		log.Logger().Warnf("missing label %s on PipelineRun %s has labels %#v", tekton.LabelBranch, pr.Name, labels)
+		return ""
+	}
+
+	name := fmt.Sprintf("%s/%s/%s #%s", owner, repo, branch, buildNumber)
+
+	if context != "" {
+		name = fmt.Sprintf("%s-%s", name, context)
+	}
+	return name
+}
@@ -24,6 +24,16 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+const (
+	// SyncModeKsync synchronises files to DevPods via ksync
+	SyncModeKsync = "ksync"
+	// SyncModeNative watches the local files and copies the changes into a running container via kubectl
+	SyncModeNative = "native"
+)
+
+// SyncModes the modes of synchronising files
+var SyncModes = []string{SyncModeKsync, SyncModeNative}
+
 type SyncOptions struct {
 	*opts.CommonOptions
 
@@ -31,19 +41,29 @@ type SyncOptions struct {
 	NoKsyncInit bool
 	SingleMode  bool
 
-	Container string
-	Namespace string
-	Pod       string
-	Dir       string
-	RemoteDir string
-	Reload    bool
-	WatchOnly bool
+	Mode        string
+	Container   string
+	Namespace   string
+	Environment string
+	Pod         string
+	Username    string
+	Dir         string
+	RemoteDir   string
+	OnChange    string
+	Period      time.Duration
+	Reload      bool
+	WatchOnly   bool
 }
 
 var (
 	sync_long = templates.LongDesc(`
 		Synchronises your local files to a DevPod so you an build and test your code easily on the cloud
 
+		By default ksync is used. In native mode the local directory is polled for changes which are copied into the
+		running container of a DevPod or preview pod via kubectl, optionally running a command in the container after
+		each change to rebuild or restart the application. This gives a fast inner loop without running a pipeline for
+		each change.
+
 		For more documentation see: [https://jenkins-x.io/developing/devpods/](https://jenkins-x.io/developing/devpods/)
 
 `)
@@ -51,6 +71,12 @@ var (
 	sync_example = templates.Examples(`
 		# Starts synchronizing the current directory files to the users DevPod
 		jx sync 
+
+		# Watches the current directory and copies the changes into the users DevPod, rebuilding on each change
+		jx sync --mode native --on-change "make build"
+
+		# Copies the changes into the pod of a preview environment and restarts the application
+		jx sync --mode native --environment pr-myapp-12 --pod myapp --remote-dir /app --on-change "kill 1"
 `)
 
 	defaultStignoreFile = `.git
@@ -81,13 +107,16 @@ func NewCmdSync(commonOpts *opts.CommonOptions) *cobra.Command {
 			helper.CheckErr(err)
 		},
 	}
-	/*	cmd.Flags().StringVarP(&options.Container, "container", "c", "", "The name of the container to log")
-		cmd.Flags().StringVarP(&options.Namespace, "namespace", "n", "", "the namespace to look for the Deployment. Defaults to the current namespace")
-		cmd.Flags().StringVarP(&options.Pod, "pod", "p", "", "the pod name to use")
-		cmd.Flags().StringVarP(&options.Dir, "dir", "d", "", "The directory to watch. Defaults to the current directory")
-		cmd.Flags().StringVarP(&options.RemoteDir, "remote-dir", "r", "", "The remote directory in the DevPod to sync")
-		cmd.Flags().BoolVarP(&options.Reload, "reload", "", false, "Should we reload the remote container on file changes?")
-	*/
+	cmd.Flags().StringVarP(&options.Mode, "mode", "m", SyncModeKsync, "The mode of synchronising the files. One of: "+strings.Join(SyncModes, ", "))
+	cmd.Flags().StringVarP(&options.Container, "container", "c", "", "The name of the container to synchronise to in native mode")
+	cmd.Flags().StringVarP(&options.Namespace, "namespace", "n", "", "The namespace of the pod in native mode. Defaults to the current namespace")
+	cmd.Flags().StringVarP(&options.Environment, "environment", "e", "", "The environment of the pod in native mode such as a preview environment")
+	cmd.Flags().StringVarP(&options.Pod, "pod", "p", "", "The name or filter of the pod to synchronise to in native mode. Defaults to the users DevPod")
+	cmd.Flags().StringVarP(&options.Username, "username", "", "", "The username of the DevPod. If not specified defaults to the current operating system user or $USER")
+	cmd.Flags().StringVarP(&options.Dir, "dir", "d", "", "The directory to watch in native mode. Defaults to the current directory")
+	cmd.Flags().StringVarP(&options.RemoteDir, "remote-dir", "r", "", "The directory in the container to synchronise to in native mode. Defaults to the working directory of the DevPod or container")
+	cmd.Flags().StringVarP(&options.OnChange, "on-change", "", "", "The shell command to run in the container after each change in native mode such as a rebuild or restart")
+	cmd.Flags().DurationVarP(&options.Period, "poll-period", "", time.Second, "How often the directory is checked for changes in native mode")
 	cmd.Flags().BoolVarP(&options.Daemon, "daemon", "", false, "Runs ksync in a background daemon")
 	cmd.Flags().BoolVarP(&options.NoKsyncInit, "no-init", "", false, "Disables the use of 'ksync init' to ensure we have initialised ksync")
 	cmd.Flags().BoolVarP(&options.SingleMode, "single-mode", "", false, "Terminates eagerly if `ksync watch` fails")
@@ -98,6 +127,13 @@ func NewCmdSync(commonOpts *opts.CommonOptions) *cobra.Command {
 }
 
 func (o *SyncOptions) Run() error {
+	switch o.Mode {
+	case "", SyncModeKsync:
+	case SyncModeNative:
+		return o.runNative()
+	default:
+		return util.InvalidOption("mode", o.Mode, SyncModes)
+	}
 
 	// ksync is installed to the jx/bin dir, so we can add it for the user
 	os.Setenv("PATH", util.PathWithBinary())
@@ -0,0 +1,141 @@
+package sync
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/jenkins-x/jx/pkg/devsync"
+	"github.com/jenkins-x/jx/pkg/kube"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// runNative watches the local directory and copies the changes into the container of a DevPod or preview pod
+func (o *SyncOptions) runNative() error {
+	client, curNs, err := o.KubeClientAndNamespace()
+	if err != nil {
+		return err
+	}
+	ns := o.Namespace
+	if ns == "" {
+		ns = curNs
+	}
+	if o.Environment != "" {
+		ns, err = o.FindEnvironmentNamespace(o.Environment)
+		if err != nil {
+			return err
+		}
+	}
+	dir := o.Dir
+	if dir == "" {
+		dir, err = os.Getwd()
+		if err != nil {
+			return err
+		}
+	}
+	dir, err = filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+
+	devPod := o.Environment == "" && o.Pod == ""
+	var names []string
+	pods := map[string]*corev1.Pod{}
+	if devPod {
+		userName, err := o.GetUsername(o.Username)
+		if err != nil {
+			return err
+		}
+		names, pods, err = kube.GetDevPodNames(client, ns, userName)
+		if err != nil {
+			return err
+		}
+		if len(names) == 0 {
+			return fmt.Errorf("there are no DevPods for user %s in namespace %s. Try 'jx create devpod'", userName, ns)
+		}
+	} else {
+		allNames, err := kube.GetPodNames(client, ns, "")
+		if err != nil {
+			return err
+		}
+		for _, n := range allNames {
+			if o.Pod == "" || strings.Contains(n, o.Pod) {
+				names = append(names, n)
+			}
+		}
+		if len(names) == 0 {
+			return fmt.Errorf("there are no pods matching %s in namespace %s", o.Pod, ns)
+		}
+	}
+	name := names[0]
+	if len(names) > 1 {
+		name, err = util.PickName(names, "Pick Pod:", "", o.GetIOFileHandles())
+		if err != nil {
+			return err
+		}
+	}
+	pod := pods[name]
+	if pod == nil {
+		pod, err = client.CoreV1().Pods(ns).Get(name, metav1.GetOptions{})
+		if err != nil {
+			return errors.Wrapf(err, "getting pod %s in namespace %s", name, ns)
+		}
+	}
+
+	if devPod {
+		err = kube.UpdateDevPodLastActivity(client, ns, name)
+		if err != nil {
+			log.Logger().Warnf("failed to record the activity of DevPod %s: %s", name, err.Error())
+		}
+	}
+
+	remoteDir := o.RemoteDir
+	if remoteDir == "" {
+		remoteDir = podWorkingDir(pod, o.Container)
+	}
+	if remoteDir == "" {
+		return util.MissingOption("remote-dir")
+	}
+
+	syncer := &devsync.Syncer{
+		Dir:       dir,
+		Namespace: ns,
+		Pod:       name,
+		Container: o.Container,
+		RemoteDir: remoteDir,
+		OnChange:  o.OnChange,
+		Period:    o.Period,
+	}
+	info := util.ColorInfo
+	log.Logger().Infof("Synchronising directory %s to pod %s path %s. Press Ctrl+C to stop", info(dir), info(name), info(remoteDir))
+
+	stop := make(chan struct{})
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+	go func() {
+		<-signals
+		close(stop)
+	}()
+	return syncer.Watch(stop)
+}
+
+// podWorkingDir returns the working directory of the DevPod or of the container of the pod
+func podWorkingDir(pod *corev1.Pod, containerName string) string {
+	if pod.Annotations != nil && pod.Annotations[kube.AnnotationWorkingDir] != "" {
+		return pod.Annotations[kube.AnnotationWorkingDir]
+	}
+	for _, c := range pod.Spec.Containers {
+		if containerName == "" || c.Name == containerName {
+			return c.WorkingDir
+		}
+	}
+	return ""
+}
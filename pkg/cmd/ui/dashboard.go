@@ -0,0 +1,380 @@
+package ui
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/client/clientset/versioned"
+	"github.com/jenkins-x/jx/pkg/cmd/get"
+	"github.com/jenkins-x/jx/pkg/cmd/start"
+	"github.com/jenkins-x/jx/pkg/gits"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/table"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/browser"
+	"github.com/pkg/errors"
+	"gopkg.in/AlecAivazis/survey.v1/terminal"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// clearScreen moves the cursor to the top left of the terminal and clears it
+	clearScreen = "\033[H\033[2J"
+
+	dashboardHelp = "[j/k] move  [l] logs  [t] retrigger  [o] open PR  [r] refresh  [q] quit"
+)
+
+// Dashboard is the state of the terminal dashboard of the Jenkins X resources
+type Dashboard struct {
+	Environments []v1.Environment
+	Previews     []v1.Environment
+	Pipelines    []v1.PipelineActivity
+	Releases     []v1.Release
+
+	// Selected is the index of the selected row in the pipelines followed by the previews
+	Selected int
+	// Message is a message to display below the dashboard such as the result of the last action
+	Message string
+}
+
+// LoadDashboard loads the environments, the most recent pipelines, the most recent releases and the preview
+// environments of the team in the given dev namespace
+func LoadDashboard(jxClient versioned.Interface, ns string, limit int) (*Dashboard, error) {
+	d := &Dashboard{}
+	envs, err := jxClient.JenkinsV1().Environments(ns).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, errors.Wrapf(err, "listing environments in namespace %s", ns)
+	}
+	for _, env := range envs.Items {
+		if env.Spec.Kind == v1.EnvironmentKindTypePreview {
+			d.Previews = append(d.Previews, env)
+		} else {
+			d.Environments = append(d.Environments, env)
+		}
+	}
+	sort.Slice(d.Environments, func(i, j int) bool {
+		return d.Environments[i].Spec.Order < d.Environments[j].Spec.Order
+	})
+	sort.Slice(d.Previews, func(i, j int) bool {
+		return d.Previews[i].Name < d.Previews[j].Name
+	})
+
+	activities, err := jxClient.JenkinsV1().PipelineActivities(ns).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, errors.Wrapf(err, "listing pipeline activities in namespace %s", ns)
+	}
+	d.Pipelines = activities.Items
+	sort.Slice(d.Pipelines, func(i, j int) bool {
+		return startedTime(&d.Pipelines[j]).Before(startedTime(&d.Pipelines[i]))
+	})
+	if len(d.Pipelines) > limit {
+		d.Pipelines = d.Pipelines[:limit]
+	}
+
+	for _, env := range d.Environments {
+		envNs := env.Spec.Namespace
+		if envNs == "" || env.Spec.Kind == v1.EnvironmentKindTypeDevelopment {
+			continue
+		}
+		releases, err := jxClient.JenkinsV1().Releases(envNs).List(metav1.ListOptions{})
+		if err != nil {
+			log.Logger().Debugf("failed to list releases in namespace %s: %s", envNs, err)
+			continue
+		}
+		d.Releases = append(d.Releases, releases.Items...)
+	}
+	sort.Slice(d.Releases, func(i, j int) bool {
+		return d.Releases[j].CreationTimestamp.Before(&d.Releases[i].CreationTimestamp)
+	})
+	if len(d.Releases) > limit {
+		d.Releases = d.Releases[:limit]
+	}
+	return d, nil
+}
+
+// Rows returns the number of selectable rows
+func (d *Dashboard) Rows() int {
+	return len(d.Pipelines) + len(d.Previews)
+}
+
+// Move moves the selection by the given number of rows keeping it within the selectable rows
+func (d *Dashboard) Move(delta int) {
+	d.Selected += delta
+	if d.Selected >= d.Rows() {
+		d.Selected = d.Rows() - 1
+	}
+	if d.Selected < 0 {
+		d.Selected = 0
+	}
+}
+
+// SelectedPipeline returns the selected pipeline or nil if a preview environment is selected
+func (d *Dashboard) SelectedPipeline() *v1.PipelineActivity {
+	if d.Selected >= 0 && d.Selected < len(d.Pipelines) {
+		return &d.Pipelines[d.Selected]
+	}
+	return nil
+}
+
+// SelectedPreview returns the selected preview environment or nil if a pipeline is selected
+func (d *Dashboard) SelectedPreview() *v1.Environment {
+	idx := d.Selected - len(d.Pipelines)
+	if idx >= 0 && idx < len(d.Previews) {
+		return &d.Previews[idx]
+	}
+	return nil
+}
+
+// PullRequestURL returns the URL of the pull request of the selected pipeline or preview environment
+func (d *Dashboard) PullRequestURL() string {
+	if preview := d.SelectedPreview(); preview != nil && preview.Spec.PreviewGitSpec.URL != "" {
+		return preview.Spec.PreviewGitSpec.URL
+	}
+	pa := d.SelectedPipeline()
+	if pa == nil || !strings.HasPrefix(strings.ToUpper(pa.Spec.GitBranch), "PR-") || pa.Spec.GitURL == "" {
+		return ""
+	}
+	gitInfo, err := gits.ParseGitURL(pa.Spec.GitURL)
+	if err != nil {
+		return ""
+	}
+	return gitInfo.PullRequestURL(pa.Spec.GitBranch[3:])
+}
+
+// Render renders the dashboard
+func (d *Dashboard) Render(out io.Writer) {
+	fmt.Fprintln(out, util.ColorInfo("ENVIRONMENTS"))
+	t := table.CreateTable(out)
+	t.AddRow("NAME", "KIND", "PROMOTE", "NAMESPACE", "SOURCE")
+	for _, env := range d.Environments {
+		t.AddRow(env.Name, string(env.Spec.Kind), string(env.Spec.PromotionStrategy), env.Spec.Namespace, env.Spec.Source.URL)
+	}
+	t.Render()
+
+	row := 0
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, util.ColorInfo("PIPELINES"))
+	t = table.CreateTable(out)
+	t.AddRow("", "PIPELINE", "BUILD", "STATUS", "STARTED", "DURATION")
+	for i := range d.Pipelines {
+		pa := &d.Pipelines[i]
+		started := ""
+		if pa.Spec.StartedTimestamp != nil {
+			started = pa.Spec.StartedTimestamp.Format("2006-01-02 15:04:05")
+		}
+		t.AddRow(d.cursor(row), pa.Spec.Pipeline, pa.Spec.Build, colorStatus(pa.Spec.Status), started, duration(pa))
+		row++
+	}
+	t.Render()
+
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, util.ColorInfo("RELEASES"))
+	t = table.CreateTable(out)
+	t.AddRow("NAME", "VERSION", "NAMESPACE", "CREATED")
+	for _, r := range d.Releases {
+		t.AddRow(r.Spec.Name, r.Spec.Version, r.Namespace, r.CreationTimestamp.Format("2006-01-02 15:04:05"))
+	}
+	t.Render()
+
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, util.ColorInfo("PREVIEWS"))
+	t = table.CreateTable(out)
+	t.AddRow("", "NAME", "PULL REQUEST", "BUILD STATUS", "APPLICATION")
+	for _, env := range d.Previews {
+		spec := env.Spec.PreviewGitSpec
+		t.AddRow(d.cursor(row), env.Name, spec.URL, spec.BuildStatus, spec.ApplicationURL)
+		row++
+	}
+	t.Render()
+
+	fmt.Fprintln(out)
+	if d.Message != "" {
+		fmt.Fprintln(out, d.Message)
+	}
+	fmt.Fprintln(out, dashboardHelp)
+}
+
+func (d *Dashboard) cursor(row int) string {
+	if row == d.Selected {
+		return util.ColorInfo(">")
+	}
+	return ""
+}
+
+// runDashboard runs the interactive terminal dashboard until the user quits
+func (o *UIOptions) runDashboard() error {
+	jxClient, ns, err := o.JXClientAndDevNamespace()
+	if err != nil {
+		return errors.Wrap(err, "creating the jx client")
+	}
+	d, err := LoadDashboard(jxClient, ns, o.Limit)
+	if err != nil {
+		return err
+	}
+
+	reader := terminal.NewRuneReader(terminal.Stdio{In: o.In, Out: o.Out, Err: o.Err})
+	err = reader.SetTermMode()
+	if err != nil {
+		return errors.Wrap(err, "switching the terminal to raw mode")
+	}
+	defer reader.RestoreTermMode() //nolint:errcheck
+
+	// only read the next key once the previous one is handled so that the commands run by the actions can read the
+	// terminal input
+	keys := make(chan rune)
+	next := make(chan bool, 1)
+	defer close(next)
+	go func() {
+		for range next {
+			r, _, err := reader.ReadRune()
+			if err != nil {
+				close(keys)
+				return
+			}
+			keys <- r
+		}
+	}()
+	next <- true
+
+	ticker := time.NewTicker(o.RefreshInterval)
+	defer ticker.Stop()
+	for {
+		o.renderDashboard(d)
+		select {
+		case <-ticker.C:
+		case key, ok := <-keys:
+			if !ok {
+				return nil
+			}
+			reload := true
+			switch key {
+			case 'q', terminal.KeyInterrupt, terminal.KeyEscape:
+				return nil
+			case 'j', terminal.KeyArrowDown:
+				d.Move(1)
+				reload = false
+			case 'k', terminal.KeyArrowUp:
+				d.Move(-1)
+				reload = false
+			case 'l', 't', 'o':
+				d.Message = o.runDashboardAction(reader, d, key)
+			}
+			next <- true
+			if !reload {
+				continue
+			}
+		}
+		selected, message := d.Selected, d.Message
+		reloaded, err := LoadDashboard(jxClient, ns, o.Limit)
+		if err != nil {
+			d.Message = util.ColorError(err.Error())
+			continue
+		}
+		d = reloaded
+		d.Selected, d.Message = selected, message
+		d.Move(0)
+	}
+}
+
+func (o *UIOptions) renderDashboard(d *Dashboard) {
+	var buffer bytes.Buffer
+	buffer.WriteString(clearScreen)
+	d.Render(&buffer)
+	// the terminal is in raw mode so new lines need a carriage return
+	fmt.Fprint(o.Out, strings.Replace(buffer.String(), "\n", "\r\n", -1))
+}
+
+// runDashboardAction runs the action bound to the key on the selected pipeline or preview environment and returns
+// the message to display
+func (o *UIOptions) runDashboardAction(reader *terminal.RuneReader, d *Dashboard, key rune) string {
+	if key == 'o' {
+		url := d.PullRequestURL()
+		if url == "" {
+			return util.ColorWarning("the selection has no pull request")
+		}
+		err := browser.OpenURL(url)
+		if err != nil {
+			return util.ColorError(fmt.Sprintf("failed to open %s: %s", url, err))
+		}
+		return fmt.Sprintf("opened %s", util.ColorInfo(url))
+	}
+
+	pa := d.SelectedPipeline()
+	if pa == nil {
+		return util.ColorWarning("select a pipeline first")
+	}
+	owner, repo, branch := pa.RepositoryOwner(), pa.RepositoryName(), pa.BranchName()
+
+	// the commands need the terminal back in its normal mode
+	err := reader.RestoreTermMode()
+	if err != nil {
+		return util.ColorError(err.Error())
+	}
+	defer reader.SetTermMode() //nolint:errcheck
+	fmt.Fprint(o.Out, clearScreen)
+
+	switch key {
+	case 'l':
+		options := &get.GetBuildLogsOptions{
+			GetOptions: get.GetOptions{
+				CommonOptions: o.CommonOptions,
+			},
+			Tail: true,
+		}
+		options.BuildFilter.Owner = owner
+		options.BuildFilter.Repository = repo
+		options.BuildFilter.Branch = branch
+		options.BuildFilter.Build = pa.Spec.Build
+		err = options.Run()
+		if err != nil {
+			return util.ColorError(fmt.Sprintf("failed to get the logs of %s: %s", pa.Spec.Pipeline, err))
+		}
+		return fmt.Sprintf("showed the logs of %s #%s", util.ColorInfo(pa.Spec.Pipeline), pa.Spec.Build)
+	default:
+		options := &start.StartPipelineOptions{
+			CommonOptions: o.CommonOptions,
+		}
+		options.Args = []string{fmt.Sprintf("%s/%s/%s", owner, repo, branch)}
+		err = options.Run()
+		if err != nil {
+			return util.ColorError(fmt.Sprintf("failed to retrigger %s: %s", pa.Spec.Pipeline, err))
+		}
+		return fmt.Sprintf("retriggered %s", util.ColorInfo(pa.Spec.Pipeline))
+	}
+}
+
+func startedTime(pa *v1.PipelineActivity) time.Time {
+	if pa.Spec.StartedTimestamp != nil {
+		return pa.Spec.StartedTimestamp.Time
+	}
+	return pa.CreationTimestamp.Time
+}
+
+func duration(pa *v1.PipelineActivity) string {
+	if pa.Spec.StartedTimestamp == nil {
+		return ""
+	}
+	end := time.Now()
+	if pa.Spec.CompletedTimestamp != nil {
+		end = pa.Spec.CompletedTimestamp.Time
+	}
+	return end.Sub(pa.Spec.StartedTimestamp.Time).Round(time.Second).String()
+}
+
+func colorStatus(status v1.ActivityStatusType) string {
+	switch status {
+	case v1.ActivityStatusTypeSucceeded:
+		return util.ColorInfo(status)
+	case v1.ActivityStatusTypeRunning, v1.ActivityStatusTypePending, v1.ActivityStatusTypeWaitingForApproval:
+		return util.ColorWarning(status)
+	case v1.ActivityStatusTypeFailed, v1.ActivityStatusTypeError, v1.ActivityStatusTypeAborted:
+		return util.ColorError(status)
+	default:
+		return string(status)
+	}
+}
@@ -0,0 +1,90 @@
+package ui
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/acarl005/stripansi"
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/client/clientset/versioned/fake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestDashboard(t *testing.T) {
+	ns := "jx"
+	now := time.Now()
+	activity := func(name string, branch string, build string, status v1.ActivityStatusType, age time.Duration) *v1.PipelineActivity {
+		started := metav1.NewTime(now.Add(-age))
+		return &v1.PipelineActivity{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: ns},
+			Spec: v1.PipelineActivitySpec{
+				Pipeline:         "myorg/myapp/" + branch,
+				Build:            build,
+				Status:           status,
+				StartedTimestamp: &started,
+				GitURL:           "https://github.com/myorg/myapp.git",
+				GitOwner:         "myorg",
+				GitRepository:    "myapp",
+				GitBranch:        branch,
+			},
+		}
+	}
+	jxClient := fake.NewSimpleClientset(
+		&v1.Environment{
+			ObjectMeta: metav1.ObjectMeta{Name: "production", Namespace: ns},
+			Spec:       v1.EnvironmentSpec{Kind: v1.EnvironmentKindTypePermanent, Namespace: "jx-production", Order: 200},
+		},
+		&v1.Environment{
+			ObjectMeta: metav1.ObjectMeta{Name: "staging", Namespace: ns},
+			Spec:       v1.EnvironmentSpec{Kind: v1.EnvironmentKindTypePermanent, Namespace: "jx-staging", Order: 100},
+		},
+		&v1.Environment{
+			ObjectMeta: metav1.ObjectMeta{Name: "myorg-myapp-pr-2", Namespace: ns},
+			Spec: v1.EnvironmentSpec{
+				Kind:           v1.EnvironmentKindTypePreview,
+				Namespace:      "jx-myorg-myapp-pr-2",
+				PreviewGitSpec: v1.PreviewGitSpec{URL: "https://github.com/myorg/myapp/pull/2"},
+			},
+		},
+		activity("myorg-myapp-master-1", "master", "1", v1.ActivityStatusTypeSucceeded, 2*time.Hour),
+		activity("myorg-myapp-pr-3-1", "PR-3", "1", v1.ActivityStatusTypeRunning, time.Minute),
+		activity("myorg-myapp-master-2", "master", "2", v1.ActivityStatusTypeFailed, time.Hour),
+		&v1.Release{
+			ObjectMeta: metav1.ObjectMeta{Name: "myapp-0.0.1", Namespace: "jx-staging"},
+			Spec:       v1.ReleaseSpec{Name: "myapp", Version: "0.0.1"},
+		},
+	)
+
+	d, err := LoadDashboard(jxClient, ns, 2)
+	require.NoError(t, err)
+
+	require.Len(t, d.Environments, 2)
+	assert.Equal(t, "staging", d.Environments[0].Name)
+	require.Len(t, d.Previews, 1)
+	require.Len(t, d.Pipelines, 2, "the pipelines should be limited")
+	assert.Equal(t, "myorg-myapp-pr-3-1", d.Pipelines[0].Name)
+	assert.Equal(t, "myorg-myapp-master-2", d.Pipelines[1].Name)
+	require.Len(t, d.Releases, 1)
+
+	assert.Equal(t, "https://github.com/myorg/myapp/pull/3", d.PullRequestURL())
+	d.Move(1)
+	assert.Equal(t, "", d.PullRequestURL(), "a master pipeline has no pull request")
+	d.Move(5)
+	assert.Equal(t, 2, d.Selected)
+	assert.Nil(t, d.SelectedPipeline())
+	assert.Equal(t, "https://github.com/myorg/myapp/pull/2", d.PullRequestURL())
+	d.Move(-10)
+	assert.Equal(t, 0, d.Selected)
+
+	var out bytes.Buffer
+	d.Render(&out)
+	text := stripansi.Strip(out.String())
+	assert.Contains(t, text, "myorg/myapp/PR-3")
+	assert.Contains(t, text, "Running")
+	assert.Contains(t, text, "jx-staging")
+	assert.Contains(t, text, "0.0.1")
+	assert.Contains(t, text, "https://github.com/myorg/myapp/pull/2")
+}
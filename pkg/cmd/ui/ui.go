@@ -27,9 +27,12 @@ import (
 type UIOptions struct {
 	*opts.CommonOptions
 
-	OnlyViewURL  bool
-	HideURLLabel bool
-	LocalPort    string
+	OnlyViewURL     bool
+	HideURLLabel    bool
+	LocalPort       string
+	Terminal        bool
+	Limit           int
+	RefreshInterval time.Duration
 }
 
 const (
@@ -42,13 +45,20 @@ var (
 		Opens the CloudBees JX UI in a browser.
 
 		Which helps you visualise your CI/CD pipelines.
+
+		Use the '--terminal' argument to show a dashboard of the environments, the recent pipelines and releases and the
+		preview environments in the terminal instead. The dashboard refreshes itself and lets you tail the logs of
+		a pipeline, retrigger a pipeline or open the pull request of a pipeline or preview environment in the browser.
 `)
 	core_example = templates.Examples(`
 		# Open the JX UI dashboard in a browser
 		jx ui
 
 		# Print the Jenkins X console URL but do not open a browser
-		jx ui -u`)
+		jx ui -u
+
+		# Show the dashboard in the terminal
+		jx ui --terminal`)
 )
 
 // NewCmdUI creates the "jx ui" command
@@ -72,12 +82,18 @@ func NewCmdUI(commonOpts *opts.CommonOptions) *cobra.Command {
 	cmd.Flags().BoolVarP(&options.OnlyViewURL, "url", "u", false, "Only displays the label and the URL and does not open the browser")
 	cmd.Flags().BoolVarP(&options.HideURLLabel, "hide-label", "l", false, "Hides the URL label from display")
 	cmd.Flags().StringVarP(&options.LocalPort, "local-port", "p", "", "The local port to forward the data to")
+	cmd.Flags().BoolVarP(&options.Terminal, "terminal", "t", false, "Shows the dashboard in the terminal rather than opening the UI in a browser")
+	cmd.Flags().IntVarP(&options.Limit, "limit", "", 10, "The number of the most recent pipelines and releases to show in the terminal dashboard")
+	cmd.Flags().DurationVarP(&options.RefreshInterval, "refresh", "", 5*time.Second, "How often the terminal dashboard is refreshed")
 
 	return cmd
 }
 
 // Run implements this command
 func (o *UIOptions) Run() error {
+	if o.Terminal {
+		return o.runDashboard()
+	}
 	kubeClient, ns, err := o.KubeClientAndDevNamespace()
 	if err != nil {
 		return err
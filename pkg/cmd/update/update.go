@@ -19,7 +19,9 @@ type UpdateOptions struct {
 var (
 	update_resources = `Valid resource types include:
 
+	* catalog
 	* cluster
+	* webhooks
 	`
 
 	update_long = templates.LongDesc(`
@@ -47,6 +49,7 @@ func NewCmdUpdate(commonOpts *opts.CommonOptions) *cobra.Command {
 		},
 	}
 
+	cmd.AddCommand(NewCmdUpdateCatalog(commonOpts))
 	cmd.AddCommand(NewCmdUpdateCluster(commonOpts))
 	cmd.AddCommand(NewCmdUpdateWebhooks(commonOpts))
 
@@ -0,0 +1,158 @@
+package update
+
+import (
+	"path/filepath"
+
+	"github.com/jenkins-x/jx/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/quickstarts"
+	"github.com/jenkins-x/jx/pkg/spring"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// UpdateCatalogOptions the options for the update catalog command
+type UpdateCatalogOptions struct {
+	*opts.CommonOptions
+
+	GitHubOrganisations []string
+	IgnoreTeam          bool
+	SkipSpring          bool
+	Bundle              string
+	FromBundle          string
+	CacheDir            string
+}
+
+var (
+	updateCatalogLong = templates.LongDesc(`
+		Refreshes the offline cache of the quickstart catalog, the source of the quickstarts and the spring initializr
+		metadata in ~/.jx/cache which is used by 'jx create quickstart' and 'jx create spring' when the quickstart
+		locations or start.spring.io cannot be reached.
+
+		The cache can be exported as a bundle and imported on machines behind restrictive proxies or in air-gapped
+		environments.
+`)
+
+	updateCatalogExample = templates.Examples(`
+		# refresh the offline cache
+		jx update catalog
+
+		# refresh the offline cache and export it as a bundle
+		jx update catalog --bundle jx-catalog.tar.gz
+
+		# pre-seed the offline cache from a bundle on an air-gapped machine
+		jx update catalog --from-bundle jx-catalog.tar.gz
+`)
+)
+
+// NewCmdUpdateCatalog creates a command object for the "update catalog" command
+func NewCmdUpdateCatalog(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &UpdateCatalogOptions{
+		CommonOptions: commonOpts,
+	}
+
+	cmd := &cobra.Command{
+		Use:     "catalog",
+		Aliases: []string{"catalogs", "quickstarts"},
+		Short:   "Refreshes the offline cache of quickstarts and spring initializr metadata",
+		Long:    updateCatalogLong,
+		Example: updateCatalogExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.Flags().StringArrayVarP(&options.GitHubOrganisations, "organisations", "g", []string{}, "The GitHub organisations to query for quickstarts")
+	cmd.Flags().BoolVarP(&options.IgnoreTeam, "ignore-team", "", false, "Ignores the quickstart locations of the team")
+	cmd.Flags().BoolVarP(&options.SkipSpring, "skip-spring", "", false, "Skips refreshing the spring initializr metadata")
+	cmd.Flags().StringVarP(&options.Bundle, "bundle", "", "", "Exports the refreshed cache as a tar.gz bundle to the given file")
+	cmd.Flags().StringVarP(&options.FromBundle, "from-bundle", "", "", "Imports the cache from the given tar.gz bundle rather than refreshing it")
+	cmd.Flags().StringVarP(&options.CacheDir, "cache-dir", "", "", "The cache directory. Defaults to ~/.jx/cache")
+	return cmd
+}
+
+// Run implements this command
+func (o *UpdateCatalogOptions) Run() error {
+	cacheDir := o.CacheDir
+	if cacheDir == "" {
+		var err error
+		cacheDir, err = util.CacheDir()
+		if err != nil {
+			return err
+		}
+	}
+	if o.FromBundle != "" {
+		err := ImportCatalogBundle(o.FromBundle, cacheDir)
+		if err != nil {
+			return err
+		}
+		log.Logger().Infof("Imported the catalog bundle %s into %s", util.ColorInfo(o.FromBundle), util.ColorInfo(cacheDir))
+		return nil
+	}
+
+	model, err := o.LoadQuickStartsModel(o.GitHubOrganisations, o.IgnoreTeam)
+	if err != nil {
+		return errors.Wrap(err, "failed to load quickstarts")
+	}
+	cache := quickstarts.NewCache(cacheDir)
+	err = cache.Refresh(model)
+	if err != nil {
+		return err
+	}
+	log.Logger().Infof("Cached %d quickstarts in %s", len(model.Quickstarts), util.ColorInfo(cache.Dir))
+
+	if !o.SkipSpring {
+		_, err = spring.RefreshSpringBoot(cacheDir)
+		if err != nil {
+			return err
+		}
+		log.Logger().Infof("Cached the spring initializr metadata in %s", util.ColorInfo(filepath.Join(cacheDir, spring.SpringBootCacheFileName)))
+	}
+
+	if o.Bundle != "" {
+		err = ExportCatalogBundle(cacheDir, o.Bundle)
+		if err != nil {
+			return err
+		}
+		log.Logger().Infof("Exported the catalog bundle to %s", util.ColorInfo(o.Bundle))
+	}
+	return nil
+}
+
+// catalogBundlePaths the paths relative to the cache directory which are included in a catalog bundle
+var catalogBundlePaths = []string{
+	quickstarts.CacheDirName,
+	spring.SpringBootCacheFileName,
+	spring.SpringBootCacheFileName + "_last_time_check",
+}
+
+// ExportCatalogBundle exports the cached quickstarts and spring initializr metadata in the cache directory as a tar.gz
+// bundle
+func ExportCatalogBundle(cacheDir string, bundle string) error {
+	err := util.TarGz(cacheDir, bundle, catalogBundlePaths...)
+	if err != nil {
+		return errors.Wrapf(err, "exporting the catalog bundle %s", bundle)
+	}
+	return nil
+}
+
+// ImportCatalogBundle imports a tar.gz bundle created by ExportCatalogBundle into the cache directory
+func ImportCatalogBundle(bundle string, cacheDir string) error {
+	exists, err := util.FileExists(bundle)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return errors.Errorf("catalog bundle %s does not exist", bundle)
+	}
+	err = util.UnTargzAll(bundle, cacheDir)
+	if err != nil {
+		return errors.Wrapf(err, "importing the catalog bundle %s", bundle)
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package update
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x/jx/pkg/quickstarts"
+	"github.com/jenkins-x/jx/pkg/spring"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCatalogBundleRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	srcDir, err := ioutil.TempDir("", "test-catalog-src-")
+	require.NoError(t, err)
+	defer os.RemoveAll(srcDir)
+
+	cache := quickstarts.NewCache(srcDir)
+	q := &quickstarts.Quickstart{
+		ID:   "jenkins-x-quickstarts/node-http",
+		Name: "node-http",
+	}
+	model := quickstarts.NewQuickstartModel()
+	model.Add(q)
+	require.NoError(t, cache.SaveModel(model))
+	require.NoError(t, cache.SaveZip(q, []byte("source")))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(srcDir, spring.SpringBootCacheFileName), []byte("{}"), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(srcDir, "unrelated.json"), []byte("{}"), 0644))
+
+	bundle := filepath.Join(srcDir, "bundle.tar.gz")
+	err = ExportCatalogBundle(srcDir, bundle)
+	require.NoError(t, err)
+
+	destDir, err := ioutil.TempDir("", "test-catalog-dest-")
+	require.NoError(t, err)
+	defer os.RemoveAll(destDir)
+
+	err = ImportCatalogBundle(bundle, destDir)
+	require.NoError(t, err)
+
+	imported := quickstarts.NewCache(destDir)
+	importedModel, _, err := imported.LoadModel()
+	require.NoError(t, err)
+	require.NotNil(t, importedModel)
+	assert.Equal(t, model.SortedNames(), importedModel.SortedNames())
+
+	fileName, exists, err := imported.CachedZip(q)
+	require.NoError(t, err)
+	require.True(t, exists)
+	data, err := ioutil.ReadFile(fileName)
+	require.NoError(t, err)
+	assert.Equal(t, "source", string(data))
+
+	assert.FileExists(t, filepath.Join(destDir, spring.SpringBootCacheFileName))
+	_, err = os.Stat(filepath.Join(destDir, "unrelated.json"))
+	assert.True(t, os.IsNotExist(err), "should only bundle the catalog files")
+}
+
+func TestImportMissingCatalogBundle(t *testing.T) {
+	t.Parallel()
+
+	err := ImportCatalogBundle(filepath.Join(os.TempDir(), "does-not-exist.tar.gz"), os.TempDir())
+	require.Error(t, err)
+}
@@ -13,13 +13,20 @@ import (
 	jenkinsv1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
 	"github.com/jenkins-x/jx/pkg/cmd/opts"
 	"github.com/jenkins-x/jx/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/pkg/log"
 	"github.com/jenkins-x/jx/pkg/util"
 	"github.com/spf13/cobra"
+	"k8s.io/client-go/kubernetes"
 )
 
 var (
 	upgradeAppsLong = templates.LongDesc(`
 		Upgrades Apps to newer releases (an app is similar to an addon)
+
+		When using GitOps the '--all' flag upgrades every installed App to the version in the version stream using a
+		single Pull Request. Apps can declare the versions of Kubernetes and the Jenkins X platform they are compatible
+		with in the 'compatibility' section of their App resource. Apps whose new version is not compatible with the
+		cluster are not upgraded and are reported instead.
 `)
 
 	upgradeAppsExample = templates.Examples(`
@@ -28,6 +35,9 @@ var (
  
         # Upgrade a specific app
         jx upgrade app cheese
+
+		# Upgrade all apps to the versions in the version stream checking they are compatible with the cluster
+		jx upgrade apps --all
 	`)
 )
 
@@ -85,9 +95,9 @@ func NewCmdUpgradeApps(commonOpts *opts.CommonOptions) *cobra.Command {
 	}
 
 	cmd.Flags().BoolVarP(&o.BatchMode, opts.OptionBatchMode, "b", false, "Enable batch mode")
-	cmd.Flags().StringVarP(&o.Version, "username", "", "",
+	cmd.Flags().StringVarP(&o.Username, "username", "", "",
 		"The username for the repository")
-	cmd.Flags().StringVarP(&o.Version, "password", "", "",
+	cmd.Flags().StringVarP(&o.Password, "password", "", "",
 		"The password for the repository")
 	cmd.Flags().StringVarP(&o.Repo, "repository", "", "",
 		"The repository from which the app should be installed")
@@ -104,6 +114,7 @@ func NewCmdUpgradeApps(commonOpts *opts.CommonOptions) *cobra.Command {
 	cmd.Flags().BoolVarP(&o.AskAll, "ask-all", "", false, "Ask all configuration questions. "+
 		"By default existing answers are reused automatically.")
 	cmd.Flags().BoolVarP(&o.AutoMerge, "auto-merge", "", false, "Automatically merge GitOps pull requests that pass CI")
+	cmd.Flags().BoolVarP(&o.All, "all", "", false, "Upgrade all apps to the versions in the version stream which are compatible with the cluster [--gitops]")
 	return cmd
 }
 
@@ -177,9 +188,6 @@ func (o *UpgradeAppsOptions) Run() error {
 		if o.Alias != "" {
 			return util.InvalidOptionf(optionAlias, o.ReleaseName, msg, optionAlias)
 		}
-		if o.Version != "" {
-			return util.InvalidOptionf(optionVersion, o.ReleaseName, msg, optionVersion)
-		}
 	}
 
 	if o.GetSecretsLocation() == secrets.VaultLocationKind {
@@ -196,6 +204,10 @@ func (o *UpgradeAppsOptions) Run() error {
 		installOpts.VaultClient = client
 	}
 
+	if o.All {
+		return o.upgradeAll(&installOpts, kubeClient)
+	}
+
 	app := ""
 	if len(o.Args) > 1 {
 		return o.Cmd.Help()
@@ -211,3 +223,41 @@ func (o *UpgradeAppsOptions) Run() error {
 	return installOpts.UpgradeApp(app, version, o.Repo, o.Username, o.Password, o.ReleaseName, o.Alias, o.HelmUpdate, o.AskAll)
 
 }
+
+func (o *UpgradeAppsOptions) upgradeAll(installOpts *apps.InstallOptions, kubeClient kubernetes.Interface) error {
+	if !o.GitOps {
+		return util.InvalidOptionf("all", o.All, "Unable to specify --%s when NOT using GitOps for your dev environment", "all")
+	}
+	if len(o.Args) > 0 {
+		return util.InvalidArgError(o.Args[0], errors.Errorf("unable to specify an app when using --all"))
+	}
+	if o.Version != "" {
+		return util.InvalidOptionf(optionVersion, o.Version, "Unable to specify --%s when using --all", optionVersion)
+	}
+	resolver, err := o.GetVersionResolver()
+	if err != nil {
+		return errors.Wrap(err, "getting the version stream")
+	}
+	kubernetesVersion := ""
+	serverVersion, err := kubeClient.Discovery().ServerVersion()
+	if err != nil {
+		log.Logger().Warnf("Failed to get Kubernetes server version so not checking Kubernetes compatibility: %s", err)
+	} else if serverVersion != nil {
+		kubernetesVersion = serverVersion.String()
+	}
+
+	blocked, err := installOpts.UpgradeAllApps(resolver.VersionsDir, kubernetesVersion, o.Username, o.Password, o.AskAll)
+	if err != nil {
+		return err
+	}
+	if len(blocked) > 0 {
+		log.Logger().Warnf("The following apps were not upgraded as they are not compatible with the cluster:")
+		table := o.CreateTable()
+		table.AddRow("APP", "CURRENT", "VERSION", "REASON")
+		for _, b := range blocked {
+			table.AddRow(b.Name, b.CurrentVersion, b.Version, b.Reason)
+		}
+		table.Render()
+	}
+	return nil
+}
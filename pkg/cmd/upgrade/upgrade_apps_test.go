@@ -27,6 +27,7 @@ import (
 
 	"github.com/blang/semver"
 
+	uuid "github.com/satori/go.uuid"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -678,3 +679,65 @@ func TestUpgradeAllAppsForGitOps(t *testing.T) {
 	}
 	assert.Len(t, found, 2)
 }
+
+func TestUpgradeApp(t *testing.T) {
+	testOptions := testhelpers.CreateAppTestOptions(false, "", t)
+	// Can't run in parallel
+	pegomock.RegisterMockTestingT(t)
+	defer func() {
+		err := testOptions.Cleanup()
+		assert.NoError(t, err)
+	}()
+
+	nameUUID, err := uuid.NewV4()
+	assert.NoError(t, err)
+	name := nameUUID.String()
+	version := "0.0.1"
+	commonOpts := *testOptions.CommonOptions
+	addOpts := &add.AddAppOptions{
+		AddOptions: add.AddOptions{
+			CommonOptions: &commonOpts,
+		},
+		Version:    version,
+		Repo:       kube.DefaultChartMuseumURL,
+		GitOps:     false,
+		DevEnv:     testOptions.DevEnv,
+		HelmUpdate: true,
+	}
+	addOpts.Args = []string{name}
+	err = addOpts.Run()
+	assert.NoError(t, err)
+
+	// Now let's upgrade
+	newVersion := "0.0.2"
+	upgradeCommonOpts := *testOptions.CommonOptions
+	o := &upgrade.UpgradeAppsOptions{
+		AddOptions: add.AddOptions{
+			CommonOptions: &upgradeCommonOpts,
+		},
+		Version:    newVersion,
+		Repo:       kube.DefaultChartMuseumURL,
+		GitOps:     false,
+		HelmUpdate: true,
+		DevEnv:     testOptions.DevEnv,
+	}
+	o.Args = []string{name}
+	err = o.Run()
+	assert.NoError(t, err)
+
+	testOptions.MockHelmer.VerifyWasCalledOnce().
+		UpgradeChart(
+			pegomock.AnyString(),
+			pegomock.EqString(fmt.Sprintf("jx-%s", name)),
+			pegomock.AnyString(),
+			pegomock.EqString(newVersion),
+			pegomock.AnyBool(),
+			pegomock.AnyInt(),
+			pegomock.AnyBool(),
+			pegomock.AnyBool(),
+			pegomock.AnyStringSlice(),
+			pegomock.AnyStringSlice(),
+			pegomock.EqString(kube.DefaultChartMuseumURL),
+			pegomock.AnyString(),
+			pegomock.AnyString())
+}
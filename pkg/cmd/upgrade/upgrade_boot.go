@@ -17,6 +17,8 @@ import (
 	"github.com/jenkins-x/jx/pkg/helm"
 	"github.com/jenkins-x/jx/pkg/kube"
 	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/notify"
+	"github.com/jenkins-x/jx/pkg/platform"
 	"github.com/jenkins-x/jx/pkg/util"
 	"github.com/jenkins-x/jx/pkg/versionstream"
 	"github.com/pkg/errors"
@@ -30,12 +32,17 @@ type UpgradeBootOptions struct {
 	Dir                     string
 	UpgradeVersionStreamRef string
 	LatestRelease           bool
+	AutoMerge               bool
+	SkipCompatibilityCheck  bool
 }
 
 var (
 	upgradeBootLong = templates.LongDesc(`
 		This command creates a pr for upgrading a jx boot gitOps cluster, incorporating changes to the boot
         config and version stream ref
+
+		The upgrade is blocked if the compatibility manifest of the new version stream does not list the Kubernetes
+		version of the cluster or the versions of its CustomResourceDefinitions as supported by its platform version.
 `)
 
 	upgradeBootExample = templates.Examples(`
@@ -68,6 +75,8 @@ func NewCmdUpgradeBoot(commonOpts *opts.CommonOptions) *cobra.Command {
 	cmd.Flags().StringVarP(&options.Dir, "dir", "d", "", "the directory to look for the Jenkins X Pipeline and requirements")
 	cmd.Flags().StringVarP(&options.UpgradeVersionStreamRef, "upgrade-version-stream-ref", "", config.DefaultVersionsRef, "a version stream ref to use to upgrade to")
 	cmd.Flags().BoolVarP(&options.LatestRelease, "latest-release", "", false, "upgrade to latest release tag")
+	cmd.Flags().BoolVarP(&options.AutoMerge, "auto-merge", "", false, "merge the upgrade PR once its checks pass, using the git provider's native auto merge if available or the auto merge label otherwise")
+	cmd.Flags().BoolVarP(&options.SkipCompatibilityCheck, "skip-compatibility-check", "", false, "upgrade even if the cluster is not compatible with the platform version of the new version stream")
 
 	return cmd
 }
@@ -99,6 +108,13 @@ func (o *UpgradeBootOptions) Run() error {
 		return nil
 	}
 
+	if !o.SkipCompatibilityCheck {
+		err = o.verifyCompatibility(reqsVersionStream.URL, upgradeVersionRef)
+		if err != nil {
+			return err
+		}
+	}
+
 	localBranch, err := o.checkoutNewBranch()
 	if err != nil {
 		return errors.Wrap(err, "failed to checkout upgrade_branch")
@@ -232,6 +248,22 @@ func (o *UpgradeBootOptions) upgradeAvailable(versionStreamURL string, versionSt
 	return upgradeRef, nil
 }
 
+// verifyCompatibility verifies the cluster is compatible with the platform version of the version stream ref
+func (o *UpgradeBootOptions) verifyCompatibility(versionStreamURL string, upgradeRef string) error {
+	versionsDir, _, err := o.CloneJXVersionsRepo(versionStreamURL, upgradeRef)
+	if err != nil {
+		return errors.Wrapf(err, "failed to clone versions repo %s", versionStreamURL)
+	}
+	platformVersion, err := versionstream.LoadStableVersionNumber(versionsDir, versionstream.KindChart, platform.JenkinsXPlatformChart)
+	if err != nil {
+		return errors.Wrapf(err, "failed to load the version of %s", platform.JenkinsXPlatformChart)
+	}
+	if platformVersion == "" {
+		return nil
+	}
+	return o.VerifyPlatformCompatibility(versionsDir, platformVersion)
+}
+
 func (o *UpgradeBootOptions) checkoutNewBranch() (string, error) {
 	localBranchUUID, err := uuid.NewV4()
 	if err != nil {
@@ -427,16 +459,44 @@ func (o *UpgradeBootOptions) raisePR() error {
 		return errors.Wrapf(err, "failed to get PR details and filter")
 	}
 
-	_, err = gits.PushRepoAndCreatePullRequest(o.Dir, upstreamInfo, nil, "master", &details, &filter, false, details.Title, true, false, o.Git(), provider)
+	prInfo, err := gits.PushRepoAndCreatePullRequest(o.Dir, upstreamInfo, nil, "master", &details, &filter, false, details.Title, true, false, o.Git(), provider)
 	if err != nil {
 		return errors.Wrapf(err, "failed to create PR for base %s and head branch %s", "master", details.BranchName)
 	}
+	if o.AutoMerge && prInfo != nil {
+		_, err = gits.EnableAutoMerge(provider, prInfo.PullRequest, details.Title)
+		if err != nil {
+			return errors.Wrap(err, "failed to enable auto merge of the upgrade PR")
+		}
+	}
+	if prInfo != nil && prInfo.PullRequest != nil {
+		o.notifyPullRequest(gitInfo, prInfo.PullRequest.URL)
+	}
 	return nil
 }
 
+// notifyPullRequest sends the notifications configured in the notifications.yaml file of the dev environment
+// repository for the upgrade Pull Request
+func (o *UpgradeBootOptions) notifyPullRequest(gitInfo *gits.GitRepository, prURL string) {
+	cfg, err := config.LoadNotificationsConfig(o.Dir)
+	if err != nil {
+		log.Logger().Warnf("Failed to load the notifications configuration: %s", err)
+		return
+	}
+	dispatcher := &notify.Dispatcher{Config: cfg}
+	err = dispatcher.Dispatch(&notify.Event{
+		Kind:           config.NotificationBootUpgradePullRequest,
+		Repository:     gitInfo.Organisation + "/" + gitInfo.Name,
+		PullRequestURL: prURL,
+	})
+	if err != nil {
+		log.Logger().Warnf("Failed to notify the upgrade Pull Request %s: %s", prURL, err)
+	}
+}
+
 func prDetailsAndFilter() (gits.PullRequestDetails, gits.PullRequestFilter, error) {
 	details := gits.PullRequestDetails{
-		BranchName: fmt.Sprintf("jx_boot_upgrade"),
+		BranchName: boot.UpgradeBranchName,
 		Title:      "feat(config): upgrade configuration",
 		Message:    "Upgrade configuration",
 	}
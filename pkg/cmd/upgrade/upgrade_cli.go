@@ -1,9 +1,20 @@
 package upgrade
 
 import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
 	"github.com/blang/semver"
 	"github.com/jenkins-x/jx/pkg/cmd/create/options"
 	"github.com/jenkins-x/jx/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/pkg/config"
+	"github.com/jenkins-x/jx/pkg/packages"
 	"github.com/pkg/errors"
 
 	"github.com/jenkins-x/jx/pkg/cmd/opts"
@@ -14,18 +25,50 @@ import (
 	"github.com/spf13/cobra"
 )
 
+const (
+	// CLIChannelStable upgrades to the version of jx in the version stream of the team
+	CLIChannelStable = "stable"
+	// CLIChannelLatest upgrades to the latest release of jx
+	CLIChannelLatest = "latest"
+	// CLIChannelLTS upgrades to the latest patch release of the minor version of jx in the version stream
+	CLIChannelLTS = "lts"
+
+	downloadTimeout = time.Hour * 2
+)
+
+// CLIChannels the release channels of the jx binary
+var CLIChannels = []string{CLIChannelStable, CLIChannelLatest, CLIChannelLTS}
+
 var (
 	upgradeCLILong = templates.LongDesc(`
 		Upgrades the Jenkins X command line tools if there is a different version stored in the version stream.
 
 		The exact version used for the version stream is stored in the Team Settings on the 'dev' Environment CRD.
 
+		The release channel chooses the version to upgrade to:
+
+		* stable - the version of jx in the version stream (the default)
+		* latest - the latest release of jx
+		* lts - the latest patch release of the minor version of jx in the version stream
+
+		The SHA256 checksum of the downloaded archive is verified before the binary is replaced. If a PGP public key
+		is specified via --public-key the signature of the checksums is verified too.
+
+		Use --download-url to download the releases from a mirror with the same layout as the GitHub releases.
+		The HTTP_PROXY and HTTPS_PROXY environment variables or --proxy are used to download via a corporate proxy.
+
 		For more information on Version Streams see: [https://jenkins-x.io/docs/concepts/version-stream/](https://jenkins-x.io/docs/concepts/version-stream/)
 `)
 
 	upgradeCLIExample = templates.Examples(`
-		# Upgrades the Jenkins X CLI tools 
+		# Upgrades the Jenkins X CLI tools
 		jx upgrade cli
+
+		# Upgrades to the latest release verifying its signature
+		jx upgrade cli --channel latest --public-key jx-release.asc
+
+		# Downgrades to a specific version downloading via a mirror
+		jx upgrade cli --to-version 2.0.1000 --download-url https://mirror.example.com/jx/releases/download/v
 	`)
 )
 
@@ -33,7 +76,13 @@ var (
 type UpgradeCLIOptions struct {
 	options.CreateOptions
 
-	Version string
+	Version     string
+	ToVersion   string
+	Channel     string
+	DownloadURL string
+	Proxy       string
+	PublicKey   string
+	SkipVerify  bool
 }
 
 // NewCmdUpgradeCLI defines the command
@@ -58,6 +107,12 @@ func NewCmdUpgradeCLI(commonOpts *opts.CommonOptions) *cobra.Command {
 		},
 	}
 	cmd.Flags().StringVarP(&options.Version, "version", "v", "", "The specific version to upgrade to (requires --no-brew on macOS)")
+	cmd.Flags().StringVarP(&options.ToVersion, "to-version", "", "", "The specific version to install even if it is older than the current version")
+	cmd.Flags().StringVarP(&options.Channel, "channel", "c", CLIChannelStable, fmt.Sprintf("The release channel to upgrade from. One of: %s", strings.Join(CLIChannels, ", ")))
+	cmd.Flags().StringVarP(&options.DownloadURL, "download-url", "", "", "The base URL of the releases to download from such as a mirror. Defaults to "+config.BinaryDownloadBaseURL)
+	cmd.Flags().StringVarP(&options.Proxy, "proxy", "", "", "The URL of the HTTP proxy to download via")
+	cmd.Flags().StringVarP(&options.PublicKey, "public-key", "", "", "A PGP public key file used to verify the signature of the release checksums")
+	cmd.Flags().BoolVarP(&options.SkipVerify, "skip-verify", "", false, "Skips verifying the checksum of the downloaded release")
 	cmd.Flags().BoolVar(&options.CommonOptions.NoBrew, opts.OptionNoBrew, false, "Disables brew package manager on MacOS when installing binary dependencies")
 	return cmd
 }
@@ -66,6 +121,9 @@ func NewCmdUpgradeCLI(commonOpts *opts.CommonOptions) *cobra.Command {
 func (o *UpgradeCLIOptions) Run() error {
 	// upgrading to a specific version is not yet supported in brew so lets disable it for upgrades
 	o.NoBrew = true
+	if util.StringArrayIndex(CLIChannels, o.Channel) < 0 && o.Channel != "" {
+		return util.InvalidOption("channel", o.Channel, CLIChannels)
+	}
 	candidateInstallVersion, err := o.candidateInstallVersion()
 	if err != nil {
 		return err
@@ -78,13 +136,17 @@ func (o *UpgradeCLIOptions) Run() error {
 
 	log.Logger().Debugf("Current version of jx: %s", util.ColorInfo(currentVersion))
 
+	if o.ToVersion != "" {
+		return o.installToVersion(currentVersion, candidateInstallVersion)
+	}
+
 	if o.needsUpgrade(currentVersion, candidateInstallVersion) {
 		shouldUpgrade, err := o.ShouldUpdate(candidateInstallVersion)
 		if err != nil {
 			return errors.Wrap(err, "failed to determine if we should upgrade")
 		}
 		if shouldUpgrade {
-			return o.InstallJx(true, candidateInstallVersion.String())
+			return o.installVersion(candidateInstallVersion)
 		}
 	}
 
@@ -92,23 +154,207 @@ func (o *UpgradeCLIOptions) Run() error {
 }
 
 func (o *UpgradeCLIOptions) candidateInstallVersion() (semver.Version, error) {
-	if o.Version == "" {
-		versionResolver, err := o.GetVersionResolver()
-		if err != nil {
-			return semver.Version{}, err
-		}
-		latestVersion, err := o.GetLatestJXVersion(versionResolver)
+	requested := o.ToVersion
+	if requested == "" {
+		requested = o.Version
+	}
+	if requested == "" {
+		return o.channelVersion()
+	}
+
+	requestedVersion, err := semver.New(strings.TrimPrefix(requested, "v"))
+	if err != nil {
+		return semver.Version{}, errors.Wrapf(err, "invalid version requested: %s", requested)
+	}
+	return *requestedVersion, nil
+}
+
+// channelVersion returns the version of jx of the release channel
+func (o *UpgradeCLIOptions) channelVersion() (semver.Version, error) {
+	if o.Channel == CLIChannelLatest {
+		latestVersion, err := util.GetLatestVersionFromGitHub("jenkins-x", "jx")
 		if err != nil {
 			return semver.Version{}, errors.Wrap(err, "failed to determine version of latest jx release")
 		}
 		return latestVersion, nil
 	}
 
-	requestedVersion, err := semver.New(o.Version)
+	streamVersion, err := o.versionStreamVersion()
 	if err != nil {
-		return semver.Version{}, errors.Wrapf(err, "invalid version requested: %s", o.Version)
+		return semver.Version{}, err
 	}
-	return *requestedVersion, nil
+	if o.Channel != CLIChannelLTS {
+		return streamVersion, nil
+	}
+	tags, err := util.GetTagsFromGithub("jenkins-x", "jx")
+	if err != nil {
+		return semver.Version{}, errors.Wrap(err, "failed to list the releases of jx")
+	}
+	names := []string{}
+	for _, tag := range tags {
+		names = append(names, util.DereferenceString(tag.Name))
+	}
+	return LatestPatchVersion(names, streamVersion), nil
+}
+
+// versionStreamVersion returns the version of jx in the version stream of the team
+func (o *UpgradeCLIOptions) versionStreamVersion() (semver.Version, error) {
+	versionResolver, err := o.GetVersionResolver()
+	if err != nil {
+		return semver.Version{}, err
+	}
+	streamVersion, err := o.GetLatestJXVersion(versionResolver)
+	if err != nil {
+		return semver.Version{}, errors.Wrap(err, "failed to determine version of latest jx release")
+	}
+	return streamVersion, nil
+}
+
+// installToVersion installs the requested version warning if it is a downgrade or incompatible with the cluster
+func (o *UpgradeCLIOptions) installToVersion(currentVersion semver.Version, toVersion semver.Version) error {
+	if toVersion.EQ(currentVersion) {
+		log.Logger().Infof("You are already on version %s of jx", util.ColorInfo(currentVersion.String()))
+		return nil
+	}
+	platformVersion, err := o.versionStreamVersion()
+	if err != nil {
+		log.Logger().Warnf("Could not determine the version of jx in the version stream of the cluster: %s", err)
+		platformVersion = toVersion
+	}
+	warnings := CompatibilityWarnings(currentVersion, toVersion, platformVersion)
+	for _, warning := range warnings {
+		log.Logger().Warn(warning)
+	}
+	if len(warnings) > 0 && !o.BatchMode {
+		message := fmt.Sprintf("Would you like to install version %s of jx?", toVersion.String())
+		if !util.Confirm(message, false, "Please indicate if you would like to install the version despite the warnings", o.GetIOFileHandles()) {
+			return nil
+		}
+	}
+	return o.installVersion(toVersion)
+}
+
+// CompatibilityWarnings returns warnings if installing the version is a downgrade or if it is older or a different
+// minor version than the version of jx in the version stream of the cluster
+func CompatibilityWarnings(currentVersion semver.Version, toVersion semver.Version, platformVersion semver.Version) []string {
+	warnings := []string{}
+	if toVersion.LT(currentVersion) {
+		warnings = append(warnings, fmt.Sprintf("version %s is older than the current version %s of jx", toVersion, currentVersion))
+	}
+	if toVersion.LT(platformVersion) {
+		warnings = append(warnings, fmt.Sprintf("version %s is older than version %s in the version stream of the cluster so some commands may not work with the cluster", toVersion, platformVersion))
+	} else if toVersion.Major != platformVersion.Major || toVersion.Minor != platformVersion.Minor {
+		warnings = append(warnings, fmt.Sprintf("version %s is a different minor version to version %s in the version stream of the cluster", toVersion, platformVersion))
+	}
+	return warnings
+}
+
+// LatestPatchVersion returns the latest release version with the same major and minor version as the base version
+func LatestPatchVersion(tags []string, base semver.Version) semver.Version {
+	answer := base
+	for _, tag := range tags {
+		v, err := semver.Parse(strings.TrimPrefix(tag, "v"))
+		if err != nil || len(v.Pre) > 0 {
+			continue
+		}
+		if v.Major == base.Major && v.Minor == base.Minor && v.GT(answer) {
+			answer = v
+		}
+	}
+	return answer
+}
+
+// installVersion downloads and verifies the release archive of the version before replacing the jx binary
+func (o *UpgradeCLIOptions) installVersion(v semver.Version) error {
+	client, err := o.httpClient()
+	if err != nil {
+		return err
+	}
+	dir, err := ioutil.TempDir("", "jx-upgrade-cli-")
+	if err != nil {
+		return errors.Wrap(err, "creating a temporary directory")
+	}
+	defer os.RemoveAll(dir)
+
+	baseURL := o.DownloadURL
+	if baseURL == "" {
+		baseURL = config.BinaryDownloadBaseURL
+	}
+	versionURL := baseURL + v.String() + "/"
+	archiveName := opts.JxArchiveName()
+	archiveFile := filepath.Join(dir, archiveName)
+	err = packages.DownloadFileWithClient(client, versionURL+archiveName, archiveFile)
+	if err != nil {
+		return err
+	}
+	if o.SkipVerify {
+		log.Logger().Warnf("Not verifying the checksum of %s", archiveName)
+	} else {
+		err = o.verify(client, versionURL, archiveFile, archiveName)
+		if err != nil {
+			return errors.Wrapf(err, "verifying version %s of jx", v.String())
+		}
+	}
+	return o.InstallJxArchive(archiveFile)
+}
+
+// verify verifies the checksum of the archive and the signature of the checksums if a public key is specified
+func (o *UpgradeCLIOptions) verify(client *http.Client, versionURL string, archiveFile string, archiveName string) error {
+	checksums, err := download(client, versionURL+config.BinaryChecksumsFileName)
+	if err != nil {
+		return err
+	}
+	if o.PublicKey != "" {
+		publicKey, err := ioutil.ReadFile(o.PublicKey)
+		if err != nil {
+			return errors.Wrapf(err, "reading public key %s", o.PublicKey)
+		}
+		signature, err := download(client, versionURL+config.BinarySignatureFileName)
+		if err != nil {
+			return err
+		}
+		err = packages.VerifySignature(checksums, signature, publicKey)
+		if err != nil {
+			return err
+		}
+		log.Logger().Infof("Verified the signature of %s", util.ColorInfo(config.BinaryChecksumsFileName))
+	} else {
+		log.Logger().Warnf("Not verifying the signature of the release as no %s was specified", util.ColorInfo("--public-key"))
+	}
+	err = packages.VerifyChecksum(archiveFile, archiveName, checksums)
+	if err != nil {
+		return err
+	}
+	log.Logger().Infof("Verified the checksum of %s", util.ColorInfo(archiveName))
+	return nil
+}
+
+func (o *UpgradeCLIOptions) httpClient() (*http.Client, error) {
+	if o.Proxy == "" {
+		return util.GetClientWithTimeout(downloadTimeout), nil
+	}
+	proxyURL, err := url.Parse(o.Proxy)
+	if err != nil {
+		return nil, util.InvalidOptionError("proxy", o.Proxy, err)
+	}
+	transport, err := util.NewTransport()
+	if err != nil {
+		return nil, err
+	}
+	transport.Proxy = http.ProxyURL(proxyURL)
+	return util.GetCustomClient(transport, int(downloadTimeout.Seconds())), nil
+}
+
+func download(client *http.Client, u string) ([]byte, error) {
+	resp, err := client.Get(u)
+	if err != nil {
+		return nil, errors.Wrapf(err, "downloading %s", u)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("download of %s failed with status %d", u, resp.StatusCode)
+	}
+	return ioutil.ReadAll(resp.Body)
 }
 
 func (o *UpgradeCLIOptions) needsUpgrade(currentVersion semver.Version, latestVersion semver.Version) bool {
@@ -199,3 +199,20 @@ func TestVersionCheckWhenCurrentVersionWithPatchIsLessThanReleaseVersion(t *test
 	assert.NoError(t, err, "should check version without failure")
 	assert.False(t, update, "should not update")
 }
+
+func TestLatestPatchVersion(t *testing.T) {
+	base := semver.MustParse("2.0.1000")
+	tags := []string{"v2.1.5", "v2.0.1002", "v2.0.1003-rc1", "v2.0.1001", "v1.3.999", "not-a-version"}
+	assert.Equal(t, "2.0.1002", LatestPatchVersion(tags, base).String())
+	assert.Equal(t, "2.2.0", LatestPatchVersion(tags, semver.MustParse("2.2.0")).String())
+}
+
+func TestCompatibilityWarnings(t *testing.T) {
+	current := semver.MustParse("2.0.1100")
+	platform := semver.MustParse("2.0.1050")
+
+	assert.Empty(t, CompatibilityWarnings(current, semver.MustParse("2.0.1200"), platform))
+	assert.Len(t, CompatibilityWarnings(current, semver.MustParse("2.0.1060"), platform), 1, "downgrade")
+	assert.Len(t, CompatibilityWarnings(current, semver.MustParse("2.0.1000"), platform), 2, "downgrade older than the platform")
+	assert.Len(t, CompatibilityWarnings(current, semver.MustParse("2.1.0"), platform), 1, "different minor version")
+}
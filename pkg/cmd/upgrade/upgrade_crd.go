@@ -1,11 +1,15 @@
 package upgrade
 
 import (
+	"strconv"
+
 	"github.com/jenkins-x/jx/pkg/cmd/helper"
 	"github.com/jenkins-x/jx/pkg/cmd/opts"
 	"github.com/jenkins-x/jx/pkg/cmd/templates"
 	"github.com/jenkins-x/jx/pkg/kube"
+	"github.com/jenkins-x/jx/pkg/kube/migrations"
 	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/util"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 )
@@ -13,17 +17,27 @@ import (
 var (
 	upgradeCRDsLong = templates.LongDesc(`
 		Upgrades the Jenkins X Custom Resource Definitions in the Kubernetes Cluster
+
+		After the Custom Resource Definitions are registered any pending migrations of the stored Environments,
+		PipelineActivities and Apps are run and objects stored at an old storage version are rewritten so that the old
+		versions can be removed from the Custom Resource Definitions.
 `)
 
 	upgradeCRDsExample = templates.Examples(`
 		# Upgrades the Custom Resource Definitions 
 		jx upgrade crd
+
+		# Lists the migrations which would run without modifying the stored objects
+		jx upgrade crd --dry-run
 	`)
 )
 
 // UpgradeCRDsOptions the options for the upgrade CRDs command
 type UpgradeCRDsOptions struct {
 	UpgradeOptions
+
+	SkipMigrations bool
+	DryRun         bool
 }
 
 // NewCmdUpgradeCRDs defines the command
@@ -46,6 +60,8 @@ func NewCmdUpgradeCRDs(commonOpts *opts.CommonOptions) *cobra.Command {
 			helper.CheckErr(err)
 		},
 	}
+	cmd.Flags().BoolVarP(&options.SkipMigrations, "skip-migrations", "", false, "Skips migrating the stored objects of the Custom Resource Definitions")
+	cmd.Flags().BoolVarP(&options.DryRun, "dry-run", "", false, "Reports the migrations which would run without modifying the stored objects")
 	return cmd
 }
 
@@ -60,5 +76,32 @@ func (o *UpgradeCRDsOptions) Run() error {
 		return errors.Wrap(err, "failed to register all CRDs")
 	}
 	log.Logger().Info("Jenkins X CRDs upgraded with success")
+	if o.SkipMigrations {
+		return nil
+	}
+	jxClient, _, err := o.JXClient()
+	if err != nil {
+		return errors.Wrap(err, "failed to create the jx client")
+	}
+	migrator := &migrations.Migrator{
+		APIExtensionsClient: apisClient,
+		JXClient:            jxClient,
+		DryRun:              o.DryRun,
+	}
+	results, err := migrator.Run()
+	if err != nil {
+		return errors.Wrap(err, "failed to migrate the stored objects of the CRDs")
+	}
+	if len(results) == 0 {
+		log.Logger().Info("The stored objects of the Jenkins X CRDs are up to date")
+		return nil
+	}
+	for _, r := range results {
+		if o.DryRun {
+			log.Logger().Infof("Would migrate %s from schema version %d to %d", util.ColorInfo(r.CRD), r.FromVersion, r.ToVersion)
+			continue
+		}
+		log.Logger().Infof("Migrated %s from schema version %d to %d updating %s objects", util.ColorInfo(r.CRD), r.FromVersion, r.ToVersion, util.ColorInfo(strconv.Itoa(r.Updated)))
+	}
 	return nil
 }
@@ -34,11 +34,17 @@ import (
 var (
 	upgrade_platform_long = templates.LongDesc(`
 		Upgrades the Jenkins X platform if there is a newer release
+
+		The upgrade is blocked if the compatibility manifest of the version stream does not list the Kubernetes version
+		of the cluster or the versions of its CustomResourceDefinitions as supported by the new platform version.
 `)
 
 	upgrade_platform_example = templates.Examples(`
 		# Upgrades the Jenkins X platform 
 		jx upgrade platform
+
+		# Shows the plan to upgrade the Jenkins X platform without changing anything
+		jx upgrade platform plan
 	`)
 )
 
@@ -54,6 +60,8 @@ type UpgradePlatformOptions struct {
 	AlwaysUpgrade bool
 	UpdateSecrets bool
 
+	SkipCompatibilityCheck bool
+
 	InstallFlags create.InstallFlags
 }
 
@@ -86,9 +94,11 @@ func NewCmdUpgradePlatform(commonOpts *opts.CommonOptions) *cobra.Command {
 	cmd.Flags().BoolVarP(&options.AlwaysUpgrade, "always-upgrade", "", false, "If set to true, jx will upgrade platform Helm chart even if requested version is already installed.")
 	cmd.Flags().BoolVarP(&options.Flags.CleanupTempFiles, "cleanup-temp-files", "", true, "Cleans up any temporary values.yaml used by helm install [default true].")
 	cmd.Flags().BoolVarP(&options.UpdateSecrets, "update-secrets", "", false, "Regenerate adminSecrets.yaml on upgrade")
+	cmd.Flags().BoolVarP(&options.SkipCompatibilityCheck, "skip-compatibility-check", "", false, "Upgrades even if the cluster is not compatible with the platform version according to the version stream")
 
 	options.InstallFlags.AddCloudEnvOptions(cmd)
 
+	cmd.AddCommand(NewCmdUpgradePlatformPlan(commonOpts))
 	return cmd
 }
 
@@ -187,6 +197,13 @@ func (o *UpgradePlatformOptions) Run() error {
 		return nil
 	}
 
+	if !o.SkipCompatibilityCheck {
+		err = o.VerifyPlatformCompatibility(versionsDir, targetVersion)
+		if err != nil {
+			return err
+		}
+	}
+
 	isGitOps, devEnv := o.GetDevEnv()
 	if isGitOps {
 		if devEnv == nil {
@@ -0,0 +1,356 @@
+package upgrade
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jenkins-x/jx/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/pkg/config"
+	"github.com/jenkins-x/jx/pkg/helm"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/platform"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/jenkins-x/jx/pkg/versionstream"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// upgradeStepCRD the step upgrades a CustomResourceDefinition
+	upgradeStepCRD = "crd"
+	// upgradeStepPlatform the step upgrades the platform chart
+	upgradeStepPlatform = "platform"
+	// upgradeStepApp the step upgrades the chart of an app
+	upgradeStepApp = "app"
+
+	// upgradeRiskLow the step has no known breaking changes or manual steps
+	upgradeRiskLow = "low"
+	// upgradeRiskMedium the step requires manual steps
+	upgradeRiskMedium = "medium"
+	// upgradeRiskHigh the step has breaking changes
+	upgradeRiskHigh = "high"
+)
+
+var (
+	upgradePlatformPlanLong = templates.LongDesc(`
+		Shows the plan to upgrade the Jenkins X platform without changing anything
+
+		The installed platform, apps and CustomResourceDefinitions are compared with the versions of the version stream
+		and the steps to upgrade them are listed in the order they should be applied:
+
+		    * the CustomResourceDefinitions which do not serve the versions the new platform requires
+		    * the platform chart
+		    * the charts of the apps installed in the development namespace
+
+		The risk of each step is based on the upgrade notes of the charts in the version stream, which describe any
+		breaking changes and manual steps, and on the compatibility manifest of the version stream.
+`)
+
+	upgradePlatformPlanExample = templates.Examples(`
+		# Shows the plan to upgrade to the platform version of the version stream
+		jx upgrade platform plan
+
+		# Shows the plan to upgrade to the platform version of a tag of the version stream as JSON
+		jx upgrade platform plan --versions-ref v1.0.200 -o json
+	`)
+)
+
+// UpgradePlatformPlanOptions the options for the upgrade platform plan command
+type UpgradePlatformPlanOptions struct {
+	*opts.CommonOptions
+
+	Version            string
+	ReleaseName        string
+	Namespace          string
+	VersionsRepository string
+	VersionsGitRef     string
+	Output             string
+}
+
+// UpgradePlan the ordered steps to upgrade the platform
+type UpgradePlan struct {
+	// CurrentVersion the installed platform version
+	CurrentVersion string `json:"currentVersion"`
+	// TargetVersion the platform version to upgrade to
+	TargetVersion string `json:"targetVersion"`
+	// Blockers the reasons the cluster cannot be upgraded such as an unsupported kubernetes version
+	Blockers []string `json:"blockers,omitempty"`
+	// Steps the steps in the order they should be applied
+	Steps []UpgradeStep `json:"steps"`
+}
+
+// UpgradeStep a step of the upgrade plan
+type UpgradeStep struct {
+	Kind           string   `json:"kind"`
+	Name           string   `json:"name"`
+	CurrentVersion string   `json:"currentVersion,omitempty"`
+	TargetVersion  string   `json:"targetVersion"`
+	Risk           string   `json:"risk"`
+	Notes          []string `json:"notes,omitempty"`
+	ManualSteps    []string `json:"manualSteps,omitempty"`
+}
+
+// NewCmdUpgradePlatformPlan defines the command
+func NewCmdUpgradePlatformPlan(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &UpgradePlatformPlanOptions{
+		CommonOptions: commonOpts,
+	}
+
+	cmd := &cobra.Command{
+		Use:     "plan",
+		Short:   "Shows the plan to upgrade the Jenkins X platform without changing anything",
+		Long:    upgradePlatformPlanLong,
+		Example: upgradePlatformPlanExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.Flags().StringVarP(&options.Namespace, "namespace", "", "", "The namespace of the platform. Defaults to the development namespace")
+	cmd.Flags().StringVarP(&options.ReleaseName, "name", "n", platform.JenkinsXPlatformRelease, "The release name of the platform")
+	cmd.Flags().StringVarP(&options.Version, "version", "v", "", "The platform version to upgrade to. Defaults to the version of the version stream")
+	cmd.Flags().StringVarP(&options.VersionsRepository, "versions-repo", "", config.DefaultVersionsURL, "Jenkins X versions Git repo")
+	cmd.Flags().StringVarP(&options.VersionsGitRef, "versions-ref", "", "", "Jenkins X versions Git repository reference (tag, branch, sha etc)")
+	cmd.Flags().StringVarP(&options.Output, "output", "o", "", "The output format such as 'json' or 'yaml'")
+	return cmd
+}
+
+// Run implements the command
+func (o *UpgradePlatformPlanOptions) Run() error {
+	if o.Output != "" && o.Output != "json" && o.Output != "yaml" {
+		return util.InvalidOption("output", o.Output, []string{"json", "yaml"})
+	}
+	versionsDir, _, err := o.CloneJXVersionsRepo(o.VersionsRepository, o.VersionsGitRef)
+	if err != nil {
+		return err
+	}
+	ns := o.Namespace
+	if ns == "" {
+		_, ns, err = o.KubeClientAndDevNamespace()
+		if err != nil {
+			return err
+		}
+	}
+	releases, _, err := o.Helm().ListReleases(ns)
+	if err != nil {
+		return errors.Wrap(err, "list charts releases")
+	}
+
+	kubeClient, err := o.KubeClient()
+	if err != nil {
+		return err
+	}
+	kubernetesVersion := ""
+	serverVersion, err := kubeClient.Discovery().ServerVersion()
+	if err != nil {
+		log.Logger().Warnf("Failed to get Kubernetes server version so not checking Kubernetes compatibility: %s", err)
+	} else if serverVersion != nil {
+		kubernetesVersion = serverVersion.String()
+	}
+	crdVersions, err := o.ServedCRDVersions()
+	if err != nil {
+		return err
+	}
+
+	plan, err := buildUpgradePlan(versionsDir, o.ReleaseName, o.Version, releases, kubernetesVersion, crdVersions)
+	if err != nil {
+		return err
+	}
+	if o.Output != "" {
+		return o.renderPlan(plan)
+	}
+	o.printPlan(plan)
+	return nil
+}
+
+// buildUpgradePlan compares the installed releases and CustomResourceDefinitions with the version stream and returns
+// the steps to upgrade them ordered so that the CustomResourceDefinitions are upgraded before the platform and the
+// platform before the apps
+func buildUpgradePlan(versionsDir string, releaseName string, targetVersion string, releases map[string]helm.ReleaseSummary,
+	kubernetesVersion string, crdVersions map[string][]string) (*UpgradePlan, error) {
+	platformRelease, ok := releases[releaseName]
+	if !ok {
+		return nil, fmt.Errorf("the Jenkins X platform helm release %s is not installed", releaseName)
+	}
+	platformStableVersion, err := versionstream.LoadStableVersion(versionsDir, versionstream.KindChart, platform.JenkinsXPlatformChart)
+	if err != nil {
+		return nil, errors.Wrapf(err, "loading the version of chart %s from the version stream", platform.JenkinsXPlatformChart)
+	}
+	if targetVersion == "" {
+		targetVersion = platformStableVersion.Version
+	}
+	if targetVersion == "" {
+		return nil, fmt.Errorf("the version stream has no version for chart %s", platform.JenkinsXPlatformChart)
+	}
+	plan := &UpgradePlan{
+		CurrentVersion: platformRelease.ChartVersion,
+		TargetVersion:  targetVersion,
+		Steps:          []UpgradeStep{},
+	}
+
+	compatibility, err := versionstream.LoadCompatibility(versionsDir)
+	if err != nil {
+		return nil, err
+	}
+	platformCompatibility, err := compatibility.ForPlatform(targetVersion)
+	if err != nil {
+		return nil, err
+	}
+	if platformCompatibility != nil {
+		plan.Blockers, err = platformCompatibility.Incompatibilities(kubernetesVersion, nil)
+		if err != nil {
+			return nil, err
+		}
+		for _, crd := range platformCompatibility.CRDs {
+			served, ok := crdVersions[crd.Name]
+			if !ok || crd.IsServed(served) {
+				continue
+			}
+			step := UpgradeStep{
+				Kind:           upgradeStepCRD,
+				Name:           crd.Name,
+				CurrentVersion: strings.Join(served, ", "),
+				TargetVersion:  strings.Join(crd.Versions, " or "),
+				Risk:           upgradeRiskHigh,
+				Notes:          []string{fmt.Sprintf("the platform %s requires the CustomResourceDefinition to serve one of %s", targetVersion, strings.Join(crd.Versions, ", "))},
+			}
+			if strings.HasSuffix(crd.Name, ".jenkins.io") {
+				step.ManualSteps = []string{"run 'jx upgrade crd'"}
+			} else {
+				step.ManualSteps = []string{"upgrade the chart which installs the CustomResourceDefinition"}
+			}
+			plan.Steps = append(plan.Steps, step)
+		}
+	}
+
+	if platformRelease.ChartVersion != targetVersion {
+		step, err := newUpgradeStep(upgradeStepPlatform, releaseName, platformRelease.ChartVersion, targetVersion, platformStableVersion)
+		if err != nil {
+			return nil, err
+		}
+		plan.Steps = append(plan.Steps, *step)
+	}
+
+	names := []string{}
+	for name := range releases {
+		if name != releaseName {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		release := releases[name]
+		chartName, err := versionstream.FindChartName(versionsDir, release.Chart)
+		if err != nil {
+			return nil, err
+		}
+		if chartName == "" {
+			log.Logger().Debugf("Ignoring release %s as the version stream has no chart %s", name, release.Chart)
+			continue
+		}
+		stableVersion, err := versionstream.LoadStableVersion(versionsDir, versionstream.KindChart, chartName)
+		if err != nil {
+			return nil, errors.Wrapf(err, "loading the version of chart %s from the version stream", chartName)
+		}
+		if stableVersion.Version == "" || stableVersion.Version == release.ChartVersion {
+			continue
+		}
+		step, err := newUpgradeStep(upgradeStepApp, name, release.ChartVersion, stableVersion.Version, stableVersion)
+		if err != nil {
+			return nil, err
+		}
+		plan.Steps = append(plan.Steps, *step)
+	}
+	return plan, nil
+}
+
+// newUpgradeStep creates the step to upgrade a chart using the upgrade notes of the chart in the version stream
+func newUpgradeStep(kind string, name string, currentVersion string, targetVersion string, stableVersion *versionstream.StableVersion) (*UpgradeStep, error) {
+	notes, err := stableVersion.UpgradeNotes(currentVersion, targetVersion)
+	if err != nil {
+		return nil, errors.Wrapf(err, "loading the upgrade notes of %s", name)
+	}
+	step := &UpgradeStep{
+		Kind:           kind,
+		Name:           name,
+		CurrentVersion: currentVersion,
+		TargetVersion:  targetVersion,
+		Risk:           upgradeRiskLow,
+	}
+	for _, note := range notes {
+		if note.Description != "" {
+			if note.Breaking {
+				step.Notes = append(step.Notes, "breaking change: "+note.Description)
+			} else {
+				step.Notes = append(step.Notes, note.Description)
+			}
+		}
+		step.ManualSteps = append(step.ManualSteps, note.ManualSteps...)
+		if note.Breaking {
+			step.Risk = upgradeRiskHigh
+		} else if len(note.ManualSteps) > 0 && step.Risk == upgradeRiskLow {
+			step.Risk = upgradeRiskMedium
+		}
+	}
+	return step, nil
+}
+
+func (o *UpgradePlatformPlanOptions) renderPlan(plan *UpgradePlan) error {
+	var data []byte
+	var err error
+	if o.Output == "json" {
+		data, err = json.MarshalIndent(plan, "", "  ")
+	} else {
+		data, err = yaml.Marshal(plan)
+	}
+	if err != nil {
+		return errors.Wrap(err, "marshalling the upgrade plan")
+	}
+	_, err = fmt.Fprintln(o.Out, string(data))
+	return err
+}
+
+func (o *UpgradePlatformPlanOptions) printPlan(plan *UpgradePlan) {
+	log.Logger().Infof("Upgrade plan from platform version %s to %s", util.ColorInfo(plan.CurrentVersion), util.ColorInfo(plan.TargetVersion))
+	for _, blocker := range plan.Blockers {
+		log.Logger().Errorf("The upgrade is blocked as %s", blocker)
+	}
+	if len(plan.Steps) == 0 {
+		log.Logger().Infof("Everything is up to date with the version stream")
+		return
+	}
+	table := o.CreateTable()
+	table.AddRow("STEP", "KIND", "NAME", "CURRENT", "TARGET", "RISK")
+	for i, step := range plan.Steps {
+		risk := step.Risk
+		switch risk {
+		case upgradeRiskHigh:
+			risk = util.ColorError(risk)
+		case upgradeRiskMedium:
+			risk = util.ColorWarning(risk)
+		}
+		table.AddRow(fmt.Sprintf("%d", i+1), step.Kind, step.Name, step.CurrentVersion, step.TargetVersion, risk)
+	}
+	table.Render()
+
+	for i, step := range plan.Steps {
+		if len(step.Notes) == 0 && len(step.ManualSteps) == 0 {
+			continue
+		}
+		log.Logger().Infof("\nStep %d: %s %s", i+1, step.Kind, util.ColorInfo(step.Name))
+		for _, note := range step.Notes {
+			log.Logger().Infof("  * %s", note)
+		}
+		for _, manualStep := range step.ManualSteps {
+			log.Logger().Infof("  * manual step: %s", manualStep)
+		}
+	}
+	log.Logger().Infof("\nApply the plan with %s, %s and %s", util.ColorInfo("jx upgrade crd"), util.ColorInfo("jx upgrade platform"), util.ColorInfo("jx upgrade apps"))
+}
@@ -0,0 +1,58 @@
+package upgrade
+
+import (
+	"testing"
+
+	"github.com/jenkins-x/jx/pkg/helm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildUpgradePlan(t *testing.T) {
+	t.Parallel()
+	versionsDir := "test_data/upgrade_platform_plan"
+	releases := map[string]helm.ReleaseSummary{
+		"jenkins-x": {ReleaseName: "jenkins-x", Chart: "jenkins-x-platform", ChartVersion: "2.0.1200"},
+		"jx-prow":   {ReleaseName: "jx-prow", Chart: "prow", ChartVersion: "0.0.900"},
+		"tekton":    {ReleaseName: "tekton", Chart: "tekton", ChartVersion: "0.0.40"},
+		"nexus":     {ReleaseName: "nexus", Chart: "nexus", ChartVersion: "0.1.20"},
+		"custom":    {ReleaseName: "custom", Chart: "custom", ChartVersion: "1.0.0"},
+	}
+	crdVersions := map[string][]string{
+		"pipelineruns.tekton.dev": {"v1alpha0"},
+		"environments.jenkins.io": {"v1"},
+	}
+
+	plan, err := buildUpgradePlan(versionsDir, "jenkins-x", "", releases, "v1.13.11-gke.14", crdVersions)
+	require.NoError(t, err)
+	assert.Equal(t, "2.0.1200", plan.CurrentVersion)
+	assert.Equal(t, "2.1.10", plan.TargetVersion)
+	assert.Equal(t, []string{"the cluster is on kubernetes 1.13.11 but the platform requires kubernetes >=1.14.0 <1.17.0"}, plan.Blockers)
+
+	require.Len(t, plan.Steps, 4)
+	steps := []string{}
+	for _, step := range plan.Steps {
+		steps = append(steps, step.Kind+" "+step.Name+" "+step.CurrentVersion+" -> "+step.TargetVersion+" "+step.Risk)
+	}
+	assert.Equal(t, []string{
+		"crd pipelineruns.tekton.dev v1alpha0 -> v1alpha1 high",
+		"platform jenkins-x 2.0.1200 -> 2.1.10 high",
+		"app jx-prow 0.0.900 -> 0.0.1200 medium",
+		"app tekton 0.0.40 -> 0.0.50 low",
+	}, steps)
+
+	platformStep := plan.Steps[1]
+	assert.Equal(t, []string{"breaking change: the jenkins server is no longer installed by default"}, platformStep.Notes)
+	assert.Equal(t, []string{"enable jenkins in myvalues.yaml to keep the jenkins server"}, platformStep.ManualSteps)
+	assert.Equal(t, []string{"delete the hmac-token secret before upgrading"}, plan.Steps[2].ManualSteps)
+
+	plan, err = buildUpgradePlan(versionsDir, "jenkins-x", "2.0.1200", releases, "v1.15.4", crdVersions)
+	require.NoError(t, err)
+	assert.Empty(t, plan.Blockers)
+	for _, step := range plan.Steps {
+		assert.Equal(t, upgradeStepApp, step.Kind, "only the apps should be upgraded when the platform is up to date")
+	}
+
+	_, err = buildUpgradePlan(versionsDir, "jx", "", releases, "", crdVersions)
+	assert.Error(t, err)
+}
@@ -0,0 +1,37 @@
+package wait
+
+import (
+	"github.com/jenkins-x/jx/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/pkg/cmd/opts"
+	"github.com/spf13/cobra"
+)
+
+// WaitOptions contains the command line options
+type WaitOptions struct {
+	*opts.CommonOptions
+}
+
+// NewCmdWait creates the command
+func NewCmdWait(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &WaitOptions{
+		CommonOptions: commonOpts,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "wait",
+		Short: "Waits for Jenkins X resources to reach a state so that scripts can be sequenced on them",
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.AddCommand(NewCmdWaitPipeline(commonOpts))
+	return cmd
+}
+
+// Run implements this command
+func (o *WaitOptions) Run() error {
+	return o.Cmd.Help()
+}
@@ -0,0 +1,248 @@
+package wait
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/builds"
+	"github.com/jenkins-x/jx/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/pkg/kube"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// WaitPipelineOptions contains the command line options
+type WaitPipelineOptions struct {
+	*opts.CommonOptions
+
+	Repository   string
+	PullRequest  int
+	Branch       string
+	Commit       string
+	Context      string
+	Timeout      time.Duration
+	PollInterval time.Duration
+	LogLines     int64
+}
+
+var (
+	waitPipelineLong = templates.LongDesc(`
+		Waits for the pipelines of a Pull Request, branch or commit of a repository to complete.
+
+		If there are pipelines for several contexts the latest pipeline of each context is waited for. The command fails
+		if any of the pipelines does not succeed or they do not complete before the timeout, printing the last lines of
+		the log of each failed step so that scripts can sequence on the pipeline results.
+`)
+
+	waitPipelineExample = templates.Examples(`
+		# Waits for the pipelines of a Pull Request
+		jx wait pipeline --repo myorg/myapp --pr 123 --timeout 30m
+
+		# Waits for the pipeline of a commit on the master branch
+		jx wait pipeline --repo myorg/environment-mycluster-dev --commit 3b5e2c1
+	`)
+)
+
+// NewCmdWaitPipeline creates the command
+func NewCmdWaitPipeline(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &WaitPipelineOptions{
+		CommonOptions: commonOpts,
+	}
+
+	cmd := &cobra.Command{
+		Use:     "pipeline",
+		Short:   "Waits for the pipelines of a Pull Request, branch or commit to complete",
+		Long:    waitPipelineLong,
+		Example: waitPipelineExample,
+		Aliases: []string{"pipelines"},
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.Flags().StringVarP(&options.Repository, "repo", "r", "", "The repository of the pipelines in the form 'owner/repository'")
+	cmd.Flags().IntVarP(&options.PullRequest, "pr", "", 0, "The number of the Pull Request whose pipelines are waited for")
+	cmd.Flags().StringVarP(&options.Branch, "branch", "b", "master", "The branch whose pipelines are waited for if no Pull Request is specified")
+	cmd.Flags().StringVarP(&options.Commit, "commit", "", "", "The SHA, or a prefix of it, of the commit whose pipelines are waited for")
+	cmd.Flags().StringVarP(&options.Context, "context", "", "", "The context of the pipeline to wait for. Defaults to all contexts")
+	cmd.Flags().DurationVarP(&options.Timeout, "timeout", "t", 30*time.Minute, "The maximum time to wait for the pipelines to complete")
+	cmd.Flags().DurationVarP(&options.PollInterval, "poll-interval", "", 10*time.Second, "The time between checks of the pipelines")
+	cmd.Flags().Int64VarP(&options.LogLines, "log-lines", "", 20, "The number of lines of the log of each failed step which are printed")
+	return cmd
+}
+
+// Run implements this command
+func (o *WaitPipelineOptions) Run() error {
+	if o.Repository == "" {
+		return util.MissingOption("repo")
+	}
+	paths := strings.Split(o.Repository, "/")
+	if len(paths) != 2 || paths[0] == "" || paths[1] == "" {
+		return util.InvalidOptionf("repo", o.Repository, "the repository must be in the form 'owner/repository'")
+	}
+	owner, repository := paths[0], paths[1]
+	branch := o.Branch
+	if o.PullRequest > 0 {
+		branch = "PR-" + strconv.Itoa(o.PullRequest)
+	}
+	jxClient, ns, err := o.JXClientAndDevNamespace()
+	if err != nil {
+		return err
+	}
+	kubeClient, err := o.KubeClient()
+	if err != nil {
+		return err
+	}
+
+	description := fmt.Sprintf("%s/%s/%s", owner, repository, branch)
+	if o.Commit != "" {
+		description += " commit " + o.Commit
+	}
+	log.Logger().Infof("Waiting for the pipelines of %s", util.ColorInfo(description))
+
+	deadline := time.Now().Add(o.Timeout)
+	var activities []v1.PipelineActivity
+	for {
+		list, err := jxClient.JenkinsV1().PipelineActivities(ns).List(metav1.ListOptions{})
+		if err != nil {
+			return errors.Wrapf(err, "failed to list the PipelineActivities in namespace %s", ns)
+		}
+		activities = latestActivities(list.Items, owner, repository, branch, o.Commit, o.Context)
+		if len(activities) > 0 && allTerminated(activities) {
+			break
+		}
+		if time.Now().After(deadline) {
+			if len(activities) == 0 {
+				return fmt.Errorf("timed out after %s waiting for a pipeline of %s to start", o.Timeout.String(), description)
+			}
+			o.printActivities(activities)
+			return fmt.Errorf("timed out after %s waiting for the pipelines of %s to complete", o.Timeout.String(), description)
+		}
+		time.Sleep(o.PollInterval)
+	}
+
+	o.printActivities(activities)
+	failed := []string{}
+	for i := range activities {
+		pa := &activities[i]
+		if pa.Spec.Status == v1.ActivityStatusTypeSucceeded {
+			continue
+		}
+		failed = append(failed, pa.Name)
+		err = o.printFailedLogs(kubeClient, ns, pa)
+		if err != nil {
+			log.Logger().Warnf("failed to get the log of %s: %s", pa.Name, err.Error())
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("the pipelines %s did not succeed", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+func (o *WaitPipelineOptions) printActivities(activities []v1.PipelineActivity) {
+	for _, pa := range activities {
+		status := pa.Spec.Status.String()
+		if status == "" {
+			status = v1.ActivityStatusTypePending.String()
+		}
+		context := pa.Spec.Context
+		if context == "" {
+			context = "default"
+		}
+		log.Logger().Infof("Pipeline %s context %s build %s: %s", util.ColorInfo(pa.Name), context, pa.Spec.Build, util.ColorStatus(status))
+	}
+}
+
+// printFailedLogs prints the last lines of the log of the containers of the build pods of the activity which failed
+func (o *WaitPipelineOptions) printFailedLogs(kubeClient kubernetes.Interface, ns string, pa *v1.PipelineActivity) error {
+	pods, err := builds.GetBuildPods(kubeClient, ns)
+	if err != nil {
+		return err
+	}
+	for _, pod := range pods {
+		info := builds.CreateBuildPodInfo(pod)
+		if info == nil || !strings.EqualFold(info.Organisation, pa.Spec.GitOwner) || !strings.EqualFold(info.Repository, pa.Spec.GitRepository) ||
+			!strings.EqualFold(info.Branch, pa.Spec.GitBranch) || info.Build != pa.Spec.Build || info.Context != pa.Spec.Context {
+			continue
+		}
+		_, statuses, _ := kube.GetContainersWithStatusAndIsInit(pod)
+		for _, status := range statuses {
+			terminated := status.State.Terminated
+			if terminated == nil || terminated.ExitCode == 0 {
+				continue
+			}
+			lines := o.LogLines
+			data, err := kubeClient.CoreV1().Pods(ns).GetLogs(pod.Name, &corev1.PodLogOptions{
+				Container: status.Name,
+				TailLines: &lines,
+			}).DoRaw()
+			if err != nil {
+				return errors.Wrapf(err, "failed to get the log of container %s of pod %s", status.Name, pod.Name)
+			}
+			log.Logger().Infof("\n%s", util.ColorError(fmt.Sprintf("The step %s of %s failed with exit code %d:", status.Name, pa.Name, terminated.ExitCode)))
+			fmt.Fprintln(o.Out, strings.TrimRight(string(data), "\n"))
+		}
+	}
+	return nil
+}
+
+// latestActivities returns the latest activity of each context of the branch of the repository optionally filtered by
+// a commit SHA prefix and context
+func latestActivities(activities []v1.PipelineActivity, owner string, repository string, branch string, commit string, context string) []v1.PipelineActivity {
+	latest := map[string]v1.PipelineActivity{}
+	for _, pa := range activities {
+		spec := &pa.Spec
+		if !strings.EqualFold(spec.GitOwner, owner) || !strings.EqualFold(spec.GitRepository, repository) || !strings.EqualFold(spec.GitBranch, branch) {
+			continue
+		}
+		if commit != "" && (spec.LastCommitSHA == "" || !strings.HasPrefix(spec.LastCommitSHA, commit)) {
+			continue
+		}
+		if context != "" && spec.Context != context {
+			continue
+		}
+		current, ok := latest[spec.Context]
+		if !ok || buildNumber(&pa) > buildNumber(&current) {
+			latest[spec.Context] = pa
+		}
+	}
+	answer := []v1.PipelineActivity{}
+	for _, pa := range latest {
+		answer = append(answer, pa)
+	}
+	sort.Slice(answer, func(i, j int) bool {
+		return answer[i].Spec.Context < answer[j].Spec.Context
+	})
+	return answer
+}
+
+func allTerminated(activities []v1.PipelineActivity) bool {
+	for _, pa := range activities {
+		if !pa.Spec.Status.IsTerminated() {
+			return false
+		}
+	}
+	return true
+}
+
+func buildNumber(pa *v1.PipelineActivity) int {
+	n, err := strconv.Atoi(pa.Spec.Build)
+	if err != nil {
+		return 0
+	}
+	return n
+}
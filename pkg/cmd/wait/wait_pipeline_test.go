@@ -0,0 +1,53 @@
+package wait
+
+import (
+	"testing"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestLatestActivities(t *testing.T) {
+	t.Parallel()
+	activity := func(branch string, context string, build string, sha string, status v1.ActivityStatusType) v1.PipelineActivity {
+		return v1.PipelineActivity{
+			ObjectMeta: metav1.ObjectMeta{Name: "myorg-myapp-" + branch + "-" + context + "-" + build},
+			Spec: v1.PipelineActivitySpec{
+				GitOwner:      "myorg",
+				GitRepository: "myapp",
+				GitBranch:     branch,
+				Context:       context,
+				Build:         build,
+				LastCommitSHA: sha,
+				Status:        status,
+			},
+		}
+	}
+	activities := []v1.PipelineActivity{
+		activity("PR-123", "pr-build", "1", "aaa111", v1.ActivityStatusTypeFailed),
+		activity("PR-123", "pr-build", "2", "bbb222", v1.ActivityStatusTypeSucceeded),
+		activity("PR-123", "integration", "1", "bbb222", v1.ActivityStatusTypeRunning),
+		activity("PR-124", "pr-build", "3", "ccc333", v1.ActivityStatusTypeSucceeded),
+		activity("master", "release", "7", "ddd444", v1.ActivityStatusTypeSucceeded),
+	}
+
+	latest := latestActivities(activities, "myorg", "myapp", "pr-123", "", "")
+	require.Len(t, latest, 2)
+	assert.Equal(t, "integration", latest[0].Spec.Context)
+	assert.Equal(t, "pr-build", latest[1].Spec.Context)
+	assert.Equal(t, "2", latest[1].Spec.Build)
+	assert.False(t, allTerminated(latest))
+
+	latest = latestActivities(activities, "myorg", "myapp", "PR-123", "aaa", "")
+	require.Len(t, latest, 1)
+	assert.Equal(t, "1", latest[0].Spec.Build)
+	assert.True(t, allTerminated(latest))
+
+	latest = latestActivities(activities, "myorg", "myapp", "PR-123", "", "pr-build")
+	require.Len(t, latest, 1)
+	assert.Equal(t, v1.ActivityStatusTypeSucceeded, latest[0].Spec.Status)
+
+	assert.Empty(t, latestActivities(activities, "myorg", "myapp", "PR-125", "", ""))
+}
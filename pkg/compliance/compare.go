@@ -0,0 +1,61 @@
+package compliance
+
+// Comparison the differences between the results of two runs of the compliance tests
+type Comparison struct {
+	// Previous the name of the previous run
+	Previous string `json:"previous"`
+	// Current the name of the current run
+	Current string `json:"current"`
+	// NewFailures the tests which failed in the current run but not in the previous run
+	NewFailures []TestResult `json:"newFailures"`
+	// Fixed the tests which failed in the previous run but not in the current run
+	Fixed []TestResult `json:"fixed"`
+	// StillFailing the tests which failed in both runs
+	StillFailing []TestResult `json:"stillFailing"`
+}
+
+// Compare compares the failed tests of the current run with the previous run
+func Compare(previous *Report, current *Report) *Comparison {
+	answer := &Comparison{
+		Previous:     previous.Name,
+		Current:      current.Name,
+		NewFailures:  []TestResult{},
+		Fixed:        []TestResult{},
+		StillFailing: []TestResult{},
+	}
+	previousFailures := failures(previous)
+	currentFailures := failures(current)
+	for _, test := range current.Tests {
+		if test.Status != StatusFailed {
+			continue
+		}
+		if _, ok := previousFailures[test.Key()]; ok {
+			answer.StillFailing = append(answer.StillFailing, test)
+		} else {
+			answer.NewFailures = append(answer.NewFailures, test)
+		}
+	}
+	for _, test := range previous.Tests {
+		if test.Status != StatusFailed {
+			continue
+		}
+		if _, ok := currentFailures[test.Key()]; !ok {
+			answer.Fixed = append(answer.Fixed, test)
+		}
+	}
+	SortTests(answer.NewFailures)
+	SortTests(answer.Fixed)
+	SortTests(answer.StillFailing)
+	return answer
+}
+
+// failures returns the failed tests of the run indexed by their key
+func failures(report *Report) map[string]TestResult {
+	answer := map[string]TestResult{}
+	for _, test := range report.Tests {
+		if test.Status == StatusFailed {
+			answer[test.Key()] = test
+		}
+	}
+	return answer
+}
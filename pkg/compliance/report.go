@@ -0,0 +1,200 @@
+package compliance
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/xml"
+	"io"
+	"io/ioutil"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// StatusPassed the test passed
+	StatusPassed = "PASSED"
+	// StatusFailed the test failed
+	StatusFailed = "FAILED"
+	// StatusSkipped the test was skipped
+	StatusSkipped = "SKIPPED"
+)
+
+// Report the results of a run of the compliance tests
+type Report struct {
+	// Name the name of the run which is the UTC time the results were collected
+	Name string `json:"name"`
+	// Status the status of the run such as complete or failed
+	Status string `json:"status"`
+	// Completed the time the results were collected
+	Completed time.Time `json:"completed"`
+	// Summary the number of tests by status
+	Summary Summary `json:"summary"`
+	// Tests the results of the tests which were not skipped
+	Tests []TestResult `json:"tests,omitempty"`
+}
+
+// Summary the number of tests by status
+type Summary struct {
+	Passed  int `json:"passed"`
+	Failed  int `json:"failed"`
+	Skipped int `json:"skipped"`
+}
+
+// TestResult the result of a test of a compliance plugin
+type TestResult struct {
+	Plugin  string `json:"plugin"`
+	Class   string `json:"class,omitempty"`
+	Name    string `json:"name"`
+	Status  string `json:"status"`
+	Message string `json:"message,omitempty"`
+}
+
+// Key returns the key which identifies the test across runs
+func (t *TestResult) Key() string {
+	return t.Plugin + "/" + t.Class + "/" + t.Name
+}
+
+type junitResults struct {
+	Suites []junitTestSuite `xml:"testsuite"`
+	Cases  []junitTestCase  `xml:"testcase"`
+}
+
+type junitTestSuite struct {
+	Name  string          `xml:"name,attr"`
+	Cases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Failure   *junitMessage `xml:"failure"`
+	Error     *junitMessage `xml:"error"`
+	Skipped   *junitMessage `xml:"skipped"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+}
+
+// NewReport creates the report of a run from the test results
+func NewReport(status string, tests []TestResult, now time.Time) *Report {
+	report := &Report{
+		Name:      now.UTC().Format("20060102T150405Z"),
+		Status:    status,
+		Completed: now,
+		Tests:     []TestResult{},
+	}
+	for _, test := range tests {
+		switch test.Status {
+		case StatusSkipped:
+			report.Summary.Skipped++
+			continue
+		case StatusFailed:
+			report.Summary.Failed++
+		default:
+			report.Summary.Passed++
+		}
+		report.Tests = append(report.Tests, test)
+	}
+	SortTests(report.Tests)
+	return report
+}
+
+// SortTests sorts the failed tests first and then by plugin, class and name
+func SortTests(tests []TestResult) {
+	sort.Slice(tests, func(i, j int) bool {
+		fi := tests[i].Status == StatusFailed
+		fj := tests[j].Status == StatusFailed
+		if fi != fj {
+			return fi
+		}
+		return tests[i].Key() < tests[j].Key()
+	})
+}
+
+// ParseResults parses the JUnit results of all the plugins in the gzipped results tarball of a run. The results of
+// a plugin are the XML files in the plugins/<plugin>/results directory of the tarball
+func ParseResults(r io.Reader) ([]TestResult, error) {
+	gzr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read the gzipped compliance results")
+	}
+	defer gzr.Close()
+	tarReader := tar.NewReader(gzr)
+	answer := []TestResult{}
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read the compliance results archive")
+		}
+		plugin := resultsPlugin(header.Name)
+		if plugin == "" || header.Typeflag != tar.TypeReg {
+			continue
+		}
+		data, err := ioutil.ReadAll(tarReader)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read %s", header.Name)
+		}
+		tests, err := ParseJUnit(plugin, data)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse the JUnit results %s", header.Name)
+		}
+		answer = append(answer, tests...)
+	}
+	return answer, nil
+}
+
+// resultsPlugin returns the plugin of a JUnit results file in the results tarball or an empty string
+func resultsPlugin(fileName string) string {
+	if !strings.HasSuffix(fileName, ".xml") {
+		return ""
+	}
+	parts := strings.Split(path.Clean(strings.TrimPrefix(fileName, "./")), "/")
+	for i := 0; i+3 < len(parts); i++ {
+		if parts[i] == "plugins" && parts[i+2] == "results" {
+			return parts[i+1]
+		}
+	}
+	return ""
+}
+
+// ParseJUnit parses the test results of a plugin from a JUnit XML document
+func ParseJUnit(plugin string, data []byte) ([]TestResult, error) {
+	results := junitResults{}
+	err := xml.Unmarshal(data, &results)
+	if err != nil {
+		return nil, err
+	}
+	cases := results.Cases
+	for _, suite := range results.Suites {
+		cases = append(cases, suite.Cases...)
+	}
+	answer := []TestResult{}
+	for _, tc := range cases {
+		result := TestResult{
+			Plugin: plugin,
+			Class:  tc.Classname,
+			Name:   tc.Name,
+			Status: StatusPassed,
+		}
+		switch {
+		case tc.Failure != nil:
+			result.Status = StatusFailed
+			result.Message = tc.Failure.Message
+		case tc.Error != nil:
+			result.Status = StatusFailed
+			result.Message = tc.Error.Message
+		case tc.Skipped != nil:
+			result.Status = StatusSkipped
+		}
+		answer = append(answer, result)
+	}
+	return answer, nil
+}
@@ -0,0 +1,118 @@
+package compliance_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"testing"
+	"time"
+
+	"github.com/jenkins-x/jx/pkg/compliance"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	e2eResults = `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites>
+  <testsuite name="Kubernetes e2e suite" tests="3">
+    <testcase name="[sig-network] DNS should provide DNS for services" classname="Kubernetes e2e suite"></testcase>
+    <testcase name="[sig-storage] Volumes should mount" classname="Kubernetes e2e suite">
+      <failure message="timed out waiting for the volume">details</failure>
+    </testcase>
+    <testcase name="[sig-apps] Deployment should roll back" classname="Kubernetes e2e suite">
+      <skipped></skipped>
+    </testcase>
+  </testsuite>
+</testsuites>`
+
+	cisResults = `<testsuite name="cis-benchmark" tests="2">
+  <testcase name="1.1.1 Ensure the API server --anonymous-auth argument is false" classname="master"></testcase>
+  <testcase name="4.2.1 Ensure the --anonymous-auth argument is false" classname="node">
+    <error message="anonymous auth is enabled"></error>
+  </testcase>
+</testsuite>`
+)
+
+func createResultsTarball(t *testing.T, files map[string]string) *bytes.Buffer {
+	var buffer bytes.Buffer
+	gzw := gzip.NewWriter(&buffer)
+	tw := tar.NewWriter(gzw)
+	for name, content := range files {
+		err := tw.WriteHeader(&tar.Header{
+			Name:     name,
+			Mode:     0600,
+			Size:     int64(len(content)),
+			Typeflag: tar.TypeReg,
+		})
+		require.NoError(t, err)
+		_, err = tw.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gzw.Close())
+	return &buffer
+}
+
+func TestParseResults(t *testing.T) {
+	t.Parallel()
+	tarball := createResultsTarball(t, map[string]string{
+		"plugins/e2e/results/global/junit_01.xml":     e2eResults,
+		"plugins/cis-benchmark/results/node1/cis.xml": cisResults,
+		"plugins/e2e/results/global/e2e.log":          "not a results file",
+		"resources/cluster/Nodes.json":                "{}",
+	})
+
+	tests, err := compliance.ParseResults(tarball)
+	require.NoError(t, err)
+	require.Len(t, tests, 5)
+
+	now := time.Date(2019, time.November, 5, 10, 30, 0, 0, time.UTC)
+	report := compliance.NewReport("complete", tests, now)
+	assert.Equal(t, "20191105T103000Z", report.Name)
+	assert.Equal(t, compliance.Summary{Passed: 2, Failed: 2, Skipped: 1}, report.Summary)
+	require.Len(t, report.Tests, 4)
+
+	failed := report.Tests[0]
+	assert.Equal(t, compliance.StatusFailed, failed.Status)
+	assert.Equal(t, "cis-benchmark", failed.Plugin)
+	assert.Equal(t, "node", failed.Class)
+	assert.Equal(t, "anonymous auth is enabled", failed.Message)
+
+	failed = report.Tests[1]
+	assert.Equal(t, compliance.StatusFailed, failed.Status)
+	assert.Equal(t, "e2e", failed.Plugin)
+	assert.Equal(t, "timed out waiting for the volume", failed.Message)
+
+	assert.Equal(t, compliance.StatusPassed, report.Tests[2].Status)
+	assert.Equal(t, compliance.StatusPassed, report.Tests[3].Status)
+}
+
+func TestCompare(t *testing.T) {
+	t.Parallel()
+	previous := compliance.NewReport("complete", []compliance.TestResult{
+		{Plugin: "e2e", Name: "a", Status: compliance.StatusFailed},
+		{Plugin: "e2e", Name: "b", Status: compliance.StatusFailed},
+		{Plugin: "e2e", Name: "c", Status: compliance.StatusPassed},
+	}, time.Now().Add(-time.Hour))
+	current := compliance.NewReport("complete", []compliance.TestResult{
+		{Plugin: "e2e", Name: "a", Status: compliance.StatusPassed},
+		{Plugin: "e2e", Name: "b", Status: compliance.StatusFailed},
+		{Plugin: "e2e", Name: "c", Status: compliance.StatusFailed},
+	}, time.Now())
+
+	comparison := compliance.Compare(previous, current)
+	assert.Equal(t, previous.Name, comparison.Previous)
+	assert.Equal(t, current.Name, comparison.Current)
+	assert.Equal(t, []string{"c"}, testNames(comparison.NewFailures))
+	assert.Equal(t, []string{"a"}, testNames(comparison.Fixed))
+	assert.Equal(t, []string{"b"}, testNames(comparison.StillFailing))
+}
+
+func testNames(tests []compliance.TestResult) []string {
+	answer := []string{}
+	for _, test := range tests {
+		answer = append(answer, test.Name)
+	}
+	return answer
+}
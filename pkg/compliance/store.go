@@ -0,0 +1,96 @@
+package compliance
+
+import (
+	"encoding/json"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jenkins-x/jx/pkg/cloud/buckets"
+	"github.com/pkg/errors"
+)
+
+const (
+	// ReportPrefix the prefix of the keys of the compliance reports in the bucket
+	ReportPrefix = "jenkins-x/compliance/"
+
+	// DefaultStoreTimeout the default timeout of reading and writing the bucket
+	DefaultStoreTimeout = time.Minute
+)
+
+// Store stores the compliance reports in a bucket as JSON files so that runs can be compared over time
+type Store struct {
+	BucketURL string
+	Timeout   time.Duration
+}
+
+// NewStore creates a store in the given bucket URL
+func NewStore(bucketURL string) *Store {
+	return &Store{
+		BucketURL: bucketURL,
+		Timeout:   DefaultStoreTimeout,
+	}
+}
+
+// ReportKey returns the key of the report with the given name
+func ReportKey(name string) string {
+	return ReportPrefix + name + ".json"
+}
+
+// Write writes the report to the store returning its key
+func (s *Store) Write(report *Report) (string, error) {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return "", errors.Wrapf(err, "marshalling compliance report %s", report.Name)
+	}
+	key := ReportKey(report.Name)
+	err = buckets.WriteBucket(s.BucketURL, key, data, s.Timeout)
+	if err != nil {
+		return "", errors.Wrapf(err, "storing compliance report %s", report.Name)
+	}
+	return key, nil
+}
+
+// List lists the names of the stored reports from the oldest to the newest
+func (s *Store) List() ([]string, error) {
+	keys, err := buckets.ListBucket(s.BucketURL, ReportPrefix, s.Timeout)
+	if err != nil {
+		return nil, err
+	}
+	answer := []string{}
+	for _, key := range keys {
+		if !strings.HasSuffix(key, ".json") {
+			continue
+		}
+		answer = append(answer, strings.TrimSuffix(path.Base(key), ".json"))
+	}
+	sort.Strings(answer)
+	return answer, nil
+}
+
+// Load loads the report with the given name
+func (s *Store) Load(name string) (*Report, error) {
+	data, err := buckets.ReadBucket(s.BucketURL, ReportKey(name), s.Timeout)
+	if err != nil {
+		return nil, err
+	}
+	report := &Report{}
+	err = json.Unmarshal(data, report)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parsing compliance report %s", name)
+	}
+	return report, nil
+}
+
+// Latest loads the newest stored report or returns nil if there are no stored reports
+func (s *Store) Latest() (*Report, error) {
+	names, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+	if len(names) == 0 {
+		return nil, nil
+	}
+	return s.Load(names[len(names)-1])
+}
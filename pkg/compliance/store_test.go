@@ -0,0 +1,52 @@
+package compliance_test
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jenkins-x/jx/pkg/compliance"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoreWriteAndLoad(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "test-compliance-store-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	store := compliance.NewStore("file://" + dir)
+	latest, err := store.Latest()
+	require.NoError(t, err)
+	assert.Nil(t, latest)
+
+	now := time.Date(2019, time.November, 5, 10, 30, 0, 0, time.UTC)
+	first := compliance.NewReport("complete", []compliance.TestResult{
+		{Plugin: "e2e", Name: "a", Status: compliance.StatusFailed, Message: "failed"},
+	}, now)
+	second := compliance.NewReport("complete", []compliance.TestResult{
+		{Plugin: "e2e", Name: "a", Status: compliance.StatusPassed},
+	}, now.Add(24*time.Hour))
+	key, err := store.Write(second)
+	require.NoError(t, err)
+	assert.Equal(t, "jenkins-x/compliance/20191106T103000Z.json", key)
+	_, err = store.Write(first)
+	require.NoError(t, err)
+
+	names, err := store.List()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"20191105T103000Z", "20191106T103000Z"}, names)
+
+	loaded, err := store.Load(first.Name)
+	require.NoError(t, err)
+	assert.Equal(t, first.Summary, loaded.Summary)
+	assert.Equal(t, first.Tests, loaded.Tests)
+
+	latest, err = store.Latest()
+	require.NoError(t, err)
+	require.NotNil(t, latest)
+	assert.Equal(t, second.Name, latest.Name)
+}
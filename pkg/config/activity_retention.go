@@ -0,0 +1,45 @@
+package config
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ActivityRetentionConfig contains the retention policy of the completed PipelineActivity resources
+type ActivityRetentionConfig struct {
+	// KeepLastN the number of completed activities to keep for each release branch
+	KeepLastN int `json:"keepLastN,omitempty"`
+	// MaxAge the maximum age of completed release activities such as '720h'
+	MaxAge string `json:"maxAge,omitempty"`
+	// PullRequestKeepLastN the number of completed activities to keep for each pull request
+	PullRequestKeepLastN int `json:"pullRequestKeepLastN,omitempty"`
+	// PullRequestMaxAge the maximum age of completed pull request activities such as '48h'
+	PullRequestMaxAge string `json:"pullRequestMaxAge,omitempty"`
+	// Archive if enabled the pruned activities are archived to the 'activities' storage location before deletion
+	Archive bool `json:"archive,omitempty"`
+}
+
+// MaxAgeDuration returns the maximum age of release activities or zero if it is not configured
+func (c *ActivityRetentionConfig) MaxAgeDuration() (time.Duration, error) {
+	return parseRetentionAge("maxAge", c.MaxAge)
+}
+
+// PullRequestMaxAgeDuration returns the maximum age of pull request activities or zero if it is not configured
+func (c *ActivityRetentionConfig) PullRequestMaxAgeDuration() (time.Duration, error) {
+	return parseRetentionAge("pullRequestMaxAge", c.PullRequestMaxAge)
+}
+
+func parseRetentionAge(name string, value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, errors.Wrapf(err, "invalid activityRetention.%s %s", name, value)
+	}
+	if d < 0 {
+		return 0, errors.Errorf("activityRetention.%s %s must not be negative", name, value)
+	}
+	return d, nil
+}
@@ -0,0 +1,85 @@
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// DependenciesConfigFileName is the name of the file inside the .jx directory of a project which declares the
+	// other applications the application depends on
+	DependenciesConfigFileName = "dependencies.yaml"
+)
+
+// DependenciesConfig declares the applications an application depends on so that they can be promoted before it.
+// It is stored in the `.jx/dependencies.yaml` file in projects
+type DependenciesConfig struct {
+	// Dependencies the applications this application depends on
+	Dependencies []AppDependency `json:"dependencies,omitempty"`
+}
+
+// AppDependency an application which another application depends on
+type AppDependency struct {
+	// Name the name of the application
+	Name string `json:"name"`
+}
+
+// LoadDependenciesConfig loads the dependencies from the `.jx/dependencies.yaml` file in the given project directory.
+// Returns nil if the project has no dependencies file
+func LoadDependenciesConfig(projectDir string) (*DependenciesConfig, error) {
+	fileName := filepath.Join(projectDir, ".jx", DependenciesConfigFileName)
+	exists, err := util.FileExists(fileName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to check if file exists %s", fileName)
+	}
+	if !exists {
+		return nil, nil
+	}
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to load file %s", fileName)
+	}
+	config, err := ParseDependenciesConfig(data)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid dependencies in file %s", fileName)
+	}
+	return config, nil
+}
+
+// ParseDependenciesConfig parses and validates the YAML of a dependencies file
+func ParseDependenciesConfig(data []byte) (*DependenciesConfig, error) {
+	config := &DependenciesConfig{}
+	err := yaml.Unmarshal(data, config)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal YAML")
+	}
+	err = config.Validate()
+	if err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// Validate returns an error if a dependency has no name
+func (c *DependenciesConfig) Validate() error {
+	for i, dep := range c.Dependencies {
+		if dep.Name == "" {
+			return fmt.Errorf("dependency %d has no name", i)
+		}
+	}
+	return nil
+}
+
+// Names returns the names of the applications depended on
+func (c *DependenciesConfig) Names() []string {
+	answer := []string{}
+	for _, dep := range c.Dependencies {
+		answer = append(answer, dep.Name)
+	}
+	return answer
+}
@@ -78,6 +78,8 @@ const (
 	RequirementKanikoServiceAccountName = "JX_REQUIREMENT_KANIKO_SA_NAME"
 	// RequirementKaniko if kaniko is required
 	RequirementKaniko = "JX_REQUIREMENT_KANIKO"
+	// RequirementObservability if the observability stack is required
+	RequirementObservability = "JX_REQUIREMENT_OBSERVABILITY"
 	// RequirementIngressTLSProduction use the lets encrypt production server
 	RequirementIngressTLSProduction = "JX_REQUIREMENT_INGRESS_TLS_PRODUCTION"
 	// RequirementChartRepository the helm chart repository for jx
@@ -164,10 +166,37 @@ const (
 	RepositoryTypeNone RepositoryType = "none"
 	// RepositoryTypeNexus if you wish to use Sonatype Nexus as the artifact repository
 	RepositoryTypeNexus RepositoryType = "nexus"
+	// RepositoryTypeGitHubPackages if you wish to use GitHub Packages as the artifact repository
+	RepositoryTypeGitHubPackages RepositoryType = "githubpackages"
+	// RepositoryTypeCodeArtifact if you wish to use AWS CodeArtifact as the artifact repository
+	RepositoryTypeCodeArtifact RepositoryType = "codeartifact"
+	// RepositoryTypeAzureArtifacts if you wish to use Azure Artifacts as the artifact repository
+	RepositoryTypeAzureArtifacts RepositoryType = "azureartifacts"
 )
 
 // RepositoryTypeValues the string values for the repository types
-var RepositoryTypeValues = []string{"none", "bucketrepo", "nexus", "artifactory"}
+var RepositoryTypeValues = []string{"none", "bucketrepo", "nexus", "artifactory", "githubpackages", "codeartifact", "azureartifacts"}
+
+// ChartRepositoryType is the type of the chart repository the release pipelines publish charts to
+type ChartRepositoryType string
+
+const (
+	// ChartRepositoryTypeChartMuseum publishes charts to ChartMuseum (or bucketrepo) via its API
+	ChartRepositoryTypeChartMuseum ChartRepositoryType = "chartmuseum"
+	// ChartRepositoryTypeBucket publishes charts to a static chart repository in a S3, GCS or Azure bucket
+	ChartRepositoryTypeBucket ChartRepositoryType = "bucket"
+	// ChartRepositoryTypeHarbor publishes charts to a project of a Harbor chart repository
+	ChartRepositoryTypeHarbor ChartRepositoryType = "harbor"
+	// ChartRepositoryTypeNexus publishes charts to a Nexus helm hosted repository
+	ChartRepositoryTypeNexus ChartRepositoryType = "nexus"
+	// ChartRepositoryTypeArtifactory publishes charts to an Artifactory helm repository
+	ChartRepositoryTypeArtifactory ChartRepositoryType = "artifactory"
+	// ChartRepositoryTypeOCI pushes charts to an OCI registry
+	ChartRepositoryTypeOCI ChartRepositoryType = "oci"
+)
+
+// ChartRepositoryTypeValues the string values for the chart repository types
+var ChartRepositoryTypeValues = []string{"chartmuseum", "bucket", "harbor", "nexus", "artifactory", "oci"}
 
 const (
 	// DefaultProfileFile location of profle config
@@ -190,6 +219,10 @@ var (
 	LatestVersionStringsBucket = ""
 	// BinaryDownloadBaseURL the base URL for downloading the binary from - will always have "VERSION/jx-OS-ARCH.EXTENSION" appended to it when used
 	BinaryDownloadBaseURL = "https://github.com/jenkins-x/jx/releases/download/v"
+	// BinaryChecksumsFileName the name of the file of the SHA256 checksums of the release archives of each version
+	BinaryChecksumsFileName = "jx-checksums.txt"
+	// BinarySignatureFileName the name of the file of the detached PGP signature of the checksums file of each version
+	BinarySignatureFileName = "jx-checksums.txt.sig"
 	// TLSDocURL the URL presented by `jx step verify preinstall` for documentation on configuring TLS
 	TLSDocURL = "https://jenkins-x.io/docs/getting-started/setup/boot/#ingress"
 )
@@ -287,6 +320,54 @@ type GKEConfig struct {
 	ProjectNumber string `json:"projectNumber,omitempty"`
 }
 
+// EKSConfig contains EKS specific requirements
+type EKSConfig struct {
+	// AccountID the AWS account ID of the cluster
+	AccountID string `json:"accountID,omitempty"`
+	// IRSA if enabled IAM roles for service accounts are used to access AWS services rather than node roles
+	IRSA bool `json:"irsa,omitempty"`
+	// VPCID the ID of the VPC of the cluster
+	VPCID string `json:"vpcID,omitempty"`
+	// IAMRoles the ARNs of the IAM roles of the service accounts indexed by service account name
+	IAMRoles map[string]string `json:"iamRoles,omitempty"`
+}
+
+// ArtifactRepositoryConfig configures the artifact repository the pipelines resolve and deploy maven and npm artifacts
+// with when it is not the bundled nexus
+type ArtifactRepositoryConfig struct {
+	// URL the URL of the Artifactory or Nexus server
+	URL string `json:"url,omitempty"`
+	// Owner the GitHub owner of the GitHub Packages or the AWS account ID which owns the CodeArtifact domain
+	Owner string `json:"owner,omitempty"`
+	// Domain the CodeArtifact domain
+	Domain string `json:"domain,omitempty"`
+	// Region the AWS region of the CodeArtifact domain. Defaults to cluster.region
+	Region string `json:"region,omitempty"`
+	// Organisation the Azure DevOps organisation of the Azure Artifacts feed
+	Organisation string `json:"organisation,omitempty"`
+	// Project the Azure DevOps project of the Azure Artifacts feed if it is project scoped
+	Project string `json:"project,omitempty"`
+	// MavenRepository the name of the maven repository, CodeArtifact repository or Azure Artifacts feed. For GitHub
+	// Packages it is the name of the git repository packages are published to
+	MavenRepository string `json:"mavenRepository,omitempty"`
+	// NpmRepository the name of the npm repository, CodeArtifact repository or Azure Artifacts feed. Defaults to the
+	// mavenRepository
+	NpmRepository string `json:"npmRepository,omitempty"`
+}
+
+// ChartRepositoryConfig configures the chart repository the release pipelines publish charts to. The URL of the chart
+// repository the environments install charts from is cluster.chartRepository
+type ChartRepositoryConfig struct {
+	// Type the kind of chart repository. Defaults to chartmuseum
+	Type ChartRepositoryType `json:"type,omitempty"`
+	// URL the URL charts are published to such as oci://gcr.io/myproject/charts. Defaults to cluster.chartRepository
+	URL string `json:"url,omitempty"`
+	// BucketURL the bucket of a static chart repository such as gs://mycharts or s3://mycharts
+	BucketURL string `json:"bucketURL,omitempty"`
+	// Project the Harbor project charts are published to
+	Project string `json:"project,omitempty"`
+}
+
 // ClusterConfig contains cluster specific requirements
 type ClusterConfig struct {
 	// AzureConfig the azure specific configuration
@@ -295,6 +376,10 @@ type ClusterConfig struct {
 	ChartRepository string `json:"chartRepository,omitempty"`
 	// GKEConfig the gke specific configuration
 	GKEConfig *GKEConfig `json:"gke,omitempty"`
+	// EKSConfig the eks specific configuration
+	EKSConfig *EKSConfig `json:"eks,omitempty"`
+	// LocalConfig the configuration of a cluster running on the local machine via kind or k3d
+	LocalConfig *LocalClusterConfig `json:"local,omitempty"`
 	// EnvironmentGitOwner the default git owner for environment repositories if none is specified explicitly
 	EnvironmentGitOwner string `json:"environmentGitOwner,omitempty"`
 	// EnvironmentGitPublic determines whether jx boot create public or private git repos for the environments
@@ -414,6 +499,17 @@ type VersionStreamConfig struct {
 	Ref string `json:"ref"`
 }
 
+// ApplyConfig contains the configuration of how the charts of the environments are applied to the cluster
+type ApplyConfig struct {
+	// ServerSide if enabled the resources are applied with Kubernetes server-side apply and the resources removed from
+	// the charts are pruned using an inventory of the applied resources
+	ServerSide bool `json:"serverSide,omitempty"`
+	// FieldManager the name of the field manager owning the fields applied. Defaults to 'jx'
+	FieldManager string `json:"fieldManager,omitempty"`
+	// ForceConflicts if enabled the fields owned by other field managers are taken over instead of failing the apply
+	ForceConflicts bool `json:"forceConflicts,omitempty"`
+}
+
 // VeleroConfig contains the configuration for velero
 type VeleroConfig struct {
 	// Namespace the namespace to install velero into
@@ -447,10 +543,19 @@ type GithubAppConfig struct {
 // RequirementsConfig contains the logical installation requirements in the `jx-requirements.yml` file when
 // installing, configuring or upgrading Jenkins X via `jx boot`
 type RequirementsConfig struct {
+	// ActivityRetention the retention policy of the completed PipelineActivity resources
+	ActivityRetention *ActivityRetentionConfig `json:"activityRetention,omitempty"`
+	// Apply configures how the charts of the environments are applied to the cluster
+	Apply *ApplyConfig `json:"apply,omitempty"`
+	// ArtifactRepository the details of the artifact repository when it is artifactory, githubpackages, codeartifact
+	// or azureartifacts
+	ArtifactRepository *ArtifactRepositoryConfig `json:"artifactRepository,omitempty"`
 	// AutoUpdate contains auto update config
 	AutoUpdate AutoUpdateConfig `json:"autoUpdate,omitempty"`
 	// BootConfigURL contains the url to which the dev environment is associated with
 	BootConfigURL string `json:"bootConfigURL,omitempty"`
+	// ChartRepository configures the kind of chart repository the release pipelines publish charts to
+	ChartRepository *ChartRepositoryConfig `json:"chartRepository,omitempty"`
 	// Cluster contains cluster specific requirements
 	Cluster ClusterConfig `json:"cluster"`
 	// Environments the requirements for the environments
@@ -464,6 +569,9 @@ type RequirementsConfig struct {
 	Kaniko bool `json:"kaniko,omitempty"`
 	// Ingress contains ingress specific requirements
 	Ingress IngressConfig `json:"ingress"`
+	// Observability whether to install the Prometheus, Grafana and Loki stack with the dashboards of the jx
+	// controllers, the Tekton pipelines and the applications of each environment
+	Observability bool `json:"observability,omitempty"`
 	// Repository specifies what kind of artifact repository you wish to use for storing artifacts (jars, tarballs, npm modules etc)
 	Repository RepositoryType `json:"repository,omitempty"`
 	// SecretStorage how should we store secrets for the cluster
@@ -921,6 +1029,12 @@ func (c *RequirementsConfig) OverrideRequirementsFromEnvironment(gcloudFn func()
 			c.Kaniko = true
 		}
 	}
+	if "" != os.Getenv(RequirementObservability) {
+		observability := os.Getenv(RequirementObservability)
+		if envVarBoolean(observability) {
+			c.Observability = true
+		}
+	}
 	if "" != os.Getenv(RequirementRepository) {
 		repositoryString := os.Getenv(RequirementRepository)
 		c.Repository = RepositoryType(repositoryString)
@@ -0,0 +1,209 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/jenkins-x/jx/pkg/cloud"
+	"github.com/jenkins-x/jx/pkg/util"
+)
+
+const (
+	// LocalDomain the default domain of a local cluster whose ingress controller is exposed on ports of localhost
+	LocalDomain = "127.0.0.1.nip.io"
+
+	// DefaultLocalRegistryPort the default port of the local container registry
+	DefaultLocalRegistryPort = 5000
+
+	// LocalTunnelNone webhooks are not tunnelled to the local cluster
+	LocalTunnelNone = "none"
+	// LocalTunnelNgrok webhooks are tunnelled to the local cluster via ngrok
+	LocalTunnelNgrok = "ngrok"
+	// LocalTunnelSmee webhooks are relayed to the local cluster via a smee compatible relay service
+	LocalTunnelSmee = "smee"
+
+	// LocalResourcesLightweight runs the Jenkins X components with a single replica and small resource requests
+	LocalResourcesLightweight = "lightweight"
+	// LocalResourcesDefault runs the Jenkins X components with the same resources as on a cloud cluster
+	LocalResourcesDefault = "default"
+)
+
+// LocalTunnelKinds the kinds of tunnels which can forward webhooks to a local cluster
+var LocalTunnelKinds = []string{LocalTunnelNone, LocalTunnelNgrok, LocalTunnelSmee}
+
+// LocalClusterConfig contains the requirements of a cluster running on the local machine via kind or k3d
+type LocalClusterConfig struct {
+	// RegistryName the host name of the local container registry
+	RegistryName string `json:"registryName,omitempty"`
+	// RegistryPort the port of the local container registry
+	RegistryPort int `json:"registryPort,omitempty"`
+	// HTTPPort the port on localhost the ingress controller is exposed on
+	HTTPPort int `json:"httpPort,omitempty"`
+	// HTTPSPort the port on localhost the ingress controller is exposed on for TLS
+	HTTPSPort int `json:"httpsPort,omitempty"`
+	// SelfSignedTLS enables TLS using a self signed certificate as LetsEncrypt cannot reach a local cluster
+	SelfSignedTLS bool `json:"selfSignedTLS,omitempty"`
+	// Tunnel the kind of tunnel which forwards the webhooks of the git provider to the cluster. Either none, ngrok or smee
+	Tunnel string `json:"tunnel,omitempty"`
+	// TunnelURL the public URL of the tunnel which forwards to the ingress controller. Used to register webhooks
+	TunnelURL string `json:"tunnelURL,omitempty"`
+	// Resources the size of the resources of the Jenkins X components. Either lightweight or default
+	Resources string `json:"resources,omitempty"`
+}
+
+// defaultLocalRegistryNames the host names of the registries created alongside the local clusters
+var defaultLocalRegistryNames = map[string]string{
+	cloud.KIND: "kind-registry",
+	cloud.K3D:  "registry.localhost",
+}
+
+// IsLocalCluster returns true if the cluster runs on the local machine
+func (c *RequirementsConfig) IsLocalCluster() bool {
+	return cloud.IsLocal(c.Cluster.Provider)
+}
+
+// IsSelfSignedTLS returns true if TLS uses a self signed certificate rather than LetsEncrypt
+func (c *RequirementsConfig) IsSelfSignedTLS() bool {
+	return c.Ingress.TLS.Enabled && c.Cluster.LocalConfig != nil && c.Cluster.LocalConfig.SelfSignedTLS
+}
+
+// ApplyLocalProfile defaults the requirements of a kind or k3d cluster so that Jenkins X can be booted on a laptop.
+// The local registry is used for images, the ingress controller is reached via localhost and the cloud services
+// like storage buckets and external DNS are disabled. Values which have been configured are not changed
+func (c *RequirementsConfig) ApplyLocalProfile() error {
+	provider := c.Cluster.Provider
+	if provider != cloud.KIND && provider != cloud.K3D {
+		return nil
+	}
+	if c.Cluster.LocalConfig == nil {
+		c.Cluster.LocalConfig = &LocalClusterConfig{}
+	}
+	local := c.Cluster.LocalConfig
+	if local.RegistryName == "" {
+		local.RegistryName = defaultLocalRegistryNames[provider]
+	}
+	if local.RegistryPort == 0 {
+		local.RegistryPort = DefaultLocalRegistryPort
+	}
+	if local.HTTPPort == 0 {
+		local.HTTPPort = 80
+	}
+	if local.HTTPSPort == 0 {
+		local.HTTPSPort = 443
+	}
+	if local.Tunnel == "" {
+		local.Tunnel = LocalTunnelNone
+	}
+	if local.Resources == "" {
+		local.Resources = LocalResourcesLightweight
+	}
+	err := local.Validate()
+	if err != nil {
+		return err
+	}
+
+	if c.Cluster.ClusterName == "" {
+		c.Cluster.ClusterName = provider
+	}
+	if c.Cluster.Registry == "" {
+		c.Cluster.Registry = fmt.Sprintf("%s:%d", local.RegistryName, local.RegistryPort)
+	}
+	if c.Ingress.Domain == "" {
+		c.Ingress.Domain = LocalDomain
+	}
+	c.Ingress.IgnoreLoadBalancer = true
+	c.Ingress.ExternalDNS = false
+	if local.SelfSignedTLS {
+		c.Ingress.TLS.Enabled = true
+		c.Ingress.TLS.Production = false
+		if c.Ingress.TLS.SecretName == "" {
+			c.Ingress.TLS.SecretName = "tls-" + c.Ingress.Domain + "-local"
+		}
+	}
+	if c.Repository == RepositoryTypeUnknown {
+		c.Repository = RepositoryTypeNone
+	}
+	if c.SecretStorage == "" {
+		c.SecretStorage = SecretStorageTypeLocal
+	}
+	c.Kaniko = true
+	for _, storage := range []*StorageEntryConfig{&c.Storage.Logs, &c.Storage.Reports, &c.Storage.Repository, &c.Storage.Backup} {
+		if storage.URL == "" {
+			storage.Enabled = false
+		}
+	}
+	return nil
+}
+
+// Validate validates the local cluster configuration
+func (l *LocalClusterConfig) Validate() error {
+	for name, port := range map[string]int{"registryPort": l.RegistryPort, "httpPort": l.HTTPPort, "httpsPort": l.HTTPSPort} {
+		if port < 0 || port > 65535 {
+			return fmt.Errorf("invalid cluster.local.%s %d", name, port)
+		}
+	}
+	if l.Tunnel != "" && util.StringArrayIndex(LocalTunnelKinds, l.Tunnel) < 0 {
+		return fmt.Errorf("invalid cluster.local.tunnel %s, should be one of %v", l.Tunnel, LocalTunnelKinds)
+	}
+	if l.Resources != "" && l.Resources != LocalResourcesLightweight && l.Resources != LocalResourcesDefault {
+		return fmt.Errorf("invalid cluster.local.resources %s, should be either %s or %s", l.Resources, LocalResourcesLightweight, LocalResourcesDefault)
+	}
+	if l.Tunnel != "" && l.Tunnel != LocalTunnelNone && l.TunnelURL == "" {
+		return fmt.Errorf("missing cluster.local.tunnelURL which is required to register webhooks via the %s tunnel", l.Tunnel)
+	}
+	return nil
+}
+
+// LocalClusterValues the helm value overrides of the Jenkins X components on a local cluster which are used if the
+// boot configuration has no values for the kubernetes provider. The ingress controller listens on the host ports
+// mapped to localhost and the components run a single replica with small resource requests
+const LocalClusterValues = `nginx-ingress:
+  controller:
+    kind: DaemonSet
+    hostNetwork: false
+    hostPort:
+      enabled: true
+    service:
+      type: NodePort
+    replicaCount: 1
+    resources:
+      requests:
+        cpu: 50m
+        memory: 90Mi
+  defaultBackend:
+    resources:
+      requests:
+        cpu: 10m
+        memory: 20Mi
+tekton:
+  webhook:
+    resources:
+      requests:
+        cpu: 20m
+        memory: 64Mi
+  controller:
+    resources:
+      requests:
+        cpu: 50m
+        memory: 128Mi
+lighthouse:
+  replicaCount: 1
+  resources:
+    requests:
+      cpu: 50m
+      memory: 64Mi
+prow:
+  hook:
+    replicaCount: 1
+  deck:
+    replicaCount: 1
+chartmuseum:
+  resources:
+    requests:
+      cpu: 20m
+      memory: 64Mi
+controllerbuild:
+  resources:
+    requests:
+      cpu: 20m
+      memory: 64Mi
+`
@@ -0,0 +1,73 @@
+package config_test
+
+import (
+	"testing"
+
+	"github.com/jenkins-x/jx/pkg/cloud"
+	"github.com/jenkins-x/jx/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyLocalProfile(t *testing.T) {
+	t.Parallel()
+
+	requirements := config.NewRequirementsConfig()
+	requirements.Cluster.Provider = cloud.KIND
+	requirements.Storage.Logs.Enabled = true
+	requirements.Cluster.LocalConfig = &config.LocalClusterConfig{SelfSignedTLS: true}
+
+	err := requirements.ApplyLocalProfile()
+	require.NoError(t, err)
+
+	assert.Equal(t, "kind", requirements.Cluster.ClusterName)
+	assert.Equal(t, "kind-registry:5000", requirements.Cluster.Registry)
+	assert.Equal(t, config.LocalDomain, requirements.Ingress.Domain)
+	assert.True(t, requirements.Ingress.IgnoreLoadBalancer)
+	assert.False(t, requirements.Storage.Logs.Enabled)
+	assert.Equal(t, config.RepositoryTypeNone, requirements.Repository)
+	assert.True(t, requirements.IsSelfSignedTLS())
+	assert.Equal(t, "tls-127.0.0.1.nip.io-local", requirements.Ingress.TLS.SecretName)
+	assert.Equal(t, config.LocalResourcesLightweight, requirements.Cluster.LocalConfig.Resources)
+	assert.True(t, requirements.IsLocalCluster())
+}
+
+func TestApplyLocalProfileKeepsConfiguredValues(t *testing.T) {
+	t.Parallel()
+
+	requirements := config.NewRequirementsConfig()
+	requirements.Cluster.Provider = cloud.K3D
+	requirements.Cluster.Registry = "myregistry:5001"
+	requirements.Ingress.Domain = "192.168.1.10.nip.io"
+
+	err := requirements.ApplyLocalProfile()
+	require.NoError(t, err)
+
+	assert.Equal(t, "myregistry:5001", requirements.Cluster.Registry)
+	assert.Equal(t, "192.168.1.10.nip.io", requirements.Ingress.Domain)
+	assert.Equal(t, "registry.localhost", requirements.Cluster.LocalConfig.RegistryName)
+	assert.False(t, requirements.IsSelfSignedTLS())
+}
+
+func TestApplyLocalProfileIgnoresCloudProviders(t *testing.T) {
+	t.Parallel()
+
+	requirements := config.NewRequirementsConfig()
+	requirements.Cluster.Provider = cloud.GKE
+
+	err := requirements.ApplyLocalProfile()
+	require.NoError(t, err)
+	assert.Nil(t, requirements.Cluster.LocalConfig)
+	assert.Empty(t, requirements.Cluster.Registry)
+}
+
+func TestLocalClusterConfigValidate(t *testing.T) {
+	t.Parallel()
+
+	assert.Error(t, (&config.LocalClusterConfig{Tunnel: "carrier-pigeon"}).Validate())
+	assert.Error(t, (&config.LocalClusterConfig{Tunnel: config.LocalTunnelNgrok}).Validate())
+	assert.Error(t, (&config.LocalClusterConfig{HTTPPort: 70000}).Validate())
+	assert.Error(t, (&config.LocalClusterConfig{Resources: "huge"}).Validate())
+	assert.NoError(t, (&config.LocalClusterConfig{Tunnel: config.LocalTunnelNgrok, TunnelURL: "https://abc.ngrok.io"}).Validate())
+	assert.NoError(t, (&config.LocalClusterConfig{Tunnel: config.LocalTunnelSmee, TunnelURL: "https://smee.io/abc123"}).Validate())
+}
@@ -0,0 +1,187 @@
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// NotificationsConfigFileName is the name of the notifications configuration file in the dev environment repository
+	NotificationsConfigFileName = "notifications.yaml"
+
+	// NotificationPipelineFailed a pipeline failed
+	NotificationPipelineFailed = "pipeline-failed"
+	// NotificationReleasePublished a release pipeline succeeded publishing a new version
+	NotificationReleasePublished = "release-published"
+	// NotificationPromotionAwaitingApproval a promotion Pull Request was raised and is waiting to be approved and merged
+	NotificationPromotionAwaitingApproval = "promotion-awaiting-approval"
+	// NotificationBootUpgradePullRequest a Pull Request was raised to upgrade the boot configuration
+	NotificationBootUpgradePullRequest = "boot-upgrade-pr-raised"
+	// NotificationCostReport a report of the estimated cost of the environments and previews
+	NotificationCostReport = "cost-report"
+
+	// NotificationChannelSlack posts to a Slack incoming webhook
+	NotificationChannelSlack = "slack"
+	// NotificationChannelTeams posts to a Microsoft Teams incoming webhook
+	NotificationChannelTeams = "teams"
+	// NotificationChannelWebhook posts the event as JSON to a generic webhook
+	NotificationChannelWebhook = "webhook"
+)
+
+var (
+	// NotificationEvents the events which can be notified
+	NotificationEvents = []string{NotificationPipelineFailed, NotificationReleasePublished, NotificationPromotionAwaitingApproval, NotificationBootUpgradePullRequest, NotificationCostReport}
+
+	// NotificationChannelKinds the supported kinds of notification channels
+	NotificationChannelKinds = []string{NotificationChannelSlack, NotificationChannelTeams, NotificationChannelWebhook}
+)
+
+// NotificationsConfig configures the notifications sent by the controllers. It is stored in the
+// `notifications.yaml` file in the dev environment repository
+type NotificationsConfig struct {
+	// Channels the channels notifications can be sent to
+	Channels []NotificationChannel `json:"channels,omitempty"`
+	// Routes the rules of which events are sent to which channels
+	Routes []NotificationRoute `json:"routes,omitempty"`
+	// Templates overrides the Go templates of the messages for each event
+	Templates map[string]string `json:"templates,omitempty"`
+}
+
+// NotificationChannel a Slack, Microsoft Teams or generic webhook to send notifications to
+type NotificationChannel struct {
+	// Name the name of the channel used by the routes
+	Name string `json:"name"`
+	// Kind the kind of channel which is one of slack, teams or webhook
+	Kind string `json:"kind"`
+	// URL the URL of the webhook
+	URL string `json:"url,omitempty"`
+	// URLFromEnv the environment variable containing the URL of the webhook so that it does not need to be stored in git
+	URLFromEnv string `json:"urlFromEnv,omitempty"`
+}
+
+// NotificationRoute sends the matching events to channels
+type NotificationRoute struct {
+	// Events the events to match. Matches all events if empty
+	Events []string `json:"events,omitempty"`
+	// Repositories the 'owner/name' of the repositories to match which may end with a '*' wildcard. Matches all
+	// repositories if empty
+	Repositories []string `json:"repositories,omitempty"`
+	// Environments the environments to match. Matches all environments if empty
+	Environments []string `json:"environments,omitempty"`
+	// Channels the names of the channels to send the events to
+	Channels []string `json:"channels"`
+	// Template overrides the Go template of the message for the events of this route
+	Template string `json:"template,omitempty"`
+}
+
+// LoadNotificationsConfig loads the notifications configuration from the `notifications.yaml` file in the given
+// directory. Returns nil if there is no configuration file
+func LoadNotificationsConfig(dir string) (*NotificationsConfig, error) {
+	fileName := filepath.Join(dir, NotificationsConfigFileName)
+	exists, err := util.FileExists(fileName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to check if file exists %s", fileName)
+	}
+	if !exists {
+		return nil, nil
+	}
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to load file %s", fileName)
+	}
+	config := &NotificationsConfig{}
+	err = yaml.Unmarshal(data, config)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal YAML file %s", fileName)
+	}
+	err = config.Validate()
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid configuration in file %s", fileName)
+	}
+	return config, nil
+}
+
+// Validate returns an error if the channels or routes are invalid
+func (c *NotificationsConfig) Validate() error {
+	for _, channel := range c.Channels {
+		if channel.Name == "" {
+			return fmt.Errorf("a channel has no name")
+		}
+		if util.StringArrayIndex(NotificationChannelKinds, channel.Kind) < 0 {
+			return util.InvalidOption("kind", channel.Kind, NotificationChannelKinds)
+		}
+		if channel.URL == "" && channel.URLFromEnv == "" {
+			return fmt.Errorf("channel %s has no url or urlFromEnv", channel.Name)
+		}
+	}
+	for _, route := range c.Routes {
+		for _, event := range route.Events {
+			if util.StringArrayIndex(NotificationEvents, event) < 0 {
+				return util.InvalidOption("events", event, NotificationEvents)
+			}
+		}
+		if len(route.Channels) == 0 {
+			return fmt.Errorf("a route has no channels")
+		}
+		for _, name := range route.Channels {
+			if c.Channel(name) == nil {
+				return fmt.Errorf("a route uses the unknown channel %s", name)
+			}
+		}
+	}
+	for event := range c.Templates {
+		if util.StringArrayIndex(NotificationEvents, event) < 0 {
+			return util.InvalidOption("templates", event, NotificationEvents)
+		}
+	}
+	return nil
+}
+
+// Channel returns the channel with the given name or nil if there is no such channel
+func (c *NotificationsConfig) Channel(name string) *NotificationChannel {
+	for i := range c.Channels {
+		if c.Channels[i].Name == name {
+			return &c.Channels[i]
+		}
+	}
+	return nil
+}
+
+// MatchingRoutes returns the routes matching the event for the repository and environment
+func (c *NotificationsConfig) MatchingRoutes(event string, repository string, environment string) []NotificationRoute {
+	answer := []NotificationRoute{}
+	for _, route := range c.Routes {
+		if route.Matches(event, repository, environment) {
+			answer = append(answer, route)
+		}
+	}
+	return answer
+}
+
+// Matches returns true if the route matches the event for the repository and environment
+func (r *NotificationRoute) Matches(event string, repository string, environment string) bool {
+	if len(r.Events) > 0 && util.StringArrayIndex(r.Events, event) < 0 {
+		return false
+	}
+	if len(r.Environments) > 0 && util.StringArrayIndex(r.Environments, environment) < 0 {
+		return false
+	}
+	return util.StringMatchesAny(repository, r.Repositories, nil)
+}
+
+// WebhookURL returns the URL of the webhook of the channel
+func (c *NotificationChannel) WebhookURL() string {
+	if c.URLFromEnv != "" {
+		value := os.Getenv(c.URLFromEnv)
+		if value != "" {
+			return value
+		}
+	}
+	return c.URL
+}
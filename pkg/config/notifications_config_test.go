@@ -0,0 +1,55 @@
+package config_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x/jx/pkg/config"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadNotificationsConfig(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := config.LoadNotificationsConfig(filepath.Join("test_data", "notifications"))
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+	assert.Len(t, cfg.Channels, 2)
+	assert.Equal(t, "Shipped {{ .Repository }} {{ .Version }}", cfg.Templates[config.NotificationReleasePublished])
+
+	routes := cfg.MatchingRoutes(config.NotificationPipelineFailed, "myorg/myapp", "")
+	require.Len(t, routes, 1)
+	assert.Equal(t, []string{"team-slack"}, routes[0].Channels)
+	assert.Empty(t, cfg.MatchingRoutes(config.NotificationPipelineFailed, "otherorg/myapp", ""))
+
+	assert.Len(t, cfg.MatchingRoutes(config.NotificationPromotionAwaitingApproval, "myorg/myapp", "production"), 1)
+	assert.Empty(t, cfg.MatchingRoutes(config.NotificationPromotionAwaitingApproval, "myorg/myapp", "staging"))
+
+	cfg, err = config.LoadNotificationsConfig("test_data")
+	require.NoError(t, err)
+	assert.Nil(t, cfg, "there should be no configuration without a notifications.yaml file")
+}
+
+func TestInvalidNotificationsConfig(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "test-notifications-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	for _, text := range []string{
+		"channels:\n- name: chat\n  kind: irc\n  url: https://example.com\n",
+		"channels:\n- name: chat\n  kind: slack\n",
+		"routes:\n- channels:\n  - chat\n",
+		"channels:\n- name: chat\n  kind: slack\n  url: https://example.com\nroutes:\n- events:\n  - pipeline-exploded\n  channels:\n  - chat\n",
+	} {
+		err = ioutil.WriteFile(filepath.Join(dir, config.NotificationsConfigFileName), []byte(text), util.DefaultWritePermissions)
+		require.NoError(t, err)
+		_, err = config.LoadNotificationsConfig(dir)
+		assert.Error(t, err, "loading %s", text)
+	}
+}
@@ -28,6 +28,8 @@ const (
 type ProjectConfig struct {
 	// List of global environment variables to add to each branch build and each step
 	Env []corev1.EnvVar `json:"env,omitempty"`
+	// List of external config stores the environment variables of each step are loaded from when the pipeline starts
+	EnvFrom []EnvFromSource `json:"envFrom,omitempty"`
 
 	PreviewEnvironments *PreviewEnvironmentConfig   `json:"previewEnvironments,omitempty"`
 	IssueTracker        *IssueTrackerConfig         `json:"issueTracker,omitempty"`
@@ -43,6 +45,27 @@ type ProjectConfig struct {
 	DockerRegistryOwner string                      `json:"dockerRegistryOwner,omitempty"`
 }
 
+// EnvFromSource an external config store whose values are added as environment variables to the steps of the pipelines.
+// Exactly one of the stores should be specified
+type EnvFromSource struct {
+	// Vault the path of a vault secret such as secret/data/myapp whose keys are the environment variable names
+	Vault string `json:"vault,omitempty"`
+	// AWSParameterStore the path of the AWS Systems Manager parameters. The names of the parameters below the path are
+	// converted into environment variable names
+	AWSParameterStore string `json:"awsParameterStore,omitempty"`
+	// GCPSecretManager the name of a GCP Secret Manager secret. If the secret is a JSON object its keys are the
+	// environment variable names otherwise its value is the value of the environment variable Name
+	GCPSecretManager string `json:"gcpSecretManager,omitempty"`
+	// Name the name of the environment variable of a single value secret
+	Name string `json:"name,omitempty"`
+	// Prefix the prefix added to the environment variable names
+	Prefix string `json:"prefix,omitempty"`
+	// Region the AWS region of the parameters. Defaults to the region of the cluster
+	Region string `json:"region,omitempty"`
+	// Project the GCP project of the secret. Defaults to the project of gcloud
+	Project string `json:"project,omitempty"`
+}
+
 type PreviewEnvironmentConfig struct {
 	Disabled         bool `json:"disabled,omitempty"`
 	MaximumInstances int  `json:"maximumInstances,omitempty"`
@@ -0,0 +1,160 @@
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// ReleaseNotesConfigFileName is the name of the release notes configuration file inside the .jx directory of a project
+	ReleaseNotesConfigFileName = "release-notes.yaml"
+
+	// ReleaseNotesTargetRelease publishes the release notes as the body of the GitHub or GitLab release
+	ReleaseNotesTargetRelease = "release"
+	// ReleaseNotesTargetSlack posts the release notes to a Slack incoming webhook
+	ReleaseNotesTargetSlack = "slack"
+	// ReleaseNotesTargetConfluence creates or updates a Confluence page with the release notes
+	ReleaseNotesTargetConfluence = "confluence"
+	// ReleaseNotesTargetBranch commits the release notes to a branch of a git repository such as a static docs site
+	ReleaseNotesTargetBranch = "branch"
+)
+
+var (
+	// ReleaseNotesTargetKinds the supported kinds of release notes targets
+	ReleaseNotesTargetKinds = []string{ReleaseNotesTargetRelease, ReleaseNotesTargetSlack, ReleaseNotesTargetConfluence, ReleaseNotesTargetBranch}
+)
+
+// ReleaseNotesConfig configures where `jx step changelog` publishes the release notes of a project. It is stored in
+// the `.jx/release-notes.yaml` file in projects
+type ReleaseNotesConfig struct {
+	// Targets the targets to publish the release notes to
+	Targets []ReleaseNotesTarget `json:"targets,omitempty"`
+}
+
+// ReleaseNotesTarget a target to publish the release notes to
+type ReleaseNotesTarget struct {
+	// Name the name of the target
+	Name string `json:"name"`
+	// Kind the kind of target which is one of release, slack, confluence or branch
+	Kind string `json:"kind"`
+	// Template the Go template of the release notes. Defaults to a template for the kind of target
+	Template string `json:"template,omitempty"`
+	// TemplateFile the file in the project containing the Go template of the release notes
+	TemplateFile string `json:"templateFile,omitempty"`
+	// URL the URL of the Slack webhook or the base URL of Confluence such as https://myorg.atlassian.net/wiki
+	URL string `json:"url,omitempty"`
+	// URLFromEnv the environment variable containing the URL so that it does not need to be stored in git
+	URLFromEnv string `json:"urlFromEnv,omitempty"`
+	// Space the key of the Confluence space of the page
+	Space string `json:"space,omitempty"`
+	// UsernameFromEnv the environment variable containing the Confluence user name. Defaults to CONFLUENCE_USER
+	UsernameFromEnv string `json:"usernameFromEnv,omitempty"`
+	// TokenFromEnv the environment variable containing the Confluence API token. Defaults to CONFLUENCE_TOKEN
+	TokenFromEnv string `json:"tokenFromEnv,omitempty"`
+	// ParentID the ID of the parent Confluence page
+	ParentID string `json:"parentId,omitempty"`
+	// Title the Go template of the title of the Confluence page
+	Title string `json:"title,omitempty"`
+	// Repository the git URL of the repository to commit the release notes to. Defaults to the project repository
+	Repository string `json:"repository,omitempty"`
+	// Branch the branch to commit the release notes to. Defaults to gh-pages
+	Branch string `json:"branch,omitempty"`
+	// Path the Go template of the file to write the release notes to in the branch
+	Path string `json:"path,omitempty"`
+}
+
+// LoadReleaseNotesConfig loads the release notes configuration from the `.jx/release-notes.yaml` file in the given
+// project directory. Returns nil if the project has no configuration file
+func LoadReleaseNotesConfig(projectDir string) (*ReleaseNotesConfig, error) {
+	fileName := filepath.Join(projectDir, ".jx", ReleaseNotesConfigFileName)
+	exists, err := util.FileExists(fileName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to check if file exists %s", fileName)
+	}
+	if !exists {
+		return nil, nil
+	}
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to load file %s", fileName)
+	}
+	config := &ReleaseNotesConfig{}
+	err = yaml.Unmarshal(data, config)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal YAML file %s", fileName)
+	}
+	for i := range config.Targets {
+		target := &config.Targets[i]
+		if target.TemplateFile != "" && target.Template == "" {
+			templateFile := filepath.Join(projectDir, target.TemplateFile)
+			data, err := ioutil.ReadFile(templateFile)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to load the template file %s of target %s", templateFile, target.Name)
+			}
+			target.Template = string(data)
+		}
+	}
+	err = config.Validate()
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid configuration in file %s", fileName)
+	}
+	return config, nil
+}
+
+// Validate returns an error if the targets are invalid
+func (c *ReleaseNotesConfig) Validate() error {
+	names := map[string]bool{}
+	for _, target := range c.Targets {
+		if target.Name == "" {
+			return fmt.Errorf("a target has no name")
+		}
+		if names[target.Name] {
+			return fmt.Errorf("duplicate target %s", target.Name)
+		}
+		names[target.Name] = true
+		if util.StringArrayIndex(ReleaseNotesTargetKinds, target.Kind) < 0 {
+			return util.InvalidOption("kind", target.Kind, ReleaseNotesTargetKinds)
+		}
+		switch target.Kind {
+		case ReleaseNotesTargetSlack:
+			if target.URL == "" && target.URLFromEnv == "" {
+				return fmt.Errorf("target %s has no url or urlFromEnv", target.Name)
+			}
+		case ReleaseNotesTargetConfluence:
+			if target.URL == "" && target.URLFromEnv == "" {
+				return fmt.Errorf("target %s has no url or urlFromEnv", target.Name)
+			}
+			if target.Space == "" {
+				return fmt.Errorf("target %s has no space", target.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// Target returns the first target of the given kind or nil if there is no such target
+func (c *ReleaseNotesConfig) Target(kind string) *ReleaseNotesTarget {
+	for i := range c.Targets {
+		if c.Targets[i].Kind == kind {
+			return &c.Targets[i]
+		}
+	}
+	return nil
+}
+
+// TargetURL returns the URL of the target, preferring the value of the URLFromEnv environment variable if it is set
+func (t *ReleaseNotesTarget) TargetURL() string {
+	if t.URLFromEnv != "" {
+		value := os.Getenv(t.URLFromEnv)
+		if value != "" {
+			return value
+		}
+	}
+	return t.URL
+}
@@ -0,0 +1,49 @@
+package config_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x/jx/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadReleaseNotesConfig(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := config.LoadReleaseNotesConfig(filepath.Join("test_data", "release_notes"))
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+	require.Len(t, cfg.Targets, 4)
+
+	release := cfg.Target(config.ReleaseNotesTargetRelease)
+	require.NotNil(t, release)
+	assert.Contains(t, release.Template, "{{ .Markdown }}", "the template should be loaded from the template file")
+
+	confluence := cfg.Target(config.ReleaseNotesTargetConfluence)
+	require.NotNil(t, confluence)
+	assert.Equal(t, "ENG", confluence.Space)
+
+	cfg, err = config.LoadReleaseNotesConfig("test_data")
+	require.NoError(t, err)
+	assert.Nil(t, cfg, "there should be no configuration without a .jx/release-notes.yaml file")
+}
+
+func TestInvalidReleaseNotesConfig(t *testing.T) {
+	t.Parallel()
+
+	invalid := []config.ReleaseNotesConfig{
+		{Targets: []config.ReleaseNotesTarget{{Kind: config.ReleaseNotesTargetRelease}}},
+		{Targets: []config.ReleaseNotesTarget{{Name: "wiki", Kind: "wiki"}}},
+		{Targets: []config.ReleaseNotesTarget{{Name: "slack", Kind: config.ReleaseNotesTargetSlack}}},
+		{Targets: []config.ReleaseNotesTarget{{Name: "wiki", Kind: config.ReleaseNotesTargetConfluence, URL: "https://wiki"}}},
+		{Targets: []config.ReleaseNotesTarget{
+			{Name: "docs", Kind: config.ReleaseNotesTargetBranch},
+			{Name: "docs", Kind: config.ReleaseNotesTargetBranch},
+		}},
+	}
+	for i := range invalid {
+		assert.Error(t, invalid[i].Validate(), "config %d should be invalid", i)
+	}
+}
@@ -0,0 +1,190 @@
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// TerraformOutputsConfigFileName is the name of the file inside the .jx directory of the boot configuration which
+	// maps the terraform outputs to the requirements
+	TerraformOutputsConfigFileName = "terraform-outputs.yaml"
+)
+
+// TerraformOutputsConfig maps the outputs of terraform to the values of the `jx-requirements.yml` file so that
+// infrastructure created by terraform can be booted without copying the values by hand
+type TerraformOutputsConfig struct {
+	// Mappings the mappings of the outputs to the requirements
+	Mappings []TerraformOutputMapping `json:"mappings,omitempty"`
+}
+
+// TerraformOutputMapping maps a terraform output to a requirement
+type TerraformOutputMapping struct {
+	// Output the name of the output. A path such as 'buckets.logs' can be used to select a value of a map output
+	Output string `json:"output"`
+	// Requirement the path of the requirement in the `jx-requirements.yml` file such as 'cluster.clusterName'
+	Requirement string `json:"requirement"`
+	// Format an optional format of the value such as 's3://%s' to convert a bucket name to a URL
+	Format string `json:"format,omitempty"`
+	// Required fails if the output does not exist
+	Required bool `json:"required,omitempty"`
+}
+
+// DefaultTerraformOutputsConfig returns the mappings of the outputs of the Jenkins X terraform modules for GKE and EKS
+func DefaultTerraformOutputsConfig() *TerraformOutputsConfig {
+	return &TerraformOutputsConfig{
+		Mappings: []TerraformOutputMapping{
+			{Output: "cluster_name", Requirement: "cluster.clusterName"},
+			{Output: "gcp_project", Requirement: "cluster.project"},
+			{Output: "zone", Requirement: "cluster.zone"},
+			{Output: "region", Requirement: "cluster.region"},
+			{Output: "vpc_id", Requirement: "cluster.eks.vpcID"},
+			{Output: "log_storage_url", Requirement: "storage.logs.url"},
+			{Output: "report_storage_url", Requirement: "storage.reports.url"},
+			{Output: "repository_storage_url", Requirement: "storage.repository.url"},
+			{Output: "backup_bucket_url", Requirement: "storage.backup.url"},
+			{Output: "lts_logs_bucket", Requirement: "storage.logs.url", Format: "s3://%s"},
+			{Output: "lts_reports_bucket", Requirement: "storage.reports.url", Format: "s3://%s"},
+			{Output: "lts_repository_bucket", Requirement: "storage.repository.url", Format: "s3://%s"},
+			{Output: "backup_bucket", Requirement: "storage.backup.url", Format: "s3://%s"},
+			{Output: "vault_bucket_name", Requirement: "vault.bucket"},
+			{Output: "vault_keyring", Requirement: "vault.keyring"},
+			{Output: "vault_key", Requirement: "vault.key"},
+			{Output: "vault_unseal_bucket", Requirement: "vault.aws.s3Bucket"},
+			{Output: "vault_dynamodb_table", Requirement: "vault.aws.dynamoDBTable"},
+			{Output: "vault_kms_unseal", Requirement: "vault.aws.kmsKeyId"},
+			{Output: "subdomain", Requirement: "ingress.domain"},
+			{Output: "domain", Requirement: "ingress.domain"},
+			{Output: "cert_manager_iam_role", Requirement: "cluster.eks.iamRoles.cert-manager"},
+			{Output: "external_dns_iam_role", Requirement: "cluster.eks.iamRoles.exdns-external-dns"},
+			{Output: "tekton_bot_iam_role", Requirement: "cluster.eks.iamRoles.tekton-bot"},
+			{Output: "cm_cainjector_iam_role", Requirement: "cluster.eks.iamRoles.cm-cainjector"},
+			{Output: "controllerbuild_iam_role", Requirement: "cluster.eks.iamRoles.jenkins-x-controllerbuild"},
+		},
+	}
+}
+
+// LoadTerraformOutputsConfig loads the mappings from the `.jx/terraform-outputs.yaml` file in the given boot
+// configuration directory. Returns nil if there is no file
+func LoadTerraformOutputsConfig(dir string) (*TerraformOutputsConfig, error) {
+	fileName := filepath.Join(dir, ".jx", TerraformOutputsConfigFileName)
+	exists, err := util.FileExists(fileName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to check if file exists %s", fileName)
+	}
+	if !exists {
+		return nil, nil
+	}
+	return LoadTerraformOutputsConfigFile(fileName)
+}
+
+// LoadTerraformOutputsConfigFile loads the mappings from the given file
+func LoadTerraformOutputsConfigFile(fileName string) (*TerraformOutputsConfig, error) {
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to load file %s", fileName)
+	}
+	config := &TerraformOutputsConfig{}
+	err = yaml.Unmarshal(data, config)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal YAML file %s", fileName)
+	}
+	err = config.Validate()
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid terraform outputs mappings in file %s", fileName)
+	}
+	return config, nil
+}
+
+// Validate validates the mappings
+func (c *TerraformOutputsConfig) Validate() error {
+	for i, m := range c.Mappings {
+		if m.Output == "" {
+			return fmt.Errorf("mapping %d has no output", i)
+		}
+		if m.Requirement == "" {
+			return fmt.Errorf("mapping of output %s has no requirement", m.Output)
+		}
+		if m.Format != "" && strings.Count(m.Format, "%s") != 1 {
+			return fmt.Errorf("the format %q of the mapping of output %s should contain one %%s", m.Format, m.Output)
+		}
+	}
+	return nil
+}
+
+// Apply sets the requirements from the values of the terraform outputs. Returns the paths of the requirements which
+// have been set
+func (c *TerraformOutputsConfig) Apply(requirements *RequirementsConfig, outputs map[string]interface{}) ([]string, error) {
+	m, err := util.ToObjectMap(requirements)
+	if err != nil {
+		return nil, errors.Wrap(err, "converting the requirements to a map")
+	}
+	applied := []string{}
+	for _, mapping := range c.Mappings {
+		value, ok := outputValue(outputs, mapping.Output)
+		if !ok {
+			if mapping.Required {
+				return nil, fmt.Errorf("missing terraform output %s", mapping.Output)
+			}
+			continue
+		}
+		if mapping.Format != "" {
+			value = fmt.Sprintf(mapping.Format, fmt.Sprint(value))
+		}
+		util.SetMapValueViaPath(m, mapping.Requirement, value)
+		applied = append(applied, mapping.Requirement)
+	}
+	if len(applied) == 0 {
+		return applied, nil
+	}
+
+	data, err := yaml.Marshal(m)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshalling the requirements")
+	}
+	updated := &RequirementsConfig{}
+	err = yaml.Unmarshal(data, updated)
+	if err != nil {
+		return nil, errors.Wrap(err, "the terraform outputs are not valid requirements")
+	}
+	for _, storage := range []*StorageEntryConfig{&updated.Storage.Logs, &updated.Storage.Reports, &updated.Storage.Repository, &updated.Storage.Backup} {
+		if storage.URL != "" {
+			storage.Enabled = true
+		}
+	}
+	*requirements = *updated
+	return applied, nil
+}
+
+// outputValue returns the value of the output of the given path. Only non empty scalar values are returned
+func outputValue(outputs map[string]interface{}, path string) (interface{}, bool) {
+	parts := strings.Split(path, ".")
+	value, ok := outputs[parts[0]]
+	for _, part := range parts[1:] {
+		if !ok {
+			break
+		}
+		var m map[string]interface{}
+		m, ok = value.(map[string]interface{})
+		if ok {
+			value, ok = m[part]
+		}
+	}
+	if !ok || value == nil {
+		return nil, false
+	}
+	switch v := value.(type) {
+	case string:
+		return v, v != ""
+	case bool, float64:
+		return v, true
+	default:
+		return nil, false
+	}
+}
@@ -0,0 +1,63 @@
+package config_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x/jx/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTerraformOutputsConfigApply(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := config.LoadTerraformOutputsConfig(filepath.Join("test_data", "terraform_outputs"))
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+
+	requirements := config.NewRequirementsConfig()
+	requirements.Cluster.Provider = "eks"
+	outputs := map[string]interface{}{
+		"cluster_name": "mycluster",
+		"buckets": map[string]interface{}{
+			"logs": "s3://logs-mycluster",
+		},
+		"vpc":         "vpc-1234",
+		"tekton_role": "arn:aws:iam::123456789012:role/tekton-bot",
+		"dns_zone":    "",
+	}
+	applied, err := cfg.Apply(requirements, outputs)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"cluster.clusterName", "storage.logs.url", "cluster.eks.vpcID", "cluster.eks.iamRoles.tekton-bot"}, applied)
+
+	assert.Equal(t, "eks", requirements.Cluster.Provider)
+	assert.Equal(t, "mycluster", requirements.Cluster.ClusterName)
+	assert.Equal(t, config.StorageEntryConfig{Enabled: true, URL: "s3://logs-mycluster"}, requirements.Storage.Logs)
+	require.NotNil(t, requirements.Cluster.EKSConfig)
+	assert.Equal(t, "vpc-1234", requirements.Cluster.EKSConfig.VPCID)
+	assert.Equal(t, map[string]string{"tekton-bot": "arn:aws:iam::123456789012:role/tekton-bot"}, requirements.Cluster.EKSConfig.IAMRoles)
+	assert.Equal(t, "", requirements.Ingress.Domain, "empty outputs should be ignored")
+
+	_, err = cfg.Apply(requirements, map[string]interface{}{})
+	assert.Error(t, err, "the cluster_name output is required")
+}
+
+func TestDefaultTerraformOutputsConfig(t *testing.T) {
+	t.Parallel()
+
+	cfg := config.DefaultTerraformOutputsConfig()
+	require.NoError(t, cfg.Validate())
+
+	requirements := config.NewRequirementsConfig()
+	_, err := cfg.Apply(requirements, map[string]interface{}{
+		"cluster_name":    "mycluster",
+		"region":          "us-east-1",
+		"lts_logs_bucket": "logs-mycluster",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "mycluster", requirements.Cluster.ClusterName)
+	assert.Equal(t, "us-east-1", requirements.Cluster.Region)
+	assert.Equal(t, "s3://logs-mycluster", requirements.Storage.Logs.URL)
+	assert.True(t, requirements.Storage.Logs.Enabled)
+}
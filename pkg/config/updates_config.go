@@ -0,0 +1,130 @@
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"time"
+
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// UpdatesConfigFileName is the name of the dependency updates configuration file inside the .jx directory of a project
+	UpdatesConfigFileName = "updates.yaml"
+
+	// UpdatesScheduleHourly checks for dependency updates every hour
+	UpdatesScheduleHourly = "hourly"
+	// UpdatesScheduleDaily checks for dependency updates every day
+	UpdatesScheduleDaily = "daily"
+	// UpdatesScheduleWeekly checks for dependency updates every week
+	UpdatesScheduleWeekly = "weekly"
+)
+
+// UpdatesConfig configures how the dependency update controller raises Pull Requests for a project. It is stored
+// in the `.jx/updates.yaml` file in projects
+type UpdatesConfig struct {
+	// Disabled disables dependency update Pull Requests for the project
+	Disabled bool `json:"disabled,omitempty"`
+	// Schedule is how often to check for dependency updates. Either hourly, daily, weekly or a duration like 12h
+	Schedule string `json:"schedule,omitempty"`
+	// Kinds the kinds of dependencies to update such as charts or docker. Defaults to all kinds
+	Kinds []string `json:"kinds,omitempty"`
+	// Ignore the dependency names to ignore which may end with a '*' wildcard
+	Ignore []string `json:"ignore,omitempty"`
+	// Groups updates to the matching dependencies into a single Pull Request per group
+	Groups []UpdateGroup `json:"groups,omitempty"`
+}
+
+// UpdateGroup a group of dependencies which are updated together in a single Pull Request
+type UpdateGroup struct {
+	// Name the name of the group
+	Name string `json:"name"`
+	// Patterns the dependency names in the group which may end with a '*' wildcard
+	Patterns []string `json:"patterns,omitempty"`
+}
+
+// LoadUpdatesConfig loads the dependency updates configuration from the `.jx/updates.yaml` file in the given project
+// directory. Returns nil if the project has no configuration file
+func LoadUpdatesConfig(projectDir string) (*UpdatesConfig, error) {
+	fileName := filepath.Join(projectDir, ".jx", UpdatesConfigFileName)
+	exists, err := util.FileExists(fileName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to check if file exists %s", fileName)
+	}
+	if !exists {
+		return nil, nil
+	}
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to load file %s", fileName)
+	}
+	config := &UpdatesConfig{}
+	err = yaml.Unmarshal(data, config)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal YAML file %s", fileName)
+	}
+	_, err = config.ScheduleDuration()
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid configuration in file %s", fileName)
+	}
+	return config, nil
+}
+
+// ScheduleDuration returns the period between dependency update checks, defaulting to daily
+func (c *UpdatesConfig) ScheduleDuration() (time.Duration, error) {
+	switch c.Schedule {
+	case "", UpdatesScheduleDaily:
+		return 24 * time.Hour, nil
+	case UpdatesScheduleHourly:
+		return time.Hour, nil
+	case UpdatesScheduleWeekly:
+		return 7 * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(c.Schedule)
+	if err != nil {
+		return 0, fmt.Errorf("schedule %s should be one of %s, %s, %s or a duration", c.Schedule, UpdatesScheduleHourly, UpdatesScheduleDaily, UpdatesScheduleWeekly)
+	}
+	return d, nil
+}
+
+// IsDue returns true if dependency updates should be checked given the time of the last check
+func (c *UpdatesConfig) IsDue(lastCheck time.Time, now time.Time) bool {
+	if lastCheck.IsZero() {
+		return true
+	}
+	d, err := c.ScheduleDuration()
+	if err != nil {
+		return false
+	}
+	return !now.Before(lastCheck.Add(d))
+}
+
+// IncludesKind returns true if dependencies of the given kind should be updated
+func (c *UpdatesConfig) IncludesKind(kind string) bool {
+	return len(c.Kinds) == 0 || util.StringArrayIndex(c.Kinds, kind) >= 0
+}
+
+// IsIgnored returns true if the dependency with the given name should not be updated
+func (c *UpdatesConfig) IsIgnored(name string) bool {
+	for _, pattern := range c.Ignore {
+		if util.StringMatchesPattern(name, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// GroupFor returns the name of the group the dependency belongs to or an empty string if its not in a group
+func (c *UpdatesConfig) GroupFor(name string) string {
+	for _, group := range c.Groups {
+		for _, pattern := range group.Patterns {
+			if util.StringMatchesPattern(name, pattern) {
+				return group.Name
+			}
+		}
+	}
+	return ""
+}
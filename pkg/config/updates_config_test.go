@@ -0,0 +1,53 @@
+package config_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jenkins-x/jx/pkg/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdatesConfigSchedule(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	cfg := &config.UpdatesConfig{}
+	d, err := cfg.ScheduleDuration()
+	assert.NoError(t, err)
+	assert.Equal(t, 24*time.Hour, d)
+	assert.True(t, cfg.IsDue(time.Time{}, now))
+	assert.False(t, cfg.IsDue(now.Add(-time.Hour), now))
+	assert.True(t, cfg.IsDue(now.Add(-25*time.Hour), now))
+
+	cfg.Schedule = config.UpdatesScheduleWeekly
+	assert.False(t, cfg.IsDue(now.Add(-25*time.Hour), now))
+
+	cfg.Schedule = "30m"
+	assert.True(t, cfg.IsDue(now.Add(-time.Hour), now))
+
+	cfg.Schedule = "fortnightly"
+	_, err = cfg.ScheduleDuration()
+	assert.Error(t, err)
+}
+
+func TestUpdatesConfigFilters(t *testing.T) {
+	t.Parallel()
+
+	cfg := &config.UpdatesConfig{
+		Kinds:  []string{"charts"},
+		Ignore: []string{"stable/*"},
+		Groups: []config.UpdateGroup{
+			{
+				Name:     "jenkins-x",
+				Patterns: []string{"jenkins-x/*"},
+			},
+		},
+	}
+	assert.True(t, cfg.IncludesKind("charts"))
+	assert.False(t, cfg.IncludesKind("docker"))
+	assert.True(t, cfg.IsIgnored("stable/postgresql"))
+	assert.False(t, cfg.IsIgnored("jenkins-x/exposecontroller"))
+	assert.Equal(t, "jenkins-x", cfg.GroupFor("jenkins-x/exposecontroller"))
+	assert.Equal(t, "", cfg.GroupFor("bitnami/redis"))
+}
@@ -265,6 +265,24 @@ func (in *ClusterConfig) DeepCopyInto(out *ClusterConfig) {
 			**out = **in
 		}
 	}
+	if in.EKSConfig != nil {
+		in, out := &in.EKSConfig, &out.EKSConfig
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(EKSConfig)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.LocalConfig != nil {
+		in, out := &in.LocalConfig, &out.LocalConfig
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(LocalClusterConfig)
+			**out = **in
+		}
+	}
 	return
 }
 
@@ -278,6 +296,29 @@ func (in *ClusterConfig) DeepCopy() *ClusterConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EKSConfig) DeepCopyInto(out *EKSConfig) {
+	*out = *in
+	if in.IAMRoles != nil {
+		in, out := &in.IAMRoles, &out.IAMRoles
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EKSConfig.
+func (in *EKSConfig) DeepCopy() *EKSConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(EKSConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EnabledConfig) DeepCopyInto(out *EnabledConfig) {
 	*out = *in
@@ -726,6 +767,22 @@ func (in *KanikoSecret) DeepCopy() *KanikoSecret {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LocalClusterConfig) DeepCopyInto(out *LocalClusterConfig) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalClusterConfig.
+func (in *LocalClusterConfig) DeepCopy() *LocalClusterConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(LocalClusterConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Nexus) DeepCopyInto(out *Nexus) {
 	*out = *in
@@ -1018,6 +1075,60 @@ func (in *TLSConfig) DeepCopy() *TLSConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UpdateGroup) DeepCopyInto(out *UpdateGroup) {
+	*out = *in
+	if in.Patterns != nil {
+		in, out := &in.Patterns, &out.Patterns
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpdateGroup.
+func (in *UpdateGroup) DeepCopy() *UpdateGroup {
+	if in == nil {
+		return nil
+	}
+	out := new(UpdateGroup)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UpdatesConfig) DeepCopyInto(out *UpdatesConfig) {
+	*out = *in
+	if in.Kinds != nil {
+		in, out := &in.Kinds, &out.Kinds
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Ignore != nil {
+		in, out := &in.Ignore, &out.Ignore
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Groups != nil {
+		in, out := &in.Groups, &out.Groups
+		*out = make([]UpdateGroup, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpdatesConfig.
+func (in *UpdatesConfig) DeepCopy() *UpdatesConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(UpdatesConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VaultAWSConfig) DeepCopyInto(out *VaultAWSConfig) {
 	*out = *in
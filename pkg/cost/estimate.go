@@ -0,0 +1,93 @@
+package cost
+
+import (
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// NamespaceCost the estimated cost of the pods running in a namespace
+type NamespaceCost struct {
+	Namespace string `json:"namespace"`
+	// Environment the name of the environment or preview of the namespace if any
+	Environment string `json:"environment,omitempty"`
+	// Kind the kind of the environment such as permanent, preview or development
+	Kind string `json:"kind,omitempty"`
+	Pods int    `json:"pods"`
+	// CPU the CPU cores requested by the pods
+	CPU float64 `json:"cpu"`
+	// MemoryGiB the GiB of memory requested by the pods
+	MemoryGiB float64 `json:"memoryGiB"`
+	// HourlyCost the estimated cost per hour of the share of the nodes used by the pods
+	HourlyCost float64 `json:"hourlyCost"`
+	// MonthlyCost the estimated cost per month if the pods keep running
+	MonthlyCost float64 `json:"monthlyCost"`
+}
+
+// Estimate estimates the cost of each namespace by charging each running pod the larger of its share of the CPU or
+// memory of its node multiplied by the hourly price of the node
+func Estimate(nodes []corev1.Node, pods []corev1.Pod, pricing Pricing) []*NamespaceCost {
+	nodeMap := map[string]*corev1.Node{}
+	for i := range nodes {
+		nodeMap[nodes[i].Name] = &nodes[i]
+	}
+	costs := map[string]*NamespaceCost{}
+	for i := range pods {
+		pod := &pods[i]
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		node := nodeMap[pod.Spec.NodeName]
+		if node == nil {
+			continue
+		}
+		nc := costs[pod.Namespace]
+		if nc == nil {
+			nc = &NamespaceCost{Namespace: pod.Namespace}
+			costs[pod.Namespace] = nc
+		}
+		cpu, memoryGiB := podRequests(pod)
+		nc.Pods++
+		nc.CPU += cpu
+		nc.MemoryGiB += memoryGiB
+
+		nodeCPU, nodeMemoryGiB := nodeCapacity(node)
+		share := 0.0
+		if nodeCPU > 0 {
+			share = cpu / nodeCPU
+		}
+		if nodeMemoryGiB > 0 && memoryGiB/nodeMemoryGiB > share {
+			share = memoryGiB / nodeMemoryGiB
+		}
+		nc.HourlyCost += share * pricing.NodeHourlyPrice(node)
+	}
+
+	answer := []*NamespaceCost{}
+	for _, nc := range costs {
+		nc.MonthlyCost = nc.HourlyCost * HoursPerMonth
+		answer = append(answer, nc)
+	}
+	SortByCost(answer)
+	return answer
+}
+
+// SortByCost sorts the costs with the most expensive first
+func SortByCost(costs []*NamespaceCost) {
+	sort.Slice(costs, func(i, j int) bool {
+		if costs[i].HourlyCost == costs[j].HourlyCost {
+			return costs[i].Namespace < costs[j].Namespace
+		}
+		return costs[i].HourlyCost > costs[j].HourlyCost
+	})
+}
+
+// podRequests returns the CPU cores and GiB of memory requested by the containers of the pod
+func podRequests(pod *corev1.Pod) (float64, float64) {
+	cpu := int64(0)
+	memory := int64(0)
+	for _, c := range pod.Spec.Containers {
+		cpu += c.Resources.Requests.Cpu().MilliValue()
+		memory += c.Resources.Requests.Memory().Value()
+	}
+	return float64(cpu) / 1000, float64(memory) / (1 << 30)
+}
@@ -0,0 +1,101 @@
+package cost_test
+
+import (
+	"testing"
+
+	"github.com/jenkins-x/jx/pkg/cloud"
+	"github.com/jenkins-x/jx/pkg/cost"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestEstimate(t *testing.T) {
+	t.Parallel()
+
+	nodes := []corev1.Node{
+		newNode("gke-node", "gce://my-project/us-central1-a/gke-node", "n1-standard-4", "4", "16Gi", nil),
+		newNode("spot-node", "aws:///us-east-1a/i-0123", "m5.xlarge", "4", "16Gi", map[string]string{"eks.amazonaws.com/capacityType": "SPOT"}),
+	}
+	pods := []corev1.Pod{
+		newPod("jx-staging", "gke-node", "2", "1Gi", corev1.PodRunning),
+		newPod("jx-preview-pr-1", "gke-node", "500m", "8Gi", corev1.PodRunning),
+		newPod("jx-preview-pr-1", "spot-node", "1", "1Gi", corev1.PodRunning),
+		newPod("jx-preview-pr-1", "gke-node", "4", "16Gi", corev1.PodSucceeded),
+		newPod("jx-pending", "", "4", "16Gi", corev1.PodPending),
+	}
+
+	pricing := &cost.ProviderPricing{Tables: cost.DefaultPriceTables}
+	costs := cost.Estimate(nodes, pods, pricing)
+	require.Len(t, costs, 2)
+
+	preview := costs[0]
+	assert.Equal(t, "jx-preview-pr-1", preview.Namespace)
+	assert.Equal(t, 2, preview.Pods)
+	assert.InDelta(t, 1.5, preview.CPU, 0.001)
+	assert.InDelta(t, 9, preview.MemoryGiB, 0.001)
+	// half the memory of the GKE node plus a quarter of the CPU of the spot node
+	assert.InDelta(t, 0.19*0.5+0.192*0.3*0.25, preview.HourlyCost, 0.0001)
+	assert.InDelta(t, preview.HourlyCost*cost.HoursPerMonth, preview.MonthlyCost, 0.0001)
+
+	staging := costs[1]
+	assert.Equal(t, "jx-staging", staging.Namespace)
+	assert.InDelta(t, 0.19*0.5, staging.HourlyCost, 0.0001)
+}
+
+func TestPriceTableUnknownInstanceType(t *testing.T) {
+	t.Parallel()
+
+	table := cost.DefaultPriceTables[cloud.AKS]
+	node := newNode("aks-node", "azure:///subscriptions/123/vm-0", "Standard_Custom", "2", "8Gi", nil)
+	assert.InDelta(t, 2*table.CPUHourly+8*table.MemoryGiBHourly, table.NodeHourlyPrice(&node), 0.0001)
+	assert.Equal(t, cloud.AKS, cost.NodeProvider(&node))
+}
+
+func newNode(name string, providerID string, instanceType string, cpu string, memory string, labels map[string]string) corev1.Node {
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	labels["node.kubernetes.io/instance-type"] = instanceType
+	return corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: labels,
+		},
+		Spec: corev1.NodeSpec{
+			ProviderID: providerID,
+		},
+		Status: corev1.NodeStatus{
+			Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse(cpu),
+				corev1.ResourceMemory: resource.MustParse(memory),
+			},
+		},
+	}
+}
+
+func newPod(ns string, nodeName string, cpu string, memory string, phase corev1.PodPhase) corev1.Pod {
+	return corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: ns,
+		},
+		Spec: corev1.PodSpec{
+			NodeName: nodeName,
+			Containers: []corev1.Container{
+				{
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse(cpu),
+							corev1.ResourceMemory: resource.MustParse(memory),
+						},
+					},
+				},
+			},
+		},
+		Status: corev1.PodStatus{
+			Phase: phase,
+		},
+	}
+}
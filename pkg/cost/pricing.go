@@ -0,0 +1,193 @@
+package cost
+
+import (
+	"io/ioutil"
+	"strings"
+
+	"github.com/jenkins-x/jx/pkg/cloud"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// HoursPerMonth the average number of hours in a month used to estimate monthly costs
+const HoursPerMonth = 730
+
+var instanceTypeLabels = []string{"node.kubernetes.io/instance-type", "beta.kubernetes.io/instance-type"}
+
+// spotLabels the node labels and values which indicate spot or preemptible nodes
+var spotLabels = map[string]string{
+	"cloud.google.com/gke-preemptible":      "true",
+	"cloud.google.com/gke-spot":             "true",
+	"eks.amazonaws.com/capacityType":        "SPOT",
+	"kubernetes.azure.com/scalesetpriority": "spot",
+	"lifecycle":                             "Ec2Spot",
+}
+
+// Pricing returns the price of the nodes of a cluster
+type Pricing interface {
+	// NodeHourlyPrice returns the hourly price of the node
+	NodeHourlyPrice(node *corev1.Node) float64
+}
+
+// PriceTable prices nodes by their instance type falling back to the price of their CPU and memory
+type PriceTable struct {
+	// InstanceTypes the hourly on demand price of each instance type
+	InstanceTypes map[string]float64 `json:"instanceTypes,omitempty"`
+	// CPUHourly the hourly price of a CPU core used for instance types which are not in the table
+	CPUHourly float64 `json:"cpuHourly,omitempty"`
+	// MemoryGiBHourly the hourly price of a GiB of memory used for instance types which are not in the table
+	MemoryGiBHourly float64 `json:"memoryGiBHourly,omitempty"`
+	// SpotFactor the fraction of the on demand price charged for spot or preemptible nodes
+	SpotFactor float64 `json:"spotFactor,omitempty"`
+}
+
+// DefaultPriceTables the approximate on demand list prices in USD of the common node types of each provider
+var DefaultPriceTables = map[string]*PriceTable{
+	cloud.GKE: {
+		InstanceTypes: map[string]float64{
+			"e2-medium":      0.0335,
+			"e2-standard-2":  0.067,
+			"e2-standard-4":  0.134,
+			"e2-standard-8":  0.268,
+			"n1-standard-1":  0.0475,
+			"n1-standard-2":  0.095,
+			"n1-standard-4":  0.19,
+			"n1-standard-8":  0.38,
+			"n1-highmem-2":   0.1184,
+			"n1-highmem-4":   0.2368,
+			"n2-standard-2":  0.0971,
+			"n2-standard-4":  0.1942,
+			"n1-highcpu-4":   0.1418,
+			"n1-standard-16": 0.76,
+		},
+		CPUHourly:       0.0316,
+		MemoryGiBHourly: 0.0042,
+		SpotFactor:      0.3,
+	},
+	cloud.EKS: {
+		InstanceTypes: map[string]float64{
+			"t3.medium":  0.0416,
+			"t3.large":   0.0832,
+			"t3.xlarge":  0.1664,
+			"m5.large":   0.096,
+			"m5.xlarge":  0.192,
+			"m5.2xlarge": 0.384,
+			"m5.4xlarge": 0.768,
+			"c5.large":   0.085,
+			"c5.xlarge":  0.17,
+			"r5.large":   0.126,
+			"r5.xlarge":  0.252,
+		},
+		CPUHourly:       0.04,
+		MemoryGiBHourly: 0.005,
+		SpotFactor:      0.3,
+	},
+	cloud.AKS: {
+		InstanceTypes: map[string]float64{
+			"Standard_B2s":    0.0416,
+			"Standard_DS2_v2": 0.146,
+			"Standard_D2s_v3": 0.096,
+			"Standard_D4s_v3": 0.192,
+			"Standard_D8s_v3": 0.384,
+			"Standard_E2s_v3": 0.126,
+			"Standard_E4s_v3": 0.252,
+		},
+		CPUHourly:       0.048,
+		MemoryGiBHourly: 0.006,
+		SpotFactor:      0.2,
+	},
+}
+
+// LoadPriceTable loads a price table from a YAML file
+func LoadPriceTable(fileName string) (*PriceTable, error) {
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to load file %s", fileName)
+	}
+	table := &PriceTable{}
+	err = yaml.Unmarshal(data, table)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal YAML file %s", fileName)
+	}
+	return table, nil
+}
+
+// NodeHourlyPrice returns the hourly price of the node
+func (t *PriceTable) NodeHourlyPrice(node *corev1.Node) float64 {
+	price, ok := t.InstanceTypes[InstanceType(node)]
+	if !ok {
+		cpu, memoryGiB := nodeCapacity(node)
+		price = cpu*t.CPUHourly + memoryGiB*t.MemoryGiBHourly
+	}
+	if IsSpot(node) && t.SpotFactor > 0 {
+		price *= t.SpotFactor
+	}
+	return price
+}
+
+// ProviderPricing prices each node using the price table of its provider, using the default provider if the
+// provider of a node cannot be detected from its provider ID
+type ProviderPricing struct {
+	Tables          map[string]*PriceTable
+	DefaultProvider string
+}
+
+// NodeHourlyPrice returns the hourly price of the node
+func (p *ProviderPricing) NodeHourlyPrice(node *corev1.Node) float64 {
+	provider := NodeProvider(node)
+	if provider == "" {
+		provider = p.DefaultProvider
+	}
+	table := p.Tables[provider]
+	if table == nil {
+		return 0
+	}
+	return table.NodeHourlyPrice(node)
+}
+
+// InstanceType returns the instance type of the node from its labels
+func InstanceType(node *corev1.Node) string {
+	for _, label := range instanceTypeLabels {
+		if value := node.Labels[label]; value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// IsSpot returns true if the node is a spot or preemptible instance
+func IsSpot(node *corev1.Node) bool {
+	for label, value := range spotLabels {
+		if strings.EqualFold(node.Labels[label], value) {
+			return true
+		}
+	}
+	return false
+}
+
+// NodeProvider returns the kubernetes provider of the node from its provider ID or an empty string if unknown
+func NodeProvider(node *corev1.Node) string {
+	providerID := node.Spec.ProviderID
+	switch {
+	case strings.HasPrefix(providerID, "gce://"):
+		return cloud.GKE
+	case strings.HasPrefix(providerID, "aws://"):
+		return cloud.EKS
+	case strings.HasPrefix(providerID, "azure://"):
+		return cloud.AKS
+	default:
+		return ""
+	}
+}
+
+// nodeCapacity returns the allocatable CPU cores and GiB of memory of the node
+func nodeCapacity(node *corev1.Node) (float64, float64) {
+	resources := node.Status.Allocatable
+	if len(resources) == 0 {
+		resources = node.Status.Capacity
+	}
+	cpu := resources.Cpu().MilliValue()
+	memory := resources.Memory().Value()
+	return float64(cpu) / 1000, float64(memory) / (1 << 30)
+}
@@ -0,0 +1,158 @@
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// descriptors the supported predefined schedules
+var descriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+type field struct {
+	name string
+	min  int
+	max  int
+}
+
+var fields = []field{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12},
+	// Sunday is either 0 or 7
+	{name: "day of week", min: 0, max: 7},
+}
+
+// Schedule a parsed cron expression
+type Schedule struct {
+	// Expression the expression the schedule was parsed from
+	Expression string
+
+	minutes     uint64
+	hours       uint64
+	daysOfMonth uint64
+	months      uint64
+	daysOfWeek  uint64
+	// anyDay is true if either the day of month or the day of week is '*' in which case both must match
+	anyDay bool
+}
+
+// Parse parses a standard 5 field cron expression 'minute hour day-of-month month day-of-week' supporting '*', lists,
+// ranges and steps such as '*/15 9-17 * * 1-5' or one of the descriptors such as '@daily'
+func Parse(expression string) (*Schedule, error) {
+	text := strings.TrimSpace(expression)
+	if d, ok := descriptors[text]; ok {
+		text = d
+	}
+	parts := strings.Fields(text)
+	if len(parts) != len(fields) {
+		return nil, fmt.Errorf("invalid cron expression '%s' which should have %d fields", expression, len(fields))
+	}
+	bits := make([]uint64, len(fields))
+	for i, f := range fields {
+		b, err := parseField(parts[i], f)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cron expression '%s': %s", expression, err)
+		}
+		bits[i] = b
+	}
+	if bits[4]&(1<<7) != 0 {
+		bits[4] = (bits[4] | 1) &^ (1 << 7)
+	}
+	return &Schedule{
+		Expression:  expression,
+		minutes:     bits[0],
+		hours:       bits[1],
+		daysOfMonth: bits[2],
+		months:      bits[3],
+		daysOfWeek:  bits[4],
+		anyDay:      strings.HasPrefix(parts[2], "*") || strings.HasPrefix(parts[4], "*"),
+	}, nil
+}
+
+func parseField(text string, f field) (uint64, error) {
+	var answer uint64
+	for _, item := range strings.Split(text, ",") {
+		rangeText := item
+		step := 1
+		if i := strings.Index(item, "/"); i >= 0 {
+			rangeText = item[:i]
+			s, err := strconv.Atoi(item[i+1:])
+			if err != nil || s <= 0 {
+				return 0, fmt.Errorf("invalid step '%s' of the %s", item[i+1:], f.name)
+			}
+			step = s
+		}
+		low, high := f.min, f.max
+		if rangeText != "*" {
+			bounds := strings.SplitN(rangeText, "-", 2)
+			var err error
+			low, err = strconv.Atoi(bounds[0])
+			if err != nil {
+				return 0, fmt.Errorf("invalid %s '%s'", f.name, bounds[0])
+			}
+			high = low
+			if len(bounds) == 2 {
+				high, err = strconv.Atoi(bounds[1])
+				if err != nil {
+					return 0, fmt.Errorf("invalid %s '%s'", f.name, bounds[1])
+				}
+			} else if step > 1 {
+				high = f.max
+			}
+			if low < f.min || high > f.max || low > high {
+				return 0, fmt.Errorf("the %s '%s' is outside of the range %d-%d", f.name, rangeText, f.min, f.max)
+			}
+		}
+		for v := low; v <= high; v += step {
+			answer |= 1 << uint(v)
+		}
+	}
+	return answer, nil
+}
+
+// Next returns the first time after the given time which matches the schedule in the location of the time. Returns the
+// zero time if there is no such time within 5 years such as for '0 0 30 2 *'
+func (s *Schedule) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		if s.months&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location()).AddDate(0, 1, 0)
+			continue
+		}
+		if !s.matchesDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location()).AddDate(0, 0, 1)
+			continue
+		}
+		if s.hours&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, t.Location()).Add(time.Hour)
+			continue
+		}
+		if s.minutes&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+func (s *Schedule) matchesDay(t time.Time) bool {
+	dom := s.daysOfMonth&(1<<uint(t.Day())) != 0
+	dow := s.daysOfWeek&(1<<uint(t.Weekday())) != 0
+	if s.anyDay {
+		return dom && dow
+	}
+	return dom || dow
+}
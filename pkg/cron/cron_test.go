@@ -0,0 +1,49 @@
+package cron_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jenkins-x/jx/pkg/cron"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduleNext(t *testing.T) {
+	t.Parallel()
+	// a Wednesday
+	now := time.Date(2019, 11, 6, 10, 17, 30, 0, time.UTC)
+
+	testCases := []struct {
+		expression string
+		expected   time.Time
+	}{
+		{"0 4 * * *", time.Date(2019, 11, 7, 4, 0, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2019, 11, 6, 10, 30, 0, 0, time.UTC)},
+		{"30 9-17 * * 1-5", time.Date(2019, 11, 6, 10, 30, 0, 0, time.UTC)},
+		{"0 0 * * 0", time.Date(2019, 11, 10, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2019, 11, 10, 0, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2019, 11, 7, 0, 0, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2019, 12, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 12 1,15 * *", time.Date(2019, 11, 15, 12, 0, 0, 0, time.UTC)},
+		{"0 12 13 * 5", time.Date(2019, 11, 8, 12, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2020, 2, 29, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tc := range testCases {
+		s, err := cron.Parse(tc.expression)
+		require.NoError(t, err, "expression %s", tc.expression)
+		assert.Equal(t, tc.expected, s.Next(now), "expression %s", tc.expression)
+	}
+
+	s, err := cron.Parse("0 0 30 2 *")
+	require.NoError(t, err)
+	assert.True(t, s.Next(now).IsZero())
+}
+
+func TestParseInvalid(t *testing.T) {
+	t.Parallel()
+	for _, expression := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "* * * 13 *", "*/0 * * * *", "5-1 * * * *", "a * * * *", "@often"} {
+		_, err := cron.Parse(expression)
+		assert.Error(t, err, "expression %s", expression)
+	}
+}
@@ -0,0 +1,368 @@
+package cve
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/kube"
+	"github.com/jenkins-x/jx/pkg/kube/naming"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// ImageScansConfigMap the name of the ConfigMap storing the vulnerability scan results of the images
+	ImageScansConfigMap = "jx-image-scans"
+
+	// ScannerTrivy the Trivy image scanner
+	ScannerTrivy = "trivy"
+	// ScannerGrype the Grype image scanner
+	ScannerGrype = "grype"
+
+	// SeverityUnknown the severity of vulnerabilities which have not been rated
+	SeverityUnknown = "UNKNOWN"
+	// SeverityLow the low severity
+	SeverityLow = "LOW"
+	// SeverityMedium the medium severity
+	SeverityMedium = "MEDIUM"
+	// SeverityHigh the high severity
+	SeverityHigh = "HIGH"
+	// SeverityCritical the critical severity
+	SeverityCritical = "CRITICAL"
+)
+
+var (
+	// Scanners the supported image scanners
+	Scanners = []string{ScannerTrivy, ScannerGrype}
+
+	// Severities the severities of vulnerabilities from the lowest to the highest
+	Severities = []string{SeverityUnknown, SeverityLow, SeverityMedium, SeverityHigh, SeverityCritical}
+)
+
+// ImageVulnerability a vulnerability of a package of an image
+type ImageVulnerability struct {
+	ID               string `json:"id"`
+	Package          string `json:"package,omitempty"`
+	InstalledVersion string `json:"installedVersion,omitempty"`
+	FixedVersion     string `json:"fixedVersion,omitempty"`
+	Severity         string `json:"severity"`
+	Title            string `json:"title,omitempty"`
+}
+
+// ImageScanReport the vulnerabilities found by scanning an image
+type ImageScanReport struct {
+	Image           string               `json:"image"`
+	Scanner         string               `json:"scanner"`
+	Timestamp       time.Time            `json:"timestamp"`
+	Vulnerabilities []ImageVulnerability `json:"vulnerabilities,omitempty"`
+}
+
+// ImageScanSummary the result of the scan of an image stored for the promotion checks
+type ImageScanSummary struct {
+	Image     string         `json:"image"`
+	Scanner   string         `json:"scanner"`
+	Timestamp time.Time      `json:"timestamp"`
+	Counts    map[string]int `json:"counts,omitempty"`
+	// Critical the critical vulnerabilities of the image
+	Critical []ImageVulnerability `json:"critical,omitempty"`
+}
+
+// trivyReport is the output of `trivy --format json`. Older versions output the results array only
+type trivyReport struct {
+	Results []trivyResult `json:"Results"`
+}
+
+type trivyResult struct {
+	Target          string `json:"Target"`
+	Vulnerabilities []struct {
+		VulnerabilityID  string `json:"VulnerabilityID"`
+		PkgName          string `json:"PkgName"`
+		InstalledVersion string `json:"InstalledVersion"`
+		FixedVersion     string `json:"FixedVersion"`
+		Severity         string `json:"Severity"`
+		Title            string `json:"Title"`
+	} `json:"Vulnerabilities"`
+}
+
+// grypeReport is the output of `grype -o json`
+type grypeReport struct {
+	Matches []struct {
+		Vulnerability struct {
+			ID          string `json:"id"`
+			Severity    string `json:"severity"`
+			Description string `json:"description"`
+			Fix         struct {
+				Versions []string `json:"versions"`
+			} `json:"fix"`
+		} `json:"vulnerability"`
+		Artifact struct {
+			Name    string `json:"name"`
+			Version string `json:"version"`
+		} `json:"artifact"`
+	} `json:"matches"`
+}
+
+// SeverityLevel returns the level of the severity from 0 for UNKNOWN to 4 for CRITICAL or -1 if it is not a severity
+func SeverityLevel(severity string) int {
+	return util.StringArrayIndex(Severities, strings.ToUpper(severity))
+}
+
+// IsSeverityAtLeast returns true if the severity is the same or higher than the threshold
+func IsSeverityAtLeast(severity string, threshold string) bool {
+	level := SeverityLevel(severity)
+	if level < 0 {
+		level = 0
+	}
+	return level >= SeverityLevel(threshold)
+}
+
+// ScanImage scans the image with the scanner returning the vulnerabilities found
+func ScanImage(scanner string, image string) (*ImageScanReport, error) {
+	cmd := util.Command{
+		Name: scanner,
+	}
+	switch scanner {
+	case ScannerTrivy:
+		cmd.Args = []string{"image", "--format", "json", "--quiet", image}
+	case ScannerGrype:
+		cmd.Args = []string{image, "-o", "json", "--quiet"}
+	default:
+		return nil, util.InvalidOption("scanner", scanner, Scanners)
+	}
+	out, err := cmd.RunWithoutRetry()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to scan image %s with %s", image, scanner)
+	}
+	return ParseImageScanReport(scanner, image, []byte(out))
+}
+
+// ParseImageScanReport parses the JSON output of the scanner
+func ParseImageScanReport(scanner string, image string, data []byte) (*ImageScanReport, error) {
+	var vulnerabilities []ImageVulnerability
+	var err error
+	switch scanner {
+	case ScannerTrivy:
+		vulnerabilities, err = ParseTrivyReport(data)
+	case ScannerGrype:
+		vulnerabilities, err = ParseGrypeReport(data)
+	default:
+		return nil, util.InvalidOption("scanner", scanner, Scanners)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &ImageScanReport{
+		Image:           image,
+		Scanner:         scanner,
+		Timestamp:       time.Now(),
+		Vulnerabilities: vulnerabilities,
+	}, nil
+}
+
+// ParseTrivyReport parses the vulnerabilities of the output of `trivy --format json`
+func ParseTrivyReport(data []byte) ([]ImageVulnerability, error) {
+	results := []trivyResult{}
+	if strings.HasPrefix(strings.TrimSpace(string(data)), "[") {
+		err := json.Unmarshal(data, &results)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse the trivy report")
+		}
+	} else {
+		report := trivyReport{}
+		err := json.Unmarshal(data, &report)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse the trivy report")
+		}
+		results = report.Results
+	}
+	answer := []ImageVulnerability{}
+	for _, result := range results {
+		for _, v := range result.Vulnerabilities {
+			answer = append(answer, ImageVulnerability{
+				ID:               v.VulnerabilityID,
+				Package:          v.PkgName,
+				InstalledVersion: v.InstalledVersion,
+				FixedVersion:     v.FixedVersion,
+				Severity:         normalizeSeverity(v.Severity),
+				Title:            v.Title,
+			})
+		}
+	}
+	return answer, nil
+}
+
+// ParseGrypeReport parses the vulnerabilities of the output of `grype -o json`
+func ParseGrypeReport(data []byte) ([]ImageVulnerability, error) {
+	report := grypeReport{}
+	err := json.Unmarshal(data, &report)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse the grype report")
+	}
+	answer := []ImageVulnerability{}
+	for _, m := range report.Matches {
+		answer = append(answer, ImageVulnerability{
+			ID:               m.Vulnerability.ID,
+			Package:          m.Artifact.Name,
+			InstalledVersion: m.Artifact.Version,
+			FixedVersion:     strings.Join(m.Vulnerability.Fix.Versions, ", "),
+			Severity:         normalizeSeverity(m.Vulnerability.Severity),
+			Title:            m.Vulnerability.Description,
+		})
+	}
+	return answer, nil
+}
+
+func normalizeSeverity(severity string) string {
+	severity = strings.ToUpper(severity)
+	if SeverityLevel(severity) < 0 {
+		// such as the Negligible severity of grype
+		return SeverityUnknown
+	}
+	return severity
+}
+
+// ApplyPolicy removes the vulnerabilities ignored by the policy
+func (r *ImageScanReport) ApplyPolicy(policy *v1.ImageScanPolicy) {
+	if policy == nil {
+		return
+	}
+	answer := []ImageVulnerability{}
+	for _, v := range r.Vulnerabilities {
+		if util.StringArrayIndex(policy.IgnoredVulnerabilities, v.ID) >= 0 {
+			continue
+		}
+		if policy.IgnoreUnfixed && v.FixedVersion == "" {
+			continue
+		}
+		answer = append(answer, v)
+	}
+	r.Vulnerabilities = answer
+}
+
+// VulnerabilitiesAtLeast returns the vulnerabilities of the given severity or higher sorted from the highest severity
+func (r *ImageScanReport) VulnerabilitiesAtLeast(severity string) []ImageVulnerability {
+	answer := []ImageVulnerability{}
+	for _, v := range r.Vulnerabilities {
+		if IsSeverityAtLeast(v.Severity, severity) {
+			answer = append(answer, v)
+		}
+	}
+	sort.SliceStable(answer, func(i, j int) bool {
+		return SeverityLevel(answer[i].Severity) > SeverityLevel(answer[j].Severity)
+	})
+	return answer
+}
+
+// Summary returns the summary of the scan stored for the promotion checks
+func (r *ImageScanReport) Summary() *ImageScanSummary {
+	answer := &ImageScanSummary{
+		Image:     r.Image,
+		Scanner:   r.Scanner,
+		Timestamp: r.Timestamp,
+		Counts:    map[string]int{},
+	}
+	for _, v := range r.Vulnerabilities {
+		answer.Counts[v.Severity]++
+		if v.Severity == SeverityCritical {
+			answer.Critical = append(answer.Critical, v)
+		}
+	}
+	return answer
+}
+
+// UnresolvedCritical returns the critical vulnerabilities of the image which have not been ignored by the policy
+func (s *ImageScanSummary) UnresolvedCritical(policy *v1.ImageScanPolicy) []ImageVulnerability {
+	answer := []ImageVulnerability{}
+	for _, v := range s.Critical {
+		if policy != nil && util.StringArrayIndex(policy.IgnoredVulnerabilities, v.ID) >= 0 {
+			continue
+		}
+		answer = append(answer, v)
+	}
+	return answer
+}
+
+// IsProtectedEnvironment returns true if images with unresolved critical vulnerabilities cannot be promoted to the
+// environment
+func IsProtectedEnvironment(policy *v1.ImageScanPolicy, environment string) bool {
+	return policy != nil && util.StringArrayIndex(policy.ProtectedEnvironments, environment) >= 0
+}
+
+// ImageNameAndTag returns the name of the image without the registry and organisation and its tag or digest
+func ImageNameAndTag(image string) (string, string) {
+	name := image
+	tag := ""
+	if i := strings.Index(name, "@"); i >= 0 {
+		tag = name[i+1:]
+		name = name[:i]
+	} else if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		tag = name[i+1:]
+		name = name[:i]
+	}
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	if tag == "" {
+		tag = "latest"
+	}
+	return name, tag
+}
+
+// imageScanKey returns the ConfigMap key of the scan of the version of the application
+func imageScanKey(app string, version string) string {
+	return naming.ToValidNameWithDots(app + "-" + strings.Replace(version, ":", "-", -1))
+}
+
+// SaveImageScanSummary stores the summary of the scan of the image
+func SaveImageScanSummary(kubeClient kubernetes.Interface, ns string, summary *ImageScanSummary) error {
+	data, err := yaml.Marshal(summary)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal the scan of image %s", summary.Image)
+	}
+	name, tag := ImageNameAndTag(summary.Image)
+	callback := func(cm *corev1.ConfigMap) error {
+		if cm.Data == nil {
+			cm.Data = map[string]string{}
+		}
+		cm.Data[imageScanKey(name, tag)] = string(data)
+		return nil
+	}
+	_, err = kube.DefaultModifyConfigMap(kubeClient, ns, ImageScansConfigMap, callback, nil)
+	return err
+}
+
+// GetImageScanSummary returns the summary of the scan of the image of the version of the application or nil if the
+// image has not been scanned
+func GetImageScanSummary(kubeClient kubernetes.Interface, ns string, app string, version string) (*ImageScanSummary, error) {
+	cm, err := kube.GetConfigMap(kubeClient, ns, ImageScansConfigMap)
+	if err != nil {
+		// no image has been scanned yet
+		return nil, nil
+	}
+	data := cm.Data[imageScanKey(app, version)]
+	if data == "" {
+		return nil, nil
+	}
+	answer := &ImageScanSummary{}
+	err = yaml.Unmarshal([]byte(data), answer)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal the scan of %s version %s in ConfigMap %s", app, version, ImageScansConfigMap)
+	}
+	return answer, nil
+}
+
+// VulnerabilitiesDescription returns a one line description of the vulnerabilities such as CVE-1 (openssl), CVE-2 (zlib)
+func VulnerabilitiesDescription(vulnerabilities []ImageVulnerability) string {
+	descriptions := []string{}
+	for _, v := range vulnerabilities {
+		descriptions = append(descriptions, fmt.Sprintf("%s (%s)", v.ID, v.Package))
+	}
+	return strings.Join(descriptions, ", ")
+}
@@ -0,0 +1,113 @@
+package cve_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/cve"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func loadImageScanReport(t *testing.T, scanner string, fileName string) *cve.ImageScanReport {
+	data, err := ioutil.ReadFile(filepath.Join("test_data", "image_scan", fileName))
+	require.NoError(t, err)
+	report, err := cve.ParseImageScanReport(scanner, "gcr.io/myorg/myapp:0.0.1", data)
+	require.NoError(t, err, "failed to parse %s", fileName)
+	return report
+}
+
+func TestParseImageScanReports(t *testing.T) {
+	t.Parallel()
+	report := loadImageScanReport(t, cve.ScannerTrivy, "trivy.json")
+	require.Len(t, report.Vulnerabilities, 3)
+	assert.Equal(t, cve.ImageVulnerability{
+		ID:               "CVE-2019-5482",
+		Package:          "curl",
+		InstalledVersion: "7.64.0-4",
+		FixedVersion:     "7.64.0-4+deb10u1",
+		Severity:         cve.SeverityCritical,
+		Title:            "curl: heap buffer overflow in TFTP",
+	}, report.Vulnerabilities[1])
+
+	report = loadImageScanReport(t, cve.ScannerTrivy, "trivy-legacy.json")
+	require.Len(t, report.Vulnerabilities, 1)
+	assert.Equal(t, cve.SeverityHigh, report.Vulnerabilities[0].Severity)
+
+	report = loadImageScanReport(t, cve.ScannerGrype, "grype.json")
+	require.Len(t, report.Vulnerabilities, 2)
+	assert.Equal(t, cve.SeverityCritical, report.Vulnerabilities[0].Severity)
+	assert.Equal(t, "7.64.0-4+deb10u1", report.Vulnerabilities[0].FixedVersion)
+	assert.Equal(t, cve.SeverityUnknown, report.Vulnerabilities[1].Severity, "negligible vulnerabilities are not rated")
+
+	_, err := cve.ParseImageScanReport("clair", "myapp", []byte("{}"))
+	assert.Error(t, err)
+}
+
+func TestImageScanPolicy(t *testing.T) {
+	t.Parallel()
+	report := loadImageScanReport(t, cve.ScannerTrivy, "trivy.json")
+	high := report.VulnerabilitiesAtLeast(cve.SeverityHigh)
+	require.Len(t, high, 2)
+	medium := report.VulnerabilitiesAtLeast(cve.SeverityMedium)
+	require.Len(t, medium, 3)
+	assert.Equal(t, cve.SeverityCritical, medium[0].Severity, "the highest severities should be first")
+
+	policy := &v1.ImageScanPolicy{
+		IgnoreUnfixed:          true,
+		IgnoredVulnerabilities: []string{"CVE-2019-1547"},
+	}
+	report.ApplyPolicy(policy)
+	require.Len(t, report.Vulnerabilities, 1)
+	assert.Equal(t, "CVE-2019-5482", report.Vulnerabilities[0].ID)
+
+	assert.True(t, cve.IsSeverityAtLeast("critical", cve.SeverityHigh))
+	assert.False(t, cve.IsSeverityAtLeast(cve.SeverityLow, cve.SeverityHigh))
+	assert.True(t, cve.IsSeverityAtLeast(cve.SeverityLow, ""), "all severities match an empty threshold")
+}
+
+func TestImageScanSummary(t *testing.T) {
+	t.Parallel()
+	kubeClient := fake.NewSimpleClientset()
+	summary, err := cve.GetImageScanSummary(kubeClient, "jx", "myapp", "0.0.1")
+	require.NoError(t, err)
+	assert.Nil(t, summary)
+
+	report := loadImageScanReport(t, cve.ScannerTrivy, "trivy.json")
+	err = cve.SaveImageScanSummary(kubeClient, "jx", report.Summary())
+	require.NoError(t, err)
+
+	summary, err = cve.GetImageScanSummary(kubeClient, "jx", "myapp", "0.0.1")
+	require.NoError(t, err)
+	require.NotNil(t, summary)
+	assert.Equal(t, 2, summary.Counts[cve.SeverityCritical])
+	assert.Equal(t, 1, summary.Counts[cve.SeverityMedium])
+	assert.Len(t, summary.UnresolvedCritical(nil), 2)
+
+	policy := &v1.ImageScanPolicy{
+		IgnoredVulnerabilities: []string{"CVE-2019-12900"},
+		ProtectedEnvironments:  []string{"production"},
+	}
+	unresolved := summary.UnresolvedCritical(policy)
+	require.Len(t, unresolved, 1)
+	assert.Equal(t, "CVE-2019-5482 (curl)", cve.VulnerabilitiesDescription(unresolved))
+	assert.True(t, cve.IsProtectedEnvironment(policy, "production"))
+	assert.False(t, cve.IsProtectedEnvironment(policy, "staging"))
+	assert.False(t, cve.IsProtectedEnvironment(nil, "production"))
+}
+
+func TestImageNameAndTag(t *testing.T) {
+	t.Parallel()
+	for image, expected := range map[string][]string{
+		"gcr.io/myorg/myapp:0.0.1":         {"myapp", "0.0.1"},
+		"localhost:5000/myorg/myapp:1.2.3": {"myapp", "1.2.3"},
+		"localhost:5000/myorg/myapp":       {"myapp", "latest"},
+		"myapp@sha256:abcdef":              {"myapp", "sha256:abcdef"},
+	} {
+		name, tag := cve.ImageNameAndTag(image)
+		assert.Equal(t, expected, []string{name, tag}, "image %s", image)
+	}
+}
@@ -0,0 +1,237 @@
+package dependencyupdates
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/blang/semver"
+	"github.com/jenkins-x/jx/pkg/config"
+	"github.com/jenkins-x/jx/pkg/helm"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/jenkins-x/jx/pkg/versionstream"
+	"github.com/pkg/errors"
+)
+
+var dockerFromRegex = regexp.MustCompile(`(?m)^FROM\s+([^\s:@]+):([^\s@]+)`)
+
+// Update is a dependency of a project which has a newer version in the version stream
+type Update struct {
+	// Kind the kind of the dependency
+	Kind versionstream.VersionKind
+	// Name the name of the dependency in the version stream
+	Name string
+	// File the file in the project which references the dependency
+	File string
+	// FromVersion the version currently used by the project
+	FromVersion string
+	// ToVersion the version in the version stream
+	ToVersion string
+	// Group the update group from the project configuration
+	Group string
+}
+
+// String returns a description of the update
+func (u *Update) String() string {
+	return fmt.Sprintf("%s from %s to %s", u.Name, u.FromVersion, u.ToVersion)
+}
+
+// FindUpdates finds the chart and docker dependencies of the project in dir which are older than the versions in the
+// version stream, filtered and grouped using the project configuration
+func FindUpdates(dir string, versionsDir string, cfg *config.UpdatesConfig) ([]*Update, error) {
+	if cfg == nil {
+		cfg = &config.UpdatesConfig{}
+	}
+	answer := []*Update{}
+	if cfg.Disabled {
+		return answer, nil
+	}
+	if cfg.IncludesKind(string(versionstream.KindChart)) {
+		updates, err := findChartUpdates(dir, versionsDir)
+		if err != nil {
+			return nil, err
+		}
+		answer = append(answer, updates...)
+	}
+	if cfg.IncludesKind(string(versionstream.KindDocker)) {
+		updates, err := findDockerUpdates(dir, versionsDir)
+		if err != nil {
+			return nil, err
+		}
+		answer = append(answer, updates...)
+	}
+
+	filtered := []*Update{}
+	for _, u := range answer {
+		if cfg.IsIgnored(u.Name) {
+			continue
+		}
+		u.Group = cfg.GroupFor(u.Name)
+		filtered = append(filtered, u)
+	}
+	return filtered, nil
+}
+
+// GroupUpdates splits the updates into the sets which should each be raised as a single Pull Request. Updates in a
+// group go together and every other update gets its own set
+func GroupUpdates(updates []*Update) [][]*Update {
+	groups := map[string][]*Update{}
+	answer := [][]*Update{}
+	for _, u := range updates {
+		if u.Group == "" {
+			answer = append(answer, []*Update{u})
+			continue
+		}
+		groups[u.Group] = append(groups[u.Group], u)
+	}
+	names := []string{}
+	for name := range groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		answer = append(answer, groups[name])
+	}
+	return answer
+}
+
+// ApplyUpdates modifies the files in the project dir to use the new versions
+func ApplyUpdates(dir string, updates []*Update) error {
+	for _, u := range updates {
+		fileName := filepath.Join(dir, u.File)
+		switch u.Kind {
+		case versionstream.KindChart:
+			requirements, err := helm.LoadRequirementsFile(fileName)
+			if err != nil {
+				return errors.Wrapf(err, "loading %s", fileName)
+			}
+			_, name := splitChartName(u.Name)
+			helm.UpdateRequirementsToNewVersion(requirements, name, u.ToVersion)
+			err = helm.SaveFile(fileName, requirements)
+			if err != nil {
+				return err
+			}
+		case versionstream.KindDocker:
+			data, err := ioutil.ReadFile(fileName)
+			if err != nil {
+				return errors.Wrapf(err, "loading %s", fileName)
+			}
+			text := strings.Replace(string(data), u.Name+":"+u.FromVersion, u.Name+":"+u.ToVersion, -1)
+			err = ioutil.WriteFile(fileName, []byte(text), util.DefaultWritePermissions)
+			if err != nil {
+				return errors.Wrapf(err, "saving %s", fileName)
+			}
+		default:
+			return errors.Errorf("unsupported dependency kind %s for %s", u.Kind, u.Name)
+		}
+	}
+	return nil
+}
+
+func findChartUpdates(dir string, versionsDir string) ([]*Update, error) {
+	answer := []*Update{}
+	files, err := filepath.Glob(filepath.Join(dir, "charts", "*", helm.RequirementsFileName))
+	if err != nil {
+		return nil, errors.Wrapf(err, "finding requirements files in %s", dir)
+	}
+	if len(files) == 0 {
+		return answer, nil
+	}
+	prefixes, err := versionstream.GetRepositoryPrefixes(versionsDir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "loading repository prefixes")
+	}
+	for _, fileName := range files {
+		requirements, err := helm.LoadRequirementsFile(fileName)
+		if err != nil {
+			return nil, errors.Wrapf(err, "loading %s", fileName)
+		}
+		rel, err := filepath.Rel(dir, fileName)
+		if err != nil {
+			return nil, err
+		}
+		for _, dep := range requirements.Dependencies {
+			if dep == nil || dep.Repository == "" {
+				continue
+			}
+			prefix := prefixes.PrefixForURL(dep.Repository)
+			if prefix == "" {
+				continue
+			}
+			name := prefix + "/" + dep.Name
+			u, err := newUpdate(versionsDir, versionstream.KindChart, name, dep.Version, rel)
+			if err != nil {
+				return nil, err
+			}
+			if u != nil {
+				answer = append(answer, u)
+			}
+		}
+	}
+	return answer, nil
+}
+
+func findDockerUpdates(dir string, versionsDir string) ([]*Update, error) {
+	answer := []*Update{}
+	fileName := filepath.Join(dir, "Dockerfile")
+	exists, err := util.FileExists(fileName)
+	if err != nil || !exists {
+		return answer, err
+	}
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "loading %s", fileName)
+	}
+	for _, match := range dockerFromRegex.FindAllStringSubmatch(string(data), -1) {
+		u, err := newUpdate(versionsDir, versionstream.KindDocker, match[1], match[2], "Dockerfile")
+		if err != nil {
+			return nil, err
+		}
+		if u != nil {
+			answer = append(answer, u)
+		}
+	}
+	return answer, nil
+}
+
+// newUpdate returns the update if the version stream has a newer version of the dependency or nil if not
+func newUpdate(versionsDir string, kind versionstream.VersionKind, name string, currentVersion string, file string) (*Update, error) {
+	sv, err := versionstream.LoadStableVersion(versionsDir, kind, name)
+	if err != nil {
+		return nil, err
+	}
+	if sv.Version == "" || !IsNewerVersion(sv.Version, currentVersion) {
+		return nil, nil
+	}
+	return &Update{
+		Kind:        kind,
+		Name:        name,
+		File:        file,
+		FromVersion: currentVersion,
+		ToVersion:   sv.Version,
+	}, nil
+}
+
+// IsNewerVersion returns true if the version is a newer semantic version than the current version
+func IsNewerVersion(version string, currentVersion string) bool {
+	v, err := semver.ParseTolerant(version)
+	if err != nil {
+		return false
+	}
+	current, err := semver.ParseTolerant(currentVersion)
+	if err != nil {
+		return false
+	}
+	return v.GT(current)
+}
+
+func splitChartName(name string) (string, string) {
+	idx := strings.LastIndex(name, "/")
+	if idx < 0 {
+		return "", name
+	}
+	return name[:idx], name[idx+1:]
+}
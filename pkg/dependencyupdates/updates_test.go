@@ -0,0 +1,111 @@
+package dependencyupdates_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x/jx/pkg/config"
+	"github.com/jenkins-x/jx/pkg/dependencyupdates"
+	"github.com/jenkins-x/jx/pkg/helm"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/jenkins-x/jx/pkg/versionstream"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	projectDir  = filepath.Join("test_data", "project")
+	versionsDir = filepath.Join("test_data", "versions")
+)
+
+func TestFindUpdates(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := config.LoadUpdatesConfig(projectDir)
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+
+	updates, err := dependencyupdates.FindUpdates(projectDir, versionsDir, cfg)
+	require.NoError(t, err)
+	require.Len(t, updates, 2)
+
+	assert.Equal(t, versionstream.KindChart, updates[0].Kind)
+	assert.Equal(t, "jenkins-x/exposecontroller", updates[0].Name)
+	assert.Equal(t, filepath.Join("charts", "myapp", "requirements.yaml"), updates[0].File)
+	assert.Equal(t, "2.3.89", updates[0].FromVersion)
+	assert.Equal(t, "2.3.118", updates[0].ToVersion)
+	assert.Equal(t, "", updates[0].Group)
+
+	assert.Equal(t, versionstream.KindDocker, updates[1].Kind)
+	assert.Equal(t, "golang", updates[1].Name)
+	assert.Equal(t, "1.11.4", updates[1].FromVersion)
+	assert.Equal(t, "1.12.9", updates[1].ToVersion)
+	assert.Equal(t, "build-images", updates[1].Group)
+
+	cfg.Kinds = []string{string(versionstream.KindDocker)}
+	updates, err = dependencyupdates.FindUpdates(projectDir, versionsDir, cfg)
+	require.NoError(t, err)
+	require.Len(t, updates, 1)
+	assert.Equal(t, "golang", updates[0].Name)
+
+	cfg.Ignore = append(cfg.Ignore, "golang")
+	updates, err = dependencyupdates.FindUpdates(projectDir, versionsDir, cfg)
+	require.NoError(t, err)
+	assert.Empty(t, updates)
+}
+
+func TestGroupUpdates(t *testing.T) {
+	t.Parallel()
+
+	updates := []*dependencyupdates.Update{
+		{Name: "jenkins-x/exposecontroller"},
+		{Name: "golang", Group: "build-images"},
+		{Name: "jenkins-x/jx-app-sso"},
+		{Name: "maven", Group: "build-images"},
+	}
+	sets := dependencyupdates.GroupUpdates(updates)
+	require.Len(t, sets, 3)
+	assert.Equal(t, []*dependencyupdates.Update{updates[0]}, sets[0])
+	assert.Equal(t, []*dependencyupdates.Update{updates[2]}, sets[1])
+	assert.Equal(t, []*dependencyupdates.Update{updates[1], updates[3]}, sets[2])
+}
+
+func TestApplyUpdates(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "test-apply-updates")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	err = util.CopyDir(projectDir, dir, true)
+	require.NoError(t, err)
+
+	updates, err := dependencyupdates.FindUpdates(dir, versionsDir, nil)
+	require.NoError(t, err)
+	err = dependencyupdates.ApplyUpdates(dir, updates)
+	require.NoError(t, err)
+
+	requirements, err := helm.LoadRequirementsFile(filepath.Join(dir, "charts", "myapp", "requirements.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, "2.3.118", requirements.Dependencies[0].Version)
+	assert.Equal(t, "0.0.12", requirements.Dependencies[1].Version)
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, "Dockerfile"))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "FROM golang:1.12.9 as build")
+
+	updates, err = dependencyupdates.FindUpdates(dir, versionsDir, nil)
+	require.NoError(t, err)
+	assert.Empty(t, updates)
+}
+
+func TestIsNewerVersion(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, dependencyupdates.IsNewerVersion("1.2.10", "1.2.9"))
+	assert.True(t, dependencyupdates.IsNewerVersion("v2.0.0", "1.9.0"))
+	assert.False(t, dependencyupdates.IsNewerVersion("1.2.9", "1.2.9"))
+	assert.False(t, dependencyupdates.IsNewerVersion("1.2.8", "1.2.9"))
+	assert.False(t, dependencyupdates.IsNewerVersion("latest", "1.2.9"))
+}
@@ -0,0 +1,64 @@
+package devsync
+
+import (
+	"archive/tar"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+// WriteTar writes a tar archive of the files of the directory to the writer. The files are slash separated paths
+// relative to the directory
+func WriteTar(w io.Writer, dir string, files []string) error {
+	tw := tar.NewWriter(w)
+	for _, file := range files {
+		err := addFile(tw, dir, file)
+		if err != nil {
+			return err
+		}
+	}
+	return tw.Close()
+}
+
+func addFile(tw *tar.Writer, dir string, file string) error {
+	path := filepath.Join(dir, filepath.FromSlash(file))
+	info, err := os.Lstat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			// the file was deleted since the snapshot so the next sync deletes it
+			return nil
+		}
+		return errors.Wrapf(err, "reading %s", path)
+	}
+	link := ""
+	if info.Mode()&os.ModeSymlink != 0 {
+		link, err = os.Readlink(path)
+		if err != nil {
+			return errors.Wrapf(err, "reading link %s", path)
+		}
+	}
+	header, err := tar.FileInfoHeader(info, link)
+	if err != nil {
+		return errors.Wrapf(err, "creating the tar header for %s", path)
+	}
+	header.Name = file
+	err = tw.WriteHeader(header)
+	if err != nil {
+		return errors.Wrapf(err, "writing the tar header for %s", path)
+	}
+	if !info.Mode().IsRegular() {
+		return nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return errors.Wrapf(err, "opening %s", path)
+	}
+	defer f.Close()
+	_, err = io.CopyN(tw, f, header.Size)
+	if err != nil {
+		return errors.Wrapf(err, "archiving %s", path)
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package devsync
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+)
+
+// IgnoreFileName the name of the file in the source tree listing the files which are not synchronised
+const IgnoreFileName = ".stignore"
+
+// DefaultIgnores the files which are not synchronised if there is no ignore file
+var DefaultIgnores = []string{".git", ".idea", ".settings", ".vscode", "bin", "build", "target", "node_modules"}
+
+// FileState the state of a file used to detect changes
+type FileState struct {
+	ModTime time.Time
+	Size    int64
+	Mode    os.FileMode
+}
+
+// Snapshot the state of the files of a source tree indexed by their slash separated path relative to the tree
+type Snapshot map[string]FileState
+
+// LoadIgnores loads the patterns of the files to ignore from the ignore file in the directory, defaulting to
+// DefaultIgnores
+func LoadIgnores(dir string) ([]string, error) {
+	fileName := filepath.Join(dir, IgnoreFileName)
+	exists, err := util.FileExists(fileName)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return DefaultIgnores, nil
+	}
+	f, err := os.Open(fileName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "opening %s", fileName)
+	}
+	defer f.Close()
+
+	answer := []string{IgnoreFileName}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "//") {
+			continue
+		}
+		answer = append(answer, strings.TrimSuffix(line, "/"))
+	}
+	return answer, scanner.Err()
+}
+
+// IsIgnored returns true if the relative path or any of its parent directories matches one of the ignore patterns
+func IsIgnored(path string, ignores []string) bool {
+	segments := strings.Split(path, "/")
+	for _, pattern := range ignores {
+		if strings.Contains(pattern, "/") {
+			if matched, _ := filepath.Match(strings.TrimPrefix(pattern, "/"), path); matched {
+				return true
+			}
+			continue
+		}
+		for _, segment := range segments {
+			if matched, _ := filepath.Match(pattern, segment); matched {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// TakeSnapshot walks the directory recording the state of each file which is not ignored
+func TakeSnapshot(dir string, ignores []string) (Snapshot, error) {
+	answer := Snapshot{}
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		if rel == "." {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+		if IsIgnored(rel, ignores) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info.IsDir() {
+			return nil
+		}
+		answer[rel] = FileState{
+			ModTime: info.ModTime(),
+			Size:    info.Size(),
+			Mode:    info.Mode(),
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "walking directory %s", dir)
+	}
+	return answer, nil
+}
+
+// Diff returns the sorted paths of the files which have been added or modified and those which have been deleted
+// since the previous snapshot
+func (s Snapshot) Diff(previous Snapshot) ([]string, []string) {
+	changed := []string{}
+	deleted := []string{}
+	for path, state := range s {
+		old, ok := previous[path]
+		if !ok || !old.ModTime.Equal(state.ModTime) || old.Size != state.Size || old.Mode != state.Mode {
+			changed = append(changed, path)
+		}
+	}
+	for path := range previous {
+		if _, ok := s[path]; !ok {
+			deleted = append(deleted, path)
+		}
+	}
+	sort.Strings(changed)
+	sort.Strings(deleted)
+	return changed, deleted
+}
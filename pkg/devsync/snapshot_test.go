@@ -0,0 +1,80 @@
+package devsync_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jenkins-x/jx/pkg/devsync"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsIgnored(t *testing.T) {
+	t.Parallel()
+
+	ignores := []string{".git", "node_modules", "*.log", "/docs/generated"}
+	assert.True(t, devsync.IsIgnored(".git/config", ignores))
+	assert.True(t, devsync.IsIgnored("web/node_modules/lib/index.js", ignores))
+	assert.True(t, devsync.IsIgnored("debug.log", ignores))
+	assert.True(t, devsync.IsIgnored("docs/generated", ignores))
+	assert.False(t, devsync.IsIgnored("docs/index.md", ignores))
+	assert.False(t, devsync.IsIgnored("main.go", ignores))
+}
+
+func TestSnapshotDiffAndTar(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "test-devsync-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	writeFile(t, dir, "main.go", "package main")
+	writeFile(t, dir, "pkg/lib.go", "package pkg")
+	writeFile(t, dir, "target/app", "binary")
+
+	previous, err := devsync.TakeSnapshot(dir, devsync.DefaultIgnores)
+	require.NoError(t, err)
+	assert.Len(t, previous, 2)
+
+	changed, deleted := previous.Diff(nil)
+	assert.Equal(t, []string{"main.go", "pkg/lib.go"}, changed)
+	assert.Empty(t, deleted)
+
+	writeFile(t, dir, "pkg/lib.go", "package pkg\n\nfunc Lib() {}")
+	modTime := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(filepath.Join(dir, "pkg/lib.go"), modTime, modTime))
+	writeFile(t, dir, "README.md", "# readme")
+	require.NoError(t, os.Remove(filepath.Join(dir, "main.go")))
+
+	current, err := devsync.TakeSnapshot(dir, devsync.DefaultIgnores)
+	require.NoError(t, err)
+	changed, deleted = current.Diff(previous)
+	assert.Equal(t, []string{"README.md", "pkg/lib.go"}, changed)
+	assert.Equal(t, []string{"main.go"}, deleted)
+
+	buffer := &bytes.Buffer{}
+	require.NoError(t, devsync.WriteTar(buffer, dir, changed))
+	tr := tar.NewReader(buffer)
+	names := []string{}
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		names = append(names, header.Name)
+	}
+	assert.Equal(t, changed, names)
+}
+
+func writeFile(t *testing.T, dir string, name string, text string) {
+	path := filepath.Join(dir, filepath.FromSlash(name))
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), os.ModePerm))
+	require.NoError(t, ioutil.WriteFile(path, []byte(text), 0644))
+}
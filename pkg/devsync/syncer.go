@@ -0,0 +1,170 @@
+package devsync
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+)
+
+// Syncer synchronises a local source tree into a directory of a container running in a pod
+type Syncer struct {
+	Dir       string
+	Namespace string
+	Pod       string
+	Container string
+	RemoteDir string
+	// OnChange an optional shell command run in the container after each sync to rebuild or restart the application
+	OnChange string
+	// Period how often the source tree is checked for changes
+	Period time.Duration
+
+	ignores  []string
+	snapshot Snapshot
+}
+
+// Sync synchronises the files which changed since the previous sync. Returns true if any files were synchronised
+func (s *Syncer) Sync() (bool, error) {
+	if s.ignores == nil {
+		ignores, err := LoadIgnores(s.Dir)
+		if err != nil {
+			return false, err
+		}
+		s.ignores = ignores
+	}
+	snapshot, err := TakeSnapshot(s.Dir, s.ignores)
+	if err != nil {
+		return false, err
+	}
+	changed, deleted := snapshot.Diff(s.snapshot)
+	if len(changed) == 0 && len(deleted) == 0 {
+		return false, nil
+	}
+
+	if len(changed) > 0 {
+		err = s.copyFiles(changed)
+		if err != nil {
+			return false, err
+		}
+	}
+	if len(deleted) > 0 {
+		err = s.deleteFiles(deleted)
+		if err != nil {
+			return false, err
+		}
+	}
+	if s.snapshot != nil {
+		log.Logger().Infof("Synchronised %s changed and %s deleted files to %s", util.ColorInfo(len(changed)), util.ColorInfo(len(deleted)), util.ColorInfo(s.Pod+":"+s.RemoteDir))
+	} else {
+		log.Logger().Infof("Synchronised %s files to %s", util.ColorInfo(len(changed)), util.ColorInfo(s.Pod+":"+s.RemoteDir))
+	}
+	s.snapshot = snapshot
+	return true, nil
+}
+
+// Watch synchronises the source tree then polls it for changes, synchronising them and running the on change hook
+// until the stop channel is closed
+func (s *Syncer) Watch(stop <-chan struct{}) error {
+	period := s.Period
+	if period <= 0 {
+		period = time.Second
+	}
+	_, err := s.Sync()
+	if err != nil {
+		return err
+	}
+	err = s.runOnChange()
+	if err != nil {
+		log.Logger().Warnf("%s", err.Error())
+	}
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return nil
+		case <-ticker.C:
+			synced, err := s.Sync()
+			if err != nil {
+				return err
+			}
+			if synced {
+				err = s.runOnChange()
+				if err != nil {
+					log.Logger().Warnf("%s", err.Error())
+				}
+			}
+		}
+	}
+}
+
+func (s *Syncer) copyFiles(files []string) error {
+	buffer := &bytes.Buffer{}
+	err := WriteTar(buffer, s.Dir, files)
+	if err != nil {
+		return err
+	}
+	script := fmt.Sprintf("mkdir -p %s && tar xmf - -C %s", quote(s.RemoteDir), quote(s.RemoteDir))
+	err = s.exec(buffer, script)
+	if err != nil {
+		return errors.Wrapf(err, "copying %d files to %s", len(files), s.Pod)
+	}
+	return nil
+}
+
+func (s *Syncer) deleteFiles(files []string) error {
+	paths := []string{}
+	for _, file := range files {
+		paths = append(paths, quote(strings.TrimSuffix(s.RemoteDir, "/")+"/"+file))
+	}
+	err := s.exec(nil, "rm -f "+strings.Join(paths, " "))
+	if err != nil {
+		return errors.Wrapf(err, "deleting %d files from %s", len(files), s.Pod)
+	}
+	return nil
+}
+
+func (s *Syncer) runOnChange() error {
+	if s.OnChange == "" {
+		return nil
+	}
+	log.Logger().Infof("Running %s in %s", util.ColorInfo(s.OnChange), util.ColorInfo(s.Pod))
+	err := s.exec(nil, "cd "+quote(s.RemoteDir)+" && "+s.OnChange)
+	if err != nil {
+		return errors.Wrapf(err, "running %s in %s", s.OnChange, s.Pod)
+	}
+	return nil
+}
+
+func (s *Syncer) exec(in *bytes.Buffer, script string) error {
+	args := []string{"exec", "-n", s.Namespace}
+	if in != nil {
+		args = append(args, "-i")
+	}
+	args = append(args, s.Pod)
+	if s.Container != "" {
+		args = append(args, "-c", s.Container)
+	}
+	args = append(args, "--", "/bin/sh", "-c", script)
+	cmd := util.Command{
+		Name: "kubectl",
+		Args: args,
+		Out:  os.Stdout,
+		Err:  os.Stderr,
+	}
+	if in != nil {
+		cmd.In = in
+	}
+	_, err := cmd.RunWithoutRetry()
+	return err
+}
+
+// quote quotes the value for use as a single argument of a shell command
+func quote(value string) string {
+	return "'" + strings.Replace(value, "'", `'\''`, -1) + "'"
+}
@@ -0,0 +1,287 @@
+package dora
+
+import (
+	"encoding/csv"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+)
+
+// Deployment a promotion of a version of an application to an environment
+type Deployment struct {
+	Owner       string `json:"owner,omitempty"`
+	Repository  string `json:"repository"`
+	Version     string `json:"version,omitempty"`
+	Environment string `json:"environment"`
+	Succeeded   bool   `json:"succeeded"`
+	// ChangeTime the time the earliest change released in the version was made
+	ChangeTime time.Time `json:"changeTime"`
+	// DeployedTime the time the promotion completed
+	DeployedTime   time.Time `json:"deployedTime"`
+	PullRequestURL string    `json:"pullRequestURL,omitempty"`
+}
+
+// Application returns the name of the application of the deployment
+func (d *Deployment) Application() string {
+	if d.Owner == "" {
+		return d.Repository
+	}
+	return d.Owner + "/" + d.Repository
+}
+
+// Metrics the DORA metrics of an application or a team
+type Metrics struct {
+	Name              string `json:"name"`
+	Deployments       int    `json:"deployments"`
+	FailedDeployments int    `json:"failedDeployments"`
+	// DeploymentFrequency the successful deployments per day
+	DeploymentFrequency float64 `json:"deploymentFrequency"`
+	// LeadTimeHours the median hours from a change to its successful deployment
+	LeadTimeHours float64 `json:"leadTimeHours"`
+	// ChangeFailureRate the ratio of the deployments which failed
+	ChangeFailureRate float64 `json:"changeFailureRate"`
+	// TimeToRestoreHours the mean hours from a failed deployment to the next successful deployment
+	TimeToRestoreHours float64 `json:"timeToRestoreHours"`
+}
+
+// Report the DORA metrics of the applications of a team deployed to an environment
+type Report struct {
+	Environment  string     `json:"environment"`
+	Since        time.Time  `json:"since"`
+	Until        time.Time  `json:"until"`
+	Team         *Metrics   `json:"team"`
+	Applications []*Metrics `json:"applications"`
+}
+
+// Deployments returns the promotions to the environment recorded in the PipelineActivities. The time of the
+// earliest change of each version is the creation of the earliest pull request of its Release or the start of its
+// release pipeline if the Release has no pull requests
+func Deployments(activities []v1.PipelineActivity, releases []v1.Release, environment string) []*Deployment {
+	releaseMap := map[string]*v1.Release{}
+	for i := range releases {
+		spec := &releases[i].Spec
+		releaseMap[releaseKey(spec.GitOwner, spec.GitRepository, spec.Version)] = &releases[i]
+	}
+	answer := []*Deployment{}
+	for i := range activities {
+		activity := &activities[i]
+		spec := &activity.Spec
+		for _, step := range spec.Steps {
+			promote := step.Promote
+			if promote == nil || promote.Environment != environment {
+				continue
+			}
+			succeeded := promote.Status == v1.ActivityStatusTypeSucceeded
+			if !succeeded && promote.Status != v1.ActivityStatusTypeFailed && promote.Status != v1.ActivityStatusTypeError {
+				continue
+			}
+			deployed := promoteCompletedTime(promote)
+			if deployed.IsZero() {
+				continue
+			}
+			d := &Deployment{
+				Owner:        spec.GitOwner,
+				Repository:   spec.GitRepository,
+				Version:      spec.Version,
+				Environment:  environment,
+				Succeeded:    succeeded,
+				ChangeTime:   changeTime(activity, releaseMap[releaseKey(spec.GitOwner, spec.GitRepository, spec.Version)]),
+				DeployedTime: deployed,
+			}
+			if promote.PullRequest != nil {
+				d.PullRequestURL = promote.PullRequest.PullRequestURL
+			}
+			answer = append(answer, d)
+		}
+	}
+	sort.Slice(answer, func(i, j int) bool {
+		return answer[i].DeployedTime.Before(answer[j].DeployedTime)
+	})
+	return answer
+}
+
+// Calculate calculates the DORA metrics of each application and of the whole team from the deployments completed
+// between since and until. The deployments must be sorted in the order they were deployed
+func Calculate(deployments []*Deployment, team string, since time.Time, until time.Time) *Report {
+	days := until.Sub(since).Hours() / 24
+	teamTotals := &totals{}
+	appTotals := map[string]*totals{}
+	for _, d := range deployments {
+		if d.DeployedTime.Before(since) || d.DeployedTime.After(until) {
+			continue
+		}
+		name := d.Application()
+		t := appTotals[name]
+		if t == nil {
+			t = &totals{}
+			appTotals[name] = t
+		}
+		t.add(d)
+	}
+
+	report := &Report{
+		Since:        since,
+		Until:        until,
+		Applications: []*Metrics{},
+	}
+	for name, t := range appTotals {
+		report.Applications = append(report.Applications, t.metrics(name, days))
+		teamTotals.merge(t)
+	}
+	sort.Slice(report.Applications, func(i, j int) bool {
+		return report.Applications[i].Name < report.Applications[j].Name
+	})
+	report.Team = teamTotals.metrics(team, days)
+	return report
+}
+
+// WriteCSV writes the metrics of the applications followed by the metrics of the team as CSV
+func WriteCSV(w io.Writer, report *Report) error {
+	writer := csv.NewWriter(w)
+	err := writer.Write([]string{"name", "deployments", "failedDeployments", "deploymentFrequency", "leadTimeHours", "changeFailureRate", "timeToRestoreHours"})
+	if err != nil {
+		return err
+	}
+	rows := append([]*Metrics{}, report.Applications...)
+	if report.Team != nil {
+		rows = append(rows, report.Team)
+	}
+	for _, m := range rows {
+		err = writer.Write([]string{
+			m.Name,
+			strconv.Itoa(m.Deployments),
+			strconv.Itoa(m.FailedDeployments),
+			formatFloat(m.DeploymentFrequency),
+			formatFloat(m.LeadTimeHours),
+			formatFloat(m.ChangeFailureRate),
+			formatFloat(m.TimeToRestoreHours),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// totals the deployments, lead times and restore times of an application or team
+type totals struct {
+	deployments int
+	failed      int
+	leadTimes   []time.Duration
+	restores    []time.Duration
+	failedSince time.Time
+}
+
+// add adds a deployment which must be added in the order they were deployed
+func (t *totals) add(d *Deployment) {
+	t.deployments++
+	if !d.Succeeded {
+		t.failed++
+		if t.failedSince.IsZero() {
+			t.failedSince = d.DeployedTime
+		}
+		return
+	}
+	if !d.ChangeTime.IsZero() && d.DeployedTime.After(d.ChangeTime) {
+		t.leadTimes = append(t.leadTimes, d.DeployedTime.Sub(d.ChangeTime))
+	}
+	if !t.failedSince.IsZero() {
+		t.restores = append(t.restores, d.DeployedTime.Sub(t.failedSince))
+		t.failedSince = time.Time{}
+	}
+}
+
+func (t *totals) merge(other *totals) {
+	t.deployments += other.deployments
+	t.failed += other.failed
+	t.leadTimes = append(t.leadTimes, other.leadTimes...)
+	t.restores = append(t.restores, other.restores...)
+}
+
+func (t *totals) metrics(name string, days float64) *Metrics {
+	m := &Metrics{
+		Name:               name,
+		Deployments:        t.deployments,
+		FailedDeployments:  t.failed,
+		LeadTimeHours:      median(t.leadTimes).Hours(),
+		TimeToRestoreHours: mean(t.restores).Hours(),
+	}
+	if days > 0 {
+		m.DeploymentFrequency = float64(t.deployments-t.failed) / days
+	}
+	if t.deployments > 0 {
+		m.ChangeFailureRate = float64(t.failed) / float64(t.deployments)
+	}
+	return m
+}
+
+// promoteCompletedTime returns the time the promotion completed or the time its pull request or update completed
+func promoteCompletedTime(promote *v1.PromoteActivityStep) time.Time {
+	if promote.CompletedTimestamp != nil {
+		return promote.CompletedTimestamp.Time
+	}
+	if promote.Update != nil && promote.Update.CompletedTimestamp != nil {
+		return promote.Update.CompletedTimestamp.Time
+	}
+	if promote.PullRequest != nil && promote.PullRequest.CompletedTimestamp != nil {
+		return promote.PullRequest.CompletedTimestamp.Time
+	}
+	return time.Time{}
+}
+
+// changeTime returns the creation time of the earliest pull request of the release or the start of the pipeline
+func changeTime(activity *v1.PipelineActivity, release *v1.Release) time.Time {
+	answer := time.Time{}
+	if release != nil {
+		for _, pr := range release.Spec.PullRequests {
+			if pr.CreationTimestamp != nil && (answer.IsZero() || pr.CreationTimestamp.Time.Before(answer)) {
+				answer = pr.CreationTimestamp.Time
+			}
+		}
+	}
+	if answer.IsZero() {
+		if activity.Spec.StartedTimestamp != nil {
+			return activity.Spec.StartedTimestamp.Time
+		}
+		return activity.CreationTimestamp.Time
+	}
+	return answer
+}
+
+func releaseKey(owner string, repository string, version string) string {
+	return owner + "/" + repository + "/" + version
+}
+
+func median(durations []time.Duration) time.Duration {
+	if len(durations) == 0 {
+		return 0
+	}
+	sorted := append([]time.Duration{}, durations...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i] < sorted[j]
+	})
+	middle := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[middle-1] + sorted[middle]) / 2
+	}
+	return sorted[middle]
+}
+
+func mean(durations []time.Duration) time.Duration {
+	if len(durations) == 0 {
+		return 0
+	}
+	var total time.Duration
+	for _, d := range durations {
+		total += d
+	}
+	return total / time.Duration(len(durations))
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', 2, 64)
+}
@@ -0,0 +1,160 @@
+package dora_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/dora"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var start = time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC)
+
+func TestDeployments(t *testing.T) {
+	t.Parallel()
+
+	prCreated := metav1.NewTime(start.Add(-2 * time.Hour))
+	activities := []v1.PipelineActivity{
+		newActivity("myapp", "1.0.1", start, v1.ActivityStatusTypeSucceeded, start.Add(time.Hour)),
+		newActivity("myapp", "1.0.2", start.Add(24*time.Hour), v1.ActivityStatusTypeFailed, start.Add(25*time.Hour)),
+		newActivity("myapp", "1.0.3", start.Add(26*time.Hour), v1.ActivityStatusTypeRunning, time.Time{}),
+	}
+	releases := []v1.Release{
+		{
+			Spec: v1.ReleaseSpec{
+				GitOwner:      "myorg",
+				GitRepository: "myapp",
+				Version:       "1.0.1",
+				PullRequests: []v1.IssueSummary{
+					{ID: "2", CreationTimestamp: &prCreated},
+				},
+			},
+		},
+	}
+
+	deployments := dora.Deployments(activities, releases, "production")
+	require.Len(t, deployments, 2)
+	assert.Equal(t, "myorg/myapp", deployments[0].Application())
+	assert.Equal(t, "1.0.1", deployments[0].Version)
+	assert.True(t, deployments[0].Succeeded)
+	assert.Equal(t, prCreated.Time, deployments[0].ChangeTime)
+	assert.Equal(t, start.Add(time.Hour), deployments[0].DeployedTime)
+	assert.Equal(t, "https://github.com/myorg/environment-production/pull/1", deployments[0].PullRequestURL)
+
+	assert.False(t, deployments[1].Succeeded)
+	assert.Equal(t, start.Add(24*time.Hour), deployments[1].ChangeTime)
+
+	assert.Empty(t, dora.Deployments(activities, releases, "staging"))
+}
+
+func TestCalculate(t *testing.T) {
+	t.Parallel()
+
+	deployments := []*dora.Deployment{
+		newDeployment("myapp", true, start.Add(time.Hour), start.Add(3*time.Hour)),
+		newDeployment("other", true, start.Add(2*time.Hour), start.Add(6*time.Hour)),
+		newDeployment("myapp", false, start.Add(24*time.Hour), start.Add(25*time.Hour)),
+		newDeployment("myapp", false, start.Add(26*time.Hour), start.Add(27*time.Hour)),
+		newDeployment("myapp", true, start.Add(28*time.Hour), start.Add(29*time.Hour)),
+		// deployed after the report
+		newDeployment("myapp", true, start.Add(24*20*time.Hour), start.Add(24*20*time.Hour)),
+	}
+
+	report := dora.Calculate(deployments, "jx", start, start.Add(10*24*time.Hour))
+	require.Len(t, report.Applications, 2)
+
+	app := report.Applications[0]
+	assert.Equal(t, "myorg/myapp", app.Name)
+	assert.Equal(t, 4, app.Deployments)
+	assert.Equal(t, 2, app.FailedDeployments)
+	assert.InDelta(t, 0.2, app.DeploymentFrequency, 0.0001)
+	assert.InDelta(t, 0.5, app.ChangeFailureRate, 0.0001)
+	assert.InDelta(t, 1.5, app.LeadTimeHours, 0.0001)
+	// restored 4 hours after the first of the two failed deployments
+	assert.InDelta(t, 4, app.TimeToRestoreHours, 0.0001)
+
+	other := report.Applications[1]
+	assert.Equal(t, "myorg/other", other.Name)
+	assert.InDelta(t, 4, other.LeadTimeHours, 0.0001)
+	assert.Equal(t, float64(0), other.TimeToRestoreHours)
+
+	team := report.Team
+	assert.Equal(t, "jx", team.Name)
+	assert.Equal(t, 5, team.Deployments)
+	assert.InDelta(t, 0.3, team.DeploymentFrequency, 0.0001)
+	assert.InDelta(t, 0.4, team.ChangeFailureRate, 0.0001)
+	assert.InDelta(t, 2, team.LeadTimeHours, 0.0001)
+	assert.InDelta(t, 4, team.TimeToRestoreHours, 0.0001)
+
+	var buf bytes.Buffer
+	err := dora.WriteCSV(&buf, report)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Equal(t, []string{
+		"name,deployments,failedDeployments,deploymentFrequency,leadTimeHours,changeFailureRate,timeToRestoreHours",
+		"myorg/myapp,4,2,0.20,1.50,0.50,4.00",
+		"myorg/other,1,0,0.10,4.00,0.00,0.00",
+		"jx,5,2,0.30,2.00,0.40,4.00",
+	}, lines)
+}
+
+func newActivity(repository string, version string, started time.Time, status v1.ActivityStatusType, completed time.Time) v1.PipelineActivity {
+	startedTime := metav1.NewTime(started)
+	promote := &v1.PromoteActivityStep{
+		CoreActivityStep: v1.CoreActivityStep{
+			Status:           status,
+			StartedTimestamp: &startedTime,
+		},
+		Environment: "production",
+		PullRequest: &v1.PromotePullRequestStep{
+			PullRequestURL: "https://github.com/myorg/environment-production/pull/1",
+		},
+	}
+	if !completed.IsZero() {
+		completedTime := metav1.NewTime(completed)
+		promote.CompletedTimestamp = &completedTime
+	}
+	return v1.PipelineActivity{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "myorg-" + repository + "-master-" + version,
+		},
+		Spec: v1.PipelineActivitySpec{
+			GitOwner:         "myorg",
+			GitRepository:    repository,
+			GitBranch:        "master",
+			Version:          version,
+			StartedTimestamp: &startedTime,
+			Steps: []v1.PipelineActivityStep{
+				{
+					Kind: v1.ActivityStepKindTypeStage,
+					Stage: &v1.StageActivityStep{
+						CoreActivityStep: v1.CoreActivityStep{
+							Name:   "Build",
+							Status: v1.ActivityStatusTypeSucceeded,
+						},
+					},
+				},
+				{
+					Kind:    v1.ActivityStepKindTypePromote,
+					Promote: promote,
+				},
+			},
+		},
+	}
+}
+
+func newDeployment(repository string, succeeded bool, changed time.Time, deployed time.Time) *dora.Deployment {
+	return &dora.Deployment{
+		Owner:        "myorg",
+		Repository:   repository,
+		Environment:  "production",
+		Succeeded:    succeeded,
+		ChangeTime:   changed,
+		DeployedTime: deployed,
+	}
+}
@@ -0,0 +1,251 @@
+package envfrom
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/aws/aws-sdk-go/service/ssm/ssmiface"
+	"github.com/jenkins-x/jx/pkg/cloud/amazon/session"
+	"github.com/jenkins-x/jx/pkg/config"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/jenkins-x/jx/pkg/vault"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// secretSuffix the suffix of the name of the secret containing the resolved environment variables of a pipeline
+	secretSuffix = "-env"
+	// vaultSecretPrefix the prefix of the vault paths of the key value secrets engine
+	vaultSecretPrefix = "secret/data/"
+)
+
+// Resolver reads the environment variables of the pipelines from the external config stores
+type Resolver struct {
+	// Vault returns the vault client of the team
+	Vault func() (vault.Client, error)
+	// ParameterStore returns the AWS Systems Manager client of the region
+	ParameterStore func(region string) (ssmiface.SSMAPI, error)
+	// SecretManager returns the latest version of the GCP Secret Manager secret of the project
+	SecretManager func(project string, secret string) (string, error)
+	// Region the default AWS region of the parameters
+	Region string
+}
+
+// NewResolver creates a resolver using the vault of the team, the AWS credentials and gcloud of the caller
+func NewResolver(vaultClient func() (vault.Client, error), region string) *Resolver {
+	return &Resolver{
+		Vault:          vaultClient,
+		ParameterStore: parameterStore,
+		SecretManager:  secretManager,
+		Region:         region,
+	}
+}
+
+// Resolve returns the environment variables of the sources. Later sources override the variables of earlier ones
+func (r *Resolver) Resolve(sources []config.EnvFromSource) (map[string]string, error) {
+	answer := map[string]string{}
+	for i := range sources {
+		source := &sources[i]
+		var values map[string]string
+		var err error
+		switch {
+		case source.Vault != "":
+			values, err = r.resolveVault(source)
+		case source.AWSParameterStore != "":
+			values, err = r.resolveParameterStore(source)
+		case source.GCPSecretManager != "":
+			values, err = r.resolveSecretManager(source)
+		default:
+			err = fmt.Errorf("the envFrom source %d has no vault, awsParameterStore or gcpSecretManager", i)
+		}
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range values {
+			answer[EnvVarName(source.Prefix+k)] = v
+		}
+	}
+	return answer, nil
+}
+
+func (r *Resolver) resolveVault(source *config.EnvFromSource) (map[string]string, error) {
+	if r.Vault == nil {
+		return nil, fmt.Errorf("no vault is configured so cannot load the environment variables from %s", source.Vault)
+	}
+	client, err := r.Vault()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create the vault client")
+	}
+	path := strings.TrimPrefix(strings.TrimPrefix(source.Vault, "/"), vaultSecretPrefix)
+	data, err := client.Read(path)
+	if err != nil {
+		return nil, err
+	}
+	answer := map[string]string{}
+	for k, v := range data {
+		answer[k], err = stringValue(v)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to convert the value of %s in vault secret %s", k, source.Vault)
+		}
+	}
+	return answer, nil
+}
+
+func (r *Resolver) resolveParameterStore(source *config.EnvFromSource) (map[string]string, error) {
+	region := source.Region
+	if region == "" {
+		region = r.Region
+	}
+	client, err := r.ParameterStore(region)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create the AWS Systems Manager client")
+	}
+	path := "/" + strings.Trim(source.AWSParameterStore, "/")
+	input := &ssm.GetParametersByPathInput{
+		Path:           aws.String(path),
+		Recursive:      aws.Bool(true),
+		WithDecryption: aws.Bool(true),
+	}
+	answer := map[string]string{}
+	err = client.GetParametersByPathPages(input, func(page *ssm.GetParametersByPathOutput, lastPage bool) bool {
+		for _, p := range page.Parameters {
+			name := strings.TrimPrefix(strings.TrimPrefix(aws.StringValue(p.Name), path), "/")
+			answer[name] = aws.StringValue(p.Value)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get the AWS parameters of path %s", path)
+	}
+	if len(answer) == 0 {
+		return nil, fmt.Errorf("no AWS parameters found in path %s of region %s", path, region)
+	}
+	return answer, nil
+}
+
+func (r *Resolver) resolveSecretManager(source *config.EnvFromSource) (map[string]string, error) {
+	value, err := r.SecretManager(source.Project, source.GCPSecretManager)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to access the GCP secret %s", source.GCPSecretManager)
+	}
+	if source.Name != "" {
+		return map[string]string{source.Name: value}, nil
+	}
+	data := map[string]interface{}{}
+	err = json.Unmarshal([]byte(value), &data)
+	if err != nil {
+		return nil, fmt.Errorf("the GCP secret %s is not a JSON object so specify the name of its environment variable", source.GCPSecretManager)
+	}
+	answer := map[string]string{}
+	for k, v := range data {
+		answer[k], err = stringValue(v)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to convert the value of %s in GCP secret %s", k, source.GCPSecretManager)
+		}
+	}
+	return answer, nil
+}
+
+// stringValue returns the value as a string marshalling structured values to JSON
+func stringValue(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case nil:
+		return "", nil
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return "", err
+		}
+		return string(data), nil
+	}
+}
+
+// parameterStore creates an AWS Systems Manager client of the region
+func parameterStore(region string) (ssmiface.SSMAPI, error) {
+	sess, err := session.NewAwsSession("", region)
+	if err != nil {
+		return nil, err
+	}
+	return ssm.New(sess), nil
+}
+
+// secretManager accesses the latest version of the secret using gcloud
+func secretManager(project string, secret string) (string, error) {
+	args := []string{"secrets", "versions", "access", "latest", "--secret", secret}
+	if project != "" {
+		args = append(args, "--project", project)
+	}
+	cmd := util.Command{
+		Name: "gcloud",
+		Args: args,
+	}
+	return cmd.RunWithoutRetry()
+}
+
+// EnvVarName converts the name into a valid environment variable name such as converting db/password into DB_PASSWORD
+func EnvVarName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if r > unicode.MaxASCII || !(unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			return '_'
+		}
+		return unicode.ToUpper(r)
+	}, name)
+	if name != "" && unicode.IsDigit(rune(name[0])) {
+		name = "_" + name
+	}
+	return name
+}
+
+// SecretName returns the name of the secret containing the resolved environment variables of the pipelines of the
+// resource
+func SecretName(resourceName string) string {
+	return resourceName + secretSuffix
+}
+
+// Secret creates the secret containing the resolved environment variables
+func Secret(name string, values map[string]string, labels map[string]string) *corev1.Secret {
+	data := map[string][]byte{}
+	for k, v := range values {
+		data[k] = []byte(v)
+	}
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: labels,
+		},
+		Data: data,
+	}
+}
+
+// EnvVars returns the environment variables referencing the values in the secret sorted by name
+func EnvVars(secretName string, values map[string]string) []corev1.EnvVar {
+	names := []string{}
+	for k := range values {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	answer := []corev1.EnvVar{}
+	for _, name := range names {
+		answer = append(answer, corev1.EnvVar{
+			Name: name,
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{
+						Name: secretName,
+					},
+					Key: name,
+				},
+			},
+		})
+	}
+	return answer
+}
@@ -0,0 +1,97 @@
+package envfrom_test
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/aws/aws-sdk-go/service/ssm/ssmiface"
+	"github.com/jenkins-x/jx/pkg/config"
+	"github.com/jenkins-x/jx/pkg/envfrom"
+	"github.com/jenkins-x/jx/pkg/vault"
+	"github.com/jenkins-x/jx/pkg/vault/fake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeParameterStore returns the parameters in pages of one parameter
+type fakeParameterStore struct {
+	ssmiface.SSMAPI
+	parameters map[string]string
+	path       string
+}
+
+func (f *fakeParameterStore) GetParametersByPathPages(input *ssm.GetParametersByPathInput, fn func(*ssm.GetParametersByPathOutput, bool) bool) error {
+	f.path = aws.StringValue(input.Path)
+	for name, value := range f.parameters {
+		page := &ssm.GetParametersByPathOutput{
+			Parameters: []*ssm.Parameter{{Name: aws.String(name), Value: aws.String(value)}},
+		}
+		if !fn(page, false) {
+			break
+		}
+	}
+	return nil
+}
+
+func TestResolveEnvFrom(t *testing.T) {
+	t.Parallel()
+
+	vaultClient := fake.NewFakeVaultClient()
+	_, err := vaultClient.Write("myapp", map[string]interface{}{"DB_USER": "admin", "retries": 3})
+	require.NoError(t, err)
+	parameters := &fakeParameterStore{parameters: map[string]string{"/myapp/prod/db/password": "s3cr3t"}}
+
+	resolver := &envfrom.Resolver{
+		Vault: func() (vault.Client, error) {
+			return vaultClient, nil
+		},
+		ParameterStore: func(region string) (ssmiface.SSMAPI, error) {
+			assert.Equal(t, "eu-west-1", region)
+			return parameters, nil
+		},
+		SecretManager: func(project string, secret string) (string, error) {
+			if secret == "api-token" {
+				return "abc", nil
+			}
+			return `{"sentry-dsn": "https://sentry"}`, nil
+		},
+		Region: "eu-west-1",
+	}
+	values, err := resolver.Resolve([]config.EnvFromSource{
+		{Vault: "secret/data/myapp"},
+		{AWSParameterStore: "myapp/prod/"},
+		{GCPSecretManager: "api-token", Name: "API_TOKEN"},
+		{GCPSecretManager: "monitoring", Prefix: "app_"},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "/myapp/prod", parameters.path)
+	assert.Equal(t, map[string]string{
+		"DB_USER":        "admin",
+		"RETRIES":        "3",
+		"DB_PASSWORD":    "s3cr3t",
+		"API_TOKEN":      "abc",
+		"APP_SENTRY_DSN": "https://sentry",
+	}, values)
+
+	_, err = resolver.Resolve([]config.EnvFromSource{{Name: "FOO"}})
+	assert.Error(t, err, "a source without a store is invalid")
+}
+
+func TestEnvVars(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "DB_PASSWORD", envfrom.EnvVarName("db/password"))
+	assert.Equal(t, "_1_TOKEN", envfrom.EnvVarName("1-token"))
+
+	name := envfrom.SecretName("acme-myapp-master")
+	env := envfrom.EnvVars(name, map[string]string{"B": "2", "A": "1"})
+	require.Len(t, env, 2)
+	assert.Equal(t, "A", env[0].Name)
+	assert.Equal(t, "acme-myapp-master-env", env[0].ValueFrom.SecretKeyRef.Name)
+	assert.Equal(t, "A", env[0].ValueFrom.SecretKeyRef.Key)
+
+	secret := envfrom.Secret(name, map[string]string{"A": "1"}, nil)
+	assert.Equal(t, []byte("1"), secret.Data["A"])
+}
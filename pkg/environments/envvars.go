@@ -0,0 +1,108 @@
+package environments
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/jenkins-x/jx/pkg/helm"
+	"github.com/pkg/errors"
+	"github.com/xeipuuv/gojsonschema"
+)
+
+const (
+	// AppEnvVarsKey the key of the environment variables in the values of the application charts
+	AppEnvVarsKey = "env"
+)
+
+var envVarNameRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// ParseEnvVars parses the environment variables given as KEY=value expressions
+func ParseEnvVars(expressions []string) (map[string]string, error) {
+	answer := map[string]string{}
+	for _, expression := range expressions {
+		idx := strings.Index(expression, "=")
+		if idx <= 0 {
+			return nil, fmt.Errorf("invalid environment variable %q which should be of the form KEY=value", expression)
+		}
+		name := expression[0:idx]
+		err := ValidateEnvVarName(name)
+		if err != nil {
+			return nil, err
+		}
+		answer[name] = expression[idx+1:]
+	}
+	return answer, nil
+}
+
+// ValidateEnvVarName returns an error if the name is not a valid environment variable name
+func ValidateEnvVarName(name string) error {
+	if !envVarNameRegex.MatchString(name) {
+		return fmt.Errorf("invalid environment variable name %q", name)
+	}
+	return nil
+}
+
+// FindAppDependency returns the dependency of the application in the requirements of an environment matching either
+// the alias or the name of the application
+func FindAppDependency(requirements *helm.Requirements, app string) *helm.Dependency {
+	for _, dep := range requirements.Dependencies {
+		if dep != nil && dep.Alias == app {
+			return dep
+		}
+	}
+	for _, dep := range requirements.Dependencies {
+		if dep != nil && dep.Name == app && dep.Alias == "" {
+			return dep
+		}
+	}
+	return nil
+}
+
+// ModifyAppEnvVars sets and removes the environment variables of the application in the values of an environment.
+// The values of the application are stored under the key of the application which is its alias or name. Returns the
+// values of the application
+func ModifyAppEnvVars(values map[string]interface{}, key string, set map[string]string, unset []string) map[string]interface{} {
+	appValues, ok := values[key].(map[string]interface{})
+	if !ok || appValues == nil {
+		appValues = map[string]interface{}{}
+	}
+	envVars, ok := appValues[AppEnvVarsKey].(map[string]interface{})
+	if !ok || envVars == nil {
+		envVars = map[string]interface{}{}
+	}
+	for name, value := range set {
+		envVars[name] = value
+	}
+	for _, name := range unset {
+		delete(envVars, name)
+	}
+	if len(envVars) > 0 {
+		appValues[AppEnvVarsKey] = envVars
+	} else {
+		delete(appValues, AppEnvVarsKey)
+	}
+	values[key] = appValues
+	return appValues
+}
+
+// ValidateValuesSchema validates the values against the JSON schema of a chart such as its values.schema.json file
+func ValidateValuesSchema(schema []byte, values map[string]interface{}) error {
+	data, err := json.Marshal(values)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal the values to JSON")
+	}
+	result, err := gojsonschema.Validate(gojsonschema.NewBytesLoader(schema), gojsonschema.NewBytesLoader(data))
+	if err != nil {
+		return errors.Wrap(err, "validating the JSON schema against the values")
+	}
+	if result.Valid() {
+		return nil
+	}
+	messages := []string{}
+	for _, e := range result.Errors() {
+		messages = append(messages, e.String())
+	}
+	return fmt.Errorf("invalid values: %s", strings.Join(messages, ", "))
+}
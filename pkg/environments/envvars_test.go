@@ -0,0 +1,93 @@
+package environments_test
+
+import (
+	"testing"
+
+	"github.com/jenkins-x/jx/pkg/environments"
+	"github.com/jenkins-x/jx/pkg/helm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseEnvVars(t *testing.T) {
+	t.Parallel()
+
+	envVars, err := environments.ParseEnvVars([]string{"LOG_LEVEL=debug", "JAVA_OPTS=-Xmx1g -Dfoo=bar", "EMPTY="})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"LOG_LEVEL": "debug",
+		"JAVA_OPTS": "-Xmx1g -Dfoo=bar",
+		"EMPTY":     "",
+	}, envVars)
+
+	for _, invalid := range []string{"NOVALUE", "=value", "1ABC=value", "MY-VAR=value"} {
+		_, err = environments.ParseEnvVars([]string{invalid})
+		assert.Error(t, err, "%s should be invalid", invalid)
+	}
+}
+
+func TestModifyAppEnvVars(t *testing.T) {
+	t.Parallel()
+
+	values := map[string]interface{}{
+		"myapp": map[string]interface{}{
+			"replicaCount": 2,
+			"env": map[string]interface{}{
+				"OLD":       "value",
+				"LOG_LEVEL": "info",
+			},
+		},
+	}
+	appValues := environments.ModifyAppEnvVars(values, "myapp", map[string]string{"LOG_LEVEL": "debug", "DB_PASSWORD": "vault:environments/staging/myapp:DB_PASSWORD"}, []string{"OLD"})
+	assert.Equal(t, 2, appValues["replicaCount"])
+	assert.Equal(t, map[string]interface{}{
+		"LOG_LEVEL":   "debug",
+		"DB_PASSWORD": "vault:environments/staging/myapp:DB_PASSWORD",
+	}, appValues["env"])
+
+	appValues = environments.ModifyAppEnvVars(values, "other", map[string]string{"A": "b"}, nil)
+	assert.Equal(t, map[string]interface{}{"env": map[string]interface{}{"A": "b"}}, values["other"])
+
+	environments.ModifyAppEnvVars(values, "other", nil, []string{"A"})
+	assert.Equal(t, map[string]interface{}{}, values["other"], "the env values should be removed once empty")
+}
+
+func TestFindAppDependency(t *testing.T) {
+	t.Parallel()
+
+	requirements := &helm.Requirements{
+		Dependencies: []*helm.Dependency{
+			{Name: "myapp", Version: "1.0.0"},
+			{Name: "postgresql", Alias: "mydb", Version: "2.0.0"},
+		},
+	}
+	require.NotNil(t, environments.FindAppDependency(requirements, "myapp"))
+	dep := environments.FindAppDependency(requirements, "mydb")
+	require.NotNil(t, dep)
+	assert.Equal(t, "postgresql", dep.Name)
+	assert.Nil(t, environments.FindAppDependency(requirements, "postgresql"))
+}
+
+func TestValidateValuesSchema(t *testing.T) {
+	t.Parallel()
+
+	schema := []byte(`{
+  "type": "object",
+  "properties": {
+    "env": {
+      "type": "object",
+      "properties": {
+        "PORT": {"type": "string", "pattern": "^[0-9]+$"}
+      }
+    }
+  }
+}`)
+	assert.NoError(t, environments.ValidateValuesSchema(schema, map[string]interface{}{
+		"env": map[string]interface{}{"PORT": "8080"},
+	}))
+	err := environments.ValidateValuesSchema(schema, map[string]interface{}{
+		"env": map[string]interface{}{"PORT": "http"},
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "PORT")
+}
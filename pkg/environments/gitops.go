@@ -590,3 +590,30 @@ func LocateAppResource(helmer helm.Helmer, chartDir string, appName string) (*je
 
 	return app, filename, nil
 }
+
+// LoadEnvironmentRequirements returns the requirements of the chart in the git repository of the environment by
+// shallow cloning the repository
+func LoadEnvironmentRequirements(gitter gits.Gitter, env *jenkinsv1.Environment) (*helm.Requirements, error) {
+	dir, err := ioutil.TempDir("", "jx-env-requirements-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+	ref := env.Spec.Source.Ref
+	if ref == "" {
+		ref = "master"
+	}
+	err = gitter.ShallowClone(dir, env.Spec.Source.URL, ref, "")
+	if err != nil {
+		return nil, errors.Wrapf(err, "cloning the git repository %s of environment %s", env.Spec.Source.URL, env.Name)
+	}
+	fileName, err := helm.FindRequirementsFileName(dir)
+	if err != nil {
+		return nil, err
+	}
+	requirements, err := helm.LoadRequirementsFile(fileName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "loading the requirements of environment %s", env.Name)
+	}
+	return requirements, nil
+}
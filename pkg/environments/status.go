@@ -0,0 +1,125 @@
+package environments
+
+import (
+	"fmt"
+	"time"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/client/clientset/versioned"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// EventReasonApplyStarted the reason of the events recorded when the apply of an environment starts
+	EventReasonApplyStarted = "ApplyStarted"
+	// EventReasonApplySucceeded the reason of the events recorded when the apply of an environment succeeds
+	EventReasonApplySucceeded = "ApplySucceeded"
+	// EventReasonApplyFailed the reason of the events recorded when the apply of an environment fails
+	EventReasonApplyFailed = "ApplyFailed"
+	// EventReasonDrifted the reason of the events recorded when applications do not run the versions of the git
+	// repository of an environment after it is applied
+	EventReasonDrifted = "Drifted"
+
+	eventSource = "environment-controller"
+)
+
+// StartApply updates the status of the environment when the apply of the commit starts
+func StartApply(env *v1.Environment, commit string, now time.Time) *corev1.Event {
+	t := metav1.NewTime(now)
+	status := &env.Status
+	status.SyncStatus = v1.EnvironmentSyncStatusApplying
+	status.LastAttemptedCommit = commit
+	status.LastAttemptedTime = &t
+	status.Message = ""
+	return NewEvent(env, corev1.EventTypeNormal, EventReasonApplyStarted, fmt.Sprintf("applying commit %s", shortCommit(commit)), now)
+}
+
+// ApplyFailed updates the status of the environment when the apply of the last attempted commit fails
+func ApplyFailed(env *v1.Environment, message string, now time.Time) *corev1.Event {
+	status := &env.Status
+	status.SyncStatus = v1.EnvironmentSyncStatusFailed
+	status.Message = message
+	return NewEvent(env, corev1.EventTypeWarning, EventReasonApplyFailed,
+		fmt.Sprintf("failed to apply commit %s: %s", shortCommit(status.LastAttemptedCommit), message), now)
+}
+
+// ApplySucceeded updates the status of the environment when the apply of the last attempted commit succeeds with the
+// number of applications which do not run the versions of the git repository of the environment
+func ApplySucceeded(env *v1.Environment, drifted int, now time.Time) *corev1.Event {
+	t := metav1.NewTime(now)
+	status := &env.Status
+	status.LastAppliedCommit = status.LastAttemptedCommit
+	status.LastAppliedTime = &t
+	status.Message = ""
+	status.DriftedResources = drifted
+	if drifted > 0 {
+		status.SyncStatus = v1.EnvironmentSyncStatusDrifted
+		return NewEvent(env, corev1.EventTypeWarning, EventReasonDrifted,
+			fmt.Sprintf("applied commit %s but %d applications do not run the versions of the git repository",
+				shortCommit(status.LastAppliedCommit), drifted), now)
+	}
+	status.SyncStatus = v1.EnvironmentSyncStatusSynced
+	return NewEvent(env, corev1.EventTypeNormal, EventReasonApplySucceeded, fmt.Sprintf("applied commit %s", shortCommit(status.LastAppliedCommit)), now)
+}
+
+// NewEvent creates a Kubernetes event about the environment named like the events of the Kubernetes event recorder
+func NewEvent(env *v1.Environment, eventType string, reason string, message string, now time.Time) *corev1.Event {
+	t := metav1.NewTime(now)
+	return &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s.%x", env.Name, now.UnixNano()),
+			Namespace: env.Namespace,
+		},
+		InvolvedObject: corev1.ObjectReference{
+			APIVersion:      v1.SchemeGroupVersion.String(),
+			Kind:            "Environment",
+			Name:            env.Name,
+			Namespace:       env.Namespace,
+			UID:             env.UID,
+			ResourceVersion: env.ResourceVersion,
+		},
+		Type:           eventType,
+		Reason:         reason,
+		Message:        message,
+		Source:         corev1.EventSource{Component: eventSource},
+		FirstTimestamp: t,
+		LastTimestamp:  t,
+		Count:          1,
+	}
+}
+
+// UpdateStatus modifies the status of the environment retrying on conflicts then records the event returned by the
+// callback, if any
+func UpdateStatus(kubeClient kubernetes.Interface, jxClient versioned.Interface, ns string, name string, callback func(env *v1.Environment) *corev1.Event) error {
+	var event *corev1.Event
+	err := util.Retry(time.Second*20, func() error {
+		env, err := jxClient.JenkinsV1().Environments(ns).Get(name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		event = callback(env)
+		_, err = jxClient.JenkinsV1().Environments(ns).Update(env)
+		return err
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to update the status of environment %s in namespace %s", name, ns)
+	}
+	if event != nil {
+		_, err = kubeClient.CoreV1().Events(ns).Create(event)
+		if err != nil {
+			return errors.Wrapf(err, "failed to create the %s event of environment %s in namespace %s", event.Reason, name, ns)
+		}
+	}
+	return nil
+}
+
+func shortCommit(commit string) string {
+	if len(commit) > 7 {
+		return commit[:7]
+	}
+	return commit
+}
@@ -0,0 +1,71 @@
+package environments_test
+
+import (
+	"testing"
+	"time"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	jxfake "github.com/jenkins-x/jx/pkg/client/clientset/versioned/fake"
+	"github.com/jenkins-x/jx/pkg/environments"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestApplyStatus(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2019, 11, 4, 10, 0, 0, 0, time.UTC)
+	env := &v1.Environment{ObjectMeta: metav1.ObjectMeta{Name: "staging", Namespace: "jx"}}
+
+	event := environments.StartApply(env, "0123456789abcdef", now)
+	assert.Equal(t, v1.EnvironmentSyncStatusApplying, env.Status.SyncStatus)
+	assert.Equal(t, "0123456789abcdef", env.Status.LastAttemptedCommit)
+	require.NotNil(t, env.Status.LastAttemptedTime)
+	assert.Equal(t, environments.EventReasonApplyStarted, event.Reason)
+	assert.Equal(t, "applying commit 0123456", event.Message)
+	assert.Equal(t, "staging", event.InvolvedObject.Name)
+
+	event = environments.ApplyFailed(env, "helm upgrade failed", now)
+	assert.Equal(t, v1.EnvironmentSyncStatusFailed, env.Status.SyncStatus)
+	assert.Equal(t, "helm upgrade failed", env.Status.Message)
+	assert.Equal(t, "", env.Status.LastAppliedCommit)
+	assert.Equal(t, corev1.EventTypeWarning, event.Type)
+
+	environments.StartApply(env, "fedcba9876543210", now)
+	event = environments.ApplySucceeded(env, 0, now)
+	assert.Equal(t, v1.EnvironmentSyncStatusSynced, env.Status.SyncStatus)
+	assert.Equal(t, "fedcba9876543210", env.Status.LastAppliedCommit)
+	assert.Equal(t, "", env.Status.Message)
+	assert.Equal(t, environments.EventReasonApplySucceeded, event.Reason)
+
+	event = environments.ApplySucceeded(env, 2, now)
+	assert.Equal(t, v1.EnvironmentSyncStatusDrifted, env.Status.SyncStatus)
+	assert.Equal(t, 2, env.Status.DriftedResources)
+	assert.Equal(t, environments.EventReasonDrifted, event.Reason)
+}
+
+func TestUpdateStatus(t *testing.T) {
+	t.Parallel()
+
+	ns := "jx"
+	env := &v1.Environment{ObjectMeta: metav1.ObjectMeta{Name: "staging", Namespace: ns}}
+	jxClient := jxfake.NewSimpleClientset(env)
+	kubeClient := fake.NewSimpleClientset()
+
+	err := environments.UpdateStatus(kubeClient, jxClient, ns, "staging", func(env *v1.Environment) *corev1.Event {
+		return environments.StartApply(env, "0123456789abcdef", time.Now())
+	})
+	require.NoError(t, err)
+
+	updated, err := jxClient.JenkinsV1().Environments(ns).Get("staging", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, v1.EnvironmentSyncStatusApplying, updated.Status.SyncStatus)
+
+	events, err := kubeClient.CoreV1().Events(ns).List(metav1.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, events.Items, 1)
+	assert.Equal(t, environments.EventReasonApplyStarted, events.Items[0].Reason)
+}
@@ -0,0 +1,106 @@
+package environments
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/pkg/errors"
+)
+
+const minutesPerWeek = 7 * 24 * 60
+
+// CheckPromotionWindow returns the reason promotions are blocked at the given time by the promotion windows of an
+// environment or an empty string if promotions are allowed. An error is returned if the windows are invalid
+func CheckPromotionWindow(settings *v1.PromotionWindowSettings, now time.Time) (string, error) {
+	if settings == nil {
+		return "", nil
+	}
+	location := time.UTC
+	if settings.TimeZone != "" {
+		var err error
+		location, err = time.LoadLocation(settings.TimeZone)
+		if err != nil {
+			return "", errors.Wrapf(err, "loading the time zone %s of the promotion windows", settings.TimeZone)
+		}
+	}
+	now = now.In(location)
+	for _, w := range settings.Freezes {
+		inside, err := insideWindow(w, now)
+		if err != nil {
+			return "", err
+		}
+		if inside {
+			return fmt.Sprintf("promotions are frozen during %s", describeWindow(w)), nil
+		}
+	}
+	if len(settings.Allowed) == 0 {
+		return "", nil
+	}
+	var names []string
+	for _, w := range settings.Allowed {
+		inside, err := insideWindow(w, now)
+		if err != nil {
+			return "", err
+		}
+		if inside {
+			return "", nil
+		}
+		names = append(names, describeWindow(w))
+	}
+	return fmt.Sprintf("promotions are only allowed during %s", strings.Join(names, ", ")), nil
+}
+
+// insideWindow returns true if the time is inside the window
+func insideWindow(w v1.PromotionWindow, now time.Time) (bool, error) {
+	start, startErr := time.Parse(time.RFC3339, w.Start)
+	end, endErr := time.Parse(time.RFC3339, w.End)
+	if startErr == nil && endErr == nil {
+		return !now.Before(start) && now.Before(end), nil
+	}
+	weeklyStart, err := parseWeeklyTime(w.Start)
+	if err != nil {
+		return false, errors.Wrapf(err, "invalid start of promotion window %s", describeWindow(w))
+	}
+	weeklyEnd, err := parseWeeklyTime(w.End)
+	if err != nil {
+		return false, errors.Wrapf(err, "invalid end of promotion window %s", describeWindow(w))
+	}
+	minute := int(now.Weekday())*24*60 + now.Hour()*60 + now.Minute()
+	if weeklyStart <= weeklyEnd {
+		return minute >= weeklyStart && minute < weeklyEnd, nil
+	}
+	// the window wraps around the end of the week such as from Friday to Monday
+	return minute >= weeklyStart || minute < weeklyEnd, nil
+}
+
+// parseWeeklyTime parses a time of the week such as 'Fri 16:00' returning the minutes since the start of Sunday
+func parseWeeklyTime(text string) (int, error) {
+	fields := strings.Fields(text)
+	if len(fields) != 2 || len(fields[0]) < 3 {
+		return 0, fmt.Errorf("%q should be of the form 'Mon 09:00' or an RFC 3339 timestamp", text)
+	}
+	day := -1
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		if strings.HasPrefix(strings.ToLower(d.String()), strings.ToLower(fields[0])) {
+			day = int(d)
+			break
+		}
+	}
+	if day < 0 {
+		return 0, fmt.Errorf("%q does not start with a day of the week", text)
+	}
+	t, err := time.Parse("15:04", fields[1])
+	if err != nil {
+		return 0, fmt.Errorf("%q does not end with a time of the day such as 09:00", text)
+	}
+	return (day*24*60 + t.Hour()*60 + t.Minute()) % minutesPerWeek, nil
+}
+
+func describeWindow(w v1.PromotionWindow) string {
+	if w.Name != "" {
+		return fmt.Sprintf("%s (%s - %s)", w.Name, w.Start, w.End)
+	}
+	return fmt.Sprintf("%s - %s", w.Start, w.End)
+}
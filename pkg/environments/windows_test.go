@@ -0,0 +1,81 @@
+package environments_test
+
+import (
+	"testing"
+	"time"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/environments"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckPromotionWindowFreezes(t *testing.T) {
+	t.Parallel()
+
+	settings := &v1.PromotionWindowSettings{
+		Freezes: []v1.PromotionWindow{
+			{Name: "weekend", Start: "Fri 16:00", End: "Mon 09:00"},
+			{Name: "end of year", Start: "2019-12-20T18:00:00Z", End: "2020-01-06T09:00:00Z"},
+		},
+	}
+	testCases := map[string]bool{
+		"2019-10-16T12:00:00Z": false, // Wednesday
+		"2019-10-18T15:59:00Z": false, // Friday before the freeze
+		"2019-10-18T16:00:00Z": true,  // Friday at the start of the freeze
+		"2019-10-20T12:00:00Z": true,  // Sunday
+		"2019-10-21T08:59:00Z": true,  // Monday before the end of the freeze
+		"2019-10-21T09:00:00Z": false, // Monday at the end of the freeze
+		"2019-12-24T12:00:00Z": true,  // Tuesday during the end of year freeze
+	}
+	for text, frozen := range testCases {
+		now, err := time.Parse(time.RFC3339, text)
+		require.NoError(t, err)
+		reason, err := environments.CheckPromotionWindow(settings, now)
+		require.NoError(t, err)
+		if frozen {
+			assert.Contains(t, reason, "frozen", "promotions should be frozen at %s", text)
+		} else {
+			assert.Empty(t, reason, "promotions should be allowed at %s", text)
+		}
+	}
+}
+
+func TestCheckPromotionWindowAllowed(t *testing.T) {
+	t.Parallel()
+
+	settings := &v1.PromotionWindowSettings{
+		TimeZone: "America/New_York",
+		Allowed: []v1.PromotionWindow{
+			{Name: "release train", Start: "Tue 10:00", End: "Tue 12:00"},
+		},
+	}
+	now, err := time.Parse(time.RFC3339, "2019-10-15T15:00:00Z") // Tuesday 11:00 in New York
+	require.NoError(t, err)
+	reason, err := environments.CheckPromotionWindow(settings, now)
+	require.NoError(t, err)
+	assert.Empty(t, reason)
+
+	now, err = time.Parse(time.RFC3339, "2019-10-15T11:00:00Z") // Tuesday 07:00 in New York
+	require.NoError(t, err)
+	reason, err = environments.CheckPromotionWindow(settings, now)
+	require.NoError(t, err)
+	assert.Contains(t, reason, "release train")
+}
+
+func TestCheckPromotionWindowInvalid(t *testing.T) {
+	t.Parallel()
+
+	reason, err := environments.CheckPromotionWindow(nil, time.Now())
+	require.NoError(t, err)
+	assert.Empty(t, reason)
+
+	for _, w := range []v1.PromotionWindow{
+		{Start: "Someday 16:00", End: "Mon 09:00"},
+		{Start: "Fri 25:00", End: "Mon 09:00"},
+		{Start: "Fri", End: "Mon 09:00"},
+	} {
+		_, err = environments.CheckPromotionWindow(&v1.PromotionWindowSettings{Freezes: []v1.PromotionWindow{w}}, time.Now())
+		assert.Error(t, err, "window %s - %s should be invalid", w.Start, w.End)
+	}
+}
@@ -0,0 +1,142 @@
+package eventbus
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jenkins-x/jx/pkg/audit"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// KindKafka distributes the events via the topics of a Kafka REST proxy
+	KindKafka = "kafka"
+
+	// TopicPipelineRequests the topic of the pipeline run requests of the webhooks received by the pipeline runner
+	TopicPipelineRequests = "jx-pipeline-requests"
+	// TopicNotifications the topic of the notifications of the PipelineActivities
+	TopicNotifications = "jx-notifications"
+
+	// GroupPipelineScheduler the consumer group of the pipeline runners which start the requested pipelines
+	GroupPipelineScheduler = "jx-pipeline-scheduler"
+	// GroupNotifications the consumer group of the build controllers which dispatch the notifications
+	GroupNotifications = "jx-notifications"
+
+	// EnvVarKind the environment variable of the kind of the event bus if the flag is not specified
+	EnvVarKind = "JX_EVENT_BUS"
+	// EnvVarURL the environment variable of the URL of the event bus if the flag is not specified
+	EnvVarURL = "JX_EVENT_BUS_URL"
+)
+
+// Kinds the kinds of the event bus
+var Kinds = []string{KindKafka}
+
+// Message a message received from the bus
+type Message struct {
+	Topic     string
+	Key       string
+	Partition int
+	Offset    int64
+	// ID the unique ID of the message which consumers can use to ignore messages they have already processed
+	ID string
+	// Time the time the message was published
+	Time time.Time
+	// Data the JSON data of the message
+	Data json.RawMessage
+}
+
+// Unmarshal unmarshals the data of the message
+func (m *Message) Unmarshal(value interface{}) error {
+	err := json.Unmarshal(m.Data, value)
+	if err != nil {
+		return errors.Wrapf(err, "failed to unmarshal message %s of topic %s", m.ID, m.Topic)
+	}
+	return nil
+}
+
+// Handler processes a message. Messages whose handler fails are retried
+type Handler func(message *Message) error
+
+// Bus distributes the events between the components so that they can be scaled horizontally and the events replayed
+type Bus interface {
+	// Publish publishes the value as JSON to the topic. Messages with the same key are received in order
+	Publish(topic string, key string, value interface{}) error
+	// Subscribe processes the messages of the topic in the background. Each message is processed by one member of
+	// the consumer group
+	Subscribe(topic string, group string, handler Handler) error
+	// Close stops the subscriptions
+	Close() error
+}
+
+// envelope the message published to the bus
+type envelope struct {
+	ID   string          `json:"id"`
+	Time time.Time       `json:"time"`
+	Data json.RawMessage `json:"data"`
+}
+
+// Options the command line options of the event bus of a component
+type Options struct {
+	Kind        string
+	URL         string
+	ReplaySince string
+}
+
+// AddFlags adds the flags of the event bus to the command
+func (o *Options) AddFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&o.Kind, "event-bus", "", "", "The event bus the events are distributed via instead of being handled in process: "+strings.Join(Kinds, ", ")+". Defaults to $"+EnvVarKind)
+	cmd.Flags().StringVarP(&o.URL, "event-bus-url", "", "", "The URL of the event bus such as the URL of the Kafka REST proxy. Defaults to $"+EnvVarURL)
+	cmd.Flags().StringVarP(&o.ReplaySince, "event-bus-replay-since", "", "", "Replays the events published since the duration such as 2h or 1d to the subscriptions of this replica when it starts")
+}
+
+// NewBus creates the event bus. Returns nil if no event bus is configured so the events are handled in process
+func (o *Options) NewBus() (Bus, error) {
+	kind := o.Kind
+	if kind == "" {
+		kind = os.Getenv(EnvVarKind)
+	}
+	u := o.URL
+	if u == "" {
+		u = os.Getenv(EnvVarURL)
+	}
+	if kind == "" {
+		return nil, nil
+	}
+	replaySince := time.Time{}
+	if o.ReplaySince != "" {
+		duration, err := audit.ParseSince(o.ReplaySince)
+		if err != nil {
+			return nil, util.InvalidOptionError("event-bus-replay-since", o.ReplaySince, err)
+		}
+		replaySince = time.Now().Add(-duration)
+	}
+	switch kind {
+	case KindKafka:
+		if u == "" {
+			return nil, util.MissingOption("event-bus-url")
+		}
+		bus := NewKafkaBus(u, nil)
+		bus.ReplaySince = replaySince
+		return bus, nil
+	default:
+		return nil, util.InvalidOption("event-bus", kind, Kinds)
+	}
+}
+
+// newEnvelope wraps the value in an envelope with a unique ID and the current time
+func newEnvelope(topic string, value interface{}) (*envelope, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to marshal the message of topic %s", topic)
+	}
+	return &envelope{
+		ID:   uuid.New().String(),
+		Time: time.Now().UTC(),
+		Data: data,
+	}, nil
+}
@@ -0,0 +1,360 @@
+package eventbus
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+)
+
+const (
+	kafkaJSONContentType = "application/vnd.kafka.json.v2+json"
+	kafkaContentType     = "application/vnd.kafka.v2+json"
+)
+
+// KafkaBus distributes the events via the topics of a Kafka REST proxy. The messages are consumed by consumer groups
+// which commit the offsets of the messages once they are processed so that a message is processed again by another
+// replica if a replica stops before processing it
+type KafkaBus struct {
+	// URL the URL of the Kafka REST proxy
+	URL    string
+	Client *http.Client
+	// ReplaySince replays the messages published since the time to the subscriptions when they start. Disabled if zero
+	ReplaySince time.Time
+	// PollInterval the time to wait before polling again when there are no messages or a handler failed
+	PollInterval time.Duration
+
+	lock      sync.Mutex
+	consumers []*kafkaConsumer
+	stop      chan struct{}
+}
+
+type kafkaRecord struct {
+	Topic     string          `json:"topic,omitempty"`
+	Key       string          `json:"key,omitempty"`
+	Value     json.RawMessage `json:"value"`
+	Partition int             `json:"partition"`
+	Offset    int64           `json:"offset"`
+}
+
+type kafkaProducerRecord struct {
+	Key   string          `json:"key,omitempty"`
+	Value json.RawMessage `json:"value"`
+}
+
+type kafkaPartition struct {
+	Topic     string `json:"topic"`
+	Partition int    `json:"partition"`
+}
+
+type kafkaOffset struct {
+	Topic     string `json:"topic"`
+	Partition int    `json:"partition"`
+	Offset    int64  `json:"offset"`
+}
+
+// kafkaStatusError the error of a request the REST proxy rejected
+type kafkaStatusError struct {
+	URL        string
+	StatusCode int
+	Body       string
+}
+
+func (e *kafkaStatusError) Error() string {
+	return fmt.Sprintf("request to %s failed with status %d: %s", e.URL, e.StatusCode, e.Body)
+}
+
+// NewKafkaBus creates a bus using the Kafka REST proxy at the URL
+func NewKafkaBus(u string, client *http.Client) *KafkaBus {
+	if client == nil {
+		client = util.GetClientWithTimeout(30 * time.Second)
+	}
+	return &KafkaBus{
+		URL:          strings.TrimSuffix(u, "/"),
+		Client:       client,
+		PollInterval: time.Second,
+		stop:         make(chan struct{}),
+	}
+}
+
+// Publish publishes the value to the topic
+func (b *KafkaBus) Publish(topic string, key string, value interface{}) error {
+	env, err := newEnvelope(topic, value)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(env)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal the message of topic %s", topic)
+	}
+	payload := map[string]interface{}{
+		"records": []kafkaProducerRecord{
+			{
+				Key:   key,
+				Value: data,
+			},
+		},
+	}
+	err = b.do(http.MethodPost, util.UrlJoin(b.URL, "topics", topic), kafkaJSONContentType, payload, nil)
+	if err != nil {
+		return errors.Wrapf(err, "failed to publish message %s to topic %s", env.ID, topic)
+	}
+	return nil
+}
+
+// Subscribe creates a consumer of the group subscribed to the topic which processes the messages until the bus is
+// closed
+func (b *KafkaBus) Subscribe(topic string, group string, handler Handler) error {
+	c := &kafkaConsumer{
+		bus:     b,
+		topic:   topic,
+		group:   group,
+		handler: handler,
+		replay:  !b.ReplaySince.IsZero(),
+	}
+	err := c.create()
+	if err != nil {
+		return err
+	}
+	b.lock.Lock()
+	b.consumers = append(b.consumers, c)
+	b.lock.Unlock()
+	go c.run()
+	return nil
+}
+
+// Close stops the consumers and deletes their instances from the REST proxy
+func (b *KafkaBus) Close() error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	select {
+	case <-b.stop:
+		return nil
+	default:
+		close(b.stop)
+	}
+	errs := []error{}
+	for _, c := range b.consumers {
+		errs = append(errs, b.do(http.MethodDelete, c.baseURI, kafkaContentType, nil, nil))
+	}
+	b.consumers = nil
+	return util.CombineErrors(errs...)
+}
+
+func (b *KafkaBus) do(method string, u string, contentType string, body interface{}, result interface{}) error {
+	var reader *bytes.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return errors.Wrapf(err, "failed to marshal the request to %s", u)
+		}
+		reader = bytes.NewReader(data)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+	req, err := http.NewRequest(method, u, reader)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create the request to %s", u)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if result != nil {
+		req.Header.Set("Accept", contentType)
+	}
+	resp, err := b.Client.Do(req)
+	if err != nil {
+		return errors.Wrapf(err, "failed to send the request to %s", u)
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read the response of %s", u)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return &kafkaStatusError{URL: u, StatusCode: resp.StatusCode, Body: strings.TrimSpace(string(data))}
+	}
+	if result != nil && len(data) > 0 {
+		err = json.Unmarshal(data, result)
+		if err != nil {
+			return errors.Wrapf(err, "failed to unmarshal the response of %s", u)
+		}
+	}
+	return nil
+}
+
+// kafkaConsumer a consumer instance of the REST proxy
+type kafkaConsumer struct {
+	bus     *KafkaBus
+	topic   string
+	group   string
+	handler Handler
+	baseURI string
+	replay  bool
+}
+
+// create creates the consumer instance and subscribes it to the topic
+func (c *kafkaConsumer) create() error {
+	b := c.bus
+	hostname, _ := os.Hostname()
+	if hostname == "" {
+		hostname = "jx"
+	}
+	request := map[string]string{
+		"name":               hostname + "-" + uuid.New().String()[:8],
+		"format":             "json",
+		"auto.offset.reset":  "earliest",
+		"auto.commit.enable": "false",
+	}
+	instance := struct {
+		InstanceID string `json:"instance_id"`
+		BaseURI    string `json:"base_uri"`
+	}{}
+	err := b.do(http.MethodPost, util.UrlJoin(b.URL, "consumers", c.group), kafkaContentType, request, &instance)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create a consumer of group %s", c.group)
+	}
+	b.lock.Lock()
+	c.baseURI = instance.BaseURI
+	b.lock.Unlock()
+	subscription := map[string][]string{
+		"topics": {c.topic},
+	}
+	err = b.do(http.MethodPost, c.baseURI+"/subscription", kafkaContentType, subscription, nil)
+	if err != nil {
+		return errors.Wrapf(err, "failed to subscribe the consumer of group %s to topic %s", c.group, c.topic)
+	}
+	return nil
+}
+
+func (c *kafkaConsumer) run() {
+	b := c.bus
+	for {
+		select {
+		case <-b.stop:
+			return
+		default:
+		}
+		processed, err := c.poll()
+		if err != nil {
+			log.Logger().Warnf("Failed to consume topic %s as group %s: %s", c.topic, c.group, err)
+			if statusErr, ok := errors.Cause(err).(*kafkaStatusError); ok && statusErr.StatusCode == http.StatusNotFound {
+				// the REST proxy has removed the consumer instance
+				err = c.create()
+				if err != nil {
+					log.Logger().Warnf("Failed to recreate the consumer of topic %s: %s", c.topic, err)
+				}
+			}
+		}
+		if err != nil || processed == 0 {
+			select {
+			case <-b.stop:
+				return
+			case <-time.After(b.PollInterval):
+			}
+		}
+	}
+}
+
+// poll fetches and processes the next messages returning the number of messages processed
+func (c *kafkaConsumer) poll() (int, error) {
+	b := c.bus
+	records := []kafkaRecord{}
+	err := b.do(http.MethodGet, c.baseURI+"/records", kafkaJSONContentType, nil, &records)
+	if err != nil {
+		return 0, err
+	}
+	if c.replay {
+		// the partitions are assigned by the first poll so the records fetched before seeking are fetched again
+		assignments := struct {
+			Partitions []kafkaPartition `json:"partitions"`
+		}{}
+		err = b.do(http.MethodGet, c.baseURI+"/assignments", kafkaContentType, nil, &assignments)
+		if err != nil {
+			return 0, err
+		}
+		if len(assignments.Partitions) == 0 {
+			return 0, nil
+		}
+		err = b.do(http.MethodPost, c.baseURI+"/positions/beginning", kafkaContentType, assignments, nil)
+		if err != nil {
+			return 0, err
+		}
+		log.Logger().Infof("Replaying the messages of topic %s published since %s", c.topic, b.ReplaySince.Format(time.RFC3339))
+		c.replay = false
+		return 0, nil
+	}
+
+	processed := map[int]kafkaOffset{}
+	unprocessed := map[int]kafkaOffset{}
+	var handlerErr error
+	for i := range records {
+		record := &records[i]
+		if handlerErr == nil {
+			handlerErr = c.process(record)
+			if handlerErr == nil {
+				processed[record.Partition] = kafkaOffset{Topic: c.topic, Partition: record.Partition, Offset: record.Offset}
+				continue
+			}
+			handlerErr = errors.Wrapf(handlerErr, "failed to process message %d of partition %d", record.Offset, record.Partition)
+		}
+		// the records of a partition are in order so the first unprocessed record is the one to fetch again
+		if _, ok := unprocessed[record.Partition]; !ok {
+			unprocessed[record.Partition] = kafkaOffset{Topic: c.topic, Partition: record.Partition, Offset: record.Offset}
+		}
+	}
+	if len(processed) > 0 {
+		err = b.do(http.MethodPost, c.baseURI+"/offsets", kafkaContentType, map[string][]kafkaOffset{"offsets": offsetList(processed)}, nil)
+		if err != nil {
+			return 0, errors.Wrapf(err, "failed to commit the offsets of topic %s", c.topic)
+		}
+	}
+	if handlerErr != nil {
+		err = b.do(http.MethodPost, c.baseURI+"/positions", kafkaContentType, map[string][]kafkaOffset{"offsets": offsetList(unprocessed)}, nil)
+		if err != nil {
+			return 0, errors.Wrapf(err, "failed to seek to the unprocessed messages of topic %s", c.topic)
+		}
+		return 0, handlerErr
+	}
+	return len(records), nil
+}
+
+// process passes the message of the record to the handler unless it was published before the messages replayed
+func (c *kafkaConsumer) process(record *kafkaRecord) error {
+	env := &envelope{}
+	err := json.Unmarshal(record.Value, env)
+	if err != nil {
+		log.Logger().Warnf("Ignoring invalid message %d of partition %d of topic %s: %s", record.Offset, record.Partition, c.topic, err)
+		return nil
+	}
+	if !c.bus.ReplaySince.IsZero() && env.Time.Before(c.bus.ReplaySince) {
+		return nil
+	}
+	return c.handler(&Message{
+		Topic:     c.topic,
+		Key:       record.Key,
+		Partition: record.Partition,
+		Offset:    record.Offset,
+		ID:        env.ID,
+		Time:      env.Time,
+		Data:      env.Data,
+	})
+}
+
+func offsetList(offsets map[int]kafkaOffset) []kafkaOffset {
+	answer := []kafkaOffset{}
+	for _, p := range offsets {
+		answer = append(answer, p)
+	}
+	return answer
+}
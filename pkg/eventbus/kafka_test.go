@@ -0,0 +1,227 @@
+package eventbus_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jenkins-x/jx/pkg/eventbus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeRecord struct {
+	Topic     string          `json:"topic"`
+	Key       string          `json:"key"`
+	Value     json.RawMessage `json:"value"`
+	Partition int             `json:"partition"`
+	Offset    int64           `json:"offset"`
+}
+
+type fakeConsumer struct {
+	group    string
+	topic    string
+	position int64
+}
+
+// fakeKafkaProxy a Kafka REST proxy with topics of a single partition
+type fakeKafkaProxy struct {
+	server    *httptest.Server
+	lock      sync.Mutex
+	topics    map[string][]fakeRecord
+	committed map[string]int64
+	consumers map[string]*fakeConsumer
+}
+
+func newFakeKafkaProxy() *fakeKafkaProxy {
+	p := &fakeKafkaProxy{
+		topics:    map[string][]fakeRecord{},
+		committed: map[string]int64{},
+		consumers: map[string]*fakeConsumer{},
+	}
+	p.server = httptest.NewServer(http.HandlerFunc(p.handle))
+	return p
+}
+
+func (p *fakeKafkaProxy) append(topic string, key string, value json.RawMessage) {
+	records := p.topics[topic]
+	p.topics[topic] = append(records, fakeRecord{Topic: topic, Key: key, Value: value, Offset: int64(len(records))})
+}
+
+func (p *fakeKafkaProxy) handle(w http.ResponseWriter, r *http.Request) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	paths := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	switch {
+	case len(paths) == 2 && paths[0] == "topics":
+		body := struct {
+			Records []fakeRecord `json:"records"`
+		}{}
+		if json.NewDecoder(r.Body).Decode(&body) != nil {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			return
+		}
+		for _, record := range body.Records {
+			p.append(paths[1], record.Key, record.Value)
+		}
+	case len(paths) == 2 && paths[0] == "consumers":
+		body := map[string]string{}
+		json.NewDecoder(r.Body).Decode(&body)
+		p.consumers[body["name"]] = &fakeConsumer{group: paths[1]}
+		json.NewEncoder(w).Encode(map[string]string{
+			"instance_id": body["name"],
+			"base_uri":    p.server.URL + "/consumers/" + paths[1] + "/instances/" + body["name"],
+		})
+	case len(paths) >= 4 && paths[0] == "consumers":
+		c := p.consumers[paths[3]]
+		if c == nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		p.handleConsumer(w, r, c, strings.Join(paths[4:], "/"))
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (p *fakeKafkaProxy) handleConsumer(w http.ResponseWriter, r *http.Request, c *fakeConsumer, path string) {
+	offsets := struct {
+		Offsets []fakeRecord `json:"offsets"`
+	}{}
+	switch path {
+	case "":
+		for name, consumer := range p.consumers {
+			if consumer == c {
+				delete(p.consumers, name)
+			}
+		}
+		w.WriteHeader(http.StatusNoContent)
+	case "subscription":
+		body := struct {
+			Topics []string `json:"topics"`
+		}{}
+		json.NewDecoder(r.Body).Decode(&body)
+		c.topic = body.Topics[0]
+		c.position = p.committed[c.group]
+		w.WriteHeader(http.StatusNoContent)
+	case "records":
+		records := p.topics[c.topic][c.position:]
+		c.position += int64(len(records))
+		json.NewEncoder(w).Encode(records)
+	case "assignments":
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"partitions": []map[string]interface{}{{"topic": c.topic, "partition": 0}},
+		})
+	case "positions/beginning":
+		c.position = 0
+		w.WriteHeader(http.StatusNoContent)
+	case "positions":
+		json.NewDecoder(r.Body).Decode(&offsets)
+		c.position = offsets.Offsets[0].Offset
+		w.WriteHeader(http.StatusNoContent)
+	case "offsets":
+		json.NewDecoder(r.Body).Decode(&offsets)
+		p.committed[c.group] = offsets.Offsets[0].Offset + 1
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+type request struct {
+	Repository string `json:"repository"`
+}
+
+func TestKafkaBus(t *testing.T) {
+	t.Parallel()
+	proxy := newFakeKafkaProxy()
+	defer proxy.server.Close()
+
+	bus := eventbus.NewKafkaBus(proxy.server.URL, proxy.server.Client())
+	bus.PollInterval = 10 * time.Millisecond
+	defer bus.Close()
+
+	require.NoError(t, bus.Publish("requests", "myorg/myapp", &request{Repository: "myapp"}))
+	require.NoError(t, bus.Publish("requests", "myorg/other", &request{Repository: "other"}))
+
+	received := make(chan string, 10)
+	failed := false
+	err := bus.Subscribe("requests", "scheduler", func(message *eventbus.Message) error {
+		value := &request{}
+		err := message.Unmarshal(value)
+		if err != nil {
+			return err
+		}
+		// the second message fails once so it is fetched again
+		if value.Repository == "other" && !failed {
+			failed = true
+			return errors.New("scheduler unavailable")
+		}
+		assert.NotEmpty(t, message.ID)
+		received <- message.Key + "=" + value.Repository
+		return nil
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "myorg/myapp=myapp", receive(t, received))
+	assert.Equal(t, "myorg/other=other", receive(t, received))
+
+	assert.Eventually(t, func() bool {
+		proxy.lock.Lock()
+		defer proxy.lock.Unlock()
+		return proxy.committed["scheduler"] == 2
+	}, 5*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, bus.Close())
+	proxy.lock.Lock()
+	assert.Empty(t, proxy.consumers)
+	proxy.lock.Unlock()
+}
+
+func TestKafkaBusReplay(t *testing.T) {
+	t.Parallel()
+	proxy := newFakeKafkaProxy()
+	defer proxy.server.Close()
+
+	now := time.Now().UTC()
+	proxy.append("notifications", "myapp", json.RawMessage(`{"id":"1","time":"`+now.Add(-3*time.Hour).Format(time.RFC3339)+`","data":{"repository":"old"}}`))
+	proxy.append("notifications", "myapp", json.RawMessage(`{"id":"2","time":"`+now.Add(-time.Hour).Format(time.RFC3339)+`","data":{"repository":"recent"}}`))
+	proxy.append("notifications", "myapp", json.RawMessage(`{"id":"3","time":"`+now.Format(time.RFC3339)+`","data":{"repository":"latest"}}`))
+	// the group has already processed all the messages
+	proxy.committed["notifications"] = 3
+
+	bus := eventbus.NewKafkaBus(proxy.server.URL, proxy.server.Client())
+	bus.PollInterval = 10 * time.Millisecond
+	bus.ReplaySince = now.Add(-2 * time.Hour)
+	defer bus.Close()
+
+	received := make(chan string, 10)
+	err := bus.Subscribe("notifications", "notifications", func(message *eventbus.Message) error {
+		value := &request{}
+		err := message.Unmarshal(value)
+		if err != nil {
+			return err
+		}
+		received <- message.ID + "=" + value.Repository
+		return nil
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "2=recent", receive(t, received))
+	assert.Equal(t, "3=latest", receive(t, received))
+}
+
+func receive(t *testing.T, received chan string) string {
+	select {
+	case value := <-received:
+		return value
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for a message")
+		return ""
+	}
+}
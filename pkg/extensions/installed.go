@@ -0,0 +1,212 @@
+package extensions
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/ghodss/yaml"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+)
+
+const (
+	// PluginPrefix the prefix of the names of the executables of plugins
+	PluginPrefix = "jx-"
+
+	installedPluginsFileName = "plugins.yaml"
+)
+
+// archiveExtensions the extensions of the archives plugins can be downloaded as
+var archiveExtensions = []string{".tar.gz", ".tgz", ".zip"}
+
+// InstalledPlugin a plugin installed by 'jx plugin install'
+type InstalledPlugin struct {
+	// Name the name of the executable such as jx-foo
+	Name string `json:"name"`
+	// Version the installed version
+	Version string `json:"version,omitempty"`
+	// URL the download URL template the plugin was installed from
+	URL string `json:"url"`
+}
+
+// InstalledPlugins the plugins installed by 'jx plugin install'
+type InstalledPlugins struct {
+	Plugins []InstalledPlugin `json:"plugins,omitempty"`
+}
+
+// InstalledPluginBinDir returns the directory the executables of installed plugins are stored in
+func InstalledPluginBinDir() (string, error) {
+	configDir, err := util.ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(configDir, "plugins", "bin")
+	err = os.MkdirAll(dir, util.DefaultWritePermissions)
+	if err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+// LookupInstalledPlugin returns the path of the executable of the installed plugin or an empty string if it is
+// not installed
+func LookupInstalledPlugin(name string) (string, error) {
+	dir, err := InstalledPluginBinDir()
+	if err != nil {
+		return "", err
+	}
+	if runtime.GOOS == "windows" && !strings.HasSuffix(name, ".exe") {
+		name += ".exe"
+	}
+	fileName := filepath.Join(dir, name)
+	exists, err := util.FileExists(fileName)
+	if err != nil || !exists {
+		return "", err
+	}
+	return fileName, nil
+}
+
+// LoadInstalledPlugins loads the list of plugins installed by 'jx plugin install'
+func LoadInstalledPlugins() (*InstalledPlugins, error) {
+	answer := &InstalledPlugins{}
+	fileName, err := installedPluginsFile()
+	if err != nil {
+		return answer, err
+	}
+	exists, err := util.FileExists(fileName)
+	if err != nil || !exists {
+		return answer, err
+	}
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return answer, errors.Wrapf(err, "failed to load file %s", fileName)
+	}
+	err = yaml.Unmarshal(data, answer)
+	if err != nil {
+		return answer, errors.Wrapf(err, "failed to unmarshal YAML file %s", fileName)
+	}
+	return answer, nil
+}
+
+// Save saves the list of installed plugins
+func (p *InstalledPlugins) Save() error {
+	fileName, err := installedPluginsFile()
+	if err != nil {
+		return err
+	}
+	sort.Slice(p.Plugins, func(i, j int) bool {
+		return p.Plugins[i].Name < p.Plugins[j].Name
+	})
+	data, err := yaml.Marshal(p)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal the installed plugins to YAML")
+	}
+	return ioutil.WriteFile(fileName, data, util.DefaultWritePermissions)
+}
+
+// SetPlugin adds or replaces the installed plugin
+func (p *InstalledPlugins) SetPlugin(plugin InstalledPlugin) {
+	for i := range p.Plugins {
+		if p.Plugins[i].Name == plugin.Name {
+			p.Plugins[i] = plugin
+			return
+		}
+	}
+	p.Plugins = append(p.Plugins, plugin)
+}
+
+// RemovePlugin removes the installed plugin returning true if it was installed
+func (p *InstalledPlugins) RemovePlugin(name string) bool {
+	for i := range p.Plugins {
+		if p.Plugins[i].Name == name {
+			p.Plugins = append(p.Plugins[:i], p.Plugins[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func installedPluginsFile() (string, error) {
+	configDir, err := util.ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "plugins", installedPluginsFileName), nil
+}
+
+// PluginNameFromURL returns the name of the executable of a plugin from its download URL template. For example
+// https://example.com/releases/{{.Version}}/jx-foo-{{.OS}}-{{.Arch}}.tar.gz returns jx-foo
+func PluginNameFromURL(u string) string {
+	name := path.Base(u)
+	if idx := strings.Index(name, "{{"); idx >= 0 {
+		name = name[:idx]
+	}
+	name = TrimArchiveExtension(name)
+	name = strings.TrimSuffix(strings.TrimSuffix(name, "-"), "_")
+	if name == "" || name == "." || name == "/" {
+		return ""
+	}
+	if !strings.HasPrefix(name, PluginPrefix) {
+		name = PluginPrefix + name
+	}
+	return name
+}
+
+// TrimArchiveExtension removes any archive file extension from the name
+func TrimArchiveExtension(name string) string {
+	for _, ext := range archiveExtensions {
+		name = strings.TrimSuffix(name, ext)
+	}
+	return name
+}
+
+// IsArchive returns true if the URL is an archive
+func IsArchive(u string) bool {
+	for _, ext := range archiveExtensions {
+		if strings.HasSuffix(u, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// PluginDownloadURL expands the download URL template of a plugin with the version and the current platform
+func PluginDownloadURL(urlTemplate string, version string) (string, error) {
+	t, err := template.New("url").Parse(urlTemplate)
+	if err != nil {
+		return "", errors.Wrapf(err, "parsing URL template %s", urlTemplate)
+	}
+	var buffer bytes.Buffer
+	err = t.Execute(&buffer, map[string]string{
+		"Version": version,
+		"OS":      runtime.GOOS,
+		"Arch":    runtime.GOARCH,
+	})
+	if err != nil {
+		return "", errors.Wrapf(err, "expanding URL template %s", urlTemplate)
+	}
+	return buffer.String(), nil
+}
+
+// PluginEnvironment returns the environment variables which give a plugin the context of the current jx session
+func PluginEnvironment(context map[string]string) []string {
+	answer := os.Environ()
+	keys := []string{}
+	for k := range context {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if context[k] != "" {
+			answer = append(answer, k+"="+context[k])
+		}
+	}
+	return answer
+}
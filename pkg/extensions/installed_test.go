@@ -0,0 +1,79 @@
+package extensions_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"runtime"
+	"testing"
+
+	"github.com/jenkins-x/jx/pkg/extensions"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPluginNameFromURL(t *testing.T) {
+	t.Parallel()
+	testCases := map[string]string{
+		"https://example.com/releases/download/v{{.Version}}/jx-foo-{{.OS}}-{{.Arch}}.tar.gz": "jx-foo",
+		"https://example.com/releases/download/v1.0.0/jx-foo.zip":                             "jx-foo",
+		"https://example.com/releases/download/v1.0.0/bar_{{.OS}}_{{.Arch}}":                  "jx-bar",
+		"https://example.com/releases/jx-foo-bar":                                             "jx-foo-bar",
+		"https://example.com/{{.Version}}":                                                    "",
+	}
+	for u, expected := range testCases {
+		assert.Equal(t, expected, extensions.PluginNameFromURL(u), "plugin name for %s", u)
+	}
+}
+
+func TestPluginDownloadURL(t *testing.T) {
+	t.Parallel()
+	actual, err := extensions.PluginDownloadURL("https://example.com/v{{.Version}}/jx-foo-{{.OS}}-{{.Arch}}.tar.gz", "1.2.3")
+	require.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf("https://example.com/v1.2.3/jx-foo-%s-%s.tar.gz", runtime.GOOS, runtime.GOARCH), actual)
+
+	_, err = extensions.PluginDownloadURL("https://example.com/{{.Version", "1.2.3")
+	assert.Error(t, err)
+}
+
+func TestInstalledPlugins(t *testing.T) {
+	dir, err := ioutil.TempDir("", "test-installed-plugins-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	originalJxHome := os.Getenv("JX_HOME")
+	defer os.Setenv("JX_HOME", originalJxHome)
+	os.Setenv("JX_HOME", dir)
+
+	installed, err := extensions.LoadInstalledPlugins()
+	require.NoError(t, err)
+	assert.Empty(t, installed.Plugins)
+
+	installed.SetPlugin(extensions.InstalledPlugin{Name: "jx-foo", Version: "1.0.0", URL: "https://example.com/jx-foo"})
+	installed.SetPlugin(extensions.InstalledPlugin{Name: "jx-bar", Version: "2.0.0", URL: "https://example.com/jx-bar"})
+	installed.SetPlugin(extensions.InstalledPlugin{Name: "jx-foo", Version: "1.1.0", URL: "https://example.com/jx-foo"})
+	require.NoError(t, installed.Save())
+
+	loaded, err := extensions.LoadInstalledPlugins()
+	require.NoError(t, err)
+	require.Len(t, loaded.Plugins, 2)
+	assert.Equal(t, "jx-bar", loaded.Plugins[0].Name)
+	assert.Equal(t, "1.1.0", loaded.Plugins[1].Version)
+
+	assert.True(t, loaded.RemovePlugin("jx-bar"))
+	assert.False(t, loaded.RemovePlugin("jx-bar"))
+	assert.Len(t, loaded.Plugins, 1)
+
+	path, err := extensions.LookupInstalledPlugin("jx-foo")
+	require.NoError(t, err)
+	assert.Equal(t, "", path)
+}
+
+func TestPluginEnvironment(t *testing.T) {
+	t.Parallel()
+	env := extensions.PluginEnvironment(map[string]string{
+		"JX_TEAM_NAMESPACE": "jx",
+		"JX_GIT_TOKEN":      "",
+	})
+	assert.Contains(t, env, "JX_TEAM_NAMESPACE=jx")
+	assert.NotContains(t, env, "JX_GIT_TOKEN=")
+}
@@ -152,9 +152,7 @@ func EnsurePluginInstalled(plugin jenkinsv1.Plugin) (string, error) {
 			log.Logger().Infof("Deleted old plugin versions: %v", util.ColorInfo(deleted))
 		}
 
-		var httpClient = &http.Client{
-			Timeout: time.Second * 10,
-		}
+		var httpClient = util.GetClientWithTimeout(time.Second * 10)
 		// Get the file
 		pluginURL, err := url.Parse(u)
 		if err != nil {
@@ -0,0 +1,170 @@
+package gits
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+)
+
+const (
+	// LabelAutoMerge is the label applied to pull requests which should be merged by the auto merge controller
+	// once all of their required checks pass
+	LabelAutoMerge = "auto-merge"
+)
+
+// AutoMerger is implemented by git providers which can natively merge a pull request as soon as its checks pass
+type AutoMerger interface {
+	// EnableAutoMerge asks the git provider to merge the pull request once its checks pass
+	EnableAutoMerge(pr *GitPullRequest, message string) error
+}
+
+// PullRequestApprover is implemented by git providers which support approving pull requests via their API
+type PullRequestApprover interface {
+	// ApprovePullRequest approves the pull request as the current user
+	ApprovePullRequest(pr *GitPullRequest) error
+}
+
+// PullRequestApprovalLister is implemented by git providers which support approving pull requests so that the
+// approvals can be checked against the OWNERS files of the repository
+type PullRequestApprovalLister interface {
+	// ListPullRequestApprovals returns the logins of the users who currently approve the pull request
+	ListPullRequestApprovals(pr *GitPullRequest) ([]string, error)
+}
+
+// PullRequestTaskCounter is implemented by git providers, such as Bitbucket Server, which support tasks on pull
+// requests which have to be resolved before they can be merged
+type PullRequestTaskCounter interface {
+	// PullRequestOpenTasks returns the number of tasks on the pull request which are not yet resolved
+	PullRequestOpenTasks(pr *GitPullRequest) (int, error)
+}
+
+// PullRequestOpenTasks returns the number of unresolved tasks on the pull request or 0 if the git provider does not
+// support tasks
+func PullRequestOpenTasks(provider GitProvider, pr *GitPullRequest) (int, error) {
+	counter, ok := provider.(PullRequestTaskCounter)
+	if !ok {
+		return 0, nil
+	}
+	return counter.PullRequestOpenTasks(pr)
+}
+
+// ListPullRequestApprovals returns the logins of the users who currently approve the pull request. Returns an error
+// if the git provider does not support approvals
+func ListPullRequestApprovals(provider GitProvider, pr *GitPullRequest) ([]string, error) {
+	lister, ok := provider.(PullRequestApprovalLister)
+	if !ok {
+		return nil, errors.Errorf("the git provider %s does not support listing the approvals of pull requests", provider.Kind())
+	}
+	if pr == nil || pr.Number == nil {
+		return nil, errors.New("cannot list the approvals as the pull request has no number")
+	}
+	return lister.ListPullRequestApprovals(pr)
+}
+
+// ApprovalsFromComments returns the users who approve a pull request with /approve or /lgtm comments, as on git
+// providers without native approvals. A later /approve cancel or /lgtm cancel comment withdraws the approval. The
+// comments must be in the order they were created
+func ApprovalsFromComments(comments []*GitIssueComment) []string {
+	approved := map[string]bool{}
+	answer := []string{}
+	for _, comment := range comments {
+		if comment == nil || comment.User.Login == "" {
+			continue
+		}
+		login := comment.User.Login
+		for _, line := range strings.Split(comment.Body, "\n") {
+			fields := strings.Fields(strings.ToLower(line))
+			if len(fields) == 0 || (fields[0] != "/approve" && fields[0] != "/lgtm") {
+				continue
+			}
+			approved[login] = len(fields) == 1 || fields[1] != "cancel"
+		}
+	}
+	for _, comment := range comments {
+		if comment == nil {
+			continue
+		}
+		login := comment.User.Login
+		if approved[login] {
+			answer = append(answer, login)
+			delete(approved, login)
+		}
+	}
+	return answer
+}
+
+// EnableAutoMerge enables the provider native auto merge of the pull request if the git provider supports it.
+// Otherwise the LabelAutoMerge label is added to the pull request so that it gets merged by the auto merge
+// controller. Returns true if the native auto merge was enabled
+func EnableAutoMerge(provider GitProvider, pr *GitPullRequest, message string) (bool, error) {
+	if pr == nil || pr.Number == nil {
+		return false, errors.New("cannot enable auto merge as the pull request has no number")
+	}
+	if autoMerger, ok := provider.(AutoMerger); ok {
+		err := autoMerger.EnableAutoMerge(pr, message)
+		if err == nil {
+			log.Logger().Infof("Enabled auto merge on pull request %s", util.ColorInfo(pr.URL))
+			return true, nil
+		}
+		log.Logger().Warnf("Failed to enable native auto merge on pull request %s, falling back to the %s label: %s", pr.URL, LabelAutoMerge, err)
+	}
+	err := provider.AddLabelsToIssue(pr.Owner, pr.Repo, *pr.Number, []string{LabelAutoMerge})
+	if err != nil {
+		return false, errors.Wrapf(err, "adding label %s to pull request %s", LabelAutoMerge, pr.URL)
+	}
+	log.Logger().Infof("Added label %s to pull request %s so it is merged once its checks pass", util.ColorInfo(LabelAutoMerge), util.ColorInfo(pr.URL))
+	return false, nil
+}
+
+// RequiredChecksPassed returns true if all of the required checks on the last commit of the pull request have
+// succeeded. If no required checks are specified then all the checks reported on the commit must have succeeded.
+// The second result lists the checks which are still pending or missing
+func RequiredChecksPassed(provider GitProvider, pr *GitPullRequest, requiredChecks []string) (bool, []string, error) {
+	if pr.LastCommitSha == "" {
+		return false, nil, errors.Errorf("pull request %s has no last commit SHA", pr.URL)
+	}
+	statuses, err := provider.ListCommitStatus(pr.Owner, pr.Repo, pr.LastCommitSha)
+	if err != nil {
+		return false, nil, errors.Wrapf(err, "listing commit statuses for %s on pull request %s", pr.LastCommitSha, pr.URL)
+	}
+
+	states := map[string]string{}
+	for _, status := range statuses {
+		if status == nil {
+			continue
+		}
+		name := status.Context
+		if name == "" {
+			name = status.ID
+		}
+		// lets keep the first status for each check as providers return the latest status first
+		if _, ok := states[name]; !ok {
+			states[name] = status.State
+		}
+	}
+
+	if len(requiredChecks) == 0 {
+		if len(states) == 0 {
+			return false, []string{}, nil
+		}
+		for name := range states {
+			requiredChecks = append(requiredChecks, name)
+		}
+		sort.Strings(requiredChecks)
+	}
+
+	waiting := []string{}
+	for _, check := range requiredChecks {
+		state := states[check]
+		if state == "error" || state == "failure" {
+			return false, nil, errors.Errorf("required check %s has state %s on pull request %s", check, state, pr.URL)
+		}
+		if state != "success" {
+			waiting = append(waiting, check)
+		}
+	}
+	return len(waiting) == 0, waiting, nil
+}
@@ -0,0 +1,116 @@
+package gits_test
+
+import (
+	"testing"
+
+	"github.com/jenkins-x/jx/pkg/gits"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func createAutoMergeFakeProvider(t *testing.T, status gits.CommitStatus) (*gits.FakeProvider, *gits.GitPullRequest) {
+	repo, err := gits.NewFakeRepository("acme", "roadrunner", nil, nil)
+	require.NoError(t, err)
+
+	number := 1
+	state := gits.PullRequestOpen
+	pr := &gits.GitPullRequest{
+		URL:           "https://fake.git/acme/roadrunner/pulls/1",
+		Owner:         "acme",
+		Repo:          "roadrunner",
+		Number:        &number,
+		State:         &state,
+		LastCommitSha: "abc123",
+	}
+	commit := &gits.FakeCommit{
+		Commit: &gits.GitCommit{
+			SHA:     "abc123",
+			Message: "chore: bump version",
+		},
+		Status: status,
+	}
+	repo.PullRequests[number] = &gits.FakePullRequest{
+		PullRequest: pr,
+		Commits:     []*gits.FakeCommit{commit},
+	}
+	repo.Commits = append(repo.Commits, commit)
+	return gits.NewFakeProvider(repo), pr
+}
+
+func TestEnableAutoMergeAddsLabel(t *testing.T) {
+	t.Parallel()
+	provider, pr := createAutoMergeFakeProvider(t, gits.CommitStatusPending)
+
+	native, err := gits.EnableAutoMerge(provider, pr, "chore: bump version")
+	require.NoError(t, err)
+	assert.False(t, native)
+	assert.True(t, pr.HasLabel(gits.LabelAutoMerge))
+
+	prs, err := gits.FilterOpenPullRequests(provider, "acme", "roadrunner", gits.PullRequestFilter{
+		Labels: []string{gits.LabelAutoMerge},
+	})
+	require.NoError(t, err)
+	assert.Len(t, prs, 1)
+}
+
+func TestRequiredChecksPassed(t *testing.T) {
+	t.Parallel()
+
+	provider, pr := createAutoMergeFakeProvider(t, gits.CommitSatusSuccess)
+	passed, waiting, err := gits.RequiredChecksPassed(provider, pr, nil)
+	require.NoError(t, err)
+	assert.True(t, passed)
+	assert.Empty(t, waiting)
+
+	passed, waiting, err = gits.RequiredChecksPassed(provider, pr, []string{"abc123", "integration"})
+	require.NoError(t, err)
+	assert.False(t, passed)
+	assert.Equal(t, []string{"integration"}, waiting)
+
+	provider, pr = createAutoMergeFakeProvider(t, gits.CommitStatusPending)
+	passed, waiting, err = gits.RequiredChecksPassed(provider, pr, nil)
+	require.NoError(t, err)
+	assert.False(t, passed)
+	assert.Equal(t, []string{"abc123"}, waiting)
+
+	provider, pr = createAutoMergeFakeProvider(t, gits.CommitStatusFailure)
+	_, _, err = gits.RequiredChecksPassed(provider, pr, nil)
+	assert.Error(t, err)
+}
+
+func TestPullRequestOpenTasksUnsupportedProvider(t *testing.T) {
+	t.Parallel()
+	provider, pr := createAutoMergeFakeProvider(t, gits.CommitSatusSuccess)
+
+	openTasks, err := gits.PullRequestOpenTasks(provider, pr)
+	require.NoError(t, err)
+	assert.Equal(t, 0, openTasks, "providers without tasks should never block a merge")
+}
+
+func TestListPullRequestApprovals(t *testing.T) {
+	t.Parallel()
+	provider, pr := createAutoMergeFakeProvider(t, gits.CommitStatusPending)
+	provider.Repositories["acme"][0].PullRequests[1].Approvals = []string{"wile"}
+
+	approvals, err := gits.ListPullRequestApprovals(provider, pr)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"wile"}, approvals)
+}
+
+func TestApprovalsFromComments(t *testing.T) {
+	t.Parallel()
+	comment := func(login string, body string) *gits.GitIssueComment {
+		return &gits.GitIssueComment{
+			User: gits.GitUser{Login: login},
+			Body: body,
+		}
+	}
+	comments := []*gits.GitIssueComment{
+		comment("wile", "looks good\n/lgtm"),
+		comment("roadrunner", "/approve"),
+		comment("coyote", "/approve"),
+		comment("coyote", "/approve cancel"),
+		comment("acme", "please /approve this"),
+	}
+	assert.Equal(t, []string{"wile", "roadrunner"}, gits.ApprovalsFromComments(comments))
+}
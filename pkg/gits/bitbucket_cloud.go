@@ -55,6 +55,7 @@ func NewBitbucketCloudProvider(server *auth.AuthServer, user *auth.UserAuth, git
 	}
 
 	cfg := bitbucket.NewConfiguration()
+	cfg.HTTPClient = RateLimitHTTPClient()
 	provider.Client = bitbucket.NewAPIClient(cfg)
 
 	return &provider, nil
@@ -453,6 +454,29 @@ func (b *BitbucketCloudProvider) GetPullRequest(owner string, repoInfo *GitRepos
 	return b.toPullRequest(pr, number), nil
 }
 
+// ListPullRequestApprovals returns the participants who approved the pull request
+func (b *BitbucketCloudProvider) ListPullRequestApprovals(pr *GitPullRequest) ([]string, error) {
+	if pr.Number == nil {
+		return nil, fmt.Errorf("Missing Number for GitPullRequest %#v", pr)
+	}
+	bitbucketPR, _, err := b.Client.PullrequestsApi.RepositoriesUsernameRepoSlugPullrequestsPullRequestIdGet(
+		b.Context,
+		pr.Owner,
+		pr.Repo,
+		int32(*pr.Number),
+	)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get the participants of pull request %s", pr.URL)
+	}
+	answer := []string{}
+	for _, participant := range bitbucketPR.Participants {
+		if participant.Approved && participant.User != nil && participant.User.Username != "" {
+			answer = append(answer, participant.User.Username)
+		}
+	}
+	return answer, nil
+}
+
 func (b *BitbucketCloudProvider) toPullRequest(pr bitbucket.Pullrequest, number int) *GitPullRequest {
 	author := &GitUser{
 		Login:     pr.Author.Username,
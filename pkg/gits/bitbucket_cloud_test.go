@@ -333,6 +333,19 @@ func (suite *BitbucketCloudProviderTestSuite) TestGetPullRequest() {
 	suite.Require().Equal(*pr.Number, 3)
 }
 
+func (suite *BitbucketCloudProviderTestSuite) TestListPullRequestApprovals() {
+	number := 3
+	pr := &gits.GitPullRequest{
+		Owner:  "test-user",
+		Repo:   "test-repo",
+		Number: &number,
+	}
+	approvals, err := suite.provider.ListPullRequestApprovals(pr)
+
+	suite.Require().Nil(err)
+	suite.Require().Equal([]string{"approving-user"}, approvals)
+}
+
 func (suite *BitbucketCloudProviderTestSuite) TestPullRequestCommits() {
 	commits, err := suite.provider.GetPullRequestCommits("test-user", &gits.GitRepository{Name: "test-repo"}, 1)
 
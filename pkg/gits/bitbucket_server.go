@@ -1,9 +1,13 @@
 package gits
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
 	"net/url"
 	"os"
 	"strconv"
@@ -24,6 +28,36 @@ import (
 // pageLimit is used for the page size for API responses
 const pageLimit = 25
 
+// bitbucketServerWebHookEvents the events a Jenkins X webhook is registered for on Bitbucket Server
+var bitbucketServerWebHookEvents = []string{
+	"repo:refs_changed",
+	"repo:modified",
+	"repo:forked",
+	"repo:comment:added",
+	"repo:comment:edited",
+	"repo:comment:deleted",
+	"pr:opened",
+	"pr:modified",
+	"pr:reviewer:approved",
+	"pr:reviewer:unapproved",
+	"pr:reviewer:needs_work",
+	"pr:merged",
+	"pr:declined",
+	"pr:deleted",
+	"pr:comment:added",
+	"pr:comment:edited",
+	"pr:comment:deleted",
+}
+
+// bitbucketServerBuildStates maps the git provider commit states onto the Bitbucket Server build states
+var bitbucketServerBuildStates = map[string]string{
+	"pending":     "INPROGRESS",
+	"in-progress": "INPROGRESS",
+	"success":     "SUCCESSFUL",
+	"error":       "FAILED",
+	"failure":     "FAILED",
+}
+
 // BitbucketServerProvider implements GitProvider interface for a bitbucket server
 type BitbucketServerProvider struct {
 	Client   *bitbucket.APIClient
@@ -88,6 +122,34 @@ type webHooksPage struct {
 	Values        []webHook `json:"values"`
 }
 
+type pullRequestTaskCount struct {
+	Open     int `json:"open"`
+	Resolved int `json:"resolved"`
+}
+
+type pullRequestParticipants struct {
+	Reviewers    []pullRequestParticipant `json:"reviewers"`
+	Participants []pullRequestParticipant `json:"participants"`
+}
+
+type pullRequestParticipant struct {
+	User struct {
+		Name string `json:"name"`
+		Slug string `json:"slug"`
+	} `json:"user"`
+	Approved bool `json:"approved"`
+}
+
+type pullRequestMergeStatus struct {
+	CanMerge   bool   `json:"canMerge"`
+	Conflicted bool   `json:"conflicted"`
+	Outcome    string `json:"outcome"`
+	Vetoes     []struct {
+		SummaryMessage  string `json:"summaryMessage"`
+		DetailedMessage string `json:"detailedMessage"`
+	} `json:"vetoes"`
+}
+
 type webHook struct {
 	ID            int64                  `json:"id"`
 	Name          string                 `json:"name"`
@@ -112,6 +174,7 @@ func NewBitbucketServerProvider(server *auth.AuthServer, user *auth.UserAuth, gi
 	}
 
 	cfg := bitbucket.NewConfiguration(server.URL + "/rest")
+	cfg.HTTPClient = RateLimitHTTPClient()
 	provider.Client = bitbucket.NewAPIClient(apiKeyAuthContext, cfg)
 
 	return &provider, nil
@@ -734,8 +797,166 @@ func (b *BitbucketServerProvider) ListCommitStatus(org, repo, sha string) ([]*Gi
 	return statuses, nil
 }
 
+// UpdateCommitStatus posts a build status for the given commit. Bitbucket Server keys build statuses by commit only
+// so the org and repo are not used
 func (b *BitbucketServerProvider) UpdateCommitStatus(org string, repo string, sha string, status *GitRepoStatus) (*GitRepoStatus, error) {
-	return &GitRepoStatus{}, errors.New("TODO")
+	state, ok := bitbucketServerBuildStates[status.State]
+	if !ok {
+		return nil, errors.Errorf("unsupported commit status state %s for Bitbucket Server", status.State)
+	}
+	key := status.Context
+	if key == "" {
+		key = status.ID
+	}
+	if key == "" {
+		key = "jenkins-x"
+	}
+	targetURL := status.TargetURL
+	if targetURL == "" {
+		targetURL = status.URL
+	}
+	if targetURL == "" {
+		return nil, errors.Errorf("missing URL for build status %s on commit %s as Bitbucket Server requires one", key, sha)
+	}
+
+	options := map[string]interface{}{
+		"state":       state,
+		"key":         key,
+		"name":        key,
+		"url":         targetURL,
+		"description": status.Description,
+	}
+	err := b.restRequest(http.MethodPost, util.UrlJoin("build-status/1.0/commits", sha), options, nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to update build status %s on commit %s", key, sha)
+	}
+	return &GitRepoStatus{
+		ID:          key,
+		Context:     key,
+		URL:         targetURL,
+		State:       status.State,
+		TargetURL:   targetURL,
+		Description: status.Description,
+	}, nil
+}
+
+// ApprovePullRequest approves the pull request as the current user
+func (b *BitbucketServerProvider) ApprovePullRequest(pr *GitPullRequest) error {
+	if pr.Number == nil {
+		return fmt.Errorf("Missing Number for GitPullRequest %#v", pr)
+	}
+	projectKey, repo := parseBitBucketServerURL(pr.URL)
+	path := util.UrlJoin("api/1.0/projects", projectKey, "repos", repo, "pull-requests", strconv.Itoa(*pr.Number), "approve")
+	err := b.restRequest(http.MethodPost, path, nil, nil)
+	if err != nil {
+		return errors.Wrapf(err, "failed to approve pull request %s", pr.URL)
+	}
+	return nil
+}
+
+// ListPullRequestApprovals returns the reviewers and participants who approved the pull request
+func (b *BitbucketServerProvider) ListPullRequestApprovals(pr *GitPullRequest) ([]string, error) {
+	if pr.Number == nil {
+		return nil, fmt.Errorf("Missing Number for GitPullRequest %#v", pr)
+	}
+	projectKey, repo := parseBitBucketServerURL(pr.URL)
+	path := util.UrlJoin("api/1.0/projects", projectKey, "repos", repo, "pull-requests", strconv.Itoa(*pr.Number))
+	participants := pullRequestParticipants{}
+	err := b.restRequest(http.MethodGet, path, nil, &participants)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get the reviewers of pull request %s", pr.URL)
+	}
+	answer := []string{}
+	for _, p := range append(participants.Reviewers, participants.Participants...) {
+		if p.Approved && p.User.Slug != "" && util.StringArrayIndex(answer, p.User.Slug) < 0 {
+			answer = append(answer, p.User.Slug)
+		}
+	}
+	return answer, nil
+}
+
+// PullRequestOpenTasks returns the number of tasks on the pull request which are not yet resolved
+func (b *BitbucketServerProvider) PullRequestOpenTasks(pr *GitPullRequest) (int, error) {
+	if pr.Number == nil {
+		return 0, fmt.Errorf("Missing Number for GitPullRequest %#v", pr)
+	}
+	projectKey, repo := parseBitBucketServerURL(pr.URL)
+	path := util.UrlJoin("api/1.0/projects", projectKey, "repos", repo, "pull-requests", strconv.Itoa(*pr.Number), "tasks/count")
+	taskCount := pullRequestTaskCount{}
+	err := b.restRequest(http.MethodGet, path, nil, &taskCount)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to count the tasks on pull request %s", pr.URL)
+	}
+	return taskCount.Open, nil
+}
+
+// PullRequestMergeVetoes returns the reasons, such as open tasks, missing approvals or failed builds, why the
+// pull request cannot currently be merged. An empty slice means the pull request can be merged
+func (b *BitbucketServerProvider) PullRequestMergeVetoes(pr *GitPullRequest) ([]string, error) {
+	if pr.Number == nil {
+		return nil, fmt.Errorf("Missing Number for GitPullRequest %#v", pr)
+	}
+	projectKey, repo := parseBitBucketServerURL(pr.URL)
+	path := util.UrlJoin("api/1.0/projects", projectKey, "repos", repo, "pull-requests", strconv.Itoa(*pr.Number), "merge")
+	mergeStatus := pullRequestMergeStatus{}
+	err := b.restRequest(http.MethodGet, path, nil, &mergeStatus)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get the merge status of pull request %s", pr.URL)
+	}
+	vetoes := []string{}
+	if mergeStatus.CanMerge {
+		return vetoes, nil
+	}
+	if mergeStatus.Conflicted {
+		vetoes = append(vetoes, "the pull request has conflicts")
+	}
+	for _, veto := range mergeStatus.Vetoes {
+		vetoes = append(vetoes, veto.SummaryMessage)
+	}
+	return vetoes, nil
+}
+
+// restRequest invokes a Bitbucket Server REST endpoint which is not exposed by the generated API client, decoding
+// the JSON response into result if it is not nil
+func (b *BitbucketServerProvider) restRequest(method string, path string, body interface{}, result interface{}) error {
+	u := util.UrlJoin(b.Server.URL, "rest", path)
+
+	var reqBody io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return errors.Wrapf(err, "failed to JSON encode request body for %s", u)
+		}
+		reqBody = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, u, reqBody)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create request for %s", u)
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+b.User.ApiToken)
+
+	resp, err := RateLimitHTTPClient().Do(req)
+	if err != nil {
+		return errors.Wrapf(err, "failed to %s %s", method, u)
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read response from %s", u)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return errors.Errorf("%s %s returned status %s: %s", method, u, resp.Status, string(data))
+	}
+	if result != nil && len(data) > 0 {
+		err = json.Unmarshal(data, result)
+		if err != nil {
+			return errors.Wrapf(err, "failed to unmarshal response from %s", u)
+		}
+	}
+	return nil
 }
 
 func convertBitBucketBuildStatusToGitStatus(buildStatus *bitbucket.BuildStatus) *GitRepoStatus {
@@ -773,6 +994,10 @@ func (b *BitbucketServerProvider) MergePullRequest(pr *GitPullRequest, message s
 
 	apiResponse, err = b.Client.DefaultApi.Merge(projectKey, repo, *pr.Number, queryParams, requestBody, []string{"application/json"})
 	if err != nil {
+		vetoes, vetoErr := b.PullRequestMergeVetoes(pr)
+		if vetoErr == nil && len(vetoes) > 0 {
+			return errors.Wrapf(err, "pull request %s cannot be merged: %s", pr.URL, strings.Join(vetoes, ", "))
+		}
 		return err
 	}
 
@@ -817,8 +1042,13 @@ func (b *BitbucketServerProvider) CreateWebHook(data *GitWebHookArguments) error
 	}
 	for _, hook := range hooks {
 		if data.URL == hook.URL {
-			log.Logger().Warnf("Already has a webhook registered for %s", data.URL)
-			return nil
+			if data.Secret == hook.Secret {
+				log.Logger().Warnf("Already has a webhook registered for %s", data.URL)
+				return nil
+			}
+			// lets make sure the existing webhook uses the current secret and events
+			data.ID = hook.ID
+			return b.UpdateWebHook(data)
 		}
 	}
 
@@ -826,7 +1056,7 @@ func (b *BitbucketServerProvider) CreateWebHook(data *GitWebHookArguments) error
 		"url":    data.URL,
 		"name":   "Jenkins X Web Hook",
 		"active": true,
-		"events": []string{"repo:refs_changed", "repo:modified", "repo:forked", "repo:comment:added", "repo:comment:edited", "repo:comment:deleted", "pr:opened", "pr:reviewer:approved", "pr:reviewer:unapproved", "pr:reviewer:needs_work", "pr:merged", "pr:declined", "pr:deleted", "pr:comment:added", "pr:comment:edited", "pr:comment:deleted"},
+		"events": bitbucketServerWebHookEvents,
 	}
 
 	if data.Secret != "" {
@@ -931,7 +1161,7 @@ func (b *BitbucketServerProvider) UpdateWebHook(data *GitWebHookArguments) error
 		"url":    data.URL,
 		"name":   "Jenkins X Web Hook",
 		"active": true,
-		"events": []string{"repo:refs_changed", "repo:modified", "repo:forked", "repo:comment:added", "repo:comment:edited", "repo:comment:deleted", "pr:opened", "pr:reviewer:approved", "pr:reviewer:unapproved", "pr:reviewer:needs_work", "pr:merged", "pr:declined", "pr:deleted", "pr:comment:added", "pr:comment:edited", "pr:comment:deleted"},
+		"events": bitbucketServerWebHookEvents,
 	}
 
 	if data.Secret != "" {
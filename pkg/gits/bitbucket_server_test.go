@@ -52,8 +52,15 @@ var bitbucketServerRouter = util.Router{
 		"GET": "pr-commits.json",
 	},
 	"/rest/api/1.0/projects/TEST-ORG/repos/test-repo/pull-requests/1/merge": util.MethodMap{
+		"GET":  "pr-merge-status.json",
 		"POST": "pr-merge-success.json",
 	},
+	"/rest/api/1.0/projects/TEST-ORG/repos/test-repo/pull-requests/1/approve": util.MethodMap{
+		"POST": "pr-approve.json",
+	},
+	"/rest/api/1.0/projects/TEST-ORG/repos/test-repo/pull-requests/1/tasks/count": util.MethodMap{
+		"GET": "pr-tasks-count.json",
+	},
 	"/rest/api/1.0/projects/TEST-ORG/repos/test-repo/pull-requests/1/comments": util.MethodMap{
 		"POST": "pr-comment.json",
 	},
@@ -68,7 +75,8 @@ var bitbucketServerRouter = util.Router{
 		"GET": "user.json",
 	},
 	"/rest/build-status/1.0/commits/d6f24ee03d76a2caf0a4e1975fb43e8f61759b9c": util.MethodMap{
-		"GET": "build-statuses.json",
+		"GET":  "build-statuses.json",
+		"POST": "build-status.json",
 	},
 }
 
@@ -272,6 +280,86 @@ func (suite *BitbucketServerProviderTestSuite) TestListCommitStatuses() {
 	}
 }
 
+func (suite *BitbucketServerProviderTestSuite) TestUpdateCommitStatus() {
+	suite.withMockServerURL(func() {
+		status, err := suite.provider.UpdateCommitStatus("TEST-ORG", "test-repo", "d6f24ee03d76a2caf0a4e1975fb43e8f61759b9c", &gits.GitRepoStatus{
+			State:       "pending",
+			Context:     "pr-build",
+			URL:         "http://jenkins.example.com/teams/jx/projects/TEST-ORG/test-repo/PR-1/1",
+			Description: "Pipeline running",
+		})
+
+		suite.Require().Nil(err)
+		suite.Require().Equal("pr-build", status.ID)
+		suite.Require().Equal("pending", status.State)
+		suite.Require().Equal("http://jenkins.example.com/teams/jx/projects/TEST-ORG/test-repo/PR-1/1", status.TargetURL)
+
+		_, err = suite.provider.UpdateCommitStatus("TEST-ORG", "test-repo", "d6f24ee03d76a2caf0a4e1975fb43e8f61759b9c", &gits.GitRepoStatus{
+			State: "pending",
+		})
+		suite.Require().NotNil(err)
+	})
+}
+
+func (suite *BitbucketServerProviderTestSuite) TestApprovePullRequest() {
+	suite.withMockServerURL(func() {
+		id := 1
+		pr := &gits.GitPullRequest{
+			URL:    "https://auth.example.com/projects/TEST-ORG/repos/test-repo/pull-requests/1",
+			Repo:   "test-repo",
+			Number: &id,
+		}
+		err := suite.provider.ApprovePullRequest(pr)
+
+		suite.Require().Nil(err)
+	})
+}
+
+func (suite *BitbucketServerProviderTestSuite) TestListPullRequestApprovals() {
+	suite.withMockServerURL(func() {
+		id := 1
+		pr := &gits.GitPullRequest{
+			URL:    "https://auth.example.com/projects/TEST-ORG/repos/test-repo/pull-requests/1",
+			Repo:   "test-repo",
+			Number: &id,
+		}
+		approvals, err := suite.provider.ListPullRequestApprovals(pr)
+
+		suite.Require().Nil(err)
+		suite.Require().Equal([]string{"approving-user"}, approvals)
+	})
+}
+
+func (suite *BitbucketServerProviderTestSuite) TestPullRequestOpenTasks() {
+	suite.withMockServerURL(func() {
+		id := 1
+		pr := &gits.GitPullRequest{
+			URL:    "https://auth.example.com/projects/TEST-ORG/repos/test-repo/pull-requests/1",
+			Repo:   "test-repo",
+			Number: &id,
+		}
+		openTasks, err := suite.provider.PullRequestOpenTasks(pr)
+
+		suite.Require().Nil(err)
+		suite.Require().Equal(2, openTasks)
+	})
+}
+
+func (suite *BitbucketServerProviderTestSuite) TestPullRequestMergeVetoes() {
+	suite.withMockServerURL(func() {
+		id := 1
+		pr := &gits.GitPullRequest{
+			URL:    "https://auth.example.com/projects/TEST-ORG/repos/test-repo/pull-requests/1",
+			Repo:   "test-repo",
+			Number: &id,
+		}
+		vetoes, err := suite.provider.PullRequestMergeVetoes(pr)
+
+		suite.Require().Nil(err)
+		suite.Require().Equal([]string{"Not enough approvals", "This pull request has unresolved tasks"}, vetoes)
+	})
+}
+
 func (suite *BitbucketServerProviderTestSuite) TestMergePullRequest() {
 
 	id := 1
@@ -375,6 +463,17 @@ func (suite *BitbucketServerProviderTestSuite) TestAddPRComment() {
 	suite.Require().Nil(err)
 }
 
+// withMockServerURL points the provider at the mock server for the REST endpoints which are not
+// covered by the generated Bitbucket client
+func (suite *BitbucketServerProviderTestSuite) withMockServerURL(fn func()) {
+	serverURL := suite.provider.Server.URL
+	suite.provider.Server.URL = suite.server.URL
+	defer func() {
+		suite.provider.Server.URL = serverURL
+	}()
+	fn()
+}
+
 func TestBitbucketServerProviderTestSuite(t *testing.T) {
 	if testing.Short() {
 		t.Skip("skipping TestBitbucketServerProviderTestSuite in short mode")
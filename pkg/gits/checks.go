@@ -0,0 +1,78 @@
+package gits
+
+import (
+	"time"
+)
+
+const (
+	// CheckRunStatusQueued the check run is waiting to start
+	CheckRunStatusQueued = "queued"
+	// CheckRunStatusInProgress the check run is running
+	CheckRunStatusInProgress = "in_progress"
+	// CheckRunStatusCompleted the check run has completed and has a conclusion
+	CheckRunStatusCompleted = "completed"
+
+	// CheckRunConclusionSuccess the check run succeeded
+	CheckRunConclusionSuccess = "success"
+	// CheckRunConclusionFailure the check run failed
+	CheckRunConclusionFailure = "failure"
+	// CheckRunConclusionCancelled the check run was cancelled
+	CheckRunConclusionCancelled = "cancelled"
+
+	// CheckRunAnnotationNotice an informational annotation
+	CheckRunAnnotationNotice = "notice"
+	// CheckRunAnnotationWarning a warning annotation
+	CheckRunAnnotationWarning = "warning"
+	// CheckRunAnnotationFailure a failure annotation
+	CheckRunAnnotationFailure = "failure"
+
+	// CheckRunActionRerun the identifier of the action which re-runs the pipeline of a check run
+	CheckRunActionRerun = "rerun"
+)
+
+// GitCheckRun a check run reporting the result of a pipeline on a commit with annotations, a detailed report and
+// actions the user can request
+type GitCheckRun struct {
+	ID          int64
+	Name        string
+	HeadSHA     string
+	ExternalID  string
+	DetailsURL  string
+	Status      string
+	Conclusion  string
+	StartedAt   *time.Time
+	CompletedAt *time.Time
+	Title       string
+	Summary     string
+	Text        string
+	Annotations []GitCheckRunAnnotation
+	Actions     []GitCheckRunAction
+}
+
+// GitCheckRunAnnotation an annotation of a file in a check run
+type GitCheckRunAnnotation struct {
+	Path       string
+	StartLine  int
+	EndLine    int
+	Level      string
+	Title      string
+	Message    string
+	RawDetails string
+}
+
+// GitCheckRunAction a button shown on a check run which the user can click to request an action
+type GitCheckRunAction struct {
+	Label       string
+	Description string
+	Identifier  string
+}
+
+// GitCheckRunProvider is implemented by git providers which support reporting check runs which are richer than
+// commit statuses
+type GitCheckRunProvider interface {
+	// CreateCheckRun creates a check run returning it with its ID populated
+	CreateCheckRun(owner string, repo string, checkRun *GitCheckRun) (*GitCheckRun, error)
+
+	// UpdateCheckRun updates the check run with the ID of the given check run
+	UpdateCheckRun(owner string, repo string, checkRun *GitCheckRun) (*GitCheckRun, error)
+}
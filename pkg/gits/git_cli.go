@@ -954,6 +954,18 @@ func (g *GitCLI) CreateTag(dir string, tag string, msg string) error {
 	return g.gitCmd(dir, "tag", "-fa", tag, "-m", msg)
 }
 
+// CreateSignedTag creates a GPG signed tag with the given name and message in the repository at the given directory
+// using the given signing key or the default key of the committer if the key is blank
+func (g *GitCLI) CreateSignedTag(dir string, tag string, msg string, key string) error {
+	args := []string{"tag", "-f"}
+	if key != "" {
+		args = append(args, "-u", key)
+	} else {
+		args = append(args, "-s")
+	}
+	return g.gitCmd(dir, append(args, tag, "-m", msg)...)
+}
+
 // PrintCreateRepositoryGenerateAccessToken prints the access token URL of a Git repository
 func (g *GitCLI) PrintCreateRepositoryGenerateAccessToken(server *auth.AuthServer, username string, o io.Writer) {
 	tokenUrl := ProviderAccessTokenURL(server.Kind, server.URL, username)
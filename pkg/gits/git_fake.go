@@ -543,6 +543,11 @@ func (g *GitFake) CreateTag(dir string, tag string, msg string) error {
 	return nil
 }
 
+// CreateSignedTag creates a signed tag
+func (g *GitFake) CreateSignedTag(dir string, tag string, msg string, key string) error {
+	return g.CreateTag(dir, tag, msg)
+}
+
 // GetRevisionBeforeDate get the revision before the date
 func (g *GitFake) GetRevisionBeforeDate(dir string, t time.Time) (string, error) {
 	return g.Revision, nil
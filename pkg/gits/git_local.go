@@ -355,6 +355,11 @@ func (g *GitLocal) CreateTag(dir string, tag string, msg string) error {
 	return g.GitCLI.CreateTag(dir, tag, msg)
 }
 
+// CreateSignedTag creates a GPG signed tag with the given name and message in the repository at the given directory
+func (g *GitLocal) CreateSignedTag(dir string, tag string, msg string, key string) error {
+	return g.GitCLI.CreateSignedTag(dir, tag, msg, key)
+}
+
 // PrintCreateRepositoryGenerateAccessToken prints the access token URL of a Git repository
 func (g *GitLocal) PrintCreateRepositoryGenerateAccessToken(server *auth.AuthServer, username string, o io.Writer) {
 	g.GitCLI.PrintCreateRepositoryGenerateAccessToken(server, username, o)
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -28,6 +29,7 @@ type GiteaProvider struct {
 
 func NewGiteaProvider(server *auth.AuthServer, user *auth.UserAuth, git Gitter) (GitProvider, error) {
 	client := gitea.NewClient(server.URL, user.ApiToken)
+	client.SetHTTPClient(RateLimitHTTPClient())
 
 	provider := GiteaProvider{
 		Client:   client,
@@ -528,6 +530,34 @@ func (p *GiteaProvider) MergePullRequest(pr *GitPullRequest, message string) err
 	return p.Client.MergePullRequest(pr.Owner, pr.Repo, int64(n))
 }
 
+// ListPullRequestApprovals returns the users who approved the pull request with /approve or /lgtm comments as
+// Gitea has no pull request approvals in its API
+func (p *GiteaProvider) ListPullRequestApprovals(pr *GitPullRequest) ([]string, error) {
+	if pr.Number == nil {
+		return nil, fmt.Errorf("Missing Number for GitPullRequest %#v", pr)
+	}
+	comments, err := p.Client.ListIssueComments(pr.Owner, pr.Repo, int64(*pr.Number))
+	if err != nil {
+		return nil, errors2.Wrapf(err, "failed to list the comments of pull request %s", pr.URL)
+	}
+	issueComments := []*GitIssueComment{}
+	for _, comment := range comments {
+		if comment == nil || comment.Poster == nil {
+			continue
+		}
+		created := comment.Created
+		issueComments = append(issueComments, &GitIssueComment{
+			User:      *toGiteaUser(comment.Poster),
+			Body:      comment.Body,
+			CreatedAt: &created,
+		})
+	}
+	sort.SliceStable(issueComments, func(i, j int) bool {
+		return issueComments[i].CreatedAt.Before(*issueComments[j].CreatedAt)
+	})
+	return ApprovalsFromComments(issueComments), nil
+}
+
 func (p *GiteaProvider) PullRequestLastCommitStatus(pr *GitPullRequest) (string, error) {
 	ref := pr.LastCommitSha
 	if ref == "" {
@@ -33,7 +33,7 @@ type GitHubProvider struct {
 }
 
 func NewGitHubProvider(server *auth.AuthServer, user *auth.UserAuth, git Gitter) (GitProvider, error) {
-	ctx := context.Background()
+	ctx := context.WithValue(context.Background(), oauth2.HTTPClient, RateLimitHTTPClient())
 
 	provider := GitHubProvider{
 		Server:   *server,
@@ -326,6 +326,32 @@ func (p *GitHubProvider) DeleteRepository(org string, name string) error {
 	return err
 }
 
+// DeleteWebHook deletes the webhook with the ID from the repository
+func (p *GitHubProvider) DeleteWebHook(owner string, repo string, id int64) error {
+	if owner == "" {
+		owner = p.Username
+	}
+	_, err := p.Client.Repositories.DeleteHook(p.Context, owner, repo, id)
+	if err != nil {
+		return errors.Wrapf(err, "failed to delete webhook %d from %s/%s", id, owner, repo)
+	}
+	return nil
+}
+
+// ArchiveRepository archives the repository making it read only
+func (p *GitHubProvider) ArchiveRepository(org string, name string) error {
+	owner := org
+	if owner == "" {
+		owner = p.Username
+	}
+	archived := true
+	_, _, err := p.Client.Repositories.Edit(p.Context, owner, name, &github.Repository{Archived: &archived})
+	if err != nil {
+		return errors.Wrapf(err, "failed to archive repository %s/%s", owner, name)
+	}
+	return nil
+}
+
 func toGitHubRepo(name string, org string, repo *github.Repository) *GitRepository {
 	var id int64
 	if repo.ID != nil {
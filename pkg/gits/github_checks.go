@@ -0,0 +1,138 @@
+package gits
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// checksPreviewMediaType the media type required by the GitHub Checks API
+	checksPreviewMediaType = "application/vnd.github.antiope-preview+json"
+
+	// maxCheckRunAnnotations the maximum number of annotations GitHub accepts in a single request
+	maxCheckRunAnnotations = 50
+	// maxCheckRunActions the maximum number of actions GitHub accepts on a check run
+	maxCheckRunActions = 3
+	// maxCheckRunText the maximum length of the text of a check run
+	maxCheckRunText = 65535
+)
+
+type githubCheckRun struct {
+	ID          int64                  `json:"id,omitempty"`
+	Name        string                 `json:"name,omitempty"`
+	HeadSHA     string                 `json:"head_sha,omitempty"`
+	ExternalID  string                 `json:"external_id,omitempty"`
+	DetailsURL  string                 `json:"details_url,omitempty"`
+	Status      string                 `json:"status,omitempty"`
+	Conclusion  string                 `json:"conclusion,omitempty"`
+	StartedAt   *time.Time             `json:"started_at,omitempty"`
+	CompletedAt *time.Time             `json:"completed_at,omitempty"`
+	Output      *githubCheckRunOutput  `json:"output,omitempty"`
+	Actions     []githubCheckRunAction `json:"actions,omitempty"`
+}
+
+type githubCheckRunOutput struct {
+	Title       string                     `json:"title"`
+	Summary     string                     `json:"summary"`
+	Text        string                     `json:"text,omitempty"`
+	Annotations []githubCheckRunAnnotation `json:"annotations,omitempty"`
+}
+
+type githubCheckRunAnnotation struct {
+	Path            string `json:"path"`
+	StartLine       int    `json:"start_line"`
+	EndLine         int    `json:"end_line"`
+	AnnotationLevel string `json:"annotation_level"`
+	Title           string `json:"title,omitempty"`
+	Message         string `json:"message"`
+	RawDetails      string `json:"raw_details,omitempty"`
+}
+
+type githubCheckRunAction struct {
+	Label       string `json:"label"`
+	Description string `json:"description"`
+	Identifier  string `json:"identifier"`
+}
+
+// CreateCheckRun creates a check run on the commit of the check run
+func (p *GitHubProvider) CreateCheckRun(owner string, repo string, checkRun *GitCheckRun) (*GitCheckRun, error) {
+	u := fmt.Sprintf("repos/%s/%s/check-runs", owner, repo)
+	return p.sendCheckRun(http.MethodPost, u, checkRun)
+}
+
+// UpdateCheckRun updates the check run with the ID of the given check run
+func (p *GitHubProvider) UpdateCheckRun(owner string, repo string, checkRun *GitCheckRun) (*GitCheckRun, error) {
+	if checkRun.ID == 0 {
+		return nil, fmt.Errorf("missing the ID of check run %s on repository %s/%s", checkRun.Name, owner, repo)
+	}
+	u := fmt.Sprintf("repos/%s/%s/check-runs/%d", owner, repo, checkRun.ID)
+	return p.sendCheckRun(http.MethodPatch, u, checkRun)
+}
+
+func (p *GitHubProvider) sendCheckRun(method string, u string, checkRun *GitCheckRun) (*GitCheckRun, error) {
+	req, err := p.Client.NewRequest(method, u, toGitHubCheckRun(checkRun))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create the request for check run %s", checkRun.Name)
+	}
+	req.Header.Set("Accept", checksPreviewMediaType)
+	result := &githubCheckRun{}
+	_, err = p.Client.Do(p.Context, req, result)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to send check run %s", checkRun.Name)
+	}
+	answer := *checkRun
+	answer.ID = result.ID
+	return &answer, nil
+}
+
+func toGitHubCheckRun(checkRun *GitCheckRun) *githubCheckRun {
+	answer := &githubCheckRun{
+		Name:        checkRun.Name,
+		HeadSHA:     checkRun.HeadSHA,
+		ExternalID:  checkRun.ExternalID,
+		DetailsURL:  checkRun.DetailsURL,
+		Status:      checkRun.Status,
+		Conclusion:  checkRun.Conclusion,
+		StartedAt:   checkRun.StartedAt,
+		CompletedAt: checkRun.CompletedAt,
+	}
+	if checkRun.Title != "" || checkRun.Summary != "" {
+		text := checkRun.Text
+		if len(text) > maxCheckRunText {
+			text = text[:maxCheckRunText]
+		}
+		answer.Output = &githubCheckRunOutput{
+			Title:   checkRun.Title,
+			Summary: checkRun.Summary,
+			Text:    text,
+		}
+		for i, a := range checkRun.Annotations {
+			if i >= maxCheckRunAnnotations {
+				break
+			}
+			answer.Output.Annotations = append(answer.Output.Annotations, githubCheckRunAnnotation{
+				Path:            a.Path,
+				StartLine:       a.StartLine,
+				EndLine:         a.EndLine,
+				AnnotationLevel: a.Level,
+				Title:           a.Title,
+				Message:         a.Message,
+				RawDetails:      a.RawDetails,
+			})
+		}
+	}
+	for i, a := range checkRun.Actions {
+		if i >= maxCheckRunActions {
+			break
+		}
+		answer.Actions = append(answer.Actions, githubCheckRunAction{
+			Label:       a.Label,
+			Description: a.Description,
+			Identifier:  a.Identifier,
+		})
+	}
+	return answer
+}
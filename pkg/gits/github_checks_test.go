@@ -0,0 +1,78 @@
+package gits
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-github/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGitHubCheckRuns(t *testing.T) {
+	t.Parallel()
+
+	requests := []*githubCheckRun{}
+	paths := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, checksPreviewMediaType, r.Header.Get("Accept"))
+		data, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		checkRun := &githubCheckRun{}
+		require.NoError(t, json.Unmarshal(data, checkRun))
+		requests = append(requests, checkRun)
+		paths = append(paths, r.Method+" "+r.URL.Path)
+		_, err = w.Write([]byte(`{"id": 42}`))
+		require.NoError(t, err)
+	}))
+	defer server.Close()
+
+	client := github.NewClient(nil)
+	baseURL, err := url.Parse(server.URL + "/")
+	require.NoError(t, err)
+	client.BaseURL = baseURL
+	provider := &GitHubProvider{
+		Client:  client,
+		Context: context.Background(),
+	}
+
+	checkRun := &GitCheckRun{
+		Name:       "jenkins-x",
+		HeadSHA:    "abc123",
+		ExternalID: "myorg-myapp-pr-1-3",
+		Status:     CheckRunStatusInProgress,
+		Title:      "Pipeline running",
+		Summary:    "The pipeline is running",
+	}
+	for i := 0; i < 60; i++ {
+		checkRun.Annotations = append(checkRun.Annotations, GitCheckRunAnnotation{
+			Path:      "jenkins-x.yml",
+			StartLine: 1,
+			EndLine:   1,
+			Level:     CheckRunAnnotationNotice,
+			Message:   "stage",
+		})
+	}
+	created, err := provider.CreateCheckRun("myorg", "myapp", checkRun)
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), created.ID)
+
+	created.Status = CheckRunStatusCompleted
+	created.Conclusion = CheckRunConclusionFailure
+	created.Actions = []GitCheckRunAction{{Label: "Re-run", Description: "Re-run the pipeline", Identifier: CheckRunActionRerun}}
+	_, err = provider.UpdateCheckRun("myorg", "myapp", created)
+	require.NoError(t, err)
+
+	require.Len(t, requests, 2)
+	assert.Equal(t, []string{"POST /repos/myorg/myapp/check-runs", "PATCH /repos/myorg/myapp/check-runs/42"}, paths)
+	assert.Len(t, requests[0].Output.Annotations, maxCheckRunAnnotations)
+	assert.Equal(t, "", requests[0].Conclusion)
+	assert.Equal(t, CheckRunConclusionFailure, requests[1].Conclusion)
+	require.Len(t, requests[1].Actions, 1)
+	assert.Equal(t, CheckRunActionRerun, requests[1].Actions[0].Identifier)
+}
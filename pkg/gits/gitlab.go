@@ -30,7 +30,7 @@ type GitlabProvider struct {
 
 func NewGitlabProvider(server *auth.AuthServer, user *auth.UserAuth, git Gitter) (GitProvider, error) {
 	u := server.URL
-	c := gitlab.NewClient(nil, user.ApiToken)
+	c := gitlab.NewClient(RateLimitHTTPClient(), user.ApiToken)
 	if !IsGitLabServerURL(u) {
 		if err := c.SetBaseURL(u); err != nil {
 			return nil, err
@@ -524,6 +524,45 @@ func (g *GitlabProvider) MergePullRequest(pr *GitPullRequest, message string) er
 	return err
 }
 
+// EnableAutoMerge merges the merge request as soon as its pipeline succeeds
+func (g *GitlabProvider) EnableAutoMerge(pr *GitPullRequest, message string) error {
+	pid, err := g.projectId(pr.Owner, g.Username, pr.Repo)
+	if err != nil {
+		return err
+	}
+
+	mergeWhenPipelineSucceeds := true
+	opt := &gitlab.AcceptMergeRequestOptions{
+		MergeCommitMessage:        &message,
+		MergeWhenPipelineSucceeds: &mergeWhenPipelineSucceeds,
+	}
+
+	_, _, err = g.Client.MergeRequests.AcceptMergeRequest(pid, *pr.Number, opt)
+	return err
+}
+
+// ListPullRequestApprovals returns the users who approved the merge request
+func (g *GitlabProvider) ListPullRequestApprovals(pr *GitPullRequest) ([]string, error) {
+	if pr.Number == nil {
+		return nil, fmt.Errorf("Missing Number for GitPullRequest %#v", pr)
+	}
+	pid, err := g.projectId(pr.Owner, g.Username, pr.Repo)
+	if err != nil {
+		return nil, err
+	}
+	approvals, _, err := g.Client.MergeRequests.GetMergeRequestApprovals(pid, *pr.Number)
+	if err != nil {
+		return nil, errors2.Wrapf(err, "failed to get the approvals of merge request %s", pr.URL)
+	}
+	answer := []string{}
+	for _, approver := range approvals.ApprovedBy {
+		if approver != nil && approver.User != nil {
+			answer = append(answer, approver.User.Username)
+		}
+	}
+	return answer, nil
+}
+
 func (g *GitlabProvider) CreateWebHook(data *GitWebHookArguments) error {
 	pid, err := g.projectId(data.Owner, g.Username, data.Repo.Name)
 	if err != nil {
@@ -102,6 +102,9 @@ func configureGitlabMock(suite *GitlabProviderSuite, mux *http.ServeMux) {
 			"GET": "merge-request.json",
 			"PUT": "update-merge-request.json",
 		},
+		fmt.Sprintf("/api/v4/projects/%s/merge_requests/%d/approvals", gitlabProjectID, gitlabMergeRequestID): util.MethodMap{
+			"GET": "merge-request-approvals.json",
+		},
 		fmt.Sprintf("/api/v4/projects/%s/merge_requests", gitlabProjectID): util.MethodMap{
 			"POST": "create-merge-request.json",
 		},
@@ -227,6 +230,19 @@ func (suite *GitlabProviderSuite) TestUpdatePullRequest() {
 	suite.Require().Equal(pr.Owner, gitlabUserName)
 }
 
+func (suite *GitlabProviderSuite) TestListPullRequestApprovals() {
+	number := gitlabMergeRequestID
+	pr := &gits.GitPullRequest{
+		Owner:  gitlabUserName,
+		Repo:   gitlabProjectName,
+		Number: &number,
+	}
+	approvals, err := suite.provider.ListPullRequestApprovals(pr)
+
+	suite.Require().Nil(err)
+	suite.Require().Equal([]string{"approver"}, approvals)
+}
+
 // In order for 'go test' to run this suite, we need to create
 // a normal test function and pass our suite to suite.Run
 func TestGitlabProviderSuite(t *testing.T) {
@@ -15,6 +15,8 @@ import (
 
 	uuid "github.com/satori/go.uuid"
 
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/audit"
 	"github.com/jenkins-x/jx/pkg/util"
 
 	"github.com/jenkins-x/jx/pkg/log"
@@ -372,6 +374,11 @@ func PushRepoAndCreatePullRequest(dir string, upstreamRepo *GitRepository, forkR
 			return nil, errors.Wrapf(err, "creating pull request with arguments %v", gha.String())
 		}
 		log.Logger().Infof("Created Pull Request: %s", util.ColorInfo(pr.URL))
+		audit.Record(v1.AuditActionPullRequestRaised, pr.URL, "", gha.Title, map[string]string{
+			"repository": upstreamRepo.Organisation + "/" + upstreamRepo.Name,
+			"base":       base,
+			"head":       gha.Head,
+		})
 	}
 
 	prInfo := &PullRequestInfo{
@@ -23,6 +23,16 @@ type OrganisationChecker interface {
 	IsUserInOrganisation(user string, organisation string) (bool, error)
 }
 
+// WebHookDeleter deletes webhooks from repositories. Not every git provider supports it
+type WebHookDeleter interface {
+	DeleteWebHook(owner string, repo string, id int64) error
+}
+
+// RepositoryArchiver archives repositories making them read only. Not every git provider supports it
+type RepositoryArchiver interface {
+	ArchiveRepository(org string, name string) error
+}
+
 // GitProvider is the interface for abstracting use of different git provider APIs
 //go:generate pegomock generate github.com/jenkins-x/jx/pkg/gits GitProvider -o mocks/git_provider.go
 type GitProvider interface {
@@ -274,6 +284,7 @@ type Gitter interface {
 	Tags(dir string) ([]string, error)
 	FilterTags(dir string, filter string) ([]string, error)
 	CreateTag(dir string, tag string, msg string) error
+	CreateSignedTag(dir string, tag string, msg string, key string) error
 	GetLatestCommitSha(dir string) (string, error)
 	GetFirstCommitSha(dir string) (string, error)
 	GetCommits(dir string, start string, end string) ([]GitCommit, error)
@@ -48,6 +48,7 @@ type PullRequestOperation struct {
 	AuthorName    string
 	AuthorEmail   string
 	SkipAutoMerge bool
+	AutoMerge     bool
 }
 
 // ChangeFilesFn is the function called to create the pull request
@@ -94,6 +95,12 @@ func (o *PullRequestOperation) CreatePullRequest(kind string, update ChangeFiles
 		if err != nil {
 			return nil, errors.Wrapf(err, "failed to create PR for base %s and head branch %s from temp dir %s", o.Base, details.BranchName, dir)
 		}
+		if o.AutoMerge && result != nil && result.PullRequest != nil {
+			_, err = gits.EnableAutoMerge(provider, result.PullRequest, details.Title)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to enable auto merge of PR %s", result.PullRequest.URL)
+			}
+		}
 	}
 	return result, nil
 }
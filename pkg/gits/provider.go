@@ -117,6 +117,13 @@ type GitIssue struct {
 	Assignees     []GitUser
 }
 
+// GitIssueComment a comment on an issue or pull request
+type GitIssueComment struct {
+	User      GitUser
+	Body      string
+	CreatedAt *time.Time
+}
+
 type GitUser struct {
 	URL       string
 	Login     string
@@ -231,6 +238,16 @@ func (pr *GitPullRequest) IsClosed() bool {
 	return pr.ClosedAt != nil
 }
 
+// HasLabel returns true if the PullRequest has the given label
+func (pr *GitPullRequest) HasLabel(label string) bool {
+	for _, l := range pr.Labels {
+		if l != nil && util.DereferenceString(l.Name) == label {
+			return true
+		}
+	}
+	return false
+}
+
 // NumberString returns the string representation of the Pull Request number or blank if its missing
 func (pr *GitPullRequest) NumberString() string {
 	n := pr.Number
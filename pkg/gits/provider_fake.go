@@ -54,6 +54,7 @@ type FakePullRequest struct {
 	PullRequest *GitPullRequest
 	Commits     []*FakeCommit
 	Comment     string
+	Approvals   []string
 }
 
 type FakeIssue struct {
@@ -350,6 +351,24 @@ func (f *FakeProvider) GetPullRequest(owner string, repo *GitRepository, number
 	return nil, fmt.Errorf("repository with name '%s' not found", repoName)
 }
 
+// ListPullRequestApprovals returns the approvals of the fake pull request
+func (f *FakeProvider) ListPullRequestApprovals(pr *GitPullRequest) ([]string, error) {
+	repos, ok := f.Repositories[pr.Owner]
+	if !ok {
+		return nil, fmt.Errorf("no repositories found for '%s'", pr.Owner)
+	}
+	for _, r := range repos {
+		if r.GitRepo.Name == pr.Repo {
+			fakePR, ok := r.PullRequests[*pr.Number]
+			if !ok {
+				return nil, fmt.Errorf("pull request with id '%d' not found", *pr.Number)
+			}
+			return fakePR.Approvals, nil
+		}
+	}
+	return nil, fmt.Errorf("repository with name '%s' not found", pr.Repo)
+}
+
 func (f *FakeProvider) ListOpenPullRequests(owner string, repo string) ([]*GitPullRequest, error) {
 	answer := make([]*GitPullRequest, 0)
 	repos, ok := f.Repositories[owner]
@@ -503,8 +522,49 @@ func (p *FakeProvider) ListWebHooks(owner string, repo string) ([]*GitWebHookArg
 	return p.WebHooks, nil
 }
 
+// UpdateWebHook replaces the webhook with the same ID or, if the ID is not set, the webhook of the repository with the
+// existing URL
 func (p *FakeProvider) UpdateWebHook(data *GitWebHookArguments) error {
-	return fmt.Errorf("not implemented!")
+	for i, hook := range p.WebHooks {
+		if data.ID != 0 && hook.ID == data.ID {
+			p.WebHooks[i] = data
+			return nil
+		}
+		if data.ID == 0 && hook.Owner == data.Owner && hook.URL == data.ExistingURL && sameRepository(hook.Repo, data.Repo) {
+			p.WebHooks[i] = data
+			return nil
+		}
+	}
+	return fmt.Errorf("webhook '%s' not found for '%s'", data.ExistingURL, data.Owner)
+}
+
+// DeleteWebHook deletes the webhook with the ID
+func (p *FakeProvider) DeleteWebHook(owner string, repo string, id int64) error {
+	for i, hook := range p.WebHooks {
+		if hook.ID == id {
+			p.WebHooks = append(p.WebHooks[:i], p.WebHooks[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("webhook %d not found for '%s/%s'", id, owner, repo)
+}
+
+// ArchiveRepository marks the repository as archived
+func (f *FakeProvider) ArchiveRepository(org string, name string) error {
+	for _, repo := range f.Repositories[org] {
+		if repo.GitRepo.Name == name {
+			repo.GitRepo.Archived = true
+			return nil
+		}
+	}
+	return fmt.Errorf("repository '%s' not found within the organization '%s'", name, org)
+}
+
+func sameRepository(a *GitRepository, b *GitRepository) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Name == b.Name
 }
 
 func (f *FakeProvider) IsGitHub() bool {
@@ -865,6 +925,7 @@ func NewFakeRepository(owner string, repoName string, addFiles func(dir string)
 			Organisation: owner,
 		},
 		PullRequests: map[int]*FakePullRequest{},
+		Issues:       map[int]*FakeIssue{},
 		Commits:      []*FakeCommit{},
 		Releases:     make(map[string]*GitRelease),
 	}
@@ -0,0 +1,363 @@
+package gits
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/util"
+)
+
+const (
+	// MaxConcurrencyEnvVar the environment variable to override the maximum number of concurrent git provider requests
+	MaxConcurrencyEnvVar = "JX_GIT_PROVIDER_MAX_CONCURRENCY"
+
+	defaultMaxConcurrency = 4
+	defaultMaxRetries     = 5
+	defaultInitialBackOff = time.Second
+	defaultMaxWait        = 2 * time.Minute
+	// secondaryRateLimitBackOff the wait recommended by GitHub when a secondary rate limit is hit without a Retry-After
+	secondaryRateLimitBackOff = time.Minute
+	defaultMaxCacheEntries    = 1000
+)
+
+var (
+	sharedRateLimitTransport     *RateLimitTransport
+	sharedRateLimitTransportOnce sync.Once
+)
+
+// RateLimitTransport wraps the transport of the git provider clients so that bulk operations such as webhook
+// reconciliation or dependency update pull requests do not exhaust the API quotas. It:
+//
+// * limits the number of concurrent requests
+// * retries with exponential backoff on server errors
+// * waits for the rate limit to reset on primary and secondary (abuse) rate limit errors
+// * caches GET responses and sends conditional requests using their ETags, which GitHub does not count against the
+// rate limit when the resource is not modified
+type RateLimitTransport struct {
+	Base           http.RoundTripper
+	MaxRetries     int
+	InitialBackOff time.Duration
+	MaxWait        time.Duration
+
+	semaphore chan struct{}
+	cache     *etagCache
+	sleep     func(ctx context.Context, d time.Duration) error
+}
+
+// NewRateLimitTransport creates a new rate limit aware transport wrapping the given base transport
+func NewRateLimitTransport(base http.RoundTripper, maxConcurrency int) *RateLimitTransport {
+	if maxConcurrency <= 0 {
+		maxConcurrency = defaultMaxConcurrency
+	}
+	return &RateLimitTransport{
+		Base:           base,
+		MaxRetries:     defaultMaxRetries,
+		InitialBackOff: defaultInitialBackOff,
+		MaxWait:        defaultMaxWait,
+		semaphore:      make(chan struct{}, maxConcurrency),
+		cache:          newETagCache(defaultMaxCacheEntries),
+		sleep:          sleepContext,
+	}
+}
+
+// SharedRateLimitTransport returns the rate limit aware transport shared by all git provider clients so that the
+// concurrency limit and the response cache apply across providers
+func SharedRateLimitTransport() *RateLimitTransport {
+	sharedRateLimitTransportOnce.Do(func() {
+		maxConcurrency := defaultMaxConcurrency
+		if value := strings.TrimSpace(os.Getenv(MaxConcurrencyEnvVar)); value != "" {
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				log.Logger().Warnf("Ignoring invalid value %s of $%s", value, MaxConcurrencyEnvVar)
+			} else {
+				maxConcurrency = n
+			}
+		}
+		sharedRateLimitTransport = NewRateLimitTransport(util.DefaultTransport(), maxConcurrency)
+	})
+	return sharedRateLimitTransport
+}
+
+// RateLimitHTTPClient returns a HTTP client for git provider API calls using the shared rate limit aware transport
+func RateLimitHTTPClient() *http.Client {
+	return &http.Client{Transport: SharedRateLimitTransport()}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *RateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	err := t.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	held := true
+	defer func() {
+		if held {
+			t.release()
+		}
+	}()
+
+	cacheKey := ""
+	if req.Method == http.MethodGet && req.Header.Get("If-None-Match") == "" && req.Header.Get("Range") == "" {
+		// lets not keep the tokens in memory as part of the cache keys
+		cacheKey = fmt.Sprintf("%x %s", sha256.Sum256([]byte(req.Header.Get("Authorization"))), req.URL.String())
+	}
+	cached := t.cache.get(cacheKey)
+	replayable := req.Body == nil || req.GetBody != nil
+
+	for attempt := 0; ; attempt++ {
+		r, err := t.prepareRequest(req, attempt, cached)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := t.Base.RoundTrip(r)
+		if err != nil {
+			if attempt >= t.MaxRetries || !replayable || !isIdempotent(req.Method) {
+				return nil, err
+			}
+			wait := t.backOff(attempt)
+			log.Logger().Debugf("Retrying %s %s in %s due to %s", req.Method, req.URL, wait, err)
+			held = false
+			if err := t.wait(ctx, wait); err != nil {
+				return nil, err
+			}
+			held = true
+			continue
+		}
+		if cached != nil && resp.StatusCode == http.StatusNotModified {
+			resp.Body.Close()
+			return cached.response(req), nil
+		}
+		wait, retry := t.retryWait(req, resp, attempt)
+		if retry && attempt < t.MaxRetries && replayable {
+			resp.Body.Close()
+			log.Logger().Debugf("Retrying %s %s in %s after status %d", req.Method, req.URL, wait, resp.StatusCode)
+			held = false
+			if err := t.wait(ctx, wait); err != nil {
+				return nil, err
+			}
+			held = true
+			continue
+		}
+		if cacheKey != "" && resp.StatusCode == http.StatusOK && resp.Header.Get("ETag") != "" {
+			body, err := ioutil.ReadAll(resp.Body)
+			resp.Body.Close()
+			if err != nil {
+				return nil, err
+			}
+			resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+			t.cache.put(cacheKey, &etagEntry{
+				etag:       resp.Header.Get("ETag"),
+				statusCode: resp.StatusCode,
+				status:     resp.Status,
+				header:     cloneHeader(resp.Header),
+				body:       body,
+			})
+		}
+		return resp, nil
+	}
+}
+
+// acquire waits for one of the concurrent request slots
+func (t *RateLimitTransport) acquire(ctx context.Context) error {
+	select {
+	case t.semaphore <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (t *RateLimitTransport) release() {
+	<-t.semaphore
+}
+
+// wait releases the concurrent request slot while waiting to retry so that other requests are not blocked by the
+// backoff and then acquires a slot again. The slot is not held if an error is returned
+func (t *RateLimitTransport) wait(ctx context.Context, d time.Duration) error {
+	t.release()
+	err := t.sleep(ctx, d)
+	if err != nil {
+		return err
+	}
+	return t.acquire(ctx)
+}
+
+// prepareRequest returns a copy of the request for the given attempt adding the ETag of any cached response
+func (t *RateLimitTransport) prepareRequest(req *http.Request, attempt int, cached *etagEntry) (*http.Request, error) {
+	r := new(http.Request)
+	*r = *req
+	r.Header = cloneHeader(req.Header)
+	if cached != nil {
+		r.Header.Set("If-None-Match", cached.etag)
+	}
+	if attempt > 0 && req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		r.Body = body
+	}
+	return r, nil
+}
+
+// retryWait returns how long to wait before retrying the request and whether it should be retried at all
+func (t *RateLimitTransport) retryWait(req *http.Request, resp *http.Response, attempt int) (time.Duration, bool) {
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusForbidden:
+		if wait, ok := retryAfter(resp); ok {
+			return wait, wait <= t.MaxWait
+		}
+		if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+			reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64)
+			if err != nil {
+				return 0, false
+			}
+			wait := time.Until(time.Unix(reset, 0)) + time.Second
+			if wait < 0 {
+				wait = 0
+			}
+			if wait > t.MaxWait {
+				log.Logger().Warnf("The git provider rate limit is exhausted until %s", time.Unix(reset, 0).Format(time.RFC1123))
+				return 0, false
+			}
+			return wait, true
+		}
+		if isSecondaryRateLimit(resp) {
+			return secondaryRateLimitBackOff, secondaryRateLimitBackOff <= t.MaxWait
+		}
+		if resp.StatusCode == http.StatusTooManyRequests {
+			return t.backOff(attempt), true
+		}
+		return 0, false
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return t.backOff(attempt), isIdempotent(req.Method)
+	}
+	return 0, false
+}
+
+func (t *RateLimitTransport) backOff(attempt int) time.Duration {
+	wait := t.InitialBackOff << uint(attempt)
+	if wait <= 0 || wait > t.MaxWait {
+		wait = t.MaxWait
+	}
+	return wait
+}
+
+// retryAfter returns the wait of the Retry-After header in seconds if there is one
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+	seconds, err := strconv.Atoi(value)
+	if err != nil || seconds < 0 {
+		return 0, false
+	}
+	return time.Duration(seconds) * time.Second, true
+}
+
+// isSecondaryRateLimit returns true if the response is a GitHub secondary rate limit or abuse detection error. The body
+// is restored so that it can still be read by the caller
+func isSecondaryRateLimit(resp *http.Response) bool {
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return false
+	}
+	text := strings.ToLower(string(body))
+	return strings.Contains(text, "secondary rate limit") || strings.Contains(text, "abuse detection")
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+func cloneHeader(header http.Header) http.Header {
+	answer := make(http.Header, len(header))
+	for k, v := range header {
+		answer[k] = append([]string(nil), v...)
+	}
+	return answer
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+type etagEntry struct {
+	etag       string
+	statusCode int
+	status     string
+	header     http.Header
+	body       []byte
+}
+
+func (e *etagEntry) response(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        e.status,
+		StatusCode:    e.statusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        cloneHeader(e.header),
+		Body:          ioutil.NopCloser(bytes.NewReader(e.body)),
+		ContentLength: int64(len(e.body)),
+		Request:       req,
+	}
+}
+
+// etagCache a bounded in memory cache of responses with ETags
+type etagCache struct {
+	lock       sync.Mutex
+	maxEntries int
+	entries    map[string]*etagEntry
+}
+
+func newETagCache(maxEntries int) *etagCache {
+	return &etagCache{
+		maxEntries: maxEntries,
+		entries:    map[string]*etagEntry{},
+	}
+}
+
+func (c *etagCache) get(key string) *etagEntry {
+	if key == "" {
+		return nil
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.entries[key]
+}
+
+func (c *etagCache) put(key string, entry *etagEntry) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if len(c.entries) >= c.maxEntries {
+		// lets keep it simple and start again rather than tracking usage
+		c.entries = map[string]*etagEntry{}
+	}
+	c.entries[key] = entry
+}
@@ -0,0 +1,248 @@
+package gits
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRateLimitTransport(maxConcurrency int) (*RateLimitTransport, *[]time.Duration) {
+	waits := &[]time.Duration{}
+	var lock sync.Mutex
+	t := NewRateLimitTransport(http.DefaultTransport, maxConcurrency)
+	t.sleep = func(ctx context.Context, d time.Duration) error {
+		lock.Lock()
+		defer lock.Unlock()
+		*waits = append(*waits, d)
+		return nil
+	}
+	return t, waits
+}
+
+func TestRateLimitTransportUsesETags(t *testing.T) {
+	t.Parallel()
+
+	var notModified int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			atomic.AddInt32(&notModified, 1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte("hello"))
+	}))
+	defer server.Close()
+
+	transport, _ := newTestRateLimitTransport(1)
+	client := &http.Client{Transport: transport}
+	for i := 0; i < 3; i++ {
+		resp, err := client.Get(server.URL + "/repos")
+		require.NoError(t, err)
+		body, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "hello", string(body))
+	}
+	assert.Equal(t, int32(2), atomic.LoadInt32(&notModified), "should have used conditional requests after the first one")
+}
+
+func TestRateLimitTransportRetries(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name          string
+		method        string
+		header        map[string]string
+		status        int
+		body          string
+		expectedCalls int32
+		expectedWaits []time.Duration
+	}{
+		{
+			name:          "retry after",
+			method:        http.MethodGet,
+			header:        map[string]string{"Retry-After": "7"},
+			status:        http.StatusForbidden,
+			expectedCalls: 2,
+			expectedWaits: []time.Duration{7 * time.Second},
+		},
+		{
+			name:          "secondary rate limit",
+			method:        http.MethodPost,
+			status:        http.StatusForbidden,
+			body:          `{"message": "You have exceeded a secondary rate limit."}`,
+			expectedCalls: 2,
+			expectedWaits: []time.Duration{secondaryRateLimitBackOff},
+		},
+		{
+			name:          "server error",
+			method:        http.MethodGet,
+			status:        http.StatusServiceUnavailable,
+			expectedCalls: 2,
+			expectedWaits: []time.Duration{time.Second},
+		},
+		{
+			name:          "server error on non idempotent request",
+			method:        http.MethodPost,
+			status:        http.StatusServiceUnavailable,
+			expectedCalls: 1,
+		},
+		{
+			name:          "forbidden",
+			method:        http.MethodGet,
+			status:        http.StatusForbidden,
+			body:          `{"message": "Must have admin rights to Repository."}`,
+			expectedCalls: 1,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var calls int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				ioutil.ReadAll(r.Body)
+				if atomic.AddInt32(&calls, 1) == 1 {
+					for k, v := range tc.header {
+						w.Header().Set(k, v)
+					}
+					w.WriteHeader(tc.status)
+					w.Write([]byte(tc.body))
+					return
+				}
+				w.Write([]byte("ok"))
+			}))
+			defer server.Close()
+
+			transport, waits := newTestRateLimitTransport(1)
+			req, err := http.NewRequest(tc.method, server.URL, strings.NewReader("{}"))
+			require.NoError(t, err)
+			resp, err := (&http.Client{Transport: transport}).Do(req)
+			require.NoError(t, err)
+			resp.Body.Close()
+
+			assert.Equal(t, tc.expectedCalls, atomic.LoadInt32(&calls))
+			assert.Equal(t, tc.expectedWaits, *waits)
+			if tc.expectedCalls == 1 {
+				assert.Equal(t, tc.status, resp.StatusCode)
+			} else {
+				assert.Equal(t, http.StatusOK, resp.StatusCode)
+			}
+		})
+	}
+}
+
+func TestRateLimitTransportLimitsConcurrency(t *testing.T) {
+	t.Parallel()
+
+	var active, maxActive int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&active, 1)
+		for {
+			m := atomic.LoadInt32(&maxActive)
+			if n <= m || atomic.CompareAndSwapInt32(&maxActive, m, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		atomic.AddInt32(&active, -1)
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	transport, _ := newTestRateLimitTransport(2)
+	client := &http.Client{Transport: transport}
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := client.Post(server.URL, "application/json", strings.NewReader("{}"))
+			if assert.NoError(t, err) {
+				resp.Body.Close()
+			}
+		}()
+	}
+	wg.Wait()
+	assert.True(t, atomic.LoadInt32(&maxActive) <= 2, "should have had at most 2 concurrent requests but had %d", maxActive)
+}
+
+func TestRateLimitTransportReleasesSlotWhileWaiting(t *testing.T) {
+	t.Parallel()
+
+	var limited int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/limited" && atomic.AddInt32(&limited, 1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	sleeping := make(chan struct{})
+	resume := make(chan struct{})
+	transport := NewRateLimitTransport(http.DefaultTransport, 1)
+	transport.sleep = func(ctx context.Context, d time.Duration) error {
+		close(sleeping)
+		<-resume
+		return nil
+	}
+	client := &http.Client{Transport: transport, Timeout: 10 * time.Second}
+
+	limitedDone := make(chan error)
+	go func() {
+		resp, err := client.Get(server.URL + "/limited")
+		if err == nil {
+			resp.Body.Close()
+		}
+		limitedDone <- err
+	}()
+	<-sleeping
+
+	resp, err := client.Get(server.URL + "/other")
+	require.NoError(t, err, "the request should not be blocked by the rate limited request waiting to retry")
+	resp.Body.Close()
+
+	close(resume)
+	assert.NoError(t, <-limitedDone)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&limited))
+}
+
+func TestRateLimitTransportCacheKeyDoesNotContainToken(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte("hello"))
+	}))
+	defer server.Close()
+
+	transport, _ := newTestRateLimitTransport(1)
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/repos", nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "token s3cr3t")
+	resp, err := transport.RoundTrip(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	transport.cache.lock.Lock()
+	defer transport.cache.lock.Unlock()
+	require.Len(t, transport.cache.entries, 1)
+	for key := range transport.cache.entries {
+		assert.NotContains(t, key, "s3cr3t")
+	}
+}
@@ -0,0 +1,151 @@
+package testfixtures
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jenkins-x/jx/pkg/gits"
+	"github.com/pkg/errors"
+)
+
+// RepositoryOption populates a fake repository of a scenario
+type RepositoryOption func(fixture *Fixture, repo *gits.FakeRepository) error
+
+// PullRequestOption modifies a fake pull request of a scenario
+type PullRequestOption func(pr *gits.FakePullRequest)
+
+// Commit adds a commit with the given status to the default branch of the repository
+func Commit(sha string, message string, status gits.CommitStatus) RepositoryOption {
+	return func(fixture *Fixture, repo *gits.FakeRepository) error {
+		repo.Commits = append(repo.Commits, &gits.FakeCommit{
+			Commit: &gits.GitCommit{
+				SHA:     sha,
+				Message: message,
+				URL:     fmt.Sprintf("%s/commits/%s", repo.GitRepo.HTMLURL, sha),
+				Branch:  "master",
+			},
+			Status: status,
+		})
+		return nil
+	}
+}
+
+// PullRequest opens a pull request from the head branch into the base branch of the repository. Pull requests are
+// numbered in the order they are added, sharing the numbers with issues
+func PullRequest(title string, head string, base string, options ...PullRequestOption) RepositoryOption {
+	return func(fixture *Fixture, repo *gits.FakeRepository) error {
+		pr, err := fixture.Provider.CreatePullRequest(&gits.GitPullRequestArguments{
+			GitRepository: repo.GitRepo,
+			Title:         title,
+			Head:          head,
+			Base:          base,
+		})
+		if err != nil {
+			return errors.Wrapf(err, "creating pull request %s", title)
+		}
+		fakePR := repo.PullRequests[*pr.Number]
+		for _, option := range options {
+			option(fakePR)
+		}
+		return nil
+	}
+}
+
+// Issue opens an issue on the repository
+func Issue(title string, labels ...string) RepositoryOption {
+	return func(fixture *Fixture, repo *gits.FakeRepository) error {
+		state := "open"
+		issue := &gits.GitIssue{
+			URL:   fmt.Sprintf("%s/issues", repo.GitRepo.HTMLURL),
+			Owner: repo.Owner,
+			Repo:  repo.Name(),
+			Title: title,
+			State: &state,
+		}
+		for i := range labels {
+			issue.Labels = append(issue.Labels, gits.GitLabel{Name: labels[i]})
+		}
+		_, err := fixture.Provider.CreateIssue(repo.Owner, repo.Name(), issue)
+		return err
+	}
+}
+
+// Release adds a release for the tag to the repository
+func Release(tag string, body string) RepositoryOption {
+	return func(fixture *Fixture, repo *gits.FakeRepository) error {
+		repo.Releases[tag] = &gits.GitRelease{
+			ID:      int64(len(repo.Releases) + 1),
+			Name:    tag,
+			TagName: tag,
+			Body:    body,
+			URL:     fmt.Sprintf("%s/releases/tag/%s", repo.GitRepo.HTMLURL, tag),
+			HTMLURL: fmt.Sprintf("%s/releases/tag/%s", repo.GitRepo.HTMLURL, tag),
+		}
+		return nil
+	}
+}
+
+// WebHook registers a webhook for the URL on the repository
+func WebHook(url string, secret string) RepositoryOption {
+	return func(fixture *Fixture, repo *gits.FakeRepository) error {
+		return fixture.Provider.CreateWebHook(&gits.GitWebHookArguments{
+			ID:     int64(len(fixture.Provider.WebHooks) + 1),
+			Owner:  repo.Owner,
+			Repo:   repo.GitRepo,
+			URL:    url,
+			Secret: secret,
+		})
+	}
+}
+
+// Fork marks the repository as a fork
+func Fork() RepositoryOption {
+	return func(fixture *Fixture, repo *gits.FakeRepository) error {
+		repo.GitRepo.Fork = true
+		return nil
+	}
+}
+
+// Author sets the login of the author of the pull request
+func Author(login string) PullRequestOption {
+	return func(pr *gits.FakePullRequest) {
+		pr.PullRequest.Author = &gits.GitUser{Login: login}
+	}
+}
+
+// Approvals sets the users who approved the pull request
+func Approvals(users ...string) PullRequestOption {
+	return func(pr *gits.FakePullRequest) {
+		pr.Approvals = append(pr.Approvals, users...)
+	}
+}
+
+// Labels adds labels to the pull request
+func Labels(labels ...string) PullRequestOption {
+	return func(pr *gits.FakePullRequest) {
+		for i := range labels {
+			pr.PullRequest.Labels = append(pr.PullRequest.Labels, &gits.Label{Name: &labels[i]})
+		}
+	}
+}
+
+// Status sets the status of the last commit of the pull request
+func Status(status gits.CommitStatus) PullRequestOption {
+	return func(pr *gits.FakePullRequest) {
+		if len(pr.Commits) > 0 {
+			pr.Commits[len(pr.Commits)-1].Status = status
+		}
+	}
+}
+
+// Merged marks the pull request as merged at the given time
+func Merged(mergedAt time.Time) PullRequestOption {
+	return func(pr *gits.FakePullRequest) {
+		merged := true
+		state := "closed"
+		pr.PullRequest.Merged = &merged
+		pr.PullRequest.MergedAt = &mergedAt
+		pr.PullRequest.ClosedAt = &mergedAt
+		pr.PullRequest.State = &state
+	}
+}
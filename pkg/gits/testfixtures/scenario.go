@@ -0,0 +1,175 @@
+// Package testfixtures provides in memory fixtures of gits.GitProvider and gits.Gitter populated from scenarios so
+// that commands, extensions and plugins can be tested without talking to a real git provider
+package testfixtures
+
+import (
+	"github.com/jenkins-x/jx/pkg/gits"
+	"github.com/pkg/errors"
+)
+
+// Scenario builds the repositories, pull requests, commits, releases and webhooks of a fake git provider along with
+// the state of a fake local git clone
+type Scenario struct {
+	providerType gits.FakeProviderType
+	username     string
+	repositories []scenarioRepository
+	gitter       gits.GitFake
+}
+
+type scenarioRepository struct {
+	owner   string
+	name    string
+	options []RepositoryOption
+}
+
+// Fixture the fake git provider and git client built from a scenario
+type Fixture struct {
+	Provider *gits.FakeProvider
+	Gitter   *gits.GitFake
+}
+
+// NewScenario creates an empty scenario of a GitHub provider with a local clone on the master branch
+func NewScenario() *Scenario {
+	return &Scenario{
+		providerType: gits.GitHub,
+		gitter: gits.GitFake{
+			CurrentBranch: "master",
+			Branches:      []string{"master"},
+		},
+	}
+}
+
+// WithProviderType sets the kind of git provider the fake provider pretends to be
+func (s *Scenario) WithProviderType(providerType gits.FakeProviderType) *Scenario {
+	s.providerType = providerType
+	return s
+}
+
+// WithUser sets the user the fake provider is authenticated as. It defaults to the owner of the first repository
+func (s *Scenario) WithUser(username string) *Scenario {
+	s.username = username
+	return s
+}
+
+// WithRepository adds a repository to the fake provider. The local clone is of the first repository
+func (s *Scenario) WithRepository(owner string, name string, options ...RepositoryOption) *Scenario {
+	s.repositories = append(s.repositories, scenarioRepository{
+		owner:   owner,
+		name:    name,
+		options: options,
+	})
+	return s
+}
+
+// WithBranch sets the current branch of the local clone adding it to the local branches if required
+func (s *Scenario) WithBranch(branch string) *Scenario {
+	s.gitter.CurrentBranch = branch
+	for _, b := range s.gitter.Branches {
+		if b == branch {
+			return s
+		}
+	}
+	s.gitter.Branches = append(s.gitter.Branches, branch)
+	return s
+}
+
+// WithRemote adds a remote to the local clone. If no remotes are added the origin is the first repository
+func (s *Scenario) WithRemote(name string, url string) *Scenario {
+	s.gitter.GitRemotes = append(s.gitter.GitRemotes, gits.GitRemote{Name: name, URL: url})
+	return s
+}
+
+// WithTag adds a tag to the local clone
+func (s *Scenario) WithTag(name string, message string) *Scenario {
+	s.gitter.GitTags = append(s.gitter.GitTags, gits.GitTag{Name: name, Message: message})
+	return s
+}
+
+// WithLocalChanges marks the local clone as having uncommitted changes
+func (s *Scenario) WithLocalChanges() *Scenario {
+	s.gitter.Changes = true
+	return s
+}
+
+// Build creates the fake git provider and git client of the scenario. Each call returns new fakes so a scenario can
+// be shared by tests
+func (s *Scenario) Build() (*Fixture, error) {
+	gitter := s.gitter
+	gitter.Branches = append([]string{}, s.gitter.Branches...)
+	gitter.GitRemotes = append([]gits.GitRemote{}, s.gitter.GitRemotes...)
+	gitter.GitTags = append([]gits.GitTag{}, s.gitter.GitTags...)
+
+	repositories := []*gits.FakeRepository{}
+	for _, r := range s.repositories {
+		repo, err := gits.NewFakeRepository(r.owner, r.name, nil, nil)
+		if err != nil {
+			return nil, errors.Wrapf(err, "creating fake repository %s/%s", r.owner, r.name)
+		}
+		repositories = append(repositories, repo)
+	}
+	provider := gits.NewFakeProvider(repositories...)
+	provider.Type = s.providerType
+	provider.Gitter = &gitter
+	if s.username != "" {
+		provider.User.Username = s.username
+	}
+	gitter.GitUser = gits.GitUser{Login: provider.User.Username}
+
+	fixture := &Fixture{
+		Provider: provider,
+		Gitter:   &gitter,
+	}
+	for i, r := range s.repositories {
+		repo := repositories[i]
+		for _, option := range r.options {
+			err := option(fixture, repo)
+			if err != nil {
+				return nil, errors.Wrapf(err, "setting up fake repository %s", repo.String())
+			}
+		}
+	}
+	if len(repositories) > 0 {
+		gitRepo := repositories[0].GitRepo
+		gitter.RepoInfo = *gitRepo
+		gitter.Fork = gitRepo.Fork
+		if len(gitter.GitRemotes) == 0 {
+			gitter.GitRemotes = []gits.GitRemote{{Name: "origin", URL: gitRepo.CloneURL}}
+		}
+	}
+	return fixture, nil
+}
+
+// GitProvider returns the fake provider as a gits.GitProvider
+func (f *Fixture) GitProvider() gits.GitProvider {
+	return f.Provider
+}
+
+// Repository returns the fake repository or nil if there is none
+func (f *Fixture) Repository(owner string, name string) *gits.FakeRepository {
+	for _, repo := range f.Provider.Repositories[owner] {
+		if repo.Name() == name {
+			return repo
+		}
+	}
+	return nil
+}
+
+// PullRequest returns the fake pull request of the repository or nil if there is none
+func (f *Fixture) PullRequest(owner string, name string, number int) *gits.FakePullRequest {
+	repo := f.Repository(owner, name)
+	if repo == nil {
+		return nil
+	}
+	return repo.PullRequests[number]
+}
+
+// WebHooks returns the webhooks registered on the repository
+func (f *Fixture) WebHooks(owner string, name string) []*gits.GitWebHookArguments {
+	answer := []*gits.GitWebHookArguments{}
+	for _, hook := range f.Provider.WebHooks {
+		if hook.Owner == owner && hook.Repo != nil && hook.Repo.Name == name {
+			answer = append(answer, hook)
+		}
+	}
+	return answer
+}
@@ -0,0 +1,128 @@
+package testfixtures_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jenkins-x/jx/pkg/gits"
+	"github.com/jenkins-x/jx/pkg/gits/testfixtures"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScenarioBuildsProvider(t *testing.T) {
+	t.Parallel()
+	mergedAt := time.Date(2019, 11, 5, 10, 0, 0, 0, time.UTC)
+	scenario := testfixtures.NewScenario().
+		WithProviderType(gits.Gitlab).
+		WithRepository("myorg", "myapp",
+			testfixtures.Commit("abc123", "initial import", gits.CommitSatusSuccess),
+			testfixtures.Issue("it is broken", "bug"),
+			testfixtures.PullRequest("fix it", "fix", "master",
+				testfixtures.Author("someone"),
+				testfixtures.Approvals("reviewer"),
+				testfixtures.Labels("lgtm"),
+				testfixtures.Status(gits.CommitStatusFailure)),
+			testfixtures.PullRequest("release it", "release", "master", testfixtures.Merged(mergedAt)),
+			testfixtures.Release("v1.0.0", "the first release"),
+			testfixtures.WebHook("http://hook.jx.example.com/hook", "secret")).
+		WithRepository("myorg", "other", testfixtures.Fork())
+
+	fixture, err := scenario.Build()
+	require.NoError(t, err)
+	provider := fixture.GitProvider()
+	assert.Equal(t, "gitlab", provider.Kind())
+	assert.Equal(t, "myorg", provider.CurrentUsername())
+
+	repos, err := provider.ListRepositories("myorg")
+	require.NoError(t, err)
+	assert.Len(t, repos, 2)
+
+	commits, err := provider.ListCommits("myorg", "myapp", &gits.ListCommitsArguments{})
+	require.NoError(t, err)
+	require.Len(t, commits, 1)
+	assert.Equal(t, "abc123", commits[0].SHA)
+
+	issue, err := provider.GetIssue("myorg", "myapp", 1)
+	require.NoError(t, err)
+	assert.Equal(t, "it is broken", issue.Title)
+
+	pr, err := provider.GetPullRequest("myorg", fixture.Repository("myorg", "myapp").GitRepo, 2)
+	require.NoError(t, err)
+	assert.Equal(t, "fix it", pr.Title)
+	assert.Equal(t, "someone", pr.Author.Login)
+	status, err := provider.PullRequestLastCommitStatus(pr)
+	require.NoError(t, err)
+	assert.Equal(t, "failure", status)
+	approvals, err := provider.ListPullRequestApprovals(pr)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"reviewer"}, approvals)
+
+	open, err := provider.ListOpenPullRequests("myorg", "myapp")
+	require.NoError(t, err)
+	require.Len(t, open, 1)
+	assert.True(t, *fixture.PullRequest("myorg", "myapp", 3).PullRequest.Merged)
+
+	release, err := provider.GetRelease("myorg", "myapp", "v1.0.0")
+	require.NoError(t, err)
+	assert.Equal(t, "the first release", release.Body)
+
+	hooks := fixture.WebHooks("myorg", "myapp")
+	require.Len(t, hooks, 1)
+	assert.Equal(t, "http://hook.jx.example.com/hook", hooks[0].URL)
+	assert.Empty(t, fixture.WebHooks("myorg", "other"))
+	assert.True(t, fixture.Repository("myorg", "other").GitRepo.Fork)
+}
+
+func TestScenarioBuildsGitter(t *testing.T) {
+	t.Parallel()
+	scenario := testfixtures.NewScenario().
+		WithRepository("myorg", "myapp").
+		WithBranch("feature").
+		WithTag("v1.0.0", "release 1.0.0").
+		WithLocalChanges()
+
+	fixture, err := scenario.Build()
+	require.NoError(t, err)
+	gitter := fixture.Gitter
+	branch, err := gitter.Branch("")
+	require.NoError(t, err)
+	assert.Equal(t, "feature", branch)
+	assert.Equal(t, []string{"master", "feature"}, gitter.Branches)
+	changes, err := gitter.HasChanges("")
+	require.NoError(t, err)
+	assert.True(t, changes)
+
+	info, err := gitter.Info("")
+	require.NoError(t, err)
+	assert.Equal(t, "myapp", info.Name)
+	assert.Equal(t, "myorg", info.Organisation)
+	url, err := gitter.DiscoverRemoteGitURL("")
+	require.NoError(t, err)
+	assert.Equal(t, "https://fake.git/myorg/myapp.git", url)
+	assert.Equal(t, gitter, fixture.Provider.Gitter)
+}
+
+func TestScenarioBuildsIndependentFixtures(t *testing.T) {
+	t.Parallel()
+	scenario := testfixtures.NewScenario().
+		WithRepository("myorg", "myapp", testfixtures.WebHook("http://hook.jx.example.com/hook", "secret"))
+
+	first, err := scenario.Build()
+	require.NoError(t, err)
+	second, err := scenario.Build()
+	require.NoError(t, err)
+
+	hook := *first.WebHooks("myorg", "myapp")[0]
+	hook.ExistingURL = hook.URL
+	hook.URL = "http://hook.jx.example.com/changed"
+	err = first.Provider.UpdateWebHook(&hook)
+	require.NoError(t, err)
+	assert.Equal(t, "http://hook.jx.example.com/changed", first.WebHooks("myorg", "myapp")[0].URL)
+	assert.Equal(t, "http://hook.jx.example.com/hook", second.WebHooks("myorg", "myapp")[0].URL)
+
+	first.Gitter.CurrentBranch = "changed"
+	branch, err := second.Gitter.Branch("")
+	require.NoError(t, err)
+	assert.Equal(t, "master", branch)
+}
@@ -0,0 +1,365 @@
+package health
+
+import (
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/jenkins-x/jx/pkg/kube"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/jenkins-x/jx/pkg/versionstream"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/api/extensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// chartVersionRegex matches the semantic version suffix of a helm chart label such as 'nexus-1.2.3-rc.1'
+var chartVersionRegex = regexp.MustCompile(`-(v?\d+\.\d+\.\d+(?:[-+][0-9A-Za-z.+-]+)?)$`)
+
+// Level is the health level of an addon
+type Level string
+
+const (
+	// LevelOK the addon is healthy
+	LevelOK Level = "OK"
+	// LevelWarning the addon is running but needs attention
+	LevelWarning Level = "Warning"
+	// LevelError the addon is not working
+	LevelError Level = "Error"
+	// LevelNotInstalled the addon is not installed
+	LevelNotInstalled Level = "NotInstalled"
+
+	// DefaultVolumeWarningPercent the percentage of a volume used above which a warning is reported
+	DefaultVolumeWarningPercent = 80
+	// DefaultCertificateWarningDays the number of days before a certificate expires at which a warning is reported
+	DefaultCertificateWarningDays = 30
+)
+
+// Addon describes how to find an addon in the cluster
+type Addon struct {
+	// Name the name of the addon
+	Name string
+	// PodPrefixes the prefixes of the names of the pods of the addon
+	PodPrefixes []string
+	// Chart the name of the chart of the addon in the version stream such as jenkins-x/tekton
+	Chart string
+}
+
+// DefaultAddons the addons checked by default
+var DefaultAddons = []Addon{
+	{Name: "nexus", PodPrefixes: []string{"nexus"}, Chart: "stable/sonatype-nexus"},
+	{Name: "chartmuseum", PodPrefixes: []string{kube.ServiceChartMuseum}, Chart: "stable/chartmuseum"},
+	{Name: "lighthouse", PodPrefixes: []string{"lighthouse"}, Chart: "jenkins-x/lighthouse"},
+	{Name: "tekton", PodPrefixes: []string{"tekton-pipelines"}, Chart: kube.ChartTekton},
+	{Name: "vault", PodPrefixes: []string{"vault-operator", "vault"}, Chart: kube.ChartVaultOperator},
+}
+
+// AddonStatus is the health of an addon
+type AddonStatus struct {
+	Name            string              `json:"name"`
+	Health          Level               `json:"health"`
+	Pods            int                 `json:"pods"`
+	ReadyPods       int                 `json:"readyPods"`
+	Restarts        int32               `json:"restarts"`
+	Version         string              `json:"version,omitempty"`
+	ExpectedVersion string              `json:"expectedVersion,omitempty"`
+	Volumes         []VolumeUsage       `json:"volumes,omitempty"`
+	Certificates    []CertificateStatus `json:"certificates,omitempty"`
+	Messages        []string            `json:"messages,omitempty"`
+}
+
+// VolumeUsage is the usage of a persistent volume claim of an addon
+type VolumeUsage struct {
+	Claim         string `json:"claim"`
+	UsedBytes     int64  `json:"usedBytes"`
+	CapacityBytes int64  `json:"capacityBytes"`
+	Percent       int    `json:"percent"`
+}
+
+// CertificateStatus is the expiry of a TLS certificate used by the ingress of an addon
+type CertificateStatus struct {
+	Secret        string    `json:"secret"`
+	Hosts         []string  `json:"hosts,omitempty"`
+	NotAfter      time.Time `json:"notAfter"`
+	DaysRemaining int       `json:"daysRemaining"`
+}
+
+// StatsSummary is the subset of the kubelet stats summary used to find the usage of volumes
+type StatsSummary struct {
+	Pods []PodStats `json:"pods"`
+}
+
+// PodStats is the stats of a pod in the kubelet stats summary
+type PodStats struct {
+	Volumes []VolumeStats `json:"volume,omitempty"`
+}
+
+// VolumeStats is the stats of a volume in the kubelet stats summary
+type VolumeStats struct {
+	Name          string        `json:"name"`
+	UsedBytes     *uint64       `json:"usedBytes,omitempty"`
+	CapacityBytes *uint64       `json:"capacityBytes,omitempty"`
+	PVCRef        *PVCReference `json:"pvcRef,omitempty"`
+}
+
+// PVCReference is a reference to the persistent volume claim of a volume in the kubelet stats summary
+type PVCReference struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+}
+
+// AddonChecker checks the health of the addons installed in a namespace
+type AddonChecker struct {
+	KubeClient  kubernetes.Interface
+	Namespace   string
+	VersionsDir string
+
+	VolumeWarningPercent   int
+	CertificateWarningDays int
+
+	// NodeStats returns the kubelet stats summary of a node. Defaults to querying the node via the API server
+	NodeStats func(node string) (*StatsSummary, error)
+	// Now returns the current time. Defaults to time.Now
+	Now func() time.Time
+}
+
+// CheckAddons checks the health of each of the addons
+func (c *AddonChecker) CheckAddons(addons []Addon) ([]*AddonStatus, error) {
+	pods, err := c.KubeClient.CoreV1().Pods(c.Namespace).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, errors.Wrapf(err, "listing pods in namespace %s", c.Namespace)
+	}
+	answer := make([]*AddonStatus, 0, len(addons))
+	claimed := map[string]bool{}
+	for _, addon := range addons {
+		addonPods := make([]*corev1.Pod, 0)
+		for i := range pods.Items {
+			pod := &pods.Items[i]
+			if !claimed[pod.Name] && util.StartsWithAny(pod.Name, addon.PodPrefixes) {
+				claimed[pod.Name] = true
+				addonPods = append(addonPods, pod)
+			}
+		}
+		status, err := c.checkAddon(addon, addonPods)
+		if err != nil {
+			return nil, errors.Wrapf(err, "checking addon %s", addon.Name)
+		}
+		answer = append(answer, status)
+	}
+	return answer, nil
+}
+
+func (c *AddonChecker) checkAddon(addon Addon, pods []*corev1.Pod) (*AddonStatus, error) {
+	status := &AddonStatus{
+		Name:   addon.Name,
+		Health: LevelOK,
+		Pods:   len(pods),
+	}
+	if len(pods) == 0 {
+		status.Health = LevelNotInstalled
+		return status, nil
+	}
+	for _, pod := range pods {
+		if kube.IsPodReady(pod) || pod.Status.Phase == corev1.PodSucceeded {
+			status.ReadyPods++
+		} else {
+			status.addMessage(LevelError, "pod %s is %s", pod.Name, kube.PodStatus(pod))
+		}
+		for _, cs := range pod.Status.ContainerStatuses {
+			status.Restarts += cs.RestartCount
+		}
+		if status.Version == "" {
+			status.Version = ChartVersion(pod.Labels)
+		}
+	}
+	if status.Restarts > 0 {
+		status.addMessage(LevelWarning, "containers have restarted %d times", status.Restarts)
+	}
+
+	if c.VersionsDir != "" && addon.Chart != "" {
+		expected, err := versionstream.LoadStableVersionNumber(c.VersionsDir, versionstream.KindChart, addon.Chart)
+		if err != nil {
+			return status, errors.Wrapf(err, "loading the version of %s from the version stream", addon.Chart)
+		}
+		status.ExpectedVersion = expected
+		if expected != "" && status.Version != "" && expected != status.Version {
+			status.addMessage(LevelWarning, "version %s does not match the version stream version %s", status.Version, expected)
+		}
+	}
+
+	err := c.checkVolumes(status, pods)
+	if err != nil {
+		status.addMessage(LevelWarning, "unable to check volume usage: %s", err)
+	}
+	err = c.checkCertificates(status, addon)
+	if err != nil {
+		status.addMessage(LevelWarning, "unable to check certificates: %s", err)
+	}
+	return status, nil
+}
+
+func (c *AddonChecker) checkVolumes(status *AddonStatus, pods []*corev1.Pod) error {
+	claims := map[string]bool{}
+	nodes := map[string]bool{}
+	for _, pod := range pods {
+		for _, v := range pod.Spec.Volumes {
+			if v.PersistentVolumeClaim != nil {
+				claims[v.PersistentVolumeClaim.ClaimName] = true
+				if pod.Spec.NodeName != "" {
+					nodes[pod.Spec.NodeName] = true
+				}
+			}
+		}
+	}
+	if len(claims) == 0 {
+		return nil
+	}
+	warningPercent := c.VolumeWarningPercent
+	if warningPercent <= 0 {
+		warningPercent = DefaultVolumeWarningPercent
+	}
+	nodeStats := c.NodeStats
+	if nodeStats == nil {
+		nodeStats = c.kubeletStats
+	}
+	for node := range nodes {
+		summary, err := nodeStats(node)
+		if err != nil {
+			return errors.Wrapf(err, "getting stats of node %s", node)
+		}
+		for _, p := range summary.Pods {
+			for _, v := range p.Volumes {
+				if v.PVCRef == nil || v.PVCRef.Namespace != c.Namespace || !claims[v.PVCRef.Name] || v.UsedBytes == nil || v.CapacityBytes == nil || *v.CapacityBytes == 0 {
+					continue
+				}
+				usage := VolumeUsage{
+					Claim:         v.PVCRef.Name,
+					UsedBytes:     int64(*v.UsedBytes),
+					CapacityBytes: int64(*v.CapacityBytes),
+					Percent:       int(*v.UsedBytes * 100 / *v.CapacityBytes),
+				}
+				status.Volumes = append(status.Volumes, usage)
+				if usage.Percent >= warningPercent {
+					status.addMessage(LevelWarning, "volume %s is %d%% full", usage.Claim, usage.Percent)
+				}
+				delete(claims, v.PVCRef.Name)
+			}
+		}
+	}
+	return nil
+}
+
+func (c *AddonChecker) checkCertificates(status *AddonStatus, addon Addon) error {
+	ingresses, err := c.KubeClient.ExtensionsV1beta1().Ingresses(c.Namespace).List(metav1.ListOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "listing ingresses in namespace %s", c.Namespace)
+	}
+	warningDays := c.CertificateWarningDays
+	if warningDays <= 0 {
+		warningDays = DefaultCertificateWarningDays
+	}
+	now := time.Now()
+	if c.Now != nil {
+		now = c.Now()
+	}
+	secrets := map[string]bool{}
+	for i := range ingresses.Items {
+		ing := &ingresses.Items[i]
+		if !ingressMatches(ing, addon.PodPrefixes) {
+			continue
+		}
+		for _, tls := range ing.Spec.TLS {
+			if tls.SecretName == "" || secrets[tls.SecretName] {
+				continue
+			}
+			secrets[tls.SecretName] = true
+			secret, err := c.KubeClient.CoreV1().Secrets(c.Namespace).Get(tls.SecretName, metav1.GetOptions{})
+			if err != nil {
+				status.addMessage(LevelError, "TLS secret %s of ingress %s not found", tls.SecretName, ing.Name)
+				continue
+			}
+			cert, err := parseCertificate(secret.Data[corev1.TLSCertKey])
+			if err != nil {
+				status.addMessage(LevelError, "invalid certificate in secret %s: %s", tls.SecretName, err)
+				continue
+			}
+			days := int(cert.NotAfter.Sub(now).Hours() / 24)
+			status.Certificates = append(status.Certificates, CertificateStatus{
+				Secret:        tls.SecretName,
+				Hosts:         tls.Hosts,
+				NotAfter:      cert.NotAfter,
+				DaysRemaining: days,
+			})
+			if days < 0 {
+				status.addMessage(LevelError, "certificate in secret %s expired on %s", tls.SecretName, cert.NotAfter.Format("2006-01-02"))
+			} else if days < warningDays {
+				status.addMessage(LevelWarning, "certificate in secret %s expires in %d days", tls.SecretName, days)
+			}
+		}
+	}
+	return nil
+}
+
+// kubeletStats queries the stats summary of the kubelet of the node via the API server proxy
+func (c *AddonChecker) kubeletStats(node string) (*StatsSummary, error) {
+	data, err := c.KubeClient.CoreV1().RESTClient().Get().Resource("nodes").Name(node).SubResource("proxy").Suffix("stats/summary").DoRaw()
+	if err != nil {
+		return nil, err
+	}
+	summary := &StatsSummary{}
+	err = json.Unmarshal(data, summary)
+	if err != nil {
+		return nil, errors.Wrap(err, "unmarshalling the kubelet stats summary")
+	}
+	return summary, nil
+}
+
+// addMessage adds a message to the status raising the health to the level if it is worse than the current health
+func (s *AddonStatus) addMessage(level Level, format string, args ...interface{}) {
+	s.Messages = append(s.Messages, fmt.Sprintf(format, args...))
+	if level == LevelError || (level == LevelWarning && s.Health == LevelOK) {
+		s.Health = level
+	}
+}
+
+// ChartVersion returns the version of the chart from the standard helm chart labels
+func ChartVersion(labels map[string]string) string {
+	for _, key := range []string{"chart", "helm.sh/chart"} {
+		m := chartVersionRegex.FindStringSubmatch(labels[key])
+		if len(m) > 1 {
+			return m[1]
+		}
+	}
+	return ""
+}
+
+func parseCertificate(data []byte) (*x509.Certificate, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM data found")
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
+
+// ingressMatches returns true if the name of the ingress or any of the services it exposes match the prefixes
+func ingressMatches(ing *v1beta1.Ingress, prefixes []string) bool {
+	if util.StartsWithAny(ing.Name, prefixes) {
+		return true
+	}
+	for _, rule := range ing.Spec.Rules {
+		if rule.HTTP == nil {
+			continue
+		}
+		for _, path := range rule.HTTP.Paths {
+			if util.StartsWithAny(path.Backend.ServiceName, prefixes) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,147 @@
+package health_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/jenkins-x/jx/pkg/health"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/api/extensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestCheckAddons(t *testing.T) {
+	t.Parallel()
+	ns := "jx"
+	now := time.Date(2019, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	nexusPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "nexus-6b9f8d8c4-x2k8p",
+			Namespace: ns,
+			Labels:    map[string]string{"chart": "nexus-0.1.18"},
+		},
+		Spec: corev1.PodSpec{
+			NodeName: "node1",
+			Volumes: []corev1.Volume{
+				{
+					Name: "data",
+					VolumeSource: corev1.VolumeSource{
+						PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "nexus"},
+					},
+				},
+			},
+		},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodRunning,
+			Conditions: []corev1.PodCondition{
+				{Type: corev1.PodReady, Status: corev1.ConditionTrue},
+			},
+		},
+	}
+	chartmuseumPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "jenkins-x-chartmuseum-7d6f5c9c7b-abcde",
+			Namespace: ns,
+		},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodPending,
+		},
+	}
+	ingress := &v1beta1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "nexus",
+			Namespace: ns,
+		},
+		Spec: v1beta1.IngressSpec{
+			TLS: []v1beta1.IngressTLS{
+				{Hosts: []string{"nexus.jx.example.com"}, SecretName: "tls-nexus"},
+			},
+		},
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "tls-nexus",
+			Namespace: ns,
+		},
+		Data: map[string][]byte{
+			corev1.TLSCertKey: createCertificate(t, now.Add(10*24*time.Hour)),
+		},
+	}
+	kubeClient := fake.NewSimpleClientset(nexusPod, chartmuseumPod, ingress, secret)
+
+	used := uint64(90)
+	capacity := uint64(100)
+	checker := &health.AddonChecker{
+		KubeClient: kubeClient,
+		Namespace:  ns,
+		NodeStats: func(node string) (*health.StatsSummary, error) {
+			assert.Equal(t, "node1", node)
+			stats := health.VolumeStats{
+				Name:          "data",
+				UsedBytes:     &used,
+				CapacityBytes: &capacity,
+				PVCRef:        &health.PVCReference{Name: "nexus", Namespace: ns},
+			}
+			return &health.StatsSummary{
+				Pods: []health.PodStats{{Volumes: []health.VolumeStats{stats}}},
+			}, nil
+		},
+		Now: func() time.Time {
+			return now
+		},
+	}
+
+	statuses, err := checker.CheckAddons(health.DefaultAddons)
+	require.NoError(t, err)
+	require.Len(t, statuses, len(health.DefaultAddons))
+
+	nexus := statuses[0]
+	assert.Equal(t, "nexus", nexus.Name)
+	assert.Equal(t, health.LevelWarning, nexus.Health, "messages %v", nexus.Messages)
+	assert.Equal(t, "0.1.18", nexus.Version)
+	assert.Equal(t, 1, nexus.ReadyPods)
+	require.Len(t, nexus.Volumes, 1)
+	assert.Equal(t, 90, nexus.Volumes[0].Percent)
+	require.Len(t, nexus.Certificates, 1)
+	assert.Equal(t, 10, nexus.Certificates[0].DaysRemaining)
+
+	chartmuseum := statuses[1]
+	assert.Equal(t, health.LevelError, chartmuseum.Health)
+	assert.Equal(t, 0, chartmuseum.ReadyPods)
+
+	lighthouse := statuses[2]
+	assert.Equal(t, health.LevelNotInstalled, lighthouse.Health)
+}
+
+func createCertificate(t *testing.T, notAfter time.Time) []byte {
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "nexus.jx.example.com"},
+		NotBefore:    notAfter.Add(-90 * 24 * time.Hour),
+		NotAfter:     notAfter,
+	}
+	data, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: data})
+}
+
+func TestChartVersion(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "1.2.3", health.ChartVersion(map[string]string{"chart": "nexus-1.2.3"}))
+	assert.Equal(t, "1.2.3-rc.1", health.ChartVersion(map[string]string{"helm.sh/chart": "jenkins-x-platform-1.2.3-rc.1"}))
+	assert.Equal(t, "0.1.0+build.5", health.ChartVersion(map[string]string{"chart": "my-chart-2-0.1.0+build.5"}))
+	assert.Equal(t, "", health.ChartVersion(map[string]string{"chart": "no-version"}))
+	assert.Equal(t, "", health.ChartVersion(map[string]string{}))
+}
@@ -84,7 +84,7 @@ func kuberHealthyState(kuberHealthIP string) (kh.State, error) {
 }
 
 func kuberHealthyRequest(kuberHealthURL string) ([]byte, error) {
-	client := &http.Client{}
+	client := &http.Client{Transport: util.DefaultTransport()}
 	req, err := http.NewRequest("GET", kuberHealthURL, nil)
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to create request for %s", kuberHealthURL)
@@ -106,6 +106,7 @@ func kuberHealthyRequest(kuberHealthURL string) ([]byte, error) {
 		}
 
 		client = &http.Client{
+			Transport: util.DefaultTransport(),
 			CheckRedirect: func(req *http.Request, via []*http.Request) error {
 				if len(via) >= 2 {
 					return errors.New("stopped after 2 kuberhealthy redirects")
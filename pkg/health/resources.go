@@ -0,0 +1,281 @@
+package health
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	apiextensionsclientset "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// DefaultResourcePollInterval the time between assessments of the resources which are not ready
+	DefaultResourcePollInterval = 5 * time.Second
+)
+
+// ResourceRef identifies a resource whose readiness is assessed
+type ResourceRef struct {
+	Kind      string
+	Namespace string
+	Name      string
+}
+
+// String returns the kind, namespace and name of the resource
+func (r ResourceRef) String() string {
+	if r.Namespace == "" {
+		return r.Kind + "/" + r.Name
+	}
+	return r.Kind + "/" + r.Namespace + "/" + r.Name
+}
+
+// ResourceHealth the readiness of a resource
+type ResourceHealth struct {
+	ResourceRef
+	// Ready the resource is ready
+	Ready bool
+	// Failed the resource will not become ready without a change such as a failed Job
+	Failed bool
+	// Message the reason the resource is not ready
+	Message string
+}
+
+// ResourcesNotReadyError the error returned when resources do not become ready
+type ResourcesNotReadyError struct {
+	Resources []ResourceHealth
+}
+
+// Error lists the resources which are not ready
+func (e *ResourcesNotReadyError) Error() string {
+	lines := []string{fmt.Sprintf("%d resources are not ready:", len(e.Resources))}
+	for _, r := range e.Resources {
+		lines = append(lines, fmt.Sprintf("  %s: %s", r.ResourceRef.String(), r.Message))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// ResourceChecker assesses the readiness of resources by kind. Deployments, StatefulSets and DaemonSets must be rolled
+// out, Jobs completed, CustomResourceDefinitions established and Ingresses must have an address. Resources of other
+// kinds are ready once they exist
+type ResourceChecker struct {
+	KubeClient          kubernetes.Interface
+	APIExtensionsClient apiextensionsclientset.Interface
+
+	// PollInterval the time between assessments. Defaults to DefaultResourcePollInterval
+	PollInterval time.Duration
+	// Now returns the current time. Defaults to time.Now
+	Now func() time.Time
+	// Sleep waits between assessments. Defaults to time.Sleep
+	Sleep func(time.Duration)
+}
+
+// WaitForResources waits for the resources to become ready returning a ResourcesNotReadyError naming the resources
+// which are not ready when the timeout expires or as soon as one of them fails
+func (c *ResourceChecker) WaitForResources(refs []ResourceRef, timeout time.Duration) error {
+	now := c.Now
+	if now == nil {
+		now = time.Now
+	}
+	sleep := c.Sleep
+	if sleep == nil {
+		sleep = time.Sleep
+	}
+	pollInterval := c.PollInterval
+	if pollInterval <= 0 {
+		pollInterval = DefaultResourcePollInterval
+	}
+	deadline := now().Add(timeout)
+	pending := refs
+	for {
+		notReady := []ResourceHealth{}
+		failed := false
+		for _, ref := range pending {
+			h, err := c.CheckResource(ref)
+			if err != nil {
+				return err
+			}
+			if !h.Ready {
+				notReady = append(notReady, h)
+				failed = failed || h.Failed
+			}
+		}
+		if len(notReady) == 0 {
+			return nil
+		}
+		if failed || !now().Before(deadline) {
+			sort.Slice(notReady, func(i, j int) bool {
+				return notReady[i].ResourceRef.String() < notReady[j].ResourceRef.String()
+			})
+			return &ResourcesNotReadyError{Resources: notReady}
+		}
+		pending = []ResourceRef{}
+		for _, h := range notReady {
+			pending = append(pending, h.ResourceRef)
+		}
+		sleep(pollInterval)
+	}
+}
+
+// CheckResource assesses the readiness of the resource
+func (c *ResourceChecker) CheckResource(ref ResourceRef) (ResourceHealth, error) {
+	answer := ResourceHealth{ResourceRef: ref, Ready: true}
+	var err error
+	switch ref.Kind {
+	case "Deployment":
+		var d *appsv1.Deployment
+		d, err = c.KubeClient.AppsV1().Deployments(ref.Namespace).Get(ref.Name, metav1.GetOptions{})
+		if err == nil {
+			answer.Ready, answer.Failed, answer.Message = deploymentReadiness(d)
+		}
+	case "StatefulSet":
+		var ss *appsv1.StatefulSet
+		ss, err = c.KubeClient.AppsV1().StatefulSets(ref.Namespace).Get(ref.Name, metav1.GetOptions{})
+		if err == nil {
+			answer.Ready, answer.Message = statefulSetReadiness(ss)
+		}
+	case "DaemonSet":
+		var ds *appsv1.DaemonSet
+		ds, err = c.KubeClient.AppsV1().DaemonSets(ref.Namespace).Get(ref.Name, metav1.GetOptions{})
+		if err == nil {
+			answer.Ready, answer.Message = daemonSetReadiness(ds)
+		}
+	case "Job":
+		var job *batchv1.Job
+		job, err = c.KubeClient.BatchV1().Jobs(ref.Namespace).Get(ref.Name, metav1.GetOptions{})
+		if err == nil {
+			answer.Ready, answer.Failed, answer.Message = jobReadiness(job)
+		}
+	case "CustomResourceDefinition":
+		if c.APIExtensionsClient == nil {
+			return answer, nil
+		}
+		var crd *apiextensionsv1beta1.CustomResourceDefinition
+		crd, err = c.APIExtensionsClient.ApiextensionsV1beta1().CustomResourceDefinitions().Get(ref.Name, metav1.GetOptions{})
+		if err == nil {
+			answer.Ready, answer.Message = crdReadiness(crd)
+		}
+	case "Ingress":
+		ing, err := c.KubeClient.ExtensionsV1beta1().Ingresses(ref.Namespace).Get(ref.Name, metav1.GetOptions{})
+		if err != nil {
+			return c.notFound(answer, err)
+		}
+		if len(ing.Status.LoadBalancer.Ingress) == 0 {
+			answer.Ready = false
+			answer.Message = "waiting for the ingress to be assigned an address"
+		}
+		return answer, nil
+	default:
+		return answer, nil
+	}
+	if err != nil {
+		return c.notFound(answer, err)
+	}
+	return answer, nil
+}
+
+func (c *ResourceChecker) notFound(answer ResourceHealth, err error) (ResourceHealth, error) {
+	if apierrors.IsNotFound(err) {
+		answer.Ready = false
+		answer.Message = "not found"
+		return answer, nil
+	}
+	return answer, errors.Wrapf(err, "failed to get %s", answer.ResourceRef.String())
+}
+
+func deploymentReadiness(d *appsv1.Deployment) (bool, bool, string) {
+	for _, c := range d.Status.Conditions {
+		if c.Type == appsv1.DeploymentProgressing && c.Reason == "ProgressDeadlineExceeded" {
+			return false, true, fmt.Sprintf("the rollout exceeded its progress deadline: %s", c.Message)
+		}
+	}
+	if d.Generation > d.Status.ObservedGeneration {
+		return false, false, "waiting for the deployment spec update to be observed"
+	}
+	replicas := int32(1)
+	if d.Spec.Replicas != nil {
+		replicas = *d.Spec.Replicas
+	}
+	if d.Status.UpdatedReplicas < replicas {
+		return false, false, fmt.Sprintf("%d of %d replicas have been updated", d.Status.UpdatedReplicas, replicas)
+	}
+	if d.Status.Replicas > d.Status.UpdatedReplicas {
+		return false, false, fmt.Sprintf("%d old replicas are pending termination", d.Status.Replicas-d.Status.UpdatedReplicas)
+	}
+	if d.Status.AvailableReplicas < d.Status.UpdatedReplicas {
+		return false, false, fmt.Sprintf("%d of %d updated replicas are available", d.Status.AvailableReplicas, d.Status.UpdatedReplicas)
+	}
+	return true, false, ""
+}
+
+func statefulSetReadiness(ss *appsv1.StatefulSet) (bool, string) {
+	if ss.Status.ObservedGeneration == 0 || ss.Generation > ss.Status.ObservedGeneration {
+		return false, "waiting for the statefulset spec update to be observed"
+	}
+	replicas := int32(1)
+	if ss.Spec.Replicas != nil {
+		replicas = *ss.Spec.Replicas
+	}
+	if ss.Status.ReadyReplicas < replicas {
+		return false, fmt.Sprintf("%d of %d replicas are ready", ss.Status.ReadyReplicas, replicas)
+	}
+	if ss.Spec.UpdateStrategy.Type == appsv1.RollingUpdateStatefulSetStrategyType && ss.Status.UpdateRevision != ss.Status.CurrentRevision {
+		return false, fmt.Sprintf("%d of %d replicas have been updated", ss.Status.UpdatedReplicas, replicas)
+	}
+	return true, ""
+}
+
+func daemonSetReadiness(ds *appsv1.DaemonSet) (bool, string) {
+	if ds.Generation > ds.Status.ObservedGeneration {
+		return false, "waiting for the daemonset spec update to be observed"
+	}
+	desired := ds.Status.DesiredNumberScheduled
+	if ds.Spec.UpdateStrategy.Type == appsv1.RollingUpdateDaemonSetStrategyType && ds.Status.UpdatedNumberScheduled < desired {
+		return false, fmt.Sprintf("%d of %d pods have been updated", ds.Status.UpdatedNumberScheduled, desired)
+	}
+	if ds.Status.NumberAvailable < desired {
+		return false, fmt.Sprintf("%d of %d pods are available", ds.Status.NumberAvailable, desired)
+	}
+	return true, ""
+}
+
+func jobReadiness(job *batchv1.Job) (bool, bool, string) {
+	for _, c := range job.Status.Conditions {
+		if c.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch c.Type {
+		case batchv1.JobComplete:
+			return true, false, ""
+		case batchv1.JobFailed:
+			return false, true, fmt.Sprintf("the job failed: %s %s", c.Reason, c.Message)
+		}
+	}
+	completions := int32(1)
+	if job.Spec.Completions != nil {
+		completions = *job.Spec.Completions
+	}
+	if job.Status.Succeeded >= completions {
+		return true, false, ""
+	}
+	return false, false, fmt.Sprintf("%d of %d completions succeeded", job.Status.Succeeded, completions)
+}
+
+func crdReadiness(crd *apiextensionsv1beta1.CustomResourceDefinition) (bool, string) {
+	for _, c := range crd.Status.Conditions {
+		if c.Type == apiextensionsv1beta1.NamesAccepted && c.Status == apiextensionsv1beta1.ConditionFalse {
+			return false, fmt.Sprintf("the names are not accepted: %s", c.Message)
+		}
+		if c.Type == apiextensionsv1beta1.Established && c.Status == apiextensionsv1beta1.ConditionTrue {
+			return true, ""
+		}
+	}
+	return false, "waiting for the custom resource definition to be established"
+}
@@ -0,0 +1,128 @@
+package health_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jenkins-x/jx/pkg/health"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/api/extensions/v1beta1"
+	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	apiextensionsfake "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestCheckResource(t *testing.T) {
+	t.Parallel()
+	ns := "jx-staging"
+	replicas := int32(2)
+
+	kubeClient := fake.NewSimpleClientset(
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "ready", Namespace: ns, Generation: 2},
+			Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+			Status:     appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 2, UpdatedReplicas: 2, AvailableReplicas: 2},
+		},
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "rolling", Namespace: ns, Generation: 2},
+			Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+			Status:     appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 3, UpdatedReplicas: 1, AvailableReplicas: 2},
+		},
+		&batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{Name: "failed", Namespace: ns},
+			Status: batchv1.JobStatus{
+				Conditions: []batchv1.JobCondition{{Type: batchv1.JobFailed, Status: corev1.ConditionTrue, Reason: "BackoffLimitExceeded"}},
+			},
+		},
+		&v1beta1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{Name: "myapp", Namespace: ns},
+		},
+	)
+	apiClient := apiextensionsfake.NewSimpleClientset(&apiextensionsv1beta1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: "apps.jenkins.io"},
+		Status: apiextensionsv1beta1.CustomResourceDefinitionStatus{
+			Conditions: []apiextensionsv1beta1.CustomResourceDefinitionCondition{
+				{Type: apiextensionsv1beta1.Established, Status: apiextensionsv1beta1.ConditionTrue},
+			},
+		},
+	})
+	checker := &health.ResourceChecker{KubeClient: kubeClient, APIExtensionsClient: apiClient}
+
+	h, err := checker.CheckResource(health.ResourceRef{Kind: "Deployment", Namespace: ns, Name: "ready"})
+	require.NoError(t, err)
+	assert.True(t, h.Ready)
+
+	h, err = checker.CheckResource(health.ResourceRef{Kind: "Deployment", Namespace: ns, Name: "rolling"})
+	require.NoError(t, err)
+	assert.False(t, h.Ready)
+	assert.Equal(t, "1 of 2 replicas have been updated", h.Message)
+
+	h, err = checker.CheckResource(health.ResourceRef{Kind: "Job", Namespace: ns, Name: "failed"})
+	require.NoError(t, err)
+	assert.False(t, h.Ready)
+	assert.True(t, h.Failed)
+
+	h, err = checker.CheckResource(health.ResourceRef{Kind: "Ingress", Namespace: ns, Name: "myapp"})
+	require.NoError(t, err)
+	assert.False(t, h.Ready)
+
+	h, err = checker.CheckResource(health.ResourceRef{Kind: "CustomResourceDefinition", Name: "apps.jenkins.io"})
+	require.NoError(t, err)
+	assert.True(t, h.Ready)
+
+	h, err = checker.CheckResource(health.ResourceRef{Kind: "Deployment", Namespace: ns, Name: "missing"})
+	require.NoError(t, err)
+	assert.False(t, h.Ready)
+	assert.Equal(t, "not found", h.Message)
+
+	h, err = checker.CheckResource(health.ResourceRef{Kind: "ConfigMap", Namespace: ns, Name: "anything"})
+	require.NoError(t, err)
+	assert.True(t, h.Ready)
+}
+
+func TestWaitForResources(t *testing.T) {
+	t.Parallel()
+	ns := "jx-staging"
+
+	kubeClient := fake.NewSimpleClientset(
+		&batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{Name: "done", Namespace: ns},
+			Status:     batchv1.JobStatus{Succeeded: 1},
+		},
+		&v1beta1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{Name: "myapp", Namespace: ns},
+		},
+	)
+	now := time.Date(2019, 11, 4, 10, 0, 0, 0, time.UTC)
+	sleeps := 0
+	checker := &health.ResourceChecker{
+		KubeClient: kubeClient,
+		Now: func() time.Time {
+			return now
+		},
+		Sleep: func(d time.Duration) {
+			sleeps++
+			now = now.Add(d)
+		},
+	}
+
+	err := checker.WaitForResources([]health.ResourceRef{{Kind: "Job", Namespace: ns, Name: "done"}}, time.Minute)
+	require.NoError(t, err)
+
+	err = checker.WaitForResources([]health.ResourceRef{
+		{Kind: "Job", Namespace: ns, Name: "done"},
+		{Kind: "Ingress", Namespace: ns, Name: "myapp"},
+	}, time.Minute)
+	require.Error(t, err)
+	notReady, ok := err.(*health.ResourcesNotReadyError)
+	require.True(t, ok)
+	require.Len(t, notReady.Resources, 1)
+	assert.Equal(t, "Ingress/jx-staging/myapp", notReady.Resources[0].String())
+	assert.Equal(t, 12, sleeps)
+	assert.Contains(t, err.Error(), "Ingress/jx-staging/myapp: waiting for the ingress to be assigned an address")
+}
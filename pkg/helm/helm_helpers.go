@@ -837,7 +837,7 @@ func PromptForRepoCredsIfNeeded(repo string, cred *HelmRepoCredential, handles u
 	}
 	u := fmt.Sprintf("%s/index.yaml", strings.TrimSuffix(repo, "/"))
 
-	httpClient := &http.Client{}
+	httpClient := &http.Client{Transport: util.DefaultTransport()}
 	surveyOpts := survey.WithStdio(handles.In, handles.Out, handles.Err)
 	if cred.Username == "" && cred.Password == "" {
 		// Try without any auth
@@ -65,6 +65,13 @@ type HelmTemplate struct {
 	KubectlValidate bool
 	KubeClient      kubernetes.Interface
 	Namespace       string
+	// ServerSideApply applies the resources with server-side apply and prunes the resources removed from the chart
+	// using the inventory of the release
+	ServerSideApply bool
+	// FieldManager the field manager of server-side apply. Defaults to DefaultFieldManager
+	FieldManager string
+	// ForceConflicts takes the ownership of the fields owned by other field managers on server-side apply
+	ForceConflicts bool
 }
 
 // NewHelmTemplate creates a new HelmTemplate instance configured to the given client side Helmer
@@ -298,7 +305,7 @@ func (h *HelmTemplate) InstallChart(chart string, releaseName string, ns string,
 	}
 
 	err = h.deleteHooks(helmHooks, helmPostPhase, hookSucceeded, ns)
-	err2 := h.deleteOldResources(ns, releaseName, versionText, wait)
+	err2 := h.pruneResources(ns, releaseName, versionText, outputDir, wait)
 	log.Logger().Info("")
 
 	return util.CombineErrors(err, err2)
@@ -382,7 +389,7 @@ func (h *HelmTemplate) UpgradeChart(chart string, releaseName string, ns string,
 	}
 
 	err = h.deleteHooks(helmHooks, helmPostPhase, hookSucceeded, ns)
-	err2 := h.deleteOldResources(ns, releaseName, versionText, wait)
+	err2 := h.pruneResources(ns, releaseName, versionText, outputDir, wait)
 
 	return util.CombineErrors(err, err2)
 }
@@ -408,15 +415,22 @@ func (h *HelmTemplate) kubectlApply(ns string, releaseName string, wait bool, cr
 
 			log.Logger().Debugf("Applying generated chart '%s' YAML via kubectl in dir: %s to namespace %s", releaseName, fullPath, namespace)
 
+			applyNs := namespace
+			if applyNs == "" {
+				applyNs = ns
+			}
+			if h.ServerSideApply {
+				err = h.serverSideApply(h.serverSideApplyArgs(applyNs, releaseName, fullPath))
+				if err != nil {
+					return err
+				}
+				continue
+			}
 			command := "apply"
 			if create {
 				command = "create"
 			}
 			args := []string{command, "--recursive", "-f", fullPath, "-l", LabelReleaseName + "=" + releaseName}
-			applyNs := namespace
-			if applyNs == "" {
-				applyNs = ns
-			}
 			if applyNs != "" {
 				args = append(args, "--namespace", applyNs)
 			}
@@ -436,6 +450,9 @@ func (h *HelmTemplate) kubectlApply(ns string, releaseName string, wait bool, cr
 	}
 
 	log.Logger().Debugf("Applying generated chart '%s' YAML via kubectl in dir: %s to namespace %s", releaseName, dir, ns)
+	if h.ServerSideApply {
+		return h.serverSideApply(h.serverSideApplyArgs(ns, releaseName, dir))
+	}
 	command := "apply"
 	if create {
 		command = "create"
@@ -493,6 +510,14 @@ func (h *HelmTemplate) kubectlDeleteFile(ns string, file string) error {
 	return h.runKubectl("delete", "-f", file, "--namespace", ns, "--wait")
 }
 
+// pruneResources removes the resources of the release which are no longer generated by its chart
+func (h *HelmTemplate) pruneResources(ns string, releaseName string, versionText string, outputDir string, wait bool) error {
+	if h.ServerSideApply {
+		return h.pruneWithInventory(ns, releaseName, outputDir, wait)
+	}
+	return h.deleteOldResources(ns, releaseName, versionText, wait)
+}
+
 func (h *HelmTemplate) deleteOldResources(ns string, releaseName string, versionText string, wait bool) error {
 	selector := LabelReleaseName + "=" + releaseName + "," + LabelReleaseChartVersion + "!=" + versionText
 	return h.deleteResourcesAndClusterResourcesBySelector(ns, selector, wait, "older releases")
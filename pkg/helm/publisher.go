@@ -0,0 +1,276 @@
+package helm
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jenkins-x/jx/pkg/cloud/buckets"
+	"github.com/jenkins-x/jx/pkg/config"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+)
+
+const (
+	// IndexFileName the name of the index file of a chart repository
+	IndexFileName = "index.yaml"
+
+	bucketTimeout = 2 * time.Minute
+)
+
+// ChartPublisher publishes packaged charts to a chart repository
+type ChartPublisher interface {
+	// Publish publishes the chart archive
+	Publish(chartFile string) error
+}
+
+// ChartPublisherOptions the options of the chart publishers
+type ChartPublisherOptions struct {
+	// HelmBinary the helm binary used to index and push charts
+	HelmBinary string
+	// Username the user name of the chart repository
+	Username string
+	// Password the password of the chart repository
+	Password string
+	// Client the HTTP client used to upload charts
+	Client *http.Client
+}
+
+// NewChartPublisher creates the publisher for the kind of chart repository
+func NewChartPublisher(repository *config.ChartRepositoryConfig, o ChartPublisherOptions) (ChartPublisher, error) {
+	if o.Client == nil {
+		o.Client = &http.Client{}
+	}
+	if o.HelmBinary == "" {
+		o.HelmBinary = "helm"
+	}
+	switch repository.Type {
+	case config.ChartRepositoryTypeChartMuseum, "":
+		if repository.URL == "" {
+			return nil, fmt.Errorf("no URL for the ChartMuseum chart repository")
+		}
+		return &chartMuseumPublisher{url: util.UrlJoin(repository.URL, "/api/charts"), options: o}, nil
+	case config.ChartRepositoryTypeHarbor:
+		if repository.URL == "" || repository.Project == "" {
+			return nil, fmt.Errorf("the Harbor chart repository requires the url and project")
+		}
+		return &harborPublisher{url: util.UrlJoin(repository.URL, "/api/chartrepo", repository.Project, "charts"), options: o}, nil
+	case config.ChartRepositoryTypeNexus, config.ChartRepositoryTypeArtifactory:
+		if repository.URL == "" {
+			return nil, fmt.Errorf("no URL for the %s chart repository", repository.Type)
+		}
+		return &uploadPublisher{url: repository.URL, options: o}, nil
+	case config.ChartRepositoryTypeBucket:
+		if repository.BucketURL == "" {
+			return nil, fmt.Errorf("the bucket chart repository requires the bucketURL")
+		}
+		return &bucketPublisher{bucketURL: repository.BucketURL, url: repository.URL, options: o}, nil
+	case config.ChartRepositoryTypeOCI:
+		if !strings.HasPrefix(repository.URL, "oci://") {
+			return nil, fmt.Errorf("the URL of the OCI chart repository should start with oci:// but was %s", repository.URL)
+		}
+		return &ociPublisher{url: repository.URL, options: o}, nil
+	default:
+		return nil, util.InvalidOption("type", string(repository.Type), config.ChartRepositoryTypeValues)
+	}
+}
+
+// chartMuseumPublisher posts the charts to the ChartMuseum API
+type chartMuseumPublisher struct {
+	url     string
+	options ChartPublisherOptions
+}
+
+// Publish posts the chart archive to ChartMuseum
+func (p *chartMuseumPublisher) Publish(chartFile string) error {
+	data, err := ioutil.ReadFile(chartFile)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read the chart archive '%s'", chartFile)
+	}
+	return p.options.upload(http.MethodPost, p.url, "application/gzip", bytes.NewReader(data))
+}
+
+// harborPublisher posts the charts to a project of the Harbor chart repository API
+type harborPublisher struct {
+	url     string
+	options ChartPublisherOptions
+}
+
+// Publish posts the chart archive as a multipart form to Harbor
+func (p *harborPublisher) Publish(chartFile string) error {
+	f, err := os.Open(chartFile)
+	if err != nil {
+		return errors.Wrapf(err, "failed to open the chart archive '%s'", chartFile)
+	}
+	defer f.Close()
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, err := writer.CreateFormFile("chart", filepath.Base(chartFile))
+	if err != nil {
+		return errors.Wrap(err, "failed to create the chart upload form")
+	}
+	_, err = io.Copy(part, f)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read the chart archive '%s'", chartFile)
+	}
+	err = writer.Close()
+	if err != nil {
+		return errors.Wrap(err, "failed to create the chart upload form")
+	}
+	return p.options.upload(http.MethodPost, p.url, writer.FormDataContentType(), body)
+}
+
+// uploadPublisher puts the charts into the repository which indexes them itself such as Nexus and Artifactory
+type uploadPublisher struct {
+	url     string
+	options ChartPublisherOptions
+}
+
+// Publish puts the chart archive into the repository
+func (p *uploadPublisher) Publish(chartFile string) error {
+	data, err := ioutil.ReadFile(chartFile)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read the chart archive '%s'", chartFile)
+	}
+	return p.options.upload(http.MethodPut, util.UrlJoin(p.url, filepath.Base(chartFile)), "application/gzip", bytes.NewReader(data))
+}
+
+// bucketPublisher writes the charts to a static chart repository in a bucket, merging them into the index of the
+// repository
+type bucketPublisher struct {
+	bucketURL string
+	url       string
+	options   ChartPublisherOptions
+}
+
+// Publish writes the chart archive to the bucket and updates the index of the repository
+func (p *bucketPublisher) Publish(chartFile string) error {
+	dir, err := ioutil.TempDir("", "jx-chart-index-")
+	if err != nil {
+		return errors.Wrap(err, "failed to create a temporary directory")
+	}
+	defer os.RemoveAll(dir)
+
+	name := filepath.Base(chartFile)
+	data, err := ioutil.ReadFile(chartFile)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read the chart archive '%s'", chartFile)
+	}
+	err = ioutil.WriteFile(filepath.Join(dir, name), data, util.DefaultFileWritePermissions)
+	if err != nil {
+		return errors.Wrapf(err, "failed to copy the chart archive '%s'", chartFile)
+	}
+
+	args := []string{"repo", "index", dir}
+	if p.url != "" {
+		args = append(args, "--url", p.url)
+	}
+	index, err := buckets.ReadBucket(p.bucketURL, IndexFileName, bucketTimeout)
+	if err != nil {
+		log.Logger().Infof("No %s in bucket %s so creating a new chart repository index", IndexFileName, p.bucketURL)
+	} else {
+		existingIndex := filepath.Join(dir, "existing-"+IndexFileName)
+		err = ioutil.WriteFile(existingIndex, index, util.DefaultFileWritePermissions)
+		if err != nil {
+			return errors.Wrap(err, "failed to save the chart repository index")
+		}
+		args = append(args, "--merge", existingIndex)
+	}
+	cmd := util.Command{
+		Name: p.options.HelmBinary,
+		Args: args,
+	}
+	_, err = cmd.RunWithoutRetry()
+	if err != nil {
+		return errors.Wrapf(err, "failed to index the chart %s", name)
+	}
+	index, err = ioutil.ReadFile(filepath.Join(dir, IndexFileName))
+	if err != nil {
+		return errors.Wrap(err, "failed to read the chart repository index")
+	}
+
+	// lets write the chart before the index so that the index never refers to a missing chart
+	log.Logger().Infof("Uploading chart file %s to %s", util.ColorInfo(name), util.ColorInfo(p.bucketURL))
+	err = buckets.WriteBucket(p.bucketURL, name, data, bucketTimeout)
+	if err != nil {
+		return err
+	}
+	return buckets.WriteBucket(p.bucketURL, IndexFileName, index, bucketTimeout)
+}
+
+// ociPublisher pushes the charts to an OCI registry
+type ociPublisher struct {
+	url     string
+	options ChartPublisherOptions
+}
+
+// Publish pushes the chart archive to the registry logging in first if there are credentials
+func (p *ociPublisher) Publish(chartFile string) error {
+	env := map[string]string{
+		"HELM_EXPERIMENTAL_OCI": "1",
+	}
+	if p.options.Username != "" && p.options.Password != "" {
+		u, err := url.Parse(p.url)
+		if err != nil {
+			return errors.Wrapf(err, "failed to parse the OCI chart repository %s", p.url)
+		}
+		login := util.Command{
+			Name: p.options.HelmBinary,
+			Args: []string{"registry", "login", u.Host, "--username", p.options.Username, "--password-stdin"},
+			Env:  env,
+			In:   strings.NewReader(p.options.Password),
+		}
+		_, err = login.RunWithoutRetry()
+		if err != nil {
+			return errors.Wrapf(err, "failed to login to the OCI registry %s", u.Host)
+		}
+	}
+	log.Logger().Infof("Pushing chart file %s to %s", util.ColorInfo(chartFile), util.ColorInfo(p.url))
+	push := util.Command{
+		Name: p.options.HelmBinary,
+		Args: []string{"push", chartFile, p.url},
+		Env:  env,
+	}
+	_, err := push.RunWithoutRetry()
+	if err != nil {
+		return errors.Wrapf(err, "failed to push the chart %s to %s", chartFile, p.url)
+	}
+	return nil
+}
+
+// upload sends the chart to the chart repository with basic authentication if there are credentials
+func (o *ChartPublisherOptions) upload(method string, u string, contentType string, body io.Reader) error {
+	log.Logger().Infof("Uploading chart to %s", util.ColorInfo(u))
+	req, err := http.NewRequest(method, u, body)
+	if err != nil {
+		return errors.Wrapf(err, "failed to build the chart upload request for endpoint '%s'", u)
+	}
+	if o.Username != "" || o.Password != "" {
+		req.SetBasicAuth(o.Username, o.Password)
+	}
+	req.Header.Set("Content-Type", contentType)
+	res, err := o.Client.Do(req)
+	if err != nil {
+		return errors.Wrapf(err, "failed to execute the chart upload HTTP request, url: '%s'", u)
+	}
+	defer res.Body.Close()
+	data, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return errors.Wrap(err, "failed to read the response body of chart upload request")
+	}
+	responseMessage := string(data)
+	log.Logger().Infof("Received %d response: %s", res.StatusCode, responseMessage)
+	if res.StatusCode >= 300 {
+		return fmt.Errorf("Failed to post chart to %s due to response %d: %s", u, res.StatusCode, responseMessage)
+	}
+	return nil
+}
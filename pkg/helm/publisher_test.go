@@ -0,0 +1,82 @@
+package helm_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x/jx/pkg/config"
+	"github.com/jenkins-x/jx/pkg/helm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChartPublishers(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "test-chart-publisher-")
+	require.NoError(t, err)
+	chartFile := filepath.Join(dir, "myapp-1.2.3.tgz")
+	err = ioutil.WriteFile(chartFile, []byte("chart"), 0644)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		repoType       config.ChartRepositoryType
+		project        string
+		expectedMethod string
+		expectedPath   string
+	}{
+		{repoType: config.ChartRepositoryTypeChartMuseum, expectedMethod: http.MethodPost, expectedPath: "/api/charts"},
+		{repoType: config.ChartRepositoryTypeHarbor, project: "myproject", expectedMethod: http.MethodPost, expectedPath: "/api/chartrepo/myproject/charts"},
+		{repoType: config.ChartRepositoryTypeNexus, expectedMethod: http.MethodPut, expectedPath: "/myapp-1.2.3.tgz"},
+		{repoType: config.ChartRepositoryTypeArtifactory, expectedMethod: http.MethodPut, expectedPath: "/myapp-1.2.3.tgz"},
+	}
+	for _, tc := range testCases {
+		var method, path, user, chart string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			method = r.Method
+			path = r.URL.Path
+			user, _, _ = r.BasicAuth()
+			if r.Method == http.MethodPost && tc.repoType == config.ChartRepositoryTypeHarbor {
+				f, _, err := r.FormFile("chart")
+				if err == nil {
+					data, _ := ioutil.ReadAll(f)
+					chart = string(data)
+				}
+			} else {
+				data, _ := ioutil.ReadAll(r.Body)
+				chart = string(data)
+			}
+			w.WriteHeader(http.StatusCreated)
+		}))
+
+		publisher, err := helm.NewChartPublisher(&config.ChartRepositoryConfig{Type: tc.repoType, URL: server.URL, Project: tc.project},
+			helm.ChartPublisherOptions{Username: "admin", Password: "s3cr3t"})
+		require.NoError(t, err, "creating the %s publisher", tc.repoType)
+		err = publisher.Publish(chartFile)
+		server.Close()
+		require.NoError(t, err, "publishing to %s", tc.repoType)
+
+		assert.Equal(t, tc.expectedMethod, method, "method for %s", tc.repoType)
+		assert.Equal(t, tc.expectedPath, path, "path for %s", tc.repoType)
+		assert.Equal(t, "admin", user, "user for %s", tc.repoType)
+		assert.Equal(t, "chart", chart, "chart for %s", tc.repoType)
+	}
+}
+
+func TestNewChartPublisherValidatesConfig(t *testing.T) {
+	t.Parallel()
+
+	invalid := []*config.ChartRepositoryConfig{
+		{Type: "unknown", URL: "http://charts"},
+		{Type: config.ChartRepositoryTypeHarbor, URL: "http://harbor"},
+		{Type: config.ChartRepositoryTypeBucket},
+		{Type: config.ChartRepositoryTypeOCI, URL: "https://gcr.io/myproject/charts"},
+	}
+	for _, repository := range invalid {
+		_, err := helm.NewChartPublisher(repository, helm.ChartPublisherOptions{})
+		assert.Error(t, err, "should fail for %#v", repository)
+	}
+}
@@ -0,0 +1,155 @@
+package helm
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// LabelReleaseState marks the ConfigMaps recording the chart version and values last applied for a release
+	LabelReleaseState = "jenkins.io/release-state"
+
+	releaseStateConfigMapPrefix = "jx-release-state-"
+	releaseStateChartVersionKey = "chartVersion"
+	releaseStateValuesHashKey   = "valuesHash"
+)
+
+// ReleaseState the chart version and the hash of the values and templates last applied for a release
+type ReleaseState struct {
+	ChartVersion string
+	ValuesHash   string
+}
+
+// Equal returns true if the states have the same chart version and hash
+func (s *ReleaseState) Equal(other *ReleaseState) bool {
+	return s != nil && other != nil && s.ChartVersion == other.ChartVersion && s.ValuesHash == other.ValuesHash
+}
+
+// HashChartInputs returns the hash of the values files, the requirements and the templates of the chart in the
+// directory. The values files are hashed in the order given as later files override earlier ones
+func HashChartInputs(dir string, valueFiles []string) (string, error) {
+	h := sha256.New()
+	add := func(file string, name string) error {
+		f, err := os.Open(file)
+		if err != nil {
+			return errors.Wrapf(err, "failed to open file %s", file)
+		}
+		defer f.Close()
+		fmt.Fprintf(h, "%s\n", name)
+		_, err = io.Copy(h, f)
+		if err != nil {
+			return errors.Wrapf(err, "failed to read file %s", file)
+		}
+		return nil
+	}
+	for _, file := range valueFiles {
+		err := add(file, filepath.Base(file))
+		if err != nil {
+			return "", err
+		}
+	}
+	files := []string{}
+	for _, name := range []string{RequirementsFileName, ValuesFileName} {
+		file := filepath.Join(dir, name)
+		exists, err := util.FileExists(file)
+		if err != nil {
+			return "", err
+		}
+		if exists {
+			files = append(files, file)
+		}
+	}
+	templatesDir := filepath.Join(dir, "templates")
+	exists, err := util.DirExists(templatesDir)
+	if err != nil {
+		return "", err
+	}
+	if exists {
+		templates := []string{}
+		err = filepath.Walk(templatesDir, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if !info.IsDir() {
+				templates = append(templates, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to find the templates in %s", templatesDir)
+		}
+		sort.Strings(templates)
+		files = append(files, templates...)
+	}
+	for _, file := range files {
+		name, err := filepath.Rel(dir, file)
+		if err != nil {
+			return "", err
+		}
+		err = add(file, name)
+		if err != nil {
+			return "", err
+		}
+	}
+	return fmt.Sprintf("%x", h.Sum(nil)), nil
+}
+
+// LoadReleaseState loads the state last applied for the release or nil if it has not been recorded
+func LoadReleaseState(kubeClient kubernetes.Interface, ns string, releaseName string) (*ReleaseState, error) {
+	name := releaseStateConfigMapPrefix + releaseName
+	cm, err := kubeClient.CoreV1().ConfigMaps(ns).Get(name, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "failed to load the release state ConfigMap %s in namespace %s", name, ns)
+	}
+	return &ReleaseState{
+		ChartVersion: cm.Data[releaseStateChartVersionKey],
+		ValuesHash:   cm.Data[releaseStateValuesHashKey],
+	}, nil
+}
+
+// SaveReleaseState records the state applied for the release
+func SaveReleaseState(kubeClient kubernetes.Interface, ns string, releaseName string, state *ReleaseState) error {
+	name := releaseStateConfigMapPrefix + releaseName
+	data := map[string]string{
+		releaseStateChartVersionKey: state.ChartVersion,
+		releaseStateValuesHashKey:   state.ValuesHash,
+	}
+	configMaps := kubeClient.CoreV1().ConfigMaps(ns)
+	existing, err := configMaps.Get(name, metav1.GetOptions{})
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return errors.Wrapf(err, "failed to load the release state ConfigMap %s in namespace %s", name, ns)
+		}
+		_, err = configMaps.Create(&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: ns,
+				Labels: map[string]string{
+					LabelReleaseState: "true",
+				},
+			},
+			Data: data,
+		})
+	} else {
+		existing.Data = data
+		_, err = configMaps.Update(existing)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "failed to save the release state ConfigMap %s in namespace %s", name, ns)
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package helm
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestHashChartInputs(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "test-hash-chart-inputs")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	valuesFile := filepath.Join(dir, ValuesFileName)
+	require.NoError(t, ioutil.WriteFile(valuesFile, []byte("replicas: 1\n"), 0600))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "templates"), 0700))
+	templateFile := filepath.Join(dir, "templates", "deployment.yaml")
+	require.NoError(t, ioutil.WriteFile(templateFile, []byte("kind: Deployment\n"), 0600))
+
+	hash, err := HashChartInputs(dir, []string{valuesFile})
+	require.NoError(t, err)
+	same, err := HashChartInputs(dir, []string{valuesFile})
+	require.NoError(t, err)
+	assert.Equal(t, hash, same)
+
+	require.NoError(t, ioutil.WriteFile(templateFile, []byte("kind: StatefulSet\n"), 0600))
+	changed, err := HashChartInputs(dir, []string{valuesFile})
+	require.NoError(t, err)
+	assert.NotEqual(t, hash, changed)
+
+	require.NoError(t, ioutil.WriteFile(valuesFile, []byte("replicas: 2\n"), 0600))
+	changedValues, err := HashChartInputs(dir, []string{valuesFile})
+	require.NoError(t, err)
+	assert.NotEqual(t, changed, changedValues)
+}
+
+func TestSaveAndLoadReleaseState(t *testing.T) {
+	t.Parallel()
+
+	kubeClient := fake.NewSimpleClientset()
+	state, err := LoadReleaseState(kubeClient, "jx", "jenkins-x")
+	require.NoError(t, err)
+	assert.Nil(t, state)
+
+	saved := &ReleaseState{ChartVersion: "0.0.1", ValuesHash: "abc"}
+	require.NoError(t, SaveReleaseState(kubeClient, "jx", "jenkins-x", saved))
+	state, err = LoadReleaseState(kubeClient, "jx", "jenkins-x")
+	require.NoError(t, err)
+	assert.True(t, saved.Equal(state))
+
+	require.NoError(t, SaveReleaseState(kubeClient, "jx", "jenkins-x", &ReleaseState{ChartVersion: "0.0.2", ValuesHash: "abc"}))
+	state, err = LoadReleaseState(kubeClient, "jx", "jenkins-x")
+	require.NoError(t, err)
+	assert.False(t, saved.Equal(state))
+}
@@ -0,0 +1,321 @@
+package helm
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+	yaml "gopkg.in/yaml.v2"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// DefaultFieldManager the field manager owning the fields of the resources applied with server-side apply
+	DefaultFieldManager = "jx"
+
+	// LabelInventory marks the ConfigMaps recording the inventory of the resources applied by a release
+	LabelInventory = "jenkins.io/inventory"
+
+	inventoryConfigMapPrefix = "jx-inventory-"
+	inventoryKey             = "inventory.yaml"
+)
+
+var (
+	conflictHeaderRegex = regexp.MustCompile(`conflicts? with "([^"]+)"(?: using ([^:\s]+))?:(.*)`)
+)
+
+// InventoryEntry a resource applied by a release
+type InventoryEntry struct {
+	APIVersion string `json:"apiVersion" yaml:"apiVersion"`
+	Kind       string `json:"kind" yaml:"kind"`
+	Namespace  string `json:"namespace,omitempty" yaml:"namespace,omitempty"`
+	Name       string `json:"name" yaml:"name"`
+}
+
+// String returns the kind, namespace and name of the resource
+func (e *InventoryEntry) String() string {
+	if e.Namespace == "" {
+		return e.Kind + "/" + e.Name
+	}
+	return e.Kind + "/" + e.Namespace + "/" + e.Name
+}
+
+// Resource returns the resource type of the entry as used by kubectl. e.g. 'deployment.apps'
+func (e *InventoryEntry) Resource() string {
+	kind := strings.ToLower(e.Kind)
+	paths := strings.Split(e.APIVersion, "/")
+	if len(paths) < 2 {
+		return kind
+	}
+	return kind + "." + paths[0]
+}
+
+// key identifies the resource ignoring its API version so that resources moving to a new API version or group are not
+// pruned
+func (e *InventoryEntry) key() string {
+	return strings.ToLower(e.Kind) + "/" + e.Namespace + "/" + e.Name
+}
+
+// ApplyConflict a field of a resource owned by another field manager
+type ApplyConflict struct {
+	Manager    string
+	APIVersion string
+	Field      string
+}
+
+// ApplyConflictError the error returned when a server-side apply fails as fields are owned by other field managers
+type ApplyConflictError struct {
+	Conflicts []ApplyConflict
+	Output    string
+}
+
+// Error returns the fields in conflict
+func (e *ApplyConflictError) Error() string {
+	lines := []string{"the apply conflicts with the changes made by other field managers:"}
+	for _, c := range e.Conflicts {
+		line := fmt.Sprintf("  %s owned by %s", c.Field, c.Manager)
+		if c.APIVersion != "" {
+			line += fmt.Sprintf(" using %s", c.APIVersion)
+		}
+		lines = append(lines, line)
+	}
+	lines = append(lines, "revert the manual changes, remove the fields from the charts or enable forceConflicts to take ownership of the fields")
+	return strings.Join(lines, "\n")
+}
+
+// ParseApplyConflicts parses the fields in conflict from the output of a failed 'kubectl apply --server-side'
+func ParseApplyConflicts(output string) []ApplyConflict {
+	answer := []ApplyConflict{}
+	var current *ApplyConflict
+	for _, line := range strings.Split(output, "\n") {
+		m := conflictHeaderRegex.FindStringSubmatch(line)
+		if m != nil {
+			current = &ApplyConflict{Manager: m[1], APIVersion: m[2]}
+			field := strings.TrimSpace(m[3])
+			if field != "" {
+				answer = append(answer, ApplyConflict{Manager: current.Manager, APIVersion: current.APIVersion, Field: field})
+			}
+			continue
+		}
+		t := strings.TrimSpace(line)
+		if current != nil && strings.HasPrefix(t, "- ") {
+			answer = append(answer, ApplyConflict{Manager: current.Manager, APIVersion: current.APIVersion, Field: strings.TrimSpace(strings.TrimPrefix(t, "- "))})
+			continue
+		}
+		current = nil
+	}
+	return answer
+}
+
+// BuildInventory returns the resources of the YAML files in the directory sorted by kind, namespace and name excluding
+// helm hooks. Resources without a namespace which are not cluster wide are in the default namespace
+func BuildInventory(dir string, defaultNamespace string) ([]InventoryEntry, error) {
+	answer := []InventoryEntry{}
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || filepath.Ext(path) != ".yaml" {
+			return nil
+		}
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return errors.Wrapf(err, "failed to read file %s", path)
+		}
+		for _, doc := range strings.Split(string(data), "\n"+resourcesSeparator) {
+			if isWhitespaceOrComments([]byte(doc)) {
+				continue
+			}
+			m := yaml.MapSlice{}
+			err = yaml.Unmarshal([]byte(doc), &m)
+			if err != nil {
+				return errors.Wrapf(err, "failed to parse YAML file %s", path)
+			}
+			entry := InventoryEntry{
+				APIVersion: getYamlValueString(&m, "apiVersion"),
+				Kind:       getYamlValueString(&m, "kind"),
+				Namespace:  getYamlValueString(&m, "metadata", "namespace"),
+				Name:       getYamlValueString(&m, "metadata", "name"),
+			}
+			// helm hooks are not part of the release
+			if entry.Kind == "" || entry.Name == "" || getYamlValueString(&m, "metadata", "annotations", "helm.sh/hook") != "" {
+				continue
+			}
+			if isClusterKind(entry.Kind) {
+				entry.Namespace = ""
+			} else if entry.Namespace == "" {
+				entry.Namespace = defaultNamespace
+			}
+			answer = append(answer, entry)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sortInventory(answer)
+	return answer, nil
+}
+
+// PrunableEntries returns the resources of the previous inventory which are not in the current inventory
+func PrunableEntries(previous []InventoryEntry, current []InventoryEntry) []InventoryEntry {
+	keys := map[string]bool{}
+	for i := range current {
+		keys[current[i].key()] = true
+	}
+	answer := []InventoryEntry{}
+	for _, e := range previous {
+		if !keys[e.key()] {
+			answer = append(answer, e)
+		}
+	}
+	return answer
+}
+
+// LoadInventory loads the inventory of the resources applied by the release or nil if the release has no inventory
+func LoadInventory(kubeClient kubernetes.Interface, ns string, releaseName string) ([]InventoryEntry, error) {
+	name := inventoryConfigMapPrefix + releaseName
+	cm, err := kubeClient.CoreV1().ConfigMaps(ns).Get(name, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "failed to load the inventory ConfigMap %s in namespace %s", name, ns)
+	}
+	answer := []InventoryEntry{}
+	err = yaml.Unmarshal([]byte(cm.Data[inventoryKey]), &answer)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse the inventory ConfigMap %s in namespace %s", name, ns)
+	}
+	return answer, nil
+}
+
+// SaveInventory records the resources applied by the release
+func SaveInventory(kubeClient kubernetes.Interface, ns string, releaseName string, entries []InventoryEntry) error {
+	data, err := yaml.Marshal(entries)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal the inventory")
+	}
+	name := inventoryConfigMapPrefix + releaseName
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: ns,
+			Labels: map[string]string{
+				LabelInventory:   "true",
+				LabelReleaseName: releaseName,
+			},
+		},
+		Data: map[string]string{
+			inventoryKey: string(data),
+		},
+	}
+	configMaps := kubeClient.CoreV1().ConfigMaps(ns)
+	existing, err := configMaps.Get(name, metav1.GetOptions{})
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return errors.Wrapf(err, "failed to load the inventory ConfigMap %s in namespace %s", name, ns)
+		}
+		_, err = configMaps.Create(cm)
+	} else {
+		existing.Labels = util.MergeMaps(existing.Labels, cm.Labels)
+		existing.Data = cm.Data
+		_, err = configMaps.Update(existing)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "failed to save the inventory ConfigMap %s in namespace %s", name, ns)
+	}
+	return nil
+}
+
+// serverSideApplyArgs returns the kubectl arguments to apply the resources of the release in the file or directory
+func (h *HelmTemplate) serverSideApplyArgs(ns string, releaseName string, path string) []string {
+	fieldManager := h.FieldManager
+	if fieldManager == "" {
+		fieldManager = DefaultFieldManager
+	}
+	args := []string{"apply", "--server-side", "--field-manager", fieldManager, "--recursive", "-f", path}
+	if releaseName != "" {
+		args = append(args, "-l", LabelReleaseName+"="+releaseName)
+	}
+	if ns != "" {
+		args = append(args, "--namespace", ns)
+	}
+	if h.ForceConflicts {
+		args = append(args, "--force-conflicts")
+	}
+	if !h.KubectlValidate {
+		args = append(args, "--validate=false")
+	}
+	return args
+}
+
+// serverSideApply applies the resources with server-side apply returning an ApplyConflictError if fields are owned by
+// other field managers
+func (h *HelmTemplate) serverSideApply(args []string) error {
+	output, err := h.runKubectlWithOutput(args...)
+	log.Logger().Debugf(output)
+	if err != nil {
+		conflicts := ParseApplyConflicts(output + "\n" + err.Error())
+		if len(conflicts) > 0 {
+			return &ApplyConflictError{Conflicts: conflicts, Output: output}
+		}
+		return err
+	}
+	return nil
+}
+
+// pruneWithInventory deletes the resources applied by the previous apply of the release which are no longer in the
+// output directory then records the new inventory of the release
+func (h *HelmTemplate) pruneWithInventory(ns string, releaseName string, outputDir string, wait bool) error {
+	if h.KubeClient == nil {
+		return fmt.Errorf("no kubernetes client to record the inventory of release %s", releaseName)
+	}
+	current, err := BuildInventory(outputDir, ns)
+	if err != nil {
+		return errors.Wrapf(err, "failed to build the inventory of release %s", releaseName)
+	}
+	previous, err := LoadInventory(h.KubeClient, ns, releaseName)
+	if err != nil {
+		return err
+	}
+	errList := []error{}
+	for _, e := range PrunableEntries(previous, current) {
+		log.Logger().Infof("Pruning %s as it was removed from release %s", util.ColorInfo(e.String()), releaseName)
+		args := []string{"delete", e.Resource(), e.Name, "--ignore-not-found"}
+		if e.Namespace != "" {
+			args = append(args, "--namespace", e.Namespace)
+		}
+		if wait {
+			args = append(args, "--wait")
+		}
+		err = h.runKubectl(args...)
+		if err != nil {
+			errList = append(errList, errors.Wrapf(err, "failed to prune %s", e.String()))
+			// keep the resource in the inventory so that it is pruned by the next apply
+			current = append(current, e)
+		}
+	}
+	sortInventory(current)
+	err = SaveInventory(h.KubeClient, ns, releaseName, current)
+	if err != nil {
+		errList = append(errList, err)
+	}
+	return util.CombineErrors(errList...)
+}
+
+func sortInventory(entries []InventoryEntry) {
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].key() < entries[j].key()
+	})
+}
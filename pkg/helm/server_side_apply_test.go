@@ -0,0 +1,91 @@
+package helm
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestParseApplyConflicts(t *testing.T) {
+	t.Parallel()
+
+	conflicts := ParseApplyConflicts(`error: Apply failed with 1 conflict: conflict with "kubectl-edit" using apps/v1: .spec.replicas
+Please review the fields above--they currently have other managers.`)
+	require.Len(t, conflicts, 1)
+	assert.Equal(t, ApplyConflict{Manager: "kubectl-edit", APIVersion: "apps/v1", Field: ".spec.replicas"}, conflicts[0])
+
+	conflicts = ParseApplyConflicts(`error: Apply failed with 2 conflicts: conflicts with "kubectl-client-side-apply" using apps/v1:
+- .spec.replicas
+- .spec.template.spec.containers[name="myapp"].image
+Please review the fields above--they currently have other managers.`)
+	require.Len(t, conflicts, 2)
+	assert.Equal(t, "kubectl-client-side-apply", conflicts[1].Manager)
+	assert.Equal(t, `.spec.template.spec.containers[name="myapp"].image`, conflicts[1].Field)
+
+	assert.Empty(t, ParseApplyConflicts(`error: unable to recognize "foo.yaml": no matches for kind "Foo"`))
+}
+
+func TestBuildInventoryAndPrune(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "test-build-inventory")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	err = ioutil.WriteFile(filepath.Join(dir, "deployment.yaml"), []byte(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: myapp
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: myapp
+  namespace: jx-staging
+`), 0600)
+	require.NoError(t, err)
+	err = ioutil.WriteFile(filepath.Join(dir, "role.yaml"), []byte(`apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: myapp
+`), 0600)
+	require.NoError(t, err)
+
+	current, err := BuildInventory(dir, "jx-staging")
+	require.NoError(t, err)
+	require.Len(t, current, 3)
+	assert.Equal(t, "ClusterRole/myapp", current[0].String())
+	assert.Equal(t, "clusterrole.rbac.authorization.k8s.io", current[0].Resource())
+	assert.Equal(t, "Deployment/jx-staging/myapp", current[1].String())
+	assert.Equal(t, "service", current[2].Resource())
+
+	previous := []InventoryEntry{
+		{APIVersion: "extensions/v1beta1", Kind: "Deployment", Namespace: "jx-staging", Name: "myapp"},
+		{APIVersion: "v1", Kind: "ConfigMap", Namespace: "jx-staging", Name: "removed"},
+	}
+	pruned := PrunableEntries(previous, current)
+	require.Len(t, pruned, 1)
+	assert.Equal(t, "removed", pruned[0].Name)
+}
+
+func TestSaveAndLoadInventory(t *testing.T) {
+	t.Parallel()
+
+	kubeClient := fake.NewSimpleClientset()
+	entries, err := LoadInventory(kubeClient, "jx-staging", "jx")
+	require.NoError(t, err)
+	assert.Nil(t, entries)
+
+	saved := []InventoryEntry{{APIVersion: "apps/v1", Kind: "Deployment", Namespace: "jx-staging", Name: "myapp"}}
+	require.NoError(t, SaveInventory(kubeClient, "jx-staging", "jx", saved))
+	require.NoError(t, SaveInventory(kubeClient, "jx-staging", "jx", saved))
+
+	entries, err = LoadInventory(kubeClient, "jx-staging", "jx")
+	require.NoError(t, err)
+	assert.Equal(t, saved, entries)
+}
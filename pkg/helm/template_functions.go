@@ -0,0 +1,181 @@
+package helm
+
+import (
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+
+	"github.com/jenkins-x/jx/pkg/config"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/secreturl"
+	"github.com/jenkins-x/jx/pkg/versionstream"
+	"github.com/pkg/errors"
+)
+
+// AddEnvironmentFunctions adds the sprig 'env' and 'expandenv' functions which helm removes from its function map
+func AddEnvironmentFunctions(funcMap template.FuncMap) {
+	funcMap["env"] = os.Getenv
+	funcMap["expandenv"] = os.ExpandEnv
+}
+
+// AddPEMFunctions adds the functions to convert PEM encoded certificates and keys to and from the single line base64
+// form used in Secrets and kubeconfig files:
+//
+// * `pemBase64 <pem>` validates the PEM and base64 encodes it
+// * `pemFromBase64 <base64>` decodes the base64 text and validates the PEM it contains
+// * `pemBlock <type> <pem>` returns the first block of the type such as "CERTIFICATE" or "RSA PRIVATE KEY"
+func AddPEMFunctions(funcMap template.FuncMap) {
+	funcMap["pemBase64"] = PEMToBase64
+	funcMap["pemFromBase64"] = PEMFromBase64
+	funcMap["pemBlock"] = PEMBlock
+}
+
+// AddRequirementsFunctions adds the functions which access the requirements by a dotted path such as
+// "cluster.clusterName":
+//
+// * `requirement <path>` returns the value failing the template if it is missing or empty
+// * `requirementOrDefault <path> <default>` returns the value or the default if it is missing or empty
+// * `hasRequirement <path>` returns true if the value is present and not empty
+func AddRequirementsFunctions(funcMap template.FuncMap, requirements map[string]interface{}) {
+	funcMap["requirement"] = func(path string) (interface{}, error) {
+		value, ok := lookupValuePath(requirements, path)
+		if !ok {
+			return nil, fmt.Errorf("the requirement %s is not specified in the %s file", path, config.RequirementsConfigFileName)
+		}
+		return value, nil
+	}
+	funcMap["requirementOrDefault"] = func(path string, defaultValue interface{}) interface{} {
+		value, ok := lookupValuePath(requirements, path)
+		if !ok {
+			return defaultValue
+		}
+		return value
+	}
+	funcMap["hasRequirement"] = func(path string) bool {
+		_, ok := lookupValuePath(requirements, path)
+		return ok
+	}
+}
+
+// AddSecretURLFunctions adds the functions which read the key of a secret from the secret storage such as vault:
+//
+// * `vault <path> <key>` returns the value failing the template if the secret or key does not exist
+// * `vaultOrDefault <path> <key> <default>` returns the value or the default if the secret or key does not exist
+//
+// If there is no secret URL client then `vault` fails and `vaultOrDefault` returns the default
+func AddSecretURLFunctions(funcMap template.FuncMap, secretURLClient secreturl.Client) {
+	funcMap["vault"] = func(path string, key string) (string, error) {
+		if secretURLClient == nil {
+			return "", fmt.Errorf("cannot read the secret %s as there is no secret storage configured", path)
+		}
+		value, ok, err := readSecretKey(secretURLClient, path, key)
+		if err != nil {
+			return "", err
+		}
+		if !ok {
+			return "", fmt.Errorf("the secret %s has no key %s", path, key)
+		}
+		return value, nil
+	}
+	funcMap["vaultOrDefault"] = func(path string, key string, defaultValue string) string {
+		if secretURLClient == nil {
+			return defaultValue
+		}
+		value, ok, err := readSecretKey(secretURLClient, path, key)
+		if err != nil {
+			log.Logger().Debugf("using the default value of %s key %s as the secret could not be read: %s", path, key, err.Error())
+			return defaultValue
+		}
+		if !ok {
+			return defaultValue
+		}
+		return value
+	}
+}
+
+// AddVersionStreamFunctions adds the `versionStream <kind> <name>` function which returns the stable version of a
+// chart, package or docker image in the version stream such as `{{ versionStream "charts" "jenkins-x/tekton" }}`
+func AddVersionStreamFunctions(funcMap template.FuncMap, resolver *versionstream.VersionResolver) {
+	funcMap["versionStream"] = func(kindString, name string) string {
+		kind := versionstream.VersionKind(kindString)
+		version, err := resolver.StableVersionNumber(kind, name)
+		if err != nil {
+			log.Logger().Errorf("failed to find %s version for %s in the version stream due to: %s\n", kindString, name, err.Error())
+		}
+		return version
+	}
+}
+
+// PEMToBase64 validates the PEM encoded text and returns it base64 encoded
+func PEMToBase64(text string) (string, error) {
+	block, _ := pem.Decode([]byte(strings.TrimSpace(text)))
+	if block == nil {
+		return "", errors.New("the text is not PEM encoded")
+	}
+	return base64.StdEncoding.EncodeToString([]byte(text)), nil
+}
+
+// PEMFromBase64 decodes the base64 text and validates it contains a PEM block
+func PEMFromBase64(text string) (string, error) {
+	data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(text))
+	if err != nil {
+		return "", errors.Wrap(err, "failed to decode the base64 text")
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return "", errors.New("the decoded text is not PEM encoded")
+	}
+	return string(data), nil
+}
+
+// PEMBlock returns the first block of the given type in the PEM encoded text
+func PEMBlock(blockType string, text string) (string, error) {
+	rest := []byte(text)
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			return "", fmt.Errorf("no PEM block of type %s found", blockType)
+		}
+		if block.Type == blockType {
+			return string(pem.EncodeToMemory(block)), nil
+		}
+	}
+}
+
+func readSecretKey(secretURLClient secreturl.Client, path string, key string) (string, bool, error) {
+	secret, err := secretURLClient.Read(path)
+	if err != nil {
+		return "", false, errors.Wrapf(err, "failed to read the secret %s", path)
+	}
+	value, ok := secret[key]
+	if !ok || value == nil {
+		return "", false, nil
+	}
+	return fmt.Sprintf("%v", value), true, nil
+}
+
+// lookupValuePath returns the value at the dotted path returning false if it is missing or an empty string
+func lookupValuePath(values map[string]interface{}, path string) (interface{}, bool) {
+	var value interface{} = values
+	for _, key := range strings.Split(path, ".") {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		value, ok = m[key]
+		if !ok {
+			return nil, false
+		}
+	}
+	if value == nil {
+		return nil, false
+	}
+	if s, ok := value.(string); ok && s == "" {
+		return nil, false
+	}
+	return value, true
+}
@@ -0,0 +1,159 @@
+package helm
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/jenkins-x/jx/pkg/util"
+)
+
+const (
+	// ValuesLayerDefaults the default values of the charts jx adds to the chart such as the observability stack
+	ValuesLayerDefaults = "defaults"
+	// ValuesLayerBase the values of the values.yaml or values.tmpl.yaml tree of the chart
+	ValuesLayerBase = "base"
+	// ValuesLayerEnvironment the values of the environment the chart is applied to
+	ValuesLayerEnvironment = "environment"
+	// ValuesLayerCluster the values of the kubernetes provider of the cluster
+	ValuesLayerCluster = "cluster"
+	// ValuesLayerSecrets the values of the secrets files
+	ValuesLayerSecrets = "secrets"
+)
+
+// ValuesLayer the values of a layer which override the values of the previous layers
+type ValuesLayer struct {
+	// Name the name of the layer such as ValuesLayerEnvironment
+	Name string
+	// Source the file the values were loaded from
+	Source string
+	// Values the values of the layer
+	Values map[string]interface{}
+}
+
+// Label returns the name of the layer with its source file if any
+func (l *ValuesLayer) Label() string {
+	if l.Source == "" {
+		return l.Name
+	}
+	return fmt.Sprintf("%s (%s)", l.Name, l.Source)
+}
+
+// EnvironmentValuesFile returns the values file of the environment in the directory of the chart preferring the
+// 'values.<environment>.tmpl.yaml' template over 'values.<environment>.yaml' or an empty string if there is none
+func EnvironmentValuesFile(dir string, environment string) (string, error) {
+	if environment == "" {
+		return "", nil
+	}
+	for _, name := range []string{"values." + environment + ".tmpl.yaml", "values." + environment + ".yaml"} {
+		file := filepath.Join(dir, name)
+		exists, err := util.FileExists(file)
+		if err != nil {
+			return "", err
+		}
+		if exists {
+			return file, nil
+		}
+	}
+	return "", nil
+}
+
+// MergeValuesLayers merges the values of the layers in order returning the effective values and the label of the
+// layer which set each value by its dotted key path
+func MergeValuesLayers(layers []ValuesLayer) (map[string]interface{}, map[string]string) {
+	values := map[string]interface{}{}
+	sources := map[string]string{}
+	for i := range layers {
+		layer := &layers[i]
+		if len(layer.Values) == 0 {
+			continue
+		}
+		recordSources(sources, layer.Values, "", layer.Label())
+		util.CombineMapTrees(values, deepCopyValues(layer.Values))
+	}
+	return values, sources
+}
+
+// EffectiveValuesYAML returns the values as YAML with a comment naming the layer which set each value
+func EffectiveValuesYAML(values map[string]interface{}, sources map[string]string) []byte {
+	var buf bytes.Buffer
+	writeAnnotatedValues(&buf, values, sources, "", 0)
+	return buf.Bytes()
+}
+
+func recordSources(sources map[string]string, values map[string]interface{}, prefix string, label string) {
+	for k, v := range values {
+		path := k
+		if prefix != "" {
+			path = prefix + "." + k
+		}
+		m, ok := v.(map[string]interface{})
+		if ok && len(m) > 0 {
+			// a map replaces any scalar value of a previous layer
+			delete(sources, path)
+			recordSources(sources, m, path, label)
+			continue
+		}
+		// a scalar or list replaces any values nested below it by a previous layer
+		for p := range sources {
+			if strings.HasPrefix(p, path+".") {
+				delete(sources, p)
+			}
+		}
+		sources[path] = label
+	}
+}
+
+func writeAnnotatedValues(buf *bytes.Buffer, values map[string]interface{}, sources map[string]string, prefix string, depth int) {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	indent := strings.Repeat("  ", depth)
+	for _, k := range keys {
+		path := k
+		if prefix != "" {
+			path = prefix + "." + k
+		}
+		v := values[k]
+		m, ok := v.(map[string]interface{})
+		if ok && len(m) > 0 {
+			fmt.Fprintf(buf, "%s%s:\n", indent, k)
+			writeAnnotatedValues(buf, m, sources, path, depth+1)
+			continue
+		}
+		fmt.Fprintf(buf, "%s%s: %s", indent, k, scalarYAML(v))
+		if source := sources[path]; source != "" {
+			fmt.Fprintf(buf, "  # %s", source)
+		}
+		buf.WriteString("\n")
+	}
+}
+
+// scalarYAML returns the value on a single line using the JSON syntax which is valid YAML
+func scalarYAML(v interface{}) string {
+	if v == nil {
+		return "null"
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(data)
+}
+
+func deepCopyValues(values map[string]interface{}) map[string]interface{} {
+	answer := map[string]interface{}{}
+	for k, v := range values {
+		if m, ok := v.(map[string]interface{}); ok {
+			answer[k] = deepCopyValues(m)
+		} else {
+			answer[k] = v
+		}
+	}
+	return answer
+}
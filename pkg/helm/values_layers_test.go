@@ -0,0 +1,93 @@
+package helm_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x/jx/pkg/helm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeValuesLayers(t *testing.T) {
+	t.Parallel()
+
+	layers := []helm.ValuesLayer{
+		{
+			Name: helm.ValuesLayerBase,
+			Values: map[string]interface{}{
+				"replicaCount": 1,
+				"image": map[string]interface{}{
+					"repository": "myapp",
+					"tag":        "1.0.0",
+				},
+				"ingress": map[string]interface{}{
+					"hosts": []interface{}{"a.example.com"},
+				},
+			},
+		},
+		{
+			Name:   helm.ValuesLayerEnvironment,
+			Source: "values.production.yaml",
+			Values: map[string]interface{}{
+				"replicaCount": 3,
+				"image": map[string]interface{}{
+					"tag": "1.0.1",
+				},
+			},
+		},
+		{
+			Name:   helm.ValuesLayerCluster,
+			Source: "kubeProviders/gke/values.tmpl.yaml",
+			Values: map[string]interface{}{
+				"ingress": "disabled",
+			},
+		},
+	}
+	values, sources := helm.MergeValuesLayers(layers)
+
+	assert.Equal(t, 3, values["replicaCount"])
+	assert.Equal(t, "disabled", values["ingress"])
+	image := values["image"].(map[string]interface{})
+	assert.Equal(t, "myapp", image["repository"])
+	assert.Equal(t, "1.0.1", image["tag"])
+	assert.Equal(t, "1.0.0", layers[0].Values["image"].(map[string]interface{})["tag"], "the layers should not be modified")
+
+	assert.Equal(t, "base", sources["image.repository"])
+	assert.Equal(t, "environment (values.production.yaml)", sources["image.tag"])
+	assert.Equal(t, "cluster (kubeProviders/gke/values.tmpl.yaml)", sources["ingress"])
+	_, ok := sources["ingress.hosts"]
+	assert.False(t, ok)
+
+	expected := `image:
+  repository: "myapp"  # base
+  tag: "1.0.1"  # environment (values.production.yaml)
+ingress: "disabled"  # cluster (kubeProviders/gke/values.tmpl.yaml)
+replicaCount: 3  # environment (values.production.yaml)
+`
+	assert.Equal(t, expected, string(helm.EffectiveValuesYAML(values, sources)))
+}
+
+func TestEnvironmentValuesFile(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "test-environment-values-file")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	file, err := helm.EnvironmentValuesFile(dir, "staging")
+	require.NoError(t, err)
+	assert.Equal(t, "", file)
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "values.staging.yaml"), []byte("a: b\n"), 0600))
+	file, err = helm.EnvironmentValuesFile(dir, "staging")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "values.staging.yaml"), file)
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "values.staging.tmpl.yaml"), []byte("a: b\n"), 0600))
+	file, err = helm.EnvironmentValuesFile(dir, "staging")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "values.staging.tmpl.yaml"), file)
+}
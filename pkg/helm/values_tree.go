@@ -50,6 +50,7 @@ func GenerateValues(requirements *config.RequirementsConfig, funcMap template.Fu
 	}
 	if funcMap == nil {
 		funcMap = NewFunctionMap()
+		AddSecretURLFunctions(funcMap, secretURLClient)
 	}
 	if ignores == nil {
 		ignores = DefaultValuesTreeIgnores
@@ -218,19 +219,28 @@ func NewFunctionMap() template.FuncMap {
 	funcMap := engine.FuncMap()
 	funcMap["hashPassword"] = util.HashPassword
 	funcMap["removeScheme"] = util.RemoveScheme
+	AddEnvironmentFunctions(funcMap)
+	AddPEMFunctions(funcMap)
 	return funcMap
 }
 
-// ReadValuesYamlFileTemplateOutput evaluates the given values.yaml file as a go template and returns the output data
+// ReadValuesYamlFileTemplateOutput evaluates the given values.yaml file as a go template and returns the output data.
+// The requirements functions such as `requirement "cluster.clusterName"` are added to the function map
 func ReadValuesYamlFileTemplateOutput(templateFile string, params chartutil.Values, funcMap template.FuncMap, requirements *config.RequirementsConfig) ([]byte, error) {
-	tmpl, err := template.New(ValuesTemplateFileName).Option("missingkey=error").Funcs(funcMap).ParseFiles(templateFile)
+	requirementsMap, err := requirements.ToMap()
 	if err != nil {
-		return nil, errors.Wrapf(err, "failed to parse Secrets template: %s", templateFile)
+		return nil, errors.Wrapf(err, "failed turn requirements into a map: %v", requirements)
 	}
 
-	requirementsMap, err := requirements.ToMap()
+	templateFuncMap := template.FuncMap{}
+	for k, v := range funcMap {
+		templateFuncMap[k] = v
+	}
+	AddRequirementsFunctions(templateFuncMap, requirementsMap)
+
+	tmpl, err := template.New(ValuesTemplateFileName).Option("missingkey=error").Funcs(templateFuncMap).ParseFiles(templateFile)
 	if err != nil {
-		return nil, errors.Wrapf(err, "failed turn requirements into a map: %v", requirements)
+		return nil, errors.Wrapf(err, "failed to parse Secrets template: %s", templateFile)
 	}
 
 	templateData := map[string]interface{}{
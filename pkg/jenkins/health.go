@@ -34,9 +34,7 @@ func CheckHealth(url string, healthTimeout time.Duration) error {
 }
 
 func ping(url string) error {
-	client := http.Client{
-		Timeout: pingTimeout,
-	}
+	client := util.GetClientWithTimeout(pingTimeout)
 	req, err := http.NewRequest(http.MethodGet, url, nil)
 	if err != nil {
 		return errors.Wrapf(err, "building ping request for URL %q", url)
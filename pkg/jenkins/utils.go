@@ -91,6 +91,7 @@ func GetJenkinsClient(url string, batch bool, configService jenkauth.ConfigServi
 	jenkins := gojenkins.NewJenkins(jauth, url)
 
 	httpClient := &http.Client{
+		Transport: util.DefaultTransport(),
 		CheckRedirect: func(req *http.Request, via []*http.Request) error {
 			return http.ErrUseLastResponse
 		}}
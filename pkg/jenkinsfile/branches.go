@@ -0,0 +1,167 @@
+package jenkinsfile
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/jenkins-x/jx/pkg/tekton/syntax"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// previewCommand the command which creates the preview environments of pull requests
+const previewCommand = "jx preview"
+
+// BranchOverride the changes to the pipelines of the branches matching a pattern
+type BranchOverride struct {
+	// Branch the pattern of the branch names such as release/* using the syntax of path.Match
+	Branch string `json:"branch"`
+	// Agent replaces the agent of the pipelines
+	Agent *syntax.Agent `json:"agent,omitempty"`
+	// Env adds or replaces environment variables of the pipelines
+	Env []corev1.EnvVar `json:"env,omitempty"`
+	// Overrides the overrides of the steps of the pipelines which are applied after the overrides of the pipelines
+	Overrides []*syntax.PipelineOverride `json:"overrides,omitempty"`
+	// NoPreview removes the steps which create preview environments
+	NoPreview bool `json:"noPreview,omitempty"`
+}
+
+// Matches returns true if the branch matches the pattern of this override
+func (b *BranchOverride) Matches(branch string) bool {
+	matched, err := path.Match(b.Branch, branch)
+	return err == nil && matched
+}
+
+// Validate returns an error if the pattern or the step overrides are invalid
+func (b *BranchOverride) Validate() error {
+	if b.Branch == "" {
+		return fmt.Errorf("no branch pattern specified")
+	}
+	_, err := path.Match(b.Branch, "")
+	if err != nil {
+		return fmt.Errorf("invalid branch pattern %s: %s", b.Branch, err)
+	}
+	for i, override := range b.Overrides {
+		if override == nil {
+			continue
+		}
+		err = override.ValidatePatch()
+		if err != nil {
+			return fmt.Errorf("invalid override %d of branch %s: %s", i, b.Branch, err)
+		}
+	}
+	return nil
+}
+
+// MatchingBranchOverrides returns the branch overrides matching the branch in the order they are declared
+func (c *PipelineConfig) MatchingBranchOverrides(branch string) []*BranchOverride {
+	answer := []*BranchOverride{}
+	if branch == "" {
+		return answer
+	}
+	for _, b := range c.Branches {
+		if b != nil && b.Matches(branch) {
+			answer = append(answer, b)
+		}
+	}
+	return answer
+}
+
+// ApplyBranchOverrides applies the branch overrides matching the branch to this configuration. This should be called
+// before extending the build pack so that the changes take precedence over it. Matching overrides are applied in the
+// order they are declared so that later overrides take precedence over earlier ones. Returns true if the steps which
+// create preview environments should be removed
+func (c *PipelineConfig) ApplyBranchOverrides(branch string) (bool, error) {
+	noPreview := false
+	for _, b := range c.MatchingBranchOverrides(branch) {
+		err := b.Validate()
+		if err != nil {
+			return false, err
+		}
+		if b.Agent != nil {
+			c.Agent = b.Agent.DeepCopy()
+		}
+		c.Env = mergeEnvVars(c.Env, b.Env)
+		for _, override := range b.Overrides {
+			if override != nil {
+				c.Pipelines.Overrides = append(c.Pipelines.Overrides, override.DeepCopy())
+			}
+		}
+		if b.NoPreview {
+			noPreview = true
+		}
+	}
+	return noPreview, nil
+}
+
+// RemovePreviewSteps removes the steps which create preview environments from all the pipelines
+func (p *Pipelines) RemovePreviewSteps() {
+	for _, lifecycles := range p.All() {
+		if lifecycles == nil {
+			continue
+		}
+		for _, l := range lifecycles.All() {
+			if l.Lifecycle != nil {
+				l.Lifecycle.PreSteps = removePreviewSteps(l.Lifecycle.PreSteps)
+				l.Lifecycle.Steps = removePreviewSteps(l.Lifecycle.Steps)
+			}
+		}
+		if lifecycles.Pipeline != nil {
+			removePreviewStageSteps(lifecycles.Pipeline.Stages)
+		}
+	}
+}
+
+func removePreviewSteps(steps []*syntax.Step) []*syntax.Step {
+	answer := []*syntax.Step{}
+	for _, step := range steps {
+		if step == nil || isPreviewStep(step) {
+			continue
+		}
+		step.Steps = removePreviewSteps(step.Steps)
+		answer = append(answer, step)
+	}
+	return answer
+}
+
+func removePreviewStageSteps(stages []syntax.Stage) {
+	for i := range stages {
+		stage := &stages[i]
+		steps := []syntax.Step{}
+		for _, step := range stage.Steps {
+			if !isPreviewStep(&step) {
+				step.Steps = removePreviewSteps(step.Steps)
+				steps = append(steps, step)
+			}
+		}
+		stage.Steps = steps
+		removePreviewStageSteps(stage.Stages)
+		removePreviewStageSteps(stage.Parallel)
+	}
+}
+
+func isPreviewStep(step *syntax.Step) bool {
+	command := strings.TrimSpace(step.GetFullCommand())
+	return command == previewCommand || strings.HasPrefix(command, previewCommand+" ")
+}
+
+// mergeEnvVars returns the environment variables with the overrides replacing the variables of the same name
+func mergeEnvVars(env []corev1.EnvVar, overrides []corev1.EnvVar) []corev1.EnvVar {
+	answer := []corev1.EnvVar{}
+	for _, e := range env {
+		found := false
+		for _, o := range overrides {
+			if o.Name == e.Name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			answer = append(answer, e)
+		}
+	}
+	for _, o := range overrides {
+		answer = append(answer, *o.DeepCopy())
+	}
+	return answer
+}
@@ -0,0 +1,110 @@
+package jenkinsfile_test
+
+import (
+	"testing"
+
+	"github.com/jenkins-x/jx/pkg/jenkinsfile"
+	"github.com/jenkins-x/jx/pkg/tekton/syntax"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/yaml"
+)
+
+const branchesConfig = `agent:
+  image: maven
+env:
+- name: PROFILE
+  value: dev
+- name: DEBUG
+  value: "false"
+pipelines:
+  pullRequest:
+    promote:
+      steps:
+      - command: jx preview --app $APP_NAME --dir ../..
+      - command: make smoke-test
+branches:
+- branch: release/*
+  agent:
+    image: maven-java11
+  env:
+  - name: PROFILE
+    value: release
+  overrides:
+  - pipeline: release
+    stage: build
+    type: after
+    step:
+      command: make sign
+- branch: release/1.*
+  env:
+  - name: PROFILE
+    value: legacy
+- branch: docs/*
+  noPreview: true
+`
+
+func loadBranchesConfig(t *testing.T) *jenkinsfile.PipelineConfig {
+	config := &jenkinsfile.PipelineConfig{}
+	require.NoError(t, yaml.Unmarshal([]byte(branchesConfig), config))
+	return config
+}
+
+func TestApplyBranchOverrides(t *testing.T) {
+	t.Parallel()
+
+	config := loadBranchesConfig(t)
+	noPreview, err := config.ApplyBranchOverrides("release/1.2")
+	require.NoError(t, err)
+	assert.False(t, noPreview)
+
+	assert.Equal(t, "maven-java11", config.Agent.Image)
+	env := map[string]string{}
+	for _, e := range config.Env {
+		env[e.Name] = e.Value
+	}
+	assert.Equal(t, map[string]string{"PROFILE": "legacy", "DEBUG": "false"}, env, "later branch overrides take precedence")
+	require.Len(t, config.Pipelines.Overrides, 1)
+	assert.Equal(t, "make sign", config.Pipelines.Overrides[0].Step.Command)
+
+	config = loadBranchesConfig(t)
+	_, err = config.ApplyBranchOverrides("master")
+	require.NoError(t, err)
+	assert.Equal(t, "maven", config.Agent.Image)
+	assert.Len(t, config.Env, 2)
+	assert.Empty(t, config.Pipelines.Overrides)
+}
+
+func TestBranchOverridesRemovePreviewSteps(t *testing.T) {
+	t.Parallel()
+
+	config := loadBranchesConfig(t)
+	noPreview, err := config.ApplyBranchOverrides("docs/readme")
+	require.NoError(t, err)
+	require.True(t, noPreview)
+
+	config.Pipelines.RemovePreviewSteps()
+	steps := config.Pipelines.PullRequest.Promote.Steps
+	require.Len(t, steps, 1)
+	assert.Equal(t, "make smoke-test", steps[0].Command)
+}
+
+func TestBranchOverrideValidate(t *testing.T) {
+	t.Parallel()
+
+	invalidType := syntax.StepOverrideType("sideways")
+	testCases := map[string]*jenkinsfile.BranchOverride{
+		"empty branch":    {},
+		"invalid pattern": {Branch: "release/["},
+		"invalid override": {
+			Branch:    "release/*",
+			Overrides: []*syntax.PipelineOverride{{Type: &invalidType}},
+		},
+	}
+	for name, branch := range testCases {
+		assert.Error(t, branch.Validate(), name)
+	}
+	assert.NoError(t, (&jenkinsfile.BranchOverride{Branch: "feature/**"}).Validate())
+	assert.True(t, (&jenkinsfile.BranchOverride{Branch: "release/*"}).Matches("release/1.0"))
+	assert.False(t, (&jenkinsfile.BranchOverride{Branch: "release/*"}).Matches("release/1.0/hotfix"))
+}
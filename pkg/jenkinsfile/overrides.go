@@ -0,0 +1,134 @@
+package jenkinsfile
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jenkins-x/jx/pkg/tekton/syntax"
+)
+
+// ValidateOverrides validates the overrides of these pipelines which patch the steps of the pipelines they extend from
+// the given base pipelines, which may be nil. An error is returned if an override has an invalid type or if it targets a
+// step by name which does not exist in any of the pipelines and stages the override matches
+func (p *Pipelines) ValidateOverrides(base *Pipelines) error {
+	if base == nil {
+		base = &Pipelines{}
+	}
+	for i, override := range p.Overrides {
+		if override == nil {
+			continue
+		}
+		err := override.ValidatePatch()
+		if err != nil {
+			return fmt.Errorf("invalid override %d: %s", i, err)
+		}
+		if override.Name == "" {
+			continue
+		}
+		stepNames, found := overrideTargetStepNames(override, p, base)
+		if found && !stepNames[override.Name] {
+			names := []string{}
+			for name := range stepNames {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			where := ""
+			if override.Pipeline != "" {
+				where += fmt.Sprintf(" of the %s pipeline", override.Pipeline)
+			}
+			if override.Stage != "" {
+				where = fmt.Sprintf(" in stage %s", override.Stage) + where
+			}
+			return fmt.Errorf("override %d targets the step %s which does not exist%s. The available steps are: %s", i, override.Name, where, strings.Join(names, ", "))
+		}
+	}
+	return nil
+}
+
+// overrideTargetStepNames returns the names of the steps the override could target and whether there were any
+// pipelines or lifecycles matched by the override at all
+func overrideTargetStepNames(override *syntax.PipelineOverride, pipelines ...*Pipelines) (map[string]bool, bool) {
+	names := map[string]bool{}
+	found := false
+	for _, p := range pipelines {
+		for _, kind := range []struct {
+			name       string
+			lifecycles *PipelineLifecycles
+		}{
+			{"pullRequest", p.PullRequest},
+			{"release", p.Release},
+			{"feature", p.Feature},
+		} {
+			if kind.lifecycles == nil || !override.MatchesPipeline(kind.name) {
+				continue
+			}
+			l := kind.lifecycles
+			for _, lifecycle := range []NamedLifecycle{
+				{"setup", l.Setup},
+				{"setVersion", l.SetVersion},
+				{"preBuild", l.PreBuild},
+				{"build", l.Build},
+				{"postBuild", l.PostBuild},
+				{"promote", l.Promote},
+			} {
+				if lifecycle.Lifecycle != nil && override.MatchesStage(lifecycle.Name) {
+					found = true
+					addStepPointerNames(names, lifecycle.Lifecycle.PreSteps)
+					addStepPointerNames(names, lifecycle.Lifecycle.Steps)
+				}
+			}
+			if l.Pipeline != nil && addStageStepNames(names, override, l.Pipeline.Stages) {
+				found = true
+			}
+		}
+		if p.Post != nil && override.MatchesPipeline("") && override.MatchesStage("post") {
+			found = true
+			addStepPointerNames(names, p.Post.Steps)
+		}
+		if p.Default != nil && addStageStepNames(names, override, p.Default.Stages) {
+			found = true
+		}
+	}
+	return names, found
+}
+
+func addStageStepNames(names map[string]bool, override *syntax.PipelineOverride, stages []syntax.Stage) bool {
+	found := false
+	for i := range stages {
+		stage := &stages[i]
+		if override.MatchesStage(stage.Name) && len(stage.Steps) > 0 {
+			found = true
+			for j := range stage.Steps {
+				addStepNames(names, &stage.Steps[j])
+			}
+		}
+		if addStageStepNames(names, override, stage.Stages) {
+			found = true
+		}
+		if addStageStepNames(names, override, stage.Parallel) {
+			found = true
+		}
+	}
+	return found
+}
+
+func addStepPointerNames(names map[string]bool, steps []*syntax.Step) {
+	for _, step := range steps {
+		if step != nil {
+			addStepNames(names, step)
+		}
+	}
+}
+
+func addStepNames(names map[string]bool, step *syntax.Step) {
+	if step.Name != "" {
+		names[step.Name] = true
+	}
+	addStepPointerNames(names, step.Steps)
+	if step.Loop != nil {
+		for i := range step.Loop.Steps {
+			addStepNames(names, &step.Loop.Steps[i])
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package jenkinsfile_test
+
+import (
+	"testing"
+
+	"github.com/jenkins-x/jx/pkg/jenkinsfile"
+	"github.com/jenkins-x/jx/pkg/tekton/syntax"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateOverrides(t *testing.T) {
+	t.Parallel()
+	overrideType := func(t syntax.StepOverrideType) *syntax.StepOverrideType {
+		return &t
+	}
+	base := &jenkinsfile.Pipelines{
+		Release: &jenkinsfile.PipelineLifecycles{
+			Build: &jenkinsfile.PipelineLifecycle{
+				Steps: []*syntax.Step{
+					{Name: "container-build", Command: "skaffold build"},
+					{Name: "dir-block", Steps: []*syntax.Step{{Name: "nested", Command: "make"}}},
+				},
+			},
+		},
+	}
+
+	testCases := []struct {
+		name     string
+		override *syntax.PipelineOverride
+		valid    bool
+	}{
+		{
+			name:     "replace existing step",
+			override: &syntax.PipelineOverride{Pipeline: "release", Name: "container-build", Step: &syntax.Step{Command: "echo"}},
+			valid:    true,
+		},
+		{
+			name:     "insert after nested step",
+			override: &syntax.PipelineOverride{Stage: "build", Name: "nested", Type: overrideType(syntax.StepOverrideAfter), Step: &syntax.Step{Command: "echo"}},
+			valid:    true,
+		},
+		{
+			name:     "delete existing step",
+			override: &syntax.PipelineOverride{Name: "container-build", Type: overrideType(syntax.StepOverrideDelete)},
+			valid:    true,
+		},
+		{
+			name:     "pipeline not defined",
+			override: &syntax.PipelineOverride{Pipeline: "feature", Name: "missing", Type: overrideType(syntax.StepOverrideDelete)},
+			valid:    true,
+		},
+		{
+			name:     "missing step",
+			override: &syntax.PipelineOverride{Pipeline: "release", Name: "missing", Type: overrideType(syntax.StepOverrideDelete)},
+		},
+		{
+			name:     "step in another stage",
+			override: &syntax.PipelineOverride{Stage: "promote", Name: "container-build", Step: &syntax.Step{Command: "echo"}},
+			valid:    true,
+		},
+		{
+			name:     "step not in the stage",
+			override: &syntax.PipelineOverride{Stage: "build", Name: "promote", Step: &syntax.Step{Command: "echo"}},
+		},
+		{
+			name:     "delete with steps",
+			override: &syntax.PipelineOverride{Name: "container-build", Type: overrideType(syntax.StepOverrideDelete), Step: &syntax.Step{Command: "echo"}},
+		},
+		{
+			name:     "insert without steps",
+			override: &syntax.PipelineOverride{Name: "container-build", Type: overrideType(syntax.StepOverrideBefore)},
+		},
+		{
+			name:     "unknown type",
+			override: &syntax.PipelineOverride{Name: "container-build", Type: overrideType("upsert"), Step: &syntax.Step{Command: "echo"}},
+		},
+	}
+	for _, tc := range testCases {
+		pipelines := &jenkinsfile.Pipelines{
+			Overrides: []*syntax.PipelineOverride{tc.override},
+		}
+		err := pipelines.ValidateOverrides(base)
+		if tc.valid {
+			assert.NoError(t, err, tc.name)
+		} else {
+			assert.Error(t, err, tc.name)
+		}
+	}
+}
+
+func TestDeleteOverride(t *testing.T) {
+	t.Parallel()
+	newPipelines := func(name string) (*jenkinsfile.Pipelines, *jenkinsfile.Pipelines) {
+		deleteType := syntax.StepOverrideDelete
+		base := &jenkinsfile.Pipelines{
+			Release: &jenkinsfile.PipelineLifecycles{
+				Build: &jenkinsfile.PipelineLifecycle{
+					Steps: []*syntax.Step{
+						{Name: "container-build", Command: "skaffold build"},
+						{Name: "post-build", Command: "jx step post build"},
+					},
+				},
+			},
+		}
+		pipelines := &jenkinsfile.Pipelines{
+			Overrides: []*syntax.PipelineOverride{{
+				Pipeline: "release",
+				Stage:    "build",
+				Name:     name,
+				Type:     &deleteType,
+			}},
+		}
+		return pipelines, base
+	}
+
+	pipelines, base := newPipelines("post-build")
+	require.NoError(t, pipelines.Extend(base))
+	require.NotNil(t, pipelines.Release.Build)
+	require.Len(t, pipelines.Release.Build.Steps, 1)
+	assert.Equal(t, "container-build", pipelines.Release.Build.Steps[0].Name)
+
+	pipelines, base = newPipelines("missing")
+	err := pipelines.Extend(base)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "The available steps are: container-build, post-build")
+}
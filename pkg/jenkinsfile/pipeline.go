@@ -127,6 +127,8 @@ type PipelineConfig struct {
 	Environment      string            `json:"environment,omitempty"`
 	Pipelines        Pipelines         `json:"pipelines,omitempty"`
 	ContainerOptions *corev1.Container `json:"containerOptions,omitempty"`
+	Branches         []*BranchOverride `json:"branches,omitempty"`
+	Triggers         *Triggers         `json:"triggers,omitempty"`
 }
 
 // CreateJenkinsfileArguments contains the arguents to generate a Jenkinsfiles dynamically
@@ -339,6 +341,10 @@ func removeWhenSteps(prow bool, steps []*syntax.Step) []*syntax.Step {
 
 // Extend extends these pipelines with the base pipeline
 func (p *Pipelines) Extend(base *Pipelines) error {
+	err := p.ValidateOverrides(base)
+	if err != nil {
+		return err
+	}
 	p.PullRequest = ExtendPipelines("pullRequest", p.PullRequest, base.PullRequest, p.Overrides)
 	p.Release = ExtendPipelines("release", p.Release, base.Release, p.Overrides)
 	p.Feature = ExtendPipelines("feature", p.Feature, base.Feature, p.Overrides)
@@ -635,8 +641,7 @@ func (c *PipelineConfig) ExtendPipeline(base *PipelineConfig, clearContainer boo
 	c.ContainerOptions = mergedContainer
 	base.defaultContainerAndDir()
 	c.defaultContainerAndDir()
-	c.Pipelines.Extend(&base.Pipelines)
-	return nil
+	return c.Pipelines.Extend(&base.Pipelines)
 }
 
 func (c *PipelineConfig) defaultContainerAndDir() {
@@ -857,8 +862,8 @@ func (c *PipelineConfig) createPipelineSteps(step *syntax.Step, prefixPath strin
 		modifyStep := c.modifyStep(s, dir, args.DockerRegistry, args.DockerRegistryOrg, args.GitName, args.ProjectID, args.KanikoImage, args.UseKaniko)
 
 		steps = append(steps, modifyStep)
-	} else if step.Loop != nil {
-		// Just copy in the loop step without altering it.
+	} else if step.Loop != nil || step.Approval != nil {
+		// Just copy in the loop or approval step without altering it.
 		// TODO: We don't get magic around image resolution etc, but we avoid naming collisions that result otherwise.
 		steps = append(steps, *step)
 	}
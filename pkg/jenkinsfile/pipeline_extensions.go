@@ -0,0 +1,85 @@
+package jenkinsfile
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/jenkins-x/jx/pkg/tekton/syntax"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// PipelineExtensionsFileName is the name of the file in the dev environment repository which defines the steps
+	// to inject into the pipelines of all the apps of the team
+	PipelineExtensionsFileName = "pipeline-extensions.yaml"
+)
+
+// PipelineExtensions defines the steps a team injects before or after the named stages and steps of every app pipeline
+type PipelineExtensions struct {
+	// Extensions the steps to inject using the same syntax as the overrides in jenkins-x.yml. Only the 'before' and
+	// 'after' types are supported so that extensions can only add steps to a pipeline
+	Extensions []*syntax.PipelineOverride `json:"extensions,omitempty"`
+}
+
+// LoadPipelineExtensions loads the pipeline extensions from the given file returning nil if the file does not exist
+func LoadPipelineExtensions(fileName string) (*PipelineExtensions, error) {
+	exists, err := util.FileExists(fileName)
+	if err != nil || !exists {
+		return nil, err
+	}
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to load file %s", fileName)
+	}
+	answer := &PipelineExtensions{}
+	err = yaml.Unmarshal(data, answer)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal file %s", fileName)
+	}
+	err = answer.Validate()
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid pipeline extensions in file %s", fileName)
+	}
+	return answer, nil
+}
+
+// LoadPipelineExtensionsFromDir loads the pipeline extensions from the pipeline-extensions.yaml file in the given
+// directory returning nil if there is no such file
+func LoadPipelineExtensionsFromDir(dir string) (*PipelineExtensions, error) {
+	return LoadPipelineExtensions(filepath.Join(dir, PipelineExtensionsFileName))
+}
+
+// Validate returns an error if any of the extensions would do anything other than inject steps into a pipeline
+func (e *PipelineExtensions) Validate() error {
+	for i, extension := range e.Extensions {
+		if extension == nil {
+			continue
+		}
+		if extension.Type == nil || (*extension.Type != syntax.StepOverrideBefore && *extension.Type != syntax.StepOverrideAfter) {
+			return fmt.Errorf("extension %d must have a type of either %s or %s", i, syntax.StepOverrideBefore, syntax.StepOverrideAfter)
+		}
+		if len(extension.AsStepsSlice()) == 0 {
+			return fmt.Errorf("extension %d has no steps to inject", i)
+		}
+		if extension.Stage == "" && extension.Name == "" {
+			return fmt.Errorf("extension %d must specify the stage or the name of the step to inject steps before or after", i)
+		}
+	}
+	return nil
+}
+
+// ApplyToPipeline injects the steps of the extensions which match the kind of pipeline into the pipeline
+func (e *PipelineExtensions) ApplyToPipeline(kind string, parsed *syntax.ParsedPipeline) *syntax.ParsedPipeline {
+	if e == nil || parsed == nil {
+		return parsed
+	}
+	for _, extension := range e.Extensions {
+		if extension != nil && extension.MatchesPipeline(kind) {
+			parsed = syntax.ApplyStepOverridesToPipeline(parsed, extension.DeepCopy())
+		}
+	}
+	return parsed
+}
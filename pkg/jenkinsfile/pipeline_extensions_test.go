@@ -0,0 +1,95 @@
+package jenkinsfile_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x/jx/pkg/jenkinsfile"
+	"github.com/jenkins-x/jx/pkg/tekton/syntax"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testPipelineExtensions = `extensions:
+- pipeline: release
+  stage: build
+  name: build-container
+  type: after
+  step:
+    name: security-scan
+    image: scanner
+    command: scan
+- stage: build
+  type: before
+  steps:
+  - name: lint
+    command: make lint
+`
+
+func TestLoadPipelineExtensions(t *testing.T) {
+	dir, err := ioutil.TempDir("", "test-pipeline-extensions-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	extensions, err := jenkinsfile.LoadPipelineExtensionsFromDir(dir)
+	require.NoError(t, err)
+	assert.Nil(t, extensions)
+
+	err = ioutil.WriteFile(filepath.Join(dir, jenkinsfile.PipelineExtensionsFileName), []byte(testPipelineExtensions), 0600)
+	require.NoError(t, err)
+	extensions, err = jenkinsfile.LoadPipelineExtensionsFromDir(dir)
+	require.NoError(t, err)
+	require.NotNil(t, extensions)
+	assert.Len(t, extensions.Extensions, 2)
+
+	err = ioutil.WriteFile(filepath.Join(dir, jenkinsfile.PipelineExtensionsFileName), []byte(`extensions:
+- stage: build
+  steps:
+  - name: lint
+    command: make lint
+`), 0600)
+	require.NoError(t, err)
+	_, err = jenkinsfile.LoadPipelineExtensionsFromDir(dir)
+	assert.Error(t, err, "replacing steps is not a valid extension")
+}
+
+func TestApplyPipelineExtensions(t *testing.T) {
+	dir, err := ioutil.TempDir("", "test-pipeline-extensions-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	fileName := filepath.Join(dir, jenkinsfile.PipelineExtensionsFileName)
+	err = ioutil.WriteFile(fileName, []byte(testPipelineExtensions), 0600)
+	require.NoError(t, err)
+	extensions, err := jenkinsfile.LoadPipelineExtensions(fileName)
+	require.NoError(t, err)
+
+	newPipeline := func() *syntax.ParsedPipeline {
+		return &syntax.ParsedPipeline{
+			Stages: []syntax.Stage{{
+				Name: "build",
+				Steps: []syntax.Step{
+					{Name: "build-container", Command: "build"},
+					{Name: "test", Command: "test"},
+				},
+			}},
+		}
+	}
+	stepNames := func(parsed *syntax.ParsedPipeline) []string {
+		answer := []string{}
+		for _, step := range parsed.Stages[0].Steps {
+			answer = append(answer, step.Name)
+		}
+		return answer
+	}
+
+	release := extensions.ApplyToPipeline(jenkinsfile.PipelineKindRelease, newPipeline())
+	assert.Equal(t, []string{"lint", "build-container", "security-scan", "test"}, stepNames(release))
+
+	pullRequest := extensions.ApplyToPipeline(jenkinsfile.PipelineKindPullRequest, newPipeline())
+	assert.Equal(t, []string{"lint", "build-container", "test"}, stepNames(pullRequest))
+
+	var noExtensions *jenkinsfile.PipelineExtensions
+	assert.Equal(t, []string{"build-container", "test"}, stepNames(noExtensions.ApplyToPipeline(jenkinsfile.PipelineKindRelease, newPipeline())))
+}
@@ -0,0 +1,67 @@
+package jenkinsfile
+
+import (
+	"fmt"
+
+	"github.com/jenkins-x/jx/pkg/cron"
+	"github.com/jenkins-x/jx/pkg/tekton/syntax"
+)
+
+const (
+	// OverlapPolicySkip skips a scheduled pipeline if the previous scheduled pipeline is still running
+	OverlapPolicySkip = "skip"
+	// OverlapPolicyReplace cancels the previous scheduled pipeline if it is still running
+	OverlapPolicyReplace = "replace"
+)
+
+// OverlapPolicies the supported policies for a scheduled pipeline which is still running when the next one is due
+var OverlapPolicies = []string{OverlapPolicySkip, OverlapPolicyReplace}
+
+// Triggers the triggers of the pipelines other than git events
+type Triggers struct {
+	// Cron the schedule of the release pipeline such as "0 4 * * *" for a nightly build at 4am UTC
+	Cron string `json:"cron,omitempty"`
+	// Branch the branch built on schedule. Defaults to master
+	Branch string `json:"branch,omitempty"`
+	// OverlapPolicy what happens if the previous scheduled pipeline is still running: skip or replace. Defaults to skip
+	OverlapPolicy string `json:"overlapPolicy,omitempty"`
+	// Parameters the custom params passed to the scheduled pipelines
+	Parameters map[string]string `json:"parameters,omitempty"`
+}
+
+// GetBranch returns the branch built on schedule
+func (t *Triggers) GetBranch() string {
+	if t.Branch == "" {
+		return "master"
+	}
+	return t.Branch
+}
+
+// GetOverlapPolicy returns the overlap policy of the scheduled pipelines
+func (t *Triggers) GetOverlapPolicy() string {
+	if t.OverlapPolicy == "" {
+		return OverlapPolicySkip
+	}
+	return t.OverlapPolicy
+}
+
+// Validate returns an error if the schedule, overlap policy or params are invalid
+func (t *Triggers) Validate() error {
+	if t.Cron != "" {
+		_, err := cron.Parse(t.Cron)
+		if err != nil {
+			return err
+		}
+	}
+	policy := t.GetOverlapPolicy()
+	if policy != OverlapPolicySkip && policy != OverlapPolicyReplace {
+		return fmt.Errorf("unknown overlapPolicy '%s' which should be one of: %s, %s", policy, OverlapPolicySkip, OverlapPolicyReplace)
+	}
+	for name := range t.Parameters {
+		err := syntax.ValidatePipelineParamName(name)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package jenkinsfile_test
+
+import (
+	"testing"
+
+	"github.com/jenkins-x/jx/pkg/jenkinsfile"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/yaml"
+)
+
+func TestTriggersYAML(t *testing.T) {
+	t.Parallel()
+	text := `triggers:
+  cron: "0 4 * * *"
+  parameters:
+    refresh_deps: "true"
+`
+	config := jenkinsfile.PipelineConfig{}
+	err := yaml.Unmarshal([]byte(text), &config)
+	require.NoError(t, err)
+	require.NotNil(t, config.Triggers)
+
+	triggers := config.Triggers
+	assert.Equal(t, "0 4 * * *", triggers.Cron)
+	assert.Equal(t, "master", triggers.GetBranch())
+	assert.Equal(t, jenkinsfile.OverlapPolicySkip, triggers.GetOverlapPolicy())
+	assert.Equal(t, map[string]string{"refresh_deps": "true"}, triggers.Parameters)
+	assert.NoError(t, triggers.Validate())
+}
+
+func TestTriggersValidate(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		name     string
+		triggers jenkinsfile.Triggers
+		valid    bool
+	}{
+		{"nightly", jenkinsfile.Triggers{Cron: "0 4 * * *", OverlapPolicy: jenkinsfile.OverlapPolicyReplace}, true},
+		{"descriptor", jenkinsfile.Triggers{Cron: "@weekly"}, true},
+		{"bad cron", jenkinsfile.Triggers{Cron: "0 25 * * *"}, false},
+		{"bad overlap policy", jenkinsfile.Triggers{Cron: "@daily", OverlapPolicy: "queue"}, false},
+		{"reserved param", jenkinsfile.Triggers{Cron: "@daily", Parameters: map[string]string{"version": "1.0.0"}}, false},
+	}
+	for _, tc := range testCases {
+		err := tc.triggers.Validate()
+		if tc.valid {
+			assert.NoError(t, err, tc.name)
+		} else {
+			assert.Error(t, err, tc.name)
+		}
+	}
+}
@@ -0,0 +1,238 @@
+package jenkinsfile
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jenkins-x/jx/pkg/tekton/syntax"
+	"github.com/jenkins-x/jx/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// ImageChecker returns true if the agent or step image is known such as being a pod template or in the version stream
+type ImageChecker func(image string) bool
+
+// validWhenExpressions the when expressions which are supported by Tekton based pipelines
+var validWhenExpressions = []string{"prow", "!prow"}
+
+// ValidateSemantics performs the semantic checks of the pipeline configuration which are not covered by the schema,
+// returning a message for each problem found. The checks are:
+//
+// * agent and step images are known via the image checker
+// * the overrides refer to pipelines and stages which exist
+// * environment variables are not defined more than once in the same scope
+// * when expressions are supported
+// * the branch overrides have valid patterns and overrides
+// * the triggers have a valid schedule, overlap policy and params
+func (c *PipelineConfig) ValidateSemantics(imageChecker ImageChecker) []string {
+	v := &semanticValidator{
+		imageChecker: imageChecker,
+		images:       map[string]string{},
+	}
+	if c.Agent != nil {
+		v.addImage(c.Agent.Image, "agent")
+	}
+	v.checkEnv(c.Env, "env")
+
+	pipelines := c.Pipelines
+	for kind, lifecycles := range pipelines.AllMap() {
+		v.checkLifecycles(lifecycles, fmt.Sprintf("pipelines.%s", kind))
+	}
+	if pipelines.Post != nil {
+		v.checkLifecycle(pipelines.Post, "pipelines.post")
+	}
+	if pipelines.Default != nil {
+		v.checkParsedPipeline(pipelines.Default, "pipelines.default")
+	}
+	v.checkOverrides(&pipelines)
+	v.checkBranches(c.Branches)
+	if c.Triggers != nil {
+		err := c.Triggers.Validate()
+		if err != nil {
+			v.addError("triggers: %s", err.Error())
+		}
+	}
+
+	if v.imageChecker != nil {
+		for image, path := range v.images {
+			if !v.imageChecker(image) {
+				v.addError("%s: unknown image %s which is not a pod template and not in the version stream", path, image)
+			}
+		}
+	}
+	sort.Strings(v.errors)
+	return v.errors
+}
+
+type semanticValidator struct {
+	imageChecker ImageChecker
+	// images maps each image to the first path it was used at
+	images map[string]string
+	errors []string
+}
+
+func (v *semanticValidator) addError(format string, args ...interface{}) {
+	v.errors = append(v.errors, fmt.Sprintf(format, args...))
+}
+
+func (v *semanticValidator) addImage(image string, path string) {
+	if image == "" {
+		return
+	}
+	if _, ok := v.images[image]; !ok {
+		v.images[image] = path
+	}
+}
+
+func (v *semanticValidator) addAgent(agent *syntax.Agent, path string) {
+	if agent != nil {
+		v.addImage(agent.Image, path+".agent")
+	}
+}
+
+// checkEnv reports any environment variables defined more than once in the same scope
+func (v *semanticValidator) checkEnv(env []corev1.EnvVar, path string) {
+	seen := map[string]bool{}
+	for _, e := range env {
+		if seen[e.Name] {
+			v.addError("%s: environment variable %s is defined more than once", path, e.Name)
+		}
+		seen[e.Name] = true
+	}
+}
+
+func (v *semanticValidator) checkLifecycles(lifecycles *PipelineLifecycles, path string) {
+	if lifecycles == nil {
+		return
+	}
+	for _, l := range lifecycles.All() {
+		v.checkLifecycle(l.Lifecycle, path+"."+l.Name)
+	}
+	if lifecycles.Pipeline != nil {
+		v.checkParsedPipeline(lifecycles.Pipeline, path+".pipeline")
+	}
+}
+
+func (v *semanticValidator) checkLifecycle(lifecycle *PipelineLifecycle, path string) {
+	if lifecycle == nil {
+		return
+	}
+	v.checkSteps(lifecycle.PreSteps, path+".preSteps")
+	v.checkSteps(lifecycle.Steps, path+".steps")
+}
+
+func (v *semanticValidator) checkSteps(steps []*syntax.Step, path string) {
+	for i, step := range steps {
+		if step != nil {
+			v.checkStep(step, fmt.Sprintf("%s[%d]", path, i))
+		}
+	}
+}
+
+func (v *semanticValidator) checkStep(step *syntax.Step, path string) {
+	v.addImage(step.Image, path+".image")
+	v.addAgent(step.Agent, path)
+	v.checkEnv(step.Env, path+".env")
+	when := strings.TrimSpace(step.When)
+	if when != "" && util.StringArrayIndex(validWhenExpressions, when) < 0 {
+		v.addError("%s: invalid when expression '%s' which should be one of: %s", path, step.When, strings.Join(validWhenExpressions, ", "))
+	}
+	v.checkSteps(step.Steps, path+".steps")
+	if step.Loop != nil {
+		for i := range step.Loop.Steps {
+			v.checkStep(&step.Loop.Steps[i], fmt.Sprintf("%s.loop.steps[%d]", path, i))
+		}
+	}
+}
+
+func (v *semanticValidator) checkParsedPipeline(parsed *syntax.ParsedPipeline, path string) {
+	v.addAgent(parsed.Agent, path)
+	v.checkEnv(append(append([]corev1.EnvVar{}, parsed.Env...), parsed.Environment...), path+".env")
+	v.checkStages(parsed.Stages, path+".stages")
+}
+
+func (v *semanticValidator) checkStages(stages []syntax.Stage, path string) {
+	for i := range stages {
+		stage := &stages[i]
+		stagePath := fmt.Sprintf("%s[%s]", path, stage.Name)
+		v.addAgent(stage.Agent, stagePath)
+		v.checkEnv(append(append([]corev1.EnvVar{}, stage.Env...), stage.Environment...), stagePath+".env")
+		for j := range stage.Steps {
+			v.checkStep(&stage.Steps[j], fmt.Sprintf("%s.steps[%d]", stagePath, j))
+		}
+		v.checkStages(stage.Stages, stagePath+".stages")
+		v.checkStages(stage.Parallel, stagePath+".parallel")
+	}
+}
+
+// checkOverrides reports any overrides which refer to an unknown kind of pipeline or to a stage which does not exist in
+// the pipelines defined in the configuration. Stages can only be checked for pipelines defined in jenkins-x.yml as any
+// other stages come from the build pack
+func (v *semanticValidator) checkOverrides(pipelines *Pipelines) {
+	for i, override := range pipelines.Overrides {
+		if override == nil {
+			continue
+		}
+		path := fmt.Sprintf("pipelines.overrides[%d]", i)
+		if override.Step != nil {
+			v.checkStep(override.Step, path+".step")
+		}
+		v.checkSteps(override.Steps, path+".steps")
+		v.addAgent(override.Agent, path)
+
+		if override.Pipeline != "" && util.StringArrayIndex(PipelineKinds, strings.ToLower(override.Pipeline)) < 0 {
+			v.addError("%s: unknown pipeline '%s' which should be one of: %s", path, override.Pipeline, strings.Join(PipelineKinds, ", "))
+			continue
+		}
+		if override.Stage == "" {
+			continue
+		}
+		for kind, lifecycles := range pipelines.AllMap() {
+			if !override.MatchesPipeline(kind) || lifecycles.Pipeline == nil {
+				continue
+			}
+			if !hasStage(lifecycles.Pipeline.Stages, override.Stage) {
+				v.addError("%s: stage '%s' is not defined in the %s pipeline", path, override.Stage, kind)
+			}
+		}
+	}
+}
+
+// checkBranches reports any branch overrides with invalid patterns, overrides or environment variables
+func (v *semanticValidator) checkBranches(branches []*BranchOverride) {
+	for i, branch := range branches {
+		if branch == nil {
+			continue
+		}
+		path := fmt.Sprintf("branches[%d]", i)
+		err := branch.Validate()
+		if err != nil {
+			v.addError("%s: %s", path, err.Error())
+		}
+		v.addAgent(branch.Agent, path)
+		v.checkEnv(branch.Env, path+".env")
+		for j, override := range branch.Overrides {
+			if override == nil {
+				continue
+			}
+			overridePath := fmt.Sprintf("%s.overrides[%d]", path, j)
+			if override.Step != nil {
+				v.checkStep(override.Step, overridePath+".step")
+			}
+			v.checkSteps(override.Steps, overridePath+".steps")
+			if override.Pipeline != "" && util.StringArrayIndex(PipelineKinds, strings.ToLower(override.Pipeline)) < 0 {
+				v.addError("%s: unknown pipeline '%s' which should be one of: %s", overridePath, override.Pipeline, strings.Join(PipelineKinds, ", "))
+			}
+		}
+	}
+}
+
+func hasStage(stages []syntax.Stage, name string) bool {
+	for _, stage := range stages {
+		if stage.Name == name || hasStage(stage.Stages, name) || hasStage(stage.Parallel, name) {
+			return true
+		}
+	}
+	return false
+}
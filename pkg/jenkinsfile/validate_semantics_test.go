@@ -0,0 +1,63 @@
+package jenkinsfile_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jenkins-x/jx/pkg/jenkinsfile"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/yaml"
+)
+
+func TestValidateSemantics(t *testing.T) {
+	t.Parallel()
+	data := `agent:
+  image: maven
+env:
+- name: FOO
+  value: a
+- name: FOO
+  value: b
+pipelines:
+  overrides:
+  - pipeline: relase
+    stage: build
+  - pipeline: release
+    stage: missing
+    step:
+      command: echo hi
+  pullRequest:
+    build:
+      steps:
+      - command: make test
+        when: "env.BRANCH_NAME == 'master'"
+      - command: make lint
+        when: "!prow"
+  release:
+    pipeline:
+      stages:
+      - name: build
+        agent:
+          image: gcr.io/example/unknown
+        steps:
+        - command: make build
+          image: gcr.io/example/builder:1.0.0
+`
+	config := &jenkinsfile.PipelineConfig{}
+	require.NoError(t, yaml.Unmarshal([]byte(data), config))
+
+	knownImages := map[string]bool{"maven": true}
+	errors := config.ValidateSemantics(func(image string) bool {
+		return knownImages[image] || strings.Contains(image, ":")
+	})
+
+	require.Len(t, errors, 5, "errors: %s", strings.Join(errors, "\n"))
+	assert.Contains(t, errors[0], "environment variable FOO is defined more than once")
+	assert.Contains(t, errors[1], "pipelines.overrides[0]: unknown pipeline 'relase'")
+	assert.Contains(t, errors[2], "pipelines.overrides[1]: stage 'missing' is not defined in the release pipeline")
+	assert.Contains(t, errors[3], "pipelines.pullrequest.build.steps[0]: invalid when expression")
+	assert.Contains(t, errors[4], "unknown image gcr.io/example/unknown")
+
+	assert.Empty(t, (&jenkinsfile.PipelineConfig{}).ValidateSemantics(nil))
+}
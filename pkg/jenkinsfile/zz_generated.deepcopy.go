@@ -9,6 +9,50 @@ import (
 	v1 "k8s.io/api/core/v1"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BranchOverride) DeepCopyInto(out *BranchOverride) {
+	*out = *in
+	if in.Agent != nil {
+		in, out := &in.Agent, &out.Agent
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(syntax.Agent)
+			**out = **in
+		}
+	}
+	if in.Env != nil {
+		in, out := &in.Env, &out.Env
+		*out = make([]v1.EnvVar, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Overrides != nil {
+		in, out := &in.Overrides, &out.Overrides
+		*out = make([]*syntax.PipelineOverride, len(*in))
+		for i := range *in {
+			if (*in)[i] == nil {
+				(*out)[i] = nil
+			} else {
+				(*out)[i] = new(syntax.PipelineOverride)
+				(*in)[i].DeepCopyInto((*out)[i])
+			}
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BranchOverride.
+func (in *BranchOverride) DeepCopy() *BranchOverride {
+	if in == nil {
+		return nil
+	}
+	out := new(BranchOverride)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CreateJenkinsfileArguments) DeepCopyInto(out *CreateJenkinsfileArguments) {
 	*out = *in
@@ -185,6 +229,27 @@ func (in *PipelineConfig) DeepCopyInto(out *PipelineConfig) {
 			(*in).DeepCopyInto(*out)
 		}
 	}
+	if in.Branches != nil {
+		in, out := &in.Branches, &out.Branches
+		*out = make([]*BranchOverride, len(*in))
+		for i := range *in {
+			if (*in)[i] == nil {
+				(*out)[i] = nil
+			} else {
+				(*out)[i] = new(BranchOverride)
+				(*in)[i].DeepCopyInto((*out)[i])
+			}
+		}
+	}
+	if in.Triggers != nil {
+		in, out := &in.Triggers, &out.Triggers
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(Triggers)
+			(*in).DeepCopyInto(*out)
+		}
+	}
 	return
 }
 
@@ -214,6 +279,34 @@ func (in *PipelineExtends) DeepCopy() *PipelineExtends {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PipelineExtensions) DeepCopyInto(out *PipelineExtensions) {
+	*out = *in
+	if in.Extensions != nil {
+		in, out := &in.Extensions, &out.Extensions
+		*out = make([]*syntax.PipelineOverride, len(*in))
+		for i := range *in {
+			if (*in)[i] == nil {
+				(*out)[i] = nil
+			} else {
+				(*out)[i] = new(syntax.PipelineOverride)
+				(*in)[i].DeepCopyInto((*out)[i])
+			}
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PipelineExtensions.
+func (in *PipelineExtensions) DeepCopy() *PipelineExtensions {
+	if in == nil {
+		return nil
+	}
+	out := new(PipelineExtensions)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PipelineLifecycle) DeepCopyInto(out *PipelineLifecycle) {
 	*out = *in
@@ -427,3 +520,26 @@ func (in *Pipelines) DeepCopy() *Pipelines {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Triggers) DeepCopyInto(out *Triggers) {
+	*out = *in
+	if in.Parameters != nil {
+		in, out := &in.Parameters, &out.Parameters
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Triggers.
+func (in *Triggers) DeepCopy() *Triggers {
+	if in == nil {
+		return nil
+	}
+	out := new(Triggers)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -230,6 +230,9 @@ const (
 	// ValueCreatedByJX for resources created by the Jenkins X CLI
 	ValueCreatedByJX = "jx"
 
+	// ValueCreatedByDiscovery for SourceRepositories created by the discovery controller
+	ValueCreatedByDiscovery = "jx-discovery"
+
 	// LabelCredentialsType the kind of jenkins credential for a secret
 	LabelCredentialsType = "jenkins.io/credentials-type"
 
@@ -282,8 +285,16 @@ const (
 	AnnotationLocalDir = "jenkins.io/local-dir"
 	// AnnotationGitURLs the newline separated list of git URLs of the DevPods
 	AnnotationGitURLs = "jenkins.io/git-urls"
+	// AnnotationDevPodIDE the remote IDE a DevPod was created for such as vscode or goland
+	AnnotationDevPodIDE = "jenkins.io/devpod-ide"
+	// AnnotationDevPodIdleTimeout the duration after which an idle DevPod is deleted
+	AnnotationDevPodIdleTimeout = "jenkins.io/devpod-idle-timeout"
+	// AnnotationDevPodLastActivity the RFC3339 time a DevPod was last used by a jx command
+	AnnotationDevPodLastActivity = "jenkins.io/devpod-last-activity"
 	// AnnotationGitReportState used to annotate what state has been reported to git
 	AnnotationGitReportState = "jenkins.io/git-report-state"
+	// AnnotationGitCheckRunID used to annotate the ID of the check run reported to git
+	AnnotationGitCheckRunID = "jenkins.io/git-check-run-id"
 
 	// AnnotationIsDefaultStorageClass used to indicate a storageclass is default
 	AnnotationIsDefaultStorageClass = "storageclass.kubernetes.io/is-default-class"
@@ -291,6 +302,9 @@ const (
 	// AnnotationReleaseName is the name of the annotation that stores the release name in the preview environment
 	AnnotationReleaseName = "jenkins.io/chart-release"
 
+	// AnnotationPreviewCommit is the name of the annotation that stores the git commit last deployed to the preview environment
+	AnnotationPreviewCommit = "jenkins.io/preview-commit"
+
 	// SecretDataUsername the username in a Secret/Credentials
 	SecretDataUsername = "username"
 
@@ -38,6 +38,10 @@ func RegisterAllCRDs(apiClient apiextensionsclientset.Interface) error {
 	if err != nil {
 		return errors.Wrap(err, "failed to register the App CRD")
 	}
+	err = RegisterAuditEventCRD(apiClient)
+	if err != nil {
+		return errors.Wrap(err, "failed to register the Audit Event CRD")
+	}
 	err = RegisterPluginCRD(apiClient)
 	if err != nil {
 		return errors.Wrap(err, "failed to register the Plugin CRD")
@@ -158,6 +162,24 @@ func RegisterEnvironmentCRD(apiClient apiextensionsclientset.Interface) error {
 			Description: "The git branch for the source of the environment configuration",
 			JSONPath:    ".spec.source.ref",
 		},
+		{
+			Name:        "Status",
+			Type:        "string",
+			Description: "The sync status of the last apply of the environment",
+			JSONPath:    ".status.syncStatus",
+		},
+		{
+			Name:        "Applied",
+			Type:        "string",
+			Description: "The last git commit applied to the environment",
+			JSONPath:    ".status.lastAppliedCommit",
+		},
+		{
+			Name:        "Drifted",
+			Type:        "integer",
+			Description: "The number of applications which do not run the versions of the git repository",
+			JSONPath:    ".status.driftedResources",
+		},
 	}
 	return RegisterCRD(apiClient, name, names, columns, jenkinsio.GroupName, jenkinsio.Package, jenkinsio.Version)
 }
@@ -248,6 +270,40 @@ func RegisterPipelineStructureCRD(apiClient apiextensionsclientset.Interface) er
 	return RegisterCRD(apiClient, name, names, columns, jenkinsio.GroupName, jenkinsio.Package, jenkinsio.Version)
 }
 
+// RegisterAuditEventCRD ensures that the CRD is registered for AuditEvent
+func RegisterAuditEventCRD(apiClient apiextensionsclientset.Interface) error {
+	name := "auditevents." + jenkinsio.GroupName
+	names := &v1beta1.CustomResourceDefinitionNames{
+		Kind:       "AuditEvent",
+		ListKind:   "AuditEventList",
+		Plural:     "auditevents",
+		Singular:   "auditevent",
+		ShortNames: []string{"audit"},
+		Categories: []string{"all"},
+	}
+	columns := []v1beta1.CustomResourceColumnDefinition{
+		{
+			Name:        "Action",
+			Type:        "string",
+			Description: "The kind of change",
+			JSONPath:    ".spec.action",
+		},
+		{
+			Name:        "User",
+			Type:        "string",
+			Description: "The user who made the change",
+			JSONPath:    ".spec.user",
+		},
+		{
+			Name:        "Resource",
+			Type:        "string",
+			Description: "The resource which was changed",
+			JSONPath:    ".spec.resource",
+		},
+	}
+	return RegisterCRD(apiClient, name, names, columns, jenkinsio.GroupName, jenkinsio.Package, jenkinsio.Version)
+}
+
 // RegisterFactCRD ensures that the CRD is registered for Fact
 func RegisterFactCRD(apiClient apiextensionsclientset.Interface) error {
 	name := "facts." + jenkinsio.GroupName
@@ -0,0 +1,429 @@
+package leaderelection
+
+import (
+	"fmt"
+	"hash/fnv"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jenkins-x/jx/pkg/kube/naming"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/observability"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	coordinationv1beta1 "k8s.io/api/coordination/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// LabelLeaseGroup the label of the Leases of a controller whose value is the name of the Lease of the leader
+	LabelLeaseGroup = "jenkins.io/lease-group"
+	// LabelLeaseKind the label of the kind of the Leases of a controller
+	LabelLeaseKind = "jenkins.io/lease-kind"
+
+	// LeaseKindShard the kind of the Leases owning the shards of the work of a controller
+	LeaseKindShard = "shard"
+	// LeaseKindMember the kind of the Leases of the live replicas of a controller which share the shards
+	LeaseKindMember = "member"
+
+	// DefaultLeaseDuration the default time a replica owns a Lease after renewing it
+	DefaultLeaseDuration = 15 * time.Second
+	// DefaultRetryPeriod the default period between renewing and acquiring the Leases
+	DefaultRetryPeriod = 2 * time.Second
+)
+
+// Options the command line options of the leader election of a controller
+type Options struct {
+	Enabled       bool
+	LeaseDuration time.Duration
+	RetryPeriod   time.Duration
+	Shards        int
+}
+
+// AddFlags adds the flags of the leader election to the command
+func (o *Options) AddFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVarP(&o.Enabled, "leader-elect", "", false, "Runs the controller as one of several replicas which elect a leader and share the work using Kubernetes Leases so that the work continues if a replica stops")
+	cmd.Flags().DurationVarP(&o.LeaseDuration, "leader-elect-lease-duration", "", DefaultLeaseDuration, "The time a replica owns a Lease after renewing it. Once it expires another replica takes over the work of the Lease")
+	cmd.Flags().DurationVarP(&o.RetryPeriod, "leader-elect-retry-period", "", DefaultRetryPeriod, "The period between renewing and acquiring the Leases")
+}
+
+// AddShardFlags adds the flags of the sharding of the work to the command of a controller whose work can be divided
+// between the replicas
+func (o *Options) AddShardFlags(cmd *cobra.Command) {
+	cmd.Flags().IntVarP(&o.Shards, "shards", "", 1, "The number of shards the work is divided into. Each replica owns an equal share of the shards if leader election is enabled")
+}
+
+// NewElector creates the elector of the Leases of the controller in the namespace. Returns nil if leader election is
+// disabled so that the replica does all the work
+func (o *Options) NewElector(kubeClient kubernetes.Interface, ns string, controller string, metrics *observability.ControllerMetrics) (*Elector, error) {
+	if !o.Enabled {
+		return nil, nil
+	}
+	shards := o.Shards
+	if shards == 0 {
+		shards = 1
+	}
+	if shards < 1 {
+		return nil, util.InvalidOptionf("shards", o.Shards, "must be at least 1")
+	}
+	if o.RetryPeriod <= 0 || o.LeaseDuration <= o.RetryPeriod {
+		return nil, util.InvalidOptionf("leader-elect-lease-duration", o.LeaseDuration, "must be longer than the retry period %s", o.RetryPeriod)
+	}
+	identity, _ := os.Hostname()
+	if identity == "" {
+		identity = uuid.New().String()
+	}
+	return &Elector{
+		Client:        kubeClient,
+		Namespace:     ns,
+		Name:          naming.ToValidName("jx-" + controller),
+		Identity:      naming.ToValidName(identity),
+		Shards:        shards,
+		LeaseDuration: o.LeaseDuration,
+		RetryPeriod:   o.RetryPeriod,
+		Controller:    controller,
+		Metrics:       metrics,
+	}, nil
+}
+
+// Elector elects the leader of the replicas of a controller and divides the shards of its work between the replicas
+// using Leases. The leader is the replica owning the first shard. A nil Elector owns all the work
+type Elector struct {
+	Client    kubernetes.Interface
+	Namespace string
+	// Name the name of the Lease of the first shard. The Leases of the other shards and replicas are prefixed by it
+	Name     string
+	Identity string
+	Shards   int
+	// LeaseDuration the time a replica owns a Lease after renewing it
+	LeaseDuration time.Duration
+	RetryPeriod   time.Duration
+	// OnChange is called when the shards owned by the replica change so that it can process the work of new shards
+	OnChange   func()
+	Controller string
+	Metrics    *observability.ControllerMetrics
+
+	lock    sync.RWMutex
+	renewed map[int]time.Time
+	now     func() time.Time
+}
+
+// Run renews and acquires the Leases periodically until the channel is closed, then releases them
+func (e *Elector) Run(stop <-chan struct{}) {
+	if e == nil {
+		return
+	}
+	log.Logger().Infof("Electing the leader of %s as %s with %s shards", util.ColorInfo(e.Controller), util.ColorInfo(e.Identity), util.ColorInfo(e.Shards))
+	for {
+		err := e.Reconcile()
+		if err != nil {
+			log.Logger().Warnf("Failed to renew the Leases of %s: %s", e.Controller, err)
+		}
+		select {
+		case <-stop:
+			err = e.Release()
+			if err != nil {
+				log.Logger().Warnf("Failed to release the Leases of %s: %s", e.Controller, err)
+			}
+			return
+		case <-time.After(e.RetryPeriod):
+		}
+	}
+}
+
+// IsLeader returns true if the replica is the leader
+func (e *Elector) IsLeader() bool {
+	if e == nil {
+		return true
+	}
+	return e.ownsShard(0)
+}
+
+// Owns returns true if the replica owns the shard of the key so should process its work
+func (e *Elector) Owns(key string) bool {
+	if e == nil {
+		return true
+	}
+	return e.ownsShard(ShardOf(key, e.Shards))
+}
+
+// OwnedShards returns the number of shards the replica owns
+func (e *Elector) OwnedShards() int {
+	if e == nil {
+		return 1
+	}
+	count := 0
+	for i := 0; i < e.Shards; i++ {
+		if e.ownsShard(i) {
+			count++
+		}
+	}
+	return count
+}
+
+// ShardOf returns the shard of the work of the key
+func ShardOf(key string, shards int) int {
+	if shards <= 1 {
+		return 0
+	}
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+	return int(h.Sum32() % uint32(shards))
+}
+
+// LeaseName returns the name of the Lease of the shard
+func (e *Elector) LeaseName(shard int) string {
+	if shard == 0 {
+		return e.Name
+	}
+	return fmt.Sprintf("%s-%d", e.Name, shard)
+}
+
+// ownsShard returns true if the replica renewed the Lease of the shard recently enough that no other replica can have
+// acquired it
+func (e *Elector) ownsShard(shard int) bool {
+	e.lock.RLock()
+	defer e.lock.RUnlock()
+	renewed, ok := e.renewed[shard]
+	return ok && e.currentTime().Sub(renewed) < e.LeaseDuration
+}
+
+// Reconcile renews the Leases of the shards the replica owns and acquires the free or expired Leases of shards until
+// the replica owns its share of the shards. Replicas owning more than their share release the extra shards
+func (e *Elector) Reconcile() error {
+	before := e.OwnedShards()
+	now := e.currentTime()
+	leases, err := e.Client.CoordinationV1beta1().Leases(e.Namespace).List(metav1.ListOptions{
+		LabelSelector: LabelLeaseGroup + "=" + e.Name,
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to list the Leases of %s in namespace %s", e.Name, e.Namespace)
+	}
+	byName := map[string]*coordinationv1beta1.Lease{}
+	for i := range leases.Items {
+		byName[leases.Items[i].Name] = &leases.Items[i]
+	}
+
+	errs := []error{}
+	share := 1
+	if e.Shards > 1 {
+		members, err := e.renewMember(byName, now)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		share = (e.Shards + members - 1) / members
+	}
+
+	owned := 0
+	free := []int{}
+	for shard := 0; shard < e.Shards; shard++ {
+		lease := byName[e.LeaseName(shard)]
+		holder := e.holder(lease, now)
+		switch {
+		case holder == e.Identity && owned < share:
+			err = e.hold(lease, e.LeaseName(shard), LeaseKindShard, now)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			e.setRenewed(shard, now)
+			owned++
+		case holder == e.Identity:
+			e.deleteRenewed(shard)
+			errs = append(errs, e.release(lease))
+		case holder == "":
+			free = append(free, shard)
+		default:
+			e.deleteRenewed(shard)
+		}
+	}
+	for _, shard := range free {
+		if owned >= share {
+			break
+		}
+		err = e.hold(byName[e.LeaseName(shard)], e.LeaseName(shard), LeaseKindShard, now)
+		if err != nil {
+			// another replica acquired it first
+			continue
+		}
+		e.setRenewed(shard, now)
+		owned++
+	}
+
+	after := e.OwnedShards()
+	if e.Metrics != nil {
+		leader := 0.0
+		if e.IsLeader() {
+			leader = 1
+		}
+		e.Metrics.Leader.WithLabelValues(e.Controller).Set(leader)
+		e.Metrics.Shards.WithLabelValues(e.Controller).Set(float64(after))
+	}
+	if after != before {
+		log.Logger().Infof("%s now owns %s of the %d shards of %s. Leader: %t", e.Identity, util.ColorInfo(after), e.Shards, e.Controller, e.IsLeader())
+		if e.OnChange != nil {
+			e.OnChange()
+		}
+	}
+	return util.CombineErrors(errs...)
+}
+
+// Release releases the Leases the replica owns so that other replicas can take over the work immediately
+func (e *Elector) Release() error {
+	if e == nil {
+		return nil
+	}
+	leases := e.Client.CoordinationV1beta1().Leases(e.Namespace)
+	errs := []error{}
+	for shard := 0; shard < e.Shards; shard++ {
+		if !e.ownsShard(shard) {
+			continue
+		}
+		e.deleteRenewed(shard)
+		lease, err := leases.Get(e.LeaseName(shard), metav1.GetOptions{})
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if e.holder(lease, e.currentTime()) == e.Identity {
+			errs = append(errs, e.release(lease))
+		}
+	}
+	if e.Shards > 1 {
+		err := leases.Delete(e.memberLeaseName(), &metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			errs = append(errs, err)
+		}
+	}
+	return util.CombineErrors(errs...)
+}
+
+// renewMember renews the member Lease of the replica, deletes the expired member Leases of replicas which have stopped
+// and returns the number of live replicas
+func (e *Elector) renewMember(byName map[string]*coordinationv1beta1.Lease, now time.Time) (int, error) {
+	name := e.memberLeaseName()
+	err := e.hold(byName[name], name, LeaseKindMember, now)
+	members := 1
+	for _, lease := range byName {
+		if lease.Labels[LabelLeaseKind] != LeaseKindMember || lease.Name == name {
+			continue
+		}
+		if e.holder(lease, now) != "" {
+			members++
+			continue
+		}
+		delErr := e.Client.CoordinationV1beta1().Leases(e.Namespace).Delete(lease.Name, &metav1.DeleteOptions{})
+		if delErr != nil && !apierrors.IsNotFound(delErr) {
+			log.Logger().Debugf("Failed to delete the expired Lease %s: %s", lease.Name, delErr)
+		}
+	}
+	return members, err
+}
+
+func (e *Elector) memberLeaseName() string {
+	return naming.ToValidName(e.Name + "-member-" + e.Identity)
+}
+
+// holder returns the identity of the replica holding the Lease or an empty string if the Lease is free or expired
+func (e *Elector) holder(lease *coordinationv1beta1.Lease, now time.Time) string {
+	if lease == nil || lease.Spec.HolderIdentity == nil || lease.Spec.RenewTime == nil {
+		return ""
+	}
+	duration := e.LeaseDuration
+	if lease.Spec.LeaseDurationSeconds != nil {
+		duration = time.Duration(*lease.Spec.LeaseDurationSeconds) * time.Second
+	}
+	if now.After(lease.Spec.RenewTime.Add(duration)) {
+		return ""
+	}
+	return *lease.Spec.HolderIdentity
+}
+
+// hold creates, acquires or renews the Lease for the replica. Fails if another replica updated the Lease since it was
+// listed
+func (e *Elector) hold(lease *coordinationv1beta1.Lease, name string, kind string, now time.Time) error {
+	leases := e.Client.CoordinationV1beta1().Leases(e.Namespace)
+	renewTime := metav1.NewMicroTime(now)
+	durationSeconds := int32(e.LeaseDuration / time.Second)
+	identity := e.Identity
+	if lease == nil {
+		transitions := int32(0)
+		_, err := leases.Create(&coordinationv1beta1.Lease{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name,
+				Labels: map[string]string{
+					LabelLeaseGroup: e.Name,
+					LabelLeaseKind:  kind,
+				},
+			},
+			Spec: coordinationv1beta1.LeaseSpec{
+				HolderIdentity:       &identity,
+				LeaseDurationSeconds: &durationSeconds,
+				AcquireTime:          &renewTime,
+				RenewTime:            &renewTime,
+				LeaseTransitions:     &transitions,
+			},
+		})
+		if err != nil {
+			return errors.Wrapf(err, "failed to create Lease %s", name)
+		}
+		return nil
+	}
+	copy := lease.DeepCopy()
+	spec := &copy.Spec
+	if spec.HolderIdentity == nil || *spec.HolderIdentity != identity {
+		transitions := int32(0)
+		if spec.LeaseTransitions != nil {
+			transitions = *spec.LeaseTransitions + 1
+		}
+		spec.HolderIdentity = &identity
+		spec.AcquireTime = &renewTime
+		spec.LeaseTransitions = &transitions
+	}
+	spec.LeaseDurationSeconds = &durationSeconds
+	spec.RenewTime = &renewTime
+	_, err := leases.Update(copy)
+	if err != nil {
+		return errors.Wrapf(err, "failed to update Lease %s", name)
+	}
+	return nil
+}
+
+// release frees the Lease so that another replica can acquire it
+func (e *Elector) release(lease *coordinationv1beta1.Lease) error {
+	copy := lease.DeepCopy()
+	copy.Spec.HolderIdentity = nil
+	copy.Spec.RenewTime = nil
+	_, err := e.Client.CoordinationV1beta1().Leases(e.Namespace).Update(copy)
+	if err != nil {
+		return errors.Wrapf(err, "failed to release Lease %s", lease.Name)
+	}
+	return nil
+}
+
+func (e *Elector) setRenewed(shard int, t time.Time) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	if e.renewed == nil {
+		e.renewed = map[int]time.Time{}
+	}
+	e.renewed[shard] = t
+}
+
+func (e *Elector) deleteRenewed(shard int) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	delete(e.renewed, shard)
+}
+
+func (e *Elector) currentTime() time.Time {
+	if e.now != nil {
+		return e.now()
+	}
+	return time.Now()
+}
@@ -0,0 +1,116 @@
+package leaderelection
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func newTestElector(client kubernetes.Interface, identity string, shards int, now *time.Time) *Elector {
+	return &Elector{
+		Client:        client,
+		Namespace:     "jx",
+		Name:          "jx-build",
+		Identity:      identity,
+		Shards:        shards,
+		LeaseDuration: 15 * time.Second,
+		RetryPeriod:   2 * time.Second,
+		Controller:    "build",
+		now: func() time.Time {
+			return *now
+		},
+	}
+}
+
+func TestElectorLeader(t *testing.T) {
+	t.Parallel()
+	client := fake.NewSimpleClientset()
+	now := time.Date(2020, 1, 2, 10, 0, 0, 0, time.UTC)
+	a := newTestElector(client, "a", 1, &now)
+	b := newTestElector(client, "b", 1, &now)
+
+	require.NoError(t, a.Reconcile())
+	require.NoError(t, b.Reconcile())
+	assert.True(t, a.IsLeader())
+	assert.False(t, b.IsLeader())
+	assert.True(t, a.Owns("myorg/myapp/master"))
+
+	lease, err := client.CoordinationV1beta1().Leases("jx").Get("jx-build", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "a", *lease.Spec.HolderIdentity)
+
+	// the leader stops renewing so the other replica takes over once the lease expires
+	now = now.Add(10 * time.Second)
+	require.NoError(t, b.Reconcile())
+	assert.False(t, b.IsLeader())
+	now = now.Add(10 * time.Second)
+	require.NoError(t, b.Reconcile())
+	assert.True(t, b.IsLeader())
+	assert.False(t, a.IsLeader())
+
+	// releasing the lease hands it over immediately
+	require.NoError(t, b.Release())
+	assert.False(t, b.IsLeader())
+	require.NoError(t, a.Reconcile())
+	assert.True(t, a.IsLeader())
+
+	// without leader election the replica does all the work
+	var disabled *Elector
+	assert.True(t, disabled.IsLeader())
+	assert.True(t, disabled.Owns("myorg/myapp/master"))
+}
+
+func TestElectorShards(t *testing.T) {
+	t.Parallel()
+	client := fake.NewSimpleClientset()
+	now := time.Date(2020, 1, 2, 10, 0, 0, 0, time.UTC)
+	a := newTestElector(client, "a", 4, &now)
+	b := newTestElector(client, "b", 4, &now)
+	changes := 0
+	b.OnChange = func() {
+		changes++
+	}
+
+	require.NoError(t, a.Reconcile())
+	assert.Equal(t, 4, a.OwnedShards())
+
+	// the new replica waits for the other replica to release its extra shards
+	require.NoError(t, b.Reconcile())
+	assert.Equal(t, 0, b.OwnedShards())
+	require.NoError(t, a.Reconcile())
+	assert.Equal(t, 2, a.OwnedShards())
+	require.NoError(t, b.Reconcile())
+	assert.Equal(t, 2, b.OwnedShards())
+	assert.Equal(t, 1, changes)
+	assert.True(t, a.IsLeader())
+	assert.False(t, b.IsLeader())
+
+	for i := 0; i < 20; i++ {
+		key := fmt.Sprintf("myorg/app%d/master", i)
+		assert.NotEqual(t, a.Owns(key), b.Owns(key), "exactly one replica owns %s", key)
+	}
+
+	// the first replica stops so the other replica takes over all the shards once their leases expire
+	now = now.Add(20 * time.Second)
+	require.NoError(t, b.Reconcile())
+	assert.Equal(t, 4, b.OwnedShards())
+	assert.True(t, b.IsLeader())
+	assert.Equal(t, 0, a.OwnedShards())
+
+	_, err := client.CoordinationV1beta1().Leases("jx").Get(a.memberLeaseName(), metav1.GetOptions{})
+	assert.Error(t, err, "the member lease of the stopped replica is deleted")
+}
+
+func TestShardOf(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, 0, ShardOf("myorg/myapp/master", 1))
+	shard := ShardOf("myorg/myapp/master", 8)
+	assert.True(t, shard >= 0 && shard < 8)
+	assert.Equal(t, shard, ShardOf("myorg/myapp/master", 8))
+}
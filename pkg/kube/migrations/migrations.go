@@ -0,0 +1,228 @@
+package migrations
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/jenkins-x/jx/pkg/client/clientset/versioned"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	apiextensionsclientset "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	// AnnotationSchemaVersion the annotation on a CustomResourceDefinition which records the schema version its stored
+	// objects have been migrated to
+	AnnotationSchemaVersion = "jenkins.io/schema-version"
+)
+
+// Migration migrates the stored objects of a CustomResourceDefinition to a schema version
+type Migration struct {
+	// CRD the name of the CustomResourceDefinition such as 'environments.jenkins.io'
+	CRD string
+	// Version the schema version the migration migrates to. The migrations of a CRD run in version order
+	Version int
+	// Description describes what the migration changes
+	Description string
+	// Migrate migrates a stored object in place returning true if it was modified
+	Migrate func(obj runtime.Object) (bool, error)
+}
+
+// Result the outcome of migrating a CustomResourceDefinition
+type Result struct {
+	// CRD the name of the CustomResourceDefinition
+	CRD string
+	// FromVersion the schema version before the migration
+	FromVersion int
+	// ToVersion the schema version after the migration
+	ToVersion int
+	// StoredVersions the stale storage versions which the objects were rewritten from
+	StoredVersions []string
+	// Updated the number of objects which were updated
+	Updated int
+}
+
+var registered []Migration
+
+// Register registers a migration which is run by Migrators which do not specify their own migrations
+func Register(m Migration) {
+	registered = append(registered, m)
+}
+
+// Registered returns the registered migrations
+func Registered() []Migration {
+	return append([]Migration{}, registered...)
+}
+
+// Migrator detects the CustomResourceDefinitions of jx resources whose stored objects are at an old schema version or
+// an old storage version and migrates and rewrites the stored objects
+type Migrator struct {
+	APIExtensionsClient apiextensionsclientset.Interface
+	JXClient            versioned.Interface
+	// Namespace the namespace of the objects to migrate. Defaults to all namespaces
+	Namespace string
+	// Migrations the migrations to run. Defaults to the registered migrations
+	Migrations []Migration
+	// DryRun reports the migrations which would run without modifying anything
+	DryRun bool
+}
+
+// Run runs the pending migrations returning the CustomResourceDefinitions which were migrated
+func (m *Migrator) Run() ([]Result, error) {
+	migrations := m.Migrations
+	if migrations == nil {
+		migrations = Registered()
+	}
+	byCRD := map[string][]Migration{}
+	for _, mig := range migrations {
+		byCRD[mig.CRD] = append(byCRD[mig.CRD], mig)
+	}
+	for name := range stores {
+		if _, ok := byCRD[name]; !ok {
+			byCRD[name] = nil
+		}
+	}
+	names := []string{}
+	for name := range byCRD {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	results := []Result{}
+	for _, name := range names {
+		r, err := m.migrateCRD(name, byCRD[name])
+		if err != nil {
+			return results, err
+		}
+		if r != nil {
+			results = append(results, *r)
+		}
+	}
+	return results, nil
+}
+
+func (m *Migrator) migrateCRD(name string, migrations []Migration) (*Result, error) {
+	crds := m.APIExtensionsClient.ApiextensionsV1beta1().CustomResourceDefinitions()
+	crd, err := crds.Get(name, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "failed to get the CustomResourceDefinition %s", name)
+	}
+	current, err := SchemaVersion(crd)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	pending := []Migration{}
+	for _, mig := range migrations {
+		if mig.Version > current {
+			pending = append(pending, mig)
+		}
+	}
+	storageVersion := StorageVersion(crd)
+	stale := []string{}
+	for _, v := range crd.Status.StoredVersions {
+		if v != storageVersion {
+			stale = append(stale, v)
+		}
+	}
+	if len(pending) == 0 && len(stale) == 0 {
+		return nil, nil
+	}
+	store, ok := stores[name]
+	if !ok {
+		return nil, fmt.Errorf("cannot migrate the CustomResourceDefinition %s as it is not a known jx resource", name)
+	}
+
+	result := &Result{CRD: name, FromVersion: current, ToVersion: current, StoredVersions: stale}
+	if len(pending) > 0 {
+		result.ToVersion = pending[len(pending)-1].Version
+	}
+	for _, mig := range pending {
+		log.Logger().Infof("Migrating %s to schema version %d: %s", util.ColorInfo(name), mig.Version, mig.Description)
+	}
+	if m.DryRun {
+		return result, nil
+	}
+
+	objects, err := store.list(m.JXClient, m.Namespace)
+	if err != nil {
+		return result, errors.Wrapf(err, "failed to list the %s", name)
+	}
+	for _, obj := range objects {
+		modified := len(stale) > 0
+		for _, mig := range pending {
+			changed, err := mig.Migrate(obj)
+			if err != nil {
+				return result, errors.Wrapf(err, "failed to migrate %s to schema version %d", objectName(obj), mig.Version)
+			}
+			modified = modified || changed
+		}
+		if !modified {
+			continue
+		}
+		err = store.update(m.JXClient, obj)
+		if err != nil {
+			return result, errors.Wrapf(err, "failed to update %s", objectName(obj))
+		}
+		result.Updated++
+	}
+
+	if crd.Annotations == nil {
+		crd.Annotations = map[string]string{}
+	}
+	crd.Annotations[AnnotationSchemaVersion] = strconv.Itoa(result.ToVersion)
+	crd, err = crds.Update(crd)
+	if err != nil {
+		return result, errors.Wrapf(err, "failed to record the schema version of %s", name)
+	}
+	if len(stale) > 0 {
+		// all the objects are now stored at the storage version so the old versions can be removed from the API server
+		crd.Status.StoredVersions = []string{storageVersion}
+		_, err = crds.UpdateStatus(crd)
+		if err != nil {
+			return result, errors.Wrapf(err, "failed to update the stored versions of %s", name)
+		}
+	}
+	return result, nil
+}
+
+// SchemaVersion returns the schema version the stored objects of the CustomResourceDefinition have been migrated to
+func SchemaVersion(crd *v1beta1.CustomResourceDefinition) (int, error) {
+	text := crd.Annotations[AnnotationSchemaVersion]
+	if text == "" {
+		return 0, nil
+	}
+	v, err := strconv.Atoi(text)
+	if err != nil {
+		return 0, errors.Wrapf(err, "invalid %s annotation %s on %s", AnnotationSchemaVersion, text, crd.Name)
+	}
+	return v, nil
+}
+
+// StorageVersion returns the version the API server stores the objects of the CustomResourceDefinition at
+func StorageVersion(crd *v1beta1.CustomResourceDefinition) string {
+	for _, v := range crd.Spec.Versions {
+		if v.Storage {
+			return v.Name
+		}
+	}
+	return crd.Spec.Version
+}
+
+func objectName(obj runtime.Object) string {
+	accessor, ok := obj.(metav1.Object)
+	if !ok {
+		return fmt.Sprintf("%T", obj)
+	}
+	return fmt.Sprintf("%s/%s", accessor.GetNamespace(), accessor.GetName())
+}
@@ -0,0 +1,107 @@
+package migrations_test
+
+import (
+	"testing"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	jxfake "github.com/jenkins-x/jx/pkg/client/clientset/versioned/fake"
+	"github.com/jenkins-x/jx/pkg/kube/migrations"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	apiextensionsfake "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestMigratorRun(t *testing.T) {
+	t.Parallel()
+	ns := "jx"
+
+	apiClient := apiextensionsfake.NewSimpleClientset(
+		&v1beta1.CustomResourceDefinition{
+			ObjectMeta: metav1.ObjectMeta{Name: migrations.EnvironmentsCRD},
+			Spec:       v1beta1.CustomResourceDefinitionSpec{Version: "v1"},
+			Status:     v1beta1.CustomResourceDefinitionStatus{StoredVersions: []string{"v1"}},
+		},
+		&v1beta1.CustomResourceDefinition{
+			ObjectMeta: metav1.ObjectMeta{Name: migrations.AppsCRD},
+			Spec:       v1beta1.CustomResourceDefinitionSpec{Version: "v1"},
+			Status:     v1beta1.CustomResourceDefinitionStatus{StoredVersions: []string{"v1alpha1", "v1"}},
+		},
+	)
+	jxClient := jxfake.NewSimpleClientset(
+		&v1.Environment{ObjectMeta: metav1.ObjectMeta{Name: "dev", Namespace: ns}, Spec: v1.EnvironmentSpec{Kind: v1.EnvironmentKindTypeDevelopment}},
+		&v1.Environment{ObjectMeta: metav1.ObjectMeta{Name: "staging", Namespace: ns}, Spec: v1.EnvironmentSpec{Kind: v1.EnvironmentKindTypePermanent}},
+		&v1.App{ObjectMeta: metav1.ObjectMeta{Name: "jx-app-sonar", Namespace: ns}},
+	)
+
+	migrator := &migrations.Migrator{
+		APIExtensionsClient: apiClient,
+		JXClient:            jxClient,
+		Migrations: []migrations.Migration{
+			{
+				CRD:         migrations.EnvironmentsCRD,
+				Version:     2,
+				Description: "label the environments",
+				Migrate: func(obj runtime.Object) (bool, error) {
+					env := obj.(*v1.Environment)
+					if env.Labels == nil {
+						env.Labels = map[string]string{}
+					}
+					env.Labels["migrated"] = "2"
+					return true, nil
+				},
+			},
+			{
+				CRD:         migrations.EnvironmentsCRD,
+				Version:     1,
+				Description: "only label the dev environment",
+				Migrate: func(obj runtime.Object) (bool, error) {
+					env := obj.(*v1.Environment)
+					if env.Spec.Kind != v1.EnvironmentKindTypeDevelopment {
+						return false, nil
+					}
+					env.Labels = map[string]string{"migrated": "1"}
+					return true, nil
+				},
+			},
+		},
+	}
+
+	dryRun := *migrator
+	dryRun.DryRun = true
+	results, err := dryRun.Run()
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, 0, results[0].Updated)
+
+	results, err = migrator.Run()
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+
+	assert.Equal(t, migrations.AppsCRD, results[0].CRD)
+	assert.Equal(t, []string{"v1alpha1"}, results[0].StoredVersions)
+	assert.Equal(t, 1, results[0].Updated)
+	appsCRD, err := apiClient.ApiextensionsV1beta1().CustomResourceDefinitions().Get(migrations.AppsCRD, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"v1"}, appsCRD.Status.StoredVersions)
+
+	assert.Equal(t, migrations.EnvironmentsCRD, results[1].CRD)
+	assert.Equal(t, 0, results[1].FromVersion)
+	assert.Equal(t, 2, results[1].ToVersion)
+	assert.Equal(t, 2, results[1].Updated)
+	envCRD, err := apiClient.ApiextensionsV1beta1().CustomResourceDefinitions().Get(migrations.EnvironmentsCRD, metav1.GetOptions{})
+	require.NoError(t, err)
+	version, err := migrations.SchemaVersion(envCRD)
+	require.NoError(t, err)
+	assert.Equal(t, 2, version)
+
+	dev, err := jxClient.JenkinsV1().Environments(ns).Get("dev", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "2", dev.Labels["migrated"])
+
+	results, err = migrator.Run()
+	require.NoError(t, err)
+	assert.Empty(t, results)
+}
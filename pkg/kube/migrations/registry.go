@@ -0,0 +1,20 @@
+package migrations
+
+import (
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func init() {
+	Register(Migration{
+		CRD:         EnvironmentsCRD,
+		Version:     1,
+		Description: "store the deprecated gitPrivate team setting of the development environment as gitPublic",
+		Migrate: func(obj runtime.Object) (bool, error) {
+			// the team settings convert gitPrivate to gitPublic when they are read so rewriting the development
+			// environment stores them as gitPublic
+			env, ok := obj.(*v1.Environment)
+			return ok && env.Spec.Kind == v1.EnvironmentKindTypeDevelopment, nil
+		},
+	})
+}
@@ -0,0 +1,101 @@
+package migrations
+
+import (
+	"fmt"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/client/clientset/versioned"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	// EnvironmentsCRD the name of the Environment CustomResourceDefinition
+	EnvironmentsCRD = "environments.jenkins.io"
+	// PipelineActivitiesCRD the name of the PipelineActivity CustomResourceDefinition
+	PipelineActivitiesCRD = "pipelineactivities.jenkins.io"
+	// AppsCRD the name of the App CustomResourceDefinition
+	AppsCRD = "apps.jenkins.io"
+)
+
+// store lists and updates the stored objects of a CustomResourceDefinition
+type store interface {
+	list(jxClient versioned.Interface, ns string) ([]runtime.Object, error)
+	update(jxClient versioned.Interface, obj runtime.Object) error
+}
+
+// stores the jx resources which can be migrated by the name of their CustomResourceDefinition
+var stores = map[string]store{
+	EnvironmentsCRD:       environmentStore{},
+	PipelineActivitiesCRD: pipelineActivityStore{},
+	AppsCRD:               appStore{},
+}
+
+type environmentStore struct{}
+
+func (environmentStore) list(jxClient versioned.Interface, ns string) ([]runtime.Object, error) {
+	list, err := jxClient.JenkinsV1().Environments(ns).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	answer := []runtime.Object{}
+	for i := range list.Items {
+		answer = append(answer, &list.Items[i])
+	}
+	return answer, nil
+}
+
+func (environmentStore) update(jxClient versioned.Interface, obj runtime.Object) error {
+	env, ok := obj.(*v1.Environment)
+	if !ok {
+		return fmt.Errorf("expected an Environment but got %T", obj)
+	}
+	_, err := jxClient.JenkinsV1().Environments(env.Namespace).Update(env)
+	return err
+}
+
+type pipelineActivityStore struct{}
+
+func (pipelineActivityStore) list(jxClient versioned.Interface, ns string) ([]runtime.Object, error) {
+	list, err := jxClient.JenkinsV1().PipelineActivities(ns).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	answer := []runtime.Object{}
+	for i := range list.Items {
+		answer = append(answer, &list.Items[i])
+	}
+	return answer, nil
+}
+
+func (pipelineActivityStore) update(jxClient versioned.Interface, obj runtime.Object) error {
+	activity, ok := obj.(*v1.PipelineActivity)
+	if !ok {
+		return fmt.Errorf("expected a PipelineActivity but got %T", obj)
+	}
+	_, err := jxClient.JenkinsV1().PipelineActivities(activity.Namespace).Update(activity)
+	return err
+}
+
+type appStore struct{}
+
+func (appStore) list(jxClient versioned.Interface, ns string) ([]runtime.Object, error) {
+	list, err := jxClient.JenkinsV1().Apps(ns).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	answer := []runtime.Object{}
+	for i := range list.Items {
+		answer = append(answer, &list.Items[i])
+	}
+	return answer, nil
+}
+
+func (appStore) update(jxClient versioned.Interface, obj runtime.Object) error {
+	app, ok := obj.(*v1.App)
+	if !ok {
+		return fmt.Errorf("expected an App but got %T", obj)
+	}
+	_, err := jxClient.JenkinsV1().Apps(app.Namespace).Update(app)
+	return err
+}
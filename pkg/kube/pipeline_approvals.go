@@ -0,0 +1,129 @@
+package kube
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/tekton/syntax"
+	"github.com/pkg/errors"
+)
+
+const (
+	// ApprovalAnnotationPrefix the prefix of the annotations on a PipelineActivity holding its approvals by name
+	ApprovalAnnotationPrefix = "approval.jenkins.io/"
+
+	// ApprovalStatePending the pipeline is waiting for the approval
+	ApprovalStatePending = "Pending"
+	// ApprovalStateApproved the approval was approved so the pipeline continues
+	ApprovalStateApproved = "Approved"
+	// ApprovalStateRejected the approval was rejected so the pipeline fails
+	ApprovalStateRejected = "Rejected"
+	// ApprovalStateTimedOut the approval was not decided before its deadline
+	ApprovalStateTimedOut = "TimedOut"
+)
+
+// PipelineApproval an approval step of a pipeline
+type PipelineApproval struct {
+	Name      string    `json:"name"`
+	Message   string    `json:"message,omitempty"`
+	Approvers []string  `json:"approvers,omitempty"`
+	State     string    `json:"state"`
+	User      string    `json:"user,omitempty"`
+	Requested time.Time `json:"requested"`
+	Deadline  time.Time `json:"deadline"`
+}
+
+// IsPending returns true if the pipeline is waiting for the approval
+func (a *PipelineApproval) IsPending() bool {
+	return a.State == ApprovalStatePending
+}
+
+// GetPipelineApprovals returns the approvals of the activity sorted by name
+func GetPipelineApprovals(activity *v1.PipelineActivity) []*PipelineApproval {
+	answer := []*PipelineApproval{}
+	for k, v := range activity.Annotations {
+		if !strings.HasPrefix(k, ApprovalAnnotationPrefix) {
+			continue
+		}
+		approval := &PipelineApproval{}
+		err := json.Unmarshal([]byte(v), approval)
+		if err != nil {
+			log.Logger().Warnf("Ignoring the invalid annotation %s on PipelineActivity %s: %s", k, activity.Name, err)
+			continue
+		}
+		answer = append(answer, approval)
+	}
+	sort.Slice(answer, func(i, j int) bool {
+		return answer[i].Name < answer[j].Name
+	})
+	return answer
+}
+
+// GetPipelineApproval returns the approval of the activity with the given name or nil if there is none
+func GetPipelineApproval(activity *v1.PipelineActivity, name string) *PipelineApproval {
+	for _, approval := range GetPipelineApprovals(activity) {
+		if approval.Name == name {
+			return approval
+		}
+	}
+	return nil
+}
+
+// SetPipelineApproval stores the approval on the activity
+func SetPipelineApproval(activity *v1.PipelineActivity, approval *PipelineApproval) error {
+	data, err := json.Marshal(approval)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal the approval %s", approval.Name)
+	}
+	if activity.Annotations == nil {
+		activity.Annotations = map[string]string{}
+	}
+	activity.Annotations[ApprovalAnnotationPrefix+approval.Name] = string(data)
+	return nil
+}
+
+// IsApprover returns true if the user is one of the approvers. The approver "OWNERS" matches the approvers in the
+// OWNERS file of the repository and "team" matches the users of the team. Any user can approve if there are no
+// approvers
+func IsApprover(user string, approvers []string, owners []string, teamUsers []string) bool {
+	if len(approvers) == 0 {
+		return user != ""
+	}
+	for _, approver := range approvers {
+		var candidates []string
+		switch approver {
+		case syntax.ApproversOwners:
+			candidates = owners
+		case syntax.ApproversTeam:
+			candidates = teamUsers
+		default:
+			candidates = []string{approver}
+		}
+		for _, candidate := range candidates {
+			if strings.EqualFold(candidate, user) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// TeamUserLogins returns the logins and account IDs of the users of the team
+func TeamUserLogins(users []v1.User) []string {
+	answer := []string{}
+	for _, user := range users {
+		if user.Spec.Login != "" {
+			answer = append(answer, user.Spec.Login)
+		}
+		for _, account := range user.Spec.Accounts {
+			if account.ID != "" {
+				answer = append(answer, account.ID)
+			}
+		}
+	}
+	return answer
+}
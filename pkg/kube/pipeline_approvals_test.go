@@ -0,0 +1,58 @@
+package kube_test
+
+import (
+	"testing"
+	"time"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/kube"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPipelineApprovals(t *testing.T) {
+	t.Parallel()
+	activity := &v1.PipelineActivity{ObjectMeta: metav1.ObjectMeta{Name: "myorg-myapp-master-1"}}
+	assert.Nil(t, kube.GetPipelineApproval(activity, "production"))
+
+	requested := time.Date(2019, 11, 4, 10, 0, 0, 0, time.UTC)
+	for _, name := range []string{"staging", "production"} {
+		err := kube.SetPipelineApproval(activity, &kube.PipelineApproval{
+			Name:      name,
+			State:     kube.ApprovalStatePending,
+			Requested: requested,
+			Deadline:  requested.Add(time.Hour),
+		})
+		require.NoError(t, err)
+	}
+	approvals := kube.GetPipelineApprovals(activity)
+	require.Len(t, approvals, 2)
+	assert.Equal(t, "production", approvals[0].Name)
+	assert.True(t, approvals[0].IsPending())
+	assert.Equal(t, requested.Add(time.Hour), approvals[0].Deadline)
+
+	approval := kube.GetPipelineApproval(activity, "staging")
+	require.NotNil(t, approval)
+	approval.State = kube.ApprovalStateApproved
+	approval.User = "jstrachan"
+	require.NoError(t, kube.SetPipelineApproval(activity, approval))
+	assert.Equal(t, kube.ApprovalStateApproved, kube.GetPipelineApproval(activity, "staging").State)
+}
+
+func TestIsApprover(t *testing.T) {
+	t.Parallel()
+	owners := []string{"jstrachan", "rawlingsj"}
+	teamUsers := kube.TeamUserLogins([]v1.User{
+		{Spec: v1.UserDetails{Login: "pmuir"}},
+		{Spec: v1.UserDetails{Accounts: []v1.AccountReference{{Provider: "github", ID: "abayer"}}}},
+	})
+
+	assert.True(t, kube.IsApprover("anyone", nil, owners, teamUsers))
+	assert.False(t, kube.IsApprover("", nil, owners, teamUsers))
+	assert.True(t, kube.IsApprover("JStrachan", []string{"OWNERS"}, owners, teamUsers))
+	assert.False(t, kube.IsApprover("pmuir", []string{"OWNERS"}, owners, teamUsers))
+	assert.True(t, kube.IsApprover("abayer", []string{"OWNERS", "team"}, owners, teamUsers))
+	assert.True(t, kube.IsApprover("cosmin", []string{"cosmin"}, owners, teamUsers))
+	assert.False(t, kube.IsApprover("cosmin", []string{"team"}, owners, teamUsers))
+}
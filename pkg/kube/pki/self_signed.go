@@ -0,0 +1,93 @@
+package pki
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"time"
+
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// SelfSignedCertificateValidity how long self signed certificates are valid for
+const SelfSignedCertificateValidity = 365 * 24 * time.Hour
+
+// GenerateSelfSignedCertificate generates a self signed certificate for the domain and its sub domains. Returns the
+// PEM encoded certificate and private key
+func GenerateSelfSignedCertificate(domain string, validity time.Duration) ([]byte, []byte, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "generating the private key")
+	}
+	serialNumber, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "generating the serial number")
+	}
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber: serialNumber,
+		Subject: pkix.Name{
+			CommonName:   domain,
+			Organization: []string{"Jenkins X"},
+		},
+		DNSNames:              []string{domain, "*." + domain},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(validity),
+		KeyUsage:              x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "creating the certificate for domain %s", domain)
+	}
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	return certPEM, keyPEM, nil
+}
+
+// EnsureSelfSignedTLSSecret creates a TLS secret with a self signed certificate for the domain if the secret does not
+// exist. Returns true if the secret was created
+func EnsureSelfSignedTLSSecret(client kubernetes.Interface, ns string, name string, domain string) (bool, error) {
+	_, err := client.CoreV1().Secrets(ns).Get(name, metav1.GetOptions{})
+	if err == nil {
+		return false, nil
+	}
+	if !apierrors.IsNotFound(err) {
+		return false, errors.Wrapf(err, "getting secret %s in namespace %s", name, ns)
+	}
+	certPEM, keyPEM, err := GenerateSelfSignedCertificate(domain, SelfSignedCertificateValidity)
+	if err != nil {
+		return false, err
+	}
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: ns,
+			Labels: map[string]string{
+				"jenkins.io/self-signed": "true",
+			},
+		},
+		Type: v1.SecretTypeTLS,
+		Data: map[string][]byte{
+			v1.TLSCertKey:       certPEM,
+			v1.TLSPrivateKeyKey: keyPEM,
+		},
+	}
+	_, err = client.CoreV1().Secrets(ns).Create(secret)
+	if err != nil {
+		return false, errors.Wrapf(err, "creating secret %s in namespace %s", name, ns)
+	}
+	log.Logger().Infof("Created the self signed TLS secret %s for domain %s in namespace %s", util.ColorInfo(name), util.ColorInfo(domain), util.ColorInfo(ns))
+	return true, nil
+}
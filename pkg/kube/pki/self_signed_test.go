@@ -0,0 +1,37 @@
+package pki_test
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+
+	"github.com/jenkins-x/jx/pkg/kube/pki"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestEnsureSelfSignedTLSSecret(t *testing.T) {
+	client := fake.NewSimpleClientset()
+
+	created, err := pki.EnsureSelfSignedTLSSecret(client, "jx", "tls-local", "127.0.0.1.nip.io")
+	require.NoError(t, err)
+	assert.True(t, created)
+
+	secret, err := client.CoreV1().Secrets("jx").Get("tls-local", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, v1.SecretTypeTLS, secret.Type)
+	assert.NotEmpty(t, secret.Data[v1.TLSPrivateKeyKey])
+
+	block, _ := pem.Decode(secret.Data[v1.TLSCertKey])
+	require.NotNil(t, block)
+	cert, err := x509.ParseCertificate(block.Bytes)
+	require.NoError(t, err)
+	assert.NoError(t, cert.VerifyHostname("jenkins-jx.127.0.0.1.nip.io"))
+
+	created, err = pki.EnsureSelfSignedTLSSecret(client, "jx", "tls-local", "127.0.0.1.nip.io")
+	require.NoError(t, err)
+	assert.False(t, created, "should not recreate an existing secret")
+}
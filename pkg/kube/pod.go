@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/jenkins-x/jx/pkg/kube/naming"
+	"github.com/pkg/errors"
 	v1 "k8s.io/api/core/v1"
 	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/fields"
@@ -241,6 +242,45 @@ func GetDevPodNames(client kubernetes.Interface, ns string, username string) ([]
 	return names, m, nil
 }
 
+// UpdateDevPodLastActivity records that the DevPod is being used so that it is not deleted as idle
+func UpdateDevPodLastActivity(client kubernetes.Interface, ns string, name string) error {
+	pod, err := client.CoreV1().Pods(ns).Get(name, meta_v1.GetOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "getting DevPod %s in namespace %s", name, ns)
+	}
+	if pod.Annotations == nil {
+		pod.Annotations = map[string]string{}
+	}
+	pod.Annotations[AnnotationDevPodLastActivity] = time.Now().UTC().Format(time.RFC3339)
+	_, err = client.CoreV1().Pods(ns).Update(pod)
+	if err != nil {
+		return errors.Wrapf(err, "updating the last activity of DevPod %s in namespace %s", name, ns)
+	}
+	return nil
+}
+
+// DevPodIdleTime returns how long the DevPod has been idle based on its last recorded activity or its creation time,
+// and its idle timeout. A zero timeout means the DevPod is never deleted as idle
+func DevPodIdleTime(pod *v1.Pod, now time.Time) (time.Duration, time.Duration) {
+	lastActivity := pod.CreationTimestamp.Time
+	timeout := time.Duration(0)
+	if pod.Annotations != nil {
+		if text := pod.Annotations[AnnotationDevPodLastActivity]; text != "" {
+			t, err := time.Parse(time.RFC3339, text)
+			if err == nil && t.After(lastActivity) {
+				lastActivity = t
+			}
+		}
+		if text := pod.Annotations[AnnotationDevPodIdleTimeout]; text != "" {
+			d, err := time.ParseDuration(text)
+			if err == nil {
+				timeout = d
+			}
+		}
+	}
+	return now.Sub(lastActivity), timeout
+}
+
 // GetPodRestars returns the number of restarts of a POD
 func GetPodRestarts(pod *v1.Pod) int32 {
 	var restarts int32
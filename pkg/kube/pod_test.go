@@ -2,6 +2,7 @@ package kube_test
 
 import (
 	"testing"
+	"time"
 
 	"github.com/jenkins-x/jx/pkg/kube"
 	"github.com/stretchr/testify/assert"
@@ -252,3 +253,25 @@ func TestIsPodReadyFailures(t *testing.T) {
 	res = kube.IsPodReady(pod)
 	assert.Equal(t, false, res)
 }
+
+func TestDevPodIdleTime(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2019, 10, 1, 12, 0, 0, 0, time.UTC)
+	pod := &v1.Pod{
+		ObjectMeta: meta_v1.ObjectMeta{
+			CreationTimestamp: meta_v1.NewTime(now.Add(-5 * time.Hour)),
+		},
+	}
+	idle, timeout := kube.DevPodIdleTime(pod, now)
+	assert.Equal(t, 5*time.Hour, idle)
+	assert.Equal(t, time.Duration(0), timeout)
+
+	pod.Annotations = map[string]string{
+		kube.AnnotationDevPodIdleTimeout:  "2h0m0s",
+		kube.AnnotationDevPodLastActivity: now.Add(-30 * time.Minute).Format(time.RFC3339),
+	}
+	idle, timeout = kube.DevPodIdleTime(pod, now)
+	assert.Equal(t, 30*time.Minute, idle)
+	assert.Equal(t, 2*time.Hour, timeout)
+}
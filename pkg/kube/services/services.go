@@ -433,6 +433,21 @@ func ServiceAppName(service *v1.Service) string {
 	return service.GetName()
 }
 
+// RequiresBasicAuth returns true if the ingress exposed for the service by exposecontroller requires basic authentication
+func RequiresBasicAuth(service *v1.Service) bool {
+	if service == nil || service.Annotations == nil {
+		return false
+	}
+	for _, line := range strings.Split(service.Annotations[ExposeIngressAnnotation], "\n") {
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) == 2 && strings.HasSuffix(strings.TrimSpace(parts[0]), "/auth-type") &&
+			strings.Trim(strings.TrimSpace(parts[1]), `"'`) == "basic" {
+			return true
+		}
+	}
+	return false
+}
+
 // AnnotateServicesWithCertManagerIssuer adds the cert-manager annotation to the services from the given namespace. If a list of
 // services is provided, it will apply the annotation only to that specific services.
 func AnnotateServicesWithCertManagerIssuer(c kubernetes.Interface, ns, issuer string, clusterIssuer bool, services ...string) ([]*v1.Service, error) {
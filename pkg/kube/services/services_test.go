@@ -286,3 +286,20 @@ func TestExtractServiceSchemePortInconclusive(t *testing.T) {
 	assert.Equal(t, "", schema)
 	assert.Equal(t, "", port)
 }
+
+func TestRequiresBasicAuth(t *testing.T) {
+	t.Parallel()
+	s := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "jenkins-x-chartmuseum",
+			Annotations: map[string]string{
+				services.ExposeIngressAnnotation: "nginx.ingress.kubernetes.io/auth-type: basic\nnginx.ingress.kubernetes.io/auth-secret: jx-basic-auth",
+			},
+		},
+	}
+	assert.True(t, services.RequiresBasicAuth(s))
+
+	s.Annotations[services.ExposeIngressAnnotation] = "kubernetes.io/ingress.class: nginx"
+	assert.False(t, services.RequiresBasicAuth(s))
+	assert.False(t, services.RequiresBasicAuth(&v1.Service{}))
+}
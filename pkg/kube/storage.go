@@ -14,12 +14,24 @@ const (
 
 	// ClassificationReports stores test results, coverage & quality reports
 	ClassificationReports = "reports"
+
+	// ClassificationAudit stores the audit events of the changes made by jx
+	ClassificationAudit = "audit"
+
+	// ClassificationActivities stores the archived PipelineActivities pruned by the garbage collector
+	ClassificationActivities = "activities"
+
+	// ClassificationPreviews stores the screenshots of preview environments
+	ClassificationPreviews = "previews"
+
+	// ClassificationCompliance stores the reports of the compliance tests
+	ClassificationCompliance = "compliance"
 )
 
 var (
 	// Classifications the common classification names
 	Classifications = []string{
-		ClassificationCoverage, ClassificationTests, ClassificationLogs, ClassificationReports,
+		ClassificationCoverage, ClassificationTests, ClassificationLogs, ClassificationReports, ClassificationAudit, ClassificationActivities, ClassificationPreviews, ClassificationCompliance,
 	}
 
 	// ClassificationValues the classification values as a string
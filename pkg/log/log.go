@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"sort"
 	"strings"
 
 	"github.com/rickar/props"
@@ -31,6 +32,26 @@ var (
 	logger *logrus.Entry
 
 	labelsPath = "/etc/labels"
+
+	// defaultLevel the level used for packages without an override
+	defaultLevel = logrus.InfoLevel
+
+	// packageLevels the per package level overrides
+	packageLevels []packageLevel
+
+	// format the layout of the log statements
+	format = FormatLayoutText
+)
+
+// packageLevel the log level of all the packages whose import path starts with the prefix
+type packageLevel struct {
+	prefix string
+	level  logrus.Level
+}
+
+const (
+	// jxPackagePrefix the prefix of the jx packages which can be omitted from package level overrides
+	jxPackagePrefix = "github.com/jenkins-x/jx/pkg/"
 )
 
 // FormatLayoutType the layout kind
@@ -48,7 +69,7 @@ func initializeLogger() error {
 	if logger == nil {
 
 		// if we are inside a pod, record some useful info
-		var fields logrus.Fields
+		fields := logrus.Fields{}
 		if exists, err := fileExists(labelsPath); err != nil {
 			return errors.Wrapf(err, "checking if %s exists", labelsPath)
 		} else if exists {
@@ -71,11 +92,17 @@ func initializeLogger() error {
 		}
 		logger = logrus.WithFields(fields)
 
-		format := os.Getenv("JX_LOG_FORMAT")
-		if format == "json" {
-			setFormatter(FormatLayoutJSON)
-		} else {
-			setFormatter(FormatLayoutText)
+		if os.Getenv("JX_LOG_FORMAT") == string(FormatLayoutJSON) {
+			format = FormatLayoutJSON
+		}
+		setFormatter(format)
+
+		levels := os.Getenv("JX_LOG_PACKAGE_LEVELS")
+		if levels != "" {
+			err := SetPackageLevels(levels)
+			if err != nil {
+				return errors.Wrap(err, "parsing $JX_LOG_PACKAGE_LEVELS")
+			}
 		}
 	}
 	return nil
@@ -98,13 +125,95 @@ func SetLevel(s string) error {
 		return errors.Errorf("Invalid log level '%s'", s)
 	}
 	Logger().Debugf("logging set to level: %s", level)
-	logrus.SetLevel(level)
+	defaultLevel = level
+	applyLevels()
 	return nil
 }
 
 // GetLevel gets the current log level
 func GetLevel() string {
-	return logrus.GetLevel().String()
+	return defaultLevel.String()
+}
+
+// SetPackageLevels overrides the log level of packages using a comma separated list of package=level pairs such as
+// 'gits=debug,kube=warn'. Packages are matched by the prefix of their import path and the
+// github.com/jenkins-x/jx/pkg/ prefix of the jx packages can be omitted
+func SetPackageLevels(s string) error {
+	overrides := []packageLevel{}
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return errors.Errorf("Invalid package log level '%s'. Expected package=level", pair)
+		}
+		level, err := logrus.ParseLevel(parts[1])
+		if err != nil {
+			return errors.Errorf("Invalid log level '%s' for package %s", parts[1], parts[0])
+		}
+		prefix := parts[0]
+		if !strings.Contains(prefix, ".") {
+			prefix = jxPackagePrefix + prefix
+		}
+		overrides = append(overrides, packageLevel{prefix: prefix, level: level})
+	}
+	// match the most specific packages first
+	sort.SliceStable(overrides, func(i, j int) bool {
+		return len(overrides[i].prefix) > len(overrides[j].prefix)
+	})
+	packageLevels = overrides
+	applyLevels()
+	return nil
+}
+
+// SetFormat sets the layout of the log statements to either 'text' or 'json'
+func SetFormat(s string) error {
+	switch FormatLayoutType(s) {
+	case FormatLayoutJSON, FormatLayoutText:
+		format = FormatLayoutType(s)
+	default:
+		return errors.Errorf("Invalid log format '%s'. Expected one of %s", s, strings.Join(GetFormats(), ", "))
+	}
+	setFormatter(format)
+	return nil
+}
+
+// GetFormats returns the list of valid log formats
+func GetFormats() []string {
+	return []string{string(FormatLayoutText), string(FormatLayoutJSON)}
+}
+
+// AddFields adds contextual fields such as the command, repository or namespace to all subsequent log statements
+func AddFields(fields map[string]interface{}) {
+	logger = Logger().WithFields(logrus.Fields(fields))
+}
+
+// applyLevels enables the most verbose of the default and package levels on logrus so that the package level
+// filtering can drop the statements which are not enabled for the package
+func applyLevels() {
+	level := defaultLevel
+	for _, pl := range packageLevels {
+		if pl.level > level {
+			level = pl.level
+		}
+	}
+	logrus.SetLevel(level)
+	logrus.SetReportCaller(len(packageLevels) > 0)
+}
+
+// levelFor returns the log level of the function with the given fully qualified name
+func levelFor(function string) logrus.Level {
+	for _, pl := range packageLevels {
+		if strings.HasPrefix(function, pl.prefix) {
+			rest := function[len(pl.prefix):]
+			if rest == "" || rest[0] == '.' || rest[0] == '/' {
+				return pl.level
+			}
+		}
+	}
+	return defaultLevel
 }
 
 // GetLevels returns the list of valid log levels
@@ -120,10 +229,23 @@ func GetLevels() []string {
 func setFormatter(layout FormatLayoutType) {
 	switch layout {
 	case FormatLayoutJSON:
-		logrus.SetFormatter(&logrus.JSONFormatter{})
+		logrus.SetFormatter(&packageLevelFormatter{formatter: &logrus.JSONFormatter{}})
 	default:
-		logrus.SetFormatter(NewJenkinsXTextFormat())
+		logrus.SetFormatter(&packageLevelFormatter{formatter: NewJenkinsXTextFormat()})
+	}
+}
+
+// packageLevelFormatter drops the log statements which are not enabled for the package which logged them
+type packageLevelFormatter struct {
+	formatter logrus.Formatter
+}
+
+// Format formats the log statement if it is enabled for its package
+func (f *packageLevelFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	if len(packageLevels) > 0 && entry.HasCaller() && entry.Level > levelFor(entry.Caller.Function) {
+		return nil, nil
 	}
+	return f.formatter.Format(entry)
 }
 
 // JenkinsXTextFormat lets use a custom text format
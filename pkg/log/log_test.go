@@ -1,6 +1,7 @@
 package log
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -25,3 +26,55 @@ func Test_setting_unknown_log_level_returns_error(t *testing.T) {
 	assert.Error(t, err)
 	assert.Equal(t, "Invalid log level 'foo'", err.Error())
 }
+
+func Test_package_levels_override_the_default_level(t *testing.T) {
+	defer func() {
+		assert.NoError(t, SetPackageLevels(""))
+		assert.NoError(t, SetLevel("info"))
+	}()
+	err := SetLevel("info")
+	assert.NoError(t, err)
+
+	err = SetPackageLevels("log=debug")
+	assert.NoError(t, err)
+	out := CaptureOutput(func() { Logger().Debug("hello") })
+	assert.Equal(t, "DEBUG: hello\n", out)
+
+	err = SetPackageLevels("gits=debug,log=warn")
+	assert.NoError(t, err)
+	out = CaptureOutput(func() { Logger().Info("hello") })
+	assert.Empty(t, out)
+	out = CaptureOutput(func() { Logger().Warn("hello") })
+	assert.Equal(t, "WARNING: hello\n", out)
+}
+
+func Test_setting_invalid_package_level_returns_error(t *testing.T) {
+	err := SetPackageLevels("gits")
+	assert.Error(t, err)
+
+	err = SetPackageLevels("gits=foo")
+	assert.Error(t, err)
+	assert.Equal(t, "Invalid log level 'foo' for package gits", err.Error())
+}
+
+func Test_json_format_includes_contextual_fields(t *testing.T) {
+	defer func() {
+		logger = nil
+		assert.NoError(t, SetFormat("text"))
+	}()
+	err := SetFormat("json")
+	assert.NoError(t, err)
+	AddFields(map[string]interface{}{"command": "jx step helm apply", "namespace": "jx"})
+
+	out := CaptureOutput(func() { Logger().Info("hello") })
+	entry := map[string]interface{}{}
+	err = json.Unmarshal([]byte(out), &entry)
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", entry["msg"])
+	assert.Equal(t, "info", entry["level"])
+	assert.Equal(t, "jx step helm apply", entry["command"])
+	assert.Equal(t, "jx", entry["namespace"])
+
+	err = SetFormat("xml")
+	assert.Error(t, err)
+}
@@ -0,0 +1,279 @@
+package mergequeue
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/client/clientset/versioned"
+	"github.com/jenkins-x/jx/pkg/kube"
+	"github.com/jenkins-x/jx/pkg/pipelinescheduler"
+	"github.com/pkg/errors"
+)
+
+const (
+	// BatchBranch the branch name of the pipelines which test a batch of pull requests before they are merged
+	BatchBranch = "batch"
+
+	// ActionMergeBatch the action of the merge controller when a batch passed its tests and is being merged
+	ActionMergeBatch = "MERGE_BATCH"
+	// ActionTriggerBatch the action of the merge controller when it starts testing a batch
+	ActionTriggerBatch = "TRIGGER_BATCH"
+)
+
+// ServiceNames the names of the services of the merge controllers which serve the merge pools
+var ServiceNames = []string{"tide", "lighthouse-keeper"}
+
+// PullRequest a pull request of a merge pool
+type PullRequest struct {
+	Number     int    `json:"Number"`
+	Title      string `json:"Title,omitempty"`
+	HeadRefOID string `json:"HeadRefOID,omitempty"`
+}
+
+// Pool the pull requests of a branch of a repository which are approved and waiting to be merged
+type Pool struct {
+	Org          string        `json:"Org"`
+	Repo         string        `json:"Repo"`
+	Branch       string        `json:"Branch"`
+	SuccessPRs   []PullRequest `json:"SuccessPRs,omitempty"`
+	PendingPRs   []PullRequest `json:"PendingPRs,omitempty"`
+	MissingPRs   []PullRequest `json:"MissingPRs,omitempty"`
+	BatchPending []PullRequest `json:"BatchPending,omitempty"`
+	Action       string        `json:"Action,omitempty"`
+	Target       []PullRequest `json:"Target,omitempty"`
+	Error        string        `json:"Error,omitempty"`
+}
+
+// pools the merge pools served by the merge controllers
+type pools struct {
+	Pools []Pool `json:"Pools"`
+}
+
+// Entry the merge queue of a branch of a repository
+type Entry struct {
+	Owner       string                `json:"owner"`
+	Repository  string                `json:"repository"`
+	Branch      string                `json:"branch"`
+	Action      string                `json:"action,omitempty"`
+	Batch       []int                 `json:"batch,omitempty"`
+	BatchBuild  string                `json:"batchBuild,omitempty"`
+	BatchStatus v1.ActivityStatusType `json:"batchStatus,omitempty"`
+	Queued      []int                 `json:"queued,omitempty"`
+	Error       string                `json:"error,omitempty"`
+}
+
+// Position returns the position of the pull request in the queue starting at 1 or 0 if it is not queued. The pull
+// requests being tested in the current batch are at the front of the queue
+func (e *Entry) Position(number int) int {
+	for i, n := range append(append([]int{}, e.Batch...), e.Queued...) {
+		if n == number {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// LoadConfig returns the merge queue configuration of the repository from its effective scheduler or nil if it has
+// none
+func LoadConfig(jxClient versioned.Interface, ns string, owner string, repository string) (*v1.MergeQueue, error) {
+	devEnv, err := kube.GetDevEnvironment(jxClient, ns)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to find the dev environment in namespace %s", ns)
+	}
+	teamSchedulerName := ""
+	if devEnv != nil {
+		teamSchedulerName = devEnv.Spec.TeamSettings.DefaultScheduler.Name
+	}
+	spec, err := pipelinescheduler.LoadRepositorySchedulerSpec(jxClient, ns, teamSchedulerName, owner, repository)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to load the scheduler of repository %s/%s", owner, repository)
+	}
+	if spec == nil || spec.Merger == nil {
+		return nil, nil
+	}
+	return spec.Merger.MergeQueue, nil
+}
+
+// BatchSizeLimit returns the maximum number of pull requests tested together or 0 if there is no limit
+func BatchSizeLimit(queue *v1.MergeQueue) int {
+	if queue == nil || queue.BatchSizeLimit == nil || *queue.BatchSizeLimit < 0 {
+		return 0
+	}
+	return *queue.BatchSizeLimit
+}
+
+// CommentsEnabled returns true if the pull requests of a batch are commented on when its tests start and complete
+func CommentsEnabled(queue *v1.MergeQueue) bool {
+	return queue == nil || queue.Comment == nil || *queue.Comment
+}
+
+// VerifyBatch returns an error if the batch of pull requests is not allowed by the merge queue of the repository
+func VerifyBatch(queue *v1.MergeQueue, owner string, repository string, size int) error {
+	limit := BatchSizeLimit(queue)
+	switch {
+	case limit == 1 && size > 1:
+		return fmt.Errorf("batching is disabled by the merge queue of repository %s/%s", owner, repository)
+	case limit > 0 && size > limit:
+		return fmt.Errorf("the batch of %d pull requests exceeds the limit of %d of the merge queue of repository %s/%s",
+			size, limit, owner, repository)
+	}
+	return nil
+}
+
+// LoadPools reads the merge pools from the URL of a merge controller
+func LoadPools(client *http.Client, u string) ([]Pool, error) {
+	if client == nil {
+		client = &http.Client{}
+	}
+	resp, err := client.Get(u)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get the merge pools from %s", u)
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read the merge pools from %s", u)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to get the merge pools from %s: status %d", u, resp.StatusCode)
+	}
+	answer := pools{}
+	err = json.Unmarshal(data, &answer)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse the merge pools from %s", u)
+	}
+	return answer.Pools, nil
+}
+
+// BuildQueues creates the merge queues of the pools using the latest batch pipelines of the repositories for the status
+// of their batches. The pull requests passing their tests are queued before the pending ones
+func BuildQueues(pools []Pool, activities []v1.PipelineActivity) []Entry {
+	answer := []Entry{}
+	for _, pool := range pools {
+		entry := Entry{
+			Owner:      pool.Org,
+			Repository: pool.Repo,
+			Branch:     pool.Branch,
+			Action:     pool.Action,
+			Error:      pool.Error,
+			Batch:      numbers(pool.BatchPending),
+		}
+		if len(entry.Batch) == 0 && (pool.Action == ActionTriggerBatch || pool.Action == ActionMergeBatch) {
+			entry.Batch = numbers(pool.Target)
+		}
+		activity := LatestBatch(activities, pool.Org, pool.Repo)
+		if activity != nil {
+			entry.BatchBuild = activity.Spec.Build
+			entry.BatchStatus = activity.Spec.Status
+			if len(entry.Batch) == 0 && !activity.Spec.Status.IsTerminated() {
+				entry.Batch = PullRequestNumbers(activity)
+			}
+		}
+		for _, n := range append(numbers(pool.SuccessPRs), numbers(pool.PendingPRs)...) {
+			if !containsNumber(entry.Batch, n) {
+				entry.Queued = append(entry.Queued, n)
+			}
+		}
+		answer = append(answer, entry)
+	}
+	sort.Slice(answer, func(i, j int) bool {
+		a := answer[i]
+		b := answer[j]
+		if a.Owner != b.Owner {
+			return a.Owner < b.Owner
+		}
+		if a.Repository != b.Repository {
+			return a.Repository < b.Repository
+		}
+		return a.Branch < b.Branch
+	})
+	return answer
+}
+
+// LatestBatch returns the batch pipeline of the repository with the highest build number or nil if there is none
+func LatestBatch(activities []v1.PipelineActivity, owner string, repository string) *v1.PipelineActivity {
+	var answer *v1.PipelineActivity
+	latest := 0
+	for i := range activities {
+		activity := &activities[i]
+		if !IsBatch(activity) || activity.Spec.GitOwner != owner || activity.Spec.GitRepository != repository {
+			continue
+		}
+		build, err := strconv.Atoi(activity.Spec.Build)
+		if err != nil {
+			continue
+		}
+		if answer == nil || build > latest {
+			answer = activity
+			latest = build
+		}
+	}
+	return answer
+}
+
+// IsBatch returns true if the pipeline tests a batch of pull requests
+func IsBatch(activity *v1.PipelineActivity) bool {
+	if activity.Labels != nil && activity.Labels[v1.LabelBranch] == BatchBranch {
+		return true
+	}
+	return strings.EqualFold(activity.Spec.GitBranch, BatchBranch)
+}
+
+// PullRequestNumbers returns the sorted numbers of the pull requests tested by the batch pipeline
+func PullRequestNumbers(activity *v1.PipelineActivity) []int {
+	answer := []int{}
+	for _, pr := range activity.Spec.BatchPipelineActivity.ComprisingPulLRequests {
+		n, err := strconv.Atoi(strings.TrimPrefix(strings.ToUpper(pr.PullRequestNumber), "PR-"))
+		if err == nil {
+			answer = append(answer, n)
+		}
+	}
+	sort.Ints(answer)
+	return answer
+}
+
+// BatchComment returns the comment for the pull requests of the batch pipeline when it reaches the status or an
+// empty string if the status is not commented on
+func BatchComment(activity *v1.PipelineActivity, status v1.ActivityStatusType) string {
+	batch := []string{}
+	for _, n := range PullRequestNumbers(activity) {
+		batch = append(batch, "#"+strconv.Itoa(n))
+	}
+	prs := strings.Join(batch, ", ")
+	build := activity.Spec.Build
+	switch status {
+	case v1.ActivityStatusTypePending, v1.ActivityStatusTypeRunning:
+		return fmt.Sprintf("This pull request is being tested in merge queue batch build #%s together with %s before it is merged.", build, prs)
+	case v1.ActivityStatusTypeSucceeded:
+		return fmt.Sprintf("The merge queue batch build #%s of %s passed so these pull requests will be merged.", build, prs)
+	case v1.ActivityStatusTypeFailed, v1.ActivityStatusTypeError:
+		return fmt.Sprintf("The merge queue batch build #%s of %s failed. These pull requests stay in the merge queue and will be tested again.", build, prs)
+	case v1.ActivityStatusTypeAborted:
+		return fmt.Sprintf("The merge queue batch build #%s of %s was aborted. These pull requests stay in the merge queue.", build, prs)
+	}
+	return ""
+}
+
+func numbers(prs []PullRequest) []int {
+	answer := []int{}
+	for _, pr := range prs {
+		answer = append(answer, pr.Number)
+	}
+	sort.Ints(answer)
+	return answer
+}
+
+func containsNumber(numbers []int, number int) bool {
+	for _, n := range numbers {
+		if n == number {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,127 @@
+package mergequeue_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/mergequeue"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestVerifyBatch(t *testing.T) {
+	t.Parallel()
+	one := 1
+	three := 3
+	assert.NoError(t, mergequeue.VerifyBatch(nil, "jstrachan", "myapp", 10))
+	assert.NoError(t, mergequeue.VerifyBatch(&v1.MergeQueue{BatchSizeLimit: &three}, "jstrachan", "myapp", 3))
+	assert.Error(t, mergequeue.VerifyBatch(&v1.MergeQueue{BatchSizeLimit: &three}, "jstrachan", "myapp", 4))
+	assert.NoError(t, mergequeue.VerifyBatch(&v1.MergeQueue{BatchSizeLimit: &one}, "jstrachan", "myapp", 1))
+	err := mergequeue.VerifyBatch(&v1.MergeQueue{BatchSizeLimit: &one}, "jstrachan", "myapp", 2)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "batching is disabled")
+}
+
+func TestCommentsEnabled(t *testing.T) {
+	t.Parallel()
+	disabled := false
+	assert.True(t, mergequeue.CommentsEnabled(nil))
+	assert.True(t, mergequeue.CommentsEnabled(&v1.MergeQueue{}))
+	assert.False(t, mergequeue.CommentsEnabled(&v1.MergeQueue{Comment: &disabled}))
+}
+
+func TestLoadPoolsAndBuildQueues(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{
+  "Queries": [],
+  "Pools": [
+    {
+      "Org": "jstrachan",
+      "Repo": "myapp",
+      "Branch": "master",
+      "SuccessPRs": [{"Number": 7, "Title": "seven"}, {"Number": 3, "Title": "three"}, {"Number": 5}],
+      "PendingPRs": [{"Number": 9}],
+      "BatchPending": [{"Number": 5}, {"Number": 3}],
+      "Action": "WAIT"
+    }
+  ]
+}`))
+	}))
+	defer server.Close()
+
+	pools, err := mergequeue.LoadPools(nil, server.URL)
+	require.NoError(t, err)
+	require.Len(t, pools, 1)
+	assert.Equal(t, "three", pools[0].SuccessPRs[1].Title)
+
+	activities := []v1.PipelineActivity{
+		batchActivity("1", v1.ActivityStatusTypeFailed, "PR-3"),
+		batchActivity("2", v1.ActivityStatusTypeRunning, "PR-3", "PR-5"),
+	}
+	queues := mergequeue.BuildQueues(pools, activities)
+	require.Len(t, queues, 1)
+	queue := queues[0]
+	assert.Equal(t, []int{3, 5}, queue.Batch)
+	assert.Equal(t, []int{7, 9}, queue.Queued)
+	assert.Equal(t, "2", queue.BatchBuild)
+	assert.Equal(t, v1.ActivityStatusTypeRunning, queue.BatchStatus)
+	assert.Equal(t, 2, queue.Position(5))
+	assert.Equal(t, 4, queue.Position(9))
+	assert.Equal(t, 0, queue.Position(1))
+}
+
+func TestBuildQueuesUsesRunningBatchPipeline(t *testing.T) {
+	t.Parallel()
+	pools := []mergequeue.Pool{
+		{
+			Org:        "jstrachan",
+			Repo:       "myapp",
+			Branch:     "master",
+			SuccessPRs: []mergequeue.PullRequest{{Number: 2}, {Number: 4}},
+		},
+	}
+	activities := []v1.PipelineActivity{
+		batchActivity("3", v1.ActivityStatusTypePending, "PR-4"),
+	}
+	queues := mergequeue.BuildQueues(pools, activities)
+	require.Len(t, queues, 1)
+	assert.Equal(t, []int{4}, queues[0].Batch)
+	assert.Equal(t, []int{2}, queues[0].Queued)
+}
+
+func TestBatchComment(t *testing.T) {
+	t.Parallel()
+	activity := batchActivity("4", v1.ActivityStatusTypeRunning, "PR-12", "PR-10")
+	assert.Equal(t, "This pull request is being tested in merge queue batch build #4 together with #10, #12 before it is merged.",
+		mergequeue.BatchComment(&activity, v1.ActivityStatusTypeRunning))
+	assert.Contains(t, mergequeue.BatchComment(&activity, v1.ActivityStatusTypeFailed), "failed")
+	assert.Equal(t, "", mergequeue.BatchComment(&activity, v1.ActivityStatusTypeNotExecuted))
+}
+
+func batchActivity(build string, status v1.ActivityStatusType, prs ...string) v1.PipelineActivity {
+	infos := []v1.PullRequestInfo{}
+	for _, pr := range prs {
+		infos = append(infos, v1.PullRequestInfo{PullRequestNumber: pr})
+	}
+	return v1.PipelineActivity{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "jstrachan-myapp-batch-" + build,
+			Labels: map[string]string{
+				v1.LabelBranch: mergequeue.BatchBranch,
+			},
+		},
+		Spec: v1.PipelineActivitySpec{
+			GitOwner:      "jstrachan",
+			GitRepository: "myapp",
+			Build:         build,
+			Status:        status,
+			BatchPipelineActivity: v1.BatchPipelineActivity{
+				ComprisingPulLRequests: infos,
+			},
+		},
+	}
+}
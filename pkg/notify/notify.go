@@ -0,0 +1,285 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/client/clientset/versioned"
+	"github.com/jenkins-x/jx/pkg/config"
+	"github.com/jenkins-x/jx/pkg/gits"
+	"github.com/jenkins-x/jx/pkg/kube"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+)
+
+// DefaultTemplates the default Go templates of the messages for each event
+var DefaultTemplates = map[string]string{
+	config.NotificationPipelineFailed:            `Pipeline {{ .Repository }} {{ .Branch }} #{{ .Build }} failed{{ if .URL }}: {{ .URL }}{{ end }}`,
+	config.NotificationReleasePublished:          `Released {{ .Repository }} version {{ .Version }}{{ if .URL }}: {{ .URL }}{{ end }}`,
+	config.NotificationPromotionAwaitingApproval: `Promotion of {{ .Repository }} version {{ .Version }} to {{ .Environment }} is awaiting approval: {{ .PullRequestURL }}`,
+	config.NotificationBootUpgradePullRequest:    `Raised a Pull Request to upgrade the boot configuration of {{ .Repository }}: {{ .PullRequestURL }}`,
+	config.NotificationCostReport:                "{{ .Report }}",
+}
+
+// Event an event to notify
+type Event struct {
+	Kind           string `json:"kind"`
+	Repository     string `json:"repository,omitempty"`
+	Branch         string `json:"branch,omitempty"`
+	Build          string `json:"build,omitempty"`
+	Version        string `json:"version,omitempty"`
+	Environment    string `json:"environment,omitempty"`
+	URL            string `json:"url,omitempty"`
+	PullRequestURL string `json:"pullRequestURL,omitempty"`
+	Author         string `json:"author,omitempty"`
+	Report         string `json:"report,omitempty"`
+}
+
+// Dispatcher sends the events to the channels of the matching routes
+type Dispatcher struct {
+	Config *config.NotificationsConfig
+	Client *http.Client
+}
+
+// Dispatch sends the event to the channels of the routes which match it
+func (d *Dispatcher) Dispatch(event *Event) error {
+	if d.Config == nil {
+		return nil
+	}
+	errs := []error{}
+	sent := map[string]bool{}
+	for _, route := range d.Config.MatchingRoutes(event.Kind, event.Repository, event.Environment) {
+		text, err := d.Message(event, route.Template)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		for _, name := range route.Channels {
+			if sent[name] {
+				continue
+			}
+			sent[name] = true
+			channel := d.Config.Channel(name)
+			if channel == nil {
+				continue
+			}
+			err = d.send(channel, event, text)
+			if err != nil {
+				errs = append(errs, errors.Wrapf(err, "failed to send the %s notification to channel %s", event.Kind, name))
+			}
+		}
+	}
+	return util.CombineErrors(errs...)
+}
+
+// Message renders the message of the event using the template of the route, the templates of the configuration or
+// the default template
+func (d *Dispatcher) Message(event *Event, routeTemplate string) (string, error) {
+	templateText := routeTemplate
+	if templateText == "" && d.Config != nil {
+		templateText = d.Config.Templates[event.Kind]
+	}
+	if templateText == "" {
+		templateText = DefaultTemplates[event.Kind]
+	}
+	tmpl, err := template.New(event.Kind).Parse(templateText)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to parse the template of the %s notification", event.Kind)
+	}
+	var buffer bytes.Buffer
+	err = tmpl.Execute(&buffer, event)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to render the template of the %s notification", event.Kind)
+	}
+	return buffer.String(), nil
+}
+
+func (d *Dispatcher) send(channel *config.NotificationChannel, event *Event, text string) error {
+	var payload interface{}
+	switch channel.Kind {
+	case config.NotificationChannelSlack:
+		payload = map[string]string{
+			"text": text,
+		}
+	case config.NotificationChannelTeams:
+		payload = map[string]string{
+			"@type":    "MessageCard",
+			"@context": "https://schema.org/extensions",
+			"summary":  event.Kind,
+			"text":     text,
+		}
+	default:
+		payload = map[string]interface{}{
+			"event": event,
+			"text":  text,
+		}
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal the notification")
+	}
+	client := d.Client
+	if client == nil {
+		client = util.GetClientWithTimeout(10 * time.Second)
+	}
+	resp, err := client.Post(channel.WebhookURL(), "application/json", bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("status %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+// ActivityEvents returns the events caused by the change of a PipelineActivity. The old activity is nil if the
+// activity was created
+func ActivityEvents(old *v1.PipelineActivity, activity *v1.PipelineActivity) []*Event {
+	answer := []*Event{}
+	spec := &activity.Spec
+	branch := activity.BranchName()
+	if branch == "" {
+		branch = spec.GitBranch
+	}
+	newEvent := func(kind string) *Event {
+		return &Event{
+			Kind:       kind,
+			Repository: activity.RepositoryOwner() + "/" + activity.RepositoryName(),
+			Branch:     branch,
+			Build:      spec.Build,
+			Version:    spec.Version,
+			Author:     spec.Author,
+		}
+	}
+	oldStatus := v1.ActivityStatusTypeNone
+	if old != nil {
+		oldStatus = old.Spec.Status
+	}
+	if spec.Status != oldStatus {
+		switch spec.Status {
+		case v1.ActivityStatusTypeFailed:
+			event := newEvent(config.NotificationPipelineFailed)
+			event.URL = spec.BuildLogsURL
+			if event.URL == "" {
+				event.URL = spec.BuildURL
+			}
+			answer = append(answer, event)
+		case v1.ActivityStatusTypeSucceeded:
+			if spec.Version != "" && !isPullRequestBranch(branch) {
+				event := newEvent(config.NotificationReleasePublished)
+				event.URL = spec.ReleaseNotesURL
+				answer = append(answer, event)
+			}
+		}
+	}
+	oldPullRequests := map[string]bool{}
+	if old != nil {
+		for _, url := range promotePullRequests(old) {
+			oldPullRequests[url] = true
+		}
+	}
+	for env, url := range promotePullRequests(activity) {
+		if oldPullRequests[url] {
+			continue
+		}
+		event := newEvent(config.NotificationPromotionAwaitingApproval)
+		event.Environment = env
+		event.PullRequestURL = url
+		answer = append(answer, event)
+	}
+	return answer
+}
+
+// promotePullRequests returns the URLs of the open promotion Pull Requests of the activity indexed by environment
+func promotePullRequests(activity *v1.PipelineActivity) map[string]string {
+	answer := map[string]string{}
+	for _, step := range activity.Spec.Steps {
+		promote := step.Promote
+		if promote == nil || promote.PullRequest == nil || promote.PullRequest.PullRequestURL == "" {
+			continue
+		}
+		if promote.PullRequest.Status.IsTerminated() {
+			continue
+		}
+		answer[promote.Environment] = promote.PullRequest.PullRequestURL
+	}
+	return answer
+}
+
+func isPullRequestBranch(branch string) bool {
+	return strings.HasPrefix(strings.ToUpper(branch), "PR-")
+}
+
+// Notifier dispatches the events using the notifications configuration in the dev environment repository which is
+// reloaded periodically
+type Notifier struct {
+	Gitter        gits.Gitter
+	JXClient      versioned.Interface
+	Namespace     string
+	RefreshPeriod time.Duration
+
+	dispatcher *Dispatcher
+	loaded     time.Time
+	lock       sync.Mutex
+}
+
+// OnActivity notifies the events caused by the change of a PipelineActivity
+func (n *Notifier) OnActivity(old *v1.PipelineActivity, activity *v1.PipelineActivity) {
+	for _, event := range ActivityEvents(old, activity) {
+		n.Notify(event)
+	}
+}
+
+// Notify dispatches the event logging any failure as a warning
+func (n *Notifier) Notify(event *Event) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	if n.dispatcher == nil || time.Now().After(n.loaded.Add(n.RefreshPeriod)) {
+		cfg, err := LoadDevEnvironmentConfig(n.Gitter, n.JXClient, n.Namespace)
+		if err != nil {
+			log.Logger().Warnf("Failed to load the notifications configuration: %s", err)
+		}
+		if err == nil || n.dispatcher == nil {
+			n.dispatcher = &Dispatcher{Config: cfg}
+		}
+		n.loaded = time.Now()
+	}
+	err := n.dispatcher.Dispatch(event)
+	if err != nil {
+		log.Logger().Warnf("Failed to notify %s for %s: %s", event.Kind, event.Repository, err)
+	}
+}
+
+// LoadDevEnvironmentConfig loads the notifications configuration from the dev environment repository. Returns nil
+// if the repository has no notifications configuration
+func LoadDevEnvironmentConfig(gitter gits.Gitter, jxClient versioned.Interface, ns string) (*config.NotificationsConfig, error) {
+	devEnv, err := kube.GetDevEnvironment(jxClient, ns)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to find the dev environment in namespace %s", ns)
+	}
+	if devEnv == nil || devEnv.Spec.Source.URL == "" {
+		return nil, nil
+	}
+	dir, err := ioutil.TempDir("", "jx-notifications-")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create a temporary directory to clone the dev environment")
+	}
+	defer os.RemoveAll(dir)
+	err = gitter.ShallowClone(dir, devEnv.Spec.Source.URL, devEnv.Spec.Source.Ref, "")
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to clone the dev environment repository %s", devEnv.Spec.Source.URL)
+	}
+	return config.LoadNotificationsConfig(dir)
+}
@@ -0,0 +1,112 @@
+package notify_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/config"
+	"github.com/jenkins-x/jx/pkg/notify"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestDispatchNotifications(t *testing.T) {
+	t.Parallel()
+
+	received := map[string]map[string]interface{}{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		payload := map[string]interface{}{}
+		require.NoError(t, json.Unmarshal(data, &payload))
+		received[r.URL.Path] = payload
+	}))
+	defer server.Close()
+
+	dispatcher := &notify.Dispatcher{
+		Config: &config.NotificationsConfig{
+			Channels: []config.NotificationChannel{
+				{Name: "slack", Kind: config.NotificationChannelSlack, URL: server.URL + "/slack"},
+				{Name: "teams", Kind: config.NotificationChannelTeams, URL: server.URL + "/teams"},
+				{Name: "hook", Kind: config.NotificationChannelWebhook, URL: server.URL + "/hook"},
+			},
+			Routes: []config.NotificationRoute{
+				{
+					Events:   []string{config.NotificationPipelineFailed},
+					Channels: []string{"slack", "teams"},
+				},
+				{
+					Repositories: []string{"myorg/*"},
+					Channels:     []string{"hook", "slack"},
+					Template:     "{{ .Kind }} {{ .Repository }}",
+				},
+			},
+		},
+	}
+	err := dispatcher.Dispatch(&notify.Event{
+		Kind:       config.NotificationPipelineFailed,
+		Repository: "myorg/myapp",
+		Branch:     "master",
+		Build:      "3",
+		URL:        "https://dashboard/logs/3",
+	})
+	require.NoError(t, err)
+
+	require.Len(t, received, 3)
+	assert.Equal(t, "Pipeline myorg/myapp master #3 failed: https://dashboard/logs/3", received["/slack"]["text"])
+	assert.Equal(t, "MessageCard", received["/teams"]["@type"])
+	assert.Equal(t, "pipeline-failed myorg/myapp", received["/hook"]["text"])
+	event := received["/hook"]["event"].(map[string]interface{})
+	assert.Equal(t, "3", event["build"])
+}
+
+func TestActivityEvents(t *testing.T) {
+	t.Parallel()
+
+	old := &v1.PipelineActivity{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "myorg-myapp-master-3",
+		},
+		Spec: v1.PipelineActivitySpec{
+			Pipeline: "myorg/myapp/master",
+			Build:    "3",
+			Version:  "1.2.3",
+			Status:   v1.ActivityStatusTypeRunning,
+		},
+	}
+	activity := old.DeepCopy()
+	activity.Spec.Status = v1.ActivityStatusTypeSucceeded
+	activity.Spec.Steps = []v1.PipelineActivityStep{
+		{
+			Kind: v1.ActivityStepKindTypePromote,
+			Promote: &v1.PromoteActivityStep{
+				Environment: "production",
+				PullRequest: &v1.PromotePullRequestStep{
+					CoreActivityStep: v1.CoreActivityStep{Status: v1.ActivityStatusTypeRunning},
+					PullRequestURL:   "https://github.com/myorg/environment-production/pull/7",
+				},
+			},
+		},
+	}
+
+	events := notify.ActivityEvents(old, activity)
+	require.Len(t, events, 2)
+	assert.Equal(t, config.NotificationReleasePublished, events[0].Kind)
+	assert.Equal(t, "myorg/myapp", events[0].Repository)
+	assert.Equal(t, config.NotificationPromotionAwaitingApproval, events[1].Kind)
+	assert.Equal(t, "production", events[1].Environment)
+
+	assert.Empty(t, notify.ActivityEvents(activity, activity), "unchanged activities should not be notified")
+
+	failed := old.DeepCopy()
+	failed.Spec.Pipeline = "myorg/myapp/PR-12"
+	failed.Spec.Status = v1.ActivityStatusTypeFailed
+	events = notify.ActivityEvents(old, failed)
+	require.Len(t, events, 1)
+	assert.Equal(t, config.NotificationPipelineFailed, events[0].Kind)
+}
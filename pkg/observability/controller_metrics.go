@@ -0,0 +1,64 @@
+package observability
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// MetricControllerLeader the gauge which is 1 if the replica is the leader of the controller
+	MetricControllerLeader = "jx_controller_leader"
+	// MetricControllerShards the gauge of the number of shards of the work of the controller owned by the replica
+	MetricControllerShards = "jx_controller_shards_owned"
+	// MetricControllerQueueDepth the gauge of the number of items waiting in a queue of the controller
+	MetricControllerQueueDepth = "jx_controller_queue_depth"
+)
+
+// ControllerMetrics the Prometheus metrics of the leadership and queues of the replicas of the controllers
+type ControllerMetrics struct {
+	Leader     *prometheus.GaugeVec
+	Shards     *prometheus.GaugeVec
+	QueueDepth *prometheus.GaugeVec
+}
+
+// NewControllerMetrics creates the controller metrics registering them with the registerer
+func NewControllerMetrics(registerer prometheus.Registerer) (*ControllerMetrics, error) {
+	m := &ControllerMetrics{
+		Leader: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: MetricControllerLeader,
+			Help: "1 if the replica is the leader of the controller, otherwise 0",
+		}, []string{"controller"}),
+		Shards: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: MetricControllerShards,
+			Help: "The number of shards of the work of the controller owned by the replica",
+		}, []string{"controller"}),
+		QueueDepth: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: MetricControllerQueueDepth,
+			Help: "The number of items waiting in a queue of the controller",
+		}, []string{"controller", "queue"}),
+	}
+	for _, c := range []prometheus.Collector{m.Leader, m.Shards, m.QueueDepth} {
+		err := registerer.Register(c)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// SetQueueDepth records the number of items waiting in the queue of the controller. Does nothing if the metrics are
+// nil
+func (m *ControllerMetrics) SetQueueDepth(controller string, queue string, depth int) {
+	if m == nil {
+		return
+	}
+	m.QueueDepth.WithLabelValues(controller, queue).Set(float64(depth))
+}
+
+// AddQueueDepth adds the delta to the number of items waiting in the queue of the controller. Does nothing if the
+// metrics are nil
+func (m *ControllerMetrics) AddQueueDepth(controller string, queue string, delta int) {
+	if m == nil {
+		return
+	}
+	m.QueueDepth.WithLabelValues(controller, queue).Add(float64(delta))
+}
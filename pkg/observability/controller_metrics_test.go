@@ -0,0 +1,33 @@
+package observability
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestControllerMetrics(t *testing.T) {
+	t.Parallel()
+	registry := prometheus.NewRegistry()
+	m, err := NewControllerMetrics(registry)
+	require.NoError(t, err)
+
+	m.SetQueueDepth("build", "pipelines", 3)
+	assert.Equal(t, float64(3), testutil.ToFloat64(m.QueueDepth.WithLabelValues("build", "pipelines")))
+
+	m.AddQueueDepth("environment", "applies", 1)
+	m.AddQueueDepth("environment", "applies", 1)
+	m.AddQueueDepth("environment", "applies", -1)
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.QueueDepth.WithLabelValues("environment", "applies")))
+
+	// the controllers without metrics record nothing
+	var disabled *ControllerMetrics
+	disabled.SetQueueDepth("build", "pipelines", 1)
+	disabled.AddQueueDepth("build", "pipelines", 1)
+
+	_, err = NewControllerMetrics(registry)
+	assert.Error(t, err, "the metrics can only be registered once")
+}
@@ -0,0 +1,247 @@
+package observability
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/jenkins-x/jx/pkg/config"
+	"github.com/jenkins-x/jx/pkg/kube"
+	"github.com/jenkins-x/jx/pkg/kube/naming"
+)
+
+const (
+	// PanelTypeGraph the Grafana panel type of a time series graph
+	PanelTypeGraph = "graph"
+	// PanelTypeStat the Grafana panel type of a single value
+	PanelTypeStat = "stat"
+
+	// DashboardTag the tag of all the dashboards generated by jx
+	DashboardTag = "jenkins-x"
+
+	panelWidth  = 12
+	panelHeight = 8
+	gridWidth   = 24
+
+	// controllerPods matches the pods of the jx controllers and the webhook handlers
+	controllerPods = "jenkins-x-controller.*|jx-.*controller.*|lighthouse-.*|hook-.*|tide-.*|crier-.*|pipelinerunner-.*"
+)
+
+// Dashboard a Grafana dashboard
+type Dashboard struct {
+	UID           string     `json:"uid"`
+	Title         string     `json:"title"`
+	Tags          []string   `json:"tags,omitempty"`
+	Editable      bool       `json:"editable"`
+	Refresh       string     `json:"refresh,omitempty"`
+	SchemaVersion int        `json:"schemaVersion"`
+	Time          *TimeRange `json:"time,omitempty"`
+	Panels        []*Panel   `json:"panels"`
+}
+
+// TimeRange the default time range of a dashboard
+type TimeRange struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// Panel a panel of a Grafana dashboard
+type Panel struct {
+	ID          int          `json:"id"`
+	Title       string       `json:"title"`
+	Type        string       `json:"type"`
+	Datasource  string       `json:"datasource,omitempty"`
+	GridPos     GridPos      `json:"gridPos"`
+	Targets     []Target     `json:"targets,omitempty"`
+	FieldConfig *FieldConfig `json:"fieldConfig,omitempty"`
+}
+
+// GridPos the position of a panel on a dashboard
+type GridPos struct {
+	H int `json:"h"`
+	W int `json:"w"`
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+// Target a query of a panel
+type Target struct {
+	RefID        string `json:"refId"`
+	Expr         string `json:"expr"`
+	LegendFormat string `json:"legendFormat,omitempty"`
+}
+
+// FieldConfig the display options of the values of a panel
+type FieldConfig struct {
+	Defaults FieldDefaults `json:"defaults"`
+}
+
+// FieldDefaults the default display options of the values of a panel
+type FieldDefaults struct {
+	Unit string `json:"unit,omitempty"`
+}
+
+// NewDashboard creates a dashboard tagged as generated by jx
+func NewDashboard(uid string, title string, tags ...string) *Dashboard {
+	return &Dashboard{
+		UID:           uid,
+		Title:         title,
+		Tags:          append([]string{DashboardTag}, tags...),
+		Refresh:       "1m",
+		SchemaVersion: 22,
+		Time: &TimeRange{
+			From: "now-6h",
+			To:   "now",
+		},
+	}
+}
+
+// AddPanel adds a Prometheus panel laying out the panels two per row
+func (d *Dashboard) AddPanel(title string, panelType string, unit string, targets ...Target) *Panel {
+	idx := len(d.Panels)
+	for i := range targets {
+		if targets[i].RefID == "" {
+			targets[i].RefID = string(rune('A' + i))
+		}
+	}
+	panel := &Panel{
+		ID:         idx + 1,
+		Title:      title,
+		Type:       panelType,
+		Datasource: PrometheusDataSource,
+		GridPos: GridPos{
+			H: panelHeight,
+			W: panelWidth,
+			X: (idx * panelWidth) % gridWidth,
+			Y: (idx * panelWidth / gridWidth) * panelHeight,
+		},
+		Targets: targets,
+	}
+	if unit != "" {
+		panel.FieldConfig = &FieldConfig{Defaults: FieldDefaults{Unit: unit}}
+	}
+	d.Panels = append(d.Panels, panel)
+	return panel
+}
+
+// JSON returns the JSON model of the dashboard which Grafana loads
+func (d *Dashboard) JSON() ([]byte, error) {
+	return json.MarshalIndent(d, "", "  ")
+}
+
+// Dashboards returns the dashboards of the jx controllers, the Tekton pipelines and the applications of each
+// environment keyed by the environment name
+func Dashboards(devNs string, environments map[string]string) []*Dashboard {
+	answer := []*Dashboard{
+		ControllersDashboard(devNs),
+		TektonDashboard(devNs),
+	}
+	names := []string{}
+	for name := range environments {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		answer = append(answer, EnvironmentDashboard(name, environments[name]))
+	}
+	return answer
+}
+
+// ControllersDashboard returns the dashboard of the resource usage and health of the jx controllers
+func ControllersDashboard(ns string) *Dashboard {
+	selector := fmt.Sprintf(`namespace="%s",pod=~"%s"`, ns, controllerPods)
+	d := NewDashboard("jx-controllers", "Jenkins X Controllers", "controllers")
+	d.AddPanel("CPU", PanelTypeGraph, "short", Target{
+		Expr:         fmt.Sprintf(`sum by (pod) (rate(container_cpu_usage_seconds_total{%s,container!=""}[5m]))`, selector),
+		LegendFormat: "{{pod}}",
+	})
+	d.AddPanel("Memory", PanelTypeGraph, "bytes", Target{
+		Expr:         fmt.Sprintf(`sum by (pod) (container_memory_working_set_bytes{%s,container!=""})`, selector),
+		LegendFormat: "{{pod}}",
+	})
+	d.AddPanel("Restarts", PanelTypeGraph, "short", Target{
+		Expr:         fmt.Sprintf(`sum by (pod) (increase(kube_pod_container_status_restarts_total{%s}[1h]))`, selector),
+		LegendFormat: "{{pod}}",
+	})
+	d.AddPanel("Unavailable Replicas", PanelTypeStat, "short", Target{
+		Expr: fmt.Sprintf(`sum(kube_deployment_status_replicas_unavailable{namespace="%s"})`, ns),
+	})
+	return d
+}
+
+// TektonDashboard returns the dashboard of the Tekton pipelines
+func TektonDashboard(ns string) *Dashboard {
+	d := NewDashboard("jx-tekton-pipelines", "Jenkins X Tekton Pipelines", "tekton")
+	d.AddPanel("Running PipelineRuns", PanelTypeStat, "short", Target{
+		Expr: "sum(tekton_running_pipelineruns_count)",
+	})
+	d.AddPanel("PipelineRuns", PanelTypeGraph, "short", Target{
+		Expr:         "sum by (status) (increase(tekton_pipelinerun_count[1h]))",
+		LegendFormat: "{{status}}",
+	})
+	d.AddPanel("PipelineRun Duration", PanelTypeGraph, "s",
+		Target{
+			Expr:         "histogram_quantile(0.5, sum by (le) (rate(tekton_pipelinerun_duration_seconds_bucket[1h])))",
+			LegendFormat: "p50",
+		},
+		Target{
+			Expr:         "histogram_quantile(0.9, sum by (le) (rate(tekton_pipelinerun_duration_seconds_bucket[1h])))",
+			LegendFormat: "p90",
+		})
+	d.AddPanel("TaskRuns", PanelTypeGraph, "short", Target{
+		Expr:         "sum by (status) (increase(tekton_taskrun_count[1h]))",
+		LegendFormat: "{{status}}",
+	})
+	d.AddPanel("Build Pods", PanelTypeGraph, "short", Target{
+		Expr:         fmt.Sprintf(`sum by (phase) (kube_pod_status_phase{namespace="%s",pod=~".*-pod-.*"})`, ns),
+		LegendFormat: "{{phase}}",
+	})
+	return d
+}
+
+// EnvironmentDashboard returns the dashboard of the health of the applications in the namespace of an environment
+func EnvironmentDashboard(name string, ns string) *Dashboard {
+	d := NewDashboard("jx-env-"+naming.ToValidName(name), "Jenkins X Environment "+name, "environment")
+	d.AddPanel("Available Replicas", PanelTypeGraph, "short", Target{
+		Expr:         fmt.Sprintf(`sum by (deployment) (kube_deployment_status_replicas_available{namespace="%s"})`, ns),
+		LegendFormat: "{{deployment}}",
+	})
+	d.AddPanel("Unavailable Replicas", PanelTypeGraph, "short", Target{
+		Expr:         fmt.Sprintf(`sum by (deployment) (kube_deployment_status_replicas_unavailable{namespace="%s"})`, ns),
+		LegendFormat: "{{deployment}}",
+	})
+	d.AddPanel("Restarts", PanelTypeGraph, "short", Target{
+		Expr:         fmt.Sprintf(`sum by (pod) (increase(kube_pod_container_status_restarts_total{namespace="%s"}[1h]))`, ns),
+		LegendFormat: "{{pod}}",
+	})
+	d.AddPanel("HTTP Error Rate", PanelTypeGraph, "percentunit", Target{
+		Expr:         fmt.Sprintf(`sum by (ingress) (rate(nginx_ingress_controller_requests{exported_namespace="%s",status=~"5.."}[5m])) / sum by (ingress) (rate(nginx_ingress_controller_requests{exported_namespace="%s"}[5m]))`, ns, ns),
+		LegendFormat: "{{ingress}}",
+	})
+	d.AddPanel("CPU", PanelTypeGraph, "short", Target{
+		Expr:         fmt.Sprintf(`sum by (pod) (rate(container_cpu_usage_seconds_total{namespace="%s",container!=""}[5m]))`, ns),
+		LegendFormat: "{{pod}}",
+	})
+	d.AddPanel("Memory", PanelTypeGraph, "bytes", Target{
+		Expr:         fmt.Sprintf(`sum by (pod) (container_memory_working_set_bytes{namespace="%s",container!=""})`, ns),
+		LegendFormat: "{{pod}}",
+	})
+	return d
+}
+
+// EnvironmentNamespaces returns the namespaces of the environments of the requirements which run in the development
+// cluster keyed by the environment name
+func EnvironmentNamespaces(requirements *config.RequirementsConfig, devNs string) map[string]string {
+	answer := map[string]string{}
+	for _, env := range requirements.Environments {
+		if env.Key == "" || env.RemoteCluster {
+			continue
+		}
+		if env.Key == kube.LabelValueDevEnvironment {
+			answer[env.Key] = devNs
+		} else {
+			answer[env.Key] = devNs + "-" + env.Key
+		}
+	}
+	return answer
+}
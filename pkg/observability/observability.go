@@ -0,0 +1,171 @@
+package observability
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/jenkins-x/jx/pkg/helm"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// StableChartRepository the chart repository of the prometheus and grafana charts
+	StableChartRepository = "https://kubernetes-charts.storage.googleapis.com"
+	// LokiChartRepository the chart repository of the loki-stack chart
+	LokiChartRepository = "https://grafana.github.io/loki/charts"
+
+	// PrometheusDataSource the name of the Grafana data source of Prometheus
+	PrometheusDataSource = "Prometheus"
+	// LokiDataSource the name of the Grafana data source of Loki
+	LokiDataSource = "Loki"
+
+	// DashboardLabel the label the Grafana sidecar uses to discover the ConfigMaps of dashboards
+	DashboardLabel = "grafana_dashboard"
+	// DashboardsFileName the name of the template file of the chart the dashboard ConfigMaps are written to
+	DashboardsFileName = "jx-observability-dashboards.yaml"
+)
+
+// Dependencies returns the charts of the observability stack. The versions are left empty so that they are resolved
+// from the version stream
+func Dependencies() []*helm.Dependency {
+	return []*helm.Dependency{
+		{Name: "prometheus", Repository: StableChartRepository},
+		{Name: "grafana", Repository: StableChartRepository},
+		{Name: "loki-stack", Repository: LokiChartRepository},
+	}
+}
+
+// AddDependencies adds the charts of the observability stack which are not already in the requirements returning
+// true if any were added
+func AddDependencies(requirements *helm.Requirements) bool {
+	modified := false
+	for _, dep := range Dependencies() {
+		found := false
+		for _, existing := range requirements.Dependencies {
+			if existing != nil && (existing.Name == dep.Name || existing.Alias == dep.Name) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			requirements.Dependencies = append(requirements.Dependencies, dep)
+			modified = true
+		}
+	}
+	return modified
+}
+
+// DefaultValues returns the values of the observability charts which configure Grafana to load the dashboard
+// ConfigMaps and to use Prometheus and Loki as its data sources
+func DefaultValues(releaseName string) map[string]interface{} {
+	return map[string]interface{}{
+		"grafana": map[string]interface{}{
+			"sidecar": map[string]interface{}{
+				"dashboards": map[string]interface{}{
+					"enabled": true,
+					"label":   DashboardLabel,
+				},
+			},
+			"datasources": map[string]interface{}{
+				"datasources.yaml": map[string]interface{}{
+					"apiVersion": 1,
+					"datasources": []interface{}{
+						map[string]interface{}{
+							"name":      PrometheusDataSource,
+							"type":      "prometheus",
+							"url":       "http://" + releaseName + "-prometheus-server",
+							"access":    "proxy",
+							"isDefault": true,
+						},
+						map[string]interface{}{
+							"name":   LokiDataSource,
+							"type":   "loki",
+							"url":    "http://" + releaseName + "-loki:3100",
+							"access": "proxy",
+						},
+					},
+				},
+			},
+		},
+		"loki-stack": map[string]interface{}{
+			"promtail": map[string]interface{}{
+				"enabled": true,
+			},
+			// the stack installs its own grafana and prometheus
+			"grafana": map[string]interface{}{
+				"enabled": false,
+			},
+			"prometheus": map[string]interface{}{
+				"enabled": false,
+			},
+		},
+		"prometheus": map[string]interface{}{
+			"pushgateway": map[string]interface{}{
+				"enabled": false,
+			},
+		},
+	}
+}
+
+// DashboardConfigMaps returns the ConfigMaps which the Grafana sidecar loads the dashboards from
+func DashboardConfigMaps(dashboards []*Dashboard) ([]*corev1.ConfigMap, error) {
+	answer := []*corev1.ConfigMap{}
+	for _, d := range dashboards {
+		data, err := d.JSON()
+		if err != nil {
+			return nil, errors.Wrapf(err, "marshalling dashboard %s", d.UID)
+		}
+		cm := &corev1.ConfigMap{}
+		cm.APIVersion = "v1"
+		cm.Kind = "ConfigMap"
+		cm.Name = "jx-dashboard-" + d.UID
+		cm.Labels = map[string]string{
+			DashboardLabel: "1",
+		}
+		cm.Data = map[string]string{
+			d.UID + ".json": string(data),
+		}
+		answer = append(answer, cm)
+	}
+	return answer, nil
+}
+
+// WriteTemplate writes the resources as a template file of the chart in the directory escaping any text which helm
+// would otherwise treat as template expressions such as the legends of the dashboards
+func WriteTemplate(dir string, fileName string, resources []interface{}) error {
+	var buf bytes.Buffer
+	for i, resource := range resources {
+		data, err := yaml.Marshal(resource)
+		if err != nil {
+			return errors.Wrapf(err, "marshalling the resources of %s", fileName)
+		}
+		if i > 0 {
+			buf.WriteString("---\n")
+		}
+		buf.Write(data)
+	}
+	templatesDir := filepath.Join(dir, "templates")
+	err := os.MkdirAll(templatesDir, util.DefaultWritePermissions)
+	if err != nil {
+		return errors.Wrapf(err, "creating dir %s", templatesDir)
+	}
+	file := filepath.Join(templatesDir, fileName)
+	err = ioutil.WriteFile(file, []byte(EscapeHelmTemplate(buf.String())), util.DefaultFileWritePermissions)
+	if err != nil {
+		return errors.Wrapf(err, "writing %s", file)
+	}
+	return nil
+}
+
+// EscapeHelmTemplate escapes the template delimiters in the text so that helm renders them literally
+func EscapeHelmTemplate(text string) string {
+	return helmEscaper.Replace(text)
+}
+
+var helmEscaper = strings.NewReplacer("{{", `{{ "{{" }}`, "}}", `{{ "}}" }}`)
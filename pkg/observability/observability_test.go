@@ -0,0 +1,130 @@
+package observability_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jenkins-x/jx/pkg/config"
+	"github.com/jenkins-x/jx/pkg/helm"
+	"github.com/jenkins-x/jx/pkg/observability"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddDependencies(t *testing.T) {
+	t.Parallel()
+	requirements := &helm.Requirements{
+		Dependencies: []*helm.Dependency{
+			{Name: "jenkins-x-platform", Repository: "http://chartmuseum.jenkins-x.io", Version: "2.0.1"},
+			{Name: "grafana", Repository: observability.StableChartRepository, Version: "4.0.0"},
+		},
+	}
+
+	assert.True(t, observability.AddDependencies(requirements))
+	names := []string{}
+	for _, dep := range requirements.Dependencies {
+		names = append(names, dep.Name)
+	}
+	assert.Equal(t, []string{"jenkins-x-platform", "grafana", "prometheus", "loki-stack"}, names)
+	assert.Equal(t, "4.0.0", requirements.Dependencies[1].Version, "should keep the version of an existing dependency")
+	assert.Empty(t, requirements.Dependencies[2].Version, "should resolve the version from the version stream")
+
+	assert.False(t, observability.AddDependencies(requirements))
+	assert.Len(t, requirements.Dependencies, 4)
+}
+
+func TestDefaultValues(t *testing.T) {
+	t.Parallel()
+	values := observability.DefaultValues("jenkins-x")
+	assert.Equal(t, true, util.GetMapValueViaPath(values, "grafana.sidecar.dashboards.enabled"))
+	assert.Equal(t, observability.DashboardLabel, util.GetMapValueAsStringViaPath(values, "grafana.sidecar.dashboards.label"))
+	assert.Equal(t, false, util.GetMapValueViaPath(values, "loki-stack.grafana.enabled"))
+
+	datasources := util.GetMapValueAsMapViaPath(values, "grafana.datasources")["datasources.yaml"].(map[string]interface{})["datasources"].([]interface{})
+	require.Len(t, datasources, 2)
+	assert.Equal(t, "http://jenkins-x-prometheus-server", datasources[0].(map[string]interface{})["url"])
+	assert.Equal(t, "http://jenkins-x-loki:3100", datasources[1].(map[string]interface{})["url"])
+}
+
+func TestDashboards(t *testing.T) {
+	t.Parallel()
+	requirements := config.NewRequirementsConfig()
+	requirements.Environments = []config.EnvironmentConfig{
+		{Key: "dev"},
+		{Key: "staging"},
+		{Key: "production", RemoteCluster: true},
+	}
+	envs := observability.EnvironmentNamespaces(requirements, "jx")
+	assert.Equal(t, map[string]string{"dev": "jx", "staging": "jx-staging"}, envs)
+
+	dashboards := observability.Dashboards("jx", envs)
+	uids := []string{}
+	for _, d := range dashboards {
+		uids = append(uids, d.UID)
+		assert.Contains(t, d.Tags, observability.DashboardTag)
+		assert.NotEmpty(t, d.Panels)
+	}
+	assert.Equal(t, []string{"jx-controllers", "jx-tekton-pipelines", "jx-env-dev", "jx-env-staging"}, uids)
+
+	staging := dashboards[3]
+	assert.Contains(t, staging.Panels[0].Targets[0].Expr, `namespace="jx-staging"`)
+	assert.Equal(t, "A", staging.Panels[0].Targets[0].RefID)
+	assert.Equal(t, observability.GridPos{H: 8, W: 12, X: 0, Y: 0}, staging.Panels[0].GridPos)
+	assert.Equal(t, observability.GridPos{H: 8, W: 12, X: 12, Y: 0}, staging.Panels[1].GridPos)
+	assert.Equal(t, observability.GridPos{H: 8, W: 12, X: 0, Y: 8}, staging.Panels[2].GridPos)
+
+	tekton := dashboards[1]
+	var duration *observability.Panel
+	for _, p := range tekton.Panels {
+		if p.Title == "PipelineRun Duration" {
+			duration = p
+		}
+	}
+	require.NotNil(t, duration)
+	require.Len(t, duration.Targets, 2)
+	assert.Equal(t, "B", duration.Targets[1].RefID)
+	assert.Equal(t, "s", duration.FieldConfig.Defaults.Unit)
+}
+
+func TestWriteDashboardTemplate(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "test-observability-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	dashboards := observability.Dashboards("jx", map[string]string{"staging": "jx-staging"})
+	configMaps, err := observability.DashboardConfigMaps(dashboards)
+	require.NoError(t, err)
+	require.Len(t, configMaps, 3)
+	cm := configMaps[2]
+	assert.Equal(t, "jx-dashboard-jx-env-staging", cm.Name)
+	assert.Equal(t, "1", cm.Labels[observability.DashboardLabel])
+	model := map[string]interface{}{}
+	err = json.Unmarshal([]byte(cm.Data["jx-env-staging.json"]), &model)
+	require.NoError(t, err)
+	assert.Equal(t, "Jenkins X Environment staging", model["title"])
+
+	resources := []interface{}{}
+	for _, cm := range configMaps {
+		resources = append(resources, cm)
+	}
+	err = observability.WriteTemplate(dir, observability.DashboardsFileName, resources)
+	require.NoError(t, err)
+	data, err := ioutil.ReadFile(filepath.Join(dir, "templates", observability.DashboardsFileName))
+	require.NoError(t, err)
+	text := string(data)
+	assert.Equal(t, 2, strings.Count(text, "\n---\n"))
+	assert.Contains(t, text, `{{ "{{" }}pod{{ "}}" }}`)
+	assert.NotContains(t, text, `"{{pod}}"`)
+}
+
+func TestEscapeHelmTemplate(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, `value: {{ "{{" }} $labels.pod {{ "}}" }}`, observability.EscapeHelmTemplate("value: {{ $labels.pod }}"))
+	assert.Equal(t, "no templates", observability.EscapeHelmTemplate("no templates"))
+}
@@ -0,0 +1,120 @@
+package observability
+
+import (
+	"strings"
+	"time"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/tekton"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// MetricPipelineRuns the counter of the completed pipelines by status
+	MetricPipelineRuns = "jx_pipeline_runs_total"
+	// MetricPipelineDuration the histogram of the time the pipelines take from starting to completing
+	MetricPipelineDuration = "jx_pipeline_duration_seconds"
+	// MetricPipelineQueueTime the histogram of the time the queued pipelines wait for the pipeline concurrency limits
+	MetricPipelineQueueTime = "jx_pipeline_queue_seconds"
+	// MetricPipelineWebHookToStart the histogram of the time from the webhook triggering a pipeline to its first step
+	// starting
+	MetricPipelineWebHookToStart = "jx_pipeline_webhook_to_start_seconds"
+
+	// PipelineKindRelease the kind label of the pipelines of branches
+	PipelineKindRelease = "release"
+	// PipelineKindPullRequest the kind label of the pipelines of Pull Requests
+	PipelineKindPullRequest = "pullrequest"
+)
+
+// pipelineLabels the labels of the pipeline metrics. The branch is not a label as Pull Request branches would create
+// a new time series for each Pull Request
+var pipelineLabels = []string{"owner", "repository", "kind"}
+
+// PipelineMetrics the Prometheus metrics of the pipelines which the pipeline SLOs are calculated from. The metrics are
+// recorded from the changes of the PipelineActivities
+type PipelineMetrics struct {
+	Runs           *prometheus.CounterVec
+	Duration       *prometheus.HistogramVec
+	QueueTime      *prometheus.HistogramVec
+	WebHookToStart *prometheus.HistogramVec
+
+	now func() time.Time
+}
+
+// NewPipelineMetrics creates the pipeline metrics registering them with the registerer
+func NewPipelineMetrics(registerer prometheus.Registerer) (*PipelineMetrics, error) {
+	m := &PipelineMetrics{
+		Runs: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: MetricPipelineRuns,
+			Help: "The number of completed pipelines by status",
+		}, append(pipelineLabels, "status")),
+		Duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    MetricPipelineDuration,
+			Help:    "The time the pipelines take from starting to completing",
+			Buckets: prometheus.ExponentialBuckets(30, 2, 9),
+		}, pipelineLabels),
+		QueueTime: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    MetricPipelineQueueTime,
+			Help:    "The time the queued pipelines wait for the pipeline concurrency limits of the team",
+			Buckets: prometheus.ExponentialBuckets(1, 2, 12),
+		}, pipelineLabels),
+		WebHookToStart: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    MetricPipelineWebHookToStart,
+			Help:    "The time from the webhook triggering a pipeline to its first step starting",
+			Buckets: prometheus.ExponentialBuckets(1, 2, 12),
+		}, pipelineLabels),
+		now: time.Now,
+	}
+	for _, c := range []prometheus.Collector{m.Runs, m.Duration, m.QueueTime, m.WebHookToStart} {
+		err := registerer.Register(c)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// OnActivity records the metrics of the pipeline when its PipelineActivity starts, leaves the pipeline queue or
+// completes
+func (m *PipelineMetrics) OnActivity(oldActivity *v1.PipelineActivity, newActivity *v1.PipelineActivity) {
+	spec := &newActivity.Spec
+	labels := prometheus.Labels{
+		"owner":      spec.GitOwner,
+		"repository": spec.GitRepository,
+		"kind":       PipelineKind(spec.GitBranch),
+	}
+
+	queuedTime := oldActivity.Annotations[tekton.QueuedTimeAnnotation]
+	if queuedTime != "" && newActivity.Annotations[tekton.QueuedTimeAnnotation] == "" {
+		t, err := time.Parse(time.RFC3339Nano, queuedTime)
+		if err == nil {
+			m.QueueTime.With(labels).Observe(m.now().Sub(t).Seconds())
+		}
+	}
+
+	if oldActivity.Spec.StartedTimestamp == nil && spec.StartedTimestamp != nil {
+		latency := spec.StartedTimestamp.Sub(newActivity.CreationTimestamp.Time)
+		if latency >= 0 {
+			m.WebHookToStart.With(labels).Observe(latency.Seconds())
+		}
+	}
+
+	if oldActivity.Spec.CompletedTimestamp == nil && spec.CompletedTimestamp != nil {
+		statusLabels := prometheus.Labels{"status": string(spec.Status)}
+		for k, v := range labels {
+			statusLabels[k] = v
+		}
+		m.Runs.With(statusLabels).Inc()
+		if spec.StartedTimestamp != nil {
+			m.Duration.With(labels).Observe(spec.CompletedTimestamp.Sub(spec.StartedTimestamp.Time).Seconds())
+		}
+	}
+}
+
+// PipelineKind returns the kind label of the pipelines of the branch
+func PipelineKind(branch string) string {
+	if strings.HasPrefix(strings.ToUpper(branch), "PR-") {
+		return PipelineKindPullRequest
+	}
+	return PipelineKindRelease
+}
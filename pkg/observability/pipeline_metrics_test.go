@@ -0,0 +1,87 @@
+package observability
+
+import (
+	"testing"
+	"time"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/tekton"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPipelineMetrics(t *testing.T) {
+	t.Parallel()
+	registry := prometheus.NewRegistry()
+	m, err := NewPipelineMetrics(registry)
+	require.NoError(t, err)
+	created := time.Date(2020, 1, 2, 10, 0, 0, 0, time.UTC)
+	m.now = func() time.Time {
+		return created.Add(90 * time.Second)
+	}
+
+	queued := &v1.PipelineActivity{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "jstrachan-myapp-master-1",
+			CreationTimestamp: metav1.NewTime(created),
+			Annotations: map[string]string{
+				tekton.QueuedTimeAnnotation: created.Add(10 * time.Second).Format(time.RFC3339Nano),
+			},
+		},
+		Spec: v1.PipelineActivitySpec{
+			GitOwner:      "jstrachan",
+			GitRepository: "myapp",
+			GitBranch:     "master",
+			Status:        v1.ActivityStatusTypePending,
+		},
+	}
+	dequeued := queued.DeepCopy()
+	dequeued.Annotations = map[string]string{}
+	m.OnActivity(queued, dequeued)
+
+	started := dequeued.DeepCopy()
+	startedAt := metav1.NewTime(created.Add(2 * time.Minute))
+	started.Spec.StartedTimestamp = &startedAt
+	started.Spec.Status = v1.ActivityStatusTypeRunning
+	m.OnActivity(dequeued, started)
+
+	completed := started.DeepCopy()
+	completedAt := metav1.NewTime(created.Add(12 * time.Minute))
+	completed.Spec.CompletedTimestamp = &completedAt
+	completed.Spec.Status = v1.ActivityStatusTypeSucceeded
+	m.OnActivity(started, completed)
+	// the same completed activity is only counted once
+	m.OnActivity(completed, completed)
+
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.Runs.WithLabelValues("jstrachan", "myapp", PipelineKindRelease, "Succeeded")))
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	sums := map[string]float64{}
+	counts := map[string]uint64{}
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			if h := metric.GetHistogram(); h != nil {
+				sums[family.GetName()] += h.GetSampleSum()
+				counts[family.GetName()] += h.GetSampleCount()
+			}
+		}
+	}
+	assert.Equal(t, uint64(1), counts[MetricPipelineQueueTime])
+	assert.Equal(t, float64(80), sums[MetricPipelineQueueTime])
+	assert.Equal(t, uint64(1), counts[MetricPipelineWebHookToStart])
+	assert.Equal(t, float64(120), sums[MetricPipelineWebHookToStart])
+	assert.Equal(t, uint64(1), counts[MetricPipelineDuration])
+	assert.Equal(t, float64(600), sums[MetricPipelineDuration])
+}
+
+func TestPipelineKind(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, PipelineKindPullRequest, PipelineKind("PR-123"))
+	assert.Equal(t, PipelineKindPullRequest, PipelineKind("pr-4"))
+	assert.Equal(t, PipelineKindRelease, PipelineKind("master"))
+	assert.Equal(t, PipelineKindRelease, PipelineKind("release-1.x"))
+}
@@ -0,0 +1,219 @@
+package observability
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// PipelineDashboardsAppName the name of the app which installs the pipeline SLO dashboards and alerts
+	PipelineDashboardsAppName = "jx-pipeline-dashboards"
+	// PipelineSLODashboardUID the uid of the pipeline SLO dashboard
+	PipelineSLODashboardUID = "jx-pipeline-slos"
+
+	// AlertPipelineSuccessRate the alert raised when the success rate of the release pipelines of a repository is
+	// below its objective
+	AlertPipelineSuccessRate = "JenkinsXPipelineSuccessRateLow"
+	// AlertPipelineDuration the alert raised when the pipelines of a repository take longer than their objective
+	AlertPipelineDuration = "JenkinsXPipelineDurationHigh"
+	// AlertPipelineQueueTime the alert raised when the queued pipelines wait longer than their objective
+	AlertPipelineQueueTime = "JenkinsXPipelineQueueTimeHigh"
+	// AlertPipelineWebHookToStart the alert raised when the pipelines take longer to start than their objective
+	AlertPipelineWebHookToStart = "JenkinsXPipelineWebHookToStartHigh"
+)
+
+// PipelineSLO the service level objectives of the pipelines
+type PipelineSLO struct {
+	// SuccessRate the minimum ratio of the release pipelines of a repository which succeed
+	SuccessRate float64
+	// Percentile the percentile of the durations, queue times and webhook to start latencies the objectives apply to
+	Percentile float64
+	// Duration the maximum time the pipelines of a repository take
+	Duration time.Duration
+	// QueueTime the maximum time the queued pipelines wait
+	QueueTime time.Duration
+	// WebHookToStart the maximum time from a webhook to the first step of its pipeline starting
+	WebHookToStart time.Duration
+	// Window the range the objectives are calculated over such as "1d"
+	Window string
+	// For the time an objective must be missed before the alert fires such as "15m"
+	For string
+}
+
+// DefaultPipelineSLO returns the default pipeline SLOs
+func DefaultPipelineSLO() PipelineSLO {
+	return PipelineSLO{
+		SuccessRate:    0.9,
+		Percentile:     0.9,
+		Duration:       30 * time.Minute,
+		QueueTime:      5 * time.Minute,
+		WebHookToStart: 2 * time.Minute,
+		Window:         "1d",
+		For:            "15m",
+	}
+}
+
+// PrometheusRule a prometheus-operator PrometheusRule resource
+type PrometheusRule struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              PrometheusRuleSpec `json:"spec"`
+}
+
+// PrometheusRuleSpec the rule groups of a PrometheusRule
+type PrometheusRuleSpec struct {
+	Groups []RuleGroup `json:"groups"`
+}
+
+// RuleGroup a group of Prometheus rules
+type RuleGroup struct {
+	Name  string `json:"name"`
+	Rules []Rule `json:"rules"`
+}
+
+// Rule a Prometheus alerting rule
+type Rule struct {
+	Alert       string            `json:"alert"`
+	Expr        string            `json:"expr"`
+	For         string            `json:"for,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// successRateExpr the ratio of the release pipelines of each repository which succeed
+func (s *PipelineSLO) successRateExpr() string {
+	return fmt.Sprintf(`sum by (owner, repository) (increase(%s{kind="%s",status="%s"}[%s])) / sum by (owner, repository) (increase(%s{kind="%s"}[%s]))`,
+		MetricPipelineRuns, PipelineKindRelease, string(v1.ActivityStatusTypeSucceeded), s.Window, MetricPipelineRuns, PipelineKindRelease, s.Window)
+}
+
+// percentileExpr the percentile of the histogram metric grouped by the labels
+func (s *PipelineSLO) percentileExpr(metric string, by string) string {
+	return fmt.Sprintf(`histogram_quantile(%s, sum by (%s) (rate(%s_bucket[%s])))`, formatFloat(s.Percentile), by, metric, s.Window)
+}
+
+// PipelineSLODashboard returns the dashboard of the pipeline SLOs
+func PipelineSLODashboard(slo PipelineSLO) *Dashboard {
+	percentile := percentileName(slo.Percentile)
+	d := NewDashboard(PipelineSLODashboardUID, "Jenkins X Pipeline SLOs", "pipelines", "slo")
+	d.AddPanel("Release Success Rate", PanelTypeGraph, "percentunit",
+		Target{
+			Expr:         slo.successRateExpr(),
+			LegendFormat: "{{owner}}/{{repository}}",
+		},
+		Target{
+			Expr:         "vector(" + formatFloat(slo.SuccessRate) + ")",
+			LegendFormat: "objective",
+		})
+	d.AddPanel("Duration "+percentile, PanelTypeGraph, "s",
+		Target{
+			Expr:         slo.percentileExpr(MetricPipelineDuration, "le, owner, repository"),
+			LegendFormat: "{{owner}}/{{repository}}",
+		},
+		Target{
+			Expr:         "vector(" + formatFloat(slo.Duration.Seconds()) + ")",
+			LegendFormat: "objective",
+		})
+	d.AddPanel("Queue Time "+percentile, PanelTypeGraph, "s",
+		Target{
+			Expr:         slo.percentileExpr(MetricPipelineQueueTime, "le, kind"),
+			LegendFormat: "{{kind}}",
+		},
+		Target{
+			Expr:         "vector(" + formatFloat(slo.QueueTime.Seconds()) + ")",
+			LegendFormat: "objective",
+		})
+	d.AddPanel("WebHook to Start "+percentile, PanelTypeGraph, "s",
+		Target{
+			Expr:         slo.percentileExpr(MetricPipelineWebHookToStart, "le, kind"),
+			LegendFormat: "{{kind}}",
+		},
+		Target{
+			Expr:         "vector(" + formatFloat(slo.WebHookToStart.Seconds()) + ")",
+			LegendFormat: "objective",
+		})
+	d.AddPanel("Completed Pipelines", PanelTypeGraph, "short", Target{
+		Expr:         fmt.Sprintf(`sum by (kind, status) (increase(%s[1h]))`, MetricPipelineRuns),
+		LegendFormat: "{{kind}} {{status}}",
+	})
+	return d
+}
+
+// PipelineSLORules returns the PrometheusRule of the alerts raised when the pipelines miss their SLOs
+func PipelineSLORules(slo PipelineSLO, name string) *PrometheusRule {
+	percentile := percentileName(slo.Percentile)
+	labels := map[string]string{"severity": "warning"}
+	rule := &PrometheusRule{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "monitoring.coreos.com/v1",
+			Kind:       "PrometheusRule",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Spec: PrometheusRuleSpec{
+			Groups: []RuleGroup{
+				{
+					Name: "jx-pipeline-slos",
+					Rules: []Rule{
+						{
+							Alert:  AlertPipelineSuccessRate,
+							Expr:   slo.successRateExpr() + " < " + formatFloat(slo.SuccessRate),
+							For:    slo.For,
+							Labels: labels,
+							Annotations: map[string]string{
+								"summary":     "The release pipelines of {{ $labels.owner }}/{{ $labels.repository }} are failing",
+								"description": fmt.Sprintf("The success rate of the release pipelines over %s is {{ $value | humanize }} which is below the objective of %s", slo.Window, formatFloat(slo.SuccessRate)),
+							},
+						},
+						{
+							Alert:  AlertPipelineDuration,
+							Expr:   slo.percentileExpr(MetricPipelineDuration, "le, owner, repository") + " > " + formatFloat(slo.Duration.Seconds()),
+							For:    slo.For,
+							Labels: labels,
+							Annotations: map[string]string{
+								"summary":     "The pipelines of {{ $labels.owner }}/{{ $labels.repository }} are slow",
+								"description": fmt.Sprintf("The %s pipeline duration is {{ $value | humanizeDuration }} which is above the objective of %s", percentile, slo.Duration.String()),
+							},
+						},
+						{
+							Alert:  AlertPipelineQueueTime,
+							Expr:   slo.percentileExpr(MetricPipelineQueueTime, "le") + " > " + formatFloat(slo.QueueTime.Seconds()),
+							For:    slo.For,
+							Labels: labels,
+							Annotations: map[string]string{
+								"summary":     "The pipelines are waiting in the pipeline queue",
+								"description": fmt.Sprintf("The %s queue time is {{ $value | humanizeDuration }} which is above the objective of %s. Consider increasing the pipeline concurrency limits", percentile, slo.QueueTime.String()),
+							},
+						},
+						{
+							Alert:  AlertPipelineWebHookToStart,
+							Expr:   slo.percentileExpr(MetricPipelineWebHookToStart, "le") + " > " + formatFloat(slo.WebHookToStart.Seconds()),
+							For:    slo.For,
+							Labels: labels,
+							Annotations: map[string]string{
+								"summary":     "The pipelines are slow to start",
+								"description": fmt.Sprintf("The %s time from a webhook to the pipeline starting is {{ $value | humanizeDuration }} which is above the objective of %s", percentile, slo.WebHookToStart.String()),
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	return rule
+}
+
+// percentileName returns the name of the percentile such as p90 for 0.9
+func percentileName(percentile float64) string {
+	return "p" + formatFloat(math.Round(percentile*1000)/10)
+}
+
+// formatFloat formats the number without an exponent or trailing zeros as PromQL numbers
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
@@ -0,0 +1,52 @@
+package observability_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jenkins-x/jx/pkg/observability"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPipelineSLORules(t *testing.T) {
+	t.Parallel()
+	slo := observability.DefaultPipelineSLO()
+	slo.Duration = 15 * time.Minute
+
+	rule := observability.PipelineSLORules(slo, "jx-pipeline-dashboards")
+	assert.Equal(t, "PrometheusRule", rule.Kind)
+	assert.Equal(t, "jx-pipeline-dashboards", rule.Name)
+	require.Len(t, rule.Spec.Groups, 1)
+	rules := map[string]observability.Rule{}
+	for _, r := range rule.Spec.Groups[0].Rules {
+		rules[r.Alert] = r
+		assert.Equal(t, "15m", r.For)
+		assert.NotEmpty(t, r.Annotations["summary"])
+	}
+	require.Len(t, rules, 4)
+
+	assert.Equal(t, `sum by (owner, repository) (increase(jx_pipeline_runs_total{kind="release",status="Succeeded"}[1d])) / sum by (owner, repository) (increase(jx_pipeline_runs_total{kind="release"}[1d])) < 0.9`,
+		rules[observability.AlertPipelineSuccessRate].Expr)
+	assert.Equal(t, `histogram_quantile(0.9, sum by (le, owner, repository) (rate(jx_pipeline_duration_seconds_bucket[1d]))) > 900`,
+		rules[observability.AlertPipelineDuration].Expr)
+	assert.Equal(t, `histogram_quantile(0.9, sum by (le) (rate(jx_pipeline_queue_seconds_bucket[1d]))) > 300`,
+		rules[observability.AlertPipelineQueueTime].Expr)
+	assert.Equal(t, `histogram_quantile(0.9, sum by (le) (rate(jx_pipeline_webhook_to_start_seconds_bucket[1d]))) > 120`,
+		rules[observability.AlertPipelineWebHookToStart].Expr)
+	assert.Contains(t, rules[observability.AlertPipelineDuration].Annotations["description"], "p90 pipeline duration")
+	assert.Contains(t, rules[observability.AlertPipelineDuration].Annotations["description"], "objective of 15m0s")
+}
+
+func TestPipelineSLODashboard(t *testing.T) {
+	t.Parallel()
+	d := observability.PipelineSLODashboard(observability.DefaultPipelineSLO())
+	assert.Equal(t, observability.PipelineSLODashboardUID, d.UID)
+	titles := []string{}
+	for _, p := range d.Panels {
+		titles = append(titles, p.Title)
+	}
+	assert.Equal(t, []string{"Release Success Rate", "Duration p90", "Queue Time p90", "WebHook to Start p90", "Completed Pipelines"}, titles)
+	assert.Equal(t, "vector(1800)", d.Panels[1].Targets[1].Expr)
+	assert.Equal(t, "objective", d.Panels[1].Targets[1].LegendFormat)
+}
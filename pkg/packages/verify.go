@@ -0,0 +1,108 @@
+package packages
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/openpgp"
+)
+
+// ParseChecksums parses the output of 'sha256sum' returning a map of file names to their SHA256 checksums
+func ParseChecksums(data []byte) map[string]string {
+	answer := map[string]string{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		answer[strings.TrimPrefix(fields[1], "*")] = strings.ToLower(fields[0])
+	}
+	return answer
+}
+
+// FileChecksum returns the hex encoded SHA256 checksum of the file
+func FileChecksum(fileName string) (string, error) {
+	f, err := os.Open(fileName)
+	if err != nil {
+		return "", errors.Wrapf(err, "opening %s", fileName)
+	}
+	defer f.Close()
+	h := sha256.New()
+	_, err = io.Copy(h, f)
+	if err != nil {
+		return "", errors.Wrapf(err, "reading %s", fileName)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// VerifyChecksum verifies the SHA256 checksum of the file matches the checksum of the name in the checksums file
+func VerifyChecksum(fileName string, name string, checksums []byte) error {
+	expected, ok := ParseChecksums(checksums)[name]
+	if !ok {
+		return fmt.Errorf("no checksum found for %s", name)
+	}
+	actual, err := FileChecksum(fileName)
+	if err != nil {
+		return err
+	}
+	if actual != expected {
+		return fmt.Errorf("checksum of %s is %s but expected %s", name, actual, expected)
+	}
+	return nil
+}
+
+// VerifySignature verifies the detached signature of the data was created by one of the keys of the public key ring.
+// Both the key ring and the signature can be ASCII armored or binary
+func VerifySignature(data []byte, signature []byte, publicKeyRing []byte) error {
+	keyRing, err := openpgp.ReadArmoredKeyRing(bytes.NewReader(publicKeyRing))
+	if err != nil {
+		keyRing, err = openpgp.ReadKeyRing(bytes.NewReader(publicKeyRing))
+		if err != nil {
+			return errors.Wrap(err, "reading the public key ring")
+		}
+	}
+	if bytes.HasPrefix(bytes.TrimSpace(signature), []byte("-----BEGIN")) {
+		_, err = openpgp.CheckArmoredDetachedSignature(keyRing, bytes.NewReader(data), bytes.NewReader(signature))
+	} else {
+		_, err = openpgp.CheckDetachedSignature(keyRing, bytes.NewReader(data), bytes.NewReader(signature))
+	}
+	if err != nil {
+		return errors.Wrap(err, "verifying the signature")
+	}
+	return nil
+}
+
+// DownloadFileWithClient downloads the URL to the file using the given HTTP client
+func DownloadFileWithClient(client *http.Client, clientURL string, fullPath string) error {
+	log.Logger().Infof("Downloading %s to %s...", util.ColorInfo(clientURL), util.ColorInfo(fullPath))
+	out, err := os.Create(fullPath)
+	if err != nil {
+		return errors.Wrapf(err, "creating %s", fullPath)
+	}
+	defer out.Close()
+
+	resp, err := client.Get(clientURL)
+	if err != nil {
+		return errors.Wrapf(err, "downloading %s", clientURL)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("download of %s failed with status %d", clientURL, resp.StatusCode)
+	}
+	_, err = io.Copy(out, resp.Body)
+	if err != nil {
+		return errors.Wrapf(err, "downloading %s", clientURL)
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package packages
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/openpgp"
+	"golang.org/x/crypto/openpgp/armor"
+)
+
+func TestVerifyChecksum(t *testing.T) {
+	dir, err := ioutil.TempDir("", "test-verify-checksum-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	fileName := filepath.Join(dir, "jx-linux-amd64.tar.gz")
+	err = ioutil.WriteFile(fileName, []byte("hello"), 0600)
+	require.NoError(t, err)
+
+	// sha256 of "hello"
+	checksum := "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+	checksums := []byte(fmt.Sprintf("%s  jx-linux-amd64.tar.gz\n0000  jx-darwin-amd64.tar.gz\n", checksum))
+
+	assert.Equal(t, checksum, ParseChecksums(checksums)["jx-linux-amd64.tar.gz"])
+	assert.NoError(t, VerifyChecksum(fileName, "jx-linux-amd64.tar.gz", checksums))
+	assert.Error(t, VerifyChecksum(fileName, "jx-darwin-amd64.tar.gz", checksums))
+	assert.Error(t, VerifyChecksum(fileName, "jx-windows-amd64.zip", checksums))
+}
+
+func TestVerifySignature(t *testing.T) {
+	entity, err := openpgp.NewEntity("Release Signer", "", "release@example.com", nil)
+	require.NoError(t, err)
+
+	var publicKey bytes.Buffer
+	w, err := armor.Encode(&publicKey, openpgp.PublicKeyType, nil)
+	require.NoError(t, err)
+	require.NoError(t, entity.Serialize(w))
+	require.NoError(t, w.Close())
+
+	data := []byte("checksums")
+	var signature bytes.Buffer
+	require.NoError(t, openpgp.ArmoredDetachSign(&signature, entity, bytes.NewReader(data), nil))
+
+	assert.NoError(t, VerifySignature(data, signature.Bytes(), publicKey.Bytes()))
+	assert.Error(t, VerifySignature([]byte("tampered"), signature.Bytes(), publicKey.Bytes()))
+}
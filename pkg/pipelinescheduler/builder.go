@@ -184,6 +184,20 @@ func applyToMerger(parent *jenkinsv1.Merger, child *jenkinsv1.Merger) {
 	if child.StatusUpdatePeriod == nil {
 		child.StatusUpdatePeriod = parent.StatusUpdatePeriod
 	}
+	if child.MergeQueue == nil {
+		child.MergeQueue = parent.MergeQueue
+	} else if parent.MergeQueue != nil {
+		applyToMergeQueue(parent.MergeQueue, child.MergeQueue)
+	}
+}
+
+func applyToMergeQueue(parent *jenkinsv1.MergeQueue, child *jenkinsv1.MergeQueue) {
+	if child.BatchSizeLimit == nil {
+		child.BatchSizeLimit = parent.BatchSizeLimit
+	}
+	if child.Comment == nil {
+		child.Comment = parent.Comment
+	}
 }
 
 // TODO use this
@@ -65,6 +65,17 @@ func TestBuildWithSomePropertiesMergedMerger(t *testing.T) {
 	assert.Equal(t, child.Merger.SquashLabel, merged.Merger.SquashLabel)
 }
 
+func TestBuildWithSomePropertiesMergedMergeQueue(t *testing.T) {
+	t.Parallel()
+	child := testhelpers.CompleteScheduler()
+	child.Merger.MergeQueue.Comment = nil
+	parent := testhelpers.CompleteScheduler()
+	merged, err := pipelinescheduler.Build([]*v1.SchedulerSpec{parent, child})
+	assert.NoError(t, err)
+	assert.Equal(t, child.Merger.MergeQueue.BatchSizeLimit, merged.Merger.MergeQueue.BatchSizeLimit)
+	assert.Equal(t, parent.Merger.MergeQueue.Comment, merged.Merger.MergeQueue.Comment)
+}
+
 func TestBuildWithEmptyMerger(t *testing.T) {
 	t.Parallel()
 	child := testhelpers.CompleteScheduler()
@@ -88,6 +88,29 @@ func GenerateProw(gitOps bool, autoApplyConfigUpdater bool, jxClient versioned.I
 	return cfg, plugs, nil
 }
 
+// LoadRepositorySchedulerSpec returns the effective scheduler of the repository merging the schedulers of the
+// repository, its repository groups and the team. Returns nil if the repository has no source repository or scheduler
+func LoadRepositorySchedulerSpec(jxClient versioned.Interface, namespace string, teamSchedulerName string, org string, repo string) (*jenkinsv1.SchedulerSpec, error) {
+	schedulers, sourceRepoGroups, sourceRepos, err := loadSchedulerResources(jxClient, namespace)
+	if err != nil {
+		return nil, errors.Wrapf(err, "loading scheduler resources")
+	}
+	for _, sourceRepo := range sourceRepos.Items {
+		if sourceRepo.Spec.Org != org || sourceRepo.Spec.Repo != repo {
+			continue
+		}
+		applicableSchedulers := []*jenkinsv1.SchedulerSpec{}
+		applicableSchedulers = addRepositoryScheduler(sourceRepo, schedulers, applicableSchedulers)
+		applicableSchedulers = addProjectSchedulers(sourceRepoGroups, sourceRepo, schedulers, applicableSchedulers)
+		applicableSchedulers = addTeamScheduler(teamSchedulerName, schedulers[teamSchedulerName], applicableSchedulers)
+		if len(applicableSchedulers) < 1 {
+			return nil, nil
+		}
+		return Build(applicableSchedulers)
+	}
+	return nil, nil
+}
+
 func loadSchedulerResources(jxClient versioned.Interface, namespace string) (map[string]*jenkinsv1.Scheduler, *jenkinsv1.SourceRepositoryGroupList, *jenkinsv1.SourceRepositoryList, error) {
 	schedulers, err := jxClient.JenkinsV1().Schedulers(namespace).List(metav1.ListOptions{})
 	if err != nil {
@@ -40,6 +40,10 @@ func CompleteScheduler() *v1.SchedulerSpec {
 			MaxGoroutines:      pointerToRandomNumber(),
 			StatusUpdatePeriod: pointerToRandomDuration(),
 			SyncPeriod:         pointerToRandomDuration(),
+			MergeQueue: &v1.MergeQueue{
+				BatchSizeLimit: pointerToRandomNumber(),
+				Comment:        pointerToTrue(),
+			},
 		},
 		Presubmits: &v1.Presubmits{
 			Items: []*v1.Presubmit{
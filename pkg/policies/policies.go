@@ -0,0 +1,373 @@
+package policies
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/jenkins-x/jx/pkg/client/clientset/versioned"
+	"github.com/jenkins-x/jx/pkg/gits"
+	"github.com/jenkins-x/jx/pkg/kube"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+)
+
+const (
+	// PoliciesDir the directory of the dev environment repository which contains the policies
+	PoliciesDir = "policies"
+
+	// EngineConftest verifies the manifests with conftest
+	EngineConftest = "conftest"
+	// EngineOPA verifies the manifests with opa eval
+	EngineOPA = "opa"
+
+	// DefaultNamespace the default Rego package of the policies
+	DefaultNamespace = "main"
+)
+
+var (
+	// Engines the supported policy engines
+	Engines = []string{EngineConftest, EngineOPA}
+
+	// manifestExtensions the extensions of the manifest files which are verified
+	manifestExtensions = []string{".yaml", ".yml", ".json"}
+
+	// failureRules the rules of the policies which fail the verification
+	failureRules = []string{"deny", "violation"}
+
+	// warningRules the rules of the policies which are reported as warnings
+	warningRules = []string{"warn"}
+
+	yamlDocumentSeparator = regexp.MustCompile(`(?m)^---\s*$`)
+)
+
+// Violation a message of a policy for a manifest file
+type Violation struct {
+	File      string `json:"file"`
+	Namespace string `json:"namespace,omitempty"`
+	Message   string `json:"message"`
+}
+
+// String returns the description of the violation
+func (v Violation) String() string {
+	return fmt.Sprintf("%s: %s", v.File, v.Message)
+}
+
+// Result the result of verifying manifests against the policies
+type Result struct {
+	Failures []Violation `json:"failures,omitempty"`
+	Warnings []Violation `json:"warnings,omitempty"`
+}
+
+// Check logs the warnings and failures of the result and returns an error if any policy was violated
+func (r *Result) Check() error {
+	for _, w := range r.Warnings {
+		log.Logger().Warnf("%s", w.String())
+	}
+	for _, f := range r.Failures {
+		log.Logger().Errorf("%s", f.String())
+	}
+	if len(r.Failures) > 0 {
+		return fmt.Errorf("%d policy violations found in the manifests", len(r.Failures))
+	}
+	return nil
+}
+
+// Verifier verifies manifests against OPA/Rego policies
+type Verifier struct {
+	Engine    string
+	PolicyDir string
+	Namespace string
+}
+
+// DetectEngine returns the engine which is available on the PATH, preferring conftest
+func DetectEngine() string {
+	for _, engine := range Engines {
+		if _, err := exec.LookPath(engine); err == nil {
+			return engine
+		}
+	}
+	return EngineConftest
+}
+
+// Verify verifies the manifest files in the given directory or file against the policies
+func (v *Verifier) Verify(manifests string) (*Result, error) {
+	if util.StringArrayIndex(Engines, v.Engine) < 0 {
+		return nil, util.InvalidOption("engine", v.Engine, Engines)
+	}
+	if v.Namespace == "" {
+		v.Namespace = DefaultNamespace
+	}
+	files, err := ManifestFiles(manifests)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		log.Logger().Debugf("no manifests found in %s to verify against the policies", manifests)
+		return &Result{}, nil
+	}
+	if v.Engine == EngineOPA {
+		return v.verifyWithOPA(files)
+	}
+	return v.verifyWithConftest(files)
+}
+
+func (v *Verifier) verifyWithConftest(files []string) (*Result, error) {
+	args := []string{"test", "--policy", v.PolicyDir, "--namespace", v.Namespace, "--output", "json"}
+	args = append(args, files...)
+	var out bytes.Buffer
+	cmd := util.Command{
+		Name: EngineConftest,
+		Args: args,
+		Out:  &out,
+		Err:  os.Stderr,
+	}
+	// conftest exits with a non zero status if a policy fails so only report an error if there is no output
+	_, err := cmd.RunWithoutRetry()
+	if err != nil && len(bytes.TrimSpace(out.Bytes())) == 0 {
+		return nil, errors.Wrapf(err, "failed to run %s", cmd.String())
+	}
+	return ParseConftestOutput(out.Bytes())
+}
+
+func (v *Verifier) verifyWithOPA(files []string) (*Result, error) {
+	answer := &Result{}
+	query := "data." + v.Namespace
+	for _, file := range files {
+		documents, err := loadDocuments(file)
+		if err != nil {
+			return nil, err
+		}
+		for _, document := range documents {
+			cmd := util.Command{
+				Name: EngineOPA,
+				Args: []string{"eval", "--format", "json", "--data", v.PolicyDir, "--stdin-input", query},
+				In:   bytes.NewReader(document),
+			}
+			output, err := cmd.RunWithoutRetry()
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to evaluate the policies for %s", file)
+			}
+			result, err := ParseOPAOutput(file, v.Namespace, []byte(output))
+			if err != nil {
+				return nil, err
+			}
+			answer.Failures = append(answer.Failures, result.Failures...)
+			answer.Warnings = append(answer.Warnings, result.Warnings...)
+		}
+	}
+	return answer, nil
+}
+
+type conftestMessage struct {
+	Msg string `json:"msg"`
+}
+
+type conftestResult struct {
+	Filename  string            `json:"filename"`
+	Namespace string            `json:"namespace"`
+	Warnings  []conftestMessage `json:"warnings"`
+	Failures  []conftestMessage `json:"failures"`
+}
+
+// ParseConftestOutput parses the JSON output of conftest test
+func ParseConftestOutput(data []byte) (*Result, error) {
+	results := []conftestResult{}
+	err := json.Unmarshal(data, &results)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse the conftest output")
+	}
+	answer := &Result{}
+	for _, r := range results {
+		for _, m := range r.Failures {
+			answer.Failures = append(answer.Failures, Violation{File: r.Filename, Namespace: r.Namespace, Message: m.Msg})
+		}
+		for _, m := range r.Warnings {
+			answer.Warnings = append(answer.Warnings, Violation{File: r.Filename, Namespace: r.Namespace, Message: m.Msg})
+		}
+	}
+	return answer, nil
+}
+
+type opaOutput struct {
+	Result []struct {
+		Expressions []struct {
+			Value map[string]interface{} `json:"value"`
+		} `json:"expressions"`
+	} `json:"result"`
+}
+
+// ParseOPAOutput parses the JSON output of opa eval for the package of the policies evaluated against the given file
+func ParseOPAOutput(file string, namespace string, data []byte) (*Result, error) {
+	output := opaOutput{}
+	err := json.Unmarshal(data, &output)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse the opa output")
+	}
+	answer := &Result{}
+	for _, result := range output.Result {
+		for _, expression := range result.Expressions {
+			for _, message := range ruleMessages(expression.Value, failureRules) {
+				answer.Failures = append(answer.Failures, Violation{File: file, Namespace: namespace, Message: message})
+			}
+			for _, message := range ruleMessages(expression.Value, warningRules) {
+				answer.Warnings = append(answer.Warnings, Violation{File: file, Namespace: namespace, Message: message})
+			}
+		}
+	}
+	return answer, nil
+}
+
+// ruleMessages returns the messages of the given rules which are either strings or objects with a msg
+func ruleMessages(value map[string]interface{}, rules []string) []string {
+	answer := []string{}
+	for _, rule := range rules {
+		items, ok := value[rule].([]interface{})
+		if !ok {
+			continue
+		}
+		for _, item := range items {
+			switch m := item.(type) {
+			case string:
+				answer = append(answer, m)
+			case map[string]interface{}:
+				if msg, ok := m["msg"].(string); ok {
+					answer = append(answer, msg)
+				}
+			}
+		}
+	}
+	sort.Strings(answer)
+	return answer
+}
+
+// loadDocuments loads the documents of a YAML or JSON manifest file as JSON
+func loadDocuments(file string) ([][]byte, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read %s", file)
+	}
+	answer := [][]byte{}
+	for _, document := range yamlDocumentSeparator.Split(string(data), -1) {
+		if strings.TrimSpace(document) == "" {
+			continue
+		}
+		j, err := yaml.YAMLToJSON([]byte(document))
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to convert %s to JSON", file)
+		}
+		if string(j) == "null" {
+			continue
+		}
+		answer = append(answer, j)
+	}
+	return answer, nil
+}
+
+// ManifestFiles returns the YAML and JSON files in the given directory or the file itself
+func ManifestFiles(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to find the manifests %s", path)
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+	answer := []string{}
+	err = filepath.Walk(path, func(file string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() && util.StringArrayIndex(manifestExtensions, strings.ToLower(filepath.Ext(file))) >= 0 {
+			answer = append(answer, file)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to find the manifests in %s", path)
+	}
+	return answer, nil
+}
+
+// FindPolicyDir returns the policies directory of the git repository containing the given directory. If the repository
+// has no policies the repository of the dev environment is cloned into a temporary directory so that the policies
+// of the team are verified for the environment repositories too. The returned function removes any clone.
+// An empty directory is returned if there are no policies
+func FindPolicyDir(gitter gits.Gitter, jxClient versioned.Interface, devNs string, dir string) (string, func(), error) {
+	cleanup := func() {}
+	gitDir, gitConf, err := gitter.FindGitConfigDir(dir)
+	if err != nil {
+		return "", cleanup, errors.Wrapf(err, "failed to find the git repository of %s", dir)
+	}
+	for _, d := range []string{dir, gitDir} {
+		if d == "" {
+			continue
+		}
+		policyDir := filepath.Join(d, PoliciesDir)
+		exists, err := util.DirExists(policyDir)
+		if err != nil {
+			return "", cleanup, err
+		}
+		if exists {
+			return policyDir, cleanup, nil
+		}
+	}
+	if jxClient == nil {
+		return "", cleanup, nil
+	}
+	devEnv, err := kube.GetDevEnvironment(jxClient, devNs)
+	if err != nil {
+		return "", cleanup, errors.Wrapf(err, "failed to find the dev environment in namespace %s", devNs)
+	}
+	if devEnv == nil || devEnv.Spec.Source.URL == "" {
+		return "", cleanup, nil
+	}
+	if gitConf != "" && isSameRepository(gitter, gitConf, devEnv.Spec.Source.URL) {
+		return "", cleanup, nil
+	}
+	cloneDir, err := ioutil.TempDir("", "jx-policies-")
+	if err != nil {
+		return "", cleanup, errors.Wrap(err, "failed to create a temporary directory to clone the dev environment")
+	}
+	cleanup = func() {
+		os.RemoveAll(cloneDir)
+	}
+	err = gitter.ShallowClone(cloneDir, devEnv.Spec.Source.URL, devEnv.Spec.Source.Ref, "")
+	if err != nil {
+		cleanup()
+		return "", func() {}, errors.Wrapf(err, "failed to clone the dev environment repository %s", devEnv.Spec.Source.URL)
+	}
+	policyDir := filepath.Join(cloneDir, PoliciesDir)
+	exists, err := util.DirExists(policyDir)
+	if err != nil || !exists {
+		cleanup()
+		return "", func() {}, err
+	}
+	return policyDir, cleanup, nil
+}
+
+// isSameRepository returns true if the remote of the given git configuration is the repository of the URL
+func isSameRepository(gitter gits.Gitter, gitConf string, gitURL string) bool {
+	remoteURL, err := gitter.DiscoverRemoteGitURL(gitConf)
+	if err != nil || remoteURL == "" {
+		return false
+	}
+	remote, err := gits.ParseGitURL(remoteURL)
+	if err != nil {
+		return false
+	}
+	repository, err := gits.ParseGitURL(gitURL)
+	if err != nil {
+		return false
+	}
+	return remote.Host == repository.Host && strings.EqualFold(remote.Organisation, repository.Organisation) && strings.EqualFold(remote.Name, repository.Name)
+}
@@ -0,0 +1,104 @@
+package policies_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x/jx/pkg/gits"
+	"github.com/jenkins-x/jx/pkg/policies"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseConftestOutput(t *testing.T) {
+	t.Parallel()
+	data, err := ioutil.ReadFile(filepath.Join("test_data", "conftest.json"))
+	require.NoError(t, err)
+
+	result, err := policies.ParseConftestOutput(data)
+	require.NoError(t, err)
+	require.Len(t, result.Failures, 2)
+	require.Len(t, result.Warnings, 1)
+	assert.Equal(t, "output/myapp/templates/deployment.yaml: Container myapp uses the image gcr.io/myorg/myapp:latest with the :latest tag", result.Failures[0].String())
+	assert.Equal(t, "main", result.Warnings[0].Namespace)
+	assert.Error(t, result.Check())
+
+	_, err = policies.ParseConftestOutput([]byte("FAIL - deployment.yaml"))
+	assert.Error(t, err)
+}
+
+func TestParseOPAOutput(t *testing.T) {
+	t.Parallel()
+	data, err := ioutil.ReadFile(filepath.Join("test_data", "opa.json"))
+	require.NoError(t, err)
+
+	result, err := policies.ParseOPAOutput("deployment.yaml", "main", data)
+	require.NoError(t, err)
+	require.Len(t, result.Failures, 2)
+	assert.Equal(t, "Container myapp does not define resource limits", result.Failures[0].Message)
+	assert.Equal(t, "Container myapp uses the image gcr.io/myorg/myapp:latest with the :latest tag", result.Failures[1].Message)
+	require.Len(t, result.Warnings, 1)
+	assert.Equal(t, "deployment.yaml", result.Warnings[0].File)
+
+	result, err = policies.ParseOPAOutput("service.yaml", "main", []byte(`{"result":[{"expressions":[{"value":{"deny":[]}}]}]}`))
+	require.NoError(t, err)
+	assert.NoError(t, result.Check())
+}
+
+func TestManifestFiles(t *testing.T) {
+	t.Parallel()
+	dir := filepath.Join("test_data", "manifests")
+	files, err := policies.ManifestFiles(dir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(dir, "templates", "deployment.yaml"),
+		filepath.Join(dir, "templates", "service.yml"),
+	}, files)
+
+	file := filepath.Join(dir, "templates", "service.yml")
+	files, err = policies.ManifestFiles(file)
+	require.NoError(t, err)
+	assert.Equal(t, []string{file}, files)
+
+	_, err = policies.ManifestFiles(filepath.Join(dir, "missing"))
+	assert.Error(t, err)
+}
+
+func TestVerifyInvalidEngine(t *testing.T) {
+	t.Parallel()
+	verifier := &policies.Verifier{Engine: "kubeval"}
+	_, err := verifier.Verify(filepath.Join("test_data", "manifests"))
+	assert.Error(t, err)
+}
+
+func TestFindPolicyDir(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "test-find-policy-dir-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	envDir := filepath.Join(dir, "env")
+	err = os.MkdirAll(envDir, util.DefaultWritePermissions)
+	require.NoError(t, err)
+	gitDir := filepath.Join(dir, ".git")
+	err = os.MkdirAll(gitDir, util.DefaultWritePermissions)
+	require.NoError(t, err)
+	err = ioutil.WriteFile(filepath.Join(gitDir, "config"), []byte(""), util.DefaultWritePermissions)
+	require.NoError(t, err)
+
+	gitter := gits.NewGitCLI()
+	policyDir, cleanup, err := policies.FindPolicyDir(gitter, nil, "jx", envDir)
+	defer cleanup()
+	require.NoError(t, err)
+	assert.Equal(t, "", policyDir, "there should be no policies")
+
+	expected := filepath.Join(dir, policies.PoliciesDir)
+	err = os.MkdirAll(expected, util.DefaultWritePermissions)
+	require.NoError(t, err)
+	policyDir, _, err = policies.FindPolicyDir(gitter, nil, "jx", envDir)
+	require.NoError(t, err)
+	assert.Equal(t, expected, policyDir, "the policies of the root of the repository should be found")
+}
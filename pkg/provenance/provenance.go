@@ -0,0 +1,357 @@
+package provenance
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/kube"
+	"github.com/jenkins-x/jx/pkg/kube/naming"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/ed25519"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// StatementType the type of the in-toto statements
+	StatementType = "https://in-toto.io/Statement/v0.1"
+	// PredicateTypeSLSA the predicate type of SLSA provenance
+	PredicateTypeSLSA = "https://slsa.dev/provenance/v0.2"
+	// PayloadType the payload type of the signed envelopes of the in-toto statements
+	PayloadType = "application/vnd.in-toto+json"
+	// BuildType the build type of the Jenkins X pipelines
+	BuildType = "https://jenkins-x.io/pipeline@v1"
+
+	// AttestationsConfigMap the name of the ConfigMap storing the signed provenance of the releases
+	AttestationsConfigMap = "jx-provenance"
+	// SigningKeySecret the name of the Secret storing the key used to sign the provenance
+	SigningKeySecret = "jx-provenance-signing-key"
+
+	privateKeyField = "private.key"
+	publicKeyField  = "public.key"
+)
+
+// Digest a set of digests of an artifact by algorithm such as sha256
+type Digest map[string]string
+
+// Subject an artifact produced by the build
+type Subject struct {
+	Name   string `json:"name"`
+	Digest Digest `json:"digest"`
+}
+
+// Builder the identity of the builder which ran the build
+type Builder struct {
+	ID string `json:"id"`
+}
+
+// ConfigSource the source of the build configuration
+type ConfigSource struct {
+	URI        string `json:"uri,omitempty"`
+	Digest     Digest `json:"digest,omitempty"`
+	EntryPoint string `json:"entryPoint,omitempty"`
+}
+
+// Invocation how the build was invoked
+type Invocation struct {
+	ConfigSource ConfigSource      `json:"configSource"`
+	Parameters   map[string]string `json:"parameters,omitempty"`
+}
+
+// Metadata the metadata of the build
+type Metadata struct {
+	BuildInvocationID string     `json:"buildInvocationId,omitempty"`
+	BuildStartedOn    *time.Time `json:"buildStartedOn,omitempty"`
+	BuildFinishedOn   *time.Time `json:"buildFinishedOn,omitempty"`
+}
+
+// Material an input of the build such as the source repository or a base image
+type Material struct {
+	URI    string `json:"uri"`
+	Digest Digest `json:"digest,omitempty"`
+}
+
+// Predicate the SLSA provenance of the build
+type Predicate struct {
+	Builder    Builder    `json:"builder"`
+	BuildType  string     `json:"buildType"`
+	Invocation Invocation `json:"invocation"`
+	Metadata   Metadata   `json:"metadata"`
+	Materials  []Material `json:"materials,omitempty"`
+}
+
+// Statement an in-toto statement about the subjects of the build
+type Statement struct {
+	Type          string    `json:"_type"`
+	PredicateType string    `json:"predicateType"`
+	Subject       []Subject `json:"subject"`
+	Predicate     Predicate `json:"predicate"`
+}
+
+// Signature a signature of an envelope
+type Signature struct {
+	KeyID string `json:"keyid"`
+	Sig   string `json:"sig"`
+}
+
+// Envelope a DSSE envelope of a signed statement
+type Envelope struct {
+	PayloadType string      `json:"payloadType"`
+	Payload     string      `json:"payload"`
+	Signatures  []Signature `json:"signatures"`
+}
+
+// SigningKey the key used to sign and verify the provenance
+type SigningKey struct {
+	PrivateKey ed25519.PrivateKey
+	PublicKey  ed25519.PublicKey
+}
+
+// KeyID returns the ID of the key which is the start of the SHA256 of the public key
+func (k *SigningKey) KeyID() string {
+	sum := sha256.Sum256(k.PublicKey)
+	return hex.EncodeToString(sum[:])[:16]
+}
+
+// NewStatement creates a SLSA provenance statement for the subjects
+func NewStatement(subjects []Subject, predicate Predicate) *Statement {
+	if predicate.BuildType == "" {
+		predicate.BuildType = BuildType
+	}
+	return &Statement{
+		Type:          StatementType,
+		PredicateType: PredicateTypeSLSA,
+		Subject:       subjects,
+		Predicate:     predicate,
+	}
+}
+
+// Validate returns an error if the statement is missing the subjects or the builder identity
+func (s *Statement) Validate() error {
+	if len(s.Subject) == 0 {
+		return fmt.Errorf("the provenance has no subjects")
+	}
+	for _, subject := range s.Subject {
+		if subject.Name == "" || len(subject.Digest) == 0 {
+			return fmt.Errorf("the subject %s of the provenance has no digest", subject.Name)
+		}
+	}
+	if s.Predicate.Builder.ID == "" {
+		return fmt.Errorf("the provenance has no builder identity")
+	}
+	return nil
+}
+
+// ParseDigest parses a digest of the form algorithm:hex such as sha256:abc123. Digests without an algorithm are
+// assumed to be sha256
+func ParseDigest(text string) (Digest, error) {
+	algorithm := "sha256"
+	value := text
+	idx := strings.Index(text, ":")
+	if idx >= 0 {
+		algorithm = strings.ToLower(text[:idx])
+		value = text[idx+1:]
+	}
+	if algorithm == "" || value == "" {
+		return nil, fmt.Errorf("invalid digest %s expected the form algorithm:hex", text)
+	}
+	if _, err := hex.DecodeString(value); err != nil {
+		return nil, errors.Wrapf(err, "invalid digest %s", text)
+	}
+	return Digest{algorithm: strings.ToLower(value)}, nil
+}
+
+// Sign signs the statement returning the DSSE envelope
+func Sign(statement *Statement, key *SigningKey) (*Envelope, error) {
+	err := statement.Validate()
+	if err != nil {
+		return nil, err
+	}
+	payload, err := json.Marshal(statement)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal the provenance")
+	}
+	sig := ed25519.Sign(key.PrivateKey, preAuthEncoding(PayloadType, payload))
+	return &Envelope{
+		PayloadType: PayloadType,
+		Payload:     base64.StdEncoding.EncodeToString(payload),
+		Signatures: []Signature{
+			{
+				KeyID: key.KeyID(),
+				Sig:   base64.StdEncoding.EncodeToString(sig),
+			},
+		},
+	}, nil
+}
+
+// Verify verifies the envelope was signed by the public key returning the statement
+func Verify(envelope *Envelope, publicKey ed25519.PublicKey) (*Statement, error) {
+	if envelope.PayloadType != PayloadType {
+		return nil, fmt.Errorf("unsupported payload type %s", envelope.PayloadType)
+	}
+	payload, err := base64.StdEncoding.DecodeString(envelope.Payload)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode the payload of the provenance")
+	}
+	pae := preAuthEncoding(envelope.PayloadType, payload)
+	verified := false
+	for _, signature := range envelope.Signatures {
+		sig, err := base64.StdEncoding.DecodeString(signature.Sig)
+		if err != nil {
+			continue
+		}
+		if ed25519.Verify(publicKey, pae, sig) {
+			verified = true
+			break
+		}
+	}
+	if !verified {
+		return nil, fmt.Errorf("the provenance is not signed by the signing key of the team")
+	}
+	statement := &Statement{}
+	err = json.Unmarshal(payload, statement)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal the provenance")
+	}
+	if statement.Type != StatementType || statement.PredicateType != PredicateTypeSLSA {
+		return nil, fmt.Errorf("unsupported provenance of type %s with predicate %s", statement.Type, statement.PredicateType)
+	}
+	return statement, statement.Validate()
+}
+
+// preAuthEncoding returns the DSSE pre-authentication encoding of the payload which is what is signed
+func preAuthEncoding(payloadType string, payload []byte) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "DSSEv1 %d %s %d ", len(payloadType), payloadType, len(payload))
+	buf.Write(payload)
+	return buf.Bytes()
+}
+
+// GetOrCreateSigningKey returns the signing key of the team from the Secret in the dev namespace, generating it the
+// first time provenance is signed
+func GetOrCreateSigningKey(kubeClient kubernetes.Interface, ns string) (*SigningKey, error) {
+	var key *SigningKey
+	callback := func(secret *corev1.Secret) error {
+		if secret.Data == nil {
+			secret.Data = map[string][]byte{}
+		}
+		if len(secret.Data[privateKeyField]) == ed25519.PrivateKeySize {
+			key = secretSigningKey(secret)
+			return nil
+		}
+		publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return errors.Wrap(err, "failed to generate the provenance signing key")
+		}
+		secret.Data[privateKeyField] = privateKey
+		secret.Data[publicKeyField] = publicKey
+		key = &SigningKey{PrivateKey: privateKey, PublicKey: publicKey}
+		return nil
+	}
+	_, err := kube.DefaultModifySecret(kubeClient, ns, SigningKeySecret, callback, nil)
+	if err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// GetPublicKey returns the public key of the team used to verify the provenance
+func GetPublicKey(kubeClient kubernetes.Interface, ns string) (ed25519.PublicKey, error) {
+	secret, err := kubeClient.CoreV1().Secrets(ns).Get(SigningKeySecret, metav1.GetOptions{})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get the provenance signing key Secret %s in namespace %s", SigningKeySecret, ns)
+	}
+	publicKey := secret.Data[publicKeyField]
+	if len(publicKey) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("the Secret %s in namespace %s has no valid %s", SigningKeySecret, ns, publicKeyField)
+	}
+	return ed25519.PublicKey(publicKey), nil
+}
+
+func secretSigningKey(secret *corev1.Secret) *SigningKey {
+	privateKey := ed25519.PrivateKey(secret.Data[privateKeyField])
+	return &SigningKey{
+		PrivateKey: privateKey,
+		PublicKey:  privateKey.Public().(ed25519.PublicKey),
+	}
+}
+
+func attestationKey(app string, version string) string {
+	return naming.ToValidNameWithDots(app + "-" + version)
+}
+
+// SaveAttestation stores the signed provenance of the version of the application
+func SaveAttestation(kubeClient kubernetes.Interface, ns string, app string, version string, envelope *Envelope) error {
+	data, err := json.Marshal(envelope)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal the provenance of %s version %s", app, version)
+	}
+	callback := func(cm *corev1.ConfigMap) error {
+		if cm.Data == nil {
+			cm.Data = map[string]string{}
+		}
+		cm.Data[attestationKey(app, version)] = string(data)
+		return nil
+	}
+	_, err = kube.DefaultModifyConfigMap(kubeClient, ns, AttestationsConfigMap, callback, nil)
+	return err
+}
+
+// GetAttestation returns the signed provenance of the version of the application or nil if there is none
+func GetAttestation(kubeClient kubernetes.Interface, ns string, app string, version string) (*Envelope, error) {
+	cm, err := kube.GetConfigMap(kubeClient, ns, AttestationsConfigMap)
+	if err != nil {
+		// no provenance has been generated yet
+		return nil, nil
+	}
+	data := cm.Data[attestationKey(app, version)]
+	if data == "" {
+		return nil, nil
+	}
+	answer := &Envelope{}
+	err = json.Unmarshal([]byte(data), answer)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal the provenance of %s version %s in ConfigMap %s", app, version, AttestationsConfigMap)
+	}
+	return answer, nil
+}
+
+// IsProtectedEnvironment returns true if promotions to the environment require valid provenance
+func IsProtectedEnvironment(policy *v1.ProvenancePolicy, environment string) bool {
+	return policy != nil && util.StringArrayIndex(policy.ProtectedEnvironments, environment) >= 0
+}
+
+// VerifyAttestation returns an error if the version of the application has no provenance signed by the signing key of
+// the team or if the provenance was produced by a builder the policy does not trust
+func VerifyAttestation(kubeClient kubernetes.Interface, ns string, app string, version string, policy *v1.ProvenancePolicy) (*Statement, error) {
+	envelope, err := GetAttestation(kubeClient, ns, app, version)
+	if err != nil {
+		return nil, err
+	}
+	if envelope == nil {
+		return nil, fmt.Errorf("%s version %s has no provenance. Generate it in the release pipeline with 'jx step attest provenance'", app, version)
+	}
+	publicKey, err := GetPublicKey(kubeClient, ns)
+	if err != nil {
+		return nil, err
+	}
+	statement, err := Verify(envelope, publicKey)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid provenance of %s version %s", app, version)
+	}
+	if policy != nil && len(policy.TrustedBuilders) > 0 && util.StringArrayIndex(policy.TrustedBuilders, statement.Predicate.Builder.ID) < 0 {
+		return nil, fmt.Errorf("%s version %s was built by %s which is not one of the trusted builders %s", app, version,
+			statement.Predicate.Builder.ID, strings.Join(policy.TrustedBuilders, ", "))
+	}
+	return statement, nil
+}
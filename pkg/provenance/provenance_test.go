@@ -0,0 +1,96 @@
+package provenance_test
+
+import (
+	"encoding/base64"
+	"testing"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/provenance"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func newTestStatement() *provenance.Statement {
+	return provenance.NewStatement([]provenance.Subject{
+		{
+			Name:   "gcr.io/myorg/myapp:1.2.3",
+			Digest: provenance.Digest{"sha256": "0123456789abcdef"},
+		},
+	}, provenance.Predicate{
+		Builder: provenance.Builder{ID: "https://jenkins-x.io/pipelines/jx"},
+		Materials: []provenance.Material{
+			{URI: "git+https://github.com/myorg/myapp.git", Digest: provenance.Digest{"sha1": "abc123"}},
+		},
+	})
+}
+
+func TestSignAndVerify(t *testing.T) {
+	t.Parallel()
+	kubeClient := fake.NewSimpleClientset()
+
+	key, err := provenance.GetOrCreateSigningKey(kubeClient, "jx")
+	require.NoError(t, err)
+	again, err := provenance.GetOrCreateSigningKey(kubeClient, "jx")
+	require.NoError(t, err)
+	assert.Equal(t, key.KeyID(), again.KeyID(), "the signing key should only be generated once")
+
+	envelope, err := provenance.Sign(newTestStatement(), key)
+	require.NoError(t, err)
+	assert.Equal(t, provenance.PayloadType, envelope.PayloadType)
+
+	statement, err := provenance.Verify(envelope, key.PublicKey)
+	require.NoError(t, err)
+	assert.Equal(t, provenance.BuildType, statement.Predicate.BuildType)
+	assert.Equal(t, "gcr.io/myorg/myapp:1.2.3", statement.Subject[0].Name)
+
+	tampered := *envelope
+	tampered.Payload = base64.StdEncoding.EncodeToString([]byte(`{"_type": "https://in-toto.io/Statement/v0.1"}`))
+	_, err = provenance.Verify(&tampered, key.PublicKey)
+	assert.Error(t, err, "a modified payload should not verify")
+
+	_, err = provenance.Sign(provenance.NewStatement(nil, provenance.Predicate{}), key)
+	assert.Error(t, err, "provenance without subjects should not be signed")
+}
+
+func TestVerifyAttestation(t *testing.T) {
+	t.Parallel()
+	kubeClient := fake.NewSimpleClientset()
+	policy := &v1.ProvenancePolicy{ProtectedEnvironments: []string{"production"}}
+
+	assert.True(t, provenance.IsProtectedEnvironment(policy, "production"))
+	assert.False(t, provenance.IsProtectedEnvironment(policy, "staging"))
+	assert.False(t, provenance.IsProtectedEnvironment(nil, "production"))
+
+	key, err := provenance.GetOrCreateSigningKey(kubeClient, "jx")
+	require.NoError(t, err)
+	_, err = provenance.VerifyAttestation(kubeClient, "jx", "myapp", "1.2.3", policy)
+	assert.Error(t, err, "a release without provenance should not verify")
+
+	envelope, err := provenance.Sign(newTestStatement(), key)
+	require.NoError(t, err)
+	err = provenance.SaveAttestation(kubeClient, "jx", "myapp", "1.2.3", envelope)
+	require.NoError(t, err)
+
+	statement, err := provenance.VerifyAttestation(kubeClient, "jx", "myapp", "1.2.3", policy)
+	require.NoError(t, err)
+	assert.Equal(t, "https://jenkins-x.io/pipelines/jx", statement.Predicate.Builder.ID)
+
+	policy.TrustedBuilders = []string{"https://jenkins-x.io/pipelines/other"}
+	_, err = provenance.VerifyAttestation(kubeClient, "jx", "myapp", "1.2.3", policy)
+	assert.Error(t, err, "provenance from an untrusted builder should not verify")
+}
+
+func TestParseDigest(t *testing.T) {
+	t.Parallel()
+	digest, err := provenance.ParseDigest("sha256:ABC123")
+	require.NoError(t, err)
+	assert.Equal(t, provenance.Digest{"sha256": "abc123"}, digest)
+
+	digest, err = provenance.ParseDigest("abc123")
+	require.NoError(t, err)
+	assert.Equal(t, provenance.Digest{"sha256": "abc123"}, digest)
+
+	_, err = provenance.ParseDigest("sha256:not-hex")
+	assert.Error(t, err)
+}
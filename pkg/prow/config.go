@@ -2,8 +2,15 @@ package prow
 
 // Owners keeps the prow OWNERS data
 type Owners struct {
-	Approvers []string `json:"approvers"`
-	Reviewers []string `json:"reviewers"`
+	Approvers []string       `json:"approvers"`
+	Reviewers []string       `json:"reviewers"`
+	Options   *OwnersOptions `json:"options,omitempty"`
+}
+
+// OwnersOptions the options of an OWNERS file
+type OwnersOptions struct {
+	// NoParentOwners stops the approvers and reviewers of the parent directories applying to the directory
+	NoParentOwners bool `json:"no_parent_owners,omitempty"`
 }
 
 // OwnersAliases keept the prow OWNERS_ALIASES data
@@ -0,0 +1,354 @@
+package prow
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// OwnersFileName the name of the files listing the approvers and reviewers of a directory
+	OwnersFileName = "OWNERS"
+	// OwnersAliasesFileName the name of the file in the root of a repository defining aliases for groups of users
+	OwnersAliasesFileName = "OWNERS_ALIASES"
+)
+
+// RepoOwners the OWNERS files of a repository which define who approves and reviews the changes to each directory
+type RepoOwners struct {
+	owners  map[string]*Owners
+	aliases map[string][]string
+}
+
+// Blockade blocks the merge of Pull Requests which change the matching files of the repositories
+type Blockade struct {
+	// Repos the repositories as org or org/repo the blockade applies to
+	Repos []string `json:"repos,omitempty"`
+	// BlockRegexps the regular expressions of the blocked file paths
+	BlockRegexps []string `json:"blockregexps,omitempty"`
+	// ExceptionRegexps the regular expressions of the file paths which are not blocked
+	ExceptionRegexps []string `json:"exceptionregexps,omitempty"`
+	// Explanation why the files are blocked
+	Explanation string `json:"explanation,omitempty"`
+}
+
+// BlockedFiles the files of a Pull Request blocked by a blockade
+type BlockedFiles struct {
+	Explanation string
+	Files       []string
+}
+
+// ReviewStatus the result of evaluating the approvals of a Pull Request against the OWNERS files
+type ReviewStatus struct {
+	// Approved is true when every changed file is approved by one of its approvers
+	Approved bool
+	// LGTM is true when an approver or reviewer of the changes other than the author approved the Pull Request
+	LGTM bool
+	// UnapprovedFiles the changed files which are not approved yet
+	UnapprovedFiles []string
+	// SuggestedApprovers the approvers of the unapproved files
+	SuggestedApprovers []string
+	// SuggestedReviewers the reviewers of the changes if the Pull Request is not reviewed yet
+	SuggestedReviewers []string
+}
+
+// NewRepoOwners creates the owners of a repository from the OWNERS files by their directory, using "." for the root
+// directory, and the aliases of OWNERS_ALIASES
+func NewRepoOwners(owners map[string]*Owners, aliases map[string][]string) *RepoOwners {
+	answer := &RepoOwners{
+		owners:  map[string]*Owners{},
+		aliases: map[string][]string{},
+	}
+	for dir, o := range owners {
+		answer.owners[path.Clean(filepath.ToSlash(dir))] = o
+	}
+	for alias, users := range aliases {
+		answer.aliases[strings.ToLower(alias)] = users
+	}
+	return answer
+}
+
+// LoadRepoOwners loads the OWNERS files and the OWNERS_ALIASES file of the repository in the directory
+func LoadRepoOwners(dir string) (*RepoOwners, error) {
+	owners := map[string]*Owners{}
+	err := filepath.Walk(dir, func(fileName string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if info.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info.Name() != OwnersFileName {
+			return nil
+		}
+		data, err := ioutil.ReadFile(fileName)
+		if err != nil {
+			return errors.Wrapf(err, "failed to read %s", fileName)
+		}
+		o := &Owners{}
+		err = yaml.Unmarshal(data, o)
+		if err != nil {
+			return errors.Wrapf(err, "failed to unmarshal %s", fileName)
+		}
+		rel, err := filepath.Rel(dir, filepath.Dir(fileName))
+		if err != nil {
+			return err
+		}
+		owners[rel] = o
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to load the OWNERS files in %s", dir)
+	}
+	aliases, err := loadOwnersAliases(filepath.Join(dir, OwnersAliasesFileName))
+	if err != nil {
+		return nil, err
+	}
+	return NewRepoOwners(owners, aliases), nil
+}
+
+func loadOwnersAliases(fileName string) (map[string][]string, error) {
+	exists, err := util.FileExists(fileName)
+	if err != nil || !exists {
+		return nil, err
+	}
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read %s", fileName)
+	}
+	aliases := struct {
+		Aliases map[string][]string `json:"aliases"`
+	}{}
+	err = yaml.Unmarshal(data, &aliases)
+	if err != nil {
+		// older versions of jx import generated a file without a map of aliases
+		log.Logger().Warnf("Ignoring the aliases in %s as they are not a map of alias to users: %s", fileName, err)
+		return nil, nil
+	}
+	return aliases.Aliases, nil
+}
+
+// Approvers returns the approvers of the file from the OWNERS files of its directory and parent directories
+func (r *RepoOwners) Approvers(fileName string) []string {
+	return r.collect(fileName, func(o *Owners) []string {
+		return o.Approvers
+	})
+}
+
+// Reviewers returns the reviewers of the file from the OWNERS files of its directory and parent directories
+func (r *RepoOwners) Reviewers(fileName string) []string {
+	return r.collect(fileName, func(o *Owners) []string {
+		return o.Reviewers
+	})
+}
+
+func (r *RepoOwners) collect(fileName string, users func(o *Owners) []string) []string {
+	answer := []string{}
+	dir := path.Dir(path.Clean(filepath.ToSlash(fileName)))
+	for {
+		o := r.owners[dir]
+		if o != nil {
+			answer = appendUsers(answer, r.expand(users(o))...)
+			if o.Options != nil && o.Options.NoParentOwners {
+				break
+			}
+		}
+		if dir == "." || dir == "/" {
+			break
+		}
+		dir = path.Dir(dir)
+	}
+	sort.Strings(answer)
+	return answer
+}
+
+// expand replaces the aliases with their users
+func (r *RepoOwners) expand(names []string) []string {
+	answer := []string{}
+	for _, name := range names {
+		if users, ok := r.aliases[strings.ToLower(name)]; ok {
+			answer = appendUsers(answer, users...)
+		} else {
+			answer = appendUsers(answer, name)
+		}
+	}
+	return answer
+}
+
+// Review evaluates the approvals of a Pull Request changing the files. The author approves the files they are an
+// approver of. Files without any approvers are approved by any approval of a user other than the author
+func (r *RepoOwners) Review(files []string, author string, approvals []string) *ReviewStatus {
+	author = strings.ToLower(author)
+	approved := map[string]bool{}
+	for _, user := range approvals {
+		approved[strings.ToLower(user)] = true
+	}
+	delete(approved, author)
+
+	status := &ReviewStatus{
+		UnapprovedFiles:    []string{},
+		SuggestedApprovers: []string{},
+		SuggestedReviewers: []string{},
+	}
+	reviewers := []string{}
+	for _, fileName := range files {
+		approvers := r.Approvers(fileName)
+		fileReviewers := appendUsers(r.Reviewers(fileName), approvers...)
+		reviewers = appendUsers(reviewers, fileReviewers...)
+		if len(approvers) == 0 {
+			if len(approved) == 0 {
+				status.UnapprovedFiles = append(status.UnapprovedFiles, fileName)
+			}
+			continue
+		}
+		if containsUser(approvers, author) && author != "" {
+			continue
+		}
+		if !containsAnyUser(approvers, approved) {
+			status.UnapprovedFiles = append(status.UnapprovedFiles, fileName)
+			status.SuggestedApprovers = appendUsers(status.SuggestedApprovers, approvers...)
+		}
+	}
+	status.Approved = len(status.UnapprovedFiles) == 0
+	if len(reviewers) == 0 {
+		status.LGTM = len(approved) > 0
+	} else {
+		status.LGTM = containsAnyUser(reviewers, approved)
+	}
+	if !status.LGTM {
+		status.SuggestedReviewers = appendUsers(status.SuggestedReviewers, reviewers...)
+	}
+	status.SuggestedApprovers = removeUser(status.SuggestedApprovers, author)
+	status.SuggestedReviewers = removeUser(status.SuggestedReviewers, author)
+	sort.Strings(status.SuggestedApprovers)
+	sort.Strings(status.SuggestedReviewers)
+	return status
+}
+
+// MatchBlockades returns the files blocked by the blockades of the repository
+func MatchBlockades(blockades []Blockade, org string, repo string, files []string) ([]BlockedFiles, error) {
+	answer := []BlockedFiles{}
+	fullName := org + "/" + repo
+	for _, blockade := range blockades {
+		applies := false
+		for _, r := range blockade.Repos {
+			if strings.EqualFold(r, org) || strings.EqualFold(r, fullName) {
+				applies = true
+				break
+			}
+		}
+		if !applies {
+			continue
+		}
+		blocks, err := compileRegexps(blockade.BlockRegexps)
+		if err != nil {
+			return nil, err
+		}
+		exceptions, err := compileRegexps(blockade.ExceptionRegexps)
+		if err != nil {
+			return nil, err
+		}
+		blocked := []string{}
+		for _, fileName := range files {
+			if matchesAny(blocks, fileName) && !matchesAny(exceptions, fileName) {
+				blocked = append(blocked, fileName)
+			}
+		}
+		if len(blocked) > 0 {
+			answer = append(answer, BlockedFiles{Explanation: blockade.Explanation, Files: blocked})
+		}
+	}
+	return answer, nil
+}
+
+// LoadBlockades loads the blockades from the plugins configmap. There are no blockades if there is no configmap
+func (o *Options) LoadBlockades() ([]Blockade, error) {
+	cm, err := o.KubeClient.CoreV1().ConfigMaps(o.NS).Get(ProwPluginsConfigMapName, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "loading prow plugins configmap")
+	}
+	pluginConfig := struct {
+		Blockades []Blockade `json:"blockades,omitempty"`
+	}{}
+	err = yaml.Unmarshal([]byte(cm.Data[ProwPluginsFilename]), &pluginConfig)
+	if err != nil {
+		return nil, errors.Wrap(err, "unmarshaling plugins")
+	}
+	return pluginConfig.Blockades, nil
+}
+
+func compileRegexps(expressions []string) ([]*regexp.Regexp, error) {
+	answer := []*regexp.Regexp{}
+	for _, expression := range expressions {
+		re, err := regexp.Compile(expression)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid blockade regular expression %s", expression)
+		}
+		answer = append(answer, re)
+	}
+	return answer, nil
+}
+
+func matchesAny(expressions []*regexp.Regexp, text string) bool {
+	for _, re := range expressions {
+		if re.MatchString(text) {
+			return true
+		}
+	}
+	return false
+}
+
+// appendUsers appends the lower case users which are not already in the list
+func appendUsers(users []string, more ...string) []string {
+	for _, user := range more {
+		user = strings.ToLower(user)
+		if user != "" && !containsUser(users, user) {
+			users = append(users, user)
+		}
+	}
+	return users
+}
+
+func containsUser(users []string, user string) bool {
+	for _, u := range users {
+		if u == user {
+			return true
+		}
+	}
+	return false
+}
+
+func containsAnyUser(users []string, set map[string]bool) bool {
+	for _, u := range users {
+		if set[u] {
+			return true
+		}
+	}
+	return false
+}
+
+func removeUser(users []string, user string) []string {
+	answer := []string{}
+	for _, u := range users {
+		if u != user {
+			answer = append(answer, u)
+		}
+	}
+	return answer
+}
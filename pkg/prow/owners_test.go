@@ -0,0 +1,158 @@
+package prow_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x/jx/pkg/prow"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	testclient "k8s.io/client-go/kubernetes/fake"
+)
+
+func writeOwnersTestFile(t *testing.T, dir string, fileName string, text string) {
+	fileName = filepath.Join(dir, fileName)
+	err := os.MkdirAll(filepath.Dir(fileName), 0755)
+	require.NoError(t, err)
+	err = ioutil.WriteFile(fileName, []byte(text), 0644)
+	require.NoError(t, err)
+}
+
+func loadTestRepoOwners(t *testing.T) *prow.RepoOwners {
+	dir, err := ioutil.TempDir("", "test-owners")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	writeOwnersTestFile(t, dir, "OWNERS", "approvers:\n- Alice\nreviewers:\n- bob\n")
+	writeOwnersTestFile(t, dir, "docs/OWNERS", "approvers:\n- carol\n")
+	writeOwnersTestFile(t, dir, "secret/OWNERS", "approvers:\n- security\noptions:\n  no_parent_owners: true\n")
+	writeOwnersTestFile(t, dir, "OWNERS_ALIASES", "aliases:\n  security:\n  - dave\n  - erin\n")
+	writeOwnersTestFile(t, dir, ".git/OWNERS", "approvers:\n- mallory\n")
+
+	owners, err := prow.LoadRepoOwners(dir)
+	require.NoError(t, err)
+	return owners
+}
+
+func TestRepoOwnersApprovers(t *testing.T) {
+	t.Parallel()
+	owners := loadTestRepoOwners(t)
+
+	assert.Equal(t, []string{"alice"}, owners.Approvers("main.go"))
+	assert.Equal(t, []string{"alice", "carol"}, owners.Approvers("docs/guide/index.md"))
+	assert.Equal(t, []string{"dave", "erin"}, owners.Approvers("secret/key.txt"))
+	assert.Equal(t, []string{"bob"}, owners.Reviewers("docs/index.md"))
+	assert.Equal(t, []string{}, owners.Reviewers("secret/key.txt"))
+}
+
+func TestRepoOwnersReview(t *testing.T) {
+	t.Parallel()
+	owners := loadTestRepoOwners(t)
+	files := []string{"main.go", "docs/index.md", "secret/key.txt"}
+
+	status := owners.Review(files, "frank", nil)
+	assert.False(t, status.Approved)
+	assert.False(t, status.LGTM)
+	assert.Equal(t, files, status.UnapprovedFiles)
+	assert.Equal(t, []string{"alice", "carol", "dave", "erin"}, status.SuggestedApprovers)
+	assert.Equal(t, []string{"alice", "bob", "carol", "dave", "erin"}, status.SuggestedReviewers)
+
+	status = owners.Review(files, "frank", []string{"ALICE"})
+	assert.False(t, status.Approved)
+	assert.True(t, status.LGTM)
+	assert.Equal(t, []string{"secret/key.txt"}, status.UnapprovedFiles)
+	assert.Equal(t, []string{"dave", "erin"}, status.SuggestedApprovers)
+	assert.Equal(t, []string{}, status.SuggestedReviewers)
+
+	status = owners.Review(files, "frank", []string{"alice", "erin"})
+	assert.True(t, status.Approved)
+	assert.True(t, status.LGTM)
+}
+
+func TestRepoOwnersReviewByAuthor(t *testing.T) {
+	t.Parallel()
+	owners := loadTestRepoOwners(t)
+
+	status := owners.Review([]string{"docs/index.md"}, "carol", []string{"carol"})
+	assert.True(t, status.Approved, "the author approves the files they are an approver of")
+	assert.False(t, status.LGTM, "the author cannot review their own changes")
+	assert.Equal(t, []string{"alice", "bob"}, status.SuggestedReviewers)
+
+	status = owners.Review([]string{"docs/index.md"}, "carol", []string{"bob"})
+	assert.True(t, status.Approved)
+	assert.True(t, status.LGTM)
+}
+
+func TestRepoOwnersReviewWithoutOwners(t *testing.T) {
+	t.Parallel()
+	owners := prow.NewRepoOwners(nil, nil)
+
+	status := owners.Review([]string{"main.go"}, "frank", []string{"frank"})
+	assert.False(t, status.Approved)
+	assert.False(t, status.LGTM)
+
+	status = owners.Review([]string{"main.go"}, "frank", []string{"george"})
+	assert.True(t, status.Approved)
+	assert.True(t, status.LGTM)
+}
+
+func TestMatchBlockades(t *testing.T) {
+	t.Parallel()
+	blockades := []prow.Blockade{
+		{
+			Repos:            []string{"myorg"},
+			BlockRegexps:     []string{`^charts/`},
+			ExceptionRegexps: []string{`^charts/.*/README\.md$`},
+			Explanation:      "the charts are frozen",
+		},
+		{
+			Repos:        []string{"otherorg/myrepo"},
+			BlockRegexps: []string{`.*`},
+			Explanation:  "another repository",
+		},
+	}
+	files := []string{"main.go", "charts/myapp/values.yaml", "charts/myapp/README.md"}
+
+	blocked, err := prow.MatchBlockades(blockades, "MyOrg", "myrepo", files)
+	require.NoError(t, err)
+	assert.Equal(t, []prow.BlockedFiles{
+		{Explanation: "the charts are frozen", Files: []string{"charts/myapp/values.yaml"}},
+	}, blocked)
+
+	blocked, err = prow.MatchBlockades(blockades, "someorg", "myrepo", files)
+	require.NoError(t, err)
+	assert.Empty(t, blocked)
+
+	_, err = prow.MatchBlockades([]prow.Blockade{{Repos: []string{"myorg"}, BlockRegexps: []string{"("}}}, "myorg", "myrepo", files)
+	assert.Error(t, err)
+}
+
+func TestLoadBlockades(t *testing.T) {
+	t.Parallel()
+	o := prow.Options{
+		KubeClient: testclient.NewSimpleClientset(),
+		NS:         "jx",
+	}
+	blockades, err := o.LoadBlockades()
+	require.NoError(t, err)
+	assert.Empty(t, blockades)
+
+	o.KubeClient = testclient.NewSimpleClientset(&v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      prow.ProwPluginsConfigMapName,
+			Namespace: "jx",
+		},
+		Data: map[string]string{
+			prow.ProwPluginsFilename: "blockades:\n- repos:\n  - myorg/myrepo\n  blockregexps:\n  - ^charts/\n  explanation: frozen\n",
+		},
+	})
+	blockades, err = o.LoadBlockades()
+	require.NoError(t, err)
+	assert.Equal(t, []prow.Blockade{
+		{Repos: []string{"myorg/myrepo"}, BlockRegexps: []string{"^charts/"}, Explanation: "frozen"},
+	}, blockades)
+}
@@ -0,0 +1,204 @@
+package quickstarts
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+)
+
+const (
+	// CacheDirName the name of the directory inside the jx cache directory containing the offline quickstarts
+	CacheDirName = "quickstarts"
+
+	// CatalogFileName the name of the file in the quickstarts cache containing the cached quickstart catalog
+	CatalogFileName = "catalog.json"
+
+	zipsDirName = "zips"
+)
+
+// CachedCatalog the quickstart catalog stored in the offline cache
+type CachedCatalog struct {
+	// Updated when the catalog was last refreshed
+	Updated time.Time `json:"updated"`
+	// Quickstarts the quickstarts in the catalog
+	Quickstarts []*Quickstart `json:"quickstarts"`
+}
+
+// Cache an offline cache of the quickstart catalog and the source zips of the quickstarts so that quickstarts can be
+// created behind restrictive proxies or in air-gapped environments
+type Cache struct {
+	Dir string
+}
+
+// NewCache creates a quickstarts cache inside the given jx cache directory
+func NewCache(cacheDir string) *Cache {
+	return &Cache{
+		Dir: filepath.Join(cacheDir, CacheDirName),
+	}
+}
+
+// DefaultCache returns the quickstarts cache in the jx cache directory
+func DefaultCache() (*Cache, error) {
+	cacheDir, err := util.CacheDir()
+	if err != nil {
+		return nil, err
+	}
+	return NewCache(cacheDir), nil
+}
+
+// CatalogFile returns the file name of the cached catalog
+func (c *Cache) CatalogFile() string {
+	return filepath.Join(c.Dir, CatalogFileName)
+}
+
+// ZipFile returns the file name of the cached source zip of the given quickstart
+func (c *Cache) ZipFile(q *Quickstart) string {
+	name := strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == ':' {
+			return '_'
+		}
+		return r
+	}, q.ID)
+	return filepath.Join(c.Dir, zipsDirName, name+".zip")
+}
+
+// SaveModel saves the quickstarts of the model as the cached catalog
+func (c *Cache) SaveModel(model *QuickstartModel) error {
+	catalog := &CachedCatalog{
+		Updated: time.Now(),
+	}
+	for _, name := range model.SortedNames() {
+		catalog.Quickstarts = append(catalog.Quickstarts, model.Quickstarts[name])
+	}
+	data, err := json.MarshalIndent(catalog, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "marshalling the quickstart catalog")
+	}
+	err = os.MkdirAll(c.Dir, util.DefaultWritePermissions)
+	if err != nil {
+		return err
+	}
+	fileName := c.CatalogFile()
+	err = ioutil.WriteFile(fileName, data, util.DefaultWritePermissions)
+	if err != nil {
+		return errors.Wrapf(err, "saving the quickstart catalog to %s", fileName)
+	}
+	return nil
+}
+
+// LoadModel loads the cached catalog returning nil if there is no cached catalog
+func (c *Cache) LoadModel() (*QuickstartModel, *CachedCatalog, error) {
+	fileName := c.CatalogFile()
+	exists, err := util.FileExists(fileName)
+	if err != nil || !exists {
+		return nil, nil, err
+	}
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "reading the quickstart catalog %s", fileName)
+	}
+	catalog := &CachedCatalog{}
+	err = json.Unmarshal(data, catalog)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "unmarshalling the quickstart catalog %s", fileName)
+	}
+	model := NewQuickstartModel()
+	for _, q := range catalog.Quickstarts {
+		if q != nil && q.ID != "" {
+			model.Quickstarts[q.ID] = q
+		}
+	}
+	return model, catalog, nil
+}
+
+// SaveZip saves the source zip of the quickstart into the cache
+func (c *Cache) SaveZip(q *Quickstart, data []byte) error {
+	fileName := c.ZipFile(q)
+	err := os.MkdirAll(filepath.Dir(fileName), util.DefaultWritePermissions)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(fileName, data, util.DefaultWritePermissions)
+}
+
+// CachedZip returns the file name of the cached source zip of the quickstart and whether it exists
+func (c *Cache) CachedZip(q *Quickstart) (string, bool, error) {
+	fileName := c.ZipFile(q)
+	exists, err := util.FileExists(fileName)
+	return fileName, exists, err
+}
+
+// Refresh saves the catalog of the model and downloads the source zips of all of its quickstarts into the cache,
+// removing any zips of quickstarts which are no longer in the catalog
+func (c *Cache) Refresh(model *QuickstartModel) error {
+	err := c.SaveModel(model)
+	if err != nil {
+		return err
+	}
+	zipsDir := filepath.Join(c.Dir, zipsDirName)
+	err = os.RemoveAll(zipsDir)
+	if err != nil {
+		return err
+	}
+	var failed []string
+	for _, name := range model.SortedNames() {
+		q := model.Quickstarts[name]
+		data, err := DownloadZip(q)
+		if err == nil {
+			err = c.SaveZip(q, data)
+		}
+		if err != nil {
+			log.Logger().Warnf("Failed to cache the source of quickstart %s: %s", q.ID, err)
+			failed = append(failed, q.ID)
+			continue
+		}
+		log.Logger().Debugf("Cached the source of quickstart %s", q.ID)
+	}
+	if len(failed) > 0 {
+		sort.Strings(failed)
+		return fmt.Errorf("failed to cache the source of quickstarts: %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// DownloadZip downloads the source zip of the quickstart using the credentials of its git provider if it has one
+func DownloadZip(q *Quickstart) ([]byte, error) {
+	u := q.DownloadZipURL
+	if u == "" {
+		return nil, fmt.Errorf("quickstart %s does not have a download zip URL", q.ID)
+	}
+	client := http.Client{}
+	req, err := http.NewRequest(http.MethodGet, u, strings.NewReader(""))
+	if err != nil {
+		return nil, err
+	}
+	gitProvider := q.GitProvider
+	if gitProvider != nil {
+		userAuth := gitProvider.UserAuth()
+		token := userAuth.ApiToken
+		username := userAuth.Username
+		if token != "" && username != "" {
+			log.Logger().Debugf("Downloading Quickstart source zip from %s with basic auth for user: %s", u, username)
+			req.SetBasicAuth(username, token)
+		}
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode >= 400 {
+		return nil, fmt.Errorf("status %s when downloading %s", res.Status, u)
+	}
+	return ioutil.ReadAll(res.Body)
+}
@@ -0,0 +1,104 @@
+package quickstarts_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/jenkins-x/jx/pkg/quickstarts"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCacheSaveAndLoadModel(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "test-quickstart-cache-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	cache := quickstarts.NewCache(dir)
+	model, catalog, err := cache.LoadModel()
+	require.NoError(t, err)
+	assert.Nil(t, model, "should have no model before the catalog is cached")
+	assert.Nil(t, catalog)
+
+	original := quickstarts.NewQuickstartModel()
+	original.Add(&quickstarts.Quickstart{
+		ID:             "jenkins-x-quickstarts/node-http",
+		Owner:          "jenkins-x-quickstarts",
+		Name:           "node-http",
+		Language:       "JavaScript",
+		Tags:           []string{"node"},
+		DownloadZipURL: "https://example.com/node-http.zip",
+	})
+	original.Add(&quickstarts.Quickstart{
+		ID:    "jenkins-x-quickstarts/golang-http",
+		Owner: "jenkins-x-quickstarts",
+		Name:  "golang-http",
+	})
+	err = cache.SaveModel(original)
+	require.NoError(t, err)
+
+	model, catalog, err = cache.LoadModel()
+	require.NoError(t, err)
+	require.NotNil(t, model)
+	assert.False(t, catalog.Updated.IsZero(), "should have recorded when the catalog was updated")
+	assert.Equal(t, original.SortedNames(), model.SortedNames())
+	q := model.Quickstarts["jenkins-x-quickstarts/node-http"]
+	require.NotNil(t, q)
+	assert.Equal(t, "JavaScript", q.Language)
+	assert.Equal(t, []string{"node"}, q.Tags)
+	assert.Equal(t, "https://example.com/node-http.zip", q.DownloadZipURL)
+}
+
+func TestCacheRefreshDownloadsZips(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing.zip" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte("zip:" + r.URL.Path))
+	}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "test-quickstart-cache-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	cache := quickstarts.NewCache(dir)
+	found := &quickstarts.Quickstart{
+		ID:             "jenkins-x-quickstarts/node-http",
+		DownloadZipURL: server.URL + "/node-http.zip",
+	}
+	missing := &quickstarts.Quickstart{
+		ID:             "jenkins-x-quickstarts/missing",
+		DownloadZipURL: server.URL + "/missing.zip",
+	}
+	model := quickstarts.NewQuickstartModel()
+	model.Add(found)
+	model.Add(missing)
+
+	err = cache.Refresh(model)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), missing.ID)
+
+	fileName, exists, err := cache.CachedZip(found)
+	require.NoError(t, err)
+	require.True(t, exists, "should have cached the zip of %s", found.ID)
+	data, err := ioutil.ReadFile(fileName)
+	require.NoError(t, err)
+	assert.Equal(t, "zip:/node-http.zip", string(data))
+
+	_, exists, err = cache.CachedZip(missing)
+	require.NoError(t, err)
+	assert.False(t, exists, "should not have cached the zip of %s", missing.ID)
+
+	cached, _, err := cache.LoadModel()
+	require.NoError(t, err)
+	assert.Len(t, cached.Quickstarts, 2)
+}
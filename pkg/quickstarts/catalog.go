@@ -0,0 +1,83 @@
+package quickstarts
+
+import (
+	"io/ioutil"
+	"os"
+
+	"github.com/jenkins-x/jx/pkg/gits"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/jenkins-x/jx/pkg/versionstream"
+	"github.com/pkg/errors"
+)
+
+// LoadCatalogQuickstarts clones the given catalog repository of the owner and loads the quickstarts listed in its
+// quickstarts.yml file. The repository is cloned using the credentials of the git provider so catalogs can be private
+func (model *QuickstartModel) LoadCatalogQuickstarts(provider gits.GitProvider, gitter gits.Gitter, owner string, catalog string, includes []string, excludes []string) error {
+	repo, err := provider.GetRepository(owner, catalog)
+	if err != nil {
+		return errors.Wrapf(err, "failed to find quickstart catalog %s/%s", owner, catalog)
+	}
+	dir, err := ioutil.TempDir("", "jx-quickstart-catalog-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	userAuth := provider.UserAuth()
+	cloneURL, err := gitter.CreateAuthenticatedURL(repo.CloneURL, &userAuth)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create authenticated URL for %s", repo.CloneURL)
+	}
+	err = gitter.ShallowClone(dir, cloneURL, "", "")
+	if err != nil {
+		return errors.Wrapf(err, "failed to clone quickstart catalog %s/%s", owner, catalog)
+	}
+	return model.LoadCatalogQuickstartsFromDir(provider, dir, owner, catalog, includes, excludes)
+}
+
+// LoadCatalogQuickstartsFromDir loads the quickstarts listed in the quickstarts.yml file of a catalog cloned into dir.
+// Quickstarts without a download URL are downloaded from the git provider at their ref which defaults to the
+// version tag
+func (model *QuickstartModel) LoadCatalogQuickstartsFromDir(provider gits.GitProvider, dir string, owner string, catalog string, includes []string, excludes []string) error {
+	qs, err := versionstream.GetQuickStarts(dir)
+	if err != nil {
+		return errors.Wrapf(err, "loading quickstart catalog %s/%s", owner, catalog)
+	}
+	if qs.DefaultOwner == "" {
+		qs.DefaultOwner = owner
+	}
+	for _, q := range qs.QuickStarts {
+		if q.Owner == "" {
+			q.Owner = qs.DefaultOwner
+		}
+		if q.Ref == "" && q.Version != "" {
+			q.Ref = "v" + q.Version
+		}
+		if q.DownloadZipURL == "" {
+			ref := q.Ref
+			if ref == "" {
+				ref = "master"
+			}
+			q.DownloadZipURL = provider.BranchArchiveURL(q.Owner, q.Name, ref)
+		}
+	}
+	qs.DefaultMissingValues()
+
+	for _, from := range qs.QuickStarts {
+		if !util.StringMatchesAny(from.Name, includes, excludes) {
+			continue
+		}
+		to := &Quickstart{
+			GitServer:   provider.ServerURL(),
+			GitKind:     provider.Kind(),
+			GitProvider: provider,
+			Catalog:     owner + "/" + catalog,
+		}
+		err = model.convertToQuickStart(from, to)
+		if err != nil {
+			return errors.Wrapf(err, "failed to convert quickstart %s from catalog %s/%s", from.ID, owner, catalog)
+		}
+		model.Add(to)
+	}
+	return nil
+}
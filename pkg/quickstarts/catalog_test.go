@@ -0,0 +1,71 @@
+package quickstarts_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x/jx/pkg/gits"
+	"github.com/jenkins-x/jx/pkg/quickstarts"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadCatalogQuickstartsFromDir(t *testing.T) {
+	t.Parallel()
+
+	provider := gits.NewFakeProvider()
+	model := quickstarts.NewQuickstartModel()
+	err := model.LoadCatalogQuickstartsFromDir(provider, filepath.Join("test_data", "catalog"), "myorg", "quickstart-catalog", []string{"*"}, []string{"WIP-*"})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"myorg/spring-postgres", "otherorg/node-worker"}, model.SortedNames())
+
+	q := model.Quickstarts["myorg/spring-postgres"]
+	require.NotNil(t, q)
+	assert.Equal(t, "1.2.0", q.Version)
+	assert.Equal(t, "myorg/quickstart-catalog", q.Catalog)
+	assert.Equal(t, "Spring Boot REST service backed by PostgreSQL", q.Description)
+	assert.Equal(t, []string{"platform-team"}, q.Maintainers)
+	assert.Equal(t, []string{"postgres-credentials"}, q.RequiredSecrets)
+	assert.Equal(t, provider.BranchArchiveURL("myorg", "spring-postgres", "v1.2.0"), q.DownloadZipURL)
+	assert.Equal(t, provider, q.GitProvider)
+
+	q = model.Quickstarts["otherorg/node-worker"]
+	require.NotNil(t, q)
+	assert.Equal(t, provider.BranchArchiveURL("otherorg", "node-worker", "release-0.3"), q.DownloadZipURL)
+}
+
+func TestQuickstartModelFilterCatalogTagsAndDescription(t *testing.T) {
+	t.Parallel()
+
+	quickstart1 := &quickstarts.Quickstart{
+		ID:          "myorg/spring-postgres",
+		Name:        "spring-postgres",
+		Tags:        []string{"java", "database"},
+		Description: "Spring Boot REST service backed by PostgreSQL",
+		Catalog:     "myorg/quickstart-catalog",
+	}
+	quickstart2 := &quickstarts.Quickstart{
+		ID:   "jenkins-x-quickstarts/spring-boot-http-gradle",
+		Name: "spring-boot-http-gradle",
+		Tags: []string{"java"},
+	}
+	model := &quickstarts.QuickstartModel{
+		Quickstarts: map[string]*quickstarts.Quickstart{
+			quickstart1.ID: quickstart1,
+			quickstart2.ID: quickstart2,
+		},
+	}
+
+	results := model.Filter(&quickstarts.QuickstartFilter{Tags: []string{"Java"}})
+	assert.Len(t, results, 2)
+
+	results = model.Filter(&quickstarts.QuickstartFilter{Tags: []string{"java", "database"}})
+	assert.Equal(t, []*quickstarts.Quickstart{quickstart1}, results)
+
+	results = model.Filter(&quickstarts.QuickstartFilter{Text: "postgresql"})
+	assert.Equal(t, []*quickstarts.Quickstart{quickstart1}, results)
+
+	results = model.Filter(&quickstarts.QuickstartFilter{Catalog: "quickstart-catalog"})
+	assert.Equal(t, []*quickstarts.Quickstart{quickstart1}, results)
+}
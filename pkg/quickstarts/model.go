@@ -248,5 +248,8 @@ func (model *QuickstartModel) convertToQuickStart(from *versionstream.QuickStart
 	to.Framework = s(to.Framework, from.Framework)
 	to.Language = s(to.Language, from.Language)
 	to.Tags = ss(to.Tags, from.Tags)
+	to.Description = s(to.Description, from.Description)
+	to.Maintainers = ss(to.Maintainers, from.Maintainers)
+	to.RequiredSecrets = ss(to.RequiredSecrets, from.RequiredSecrets)
 	return nil
 }
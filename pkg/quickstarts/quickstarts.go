@@ -19,7 +19,7 @@ func (f *QuickstartFilter) Matches(q *Quickstart) bool {
 		return false
 	}
 	text := f.Text
-	if text != "" && !strings.Contains(q.ID, text) {
+	if text != "" && !strings.Contains(q.ID, text) && !strings.Contains(strings.ToLower(q.Description), strings.ToLower(text)) {
 		return false
 	}
 	owner := strings.ToLower(f.Owner)
@@ -37,6 +37,15 @@ func (f *QuickstartFilter) Matches(q *Quickstart) bool {
 	if !f.AllowML && util.StartsWith(q.Name, "ML-") {
 		return false
 	}
+	catalog := strings.ToLower(f.Catalog)
+	if catalog != "" && !strings.Contains(strings.ToLower(q.Catalog), catalog) {
+		return false
+	}
+	for _, tag := range f.Tags {
+		if util.StringArrayIndex(util.StringArrayToLower(q.Tags), strings.ToLower(tag)) < 0 {
+			return false
+		}
+	}
 	return true
 }
 
@@ -0,0 +1,206 @@
+package quickstarts
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// TemplateConfigFileName the name of the file in the root of a quickstart which configures its templating
+	TemplateConfigFileName = "quickstart.yaml"
+
+	defaultLeftDelim  = "[["
+	defaultRightDelim = "]]"
+)
+
+// TemplateConfig configures the templating of the files of a quickstart when a project is created from it
+type TemplateConfig struct {
+	// Parameters the parameters to prompt for which are available to templates via .Values
+	Parameters []TemplateParameter `json:"parameters,omitempty"`
+	// Files the patterns of the files to template matching the path or file name. Patterns ending in '/*' match all
+	// the files in a directory. Defaults to all the text files in the quickstart, in which case files which are not
+	// valid templates, such as shell scripts using '[[', are left unchanged
+	Files []string `json:"files,omitempty"`
+	// Excludes the patterns of the files to not template
+	Excludes []string `json:"excludes,omitempty"`
+	// LeftDelim the left template delimiter which defaults to '[[' to avoid clashing with helm templates
+	LeftDelim string `json:"leftDelim,omitempty"`
+	// RightDelim the right template delimiter which defaults to ']]' to avoid clashing with helm templates
+	RightDelim string `json:"rightDelim,omitempty"`
+}
+
+// TemplateParameter a parameter of a quickstart template
+type TemplateParameter struct {
+	// Name the name of the parameter
+	Name string `json:"name"`
+	// Description the description used when prompting for the value
+	Description string `json:"description,omitempty"`
+	// Default the default value
+	Default string `json:"default,omitempty"`
+	// Options the allowed values if the parameter is a choice
+	Options []string `json:"options,omitempty"`
+	// Required whether a value must be specified
+	Required bool `json:"required,omitempty"`
+}
+
+// TemplateData the data available to the templates of a quickstart
+type TemplateData struct {
+	// Name the name of the project being created
+	Name string
+	// Values the values of the template parameters
+	Values map[string]string
+}
+
+// LoadTemplateConfig loads the quickstart.yaml file from the given quickstart directory. Returns nil if the
+// quickstart has no templating configuration
+func LoadTemplateConfig(dir string) (*TemplateConfig, error) {
+	fileName := filepath.Join(dir, TemplateConfigFileName)
+	exists, err := util.FileExists(fileName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to check if file exists %s", fileName)
+	}
+	if !exists {
+		return nil, nil
+	}
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to load file %s", fileName)
+	}
+	config := &TemplateConfig{}
+	err = yaml.Unmarshal(data, config)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal YAML file %s", fileName)
+	}
+	return config, nil
+}
+
+// ResolveValues returns the values of all the parameters using the given values, prompting for any missing values
+// unless in batch mode where defaults are used instead
+func (c *TemplateConfig) ResolveValues(values map[string]string, batchMode bool, handles util.IOFileHandles) (map[string]string, error) {
+	answer := map[string]string{}
+	for k, v := range values {
+		answer[k] = v
+	}
+	for _, p := range c.Parameters {
+		value, ok := answer[p.Name]
+		if !ok {
+			message := p.Description
+			if message == "" {
+				message = p.Name
+			}
+			var err error
+			if batchMode {
+				value = p.Default
+			} else if len(p.Options) > 0 {
+				value, err = util.PickNameWithDefault(p.Options, message+":", p.Default, "", handles)
+			} else {
+				value, err = util.PickValue(message+":", p.Default, p.Required, "", handles)
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+		if value == "" && p.Required {
+			return nil, util.MissingOption(p.Name)
+		}
+		if value != "" && len(p.Options) > 0 && util.StringArrayIndex(p.Options, value) < 0 {
+			return nil, util.InvalidOption(p.Name, value, p.Options)
+		}
+		answer[p.Name] = value
+	}
+	return answer, nil
+}
+
+// ApplyTemplates renders the matching files in the quickstart directory as templates with the given data and then
+// removes the quickstart.yaml file
+func (c *TemplateConfig) ApplyTemplates(dir string, data *TemplateData) error {
+	leftDelim := c.LeftDelim
+	if leftDelim == "" {
+		leftDelim = defaultLeftDelim
+	}
+	rightDelim := c.RightDelim
+	if rightDelim == "" {
+		rightDelim = defaultRightDelim
+	}
+	funcs := template.FuncMap{
+		"lower": strings.ToLower,
+		"upper": strings.ToUpper,
+		"title": strings.Title,
+	}
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if info.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		if rel == TemplateConfigFileName || !c.matchesFile(rel) {
+			return nil
+		}
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			return errors.Wrapf(err, "failed to load file %s", path)
+		}
+		if bytes.IndexByte(content, 0) >= 0 || !bytes.Contains(content, []byte(leftDelim)) {
+			// binary files and files without any template expressions are left alone
+			return nil
+		}
+		tmpl, err := template.New(rel).Delims(leftDelim, rightDelim).Funcs(funcs).Option("missingkey=error").Parse(string(content))
+		if err == nil {
+			var buf bytes.Buffer
+			err = tmpl.Execute(&buf, data)
+			if err == nil {
+				return ioutil.WriteFile(path, buf.Bytes(), info.Mode())
+			}
+		}
+		if len(c.Files) > 0 {
+			return errors.Wrapf(err, "failed to render template %s", rel)
+		}
+		// the file was not explicitly listed as a template so it may just happen to contain the delimiters
+		log.Logger().Warnf("Not templating %s as it is not a valid template: %s", rel, err)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return os.Remove(filepath.Join(dir, TemplateConfigFileName))
+}
+
+// matchesFile returns true if the file with the given path relative to the quickstart should be templated
+func (c *TemplateConfig) matchesFile(rel string) bool {
+	rel = filepath.ToSlash(rel)
+	matches := func(patterns []string) bool {
+		for _, pattern := range patterns {
+			if m, _ := filepath.Match(pattern, rel); m {
+				return true
+			}
+			if m, _ := filepath.Match(pattern, filepath.Base(rel)); m {
+				return true
+			}
+			if strings.HasSuffix(pattern, "/*") && strings.HasPrefix(rel, strings.TrimSuffix(pattern, "*")) {
+				return true
+			}
+		}
+		return false
+	}
+	if matches(c.Excludes) {
+		return false
+	}
+	return len(c.Files) == 0 || matches(c.Files)
+}
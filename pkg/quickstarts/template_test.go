@@ -0,0 +1,72 @@
+package quickstarts_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x/jx/pkg/quickstarts"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuickstartTemplates(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "test-quickstart-templates")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	err = util.CopyDir(filepath.Join("test_data", "template"), dir, true)
+	require.NoError(t, err)
+
+	config, err := quickstarts.LoadTemplateConfig(dir)
+	require.NoError(t, err)
+	require.NotNil(t, config)
+	require.Len(t, config.Parameters, 3)
+
+	values, err := config.ResolveValues(map[string]string{"groupId": "com.acme"}, true, util.IOFileHandles{})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"groupId": "com.acme", "port": "8080", "database": "postgres"}, values)
+
+	_, err = config.ResolveValues(map[string]string{"database": "oracle"}, true, util.IOFileHandles{})
+	assert.Error(t, err)
+
+	err = config.ApplyTemplates(dir, &quickstarts.TemplateData{Name: "myapp", Values: values})
+	require.NoError(t, err)
+
+	assertFileContains(t, filepath.Join(dir, "pom.xml"), "<groupId>com.acme</groupId>", "<artifactId>myapp</artifactId>")
+	assertFileContains(t, filepath.Join(dir, "src", "main", "resources", "application.properties"), "server.port=8080", "spring.datasource.platform=POSTGRES")
+	assertFileContains(t, filepath.Join(dir, "charts", "myapp", "templates", "service.yaml"), `{{ template "fullname" . }}`, "[[ not a template ]]")
+	assertFileContains(t, filepath.Join(dir, "build.sh"), `if [[ -z "$VERSION" ]]; then`)
+	config, err = quickstarts.LoadTemplateConfig(dir)
+	require.NoError(t, err)
+	assert.Nil(t, config, "the %s file should have been removed", quickstarts.TemplateConfigFileName)
+}
+
+func TestQuickstartTemplatesFailOnInvalidListedFiles(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "test-quickstart-templates")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	err = util.CopyDir(filepath.Join("test_data", "template"), dir, true)
+	require.NoError(t, err)
+
+	config, err := quickstarts.LoadTemplateConfig(dir)
+	require.NoError(t, err)
+	require.NotNil(t, config)
+	config.Files = []string{"*.sh", "pom.xml"}
+
+	err = config.ApplyTemplates(dir, &quickstarts.TemplateData{Name: "myapp", Values: map[string]string{"groupId": "com.acme"}})
+	assert.Error(t, err, "files explicitly listed as templates should fail if they are not valid templates")
+}
+
+func assertFileContains(t *testing.T, fileName string, texts ...string) {
+	data, err := ioutil.ReadFile(fileName)
+	require.NoError(t, err)
+	for _, text := range texts {
+		assert.Contains(t, string(data), text, "file %s", fileName)
+	}
+}
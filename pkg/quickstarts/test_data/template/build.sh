@@ -0,0 +1,5 @@
+#!/bin/bash
+if [[ -z "$VERSION" ]]; then
+  VERSION=0.0.1
+fi
+echo "building $VERSION"
@@ -15,7 +15,15 @@ type Quickstart struct {
 	DownloadZipURL string
 	GitServer      string
 	GitKind        string
-	GitProvider    gits.GitProvider
+	GitProvider    gits.GitProvider `json:"-"`
+	// Catalog the catalog the quickstart was loaded from in the form owner/name if any
+	Catalog string
+	// Description a description of the quickstart
+	Description string
+	// Maintainers the maintainers of the quickstart
+	Maintainers []string
+	// RequiredSecrets the names of the secrets an application created from the quickstart needs
+	RequiredSecrets []string
 }
 
 type QuickstartModel struct {
@@ -30,6 +38,7 @@ type QuickstartFilter struct {
 	ProjectName string
 	Tags        []string
 	AllowML     bool
+	Catalog     string
 }
 
 type QuickstartForm struct {
@@ -0,0 +1,502 @@
+package registry
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+)
+
+const (
+	mediaTypeDockerManifest     = "application/vnd.docker.distribution.manifest.v2+json"
+	mediaTypeDockerManifestList = "application/vnd.docker.distribution.manifest.list.v2+json"
+	mediaTypeOCIManifest        = "application/vnd.oci.image.manifest.v1+json"
+	mediaTypeOCIIndex           = "application/vnd.oci.image.index.v1+json"
+
+	dockerHubHost     = "docker.io"
+	dockerHubRegistry = "registry-1.docker.io"
+)
+
+var acceptedManifests = []string{mediaTypeDockerManifestList, mediaTypeOCIIndex, mediaTypeDockerManifest, mediaTypeOCIManifest}
+
+// ImageReference a reference to an image in a registry by tag or digest
+type ImageReference struct {
+	// Host the host of the registry
+	Host string
+	// Repository the repository of the image in the registry
+	Repository string
+	// Reference the tag or digest of the image
+	Reference string
+}
+
+// ParseImageReference parses an image name such as gcr.io/acme/myapp:1.2.3 defaulting to docker hub and the latest tag
+func ParseImageReference(image string) (*ImageReference, error) {
+	if image == "" {
+		return nil, fmt.Errorf("no image name")
+	}
+	host, repository := SplitImage(image)
+	reference := "latest"
+	if idx := strings.Index(image, "@"); idx > 0 {
+		reference = image[idx+1:]
+	} else {
+		name := image
+		if host != "" {
+			name = image[len(host)+1:]
+		}
+		if idx := strings.LastIndex(name, ":"); idx > 0 {
+			reference = name[idx+1:]
+		}
+	}
+	if host == "" {
+		host = dockerHubHost
+	}
+	if host == dockerHubHost && !strings.Contains(repository, "/") {
+		repository = "library/" + repository
+	}
+	if repository == "" {
+		return nil, fmt.Errorf("no repository in image %s", image)
+	}
+	return &ImageReference{Host: host, Repository: repository, Reference: reference}, nil
+}
+
+// String returns the image name
+func (r *ImageReference) String() string {
+	separator := ":"
+	if strings.Contains(r.Reference, ":") {
+		separator = "@"
+	}
+	return r.Host + "/" + r.Repository + separator + r.Reference
+}
+
+// CopyOptions the options for copying images between registries
+type CopyOptions struct {
+	// Client the HTTP client used to talk to the registries
+	Client *http.Client
+	// Credentials returns the credentials of a registry host or nil for anonymous access
+	Credentials func(host string) (*Credentials, error)
+	// Insecure uses HTTP rather than HTTPS to talk to the registries
+	Insecure bool
+
+	lock   sync.Mutex
+	tokens map[string]string
+}
+
+// manifest the parts of the image manifests and indexes needed to copy them
+type manifest struct {
+	MediaType string       `json:"mediaType,omitempty"`
+	Config    *descriptor  `json:"config,omitempty"`
+	Layers    []descriptor `json:"layers,omitempty"`
+	Manifests []descriptor `json:"manifests,omitempty"`
+}
+
+// descriptor the reference to a blob or manifest
+type descriptor struct {
+	MediaType string   `json:"mediaType,omitempty"`
+	Digest    string   `json:"digest"`
+	URLs      []string `json:"urls,omitempty"`
+}
+
+// Copy copies the image including all of its platforms from the source to the destination without pulling it to the
+// local docker daemon. Blobs which already exist in the destination are not copied and blobs within the same registry
+// are mounted. Returns the digest of the copied manifest
+func (o *CopyOptions) Copy(src *ImageReference, dst *ImageReference) (string, error) {
+	if o.Client == nil {
+		o.Client = &http.Client{}
+	}
+	log.Logger().Infof("Copying image %s to %s", util.ColorInfo(src.String()), util.ColorInfo(dst.String()))
+	return o.copyManifest(src, dst, src.Reference, dst.Reference)
+}
+
+// copyManifest copies the manifest and everything it refers to returning its digest
+func (o *CopyOptions) copyManifest(src *ImageReference, dst *ImageReference, srcRef string, dstRef string) (string, error) {
+	mediaType, data, digest, err := o.getManifest(src, srcRef)
+	if err != nil {
+		return "", err
+	}
+	m := manifest{}
+	err = json.Unmarshal(data, &m)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to parse the manifest of %s", src.String())
+	}
+	switch mediaType {
+	case mediaTypeDockerManifestList, mediaTypeOCIIndex:
+		for _, child := range m.Manifests {
+			_, err = o.copyManifest(src, dst, child.Digest, child.Digest)
+			if err != nil {
+				return "", err
+			}
+		}
+	default:
+		blobs := []descriptor{}
+		if m.Config != nil {
+			blobs = append(blobs, *m.Config)
+		}
+		blobs = append(blobs, m.Layers...)
+		for _, blob := range blobs {
+			if len(blob.URLs) > 0 {
+				// foreign layers such as windows base layers are not stored in the registry
+				continue
+			}
+			err = o.copyBlob(src, dst, blob.Digest)
+			if err != nil {
+				return "", err
+			}
+		}
+	}
+	err = o.putManifest(dst, dstRef, mediaType, data)
+	if err != nil {
+		return "", err
+	}
+	return digest, nil
+}
+
+// getManifest returns the media type, content and digest of the manifest
+func (o *CopyOptions) getManifest(image *ImageReference, reference string) (string, []byte, string, error) {
+	u := o.url(image.Host, image.Repository, "manifests", reference)
+	header := http.Header{}
+	header.Set("Accept", strings.Join(acceptedManifests, ", "))
+	resp, err := o.do(http.MethodGet, u, header, nil, -1, image, "pull")
+	if err != nil {
+		return "", nil, "", err
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", nil, "", errors.Wrapf(err, "failed to read the manifest %s", u)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", nil, "", fmt.Errorf("failed to get the manifest %s: status %d: %s", u, resp.StatusCode, string(data))
+	}
+	mediaType := resp.Header.Get("Content-Type")
+	if idx := strings.Index(mediaType, ";"); idx > 0 {
+		mediaType = mediaType[:idx]
+	}
+	if mediaType == "" || mediaType == "application/json" {
+		m := manifest{}
+		if json.Unmarshal(data, &m) == nil && m.MediaType != "" {
+			mediaType = m.MediaType
+		}
+	}
+	digest := resp.Header.Get("Docker-Content-Digest")
+	if digest == "" {
+		digest = fmt.Sprintf("sha256:%x", sha256.Sum256(data))
+	}
+	return mediaType, data, digest, nil
+}
+
+// putManifest uploads the manifest to the tag or digest
+func (o *CopyOptions) putManifest(image *ImageReference, reference string, mediaType string, data []byte) error {
+	u := o.url(image.Host, image.Repository, "manifests", reference)
+	header := http.Header{}
+	header.Set("Content-Type", mediaType)
+	resp, err := o.do(http.MethodPut, u, header, data, int64(len(data)), image, "pull,push")
+	if err != nil {
+		return err
+	}
+	return checkResponse(resp, u, http.StatusCreated, http.StatusOK)
+}
+
+// copyBlob copies the blob unless it already exists in the destination
+func (o *CopyOptions) copyBlob(src *ImageReference, dst *ImageReference, digest string) error {
+	u := o.url(dst.Host, dst.Repository, "blobs", digest)
+	resp, err := o.do(http.MethodHead, u, nil, nil, -1, dst, "pull,push")
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	uploads := o.url(dst.Host, dst.Repository, "blobs", "uploads/")
+	if src.Host == dst.Host {
+		mount := uploads + "?mount=" + url.QueryEscape(digest) + "&from=" + url.QueryEscape(src.Repository)
+		resp, err = o.do(http.MethodPost, mount, nil, nil, 0, dst, "pull,push")
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		if resp.StatusCode == http.StatusCreated {
+			return nil
+		}
+	} else {
+		resp, err = o.do(http.MethodPost, uploads, nil, nil, 0, dst, "pull,push")
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+	}
+	if resp.StatusCode != http.StatusAccepted {
+		return fmt.Errorf("failed to start the upload of blob %s to %s: status %d", digest, dst.String(), resp.StatusCode)
+	}
+	location, err := resolveLocation(uploads, resp.Header.Get("Location"))
+	if err != nil {
+		return err
+	}
+
+	srcURL := o.url(src.Host, src.Repository, "blobs", digest)
+	blob, err := o.do(http.MethodGet, srcURL, nil, nil, -1, src, "pull")
+	if err != nil {
+		return err
+	}
+	defer blob.Body.Close()
+	if blob.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to get the blob %s: status %d", srcURL, blob.StatusCode)
+	}
+	separator := "?"
+	if strings.Contains(location, "?") {
+		separator = "&"
+	}
+	location += separator + "digest=" + url.QueryEscape(digest)
+	header := http.Header{}
+	header.Set("Content-Type", "application/octet-stream")
+	resp, err = o.do(http.MethodPut, location, header, blob.Body, blob.ContentLength, dst, "pull,push")
+	if err != nil {
+		return err
+	}
+	return checkResponse(resp, location, http.StatusCreated)
+}
+
+// do performs the request authenticating with the registry when challenged
+func (o *CopyOptions) do(method string, u string, header http.Header, body interface{}, length int64, image *ImageReference, actions string) (*http.Response, error) {
+	key := image.Host + "/" + image.Repository + ":" + actions
+	o.lock.Lock()
+	token := o.tokens[key]
+	o.lock.Unlock()
+
+	resp, err := o.send(method, u, header, body, length, token)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusUnauthorized {
+		return resp, nil
+	}
+	challenge := resp.Header.Get("WWW-Authenticate")
+	resp.Body.Close()
+	if _, ok := body.(io.Reader); ok {
+		// the body has been consumed so lets authenticate before the blob is streamed
+		return nil, fmt.Errorf("unauthorized to upload to %s", u)
+	}
+	token, err = o.authenticate(image, challenge, actions)
+	if err != nil {
+		return nil, err
+	}
+	o.lock.Lock()
+	if o.tokens == nil {
+		o.tokens = map[string]string{}
+	}
+	o.tokens[key] = token
+	o.lock.Unlock()
+	return o.send(method, u, header, body, length, token)
+}
+
+// send sends the request with the authorization header
+func (o *CopyOptions) send(method string, u string, header http.Header, body interface{}, length int64, authorization string) (*http.Response, error) {
+	var reader io.Reader
+	switch b := body.(type) {
+	case []byte:
+		reader = strings.NewReader(string(b))
+	case io.Reader:
+		reader = b
+	}
+	req, err := http.NewRequest(method, u, reader)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create the request %s %s", method, u)
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	if length >= 0 && reader != nil {
+		req.ContentLength = length
+	}
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	resp, err := o.Client.Do(req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to %s %s", method, u)
+	}
+	return resp, nil
+}
+
+// authenticate returns the authorization header for the challenge of the registry
+func (o *CopyOptions) authenticate(image *ImageReference, challenge string, actions string) (string, error) {
+	var credentials *Credentials
+	if o.Credentials != nil {
+		var err error
+		credentials, err = o.Credentials(image.Host)
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to get the credentials of registry %s", image.Host)
+		}
+	}
+	scheme, params := parseChallenge(challenge)
+	switch scheme {
+	case "basic":
+		if credentials == nil {
+			return "", fmt.Errorf("no credentials for registry %s", image.Host)
+		}
+		return "Basic " + base64.StdEncoding.EncodeToString([]byte(credentials.Username+":"+credentials.Password)), nil
+	case "bearer":
+		realm := params["realm"]
+		if realm == "" {
+			return "", fmt.Errorf("no realm in the challenge of registry %s", image.Host)
+		}
+		values := url.Values{}
+		if params["service"] != "" {
+			values.Set("service", params["service"])
+		}
+		values.Set("scope", "repository:"+image.Repository+":"+actions)
+		req, err := http.NewRequest(http.MethodGet, realm+"?"+values.Encode(), nil)
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to create the token request for registry %s", image.Host)
+		}
+		if credentials != nil {
+			req.SetBasicAuth(credentials.Username, credentials.Password)
+		}
+		resp, err := o.Client.Do(req)
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to get a token for registry %s", image.Host)
+		}
+		defer resp.Body.Close()
+		data, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to read the token of registry %s", image.Host)
+		}
+		if resp.StatusCode != http.StatusOK {
+			return "", fmt.Errorf("failed to get a token for registry %s: status %d: %s", image.Host, resp.StatusCode, string(data))
+		}
+		token := struct {
+			Token       string `json:"token"`
+			AccessToken string `json:"access_token"`
+		}{}
+		err = json.Unmarshal(data, &token)
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to parse the token of registry %s", image.Host)
+		}
+		if token.Token == "" {
+			token.Token = token.AccessToken
+		}
+		return "Bearer " + token.Token, nil
+	default:
+		return "", fmt.Errorf("unsupported authentication challenge '%s' of registry %s", challenge, image.Host)
+	}
+}
+
+// url returns the URL of the registry API
+func (o *CopyOptions) url(host string, repository string, kind string, reference string) string {
+	scheme := "https"
+	if o.Insecure {
+		scheme = "http"
+	}
+	if host == dockerHubHost {
+		host = dockerHubRegistry
+	}
+	return fmt.Sprintf("%s://%s/v2/%s/%s/%s", scheme, host, repository, kind, reference)
+}
+
+// parseChallenge parses a WWW-Authenticate header such as: Bearer realm="https://auth.docker.io/token",service="registry.docker.io"
+func parseChallenge(challenge string) (string, map[string]string) {
+	params := map[string]string{}
+	parts := strings.SplitN(strings.TrimSpace(challenge), " ", 2)
+	scheme := strings.ToLower(parts[0])
+	if len(parts) < 2 {
+		return scheme, params
+	}
+	for _, param := range strings.Split(parts[1], ",") {
+		values := strings.SplitN(strings.TrimSpace(param), "=", 2)
+		if len(values) == 2 {
+			params[strings.ToLower(values[0])] = strings.Trim(values[1], `"`)
+		}
+	}
+	return scheme, params
+}
+
+// resolveLocation resolves the upload location which may be relative to the registry
+func resolveLocation(base string, location string) (string, error) {
+	if location == "" {
+		return "", fmt.Errorf("no upload location returned by %s", base)
+	}
+	b, err := url.Parse(base)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to parse URL %s", base)
+	}
+	l, err := url.Parse(location)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to parse the upload location %s", location)
+	}
+	return b.ResolveReference(l).String(), nil
+}
+
+// checkResponse returns an error if the response does not have one of the expected status codes
+func checkResponse(resp *http.Response, u string, codes ...int) error {
+	defer resp.Body.Close()
+	for _, code := range codes {
+		if resp.StatusCode == code {
+			return nil
+		}
+	}
+	data, _ := ioutil.ReadAll(resp.Body)
+	return fmt.Errorf("unexpected status %d from %s: %s", resp.StatusCode, u, string(data))
+}
+
+// DefaultCredentials returns the credentials of registry hosts using the identity of the caller for cloud registries
+// falling back to the docker config file
+func DefaultCredentials(region string) func(host string) (*Credentials, error) {
+	return func(host string) (*Credentials, error) {
+		reg := NewRegistry(host, region)
+		credentials, err := reg.Credentials()
+		if err != nil {
+			log.Logger().Warnf("Failed to get the credentials of registry %s using the %s so using the docker config: %s", host, reg.Identity(), err)
+		}
+		if credentials != nil {
+			return credentials, nil
+		}
+		return DockerConfigCredentials(DockerConfigFile(), host)
+	}
+}
+
+// DockerConfigCredentials returns the credentials of the registry host from the docker config file or nil if there
+// are none
+func DockerConfigCredentials(fileName string, host string) (*Credentials, error) {
+	exists, err := util.FileExists(fileName)
+	if err != nil || !exists {
+		return nil, err
+	}
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read the docker config %s", fileName)
+	}
+	config := struct {
+		Auths map[string]struct {
+			Auth string `json:"auth"`
+		} `json:"auths"`
+	}{}
+	err = json.Unmarshal(data, &config)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse the docker config %s", fileName)
+	}
+	for key, auth := range config.Auths {
+		if key != host && strings.TrimPrefix(strings.TrimPrefix(key, "https://"), "http://") != host {
+			continue
+		}
+		decoded, err := base64.StdEncoding.DecodeString(auth.Auth)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to decode the auth of registry %s in %s", host, fileName)
+		}
+		values := strings.SplitN(string(decoded), ":", 2)
+		if len(values) == 2 {
+			return &Credentials{Username: values[0], Password: values[1]}, nil
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,147 @@
+package registry_test
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/jenkins-x/jx/pkg/registry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRegistry an in memory registry implementing the parts of the registry API used to copy images
+type fakeRegistry struct {
+	lock      sync.Mutex
+	user      string
+	blobs     map[string][]byte
+	manifests map[string][]byte
+	uploads   int
+}
+
+func newFakeRegistry(user string) *fakeRegistry {
+	return &fakeRegistry{user: user, blobs: map[string][]byte{}, manifests: map[string][]byte{}}
+}
+
+func (f *fakeRegistry) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if f.user != "" {
+		user, _, ok := r.BasicAuth()
+		if !ok || user != f.user {
+			w.Header().Set("WWW-Authenticate", `Basic realm="test"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+	}
+	path := strings.TrimPrefix(r.URL.Path, "/v2/")
+	switch {
+	case strings.Contains(path, "/blobs/uploads/"):
+		if r.Method == http.MethodPost {
+			w.Header().Set("Location", "/v2/"+path+"session")
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		data, _ := ioutil.ReadAll(r.Body)
+		f.blobs[r.URL.Query().Get("digest")] = data
+		f.uploads++
+		w.WriteHeader(http.StatusCreated)
+	case strings.Contains(path, "/blobs/"):
+		digest := path[strings.LastIndex(path, "/")+1:]
+		data, ok := f.blobs[digest]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.Method == http.MethodGet {
+			w.Write(data)
+		}
+	case strings.Contains(path, "/manifests/"):
+		if r.Method == http.MethodPut {
+			data, _ := ioutil.ReadAll(r.Body)
+			f.manifests[path] = data
+			w.WriteHeader(http.StatusCreated)
+			return
+		}
+		data, ok := f.manifests[path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/vnd.docker.distribution.manifest.v2+json")
+		w.Write(data)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func digestOf(data string) string {
+	return fmt.Sprintf("sha256:%x", sha256.Sum256([]byte(data)))
+}
+
+func TestCopyImage(t *testing.T) {
+	t.Parallel()
+
+	config := `{"architecture": "amd64"}`
+	layer := "layer-content"
+	existing := "existing-layer"
+	manifest := fmt.Sprintf(`{"schemaVersion": 2, "mediaType": "application/vnd.docker.distribution.manifest.v2+json", "config": {"digest": "%s"}, "layers": [{"digest": "%s"}, {"digest": "%s"}]}`,
+		digestOf(config), digestOf(layer), digestOf(existing))
+
+	source := newFakeRegistry("")
+	source.blobs[digestOf(config)] = []byte(config)
+	source.blobs[digestOf(layer)] = []byte(layer)
+	source.blobs[digestOf(existing)] = []byte(existing)
+	source.manifests["acme/myapp/manifests/1.2.3"] = []byte(manifest)
+	sourceServer := httptest.NewServer(source)
+	defer sourceServer.Close()
+
+	target := newFakeRegistry("prod")
+	target.blobs[digestOf(existing)] = []byte(existing)
+	targetServer := httptest.NewServer(target)
+	defer targetServer.Close()
+
+	src, err := registry.ParseImageReference(strings.TrimPrefix(sourceServer.URL, "http://") + "/acme/myapp:1.2.3")
+	require.NoError(t, err)
+	dst, err := registry.ParseImageReference(strings.TrimPrefix(targetServer.URL, "http://") + "/prod/myapp:1.2.3")
+	require.NoError(t, err)
+
+	o := &registry.CopyOptions{
+		Insecure: true,
+		Credentials: func(host string) (*registry.Credentials, error) {
+			return &registry.Credentials{Username: "prod", Password: "s3cr3t"}, nil
+		},
+	}
+	digest, err := o.Copy(src, dst)
+	require.NoError(t, err)
+
+	assert.Equal(t, digestOf(manifest), digest)
+	assert.Equal(t, manifest, string(target.manifests["prod/myapp/manifests/1.2.3"]))
+	assert.Equal(t, layer, string(target.blobs[digestOf(layer)]))
+	assert.Equal(t, config, string(target.blobs[digestOf(config)]))
+	assert.Equal(t, 2, target.uploads, "should not upload the layer which already exists")
+}
+
+func TestParseImageReference(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		image    string
+		expected registry.ImageReference
+	}{
+		{"gcr.io/acme/myapp:1.2.3", registry.ImageReference{Host: "gcr.io", Repository: "acme/myapp", Reference: "1.2.3"}},
+		{"localhost:5000/myapp", registry.ImageReference{Host: "localhost:5000", Repository: "myapp", Reference: "latest"}},
+		{"golang:1.13", registry.ImageReference{Host: "docker.io", Repository: "library/golang", Reference: "1.13"}},
+		{"acme.azurecr.io/myapp@sha256:abc", registry.ImageReference{Host: "acme.azurecr.io", Repository: "myapp", Reference: "sha256:abc"}},
+	}
+	for _, tc := range testCases {
+		actual, err := registry.ParseImageReference(tc.image)
+		require.NoError(t, err, "parsing %s", tc.image)
+		assert.Equal(t, tc.expected, *actual, "parsing %s", tc.image)
+	}
+}
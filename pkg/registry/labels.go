@@ -0,0 +1,77 @@
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// RevisionLabel the standard OCI label of the source control revision an image was built from
+	RevisionLabel = "org.opencontainers.image.revision"
+)
+
+// imageConfig the parts of the image configuration blob containing the labels
+type imageConfig struct {
+	Config struct {
+		Labels map[string]string `json:"Labels,omitempty"`
+	} `json:"config"`
+}
+
+// Labels returns the labels of the image. For multi platform images the labels of the first platform are returned
+// as they are built from the same source
+func (o *CopyOptions) Labels(image *ImageReference) (map[string]string, error) {
+	if o.Client == nil {
+		o.Client = &http.Client{}
+	}
+	reference := image.Reference
+	for {
+		mediaType, data, _, err := o.getManifest(image, reference)
+		if err != nil {
+			return nil, err
+		}
+		m := manifest{}
+		err = json.Unmarshal(data, &m)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse the manifest of %s", image.String())
+		}
+		switch mediaType {
+		case mediaTypeDockerManifestList, mediaTypeOCIIndex:
+			if len(m.Manifests) == 0 {
+				return nil, fmt.Errorf("the image index %s has no manifests", image.String())
+			}
+			reference = m.Manifests[0].Digest
+			continue
+		}
+		if m.Config == nil {
+			return nil, fmt.Errorf("the manifest of %s has no configuration", image.String())
+		}
+		return o.configLabels(image, m.Config.Digest)
+	}
+}
+
+// configLabels returns the labels of the image configuration blob
+func (o *CopyOptions) configLabels(image *ImageReference, digest string) (map[string]string, error) {
+	u := o.url(image.Host, image.Repository, "blobs", digest)
+	resp, err := o.do(http.MethodGet, u, nil, nil, -1, image, "pull")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read the image configuration %s", u)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to get the image configuration %s: status %d", u, resp.StatusCode)
+	}
+	config := imageConfig{}
+	err = json.Unmarshal(data, &config)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse the image configuration of %s", image.String())
+	}
+	return config.Config.Labels, nil
+}
@@ -0,0 +1,34 @@
+package registry_test
+
+import (
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jenkins-x/jx/pkg/registry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImageLabels(t *testing.T) {
+	t.Parallel()
+
+	config := `{"architecture": "amd64", "config": {"Labels": {"org.opencontainers.image.revision": "abc123"}}}`
+	manifest := fmt.Sprintf(`{"schemaVersion": 2, "mediaType": "application/vnd.docker.distribution.manifest.v2+json", "config": {"digest": "%s"}, "layers": []}`,
+		digestOf(config))
+
+	source := newFakeRegistry("")
+	source.blobs[digestOf(config)] = []byte(config)
+	source.manifests["acme/myapp/manifests/1.2.3"] = []byte(manifest)
+	server := httptest.NewServer(source)
+	defer server.Close()
+
+	image, err := registry.ParseImageReference(strings.TrimPrefix(server.URL, "http://") + "/acme/myapp:1.2.3")
+	require.NoError(t, err)
+
+	o := &registry.CopyOptions{Insecure: true}
+	labels, err := o.Labels(image)
+	require.NoError(t, err)
+	assert.Equal(t, "abc123", labels[registry.RevisionLabel])
+}
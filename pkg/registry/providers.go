@@ -0,0 +1,182 @@
+package registry
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/jenkins-x/jx/pkg/cloud/amazon"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/util"
+)
+
+const (
+	// garUser the user name of an access token for Google registries
+	garUser = "oauth2accesstoken"
+	// acrUser the user name of an access token for Azure Container Registry
+	acrUser = "00000000-0000-0000-0000-000000000000"
+)
+
+// ecrRegistry an AWS Elastic Container Registry which requires repositories to be created before pushing and whose
+// tokens expire after 12 hours
+type ecrRegistry struct {
+	host   string
+	region string
+}
+
+func (r *ecrRegistry) Kind() Kind {
+	return KindECR
+}
+
+func (r *ecrRegistry) Host() string {
+	return r.host
+}
+
+func (r *ecrRegistry) EnsureRepository(image string) error {
+	_, repo := SplitImage(image)
+	org := ""
+	app := repo
+	if idx := strings.LastIndex(repo, "/"); idx > 0 {
+		org = repo[:idx]
+		app = repo[idx+1:]
+	}
+	return amazon.LazyCreateRegistry(nil, "", r.region, r.host, org, app)
+}
+
+func (r *ecrRegistry) Credentials() (*Credentials, error) {
+	username, password, err := amazon.GetECRAuthorizationToken(r.region)
+	if err != nil {
+		return nil, err
+	}
+	return &Credentials{Username: username, Password: password}, nil
+}
+
+func (r *ecrRegistry) Identity() string {
+	if os.Getenv("AWS_WEB_IDENTITY_TOKEN_FILE") != "" {
+		return "IAM role for service account"
+	}
+	return "AWS credentials"
+}
+
+// acrRegistry an Azure Container Registry which creates repositories on push
+type acrRegistry struct {
+	host string
+}
+
+func (r *acrRegistry) Kind() Kind {
+	return KindACR
+}
+
+func (r *acrRegistry) Host() string {
+	return r.host
+}
+
+func (r *acrRegistry) EnsureRepository(image string) error {
+	return nil
+}
+
+func (r *acrRegistry) Credentials() (*Credentials, error) {
+	_, err := runCommand("az", "account", "show")
+	if err != nil {
+		log.Logger().Debugf("not logged into azure so logging in with the managed identity: %s", err)
+		args := []string{"login", "--identity"}
+		if clientID := os.Getenv("AZURE_CLIENT_ID"); clientID != "" {
+			args = append(args, "--username", clientID)
+		}
+		_, err = runCommand("az", args...)
+		if err != nil {
+			return nil, err
+		}
+	}
+	name := strings.Split(r.host, ".")[0]
+	token, err := runCommand("az", "acr", "login", "--name", name, "--expose-token", "--output", "tsv", "--query", "accessToken")
+	if err != nil {
+		return nil, err
+	}
+	return &Credentials{Username: acrUser, Password: token}, nil
+}
+
+func (r *acrRegistry) Identity() string {
+	return "managed identity"
+}
+
+// garRegistry a Google Artifact Registry which requires repositories to be created before pushing
+type garRegistry struct {
+	host string
+}
+
+func (r *garRegistry) Kind() Kind {
+	return KindGAR
+}
+
+func (r *garRegistry) Host() string {
+	return r.host
+}
+
+func (r *garRegistry) EnsureRepository(image string) error {
+	location, project, repo, err := r.repository(image)
+	if err != nil {
+		return err
+	}
+	args := []string{"--project", project, "--location", location}
+	_, err = runCommand("gcloud", append([]string{"artifacts", "repositories", "describe", repo}, args...)...)
+	if err == nil {
+		return nil
+	}
+	log.Logger().Infof("Creating the Artifact Registry repository %s in project %s", util.ColorInfo(repo), util.ColorInfo(project))
+	_, err = runCommand("gcloud", append([]string{"artifacts", "repositories", "create", repo, "--repository-format", "docker"}, args...)...)
+	return err
+}
+
+// repository returns the location, project and repository of the image
+func (r *garRegistry) repository(image string) (string, string, string, error) {
+	_, path := SplitImage(image)
+	paths := strings.Split(path, "/")
+	if len(paths) < 3 {
+		return "", "", "", fmt.Errorf("the Artifact Registry image %s should be of the form %s/project/repository/image", image, r.host)
+	}
+	return strings.TrimSuffix(r.host, "-docker.pkg.dev"), paths[0], paths[1], nil
+}
+
+func (r *garRegistry) Credentials() (*Credentials, error) {
+	return googleCredentials()
+}
+
+func (r *garRegistry) Identity() string {
+	return "workload identity"
+}
+
+// gcrRegistry a Google Container Registry which creates repositories on push
+type gcrRegistry struct {
+	host string
+}
+
+func (r *gcrRegistry) Kind() Kind {
+	return KindGCR
+}
+
+func (r *gcrRegistry) Host() string {
+	return r.host
+}
+
+func (r *gcrRegistry) EnsureRepository(image string) error {
+	return nil
+}
+
+func (r *gcrRegistry) Credentials() (*Credentials, error) {
+	return googleCredentials()
+}
+
+func (r *gcrRegistry) Identity() string {
+	return "workload identity"
+}
+
+// googleCredentials returns an access token of the identity of the caller which is the workload identity of the
+// service account in the build pods
+func googleCredentials() (*Credentials, error) {
+	token, err := runCommand("gcloud", "auth", "print-access-token")
+	if err != nil {
+		return nil, err
+	}
+	return &Credentials{Username: garUser, Password: token}, nil
+}
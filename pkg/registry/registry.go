@@ -0,0 +1,214 @@
+package registry
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+)
+
+// Kind the kind of container registry
+type Kind string
+
+const (
+	// KindDocker a registry which uses the docker credentials of the team such as docker hub or the in cluster registry
+	KindDocker Kind = "docker"
+	// KindECR AWS Elastic Container Registry
+	KindECR Kind = "ecr"
+	// KindACR Azure Container Registry
+	KindACR Kind = "acr"
+	// KindGAR Google Artifact Registry
+	KindGAR Kind = "gar"
+	// KindGCR Google Container Registry
+	KindGCR Kind = "gcr"
+)
+
+var ecrHostRegex = regexp.MustCompile(`^[0-9]+\.dkr\.ecr\.([a-z0-9-]+)\.amazonaws\.com(\.cn)?$`)
+
+// Credentials the credentials used to push images to a registry
+type Credentials struct {
+	Username string
+	Password string
+}
+
+// Registry a container registry the pipelines push images to
+type Registry interface {
+	// Kind returns the kind of registry
+	Kind() Kind
+	// Host returns the host name of the registry
+	Host() string
+	// EnsureRepository creates the repository of the image if the registry requires repositories to be created
+	// before pushing
+	EnsureRepository(image string) error
+	// Credentials returns short lived credentials to push to the registry using the identity of the caller or nil
+	// if the registry uses the docker credentials of the team
+	Credentials() (*Credentials, error)
+	// Identity describes how the registry is authenticated
+	Identity() string
+}
+
+// KindFromHost returns the kind of registry from its host name
+func KindFromHost(host string) Kind {
+	host = strings.ToLower(host)
+	switch {
+	case ecrHostRegex.MatchString(host):
+		return KindECR
+	case strings.HasSuffix(host, ".azurecr.io"):
+		return KindACR
+	case strings.HasSuffix(host, "-docker.pkg.dev"):
+		return KindGAR
+	case host == "gcr.io" || strings.HasSuffix(host, ".gcr.io"):
+		return KindGCR
+	default:
+		return KindDocker
+	}
+}
+
+// RequiresRepositories returns true if the kind of registry requires the repositories of images to be created before
+// pushing them
+func RequiresRepositories(kind Kind) bool {
+	return kind == KindECR || kind == KindGAR
+}
+
+// ECRRegion returns the region of an ECR registry host
+func ECRRegion(host string) string {
+	submatch := ecrHostRegex.FindStringSubmatch(strings.ToLower(host))
+	if len(submatch) > 1 {
+		return submatch[1]
+	}
+	return ""
+}
+
+// NewRegistry creates the registry for the host. The region is used for ECR registries when it cannot be found from
+// the host name
+func NewRegistry(host string, region string) Registry {
+	switch KindFromHost(host) {
+	case KindECR:
+		if r := ECRRegion(host); r != "" {
+			region = r
+		}
+		return &ecrRegistry{host: host, region: region}
+	case KindACR:
+		return &acrRegistry{host: host}
+	case KindGAR:
+		return &garRegistry{host: host}
+	case KindGCR:
+		return &gcrRegistry{host: host}
+	default:
+		return &dockerRegistry{host: host}
+	}
+}
+
+// SplitImage splits an image name into the host of the registry and the repository without any tag or digest
+func SplitImage(image string) (string, string) {
+	if idx := strings.Index(image, "@"); idx > 0 {
+		image = image[:idx]
+	}
+	host := ""
+	paths := strings.SplitN(image, "/", 2)
+	if len(paths) == 2 && strings.ContainsAny(paths[0], ".:") {
+		host = paths[0]
+		image = paths[1]
+	}
+	if idx := strings.LastIndex(image, ":"); idx > 0 && !strings.Contains(image[idx:], "/") {
+		image = image[:idx]
+	}
+	return host, strings.ToLower(image)
+}
+
+// DockerConfigFile returns the docker config file the credentials are written to
+func DockerConfigFile() string {
+	dir := os.Getenv("DOCKER_CONFIG")
+	if dir == "" {
+		dir = filepath.Join(util.HomeDir(), ".docker")
+	}
+	return filepath.Join(dir, "config.json")
+}
+
+// WriteDockerConfig adds the credentials of the registry host to the docker config file keeping any other entries
+func WriteDockerConfig(fileName string, host string, credentials *Credentials) error {
+	config := map[string]interface{}{}
+	exists, err := util.FileExists(fileName)
+	if err != nil {
+		return errors.Wrapf(err, "failed to check if file %s exists", fileName)
+	}
+	if exists {
+		data, err := ioutil.ReadFile(fileName)
+		if err != nil {
+			return errors.Wrapf(err, "failed to read the docker config %s", fileName)
+		}
+		if len(data) > 0 {
+			err = json.Unmarshal(data, &config)
+			if err != nil {
+				return errors.Wrapf(err, "failed to parse the docker config %s", fileName)
+			}
+		}
+	}
+	auths, ok := config["auths"].(map[string]interface{})
+	if !ok {
+		auths = map[string]interface{}{}
+	}
+	auths[host] = map[string]interface{}{
+		"auth": base64.StdEncoding.EncodeToString([]byte(credentials.Username + ":" + credentials.Password)),
+	}
+	config["auths"] = auths
+
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal the docker config")
+	}
+	err = os.MkdirAll(filepath.Dir(fileName), util.DefaultWritePermissions)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create the directory of the docker config %s", fileName)
+	}
+	err = ioutil.WriteFile(fileName, data, 0600)
+	if err != nil {
+		return errors.Wrapf(err, "failed to write the docker config %s", fileName)
+	}
+	return nil
+}
+
+// dockerRegistry a registry using the docker credentials of the team
+type dockerRegistry struct {
+	host string
+}
+
+func (r *dockerRegistry) Kind() Kind {
+	return KindDocker
+}
+
+func (r *dockerRegistry) Host() string {
+	return r.host
+}
+
+func (r *dockerRegistry) EnsureRepository(image string) error {
+	return nil
+}
+
+func (r *dockerRegistry) Credentials() (*Credentials, error) {
+	return nil, nil
+}
+
+func (r *dockerRegistry) Identity() string {
+	return "docker config secret"
+}
+
+// runCommand runs the command returning its trimmed output
+func runCommand(name string, args ...string) (string, error) {
+	cmd := util.Command{
+		Name: name,
+		Args: args,
+	}
+	out, err := cmd.RunWithoutRetry()
+	if err != nil {
+		return "", fmt.Errorf("%s %s failed: %s", name, strings.Join(args, " "), err)
+	}
+	return strings.TrimSpace(out), nil
+}
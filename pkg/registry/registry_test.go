@@ -0,0 +1,85 @@
+package registry_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x/jx/pkg/registry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKindFromHost(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]registry.Kind{
+		"123456789012.dkr.ecr.eu-west-1.amazonaws.com": registry.KindECR,
+		"acme.azurecr.io":             registry.KindACR,
+		"europe-west1-docker.pkg.dev": registry.KindGAR,
+		"gcr.io":                      registry.KindGCR,
+		"eu.gcr.io":                   registry.KindGCR,
+		"docker.io":                   registry.KindDocker,
+		"10.0.0.1:5000":               registry.KindDocker,
+	}
+	for host, expected := range testCases {
+		assert.Equal(t, expected, registry.KindFromHost(host), "kind of host %s", host)
+	}
+	assert.Equal(t, "eu-west-1", registry.ECRRegion("123456789012.dkr.ecr.eu-west-1.amazonaws.com"))
+	assert.Equal(t, "", registry.ECRRegion("gcr.io"))
+}
+
+func TestSplitImage(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		image        string
+		expectedHost string
+		expectedRepo string
+	}{
+		{"gcr.io/acme/myapp:1.2.3", "gcr.io", "acme/myapp"},
+		{"europe-west1-docker.pkg.dev/project/repo/myapp@sha256:abc", "europe-west1-docker.pkg.dev", "project/repo/myapp"},
+		{"localhost:5000/myapp", "localhost:5000", "myapp"},
+		{"acme/MyApp:latest", "", "acme/myapp"},
+	}
+	for _, tc := range testCases {
+		host, repo := registry.SplitImage(tc.image)
+		assert.Equal(t, tc.expectedHost, host, "host of image %s", tc.image)
+		assert.Equal(t, tc.expectedRepo, repo, "repository of image %s", tc.image)
+	}
+}
+
+func TestWriteDockerConfig(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "test-docker-config-")
+	require.NoError(t, err)
+	fileName := filepath.Join(dir, "config.json")
+	err = ioutil.WriteFile(fileName, []byte(`{"auths": {"docker.io": {"auth": "abc"}}, "credHelpers": {"gcr.io": "gcloud"}}`), 0600)
+	require.NoError(t, err)
+
+	err = registry.WriteDockerConfig(fileName, "acme.azurecr.io", &registry.Credentials{Username: "user", Password: "token"})
+	require.NoError(t, err)
+
+	data, err := ioutil.ReadFile(fileName)
+	require.NoError(t, err)
+	config := map[string]interface{}{}
+	require.NoError(t, json.Unmarshal(data, &config))
+	assert.Equal(t, map[string]interface{}{
+		"auths": map[string]interface{}{
+			"docker.io":       map[string]interface{}{"auth": "abc"},
+			"acme.azurecr.io": map[string]interface{}{"auth": "dXNlcjp0b2tlbg=="},
+		},
+		"credHelpers": map[string]interface{}{"gcr.io": "gcloud"},
+	}, config)
+}
+
+func TestRegistryRequiresRepositories(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, registry.RequiresRepositories(registry.NewRegistry("123456789012.dkr.ecr.us-east-1.amazonaws.com", "").Kind()))
+	assert.True(t, registry.RequiresRepositories(registry.NewRegistry("us-docker.pkg.dev", "").Kind()))
+	assert.False(t, registry.RequiresRepositories(registry.NewRegistry("acme.azurecr.io", "").Kind()))
+	assert.False(t, registry.RequiresRepositories(registry.NewRegistry("docker.io", "").Kind()))
+}
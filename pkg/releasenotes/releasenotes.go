@@ -0,0 +1,386 @@
+package releasenotes
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/config"
+	"github.com/jenkins-x/jx/pkg/gits"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+)
+
+const (
+	// DefaultBranch the default branch the release notes are committed to
+	DefaultBranch = "gh-pages"
+	// DefaultPath the default path of the file the release notes are committed to
+	DefaultPath = "releases/{{ .Version }}.md"
+	// DefaultTitle the default title of the Confluence page of the release notes
+	DefaultTitle = "{{ .Name }} {{ .Version }} release notes"
+
+	defaultConfluenceUserEnv  = "CONFLUENCE_USER"
+	defaultConfluenceTokenEnv = "CONFLUENCE_TOKEN"
+)
+
+// DefaultTemplates the default Go templates of the release notes for each kind of target
+var DefaultTemplates = map[string]string{
+	config.ReleaseNotesTargetRelease:    `{{ .Markdown }}`,
+	config.ReleaseNotesTargetSlack:      `Released {{ .Name }} version {{ .Version }}{{ if .URL }}: {{ .URL }}{{ end }}{{ "\n\n" }}{{ .Markdown }}`,
+	config.ReleaseNotesTargetConfluence: `<p>Released {{ .Name }} version {{ .Version }}{{ if .URL }} <a href="{{ .URL }}">{{ .URL }}</a>{{ end }}</p><pre>{{ html .Markdown }}</pre>`,
+	config.ReleaseNotesTargetBranch:     "---\ntitle: \"{{ .Name }} {{ .Version }}\"\n---\n\n{{ .Markdown }}",
+}
+
+// Notes the release notes passed to the templates of the targets
+type Notes struct {
+	// Name the name of the application
+	Name string
+	// Version the version of the release
+	Version string
+	// TagName the git tag of the release
+	TagName string
+	// URL the URL of the release
+	URL string
+	// Markdown the generated changelog
+	Markdown string
+	// Release the release resource
+	Release *v1.ReleaseSpec
+}
+
+// Publisher publishes release notes to a target
+type Publisher interface {
+	// Publish publishes the release notes and returns the URL they were published to if there is one
+	Publish(notes *Notes) (string, error)
+}
+
+// Render renders the template of the target or the default template for the kind of target
+func Render(target *config.ReleaseNotesTarget, notes *Notes) (string, error) {
+	text := target.Template
+	if text == "" {
+		text = DefaultTemplates[target.Kind]
+	}
+	return renderTemplate(target.Name, text, notes)
+}
+
+func renderTemplate(name string, text string, notes *Notes) (string, error) {
+	tmpl, err := template.New(name).Funcs(template.FuncMap{
+		"html": html.EscapeString,
+	}).Parse(text)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to parse the template of target %s", name)
+	}
+	var buffer bytes.Buffer
+	err = tmpl.Execute(&buffer, notes)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to render the template of target %s", name)
+	}
+	return buffer.String(), nil
+}
+
+// NewPublisher creates the publisher for the given target. The release target is published by `jx step changelog`
+// itself as part of the release so has no publisher
+func NewPublisher(target *config.ReleaseNotesTarget, gitter gits.Gitter, defaultRepository string) (Publisher, error) {
+	switch target.Kind {
+	case config.ReleaseNotesTargetSlack:
+		return &SlackPublisher{Target: target}, nil
+	case config.ReleaseNotesTargetConfluence:
+		return &ConfluencePublisher{Target: target}, nil
+	case config.ReleaseNotesTargetBranch:
+		repository := target.Repository
+		if repository == "" {
+			repository = defaultRepository
+		}
+		if repository == "" {
+			return nil, fmt.Errorf("target %s has no repository", target.Name)
+		}
+		return &BranchPublisher{Target: target, Gitter: gitter, Repository: repository}, nil
+	default:
+		return nil, util.InvalidOption("kind", target.Kind, []string{config.ReleaseNotesTargetSlack, config.ReleaseNotesTargetConfluence, config.ReleaseNotesTargetBranch})
+	}
+}
+
+func httpClient(client *http.Client) *http.Client {
+	if client == nil {
+		return util.GetClientWithTimeout(30 * time.Second)
+	}
+	return client
+}
+
+func checkResponse(resp *http.Response) error {
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("status %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+// SlackPublisher posts the release notes to a Slack incoming webhook
+type SlackPublisher struct {
+	Target *config.ReleaseNotesTarget
+	Client *http.Client
+}
+
+// Publish posts the release notes to the Slack webhook
+func (p *SlackPublisher) Publish(notes *Notes) (string, error) {
+	text, err := Render(p.Target, notes)
+	if err != nil {
+		return "", err
+	}
+	data, err := json.Marshal(map[string]string{
+		"text": text,
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "failed to marshal the Slack message")
+	}
+	resp, err := httpClient(p.Client).Post(p.Target.TargetURL(), "application/json", bytes.NewReader(data))
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to post the release notes to target %s", p.Target.Name)
+	}
+	defer resp.Body.Close()
+	err = checkResponse(resp)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to post the release notes to target %s", p.Target.Name)
+	}
+	return "", nil
+}
+
+// ConfluencePublisher creates or updates a Confluence page with the release notes
+type ConfluencePublisher struct {
+	Target *config.ReleaseNotesTarget
+	Client *http.Client
+}
+
+type confluenceContent struct {
+	ID        string                 `json:"id,omitempty"`
+	Type      string                 `json:"type"`
+	Title     string                 `json:"title"`
+	Space     *confluenceSpace       `json:"space,omitempty"`
+	Ancestors []confluenceAncestor   `json:"ancestors,omitempty"`
+	Version   *confluenceVersion     `json:"version,omitempty"`
+	Body      *confluenceContentBody `json:"body,omitempty"`
+	Links     *confluenceLinks       `json:"_links,omitempty"`
+}
+
+type confluenceSpace struct {
+	Key string `json:"key"`
+}
+
+type confluenceAncestor struct {
+	ID string `json:"id"`
+}
+
+type confluenceVersion struct {
+	Number int `json:"number"`
+}
+
+type confluenceContentBody struct {
+	Storage confluenceStorage `json:"storage"`
+}
+
+type confluenceStorage struct {
+	Value          string `json:"value"`
+	Representation string `json:"representation"`
+}
+
+type confluenceLinks struct {
+	Base  string `json:"base,omitempty"`
+	WebUI string `json:"webui,omitempty"`
+}
+
+type confluenceSearchResult struct {
+	Results []confluenceContent `json:"results"`
+}
+
+// Publish creates the Confluence page of the release notes or updates it if it already exists
+func (p *ConfluencePublisher) Publish(notes *Notes) (string, error) {
+	target := p.Target
+	body, err := Render(target, notes)
+	if err != nil {
+		return "", err
+	}
+	titleTemplate := target.Title
+	if titleTemplate == "" {
+		titleTemplate = DefaultTitle
+	}
+	title, err := renderTemplate(target.Name, titleTemplate, notes)
+	if err != nil {
+		return "", err
+	}
+	baseURL := strings.TrimSuffix(target.TargetURL(), "/")
+
+	query := url.Values{}
+	query.Set("spaceKey", target.Space)
+	query.Set("title", title)
+	query.Set("expand", "version")
+	search := &confluenceSearchResult{}
+	err = p.send(http.MethodGet, baseURL+"/rest/api/content?"+query.Encode(), nil, search)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to find the Confluence page %s", title)
+	}
+
+	content := &confluenceContent{
+		Type:  "page",
+		Title: title,
+		Space: &confluenceSpace{Key: target.Space},
+		Body: &confluenceContentBody{
+			Storage: confluenceStorage{
+				Value:          body,
+				Representation: "storage",
+			},
+		},
+	}
+	if target.ParentID != "" {
+		content.Ancestors = []confluenceAncestor{{ID: target.ParentID}}
+	}
+	result := &confluenceContent{}
+	if len(search.Results) > 0 {
+		existing := search.Results[0]
+		version := 1
+		if existing.Version != nil {
+			version = existing.Version.Number + 1
+		}
+		content.ID = existing.ID
+		content.Version = &confluenceVersion{Number: version}
+		err = p.send(http.MethodPut, baseURL+"/rest/api/content/"+existing.ID, content, result)
+	} else {
+		err = p.send(http.MethodPost, baseURL+"/rest/api/content", content, result)
+	}
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to publish the Confluence page %s", title)
+	}
+	if result.Links == nil || result.Links.WebUI == "" {
+		return "", nil
+	}
+	base := result.Links.Base
+	if base == "" {
+		base = baseURL
+	}
+	return util.UrlJoin(base, result.Links.WebUI), nil
+}
+
+func (p *ConfluencePublisher) send(method string, u string, body interface{}, result interface{}) error {
+	var reader *bytes.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return errors.Wrap(err, "failed to marshal the Confluence content")
+		}
+		reader = bytes.NewReader(data)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+	req, err := http.NewRequest(method, u, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	userEnv := p.Target.UsernameFromEnv
+	if userEnv == "" {
+		userEnv = defaultConfluenceUserEnv
+	}
+	tokenEnv := p.Target.TokenFromEnv
+	if tokenEnv == "" {
+		tokenEnv = defaultConfluenceTokenEnv
+	}
+	user := os.Getenv(userEnv)
+	if user != "" {
+		req.SetBasicAuth(user, os.Getenv(tokenEnv))
+	}
+	resp, err := httpClient(p.Client).Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	err = checkResponse(resp)
+	if err != nil {
+		return err
+	}
+	return json.NewDecoder(resp.Body).Decode(result)
+}
+
+// BranchPublisher commits the release notes to a branch of a git repository such as the gh-pages branch of a
+// static docs site
+type BranchPublisher struct {
+	Target     *config.ReleaseNotesTarget
+	Gitter     gits.Gitter
+	Repository string
+}
+
+// Publish clones the repository, writes the release notes to the path of the target and pushes the branch
+func (p *BranchPublisher) Publish(notes *Notes) (string, error) {
+	target := p.Target
+	text, err := Render(target, notes)
+	if err != nil {
+		return "", err
+	}
+	pathTemplate := target.Path
+	if pathTemplate == "" {
+		pathTemplate = DefaultPath
+	}
+	path, err := renderTemplate(target.Name, pathTemplate, notes)
+	if err != nil {
+		return "", err
+	}
+	branch := target.Branch
+	if branch == "" {
+		branch = DefaultBranch
+	}
+
+	dir, err := ioutil.TempDir("", "release-notes-")
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create a temporary directory")
+	}
+	defer os.RemoveAll(dir)
+
+	err = p.Gitter.Clone(p.Repository, dir)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to clone %s", p.Repository)
+	}
+	err = p.Gitter.CheckoutRemoteBranch(dir, branch)
+	if err != nil {
+		err = p.Gitter.CheckoutOrphan(dir, branch)
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to create the branch %s", branch)
+		}
+		err = p.Gitter.RemoveForce(dir, ".")
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to remove the files of the new branch %s", branch)
+		}
+	}
+
+	fileName := filepath.Join(dir, path)
+	err = os.MkdirAll(filepath.Dir(fileName), util.DefaultWritePermissions)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to create the directory of %s", fileName)
+	}
+	err = ioutil.WriteFile(fileName, []byte(text), util.DefaultWritePermissions)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to write %s", fileName)
+	}
+	err = p.Gitter.Add(dir, path)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to add %s", path)
+	}
+	err = p.Gitter.CommitIfChanges(dir, fmt.Sprintf("release notes of %s %s", notes.Name, notes.Version))
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to commit the release notes to %s", branch)
+	}
+	err = p.Gitter.Push(dir, "origin", false, "HEAD:"+branch)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to push the branch %s to %s", branch, p.Repository)
+	}
+	return "", nil
+}
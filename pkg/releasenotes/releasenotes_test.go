@@ -0,0 +1,100 @@
+package releasenotes_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jenkins-x/jx/pkg/config"
+	"github.com/jenkins-x/jx/pkg/releasenotes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testNotes = &releasenotes.Notes{
+	Name:     "myapp",
+	Version:  "1.2.3",
+	TagName:  "v1.2.3",
+	URL:      "https://github.com/myorg/myapp/releases/tag/v1.2.3",
+	Markdown: "### Bug Fixes\n\n* fix <things>",
+}
+
+func TestRender(t *testing.T) {
+	t.Parallel()
+
+	text, err := releasenotes.Render(&config.ReleaseNotesTarget{Name: "github", Kind: config.ReleaseNotesTargetRelease}, testNotes)
+	require.NoError(t, err)
+	assert.Equal(t, testNotes.Markdown, text)
+
+	text, err = releasenotes.Render(&config.ReleaseNotesTarget{Name: "wiki", Kind: config.ReleaseNotesTargetConfluence}, testNotes)
+	require.NoError(t, err)
+	assert.Contains(t, text, "fix &lt;things&gt;", "the markdown should be escaped in the Confluence page")
+
+	text, err = releasenotes.Render(&config.ReleaseNotesTarget{Name: "custom", Kind: config.ReleaseNotesTargetSlack, Template: "{{ .TagName }} is out"}, testNotes)
+	require.NoError(t, err)
+	assert.Equal(t, "v1.2.3 is out", text)
+}
+
+func TestSlackPublisher(t *testing.T) {
+	t.Parallel()
+
+	var message map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(data, &message))
+	}))
+	defer server.Close()
+
+	publisher := &releasenotes.SlackPublisher{
+		Target: &config.ReleaseNotesTarget{Name: "slack", Kind: config.ReleaseNotesTargetSlack, URL: server.URL},
+	}
+	_, err := publisher.Publish(testNotes)
+	require.NoError(t, err)
+	assert.Contains(t, message["text"], "Released myapp version 1.2.3")
+	assert.Contains(t, message["text"], "### Bug Fixes")
+}
+
+func TestConfluencePublisher(t *testing.T) {
+	t.Parallel()
+
+	existing := false
+	var published map[string]interface{}
+	var method string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			assert.Equal(t, "ENG", r.URL.Query().Get("spaceKey"))
+			assert.Equal(t, "myapp 1.2.3 release notes", r.URL.Query().Get("title"))
+			if existing {
+				w.Write([]byte(`{"results": [{"id": "42", "type": "page", "title": "myapp 1.2.3 release notes", "version": {"number": 3}}]}`))
+			} else {
+				w.Write([]byte(`{"results": []}`))
+			}
+			return
+		}
+		method = r.Method + " " + r.URL.Path
+		data, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(data, &published))
+		w.Write([]byte(`{"id": "42", "_links": {"webui": "/spaces/ENG/pages/42"}}`))
+	}))
+	defer server.Close()
+
+	publisher := &releasenotes.ConfluencePublisher{
+		Target: &config.ReleaseNotesTarget{Name: "wiki", Kind: config.ReleaseNotesTargetConfluence, URL: server.URL, Space: "ENG", ParentID: "7"},
+	}
+	u, err := publisher.Publish(testNotes)
+	require.NoError(t, err)
+	assert.Equal(t, "POST /rest/api/content", method)
+	assert.Equal(t, server.URL+"/spaces/ENG/pages/42", u)
+	assert.Equal(t, "myapp 1.2.3 release notes", published["title"])
+	assert.NotNil(t, published["ancestors"])
+
+	existing = true
+	_, err = publisher.Publish(testNotes)
+	require.NoError(t, err)
+	assert.Equal(t, "PUT /rest/api/content/42", method)
+	assert.Equal(t, map[string]interface{}{"number": float64(4)}, published["version"])
+}
@@ -0,0 +1,307 @@
+package reports
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jenkins-x/jx/pkg/kube"
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// CoverageHistoryConfigMap the name of the ConfigMap storing the coverage history of the repositories
+	CoverageHistoryConfigMap = "jx-coverage-history"
+
+	// CoverageFormatLcov the format of lcov tracefiles
+	CoverageFormatLcov = "lcov"
+
+	// CoverageFormatCobertura the format of Cobertura XML reports
+	CoverageFormatCobertura = "cobertura"
+
+	// CoverageFormatGoCover the format of the profiles generated by `go test -coverprofile`
+	CoverageFormatGoCover = "gocover"
+
+	// maxCoverageHistoryRecords the maximum number of coverage records stored for each repository
+	maxCoverageHistoryRecords = 100
+)
+
+// CoverageFormats the supported formats of coverage reports
+var CoverageFormats = []string{CoverageFormatLcov, CoverageFormatCobertura, CoverageFormatGoCover}
+
+// CoverageReport the number of lines or statements covered by the tests
+type CoverageReport struct {
+	Lines   int `json:"lines"`
+	Covered int `json:"covered"`
+}
+
+// CoverageRecord the coverage of a build of a commit
+type CoverageRecord struct {
+	SHA         string    `json:"sha"`
+	Branch      string    `json:"branch,omitempty"`
+	Build       string    `json:"build,omitempty"`
+	PullRequest string    `json:"pullRequest,omitempty"`
+	Lines       int       `json:"lines"`
+	Covered     int       `json:"covered"`
+	Timestamp   time.Time `json:"timestamp"`
+}
+
+// CoverageHistory the coverage of the most recent builds of a repository, oldest first
+type CoverageHistory struct {
+	Records []CoverageRecord `json:"records,omitempty"`
+}
+
+// cobertura the root element of a Cobertura XML report
+type cobertura struct {
+	LinesValid   *int            `xml:"lines-valid,attr"`
+	LinesCovered *int            `xml:"lines-covered,attr"`
+	Lines        []coberturaLine `xml:"packages>package>classes>class>lines>line"`
+}
+
+type coberturaLine struct {
+	Number int `xml:"number,attr"`
+	Hits   int `xml:"hits,attr"`
+}
+
+// Percent returns the percentage of covered lines
+func (r *CoverageReport) Percent() float64 {
+	return coveragePercent(r.Covered, r.Lines)
+}
+
+// Add adds the lines of the other report
+func (r *CoverageReport) Add(other *CoverageReport) {
+	r.Lines += other.Lines
+	r.Covered += other.Covered
+}
+
+// Percent returns the percentage of covered lines
+func (r *CoverageRecord) Percent() float64 {
+	return coveragePercent(r.Covered, r.Lines)
+}
+
+func coveragePercent(covered int, lines int) float64 {
+	if lines <= 0 {
+		return 0
+	}
+	return float64(covered) * 100 / float64(lines)
+}
+
+// DetectCoverageFormat returns the format of the coverage report or an empty string if it is unknown
+func DetectCoverageFormat(data []byte) string {
+	text := strings.TrimSpace(string(data))
+	switch {
+	case strings.HasPrefix(text, "mode:"):
+		return CoverageFormatGoCover
+	case strings.Contains(text, "<coverage"):
+		return CoverageFormatCobertura
+	case strings.HasPrefix(text, "TN:") || strings.HasPrefix(text, "SF:") || strings.Contains(text, "end_of_record"):
+		return CoverageFormatLcov
+	default:
+		return ""
+	}
+}
+
+// ParseCoverage parses the coverage report of the given format. If no format is specified it is detected
+func ParseCoverage(format string, data []byte) (*CoverageReport, error) {
+	if format == "" {
+		format = DetectCoverageFormat(data)
+	}
+	switch format {
+	case CoverageFormatLcov:
+		return ParseLcovCoverage(data)
+	case CoverageFormatCobertura:
+		return ParseCoberturaCoverage(data)
+	case CoverageFormatGoCover:
+		return ParseGoCoverProfile(data)
+	case "":
+		return nil, fmt.Errorf("could not detect the format of the coverage report. Supported formats are: %s", strings.Join(CoverageFormats, ", "))
+	default:
+		return nil, fmt.Errorf("unsupported coverage format %s. Supported formats are: %s", format, strings.Join(CoverageFormats, ", "))
+	}
+}
+
+// ParseLcovCoverage parses an lcov tracefile using the LF and LH totals of each source file or its DA lines if there
+// are no totals
+func ParseLcovCoverage(data []byte) (*CoverageReport, error) {
+	answer := &CoverageReport{}
+	file := &CoverageReport{}
+	daLines := &CoverageReport{}
+	hasTotals := false
+	endOfRecord := func() {
+		if hasTotals {
+			answer.Add(file)
+		} else {
+			answer.Add(daLines)
+		}
+		file = &CoverageReport{}
+		daLines = &CoverageReport{}
+		hasTotals = false
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case strings.HasPrefix(line, "LF:"):
+			n, err := strconv.Atoi(strings.TrimPrefix(line, "LF:"))
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to parse lcov line %s", line)
+			}
+			file.Lines = n
+			hasTotals = true
+		case strings.HasPrefix(line, "LH:"):
+			n, err := strconv.Atoi(strings.TrimPrefix(line, "LH:"))
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to parse lcov line %s", line)
+			}
+			file.Covered = n
+			hasTotals = true
+		case strings.HasPrefix(line, "DA:"):
+			fields := strings.Split(strings.TrimPrefix(line, "DA:"), ",")
+			if len(fields) < 2 {
+				return nil, fmt.Errorf("failed to parse lcov line %s", line)
+			}
+			daLines.Lines++
+			if hits, err := strconv.Atoi(fields[1]); err == nil && hits > 0 {
+				daLines.Covered++
+			}
+		case line == "end_of_record":
+			endOfRecord()
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, "failed to read the lcov tracefile")
+	}
+	endOfRecord()
+	return answer, nil
+}
+
+// ParseCoberturaCoverage parses a Cobertura XML report using its line totals or its lines if there are no totals
+func ParseCoberturaCoverage(data []byte) (*CoverageReport, error) {
+	root := cobertura{}
+	err := xml.Unmarshal(data, &root)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse the Cobertura report")
+	}
+	if root.LinesValid != nil && root.LinesCovered != nil {
+		return &CoverageReport{Lines: *root.LinesValid, Covered: *root.LinesCovered}, nil
+	}
+	answer := &CoverageReport{}
+	for _, line := range root.Lines {
+		answer.Lines++
+		if line.Hits > 0 {
+			answer.Covered++
+		}
+	}
+	return answer, nil
+}
+
+// ParseGoCoverProfile parses a profile generated by `go test -coverprofile` counting the covered statements. Blocks
+// which are reported more than once, such as when using -coverpkg, are counted once
+func ParseGoCoverProfile(data []byte) (*CoverageReport, error) {
+	statements := map[string]int{}
+	covered := map[string]bool{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "mode:") {
+			continue
+		}
+		// a line is formatted as: name.go:line.column,line.column numberOfStatements count
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("failed to parse go cover profile line %s", line)
+		}
+		numStmt, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse go cover profile line %s", line)
+		}
+		count, err := strconv.Atoi(fields[2])
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse go cover profile line %s", line)
+		}
+		block := fields[0]
+		statements[block] = numStmt
+		if count > 0 {
+			covered[block] = true
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, "failed to read the go cover profile")
+	}
+	answer := &CoverageReport{}
+	for block, numStmt := range statements {
+		answer.Lines += numStmt
+		if covered[block] {
+			answer.Covered += numStmt
+		}
+	}
+	return answer, nil
+}
+
+// Add adds the coverage record replacing any previous record of the same commit and branch
+func (h *CoverageHistory) Add(record CoverageRecord) {
+	records := []CoverageRecord{}
+	for _, r := range h.Records {
+		if r.SHA != record.SHA || r.Branch != record.Branch {
+			records = append(records, r)
+		}
+	}
+	records = append(records, record)
+	if len(records) > maxCoverageHistoryRecords {
+		records = records[len(records)-maxCoverageHistoryRecords:]
+	}
+	h.Records = records
+}
+
+// LatestForBranch returns the most recent coverage record of the branch or nil if there is none
+func (h *CoverageHistory) LatestForBranch(branch string) *CoverageRecord {
+	for i := len(h.Records) - 1; i >= 0; i-- {
+		if h.Records[i].Branch == branch {
+			return &h.Records[i]
+		}
+	}
+	return nil
+}
+
+// LoadCoverageHistory loads the coverage history of the repository or returns an empty history if there is none
+func LoadCoverageHistory(kubeClient kubernetes.Interface, ns string, repository string) (*CoverageHistory, error) {
+	answer := &CoverageHistory{}
+	cm, err := kube.GetConfigMap(kubeClient, ns, CoverageHistoryConfigMap)
+	if err != nil {
+		// no coverage history has been stored yet
+		return answer, nil
+	}
+	data := cm.Data[repositoryKey(repository)]
+	if data == "" {
+		return answer, nil
+	}
+	err = yaml.Unmarshal([]byte(data), answer)
+	if err != nil {
+		return answer, errors.Wrapf(err, "failed to unmarshal the coverage history of %s in ConfigMap %s", repository, CoverageHistoryConfigMap)
+	}
+	return answer, nil
+}
+
+// SaveCoverageHistory saves the coverage history of the repository
+func SaveCoverageHistory(kubeClient kubernetes.Interface, ns string, repository string, history *CoverageHistory) error {
+	data, err := yaml.Marshal(history)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal the coverage history of %s", repository)
+	}
+	callback := func(cm *v1.ConfigMap) error {
+		if cm.Data == nil {
+			cm.Data = map[string]string{}
+		}
+		cm.Data[repositoryKey(repository)] = string(data)
+		return nil
+	}
+	_, err = kube.DefaultModifyConfigMap(kubeClient, ns, CoverageHistoryConfigMap, callback, nil)
+	return err
+}
@@ -0,0 +1,57 @@
+package reports_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x/jx/pkg/reports"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestParseCoverage(t *testing.T) {
+	t.Parallel()
+	for fileName, expected := range map[string]reports.CoverageReport{
+		"coverage.out":        {Lines: 10, Covered: 7},
+		"lcov.info":           {Lines: 7, Covered: 4},
+		"cobertura.xml":       {Lines: 8, Covered: 6},
+		"cobertura-lines.xml": {Lines: 2, Covered: 1},
+	} {
+		data, err := ioutil.ReadFile(filepath.Join("test_data", "coverage", fileName))
+		require.NoError(t, err)
+		report, err := reports.ParseCoverage("", data)
+		require.NoError(t, err, "failed to parse %s", fileName)
+		assert.Equal(t, expected, *report, "coverage of %s", fileName)
+	}
+
+	_, err := reports.ParseCoverage("", []byte("not a coverage report"))
+	assert.Error(t, err)
+	_, err = reports.ParseCoverage("jacoco", []byte("mode: set"))
+	assert.Error(t, err)
+	assert.Equal(t, 70.0, (&reports.CoverageReport{Lines: 10, Covered: 7}).Percent())
+	assert.Equal(t, 0.0, (&reports.CoverageReport{}).Percent())
+}
+
+func TestCoverageHistory(t *testing.T) {
+	t.Parallel()
+	kubeClient := fake.NewSimpleClientset()
+	history, err := reports.LoadCoverageHistory(kubeClient, "jx", "acme/app")
+	require.NoError(t, err)
+	assert.Nil(t, history.LatestForBranch("master"))
+
+	history.Add(reports.CoverageRecord{SHA: "sha1", Branch: "master", Build: "1", Lines: 10, Covered: 5})
+	history.Add(reports.CoverageRecord{SHA: "sha2", Branch: "PR-1", Build: "1", Lines: 10, Covered: 6})
+	history.Add(reports.CoverageRecord{SHA: "sha1", Branch: "master", Build: "2", Lines: 10, Covered: 7})
+	require.Len(t, history.Records, 2, "the coverage of a rebuilt commit should be replaced")
+	assert.Equal(t, "2", history.LatestForBranch("master").Build)
+	assert.Equal(t, 70.0, history.LatestForBranch("master").Percent())
+
+	err = reports.SaveCoverageHistory(kubeClient, "jx", "acme/app", history)
+	require.NoError(t, err)
+	loaded, err := reports.LoadCoverageHistory(kubeClient, "jx", "acme/app")
+	require.NoError(t, err)
+	assert.Equal(t, history.Records[0].SHA, loaded.Records[0].SHA)
+	assert.Equal(t, history.Records[1].Covered, loaded.Records[1].Covered)
+}
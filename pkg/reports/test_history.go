@@ -0,0 +1,407 @@
+package reports
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/jenkins-x/jx/pkg/kube"
+	"github.com/jenkins-x/jx/pkg/kube/naming"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// TestHistoryConfigMap the name of the ConfigMap storing the test history of the repositories
+	TestHistoryConfigMap = "jx-test-history"
+
+	// maxTestHistoryCommits the maximum number of commits stored for each test
+	maxTestHistoryCommits = 10
+)
+
+// TestCaseResult the result of a test case in a test report
+type TestCaseResult struct {
+	Name    string `json:"name"`
+	Failed  bool   `json:"failed,omitempty"`
+	Skipped bool   `json:"skipped,omitempty"`
+	// Message the failure message of a failed test
+	Message string `json:"message,omitempty"`
+}
+
+// TestHistory the failure history of the tests of a repository. Only the tests which have failed are tracked
+type TestHistory struct {
+	Tests map[string]*TestRecord `json:"tests,omitempty"`
+	// Retriggered the commit SHA each pull request was last retriggered for
+	Retriggered map[string]string `json:"retriggered,omitempty"`
+}
+
+// TestRecord the failure history of a test
+type TestRecord struct {
+	Runs     int `json:"runs"`
+	Failures int `json:"failures"`
+	// Flakes the number of commits for which the test both failed and passed
+	Flakes     int      `json:"flakes"`
+	FailedSHAs []string `json:"failedSHAs,omitempty"`
+	PassedSHAs []string `json:"passedSHAs,omitempty"`
+}
+
+// FlakyTestAnalysis the analysis of the failed tests of a test run
+type FlakyTestAnalysis struct {
+	Tests  int      `json:"tests"`
+	Failed []string `json:"failed,omitempty"`
+	Flaky  []string `json:"flaky,omitempty"`
+}
+
+// junitSuite is either the <testsuites> or a <testsuite> element of a JUnit XML report
+type junitSuite struct {
+	XMLName   xml.Name
+	Name      string          `xml:"name,attr"`
+	Suites    []junitSuite    `xml:"testsuite"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure"`
+	Error     *junitFailure `xml:"error"`
+	Skipped   *struct{}     `xml:"skipped"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// goTestEvent is an event of the output of `go test -json`
+type goTestEvent struct {
+	Action  string `json:"Action"`
+	Package string `json:"Package"`
+	Test    string `json:"Test"`
+	Output  string `json:"Output"`
+}
+
+// ParseTestReport parses a JUnit XML report or a `go test -json` report depending on the extension of the file name
+func ParseTestReport(fileName string, data []byte) ([]TestCaseResult, error) {
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".xml":
+		return ParseJUnitReport(data)
+	case ".json":
+		return ParseGoTestReport(data)
+	default:
+		return nil, fmt.Errorf("unsupported test report %s. Expected a JUnit .xml file or a go test -json .json file", fileName)
+	}
+}
+
+// ParseJUnitReport parses the test cases of a JUnit XML report
+func ParseJUnitReport(data []byte) ([]TestCaseResult, error) {
+	root := junitSuite{}
+	err := xml.Unmarshal(data, &root)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse the JUnit report")
+	}
+	answer := []TestCaseResult{}
+	var collect func(suite *junitSuite)
+	collect = func(suite *junitSuite) {
+		for _, tc := range suite.TestCases {
+			prefix := tc.Classname
+			if prefix == "" {
+				prefix = suite.Name
+			}
+			name := tc.Name
+			if prefix != "" {
+				name = prefix + "." + name
+			}
+			result := TestCaseResult{
+				Name:    name,
+				Skipped: tc.Skipped != nil,
+			}
+			for _, failure := range []*junitFailure{tc.Failure, tc.Error} {
+				if failure != nil && !result.Failed {
+					result.Failed = true
+					result.Message = failure.Message
+					if result.Message == "" {
+						result.Message = strings.TrimSpace(failure.Text)
+					}
+				}
+			}
+			answer = append(answer, result)
+		}
+		for i := range suite.Suites {
+			collect(&suite.Suites[i])
+		}
+	}
+	collect(&root)
+	return answer, nil
+}
+
+// ParseGoTestReport parses the tests of the output of `go test -json` or `go test -v`. The lines which are not test
+// events or test results are ignored
+func ParseGoTestReport(data []byte) ([]TestCaseResult, error) {
+	names := []string{}
+	results := map[string]*TestCaseResult{}
+	output := map[string][]string{}
+	// pending the results of `go test -v` which are waiting for the package summary line
+	pending := []*TestCaseResult{}
+	var lastFailed *TestCaseResult
+
+	addResult := func(name string, action string) *TestCaseResult {
+		result := results[name]
+		if result == nil {
+			result = &TestCaseResult{Name: name}
+			results[name] = result
+			names = append(names, name)
+		}
+		result.Failed = action == "fail"
+		result.Skipped = action == "skip"
+		return result
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		text := scanner.Text()
+		line := strings.TrimSpace(text)
+		if strings.HasPrefix(line, "{") {
+			event := goTestEvent{}
+			err := json.Unmarshal([]byte(line), &event)
+			if err != nil || event.Test == "" {
+				continue
+			}
+			name := event.Package + "." + event.Test
+			switch event.Action {
+			case "output":
+				output[name] = append(output[name], event.Output)
+			case "pass", "fail", "skip":
+				result := addResult(name, event.Action)
+				if result.Failed {
+					result.Message = goTestFailureMessage(output[name])
+				}
+			}
+			continue
+		}
+
+		if action, test := goTestResultLine(line); action != "" {
+			lastFailed = nil
+			result := &TestCaseResult{Name: test, Failed: action == "fail", Skipped: action == "skip"}
+			if result.Failed {
+				lastFailed = result
+			}
+			pending = append(pending, result)
+			continue
+		}
+		if pkg := goTestPackageLine(line); pkg != "" {
+			for _, p := range pending {
+				result := addResult(pkg+"."+p.Name, actionOf(p))
+				result.Message = p.Message
+			}
+			pending = nil
+			lastFailed = nil
+			continue
+		}
+		// the output of a failed test is indented below its result line
+		if lastFailed != nil && strings.HasPrefix(text, "    ") {
+			if lastFailed.Message != "" {
+				lastFailed.Message += "\n"
+			}
+			lastFailed.Message += line
+			continue
+		}
+		lastFailed = nil
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, "failed to read the go test report")
+	}
+	for _, p := range pending {
+		result := addResult(p.Name, actionOf(p))
+		result.Message = p.Message
+	}
+	answer := []TestCaseResult{}
+	for _, name := range names {
+		answer = append(answer, *results[name])
+	}
+	return answer, nil
+}
+
+// goTestResultLine returns the action and test name of a `--- PASS: TestFoo (0.00s)` line of `go test -v`
+func goTestResultLine(line string) (string, string) {
+	for _, action := range []string{"pass", "fail", "skip"} {
+		prefix := "--- " + strings.ToUpper(action) + ": "
+		if strings.HasPrefix(line, prefix) {
+			fields := strings.Fields(strings.TrimPrefix(line, prefix))
+			if len(fields) > 0 {
+				return action, fields[0]
+			}
+		}
+	}
+	return "", ""
+}
+
+// goTestPackageLine returns the package of an `ok  github.com/acme/app 0.1s` or `FAIL github.com/acme/app 0.1s` line
+// of `go test -v`
+func goTestPackageLine(line string) string {
+	fields := strings.Fields(line)
+	if len(fields) >= 2 && (fields[0] == "ok" || fields[0] == "FAIL") {
+		return fields[1]
+	}
+	return ""
+}
+
+func actionOf(result *TestCaseResult) string {
+	switch {
+	case result.Failed:
+		return "fail"
+	case result.Skipped:
+		return "skip"
+	default:
+		return "pass"
+	}
+}
+
+// goTestFailureMessage returns the output of a failed test without the lines generated by `go test`
+func goTestFailureMessage(output []string) string {
+	lines := []string{}
+	for _, o := range output {
+		line := strings.TrimSpace(o)
+		if line == "" || strings.HasPrefix(line, "=== ") || strings.HasPrefix(line, "--- ") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// IsFlaky returns true if the test both failed and passed for the same commit
+func (r *TestRecord) IsFlaky() bool {
+	return r != nil && r.Flakes > 0
+}
+
+// Analyse returns which of the failed tests of the results are known to be flaky
+func (h *TestHistory) Analyse(results []TestCaseResult) *FlakyTestAnalysis {
+	answer := &FlakyTestAnalysis{
+		Tests: len(results),
+	}
+	for _, result := range results {
+		if result.Skipped {
+			answer.Tests--
+			continue
+		}
+		if !result.Failed {
+			continue
+		}
+		answer.Failed = append(answer.Failed, result.Name)
+		if h.Tests[result.Name].IsFlaky() {
+			answer.Flaky = append(answer.Flaky, result.Name)
+		}
+	}
+	sort.Strings(answer.Failed)
+	sort.Strings(answer.Flaky)
+	return answer
+}
+
+// Record adds the results of a test run of the given commit to the history. A test which fails and passes for the same
+// commit is marked as flaky
+func (h *TestHistory) Record(sha string, results []TestCaseResult) {
+	if h.Tests == nil {
+		h.Tests = map[string]*TestRecord{}
+	}
+	for _, result := range results {
+		if result.Skipped {
+			continue
+		}
+		record := h.Tests[result.Name]
+		if record == nil {
+			if !result.Failed {
+				continue
+			}
+			record = &TestRecord{}
+			h.Tests[result.Name] = record
+		}
+		record.Runs++
+		if result.Failed {
+			record.Failures++
+		}
+		if sha == "" {
+			continue
+		}
+		failed := util.StringArrayIndex(record.FailedSHAs, sha) >= 0
+		passed := util.StringArrayIndex(record.PassedSHAs, sha) >= 0
+		if result.Failed {
+			if passed && !failed {
+				record.Flakes++
+			}
+			record.FailedSHAs = addSHA(record.FailedSHAs, sha)
+		} else {
+			if failed && !passed {
+				record.Flakes++
+			}
+			record.PassedSHAs = addSHA(record.PassedSHAs, sha)
+		}
+	}
+}
+
+// OnlyFlakyFailures returns true if tests failed and all of them are known to be flaky
+func (a *FlakyTestAnalysis) OnlyFlakyFailures() bool {
+	return len(a.Failed) > 0 && len(a.Flaky) == len(a.Failed)
+}
+
+// LoadTestHistory loads the test history of the repository or returns an empty history if there is none
+func LoadTestHistory(kubeClient kubernetes.Interface, ns string, repository string) (*TestHistory, error) {
+	answer := &TestHistory{}
+	cm, err := kube.GetConfigMap(kubeClient, ns, TestHistoryConfigMap)
+	if err != nil {
+		// no test history has been stored yet
+		return answer, nil
+	}
+	data := cm.Data[repositoryKey(repository)]
+	if data == "" {
+		return answer, nil
+	}
+	err = yaml.Unmarshal([]byte(data), answer)
+	if err != nil {
+		return answer, errors.Wrapf(err, "failed to unmarshal the test history of %s in ConfigMap %s", repository, TestHistoryConfigMap)
+	}
+	return answer, nil
+}
+
+// SaveTestHistory saves the test history of the repository
+func SaveTestHistory(kubeClient kubernetes.Interface, ns string, repository string, history *TestHistory) error {
+	data, err := yaml.Marshal(history)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal the test history of %s", repository)
+	}
+	callback := func(cm *v1.ConfigMap) error {
+		if cm.Data == nil {
+			cm.Data = map[string]string{}
+		}
+		cm.Data[repositoryKey(repository)] = string(data)
+		return nil
+	}
+	_, err = kube.DefaultModifyConfigMap(kubeClient, ns, TestHistoryConfigMap, callback, nil)
+	return err
+}
+
+// repositoryKey returns the ConfigMap key of the history of the repository such as owner.repo
+func repositoryKey(repository string) string {
+	return naming.ToValidNameWithDots(strings.Replace(repository, "/", ".", -1))
+}
+
+// addSHA adds the commit SHA keeping the most recent ones
+func addSHA(shas []string, sha string) []string {
+	if util.StringArrayIndex(shas, sha) >= 0 {
+		return shas
+	}
+	shas = append(shas, sha)
+	if len(shas) > maxTestHistoryCommits {
+		shas = shas[len(shas)-maxTestHistoryCommits:]
+	}
+	return shas
+}
@@ -0,0 +1,87 @@
+package reports_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x/jx/pkg/reports"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestParseTestReport(t *testing.T) {
+	t.Parallel()
+	for fileName, expected := range map[string][]reports.TestCaseResult{
+		"report.junit.xml": {
+			{Name: "com.acme.AppTest.creates an app"},
+			{Name: "com.acme.AppTest.deletes an app", Failed: true, Message: "expected 0 but was 1"},
+			{Name: "app.imports an app", Failed: true, Message: "timeout"},
+			{Name: "com.acme.AppTest.upgrades an app", Skipped: true},
+		},
+		"report.json": {
+			{Name: "github.com/acme/app/pkg/cmd.TestCreate"},
+			{Name: "github.com/acme/app/pkg/cmd.TestDelete", Failed: true, Message: "delete_test.go:12: expected 0 but was 1"},
+			{Name: "github.com/acme/app/pkg/cmd.TestUpgrade", Skipped: true},
+		},
+	} {
+		data, err := ioutil.ReadFile(filepath.Join("test_data", "test_history", fileName))
+		require.NoError(t, err)
+		results, err := reports.ParseTestReport(fileName, data)
+		require.NoError(t, err, "failed to parse %s", fileName)
+		assert.Equal(t, expected, results, "results of %s", fileName)
+	}
+
+	_, err := reports.ParseTestReport("report.txt", []byte("ok"))
+	assert.Error(t, err)
+}
+
+func TestTestHistory(t *testing.T) {
+	t.Parallel()
+	history := &reports.TestHistory{}
+	failed := []reports.TestCaseResult{{Name: "TestCreate"}, {Name: "TestDelete", Failed: true}, {Name: "TestUpgrade", Skipped: true}}
+	passed := []reports.TestCaseResult{{Name: "TestCreate"}, {Name: "TestDelete"}}
+
+	history.Record("sha1", passed)
+	assert.Empty(t, history.Tests, "the tests which never failed should not be tracked")
+
+	analysis := history.Analyse(failed)
+	assert.Equal(t, 2, analysis.Tests, "skipped tests should not be counted")
+	assert.Equal(t, []string{"TestDelete"}, analysis.Failed)
+	assert.False(t, analysis.OnlyFlakyFailures())
+	history.Record("sha2", failed)
+	history.Record("sha3", passed)
+	assert.False(t, history.Tests["TestDelete"].IsFlaky(), "a test fixed by a new commit is not flaky")
+
+	history.Record("sha3", failed)
+	assert.True(t, history.Tests["TestDelete"].IsFlaky(), "a test which failed and passed for the same commit is flaky")
+	assert.Equal(t, 3, history.Tests["TestDelete"].Runs)
+	assert.Equal(t, 2, history.Tests["TestDelete"].Failures)
+
+	analysis = history.Analyse(failed)
+	assert.Equal(t, []string{"TestDelete"}, analysis.Flaky)
+	assert.True(t, analysis.OnlyFlakyFailures())
+	assert.False(t, history.Analyse(passed).OnlyFlakyFailures())
+}
+
+func TestSaveTestHistory(t *testing.T) {
+	t.Parallel()
+	kubeClient := fake.NewSimpleClientset()
+	history, err := reports.LoadTestHistory(kubeClient, "jx", "acme/app")
+	require.NoError(t, err)
+	assert.Empty(t, history.Tests)
+
+	history.Record("sha1", []reports.TestCaseResult{{Name: "TestDelete", Failed: true}})
+	history.Retriggered = map[string]string{"12": "sha1"}
+	err = reports.SaveTestHistory(kubeClient, "jx", "acme/app", history)
+	require.NoError(t, err)
+
+	loaded, err := reports.LoadTestHistory(kubeClient, "jx", "acme/app")
+	require.NoError(t, err)
+	assert.Equal(t, history, loaded)
+
+	other, err := reports.LoadTestHistory(kubeClient, "jx", "acme/other")
+	require.NoError(t, err)
+	assert.Empty(t, other.Tests)
+}
@@ -0,0 +1,95 @@
+package reports
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+)
+
+const (
+	// TestReportFormatJUnit the format of JUnit XML reports
+	TestReportFormatJUnit = "junit"
+
+	// TestReportFormatGoTest the format of the output of `go test -json` or `go test -v`
+	TestReportFormatGoTest = "gotest"
+)
+
+// TestReportFormats the supported formats of test output
+var TestReportFormats = []string{TestReportFormatJUnit, TestReportFormatGoTest}
+
+// TestReport the structured report of the tests run by a pipeline
+type TestReport struct {
+	Tests   int              `json:"tests"`
+	Passed  int              `json:"passed"`
+	Failed  int              `json:"failed"`
+	Skipped int              `json:"skipped"`
+	Results []TestCaseResult `json:"results,omitempty"`
+}
+
+// ParseTestOutput parses the test output of the given format
+func ParseTestOutput(format string, data []byte) ([]TestCaseResult, error) {
+	switch format {
+	case TestReportFormatJUnit:
+		return ParseJUnitReport(data)
+	case TestReportFormatGoTest:
+		return ParseGoTestReport(data)
+	default:
+		return nil, fmt.Errorf("unsupported test output format %s. Supported formats are: %s", format, strings.Join(TestReportFormats, ", "))
+	}
+}
+
+// IsTestOutputFile returns true if the file name has an extension used for test output of the given format
+func IsTestOutputFile(format string, fileName string) bool {
+	ext := strings.ToLower(filepath.Ext(fileName))
+	switch format {
+	case TestReportFormatJUnit:
+		return ext == ".xml"
+	case TestReportFormatGoTest:
+		return ext == ".json" || ext == ".log" || ext == ".txt"
+	default:
+		return false
+	}
+}
+
+// NewTestReport creates a test report counting the results
+func NewTestReport(results []TestCaseResult) *TestReport {
+	answer := &TestReport{
+		Tests:   len(results),
+		Results: results,
+	}
+	for _, result := range results {
+		switch {
+		case result.Skipped:
+			answer.Skipped++
+		case result.Failed:
+			answer.Failed++
+		default:
+			answer.Passed++
+		}
+	}
+	return answer
+}
+
+// FailedTests returns the results of the failed tests
+func (r *TestReport) FailedTests() []TestCaseResult {
+	answer := []TestCaseResult{}
+	for _, result := range r.Results {
+		if result.Failed && !result.Skipped {
+			answer = append(answer, result)
+		}
+	}
+	return answer
+}
+
+// Summary returns the test counts of the report to be recorded on a PipelineActivity
+func (r *TestReport) Summary(reportURL string) *v1.TestSummary {
+	return &v1.TestSummary{
+		Tests:     r.Tests,
+		Passed:    r.Passed,
+		Failed:    r.Failed,
+		Skipped:   r.Skipped,
+		ReportURL: reportURL,
+	}
+}
@@ -0,0 +1,48 @@
+package reports_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x/jx/pkg/reports"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseGoTestVerboseOutput(t *testing.T) {
+	t.Parallel()
+	data, err := ioutil.ReadFile(filepath.Join("test_data", "test_report", "go-test.log"))
+	require.NoError(t, err)
+	results, err := reports.ParseTestOutput(reports.TestReportFormatGoTest, data)
+	require.NoError(t, err)
+	expected := []reports.TestCaseResult{
+		{Name: "github.com/acme/app/pkg/cmd.TestCreate"},
+		{Name: "github.com/acme/app/pkg/cmd.TestDelete", Failed: true, Message: "delete_test.go:12: expected 0 but was 1\ndelete_test.go:13: app still exists"},
+		{Name: "github.com/acme/app/pkg/cmd.TestUpgrade", Skipped: true},
+		{Name: "github.com/acme/app/pkg/kube.TestImport"},
+	}
+	assert.Equal(t, expected, results)
+
+	_, err = reports.ParseTestOutput("nunit", data)
+	assert.Error(t, err)
+}
+
+func TestNewTestReport(t *testing.T) {
+	t.Parallel()
+	report := reports.NewTestReport([]reports.TestCaseResult{
+		{Name: "TestCreate"},
+		{Name: "TestDelete", Failed: true, Message: "expected 0 but was 1"},
+		{Name: "TestUpgrade", Skipped: true},
+	})
+	assert.Equal(t, 3, report.Tests)
+	assert.Equal(t, 1, report.Passed)
+	assert.Equal(t, 1, report.Failed)
+	assert.Equal(t, 1, report.Skipped)
+	assert.Equal(t, []reports.TestCaseResult{{Name: "TestDelete", Failed: true, Message: "expected 0 but was 1"}}, report.FailedTests())
+
+	summary := report.Summary("gs://reports/jenkins-x/reports/acme/app/master/1/tests.json")
+	assert.Equal(t, 3, summary.Tests)
+	assert.Equal(t, 1, summary.Failed)
+	assert.Equal(t, "gs://reports/jenkins-x/reports/acme/app/master/1/tests.json", summary.ReportURL)
+}
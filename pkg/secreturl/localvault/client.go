@@ -2,11 +2,13 @@ package localvault
 
 import (
 	"fmt"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"regexp"
 	"strings"
 
+	"github.com/ghodss/yaml"
 	"github.com/jenkins-x/jx/pkg/helm"
 	"github.com/jenkins-x/jx/pkg/secreturl"
 	"github.com/jenkins-x/jx/pkg/util"
@@ -21,6 +23,8 @@ const CanonicalClusterPath = "currentCluster"
 // FileSystemClient a local file system based client loading/saving content from the given URL
 type FileSystemClient struct {
 	Dir string
+	// EncryptionKey the passphrase used to encrypt the files at rest, if empty the files are saved in plain text
+	EncryptionKey string
 }
 
 // NewFileSystemClient create a new local file system based client loading content from the given URL
@@ -30,6 +34,15 @@ func NewFileSystemClient(dir string) secreturl.Client {
 	}
 }
 
+// NewEncryptedFileSystemClient create a new local file system based client which encrypts the files with the given
+// passphrase. Existing plain text files can still be read and are encrypted the next time they are written
+func NewEncryptedFileSystemClient(dir string, encryptionKey string) secreturl.Client {
+	return &FileSystemClient{
+		Dir:           dir,
+		EncryptionKey: encryptionKey,
+	}
+}
+
 // Read reads a named secret from the vault
 func (c *FileSystemClient) Read(secretName string) (map[string]interface{}, error) {
 	name := c.fileName(secretName)
@@ -50,11 +63,11 @@ func (c *FileSystemClient) Read(secretName string) (map[string]interface{}, erro
 			if !exists {
 				return nil, errors.Wrapf(err, "the canonical path %s doesn't exist", name)
 			}
-			return helm.LoadValuesFile(name)
+			return c.loadFile(name)
 		}
 		return nil, fmt.Errorf("local vault file does not exist: %s", name)
 	}
-	return helm.LoadValuesFile(name)
+	return c.loadFile(name)
 }
 
 // ReadObject reads a generic named object from vault.
@@ -80,7 +93,7 @@ func (c *FileSystemClient) Write(secretName string, data map[string]interface{})
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to ensure that parent directory exists %s", dir)
 	}
-	err = helm.SaveFile(path, data)
+	err = c.saveFile(path, data)
 	if err != nil {
 		return nil, err
 	}
@@ -96,7 +109,7 @@ func (c *FileSystemClient) WriteObject(secretName string, secret interface{}) (m
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to ensure that parent directory exists %s", dir)
 	}
-	err = helm.SaveFile(path, secret)
+	err = c.saveFile(path, secret)
 	if err != nil {
 		return nil, err
 	}
@@ -111,3 +124,45 @@ func (c *FileSystemClient) ReplaceURIs(s string) (string, error) {
 func (c *FileSystemClient) fileName(secretName string) string {
 	return filepath.Join(c.Dir, secretName+".yaml")
 }
+
+// loadFile loads the values of the file decrypting it if it is encrypted
+func (c *FileSystemClient) loadFile(fileName string) (map[string]interface{}, error) {
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading %s", fileName)
+	}
+	if IsEncrypted(data) {
+		if c.EncryptionKey == "" {
+			return nil, fmt.Errorf("file %s is encrypted but no encryption key is configured. Set $%s or $%s", fileName, EncryptionKeyEnvVar, EncryptionKeyFileEnvVar)
+		}
+		data, err = Decrypt(c.EncryptionKey, data)
+		if err != nil {
+			return nil, errors.Wrapf(err, "decrypting %s", fileName)
+		}
+	}
+	values, err := helm.LoadValues(data)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unmarshaling %s", fileName)
+	}
+	return values, nil
+}
+
+// saveFile saves the contents as YAML encrypting them if an encryption key is configured
+func (c *FileSystemClient) saveFile(fileName string, contents interface{}) error {
+	if c.EncryptionKey == "" {
+		return helm.SaveFile(fileName, contents)
+	}
+	data, err := yaml.Marshal(contents)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal %s", fileName)
+	}
+	data, err = Encrypt(c.EncryptionKey, data)
+	if err != nil {
+		return errors.Wrapf(err, "encrypting %s", fileName)
+	}
+	err = ioutil.WriteFile(fileName, data, 0600)
+	if err != nil {
+		return errors.Wrapf(err, "failed to save %s", fileName)
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package localvault
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"runtime"
+	"strings"
+
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	// EncryptionKeyEnvVar the environment variable containing the passphrase used to encrypt the local secrets
+	EncryptionKeyEnvVar = "JX_SECRETS_ENCRYPTION_KEY"
+	// EncryptionKeyFileEnvVar the environment variable containing the path of a file containing the passphrase
+	EncryptionKeyFileEnvVar = "JX_SECRETS_ENCRYPTION_KEY_FILE"
+	// KeychainService the name of the service of the passphrase in the macOS keychain or the secret service on linux
+	KeychainService = "jx-local-secrets"
+
+	encryptedHeader = "$JX_ENCRYPTED;v1;scrypt-aes256-gcm\n"
+	saltLength      = 16
+	keyLength       = 32
+
+	// scrypt parameters recommended for interactive logins
+	scryptN = 32768
+	scryptR = 8
+	scryptP = 1
+)
+
+// LoadEncryptionKey loads the passphrase used to encrypt the local secrets from the $JX_SECRETS_ENCRYPTION_KEY
+// environment variable, the file referenced by $JX_SECRETS_ENCRYPTION_KEY_FILE or the keychain of the operating
+// system. Returns an empty string if no passphrase is configured in which case the secrets are not encrypted
+func LoadEncryptionKey() (string, error) {
+	key := os.Getenv(EncryptionKeyEnvVar)
+	if key != "" {
+		return key, nil
+	}
+	keyFile := os.Getenv(EncryptionKeyFileEnvVar)
+	if keyFile != "" {
+		data, err := ioutil.ReadFile(keyFile)
+		if err != nil {
+			return "", errors.Wrapf(err, "reading the encryption key file %s from $%s", keyFile, EncryptionKeyFileEnvVar)
+		}
+		return strings.TrimSpace(string(data)), nil
+	}
+	return loadKeychainKey(), nil
+}
+
+// loadKeychainKey returns the passphrase stored in the keychain of the operating system or an empty string
+func loadKeychainKey() string {
+	var cmd *util.Command
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = &util.Command{
+			Name: "security",
+			Args: []string{"find-generic-password", "-s", KeychainService, "-w"},
+		}
+	case "linux":
+		cmd = &util.Command{
+			Name: "secret-tool",
+			Args: []string{"lookup", "service", KeychainService},
+		}
+	default:
+		return ""
+	}
+	out, err := cmd.RunWithoutRetry()
+	if err != nil {
+		log.Logger().Debugf("no local secrets encryption key found in the keychain: %s", err)
+		return ""
+	}
+	return strings.TrimSpace(out)
+}
+
+// IsEncrypted returns true if the data has been encrypted by Encrypt
+func IsEncrypted(data []byte) bool {
+	return bytes.HasPrefix(data, []byte(encryptedHeader))
+}
+
+// Encrypt encrypts the data with AES-256-GCM using a key derived from the passphrase with scrypt
+func Encrypt(passphrase string, data []byte) ([]byte, error) {
+	salt := make([]byte, saltLength)
+	_, err := io.ReadFull(rand.Reader, salt)
+	if err != nil {
+		return nil, errors.Wrap(err, "generating salt")
+	}
+	gcm, err := newGCM(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	_, err = io.ReadFull(rand.Reader, nonce)
+	if err != nil {
+		return nil, errors.Wrap(err, "generating nonce")
+	}
+	sealed := append(salt, nonce...)
+	sealed = gcm.Seal(sealed, nonce, data, []byte(encryptedHeader))
+	return []byte(encryptedHeader + base64.StdEncoding.EncodeToString(sealed) + "\n"), nil
+}
+
+// Decrypt decrypts the data encrypted by Encrypt with the same passphrase
+func Decrypt(passphrase string, data []byte) ([]byte, error) {
+	if !IsEncrypted(data) {
+		return nil, fmt.Errorf("the data is not encrypted")
+	}
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data[len(encryptedHeader):])))
+	if err != nil {
+		return nil, errors.Wrap(err, "decoding the encrypted data")
+	}
+	if len(sealed) < saltLength {
+		return nil, fmt.Errorf("the encrypted data is truncated")
+	}
+	gcm, err := newGCM(passphrase, sealed[:saltLength])
+	if err != nil {
+		return nil, err
+	}
+	sealed = sealed[saltLength:]
+	if len(sealed) < gcm.NonceSize() {
+		return nil, fmt.Errorf("the encrypted data is truncated")
+	}
+	nonce := sealed[:gcm.NonceSize()]
+	answer, err := gcm.Open(nil, nonce, sealed[gcm.NonceSize():], []byte(encryptedHeader))
+	if err != nil {
+		return nil, errors.New("failed to decrypt the data, the encryption key may be wrong")
+	}
+	return answer, nil
+}
+
+func newGCM(passphrase string, salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(passphrase), salt, scryptN, scryptR, scryptP, keyLength)
+	if err != nil {
+		return nil, errors.Wrap(err, "deriving the encryption key")
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating the cipher")
+	}
+	return cipher.NewGCM(block)
+}
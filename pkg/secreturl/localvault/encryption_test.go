@@ -0,0 +1,63 @@
+package localvault_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x/jx/pkg/secreturl/localvault"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncryptDecrypt(t *testing.T) {
+	t.Parallel()
+
+	data := []byte("token: s3cr3t\n")
+	encrypted, err := localvault.Encrypt("my-passphrase", data)
+	require.NoError(t, err)
+	assert.True(t, localvault.IsEncrypted(encrypted))
+	assert.NotContains(t, string(encrypted), "s3cr3t")
+
+	decrypted, err := localvault.Decrypt("my-passphrase", encrypted)
+	require.NoError(t, err)
+	assert.Equal(t, data, decrypted)
+
+	_, err = localvault.Decrypt("wrong-passphrase", encrypted)
+	assert.Error(t, err)
+}
+
+func TestEncryptedFileSystemClient(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "test-encrypted-local-vault")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	plainClient := localvault.NewFileSystemClient(dir)
+	_, err = plainClient.Write("mycluster/adminUser", map[string]interface{}{"password": "plain"})
+	require.NoError(t, err)
+
+	client := localvault.NewEncryptedFileSystemClient(dir, "my-passphrase")
+	values, err := client.Read("mycluster/adminUser")
+	require.NoError(t, err, "plain text files should still be readable")
+	assert.Equal(t, "plain", values["password"])
+
+	_, err = client.Write("mycluster/adminUser", map[string]interface{}{"password": "s3cr3t"})
+	require.NoError(t, err)
+	data, err := ioutil.ReadFile(filepath.Join(dir, "mycluster", "adminUser.yaml"))
+	require.NoError(t, err)
+	assert.True(t, localvault.IsEncrypted(data))
+
+	values, err = client.Read("mycluster/adminUser")
+	require.NoError(t, err)
+	assert.Equal(t, "s3cr3t", values["password"])
+
+	_, err = plainClient.Read("mycluster/adminUser")
+	assert.Error(t, err, "encrypted files cannot be read without the encryption key")
+
+	text, err := client.ReplaceURIs("password: local:mycluster/adminUser:password")
+	require.NoError(t, err)
+	assert.Equal(t, "password: s3cr3t", text)
+}
@@ -103,34 +103,55 @@ type errorResponse struct {
 	Path      string `json:"path,omitempty"`
 }
 
+// SpringBootCacheFileName the name of the file in the cache directory containing the start.spring.io metadata
+const SpringBootCacheFileName = "start_spring_io.json"
+
+// LoadSpringBoot loads the spring boot metadata from start.spring.io using the cache in the given directory if not blank.
+// If start.spring.io cannot be reached then any previously cached metadata is used
 func LoadSpringBoot(cacheDir string) (*SpringBootModel, error) {
-	loader := func() ([]byte, error) {
-		client := http.Client{}
-		req, err := http.NewRequest(http.MethodGet, startSpringURL, nil)
-		if err != nil {
-			return nil, err
-		}
-		req.Header.Set("Accept", "application/json")
-		addClientHeader(req)
+	cacheFileName := ""
+	if cacheDir != "" {
+		cacheFileName = filepath.Join(cacheDir, SpringBootCacheFileName)
+	}
+	body, err := util.LoadCacheData(cacheFileName, loadSpringBootMetadata)
+	if err != nil {
+		return nil, err
+	}
+	return parseSpringBootModel(body)
+}
 
-		res, err := client.Do(req)
-		if err != nil {
-			return nil, err
-		}
-		return ioutil.ReadAll(res.Body)
+// RefreshSpringBoot downloads the latest spring boot metadata from start.spring.io into the cache in the given directory
+func RefreshSpringBoot(cacheDir string) (*SpringBootModel, error) {
+	body, err := util.RefreshCacheData(filepath.Join(cacheDir, SpringBootCacheFileName), loadSpringBootMetadata)
+	if err != nil {
+		return nil, errors.Wrapf(err, "refreshing the spring boot metadata from %s", startSpringURL)
 	}
+	return parseSpringBootModel(body)
+}
 
-	cacheFileName := ""
-	if cacheDir != "" {
-		cacheFileName = filepath.Join(cacheDir, "start_spring_io.json")
+func loadSpringBootMetadata() ([]byte, error) {
+	client := http.Client{}
+	req, err := http.NewRequest(http.MethodGet, startSpringURL, nil)
+	if err != nil {
+		return nil, err
 	}
-	body, err := util.LoadCacheData(cacheFileName, loader)
+	req.Header.Set("Accept", "application/json")
+	addClientHeader(req)
+
+	res, err := client.Do(req)
 	if err != nil {
 		return nil, err
 	}
+	defer res.Body.Close()
+	if res.StatusCode >= 400 {
+		return nil, fmt.Errorf("status %s when loading %s", res.Status, startSpringURL)
+	}
+	return ioutil.ReadAll(res.Body)
+}
 
+func parseSpringBootModel(body []byte) (*SpringBootModel, error) {
 	model := SpringBootModel{}
-	err = json.Unmarshal(body, &model)
+	err := json.Unmarshal(body, &model)
 	if err != nil {
 		return nil, err
 	}
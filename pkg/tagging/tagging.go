@@ -0,0 +1,73 @@
+package tagging
+
+import (
+	"fmt"
+	"strings"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/kube/naming"
+	"github.com/jenkins-x/jx/pkg/registry"
+	"github.com/jenkins-x/jx/pkg/util"
+)
+
+const (
+	// ImageTagValue the path of the helm value of the image tag of the charts generated by the build packs
+	ImageTagValue = "image.tag"
+)
+
+// Release the references to the commit of a release from its git tag, chart and image
+type Release struct {
+	App             string
+	Version         string
+	Tag             string
+	TagCommit       string
+	ChartVersion    string
+	ChartAppVersion string
+	Image           string
+	ImageTag        string
+	ImageRevision   string
+}
+
+// VersionTag returns the git tag of the version
+func VersionTag(version string) string {
+	return "v" + strings.TrimPrefix(version, "v")
+}
+
+// IsProtectedEnvironment returns true if the releases promoted to the environment must have a git tag, chart version
+// and image tag which refer to the same commit
+func IsProtectedEnvironment(policy *v1.TagPolicy, environment string) bool {
+	return policy != nil && util.StringArrayIndex(policy.ProtectedEnvironments, environment) >= 0
+}
+
+// ImageTag returns the image tag of the chart values
+func ImageTag(values map[string]interface{}) string {
+	return util.GetMapValueAsStringViaPath(values, ImageTagValue)
+}
+
+// Verify returns an error describing every reference of the release which does not refer to the version or to the
+// commit of its git tag
+func (r *Release) Verify() error {
+	problems := []string{}
+	if r.TagCommit == "" {
+		problems = append(problems, fmt.Sprintf("there is no git tag %s", r.Tag))
+	}
+	if r.ChartVersion != r.Version {
+		problems = append(problems, fmt.Sprintf("the chart version is %s", r.ChartVersion))
+	}
+	if r.ChartAppVersion != "" && r.ChartAppVersion != r.Version {
+		problems = append(problems, fmt.Sprintf("the chart appVersion is %s", r.ChartAppVersion))
+	}
+	if r.ImageTag != naming.ToValidImageVersion(r.Version) {
+		problems = append(problems, fmt.Sprintf("the chart deploys the image tag %s", r.ImageTag))
+	}
+	if r.ImageRevision == "" {
+		problems = append(problems, fmt.Sprintf("the image %s has no %s label", r.Image, registry.RevisionLabel))
+	} else if r.TagCommit != "" && r.ImageRevision != r.TagCommit {
+		problems = append(problems, fmt.Sprintf("the image %s was built from commit %s but the git tag %s refers to commit %s",
+			r.Image, r.ImageRevision, r.Tag, r.TagCommit))
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("the release %s version %s is inconsistent: %s", r.App, r.Version, strings.Join(problems, ", "))
+	}
+	return nil
+}
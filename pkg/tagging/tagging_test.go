@@ -0,0 +1,51 @@
+package tagging_test
+
+import (
+	"testing"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/tagging"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyRelease(t *testing.T) {
+	t.Parallel()
+
+	valid := tagging.Release{
+		App:             "myapp",
+		Version:         "1.2.3",
+		Tag:             "v1.2.3",
+		TagCommit:       "abc123",
+		ChartVersion:    "1.2.3",
+		ChartAppVersion: "1.2.3",
+		Image:           "gcr.io/acme/myapp:1.2.3",
+		ImageTag:        "1.2.3",
+		ImageRevision:   "abc123",
+	}
+	assert.NoError(t, valid.Verify())
+
+	testCases := map[string]func(r *tagging.Release){
+		"no git tag":              func(r *tagging.Release) { r.TagCommit = "" },
+		"chart version":           func(r *tagging.Release) { r.ChartVersion = "1.2.2" },
+		"chart appVersion":        func(r *tagging.Release) { r.ChartAppVersion = "1.2.2" },
+		"image tag":               func(r *tagging.Release) { r.ImageTag = "1.2.2" },
+		"no image revision":       func(r *tagging.Release) { r.ImageRevision = "" },
+		"image from other commit": func(r *tagging.Release) { r.ImageRevision = "def456" },
+	}
+	for name, modify := range testCases {
+		release := valid
+		modify(&release)
+		assert.Error(t, release.Verify(), name)
+	}
+}
+
+func TestIsProtectedEnvironment(t *testing.T) {
+	t.Parallel()
+
+	policy := &v1.TagPolicy{ProtectedEnvironments: []string{"production"}}
+	assert.True(t, tagging.IsProtectedEnvironment(policy, "production"))
+	assert.False(t, tagging.IsProtectedEnvironment(policy, "staging"))
+	assert.False(t, tagging.IsProtectedEnvironment(nil, "production"))
+	assert.Equal(t, "v1.2.3", tagging.VersionTag("1.2.3"))
+	assert.Equal(t, "1.2.3", tagging.ImageTag(map[string]interface{}{"image": map[string]interface{}{"tag": "1.2.3"}}))
+}
@@ -0,0 +1,125 @@
+package tekton
+
+import (
+	jenkinsv1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	pipelineapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// ApplyBuildPodPolicy applies the defaults of the build pod policy to the steps of the tasks and to the PipelineRun.
+// Values specified by the pipeline itself are never overridden
+func ApplyBuildPodPolicy(policy *jenkinsv1.BuildPodPolicy, pipeline *pipelineapi.Pipeline, tasks []*pipelineapi.Task, run *pipelineapi.PipelineRun) {
+	if policy == nil {
+		return
+	}
+	for _, task := range tasks {
+		for i := range task.Spec.Steps {
+			step := &task.Spec.Steps[i]
+			step.Resources.Requests = defaultResources(step.Resources.Requests, policy.Resources.Requests)
+			step.Resources.Limits = defaultResources(step.Resources.Limits, policy.Resources.Limits)
+			if step.SecurityContext == nil && policy.SecurityContext != nil {
+				step.SecurityContext = policy.SecurityContext.DeepCopy()
+			}
+		}
+	}
+	if run == nil {
+		return
+	}
+	for k, v := range policy.NodeSelector {
+		if run.Spec.NodeSelector == nil {
+			run.Spec.NodeSelector = map[string]string{}
+		}
+		if _, ok := run.Spec.NodeSelector[k]; !ok {
+			run.Spec.NodeSelector[k] = v
+		}
+	}
+	if len(run.Spec.Tolerations) == 0 && len(policy.Tolerations) > 0 {
+		run.Spec.Tolerations = append([]corev1.Toleration{}, policy.Tolerations...)
+	}
+	if policy.IsSpot() {
+		applySpotBuildPool(policy.GetPreemptionRetries(), pipeline, run)
+	}
+}
+
+// applySpotBuildPool lets the pods of the run be scheduled on the spot build node pool, preferring it when it has
+// capacity, and retries the tasks of the pipeline so that builds survive the preemption of a node
+func applySpotBuildPool(retries int, pipeline *pipelineapi.Pipeline, run *pipelineapi.PipelineRun) {
+	toleration := corev1.Toleration{
+		Key:      jenkinsv1.SpotBuildPoolLabel,
+		Operator: corev1.TolerationOpEqual,
+		Value:    jenkinsv1.SpotBuildPoolValue,
+		Effect:   corev1.TaintEffectNoSchedule,
+	}
+	found := false
+	for _, t := range run.Spec.Tolerations {
+		if t.Key == toleration.Key {
+			found = true
+			break
+		}
+	}
+	if !found {
+		run.Spec.Tolerations = append(run.Spec.Tolerations, toleration)
+	}
+
+	if run.Spec.Affinity == nil {
+		run.Spec.Affinity = &corev1.Affinity{}
+	}
+	if run.Spec.Affinity.NodeAffinity == nil {
+		run.Spec.Affinity.NodeAffinity = &corev1.NodeAffinity{}
+	}
+	nodeAffinity := run.Spec.Affinity.NodeAffinity
+	nodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution = append(nodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution, corev1.PreferredSchedulingTerm{
+		Weight: 100,
+		Preference: corev1.NodeSelectorTerm{
+			MatchExpressions: []corev1.NodeSelectorRequirement{
+				{
+					Key:      jenkinsv1.SpotBuildPoolLabel,
+					Operator: corev1.NodeSelectorOpIn,
+					Values:   []string{jenkinsv1.SpotBuildPoolValue},
+				},
+			},
+		},
+	})
+
+	if pipeline != nil {
+		for i := range pipeline.Spec.Tasks {
+			if pipeline.Spec.Tasks[i].Retries == 0 {
+				pipeline.Spec.Tasks[i].Retries = retries
+			}
+		}
+	}
+}
+
+// ApplyDefaultEnv adds the environment variables to the steps of the tasks which do not already define them
+func ApplyDefaultEnv(env []corev1.EnvVar, tasks []*pipelineapi.Task) {
+	for _, task := range tasks {
+		for i := range task.Spec.Steps {
+			step := &task.Spec.Steps[i]
+			for _, e := range env {
+				found := false
+				for _, existing := range step.Env {
+					if existing.Name == e.Name {
+						found = true
+						break
+					}
+				}
+				if !found {
+					step.Env = append(step.Env, *e.DeepCopy())
+				}
+			}
+		}
+	}
+}
+
+// defaultResources adds the default quantities of the resources which are not already specified
+func defaultResources(resources corev1.ResourceList, defaults corev1.ResourceList) corev1.ResourceList {
+	for name, quantity := range defaults {
+		if resources == nil {
+			resources = corev1.ResourceList{}
+		}
+		if _, ok := resources[name]; !ok {
+			resources[name] = quantity.DeepCopy()
+		}
+	}
+	return resources
+}
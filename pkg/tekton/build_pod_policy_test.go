@@ -0,0 +1,124 @@
+package tekton_test
+
+import (
+	"testing"
+
+	jenkinsv1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/tekton"
+	"github.com/stretchr/testify/assert"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestApplyBuildPodPolicy(t *testing.T) {
+	runAsUser := int64(1000)
+	policy := &jenkinsv1.BuildPodPolicy{
+		Resources: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("400m"),
+				corev1.ResourceMemory: resource.MustParse("512Mi"),
+			},
+			Limits: corev1.ResourceList{
+				corev1.ResourceMemory: resource.MustParse("2Gi"),
+			},
+		},
+		NodeSelector:    map[string]string{"pool": "builds", "os": "linux"},
+		Tolerations:     []corev1.Toleration{{Key: "pool", Operator: corev1.TolerationOpEqual, Value: "builds", Effect: corev1.TaintEffectNoSchedule}},
+		SecurityContext: &corev1.SecurityContext{RunAsUser: &runAsUser},
+	}
+	privileged := true
+	task := &v1alpha1.Task{
+		Spec: v1alpha1.TaskSpec{
+			Steps: []corev1.Container{
+				{
+					Name: "build",
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")},
+					},
+					SecurityContext: &corev1.SecurityContext{Privileged: &privileged},
+				},
+				{
+					Name: "test",
+				},
+			},
+		},
+	}
+	run := &v1alpha1.PipelineRun{
+		Spec: v1alpha1.PipelineRunSpec{
+			NodeSelector: map[string]string{"pool": "highmem"},
+		},
+	}
+
+	tekton.ApplyBuildPodPolicy(policy, nil, []*v1alpha1.Task{task}, run)
+
+	build := task.Spec.Steps[0]
+	assert.Equal(t, "1", build.Resources.Requests.Cpu().String(), "the pipeline resources should not be overridden")
+	assert.Equal(t, "512Mi", build.Resources.Requests.Memory().String())
+	assert.Equal(t, "2Gi", build.Resources.Limits.Memory().String())
+	assert.Nil(t, build.SecurityContext.RunAsUser, "the pipeline security context should not be overridden")
+
+	test := task.Spec.Steps[1]
+	assert.Equal(t, "400m", test.Resources.Requests.Cpu().String())
+	assert.Equal(t, int64(1000), *test.SecurityContext.RunAsUser)
+
+	assert.Equal(t, map[string]string{"pool": "highmem", "os": "linux"}, run.Spec.NodeSelector)
+	assert.Equal(t, policy.Tolerations, run.Spec.Tolerations)
+
+	tekton.ApplyBuildPodPolicy(nil, nil, []*v1alpha1.Task{task}, run)
+}
+
+func TestApplyBuildPodPolicySpot(t *testing.T) {
+	spot := true
+	policy := &jenkinsv1.BuildPodPolicy{Spot: &spot}
+	pipeline := &v1alpha1.Pipeline{
+		Spec: v1alpha1.PipelineSpec{
+			Tasks: []v1alpha1.PipelineTask{
+				{Name: "build"},
+				{Name: "test", Retries: 5},
+			},
+		},
+	}
+	run := &v1alpha1.PipelineRun{}
+
+	tekton.ApplyBuildPodPolicy(policy, pipeline, nil, run)
+
+	assert.Equal(t, jenkinsv1.DefaultPreemptionRetries, pipeline.Spec.Tasks[0].Retries)
+	assert.Equal(t, 5, pipeline.Spec.Tasks[1].Retries, "the pipeline retries should not be overridden")
+
+	assert.Len(t, run.Spec.Tolerations, 1)
+	assert.Equal(t, jenkinsv1.SpotBuildPoolLabel, run.Spec.Tolerations[0].Key)
+	assert.Equal(t, corev1.TaintEffectNoSchedule, run.Spec.Tolerations[0].Effect)
+
+	preferred := run.Spec.Affinity.NodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution
+	assert.Len(t, preferred, 1)
+	assert.Equal(t, []string{jenkinsv1.SpotBuildPoolValue}, preferred[0].Preference.MatchExpressions[0].Values)
+}
+
+func TestApplyDefaultEnv(t *testing.T) {
+	task := &v1alpha1.Task{
+		Spec: v1alpha1.TaskSpec{
+			Steps: []corev1.Container{
+				{
+					Name: "build",
+					Env:  []corev1.EnvVar{{Name: "ARTIFACT_REPOSITORY_MAVEN_URL", Value: "https://custom/maven/"}},
+				},
+				{
+					Name: "release",
+				},
+			},
+		},
+	}
+	env := []corev1.EnvVar{
+		{Name: "ARTIFACT_REPOSITORY_MAVEN_URL", Value: "https://artifacts/maven/"},
+		{Name: "ARTIFACT_REPOSITORY_NPM_URL", Value: "https://artifacts/npm/"},
+	}
+
+	tekton.ApplyDefaultEnv(env, []*v1alpha1.Task{task})
+
+	assert.Equal(t, []corev1.EnvVar{
+		{Name: "ARTIFACT_REPOSITORY_MAVEN_URL", Value: "https://custom/maven/"},
+		{Name: "ARTIFACT_REPOSITORY_NPM_URL", Value: "https://artifacts/npm/"},
+	}, task.Spec.Steps[0].Env, "should not override the env of the step")
+	assert.Equal(t, env, task.Spec.Steps[1].Env)
+}
@@ -25,4 +25,7 @@ const (
 
 	// LabelType is the label added to Tekton CRDs for the type of pipeline.
 	LabelType = "jenkins.io/pipelineType"
+
+	// LabelKind is the label added to Tekton PipelineRuns for the kind of pipeline such as release or pullrequest.
+	LabelKind = "jenkins.io/pipelineKind"
 )
@@ -0,0 +1,139 @@
+package cronpipelines
+
+import (
+	"encoding/json"
+	"sort"
+	"time"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/client/clientset/versioned"
+	"github.com/jenkins-x/jx/pkg/cron"
+	"github.com/jenkins-x/jx/pkg/jenkinsfile"
+	"github.com/jenkins-x/jx/pkg/kube"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// AnnotationTriggers the annotation on a SourceRepository holding the JSON of the triggers declared in its
+	// jenkins-x.yml
+	AnnotationTriggers = "jenkins.io/cron-triggers"
+
+	// AnnotationLastScheduled the annotation on a SourceRepository holding the RFC3339 time its pipeline was last
+	// scheduled. The next pipeline is due at the first time matching the schedule after it
+	AnnotationLastScheduled = "jenkins.io/cron-last-scheduled"
+
+	// LabelCronTrigger the label added to the PipelineRuns triggered by a schedule
+	LabelCronTrigger = "jenkins.io/cron-trigger"
+)
+
+// CronPipeline the scheduled pipeline of a SourceRepository
+type CronPipeline struct {
+	SourceRepository *v1.SourceRepository
+	Triggers         jenkinsfile.Triggers
+	Schedule         *cron.Schedule
+	LastScheduled    time.Time
+}
+
+// Name returns the owner and name of the repository
+func (c *CronPipeline) Name() string {
+	return c.SourceRepository.Spec.Org + "/" + c.SourceRepository.Spec.Repo
+}
+
+// Next returns the time the next pipeline is due or the zero time if the schedule never matches
+func (c *CronPipeline) Next() time.Time {
+	return c.Schedule.Next(c.LastScheduled)
+}
+
+// Register stores the cron triggers of the repository on its SourceRepository so that the scheduled pipelines are
+// created by 'jx controller cron-pipelines'. Any previous registration is removed if there are no triggers with a
+// schedule. The time of the last scheduled pipeline is reset to now whenever the triggers change
+func Register(jxClient versioned.Interface, ns string, owner string, repository string, triggers *jenkinsfile.Triggers, now time.Time) error {
+	sr, err := kube.FindSourceRepository(jxClient, ns, owner, repository)
+	if err != nil {
+		return err
+	}
+	value := ""
+	if triggers != nil && triggers.Cron != "" {
+		err = triggers.Validate()
+		if err != nil {
+			return errors.Wrapf(err, "invalid triggers for %s/%s", owner, repository)
+		}
+		data, err := json.Marshal(triggers)
+		if err != nil {
+			return errors.Wrap(err, "failed to marshal the triggers")
+		}
+		value = string(data)
+	}
+	if sr.Annotations[AnnotationTriggers] == value {
+		return nil
+	}
+	if value == "" {
+		delete(sr.Annotations, AnnotationTriggers)
+		delete(sr.Annotations, AnnotationLastScheduled)
+	} else {
+		if sr.Annotations == nil {
+			sr.Annotations = map[string]string{}
+		}
+		sr.Annotations[AnnotationTriggers] = value
+		sr.Annotations[AnnotationLastScheduled] = now.UTC().Format(time.RFC3339)
+	}
+	_, err = jxClient.JenkinsV1().SourceRepositories(ns).Update(sr)
+	if err != nil {
+		return errors.Wrapf(err, "failed to update the triggers of SourceRepository %s", sr.Name)
+	}
+	return nil
+}
+
+// List returns the scheduled pipelines of the SourceRepositories in the namespace sorted by name. Repositories with
+// invalid triggers are ignored with a warning
+func List(jxClient versioned.Interface, ns string) ([]*CronPipeline, error) {
+	repositories, err := jxClient.JenkinsV1().SourceRepositories(ns).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, errors.Wrapf(err, "listing SourceRepositories in namespace %s", ns)
+	}
+	answer := []*CronPipeline{}
+	for i := range repositories.Items {
+		sr := &repositories.Items[i]
+		value := sr.Annotations[AnnotationTriggers]
+		if value == "" {
+			continue
+		}
+		cp, err := toCronPipeline(sr, value)
+		if err != nil {
+			log.Logger().Warnf("Ignoring the triggers of SourceRepository %s: %s", sr.Name, err)
+			continue
+		}
+		answer = append(answer, cp)
+	}
+	sort.Slice(answer, func(i, j int) bool {
+		return answer[i].Name() < answer[j].Name()
+	})
+	return answer, nil
+}
+
+func toCronPipeline(sr *v1.SourceRepository, value string) (*CronPipeline, error) {
+	answer := &CronPipeline{SourceRepository: sr}
+	err := json.Unmarshal([]byte(value), &answer.Triggers)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal the triggers")
+	}
+	err = answer.Triggers.Validate()
+	if err != nil {
+		return nil, err
+	}
+	answer.Schedule, err = cron.Parse(answer.Triggers.Cron)
+	if err != nil {
+		return nil, err
+	}
+	answer.LastScheduled = sr.CreationTimestamp.Time
+	text := sr.Annotations[AnnotationLastScheduled]
+	if text != "" {
+		answer.LastScheduled, err = time.Parse(time.RFC3339, text)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid annotation %s", AnnotationLastScheduled)
+		}
+	}
+	return answer, nil
+}
@@ -0,0 +1,146 @@
+package cronpipelines
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jenkins-x/jx/pkg/client/clientset/versioned"
+	"github.com/jenkins-x/jx/pkg/jenkinsfile"
+	"github.com/jenkins-x/jx/pkg/kube"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/tekton"
+	"github.com/jenkins-x/jx/pkg/tekton/metapipeline"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+	pipelineapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	tektonclient "github.com/tektoncd/pipeline/pkg/client/clientset/versioned"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Reconciler creates the pipelines of the SourceRepositories whose schedule is due
+type Reconciler struct {
+	JXClient           versioned.Interface
+	TektonClient       tektonclient.Interface
+	MetaPipelineClient metapipeline.Client
+	Namespace          string
+	ServiceAccount     string
+
+	// Now returns the current time. Defaults to time.Now
+	Now func() time.Time
+}
+
+// Reconcile creates the pipelines which are due. Runs which were missed, for example while the controller was not
+// running, are coalesced into a single pipeline
+func (r *Reconciler) Reconcile() error {
+	pipelines, err := List(r.JXClient, r.Namespace)
+	if err != nil {
+		return err
+	}
+	for _, cp := range pipelines {
+		err = r.reconcilePipeline(cp)
+		if err != nil {
+			log.Logger().Warnf("Failed to schedule the pipeline of %s: %s", cp.Name(), err)
+		}
+	}
+	return nil
+}
+
+func (r *Reconciler) reconcilePipeline(cp *CronPipeline) error {
+	now := time.Now()
+	if r.Now != nil {
+		now = r.Now()
+	}
+	next := cp.Next()
+	if next.IsZero() || next.After(now) {
+		return nil
+	}
+	running, err := r.runningPipelineRuns(cp)
+	if err != nil {
+		return err
+	}
+	if len(running) > 0 {
+		if cp.Triggers.GetOverlapPolicy() == jenkinsfile.OverlapPolicySkip {
+			log.Logger().Infof("Skipping the scheduled pipeline of %s as the previous one is still running", util.ColorInfo(cp.Name()))
+			return r.updateLastScheduled(cp, now)
+		}
+		for _, pr := range running {
+			log.Logger().Infof("Cancelling the scheduled PipelineRun %s of %s", util.ColorInfo(pr.Name), util.ColorInfo(cp.Name()))
+			err = tekton.CancelPipelineRun(r.TektonClient, r.Namespace, pr)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	param, err := r.pipelineCreateParam(cp)
+	if err != nil {
+		return err
+	}
+	activityKey, crds, err := r.MetaPipelineClient.Create(param)
+	if err != nil {
+		return errors.Wrap(err, "failed to create the pipeline")
+	}
+	err = r.MetaPipelineClient.Apply(activityKey, crds)
+	if err != nil {
+		return errors.Wrap(err, "failed to apply the pipeline")
+	}
+	log.Logger().Infof("Triggered the scheduled pipeline of %s branch %s", util.ColorInfo(cp.Name()), util.ColorInfo(cp.Triggers.GetBranch()))
+	return r.updateLastScheduled(cp, now)
+}
+
+func (r *Reconciler) pipelineCreateParam(cp *CronPipeline) (metapipeline.PipelineCreateParam, error) {
+	gitURL, err := kube.GetRepositoryGitURL(cp.SourceRepository)
+	if err != nil {
+		return metapipeline.PipelineCreateParam{}, err
+	}
+	branch := cp.Triggers.GetBranch()
+	kind := metapipeline.FeaturePipeline
+	if branch == "master" {
+		kind = metapipeline.ReleasePipeline
+	}
+	return metapipeline.PipelineCreateParam{
+		PullRef:        metapipeline.NewPullRef(gitURL, branch, ""),
+		PipelineKind:   kind,
+		Parameters:     cp.Triggers.Parameters,
+		Labels:         map[string]string{LabelCronTrigger: "true"},
+		ServiceAccount: r.ServiceAccount,
+	}, nil
+}
+
+// runningPipelineRuns returns the PipelineRuns triggered by the schedule of the repository which have not completed
+func (r *Reconciler) runningPipelineRuns(cp *CronPipeline) ([]*pipelineapi.PipelineRun, error) {
+	selector := fmt.Sprintf("%s=true,%s=%s,%s=%s,%s=%s", LabelCronTrigger,
+		tekton.LabelOwner, cp.SourceRepository.Spec.Org, tekton.LabelRepo, cp.SourceRepository.Spec.Repo,
+		tekton.LabelBranch, cp.Triggers.GetBranch())
+	runs, err := r.TektonClient.TektonV1alpha1().PipelineRuns(r.Namespace).List(metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list the PipelineRuns in namespace %s", r.Namespace)
+	}
+	answer := []*pipelineapi.PipelineRun{}
+	for i := range runs.Items {
+		pr := &runs.Items[i]
+		if !tekton.PipelineRunIsComplete(pr) && pr.Spec.Status != pipelineapi.PipelineRunSpecStatusCancelled {
+			answer = append(answer, pr)
+		}
+	}
+	return answer, nil
+}
+
+func (r *Reconciler) updateLastScheduled(cp *CronPipeline, now time.Time) error {
+	repositories := r.JXClient.JenkinsV1().SourceRepositories(r.Namespace)
+	sr, err := repositories.Get(cp.SourceRepository.Name, metav1.GetOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "failed to get SourceRepository %s", cp.SourceRepository.Name)
+	}
+	if sr.Annotations == nil {
+		sr.Annotations = map[string]string{}
+	}
+	sr.Annotations[AnnotationLastScheduled] = now.UTC().Format(time.RFC3339)
+	_, err = repositories.Update(sr)
+	if err != nil {
+		return errors.Wrapf(err, "failed to update SourceRepository %s", cp.SourceRepository.Name)
+	}
+	cp.SourceRepository = sr
+	cp.LastScheduled = now
+	return nil
+}
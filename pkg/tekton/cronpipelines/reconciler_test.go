@@ -0,0 +1,136 @@
+package cronpipelines_test
+
+import (
+	"testing"
+	"time"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/client/clientset/versioned/fake"
+	"github.com/jenkins-x/jx/pkg/jenkinsfile"
+	"github.com/jenkins-x/jx/pkg/kube"
+	"github.com/jenkins-x/jx/pkg/tekton"
+	"github.com/jenkins-x/jx/pkg/tekton/cronpipelines"
+	"github.com/jenkins-x/jx/pkg/tekton/metapipeline"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	pipelineapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	tektonfake "github.com/tektoncd/pipeline/pkg/client/clientset/versioned/fake"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type fakeMetaPipelineClient struct {
+	params []metapipeline.PipelineCreateParam
+}
+
+func (f *fakeMetaPipelineClient) Create(param metapipeline.PipelineCreateParam) (kube.PromoteStepActivityKey, tekton.CRDWrapper, error) {
+	f.params = append(f.params, param)
+	return kube.PromoteStepActivityKey{}, tekton.CRDWrapper{}, nil
+}
+
+func (f *fakeMetaPipelineClient) Apply(pipelineActivity kube.PromoteStepActivityKey, crds tekton.CRDWrapper) error {
+	return nil
+}
+
+func (f *fakeMetaPipelineClient) Close() error {
+	return nil
+}
+
+func TestRegisterAndList(t *testing.T) {
+	t.Parallel()
+	ns := "jx"
+	jxClient := fake.NewSimpleClientset(&v1.SourceRepository{
+		ObjectMeta: metav1.ObjectMeta{Name: "myorg-myapp", Namespace: ns},
+		Spec:       v1.SourceRepositorySpec{Org: "myorg", Repo: "myapp", Provider: "https://github.com"},
+	})
+	now := time.Date(2019, 11, 4, 10, 30, 0, 0, time.UTC)
+
+	err := cronpipelines.Register(jxClient, ns, "myorg", "myapp", &jenkinsfile.Triggers{Cron: "0 4 * * *"}, now)
+	require.NoError(t, err)
+
+	pipelines, err := cronpipelines.List(jxClient, ns)
+	require.NoError(t, err)
+	require.Len(t, pipelines, 1)
+	assert.Equal(t, "myorg/myapp", pipelines[0].Name())
+	assert.Equal(t, now, pipelines[0].LastScheduled)
+	assert.Equal(t, time.Date(2019, 11, 5, 4, 0, 0, 0, time.UTC), pipelines[0].Next())
+
+	err = cronpipelines.Register(jxClient, ns, "myorg", "myapp", &jenkinsfile.Triggers{Cron: "bad"}, now)
+	assert.Error(t, err)
+
+	err = cronpipelines.Register(jxClient, ns, "myorg", "myapp", nil, now)
+	require.NoError(t, err)
+	pipelines, err = cronpipelines.List(jxClient, ns)
+	require.NoError(t, err)
+	assert.Empty(t, pipelines)
+}
+
+func TestReconcile(t *testing.T) {
+	t.Parallel()
+	ns := "jx"
+	jxClient := fake.NewSimpleClientset(&v1.SourceRepository{
+		ObjectMeta: metav1.ObjectMeta{Name: "myorg-myapp", Namespace: ns},
+		Spec:       v1.SourceRepositorySpec{Org: "myorg", Repo: "myapp", Provider: "https://github.com"},
+	})
+	tektonClient := tektonfake.NewSimpleClientset()
+	metaClient := &fakeMetaPipelineClient{}
+	now := time.Date(2019, 11, 4, 10, 30, 0, 0, time.UTC)
+	reconciler := &cronpipelines.Reconciler{
+		JXClient:           jxClient,
+		TektonClient:       tektonClient,
+		MetaPipelineClient: metaClient,
+		Namespace:          ns,
+		Now: func() time.Time {
+			return now
+		},
+	}
+	triggers := &jenkinsfile.Triggers{Cron: "0 * * * *", Parameters: map[string]string{"refresh": "true"}}
+	err := cronpipelines.Register(jxClient, ns, "myorg", "myapp", triggers, now)
+	require.NoError(t, err)
+
+	// not due yet
+	require.NoError(t, reconciler.Reconcile())
+	assert.Empty(t, metaClient.params)
+
+	// several missed runs are coalesced into one pipeline
+	now = now.Add(3 * time.Hour)
+	require.NoError(t, reconciler.Reconcile())
+	require.Len(t, metaClient.params, 1)
+	param := metaClient.params[0]
+	assert.Equal(t, "https://github.com/myorg/myapp.git", param.PullRef.SourceURL())
+	assert.Equal(t, "master", param.PullRef.BaseBranch())
+	assert.Equal(t, metapipeline.ReleasePipeline, param.PipelineKind)
+	assert.Equal(t, map[string]string{"refresh": "true"}, param.Parameters)
+	assert.Equal(t, "true", param.Labels[cronpipelines.LabelCronTrigger])
+
+	require.NoError(t, reconciler.Reconcile())
+	assert.Len(t, metaClient.params, 1)
+
+	// the next run is skipped while the previous one is still running
+	_, err = tektonClient.TektonV1alpha1().PipelineRuns(ns).Create(&pipelineapi.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "myorg-myapp-master-1",
+			Namespace: ns,
+			Labels: map[string]string{
+				cronpipelines.LabelCronTrigger: "true",
+				tekton.LabelOwner:              "myorg",
+				tekton.LabelRepo:               "myapp",
+				tekton.LabelBranch:             "master",
+			},
+		},
+	})
+	require.NoError(t, err)
+	now = now.Add(time.Hour)
+	require.NoError(t, reconciler.Reconcile())
+	assert.Len(t, metaClient.params, 1)
+
+	// the running pipeline is cancelled with the replace policy
+	triggers.OverlapPolicy = jenkinsfile.OverlapPolicyReplace
+	err = cronpipelines.Register(jxClient, ns, "myorg", "myapp", triggers, now)
+	require.NoError(t, err)
+	now = now.Add(time.Hour)
+	require.NoError(t, reconciler.Reconcile())
+	assert.Len(t, metaClient.params, 2)
+	pr, err := tektonClient.TektonV1alpha1().PipelineRuns(ns).Get("myorg-myapp-master-1", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, pipelineapi.PipelineRunSpecStatusCancelled, pr.Spec.Status)
+}
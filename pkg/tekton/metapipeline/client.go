@@ -21,6 +21,10 @@ type PipelineCreateParam struct {
 	// build pipeline.
 	EnvVariables map[string]string
 
+	// Parameters defines a set of custom params passed to the build pipeline as Tekton params which are also exposed
+	// to its steps as upper case environment variables.
+	Parameters map[string]string
+
 	// Labels defines a set of labels to be applied to the generated CRDs.
 	Labels map[string]string
 
@@ -134,6 +134,7 @@ func (c *clientFactory) Create(param PipelineCreateParam) (kube.PromoteStepActiv
 		ServiceAccount:      param.ServiceAccount,
 		Labels:              param.Labels,
 		EnvVars:             param.EnvVariables,
+		Params:              param.Parameters,
 		DefaultImage:        param.DefaultImage,
 		Apps:                extendingApps,
 		VersionsDir:         c.versionDir,
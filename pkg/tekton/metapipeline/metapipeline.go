@@ -53,6 +53,7 @@ type CRDCreationParameters struct {
 	ServiceAccount      string
 	Labels              map[string]string
 	EnvVars             map[string]string
+	Params              map[string]string
 	DefaultImage        string
 	Apps                []jenkinsv1.App
 	VersionsDir         string
@@ -265,6 +266,9 @@ func stepCreateTektonCRDs(params CRDCreationParameters) syntax.Step {
 	for k, v := range params.Labels {
 		args = append(args, "--label", fmt.Sprintf("%s=%s", k, v))
 	}
+	for _, p := range syntax.PipelineParamArgs(params.Params) {
+		args = append(args, "--param", p)
+	}
 
 	step := syntax.Step{
 		Name:      createTektonCRDsStepName,
@@ -0,0 +1,130 @@
+package tekton
+
+import (
+	"fmt"
+
+	jenkinsv1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/builds"
+	"github.com/jenkins-x/jx/pkg/client/clientset/versioned"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+	pipelineapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	tektonclient "github.com/tektoncd/pipeline/pkg/client/clientset/versioned"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+)
+
+// AbortPipelineRun cancels the PipelineRun, deletes its pods which are still running and marks its PipelineActivity as
+// aborted for the given reason
+func AbortPipelineRun(kubeClient kubernetes.Interface, tektonClient tektonclient.Interface, jxClient versioned.Interface, ns string, pr *pipelineapi.PipelineRun, reason string) error {
+	if pr.Spec.Status != pipelineapi.PipelineRunSpecStatusCancelled {
+		err := CancelPipelineRun(tektonClient, ns, pr)
+		if err != nil {
+			return err
+		}
+	}
+	err := deleteRunningPods(kubeClient, ns, pr.Name)
+	if err != nil {
+		return err
+	}
+	activity, err := FindPipelineActivityForRun(jxClient, ns, pr)
+	if err != nil {
+		return err
+	}
+	if activity == nil {
+		log.Logger().Warnf("no PipelineActivity found for PipelineRun %s", pr.Name)
+		return nil
+	}
+	return AbortPipelineActivity(jxClient, ns, activity, reason)
+}
+
+// AbortPipelineActivity marks the PipelineActivity and its unfinished stages as aborted for the given reason. If the
+// PipelineRun of the activity is queued it is removed from the pipeline queue
+func AbortPipelineActivity(jxClient versioned.Interface, ns string, activity *jenkinsv1.PipelineActivity, reason string) error {
+	name := activity.Name
+	activities := jxClient.JenkinsV1().PipelineActivities(ns)
+	activity, err := activities.Get(name, metav1.GetOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "failed to get the PipelineActivity %s", name)
+	}
+	now := metav1.Now()
+	spec := &activity.Spec
+	spec.Status = jenkinsv1.ActivityStatusTypeAborted
+	spec.Message = reason
+	if spec.CompletedTimestamp == nil {
+		spec.CompletedTimestamp = &now
+	}
+	for i := range spec.Steps {
+		stage := spec.Steps[i].Stage
+		if stage == nil || stage.Status.IsTerminated() || stage.Status == jenkinsv1.ActivityStatusTypeNotExecuted {
+			continue
+		}
+		if stage.Status == jenkinsv1.ActivityStatusTypePending {
+			stage.Status = jenkinsv1.ActivityStatusTypeNotExecuted
+		} else {
+			stage.Status = jenkinsv1.ActivityStatusTypeAborted
+			stage.CompletedTimestamp = &now
+		}
+	}
+	delete(activity.Annotations, QueuedPipelineRunAnnotation)
+	delete(activity.Annotations, QueuedTimeAnnotation)
+	_, err = activities.Update(activity)
+	if err != nil {
+		return errors.Wrapf(err, "failed to update the PipelineActivity %s", activity.Name)
+	}
+	log.Logger().Infof("marked PipelineActivity %s as %s: %s", util.ColorInfo(activity.Name), util.ColorWarning(string(jenkinsv1.ActivityStatusTypeAborted)), reason)
+	return nil
+}
+
+// FindPipelineActivityForRun returns the PipelineActivity of the build of the PipelineRun or nil if there is none
+func FindPipelineActivityForRun(jxClient versioned.Interface, ns string, pr *pipelineapi.PipelineRun) (*jenkinsv1.PipelineActivity, error) {
+	selector := labels.Set{
+		jenkinsv1.LabelOwner:      pr.Labels[LabelOwner],
+		jenkinsv1.LabelRepository: pr.Labels[LabelRepo],
+		jenkinsv1.LabelBranch:     pr.Labels[LabelBranch],
+		jenkinsv1.LabelBuild:      pr.Labels[LabelBuild],
+	}
+	if pr.Labels[LabelContext] != "" {
+		selector[jenkinsv1.LabelContext] = pr.Labels[LabelContext]
+	}
+	list, err := jxClient.JenkinsV1().PipelineActivities(ns).List(metav1.ListOptions{
+		LabelSelector: selector.AsSelector().String(),
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list the PipelineActivities in namespace %s", ns)
+	}
+	for i := range list.Items {
+		activity := &list.Items[i]
+		if pr.Labels[LabelContext] == "" && activity.Spec.Context != "" {
+			continue
+		}
+		return activity, nil
+	}
+	return nil, nil
+}
+
+// deleteRunningPods deletes the pods of the PipelineRun which have not terminated so that no step carries on running
+// after the PipelineRun is cancelled. The pods which have terminated are kept for their logs
+func deleteRunningPods(kubeClient kubernetes.Interface, ns string, prName string) error {
+	pods := kubeClient.CoreV1().Pods(ns)
+	podList, err := pods.List(metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=%s", builds.LabelPipelineRunName, prName),
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to list the pods of PipelineRun %s", prName)
+	}
+	for _, pod := range podList.Items {
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		err = pods.Delete(pod.Name, &metav1.DeleteOptions{})
+		if err != nil {
+			return errors.Wrapf(err, "failed to delete pod %s of PipelineRun %s", pod.Name, prName)
+		}
+		log.Logger().Infof("deleted pod %s", util.ColorInfo(pod.Name))
+	}
+	return nil
+}
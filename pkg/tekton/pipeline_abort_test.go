@@ -0,0 +1,85 @@
+package tekton_test
+
+import (
+	"testing"
+
+	jenkinsv1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/builds"
+	jxfake "github.com/jenkins-x/jx/pkg/client/clientset/versioned/fake"
+	"github.com/jenkins-x/jx/pkg/tekton"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	tektonfake "github.com/tektoncd/pipeline/pkg/client/clientset/versioned/fake"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestAbortPipelineRun(t *testing.T) {
+	pr := &v1alpha1.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "myorg-app1-master-abcde-3",
+			Namespace: ns,
+			Labels: map[string]string{
+				tekton.LabelOwner:  "myorg",
+				tekton.LabelRepo:   "app1",
+				tekton.LabelBranch: "master",
+				tekton.LabelBuild:  "3",
+			},
+		},
+	}
+	pod := func(name string, phase corev1.PodPhase) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: ns,
+				Labels:    map[string]string{builds.LabelPipelineRunName: pr.Name},
+			},
+			Status: corev1.PodStatus{Phase: phase},
+		}
+	}
+	kubeClient := kubefake.NewSimpleClientset(pod("build", corev1.PodSucceeded), pod("test", corev1.PodRunning))
+	tektonClient := tektonfake.NewSimpleClientset(pr)
+	jxClient := jxfake.NewSimpleClientset(&jenkinsv1.PipelineActivity{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "myorg-app1-master-3",
+			Namespace: ns,
+			Labels: map[string]string{
+				jenkinsv1.LabelOwner:      "myorg",
+				jenkinsv1.LabelRepository: "app1",
+				jenkinsv1.LabelBranch:     "master",
+				jenkinsv1.LabelBuild:      "3",
+			},
+		},
+		Spec: jenkinsv1.PipelineActivitySpec{
+			Status: jenkinsv1.ActivityStatusTypeRunning,
+			Steps: []jenkinsv1.PipelineActivityStep{
+				{Stage: &jenkinsv1.StageActivityStep{CoreActivityStep: jenkinsv1.CoreActivityStep{Name: "build", Status: jenkinsv1.ActivityStatusTypeSucceeded}}},
+				{Stage: &jenkinsv1.StageActivityStep{CoreActivityStep: jenkinsv1.CoreActivityStep{Name: "test", Status: jenkinsv1.ActivityStatusTypeRunning}}},
+				{Stage: &jenkinsv1.StageActivityStep{CoreActivityStep: jenkinsv1.CoreActivityStep{Name: "promote", Status: jenkinsv1.ActivityStatusTypePending}}},
+			},
+		},
+	})
+
+	err := tekton.AbortPipelineRun(kubeClient, tektonClient, jxClient, ns, pr, "superseded")
+	require.NoError(t, err)
+
+	cancelled, err := tektonClient.TektonV1alpha1().PipelineRuns(ns).Get(pr.Name, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, v1alpha1.PipelineRunSpecStatusCancelled, cancelled.Spec.Status)
+
+	pods, err := kubeClient.CoreV1().Pods(ns).List(metav1.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, pods.Items, 1, "the running pod should be deleted")
+	assert.Equal(t, "build", pods.Items[0].Name)
+
+	activity, err := jxClient.JenkinsV1().PipelineActivities(ns).Get("myorg-app1-master-3", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, jenkinsv1.ActivityStatusTypeAborted, activity.Spec.Status)
+	assert.Equal(t, "superseded", activity.Spec.Message)
+	assert.NotNil(t, activity.Spec.CompletedTimestamp)
+	assert.Equal(t, jenkinsv1.ActivityStatusTypeSucceeded, activity.Spec.Steps[0].Stage.Status)
+	assert.Equal(t, jenkinsv1.ActivityStatusTypeAborted, activity.Spec.Steps[1].Stage.Status)
+	assert.Equal(t, jenkinsv1.ActivityStatusTypeNotExecuted, activity.Spec.Steps[2].Stage.Status)
+}
@@ -0,0 +1,282 @@
+package tekton
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	jenkinsv1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/client/clientset/versioned"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+	pipelineapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	tektonclient "github.com/tektoncd/pipeline/pkg/client/clientset/versioned"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// QueuedPipelineRunAnnotation the annotation of a PipelineActivity storing its PipelineRun while it waits in the
+	// pipeline queue
+	QueuedPipelineRunAnnotation = "jenkins.io/queued-pipelinerun"
+
+	// QueuedTimeAnnotation the annotation of a PipelineActivity storing the time its PipelineRun was queued
+	QueuedTimeAnnotation = "jenkins.io/queued-time"
+)
+
+// QueuedPipelineRun a PipelineRun waiting in the pipeline queue for a running PipelineRun to complete
+type QueuedPipelineRun struct {
+	Activity    *jenkinsv1.PipelineActivity
+	PipelineRun *pipelineapi.PipelineRun
+	Priority    int
+	QueuedTime  time.Time
+}
+
+// GetPipelineQueue returns the queued PipelineRuns in the order they start: highest priority first then first in first out
+func GetPipelineQueue(jxClient versioned.Interface, ns string, concurrency *jenkinsv1.PipelineConcurrency) ([]*QueuedPipelineRun, error) {
+	activities, err := jxClient.JenkinsV1().PipelineActivities(ns).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list the PipelineActivities in namespace %s", ns)
+	}
+	answer := []*QueuedPipelineRun{}
+	for i := range activities.Items {
+		activity := &activities.Items[i]
+		data := activity.Annotations[QueuedPipelineRunAnnotation]
+		if data == "" {
+			continue
+		}
+		run := &pipelineapi.PipelineRun{}
+		err = json.Unmarshal([]byte(data), run)
+		if err != nil {
+			log.Logger().Warnf("Ignoring the invalid queued PipelineRun of PipelineActivity %s: %s", activity.Name, err)
+			continue
+		}
+		queuedTime := activity.CreationTimestamp.Time
+		t, err := time.Parse(time.RFC3339Nano, activity.Annotations[QueuedTimeAnnotation])
+		if err == nil {
+			queuedTime = t
+		}
+		answer = append(answer, &QueuedPipelineRun{
+			Activity:    activity,
+			PipelineRun: run,
+			Priority:    concurrency.GetPriority(run.Labels[LabelKind]),
+			QueuedTime:  queuedTime,
+		})
+	}
+	sort.SliceStable(answer, func(i, j int) bool {
+		if answer[i].Priority != answer[j].Priority {
+			return answer[i].Priority > answer[j].Priority
+		}
+		return answer[i].QueuedTime.Before(answer[j].QueuedTime)
+	})
+	return answer, nil
+}
+
+// GetRunningPipelineRuns returns the build PipelineRuns which have not completed. Meta pipelines are not included as
+// they create the build PipelineRuns
+func GetRunningPipelineRuns(tektonClient tektonclient.Interface, ns string) ([]*pipelineapi.PipelineRun, error) {
+	runs, err := tektonClient.TektonV1alpha1().PipelineRuns(ns).List(metav1.ListOptions{
+		LabelSelector: LabelType + "=" + BuildPipeline.String(),
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list the PipelineRuns in namespace %s", ns)
+	}
+	answer := []*pipelineapi.PipelineRun{}
+	for i := range runs.Items {
+		run := &runs.Items[i]
+		if !PipelineRunIsComplete(run) && run.Spec.Status != pipelineapi.PipelineRunSpecStatusCancelled {
+			answer = append(answer, run)
+		}
+	}
+	return answer, nil
+}
+
+// CanStartPipelineRun returns true if the PipelineRun can start without exceeding the concurrency limits otherwise
+// the reason why it cannot start
+func CanStartPipelineRun(concurrency *jenkinsv1.PipelineConcurrency, running []*pipelineapi.PipelineRun, run *pipelineapi.PipelineRun) (bool, string) {
+	if !concurrency.IsLimited() {
+		return true, ""
+	}
+	if concurrency.MaxRuns > 0 && len(running) >= concurrency.MaxRuns {
+		return false, fmt.Sprintf("%d pipelines are running in the cluster", len(running))
+	}
+	if concurrency.MaxRunsPerRepository > 0 {
+		count := 0
+		for _, r := range running {
+			if isSameRepository(r, run) {
+				count++
+			}
+		}
+		if count >= concurrency.MaxRunsPerRepository {
+			return false, fmt.Sprintf("%d pipelines are running for the repository %s/%s", count, run.Labels[LabelOwner], run.Labels[LabelRepo])
+		}
+	}
+	return true, ""
+}
+
+// QueuePipelineRun stores the PipelineRun on its PipelineActivity until it can start
+func QueuePipelineRun(jxClient versioned.Interface, ns string, activity *jenkinsv1.PipelineActivity, run *pipelineapi.PipelineRun) error {
+	data, err := json.Marshal(run)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal the PipelineRun %s", run.Name)
+	}
+	name := activity.Name
+	activities := jxClient.JenkinsV1().PipelineActivities(ns)
+	activity, err = activities.Get(name, metav1.GetOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "failed to get the PipelineActivity %s", name)
+	}
+	if activity.Annotations == nil {
+		activity.Annotations = map[string]string{}
+	}
+	activity.Annotations[QueuedPipelineRunAnnotation] = string(data)
+	activity.Annotations[QueuedTimeAnnotation] = time.Now().UTC().Format(time.RFC3339Nano)
+	_, err = activities.Update(activity)
+	if err != nil {
+		return errors.Wrapf(err, "failed to update the PipelineActivity %s", activity.Name)
+	}
+	return nil
+}
+
+// StartQueuedPipelineRun creates the queued PipelineRun and removes it from the queue
+func StartQueuedPipelineRun(jxClient versioned.Interface, tektonClient tektonclient.Interface, ns string, queued *QueuedPipelineRun) (*pipelineapi.PipelineRun, error) {
+	run, err := ApplyPipelineRun(tektonClient, ns, queued.PipelineRun)
+	if err != nil {
+		return nil, err
+	}
+	activities := jxClient.JenkinsV1().PipelineActivities(ns)
+	activity, err := activities.Get(queued.Activity.Name, metav1.GetOptions{})
+	if err != nil {
+		return run, errors.Wrapf(err, "failed to get the PipelineActivity %s", queued.Activity.Name)
+	}
+	delete(activity.Annotations, QueuedPipelineRunAnnotation)
+	delete(activity.Annotations, QueuedTimeAnnotation)
+	_, err = activities.Update(activity)
+	if err != nil {
+		return run, errors.Wrapf(err, "failed to update the PipelineActivity %s", activity.Name)
+	}
+	log.Logger().Infof("started the queued PipelineRun %s", util.ColorInfo(run.Name))
+	return run, nil
+}
+
+// ProcessPipelineQueue starts the queued PipelineRuns which no longer exceed the concurrency limits. If preemption is
+// enabled the running PipelineRuns with a lower priority are cancelled to start the queued PipelineRuns
+func ProcessPipelineQueue(jxClient versioned.Interface, tektonClient tektonclient.Interface, ns string, concurrency *jenkinsv1.PipelineConcurrency) error {
+	queue, err := GetPipelineQueue(jxClient, ns, concurrency)
+	if err != nil {
+		return err
+	}
+	if len(queue) == 0 {
+		return nil
+	}
+	running, err := GetRunningPipelineRuns(tektonClient, ns)
+	if err != nil {
+		return err
+	}
+	for _, queued := range queue {
+		ok, _ := CanStartPipelineRun(concurrency, running, queued.PipelineRun)
+		if !ok && concurrency.Preempt {
+			victim := preemptionCandidate(concurrency, running, queued)
+			if victim != nil {
+				err = CancelPipelineRun(tektonClient, ns, victim)
+				if err != nil {
+					return err
+				}
+				log.Logger().Infof("cancelled PipelineRun %s to start the higher priority PipelineRun %s", util.ColorWarning(victim.Name), util.ColorInfo(queued.PipelineRun.Name))
+				running = removePipelineRun(running, victim)
+				ok, _ = CanStartPipelineRun(concurrency, running, queued.PipelineRun)
+			}
+		}
+		if !ok {
+			continue
+		}
+		run, err := StartQueuedPipelineRun(jxClient, tektonClient, ns, queued)
+		if err != nil {
+			return err
+		}
+		running = append(running, run)
+	}
+	return nil
+}
+
+// queuePipelineRunIfLimited queues the PipelineRun if it cannot start or if PipelineRuns which should start before it
+// are already queued. Returns true if the PipelineRun was queued
+func queuePipelineRunIfLimited(jxClient versioned.Interface, tektonClient tektonclient.Interface, ns string, concurrency *jenkinsv1.PipelineConcurrency, activity *jenkinsv1.PipelineActivity, run *pipelineapi.PipelineRun) (bool, error) {
+	running, err := GetRunningPipelineRuns(tektonClient, ns)
+	if err != nil {
+		return false, err
+	}
+	ok, reason := CanStartPipelineRun(concurrency, running, run)
+	if ok {
+		queue, err := GetPipelineQueue(jxClient, ns, concurrency)
+		if err != nil {
+			return false, err
+		}
+		priority := concurrency.GetPriority(run.Labels[LabelKind])
+		for _, queued := range queue {
+			if queued.Priority >= priority && (concurrency.MaxRuns > 0 || isSameRepository(queued.PipelineRun, run)) {
+				ok = false
+				reason = fmt.Sprintf("PipelineRun %s is queued before it", queued.PipelineRun.Name)
+				break
+			}
+		}
+	}
+	if ok {
+		return false, nil
+	}
+	err = QueuePipelineRun(jxClient, ns, activity, run)
+	if err != nil {
+		return false, err
+	}
+	log.Logger().Infof("queued PipelineRun %s as %s", util.ColorInfo(run.Name), reason)
+	return true, nil
+}
+
+// preemptionCandidate returns the running PipelineRun with the lowest priority, and the most recent, which can be
+// cancelled to start the queued PipelineRun or nil if there is none
+func preemptionCandidate(concurrency *jenkinsv1.PipelineConcurrency, running []*pipelineapi.PipelineRun, queued *QueuedPipelineRun) *pipelineapi.PipelineRun {
+	// if the limit of the repository is reached only cancelling a PipelineRun of the same repository lets it start
+	sameRepository := false
+	if concurrency.MaxRunsPerRepository > 0 {
+		count := 0
+		for _, run := range running {
+			if isSameRepository(run, queued.PipelineRun) {
+				count++
+			}
+		}
+		sameRepository = count >= concurrency.MaxRunsPerRepository
+	}
+	var answer *pipelineapi.PipelineRun
+	answerPriority := 0
+	for _, run := range running {
+		priority := concurrency.GetPriority(run.Labels[LabelKind])
+		if priority >= queued.Priority {
+			continue
+		}
+		if sameRepository && !isSameRepository(run, queued.PipelineRun) {
+			continue
+		}
+		if answer == nil || priority < answerPriority || (priority == answerPriority && answer.CreationTimestamp.Before(&run.CreationTimestamp)) {
+			answer = run
+			answerPriority = priority
+		}
+	}
+	return answer
+}
+
+func isSameRepository(run1 *pipelineapi.PipelineRun, run2 *pipelineapi.PipelineRun) bool {
+	owner := run1.Labels[LabelOwner]
+	repo := run1.Labels[LabelRepo]
+	return repo != "" && owner == run2.Labels[LabelOwner] && repo == run2.Labels[LabelRepo]
+}
+
+func removePipelineRun(runs []*pipelineapi.PipelineRun, run *pipelineapi.PipelineRun) []*pipelineapi.PipelineRun {
+	answer := []*pipelineapi.PipelineRun{}
+	for _, r := range runs {
+		if r.Name != run.Name {
+			answer = append(answer, r)
+		}
+	}
+	return answer
+}
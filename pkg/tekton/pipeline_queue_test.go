@@ -0,0 +1,104 @@
+package tekton_test
+
+import (
+	"testing"
+
+	jenkinsv1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	jxfake "github.com/jenkins-x/jx/pkg/client/clientset/versioned/fake"
+	"github.com/jenkins-x/jx/pkg/tekton"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	tektonfake "github.com/tektoncd/pipeline/pkg/client/clientset/versioned/fake"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func queueTestPipelineRun(name string, repo string, kind string) *v1alpha1.PipelineRun {
+	return &v1alpha1.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: ns,
+			Labels: map[string]string{
+				tekton.LabelOwner: "myorg",
+				tekton.LabelRepo:  repo,
+				tekton.LabelType:  tekton.BuildPipeline.String(),
+				tekton.LabelKind:  kind,
+			},
+		},
+	}
+}
+
+func TestCanStartPipelineRun(t *testing.T) {
+	running := []*v1alpha1.PipelineRun{
+		queueTestPipelineRun("app1-pr-1", "app1", "pullrequest"),
+		queueTestPipelineRun("app1-pr-2", "app1", "pullrequest"),
+		queueTestPipelineRun("app2-master-1", "app2", "release"),
+	}
+	run := queueTestPipelineRun("app1-pr-3", "app1", "pullrequest")
+
+	ok, _ := tekton.CanStartPipelineRun(nil, running, run)
+	assert.True(t, ok, "the pipelines should not be limited without a concurrency policy")
+
+	ok, reason := tekton.CanStartPipelineRun(&jenkinsv1.PipelineConcurrency{MaxRuns: 3}, running, run)
+	assert.False(t, ok)
+	assert.Equal(t, "3 pipelines are running in the cluster", reason)
+
+	ok, reason = tekton.CanStartPipelineRun(&jenkinsv1.PipelineConcurrency{MaxRunsPerRepository: 2}, running, run)
+	assert.False(t, ok)
+	assert.Equal(t, "2 pipelines are running for the repository myorg/app1", reason)
+
+	ok, _ = tekton.CanStartPipelineRun(&jenkinsv1.PipelineConcurrency{MaxRunsPerRepository: 2}, running, queueTestPipelineRun("app2-pr-1", "app2", "pullrequest"))
+	assert.True(t, ok)
+}
+
+func TestProcessPipelineQueue(t *testing.T) {
+	concurrency := &jenkinsv1.PipelineConcurrency{MaxRuns: 1}
+	completed := queueTestPipelineRun("app1-pr-1", "app1", "pullrequest")
+	now := metav1.Now()
+	completed.Status.CompletionTime = &now
+	running := queueTestPipelineRun("app1-pr-2", "app1", "pullrequest")
+	tektonClient := tektonfake.NewSimpleClientset(completed, running)
+
+	activities := []*jenkinsv1.PipelineActivity{}
+	jxClient := jxfake.NewSimpleClientset()
+	for _, name := range []string{"app1-pr-3", "app2-master-1"} {
+		activity, err := jxClient.JenkinsV1().PipelineActivities(ns).Create(&jenkinsv1.PipelineActivity{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: ns},
+		})
+		require.NoError(t, err)
+		activities = append(activities, activity)
+	}
+	err := tekton.QueuePipelineRun(jxClient, ns, activities[0], queueTestPipelineRun("app1-pr-3", "app1", "pullrequest"))
+	require.NoError(t, err)
+	err = tekton.QueuePipelineRun(jxClient, ns, activities[1], queueTestPipelineRun("app2-master-1", "app2", "release"))
+	require.NoError(t, err)
+
+	queue, err := tekton.GetPipelineQueue(jxClient, ns, concurrency)
+	require.NoError(t, err)
+	require.Len(t, queue, 2)
+	assert.Equal(t, "app2-master-1", queue[0].PipelineRun.Name, "the release pipeline should be first")
+	assert.Equal(t, jenkinsv1.DefaultReleasePipelinePriority, queue[0].Priority)
+	assert.Equal(t, "app1-pr-3", queue[1].PipelineRun.Name)
+
+	err = tekton.ProcessPipelineQueue(jxClient, tektonClient, ns, concurrency)
+	require.NoError(t, err)
+	queue, err = tekton.GetPipelineQueue(jxClient, ns, concurrency)
+	require.NoError(t, err)
+	assert.Len(t, queue, 2, "no pipeline should start while the limit is reached")
+
+	concurrency.Preempt = true
+	err = tekton.ProcessPipelineQueue(jxClient, tektonClient, ns, concurrency)
+	require.NoError(t, err)
+
+	cancelled, err := tektonClient.TektonV1alpha1().PipelineRuns(ns).Get("app1-pr-2", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, v1alpha1.PipelineRunSpecStatusCancelled, cancelled.Spec.Status, "the pull request pipeline should be preempted")
+
+	_, err = tektonClient.TektonV1alpha1().PipelineRuns(ns).Get("app2-master-1", metav1.GetOptions{})
+	assert.NoError(t, err, "the release pipeline should have started")
+
+	queue, err = tekton.GetPipelineQueue(jxClient, ns, concurrency)
+	require.NoError(t, err)
+	require.Len(t, queue, 1)
+	assert.Equal(t, "app1-pr-3", queue[0].PipelineRun.Name)
+}
@@ -0,0 +1,293 @@
+package tekton
+
+import (
+	"fmt"
+	"strings"
+
+	jenkinsv1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/client/clientset/versioned"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/tekton/syntax"
+	"github.com/jenkins-x/jx/pkg/util"
+	knativeapis "github.com/knative/pkg/apis"
+	"github.com/pkg/errors"
+	pipelineapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	tektonclient "github.com/tektoncd/pipeline/pkg/client/clientset/versioned"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PipelineRunSucceeded returns true if the PipelineRun completed successfully
+func PipelineRunSucceeded(pr *pipelineapi.PipelineRun) bool {
+	condition := pr.Status.GetCondition(knativeapis.ConditionSucceeded)
+	return condition != nil && condition.Status == corev1.ConditionTrue
+}
+
+// RerunPipelineRun creates a new PipelineRun for the build of a failed PipelineRun which restarts from its failed
+// stages. The stages which succeeded are skipped unless a rerun stage needs their workspace. The rerun stages are
+// retried by Tekton the given number of times unless they specify their own retries.
+// Returns the new PipelineRun and the names of the skipped stages
+func RerunPipelineRun(jxClient versioned.Interface, tektonClient tektonclient.Interface, ns string, pr *pipelineapi.PipelineRun, retries int) (*pipelineapi.PipelineRun, []string, error) {
+	if !PipelineRunIsComplete(pr) {
+		return nil, nil, fmt.Errorf("PipelineRun %s has not completed", pr.Name)
+	}
+	if PipelineRunSucceeded(pr) {
+		return nil, nil, fmt.Errorf("PipelineRun %s succeeded", pr.Name)
+	}
+	pipelineName := pr.Spec.PipelineRef.Name
+	pipeline, err := tektonClient.TektonV1alpha1().Pipelines(ns).Get(pipelineName, metav1.GetOptions{})
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to get the Pipeline %s of PipelineRun %s", pipelineName, pr.Name)
+	}
+
+	tasks, skipped := RerunPipelineTasks(pipeline.Spec.Tasks, succeededPipelineTasks(pr))
+	if len(tasks) == 0 {
+		return nil, nil, fmt.Errorf("PipelineRun %s has no failed stages to rerun", pr.Name)
+	}
+	skippedTaskRefs := map[string]bool{}
+	for _, task := range pipeline.Spec.Tasks {
+		if util.StringArrayIndex(skipped, task.Name) >= 0 {
+			skippedTaskRefs[task.TaskRef.Name] = true
+		}
+	}
+	for i := range tasks {
+		if tasks[i].Retries == 0 {
+			tasks[i].Retries = retries
+		}
+	}
+
+	labels := pr.Labels
+	name := PipelineResourceName(labels[LabelOwner], labels[LabelRepo], labels[LabelBranch], labels[LabelContext], labels[LabelType], true) + "-" + labels[LabelBuild]
+	rerunPipeline := &pipelineapi.Pipeline{
+		TypeMeta: pipeline.TypeMeta,
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            name,
+			Namespace:       ns,
+			Labels:          util.MergeMaps(pipeline.Labels),
+			Annotations:     util.MergeMaps(pipeline.Annotations),
+			OwnerReferences: pipeline.OwnerReferences,
+		},
+		Spec: *pipeline.Spec.DeepCopy(),
+	}
+	rerunPipeline.Spec.Tasks = tasks
+	rerunPipeline, err = CreateOrUpdatePipeline(tektonClient, ns, rerunPipeline)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to create the Pipeline %s", name)
+	}
+
+	run := &pipelineapi.PipelineRun{
+		TypeMeta: pr.TypeMeta,
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   ns,
+			Labels:      map[string]string{},
+			Annotations: util.MergeMaps(pr.Annotations),
+		},
+		Spec: *pr.Spec.DeepCopy(),
+	}
+	for k, v := range pr.Labels {
+		// the tekton labels are added by tekton for the new PipelineRun
+		if !strings.HasPrefix(k, "tekton.dev/") {
+			run.Labels[k] = v
+		}
+	}
+	run.Spec.PipelineRef.Name = name
+	run.Spec.Status = ""
+
+	err = resetPipelineActivity(jxClient, ns, pr)
+	if err != nil {
+		return nil, nil, err
+	}
+	run, err = ApplyPipelineRun(tektonClient, ns, run)
+	if err != nil {
+		return nil, nil, err
+	}
+	log.Logger().Infof("created PipelineRun %s", util.ColorInfo(run.Name))
+
+	structures := jxClient.JenkinsV1().PipelineStructures(ns)
+	structure, err := structures.Get(pr.Name, metav1.GetOptions{})
+	if err != nil {
+		return run, skipped, errors.Wrapf(err, "failed to get the PipelineStructure of PipelineRun %s", pr.Name)
+	}
+	rerunStructure := &jenkinsv1.PipelineStructure{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: ns,
+			Labels:    util.MergeMaps(structure.Labels),
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion: syntax.TektonAPIVersion,
+					Kind:       "pipeline",
+					Name:       rerunPipeline.Name,
+					UID:        rerunPipeline.UID,
+				},
+			},
+		},
+		PipelineRef:    &rerunPipeline.Name,
+		PipelineRunRef: &run.Name,
+		Stages:         RerunPipelineStructureStages(structure.Stages, skippedTaskRefs),
+	}
+	_, err = structures.Create(rerunStructure)
+	if err != nil {
+		return run, skipped, errors.Wrapf(err, "failed to create the PipelineStructure %s", name)
+	}
+	return run, skipped, nil
+}
+
+// RerunPipelineTasks returns the pipeline tasks to rerun after the given pipeline tasks succeeded and the names of the
+// skipped pipeline tasks. A succeeded pipeline task is rerun if a rerun pipeline task gets its workspace from it
+func RerunPipelineTasks(tasks []pipelineapi.PipelineTask, succeeded map[string]bool) ([]pipelineapi.PipelineTask, []string) {
+	rerun := map[string]bool{}
+	for _, task := range tasks {
+		if !succeeded[task.Name] {
+			rerun[task.Name] = true
+		}
+	}
+	for changed := true; changed; {
+		changed = false
+		for _, task := range tasks {
+			if !rerun[task.Name] || task.Resources == nil {
+				continue
+			}
+			for _, input := range task.Resources.Inputs {
+				for _, from := range input.From {
+					if !rerun[from] {
+						rerun[from] = true
+						changed = true
+					}
+				}
+			}
+		}
+	}
+
+	answer := []pipelineapi.PipelineTask{}
+	skipped := []string{}
+	for _, task := range tasks {
+		if !rerun[task.Name] {
+			skipped = append(skipped, task.Name)
+			continue
+		}
+		rerunTask := task.DeepCopy()
+		rerunTask.RunAfter = nil
+		for _, name := range task.RunAfter {
+			if rerun[name] {
+				rerunTask.RunAfter = append(rerunTask.RunAfter, name)
+			}
+		}
+		answer = append(answer, *rerunTask)
+	}
+	return answer, skipped
+}
+
+// RerunPipelineStructureStages returns the stages of a PipelineStructure without the stages of the skipped tasks and
+// without the parent stages which no longer have any child stages
+func RerunPipelineStructureStages(stages []jenkinsv1.PipelineStructureStage, skippedTaskRefs map[string]bool) []jenkinsv1.PipelineStructureStage {
+	removed := map[string]bool{}
+	stageMap := map[string]*jenkinsv1.PipelineStructureStage{}
+	for i := range stages {
+		stage := &stages[i]
+		stageMap[stage.Name] = stage
+		if stage.TaskRef != nil && skippedTaskRefs[*stage.TaskRef] {
+			removed[stage.Name] = true
+		}
+	}
+	for changed := true; changed; {
+		changed = false
+		for _, stage := range stages {
+			children := append(append([]string{}, stage.Stages...), stage.Parallel...)
+			if removed[stage.Name] || stage.TaskRef != nil || len(children) == 0 {
+				continue
+			}
+			allRemoved := true
+			for _, child := range children {
+				if !removed[child] {
+					allRemoved = false
+					break
+				}
+			}
+			if allRemoved {
+				removed[stage.Name] = true
+				changed = true
+			}
+		}
+	}
+
+	// skips the removed stages when linking the previous and next stages
+	link := func(name *string, next func(stage *jenkinsv1.PipelineStructureStage) *string) *string {
+		for name != nil && removed[*name] {
+			stage := stageMap[*name]
+			if stage == nil {
+				return nil
+			}
+			name = next(stage)
+		}
+		return name
+	}
+	filter := func(names []string) []string {
+		var answer []string
+		for _, name := range names {
+			if !removed[name] {
+				answer = append(answer, name)
+			}
+		}
+		return answer
+	}
+
+	answer := []jenkinsv1.PipelineStructureStage{}
+	for _, s := range stages {
+		if removed[s.Name] {
+			continue
+		}
+		stage := s.DeepCopy()
+		stage.TaskRunRef = nil
+		stage.Stages = filter(stage.Stages)
+		stage.Parallel = filter(stage.Parallel)
+		stage.Previous = link(stage.Previous, func(s *jenkinsv1.PipelineStructureStage) *string { return s.Previous })
+		stage.Next = link(stage.Next, func(s *jenkinsv1.PipelineStructureStage) *string { return s.Next })
+		answer = append(answer, *stage)
+	}
+	return answer
+}
+
+// succeededPipelineTasks returns the names of the pipeline tasks of the PipelineRun which succeeded
+func succeededPipelineTasks(pr *pipelineapi.PipelineRun) map[string]bool {
+	answer := map[string]bool{}
+	for _, taskRun := range pr.Status.TaskRuns {
+		if taskRun == nil || taskRun.Status == nil {
+			continue
+		}
+		condition := taskRun.Status.GetCondition(knativeapis.ConditionSucceeded)
+		if condition != nil && condition.Status == corev1.ConditionTrue {
+			answer[taskRun.PipelineTaskName] = true
+		}
+	}
+	return answer
+}
+
+// resetPipelineActivity marks the PipelineActivity of the build of the PipelineRun and its stages which did not
+// succeed as pending so that the controller tracks the stages of the rerun
+func resetPipelineActivity(jxClient versioned.Interface, ns string, pr *pipelineapi.PipelineRun) error {
+	activity, err := FindPipelineActivityForRun(jxClient, ns, pr)
+	if err != nil {
+		return err
+	}
+	if activity == nil {
+		return nil
+	}
+	spec := &activity.Spec
+	spec.Status = jenkinsv1.ActivityStatusTypePending
+	spec.Message = ""
+	spec.CompletedTimestamp = nil
+	for i := range spec.Steps {
+		stage := spec.Steps[i].Stage
+		if stage != nil && stage.Status != jenkinsv1.ActivityStatusTypeSucceeded {
+			stage.Status = jenkinsv1.ActivityStatusTypePending
+			stage.CompletedTimestamp = nil
+		}
+	}
+	_, err = jxClient.JenkinsV1().PipelineActivities(ns).Update(activity)
+	if err != nil {
+		return errors.Wrapf(err, "failed to update the PipelineActivity %s", activity.Name)
+	}
+	return nil
+}
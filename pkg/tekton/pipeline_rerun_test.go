@@ -0,0 +1,89 @@
+package tekton_test
+
+import (
+	"testing"
+
+	jenkinsv1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/tekton"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+)
+
+func rerunTestTask(name string, from string, runAfter ...string) v1alpha1.PipelineTask {
+	task := v1alpha1.PipelineTask{
+		Name:     name,
+		TaskRef:  v1alpha1.TaskRef{Name: "task-" + name},
+		RunAfter: runAfter,
+		Resources: &v1alpha1.PipelineTaskResources{
+			Inputs: []v1alpha1.PipelineTaskInputResource{
+				{
+					Name:     "workspace",
+					Resource: "source",
+				},
+			},
+		},
+	}
+	if from != "" {
+		task.Resources.Inputs[0].From = []string{from}
+	}
+	return task
+}
+
+func TestRerunPipelineTasks(t *testing.T) {
+	tasks := []v1alpha1.PipelineTask{
+		rerunTestTask("build", ""),
+		rerunTestTask("lint", "", "build"),
+		rerunTestTask("test", "build", "build"),
+		rerunTestTask("promote", "test", "test", "lint"),
+	}
+
+	rerun, skipped := tekton.RerunPipelineTasks(tasks, map[string]bool{"build": true, "lint": true})
+	require.Len(t, rerun, 3, "the build task should be rerun as the test task uses its workspace")
+	assert.Equal(t, "build", rerun[0].Name)
+	assert.Equal(t, "test", rerun[1].Name)
+	assert.Equal(t, "promote", rerun[2].Name)
+	assert.Equal(t, []string{"test"}, rerun[2].RunAfter, "the skipped lint task should be removed from runAfter")
+	assert.Equal(t, []string{"lint"}, skipped)
+
+	// a task with an empty workspace does not need the workspace of the tasks it runs after
+	tasks[2] = rerunTestTask("test", "", "build")
+	rerun, skipped = tekton.RerunPipelineTasks(tasks, map[string]bool{"build": true, "lint": true, "test": true})
+	require.Len(t, rerun, 2)
+	assert.Equal(t, "test", rerun[0].Name)
+	assert.Equal(t, "promote", rerun[1].Name)
+	assert.Empty(t, rerun[0].RunAfter)
+	assert.Equal(t, []string{"test"}, rerun[1].RunAfter)
+	assert.Equal(t, []string{"build", "lint"}, skipped)
+
+	rerun, _ = tekton.RerunPipelineTasks(tasks[:2], map[string]bool{"build": true, "lint": true})
+	assert.Empty(t, rerun)
+}
+
+func TestRerunPipelineStructureStages(t *testing.T) {
+	str := func(s string) *string {
+		return &s
+	}
+	stages := []jenkinsv1.PipelineStructureStage{
+		{Name: "ci", Stages: []string{"checks", "test"}, Depth: 0},
+		{Name: "checks", Parallel: []string{"lint", "vet"}, Depth: 1, Parent: str("ci"), Next: str("test")},
+		{Name: "lint", TaskRef: str("task-lint"), TaskRunRef: str("run-lint"), Depth: 2, Parent: str("checks")},
+		{Name: "vet", TaskRef: str("task-vet"), Depth: 2, Parent: str("checks")},
+		{Name: "test", TaskRef: str("task-test"), Depth: 1, Parent: str("ci"), Previous: str("checks")},
+	}
+
+	answer := tekton.RerunPipelineStructureStages(stages, map[string]bool{"task-lint": true, "task-vet": true})
+	require.Len(t, answer, 2)
+	assert.Equal(t, "ci", answer[0].Name)
+	assert.Equal(t, []string{"test"}, answer[0].Stages)
+	assert.Equal(t, "test", answer[1].Name)
+	assert.Nil(t, answer[1].Previous, "the removed stages should not be linked")
+
+	answer = tekton.RerunPipelineStructureStages(stages, map[string]bool{"task-lint": true})
+	require.Len(t, answer, 4)
+	assert.Equal(t, []string{"vet"}, answer[1].Parallel)
+	assert.Equal(t, "checks", *answer[3].Previous)
+	for _, stage := range answer {
+		assert.Nil(t, stage.TaskRunRef)
+	}
+}
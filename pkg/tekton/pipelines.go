@@ -8,6 +8,7 @@ import (
 	"time"
 
 	jenkinsio "github.com/jenkins-x/jx/pkg/apis/jenkins.io"
+	jenkinsv1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
 	v1 "github.com/jenkins-x/jx/pkg/client/clientset/versioned/typed/jenkins.io/v1"
 	"github.com/jenkins-x/jx/pkg/kube/naming"
 	"k8s.io/apimachinery/pkg/labels"
@@ -395,14 +396,23 @@ func PipelineResourceName(organisation string, name string, branch string, conte
 // and creates and applies a PipelineResource for their source repo and a pipelineRun
 // to execute them.
 func ApplyPipeline(jxClient versioned.Interface, tektonClient tektonclient.Interface, crds *CRDWrapper, ns string, activityKey *kube.PromoteStepActivityKey) error {
+	_, err := ApplyPipelineWithConcurrency(jxClient, tektonClient, crds, ns, activityKey, nil)
+	return err
+}
+
+// ApplyPipelineWithConcurrency applies the tasks and pipeline to the cluster like ApplyPipeline but queues the
+// pipelineRun if it cannot start without exceeding the concurrency limits. Returns true if the pipelineRun was queued
+func ApplyPipelineWithConcurrency(jxClient versioned.Interface, tektonClient tektonclient.Interface, crds *CRDWrapper, ns string, activityKey *kube.PromoteStepActivityKey, concurrency *jenkinsv1.PipelineConcurrency) (bool, error) {
 	info := util.ColorInfo
 
+	var activity *jenkinsv1.PipelineActivity
 	var activityOwnerReference *metav1.OwnerReference
 
 	if activityKey != nil {
-		activity, _, err := activityKey.GetOrCreate(jxClient, crds.Pipeline().Namespace)
+		var err error
+		activity, _, err = activityKey.GetOrCreate(jxClient, crds.Pipeline().Namespace)
 		if err != nil {
-			return err
+			return false, err
 		}
 
 		activityOwnerReference = &metav1.OwnerReference{
@@ -416,7 +426,7 @@ func ApplyPipeline(jxClient versioned.Interface, tektonClient tektonclient.Inter
 	for _, resource := range crds.Resources() {
 		_, err := CreateOrUpdateSourceResource(tektonClient, ns, resource)
 		if err != nil {
-			return errors.Wrapf(err, "failed to create/update PipelineResource %s in namespace %s", resource.Name, ns)
+			return false, errors.Wrapf(err, "failed to create/update PipelineResource %s in namespace %s", resource.Name, ns)
 		}
 		if resource.Spec.Type == pipelineapi.PipelineResourceTypeGit {
 			gitURL := activityKey.GitInfo.HttpCloneURL()
@@ -432,7 +442,7 @@ func ApplyPipeline(jxClient versioned.Interface, tektonClient tektonclient.Inter
 		}
 		_, err := CreateOrUpdateTask(tektonClient, ns, task)
 		if err != nil {
-			return errors.Wrapf(err, "failed to create/update the task %s in namespace %s", task.Name, ns)
+			return false, errors.Wrapf(err, "failed to create/update the task %s in namespace %s", task.Name, ns)
 		}
 		log.Logger().Infof("upserted Task %s", info(task.Name))
 	}
@@ -443,7 +453,7 @@ func ApplyPipeline(jxClient versioned.Interface, tektonClient tektonclient.Inter
 
 	pipeline, err := CreateOrUpdatePipeline(tektonClient, ns, crds.Pipeline())
 	if err != nil {
-		return errors.Wrapf(err, "failed to create/update the pipeline in namespace %s", ns)
+		return false, errors.Wrapf(err, "failed to create/update the pipeline in namespace %s", ns)
 	}
 	log.Logger().Infof("upserted Pipeline %s", info(pipeline.Name))
 
@@ -456,11 +466,20 @@ func ApplyPipeline(jxClient versioned.Interface, tektonClient tektonclient.Inter
 
 	crds.structure.OwnerReferences = []metav1.OwnerReference{pipelineOwnerReference}
 
-	_, err = ApplyPipelineRun(tektonClient, ns, crds.PipelineRun())
-	if err != nil {
-		return errors.Wrapf(err, "failed to create the pipelineRun in namespace %s", ns)
+	queued := false
+	if activity != nil && concurrency.IsLimited() {
+		queued, err = queuePipelineRunIfLimited(jxClient, tektonClient, ns, concurrency, activity, crds.PipelineRun())
+		if err != nil {
+			return false, errors.Wrapf(err, "failed to queue the pipelineRun in namespace %s", ns)
+		}
+	}
+	if !queued {
+		_, err = ApplyPipelineRun(tektonClient, ns, crds.PipelineRun())
+		if err != nil {
+			return false, errors.Wrapf(err, "failed to create the pipelineRun in namespace %s", ns)
+		}
+		log.Logger().Infof("created PipelineRun %s", info(crds.PipelineRun().Name))
 	}
-	log.Logger().Infof("created PipelineRun %s", info(crds.PipelineRun().Name))
 
 	if crds.Structure() != nil {
 		crds.Structure().PipelineRunRef = &crds.PipelineRun().Name
@@ -475,12 +494,12 @@ func ApplyPipeline(jxClient versioned.Interface, tektonClient tektonclient.Inter
 		crds.Structure().PipelineRunRef = &crds.PipelineRun().Name
 
 		if _, structErr := structuresClient.Create(crds.Structure()); structErr != nil {
-			return errors.Wrapf(structErr, "failed to create the PipelineStructure in namespace %s", ns)
+			return false, errors.Wrapf(structErr, "failed to create the PipelineStructure in namespace %s", ns)
 		}
 		log.Logger().Infof("created PipelineStructure %s", info(crds.Structure().Name))
 	}
 
-	return nil
+	return queued, nil
 }
 
 // PipelineRunIsNotPending returns true if the PipelineRun has completed or has running steps.
@@ -0,0 +1,108 @@
+package syntax
+
+import (
+	"strings"
+	"time"
+
+	"github.com/knative/pkg/apis"
+)
+
+const (
+	// ApproversOwners approves by the approvers in the OWNERS file of the repository
+	ApproversOwners = "OWNERS"
+	// ApproversTeam approves by any user of the team
+	ApproversTeam = "team"
+
+	// OnTimeoutFail fails the pipeline if it is not approved before the timeout
+	OnTimeoutFail = "fail"
+	// OnTimeoutApprove continues the pipeline if it is not approved or rejected before the timeout
+	OnTimeoutApprove = "approve"
+
+	// DefaultApprovalTimeout the time a pipeline waits for an approval by default
+	DefaultApprovalTimeout = "24h"
+)
+
+// Approval is a special step which pauses the pipeline until it is approved or rejected with
+// 'jx approve pipeline'
+type Approval struct {
+	// Message the message displayed to the approvers
+	Message string `json:"message,omitempty"`
+	// Approvers the git user names which can approve or OWNERS for the approvers of the repository or team for any
+	// user of the team. Defaults to any user who can approve pipelines in the team
+	Approvers []string `json:"approvers,omitempty"`
+	// Timeout the duration to wait for an approval such as 2h. Defaults to 24h
+	Timeout string `json:"timeout,omitempty"`
+	// OnTimeout what happens when the timeout expires: fail or approve. Defaults to fail
+	OnTimeout string `json:"onTimeout,omitempty"`
+}
+
+// GetTimeout returns the duration to wait for an approval
+func (a *Approval) GetTimeout() string {
+	if a.Timeout == "" {
+		return DefaultApprovalTimeout
+	}
+	return a.Timeout
+}
+
+// GetOnTimeout returns what happens when the timeout expires
+func (a *Approval) GetOnTimeout() string {
+	if a.OnTimeout == "" {
+		return OnTimeoutFail
+	}
+	return a.OnTimeout
+}
+
+// toStep returns the command step which waits for the approval of the step
+func (a *Approval) toStep(s Step) Step {
+	args := []string{"step", "wait-for-approval", "--name", shellQuote(s.Name), "--timeout", shellQuote(a.GetTimeout()),
+		"--on-timeout", shellQuote(a.GetOnTimeout())}
+	if a.Message != "" {
+		args = append(args, "--message", shellQuote(a.Message))
+	}
+	for _, approver := range a.Approvers {
+		args = append(args, "--approver", shellQuote(approver))
+	}
+	image := s.Image
+	if image == "" && s.Agent == nil {
+		image = GitMergeImage
+	}
+	return Step{
+		Name:      s.Name,
+		Command:   "jx",
+		Arguments: args,
+		Image:     image,
+		Agent:     s.Agent,
+		Env:       s.Env,
+	}
+}
+
+func validateApproval(s Step) *apis.FieldError {
+	a := s.Approval
+	if a == nil {
+		return nil
+	}
+	if s.Name == "" {
+		return &apis.FieldError{
+			Message: "an approval step must have a name which is used to approve it",
+			Paths:   []string{"name"},
+		}
+	}
+	if _, err := time.ParseDuration(a.GetTimeout()); err != nil {
+		return &apis.FieldError{
+			Message: err.Error(),
+			Paths:   []string{"approval.timeout"},
+		}
+	}
+	if a.GetOnTimeout() != OnTimeoutFail && a.GetOnTimeout() != OnTimeoutApprove {
+		return &apis.FieldError{
+			Message: "onTimeout must be one of: " + strings.Join([]string{OnTimeoutFail, OnTimeoutApprove}, ", "),
+			Paths:   []string{"approval.onTimeout"},
+		}
+	}
+	return nil
+}
+
+// shellQuote quotes the text so that it is passed as a single argument by the shell
+func shellQuote(text string) string {
+	return "'" + strings.Replace(text, "'", `'"'"'`, -1) + "'"
+}
@@ -0,0 +1,50 @@
+package syntax
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApprovalToStep(t *testing.T) {
+	t.Parallel()
+	step := Step{
+		Name: "production",
+		Approval: &Approval{
+			Message:   "Release Bob's change?",
+			Approvers: []string{ApproversOwners, "jstrachan"},
+		},
+	}
+	assert.Nil(t, validateStep(step))
+
+	actual := step.Approval.toStep(step)
+	assert.Equal(t, "production", actual.Name)
+	assert.Equal(t, GitMergeImage, actual.Image)
+	assert.Equal(t, "jx step wait-for-approval --name 'production' --timeout '24h' --on-timeout 'fail' "+
+		`--message 'Release Bob'"'"'s change?' --approver 'OWNERS' --approver 'jstrachan'`, actual.GetFullCommand())
+	assert.Nil(t, validateStep(actual))
+}
+
+func TestValidateApproval(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		name  string
+		step  Step
+		valid bool
+	}{
+		{"defaults", Step{Name: "approve", Approval: &Approval{}}, true},
+		{"approve on timeout", Step{Name: "approve", Approval: &Approval{Timeout: "30m", OnTimeout: OnTimeoutApprove}}, true},
+		{"missing name", Step{Approval: &Approval{}}, false},
+		{"invalid timeout", Step{Name: "approve", Approval: &Approval{Timeout: "tomorrow"}}, false},
+		{"invalid on timeout", Step{Name: "approve", Approval: &Approval{OnTimeout: "skip"}}, false},
+		{"approval and command", Step{Name: "approve", Command: "make", Approval: &Approval{}}, false},
+	}
+	for _, tc := range testCases {
+		err := validateStep(tc.step)
+		if tc.valid {
+			assert.Nil(t, err, tc.name)
+		} else {
+			assert.NotNil(t, err, tc.name)
+		}
+	}
+}
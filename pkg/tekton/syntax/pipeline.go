@@ -153,7 +153,7 @@ type Step struct {
 	// An optional name to give the step for reporting purposes
 	Name string `json:"name,omitempty"`
 
-	// One of command, step, or loop is required.
+	// One of command, step, loop or approval is required.
 	Command string `json:"command,omitempty"`
 	// args is optional, but only allowed with command
 	Arguments []string `json:"args,omitempty"`
@@ -168,6 +168,9 @@ type Step struct {
 
 	Loop *Loop `json:"loop,omitempty"`
 
+	// Approval pauses the pipeline until the step is approved
+	Approval *Approval `json:"approval,omitempty"`
+
 	// agent can be overridden on a step
 	Agent *Agent `json:"agent,omitempty"`
 
@@ -242,7 +245,8 @@ type PostAction struct {
 }
 
 // StepOverrideType is used to specify whether the existing step should be replaced (default), new step(s) should be
-// prepended before the existing step, or new step(s) should be appended after the existing step.
+// prepended before the existing step, new step(s) should be appended after the existing step, or the existing step
+// should be deleted.
 type StepOverrideType string
 
 // The available override types
@@ -250,8 +254,12 @@ const (
 	StepOverrideReplace StepOverrideType = "replace"
 	StepOverrideBefore  StepOverrideType = "before"
 	StepOverrideAfter   StepOverrideType = "after"
+	StepOverrideDelete  StepOverrideType = "delete"
 )
 
+// StepOverrideTypes the valid override types
+var StepOverrideTypes = []StepOverrideType{StepOverrideReplace, StepOverrideBefore, StepOverrideAfter, StepOverrideDelete}
+
 // PipelineOverride allows for overriding named steps, stages, or pipelines in the build pack or default pipeline
 type PipelineOverride struct {
 	Pipeline         string            `json:"pipeline,omitempty"`
@@ -701,24 +709,24 @@ func validateStep(s Step) *apis.FieldError {
 		}
 	}
 
-	if s.GetCommand() == "" && s.Step == "" && s.Loop == nil {
-		return apis.ErrMissingOneOf("command", "step", "loop")
+	if s.GetCommand() == "" && s.Step == "" && s.Loop == nil && s.Approval == nil {
+		return apis.ErrMissingOneOf("command", "step", "loop", "approval")
 	}
 
-	if moreThanOneAreTrue(s.GetCommand() != "", s.Step != "", s.Loop != nil) {
-		return apis.ErrMultipleOneOf("command", "step", "loop")
+	if moreThanOneAreTrue(s.GetCommand() != "", s.Step != "", s.Loop != nil, s.Approval != nil) {
+		return apis.ErrMultipleOneOf("command", "step", "loop", "approval")
 	}
 
-	if (s.GetCommand() != "" || s.Loop != nil) && len(s.Options) != 0 {
+	if (s.GetCommand() != "" || s.Loop != nil || s.Approval != nil) && len(s.Options) != 0 {
 		return &apis.FieldError{
-			Message: "Cannot set options for a command or a loop",
+			Message: "Cannot set options for a command, a loop or an approval",
 			Paths:   []string{"options"},
 		}
 	}
 
-	if (s.Step != "" || s.Loop != nil) && len(s.Arguments) != 0 {
+	if (s.Step != "" || s.Loop != nil || s.Approval != nil) && len(s.Arguments) != 0 {
 		return &apis.FieldError{
-			Message: "Cannot set command-line arguments for a step or a loop",
+			Message: "Cannot set command-line arguments for a step, a loop or an approval",
 			Paths:   []string{"args"},
 		}
 	}
@@ -727,6 +735,10 @@ func validateStep(s Step) *apis.FieldError {
 		return err.ViaField("loop")
 	}
 
+	if err := validateApproval(s); err != nil {
+		return err
+	}
+
 	if s.Agent != nil {
 		return validateAgent(s.Agent).ViaField("agent")
 	}
@@ -1496,6 +1508,10 @@ func generateSteps(params generateStepsParams) ([]corev1.Container, map[string]c
 	volumes := make(map[string]corev1.Volume)
 	var steps []corev1.Container
 
+	if params.step.Approval != nil {
+		params.step = params.step.Approval.toStep(params.step)
+	}
+
 	stepImage := params.inheritedAgent
 	if params.step.GetImage() != "" {
 		stepImage = params.step.GetImage()
@@ -2033,6 +2049,38 @@ func (p *PipelineOverride) AsStepsSlice() []*Step {
 	return []*Step{}
 }
 
+// IsDelete returns true if this override deletes the named step or the steps of the stage
+func (p *PipelineOverride) IsDelete() bool {
+	return p.Type != nil && *p.Type == StepOverrideDelete
+}
+
+// ValidatePatch returns an error if the override type is unknown or is not consistent with the rest of the override
+func (p *PipelineOverride) ValidatePatch() error {
+	if p.Type == nil {
+		return nil
+	}
+	switch *p.Type {
+	case StepOverrideReplace:
+		return nil
+	case StepOverrideBefore, StepOverrideAfter:
+		if len(p.AsStepsSlice()) == 0 {
+			return fmt.Errorf("an override of type %s must specify the step or steps to insert", *p.Type)
+		}
+		return nil
+	case StepOverrideDelete:
+		if len(p.AsStepsSlice()) > 0 {
+			return fmt.Errorf("an override of type %s must not specify any steps", *p.Type)
+		}
+		return nil
+	default:
+		values := []string{}
+		for _, t := range StepOverrideTypes {
+			values = append(values, string(t))
+		}
+		return fmt.Errorf("invalid override type %s which should be one of: %s", *p.Type, strings.Join(values, ", "))
+	}
+}
+
 // MatchesPipeline returns true if the pipeline name is specified in the override or no pipeline is specified at all in the override
 func (p *PipelineOverride) MatchesPipeline(name string) bool {
 	if p.Pipeline == "" || strings.EqualFold(p.Pipeline, name) {
@@ -2247,6 +2295,9 @@ func ApplyStepOverridesToStage(stage Stage, override *PipelineOverride) Stage {
 func OverrideStep(step Step, override *PipelineOverride) []Step {
 	if override != nil {
 		if step.Name == override.Name {
+			if override.IsDelete() {
+				return nil
+			}
 			var newSteps []Step
 
 			if override.Step != nil {
@@ -0,0 +1,70 @@
+package syntax
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	pipelineapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+)
+
+var (
+	// ReservedPipelineParams the names of the params which jx sets on every pipeline so cannot be passed by users
+	ReservedPipelineParams = []string{"version", "build_id"}
+
+	pipelineParamNameRegex = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+)
+
+// ParsePipelineParams parses the 'name=value' pairs of custom pipeline params. The value may contain '='
+func ParsePipelineParams(values []string) (map[string]string, error) {
+	answer := map[string]string{}
+	for _, text := range values {
+		parts := strings.SplitN(text, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("the param %s must be in the form 'name=value'", text)
+		}
+		err := ValidatePipelineParamName(parts[0])
+		if err != nil {
+			return nil, err
+		}
+		answer[parts[0]] = parts[1]
+	}
+	return answer, nil
+}
+
+// ValidatePipelineParamName returns an error if the name cannot be used as a custom pipeline param. As the params
+// are exposed to the steps as upper case environment variables the names are limited to letters, digits and '_'
+func ValidatePipelineParamName(name string) error {
+	if !pipelineParamNameRegex.MatchString(name) {
+		return fmt.Errorf("invalid param name '%s' which must start with a letter or '_' followed by letters, digits or '_'", name)
+	}
+	for _, reserved := range ReservedPipelineParams {
+		if strings.EqualFold(name, reserved) {
+			return fmt.Errorf("the param name '%s' is reserved by jx", name)
+		}
+	}
+	return nil
+}
+
+// PipelineParamArgs returns the params as sorted 'name=value' command line arguments
+func PipelineParamArgs(params map[string]string) []string {
+	answer := []string{}
+	for k, v := range params {
+		answer = append(answer, k+"="+v)
+	}
+	sort.Strings(answer)
+	return answer
+}
+
+// ToPipelineParams converts the params to Tekton params sorted by name
+func ToPipelineParams(params map[string]string) []pipelineapi.Param {
+	answer := []pipelineapi.Param{}
+	for k, v := range params {
+		answer = append(answer, pipelineapi.Param{Name: k, Value: v})
+	}
+	sort.Slice(answer, func(i, j int) bool {
+		return answer[i].Name < answer[j].Name
+	})
+	return answer
+}
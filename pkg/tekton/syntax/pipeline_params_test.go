@@ -0,0 +1,28 @@
+package syntax_test
+
+import (
+	"testing"
+
+	"github.com/jenkins-x/jx/pkg/tekton/syntax"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePipelineParams(t *testing.T) {
+	t.Parallel()
+
+	params, err := syntax.ParsePipelineParams([]string{"target=staging", "query=a=b", "EMPTY="})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"target": "staging", "query": "a=b", "EMPTY": ""}, params)
+	assert.Equal(t, []string{"EMPTY=", "query=a=b", "target=staging"}, syntax.PipelineParamArgs(params))
+
+	pipelineParams := syntax.ToPipelineParams(params)
+	require.Len(t, pipelineParams, 3)
+	assert.Equal(t, "EMPTY", pipelineParams[0].Name)
+	assert.Equal(t, "a=b", pipelineParams[1].Value)
+
+	for _, text := range []string{"target", "my-param=x", "1st=x", "version=1.0.0", "BUILD_ID=3"} {
+		_, err = syntax.ParsePipelineParams([]string{text})
+		assert.Error(t, err, "param %s", text)
+	}
+}
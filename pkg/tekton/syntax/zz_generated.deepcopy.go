@@ -25,6 +25,27 @@ func (in *Agent) DeepCopy() *Agent {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Approval) DeepCopyInto(out *Approval) {
+	*out = *in
+	if in.Approvers != nil {
+		in, out := &in.Approvers, &out.Approvers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Approval.
+func (in *Approval) DeepCopy() *Approval {
+	if in == nil {
+		return nil
+	}
+	out := new(Approval)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CRDsFromPipelineParams) DeepCopyInto(out *CRDsFromPipelineParams) {
 	*out = *in
@@ -523,6 +544,15 @@ func (in *Step) DeepCopyInto(out *Step) {
 			(*in).DeepCopyInto(*out)
 		}
 	}
+	if in.Approval != nil {
+		in, out := &in.Approval, &out.Approval
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(Approval)
+			(*in).DeepCopyInto(*out)
+		}
+	}
 	if in.Agent != nil {
 		in, out := &in.Agent, &out.Agent
 		if *in == nil {
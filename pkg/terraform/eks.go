@@ -0,0 +1,430 @@
+package terraform
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+)
+
+const (
+	// VarsFileName the name of the variables file generated alongside a terraform module
+	VarsFileName = "terraform.tfvars.json"
+	// OutputsFileName the name of the file the outputs of an applied module are written to
+	OutputsFileName = "outputs.json"
+)
+
+// EKSModule the variables of the terraform module which creates an EKS cluster for Jenkins X. The JSON names are the
+// names of the terraform variables so that the module can be marshalled directly into a variables file
+type EKSModule struct {
+	ClusterName       string            `json:"cluster_name"`
+	Region            string            `json:"region"`
+	AvailabilityZones []string          `json:"availability_zones"`
+	NodeType          string            `json:"node_type"`
+	NodesDesired      int               `json:"nodes_desired"`
+	NodesMin          int               `json:"nodes_min"`
+	NodesMax          int               `json:"nodes_max"`
+	NodeVolumeSize    int               `json:"node_volume_size"`
+	SpotBuildPool     bool              `json:"spot_build_pool"`
+	SpotNodeTypes     []string          `json:"spot_node_types"`
+	SpotNodesMax      int               `json:"spot_nodes_max"`
+	Domain            string            `json:"domain"`
+	Tags              map[string]string `json:"tags"`
+}
+
+// Validate validates the module variables
+func (m *EKSModule) Validate() error {
+	if m.ClusterName == "" {
+		return util.MissingOption("cluster-name")
+	}
+	if m.Region == "" {
+		return util.MissingOption("region")
+	}
+	if m.NodesMin > m.NodesMax {
+		return fmt.Errorf("the minimum number of nodes %d is greater than the maximum %d", m.NodesMin, m.NodesMax)
+	}
+	if m.NodesDesired < m.NodesMin || m.NodesDesired > m.NodesMax {
+		return fmt.Errorf("the number of nodes %d should be between %d and %d", m.NodesDesired, m.NodesMin, m.NodesMax)
+	}
+	if m.SpotBuildPool && len(m.SpotNodeTypes) == 0 {
+		return util.MissingOption("spot-node-types")
+	}
+	return nil
+}
+
+// WriteEKSModule writes the terraform module which creates the EKS cluster, node groups, IAM roles, buckets and DNS
+// zone to the given directory together with a variables file. The module is not applied so that it can be reviewed
+// and committed before it is applied. Returns the names of the files which were written
+func WriteEKSModule(dir string, module *EKSModule) ([]string, error) {
+	err := module.Validate()
+	if err != nil {
+		return nil, err
+	}
+	err = os.MkdirAll(dir, util.DefaultWritePermissions)
+	if err != nil {
+		return nil, errors.Wrapf(err, "creating directory %s", dir)
+	}
+	vars, err := json.MarshalIndent(module, "", "  ")
+	if err != nil {
+		return nil, errors.Wrap(err, "marshalling the terraform variables")
+	}
+	files := []struct {
+		name string
+		data []byte
+	}{
+		{"main.tf", []byte(eksMainTf)},
+		{"iam.tf", []byte(eksIAMTf)},
+		{"variables.tf", []byte(eksVariablesTf)},
+		{"outputs.tf", []byte(eksOutputsTf)},
+		{VarsFileName, append(vars, '\n')},
+	}
+	for _, f := range files {
+		exists, err := util.FileExists(filepath.Join(dir, f.name))
+		if err != nil {
+			return nil, errors.Wrapf(err, "checking if %s exists", f.name)
+		}
+		if exists {
+			return nil, fmt.Errorf("the file %s already exists in %s, remove it or use a different directory", f.name, dir)
+		}
+	}
+	answer := []string{}
+	for _, f := range files {
+		fileName := filepath.Join(dir, f.name)
+		err = ioutil.WriteFile(fileName, f.data, util.DefaultFileWritePermissions)
+		if err != nil {
+			return answer, errors.Wrapf(err, "writing %s", fileName)
+		}
+		answer = append(answer, fileName)
+	}
+	return answer, nil
+}
+
+const eksMainTf = `terraform {
+  required_version = ">= 0.12.6"
+}
+
+provider "aws" {
+  version = "~> 2.40"
+  region  = var.region
+}
+
+data "aws_availability_zones" "available" {}
+
+locals {
+  azs  = length(var.availability_zones) > 0 ? var.availability_zones : slice(data.aws_availability_zones.available.names, 0, 3)
+  tags = merge(var.tags, { "kubernetes.io/cluster/${var.cluster_name}" = "shared" })
+}
+
+// Network
+module "vpc" {
+  source  = "terraform-aws-modules/vpc/aws"
+  version = "~> 2.21"
+
+  name                 = var.cluster_name
+  cidr                 = var.vpc_cidr_block
+  azs                  = local.azs
+  public_subnets       = [for i, az in local.azs : cidrsubnet(var.vpc_cidr_block, 4, i)]
+  enable_dns_hostnames = true
+  tags                 = local.tags
+}
+
+// Cluster and node groups
+module "eks" {
+  source  = "terraform-aws-modules/eks/aws"
+  version = "~> 7.0"
+
+  cluster_name    = var.cluster_name
+  cluster_version = var.cluster_version
+  vpc_id          = module.vpc.vpc_id
+  subnets         = module.vpc.public_subnets
+  enable_irsa     = true
+  tags            = var.tags
+
+  node_groups = {
+    workers = {
+      instance_type    = var.node_type
+      desired_capacity = var.nodes_desired
+      min_capacity     = var.nodes_min
+      max_capacity     = var.nodes_max
+      disk_size        = var.node_volume_size
+    }
+  }
+
+  worker_groups_launch_template = var.spot_build_pool ? [
+    {
+      name                    = "jx-builds-spot"
+      override_instance_types = var.spot_node_types
+      spot_instance_pools     = min(length(var.spot_node_types), 20)
+      asg_desired_capacity    = 0
+      asg_min_size            = 0
+      asg_max_size            = var.spot_nodes_max
+      root_volume_size        = var.node_volume_size
+      kubelet_extra_args      = "--node-labels=jenkins-x.io/build-pool=spot --register-with-taints=jenkins-x.io/build-pool=spot:NoSchedule"
+    },
+  ] : []
+}
+
+// Long term storage of logs, reports and repositories plus the backups
+resource "aws_s3_bucket" "storage" {
+  for_each      = toset(["logs", "reports", "repository", "backup"])
+  bucket_prefix = "${var.cluster_name}-${each.key}-"
+  acl           = "private"
+  force_destroy = var.force_destroy_buckets
+  tags          = var.tags
+
+  versioning {
+    enabled = each.key == "backup"
+  }
+}
+
+// DNS zone of the domain of the cluster
+resource "aws_route53_zone" "domain" {
+  count = var.domain != "" ? 1 : 0
+  name  = var.domain
+  tags  = var.tags
+}
+`
+
+const eksIAMTf = `// IAM roles for the service accounts of Jenkins X so that no long lived credentials are stored in the cluster
+locals {
+  service_account_roles = {
+    "cert-manager"              = { namespace = "cert-manager", policy = data.aws_iam_policy_document.dns.json }
+    "cm-cainjector"             = { namespace = "cert-manager", policy = "" }
+    "exdns-external-dns"        = { namespace = "jx", policy = data.aws_iam_policy_document.dns.json }
+    "tekton-bot"                = { namespace = "jx", policy = data.aws_iam_policy_document.builds.json }
+    "jenkins-x-controllerbuild" = { namespace = "jx", policy = data.aws_iam_policy_document.builds.json }
+  }
+  oidc_issuer = replace(module.eks.cluster_oidc_issuer_url, "https://", "")
+}
+
+data "aws_iam_policy_document" "assume_role" {
+  for_each = local.service_account_roles
+
+  statement {
+    actions = ["sts:AssumeRoleWithWebIdentity"]
+
+    principals {
+      type        = "Federated"
+      identifiers = [module.eks.oidc_provider_arn]
+    }
+
+    condition {
+      test     = "StringEquals"
+      variable = "${local.oidc_issuer}:sub"
+      values   = ["system:serviceaccount:${each.value.namespace}:${each.key}"]
+    }
+  }
+}
+
+data "aws_iam_policy_document" "dns" {
+  statement {
+    actions   = ["route53:GetChange"]
+    resources = ["arn:aws:route53:::change/*"]
+  }
+  statement {
+    actions   = ["route53:ChangeResourceRecordSets", "route53:ListResourceRecordSets"]
+    resources = ["arn:aws:route53:::hostedzone/*"]
+  }
+  statement {
+    actions   = ["route53:ListHostedZones", "route53:ListHostedZonesByName"]
+    resources = ["*"]
+  }
+}
+
+data "aws_iam_policy_document" "builds" {
+  statement {
+    actions   = ["s3:ListBucket", "s3:GetObject", "s3:PutObject", "s3:DeleteObject"]
+    resources = flatten([for b in aws_s3_bucket.storage : [b.arn, "${b.arn}/*"]])
+  }
+  statement {
+    actions = [
+      "ecr:GetAuthorizationToken",
+      "ecr:BatchCheckLayerAvailability",
+      "ecr:GetDownloadUrlForLayer",
+      "ecr:BatchGetImage",
+      "ecr:CreateRepository",
+      "ecr:DescribeRepositories",
+      "ecr:InitiateLayerUpload",
+      "ecr:UploadLayerPart",
+      "ecr:CompleteLayerUpload",
+      "ecr:PutImage",
+    ]
+    resources = ["*"]
+  }
+}
+
+resource "aws_iam_role" "service_account" {
+  for_each           = local.service_account_roles
+  name               = "${var.cluster_name}-${each.key}"
+  assume_role_policy = data.aws_iam_policy_document.assume_role[each.key].json
+  tags               = var.tags
+}
+
+resource "aws_iam_role_policy" "service_account" {
+  for_each = { for name, role in local.service_account_roles : name => role if role.policy != "" }
+  name     = each.key
+  role     = aws_iam_role.service_account[each.key].id
+  policy   = each.value.policy
+}
+`
+
+const eksVariablesTf = `variable "cluster_name" {
+  description = "The name of the EKS cluster"
+  type        = string
+}
+
+variable "region" {
+  description = "The AWS region to create the cluster in"
+  type        = string
+}
+
+variable "availability_zones" {
+  description = "The availability zones of the cluster. The first 3 zones of the region are used if empty"
+  type        = list(string)
+  default     = []
+}
+
+variable "cluster_version" {
+  description = "The Kubernetes version of the cluster"
+  type        = string
+  default     = "1.14"
+}
+
+variable "vpc_cidr_block" {
+  description = "The CIDR block of the VPC of the cluster"
+  type        = string
+  default     = "10.0.0.0/16"
+}
+
+variable "node_type" {
+  description = "The instance type of the nodes"
+  type        = string
+  default     = "m5.large"
+}
+
+variable "nodes_desired" {
+  description = "The number of nodes"
+  type        = number
+  default     = 3
+}
+
+variable "nodes_min" {
+  description = "The minimum number of nodes"
+  type        = number
+  default     = 3
+}
+
+variable "nodes_max" {
+  description = "The maximum number of nodes"
+  type        = number
+  default     = 5
+}
+
+variable "node_volume_size" {
+  description = "The size of the volumes of the nodes in GB"
+  type        = number
+  default     = 20
+}
+
+variable "spot_build_pool" {
+  description = "Creates a node group of spot instances for the build pods"
+  type        = bool
+  default     = false
+}
+
+variable "spot_node_types" {
+  description = "The instance types of the spot build node group"
+  type        = list(string)
+  default     = []
+}
+
+variable "spot_nodes_max" {
+  description = "The maximum number of nodes of the spot build node group"
+  type        = number
+  default     = 5
+}
+
+variable "domain" {
+  description = "The domain to create a Route 53 hosted zone for. No zone is created if empty"
+  type        = string
+  default     = ""
+}
+
+variable "force_destroy_buckets" {
+  description = "Deletes the contents of the buckets when the module is destroyed"
+  type        = bool
+  default     = false
+}
+
+variable "tags" {
+  description = "The tags of all the resources"
+  type        = map(string)
+  default     = {}
+}
+`
+
+// the names of the outputs match the default terraform output mappings of 'jx boot --requirements-from-terraform'
+const eksOutputsTf = `output "cloud_provider" {
+  value = "eks"
+}
+
+output "cluster_name" {
+  value = module.eks.cluster_id
+}
+
+output "region" {
+  value = var.region
+}
+
+output "vpc_id" {
+  value = module.vpc.vpc_id
+}
+
+output "lts_logs_bucket" {
+  value = aws_s3_bucket.storage["logs"].id
+}
+
+output "lts_reports_bucket" {
+  value = aws_s3_bucket.storage["reports"].id
+}
+
+output "lts_repository_bucket" {
+  value = aws_s3_bucket.storage["repository"].id
+}
+
+output "backup_bucket" {
+  value = aws_s3_bucket.storage["backup"].id
+}
+
+output "domain" {
+  value = var.domain
+}
+
+output "domain_name_servers" {
+  value = length(aws_route53_zone.domain) > 0 ? aws_route53_zone.domain[0].name_servers : []
+}
+
+output "cert_manager_iam_role" {
+  value = aws_iam_role.service_account["cert-manager"].arn
+}
+
+output "cm_cainjector_iam_role" {
+  value = aws_iam_role.service_account["cm-cainjector"].arn
+}
+
+output "external_dns_iam_role" {
+  value = aws_iam_role.service_account["exdns-external-dns"].arn
+}
+
+output "tekton_bot_iam_role" {
+  value = aws_iam_role.service_account["tekton-bot"].arn
+}
+
+output "controllerbuild_iam_role" {
+  value = aws_iam_role.service_account["jenkins-x-controllerbuild"].arn
+}
+`
@@ -0,0 +1,65 @@
+package terraform_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x/jx/pkg/terraform"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteEKSModule(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "test-eks-module-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	module := &terraform.EKSModule{
+		ClusterName:    "mycluster",
+		Region:         "us-west-2",
+		NodeType:       "m5.large",
+		NodesDesired:   3,
+		NodesMin:       3,
+		NodesMax:       5,
+		NodeVolumeSize: 20,
+		Domain:         "jx.example.com",
+		Tags:           map[string]string{"CreatedBy": "JenkinsX"},
+	}
+	files, err := terraform.WriteEKSModule(dir, module)
+	require.NoError(t, err)
+	assert.Len(t, files, 5)
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, terraform.VarsFileName))
+	require.NoError(t, err)
+	vars := map[string]interface{}{}
+	err = json.Unmarshal(data, &vars)
+	require.NoError(t, err)
+	assert.Equal(t, "mycluster", vars["cluster_name"])
+	assert.Equal(t, "jx.example.com", vars["domain"])
+	assert.Equal(t, float64(5), vars["nodes_max"])
+
+	outputs, err := ioutil.ReadFile(filepath.Join(dir, "outputs.tf"))
+	require.NoError(t, err)
+	assert.Contains(t, string(outputs), `output "lts_logs_bucket"`)
+
+	_, err = terraform.WriteEKSModule(dir, module)
+	assert.Error(t, err, "should not overwrite an existing module")
+}
+
+func TestEKSModuleValidate(t *testing.T) {
+	t.Parallel()
+
+	module := &terraform.EKSModule{ClusterName: "mycluster", Region: "us-west-2", NodesDesired: 6, NodesMin: 3, NodesMax: 5}
+	assert.Error(t, module.Validate())
+
+	module.NodesDesired = 4
+	assert.NoError(t, module.Validate())
+
+	module.SpotBuildPool = true
+	assert.Error(t, module.Validate())
+}
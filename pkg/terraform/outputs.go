@@ -0,0 +1,84 @@
+package terraform
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/pkg/errors"
+)
+
+// outputValue a single output of `terraform output -json` or of a state file
+type outputValue struct {
+	Sensitive bool        `json:"sensitive,omitempty"`
+	Value     interface{} `json:"value"`
+}
+
+// stateFile the subset of a terraform state file containing the outputs. Version 4 state files contain the root
+// module outputs at the top level whereas older versions contain them in the root module
+type stateFile struct {
+	Version int                    `json:"version"`
+	Outputs map[string]outputValue `json:"outputs"`
+	Modules []struct {
+		Path    []string               `json:"path"`
+		Outputs map[string]outputValue `json:"outputs"`
+	} `json:"modules"`
+}
+
+// LoadOutputs loads the values of the outputs from either a terraform state file or the JSON written by
+// `terraform output -json`
+func LoadOutputs(fileName string) (map[string]interface{}, error) {
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read terraform outputs file %s", fileName)
+	}
+	outputs, err := ParseOutputs(data)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse terraform outputs file %s", fileName)
+	}
+	return outputs, nil
+}
+
+// ParseOutputs parses the values of the outputs from either a terraform state file or the JSON written by
+// `terraform output -json`
+func ParseOutputs(data []byte) (map[string]interface{}, error) {
+	raw := map[string]json.RawMessage{}
+	err := json.Unmarshal(data, &raw)
+	if err != nil {
+		return nil, err
+	}
+	answer := map[string]interface{}{}
+	_, hasVersion := raw["version"]
+	if !hasVersion {
+		outputs := map[string]outputValue{}
+		err = json.Unmarshal(data, &outputs)
+		if err != nil {
+			return nil, errors.Wrap(err, "unmarshalling the terraform output JSON")
+		}
+		for name, output := range outputs {
+			answer[name] = output.Value
+		}
+		return answer, nil
+	}
+
+	state := &stateFile{}
+	err = json.Unmarshal(data, state)
+	if err != nil {
+		return nil, errors.Wrap(err, "unmarshalling the terraform state")
+	}
+	if state.Version >= 4 {
+		for name, output := range state.Outputs {
+			answer[name] = output.Value
+		}
+		return answer, nil
+	}
+	for _, module := range state.Modules {
+		if len(module.Path) == 1 && module.Path[0] == "root" {
+			for name, output := range module.Outputs {
+				answer[name] = output.Value
+			}
+			return answer, nil
+		}
+	}
+	return nil, fmt.Errorf("no root module found in terraform state version %d", state.Version)
+}
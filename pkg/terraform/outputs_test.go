@@ -0,0 +1,34 @@
+package terraform_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x/jx/pkg/terraform"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadOutputs(t *testing.T) {
+	t.Parallel()
+
+	outputs, err := terraform.LoadOutputs(filepath.Join("test_data", "outputs.json"))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"cluster_name":    "mycluster",
+		"lts_logs_bucket": "logs-mycluster-abc",
+	}, outputs)
+
+	outputs, err = terraform.LoadOutputs(filepath.Join("test_data", "terraform.tfstate"))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"cluster_name": "mycluster",
+		"buckets": map[string]interface{}{
+			"logs": "gs://logs-mycluster",
+		},
+	}, outputs)
+
+	outputs, err = terraform.LoadOutputs(filepath.Join("test_data", "terraform-v3.tfstate"))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"cluster_name": "mycluster"}, outputs)
+}
@@ -188,3 +188,65 @@ func extractVersionFromTerraformOutput(output string) (string, error) {
 	return "", errors.Errorf("unable to extract version from output '%s'", output)
 
 }
+
+// InitDir initialises the terraform module in the given directory
+func InitDir(dir string) error {
+	log.Logger().Infof("Initialising Terraform in %s", util.ColorInfo(dir))
+	cmd := util.Command{
+		Name: "terraform",
+		Args: []string{"init", "-input=false"},
+		Dir:  dir,
+	}
+	_, err := cmd.RunWithoutRetry()
+	if err != nil {
+		return errors.Wrapf(err, "initialising terraform in %s", dir)
+	}
+	return nil
+}
+
+// PlanDir creates a plan of the terraform module in the given directory which is saved to the plan file so that
+// exactly the reviewed changes are applied. Returns the output of the plan
+func PlanDir(dir string, planFile string) (string, error) {
+	log.Logger().Infof("Showing Terraform Plan")
+	cmd := util.Command{
+		Name: "terraform",
+		Args: []string{"plan", "-input=false", fmt.Sprintf("-out=%s", planFile)},
+		Dir:  dir,
+	}
+	out, err := cmd.RunWithoutRetry()
+	if err != nil {
+		return out, errors.Wrapf(err, "planning terraform in %s", dir)
+	}
+	return out, nil
+}
+
+// ApplyPlan applies a plan file created by PlanDir in the given directory
+func ApplyPlan(dir string, planFile string, stdout io.Writer, stderr io.Writer) error {
+	log.Logger().Infof("Applying Terraform")
+	cmd := util.Command{
+		Name: "terraform",
+		Args: []string{"apply", "-input=false", planFile},
+		Dir:  dir,
+		Out:  stdout,
+		Err:  stderr,
+	}
+	_, err := cmd.RunWithoutRetry()
+	if err != nil {
+		return errors.Wrapf(err, "applying terraform plan %s in %s", planFile, dir)
+	}
+	return nil
+}
+
+// OutputJSON returns the JSON of all the outputs of the terraform module in the given directory
+func OutputJSON(dir string) (string, error) {
+	cmd := util.Command{
+		Name: "terraform",
+		Args: []string{"output", "-json"},
+		Dir:  dir,
+	}
+	out, err := cmd.RunWithoutRetry()
+	if err != nil {
+		return out, errors.Wrapf(err, "getting the terraform outputs in %s", dir)
+	}
+	return out, nil
+}
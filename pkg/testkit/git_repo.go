@@ -0,0 +1,97 @@
+package testkit
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/stretchr/testify/require"
+)
+
+// GitRepo a temporary git repository built up by tests using the git binary
+type GitRepo struct {
+	// Dir the directory of the working tree
+	Dir string
+
+	t *testing.T
+}
+
+// NewGitRepo initialises a git repository in a new temporary directory of the harness with a local user so that
+// commits work on CI machines without any git configuration
+func (h *Harness) NewGitRepo() *GitRepo {
+	repo := &GitRepo{
+		Dir: h.TempDir(),
+		t:   h.t,
+	}
+	repo.Git("init")
+	repo.Git("config", "user.name", "jenkins-x-test")
+	repo.Git("config", "user.email", "jenkins-x-test@example.com")
+	return repo
+}
+
+// WriteFile writes the file relative to the working tree creating any parent directories
+func (r *GitRepo) WriteFile(name string, contents string) *GitRepo {
+	fileName := filepath.Join(r.Dir, name)
+	err := os.MkdirAll(filepath.Dir(fileName), util.DefaultWritePermissions)
+	require.NoError(r.t, err)
+	err = ioutil.WriteFile(fileName, []byte(contents), util.DefaultWritePermissions)
+	require.NoError(r.t, err)
+	return r
+}
+
+// CopyDir copies the files of the directory such as test data into the working tree
+func (r *GitRepo) CopyDir(dir string) *GitRepo {
+	err := util.CopyDirOverwrite(dir, r.Dir)
+	require.NoError(r.t, err)
+	return r
+}
+
+// Commit adds all the changes and commits them returning the SHA of the commit
+func (r *GitRepo) Commit(message string) string {
+	r.Git("add", "-A")
+	r.Git("commit", "--allow-empty", "-m", message)
+	return r.Head()
+}
+
+// Tag creates an annotated tag of the current commit
+func (r *GitRepo) Tag(tag string, message string) *GitRepo {
+	r.Git("tag", "-a", tag, "-m", message)
+	return r
+}
+
+// Branch creates a branch and checks it out
+func (r *GitRepo) Branch(branch string) *GitRepo {
+	r.Git("checkout", "-b", branch)
+	return r
+}
+
+// Checkout checks out the branch, tag or commit
+func (r *GitRepo) Checkout(ref string) *GitRepo {
+	r.Git("checkout", ref)
+	return r
+}
+
+// Head returns the SHA of the current commit
+func (r *GitRepo) Head() string {
+	return r.Git("rev-parse", "HEAD")
+}
+
+// URL returns the URL other repositories can clone or push to
+func (r *GitRepo) URL() string {
+	return "file://" + r.Dir
+}
+
+// Git runs the git command in the working tree returning its trimmed output
+func (r *GitRepo) Git(args ...string) string {
+	cmd := util.Command{
+		Dir:  r.Dir,
+		Name: "git",
+		Args: args,
+	}
+	out, err := cmd.RunWithoutRetry()
+	require.NoError(r.t, err, "git %s", strings.Join(args, " "))
+	return strings.TrimSpace(out)
+}
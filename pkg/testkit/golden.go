@@ -0,0 +1,88 @@
+package testkit
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ghodss/yaml"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// UpdateGoldenEnvVar the environment variable which, when set, makes the golden assertions rewrite the golden files
+// with the actual values rather than comparing them - use with care!
+const UpdateGoldenEnvVar = "UPDATE_GOLDEN"
+
+// AssertGolden asserts the actual text matches the golden file ignoring trailing whitespace. To update the golden
+// files run the tests with UPDATE_GOLDEN=1
+func AssertGolden(t *testing.T, goldenFile string, actual string) bool {
+	actual = strings.TrimRight(actual, " \t\r\n") + "\n"
+	if os.Getenv(UpdateGoldenEnvVar) != "" {
+		err := os.MkdirAll(filepath.Dir(goldenFile), util.DefaultWritePermissions)
+		require.NoError(t, err)
+		err = ioutil.WriteFile(goldenFile, []byte(actual), util.DefaultFileWritePermissions)
+		require.NoError(t, err, "failed to update the golden file %s", goldenFile)
+		return true
+	}
+	data, err := ioutil.ReadFile(goldenFile)
+	require.NoError(t, err, "failed to read the golden file %s, run the test with %s=1 to create it", goldenFile, UpdateGoldenEnvVar)
+	expected := strings.TrimRight(string(data), " \t\r\n") + "\n"
+	return assert.Equal(t, expected, actual, "the golden file %s does not match", goldenFile)
+}
+
+// AssertGoldenYAML asserts the value marshalled as YAML matches the golden file
+func AssertGoldenYAML(t *testing.T, goldenFile string, actual interface{}) bool {
+	data, err := yaml.Marshal(actual)
+	require.NoError(t, err, "failed to marshal the value to YAML")
+	return AssertGolden(t, goldenFile, string(data))
+}
+
+// AssertGoldenDir asserts the files of the directory match the golden directory file by file
+func AssertGoldenDir(t *testing.T, goldenDir string, actualDir string) bool {
+	if os.Getenv(UpdateGoldenEnvVar) != "" {
+		err := os.RemoveAll(goldenDir)
+		require.NoError(t, err)
+		err = util.CopyDirOverwrite(actualDir, goldenDir)
+		require.NoError(t, err, "failed to update the golden directory %s", goldenDir)
+		return true
+	}
+	expected := relativeFiles(t, goldenDir)
+	actual := relativeFiles(t, actualDir)
+	if !assert.Equal(t, expected, actual, "the files in %s do not match the golden directory %s", actualDir, goldenDir) {
+		return false
+	}
+	answer := true
+	for _, name := range actual {
+		data, err := ioutil.ReadFile(filepath.Join(actualDir, name))
+		require.NoError(t, err)
+		answer = AssertGolden(t, filepath.Join(goldenDir, name), string(data)) && answer
+	}
+	return answer
+}
+
+func relativeFiles(t *testing.T, dir string) []string {
+	answer := []string{}
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if info.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		answer = append(answer, filepath.ToSlash(rel))
+		return nil
+	})
+	require.NoError(t, err, "failed to list the files in %s", dir)
+	return answer
+}
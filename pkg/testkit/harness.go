@@ -0,0 +1,216 @@
+// Package testkit provides a fake cluster harness so that cobra commands can be tested end to end against fake
+// kubernetes, Jenkins X and Tekton clientsets, a fake git provider and temporary git repositories
+package testkit
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	v1fake "github.com/jenkins-x/jx/pkg/client/clientset/versioned/fake"
+	"github.com/jenkins-x/jx/pkg/cmd/clients/fake"
+	"github.com/jenkins-x/jx/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/pkg/cmd/testhelpers"
+	"github.com/jenkins-x/jx/pkg/gits"
+	"github.com/jenkins-x/jx/pkg/gits/testfixtures"
+	"github.com/jenkins-x/jx/pkg/helm"
+	helm_test "github.com/jenkins-x/jx/pkg/helm/mocks"
+	resources_test "github.com/jenkins-x/jx/pkg/kube/resources/mocks"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+	tektonfake "github.com/tektoncd/pipeline/pkg/client/clientset/versioned/fake"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+// executeLock serialises command executions as the handler of fatal command errors is global
+var executeLock sync.Mutex
+
+// Harness the fake cluster and git provider a command is run against
+type Harness struct {
+	// CommonOptions the options to create commands with which use the fake clients
+	CommonOptions *opts.CommonOptions
+	KubeClient    *kubefake.Clientset
+	JXClient      *v1fake.Clientset
+	TektonClient  *tektonfake.Clientset
+	GitProvider   *gits.FakeProvider
+	Gitter        gits.Gitter
+	Helmer        helm.Helmer
+	// Namespace the namespace of the development environment
+	Namespace string
+
+	t       *testing.T
+	out     *os.File
+	tempDir string
+}
+
+// Option configures a harness
+type Option func(config *harnessConfig)
+
+type harnessConfig struct {
+	kubeObjects   []runtime.Object
+	jxObjects     []runtime.Object
+	tektonObjects []runtime.Object
+	scenario      *testfixtures.Scenario
+	gitProvider   *gits.FakeProvider
+	gitter        gits.Gitter
+	helmer        helm.Helmer
+}
+
+// KubeObjects adds the kubernetes resources to the fake cluster
+func KubeObjects(objects ...runtime.Object) Option {
+	return func(config *harnessConfig) {
+		config.kubeObjects = append(config.kubeObjects, objects...)
+	}
+}
+
+// JXObjects adds the Jenkins X resources such as environments and pipeline activities to the fake cluster
+func JXObjects(objects ...runtime.Object) Option {
+	return func(config *harnessConfig) {
+		config.jxObjects = append(config.jxObjects, objects...)
+	}
+}
+
+// TektonObjects adds the Tekton resources such as pipeline runs to the fake cluster
+func TektonObjects(objects ...runtime.Object) Option {
+	return func(config *harnessConfig) {
+		config.tektonObjects = append(config.tektonObjects, objects...)
+	}
+}
+
+// GitScenario uses the fake git provider and git client built from the scenario
+func GitScenario(scenario *testfixtures.Scenario) Option {
+	return func(config *harnessConfig) {
+		config.scenario = scenario
+	}
+}
+
+// GitProvider uses the fake git provider
+func GitProvider(provider *gits.FakeProvider) Option {
+	return func(config *harnessConfig) {
+		config.gitProvider = provider
+	}
+}
+
+// Gitter uses the git client such as gits.NewGitCLI() when commands need to work on real repositories
+func Gitter(gitter gits.Gitter) Option {
+	return func(config *harnessConfig) {
+		config.gitter = gitter
+	}
+}
+
+// Helmer uses the helm client. It defaults to a mock helmer
+func Helmer(helmer helm.Helmer) Option {
+	return func(config *harnessConfig) {
+		config.helmer = helmer
+	}
+}
+
+// NewHarness creates a harness of fake clients configured by the options. Call Cleanup when the test completes
+func NewHarness(t *testing.T, options ...Option) *Harness {
+	config := &harnessConfig{}
+	for _, option := range options {
+		option(config)
+	}
+	if config.scenario != nil {
+		fixture, err := config.scenario.Build()
+		require.NoError(t, err, "building the git scenario")
+		if config.gitProvider == nil {
+			config.gitProvider = fixture.Provider
+		}
+		if config.gitter == nil {
+			config.gitter = fixture.Gitter
+		}
+	}
+	if config.gitProvider == nil {
+		config.gitProvider = gits.NewFakeProvider()
+	}
+	if config.gitter == nil {
+		config.gitter = gits.NewGitFake()
+	}
+	if config.helmer == nil {
+		config.helmer = helm_test.NewMockHelmer()
+	}
+
+	tempDir, err := ioutil.TempDir("", "testkit-")
+	require.NoError(t, err)
+	out, err := ioutil.TempFile(tempDir, "out-")
+	require.NoError(t, err)
+
+	commonOpts := opts.NewCommonOptionsWithFactory(fake.NewFakeFactory())
+	commonOpts.Out = out
+	commonOpts.Err = out
+	testhelpers.ConfigureTestOptionsWithResources(&commonOpts, config.kubeObjects, config.jxObjects, config.gitter,
+		config.gitProvider, config.helmer, resources_test.NewMockInstaller())
+	tektonClient := tektonfake.NewSimpleClientset(config.tektonObjects...)
+	commonOpts.SetTektonClient(tektonClient)
+	testhelpers.SetFakeFactoryFromKubeClients(&commonOpts)
+
+	kubeClient, ns, err := commonOpts.KubeClientAndNamespace()
+	require.NoError(t, err)
+	jxClient, _, err := commonOpts.JXClient()
+	require.NoError(t, err)
+
+	return &Harness{
+		CommonOptions: &commonOpts,
+		KubeClient:    kubeClient.(*kubefake.Clientset),
+		JXClient:      jxClient.(*v1fake.Clientset),
+		TektonClient:  tektonClient,
+		GitProvider:   config.gitProvider,
+		Gitter:        config.gitter,
+		Helmer:        config.helmer,
+		Namespace:     ns,
+		t:             t,
+		out:           out,
+		tempDir:       tempDir,
+	}
+}
+
+// Execute runs the command with the arguments returning the error it failed with. Commands report errors via
+// helper.CheckErr so the fatal error handler is replaced while the command runs
+func (h *Harness) Execute(cmd *cobra.Command, args ...string) error {
+	executeLock.Lock()
+	defer executeLock.Unlock()
+
+	var answer error
+	helper.BehaviorOnFatal(func(msg string, code int) {
+		answer = errors.Errorf("%s (exit code %d)", strings.TrimPrefix(strings.TrimSpace(msg), "error: "), code)
+	})
+	defer helper.DefaultBehaviorOnFatal()
+
+	cmd.SetArgs(args)
+	cmd.SetOutput(h.out)
+	cmd.SilenceUsage = true
+	err := cmd.Execute()
+	if err != nil {
+		return err
+	}
+	return answer
+}
+
+// Output returns everything written to the output of the commands so far
+func (h *Harness) Output() string {
+	data, err := ioutil.ReadFile(h.out.Name())
+	require.NoError(h.t, err)
+	return string(data)
+}
+
+// TempDir creates a temporary directory which is removed by Cleanup
+func (h *Harness) TempDir() string {
+	dir, err := ioutil.TempDir(h.tempDir, "dir-")
+	require.NoError(h.t, err)
+	return dir
+}
+
+// Cleanup removes the temporary files of the harness
+func (h *Harness) Cleanup() {
+	h.out.Close()
+	err := os.RemoveAll(h.tempDir)
+	if err != nil {
+		h.t.Logf("failed to remove %s: %s", h.tempDir, err)
+	}
+}
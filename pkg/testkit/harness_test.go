@@ -0,0 +1,103 @@
+package testkit_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x/jx/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/pkg/gits/testfixtures"
+	"github.com/jenkins-x/jx/pkg/kube"
+	"github.com/jenkins-x/jx/pkg/testkit"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	pipelineapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// newCmdRuns creates a command which lists the pipeline runs of an environment like the jx commands do
+func newCmdRuns(commonOpts *opts.CommonOptions) *cobra.Command {
+	return &cobra.Command{
+		Use: "runs",
+		Run: func(cmd *cobra.Command, args []string) {
+			helper.CheckErr(listRuns(commonOpts, args[0]))
+		},
+	}
+}
+
+func listRuns(commonOpts *opts.CommonOptions, envName string) error {
+	jxClient, ns, err := commonOpts.JXClientAndDevNamespace()
+	if err != nil {
+		return err
+	}
+	env, err := jxClient.JenkinsV1().Environments(ns).Get(envName, metav1.GetOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "environment %s not found", envName)
+	}
+	tektonClient, _, err := commonOpts.TektonClient()
+	if err != nil {
+		return err
+	}
+	runs, err := tektonClient.TektonV1alpha1().PipelineRuns(env.Spec.Namespace).List(metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for _, run := range runs.Items {
+		fmt.Fprintf(commonOpts.Out, "%s\n", run.Name)
+	}
+	return nil
+}
+
+func TestHarnessExecutesCommands(t *testing.T) {
+	t.Parallel()
+	staging := kube.NewPermanentEnvironment("staging")
+	staging.Spec.Namespace = "jx-staging"
+	h := testkit.NewHarness(t,
+		testkit.JXObjects(staging),
+		testkit.TektonObjects(&pipelineapi.PipelineRun{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "myorg-myapp-master-1",
+				Namespace: "jx-staging",
+			},
+		}),
+		testkit.GitScenario(testfixtures.NewScenario().WithRepository("myorg", "myapp")))
+	defer h.Cleanup()
+
+	assert.Equal(t, "jx", h.Namespace)
+	assert.Equal(t, "myorg", h.GitProvider.CurrentUsername())
+	_, err := h.KubeClient.CoreV1().Namespaces().Get("jx-staging", metav1.GetOptions{})
+	require.NoError(t, err, "the namespaces of the environments are created")
+
+	err = h.Execute(newCmdRuns(h.CommonOptions), "staging")
+	require.NoError(t, err)
+	assert.Equal(t, "myorg-myapp-master-1\n", h.Output())
+
+	err = h.Execute(newCmdRuns(h.CommonOptions), "production")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "environment production not found")
+}
+
+func TestGitRepoAndGolden(t *testing.T) {
+	t.Parallel()
+	h := testkit.NewHarness(t)
+	defer h.Cleanup()
+
+	repo := h.NewGitRepo()
+	first := repo.WriteFile("env/values.yaml", "replicaCount: 1\n").Commit("initial import")
+	repo.Tag("v1.0.0", "release 1.0.0")
+	second := repo.Branch("upgrade").WriteFile("env/values.yaml", "replicaCount: 2\n").Commit("scale up")
+	assert.NotEqual(t, first, second)
+	assert.Equal(t, second, repo.Head())
+	assert.Equal(t, "upgrade", repo.Git("rev-parse", "--abbrev-ref", "HEAD"))
+	assert.Equal(t, first, repo.Git("rev-list", "-n", "1", "v1.0.0"))
+
+	data, err := ioutil.ReadFile(filepath.Join(repo.Dir, "env", "values.yaml"))
+	require.NoError(t, err)
+	testkit.AssertGolden(t, filepath.Join("test_data", "golden", "env", "values.yaml"), string(data))
+	testkit.AssertGoldenYAML(t, filepath.Join("test_data", "golden", "env", "values.yaml"), map[string]int{"replicaCount": 2})
+	testkit.AssertGoldenDir(t, filepath.Join("test_data", "golden"), repo.Dir)
+}
@@ -0,0 +1,112 @@
+package tunnel
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+)
+
+// DefaultNgrokAPI the URL of the local API of the ngrok agent
+const DefaultNgrokAPI = "http://127.0.0.1:4040"
+
+// NgrokTunnel tunnels requests to the target URL via the ngrok agent
+type NgrokTunnel struct {
+	Target string
+	// API the URL of the local API of the ngrok agent which is used to find the public URL
+	API string
+	// Timeout how long to wait for the ngrok agent to establish the tunnel
+	Timeout time.Duration
+
+	process
+}
+
+type ngrokTunnels struct {
+	Tunnels []ngrokTunnel `json:"tunnels"`
+}
+
+type ngrokTunnel struct {
+	PublicURL string `json:"public_url"`
+	Proto     string `json:"proto"`
+}
+
+// NewNgrokTunnel creates a new ngrok tunnel forwarding to the target URL
+func NewNgrokTunnel(target string) *NgrokTunnel {
+	return &NgrokTunnel{
+		Target:  target,
+		API:     DefaultNgrokAPI,
+		Timeout: 30 * time.Second,
+	}
+}
+
+// Start starts the ngrok agent and waits for its public URL
+func (t *NgrokTunnel) Start() (string, error) {
+	err := t.start("ngrok", "http", "--log=stdout", "--log-level=warn", "--host-header=rewrite", t.Target)
+	if err != nil {
+		return "", err
+	}
+	publicURL := ""
+	err = util.Retry(t.Timeout, func() error {
+		publicURL, err = t.publicURL()
+		return err
+	})
+	if err != nil {
+		t.Stop()
+		return "", errors.Wrapf(err, "waiting for the ngrok tunnel to %s", t.Target)
+	}
+	return publicURL, nil
+}
+
+// Wait blocks until the ngrok agent exits
+func (t *NgrokTunnel) Wait() error {
+	return t.wait()
+}
+
+// Stop stops the ngrok agent
+func (t *NgrokTunnel) Stop() error {
+	return t.stop()
+}
+
+func (t *NgrokTunnel) publicURL() (string, error) {
+	apiURL := util.UrlJoin(t.API, "api", "tunnels")
+	resp, err := http.Get(apiURL)
+	if err != nil {
+		return "", errors.Wrapf(err, "querying the ngrok API at %s", apiURL)
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", errors.Wrapf(err, "reading the response of the ngrok API at %s", apiURL)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("ngrok API at %s returned status %d: %s", apiURL, resp.StatusCode, string(data))
+	}
+	return ParseNgrokPublicURL(data)
+}
+
+// ParseNgrokPublicURL returns the public URL from the response of the ngrok tunnels API, preferring HTTPS
+func ParseNgrokPublicURL(data []byte) (string, error) {
+	tunnels := ngrokTunnels{}
+	err := json.Unmarshal(data, &tunnels)
+	if err != nil {
+		return "", errors.Wrap(err, "parsing the ngrok tunnels")
+	}
+	answer := ""
+	for _, tunnel := range tunnels.Tunnels {
+		if tunnel.Proto == "https" || strings.HasPrefix(tunnel.PublicURL, "https://") {
+			return tunnel.PublicURL, nil
+		}
+		if answer == "" {
+			answer = tunnel.PublicURL
+		}
+	}
+	if answer == "" {
+		return "", fmt.Errorf("no ngrok tunnels are running")
+	}
+	return answer, nil
+}
@@ -0,0 +1,27 @@
+package tunnel_test
+
+import (
+	"testing"
+
+	"github.com/jenkins-x/jx/pkg/tunnel"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseNgrokPublicURL(t *testing.T) {
+	data := `{"tunnels":[
+	  {"name":"command_line (http)","public_url":"http://1a2b3c.ngrok.io","proto":"http"},
+	  {"name":"command_line","public_url":"https://1a2b3c.ngrok.io","proto":"https"}
+	],"uri":"/api/tunnels"}`
+
+	url, err := tunnel.ParseNgrokPublicURL([]byte(data))
+	require.NoError(t, err)
+	assert.Equal(t, "https://1a2b3c.ngrok.io", url)
+
+	url, err = tunnel.ParseNgrokPublicURL([]byte(`{"tunnels":[{"public_url":"http://1a2b3c.ngrok.io","proto":"http"}]}`))
+	require.NoError(t, err)
+	assert.Equal(t, "http://1a2b3c.ngrok.io", url)
+
+	_, err = tunnel.ParseNgrokPublicURL([]byte(`{"tunnels":[]}`))
+	assert.Error(t, err)
+}
@@ -0,0 +1,79 @@
+package tunnel
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+)
+
+// DefaultRelayServer the smee compatible relay service used to create new channels
+const DefaultRelayServer = "https://smee.io"
+
+// RelayTunnel relays the requests sent to a channel of a smee compatible relay service to the target URL
+type RelayTunnel struct {
+	Target string
+	// URL the URL of the channel. A new channel is created on the Server if it is empty
+	URL string
+	// Server the relay service used to create a new channel
+	Server string
+
+	process
+}
+
+// NewRelayTunnel creates a new relay tunnel from the channel URL to the target URL
+func NewRelayTunnel(target string, url string) *RelayTunnel {
+	return &RelayTunnel{
+		Target: target,
+		URL:    url,
+		Server: DefaultRelayServer,
+	}
+}
+
+// Start starts the relay client, creating a new channel if required
+func (t *RelayTunnel) Start() (string, error) {
+	if t.URL == "" {
+		url, err := NewRelayChannel(t.Server)
+		if err != nil {
+			return "", err
+		}
+		t.URL = url
+	}
+	err := t.start("smee", "--url", t.URL, "--target", t.Target)
+	if err != nil {
+		return "", err
+	}
+	return t.URL, nil
+}
+
+// Wait blocks until the relay client exits
+func (t *RelayTunnel) Wait() error {
+	return t.wait()
+}
+
+// Stop stops the relay client
+func (t *RelayTunnel) Stop() error {
+	return t.stop()
+}
+
+// NewRelayChannel creates a new channel on the relay server and returns its URL
+func NewRelayChannel(server string) (string, error) {
+	client := &http.Client{
+		Transport: util.DefaultTransport(),
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	newURL := server + "/new"
+	resp, err := client.Head(newURL)
+	if err != nil {
+		return "", errors.Wrapf(err, "creating a new channel via %s", newURL)
+	}
+	defer resp.Body.Close()
+	location := resp.Header.Get("Location")
+	if location == "" {
+		return "", fmt.Errorf("no channel was returned by %s, status %d", newURL, resp.StatusCode)
+	}
+	return location, nil
+}
@@ -0,0 +1,83 @@
+package tunnel
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+)
+
+const (
+	// KindNgrok tunnels requests to the target via ngrok
+	KindNgrok = "ngrok"
+	// KindRelay relays requests to the target via a smee compatible relay service
+	KindRelay = "smee"
+)
+
+// Kinds the kinds of tunnels which are supported
+var Kinds = []string{KindNgrok, KindRelay}
+
+// Tunnel forwards the requests sent to a public URL to a target URL which is not reachable from the internet
+type Tunnel interface {
+	// Start starts the tunnel and returns the public URL it receives requests on
+	Start() (string, error)
+	// Wait blocks until the tunnel process exits
+	Wait() error
+	// Stop stops the tunnel
+	Stop() error
+}
+
+// NewTunnel creates a tunnel of the given kind forwarding to the target URL. The url is the channel of the relay
+// service which is only used by relay tunnels
+func NewTunnel(kind string, target string, url string) (Tunnel, error) {
+	switch kind {
+	case KindNgrok:
+		return NewNgrokTunnel(target), nil
+	case KindRelay:
+		return NewRelayTunnel(target, url), nil
+	default:
+		return nil, util.InvalidOption("kind", kind, Kinds)
+	}
+}
+
+// process runs a tunnel binary in the background
+type process struct {
+	cmd *exec.Cmd
+}
+
+func (p *process) start(name string, args ...string) error {
+	_, err := exec.LookPath(name)
+	if err != nil {
+		return errors.Wrapf(err, "the %s binary must be on the PATH to create the tunnel", name)
+	}
+	p.cmd = exec.Command(name, args...)
+	p.cmd.Stdout = os.Stdout
+	p.cmd.Stderr = os.Stderr
+	log.Logger().Debugf("running %s", util.ColorInfo(fmt.Sprintf("%s %v", name, args)))
+	err = p.cmd.Start()
+	if err != nil {
+		return errors.Wrapf(err, "starting %s", name)
+	}
+	return nil
+}
+
+func (p *process) wait() error {
+	if p.cmd == nil {
+		return fmt.Errorf("the tunnel has not been started")
+	}
+	return p.cmd.Wait()
+}
+
+func (p *process) stop() error {
+	if p.cmd == nil || p.cmd.Process == nil {
+		return nil
+	}
+	err := p.cmd.Process.Kill()
+	if err != nil && err.Error() != "os: process already finished" {
+		return errors.Wrap(err, "stopping the tunnel")
+	}
+	return nil
+}
@@ -40,10 +40,27 @@ func LoadCacheData(fileName string, loader CacheLoader) ([]byte, error) {
 			log.Logger().Warnf("Failed to update cache file %s due to %s", fileName, err2)
 		}
 		writeTimeToFile(timecheckFileName, time.Now())
+	} else if exists {
+		// lets fall back to the stale cache so we can work offline
+		log.Logger().Warnf("Using the cached data in %s as it could not be refreshed: %s", fileName, err)
+		return ioutil.ReadFile(fileName)
 	}
 	return data, err
 }
 
+// RefreshCacheData invokes the loader and updates the cache file ignoring the cache timeout
+func RefreshCacheData(fileName string, loader CacheLoader) ([]byte, error) {
+	data, err := loader()
+	if err != nil {
+		return data, err
+	}
+	err = ioutil.WriteFile(fileName, data, defaultFileWritePermisons)
+	if err != nil {
+		return data, err
+	}
+	return data, writeTimeToFile(fileName+"_last_time_check", time.Now())
+}
+
 // shouldUseCache returns true if we should use the cached data to serve up the content
 func shouldUseCache(filePath string) bool {
 	lastUpdateTime := getTimeFromFileIfExists(filePath)
@@ -3,12 +3,14 @@ package util
 import (
 	"fmt"
 	"io"
+	"net/url"
 	"os"
 	"os/exec"
 	"strings"
 	"time"
 
 	"github.com/cenkalti/backoff"
+	"github.com/jenkins-x/jx/pkg/log"
 )
 
 // Command is a struct containing the details of an external command to be executed
@@ -199,6 +201,9 @@ func (c *Command) run() (string, error) {
 	e := exec.Command(c.Name, c.Args...) // #nosec
 	if c.Dir != "" {
 		e.Dir = c.Dir
+		log.Logger().Debugf("running command in %s: %s %s", c.Dir, c.Name, strings.Join(RedactArgs(c.Args), " "))
+	} else {
+		log.Logger().Debugf("running command: %s %s", c.Name, strings.Join(RedactArgs(c.Args), " "))
 	}
 	if len(c.Env) > 0 {
 		m := map[string]string{}
@@ -272,3 +277,36 @@ func PathWithBinary(paths ...string) string {
 	}
 	return answer
 }
+
+// sensitiveArgs are the command line flags whose values are redacted when logging a command
+var sensitiveArgs = []string{"--password", "--token", "--api-token"}
+
+// RedactArgs returns a copy of the command line arguments with the values of password and token flags and the
+// credentials of any URLs replaced so that the command line can be safely logged
+func RedactArgs(args []string) []string {
+	answer := make([]string, len(args))
+	redactNext := false
+	for i, arg := range args {
+		if redactNext {
+			answer[i] = "*****"
+			redactNext = false
+			continue
+		}
+		answer[i] = arg
+		for _, flag := range sensitiveArgs {
+			if arg == flag {
+				redactNext = true
+			} else if strings.HasPrefix(arg, flag+"=") {
+				answer[i] = flag + "=*****"
+			}
+		}
+		if strings.Contains(arg, "://") && strings.Contains(arg, "@") {
+			u, err := url.Parse(arg)
+			if err == nil && u.User != nil {
+				u.User = nil
+				answer[i] = u.String()
+			}
+		}
+	}
+	return answer
+}
@@ -154,6 +154,7 @@ func getLatestReleaseFromHostUsingHttpRedirect(host, githubOwner, githubRepo str
 	url := fmt.Sprintf("%s/%s/%s/releases/latest", host, githubOwner, githubRepo)
 
 	client := &http.Client{
+		Transport: DefaultTransport(),
 		CheckRedirect: func(req *http.Request, via []*http.Request) error { // Don't follow redirects
 			// We want to follow 301 permanent redirects (eg, repo renames like kubernetes/helm --> helm/helm)
 			// but not temporary 302 temporary redirects (as these point to the latest tag)
@@ -344,3 +345,61 @@ func UnTarFile(header *tar.Header, target string, tarReader io.Reader) error {
 	_, err = io.Copy(file, tarReader)
 	return err
 }
+
+// TarGz creates the gzipped tarball containing the given files and directories which are relative to the source
+// directory. Any paths which do not exist are ignored
+func TarGz(srcDir string, tarball string, paths ...string) error {
+	out, err := os.Create(tarball)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	zwriter := gzip.NewWriter(out)
+	defer zwriter.Close()
+	tarWriter := tar.NewWriter(zwriter)
+	defer tarWriter.Close()
+
+	for _, p := range paths {
+		root := filepath.Join(srcDir, p)
+		exists, err := FileExists(root)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			continue
+		}
+		err = filepath.Walk(root, func(file string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			name, err := filepath.Rel(srcDir, file)
+			if err != nil {
+				return err
+			}
+			header, err := tar.FileInfoHeader(info, "")
+			if err != nil {
+				return err
+			}
+			header.Name = filepath.ToSlash(name)
+			err = tarWriter.WriteHeader(header)
+			if err != nil {
+				return err
+			}
+			if !info.Mode().IsRegular() {
+				return nil
+			}
+			f, err := os.Open(file)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			_, err = io.Copy(tarWriter, f)
+			return err
+		})
+		if err != nil {
+			return errors.Wrapf(err, "adding %s to %s", p, tarball)
+		}
+	}
+	return nil
+}
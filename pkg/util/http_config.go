@@ -0,0 +1,104 @@
+package util
+
+import (
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// HTTPConfigFileName the name of the file in the jx home directory containing the HTTP client settings
+	HTTPConfigFileName = "http.yaml"
+
+	// CAFileEnvVar the environment variable which overrides the custom CA bundle of the HTTP config
+	CAFileEnvVar = "JX_CA_FILE"
+)
+
+// HTTPConfig the settings of the HTTP clients used by jx
+type HTTPConfig struct {
+	// CAFile a file of PEM encoded certificates of custom certificate authorities which are trusted in addition to the
+	// system ones
+	CAFile string `json:"caFile,omitempty"`
+}
+
+// HTTPConfigFile returns the location of the HTTP config file
+func HTTPConfigFile() (string, error) {
+	dir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, HTTPConfigFileName), nil
+}
+
+// LoadHTTPConfig loads the HTTP config returning an empty config if the file does not exist
+func LoadHTTPConfig() (*HTTPConfig, error) {
+	answer := &HTTPConfig{}
+	fileName, err := HTTPConfigFile()
+	if err != nil {
+		return answer, err
+	}
+	exists, err := FileExists(fileName)
+	if err != nil || !exists {
+		return answer, err
+	}
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return answer, errors.Wrapf(err, "reading %s", fileName)
+	}
+	err = yaml.Unmarshal(data, answer)
+	if err != nil {
+		return answer, errors.Wrapf(err, "unmarshalling %s", fileName)
+	}
+	return answer, nil
+}
+
+// SaveHTTPConfig saves the HTTP config
+func SaveHTTPConfig(config *HTTPConfig) error {
+	fileName, err := HTTPConfigFile()
+	if err != nil {
+		return err
+	}
+	data, err := yaml.Marshal(config)
+	if err != nil {
+		return err
+	}
+	err = ioutil.WriteFile(fileName, data, DefaultWritePermissions)
+	if err != nil {
+		return errors.Wrapf(err, "saving %s", fileName)
+	}
+	return nil
+}
+
+// CAFile returns the custom CA bundle from the $JX_CA_FILE environment variable or the HTTP config, if any
+func CAFile() (string, error) {
+	caFile := os.Getenv(CAFileEnvVar)
+	if caFile != "" {
+		return caFile, nil
+	}
+	config, err := LoadHTTPConfig()
+	if err != nil {
+		return "", err
+	}
+	return config.CAFile, nil
+}
+
+// LoadCertPool returns the system certificate pool with the certificates of the given CA bundle appended
+func LoadCertPool(caFile string) (*x509.CertPool, error) {
+	pool, err := x509.SystemCertPool()
+	if err != nil || pool == nil {
+		pool = x509.NewCertPool()
+	}
+	data, err := ioutil.ReadFile(caFile)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading the CA file %s", caFile)
+	}
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no PEM encoded certificates found in the CA file %s", caFile)
+	}
+	return pool, nil
+}
@@ -0,0 +1,52 @@
+package util
+
+import (
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewTransportTrustsCAFile(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "test-ca-file-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	caFile := filepath.Join(dir, "ca.pem")
+	data := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	require.NoError(t, ioutil.WriteFile(caFile, data, DefaultWritePermissions))
+
+	oldValue, hasOldValue := os.LookupEnv(CAFileEnvVar)
+	defer func() {
+		if hasOldValue {
+			os.Setenv(CAFileEnvVar, oldValue)
+		} else {
+			os.Unsetenv(CAFileEnvVar)
+		}
+	}()
+
+	os.Setenv(CAFileEnvVar, caFile)
+	transport, err := NewTransport()
+	require.NoError(t, err)
+	resp, err := (&http.Client{Transport: transport}).Get(server.URL)
+	require.NoError(t, err, "should trust the certificate of the CA file")
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	invalidFile := filepath.Join(dir, "invalid.pem")
+	require.NoError(t, ioutil.WriteFile(invalidFile, []byte("not a certificate"), DefaultWritePermissions))
+	os.Setenv(CAFileEnvVar, invalidFile)
+	_, err = NewTransport()
+	require.Error(t, err, "should fail for a CA file without certificates")
+}
@@ -2,7 +2,7 @@ package util
 
 import (
 	"bytes"
-
+	"crypto/tls"
 	"io/ioutil"
 	"net"
 	"net/http"
@@ -10,6 +10,7 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/cenkalti/backoff"
@@ -17,31 +18,72 @@ import (
 	"github.com/pkg/errors"
 )
 
-// defaults mirror the default http.Transport values
-var jxDefaultTransport http.RoundTripper = &http.Transport{
-	DialContext: (&net.Dialer{
-		Timeout:   time.Duration(getIntFromEnv("HTTP_DIALER_TIMEOUT", 30)) * time.Second,
-		KeepAlive: time.Duration(getIntFromEnv("HTTP_DIALER_KEEP_ALIVE", 30)) * time.Second,
-		DualStack: getBoolFromEnv("HTTP_USE_DUAL_STACK", true),
-	}).DialContext,
-	MaxIdleConns:          getIntFromEnv("HTTP_MAX_IDLE_CONNS", 100),
-	IdleConnTimeout:       time.Duration(getIntFromEnv("HTTP_IDLE_CONN_TIMEOUT", 90)) * time.Second,
-	TLSHandshakeTimeout:   time.Duration(getIntFromEnv("HTTP_TLS_HANDSHAKE_TIMEOUT", 10)) * time.Second,
-	ExpectContinueTimeout: time.Duration(getIntFromEnv("HTTP_EXPECT_CONTINUE_TIMEOUT", 1)) * time.Second,
-	Proxy:                 http.ProxyFromEnvironment,
+var (
+	defaultTransportOnce sync.Once
+	jxDefaultTransport   http.RoundTripper
+	defaultClient        *http.Client
+)
+
+// NewTransport returns a new transport which uses the proxy settings of the $HTTP_PROXY, $HTTPS_PROXY and $NO_PROXY
+// environment variables and trusts the custom CA bundle configured via 'jx edit config --ca-file' or $JX_CA_FILE
+// in addition to the system certificate authorities. The defaults mirror the default http.Transport values
+func NewTransport() (*http.Transport, error) {
+	transport := &http.Transport{
+		DialContext: (&net.Dialer{
+			Timeout:   time.Duration(getIntFromEnv("HTTP_DIALER_TIMEOUT", 30)) * time.Second,
+			KeepAlive: time.Duration(getIntFromEnv("HTTP_DIALER_KEEP_ALIVE", 30)) * time.Second,
+			DualStack: getBoolFromEnv("HTTP_USE_DUAL_STACK", true),
+		}).DialContext,
+		MaxIdleConns:          getIntFromEnv("HTTP_MAX_IDLE_CONNS", 100),
+		IdleConnTimeout:       time.Duration(getIntFromEnv("HTTP_IDLE_CONN_TIMEOUT", 90)) * time.Second,
+		TLSHandshakeTimeout:   time.Duration(getIntFromEnv("HTTP_TLS_HANDSHAKE_TIMEOUT", 10)) * time.Second,
+		ExpectContinueTimeout: time.Duration(getIntFromEnv("HTTP_EXPECT_CONTINUE_TIMEOUT", 1)) * time.Second,
+		Proxy:                 http.ProxyFromEnvironment,
+	}
+	caFile, err := CAFile()
+	if err != nil {
+		return transport, err
+	}
+	if caFile != "" {
+		pool, err := LoadCertPool(caFile)
+		if err != nil {
+			return transport, err
+		}
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool}
+	}
+	return transport, nil
+}
+
+// DefaultTransport returns the transport shared by all the HTTP clients created by jx
+func DefaultTransport() http.RoundTripper {
+	defaultTransportOnce.Do(func() {
+		transport, err := NewTransport()
+		if err != nil {
+			log.Logger().Warnf("Failed to configure the custom CA bundle so only the system certificate authorities are trusted: %s", err)
+		}
+		jxDefaultTransport = transport
+		defaultClient = &http.Client{Transport: transport, Timeout: time.Duration(getIntFromEnv("DEFAULT_HTTP_REQUEST_TIMEOUT", 30)) * time.Second}
+	})
+	return jxDefaultTransport
 }
 
-var defaultClient = http.Client{Transport: jxDefaultTransport, Timeout: time.Duration(getIntFromEnv("DEFAULT_HTTP_REQUEST_TIMEOUT", 30)) * time.Second}
+// ConfigureDefaultTransport replaces the transport of http.DefaultClient and of any clients created without a
+// transport, such as those of the git provider and cloud SDKs, with the jx default transport so that every outbound
+// call honors the proxy settings and the custom CA bundle
+func ConfigureDefaultTransport() {
+	http.DefaultTransport = DefaultTransport()
+}
 
 // GetClient returns a Client reference with our default configuration
 func GetClient() *http.Client {
-	return &defaultClient
+	DefaultTransport()
+	return defaultClient
 }
 
 // GetClientWithTimeout returns a client with JX default transport and user specified timeout
 func GetClientWithTimeout(duration time.Duration) *http.Client {
 	client := http.Client{}
-	client.Transport = jxDefaultTransport
+	client.Transport = DefaultTransport()
 	client.Timeout = duration
 	return &client
 }
@@ -92,7 +134,7 @@ func CallWithExponentialBackOff(url string, auth string, httpMethod string, reqB
 				req.URL.RawQuery = reqParams.Encode()
 			}
 
-			resp, err = defaultClient.Do(req)
+			resp, err = GetClient().Do(req)
 			if err != nil {
 				return backoff.Permanent(err)
 			}
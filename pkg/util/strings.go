@@ -280,3 +280,13 @@ func StripTrailingSlash(url string) string {
 func StartsWith(s, substr string) bool {
 	return strings.Index(s, substr) == 0
 }
+
+// StartsWithAny returns true if the string starts with any of the given prefixes
+func StartsWithAny(s string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+	return false
+}
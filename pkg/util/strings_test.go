@@ -156,3 +156,9 @@ func Test_StartsWith(t *testing.T) {
 	assert.True(t, util.StartsWith("ML-a-machine-learning-quickstart", "ML-"))
 	assert.False(t, util.StartsWith("not-a-machine-learning-quickstart", "ML-"))
 }
+
+func Test_StartsWithAny(t *testing.T) {
+	assert.True(t, util.StartsWithAny("jenkins-x-chartmuseum-1234", []string{"jx-", "jenkins-x-"}))
+	assert.False(t, util.StartsWithAny("my-app-1234", []string{"jx-", "jenkins-x-"}))
+	assert.False(t, util.StartsWithAny("my-app-1234", nil))
+}
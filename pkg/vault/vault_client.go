@@ -7,6 +7,8 @@ import (
 	"regexp"
 
 	"github.com/hashicorp/vault/api"
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/audit"
 	"github.com/jenkins-x/jx/pkg/secreturl"
 	"github.com/jenkins-x/jx/pkg/util"
 	"github.com/pkg/errors"
@@ -68,6 +70,9 @@ func (v *client) Write(secretName string, data map[string]interface{}) (map[stri
 		"data": data,
 	}
 	secret, err := v.client.Logical().Write(secretPath(secretName), payload)
+	if err == nil {
+		audit.Record(v1.AuditActionSecretWritten, "vault:"+secretName, "", fmt.Sprintf("wrote the secret %s to vault", secretName), nil)
+	}
 	if secret != nil {
 		return secret.Data, err
 	}
@@ -0,0 +1,147 @@
+package versionstream
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/blang/semver"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// CompatibilityFileName the name of the file in the version stream which defines the kubernetes versions and CRD
+	// versions each platform version supports
+	CompatibilityFileName = "compatibility.yml"
+)
+
+// Compatibility the compatibility manifest of the version stream
+type Compatibility struct {
+	// Platforms the compatibility of ranges of platform versions. The first matching entry is used
+	Platforms []PlatformCompatibility `json:"platforms,omitempty"`
+}
+
+// PlatformCompatibility the cluster a range of platform versions is compatible with
+type PlatformCompatibility struct {
+	// Versions the range of platform versions such as '>=2.0.0 <2.1.0'
+	Versions string `json:"versions"`
+	// Kubernetes the range of supported kubernetes versions such as '>=1.13.0 <1.17.0'
+	Kubernetes string `json:"kubernetes,omitempty"`
+	// CRDs the versions the CustomResourceDefinitions in the cluster must serve
+	CRDs []CRDCompatibility `json:"crds,omitempty"`
+}
+
+// CRDCompatibility the versions a CustomResourceDefinition must serve if it exists in the cluster
+type CRDCompatibility struct {
+	// Name the name of the CustomResourceDefinition such as 'pipelineruns.tekton.dev'
+	Name string `json:"name"`
+	// Versions the versions of which at least one must be served
+	Versions []string `json:"versions"`
+}
+
+// LoadCompatibility loads the compatibility manifest of the version stream returning an empty manifest if there is none
+func LoadCompatibility(dir string) (*Compatibility, error) {
+	answer := &Compatibility{}
+	fileName := filepath.Join(dir, CompatibilityFileName)
+	exists, err := util.FileExists(fileName)
+	if err != nil {
+		return answer, errors.Wrapf(err, "failed to check if file exists %s", fileName)
+	}
+	if !exists {
+		return answer, nil
+	}
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return answer, errors.Wrapf(err, "failed to load YAML file %s", fileName)
+	}
+	err = yaml.Unmarshal(data, answer)
+	if err != nil {
+		return answer, errors.Wrapf(err, "failed to unmarshal YAML for file %s", fileName)
+	}
+	return answer, nil
+}
+
+// ForPlatform returns the compatibility of the platform version or nil if there is none
+func (c *Compatibility) ForPlatform(platformVersion string) (*PlatformCompatibility, error) {
+	v, err := semver.ParseTolerant(platformVersion)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse the platform version %s", platformVersion)
+	}
+	for i := range c.Platforms {
+		p := &c.Platforms[i]
+		r, err := semver.ParseRange(p.Versions)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse the platform versions %s", p.Versions)
+		}
+		if r(v) {
+			return p, nil
+		}
+	}
+	return nil, nil
+}
+
+// Incompatibilities returns why the cluster is incompatible with the platform given the kubernetes version of the
+// cluster and the versions served by its CustomResourceDefinitions by name
+func (p *PlatformCompatibility) Incompatibilities(kubernetesVersion string, crdVersions map[string][]string) ([]string, error) {
+	answer := []string{}
+	if p.Kubernetes != "" && kubernetesVersion != "" {
+		v, err := ParseKubernetesVersion(kubernetesVersion)
+		if err != nil {
+			return nil, err
+		}
+		r, err := semver.ParseRange(p.Kubernetes)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse the kubernetes versions %s", p.Kubernetes)
+		}
+		if !r(v) {
+			answer = append(answer, fmt.Sprintf("the cluster is on kubernetes %s but the platform requires kubernetes %s", v, p.Kubernetes))
+		}
+	}
+	for _, crd := range p.CRDs {
+		served, ok := crdVersions[crd.Name]
+		if !ok {
+			continue
+		}
+		if !crd.IsServed(served) {
+			answer = append(answer, fmt.Sprintf("the CustomResourceDefinition %s serves versions %s but the platform requires one of %s",
+				crd.Name, strings.Join(served, ", "), strings.Join(crd.Versions, ", ")))
+		}
+	}
+	return answer, nil
+}
+
+// IsServed returns true if one of the served versions of the CustomResourceDefinition is one of the required versions
+func (c *CRDCompatibility) IsServed(served []string) bool {
+	return containsAny(served, c.Versions)
+}
+
+// ParseKubernetesVersion parses the version of a kubernetes cluster ignoring any 'v' prefix and provider suffix such
+// as '-gke.12' so that provider builds compare equal to the upstream release
+func ParseKubernetesVersion(text string) (semver.Version, error) {
+	text = strings.TrimPrefix(strings.TrimSpace(text), "v")
+	if idx := strings.IndexAny(text, "-+"); idx > 0 {
+		text = text[:idx]
+	}
+	if strings.Count(text, ".") == 1 {
+		text += ".0"
+	}
+	v, err := semver.Make(text)
+	if err != nil {
+		return v, errors.Wrapf(err, "failed to parse the kubernetes version %s", text)
+	}
+	return v, nil
+}
+
+func containsAny(values []string, candidates []string) bool {
+	for _, v := range values {
+		for _, c := range candidates {
+			if v == c {
+				return true
+			}
+		}
+	}
+	return false
+}
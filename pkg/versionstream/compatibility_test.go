@@ -0,0 +1,45 @@
+package versionstream_test
+
+import (
+	"testing"
+
+	"github.com/jenkins-x/jx/pkg/versionstream"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlatformCompatibility(t *testing.T) {
+	t.Parallel()
+
+	compatibility, err := versionstream.LoadCompatibility(dataDir)
+	require.NoError(t, err)
+	require.Len(t, compatibility.Platforms, 2)
+
+	platform, err := compatibility.ForPlatform("1.3.100")
+	require.NoError(t, err)
+	assert.Nil(t, platform)
+
+	platform, err = compatibility.ForPlatform("2.0.1200")
+	require.NoError(t, err)
+	require.NotNil(t, platform)
+	problems, err := platform.Incompatibilities("v1.15.4-gke.22", nil)
+	require.NoError(t, err)
+	assert.Empty(t, problems)
+
+	platform, err = compatibility.ForPlatform("2.1.3")
+	require.NoError(t, err)
+	require.NotNil(t, platform)
+	problems, err = platform.Incompatibilities("v1.13.11-gke.14", map[string][]string{
+		"pipelineruns.tekton.dev": {"v1alpha0"},
+		"apps.jenkins.io":         {"v1"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"the cluster is on kubernetes 1.13.11 but the platform requires kubernetes >=1.14.0 <1.17.0",
+		"the CustomResourceDefinition pipelineruns.tekton.dev serves versions v1alpha0 but the platform requires one of v1alpha1, v1beta1",
+	}, problems)
+
+	empty, err := versionstream.LoadCompatibility("test_data")
+	require.NoError(t, err)
+	assert.Empty(t, empty.Platforms)
+}
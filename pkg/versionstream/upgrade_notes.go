@@ -0,0 +1,44 @@
+package versionstream
+
+import (
+	"github.com/blang/semver"
+	"github.com/pkg/errors"
+)
+
+// UpgradeNote describes the risks of upgrading into a range of versions, such as the breaking changes of a chart and
+// the manual steps required before or after the upgrade
+type UpgradeNote struct {
+	// Versions the range of versions the note applies to such as '>=2.0.0'
+	Versions string `json:"versions"`
+	// Breaking true if the upgrade has breaking changes
+	Breaking bool `json:"breaking,omitempty"`
+	// Description describes the changes
+	Description string `json:"description,omitempty"`
+	// ManualSteps the steps which must be performed by hand
+	ManualSteps []string `json:"manualSteps,omitempty"`
+}
+
+// UpgradeNotes returns the notes which apply to an upgrade from the current version to the target version. A note
+// applies if its range contains the target version but not the current version
+func (data *StableVersion) UpgradeNotes(currentVersion string, targetVersion string) ([]UpgradeNote, error) {
+	answer := []UpgradeNote{}
+	if len(data.Upgrades) == 0 {
+		return answer, nil
+	}
+	target, err := semver.ParseTolerant(targetVersion)
+	if err != nil {
+		return answer, errors.Wrapf(err, "failed to parse the target version %s", targetVersion)
+	}
+	// an unknown or unparsable current version is treated as being outside of every range
+	current, currentErr := semver.ParseTolerant(currentVersion)
+	for _, note := range data.Upgrades {
+		r, err := semver.ParseRange(note.Versions)
+		if err != nil {
+			return answer, errors.Wrapf(err, "failed to parse the upgrade versions %s", note.Versions)
+		}
+		if r(target) && (currentErr != nil || !r(current)) {
+			answer = append(answer, note)
+		}
+	}
+	return answer, nil
+}
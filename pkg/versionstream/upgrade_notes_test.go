@@ -0,0 +1,45 @@
+package versionstream_test
+
+import (
+	"testing"
+
+	"github.com/jenkins-x/jx/pkg/versionstream"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpgradeNotes(t *testing.T) {
+	t.Parallel()
+
+	version, err := versionstream.LoadStableVersionFromData([]byte(`version: 2.1.10
+upgrades:
+- versions: ">=2.0.0"
+  breaking: true
+  description: the jenkins server is no longer installed by default
+  manualSteps:
+  - run 'jx upgrade crd' before upgrading
+- versions: ">=2.1.0"
+  description: the default ingress class changed
+`))
+	require.NoError(t, err)
+	require.Len(t, version.Upgrades, 2)
+
+	notes, err := version.UpgradeNotes("1.3.1000", "2.1.10")
+	require.NoError(t, err)
+	require.Len(t, notes, 2)
+	assert.True(t, notes[0].Breaking)
+	assert.Equal(t, []string{"run 'jx upgrade crd' before upgrading"}, notes[0].ManualSteps)
+
+	notes, err = version.UpgradeNotes("2.0.1200", "2.1.10")
+	require.NoError(t, err)
+	require.Len(t, notes, 1)
+	assert.Equal(t, "the default ingress class changed", notes[0].Description)
+
+	notes, err = version.UpgradeNotes("2.1.3", "2.1.10")
+	require.NoError(t, err)
+	assert.Empty(t, notes)
+
+	notes, err = version.UpgradeNotes("", "2.0.5")
+	require.NoError(t, err)
+	assert.Len(t, notes, 1)
+}
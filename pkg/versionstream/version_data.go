@@ -70,6 +70,8 @@ type StableVersion struct {
 	Component string `json:"component,omitempty"`
 	// URL the URL for the documentation
 	URL string `json:"url,omitempty"`
+	// Upgrades the notes about upgrading to ranges of versions such as breaking changes or manual steps
+	Upgrades []UpgradeNote `json:"upgrades,omitempty"`
 }
 
 // VerifyPackage verifies the current version of the package is valid
@@ -151,6 +153,28 @@ func LoadStableVersion(wrkDir string, kind VersionKind, name string) (*StableVer
 	return LoadStableVersionFile(path)
 }
 
+// FindChartName returns the full name of the chart, including its repository prefix, in the version stream or an
+// empty string if the version stream has no chart of the given name. The 'jenkins-x' repository is preferred if
+// several repositories have a chart of the same name
+func FindChartName(wrkDir string, chartName string) (string, error) {
+	chartsDir := filepath.Join(wrkDir, string(KindChart))
+	matches, err := filepath.Glob(filepath.Join(chartsDir, "*", chartName+".yml"))
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to find chart %s in %s", chartName, chartsDir)
+	}
+	if len(matches) == 0 {
+		return "", nil
+	}
+	sort.Strings(matches)
+	path := matches[0]
+	for _, m := range matches {
+		if filepath.Base(filepath.Dir(m)) == "jenkins-x" {
+			path = m
+		}
+	}
+	return NameFromPath(chartsDir, path)
+}
+
 // GitURLToName lets trim any URL scheme and trailing .git or / from a git URL
 func GitURLToName(name string) string {
 	// lets trim the URL scheme
@@ -405,6 +429,14 @@ type QuickStart struct {
 	Framework      string   `json:"framework,omitempty"`
 	Tags           []string `json:"tags,omitempty"`
 	DownloadZipURL string   `json:"downloadZipURL,omitempty"`
+	// Ref the git tag, branch or sha of the version of the quickstart to download
+	Ref string `json:"ref,omitempty"`
+	// Description a description of the quickstart
+	Description string `json:"description,omitempty"`
+	// Maintainers the maintainers of the quickstart
+	Maintainers []string `json:"maintainers,omitempty"`
+	// RequiredSecrets the names of the secrets an application created from the quickstart needs
+	RequiredSecrets []string `json:"requiredSecrets,omitempty"`
 }
 
 // QuickStarts the configuration of a the quickstarts in the version stream
@@ -459,7 +491,11 @@ func (q *QuickStart) defaultMissingValues(qs *QuickStarts) {
 		q.ID = fmt.Sprintf("%s/%s", q.Owner, q.Name)
 	}
 	if q.DownloadZipURL == "" {
-		q.DownloadZipURL = fmt.Sprintf("https://codeload.github.com/%s/%s/zip/master", q.Owner, q.Name)
+		ref := q.Ref
+		if ref == "" {
+			ref = "master"
+		}
+		q.DownloadZipURL = fmt.Sprintf("https://codeload.github.com/%s/%s/zip/%s", q.Owner, q.Name, ref)
 	}
 }
 
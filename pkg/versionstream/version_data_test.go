@@ -137,3 +137,15 @@ func TestGitURLToName(t *testing.T) {
 		assert.Equal(t, expected, actual, "GitURLToName for %s", gitURL)
 	}
 }
+
+func TestFindChartName(t *testing.T) {
+	t.Parallel()
+
+	name, err := versionstream.FindChartName(dataDir, "prow")
+	require.NoError(t, err)
+	assert.Equal(t, "jenkins-x/prow", name)
+
+	name, err = versionstream.FindChartName(dataDir, "does-not-exist")
+	require.NoError(t, err)
+	assert.Equal(t, "", name)
+}